@@ -0,0 +1,105 @@
+// Package audit defines the structured audit events that services publish for
+// the audit service, such as admin actions, price changes, refunds and
+// permission grants, and a Recorder to publish them. Events are published to
+// the AUDIT JetStream stream on the subject "audit.<service>" so that none is
+// lost while the audit service is unavailable.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/yourusername/goshop/pkg/events"
+)
+
+const (
+	// StreamName is the JetStream stream retaining audit events until the audit
+	// service has stored them
+	StreamName = "AUDIT"
+	// SubjectPrefix prefixes the subject of the audit events of every service
+	SubjectPrefix = "audit."
+	// Subjects matches the audit events of all services
+	Subjects = SubjectPrefix + ">"
+	// Version is the schema version of Entry
+	Version = 1
+)
+
+// Actor types
+const (
+	ActorUser    = "user"    // a customer acting on their own account
+	ActorAdmin   = "admin"   // a staff member using the admin APIs
+	ActorService = "service" // another service calling on its own behalf
+	ActorSystem  = "system"  // scheduled jobs and automatic decisions
+)
+
+// Actor is who performed the action
+type Actor struct {
+	Type      string `json:"type"`
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// Entity is what the action was performed on
+type Entity struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+}
+
+// Change is the old and new value of a changed field
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Entry is one audit event. Action names what happened as "<entity>.<verb>",
+// e.g. "product.price_changed", "payment.refunded" or "role.granted".
+type Entry struct {
+	Action     string                 `json:"action"`
+	Actor      Actor                  `json:"actor"`
+	Entity     Entity                 `json:"entity"`
+	Changes    map[string]Change      `json:"changes,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// EnsureStream creates or updates the AUDIT stream. Both the audit service and
+// the services recording events call it on startup, so that events are retained
+// even when published before the audit service first started.
+func EnsureStream(js nats.JetStreamContext, maxAge time.Duration) error {
+	return events.EnsureStream(js, events.StreamConfig{
+		Name:     StreamName,
+		Subjects: []string{Subjects},
+		MaxAge:   maxAge,
+	})
+}
+
+// Subject returns the subject the audit events of a service are published on
+func Subject(service string) string {
+	return SubjectPrefix + service
+}
+
+// Recorder publishes the audit events of one service
+type Recorder struct {
+	publisher *events.Publisher
+	subject   string
+}
+
+// NewRecorder creates a recorder publishing on behalf of service
+func NewRecorder(js nats.JetStreamContext, service string) *Recorder {
+	return &Recorder{
+		publisher: events.NewPublisher(js, service),
+		subject:   Subject(service),
+	}
+}
+
+// Record publishes an audit event, OccurredAt defaults to now
+func (r *Recorder) Record(ctx context.Context, entry *Entry) error {
+	if entry.OccurredAt.IsZero() {
+		entry.OccurredAt = time.Now().UTC()
+	}
+	return r.publisher.Publish(ctx, r.subject, Version, entry)
+}
@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Middleware records every successful write to an admin route, i.e. a POST,
+// PUT, PATCH or DELETE request whose route contains "/admin/". The action is
+// derived from the route, e.g. "POST /api/v1/reviews/admin/reviews/:id/resolve"
+// is recorded as "reviews.resolve" on the entity ("reviews", <id>). Request
+// bodies are not recorded since they may carry secrets; services record the
+// changed values of sensitive actions with Recorder.Record. Failures to publish
+// are logged and do not fail the request.
+func Middleware(recorder *Recorder, log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		route := c.FullPath()
+		if !isWrite(c.Request.Method) || !strings.Contains(route, "/admin/") {
+			return
+		}
+		if len(c.Errors) > 0 || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		entity, idParam, action := describeRoute(route, c.Request.Method)
+		entry := &Entry{
+			Action: action,
+			Actor:  ActorFromRequest(c, ActorAdmin),
+			Entity: Entity{Type: entity, ID: c.Param(idParam)},
+			Metadata: map[string]interface{}{
+				"method": c.Request.Method,
+				"route":  route,
+				"status": c.Writer.Status(),
+			},
+		}
+		if query := c.Request.URL.RawQuery; query != "" {
+			entry.Metadata["query"] = query
+		}
+		if err := recorder.Record(c.Request.Context(), entry); err != nil {
+			log.Error(c.Request.Context(), "Failed to record audit event",
+				zap.String("action", action),
+				zap.String("route", route),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// ActorFromRequest describes the caller of a request authenticated by the
+// gateway, which passes the user ID in the X-User-ID header
+func ActorFromRequest(c *gin.Context, actorType string) Actor {
+	return Actor{
+		Type:      actorType,
+		ID:        c.GetHeader("X-User-ID"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// describeRoute derives the entity type, the name of the parameter holding the
+// entity ID and the action from a route. The entity is the last static segment
+// other than a trailing verb such as "resolve", and the action is
+// "<entity>.<verb>" where the verb is derived from the method when the route
+// does not end with one.
+func describeRoute(route, method string) (string, string, string) {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	entity, idParam, verb := "", "", ""
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		if isParam(segment) || segment == "admin" {
+			continue
+		}
+		if verb == "" && i == len(segments)-1 && i > 0 && segments[i-1] != "admin" {
+			verb = segment
+			continue
+		}
+		entity = segment
+		if i+1 < len(segments) && isParam(segments[i+1]) {
+			idParam = segments[i+1][1:]
+		}
+		break
+	}
+	if entity == "" {
+		entity, verb = verb, ""
+	}
+	if verb == "" {
+		switch method {
+		case http.MethodPost:
+			verb = "create"
+		case http.MethodDelete:
+			verb = "delete"
+		default:
+			verb = "update"
+		}
+	}
+	return entity, idParam, entity + "." + strings.ReplaceAll(verb, "-", "_")
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*")
+}
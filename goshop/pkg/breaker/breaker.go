@@ -0,0 +1,163 @@
+// Package breaker implements a circuit breaker. A breaker opens after a number
+// of consecutive failures and rejects calls until its open timeout elapses; it
+// then lets a limited number of probe calls through, closing again when they
+// succeed and reopening on the first failure.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow while the breaker rejects calls
+var ErrOpen = errors.New("breaker: circuit open")
+
+// State is the state of a breaker
+type State int
+
+const (
+	Closed   State = iota // calls pass, failures are counted
+	Open                  // calls are rejected
+	HalfOpen              // a limited number of probe calls pass
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Settings configures a breaker
+type Settings struct {
+	FailureThreshold int           // consecutive failures opening the breaker
+	OpenTimeout      time.Duration // time the breaker stays open before probing
+	HalfOpenRequests int           // concurrent probe calls, all must succeed to close
+	// OnStateChange is called with the breaker's lock held, it must not call
+	// the breaker
+	OnStateChange func(name string, from, to State)
+}
+
+// Breaker is a circuit breaker, safe for concurrent use
+type Breaker struct {
+	name     string
+	settings Settings
+	now      func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	failures   int
+	openedAt   time.Time
+	inFlight   int    // probe calls in progress while half-open
+	successes  int    // successful probe calls while half-open
+	generation uint64 // incremented on every state change
+}
+
+// New creates a closed breaker
+func New(name string, settings Settings) *Breaker {
+	if settings.FailureThreshold < 1 {
+		settings.FailureThreshold = 1
+	}
+	if settings.HalfOpenRequests < 1 {
+		settings.HalfOpenRequests = 1
+	}
+	return &Breaker{name: name, settings: settings, now: time.Now}
+}
+
+// Name returns the name of the breaker
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state, an open breaker whose timeout elapsed is
+// reported half-open
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+	return b.state
+}
+
+// Allow reports whether a call may proceed. When it may, done must be called
+// exactly once with the outcome of the call. While the breaker rejects calls
+// Allow returns ErrOpen and the time after which calls may be retried.
+func (b *Breaker) Allow() (done func(success bool), retryAt time.Time, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire()
+
+	switch b.state {
+	case Open:
+		return nil, b.openedAt.Add(b.settings.OpenTimeout), ErrOpen
+	case HalfOpen:
+		if b.inFlight >= b.settings.HalfOpenRequests {
+			return nil, b.now(), ErrOpen
+		}
+		b.inFlight++
+	}
+	generation := b.generation
+	var once sync.Once
+	return func(success bool) {
+		once.Do(func() { b.record(generation, success) })
+	}, time.Time{}, nil
+}
+
+// record records the outcome of a call allowed in generation. Outcomes of
+// calls allowed before the last state change are ignored.
+func (b *Breaker) record(generation uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation != b.generation {
+		return
+	}
+
+	if b.state == HalfOpen {
+		b.inFlight--
+		if !success {
+			b.setState(Open)
+			return
+		}
+		b.successes++
+		if b.successes >= b.settings.HalfOpenRequests {
+			b.setState(Closed)
+		}
+		return
+	}
+
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.settings.FailureThreshold {
+		b.setState(Open)
+	}
+}
+
+// expire moves an open breaker whose timeout elapsed to half-open
+func (b *Breaker) expire() {
+	if b.state == Open && !b.now().Before(b.openedAt.Add(b.settings.OpenTimeout)) {
+		b.setState(HalfOpen)
+	}
+}
+
+func (b *Breaker) setState(state State) {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.failures = 0
+	b.successes = 0
+	b.inFlight = 0
+	if state == Open {
+		b.openedAt = b.now()
+	}
+	if b.settings.OnStateChange != nil && from != state {
+		b.settings.OnStateChange(b.name, from, state)
+	}
+}
@@ -0,0 +1,175 @@
+// Package cache wraps a Redis client with JSON helpers for caching values:
+// namespaced keys, TTL jitter, cache-aside loading with stampede protection and
+// invalidation by tag or key pattern.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrMiss is returned by Get when the key is not cached
+var ErrMiss = errors.New("cache: miss")
+
+const (
+	// defaultJitter spreads expirations of keys cached at the same time by up to 10% of the TTL
+	defaultJitter = 0.1
+	// scanCount is the number of keys examined per SCAN call when invalidating by pattern
+	scanCount = 500
+)
+
+// Cache stores JSON encoded values in Redis under the keys of one namespace,
+// normally the service name, so that services sharing a Redis never collide
+type Cache struct {
+	rdb       *redis.Client
+	namespace string
+	jitter    float64
+	group     singleflight.Group
+}
+
+// Option configures a Cache
+type Option func(*Cache)
+
+// WithJitter sets the fraction of the TTL randomly added to each expiration,
+// 0 disables jitter
+func WithJitter(fraction float64) Option {
+	return func(c *Cache) {
+		c.jitter = fraction
+	}
+}
+
+// New creates a cache storing keys under namespace
+func New(rdb *redis.Client, namespace string, opts ...Option) *Cache {
+	c := &Cache{
+		rdb:       rdb,
+		namespace: namespace,
+		jitter:    defaultJitter,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Key joins parts into a cache key, e.g. Key("product", "42") is "product:42".
+// The namespace is added when the key is used, not here.
+func Key(parts ...string) string {
+	return strings.Join(parts, ":")
+}
+
+// Get decodes the value cached under key into dest, returning ErrMiss when the key
+// is not cached
+func (c *Cache) Get(ctx context.Context, key string, dest interface{}) error {
+	data, err := c.rdb.Get(ctx, c.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return ErrMiss
+	}
+	if err != nil {
+		return fmt.Errorf("cache: get %s: %w", key, err)
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("cache: decode %s: %w", key, err)
+	}
+	return nil
+}
+
+// Set caches value under key as JSON for ttl plus jitter and attaches the key to
+// tags, so that it can be removed later with InvalidateTags
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cache: encode %s: %w", key, err)
+	}
+	ttl = c.withJitter(ttl)
+
+	pipe := c.rdb.TxPipeline()
+	pipe.Set(ctx, c.key(key), data, ttl)
+	for _, tag := range tags {
+		// The tag set lives at least as long as its newest member, stale members are
+		// harmless because invalidation deletes keys that may already have expired
+		pipe.SAdd(ctx, c.tagKey(tag), c.key(key))
+		pipe.Expire(ctx, c.tagKey(tag), ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("cache: set %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes keys from the cache
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	full := make([]string, len(keys))
+	for i, key := range keys {
+		full[i] = c.key(key)
+	}
+	if err := c.rdb.Unlink(ctx, full...).Err(); err != nil {
+		return fmt.Errorf("cache: delete: %w", err)
+	}
+	return nil
+}
+
+// InvalidateTags removes every key attached to tags
+func (c *Cache) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		keys, err := c.rdb.SMembers(ctx, c.tagKey(tag)).Result()
+		if err != nil {
+			return fmt.Errorf("cache: read tag %s: %w", tag, err)
+		}
+		keys = append(keys, c.tagKey(tag))
+		if err := c.rdb.Unlink(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("cache: invalidate tag %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// InvalidatePattern removes the keys of the namespace matching the glob pattern,
+// e.g. "product:*", and returns how many were removed. It walks the keyspace with
+// SCAN, so it is meant for administrative invalidation rather than hot paths.
+func (c *Cache) InvalidatePattern(ctx context.Context, pattern string) (int, error) {
+	var cursor uint64
+	removed := 0
+	for {
+		keys, next, err := c.rdb.Scan(ctx, cursor, c.key(pattern), scanCount).Result()
+		if err != nil {
+			return removed, fmt.Errorf("cache: scan %s: %w", pattern, err)
+		}
+		if len(keys) > 0 {
+			n, err := c.rdb.Unlink(ctx, keys...).Result()
+			if err != nil {
+				return removed, fmt.Errorf("cache: invalidate %s: %w", pattern, err)
+			}
+			removed += int(n)
+		}
+		if next == 0 {
+			return removed, nil
+		}
+		cursor = next
+	}
+}
+
+func (c *Cache) key(key string) string {
+	return c.namespace + ":" + key
+}
+
+func (c *Cache) tagKey(tag string) string {
+	return c.namespace + ":tag:" + tag
+}
+
+func (c *Cache) withJitter(ttl time.Duration) time.Duration {
+	if c.jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int63n(int64(float64(ttl)*c.jitter)+1))
+}
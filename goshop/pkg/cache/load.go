@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Loader loads a value from the source of truth when it is not cached
+type Loader[T any] func(ctx context.Context) (T, error)
+
+// GetOrLoad returns the value cached under key, loading and caching it on a miss.
+// Concurrent misses for the same key within the process share a single load, so an
+// expired hot key does not stampede the source. Redis failures are not fatal: the
+// value is loaded directly and the error is dropped, a cache must never make the
+// source unreachable.
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load Loader[T], tags ...string) (T, error) {
+	var value T
+	err := c.Get(ctx, key, &value)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, ErrMiss) {
+		return load(ctx)
+	}
+
+	v, err, _ := c.group.Do(c.key(key), func() (interface{}, error) {
+		loaded, err := load(ctx)
+		if err != nil {
+			return loaded, err
+		}
+		// Best effort, the loaded value is returned even if it cannot be cached
+		_ = c.Set(ctx, key, loaded, ttl, tags...)
+		return loaded, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v.(T), nil
+}
@@ -1,23 +1,54 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/viper"
 )
 
+// defaultJWTSecret is the development JWT secret, refused in production
+const defaultJWTSecret = "change-me-in-production"
+
 // Config contains configuration items for all services
 type Config struct {
 	Service  ServiceConfig
+	Log      LoggerConfig
 	Database DatabaseConfig
 	Redis    RedisConfig
 	Search   SearchConfig
 	NATS     NATSConfig
+	Storage  StorageConfig
 	Auth     AuthConfig
 	Trace    TraceConfig
 	HTTP     HTTPConfig
 	GRPC     GRPCConfig
+	I18n     I18nConfig
+	Workflow WorkflowConfig
+	Secrets  SecretsConfig
+
+	User         UserConfig
+	Notification NotificationConfig
+	Webhook      WebhookConfig
+	Support      SupportConfig
+	Audit        AuditConfig
+	Scheduler    SchedulerConfig
+	Seller       SellerConfig
+	GraphQL      GraphQLConfig
+	Currency     CurrencyConfig
+	Fraud        FraudConfig
+	Discovery    DiscoveryConfig
+	Gateway      GatewayConfig
+
+	// Endpoints maps service names to their HTTP base URLs
+	Endpoints map[string]string
+
+	// FeatureFlags holds the flags of the service by key, flags stored in Redis
+	// take precedence at runtime
+	FeatureFlags map[string]FeatureFlagConfig
 }
 
 // ServiceConfig contains basic service information
@@ -27,6 +58,22 @@ type ServiceConfig struct {
 	LogLevel    string
 }
 
+// LoggerConfig contains log output configuration
+type LoggerConfig struct {
+	Outputs          []string // stdout, file and/or syslog
+	Encoding         string   // json or console, empty for console in development and json elsewhere
+	File             string   // log file path for the file output
+	MaxSize          int      // megabytes written before the log file is rotated
+	MaxBackups       int      // rotated log files kept
+	MaxAge           int      // days rotated log files are kept
+	Compress         bool     // gzip rotated log files
+	SyslogNetwork    string   // syslog network, empty for the local daemon
+	SyslogAddr       string   // syslog address, empty for the local daemon
+	SampleInitial    int      // debug and info entries logged per message and second before sampling, 0 disables sampling
+	SampleThereafter int      // then one entry out of SampleThereafter is logged
+	RedactKeys       []string // extra field keys redacted in addition to passwords, tokens, phones, emails and card numbers
+}
+
 // DatabaseConfig contains database configuration
 type DatabaseConfig struct {
 	Host     string
@@ -35,6 +82,14 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	MaxOpenConns    int      // maximum open connections, 0 means unlimited
+	MaxIdleConns    int      // maximum idle connections kept in the pool
+	ConnMaxLifetime int      // minutes a connection may be reused
+	ConnMaxIdleTime int      // minutes a connection may stay idle
+	SlowThreshold   int      // milliseconds, slower queries are logged as warnings
+	Replicas        []string // read replica hosts in host:port form, sharing credentials with the primary
+	MigrationsPath  string   // directory of SQL migrations applied on startup, empty to skip
 }
 
 // RedisConfig contains Redis configuration
@@ -47,26 +102,128 @@ type RedisConfig struct {
 
 // SearchConfig contains search engine configuration
 type SearchConfig struct {
+	// Engine selects the search engine implementation, only meilisearch is
+	// supported for now
+	Engine    string
 	URL       string
 	APIKey    string
 	IndexName string
+	Typo      TypoConfig
+}
+
+// TypoConfig controls typo tolerance, words shorter than the minimum lengths
+// must match exactly
+type TypoConfig struct {
+	Enabled           bool
+	OneTypoMinLength  int
+	TwoTyposMinLength int
 }
 
 // NATSConfig contains NATS configuration
 type NATSConfig struct {
-	URL string
+	URL          string
+	StreamMaxAge int // hours events are kept in the domain streams created by consumers
 }
 
-// AuthConfig contains authentication configuration
+// StorageConfig contains the S3 compatible object storage of uploaded files.
+// Endpoint is empty for AWS S3 and points at the server for MinIO, which also
+// needs path-style addressing. Objects are linked through PublicURL, the bucket
+// or a CDN in front of it.
+type StorageConfig struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string // empty uses the default AWS credential chain
+	SecretAccessKey string
+	UsePathStyle    bool
+	PublicURL       string
+}
+
+// AuthConfig contains authentication configuration. The gateway and the
+// services checking permissions cache the roles and permissions of a user in
+// Redis for PermissionCacheTTL, so role changes apply within that delay.
 type AuthConfig struct {
-	JWTSecret     string
-	TokenDuration int // minutes
+	JWTSecret            string
+	TokenDuration        int // minutes an access token is valid
+	RefreshTokenDuration int // hours a refresh token is valid
+	PermissionCacheTTL   int // seconds, 0 asks the auth service on every check
+}
+
+// UserConfig contains the account flows of the user service. Verification
+// emails link to VerifyEmailURL with the token in the token query parameter,
+// the link expires after EmailVerificationTTL and a new one may be requested
+// once every VerificationResendInterval. Address provinces, cities and
+// districts are checked against the dataset in RegionsFile, the built-in
+// dataset when empty.
+type UserConfig struct {
+	VerifyEmailURL             string // e.g. https://shop.example.com/verify-email
+	EmailVerificationTTL       int    // hours
+	VerificationResendInterval int    // seconds
+	MaxAddresses               int    // per user
+	RegionsFile                string
+	AvatarMaxSize              int // bytes of an uploaded avatar
+	AvatarSize                 int // pixels of the square avatars are resized to
+	EventStreamMaxAge          int // hours user events are kept in the USERS stream
+	CacheTTL                   int // seconds users are cached in Redis, 0 disables the cache
+	PasswordPolicy             PasswordPolicyConfig
+	Lockout                    LockoutConfig
+	OAuth                      OAuthConfig
+}
+
+// PasswordPolicyConfig contains the rules passwords must follow when users register
+// or change their password. History is the number of previous passwords, besides
+// the current one, that cannot be reused; 0 only forbids the current password.
+type PasswordPolicyConfig struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	History       int
+}
+
+// LockoutConfig contains the brute-force protection of password logins. Failed
+// logins are counted in Redis per account and per client IP over Window; an
+// account is locked for LockDuration after MaxFailures consecutive failures and
+// an IP is refused logins for the rest of the window after MaxIPFailures. Locked
+// users are emailed a link to UnlockURL that unlocks the account.
+type LockoutConfig struct {
+	MaxFailures   int
+	MaxIPFailures int
+	Window        int // minutes
+	LockDuration  int // minutes
+	UnlockURL     string
+}
+
+// OAuthConfig contains the social login providers of the user service. The
+// state parameter of authorization requests is signed with StateSecret and
+// expires after StateTTL; providers without a client ID are disabled.
+type OAuthConfig struct {
+	StateSecret string
+	StateTTL    int // seconds
+	Google      OAuthProviderConfig
+	GitHub      OAuthProviderConfig
+	WeChat      OAuthProviderConfig // website application of the WeChat open platform
+}
+
+// OAuthProviderConfig contains the OAuth client registered with a provider,
+// RedirectURL must match the callback registered with the provider
+type OAuthProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Enabled reports whether the provider is configured
+func (c OAuthProviderConfig) Enabled() bool {
+	return c.ClientID != ""
 }
 
 // TraceConfig contains distributed tracing configuration
 type TraceConfig struct {
-	Enabled bool
-	URL     string
+	Enabled     bool
+	URL         string  // OTLP/HTTP traces endpoint, e.g. http://localhost:4318/v1/traces
+	SampleRatio float64 // fraction of new traces sampled, traces started upstream follow the caller
 }
 
 // HTTPConfig contains HTTP server configuration
@@ -75,15 +232,405 @@ type HTTPConfig struct {
 	Timeout int // seconds
 }
 
-// GRPCConfig contains gRPC server configuration
+// GRPCConfig contains gRPC server and client configuration
 type GRPCConfig struct {
 	Port int
+
+	Targets       map[string]string // service names to client targets, e.g. dns:///user:9001
+	CallTimeout   int               // milliseconds, deadline of outgoing calls without one
+	MaxRetries    int               // retries of outgoing calls failing with a transient error
+	HedgingDelay  int               // milliseconds before a hedged call is sent, 0 disables hedging
+	KeepaliveTime int               // seconds between keepalive pings on idle connections
+}
+
+// I18nConfig contains localization configuration
+type I18nConfig struct {
+	DefaultLocale string
+	Locales       []string // supported locales, including the default one
+}
+
+// WorkflowConfig contains the CMS editorial review configuration
+type WorkflowConfig struct {
+	ReviewTypes   []string // content types that must be approved before publishing
+	ApproverRoles []string // roles allowed to approve content and edit it after publishing
+}
+
+// FeatureFlagConfig describes a feature flag. An enabled flag is on for the
+// targeted users and segments and for Rollout percent of the other users.
+type FeatureFlagConfig struct {
+	Enabled  bool
+	Rollout  int      // percentage of users the flag is on for, 100 for everyone
+	Users    []string // user IDs the flag is always on for
+	Segments []string // user segments the flag is always on for, e.g. "staff" or "beta"
+}
+
+// NotificationConfig contains the providers and delivery settings of the
+// notification service
+type NotificationConfig struct {
+	EmailProvider string   // smtp or sendgrid, empty disables email
+	SMSProvider   string   // twilio or aliyun, empty disables SMS
+	PushProvider  string   // fcm, empty disables push notifications
+	FromEmail     string   // sender address of emails
+	FromName      string   // sender name of emails
+	AlertEmails   []string // recipients of operational alerts such as low stock
+	MaxAttempts   int      // delivery attempts before a notification is marked failed
+	RetryInterval int      // seconds before the first retry, doubled after each failed attempt
+
+	SMTP     SMTPConfig
+	SendGrid SendGridConfig
+	Twilio   TwilioConfig
+	Aliyun   AliyunSMSConfig
+	FCM      FCMConfig
+}
+
+// WebhookConfig contains the delivery settings of the webhook service
+type WebhookConfig struct {
+	Subjects         []string // NATS subjects whose events can be subscribed to by endpoints, e.g. order.>
+	Timeout          int      // seconds an endpoint has to respond to a delivery
+	MaxAttempts      int      // delivery attempts before a delivery is dead-lettered
+	RetryInterval    int      // seconds before the first retry, doubled after each failed attempt
+	MaxRetryInterval int      // upper bound in seconds of the delay between two attempts
+	AllowHTTP        bool     // accept plain http endpoint URLs, for development only
+}
+
+// SupportConfig contains the SLA targets and email settings of the support
+// service. SLA targets are keyed by ticket priority: low, normal, high, urgent.
+type SupportConfig struct {
+	FirstResponseMinutes map[string]int // minutes until the first agent reply is due
+	ResolutionMinutes    map[string]int // minutes until resolution is due, time waiting on the customer excluded
+	InboundToken         string         // shared secret presented by the inbound email webhook
+	ReplyAddress         string         // address customers reply to, replies are matched by the ticket number in the subject
+}
+
+// AuditConfig contains the retention settings of the audit service. Retention
+// policies override RetentionDays for the actions they match.
+type AuditConfig struct {
+	RetentionDays    int // days records matching no retention policy are kept
+	MinRetentionDays int // lower bound of the retention of any policy
+	PurgeInterval    int // minutes between two purges of expired records
+	StreamMaxAge     int // hours events are kept in the AUDIT stream if not yet stored
+}
+
+// SchedulerConfig contains the settings of the scheduler service and the shared
+// secret services use to authenticate its job callbacks
+type SchedulerConfig struct {
+	CallbackToken  string // sent in the X-Scheduler-Token header of every job callback
+	PollInterval   int    // seconds between two checks for due jobs
+	MaxConcurrency int    // jobs run at the same time
+	HistoryDays    int    // days execution history is kept
+}
+
+// SellerConfig contains the commission and payout settings of the seller
+// service. Commission rules per category override DefaultCommissionRate.
+type SellerConfig struct {
+	DefaultCommissionRate float64 // percent of the line total kept as commission when no rule applies
+	HoldDays              int     // days after delivery before sale proceeds become available for payout
+	MinPayout             float64 // smallest available balance paid out
+	SettleSchedule        string  // cron expression of the job settling delivered sub-orders
+	PayoutSchedule        string  // cron expression of the job creating payouts
+}
+
+// GraphQLConfig contains the settings of the federation router of the gateway.
+// Subgraphs are reached at Path on their endpoint.
+type GraphQLConfig struct {
+	Subgraphs       []string // services composed into the graph
+	Path            string   // path of the GraphQL endpoint of every subgraph
+	RefreshInterval int      // seconds between two reloads of the subgraph schemas
+	Timeout         int      // seconds a subgraph request may take
+}
+
+// CurrencyConfig contains the exchange rate settings of the currency service.
+// Rates of Currencies against Base are fetched from Provider on RefreshSchedule.
+type CurrencyConfig struct {
+	Base            string             // currency the rates are quoted against
+	Currencies      []string           // currencies rates are kept for, including Base
+	Provider        string             // openexchangerates or static
+	ProviderURL     string             // latest rates endpoint of the provider
+	APIKey          string             // app ID of the provider
+	StaticRates     map[string]float64 // rates of the static provider in units per unit of Base
+	RefreshSchedule string             // cron expression of the job fetching the rates
+	MaxAge          int                // hours after which rates are reported as stale
+}
+
+// FraudConfig contains the risk scoring settings of the fraud service. Attempts
+// scoring ReviewScore or more are held for manual review, BlockScore or more
+// are rejected.
+type FraudConfig struct {
+	ReviewScore           int      // lowest score held for review
+	BlockScore            int      // lowest score rejected
+	UserAttemptsPerHour   int      // attempts of a user within an hour before velocity rules fire
+	IPAttemptsPerHour     int      // attempts from an IP address within an hour
+	DeviceAttemptsPerHour int      // attempts from a device within an hour
+	DeviceUsersPerDay     int      // distinct users seen on a device within a day
+	HighAmount            float64  // amount in the default currency considered high for new accounts
+	NewAccountHours       int      // accounts younger than this are new
+	DisposableDomains     []string // email domains of disposable mailbox providers
+	ReputationURL         string   // IP reputation lookup endpoint, empty disables the lookup
+	ReputationAPIKey      string
+	ReputationTimeout     int // milliseconds a reputation lookup may take before it is skipped
+}
+
+// DiscoveryConfig contains how the gateway finds the instances of services.
+// The static provider uses Endpoints, the consul provider the instances whose
+// Consul checks pass. Either way instances failing UnhealthyThreshold
+// consecutive probes of HealthPath are skipped until they recover.
+type DiscoveryConfig struct {
+	Provider            string // static or consul
+	ConsulAddress       string
+	ConsulToken         string
+	Datacenter          string // Consul datacenter, the agent's when empty
+	RefreshInterval     int    // seconds between registry lookups
+	HealthPath          string
+	HealthCheckInterval int // seconds between health probes of every instance
+	UnhealthyThreshold  int // consecutive failures evicting an instance
+}
+
+// GatewayConfig contains the gateway's handling of upstream services
+type GatewayConfig struct {
+	CircuitBreaker CircuitBreakerConfig
+	ResponseCache  ResponseCacheConfig
+	APIKeys        APIKeyConfig
+	Retry          RetryConfig
+	CORS           CORSConfig
+	IPFilter       IPFilterConfig
+	Compression    CompressionConfig
+	RBAC           RBACConfig
+	RequestAudit   RequestAuditConfig
+	Admin          AdminConfig
+
+	// TrustedProxies are the IPs or CIDRs of the load balancers in front of
+	// the gateway, whose X-Forwarded-For header gives the client IP. The client
+	// IP is the connection's peer when empty.
+	TrustedProxies []string
+
+	MaxBodySize     int // bytes, larger request bodies are rejected with 413
+	UpstreamTimeout int // seconds forwarded requests may take unless their route sets a timeout, 0 disables it
+	// Routes are forwarded in addition to the routes built into the gateway,
+	// which take precedence. Changes apply without restarting the gateway.
+	Routes []RouteConfig
+}
+
+// RouteConfig contains a route of the gateway's route table
+type RouteConfig struct {
+	Method     string   // HTTP method, ANY matches every method
+	Path       string   // gateway path, may contain :name and a trailing *name parameter
+	Service    string   // upstream service
+	Upstream   string   // path on the service with the same parameters, Path when empty
+	Auth       bool     // requests must be authenticated
+	APIKey     bool     // partners may authenticate with a signed API key instead of a user token
+	Roles      []string // roles a user token must have, API keys are refused when set
+	Permission string   // permission code the API key or the user's role must hold, e.g. orders.read
+	NoCompress bool     // never compress the responses, e.g. of already compressed downloads
+	RateLimit  int      // requests per minute per client IP, 0 disables the limit
+	Timeout    int      // seconds the upstream may take, 0 disables the timeout
+}
+
+// APIKeyConfig contains the authentication of partner requests with API keys.
+// Requests are signed with the key's secret and rejected when their timestamp
+// is more than SignatureWindow away from the gateway's clock, or when their
+// signature was already accepted, so a request cannot be replayed.
+type APIKeyConfig struct {
+	SignatureWindow int // seconds
+}
+
+// CircuitBreakerConfig contains the breaker kept per upstream service. A
+// breaker opens after FailureThreshold consecutive failed requests, rejects
+// requests for OpenTimeout and then lets HalfOpenRequests probes through.
+type CircuitBreakerConfig struct {
+	FailureThreshold int
+	OpenTimeout      int // seconds
+	HalfOpenRequests int
+}
+
+// CORSConfig contains the cross-origin requests browsers may make to the
+// gateway. AllowedOrigins lists origins such as https://shop.example.com, an
+// origin may start with a * wildcard subdomain, e.g. https://*.example.com, and
+// a lone * allows every origin, which browsers refuse with credentials.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string // response headers readable by scripts
+	AllowCredentials bool     // allow cookies and Authorization headers
+	MaxAge           int      // seconds browsers may cache preflight responses
+}
+
+// AdminConfig contains the /api/v1/admin/ route group of the back office. A
+// request to /api/v1/admin/{name}/... is forwarded to the service Services[name]
+// at /api/v1/{name}/admin/..., requires a user token with one of Roles and is
+// limited per user, more strictly than the public routes.
+type AdminConfig struct {
+	Roles     []string
+	RateLimit int               // requests per minute per user, 0 disables the limit
+	Services  map[string]string // path name to service, e.g. sellers to seller
+}
+
+// RBACConfig contains the role and permission checks of the gateway. Roles
+// come from the claims of the user's access token and the roles assigned to
+// the user, permissions from the auth service, cached for
+// AuthConfig.PermissionCacheTTL.
+type RBACConfig struct {
+	Rules []RBACRuleConfig
+}
+
+// RBACRuleConfig requires a user token with one of Roles, when set, whose role
+// holds Permission, when set, on the requests whose path starts with
+// PathPrefix and whose method is in Methods, every method when empty.
+type RBACRuleConfig struct {
+	PathPrefix string // e.g. /api/v1/admin/
+	Methods    []string
+	Roles      []string
+	Permission string // e.g. payments.refund
+}
+
+// IPFilterConfig contains the client IP and country rules of the gateway. A
+// request must pass every rule whose PathPrefix its path starts with. The
+// country of a request is read from CountryHeader, set by the CDN in front of
+// the gateway, e.g. CF-IPCountry or CloudFront-Viewer-Country.
+type IPFilterConfig struct {
+	CountryHeader string
+	Rules         []IPRuleConfig
+}
+
+// IPRuleConfig denies the IPs in Deny and, when Allow is set, every IP not in
+// Allow. Countries are ISO 3166-1 alpha-2 codes; requests of unknown country
+// are denied when AllowCountries is set.
+type IPRuleConfig struct {
+	PathPrefix     string   // e.g. /api/v1/admin/
+	Allow          []string // IPs or CIDRs
+	Deny           []string // IPs or CIDRs
+	AllowCountries []string
+	DenyCountries  []string
+}
+
+// RequestAuditConfig contains the audit records of requests to sensitive
+// routes such as payments, refunds and admin actions, kept for compliance
+// investigations. A request matching a rule is recorded with its method, path,
+// user, status and latency on the audit stream, and its request body when the
+// rule captures it. Values of RedactFields are replaced in JSON and form
+// bodies, bodies larger than MaxBodySize are not captured.
+type RequestAuditConfig struct {
+	Rules        []RequestAuditRuleConfig
+	RedactFields []string // field names matched case-insensitively at any depth
+	MaxBodySize  int      // bytes
+}
+
+// RequestAuditRuleConfig records the requests whose path starts with
+// PathPrefix and whose method is in Methods, every method when empty.
+type RequestAuditRuleConfig struct {
+	PathPrefix  string // e.g. /api/v1/payments/
+	Methods     []string
+	CaptureBody bool
+}
+
+// CompressionConfig contains the gzip compression of responses. Responses are
+// compressed when the client accepts gzip, their Content-Type is listed in
+// ContentTypes and their body reaches MinSize. Brotli is not offered, the
+// standard library has no encoder for it.
+type CompressionConfig struct {
+	Enabled       bool
+	MinSize       int      // bytes, smaller bodies are sent as is
+	Level         int      // gzip level from 1 (fastest) to 9 (smallest), -1 for the default
+	ContentTypes  []string // media types without parameters, e.g. application/json
+	ExcludedPaths []string // path prefixes never compressed
+}
+
+// RetryConfig contains the retries of idempotent requests without a body whose
+// upstream could not be reached or answered 502, 503 or 504. Retries go to
+// another instance and are bounded gateway-wide by Budget, a ratio of the
+// requests forwarded, on top of MinRetriesPerSecond.
+type RetryConfig struct {
+	MaxRetries          int // retries per request, 0 disables retries
+	Budget              float64
+	MinRetriesPerSecond int
+}
+
+// ResponseCacheConfig contains the Redis cache of GET responses. Routes opt in
+// by rule name, routes whose rule is not configured are never cached.
+type ResponseCacheConfig struct {
+	Enabled      bool
+	MaxEntrySize int // bytes, larger responses are not cached
+	Rules        map[string]CacheRuleConfig
+}
+
+// CacheRuleConfig contains how long the responses of a rule are cached and the
+// NATS subjects whose events invalidate them
+type CacheRuleConfig struct {
+	TTL          int // seconds
+	InvalidateOn []string
+}
+
+// SMTPConfig contains the SMTP relay used to send emails
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// SendGridConfig contains the SendGrid API credentials
+type SendGridConfig struct {
+	APIKey string
+}
+
+// TwilioConfig contains the Twilio SMS credentials
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	From       string // sending phone number or messaging service SID
+}
+
+// AliyunSMSConfig contains the Aliyun SMS credentials. Aliyun only sends
+// pre-approved templates, Templates maps template keys with dots replaced by
+// underscores, e.g. order_paid, to Aliyun template codes.
+type AliyunSMSConfig struct {
+	AccessKeyID     string
+	AccessKeySecret string
+	SignName        string
+	Templates       map[string]string
+}
+
+// FCMConfig contains the Firebase Cloud Messaging project
+type FCMConfig struct {
+	ProjectID       string
+	CredentialsFile string // service account key file, empty to use the default credentials
+}
+
+// SecretsConfig contains the secret backends used to resolve secret references
+// such as vault://secret/goshop/database#password in other settings
+type SecretsConfig struct {
+	VaultAddr      string // Vault server address, defaults to VAULT_ADDR
+	VaultToken     string // Vault token, defaults to VAULT_TOKEN
+	VaultNamespace string // Vault Enterprise namespace, empty for none
+	AWSRegion      string // AWS Secrets Manager region, empty to use the default chain
+	CacheTTL       int    // seconds a resolved secret is reused before being fetched again
 }
 
 // DSN returns PostgreSQL connection string
 func (c *DatabaseConfig) DSN() string {
+	return c.hostDSN(c.Host, c.Port)
+}
+
+// ReplicaDSNs returns PostgreSQL connection strings of the read replicas
+func (c *DatabaseConfig) ReplicaDSNs() ([]string, error) {
+	dsns := make([]string, 0, len(c.Replicas))
+	for _, replica := range c.Replicas {
+		host, port, err := net.SplitHostPort(replica)
+		if err != nil {
+			host, port = replica, strconv.Itoa(c.Port)
+		}
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replica address %q: %w", replica, err)
+		}
+		dsns = append(dsns, c.hostDSN(host, p))
+	}
+	return dsns, nil
+}
+
+func (c *DatabaseConfig) hostDSN(host string, port int) string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
+		host, port, c.User, c.Password, c.DBName, c.SSLMode)
 }
 
 // RedisAddr returns Redis address
@@ -93,6 +640,25 @@ func (c *RedisConfig) RedisAddr() string {
 
 // Load loads configuration from file and environment variables
 func Load(serviceName, configPath string) (*Config, error) {
+	v, err := newViper(serviceName, configPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := decode(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := NewSecretResolver(cfg.Secrets).ResolveConfig(context.Background(), cfg); err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// newViper reads the configuration sources of a service into a viper instance
+func newViper(serviceName, configPath string) (*viper.Viper, error) {
 	v := viper.New()
 
 	// Set default values
@@ -112,6 +678,9 @@ func Load(serviceName, configPath string) (*Config, error) {
 	v.SetEnvPrefix("GOSHOP")
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
+	// Also honor the standard Vault variables
+	_ = v.BindEnv("secrets.vaultAddr", "GOSHOP_SECRETS_VAULTADDR", "VAULT_ADDR")
+	_ = v.BindEnv("secrets.vaultToken", "GOSHOP_SECRETS_VAULTTOKEN", "VAULT_TOKEN")
 
 	if err := v.ReadInConfig(); err != nil {
 		// If config file not found, just warn, not error
@@ -120,6 +689,11 @@ func Load(serviceName, configPath string) (*Config, error) {
 		}
 	}
 
+	return v, nil
+}
+
+// decode parses the settings of v into a Config
+func decode(v *viper.Viper) (*Config, error) {
 	var config Config
 	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
@@ -135,6 +709,20 @@ func setDefaults(v *viper.Viper, serviceName string) {
 	v.SetDefault("service.environment", "development")
 	v.SetDefault("service.logLevel", "info")
 
+	// Log configuration
+	v.SetDefault("log.outputs", []string{"stdout"})
+	v.SetDefault("log.encoding", "")
+	v.SetDefault("log.file", fmt.Sprintf("logs/%s.log", serviceName))
+	v.SetDefault("log.maxSize", 100) // 100 MB
+	v.SetDefault("log.maxBackups", 7)
+	v.SetDefault("log.maxAge", 30) // 30 days
+	v.SetDefault("log.compress", true)
+	v.SetDefault("log.syslogNetwork", "")
+	v.SetDefault("log.syslogAddr", "")
+	v.SetDefault("log.sampleInitial", 100)
+	v.SetDefault("log.sampleThereafter", 100)
+	v.SetDefault("log.redactKeys", []string{})
+
 	// Database configuration
 	v.SetDefault("database.host", "localhost")
 	v.SetDefault("database.port", 5432)
@@ -142,6 +730,13 @@ func setDefaults(v *viper.Viper, serviceName string) {
 	v.SetDefault("database.password", "goshop")
 	v.SetDefault("database.dbname", fmt.Sprintf("goshop_%s", serviceName))
 	v.SetDefault("database.sslmode", "disable")
+	v.SetDefault("database.maxOpenConns", 25)
+	v.SetDefault("database.maxIdleConns", 10)
+	v.SetDefault("database.connMaxLifetime", 30) // 30 minutes
+	v.SetDefault("database.connMaxIdleTime", 5)  // 5 minutes
+	v.SetDefault("database.slowThreshold", 200)  // 200 milliseconds
+	v.SetDefault("database.replicas", []string{})
+	v.SetDefault("database.migrationsPath", "")
 
 	// Redis configuration
 	v.SetDefault("redis.host", "localhost")
@@ -150,20 +745,58 @@ func setDefaults(v *viper.Viper, serviceName string) {
 	v.SetDefault("redis.db", 0)
 
 	// Search engine configuration
+	v.SetDefault("search.engine", "meilisearch")
 	v.SetDefault("search.url", "http://localhost:7700")
 	v.SetDefault("search.apiKey", "masterKey")
 	v.SetDefault("search.indexName", fmt.Sprintf("%s_index", serviceName))
+	v.SetDefault("search.typo.enabled", true)
+	v.SetDefault("search.typo.oneTypoMinLength", 5)
+	v.SetDefault("search.typo.twoTyposMinLength", 9)
 
 	// NATS configuration
 	v.SetDefault("nats.url", "nats://localhost:4222")
+	v.SetDefault("nats.streamMaxAge", 7*24)
+
+	// Object storage configuration, the development defaults use the MinIO
+	// server of docker-compose
+	v.SetDefault("storage.endpoint", "http://localhost:9000")
+	v.SetDefault("storage.region", "us-east-1")
+	v.SetDefault("storage.bucket", "goshop")
+	v.SetDefault("storage.accessKeyID", "minioadmin")
+	v.SetDefault("storage.secretAccessKey", "minioadmin")
+	v.SetDefault("storage.usePathStyle", true)
+	v.SetDefault("storage.publicURL", "http://localhost:9000/goshop")
 
 	// Authentication configuration
-	v.SetDefault("auth.jwtSecret", "change-me-in-production")
-	v.SetDefault("auth.tokenDuration", 60) // 60 minutes
+	v.SetDefault("auth.jwtSecret", defaultJWTSecret)
+	v.SetDefault("auth.tokenDuration", 60)         // 60 minutes
+	v.SetDefault("auth.refreshTokenDuration", 720) // 30 days
+	v.SetDefault("auth.permissionCacheTTL", 60)
+
+	// User account flows
+	v.SetDefault("user.verifyEmailURL", "http://localhost:8080/api/v1/users/verify-email")
+	v.SetDefault("user.emailVerificationTTL", 24)
+	v.SetDefault("user.verificationResendInterval", 60)
+	v.SetDefault("user.maxAddresses", 20)
+	v.SetDefault("user.avatarMaxSize", 5<<20) // 5 MB
+	v.SetDefault("user.avatarSize", 256)
+	v.SetDefault("user.eventStreamMaxAge", 7*24)
+	v.SetDefault("user.cacheTTL", 300)
+	v.SetDefault("user.passwordPolicy.minLength", 8)
+	v.SetDefault("user.passwordPolicy.requireLower", true)
+	v.SetDefault("user.passwordPolicy.requireDigit", true)
+	v.SetDefault("user.passwordPolicy.history", 5)
+	v.SetDefault("user.lockout.maxFailures", 5)
+	v.SetDefault("user.lockout.maxIPFailures", 50)
+	v.SetDefault("user.lockout.window", 15)
+	v.SetDefault("user.lockout.lockDuration", 30)
+	v.SetDefault("user.lockout.unlockURL", "http://localhost:8080/api/v1/users/unlock")
+	v.SetDefault("user.oauth.stateTTL", 600)
 
 	// Tracing configuration
 	v.SetDefault("trace.enabled", true)
-	v.SetDefault("trace.url", "http://localhost:14268/api/traces")
+	v.SetDefault("trace.url", "http://localhost:4318/v1/traces")
+	v.SetDefault("trace.sampleRatio", 1.0)
 
 	// HTTP configuration
 	v.SetDefault("http.port", getDefaultHTTPPort(serviceName))
@@ -171,22 +804,255 @@ func setDefaults(v *viper.Viper, serviceName string) {
 
 	// gRPC configuration
 	v.SetDefault("grpc.port", getDefaultGRPCPort(serviceName))
+	v.SetDefault("grpc.targets", getDefaultGRPCTargets())
+	v.SetDefault("grpc.callTimeout", 3000) // 3 seconds
+	v.SetDefault("grpc.maxRetries", 2)
+	v.SetDefault("grpc.hedgingDelay", 0)
+	v.SetDefault("grpc.keepaliveTime", 30) // 30 seconds
+
+	// Localization configuration
+	v.SetDefault("i18n.defaultLocale", "zh-CN")
+	v.SetDefault("i18n.locales", []string{"zh-CN", "en-US"})
+
+	// Editorial workflow configuration, no content type requires review by default
+	v.SetDefault("workflow.reviewTypes", []string{})
+	v.SetDefault("workflow.approverRoles", []string{"admin"})
+
+	// Secrets configuration
+	v.SetDefault("secrets.vaultAddr", "")
+	v.SetDefault("secrets.vaultToken", "")
+	v.SetDefault("secrets.vaultNamespace", "")
+	v.SetDefault("secrets.awsRegion", "")
+	v.SetDefault("secrets.cacheTTL", 300) // 5 minutes
+
+	// Notification configuration, SMS and push are disabled until a provider is configured
+	v.SetDefault("notification.emailProvider", "smtp")
+	v.SetDefault("notification.smsProvider", "")
+	v.SetDefault("notification.pushProvider", "")
+	v.SetDefault("notification.fromEmail", "noreply@goshop.local")
+	v.SetDefault("notification.fromName", "GoShop")
+	v.SetDefault("notification.alertEmails", []string{})
+	v.SetDefault("notification.maxAttempts", 5)
+	v.SetDefault("notification.retryInterval", 60) // 1 minute
+	v.SetDefault("notification.smtp.host", "localhost")
+	v.SetDefault("notification.smtp.port", 1025)
+	v.SetDefault("notification.smtp.username", "")
+	v.SetDefault("notification.smtp.password", "")
+	v.SetDefault("notification.sendGrid.apiKey", "")
+	v.SetDefault("notification.twilio.accountSID", "")
+	v.SetDefault("notification.twilio.authToken", "")
+	v.SetDefault("notification.twilio.from", "")
+	v.SetDefault("notification.aliyun.accessKeyID", "")
+	v.SetDefault("notification.aliyun.accessKeySecret", "")
+	v.SetDefault("notification.aliyun.signName", "")
+	v.SetDefault("notification.aliyun.templates", map[string]string{})
+	v.SetDefault("notification.fcm.projectID", "")
+	v.SetDefault("notification.fcm.credentialsFile", "")
+
+	// Webhook configuration, retries span about a day before a delivery is dead-lettered
+	v.SetDefault("webhook.subjects", []string{"order.>", "payment.>", "shipment.>", "product.>", "inventory.>", "seller.>"})
+	v.SetDefault("webhook.timeout", 10)
+	v.SetDefault("webhook.maxAttempts", 10)
+	v.SetDefault("webhook.retryInterval", 60)       // 1 minute
+	v.SetDefault("webhook.maxRetryInterval", 21600) // 6 hours
+	v.SetDefault("webhook.allowHTTP", false)
+
+	// Support configuration, SLA targets in minutes per ticket priority
+	v.SetDefault("support.firstResponseMinutes", map[string]int{"low": 2880, "normal": 1440, "high": 240, "urgent": 60})
+	v.SetDefault("support.resolutionMinutes", map[string]int{"low": 10080, "normal": 4320, "high": 1440, "urgent": 480})
+	v.SetDefault("support.inboundToken", "")
+	v.SetDefault("support.replyAddress", "support@goshop.local")
+
+	// Audit configuration, records are kept 7 years unless a retention policy applies
+	v.SetDefault("audit.retentionDays", 2555)
+	v.SetDefault("audit.minRetentionDays", 90)
+	v.SetDefault("audit.purgeInterval", 360) // 6 hours
+	v.SetDefault("audit.streamMaxAge", 168)  // 7 days
+
+	// Scheduler configuration
+	v.SetDefault("scheduler.callbackToken", "")
+	v.SetDefault("scheduler.pollInterval", 5)
+	v.SetDefault("scheduler.maxConcurrency", 10)
+	v.SetDefault("scheduler.historyDays", 30)
+
+	// Seller configuration, payouts are created every Monday at 02:00
+	v.SetDefault("seller.defaultCommissionRate", 10)
+	v.SetDefault("seller.holdDays", 7)
+	v.SetDefault("seller.minPayout", 100)
+	v.SetDefault("seller.settleSchedule", "@hourly")
+	v.SetDefault("seller.payoutSchedule", "0 2 * * 1")
+
+	// GraphQL federation configuration
+	v.SetDefault("graphql.subgraphs", []string{"product", "user", "order", "cms", "marketing"})
+	v.SetDefault("graphql.path", "/api/v1/graphql")
+	v.SetDefault("graphql.refreshInterval", 60)
+	v.SetDefault("graphql.timeout", 10)
+
+	// Currency configuration, the static provider serves fixed rates until a
+	// rate provider is configured
+	v.SetDefault("currency.base", "CNY")
+	v.SetDefault("currency.currencies", []string{"CNY", "USD", "EUR", "HKD", "JPY"})
+	v.SetDefault("currency.provider", "static")
+	v.SetDefault("currency.providerURL", "https://openexchangerates.org/api/latest.json")
+	v.SetDefault("currency.apiKey", "")
+	v.SetDefault("currency.staticRates", map[string]float64{"USD": 0.14, "EUR": 0.13, "HKD": 1.09, "JPY": 20.8})
+	v.SetDefault("currency.refreshSchedule", "0 1 * * *")
+	v.SetDefault("currency.maxAge", 48)
+
+	// Fraud configuration
+	v.SetDefault("fraud.reviewScore", 50)
+	v.SetDefault("fraud.blockScore", 80)
+	v.SetDefault("fraud.userAttemptsPerHour", 5)
+	v.SetDefault("fraud.ipAttemptsPerHour", 20)
+	v.SetDefault("fraud.deviceAttemptsPerHour", 10)
+	v.SetDefault("fraud.deviceUsersPerDay", 3)
+	v.SetDefault("fraud.highAmount", 5000)
+	v.SetDefault("fraud.newAccountHours", 24)
+	v.SetDefault("fraud.disposableDomains", []string{"mailinator.com", "guerrillamail.com", "10minutemail.com", "temp-mail.org", "yopmail.com", "trashmail.com", "sharklasers.com", "getnada.com"})
+	v.SetDefault("fraud.reputationURL", "")
+	v.SetDefault("fraud.reputationAPIKey", "")
+	v.SetDefault("fraud.reputationTimeout", 300)
+
+	// Service discovery configuration
+	v.SetDefault("discovery.provider", "static")
+	v.SetDefault("discovery.consulAddress", "http://localhost:8500")
+	v.SetDefault("discovery.consulToken", "")
+	v.SetDefault("discovery.datacenter", "")
+	v.SetDefault("discovery.refreshInterval", 10)
+	v.SetDefault("discovery.healthPath", "/health/ready")
+	v.SetDefault("discovery.healthCheckInterval", 5)
+	v.SetDefault("discovery.unhealthyThreshold", 3)
+
+	// Gateway circuit breaker configuration
+	v.SetDefault("gateway.circuitBreaker.failureThreshold", 5)
+	v.SetDefault("gateway.circuitBreaker.openTimeout", 30)
+	v.SetDefault("gateway.circuitBreaker.halfOpenRequests", 1)
+
+	// Gateway request limits and retries
+	v.SetDefault("gateway.maxBodySize", 10<<20)
+	v.SetDefault("gateway.upstreamTimeout", 30)
+	v.SetDefault("gateway.retry.maxRetries", 2)
+	v.SetDefault("gateway.retry.budget", 0.1)
+	v.SetDefault("gateway.retry.minRetriesPerSecond", 10)
+
+	// Gateway CORS policy, every origin without credentials by default
+	v.SetDefault("gateway.cors.allowedOrigins", []string{"*"})
+	v.SetDefault("gateway.cors.allowedMethods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	v.SetDefault("gateway.cors.allowedHeaders", []string{"Content-Type", "Authorization", "X-Requested-With", "X-Request-ID"})
+	v.SetDefault("gateway.cors.exposedHeaders", []string{"X-Request-ID", "Retry-After"})
+	v.SetDefault("gateway.cors.allowCredentials", false)
+	v.SetDefault("gateway.cors.maxAge", 600)
+
+	// Gateway client IP handling, no IP rules by default
+	v.SetDefault("gateway.trustedProxies", []string{})
+	v.SetDefault("gateway.ipFilter.countryHeader", "")
+	v.SetDefault("gateway.ipFilter.rules", []map[string]interface{}{})
+
+	// Gateway role and permission checks. Every admin route the gateway forwards
+	// requires a back office role, audit records only the admin role, so that a
+	// route missing its own check fails closed
+	v.SetDefault("gateway.rbac.rules", []map[string]interface{}{
+		{"pathPrefix": "/api/v1/admin/", "roles": []string{"admin", "staff"}},
+		{"pathPrefix": "/api/v1/admin/audit/", "roles": []string{"admin"}},
+		{"pathPrefix": "/api/v1/audit/admin/", "roles": []string{"admin"}},
+		{"pathPrefix": "/api/v1/cms/admin/", "roles": []string{"admin", "staff"}},
+		{"pathPrefix": "/api/v1/currency/admin/", "roles": []string{"admin", "staff"}},
+		{"pathPrefix": "/api/v1/fraud/admin/", "roles": []string{"admin", "staff"}},
+		{"pathPrefix": "/api/v1/reviews/admin/", "roles": []string{"admin", "staff"}},
+		{"pathPrefix": "/api/v1/scheduler/admin/", "roles": []string{"admin", "staff"}},
+		{"pathPrefix": "/api/v1/sellers/admin/", "roles": []string{"admin", "staff"}},
+		{"pathPrefix": "/api/v1/support/admin/", "roles": []string{"admin", "staff"}},
+	})
+
+	// Gateway admin route group, limited to the back office roles
+	v.SetDefault("gateway.admin.roles", []string{"admin", "staff"})
+	v.SetDefault("gateway.admin.rateLimit", 60)
+	v.SetDefault("gateway.admin.services", map[string]string{
+		"audit":     "audit",
+		"cms":       "cms",
+		"currency":  "currency",
+		"fraud":     "fraud",
+		"marketing": "marketing",
+		"reviews":   "review",
+		"scheduler": "scheduler",
+		"search":    "search",
+		"sellers":   "seller",
+		"shipping":  "shipping",
+		"support":   "support",
+		"users":     "user",
+	})
+
+	// Gateway request audit of payments and admin actions
+	v.SetDefault("gateway.requestAudit.rules", []map[string]interface{}{
+		{"pathPrefix": "/api/v1/payments", "captureBody": true},
+		{"pathPrefix": "/api/v1/admin/", "captureBody": true},
+		{"pathPrefix": "/api/v1/cms/admin/", "methods": []string{"POST", "PUT", "PATCH", "DELETE"}, "captureBody": true},
+	})
+	v.SetDefault("gateway.requestAudit.redactFields", []string{"password", "token", "access_token", "refresh_token", "secret", "api_key", "card_number", "cvv", "cvc", "account_number"})
+	v.SetDefault("gateway.requestAudit.maxBodySize", 64<<10)
+
+	// Gateway response compression
+	v.SetDefault("gateway.compression.enabled", true)
+	v.SetDefault("gateway.compression.minSize", 1024)
+	v.SetDefault("gateway.compression.level", -1)
+	v.SetDefault("gateway.compression.contentTypes", []string{"application/json", "application/problem+json", "application/graphql-response+json", "text/plain", "text/html", "text/css", "application/javascript"})
+	v.SetDefault("gateway.compression.excludedPaths", []string{"/metrics", "/ws/"})
+
+	// Gateway partner API key configuration
+	v.SetDefault("gateway.apiKeys.signatureWindow", 300)
+
+	// Gateway route table, empty unless routes are configured
+	v.SetDefault("gateway.routes", []map[string]interface{}{})
+
+	// Gateway response cache configuration
+	v.SetDefault("gateway.responseCache.enabled", false)
+	v.SetDefault("gateway.responseCache.maxEntrySize", 1<<20)
+	v.SetDefault("gateway.responseCache.rules", map[string]interface{}{
+		"products": map[string]interface{}{
+			"ttl":          60,
+			"invalidateOn": []string{"product.created", "product.updated", "product.deleted"},
+		},
+		"banners": map[string]interface{}{
+			"ttl":          300,
+			"invalidateOn": []string{"cms.banners_changed"},
+		},
+	})
+
+	// Service endpoints configuration
+	v.SetDefault("endpoints", getDefaultEndpoints())
+
+	// Feature flags configuration, features are off unless configured. Flags
+	// guarding established behaviour are on so that they act as kill switches.
+	v.SetDefault("featureFlags", map[string]interface{}{
+		"gift-stock-check": map[string]interface{}{"enabled": true, "rollout": 100},
+	})
 }
 
 // Assign unique default port for each service
 func getDefaultHTTPPort(serviceName string) int {
 	ports := map[string]int{
-		"user":      8001,
-		"product":   8002,
-		"inventory": 8003,
-		"order":     8004,
-		"payment":   8005,
-		"marketing": 8006,
-		"cms":       8007,
-		"shipping":  8008,
-		"gateway":   8000,
-		"auth":      8009,
-		"admin":     8010,
+		"user":         8001,
+		"product":      8002,
+		"inventory":    8003,
+		"order":        8004,
+		"payment":      8005,
+		"marketing":    8006,
+		"cms":          8007,
+		"shipping":     8008,
+		"gateway":      8000,
+		"auth":         8009,
+		"admin":        8010,
+		"notification": 8011,
+		"search":       8012,
+		"analytics":    8013,
+		"webhook":      8014,
+		"review":       8015,
+		"support":      8016,
+		"audit":        8017,
+		"scheduler":    8018,
+		"seller":       8019,
+		"currency":     8020,
+		"fraud":        8021,
 	}
 
 	if port, ok := ports[serviceName]; ok {
@@ -195,20 +1061,49 @@ func getDefaultHTTPPort(serviceName string) int {
 	return 8080
 }
 
+// Default HTTP base URL of every service, assuming they run on the same host
+func getDefaultEndpoints() map[string]string {
+	endpoints := make(map[string]string)
+	for _, name := range []string{"user", "product", "inventory", "order", "payment", "marketing", "cms", "shipping", "auth", "admin", "notification", "search", "analytics", "webhook", "review", "support", "audit", "scheduler", "seller", "currency", "fraud"} {
+		endpoints[name] = fmt.Sprintf("http://localhost:%d", getDefaultHTTPPort(name))
+	}
+	return endpoints
+}
+
+// Default gRPC target of every service, assuming they run on the same host
+func getDefaultGRPCTargets() map[string]string {
+	targets := make(map[string]string)
+	for _, name := range []string{"user", "product", "inventory", "order", "payment", "marketing", "cms", "shipping", "auth", "admin", "notification", "search", "analytics", "webhook", "review", "support", "audit", "scheduler", "seller", "currency", "fraud"} {
+		targets[name] = fmt.Sprintf("dns:///localhost:%d", getDefaultGRPCPort(name))
+	}
+	return targets
+}
+
 // Assign unique default gRPC port for each service
 func getDefaultGRPCPort(serviceName string) int {
 	ports := map[string]int{
-		"user":      9001,
-		"product":   9002,
-		"inventory": 9003,
-		"order":     9004,
-		"payment":   9005,
-		"marketing": 9006,
-		"cms":       9007,
-		"shipping":  9008,
-		"gateway":   9000,
-		"auth":      9009,
-		"admin":     9010,
+		"user":         9001,
+		"product":      9002,
+		"inventory":    9003,
+		"order":        9004,
+		"payment":      9005,
+		"marketing":    9006,
+		"cms":          9007,
+		"shipping":     9008,
+		"gateway":      9000,
+		"auth":         9009,
+		"admin":        9010,
+		"notification": 9011,
+		"search":       9012,
+		"analytics":    9013,
+		"webhook":      9014,
+		"review":       9015,
+		"support":      9016,
+		"audit":        9017,
+		"scheduler":    9018,
+		"seller":       9019,
+		"currency":     9020,
+		"fraud":        9021,
 	}
 
 	if port, ok := ports[serviceName]; ok {
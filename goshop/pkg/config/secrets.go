@@ -0,0 +1,264 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// Secret references replace plaintext values of string settings and are resolved
+// when the configuration is loaded:
+//
+//	vault://<mount>/<path>#<key>   key of a Vault KV v2 secret, e.g. vault://secret/goshop/database#password
+//	awssm://<secret-id>[#<key>]    AWS Secrets Manager secret, or one key of a JSON secret
+const (
+	vaultScheme = "vault"
+	awsSMScheme = "awssm"
+)
+
+// secretsTimeout bounds the resolution of all the secrets of a configuration
+const secretsTimeout = 10 * time.Second
+
+// IsSecretRef reports whether value is a secret reference rather than a plain value
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, vaultScheme+"://") || strings.HasPrefix(value, awsSMScheme+"://")
+}
+
+// SecretResolver resolves secret references, caching the values for the cache
+// TTL so that reloads pick up rotated secrets without hitting the backend on
+// every load. It is safe for concurrent use.
+type SecretResolver struct {
+	cfg        SecretsConfig
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+	awsSM *secretsmanager.Client
+}
+
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// NewSecretResolver creates a resolver for the configured backends
+func NewSecretResolver(cfg SecretsConfig) *SecretResolver {
+	return &SecretResolver{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		cache:      make(map[string]cachedSecret),
+	}
+}
+
+// Resolve returns the value of a secret reference, or value itself when it is not
+// a reference. When the backend fails and the secret was fetched before, the
+// previous value is returned so that an outage does not break reloads.
+func (r *SecretResolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsSecretRef(value) {
+		return value, nil
+	}
+
+	r.mu.Lock()
+	cached, ok := r.cache[value]
+	r.mu.Unlock()
+	ttl := time.Duration(r.cfg.CacheTTL) * time.Second
+	if ok && time.Since(cached.fetchedAt) < ttl {
+		return cached.value, nil
+	}
+
+	secret, err := r.fetch(ctx, value)
+	if err != nil {
+		if ok {
+			return cached.value, nil
+		}
+		return "", err
+	}
+
+	r.mu.Lock()
+	r.cache[value] = cachedSecret{value: secret, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return secret, nil
+}
+
+func (r *SecretResolver) fetch(ctx context.Context, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret reference: %w", err)
+	}
+	switch u.Scheme {
+	case vaultScheme:
+		return r.fetchVault(ctx, u)
+	case awsSMScheme:
+		return r.fetchAWS(ctx, u)
+	default:
+		return "", fmt.Errorf("unsupported secret scheme %q", u.Scheme)
+	}
+}
+
+// fetchVault reads a key of a KV version 2 secret
+func (r *SecretResolver) fetchVault(ctx context.Context, u *url.URL) (string, error) {
+	mount, path := u.Host, strings.TrimPrefix(u.Path, "/")
+	if mount == "" || path == "" || u.Fragment == "" {
+		return "", fmt.Errorf("invalid Vault secret reference %s, expected vault://<mount>/<path>#<key>", redactRef(u))
+	}
+	if r.cfg.VaultAddr == "" {
+		return "", errors.New("secrets.vaultAddr is not configured")
+	}
+
+	endpoint := strings.TrimRight(r.cfg.VaultAddr, "/") + "/v1/" + mount + "/data/" + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", r.cfg.VaultToken)
+	if r.cfg.VaultNamespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.cfg.VaultNamespace)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("read Vault secret %s: %w", redactRef(u), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("read Vault secret %s: status %d", redactRef(u), resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode Vault secret %s: %w", redactRef(u), err)
+	}
+	value, ok := body.Data.Data[u.Fragment]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no key %q", redactRef(u), u.Fragment)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// fetchAWS reads a Secrets Manager secret, or one key of a secret holding a JSON
+// object
+func (r *SecretResolver) fetchAWS(ctx context.Context, u *url.URL) (string, error) {
+	id := u.Host + u.Path
+	if id == "" {
+		return "", errors.New("invalid AWS secret reference, expected awssm://<secret-id>[#<key>]")
+	}
+	client, err := r.awsClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		return "", fmt.Errorf("read AWS secret %s: %w", id, err)
+	}
+	secret := aws.ToString(out.SecretString)
+	if u.Fragment == "" {
+		return secret, nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return "", fmt.Errorf("AWS secret %s is not a JSON object: %w", id, err)
+	}
+	value, ok := values[u.Fragment]
+	if !ok {
+		return "", fmt.Errorf("AWS secret %s has no key %q", id, u.Fragment)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// awsClient creates the Secrets Manager client on first use, with credentials
+// from the default chain: environment, shared files or the instance role
+func (r *SecretResolver) awsClient(ctx context.Context) (*secretsmanager.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.awsSM != nil {
+		return r.awsSM, nil
+	}
+	var opts []func(*awsconfig.LoadOptions) error
+	if r.cfg.AWSRegion != "" {
+		opts = append(opts, awsconfig.WithRegion(r.cfg.AWSRegion))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load AWS configuration: %w", err)
+	}
+	r.awsSM = secretsmanager.NewFromConfig(awsCfg)
+	return r.awsSM, nil
+}
+
+// ResolveConfig replaces every secret reference found in the string settings of
+// cfg with the secret value
+func (r *SecretResolver) ResolveConfig(ctx context.Context, cfg *Config) error {
+	ctx, cancel := context.WithTimeout(ctx, secretsTimeout)
+	defer cancel()
+	return r.resolveValue(ctx, reflect.ValueOf(cfg).Elem(), "")
+}
+
+func (r *SecretResolver) resolveValue(ctx context.Context, v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			// The secrets settings configure the resolver itself
+			if !field.IsExported() || (path == "" && field.Name == "Secrets") {
+				continue
+			}
+			if err := r.resolveValue(ctx, v.Field(i), joinPath(path, field.Name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := r.resolveValue(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for _, key := range v.MapKeys() {
+			value, err := r.Resolve(ctx, v.MapIndex(key).String())
+			if err != nil {
+				return fmt.Errorf("resolve %s[%v]: %w", path, key, err)
+			}
+			v.SetMapIndex(key, reflect.ValueOf(value))
+		}
+	case reflect.String:
+		value, err := r.Resolve(ctx, v.String())
+		if err != nil {
+			return fmt.Errorf("resolve %s: %w", path, err)
+		}
+		v.SetString(value)
+	}
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// redactRef drops the key of a reference from error messages, keeping the path
+func redactRef(u *url.URL) string {
+	return u.Scheme + "://" + u.Host + u.Path
+}
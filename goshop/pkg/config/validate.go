@@ -0,0 +1,708 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/goshop/pkg/cron"
+)
+
+// Environments a service may run in
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// minProductionSecretLen is the minimum length of the JWT secret in production,
+// 32 bytes being the HMAC-SHA256 key size
+const minProductionSecretLen = 32
+
+var (
+	validEnvironments  = []string{EnvDevelopment, EnvTest, EnvStaging, EnvProduction}
+	validLogLevels     = []string{"debug", "info", "warn", "error", "dpanic", "panic", "fatal"}
+	validSSLModes      = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	validLogOutputs    = []string{"stdout", "file", "syslog"}
+	validLogEncodings  = []string{"", "json", "console"}
+	validEmailSenders  = []string{"", "smtp", "sendgrid"}
+	validSMSSenders    = []string{"", "twilio", "aliyun"}
+	validPushSenders   = []string{"", "fcm"}
+	validSearchEngines = []string{"meilisearch"}
+	supportPriorities  = []string{"low", "normal", "high", "urgent"}
+	validRouteMethods  = []string{"ANY", "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+)
+
+// ValidationError lists every problem found in a configuration, so that all of
+// them can be fixed at once
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// problems collects validation failures
+type problems []string
+
+func (p *problems) addf(format string, args ...interface{}) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+// Validate checks the configuration after it is loaded: required settings,
+// value ranges, consistency between settings and the stricter requirements of
+// production. It returns a *ValidationError listing all the problems found.
+func (c *Config) Validate() error {
+	var p problems
+	prod := c.Service.Environment == EnvProduction
+
+	if c.Service.Name == "" {
+		p.addf("service.name is required")
+	}
+	if !contains(validEnvironments, c.Service.Environment) {
+		p.addf("service.environment %q must be one of %s", c.Service.Environment, strings.Join(validEnvironments, ", "))
+	}
+	if !contains(validLogLevels, strings.ToLower(c.Service.LogLevel)) {
+		p.addf("service.logLevel %q must be one of %s", c.Service.LogLevel, strings.Join(validLogLevels, ", "))
+	}
+
+	c.Log.validate(&p)
+
+	checkPort(&p, "http.port", c.HTTP.Port)
+	checkPort(&p, "grpc.port", c.GRPC.Port)
+	if c.HTTP.Port == c.GRPC.Port {
+		p.addf("http.port and grpc.port must differ, both are %d", c.HTTP.Port)
+	}
+	if c.HTTP.Timeout <= 0 {
+		p.addf("http.timeout must be positive, got %d", c.HTTP.Timeout)
+	}
+	if c.GRPC.CallTimeout < 0 || c.GRPC.MaxRetries < 0 || c.GRPC.HedgingDelay < 0 || c.GRPC.KeepaliveTime < 0 {
+		p.addf("grpc.callTimeout, grpc.maxRetries, grpc.hedgingDelay and grpc.keepaliveTime must not be negative")
+	}
+	if c.GRPC.HedgingDelay > 0 && c.GRPC.CallTimeout > 0 && c.GRPC.HedgingDelay >= c.GRPC.CallTimeout {
+		p.addf("grpc.hedgingDelay (%dms) must be shorter than grpc.callTimeout (%dms)", c.GRPC.HedgingDelay, c.GRPC.CallTimeout)
+	}
+
+	c.Database.validate(&p, prod)
+
+	if c.Redis.Host == "" {
+		p.addf("redis.host is required")
+	}
+	checkPort(&p, "redis.port", c.Redis.Port)
+	if c.Redis.DB < 0 || c.Redis.DB > 15 {
+		p.addf("redis.db must be between 0 and 15, got %d", c.Redis.DB)
+	}
+
+	checkURL(&p, "nats.url", c.NATS.URL, "nats", "tls")
+	if c.NATS.StreamMaxAge <= 0 {
+		p.addf("nats.streamMaxAge must be positive, got %d", c.NATS.StreamMaxAge)
+	}
+	c.Storage.validate(&p)
+
+	if c.Auth.JWTSecret == "" {
+		p.addf("auth.jwtSecret is required")
+	} else if prod && c.Auth.JWTSecret == defaultJWTSecret {
+		p.addf("auth.jwtSecret must be changed from the development default in production, e.g. to a vault:// or awssm:// secret reference")
+	} else if prod && len(c.Auth.JWTSecret) < minProductionSecretLen {
+		p.addf("auth.jwtSecret must be at least %d characters in production", minProductionSecretLen)
+	}
+	if c.Auth.TokenDuration <= 0 {
+		p.addf("auth.tokenDuration must be positive, got %d", c.Auth.TokenDuration)
+	}
+	if c.Auth.RefreshTokenDuration <= 0 {
+		p.addf("auth.refreshTokenDuration must be positive, got %d", c.Auth.RefreshTokenDuration)
+	}
+	if c.Auth.PermissionCacheTTL < 0 {
+		p.addf("auth.permissionCacheTTL must not be negative, got %d", c.Auth.PermissionCacheTTL)
+	}
+	c.User.validate(&p, prod)
+
+	if c.Trace.Enabled {
+		checkURL(&p, "trace.url", c.Trace.URL, "http", "https")
+	}
+	if c.Trace.SampleRatio < 0 || c.Trace.SampleRatio > 1 {
+		p.addf("trace.sampleRatio must be between 0 and 1, got %g", c.Trace.SampleRatio)
+	}
+
+	if c.I18n.DefaultLocale == "" {
+		p.addf("i18n.defaultLocale is required")
+	} else if len(c.I18n.Locales) > 0 && !contains(c.I18n.Locales, c.I18n.DefaultLocale) {
+		p.addf("i18n.locales %v must include i18n.defaultLocale %q", c.I18n.Locales, c.I18n.DefaultLocale)
+	}
+
+	if c.Secrets.VaultToken != "" && c.Secrets.VaultAddr == "" {
+		p.addf("secrets.vaultAddr is required when secrets.vaultToken is set")
+	}
+	if c.Secrets.CacheTTL < 0 {
+		p.addf("secrets.cacheTTL must not be negative, got %d", c.Secrets.CacheTTL)
+	}
+
+	for name, endpoint := range c.Endpoints {
+		checkURL(&p, "endpoints."+name, endpoint, "http", "https")
+	}
+
+	c.Notification.validate(&p)
+	c.Search.validate(&p)
+	c.Webhook.validate(&p, prod)
+	c.Support.validate(&p, prod)
+	c.Audit.validate(&p)
+	c.Scheduler.validate(&p, prod)
+	c.Seller.validate(&p)
+	c.GraphQL.validate(&p, c.Endpoints)
+	c.Currency.validate(&p)
+	c.Fraud.validate(&p)
+	c.Discovery.validate(&p)
+	c.Gateway.validate(&p)
+
+	for key, flag := range c.FeatureFlags {
+		if flag.Rollout < 0 || flag.Rollout > 100 {
+			p.addf("featureFlags.%s.rollout must be between 0 and 100, got %d", key, flag.Rollout)
+		}
+	}
+
+	if len(p) > 0 {
+		return &ValidationError{Problems: p}
+	}
+	return nil
+}
+
+func (c *DatabaseConfig) validate(p *problems, prod bool) {
+	if c.Host == "" {
+		p.addf("database.host is required")
+	}
+	checkPort(p, "database.port", c.Port)
+	if c.User == "" {
+		p.addf("database.user is required")
+	}
+	if c.DBName == "" {
+		p.addf("database.dbname is required")
+	}
+	if !contains(validSSLModes, c.SSLMode) {
+		p.addf("database.sslmode %q must be one of %s", c.SSLMode, strings.Join(validSSLModes, ", "))
+	} else if prod && c.SSLMode == "disable" {
+		p.addf("database.sslmode must not be disable in production")
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 {
+		p.addf("database.maxOpenConns and database.maxIdleConns must not be negative")
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		p.addf("database.maxIdleConns (%d) must not exceed database.maxOpenConns (%d)", c.MaxIdleConns, c.MaxOpenConns)
+	}
+	if _, err := c.ReplicaDSNs(); err != nil {
+		p.addf("database.replicas: %v", err)
+	}
+}
+
+func (c *LoggerConfig) validate(p *problems) {
+	for _, output := range c.Outputs {
+		if !contains(validLogOutputs, output) {
+			p.addf("log.outputs contains %q, outputs must be among %s", output, strings.Join(validLogOutputs, ", "))
+		}
+	}
+	if contains(c.Outputs, "file") && c.File == "" {
+		p.addf("log.file is required when log.outputs includes file")
+	}
+	if !contains(validLogEncodings, c.Encoding) {
+		p.addf("log.encoding %q must be json or console", c.Encoding)
+	}
+	if c.MaxSize < 0 || c.MaxBackups < 0 || c.MaxAge < 0 {
+		p.addf("log.maxSize, log.maxBackups and log.maxAge must not be negative")
+	}
+	if c.SampleInitial < 0 || c.SampleThereafter < 0 {
+		p.addf("log.sampleInitial and log.sampleThereafter must not be negative")
+	}
+}
+
+func (c *NotificationConfig) validate(p *problems) {
+	if !contains(validEmailSenders, c.EmailProvider) {
+		p.addf("notification.emailProvider %q must be one of %s", c.EmailProvider, strings.Join(validEmailSenders[1:], ", "))
+	}
+	if !contains(validSMSSenders, c.SMSProvider) {
+		p.addf("notification.smsProvider %q must be one of %s", c.SMSProvider, strings.Join(validSMSSenders[1:], ", "))
+	}
+	if !contains(validPushSenders, c.PushProvider) {
+		p.addf("notification.pushProvider %q must be one of %s", c.PushProvider, strings.Join(validPushSenders[1:], ", "))
+	}
+	if c.EmailProvider != "" && c.FromEmail == "" {
+		p.addf("notification.fromEmail is required when email is enabled")
+	}
+	if c.MaxAttempts <= 0 || c.RetryInterval <= 0 {
+		p.addf("notification.maxAttempts and notification.retryInterval must be positive")
+	}
+
+	switch c.EmailProvider {
+	case "smtp":
+		if c.SMTP.Host == "" {
+			p.addf("notification.smtp.host is required when notification.emailProvider is smtp")
+		}
+		checkPort(p, "notification.smtp.port", c.SMTP.Port)
+	case "sendgrid":
+		if c.SendGrid.APIKey == "" {
+			p.addf("notification.sendGrid.apiKey is required when notification.emailProvider is sendgrid")
+		}
+	}
+	switch c.SMSProvider {
+	case "twilio":
+		if c.Twilio.AccountSID == "" || c.Twilio.AuthToken == "" || c.Twilio.From == "" {
+			p.addf("notification.twilio.accountSID, authToken and from are required when notification.smsProvider is twilio")
+		}
+	case "aliyun":
+		if c.Aliyun.AccessKeyID == "" || c.Aliyun.AccessKeySecret == "" || c.Aliyun.SignName == "" {
+			p.addf("notification.aliyun.accessKeyID, accessKeySecret and signName are required when notification.smsProvider is aliyun")
+		}
+	}
+	if c.PushProvider == "fcm" && c.FCM.ProjectID == "" {
+		p.addf("notification.fcm.projectID is required when notification.pushProvider is fcm")
+	}
+}
+
+func (c *SearchConfig) validate(p *problems) {
+	if !contains(validSearchEngines, c.Engine) {
+		p.addf("search.engine %q must be one of %s", c.Engine, strings.Join(validSearchEngines, ", "))
+	}
+	checkURL(p, "search.url", c.URL, "http", "https")
+	if c.Typo.Enabled && (c.Typo.OneTypoMinLength < 1 || c.Typo.TwoTyposMinLength < c.Typo.OneTypoMinLength) {
+		p.addf("search.typo.oneTypoMinLength must be positive and not exceed search.typo.twoTyposMinLength")
+	}
+}
+
+func (c *WebhookConfig) validate(p *problems, prod bool) {
+	if c.Timeout <= 0 {
+		p.addf("webhook.timeout must be positive, got %d", c.Timeout)
+	}
+	if c.MaxAttempts <= 0 || c.RetryInterval <= 0 {
+		p.addf("webhook.maxAttempts and webhook.retryInterval must be positive")
+	}
+	if c.MaxRetryInterval < c.RetryInterval {
+		p.addf("webhook.maxRetryInterval (%d) must not be less than webhook.retryInterval (%d)", c.MaxRetryInterval, c.RetryInterval)
+	}
+	if prod && c.AllowHTTP {
+		p.addf("webhook.allowHTTP must not be enabled in production")
+	}
+}
+
+func (c *SupportConfig) validate(p *problems, prod bool) {
+	for _, priority := range supportPriorities {
+		if c.FirstResponseMinutes[priority] <= 0 || c.ResolutionMinutes[priority] <= 0 {
+			p.addf("support.firstResponseMinutes and support.resolutionMinutes must be positive for priority %s", priority)
+		}
+	}
+	if c.ReplyAddress == "" {
+		p.addf("support.replyAddress is required")
+	}
+	if prod && c.InboundToken == "" {
+		p.addf("support.inboundToken is required in production")
+	}
+}
+
+func (c *AuditConfig) validate(p *problems) {
+	if c.MinRetentionDays <= 0 || c.RetentionDays < c.MinRetentionDays {
+		p.addf("audit.minRetentionDays must be positive and not exceed audit.retentionDays (%d)", c.RetentionDays)
+	}
+	if c.PurgeInterval <= 0 || c.StreamMaxAge <= 0 {
+		p.addf("audit.purgeInterval and audit.streamMaxAge must be positive")
+	}
+}
+
+func (c *SchedulerConfig) validate(p *problems, prod bool) {
+	if c.PollInterval <= 0 || c.MaxConcurrency <= 0 || c.HistoryDays <= 0 {
+		p.addf("scheduler.pollInterval, scheduler.maxConcurrency and scheduler.historyDays must be positive")
+	}
+	if prod && c.CallbackToken == "" {
+		p.addf("scheduler.callbackToken is required in production")
+	}
+}
+
+func (c *SellerConfig) validate(p *problems) {
+	if c.DefaultCommissionRate < 0 || c.DefaultCommissionRate > 100 {
+		p.addf("seller.defaultCommissionRate must be between 0 and 100, got %v", c.DefaultCommissionRate)
+	}
+	if c.HoldDays < 0 || c.MinPayout < 0 {
+		p.addf("seller.holdDays and seller.minPayout must not be negative")
+	}
+	if _, err := cron.Parse(c.SettleSchedule); err != nil {
+		p.addf("seller.settleSchedule is invalid: %v", err)
+	}
+	if _, err := cron.Parse(c.PayoutSchedule); err != nil {
+		p.addf("seller.payoutSchedule is invalid: %v", err)
+	}
+}
+
+func (c *GraphQLConfig) validate(p *problems, endpoints map[string]string) {
+	for _, name := range c.Subgraphs {
+		if _, ok := endpoints[name]; !ok {
+			p.addf("graphql.subgraphs: no endpoint configured for %q", name)
+		}
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		p.addf("graphql.path must start with /, got %q", c.Path)
+	}
+	if c.RefreshInterval <= 0 || c.Timeout <= 0 {
+		p.addf("graphql.refreshInterval and graphql.timeout must be positive")
+	}
+}
+
+func (c *CurrencyConfig) validate(p *problems) {
+	if !contains(c.Currencies, c.Base) {
+		p.addf("currency.currencies must include the base currency %s", c.Base)
+	}
+	switch c.Provider {
+	case "openexchangerates":
+		checkURL(p, "currency.providerURL", c.ProviderURL, "https")
+		if c.APIKey == "" {
+			p.addf("currency.apiKey is required by the openexchangerates provider")
+		}
+	case "static":
+		for _, code := range c.Currencies {
+			if code == c.Base {
+				continue
+			}
+			// viper lowercases map keys
+			if c.StaticRates[code] <= 0 && c.StaticRates[strings.ToLower(code)] <= 0 {
+				p.addf("currency.staticRates must have a positive rate for %s", code)
+			}
+		}
+	default:
+		p.addf("currency.provider must be openexchangerates or static, got %q", c.Provider)
+	}
+	if _, err := cron.Parse(c.RefreshSchedule); err != nil {
+		p.addf("currency.refreshSchedule is invalid: %v", err)
+	}
+	if c.MaxAge <= 0 {
+		p.addf("currency.maxAge must be positive")
+	}
+}
+
+func (c *FraudConfig) validate(p *problems) {
+	if c.ReviewScore <= 0 || c.BlockScore > 100 || c.ReviewScore >= c.BlockScore {
+		p.addf("fraud scores must satisfy 0 < reviewScore < blockScore <= 100, got %d and %d", c.ReviewScore, c.BlockScore)
+	}
+	if c.UserAttemptsPerHour <= 0 || c.IPAttemptsPerHour <= 0 || c.DeviceAttemptsPerHour <= 0 || c.DeviceUsersPerDay <= 0 {
+		p.addf("fraud.userAttemptsPerHour, fraud.ipAttemptsPerHour, fraud.deviceAttemptsPerHour and fraud.deviceUsersPerDay must be positive")
+	}
+	if c.HighAmount <= 0 || c.NewAccountHours <= 0 {
+		p.addf("fraud.highAmount and fraud.newAccountHours must be positive")
+	}
+	if c.ReputationURL != "" {
+		checkURL(p, "fraud.reputationURL", c.ReputationURL, "http", "https")
+		if c.ReputationTimeout <= 0 {
+			p.addf("fraud.reputationTimeout must be positive")
+		}
+	}
+}
+
+func (c *StorageConfig) validate(p *problems) {
+	if c.Endpoint != "" {
+		checkURL(p, "storage.endpoint", c.Endpoint, "http", "https")
+	}
+	if c.Region == "" {
+		p.addf("storage.region is required")
+	}
+	if c.Bucket == "" {
+		p.addf("storage.bucket is required")
+	}
+	if (c.AccessKeyID == "") != (c.SecretAccessKey == "") {
+		p.addf("storage.accessKeyID and storage.secretAccessKey must be set together")
+	}
+	checkURL(p, "storage.publicURL", c.PublicURL, "http", "https")
+}
+
+func (c *UserConfig) validate(p *problems, prod bool) {
+	checkURL(p, "user.verifyEmailURL", c.VerifyEmailURL, "http", "https")
+	if c.EmailVerificationTTL <= 0 {
+		p.addf("user.emailVerificationTTL must be positive, got %d", c.EmailVerificationTTL)
+	}
+	if c.VerificationResendInterval < 0 {
+		p.addf("user.verificationResendInterval must not be negative, got %d", c.VerificationResendInterval)
+	}
+	if c.MaxAddresses <= 0 {
+		p.addf("user.maxAddresses must be positive, got %d", c.MaxAddresses)
+	}
+	if c.AvatarMaxSize <= 0 {
+		p.addf("user.avatarMaxSize must be positive, got %d", c.AvatarMaxSize)
+	}
+	if c.AvatarSize < 32 || c.AvatarSize > 1024 {
+		p.addf("user.avatarSize must be between 32 and 1024, got %d", c.AvatarSize)
+	}
+	if c.EventStreamMaxAge <= 0 {
+		p.addf("user.eventStreamMaxAge must be positive, got %d", c.EventStreamMaxAge)
+	}
+	if c.CacheTTL < 0 {
+		p.addf("user.cacheTTL must not be negative, got %d", c.CacheTTL)
+	}
+	// bcrypt only uses the first 72 bytes of a password
+	if c.PasswordPolicy.MinLength < 8 || c.PasswordPolicy.MinLength > 72 {
+		p.addf("user.passwordPolicy.minLength must be between 8 and 72, got %d", c.PasswordPolicy.MinLength)
+	}
+	if c.PasswordPolicy.History < 0 || c.PasswordPolicy.History > 24 {
+		p.addf("user.passwordPolicy.history must be between 0 and 24, got %d", c.PasswordPolicy.History)
+	}
+	if c.Lockout.MaxFailures <= 0 {
+		p.addf("user.lockout.maxFailures must be positive, got %d", c.Lockout.MaxFailures)
+	}
+	if c.Lockout.MaxIPFailures < c.Lockout.MaxFailures {
+		p.addf("user.lockout.maxIPFailures must not be less than user.lockout.maxFailures, got %d", c.Lockout.MaxIPFailures)
+	}
+	if c.Lockout.Window <= 0 {
+		p.addf("user.lockout.window must be positive, got %d", c.Lockout.Window)
+	}
+	if c.Lockout.LockDuration <= 0 {
+		p.addf("user.lockout.lockDuration must be positive, got %d", c.Lockout.LockDuration)
+	}
+	checkURL(p, "user.lockout.unlockURL", c.Lockout.UnlockURL, "http", "https")
+
+	providers := map[string]OAuthProviderConfig{
+		"google": c.OAuth.Google,
+		"github": c.OAuth.GitHub,
+		"wechat": c.OAuth.WeChat,
+	}
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	enabled := false
+	for _, name := range names {
+		provider := providers[name]
+		if !provider.Enabled() {
+			continue
+		}
+		enabled = true
+		if provider.ClientSecret == "" {
+			p.addf("user.oauth.%s.clientSecret is required", name)
+		}
+		checkURL(p, "user.oauth."+name+".redirectURL", provider.RedirectURL, "http", "https")
+	}
+	if !enabled {
+		return
+	}
+	if c.OAuth.StateSecret == "" {
+		p.addf("user.oauth.stateSecret is required when an OAuth provider is enabled")
+	} else if prod && len(c.OAuth.StateSecret) < minProductionSecretLen {
+		p.addf("user.oauth.stateSecret must be at least %d characters in production", minProductionSecretLen)
+	}
+	if c.OAuth.StateTTL <= 0 {
+		p.addf("user.oauth.stateTTL must be positive, got %d", c.OAuth.StateTTL)
+	}
+}
+
+func (c *DiscoveryConfig) validate(p *problems) {
+	switch c.Provider {
+	case "static":
+	case "consul":
+		checkURL(p, "discovery.consulAddress", c.ConsulAddress, "http", "https")
+	default:
+		p.addf("discovery.provider must be static or consul, got %q", c.Provider)
+	}
+	if !strings.HasPrefix(c.HealthPath, "/") {
+		p.addf("discovery.healthPath must start with /, got %q", c.HealthPath)
+	}
+	if c.RefreshInterval <= 0 || c.HealthCheckInterval <= 0 || c.UnhealthyThreshold <= 0 {
+		p.addf("discovery.refreshInterval, discovery.healthCheckInterval and discovery.unhealthyThreshold must be positive")
+	}
+}
+
+func (c *GatewayConfig) validate(p *problems) {
+	cb := c.CircuitBreaker
+	if cb.FailureThreshold <= 0 || cb.OpenTimeout <= 0 || cb.HalfOpenRequests <= 0 {
+		p.addf("gateway.circuitBreaker.failureThreshold, gateway.circuitBreaker.openTimeout and gateway.circuitBreaker.halfOpenRequests must be positive")
+	}
+	if c.MaxBodySize <= 0 {
+		p.addf("gateway.maxBodySize must be positive, got %d", c.MaxBodySize)
+	}
+	if c.UpstreamTimeout < 0 {
+		p.addf("gateway.upstreamTimeout must not be negative, got %d", c.UpstreamTimeout)
+	}
+	if c.Retry.MaxRetries < 0 || c.Retry.Budget < 0 || c.Retry.MinRetriesPerSecond < 0 {
+		p.addf("gateway.retry.maxRetries, gateway.retry.budget and gateway.retry.minRetriesPerSecond must not be negative")
+	}
+	c.CORS.validate(p)
+	c.IPFilter.validate(p)
+	c.RBAC.validate(p)
+	c.RequestAudit.validate(p)
+	if len(c.Admin.Roles) == 0 {
+		p.addf("gateway.admin.roles must not be empty")
+	}
+	if c.Admin.RateLimit < 0 {
+		p.addf("gateway.admin.rateLimit must not be negative, got %d", c.Admin.RateLimit)
+	}
+	adminNames := make([]string, 0, len(c.Admin.Services))
+	for name := range c.Admin.Services {
+		adminNames = append(adminNames, name)
+	}
+	sort.Strings(adminNames)
+	for _, name := range adminNames {
+		if strings.Contains(name, "/") || c.Admin.Services[name] == "" {
+			p.addf("gateway.admin.services.%s must name a service and not contain /", name)
+		}
+	}
+	if c.Compression.Enabled {
+		if c.Compression.MinSize < 0 {
+			p.addf("gateway.compression.minSize must not be negative, got %d", c.Compression.MinSize)
+		}
+		if c.Compression.Level != -1 && (c.Compression.Level < 1 || c.Compression.Level > 9) {
+			p.addf("gateway.compression.level must be -1 or between 1 and 9, got %d", c.Compression.Level)
+		}
+	}
+	for i, proxy := range c.TrustedProxies {
+		checkIPOrCIDR(p, fmt.Sprintf("gateway.trustedProxies[%d]", i), proxy)
+	}
+	if c.APIKeys.SignatureWindow <= 0 {
+		p.addf("gateway.apiKeys.signatureWindow must be positive, got %d", c.APIKeys.SignatureWindow)
+	}
+	for i, route := range c.Routes {
+		if !contains(validRouteMethods, strings.ToUpper(route.Method)) {
+			p.addf("gateway.routes[%d].method must be one of %s, got %q", i, strings.Join(validRouteMethods, ", "), route.Method)
+		}
+		if !strings.HasPrefix(route.Path, "/") {
+			p.addf("gateway.routes[%d].path must start with /, got %q", i, route.Path)
+		}
+		if route.Upstream != "" && !strings.HasPrefix(route.Upstream, "/") {
+			p.addf("gateway.routes[%d].upstream must start with /, got %q", i, route.Upstream)
+		}
+		if route.Service == "" {
+			p.addf("gateway.routes[%d].service is required", i)
+		}
+		if route.Permission != "" && !route.APIKey && !route.Auth {
+			p.addf("gateway.routes[%d].permission requires auth or apiKey", i)
+		}
+		if len(route.Roles) > 0 && !route.Auth {
+			p.addf("gateway.routes[%d].roles requires auth", i)
+		}
+		if route.RateLimit < 0 || route.Timeout < 0 {
+			p.addf("gateway.routes[%d].rateLimit and gateway.routes[%d].timeout must not be negative", i, i)
+		}
+	}
+	if !c.ResponseCache.Enabled {
+		return
+	}
+	if c.ResponseCache.MaxEntrySize <= 0 {
+		p.addf("gateway.responseCache.maxEntrySize must be positive")
+	}
+	names := make([]string, 0, len(c.ResponseCache.Rules))
+	for name := range c.ResponseCache.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c.ResponseCache.Rules[name].TTL <= 0 {
+			p.addf("gateway.responseCache.rules.%s.ttl must be positive", name)
+		}
+	}
+}
+
+func (c *CORSConfig) validate(p *problems) {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				p.addf("gateway.cors.allowedOrigins must list the allowed origins when gateway.cors.allowCredentials is set, browsers reject * with credentials")
+			}
+			continue
+		}
+		// https://*.example.com is checked as https://example.com
+		bare := strings.Replace(origin, "://*.", "://", 1)
+		if strings.Contains(bare, "*") {
+			p.addf("gateway.cors.allowedOrigins may only use * as a wildcard subdomain, got %q", origin)
+		}
+		checkURL(p, "gateway.cors.allowedOrigins", bare, "http", "https")
+	}
+	if c.MaxAge < 0 {
+		p.addf("gateway.cors.maxAge must not be negative, got %d", c.MaxAge)
+	}
+}
+
+func (c *RBACConfig) validate(p *problems) {
+	for i, rule := range c.Rules {
+		name := fmt.Sprintf("gateway.rbac.rules[%d]", i)
+		if !strings.HasPrefix(rule.PathPrefix, "/") {
+			p.addf("%s.pathPrefix must start with /, got %q", name, rule.PathPrefix)
+		}
+		for _, method := range rule.Methods {
+			if !contains(validRouteMethods, strings.ToUpper(method)) || strings.EqualFold(method, "ANY") {
+				p.addf("%s.methods must be HTTP methods, got %q", name, method)
+			}
+		}
+		if len(rule.Roles) == 0 && rule.Permission == "" {
+			p.addf("%s must set roles or permission", name)
+		}
+	}
+}
+
+func (c *RequestAuditConfig) validate(p *problems) {
+	if c.MaxBodySize < 0 {
+		p.addf("gateway.requestAudit.maxBodySize must not be negative, got %d", c.MaxBodySize)
+	}
+	for i, rule := range c.Rules {
+		name := fmt.Sprintf("gateway.requestAudit.rules[%d]", i)
+		if !strings.HasPrefix(rule.PathPrefix, "/") {
+			p.addf("%s.pathPrefix must start with /, got %q", name, rule.PathPrefix)
+		}
+		for _, method := range rule.Methods {
+			if !contains(validRouteMethods, strings.ToUpper(method)) || strings.EqualFold(method, "ANY") {
+				p.addf("%s.methods must be HTTP methods, got %q", name, method)
+			}
+		}
+	}
+}
+
+func (c *IPFilterConfig) validate(p *problems) {
+	for i, rule := range c.Rules {
+		name := fmt.Sprintf("gateway.ipFilter.rules[%d]", i)
+		if !strings.HasPrefix(rule.PathPrefix, "/") {
+			p.addf("%s.pathPrefix must start with /, got %q", name, rule.PathPrefix)
+		}
+		for j, ip := range rule.Allow {
+			checkIPOrCIDR(p, fmt.Sprintf("%s.allow[%d]", name, j), ip)
+		}
+		for j, ip := range rule.Deny {
+			checkIPOrCIDR(p, fmt.Sprintf("%s.deny[%d]", name, j), ip)
+		}
+		for _, country := range append(append([]string{}, rule.AllowCountries...), rule.DenyCountries...) {
+			if len(country) != 2 {
+				p.addf("%s countries must be ISO 3166-1 alpha-2 codes, got %q", name, country)
+			}
+		}
+		if len(rule.AllowCountries)+len(rule.DenyCountries) > 0 && c.CountryHeader == "" {
+			p.addf("gateway.ipFilter.countryHeader is required by the country rules of %s", name)
+		}
+	}
+}
+
+func checkIPOrCIDR(p *problems, name, value string) {
+	if net.ParseIP(value) != nil {
+		return
+	}
+	if _, _, err := net.ParseCIDR(value); err != nil {
+		p.addf("%s must be an IP or CIDR, got %q", name, value)
+	}
+}
+
+func checkPort(p *problems, name string, port int) {
+	if port < 1 || port > 65535 {
+		p.addf("%s must be between 1 and 65535, got %d", name, port)
+	}
+}
+
+func checkURL(p *problems, name, raw string, schemes ...string) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		p.addf("%s %q must be an absolute URL", name, raw)
+		return
+	}
+	if !contains(schemes, u.Scheme) {
+		p.addf("%s %q must use one of the schemes %s", name, raw, strings.Join(schemes, ", "))
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// ChangeFunc is called after the configuration changed with the previous and the
+// new configuration. Callbacks run sequentially on the watcher goroutine and
+// should return quickly.
+type ChangeFunc func(old, new *Config)
+
+// ErrorFunc is called when a changed configuration cannot be loaded, the
+// previous configuration then stays current
+type ErrorFunc func(err error)
+
+// Watcher keeps the configuration of a service current while it runs. It reloads
+// the configuration file whenever it is written, and on demand with Reload, e.g.
+// on SIGHUP. Environment overrides are applied again on every reload.
+//
+// Only settings read through Current or delivered to ChangeFunc callbacks take
+// effect at runtime: servers, pools and clients built at startup keep the values
+// they were built with until the service restarts.
+type Watcher struct {
+	v        *viper.Viper
+	secrets  *SecretResolver
+	reloadMu sync.Mutex // viper is not safe for concurrent reads of the file
+
+	mu        sync.RWMutex
+	current   *Config
+	callbacks []ChangeFunc
+	onError   ErrorFunc
+}
+
+// Watch loads the configuration like Load and starts watching the configuration
+// file for changes
+func Watch(serviceName, configPath string) (*Watcher, error) {
+	v, err := newViper(serviceName, configPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := decode(v)
+	if err != nil {
+		return nil, err
+	}
+	secrets := NewSecretResolver(cfg.Secrets)
+	if err := secrets.ResolveConfig(context.Background(), cfg); err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{v: v, secrets: secrets, current: cfg}
+	if v.ConfigFileUsed() != "" {
+		v.OnConfigChange(func(fsnotify.Event) {
+			if err := w.Reload(); err != nil {
+				w.reportError(err)
+			}
+		})
+		v.WatchConfig()
+	}
+	return w, nil
+}
+
+// Current returns the current configuration, which must not be modified
+func (w *Watcher) Current() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// OnChange registers a callback invoked after each configuration change
+func (w *Watcher) OnChange(fn ChangeFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callbacks = append(w.callbacks, fn)
+}
+
+// OnError registers the callback reporting failed reloads triggered by a file
+// change, which have no caller to return the error to
+func (w *Watcher) OnError(fn ErrorFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = fn
+}
+
+// Reload reads the configuration file again and notifies the callbacks. A file
+// that fails to parse leaves the current configuration in place.
+func (w *Watcher) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	if w.v.ConfigFileUsed() != "" {
+		if err := w.v.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to reload config file: %w", err)
+		}
+	}
+	cfg, err := decode(w.v)
+	if err != nil {
+		return err
+	}
+	if err := w.secrets.ResolveConfig(context.Background(), cfg); err != nil {
+		return fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	old := w.current
+	w.current = cfg
+	callbacks := append([]ChangeFunc(nil), w.callbacks...)
+	w.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(old, cfg)
+	}
+	return nil
+}
+
+// ReloadOnSignal reloads the configuration whenever the process receives one of
+// sigs, typically SIGHUP. Failures are reported to the OnError callback.
+func (w *Watcher) ReloadOnSignal(sigs ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	go func() {
+		for range ch {
+			if err := w.Reload(); err != nil {
+				w.reportError(err)
+			}
+		}
+	}()
+}
+
+// RefreshSecrets reloads the configuration every interval so that rotated
+// secrets are picked up once their cached value expires. Callbacks are invoked
+// on every refresh and should compare the settings they care about. A
+// non-positive interval disables the refresh.
+func (w *Watcher) RefreshSecrets(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := w.Reload(); err != nil {
+					w.reportError(err)
+				}
+			}
+		}
+	}()
+}
+
+func (w *Watcher) reportError(err error) {
+	w.mu.RLock()
+	onError := w.onError
+	w.mu.RUnlock()
+	if onError != nil {
+		onError(err)
+	}
+}
@@ -0,0 +1,190 @@
+// Package cron parses standard five-field cron expressions and computes their
+// next activation time. The fields are minute, hour, day of month, month and
+// day of week; each accepts *, values, ranges (1-5), lists (1,15) and steps
+// (*/10 or 8-18/2), and months and days of week also accept three-letter names.
+// The descriptors @yearly, @monthly, @weekly, @daily and @hourly are supported.
+// As in Vixie cron, when both the day of month and the day of week are
+// restricted, a time matches if either of them does.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit bounds the search for the next activation, an expression such as
+// "0 0 30 2 *" never matches
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var fields = [5]field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	{name: "day of week", min: 0, max: 7, names: dayNames}, // 7 is also Sunday
+}
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+// Parse parses a cron expression
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if d, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields, got %d", expr, len(parts))
+	}
+
+	var sets [5]uint64
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+		sets[i] = set
+	}
+	// Fold Sunday as 7 into 0
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+	return &Schedule{
+		expr:          expr,
+		minute:        sets[0],
+		hour:          sets[1],
+		dom:           sets[2],
+		month:         sets[3],
+		dow:           sets[4],
+		domRestricted: parts[2] != "*",
+		dowRestricted: parts[4] != "*",
+	}, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first activation strictly after t, in the location of t. It
+// returns the zero time when the expression never matches.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// parseField parses one field into a bit set of the matching values
+func parseField(spec string, f field) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(spec, ",") {
+		lo, hi, step := f.min, f.max, 1
+		rng := part
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, part)
+			}
+			rng, step = part[:i], n
+		}
+		if rng != "*" {
+			var err error
+			if i := strings.Index(rng, "-"); i >= 0 {
+				if lo, err = parseValue(rng[:i], f); err != nil {
+					return 0, err
+				}
+				if hi, err = parseValue(rng[i+1:], f); err != nil {
+					return 0, err
+				}
+			} else {
+				if lo, err = parseValue(rng, f); err != nil {
+					return 0, err
+				}
+				// A single value with a step, e.g. 5/15, runs up to the maximum
+				if step == 1 {
+					hi = lo
+				}
+			}
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("invalid range in %s field %q", f.name, part)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q, must be between %d and %d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
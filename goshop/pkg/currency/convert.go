@@ -0,0 +1,120 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Rates converts between currencies
+type Rates interface {
+	// Rate returns the number of units of to per unit of from
+	Rate(from, to Code) (float64, error)
+}
+
+// StaticRates are fixed exchange rates against a base currency, e.g. loaded from
+// configuration
+type StaticRates struct {
+	Base  Code
+	Rates map[Code]float64 // units of the currency per unit of Base
+}
+
+// Rate implements Rates, converting through the base currency
+func (s StaticRates) Rate(from, to Code) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	rateOf := func(c Code) (float64, error) {
+		if c == s.Base {
+			return 1, nil
+		}
+		r, ok := s.Rates[c]
+		if !ok || r <= 0 {
+			return 0, fmt.Errorf("currency: no exchange rate for %s", c)
+		}
+		return r, nil
+	}
+	fromRate, err := rateOf(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := rateOf(to)
+	if err != nil {
+		return 0, err
+	}
+	return toRate / fromRate, nil
+}
+
+// Table is a snapshot of exchange rates against a base currency, as published
+// by the currency service when it fetches the rates of the day
+type Table struct {
+	Base  Code             `json:"base"`
+	Date  time.Time        `json:"date"`
+	Rates map[Code]float64 `json:"rates"` // units of the currency per unit of Base
+}
+
+// Rate implements Rates, converting through the base currency
+func (t Table) Rate(from, to Code) (float64, error) {
+	return StaticRates{Base: t.Base, Rates: t.Rates}.Rate(from, to)
+}
+
+// ErrNoRates is returned by LiveRates before a table is set
+var ErrNoRates = errors.New("currency: exchange rates not loaded")
+
+// LiveRates are the latest exchange rates of a service, replaced whenever the
+// currency service publishes a new table. It is safe for concurrent use.
+type LiveRates struct {
+	mu    sync.RWMutex
+	table *Table
+}
+
+// Set replaces the rates with t, unless t is older than the current table as
+// rate updates may be delivered out of order
+func (l *LiveRates) Set(t Table) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.table != nil && t.Date.Before(l.table.Date) {
+		return
+	}
+	l.table = &t
+}
+
+// Table returns the current table, false before one is set
+func (l *LiveRates) Table() (Table, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.table == nil {
+		return Table{}, false
+	}
+	return *l.table, true
+}
+
+// Rate implements Rates with the current table
+func (l *LiveRates) Rate(from, to Code) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	t, ok := l.Table()
+	if !ok {
+		return 0, ErrNoRates
+	}
+	return t.Rate(from, to)
+}
+
+// Convert converts m into currency to with rates, rounding with mode. The
+// difference of minor unit digits between currencies is accounted for.
+func Convert(m Money, to Code, rates Rates, mode RoundingMode) (Money, error) {
+	if m.Currency() == to {
+		return m, nil
+	}
+	rate, err := rates.Rate(m.Currency(), to)
+	if err != nil {
+		return Money{}, err
+	}
+	r := new(big.Rat).SetInt64(m.amount)
+	r.Mul(r, new(big.Rat).SetFloat64(rate))
+	r.Mul(r, new(big.Rat).SetFrac64(to.factor(), m.Currency().factor()))
+	return Money{amount: round(r, mode), currency: to}, nil
+}
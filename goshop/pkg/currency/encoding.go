@@ -0,0 +1,104 @@
+package currency
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// jsonMoney is the JSON form of Money. The amount is a decimal string so that
+// clients do not parse it into a float.
+type jsonMoney struct {
+	Amount   string `json:"amount"`
+	Currency Code   `json:"currency"`
+}
+
+// MarshalJSON encodes m as {"amount":"19.99","currency":"CNY"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMoney{Amount: m.Decimal(), Currency: m.Currency()})
+}
+
+// UnmarshalJSON decodes the object written by MarshalJSON, or a plain number or
+// decimal string in the default currency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '{' {
+		var v jsonMoney
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		if v.Currency == "" {
+			v.Currency = Default
+		}
+		parsed, err := Parse(v.Amount, v.Currency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+
+	raw := string(data)
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+	parsed, err := Parse(raw, Default)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores m as a decimal amount in major units. The column holds no
+// currency: tables storing amounts of other currencies than the default keep the
+// currency code in a column of its own and restore it with WithCurrency after
+// scanning.
+func (m Money) Value() (driver.Value, error) {
+	return m.Decimal(), nil
+}
+
+// Scan reads a decimal amount of the default currency, see WithCurrency for
+// amounts of other currencies
+func (m *Money) Scan(src interface{}) error {
+	var raw string
+	switch v := src.(type) {
+	case nil:
+		*m = Zero(Default)
+		return nil
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	case int64:
+		*m = New(v*Default.factor(), Default)
+		return nil
+	case float64:
+		*m = FromMajor(v, Default)
+		return nil
+	default:
+		return fmt.Errorf("currency: cannot scan %T into Money", src)
+	}
+	parsed, err := Parse(raw, Default)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// WithCurrency returns the amount of m in major units as an amount of c, e.g.
+// the "1500.00" scanned from a decimal column as CNY becomes 1500 JPY. It
+// restores the currency of amounts scanned by Scan from the currency column of
+// the row, and fails when the amount has more decimals than c.
+func (m Money) WithCurrency(c Code) (Money, error) {
+	if c == "" || c == m.Currency() {
+		return m, nil
+	}
+	return Parse(m.Decimal(), c)
+}
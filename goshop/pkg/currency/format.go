@@ -0,0 +1,117 @@
+package currency
+
+import (
+	"strings"
+)
+
+// fallbackLocale formats amounts of locales without rules of their own
+const fallbackLocale = "en-US"
+
+// localeFormat is how a locale writes amounts: the decimal and grouping
+// separators and whether the symbol follows the number
+type localeFormat struct {
+	decimal     string
+	group       string
+	symbolAfter bool
+}
+
+var localeFormats = map[string]localeFormat{
+	"zh-CN": {decimal: ".", group: ","},
+	"zh-HK": {decimal: ".", group: ","},
+	"zh-TW": {decimal: ".", group: ","},
+	"en-US": {decimal: ".", group: ","},
+	"en-GB": {decimal: ".", group: ","},
+	"ja-JP": {decimal: ".", group: ","},
+	"de-DE": {decimal: ",", group: ".", symbolAfter: true},
+	"fr-FR": {decimal: ",", group: " ", symbolAfter: true},
+	"es-ES": {decimal: ",", group: ".", symbolAfter: true},
+}
+
+// symbols are the symbols of currencies in locales that have no override
+var symbols = map[Code]string{
+	CNY: "¥",
+	USD: "$",
+	EUR: "€",
+	HKD: "HK$",
+	JPY: "¥",
+}
+
+// localSymbols disambiguate symbols shared by several currencies, e.g. ¥ is
+// the yuan in China and the yen in Japan
+var localSymbols = map[string]map[Code]string{
+	"zh-CN": {USD: "US$", JPY: "JP¥"},
+	"zh-HK": {CNY: "CN¥", USD: "US$", JPY: "JP¥"},
+	"zh-TW": {CNY: "CN¥", USD: "US$", JPY: "JP¥"},
+	"en-US": {CNY: "CN¥", JPY: "JP¥"},
+	"en-GB": {CNY: "CN¥", USD: "US$", JPY: "JP¥"},
+	"ja-JP": {CNY: "元", USD: "$", JPY: "￥"},
+	"de-DE": {CNY: "CN¥", USD: "$", JPY: "¥"},
+	"fr-FR": {CNY: "CNY", USD: "$US", JPY: "JPY"},
+	"es-ES": {CNY: "CNY", USD: "US$", JPY: "JPY"},
+}
+
+// resolveLocale returns the supported locale closest to locale: the locale
+// itself, the first one of the same language, or the fallback locale
+func resolveLocale(locale string) string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	for supported := range localeFormats {
+		if strings.EqualFold(supported, locale) {
+			return supported
+		}
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	var match string
+	for supported := range localeFormats {
+		if lang, _, _ := strings.Cut(supported, "-"); strings.EqualFold(lang, language) && (match == "" || supported < match) {
+			match = supported
+		}
+	}
+	if match != "" {
+		return match
+	}
+	return fallbackLocale
+}
+
+// Symbol returns the symbol of c in locale, the currency code when the
+// currency has no known symbol
+func Symbol(c Code, locale string) string {
+	if s, ok := localSymbols[resolveLocale(locale)][c]; ok {
+		return s
+	}
+	if s, ok := symbols[c]; ok {
+		return s
+	}
+	return string(c)
+}
+
+// Format formats m for display in locale with the currency symbol, grouping
+// separators and the decimal rules of the currency, e.g. "¥1,234.50" in zh-CN
+// or "1.234,50 €" in de-DE. Unsupported locales fall back to en-US.
+func Format(m Money, locale string) string {
+	locale = resolveLocale(locale)
+	f := localeFormats[locale]
+
+	amount := m.Abs().Decimal()
+	integer, fraction, _ := strings.Cut(amount, ".")
+	var b strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(digit)
+	}
+	number := b.String()
+	if fraction != "" {
+		number += f.decimal + fraction
+	}
+
+	sign := ""
+	if m.IsNegative() {
+		sign = "-"
+	}
+	symbol := Symbol(m.Currency(), locale)
+	if f.symbolAfter {
+		return sign + number + " " + symbol
+	}
+	return sign + symbol + number
+}
@@ -0,0 +1,262 @@
+// Package currency represents amounts of money exactly, as an integer number of
+// minor units (cents, fen) of a currency. Prices and balances must not be
+// float64: binary floating point cannot represent most decimal amounts, and the
+// rounding errors of sums and percentages end up as accounting discrepancies.
+package currency
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Code is an ISO 4217 currency code
+type Code string
+
+// Supported currencies
+const (
+	CNY Code = "CNY"
+	USD Code = "USD"
+	EUR Code = "EUR"
+	HKD Code = "HKD"
+	JPY Code = "JPY"
+)
+
+// Default is the currency of amounts stored without a currency, e.g. in the
+// decimal columns of the database
+const Default = CNY
+
+// digits is the number of minor unit digits of each currency
+var digits = map[Code]int{
+	CNY: 2,
+	USD: 2,
+	EUR: 2,
+	HKD: 2,
+	JPY: 0,
+}
+
+// Digits returns the number of decimal digits of the minor unit of c, 2 for
+// unknown currencies
+func (c Code) Digits() int {
+	if d, ok := digits[c]; ok {
+		return d
+	}
+	return 2
+}
+
+// factor returns the number of minor units in one major unit of c
+func (c Code) factor() int64 {
+	f := int64(1)
+	for i := 0; i < c.Digits(); i++ {
+		f *= 10
+	}
+	return f
+}
+
+// Money is an amount in minor units of a currency. The zero value is zero in the
+// default currency.
+type Money struct {
+	amount   int64
+	currency Code
+}
+
+// New returns amount minor units of currency, e.g. New(1999, CNY) is ¥19.99
+func New(amount int64, currency Code) Money {
+	return Money{amount: amount, currency: currency}
+}
+
+// Zero returns no money in currency
+func Zero(currency Code) Money {
+	return Money{currency: currency}
+}
+
+// FromMajor converts an amount in major units, e.g. 19.99, rounding half away
+// from zero to the minor unit. It is meant for amounts received as float64 from
+// outside, such as events of other services.
+func FromMajor(amount float64, currency Code) Money {
+	return Money{amount: int64(math.Round(amount * float64(currency.factor()))), currency: currency}
+}
+
+// Parse parses a decimal amount in major units such as "19.99" or "-5" exactly.
+// More decimals than the currency has are rejected rather than rounded.
+func Parse(s string, currency Code) (Money, error) {
+	s = strings.TrimSpace(s)
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Money{}, fmt.Errorf("currency: invalid amount %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(currency.factor()))
+	if !r.IsInt() {
+		return Money{}, fmt.Errorf("currency: amount %q has more than %d decimals", s, currency.Digits())
+	}
+	if !r.Num().IsInt64() {
+		return Money{}, fmt.Errorf("currency: amount %q out of range", s)
+	}
+	return Money{amount: r.Num().Int64(), currency: currency}, nil
+}
+
+// MustParse is Parse panicking on invalid amounts, for constants
+func MustParse(s string, currency Code) Money {
+	m, err := Parse(s, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Amount returns the amount in minor units
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+// Currency returns the currency of m
+func (m Money) Currency() Code {
+	if m.currency == "" {
+		return Default
+	}
+	return m.currency
+}
+
+// IsZero reports whether m is zero
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// IsPositive reports whether m is greater than zero
+func (m Money) IsPositive() bool {
+	return m.amount > 0
+}
+
+// IsNegative reports whether m is less than zero
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// SameCurrency reports whether m and o are in the same currency
+func (m Money) SameCurrency(o Money) bool {
+	return m.Currency() == o.Currency()
+}
+
+// mustMatch panics when m and o are in different currencies, mixing currencies
+// without a conversion being a programming error
+func (m Money) mustMatch(o Money) {
+	if !m.SameCurrency(o) {
+		panic(fmt.Sprintf("currency: mismatched currencies %s and %s", m.Currency(), o.Currency()))
+	}
+}
+
+// Add returns m + o, which must be in the same currency
+func (m Money) Add(o Money) Money {
+	m.mustMatch(o)
+	return Money{amount: m.amount + o.amount, currency: m.Currency()}
+}
+
+// Sub returns m - o, which must be in the same currency
+func (m Money) Sub(o Money) Money {
+	m.mustMatch(o)
+	return Money{amount: m.amount - o.amount, currency: m.Currency()}
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{amount: -m.amount, currency: m.Currency()}
+}
+
+// Abs returns the absolute value of m
+func (m Money) Abs() Money {
+	if m.amount < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// Mul returns m multiplied by n, e.g. a unit price by a quantity
+func (m Money) Mul(n int64) Money {
+	return Money{amount: m.amount * n, currency: m.Currency()}
+}
+
+// MulRate returns m multiplied by rate, rounded to the minor unit with mode,
+// e.g. a discount rate of 0.95 or a refund ratio
+func (m Money) MulRate(rate float64, mode RoundingMode) Money {
+	r := new(big.Rat).SetInt64(m.amount)
+	r.Mul(r, new(big.Rat).SetFloat64(rate))
+	return Money{amount: round(r, mode), currency: m.Currency()}
+}
+
+// Percent returns percent % of m rounded with mode, e.g. a 5% commission
+func (m Money) Percent(percent float64, mode RoundingMode) Money {
+	r := new(big.Rat).SetInt64(m.amount)
+	r.Mul(r, new(big.Rat).SetFloat64(percent))
+	r.Quo(r, big.NewRat(100, 1))
+	return Money{amount: round(r, mode), currency: m.Currency()}
+}
+
+// Cmp compares m and o, which must be in the same currency, returning -1, 0 or +1
+func (m Money) Cmp(o Money) int {
+	m.mustMatch(o)
+	switch {
+	case m.amount < o.amount:
+		return -1
+	case m.amount > o.amount:
+		return 1
+	}
+	return 0
+}
+
+// Equal reports whether m and o are the same amount of the same currency
+func (m Money) Equal(o Money) bool {
+	return m.SameCurrency(o) && m.amount == o.amount
+}
+
+// LessThan reports whether m < o
+func (m Money) LessThan(o Money) bool {
+	return m.Cmp(o) < 0
+}
+
+// GreaterThan reports whether m > o
+func (m Money) GreaterThan(o Money) bool {
+	return m.Cmp(o) > 0
+}
+
+// Min returns the smaller of m and o
+func (m Money) Min(o Money) Money {
+	if o.LessThan(m) {
+		return o
+	}
+	return m
+}
+
+// Max returns the larger of m and o
+func (m Money) Max(o Money) Money {
+	if o.GreaterThan(m) {
+		return o
+	}
+	return m
+}
+
+// Decimal formats the amount in major units without currency, e.g. "19.99"
+func (m Money) Decimal() string {
+	d := m.Currency().Digits()
+	if d == 0 {
+		return fmt.Sprintf("%d", m.amount)
+	}
+	sign := ""
+	amount := m.amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	f := m.Currency().factor()
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/f, d, amount%f)
+}
+
+// Float64 returns the amount in major units, for statistics and display only
+func (m Money) Float64() float64 {
+	return float64(m.amount) / float64(m.Currency().factor())
+}
+
+// String formats m with its currency, e.g. "19.99 CNY"
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Currency())
+}
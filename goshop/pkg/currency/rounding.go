@@ -0,0 +1,101 @@
+package currency
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode decides how amounts falling between two minor units are rounded
+type RoundingMode int
+
+const (
+	// HalfUp rounds half away from zero, the usual commercial rounding
+	HalfUp RoundingMode = iota
+	// HalfEven rounds half to the even minor unit, avoiding the upward bias of
+	// HalfUp over many roundings
+	HalfEven
+	// Down truncates toward zero, never granting more than computed
+	Down
+	// Up rounds away from zero
+	Up
+)
+
+// round rounds r to an integer with mode
+func round(r *big.Rat, mode RoundingMode) int64 {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+	neg := num.Sign() < 0
+	num.Abs(num)
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		// Compare twice the remainder with the denominator to locate the half
+		half := new(big.Int).Lsh(rem, 1).Cmp(den)
+		switch mode {
+		case HalfUp:
+			if half >= 0 {
+				q.Add(q, big.NewInt(1))
+			}
+		case HalfEven:
+			if half > 0 || (half == 0 && q.Bit(0) == 1) {
+				q.Add(q, big.NewInt(1))
+			}
+		case Up:
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if neg {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
+
+// Allocate splits m between parties in proportion to ratios without losing a
+// minor unit: the remainder left by rounding down is handed out one unit at a
+// time, in order, so the parts always add up to m. It is used to spread an order
+// discount over its items.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, fmt.Errorf("currency: negative allocation ratio %d", r)
+		}
+		total += r
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("currency: allocation ratios sum to zero")
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.amount
+	for i, r := range ratios {
+		share := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(r))
+		share.Quo(share, big.NewInt(total))
+		parts[i] = Money{amount: share.Int64(), currency: m.Currency()}
+		remainder -= parts[i].amount
+	}
+	unit := int64(1)
+	if remainder < 0 {
+		unit = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].amount += unit
+		remainder -= unit
+	}
+	return parts, nil
+}
+
+// Split divides m into n parts differing by at most one minor unit
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("currency: cannot split into %d parts", n)
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
@@ -0,0 +1,142 @@
+// Package database opens the PostgreSQL connection shared by the services, with
+// connection pooling, query logging through pkg/logger, OpenTelemetry tracing,
+// schema migrations and optional read-replica routing.
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/logger"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+	"gorm.io/plugin/opentelemetry/tracing"
+)
+
+// options holds the optional settings of Open
+type options struct {
+	logLevel       gormlogger.LogLevel
+	migrationsPath string
+	models         []interface{}
+}
+
+// Option configures Open
+type Option func(*options)
+
+// WithLogLevel sets the GORM log level, queries are only logged at Info level.
+// The default is Warn, which logs slow queries and errors.
+func WithLogLevel(level gormlogger.LogLevel) Option {
+	return func(o *options) {
+		o.logLevel = level
+	}
+}
+
+// WithMigrations applies the SQL migrations in path on startup, overriding
+// DatabaseConfig.MigrationsPath
+func WithMigrations(path string) Option {
+	return func(o *options) {
+		o.migrationsPath = path
+	}
+}
+
+// WithAutoMigrate runs GORM AutoMigrate for models on startup, after the SQL
+// migrations have been applied
+func WithAutoMigrate(models ...interface{}) Option {
+	return func(o *options) {
+		o.models = append(o.models, models...)
+	}
+}
+
+// Open connects to the primary database described by cfg and prepares it for use:
+// it configures the connection pool, routes reads to the replicas when configured,
+// registers tracing and finally applies migrations
+func Open(cfg config.DatabaseConfig, log *logger.Logger, opts ...Option) (*gorm.DB, error) {
+	o := &options{
+		logLevel:       gormlogger.Warn,
+		migrationsPath: cfg.MigrationsPath,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{
+		Logger: newGormLogger(log, time.Duration(cfg.SlowThreshold)*time.Millisecond).LogMode(o.logLevel),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get database handle: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Minute)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Minute)
+
+	if err := useReplicas(db, cfg); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	if err := db.Use(tracing.NewPlugin(tracing.WithDBName(cfg.DBName))); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("register tracing plugin: %w", err)
+	}
+
+	if o.migrationsPath != "" {
+		if err := Migrate(cfg.DSN(), o.migrationsPath); err != nil {
+			sqlDB.Close()
+			return nil, err
+		}
+	}
+	if len(o.models) > 0 {
+		if err := db.AutoMigrate(o.models...); err != nil {
+			sqlDB.Close()
+			return nil, fmt.Errorf("auto migrate: %w", err)
+		}
+	}
+	return db, nil
+}
+
+// Close closes the connection pool of db
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// useReplicas routes queries outside of transactions to the read replicas, writes and
+// transactions keep using the primary. Replicas share the pool settings of the primary.
+func useReplicas(db *gorm.DB, cfg config.DatabaseConfig) error {
+	if len(cfg.Replicas) == 0 {
+		return nil
+	}
+	dsns, err := cfg.ReplicaDSNs()
+	if err != nil {
+		return err
+	}
+	replicas := make([]gorm.Dialector, len(dsns))
+	for i, dsn := range dsns {
+		replicas[i] = postgres.Open(dsn)
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas:          replicas,
+		Policy:            dbresolver.RandomPolicy{},
+		TraceResolverMode: true,
+	}).
+		SetMaxOpenConns(cfg.MaxOpenConns).
+		SetMaxIdleConns(cfg.MaxIdleConns).
+		SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Minute).
+		SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Minute)
+	if err := db.Use(resolver); err != nil {
+		return fmt.Errorf("register read replicas: %w", err)
+	}
+	return nil
+}
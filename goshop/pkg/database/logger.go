@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger writes GORM logs through pkg/logger so that queries carry the trace ID
+// of the request context
+type gormLogger struct {
+	log           *logger.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// newGormLogger creates a GORM logger, queries slower than slowThreshold are logged
+// as warnings. A zero slowThreshold disables slow query logging.
+func newGormLogger(log *logger.Logger, slowThreshold time.Duration) *gormLogger {
+	return &gormLogger{
+		log:           log,
+		level:         gormlogger.Warn,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// LogMode implements gormlogger.Interface
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info implements gormlogger.Interface
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.log.Info(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn implements gormlogger.Interface
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.log.Warn(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error implements gormlogger.Interface
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.log.Error(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace implements gormlogger.Interface. Failed queries are logged as errors, except
+// record not found which is an expected outcome, and slow queries as warnings.
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		l.log.Error(ctx, "SQL query failed", queryFields(sql, rows, elapsed, zap.Error(err))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		l.log.Warn(ctx, "Slow SQL query", queryFields(sql, rows, elapsed, zap.Duration("threshold", l.slowThreshold))...)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		l.log.Debug(ctx, "SQL query", queryFields(sql, rows, elapsed)...)
+	}
+}
+
+func queryFields(sql string, rows int64, elapsed time.Duration, fields ...zap.Field) []zap.Field {
+	return append([]zap.Field{
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+		zap.Duration("elapsed", elapsed),
+	}, fields...)
+}
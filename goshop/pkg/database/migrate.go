@@ -0,0 +1,41 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file" // file:// migration source
+)
+
+// Migrate applies the pending golang-migrate SQL migrations in dir to the database
+// at dsn. Migration files are named like 000001_create_users.up.sql; the applied
+// version is recorded in the schema_migrations table. Migrations run on a
+// dedicated connection pool, closed before Migrate returns, so that the pool of
+// the service is never shared with or closed by the migration driver.
+func Migrate(dsn, dir string) error {
+	// The "postgres" driver is registered by the migration driver's lib/pq import
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return fmt.Errorf("open migration connection: %w", err)
+	}
+	driver, err := postgres.WithInstance(sqlDB, &postgres.Config{})
+	if err != nil {
+		sqlDB.Close()
+		return fmt.Errorf("create migration driver: %w", err)
+	}
+	m, err := migrate.NewWithDatabaseInstance("file://"+dir, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return fmt.Errorf("load migrations from %s: %w", dir, err)
+	}
+	// Closing the migrate instance closes the driver and sqlDB with it
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+	return nil
+}
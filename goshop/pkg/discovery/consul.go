@@ -0,0 +1,154 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Consul is a Registry backed by the HTTP API of a Consul agent. Services
+// register themselves with Register and are listed once their Consul health
+// checks pass.
+type Consul struct {
+	address    string
+	token      string
+	datacenter string
+	httpClient *http.Client
+}
+
+// NewConsul creates a client of the Consul agent at address, e.g.
+// http://127.0.0.1:8500. token is the ACL token, datacenter defaults to the
+// agent's when empty.
+func NewConsul(address, token, datacenter string) *Consul {
+	return &Consul{
+		address:    strings.TrimSuffix(address, "/"),
+		token:      token,
+		datacenter: datacenter,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Instances returns the instances of service whose Consul checks pass
+func (c *Consul) Instances(ctx context.Context, service string) ([]Instance, error) {
+	query := url.Values{}
+	query.Set("passing", "true")
+	if c.datacenter != "" {
+		query.Set("dc", c.datacenter)
+	}
+	resp, err := c.do(ctx, http.MethodGet, "/v1/health/service/"+url.PathEscape(service)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var entries []struct {
+		Node struct {
+			Address string
+		}
+		Service struct {
+			ID      string
+			Service string
+			Address string
+			Port    int
+			Meta    map[string]string
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("discovery: failed to decode consul response: %w", err)
+	}
+	instances := make([]Instance, 0, len(entries))
+	for _, entry := range entries {
+		host := entry.Service.Address
+		if host == "" {
+			host = entry.Node.Address
+		}
+		scheme := entry.Service.Meta["scheme"]
+		if scheme == "" {
+			scheme = "http"
+		}
+		instances = append(instances, Instance{
+			ID:      entry.Service.ID,
+			Service: service,
+			URL:     fmt.Sprintf("%s://%s", scheme, joinHostPort(host, entry.Service.Port)),
+		})
+	}
+	return instances, nil
+}
+
+// Registration describes an instance registered with Consul
+type Registration struct {
+	ID         string // unique per instance, e.g. service name and host name
+	Name       string // service name
+	Address    string
+	Port       int
+	Tags       []string
+	HealthPath string        // HTTP path Consul checks, e.g. /health/ready
+	Interval   time.Duration // interval of the health check
+}
+
+// Register registers an instance with the local agent. Instances failing
+// their check for a minute are deregistered by Consul.
+func (c *Consul) Register(ctx context.Context, reg Registration) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"ID":      reg.ID,
+		"Name":    reg.Name,
+		"Address": reg.Address,
+		"Port":    reg.Port,
+		"Tags":    reg.Tags,
+		"Check": map[string]interface{}{
+			"HTTP":                           fmt.Sprintf("http://%s%s", joinHostPort(reg.Address, reg.Port), reg.HealthPath),
+			"Interval":                       reg.Interval.String(),
+			"DeregisterCriticalServiceAfter": "1m",
+		},
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, http.MethodPut, "/v1/agent/service/register", body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Deregister removes an instance from the local agent
+func (c *Consul) Deregister(ctx context.Context, id string) error {
+	resp, err := c.do(ctx, http.MethodPut, "/v1/agent/service/deregister/"+url.PathEscape(id), nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *Consul) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.address+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: consul request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("discovery: consul %s %s returned status %d", method, path, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func joinHostPort(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
@@ -0,0 +1,44 @@
+// Package discovery resolves the addresses of service instances from a service
+// registry. A Resolver keeps the instances of a set of services up to date,
+// probes their health endpoints to evict failing instances and selects
+// instances round-robin among the healthy ones.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoInstances is returned when a service has no healthy instance
+var ErrNoInstances = errors.New("discovery: no healthy instance")
+
+// Instance is a running instance of a service
+type Instance struct {
+	ID      string // unique within the service
+	Service string
+	URL     string // HTTP base URL, e.g. http://10.0.3.7:8002
+}
+
+// Registry lists the instances of services
+type Registry interface {
+	Instances(ctx context.Context, service string) ([]Instance, error)
+}
+
+// StaticRegistry serves a fixed list of base URLs per service, e.g. the
+// configured endpoints when no registry is deployed
+type StaticRegistry map[string][]string
+
+// Instances returns the configured instances of service
+func (r StaticRegistry) Instances(ctx context.Context, service string) ([]Instance, error) {
+	urls, ok := r[service]
+	if !ok {
+		return nil, fmt.Errorf("discovery: unknown service %s", service)
+	}
+	instances := make([]Instance, len(urls))
+	for i, u := range urls {
+		instances[i] = Instance{ID: u, Service: service, URL: strings.TrimSuffix(u, "/")}
+	}
+	return instances, nil
+}
@@ -0,0 +1,226 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default settings of a Resolver
+const (
+	defaultRefreshInterval    = 10 * time.Second
+	defaultHealthInterval     = 5 * time.Second
+	defaultHealthPath         = "/health/ready"
+	defaultUnhealthyThreshold = 3
+)
+
+// Option configures a Resolver
+type Option func(*Resolver)
+
+// WithRefreshInterval sets how often instances are reloaded from the registry,
+// 10 seconds by default
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(r *Resolver) {
+		r.refreshInterval = interval
+	}
+}
+
+// WithHealthCheck sets the path probed on every instance, how often it is
+// probed and how many consecutive failures evict an instance. Defaults are
+// /health/ready every 5 seconds and 3 failures.
+func WithHealthCheck(path string, interval time.Duration, threshold int) Option {
+	return func(r *Resolver) {
+		r.healthPath = path
+		r.healthInterval = interval
+		r.threshold = threshold
+	}
+}
+
+// instance is an instance and its consecutive failures, of health probes or
+// of requests reported by callers
+type instance struct {
+	Instance
+	failures int
+}
+
+// pool holds the instances of a service
+type pool struct {
+	instances []*instance
+	next      atomic.Uint64
+}
+
+// Resolver selects instances of services from a registry. Instances failing
+// threshold consecutive health probes or requests are evicted from the
+// selection until a probe succeeds again.
+type Resolver struct {
+	registry        Registry
+	services        []string
+	refreshInterval time.Duration
+	healthInterval  time.Duration
+	healthPath      string
+	threshold       int
+	httpClient      *http.Client
+
+	mu    sync.RWMutex
+	pools map[string]*pool
+}
+
+// NewResolver creates a resolver of the instances of services in registry.
+// Call Refresh or Run before picking instances.
+func NewResolver(registry Registry, services []string, opts ...Option) *Resolver {
+	r := &Resolver{
+		registry:        registry,
+		services:        services,
+		refreshInterval: defaultRefreshInterval,
+		healthInterval:  defaultHealthInterval,
+		healthPath:      defaultHealthPath,
+		threshold:       defaultUnhealthyThreshold,
+		pools:           make(map[string]*pool, len(services)),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.httpClient = &http.Client{Timeout: r.healthInterval}
+	return r
+}
+
+// Run refreshes the instances and probes their health until ctx is
+// cancelled, reporting failures to onError
+func (r *Resolver) Run(ctx context.Context, onError func(error)) {
+	if err := r.Refresh(ctx); err != nil {
+		onError(err)
+	}
+	refresh := time.NewTicker(r.refreshInterval)
+	defer refresh.Stop()
+	check := time.NewTicker(r.healthInterval)
+	defer check.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refresh.C:
+			if err := r.Refresh(ctx); err != nil {
+				onError(err)
+			}
+		case <-check.C:
+			r.Check(ctx)
+		}
+	}
+}
+
+// Refresh reloads the instances of every service. Instances still registered
+// keep their failure counts; a service whose lookup fails keeps its previous
+// instances.
+func (r *Resolver) Refresh(ctx context.Context) error {
+	var errs []error
+	for _, service := range r.services {
+		found, err := r.registry.Instances(ctx, service)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", service, err))
+			continue
+		}
+
+		r.mu.Lock()
+		previous := map[string]*instance{}
+		if p, ok := r.pools[service]; ok {
+			for _, inst := range p.instances {
+				previous[inst.ID] = inst
+			}
+		}
+		next := &pool{instances: make([]*instance, len(found))}
+		for i, inst := range found {
+			if prev, ok := previous[inst.ID]; ok && prev.URL == inst.URL {
+				next.instances[i] = prev
+				continue
+			}
+			next.instances[i] = &instance{Instance: inst}
+		}
+		if p, ok := r.pools[service]; ok {
+			next.next.Store(p.next.Load())
+		}
+		r.pools[service] = next
+		r.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Check probes the health endpoint of every instance concurrently
+func (r *Resolver) Check(ctx context.Context) {
+	r.mu.RLock()
+	var instances []*instance
+	for _, p := range r.pools {
+		instances = append(instances, p.instances...)
+	}
+	r.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, inst := range instances {
+		wg.Add(1)
+		go func(inst *instance) {
+			defer wg.Done()
+			healthy := r.probe(ctx, inst.URL)
+			r.mu.Lock()
+			if healthy {
+				inst.failures = 0
+			} else {
+				inst.failures++
+			}
+			r.mu.Unlock()
+		}(inst)
+	}
+	wg.Wait()
+}
+
+func (r *Resolver) probe(ctx context.Context, baseURL string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+r.healthPath, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// Pick selects the next healthy instance of service round-robin, or returns
+// ErrNoInstances
+func (r *Resolver) Pick(service string) (Instance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.pools[service]
+	if !ok {
+		return Instance{}, fmt.Errorf("%w of %s", ErrNoInstances, service)
+	}
+	healthy := make([]*instance, 0, len(p.instances))
+	for _, inst := range p.instances {
+		if inst.failures < r.threshold {
+			healthy = append(healthy, inst)
+		}
+	}
+	if len(healthy) == 0 {
+		return Instance{}, fmt.Errorf("%w of %s", ErrNoInstances, service)
+	}
+	n := p.next.Add(1) - 1
+	return healthy[n%uint64(len(healthy))].Instance, nil
+}
+
+// ReportFailure records a failed request to inst, e.g. a refused connection,
+// so that failing instances are evicted before their next health probe
+func (r *Resolver) ReportFailure(inst Instance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.pools[inst.Service]; ok {
+		for _, candidate := range p.instances {
+			if candidate.ID == inst.ID {
+				candidate.failures++
+				return
+			}
+		}
+	}
+}
@@ -18,6 +18,9 @@ const (
 	ErrConflict             ErrorCode = "CONFLICT"
 	ErrInternalServer       ErrorCode = "INTERNAL_SERVER_ERROR"
 	ErrServiceUnavailable   ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrTooManyRequests      ErrorCode = "TOO_MANY_REQUESTS"
+	ErrGatewayTimeout       ErrorCode = "GATEWAY_TIMEOUT"
+	ErrPayloadTooLarge      ErrorCode = "PAYLOAD_TOO_LARGE"
 
 	// User related errors
 	ErrUserNotFound         ErrorCode = "USER_NOT_FOUND"
@@ -37,18 +40,47 @@ const (
 
 	// Payment related errors
 	ErrPaymentFailed        ErrorCode = "PAYMENT_FAILED"
+
+	// Shipping related errors
+	ErrShipmentNotFound     ErrorCode = "SHIPMENT_NOT_FOUND"
+	ErrShippingUnavailable  ErrorCode = "SHIPPING_UNAVAILABLE"
+
+	// Marketing related errors
+	ErrCouponNotFound       ErrorCode = "COUPON_NOT_FOUND"
+	ErrCouponCodeUsed       ErrorCode = "COUPON_CODE_USED"
+	ErrCouponExhausted      ErrorCode = "COUPON_EXHAUSTED"
+	ErrPromotionExhausted   ErrorCode = "PROMOTION_EXHAUSTED"
+	ErrFlashSaleSoldOut     ErrorCode = "FLASH_SALE_SOLD_OUT"
+	ErrFlashSaleBusy        ErrorCode = "FLASH_SALE_BUSY"
 )
 
+// Error implements error so that codes can be matched with errors.Is
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
 // Error is the standard error type for the system
 type Error struct {
-	Code     ErrorCode `json:"code"`
-	Message  string    `json:"message"`
-	HTTPCode int       `json:"-"`
-	Err      error     `json:"-"`
+	Code     ErrorCode         `json:"code"`
+	Message  string            `json:"message"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	HTTPCode int               `json:"-"`
+	Err      error             `json:"-"`
+
+	context string                 // message added by Wrap
+	values  map[string]interface{} // diagnostic values added by WithField
+	wrapper bool                   // annotates Err rather than being the origin
+	stack   []uintptr              // call stack captured where the error was created
 }
 
 // Implements error interface
 func (e *Error) Error() string {
+	if e.wrapper {
+		if e.context == "" {
+			return e.Err.Error()
+		}
+		return fmt.Sprintf("%s: %v", e.context, e.Err)
+	}
 	if e.Err != nil {
 		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
 	}
@@ -60,6 +92,25 @@ func (e *Error) Unwrap() error {
 	return e.Err
 }
 
+// Is reports whether the error has the code of target, which may be an ErrorCode
+// or another *Error, so that errors.Is(err, ErrNotFound) matches wrapped errors
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case ErrorCode:
+		return e.Code == t
+	case *Error:
+		return e.Code == t.Code
+	}
+	return false
+}
+
+// WithFields attaches per-field messages to the error, e.g. validation failures
+// keyed by the JSON name of the field
+func (e *Error) WithFields(fields map[string]string) *Error {
+	e.Fields = fields
+	return e
+}
+
 // New creates a new error
 func New(code ErrorCode, message string, httpCode int, err error) *Error {
 	return &Error{
@@ -67,6 +118,7 @@ func New(code ErrorCode, message string, httpCode int, err error) *Error {
 		Message:  message,
 		HTTPCode: httpCode,
 		Err:      err,
+		stack:    callers(),
 	}
 }
 
@@ -104,3 +156,18 @@ func NewInternalServerError(message string, err error) *Error {
 func NewServiceUnavailable(message string, err error) *Error {
 	return New(ErrServiceUnavailable, message, http.StatusServiceUnavailable, err)
 }
+
+// NewTooManyRequests creates a 429 error
+func NewTooManyRequests(message string, err error) *Error {
+	return New(ErrTooManyRequests, message, http.StatusTooManyRequests, err)
+}
+
+// NewPayloadTooLarge creates a 413 error
+func NewPayloadTooLarge(message string, err error) *Error {
+	return New(ErrPayloadTooLarge, message, http.StatusRequestEntityTooLarge, err)
+}
+
+// NewGatewayTimeout creates a 504 error
+func NewGatewayTimeout(message string, err error) *Error {
+	return New(ErrGatewayTimeout, message, http.StatusGatewayTimeout, err)
+}
@@ -0,0 +1,183 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain identifies the ErrorInfo details written by this package
+const errorDomain = "goshop"
+
+// ErrorInfo metadata keys
+const (
+	metaHTTPStatus  = "http_status"
+	metaFieldPrefix = "field."
+)
+
+// grpcCodes maps error codes whose gRPC semantics differ from the default of
+// their HTTP status. Stock and quota errors are FailedPrecondition rather than
+// ResourceExhausted, which clients retry.
+var grpcCodes = map[ErrorCode]codes.Code{
+	ErrOutOfStock:          codes.FailedPrecondition,
+	ErrCouponCodeUsed:      codes.FailedPrecondition,
+	ErrCouponExhausted:     codes.FailedPrecondition,
+	ErrPromotionExhausted:  codes.FailedPrecondition,
+	ErrFlashSaleSoldOut:    codes.FailedPrecondition,
+	ErrFlashSaleBusy:       codes.Unavailable,
+	ErrPaymentFailed:       codes.Aborted,
+	ErrShippingUnavailable: codes.FailedPrecondition,
+}
+
+// GRPCCode returns the gRPC status code of an application error
+func GRPCCode(e *Error) codes.Code {
+	if c, ok := grpcCodes[e.Code]; ok {
+		return c
+	}
+	switch e.HTTPCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if e.HTTPCode >= 400 && e.HTTPCode < 500 {
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
+
+// fromGRPCCode returns the error code and HTTP status of a gRPC status code,
+// used for statuses without ErrorInfo, e.g. from gRPC itself
+func fromGRPCCode(c codes.Code) (ErrorCode, int) {
+	switch c {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return ErrBadRequest, http.StatusBadRequest
+	case codes.Unauthenticated:
+		return ErrUnauthorized, http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return ErrForbidden, http.StatusForbidden
+	case codes.NotFound:
+		return ErrNotFound, http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition:
+		return ErrConflict, http.StatusConflict
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return ErrServiceUnavailable, http.StatusServiceUnavailable
+	}
+	return ErrInternalServer, http.StatusInternalServerError
+}
+
+// ToStatus converts err into a gRPC status. Application errors keep their code,
+// HTTP status and field messages in an ErrorInfo detail so that FromStatus can
+// restore them on the client side. Errors that already carry a status and
+// context errors keep their gRPC code.
+func ToStatus(err error) *status.Status {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		return status.FromContextError(err)
+	}
+
+	st := status.New(GRPCCode(appErr), appErr.Message)
+	info := &errdetails.ErrorInfo{
+		Reason: string(appErr.Code),
+		Domain: errorDomain,
+		Metadata: map[string]string{
+			metaHTTPStatus: strconv.Itoa(appErr.HTTPCode),
+		},
+	}
+	for field, msg := range appErr.Fields {
+		info.Metadata[metaFieldPrefix+field] = msg
+	}
+	if detailed, detailErr := st.WithDetails(info); detailErr == nil {
+		return detailed
+	}
+	return st
+}
+
+// FromStatus converts an error returned by a gRPC call back into an application
+// error with the code, HTTP status and field messages set by the server. Errors
+// that are not gRPC statuses are returned unchanged.
+func FromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != errorDomain {
+			continue
+		}
+		var fields map[string]string
+		httpCode := http.StatusInternalServerError
+		for key, value := range info.GetMetadata() {
+			switch {
+			case key == metaHTTPStatus:
+				if code, convErr := strconv.Atoi(value); convErr == nil {
+					httpCode = code
+				}
+			case strings.HasPrefix(key, metaFieldPrefix):
+				if fields == nil {
+					fields = make(map[string]string)
+				}
+				fields[strings.TrimPrefix(key, metaFieldPrefix)] = value
+			}
+		}
+		e := New(ErrorCode(info.GetReason()), st.Message(), httpCode, err)
+		e.Fields = fields
+		return e
+	}
+
+	code, httpCode := fromGRPCCode(st.Code())
+	return New(code, st.Message(), httpCode, err)
+}
+
+// UnaryServerInterceptor converts the errors returned by handlers into gRPC
+// statuses with ToStatus. It should be the innermost interceptor so that the
+// others observe the final status code.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// UnaryClientInterceptor converts the errors of calls back into application
+// errors with FromStatus. The gRPC status stays reachable through the chain of
+// the returned error, so status.Code still works on it.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromStatus(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
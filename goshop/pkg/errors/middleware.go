@@ -0,0 +1,117 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RequestIDHeader is the header carrying the ID of a request across services
+const RequestIDHeader = "X-Request-ID"
+
+// environmentProduction hides internal error details from clients
+const environmentProduction = "production"
+
+// Response is the JSON envelope of error responses
+type Response struct {
+	Code      ErrorCode         `json:"code"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	Detail    string            `json:"detail,omitempty"` // underlying error, omitted in production
+}
+
+// Translator converts errors this package does not know about, e.g. validation
+// errors, into application errors. It returns false to leave err to the defaults.
+type Translator func(c *gin.Context, err error) (*Error, bool)
+
+// middlewareOptions holds the optional settings of Middleware
+type middlewareOptions struct {
+	translators []Translator
+}
+
+// MiddlewareOption configures Middleware
+type MiddlewareOption func(*middlewareOptions)
+
+// WithTranslator adds a translator tried before the default conversions
+func WithTranslator(t Translator) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.translators = append(o.translators, t)
+	}
+}
+
+// Middleware renders the last error pushed with c.Error as a Response with the
+// HTTP status of its application error. Records not found map to 404 and other
+// errors to 500. Server errors are logged with their stack trace, and outside
+// production their details are returned to ease debugging.
+func Middleware(log *logger.Logger, environment string, opts ...MiddlewareOption) gin.HandlerFunc {
+	o := &middlewareOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		appErr := o.convert(c, err)
+
+		resp := Response{
+			Code:      appErr.Code,
+			Message:   appErr.Message,
+			Fields:    appErr.Fields,
+			RequestID: requestID(c),
+		}
+		if appErr.HTTPCode >= http.StatusInternalServerError {
+			log.Error(c.Request.Context(), "Request failed",
+				zap.String("path", c.FullPath()),
+				zap.String("request_id", resp.RequestID),
+				zap.Error(err),
+				zap.Any("values", Values(err)),
+				zap.String("stack", appErr.StackTrace()),
+			)
+			if environment == environmentProduction {
+				resp.Message = http.StatusText(appErr.HTTPCode)
+			}
+		}
+		if environment != environmentProduction {
+			resp.Detail = err.Error()
+		}
+		c.AbortWithStatusJSON(appErr.HTTPCode, resp)
+	}
+}
+
+// convert returns the application error err maps to
+func (o *middlewareOptions) convert(c *gin.Context, err error) *Error {
+	for _, t := range o.translators {
+		if appErr, ok := t(c, err); ok {
+			return appErr
+		}
+	}
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NewNotFound("记录不存在", err)
+	}
+	return NewInternalServerError("服务器内部错误", err)
+}
+
+// requestID returns the ID of the request set by the gateway
+func requestID(c *gin.Context) string {
+	if id := c.GetString("RequestID"); id != "" {
+		return id
+	}
+	if id := c.GetHeader(RequestIDHeader); id != "" {
+		return id
+	}
+	return c.Writer.Header().Get(RequestIDHeader)
+}
@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// maxStackDepth limits the frames captured for an error
+const maxStackDepth = 32
+
+// callers captures the call stack of the function creating an error
+func callers() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	// Skip runtime.Callers, callers and New
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// StackTrace returns the call stack captured where the error was created, one
+// "function\n\tfile:line" entry per frame. Frames of the constructors in this
+// package are omitted.
+func (e *Error) StackTrace() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePath+".") {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// packagePath is the import path of this package, used to hide its frames
+var packagePath = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	return name[:strings.LastIndex(name, ".")]
+}()
+
+// Format implements fmt.Formatter, %+v prints the message followed by the stack
+// trace
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, e.Error())
+		if s.Flag('+') {
+			io.WriteString(s, "\n")
+			io.WriteString(s, e.StackTrace())
+		}
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
@@ -0,0 +1,134 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Wrap annotates err with a message while keeping the code, HTTP mapping and
+// stack of the application error it wraps. Errors without an application error
+// in their chain become internal server errors. Wrap returns nil when err is nil.
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, message, nil)
+}
+
+// Wrapf is Wrap with a formatted message
+func Wrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, fmt.Sprintf(format, args...), nil)
+}
+
+// WithField attaches a diagnostic value to err, e.g. the ID of the entity being
+// processed. Values are meant for logs and are not sent to clients, see Values.
+func WithField(err error, key string, value interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, "", map[string]interface{}{key: value})
+}
+
+func wrap(err error, message string, values map[string]interface{}) *Error {
+	e := &Error{
+		Err:     err,
+		context: message,
+		values:  values,
+		wrapper: true,
+	}
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		e.Code = appErr.Code
+		e.Message = appErr.Message
+		e.Fields = appErr.Fields
+		e.HTTPCode = appErr.HTTPCode
+		e.stack = appErr.stack
+		return e
+	}
+	e.Code = ErrInternalServer
+	e.Message = message
+	if e.Message == "" {
+		e.Message = err.Error()
+	}
+	e.HTTPCode = http.StatusInternalServerError
+	e.stack = callers()
+	return e
+}
+
+// Values returns the diagnostic values attached with WithField along the chain of
+// err, values added closer to the top of the chain win
+func Values(err error) map[string]interface{} {
+	var chain []*Error
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*Error); ok && len(e.values) > 0 {
+			chain = append(chain, e)
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{})
+	for i := len(chain) - 1; i >= 0; i-- {
+		for k, v := range chain[i].values {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// CodeOf returns the code of the first application error in the chain of err,
+// ErrInternalServer when there is none
+func CodeOf(err error) ErrorCode {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ErrInternalServer
+}
+
+// HTTPStatus returns the HTTP status of the first application error in the chain
+// of err, 500 when there is none
+func HTTPStatus(err error) int {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.HTTPCode
+	}
+	return http.StatusInternalServerError
+}
+
+// RetryableError marks errors of operations that may succeed when retried, such
+// as timeouts of downstream services
+type RetryableError struct {
+	Err error
+}
+
+// Retryable marks err as retryable, it returns nil when err is nil
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether err was marked with Retryable or maps to 503
+// Service Unavailable
+func IsRetryable(err error) bool {
+	var retryable *RetryableError
+	if errors.As(err, &retryable) {
+		return true
+	}
+	return HTTPStatus(err) == http.StatusServiceUnavailable
+}
@@ -0,0 +1,208 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Handler handles one event. Returning nil acknowledges the event, returning an
+// error redelivers it later, returning an error wrapped with Permanent
+// dead-letters it right away. Handlers must be idempotent, delivery is at least
+// once.
+type Handler func(ctx context.Context, env *Envelope) error
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that the event is dead-lettered instead of redelivered,
+// e.g. when the payload is invalid
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// ConsumerConfig tunes a durable consumer
+type ConsumerConfig struct {
+	// Stream the consumed subjects belong to. When empty each subscription binds
+	// to the domain stream of its subject, created with StreamMaxAge if missing,
+	// so that one consumer can span domains.
+	Stream string
+	// StreamMaxAge is the retention of the domain streams created by Subscribe
+	StreamMaxAge time.Duration
+	// Durable is the consumer name, instances of a service sharing it share the work
+	Durable string
+	// MaxDeliver is the number of delivery attempts before an event is dead-lettered
+	MaxDeliver int
+	// AckWait is how long an event may be handled before it is redelivered
+	AckWait time.Duration
+	// Backoff is the base redelivery delay, doubled on each attempt
+	Backoff time.Duration
+	// Broadcast delivers every event to each instance instead of sharing the
+	// events between the instances, for state every instance keeps in memory.
+	// Broadcast subscriptions are ephemeral and start with the last event of
+	// each subject, Durable only names their dead letters.
+	Broadcast bool
+}
+
+// Consumer consumes events through durable JetStream consumers
+type Consumer struct {
+	js   nats.JetStreamContext
+	cfg  ConsumerConfig
+	log  *logger.Logger
+	subs []*nats.Subscription
+}
+
+// NewConsumer creates a consumer
+func NewConsumer(js nats.JetStreamContext, cfg ConsumerConfig, log *logger.Logger) *Consumer {
+	if cfg.MaxDeliver <= 0 {
+		cfg.MaxDeliver = 5
+	}
+	if cfg.AckWait <= 0 {
+		cfg.AckWait = 30 * time.Second
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	return &Consumer{
+		js:  js,
+		cfg: cfg,
+		log: log,
+	}
+}
+
+// Subscribe handles the events of eventType. Each subscription gets its own
+// durable consumer named after the consumer and the event type, so that a slow
+// event type does not hold back the others; broadcast subscriptions get an
+// ephemeral consumer per instance instead.
+func (c *Consumer) Subscribe(eventType string, handler Handler) error {
+	stream := c.cfg.Stream
+	if stream == "" {
+		if err := EnsureDomainStreams(c.js, c.cfg.StreamMaxAge, eventType); err != nil {
+			return fmt.Errorf("subscribe to %s: %w", eventType, err)
+		}
+		stream = DomainStream(eventType, c.cfg.StreamMaxAge).Name
+	}
+
+	cb := func(msg *nats.Msg) {
+		c.handle(msg, handler)
+	}
+	opts := []nats.SubOpt{
+		nats.BindStream(stream),
+		nats.ManualAck(),
+		nats.AckExplicit(),
+		nats.AckWait(c.cfg.AckWait),
+		nats.MaxDeliver(c.cfg.MaxDeliver),
+	}
+	var sub *nats.Subscription
+	var err error
+	if c.cfg.Broadcast {
+		sub, err = c.js.Subscribe(eventType, cb, append(opts, nats.DeliverLastPerSubject())...)
+	} else {
+		durable := durableName(c.cfg.Durable, eventType)
+		sub, err = c.js.QueueSubscribe(eventType, durable, cb, append(opts, nats.Durable(durable), nats.DeliverAll())...)
+	}
+	if err != nil {
+		return fmt.Errorf("subscribe to %s: %w", eventType, err)
+	}
+	c.subs = append(c.subs, sub)
+	return nil
+}
+
+// Close drains the subscriptions, letting in-flight events finish
+func (c *Consumer) Close() {
+	for _, sub := range c.subs {
+		if err := sub.Drain(); err != nil {
+			c.log.Warn(context.Background(), "Failed to drain subscription", zap.String("subject", sub.Subject), zap.Error(err))
+		}
+	}
+}
+
+func (c *Consumer) handle(msg *nats.Msg, handler Handler) {
+	var env Envelope
+	if err := json.Unmarshal(msg.Data, &env); err != nil {
+		c.deadLetter(context.Background(), msg, fmt.Errorf("decode envelope: %w", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(env.Context(context.Background()), c.cfg.AckWait)
+	defer cancel()
+
+	err := handler(ctx, &env)
+	if err == nil {
+		if err := msg.Ack(); err != nil {
+			c.log.Warn(ctx, "Failed to ack event", zap.String("event_id", env.ID), zap.Error(err))
+		}
+		return
+	}
+
+	delivered := 1
+	if meta, metaErr := msg.Metadata(); metaErr == nil && meta != nil {
+		delivered = int(meta.NumDelivered)
+	}
+	var permanent *permanentError
+	if errors.As(err, &permanent) || delivered >= c.cfg.MaxDeliver {
+		c.deadLetter(ctx, msg, err)
+		return
+	}
+
+	c.log.Warn(ctx, "Failed to handle event, will retry",
+		zap.String("event_id", env.ID),
+		zap.String("event_type", env.Type),
+		zap.Int("delivered", delivered),
+		zap.Error(err),
+	)
+	if err := msg.NakWithDelay(c.cfg.Backoff << (delivered - 1)); err != nil {
+		c.log.Warn(ctx, "Failed to nak event", zap.String("event_id", env.ID), zap.Error(err))
+	}
+}
+
+// deadLetter republishes the raw message on the dead-letter subject with the
+// failure reason in the headers, then terminates its delivery
+func (c *Consumer) deadLetter(ctx context.Context, msg *nats.Msg, cause error) {
+	c.log.Error(ctx, "Dead-lettering event",
+		zap.String("subject", msg.Subject),
+		zap.String("consumer", c.cfg.Durable),
+		zap.Error(cause),
+	)
+
+	dlq := nats.NewMsg(DeadLetterSubject(msg.Subject))
+	dlq.Data = msg.Data
+	dlq.Header.Set("Goshop-Error", cause.Error())
+	dlq.Header.Set("Goshop-Consumer", c.cfg.Durable)
+	if _, err := c.js.PublishMsg(dlq); err != nil {
+		// Leave the event to be redelivered rather than lose it
+		c.log.Error(ctx, "Failed to publish dead letter", zap.String("subject", msg.Subject), zap.Error(err))
+		if err := msg.Nak(); err != nil {
+			c.log.Warn(ctx, "Failed to nak event", zap.String("subject", msg.Subject), zap.Error(err))
+		}
+		return
+	}
+	if err := msg.Term(); err != nil {
+		c.log.Warn(ctx, "Failed to terminate event", zap.String("subject", msg.Subject), zap.Error(err))
+	}
+}
+
+// durableName derives a consumer name valid in JetStream, which forbids dots
+func durableName(durable, eventType string) string {
+	b := []byte(durable + "_" + eventType)
+	for i, ch := range b {
+		if ch == '.' || ch == '*' || ch == '>' || ch == ' ' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
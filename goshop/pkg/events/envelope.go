@@ -0,0 +1,78 @@
+// Package events is the shared event bus of the services. Events are published to
+// NATS JetStream wrapped in a versioned Envelope, either directly or through a
+// transactional outbox flushed by a Relay, and consumed by durable consumers that
+// acknowledge explicitly and move poison messages to a dead-letter subject.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/logger"
+)
+
+// Envelope wraps the data of every event published on the bus. Type is also the
+// NATS subject, Version is the schema version of Data so that consumers can keep
+// accepting older payloads while producers move on.
+type Envelope struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	Source     string          `json:"source"`
+	TraceID    string          `json:"trace_id,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// NewEnvelope wraps data into an envelope, taking the trace ID from ctx
+func NewEnvelope(ctx context.Context, source, eventType string, version int, data interface{}) (*Envelope, error) {
+	if version <= 0 {
+		version = 1
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event %s: %w", eventType, err)
+	}
+	id, err := newEventID()
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		ID:         id,
+		Type:       eventType,
+		Version:    version,
+		Source:     source,
+		TraceID:    logger.GetTraceID(ctx),
+		OccurredAt: time.Now().UTC(),
+		Data:       raw,
+	}, nil
+}
+
+// Decode unmarshals the event data into v
+func (e *Envelope) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("decode event %s v%d: %w", e.Type, e.Version, err)
+	}
+	return nil
+}
+
+// Context returns a context carrying the trace ID of the event, so that the logs
+// of a consumer can be correlated with the request that produced the event
+func (e *Envelope) Context(parent context.Context) context.Context {
+	if e.TraceID == "" {
+		return parent
+	}
+	return logger.WithTraceID(parent, e.TraceID)
+}
+
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate event id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,245 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxMessage is an event stored in the database in the same transaction as the
+// change it describes, waiting to be published by the Relay
+type OutboxMessage struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	EventID     string     `gorm:"size:32;uniqueIndex;not null" json:"event_id"`
+	Subject     string     `gorm:"size:200;not null" json:"subject"`
+	Payload     []byte     `gorm:"not null" json:"payload"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	LastError   string     `gorm:"size:500" json:"last_error,omitempty"`
+	PublishedAt *time.Time `gorm:"index" json:"published_at,omitempty"`
+	// NextAttemptAt delays the retry of an event that failed to publish, nil
+	// until the first failure
+	NextAttemptAt *time.Time `gorm:"index" json:"next_attempt_at,omitempty"`
+	// FailedAt is set when the relay gave up publishing the event after
+	// MaxAttempts, the row is kept for inspection and is not retried
+	FailedAt  *time.Time `gorm:"index" json:"failed_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName keeps the outbox table name identical in every service database
+func (OutboxMessage) TableName() string {
+	return "event_outbox"
+}
+
+// Outbox records events in the service database. Services add OutboxMessage to
+// their AutoMigrate models and run a Relay to publish the recorded events.
+type Outbox struct {
+	source string
+}
+
+// NewOutbox creates an outbox, source is the name of the publishing service
+func NewOutbox(source string) *Outbox {
+	return &Outbox{source: source}
+}
+
+// Add records an event using tx, which must be the transaction of the change the
+// event describes: the event is published if and only if the transaction commits
+func (o *Outbox) Add(ctx context.Context, tx *gorm.DB, eventType string, version int, data interface{}) error {
+	env, err := NewEnvelope(ctx, o.source, eventType, version, data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("marshal event %s: %w", eventType, err)
+	}
+	msg := &OutboxMessage{
+		EventID: env.ID,
+		Subject: env.Type,
+		Payload: payload,
+	}
+	if err := tx.WithContext(ctx).Create(msg).Error; err != nil {
+		return fmt.Errorf("store event %s: %w", eventType, err)
+	}
+	return nil
+}
+
+// RelayConfig tunes the outbox relay
+type RelayConfig struct {
+	// Interval between two polls of the outbox when it was found empty
+	Interval time.Duration
+	// BatchSize is the maximum number of events published per poll
+	BatchSize int
+	// Retention is how long published events are kept before being purged
+	Retention time.Duration
+	// MaxAttempts is the number of publish attempts before an event is given up
+	MaxAttempts int
+	// Backoff is the delay before retrying an event, doubled on each attempt up
+	// to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Relay publishes the events recorded in the outbox, oldest first. Several
+// instances of a service may run a relay: rows are locked with SKIP LOCKED so each
+// event is claimed by a single relay at a time, and the JetStream message ID makes
+// a publish retried after a crash a no-op.
+type Relay struct {
+	db        *gorm.DB
+	publisher *Publisher
+	cfg       RelayConfig
+	log       *logger.Logger
+}
+
+// NewRelay creates an outbox relay
+func NewRelay(db *gorm.DB, publisher *Publisher, cfg RelayConfig, log *logger.Logger) *Relay {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 7 * 24 * time.Hour
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Minute
+	}
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		cfg:       cfg,
+		log:       log,
+	}
+}
+
+// Run publishes outbox events until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	purge := time.NewTicker(time.Hour)
+	defer purge.Stop()
+
+	for {
+		// Keep flushing without waiting while full batches are found
+		for {
+			n, err := r.Flush(ctx)
+			if err != nil {
+				r.log.Error(ctx, "Failed to flush event outbox", zap.Error(err))
+				break
+			}
+			if n < r.cfg.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-purge.C:
+			if err := r.Purge(ctx); err != nil {
+				r.log.Error(ctx, "Failed to purge event outbox", zap.Error(err))
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush publishes one batch of due events and returns how many were claimed. An
+// event failing to publish is retried after a backoff, and given up after
+// MaxAttempts so that a poison event does not keep a slot in every batch.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	claimed := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var msgs []OutboxMessage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND failed_at IS NULL").
+			Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now).
+			Order("id").
+			Limit(r.cfg.BatchSize).
+			Find(&msgs).Error; err != nil {
+			return err
+		}
+		claimed = len(msgs)
+
+		for _, msg := range msgs {
+			attempts := msg.Attempts + 1
+			updates := map[string]interface{}{"attempts": attempts}
+			if err := r.publish(ctx, msg); err != nil {
+				updates["last_error"] = truncateError(err)
+				if attempts >= r.cfg.MaxAttempts {
+					r.log.Error(ctx, "Giving up publishing outbox event",
+						zap.String("event_id", msg.EventID),
+						zap.String("subject", msg.Subject),
+						zap.Int("attempts", attempts),
+						zap.Error(err),
+					)
+					updates["failed_at"] = now
+				} else {
+					r.log.Warn(ctx, "Failed to publish outbox event",
+						zap.String("event_id", msg.EventID),
+						zap.String("subject", msg.Subject),
+						zap.Int("attempts", attempts),
+						zap.Error(err),
+					)
+					updates["next_attempt_at"] = now.Add(r.backoff(attempts))
+				}
+			} else {
+				updates["published_at"] = now
+				updates["last_error"] = ""
+			}
+			if err := tx.Model(&OutboxMessage{}).Where("id = ?", msg.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// backoff returns the delay before the attempt following attempts
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.cfg.Backoff
+	for i := 1; i < attempts && delay < r.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.cfg.MaxBackoff {
+		delay = r.cfg.MaxBackoff
+	}
+	return delay
+}
+
+// Purge deletes the events published longer than the retention ago
+func (r *Relay) Purge(ctx context.Context) error {
+	return r.db.WithContext(ctx).
+		Where("published_at < ?", time.Now().Add(-r.cfg.Retention)).
+		Delete(&OutboxMessage{}).Error
+}
+
+func (r *Relay) publish(ctx context.Context, msg OutboxMessage) error {
+	var env Envelope
+	if err := json.Unmarshal(msg.Payload, &env); err != nil {
+		return fmt.Errorf("decode outbox event: %w", err)
+	}
+	return r.publisher.PublishEnvelope(ctx, &env)
+}
+
+func truncateError(err error) string {
+	s := err.Error()
+	if len(s) > 500 {
+		return s[:500]
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Publisher publishes events to JetStream. Use it for events that need not be
+// consistent with a database write, otherwise add them to the Outbox.
+type Publisher struct {
+	js     nats.JetStreamContext
+	source string
+}
+
+// NewPublisher creates a publisher, source is the name of the publishing service
+func NewPublisher(js nats.JetStreamContext, source string) *Publisher {
+	return &Publisher{
+		js:     js,
+		source: source,
+	}
+}
+
+// Publish wraps data into an envelope and publishes it on the eventType subject
+func (p *Publisher) Publish(ctx context.Context, eventType string, version int, data interface{}) error {
+	env, err := NewEnvelope(ctx, p.source, eventType, version, data)
+	if err != nil {
+		return err
+	}
+	return p.PublishEnvelope(ctx, env)
+}
+
+// PublishEnvelope publishes an already built envelope. The envelope ID is used as
+// the JetStream message ID, so publishing the same envelope twice within the
+// duplicate window of the stream stores it once.
+func (p *Publisher) PublishEnvelope(ctx context.Context, env *Envelope) error {
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("marshal event %s: %w", env.Type, err)
+	}
+	if _, err := p.js.Publish(env.Type, payload, nats.MsgId(env.ID), nats.Context(ctx)); err != nil {
+		return fmt.Errorf("publish event %s: %w", env.Type, err)
+	}
+	return nil
+}
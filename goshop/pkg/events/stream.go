@@ -0,0 +1,72 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// DeadLetterPrefix prefixes the subject of events that could not be handled, an
+// event of type "order.created" is dead-lettered to "dlq.order.created"
+const DeadLetterPrefix = "dlq."
+
+// DeadLetterSubject returns the dead-letter subject of an event type
+func DeadLetterSubject(eventType string) string {
+	return DeadLetterPrefix + eventType
+}
+
+// StreamConfig describes a JetStream stream owned by a service
+type StreamConfig struct {
+	// Name of the stream, e.g. "ORDERS"
+	Name string
+	// Subjects captured by the stream, e.g. "order.>"
+	Subjects []string
+	// MaxAge is how long events are retained, 0 keeps them until the limits are hit
+	MaxAge time.Duration
+	// Duplicates is the window in which events with the same ID are published only
+	// once, which makes the outbox relay safe to retry
+	Duplicates time.Duration
+}
+
+// EnsureStream creates the stream or updates its configuration when it already
+// exists. Services call it on startup for the streams they publish to.
+func EnsureStream(js nats.JetStreamContext, cfg StreamConfig) error {
+	if cfg.Duplicates == 0 {
+		cfg.Duplicates = 2 * time.Minute
+	}
+	sc := &nats.StreamConfig{
+		Name:       cfg.Name,
+		Subjects:   cfg.Subjects,
+		Retention:  nats.LimitsPolicy,
+		Storage:    nats.FileStorage,
+		MaxAge:     cfg.MaxAge,
+		Duplicates: cfg.Duplicates,
+	}
+
+	_, err := js.StreamInfo(cfg.Name)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		if _, err := js.AddStream(sc); err != nil {
+			return fmt.Errorf("create stream %s: %w", cfg.Name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get stream %s: %w", cfg.Name, err)
+	}
+	if _, err := js.UpdateStream(sc); err != nil {
+		return fmt.Errorf("update stream %s: %w", cfg.Name, err)
+	}
+	return nil
+}
+
+// EnsureDeadLetterStream creates the stream retaining the dead-lettered events of
+// all services
+func EnsureDeadLetterStream(js nats.JetStreamContext, maxAge time.Duration) error {
+	return EnsureStream(js, StreamConfig{
+		Name:     "DEAD_LETTERS",
+		Subjects: []string{DeadLetterPrefix + ">"},
+		MaxAge:   maxAge,
+	})
+}
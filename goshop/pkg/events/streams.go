@@ -0,0 +1,78 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// domainStreams names the stream capturing the events of each domain, the
+// domain of an event being the first token of its type
+var domainStreams = map[string]string{
+	"cms":       "CMS",
+	"content":   "CONTENT",
+	"coupon":    "COUPONS",
+	"currency":  "CURRENCY",
+	"fraud":     "FRAUD",
+	"inventory": "INVENTORY",
+	"loyalty":   "LOYALTY",
+	"marketing": "MARKETING",
+	"order":     "ORDERS",
+	"payment":   "PAYMENTS",
+	"product":   "PRODUCTS",
+	"promotion": "PROMOTIONS",
+	"return":    "RETURNS",
+	"review":    "REVIEWS",
+	"seller":    "SELLERS",
+	"shipment":  "SHIPMENTS",
+	"support":   "SUPPORT",
+	"traffic":   "TRAFFIC",
+	"user":      "USERS",
+}
+
+// DomainStream returns the stream capturing the events of the domain of
+// eventType, e.g. ORDERS capturing "order.>" for "order.paid"
+func DomainStream(eventType string, maxAge time.Duration) StreamConfig {
+	domain := eventType
+	if i := strings.IndexByte(eventType, '.'); i >= 0 {
+		domain = eventType[:i]
+	}
+	name, ok := domainStreams[domain]
+	if !ok {
+		name = strings.ToUpper(domain)
+	}
+	return StreamConfig{
+		Name:     name,
+		Subjects: []string{domain + ".>"},
+		MaxAge:   maxAge,
+	}
+}
+
+// EnsureDomainStreams creates the missing streams of the domains of eventTypes,
+// so that consumers can bind to them before their producers first started.
+// Existing streams are left as configured by their producer.
+func EnsureDomainStreams(js nats.JetStreamContext, maxAge time.Duration, eventTypes ...string) error {
+	seen := map[string]bool{}
+	for _, eventType := range eventTypes {
+		cfg := DomainStream(eventType, maxAge)
+		if seen[cfg.Name] {
+			continue
+		}
+		seen[cfg.Name] = true
+
+		_, err := js.StreamInfo(cfg.Name)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, nats.ErrStreamNotFound) {
+			return fmt.Errorf("get stream %s: %w", cfg.Name, err)
+		}
+		if err := EnsureStream(js, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
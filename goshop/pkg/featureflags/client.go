@@ -0,0 +1,219 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Redis keys of the flags shared by every instance
+const (
+	flagsKey      = "featureflags"         // hash of flag key to JSON Flag
+	changeChannel = "featureflags:changed" // publishes the key of changed flags
+)
+
+// defaultRefreshInterval reloads the flags periodically in case a change
+// notification was missed
+const defaultRefreshInterval = time.Minute
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithRefreshInterval sets how often flags are reloaded from Redis besides
+// change notifications, 1 minute by default
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.refreshInterval = interval
+	}
+}
+
+// Client evaluates flags from a local copy of the configured flags overridden
+// by the ones stored in Redis
+type Client struct {
+	rdb             *redis.Client
+	log             *logger.Logger
+	defaults        map[string]Flag
+	refreshInterval time.Duration
+
+	mu        sync.RWMutex
+	flags     map[string]Flag
+	listeners []func(key string)
+}
+
+// New creates a client serving the configured flags. rdb may be nil to only
+// use the configuration. Call Start to load and follow the flags of Redis.
+func New(rdb *redis.Client, flags map[string]config.FeatureFlagConfig, log *logger.Logger, opts ...Option) *Client {
+	c := &Client{
+		rdb:             rdb,
+		log:             log,
+		defaults:        FromConfig(flags),
+		refreshInterval: defaultRefreshInterval,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.flags = c.defaults
+	return c
+}
+
+// Start loads the flags stored in Redis, then follows their changes until ctx is
+// done. It fails when the first load does, later failures are logged and the
+// last known flags keep being served.
+func (c *Client) Start(ctx context.Context) error {
+	if c.rdb == nil {
+		return nil
+	}
+	if err := c.refresh(ctx); err != nil {
+		return err
+	}
+
+	pubsub := c.rdb.Subscribe(ctx, changeChannel)
+	go func() {
+		defer pubsub.Close()
+		ticker := time.NewTicker(c.refreshInterval)
+		defer ticker.Stop()
+
+		changes := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changes:
+			case <-ticker.C:
+			}
+			if err := c.refresh(ctx); err != nil && ctx.Err() == nil {
+				c.log.Warn(ctx, "Failed to refresh feature flags", zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
+
+// IsEnabled reports whether the flag key is on for target, unknown flags are off
+func (c *Client) IsEnabled(key string, target Target) bool {
+	c.mu.RLock()
+	flag, ok := c.flags[key]
+	c.mu.RUnlock()
+	return ok && flag.Evaluate(target)
+}
+
+// Flag returns the current definition of the flag key
+func (c *Client) Flag(key string) (Flag, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	flag, ok := c.flags[key]
+	return flag, ok
+}
+
+// Flags returns the current definition of every flag
+func (c *Client) Flags() []Flag {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	flags := make([]Flag, 0, len(c.flags))
+	for _, flag := range c.flags {
+		flags = append(flags, flag)
+	}
+	return flags
+}
+
+// OnChange registers fn to be called with the key of every flag that changes,
+// including the ones deleted from Redis
+func (c *Client) OnChange(fn func(key string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// Set stores flag in Redis and notifies every instance
+func (c *Client) Set(ctx context.Context, flag Flag) error {
+	if c.rdb == nil {
+		return fmt.Errorf("feature flags are not backed by Redis")
+	}
+	if flag.Rollout < 0 || flag.Rollout > 100 {
+		return fmt.Errorf("rollout of flag %s must be between 0 and 100, got %d", flag.Key, flag.Rollout)
+	}
+	raw, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	if err := c.rdb.HSet(ctx, flagsKey, flag.Key, raw).Err(); err != nil {
+		return fmt.Errorf("store flag %s: %w", flag.Key, err)
+	}
+	return c.notify(ctx, flag.Key)
+}
+
+// Delete removes the flag key from Redis, reverting it to its configured value
+func (c *Client) Delete(ctx context.Context, key string) error {
+	if c.rdb == nil {
+		return fmt.Errorf("feature flags are not backed by Redis")
+	}
+	if err := c.rdb.HDel(ctx, flagsKey, key).Err(); err != nil {
+		return fmt.Errorf("delete flag %s: %w", key, err)
+	}
+	return c.notify(ctx, key)
+}
+
+// notify applies a change locally right away and tells the other instances
+func (c *Client) notify(ctx context.Context, key string) error {
+	if err := c.refresh(ctx); err != nil {
+		return err
+	}
+	if err := c.rdb.Publish(ctx, changeChannel, key).Err(); err != nil {
+		return fmt.Errorf("publish change of flag %s: %w", key, err)
+	}
+	return nil
+}
+
+// refresh reloads the flags of Redis and calls the listeners for the changed ones
+func (c *Client) refresh(ctx context.Context) error {
+	stored, err := c.rdb.HGetAll(ctx, flagsKey).Result()
+	if err != nil {
+		return fmt.Errorf("load feature flags: %w", err)
+	}
+
+	flags := make(map[string]Flag, len(c.defaults)+len(stored))
+	for key, flag := range c.defaults {
+		flags[key] = flag
+	}
+	for key, raw := range stored {
+		var flag Flag
+		if err := json.Unmarshal([]byte(raw), &flag); err != nil {
+			c.log.Warn(ctx, "Ignoring invalid feature flag", zap.String("flag", key), zap.Error(err))
+			continue
+		}
+		flag.Key = key
+		flags[key] = flag
+	}
+
+	c.mu.Lock()
+	var changed []string
+	for key, flag := range flags {
+		if old, ok := c.flags[key]; !ok || !reflect.DeepEqual(old, flag) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range c.flags {
+		if _, ok := flags[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	c.flags = flags
+	listeners := append([]func(string){}, c.listeners...)
+	c.mu.Unlock()
+
+	for _, key := range changed {
+		c.log.Info(ctx, "Feature flag changed", zap.String("flag", key))
+		for _, fn := range listeners {
+			fn(key)
+		}
+	}
+	return nil
+}
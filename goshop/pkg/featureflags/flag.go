@@ -0,0 +1,83 @@
+// Package featureflags evaluates feature flags at runtime, so that risky
+// features can be rolled out gradually and switched off without a deploy. Flags
+// come from the service configuration and can be overridden in Redis; every
+// instance keeps a local copy refreshed on change notifications.
+package featureflags
+
+import (
+	"hash/fnv"
+
+	"github.com/yourusername/goshop/pkg/config"
+)
+
+// Flag is the definition of a feature flag
+type Flag struct {
+	Key      string   `json:"key"`
+	Enabled  bool     `json:"enabled"`
+	Rollout  int      `json:"rollout"` // percentage of users, 100 for everyone
+	Users    []string `json:"users,omitempty"`
+	Segments []string `json:"segments,omitempty"`
+}
+
+// Target is who a flag is evaluated for
+type Target struct {
+	UserID   string
+	Segments []string
+}
+
+// Evaluate reports whether the flag is on for target. A disabled flag is off for
+// everyone. An enabled flag is on for the targeted users and segments, and for
+// Rollout percent of the other users, chosen by a stable hash of the user ID so
+// that a user keeps seeing the same variant while the rollout grows. Anonymous
+// targets only get fully rolled out flags.
+func (f Flag) Evaluate(target Target) bool {
+	if !f.Enabled {
+		return false
+	}
+	if target.UserID != "" {
+		for _, user := range f.Users {
+			if user == target.UserID {
+				return true
+			}
+		}
+	}
+	for _, segment := range f.Segments {
+		for _, s := range target.Segments {
+			if s == segment {
+				return true
+			}
+		}
+	}
+	if f.Rollout >= 100 {
+		return true
+	}
+	if f.Rollout <= 0 || target.UserID == "" {
+		return false
+	}
+	return bucket(f.Key, target.UserID) < f.Rollout
+}
+
+// bucket places a user in one of 100 buckets. The flag key is part of the hash
+// so that the same users are not always the first to get every feature.
+func bucket(key, userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	h.Write([]byte{':'})
+	h.Write([]byte(userID))
+	return int(h.Sum32() % 100)
+}
+
+// FromConfig converts the flags of the service configuration
+func FromConfig(flags map[string]config.FeatureFlagConfig) map[string]Flag {
+	out := make(map[string]Flag, len(flags))
+	for key, f := range flags {
+		out[key] = Flag{
+			Key:      key,
+			Enabled:  f.Enabled,
+			Rollout:  f.Rollout,
+			Users:    f.Users,
+			Segments: f.Segments,
+		}
+	}
+	return out
+}
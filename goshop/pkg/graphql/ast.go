@@ -0,0 +1,212 @@
+package graphql
+
+// Document is a parsed executable document: operations and the fragments they
+// spread
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*FragmentDefinition
+}
+
+// Operation is a query or mutation of a document
+type Operation struct {
+	Type         string // "query" or "mutation"
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+}
+
+// VariableDefinition declares a variable of an operation
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default *Value
+}
+
+// TypeRef is a type as written in a document, e.g. [ID!]!. List types have a
+// non-nil Elem and no Name.
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+// String returns the type in GraphQL notation
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// NamedType returns the name of the innermost type
+func (t *TypeRef) NamedType() string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.Name
+}
+
+// Selection is a *FieldSelection, *FragmentSpread or *InlineFragment
+type Selection interface {
+	isSelection()
+}
+
+// FieldSelection selects a field, Alias is empty when the field is not aliased
+type FieldSelection struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	Pos          Position
+}
+
+// ResponseKey returns the key of the field in the response
+func (f *FieldSelection) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread spreads a named fragment
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+}
+
+// InlineFragment applies its selections when the object has TypeCondition, or
+// always when TypeCondition is empty
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+// FragmentDefinition is a named fragment
+type FragmentDefinition struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+}
+
+func (*FieldSelection) isSelection() {}
+func (*FragmentSpread) isSelection() {}
+func (*InlineFragment) isSelection() {}
+
+// Argument is an argument of a field or directive
+type Argument struct {
+	Name  string
+	Value *Value
+}
+
+// Directive is a directive applied to a selection or definition
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+}
+
+// Argument returns the value of the named argument, or nil
+func (d *Directive) Argument(name string) *Value {
+	for _, arg := range d.Arguments {
+		if arg.Name == name {
+			return arg.Value
+		}
+	}
+	return nil
+}
+
+// ValueKind is the kind of a literal value
+type ValueKind int
+
+// Value kinds
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+// Value is a literal or variable reference. Raw holds the variable name, the
+// unquoted string or the token text of other scalars.
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []*Value
+	Fields []*ObjectField
+}
+
+// ObjectField is a field of an input object literal
+type ObjectField struct {
+	Name  string
+	Value *Value
+}
+
+// SchemaDocument is a parsed type system document. Only the definitions a
+// subgraph publishes are supported: scalars, enums, unions and object types,
+// which may be extensions.
+type SchemaDocument struct {
+	Types []*TypeDefinition
+}
+
+// TypeDefinition kinds
+const (
+	KindScalar = "SCALAR"
+	KindObject = "OBJECT"
+	KindUnion  = "UNION"
+	KindEnum   = "ENUM"
+)
+
+// TypeDefinition defines or extends a named type
+type TypeDefinition struct {
+	Kind       string
+	Name       string
+	Extend     bool
+	Directives []*Directive
+	Fields     []*FieldDefinition // object types
+	Members    []string           // union member types
+	Values     []string           // enum values
+}
+
+// Directive returns the first directive called name, or nil
+func (t *TypeDefinition) Directive(name string) *Directive {
+	return findDirective(t.Directives, name)
+}
+
+// FieldDefinition defines a field of an object type
+type FieldDefinition struct {
+	Name       string
+	Arguments  []*InputValueDefinition
+	Type       *TypeRef
+	Directives []*Directive
+}
+
+// Directive returns the first directive called name, or nil
+func (f *FieldDefinition) Directive(name string) *Directive {
+	return findDirective(f.Directives, name)
+}
+
+// InputValueDefinition defines an argument of a field
+type InputValueDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default *Value
+}
+
+func findDirective(directives []*Directive, name string) *Directive {
+	for _, d := range directives {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
@@ -0,0 +1,730 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+)
+
+// Request is a GraphQL request as posted by clients
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is omitted when the request failed
+// before execution, e.g. on a syntax error.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Error is an error of a response. Errors raised by resolvers carry the path of
+// the field, and the code of a pkg/errors error in extensions.
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Position             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// newError converts err to a response error. Only the message of a pkg/errors
+// error is exposed, the error it wraps may hold internal details.
+func newError(err error) *Error {
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		return &Error{Message: appErr.Message, Extensions: map[string]interface{}{"code": appErr.Code}}
+	}
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return &Error{Message: err.Error(), Locations: []Position{syntaxErr.Pos}, Extensions: map[string]interface{}{"code": "GRAPHQL_PARSE_FAILED"}}
+	}
+	return &Error{Message: err.Error()}
+}
+
+// ErrorResponse returns a response reporting err without data
+func ErrorResponse(err error) *Response {
+	return &Response{Errors: []*Error{newError(err)}}
+}
+
+// OrderedMap is a JSON object that keeps its keys in insertion order, fields of
+// a response are listed in the order they were selected
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap creates an empty object
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{values: map[string]interface{}{}}
+}
+
+// Set sets key to value, keeping the position of an existing key
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get returns the value of key
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Keys returns the keys in insertion order
+func (m *OrderedMap) Keys() []string {
+	return m.keys
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Operation returns the operation called name, or the only operation of the
+// document when name is empty
+func (d *Document) Operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) != 1 {
+			return nil, fmt.Errorf("operationName is required for a document with %d operations", len(d.Operations))
+		}
+		return d.Operations[0], nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// Execute runs a request against the schema. Fields are resolved one at a time
+// in selection order; an error in a field makes it null, or its parent when the
+// field is non-null, and is reported with the field's path.
+func (s *Schema) Execute(ctx context.Context, req *Request) *Response {
+	doc, err := ParseQuery(req.Query)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+	op, err := doc.Operation(req.OperationName)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+	root := s.query
+	if op.Type == "mutation" {
+		if s.mutation == nil {
+			return ErrorResponse(errors.New("the schema has no mutations"))
+		}
+		root = s.mutation
+	}
+	vars, err := s.coerceVariables(op, req.Variables)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+
+	e := &executor{schema: s, doc: doc, vars: vars}
+	data, _ := e.executeObject(ctx, root, nil, op.SelectionSet, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+func (s *Schema) coerceVariables(op *Operation, values map[string]interface{}) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	for _, def := range op.Variables {
+		t, err := s.typeFromRef(def.Type)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := values[def.Name]
+		if !ok && def.Default != nil {
+			if value, err = ValueFromAST(def.Default, nil); err != nil {
+				return nil, err
+			}
+			ok = true
+		}
+		if !ok {
+			if def.Type.NonNull {
+				return nil, fmt.Errorf("variable $%s of type %s is required", def.Name, def.Type)
+			}
+			continue
+		}
+		if vars[def.Name], err = coerceInput(t, value); err != nil {
+			return nil, fmt.Errorf("variable $%s: %w", def.Name, err)
+		}
+	}
+	return vars, nil
+}
+
+// typeFromRef resolves a type written in a document, which must be an input type
+func (s *Schema) typeFromRef(ref *TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := s.typeFromRef(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = ListOf(elem)
+	} else {
+		switch named := s.types[ref.Name].(type) {
+		case *Scalar, *Enum:
+			t = named
+		case nil:
+			return nil, fmt.Errorf("unknown type %s", ref.Name)
+		default:
+			return nil, fmt.Errorf("type %s cannot be used as input", ref.Name)
+		}
+	}
+	if ref.NonNull {
+		t = NonNullOf(t)
+	}
+	return t, nil
+}
+
+// coerceInput converts an argument or variable value to the type
+func coerceInput(t Type, value interface{}) (interface{}, error) {
+	if nn, ok := t.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("expected non-null %s", nn.Of)
+		}
+		return coerceInput(nn.Of, value)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]interface{})
+		if !ok {
+			item, err := coerceInput(t.Of, value)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			if result[i], err = coerceInput(t.Of, item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case *Scalar:
+		if n, ok := value.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				value = i
+			} else if f, err := n.Float64(); err == nil {
+				value = f
+			}
+		}
+		return t.ParseValue(value)
+	case *Enum:
+		if s, ok := value.(string); ok && t.has(s) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("%v is not a value of %s", value, t.Name)
+	}
+	return nil, fmt.Errorf("type %s cannot be used as input", t)
+}
+
+// ValueFromAST converts a literal to the Go value it denotes, variables are
+// replaced by their values
+func ValueFromAST(v *Value, vars map[string]interface{}) (interface{}, error) {
+	switch v.Kind {
+	case VariableValue:
+		return vars[v.Raw], nil
+	case IntValue:
+		return strconv.ParseInt(v.Raw, 10, 64)
+	case FloatValue:
+		return strconv.ParseFloat(v.Raw, 64)
+	case StringValue, EnumValue:
+		return v.Raw, nil
+	case BooleanValue:
+		return v.Raw == "true", nil
+	case NullValue:
+		return nil, nil
+	case ListValue:
+		items := make([]interface{}, len(v.List))
+		for i, item := range v.List {
+			var err error
+			if items[i], err = ValueFromAST(item, vars); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case ObjectValue:
+		fields := make(map[string]interface{}, len(v.Fields))
+		for _, f := range v.Fields {
+			value, err := ValueFromAST(f.Value, vars)
+			if err != nil {
+				return nil, err
+			}
+			fields[f.Name] = value
+		}
+		return fields, nil
+	}
+	return nil, fmt.Errorf("invalid value")
+}
+
+// path is the path of a field in the response
+type path struct {
+	prev *path
+	key  interface{}
+}
+
+func (p *path) with(key interface{}) *path {
+	return &path{prev: p, key: key}
+}
+
+func (p *path) slice() []interface{} {
+	var keys []interface{}
+	for ; p != nil; p = p.prev {
+		keys = append(keys, p.key)
+	}
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return keys
+}
+
+type executor struct {
+	schema *Schema
+	doc    *Document
+	vars   map[string]interface{}
+	errors []*Error
+}
+
+func (e *executor) addError(err error, field *FieldSelection, p *path) {
+	gqlErr := newError(err)
+	result := *gqlErr
+	if field != nil {
+		result.Locations = []Position{field.Pos}
+	}
+	result.Path = p.slice()
+	e.errors = append(e.errors, &result)
+}
+
+// CollectFields groups the fields of a selection set by response key, applying
+// fragments whose type condition matches the object type and the @skip and
+// @include directives. Fields with the same response key are merged.
+func CollectFields(typeName string, selections []Selection, fragments map[string]*FragmentDefinition, vars map[string]interface{}, possibleTypes func(typeCondition string) []string) ([]string, map[string][]*FieldSelection) {
+	c := &collector{
+		typeName:      typeName,
+		fragments:     fragments,
+		vars:          vars,
+		possibleTypes: possibleTypes,
+		fields:        map[string][]*FieldSelection{},
+		visited:       map[string]bool{},
+	}
+	c.collect(selections)
+	return c.keys, c.fields
+}
+
+type collector struct {
+	typeName      string
+	fragments     map[string]*FragmentDefinition
+	vars          map[string]interface{}
+	possibleTypes func(string) []string
+	keys          []string
+	fields        map[string][]*FieldSelection
+	visited       map[string]bool
+}
+
+func (c *collector) collect(selections []Selection) {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *FieldSelection:
+			if !included(s.Directives, c.vars) {
+				continue
+			}
+			key := s.ResponseKey()
+			if _, ok := c.fields[key]; !ok {
+				c.keys = append(c.keys, key)
+			}
+			c.fields[key] = append(c.fields[key], s)
+		case *InlineFragment:
+			if included(s.Directives, c.vars) && c.applies(s.TypeCondition) {
+				c.collect(s.SelectionSet)
+			}
+		case *FragmentSpread:
+			fragment, ok := c.fragments[s.Name]
+			if !ok || c.visited[s.Name] || !included(s.Directives, c.vars) || !c.applies(fragment.TypeCondition) {
+				continue
+			}
+			c.visited[s.Name] = true
+			c.collect(fragment.SelectionSet)
+		}
+	}
+}
+
+func (c *collector) applies(typeCondition string) bool {
+	if typeCondition == "" || typeCondition == c.typeName {
+		return true
+	}
+	if c.possibleTypes == nil {
+		return false
+	}
+	for _, name := range c.possibleTypes(typeCondition) {
+		if name == c.typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// included evaluates @skip(if:) and @include(if:)
+func included(directives []*Directive, vars map[string]interface{}) bool {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		arg := d.Argument("if")
+		if arg == nil {
+			continue
+		}
+		value, _ := ValueFromAST(arg, vars)
+		if b, _ := value.(bool); b == (d.Name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *executor) possibleTypes(typeCondition string) []string {
+	union, ok := e.schema.types[typeCondition].(*Union)
+	if !ok {
+		return nil
+	}
+	names := make([]string, len(union.Types))
+	for i, member := range union.Types {
+		names[i] = member.Name
+	}
+	return names
+}
+
+// executeObject resolves the selections of an object. It returns false when a
+// non-null field is null, which makes the object null.
+func (e *executor) executeObject(ctx context.Context, obj *Object, source interface{}, selections []Selection, p *path) (*OrderedMap, bool) {
+	keys, fields := CollectFields(obj.Name, selections, e.doc.Fragments, e.vars, e.possibleTypes)
+	result := NewOrderedMap()
+	for _, key := range keys {
+		value, ok := e.executeField(ctx, obj, source, fields[key], p.with(key))
+		if !ok {
+			return nil, false
+		}
+		result.Set(key, value)
+	}
+	return result, true
+}
+
+func (e *executor) executeField(ctx context.Context, obj *Object, source interface{}, fields []*FieldSelection, p *path) (interface{}, bool) {
+	field := fields[0]
+	if field.Name == "__typename" {
+		return obj.Name, true
+	}
+	def := obj.Field(field.Name)
+	if def == nil {
+		e.addError(fmt.Errorf("cannot query field %q on type %q", field.Name, obj.Name), field, p)
+		return nil, true
+	}
+
+	value, err := e.resolve(ctx, def, source, field)
+	if err != nil {
+		e.addError(err, field, p)
+		_, nonNull := def.Type.(*NonNull)
+		return nil, !nonNull
+	}
+	var selections []Selection
+	for _, f := range fields {
+		selections = append(selections, f.SelectionSet...)
+	}
+	return e.complete(ctx, def.Type, field, selections, value, p)
+}
+
+func (e *executor) resolve(ctx context.Context, def *Field, source interface{}, field *FieldSelection) (value interface{}, err error) {
+	args, err := e.coerceArguments(def, field)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error resolving %s: %v", def.Name, r)
+		}
+	}()
+	if def.Resolve != nil {
+		return def.Resolve(ResolveParams{Context: ctx, Source: source, Args: args})
+	}
+	return DefaultResolver(source, def.Name)
+}
+
+func (e *executor) coerceArguments(def *Field, field *FieldSelection) (map[string]interface{}, error) {
+	literals := map[string]*Value{}
+	for _, arg := range field.Arguments {
+		if !hasArg(def.Args, arg.Name) {
+			return nil, fmt.Errorf("unknown argument %q on field %q", arg.Name, def.Name)
+		}
+		literals[arg.Name] = arg.Value
+	}
+
+	args := map[string]interface{}{}
+	for _, arg := range def.Args {
+		literal, ok := literals[arg.Name]
+		if ok && literal.Kind == VariableValue {
+			_, ok = e.vars[literal.Raw]
+		}
+		if !ok {
+			if arg.Default != nil {
+				args[arg.Name] = arg.Default
+				continue
+			}
+			if _, nonNull := arg.Type.(*NonNull); nonNull {
+				return nil, fmt.Errorf("argument %q of type %s is required", arg.Name, arg.Type)
+			}
+			continue
+		}
+		value, err := ValueFromAST(literal, e.vars)
+		if err != nil {
+			return nil, err
+		}
+		if args[arg.Name], err = coerceInput(arg.Type, value); err != nil {
+			return nil, fmt.Errorf("argument %q: %w", arg.Name, err)
+		}
+	}
+	return args, nil
+}
+
+func hasArg(args []*Arg, name string) bool {
+	for _, arg := range args {
+		if arg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// complete converts a resolved value to its result. Errors in nullable
+// positions are absorbed as null; false is returned when a non-null position is
+// null so that the parent becomes null instead.
+func (e *executor) complete(ctx context.Context, t Type, field *FieldSelection, selections []Selection, value interface{}, p *path) (interface{}, bool) {
+	if nn, ok := t.(*NonNull); ok {
+		result, ok := e.completeNullable(ctx, nn.Of, field, selections, value, p)
+		if !ok {
+			return nil, false
+		}
+		if result == nil {
+			e.addError(errors.New("cannot return null for non-nullable field"), field, p)
+			return nil, false
+		}
+		return result, true
+	}
+	result, ok := e.completeNullable(ctx, t, field, selections, value, p)
+	if !ok {
+		return nil, true
+	}
+	return result, true
+}
+
+func (e *executor) completeNullable(ctx context.Context, t Type, field *FieldSelection, selections []Selection, value interface{}, p *path) (interface{}, bool) {
+	if isNull(value) {
+		return nil, true
+	}
+	switch t := t.(type) {
+	case *List:
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.addError(fmt.Errorf("expected a list, got %T", value), field, p)
+			return nil, false
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, ok := e.complete(ctx, t.Of, field, selections, v.Index(i).Interface(), p.with(i))
+			if !ok {
+				return nil, false
+			}
+			items[i] = item
+		}
+		return items, true
+	case *Scalar:
+		result, err := t.Serialize(indirect(value))
+		if err != nil {
+			e.addError(err, field, p)
+			return nil, false
+		}
+		return result, true
+	case *Enum:
+		v := reflect.ValueOf(indirect(value))
+		if v.Kind() != reflect.String || !t.has(v.String()) {
+			e.addError(fmt.Errorf("%v is not a value of %s", value, t.Name), field, p)
+			return nil, false
+		}
+		return v.String(), true
+	case *Object:
+		return e.executeObject(ctx, t, value, selections, p)
+	case *Union:
+		var obj *Object
+		if ent, ok := value.(entity); ok {
+			obj, value = ent.object, ent.value
+		} else if t.ResolveType != nil {
+			obj = t.ResolveType(value)
+		}
+		if obj == nil {
+			e.addError(fmt.Errorf("cannot resolve the type of %s", t.Name), field, p)
+			return nil, false
+		}
+		return e.executeObject(ctx, obj, value, selections, p)
+	}
+	e.addError(fmt.Errorf("unsupported type %s", t), field, p)
+	return nil, false
+}
+
+// isNull reports whether a resolved value is null. Nil slices are empty lists,
+// not null, as GORM leaves unloaded associations nil.
+func isNull(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+func indirect(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// DefaultResolver resolves the field called name of source: the value of key
+// name in a map, or the struct field whose JSON name is name or name in snake
+// case, so that createdAt reads the field tagged json:"created_at"
+func DefaultResolver(source interface{}, name string) (interface{}, error) {
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !value.IsValid() {
+			return nil, nil
+		}
+		return value.Interface(), nil
+	case reflect.Struct:
+		index, ok := jsonFields(v.Type())[name]
+		if !ok {
+			index, ok = jsonFields(v.Type())[snakeCase(name)]
+		}
+		if !ok {
+			break
+		}
+		value, err := v.FieldByIndexErr(index)
+		if err != nil {
+			// nil embedded pointer
+			return nil, nil
+		}
+		return value.Interface(), nil
+	}
+	return nil, fmt.Errorf("cannot resolve field %q of %T", name, source)
+}
+
+var jsonFieldCache sync.Map // reflect.Type -> map[string][]int
+
+// jsonFields maps the JSON names of the exported fields of a struct type,
+// including promoted fields, to their indexes
+func jsonFields(t reflect.Type) map[string][]int {
+	if cached, ok := jsonFieldCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+	fields := map[string][]int{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		if _, ok := fields[name]; !ok || len(f.Index) < len(fields[name]) {
+			fields[name] = f.Index
+		}
+	}
+	jsonFieldCache.Store(t, fields)
+	return fields
+}
+
+// snakeCase converts a camel case field name to snake case, keeping acronyms
+// together: skuCode becomes sku_code and imageURL image_url
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		upper := r >= 'A' && r <= 'Z'
+		if upper && i > 0 {
+			prev := runes[i-1]
+			prevLower := prev >= 'a' && prev <= 'z' || prev >= '0' && prev <= '9'
+			nextLower := i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z'
+			if prevLower || (prev >= 'A' && prev <= 'Z' && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		if upper {
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,229 @@
+package federation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/goshop/pkg/graphql"
+)
+
+// supergraph is the composition of the subgraph schemas
+type supergraph struct {
+	types map[string]*typeDef
+	order []string
+	urls  map[string]string // subgraph name -> GraphQL endpoint
+	sdl   string
+}
+
+// typeDef is a type of the supergraph merged from its definitions in the
+// subgraphs
+type typeDef struct {
+	name       string
+	kind       string
+	keys       map[string][]string // subgraph -> key fields of the entity in that subgraph
+	fields     map[string]*fieldDef
+	fieldOrder []string
+	members    []string
+	values     []string
+}
+
+// fieldDef is a field of an object type and the subgraphs that resolve it.
+// Fields of entities and root fields have an owner, key fields and fields of
+// value types are shared by the subgraphs defining them.
+type fieldDef struct {
+	def       *graphql.FieldDefinition
+	owner     string
+	subgraphs []string
+}
+
+func (t *typeDef) isEntity() bool {
+	return len(t.keys) > 0
+}
+
+// resolvableIn reports whether subgraph resolves the field of the type
+func (t *typeDef) resolvableIn(field, subgraph string) bool {
+	f, ok := t.fields[field]
+	return ok && (f.owner == subgraph || contains(f.subgraphs, subgraph))
+}
+
+// owner returns a subgraph resolving the field
+func (t *typeDef) owner(field string) string {
+	f := t.fields[field]
+	if f.owner != "" {
+		return f.owner
+	}
+	return f.subgraphs[0]
+}
+
+// compose merges the subgraph schemas. Root and entity fields are owned by
+// exactly one subgraph; key fields and value types, which have no key, may be
+// defined by several subgraphs and are resolved by each of them.
+func compose(names []string, urls map[string]string, schemas map[string]*graphql.SchemaDocument) (*supergraph, error) {
+	sg := &supergraph{types: map[string]*typeDef{}, urls: urls}
+	for _, name := range names {
+		for _, def := range schemas[name].Types {
+			if strings.HasPrefix(def.Name, "_") {
+				continue
+			}
+			if err := sg.add(name, def); err != nil {
+				return nil, fmt.Errorf("subgraph %s: %w", name, err)
+			}
+		}
+	}
+	if err := sg.check(); err != nil {
+		return nil, err
+	}
+	sg.sdl = sg.print()
+	return sg, nil
+}
+
+func (sg *supergraph) add(subgraph string, def *graphql.TypeDefinition) error {
+	t, ok := sg.types[def.Name]
+	if !ok {
+		t = &typeDef{name: def.Name, kind: def.Kind, keys: map[string][]string{}, fields: map[string]*fieldDef{}}
+		sg.types[def.Name] = t
+		sg.order = append(sg.order, def.Name)
+	}
+	if t.kind != def.Kind {
+		return fmt.Errorf("type %s is a %s, it was defined as a %s", def.Name, def.Kind, t.kind)
+	}
+
+	switch def.Kind {
+	case graphql.KindUnion:
+		for _, member := range def.Members {
+			if !contains(t.members, member) {
+				t.members = append(t.members, member)
+			}
+		}
+	case graphql.KindEnum:
+		for _, value := range def.Values {
+			if !contains(t.values, value) {
+				t.values = append(t.values, value)
+			}
+		}
+	case graphql.KindObject:
+		var keys []string
+		if key := def.Directive("key"); key != nil {
+			fields := key.Argument("fields")
+			if fields == nil || fields.Kind != graphql.StringValue {
+				return fmt.Errorf("@key of %s needs a fields argument", def.Name)
+			}
+			keys = strings.Fields(fields.Raw)
+			t.keys[subgraph] = keys
+		}
+		for _, f := range def.Fields {
+			external := f.Directive("external") != nil
+			if external && !contains(keys, f.Name) {
+				// only key fields are copied from the owning subgraph
+				continue
+			}
+			existing, ok := t.fields[f.Name]
+			if !ok {
+				existing = &fieldDef{def: f}
+				t.fields[f.Name] = existing
+				t.fieldOrder = append(t.fieldOrder, f.Name)
+			} else if existing.def.Type.String() != f.Type.String() {
+				return fmt.Errorf("field %s.%s is %s, it was defined as %s", def.Name, f.Name, f.Type, existing.def.Type)
+			}
+			// key fields and fields of value types are resolved by every
+			// subgraph defining them, other fields by a single owner
+			if contains(keys, f.Name) || (keys == nil && def.Name != "Query") {
+				existing.subgraphs = append(existing.subgraphs, subgraph)
+				continue
+			}
+			if existing.owner != "" {
+				return fmt.Errorf("field %s.%s is already resolved by %s", def.Name, f.Name, existing.owner)
+			}
+			existing.owner = subgraph
+		}
+	}
+	return nil
+}
+
+// check verifies that every type referenced by a field is defined, that
+// entities are resolvable by their keys and that the graph has queries
+func (sg *supergraph) check() error {
+	query, ok := sg.types["Query"]
+	if !ok || len(query.fields) == 0 {
+		return fmt.Errorf("no subgraph defines a query")
+	}
+	builtin := map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+	for _, name := range sg.order {
+		t := sg.types[name]
+		for _, fieldName := range t.fieldOrder {
+			f := t.fields[fieldName]
+			if f.owner == "" && len(f.subgraphs) == 0 {
+				return fmt.Errorf("field %s.%s is not resolved by any subgraph", name, fieldName)
+			}
+			typeName := f.def.Type.NamedType()
+			if _, ok := sg.types[typeName]; !ok && !builtin[typeName] {
+				return fmt.Errorf("field %s.%s has unknown type %s", name, fieldName, typeName)
+			}
+		}
+		for subgraph, keys := range t.keys {
+			for _, key := range keys {
+				if !t.resolvableIn(key, subgraph) {
+					return fmt.Errorf("key field %s.%s is not defined in subgraph %s", name, key, subgraph)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// print returns the SDL of the composed graph, without federation directives
+func (sg *supergraph) print() string {
+	names := append([]string{}, sg.order...)
+	sort.SliceStable(names, func(i, j int) bool {
+		return names[i] == "Query" && names[j] != "Query"
+	})
+
+	var b strings.Builder
+	for _, name := range names {
+		t := sg.types[name]
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		switch t.kind {
+		case graphql.KindScalar:
+			fmt.Fprintf(&b, "scalar %s\n", name)
+		case graphql.KindUnion:
+			fmt.Fprintf(&b, "union %s = %s\n", name, strings.Join(t.members, " | "))
+		case graphql.KindEnum:
+			fmt.Fprintf(&b, "enum %s {\n", name)
+			for _, value := range t.values {
+				fmt.Fprintf(&b, "  %s\n", value)
+			}
+			b.WriteString("}\n")
+		case graphql.KindObject:
+			fmt.Fprintf(&b, "type %s {\n", name)
+			for _, fieldName := range t.fieldOrder {
+				f := t.fields[fieldName].def
+				b.WriteString("  " + f.Name)
+				if len(f.Arguments) > 0 {
+					args := make([]string, len(f.Arguments))
+					for i, arg := range f.Arguments {
+						args[i] = arg.Name + ": " + arg.Type.String()
+						if arg.Default != nil {
+							args[i] += " = " + graphql.PrintLiteral(arg.Default, nil)
+						}
+					}
+					b.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				b.WriteString(": " + f.Type.String() + "\n")
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package federation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/goshop/pkg/graphql"
+)
+
+// keyPrefix aliases the key fields and __typename the router adds to a
+// selection to build entity representations, so they never clash with the
+// fields of the client
+const keyPrefix = "_fed_"
+
+// fetch is a request to one subgraph. Root fetches select fields of Query;
+// entity fetches select fields of the entities found at path in the results
+// of their parent fetch and are sent once the parent has returned.
+type fetch struct {
+	subgraph  string
+	path      []string // response keys from the root to the entities, lists are traversed
+	typeName  string   // type of the entities
+	keys      []string // key fields of the entities in subgraph
+	selection string   // selection set sent to the subgraph
+	children  []*fetch
+
+	// fields of the entities to resolve, grouped by response key, while the
+	// fetch is being planned
+	fieldKeys []string
+	fields    map[string][]*graphql.FieldSelection
+}
+
+// planner splits an operation into fetches
+type planner struct {
+	sg   *supergraph
+	doc  *graphql.Document
+	vars map[string]interface{}
+}
+
+// plan returns the root fetches of a query, in the order of the first root
+// field each of them resolves
+func (p *planner) plan(op *graphql.Operation) ([]*fetch, error) {
+	query := p.sg.types["Query"]
+	keys, fields := p.collect(query, op.SelectionSet)
+
+	var roots []*fetch
+	bySubgraph := map[string]*fetch{}
+	for _, key := range keys {
+		field := fields[key][0]
+		if field.Name == "__typename" {
+			continue
+		}
+		if _, ok := query.fields[field.Name]; !ok {
+			return nil, fmt.Errorf("cannot query field %q on type \"Query\"", field.Name)
+		}
+		subgraph := query.owner(field.Name)
+		f, ok := bySubgraph[subgraph]
+		if !ok {
+			f = &fetch{subgraph: subgraph, fields: map[string][]*graphql.FieldSelection{}}
+			bySubgraph[subgraph] = f
+			roots = append(roots, f)
+		}
+		f.fieldKeys = append(f.fieldKeys, key)
+		f.fields[key] = fields[key]
+	}
+
+	for _, f := range roots {
+		selection, err := p.selection(f, query, f.fieldKeys, f.fields, nil)
+		if err != nil {
+			return nil, err
+		}
+		f.selection = selection
+	}
+	return roots, nil
+}
+
+// collect groups the selections on an object of type t by response key
+func (p *planner) collect(t *typeDef, selections []graphql.Selection) ([]string, map[string][]*graphql.FieldSelection) {
+	return graphql.CollectFields(t.name, selections, p.doc.Fragments, p.vars, p.possibleTypes)
+}
+
+func (p *planner) possibleTypes(typeCondition string) []string {
+	if t, ok := p.sg.types[typeCondition]; ok {
+		return t.members
+	}
+	return nil
+}
+
+// selection prints the selection set sent by fetch f for an object of type t
+// at path. Fields f's subgraph cannot resolve are planned as entity fetches of
+// the subgraphs owning them, which become children of f, and the key fields
+// those fetches need are added to the selection.
+func (p *planner) selection(f *fetch, t *typeDef, keys []string, fields map[string][]*graphql.FieldSelection, path []string) (string, error) {
+	var parts []string
+	var deps []*fetch
+	depBySubgraph := map[string]*fetch{}
+
+	for _, key := range keys {
+		field := fields[key][0]
+		if field.Name == "__typename" {
+			parts = append(parts, alias(key, "__typename"))
+			continue
+		}
+		def, ok := t.fields[field.Name]
+		if !ok {
+			return "", fmt.Errorf("cannot query field %q on type %q", field.Name, t.name)
+		}
+		if !t.resolvableIn(field.Name, f.subgraph) {
+			owner := t.owner(field.Name)
+			if _, ok := t.keys[owner]; !ok || !t.isEntity() {
+				return "", fmt.Errorf("field %s.%s cannot be resolved from subgraph %s", t.name, field.Name, f.subgraph)
+			}
+			dep, ok := depBySubgraph[owner]
+			if !ok {
+				dep = &fetch{
+					subgraph: owner,
+					path:     path,
+					typeName: t.name,
+					keys:     t.keys[owner],
+					fields:   map[string][]*graphql.FieldSelection{},
+				}
+				depBySubgraph[owner] = dep
+				deps = append(deps, dep)
+			}
+			dep.fieldKeys = append(dep.fieldKeys, key)
+			dep.fields[key] = fields[key]
+			continue
+		}
+
+		part := alias(key, field.Name) + p.arguments(field)
+		if fieldType, ok := p.sg.types[def.def.Type.NamedType()]; ok && fieldType.kind == graphql.KindObject {
+			var subSelections []graphql.Selection
+			for _, same := range fields[key] {
+				subSelections = append(subSelections, same.SelectionSet...)
+			}
+			if len(subSelections) == 0 {
+				return "", fmt.Errorf("field %s.%s of type %s must have a selection of subfields", t.name, field.Name, fieldType.name)
+			}
+			subKeys, subFields := p.collect(fieldType, subSelections)
+			sub, err := p.selection(f, fieldType, subKeys, subFields, append(append([]string{}, path...), key))
+			if err != nil {
+				return "", err
+			}
+			part += " " + sub
+		} else if ok && fieldType.kind == graphql.KindUnion {
+			return "", fmt.Errorf("field %s.%s: union types are not supported by the router", t.name, field.Name)
+		}
+		parts = append(parts, part)
+	}
+
+	if len(deps) > 0 {
+		parts = append(parts, alias(keyPrefix+"typename", "__typename"))
+		added := map[string]bool{}
+		for _, dep := range deps {
+			for _, k := range dep.keys {
+				if !t.resolvableIn(k, f.subgraph) {
+					return "", fmt.Errorf("key field %s.%s cannot be resolved from subgraph %s", t.name, k, f.subgraph)
+				}
+				if !added[k] {
+					parts = append(parts, alias(keyPrefix+k, k))
+					added[k] = true
+				}
+			}
+			selection, err := p.selection(dep, t, dep.fieldKeys, dep.fields, path)
+			if err != nil {
+				return "", err
+			}
+			dep.selection = selection
+			f.children = append(f.children, dep)
+		}
+	}
+	return "{ " + strings.Join(parts, " ") + " }", nil
+}
+
+// arguments prints the arguments of a field with variables replaced by their
+// values, subgraph requests carry no variables of the client
+func (p *planner) arguments(field *graphql.FieldSelection) string {
+	if len(field.Arguments) == 0 {
+		return ""
+	}
+	args := make([]string, 0, len(field.Arguments))
+	for _, arg := range field.Arguments {
+		if arg.Value.Kind == graphql.VariableValue {
+			if _, ok := p.vars[arg.Value.Raw]; !ok {
+				// an omitted variable leaves the argument unset
+				continue
+			}
+		}
+		args = append(args, arg.Name+": "+graphql.PrintLiteral(arg.Value, p.vars))
+	}
+	if len(args) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(args, ", ") + ")"
+}
+
+func alias(key, name string) string {
+	if key == name {
+		return name
+	}
+	return key + ": " + name
+}
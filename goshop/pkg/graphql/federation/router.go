@@ -0,0 +1,491 @@
+// Package federation composes GraphQL subgraphs into a single graph. The
+// Router loads the SDL every subgraph publishes through _service, merges the
+// types and answers client queries by sending each subgraph the part of the
+// query it resolves. Fields of an entity owned by another subgraph are fetched
+// from it with _entities, using the key fields the first subgraph returned,
+// so a query can join orders to the products of their items or users to
+// their orders in one request.
+package federation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/graphql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// maxResponseSize bounds the body read from a subgraph
+const maxResponseSize = 10 << 20
+
+// Subgraph is a service serving a subgraph at URL
+type Subgraph struct {
+	Name string
+	URL  string
+}
+
+// DefaultHeaders are the headers of the client request forwarded to subgraphs
+var DefaultHeaders = []string{"Authorization", "X-User-ID", "X-Trace-ID", "Accept-Language"}
+
+// Option configures a Router
+type Option func(*Router)
+
+// WithTimeout bounds each subgraph request, 10 seconds by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *Router) {
+		r.client.Timeout = timeout
+	}
+}
+
+// WithHeaders replaces the headers forwarded to subgraphs
+func WithHeaders(headers ...string) Option {
+	return func(r *Router) {
+		r.headers = headers
+	}
+}
+
+// Router executes queries against the composed graph of its subgraphs. It
+// implements graphql.Executor and is served with graphql.Handler.
+type Router struct {
+	subgraphs []Subgraph
+	client    *http.Client
+	headers   []string
+
+	mu    sync.RWMutex
+	graph *supergraph
+}
+
+// New creates a router of the subgraphs, Load must succeed before it answers
+// queries
+func New(subgraphs []Subgraph, opts ...Option) *Router {
+	r := &Router{
+		subgraphs: subgraphs,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		headers:   DefaultHeaders,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Load fetches the SDL of every subgraph and replaces the composed graph. The
+// previous graph is kept when a subgraph cannot be reached or the schemas do
+// not compose.
+func (r *Router) Load(ctx context.Context) error {
+	names := make([]string, len(r.subgraphs))
+	urls := map[string]string{}
+	schemas := map[string]*graphql.SchemaDocument{}
+	for i, subgraph := range r.subgraphs {
+		names[i] = subgraph.Name
+		urls[subgraph.Name] = subgraph.URL
+
+		resp, err := r.send(ctx, subgraph.URL, &graphql.Request{Query: "{ _service { sdl } }"}, nil)
+		if err != nil {
+			return fmt.Errorf("subgraph %s: %w", subgraph.Name, err)
+		}
+		if len(resp.Errors) > 0 {
+			return fmt.Errorf("subgraph %s: %s", subgraph.Name, resp.Errors[0].Message)
+		}
+		var data struct {
+			Service struct {
+				SDL string `json:"sdl"`
+			} `json:"_service"`
+		}
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			return fmt.Errorf("subgraph %s: %w", subgraph.Name, err)
+		}
+		if schemas[subgraph.Name], err = graphql.ParseSchema(data.Service.SDL); err != nil {
+			return fmt.Errorf("subgraph %s: %w", subgraph.Name, err)
+		}
+	}
+
+	graph, err := compose(names, urls, schemas)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.graph = graph
+	r.mu.Unlock()
+	return nil
+}
+
+// Run loads the graph and reloads it every interval until ctx is cancelled,
+// so that subgraphs can be deployed with new fields without restarting the
+// router. Failed loads are passed to onError.
+func (r *Router) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Load(ctx); err != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SDL returns the schema of the composed graph, empty before the first load
+func (r *Router) SDL() string {
+	graph := r.current()
+	if graph == nil {
+		return ""
+	}
+	return graph.sdl
+}
+
+func (r *Router) current() *supergraph {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.graph
+}
+
+// Execute plans a query and runs the fetches. Root fetches run in parallel,
+// the entity fetches depending on each of them run in order once it has
+// returned. Mutations are not supported.
+func (r *Router) Execute(ctx context.Context, req *graphql.Request) *graphql.Response {
+	graph := r.current()
+	if graph == nil {
+		return graphql.ErrorResponse(errors.New("the graph is not loaded yet"))
+	}
+	doc, err := graphql.ParseQuery(req.Query)
+	if err != nil {
+		return graphql.ErrorResponse(err)
+	}
+	op, err := doc.Operation(req.OperationName)
+	if err != nil {
+		return graphql.ErrorResponse(err)
+	}
+	if op.Type != "query" {
+		return graphql.ErrorResponse(errors.New("the router only supports queries"))
+	}
+	vars, err := variables(op, req.Variables)
+	if err != nil {
+		return graphql.ErrorResponse(err)
+	}
+	p := &planner{sg: graph, doc: doc, vars: vars}
+	roots, err := p.plan(op)
+	if err != nil {
+		return graphql.ErrorResponse(err)
+	}
+
+	header := forwardedHeader(graphql.Header(ctx), r.headers)
+	results := make([]*result, len(roots))
+	var wg sync.WaitGroup
+	for i, f := range roots {
+		wg.Add(1)
+		go func(i int, f *fetch) {
+			defer wg.Done()
+			results[i] = r.runRoot(ctx, graph, f, header)
+		}(i, f)
+	}
+	wg.Wait()
+
+	data := map[string]interface{}{}
+	var errs []*graphql.Error
+	for _, res := range results {
+		for key, value := range res.data {
+			data[key] = value
+		}
+		errs = append(errs, res.errors...)
+	}
+	return &graphql.Response{Data: p.shape(graph.types["Query"], op.SelectionSet, data), Errors: errs}
+}
+
+// variables applies the defaults of the variable definitions
+func variables(op *graphql.Operation, values map[string]interface{}) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	for key, value := range values {
+		vars[key] = value
+	}
+	for _, def := range op.Variables {
+		if _, ok := vars[def.Name]; ok || def.Default == nil {
+			continue
+		}
+		value, err := graphql.ValueFromAST(def.Default, nil)
+		if err != nil {
+			return nil, err
+		}
+		vars[def.Name] = value
+	}
+	return vars, nil
+}
+
+func forwardedHeader(from http.Header, names []string) http.Header {
+	header := http.Header{}
+	for _, name := range names {
+		if value := from.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	return header
+}
+
+// result is the data and errors of a root fetch and its entity fetches
+type result struct {
+	data   map[string]interface{}
+	errors []*graphql.Error
+}
+
+func (r *Router) runRoot(ctx context.Context, graph *supergraph, f *fetch, header http.Header) *result {
+	res := &result{data: map[string]interface{}{}}
+	resp, err := r.send(ctx, graph.urls[f.subgraph], &graphql.Request{Query: "query " + f.selection}, header)
+	if err != nil {
+		for _, key := range f.fieldKeys {
+			res.errors = append(res.errors, &graphql.Error{Message: fmt.Sprintf("subgraph %s: %v", f.subgraph, err), Path: []interface{}{key}})
+		}
+		return res
+	}
+	res.errors = append(res.errors, resp.Errors...)
+	if len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := decode(resp.Data, &res.data); err != nil {
+			res.errors = append(res.errors, &graphql.Error{Message: fmt.Sprintf("subgraph %s: %v", f.subgraph, err)})
+			return res
+		}
+	}
+	for _, child := range f.children {
+		r.runEntities(ctx, graph, child, res, header)
+	}
+	return res
+}
+
+// target is an entity found in the results and its path in the response
+type target struct {
+	object map[string]interface{}
+	path   []interface{}
+}
+
+// runEntities resolves the fields of fetch f for the entities at its path and
+// merges them into the entities, then runs the fetches depending on it. Like a
+// dataloader, the entities are fetched in a single request and an entity found
+// several times, e.g. a product in many orders, is requested once.
+func (r *Router) runEntities(ctx context.Context, graph *supergraph, f *fetch, res *result, header http.Header) {
+	var targets []target
+	collectTargets(res.data, f.path, nil, &targets)
+
+	var reps []interface{}
+	var resolved []target // first entity of each representation
+	var merged [][]target // every entity of each representation
+	seen := map[string]int{}
+	for _, t := range targets {
+		rep := map[string]interface{}{"__typename": f.typeName}
+		complete := true
+		for _, key := range f.keys {
+			value, ok := t.object[keyPrefix+key]
+			if !ok || value == nil {
+				complete = false
+				break
+			}
+			rep[key] = value
+		}
+		if typename, _ := t.object[keyPrefix+"typename"].(string); typename != f.typeName || !complete {
+			continue
+		}
+		id := representationKey(rep, f.keys)
+		if i, ok := seen[id]; ok {
+			merged[i] = append(merged[i], t)
+			continue
+		}
+		seen[id] = len(reps)
+		reps = append(reps, rep)
+		resolved = append(resolved, t)
+		merged = append(merged, []target{t})
+	}
+	if len(reps) == 0 {
+		return
+	}
+
+	query := fmt.Sprintf("query($representations: [_Any!]!) { _entities(representations: $representations) { ... on %s %s } }", f.typeName, f.selection)
+	resp, err := r.send(ctx, graph.urls[f.subgraph], &graphql.Request{
+		Query:     query,
+		Variables: map[string]interface{}{"representations": reps},
+	}, header)
+	if err != nil {
+		res.errors = append(res.errors, &graphql.Error{Message: fmt.Sprintf("subgraph %s: %v", f.subgraph, err), Path: resolved[0].path})
+		return
+	}
+
+	// paths of entity errors start with _entities and the index of the
+	// representation, they are rewritten to the path of the entity
+	for _, e := range resp.Errors {
+		if len(e.Path) >= 2 && e.Path[0] == "_entities" {
+			if i, ok := pathIndex(e.Path[1]); ok && i < len(resolved) {
+				e.Path = append(append([]interface{}{}, resolved[i].path...), e.Path[2:]...)
+			}
+		}
+		res.errors = append(res.errors, e)
+	}
+	var data struct {
+		Entities []map[string]interface{} `json:"_entities"`
+	}
+	if len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := decode(resp.Data, &data); err != nil {
+			res.errors = append(res.errors, &graphql.Error{Message: fmt.Sprintf("subgraph %s: %v", f.subgraph, err)})
+			return
+		}
+	}
+	for i, entity := range data.Entities {
+		if i >= len(merged) {
+			break
+		}
+		for _, t := range merged[i] {
+			for key, value := range entity {
+				t.object[key] = value
+			}
+		}
+	}
+
+	for _, child := range f.children {
+		r.runEntities(ctx, graph, child, res, header)
+	}
+}
+
+// representationKey identifies the entity of a representation by its key fields
+func representationKey(rep map[string]interface{}, keys []string) string {
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%v\x00", rep[key])
+	}
+	return b.String()
+}
+
+// collectTargets finds the objects at path below value, traversing lists
+func collectTargets(value interface{}, path []string, at []interface{}, targets *[]target) {
+	switch v := value.(type) {
+	case []interface{}:
+		for i, item := range v {
+			collectTargets(item, path, appendPath(at, i), targets)
+		}
+	case map[string]interface{}:
+		if len(path) == 0 {
+			*targets = append(*targets, target{object: v, path: at})
+			return
+		}
+		collectTargets(v[path[0]], path[1:], appendPath(at, path[0]), targets)
+	}
+}
+
+func appendPath(path []interface{}, key interface{}) []interface{} {
+	return append(append(make([]interface{}, 0, len(path)+1), path...), key)
+}
+
+func pathIndex(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+// shape builds the response of the client from the merged results: only the
+// fields the client selected, in selection order, with the fields the router
+// added for entity fetches removed and missing fields null
+func (p *planner) shape(t *typeDef, selections []graphql.Selection, object map[string]interface{}) *graphql.OrderedMap {
+	keys, fields := p.collect(t, selections)
+	result := graphql.NewOrderedMap()
+	for _, key := range keys {
+		field := fields[key][0]
+		value := object[key]
+		if field.Name == "__typename" {
+			if value == nil {
+				value = t.name
+			}
+			result.Set(key, value)
+			continue
+		}
+		def, ok := t.fields[field.Name]
+		if !ok {
+			result.Set(key, nil)
+			continue
+		}
+		fieldType, ok := p.sg.types[def.def.Type.NamedType()]
+		if !ok || fieldType.kind != graphql.KindObject {
+			result.Set(key, value)
+			continue
+		}
+		var subSelections []graphql.Selection
+		for _, same := range fields[key] {
+			subSelections = append(subSelections, same.SelectionSet...)
+		}
+		result.Set(key, p.shapeValue(fieldType, subSelections, value))
+	}
+	return result
+}
+
+func (p *planner) shapeValue(t *typeDef, selections []graphql.Selection, value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = p.shapeValue(t, selections, item)
+		}
+		return items
+	case map[string]interface{}:
+		return p.shape(t, selections, v)
+	}
+	return nil
+}
+
+// subgraphResponse is a response of a subgraph, data is decoded once the
+// router knows its shape
+type subgraphResponse struct {
+	Data   json.RawMessage  `json:"data"`
+	Errors []*graphql.Error `json:"errors"`
+}
+
+func (r *Router) send(ctx context.Context, url string, req *graphql.Request, header http.Header) (*subgraphResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+
+	httpResp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", httpResp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	var resp subgraphResponse
+	if err := decode(raw, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// decode unmarshals JSON keeping numbers as json.Number, so that IDs and
+// amounts pass through the router unchanged
+func decode(raw []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
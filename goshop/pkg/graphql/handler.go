@@ -0,0 +1,77 @@
+// Package graphql is a small GraphQL implementation for exposing services as
+// subgraphs of a federated graph. Schemas are defined in Go with Object, Field
+// and the built-in scalars; fields without a resolver read the JSON-tagged
+// struct field of the same name in snake case, so models can be returned as
+// they are. Queries and mutations, fragments, variables and the @skip and
+// @include directives are supported; interfaces, input objects, subscriptions
+// and introspection are not, the schema is published as SDL instead.
+//
+// NewSubgraph adds the _service and _entities fields of Apollo Federation v1,
+// and package federation composes subgraphs into a single graph.
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type headerKey struct{}
+
+// WithHeader returns a context carrying the headers of the HTTP request being
+// served, resolvers read the caller's identity from them
+func WithHeader(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, headerKey{}, header)
+}
+
+// Header returns the headers of the HTTP request being served
+func Header(ctx context.Context) http.Header {
+	if header, ok := ctx.Value(headerKey{}).(http.Header); ok {
+		return header
+	}
+	return http.Header{}
+}
+
+// Executor executes GraphQL requests, *Schema and federation.Router implement it
+type Executor interface {
+	Execute(ctx context.Context, req *Request) *Response
+}
+
+// Handler serves GraphQL over HTTP: POST with a JSON request body, or GET with
+// query, operationName and variables in the query string. GET requests may
+// only run queries. Responses are 200 with errors in the body unless the
+// request could not be read.
+func Handler(executor Executor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req Request
+		switch c.Request.Method {
+		case http.MethodGet:
+			req.Query = c.Query("query")
+			req.OperationName = c.Query("operationName")
+			if raw := c.Query("variables"); raw != "" {
+				if err := json.Unmarshal([]byte(raw), &req.Variables); err != nil {
+					c.JSON(http.StatusBadRequest, ErrorResponse(err))
+					return
+				}
+			}
+			if doc, err := ParseQuery(req.Query); err == nil {
+				if op, err := doc.Operation(req.OperationName); err == nil && op.Type != "query" {
+					c.JSON(http.StatusMethodNotAllowed, ErrorResponse(errGetMutation))
+					return
+				}
+			}
+		default:
+			if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse(err))
+				return
+			}
+		}
+
+		ctx := WithHeader(c.Request.Context(), c.Request.Header)
+		c.JSON(http.StatusOK, executor.Execute(ctx, &req))
+	}
+}
+
+var errGetMutation = &Error{Message: "mutations must be sent with POST"}
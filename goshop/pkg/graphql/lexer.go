@@ -0,0 +1,277 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position is a location in a document, both counted from 1
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string // the punctuator, name, number text or unescaped string
+	pos   Position
+}
+
+// SyntaxError reports a malformed document
+type SyntaxError struct {
+	Message string
+	Pos     Position
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", e.Pos.Line, e.Pos.Column, e.Message)
+}
+
+type lexer struct {
+	src  string
+	i    int
+	line int
+	col  int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1, col: 1}
+}
+
+func (l *lexer) errorf(pos Position, format string, args ...interface{}) error {
+	return &SyntaxError{Message: fmt.Sprintf(format, args...), Pos: pos}
+}
+
+func (l *lexer) advance(n int) {
+	for _, r := range l.src[l.i : l.i+n] {
+		if r == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+	}
+	l.i += n
+}
+
+// skipIgnored skips whitespace, commas and comments
+func (l *lexer) skipIgnored() {
+	for l.i < len(l.src) {
+		switch c := l.src[l.i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.advance(1)
+		case c == '#':
+			for l.i < len(l.src) && l.src[l.i] != '\n' {
+				l.advance(1)
+			}
+		case strings.HasPrefix(l.src[l.i:], "\uFEFF"):
+			l.advance(len("\uFEFF"))
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	pos := Position{Line: l.line, Column: l.col}
+	if l.i >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+
+	c := l.src[l.i]
+	switch {
+	case strings.HasPrefix(l.src[l.i:], "..."):
+		l.advance(3)
+		return token{kind: tokenPunct, value: "...", pos: pos}, nil
+	case strings.IndexByte("!$&()/:=@[]{|}", c) >= 0:
+		l.advance(1)
+		return token{kind: tokenPunct, value: string(c), pos: pos}, nil
+	case c == '_' || isLetter(c):
+		start := l.i
+		for l.i < len(l.src) && (l.src[l.i] == '_' || isLetter(l.src[l.i]) || isDigit(l.src[l.i])) {
+			l.advance(1)
+		}
+		return token{kind: tokenName, value: l.src[start:l.i], pos: pos}, nil
+	case c == '-' || isDigit(c):
+		return l.number(pos)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.i:], `"""`) {
+			return l.blockString(pos)
+		}
+		return l.string(pos)
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.i:])
+	return token{}, l.errorf(pos, "unexpected character %q", r)
+}
+
+func (l *lexer) number(pos Position) (token, error) {
+	start := l.i
+	float := false
+	if l.src[l.i] == '-' {
+		l.advance(1)
+	}
+	digits := l.digits()
+	if digits == 0 {
+		return token{}, l.errorf(pos, "invalid number")
+	}
+	if l.i < len(l.src) && l.src[l.i] == '.' {
+		float = true
+		l.advance(1)
+		if l.digits() == 0 {
+			return token{}, l.errorf(pos, "invalid number")
+		}
+	}
+	if l.i < len(l.src) && (l.src[l.i] == 'e' || l.src[l.i] == 'E') {
+		float = true
+		l.advance(1)
+		if l.i < len(l.src) && (l.src[l.i] == '+' || l.src[l.i] == '-') {
+			l.advance(1)
+		}
+		if l.digits() == 0 {
+			return token{}, l.errorf(pos, "invalid number")
+		}
+	}
+	if l.i < len(l.src) && (l.src[l.i] == '_' || l.src[l.i] == '.' || isLetter(l.src[l.i])) {
+		return token{}, l.errorf(pos, "invalid number")
+	}
+	kind := tokenInt
+	if float {
+		kind = tokenFloat
+	}
+	return token{kind: kind, value: l.src[start:l.i], pos: pos}, nil
+}
+
+func (l *lexer) digits() int {
+	n := 0
+	for l.i < len(l.src) && isDigit(l.src[l.i]) {
+		l.advance(1)
+		n++
+	}
+	return n
+}
+
+func (l *lexer) string(pos Position) (token, error) {
+	l.advance(1)
+	var b strings.Builder
+	for {
+		if l.i >= len(l.src) || l.src[l.i] == '\n' || l.src[l.i] == '\r' {
+			return token{}, l.errorf(pos, "unterminated string")
+		}
+		c := l.src[l.i]
+		switch c {
+		case '"':
+			l.advance(1)
+			return token{kind: tokenString, value: b.String(), pos: pos}, nil
+		case '\\':
+			if l.i+1 >= len(l.src) {
+				return token{}, l.errorf(pos, "unterminated string")
+			}
+			esc := l.src[l.i+1]
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.i+6 > len(l.src) {
+					return token{}, l.errorf(pos, "invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.src[l.i+2:l.i+6], 16, 32)
+				if err != nil {
+					return token{}, l.errorf(pos, "invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				l.advance(4)
+			default:
+				return token{}, l.errorf(pos, "invalid escape \\%c", esc)
+			}
+			l.advance(2)
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.i:])
+			b.WriteRune(r)
+			l.advance(size)
+		}
+	}
+}
+
+// blockString reads a """ string, removing the common indentation and the
+// leading and trailing blank lines
+func (l *lexer) blockString(pos Position) (token, error) {
+	l.advance(3)
+	start := l.i
+	for {
+		if l.i >= len(l.src) {
+			return token{}, l.errorf(pos, "unterminated block string")
+		}
+		if strings.HasPrefix(l.src[l.i:], `\"""`) {
+			l.advance(4)
+			continue
+		}
+		if strings.HasPrefix(l.src[l.i:], `"""`) {
+			raw := strings.ReplaceAll(l.src[start:l.i], `\"""`, `"""`)
+			l.advance(3)
+			return token{kind: tokenString, value: blockStringValue(raw), pos: pos}, nil
+		}
+		l.advance(1)
+	}
+}
+
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,709 @@
+package graphql
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func newParser(src string) (*parser, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return p.lex.errorf(p.tok.pos, format, args...)
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) peekName(name string) bool {
+	return p.tok.kind == tokenName && p.tok.value == name
+}
+
+// skip consumes the punctuator if it is next and reports whether it was
+func (p *parser) skip(punct string) (bool, error) {
+	if !p.peek(punct) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return p.errorf("expected %q, found %s", punct, p.describe())
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.errorf("expected name, found %s", p.describe())
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) keyword(name string) error {
+	if !p.peekName(name) {
+		return p.errorf("expected %q, found %s", name, p.describe())
+	}
+	return p.advance()
+}
+
+func (p *parser) describe() string {
+	switch p.tok.kind {
+	case tokenEOF:
+		return "end of document"
+	case tokenString:
+		return "string"
+	default:
+		return "\"" + p.tok.value + "\""
+	}
+}
+
+// ParseQuery parses an executable document and rejects fragment cycles,
+// undefined fragments and selections nested deeper than MaxDepth
+func ParseQuery(src string) (*Document, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{Fragments: map[string]*FragmentDefinition{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", SelectionSet: selections})
+		case p.peekName("query") || p.peekName("mutation"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.peekName("fragment"):
+			fragment, err := p.fragmentDefinition()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.Fragments[fragment.Name]; ok {
+				return nil, p.errorf("fragment %s is defined more than once", fragment.Name)
+			}
+			doc.Fragments[fragment.Name] = fragment
+		default:
+			return nil, p.errorf("unexpected %s", p.describe())
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, p.errorf("document has no operation")
+	}
+	if err := validate(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Type: p.tok.value}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.Name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(")") {
+			def, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.Variables = append(op.Variables, def)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.SelectionSet = selections
+	return op, nil
+}
+
+func (p *parser) variableDefinition() (*VariableDefinition, error) {
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	typ, err := p.typeRef()
+	if err != nil {
+		return nil, err
+	}
+	def := &VariableDefinition{Name: name, Type: typ}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		if def.Default, err = p.value(true); err != nil {
+			return nil, err
+		}
+	}
+	return def, nil
+}
+
+func (p *parser) fragmentDefinition() (*FragmentDefinition, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, p.errorf("invalid fragment name \"on\"")
+	}
+	if err := p.keyword("on"); err != nil {
+		return nil, err
+	}
+	typeCondition, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &FragmentDefinition{Name: name, TypeCondition: typeCondition, SelectionSet: selections}, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []Selection
+	for !p.peek("}") {
+		selection, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, p.errorf("empty selection set")
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) selection() (Selection, error) {
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragment()
+	}
+
+	pos := p.tok.pos
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	field := &FieldSelection{Name: name, Pos: pos}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		field.Alias = name
+		if field.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if field.Arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if field.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if field.SelectionSet, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *parser) fragment() (Selection, error) {
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &FragmentSpread{Name: p.tok.value}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		spread.Directives, err = p.directives()
+		return spread, err
+	}
+
+	fragment := &InlineFragment{}
+	if p.peekName("on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if fragment.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if fragment.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if fragment.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*Argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+	var args []*Argument
+	for !p.peek(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.value(constant)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, &Argument{Name: name, Value: value})
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.arguments(false)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &Directive{Name: name, Arguments: args})
+	}
+	return directives, nil
+}
+
+func (p *parser) typeRef() (*TypeRef, error) {
+	var typ *TypeRef
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		typ = &TypeRef{Elem: elem}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		typ = &TypeRef{Name: name}
+	}
+	if ok, err := p.skip("!"); err != nil {
+		return nil, err
+	} else if ok {
+		typ.NonNull = true
+	}
+	return typ, nil
+}
+
+// value parses a value literal, variables are rejected when constant is set
+func (p *parser) value(constant bool) (*Value, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		return &Value{Kind: IntValue, Raw: tok.value}, p.advance()
+	case tokenFloat:
+		return &Value{Kind: FloatValue, Raw: tok.value}, p.advance()
+	case tokenString:
+		return &Value{Kind: StringValue, Raw: tok.value}, p.advance()
+	case tokenName:
+		value := &Value{Kind: EnumValue, Raw: tok.value}
+		switch tok.value {
+		case "true", "false":
+			value.Kind = BooleanValue
+		case "null":
+			value.Kind = NullValue
+		}
+		return value, p.advance()
+	}
+
+	switch {
+	case p.peek("$") && !constant:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		return &Value{Kind: VariableValue, Raw: name}, nil
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		value := &Value{Kind: ListValue}
+		for !p.peek("]") {
+			item, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			value.List = append(value.List, item)
+		}
+		return value, p.advance()
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		value := &Value{Kind: ObjectValue}
+		for !p.peek("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			item, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			value.Fields = append(value.Fields, &ObjectField{Name: name, Value: item})
+		}
+		return value, p.advance()
+	}
+	return nil, p.errorf("unexpected %s", p.describe())
+}
+
+// ParseSchema parses a type system document such as the SDL a subgraph
+// publishes. Descriptions are accepted and discarded, directive definitions and
+// schema definitions are skipped.
+func ParseSchema(src string) (*SchemaDocument, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+	doc := &SchemaDocument{}
+	for p.tok.kind != tokenEOF {
+		if err := p.description(); err != nil {
+			return nil, err
+		}
+		extend := false
+		if p.peekName("extend") {
+			extend = true
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		if p.tok.kind != tokenName {
+			return nil, p.errorf("unexpected %s", p.describe())
+		}
+
+		var def *TypeDefinition
+		switch p.tok.value {
+		case "scalar":
+			def, err = p.namedDefinition(KindScalar)
+		case "type":
+			def, err = p.objectDefinition()
+		case "union":
+			def, err = p.unionDefinition()
+		case "enum":
+			def, err = p.enumDefinition()
+		case "directive":
+			err = p.skipDirectiveDefinition()
+		case "schema":
+			err = p.skipSchemaDefinition()
+		default:
+			err = p.errorf("unsupported definition %s", p.describe())
+		}
+		if err != nil {
+			return nil, err
+		}
+		if def != nil {
+			def.Extend = extend
+			doc.Types = append(doc.Types, def)
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) description() error {
+	if p.tok.kind == tokenString {
+		return p.advance()
+	}
+	return nil
+}
+
+// namedDefinition parses the keyword, name and directives shared by all
+// definitions
+func (p *parser) namedDefinition(kind string) (*TypeDefinition, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	directives, err := p.directives()
+	if err != nil {
+		return nil, err
+	}
+	return &TypeDefinition{Kind: kind, Name: name, Directives: directives}, nil
+}
+
+func (p *parser) objectDefinition() (*TypeDefinition, error) {
+	def, err := p.namedDefinition(KindObject)
+	if err != nil {
+		return nil, err
+	}
+	if p.peekName("implements") {
+		return nil, p.errorf("interfaces are not supported")
+	}
+	if ok, err := p.skip("{"); err != nil || !ok {
+		return def, err
+	}
+	for !p.peek("}") {
+		field, err := p.fieldDefinition()
+		if err != nil {
+			return nil, err
+		}
+		def.Fields = append(def.Fields, field)
+	}
+	return def, p.advance()
+}
+
+func (p *parser) fieldDefinition() (*FieldDefinition, error) {
+	if err := p.description(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	field := &FieldDefinition{Name: name}
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(")") {
+			arg, err := p.inputValueDefinition()
+			if err != nil {
+				return nil, err
+			}
+			field.Arguments = append(field.Arguments, arg)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if field.Type, err = p.typeRef(); err != nil {
+		return nil, err
+	}
+	if field.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func (p *parser) inputValueDefinition() (*InputValueDefinition, error) {
+	if err := p.description(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	typ, err := p.typeRef()
+	if err != nil {
+		return nil, err
+	}
+	def := &InputValueDefinition{Name: name, Type: typ}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		if def.Default, err = p.value(true); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (p *parser) unionDefinition() (*TypeDefinition, error) {
+	def, err := p.namedDefinition(KindUnion)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip("="); err != nil || !ok {
+		return def, err
+	}
+	if _, err := p.skip("|"); err != nil {
+		return nil, err
+	}
+	for {
+		member, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		def.Members = append(def.Members, member)
+		if ok, err := p.skip("|"); err != nil {
+			return nil, err
+		} else if !ok {
+			return def, nil
+		}
+	}
+}
+
+func (p *parser) enumDefinition() (*TypeDefinition, error) {
+	def, err := p.namedDefinition(KindEnum)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip("{"); err != nil || !ok {
+		return def, err
+	}
+	for !p.peek("}") {
+		if err := p.description(); err != nil {
+			return nil, err
+		}
+		value, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.directives(); err != nil {
+			return nil, err
+		}
+		def.Values = append(def.Values, value)
+	}
+	return def, p.advance()
+}
+
+// skipDirectiveDefinition skips "directive @name(args) repeatable on A | B"
+func (p *parser) skipDirectiveDefinition() error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	if ok, err := p.skip("("); err != nil {
+		return err
+	} else if ok {
+		for !p.peek(")") {
+			if _, err := p.inputValueDefinition(); err != nil {
+				return err
+			}
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	if p.peekName("repeatable") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	if err := p.keyword("on"); err != nil {
+		return err
+	}
+	if _, err := p.skip("|"); err != nil {
+		return err
+	}
+	for {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if ok, err := p.skip("|"); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+	}
+}
+
+// skipSchemaDefinition skips "schema { query: Query }"
+func (p *parser) skipSchemaDefinition() error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.peek("}") {
+		if p.tok.kind == tokenEOF {
+			return p.errorf("unexpected end of document")
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	return p.advance()
+}
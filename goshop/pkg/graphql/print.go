@@ -0,0 +1,146 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SDL prints the types of the schema in the federation SDL a subgraph
+// publishes through _service: entities carry @key, extended types are printed
+// as "extend type" and copied fields as @external
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	for _, name := range s.order {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		switch t := s.types[name].(type) {
+		case *Scalar:
+			fmt.Fprintf(&b, "scalar %s\n", t.Name)
+		case *Enum:
+			fmt.Fprintf(&b, "enum %s {\n", t.Name)
+			for _, value := range t.Values {
+				fmt.Fprintf(&b, "  %s\n", value)
+			}
+			b.WriteString("}\n")
+		case *Union:
+			names := make([]string, len(t.Types))
+			for i, member := range t.Types {
+				names[i] = member.Name
+			}
+			fmt.Fprintf(&b, "union %s = %s\n", t.Name, strings.Join(names, " | "))
+		case *Object:
+			if t.Extends {
+				b.WriteString("extend ")
+			}
+			fmt.Fprintf(&b, "type %s", t.Name)
+			if t.Key != "" {
+				fmt.Fprintf(&b, " @key(fields: %s)", strconv.Quote(t.Key))
+			}
+			b.WriteString(" {\n")
+			for _, f := range t.Fields {
+				b.WriteString("  " + f.Name)
+				if len(f.Args) > 0 {
+					args := make([]string, len(f.Args))
+					for i, arg := range f.Args {
+						args[i] = arg.Name + ": " + arg.Type.String()
+						if arg.Default != nil {
+							args[i] += " = " + PrintValue(arg.Default)
+						}
+					}
+					b.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				b.WriteString(": " + f.Type.String())
+				if f.External {
+					b.WriteString(" @external")
+				}
+				b.WriteString("\n")
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+// PrintValue prints a Go value, as decoded from JSON or passed as an argument
+// default, as a GraphQL literal. Strings are always quoted; schemas of this
+// package accept quoted enum values.
+func PrintValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		b, _ := json.Marshal(v)
+		return string(b)
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = PrintValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = key + ": " + PrintValue(v[key])
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 32)
+	case reflect.String:
+		return PrintValue(rv.String())
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return PrintValue(items)
+	}
+	return "null"
+}
+
+// PrintLiteral prints a value of a document, replacing variables with their
+// values so that the result can be sent in a document without them
+func PrintLiteral(v *Value, vars map[string]interface{}) string {
+	switch v.Kind {
+	case VariableValue:
+		return PrintValue(vars[v.Raw])
+	case StringValue:
+		return PrintValue(v.Raw)
+	case ListValue:
+		items := make([]string, len(v.List))
+		for i, item := range v.List {
+			items[i] = PrintLiteral(item, vars)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case ObjectValue:
+		fields := make([]string, len(v.Fields))
+		for i, f := range v.Fields {
+			fields[i] = f.Name + ": " + PrintLiteral(f.Value, vars)
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return v.Raw
+}
@@ -0,0 +1,377 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Type is an output or argument type: *Scalar, *Enum, *Object, *Union, *List
+// or *NonNull
+type Type interface {
+	String() string
+}
+
+// List wraps a type as a list
+type List struct {
+	Of Type
+}
+
+// NonNull marks a type as never null
+type NonNull struct {
+	Of Type
+}
+
+func (t *List) String() string    { return "[" + t.Of.String() + "]" }
+func (t *NonNull) String() string { return t.Of.String() + "!" }
+
+// ListOf returns [t]
+func ListOf(t Type) *List {
+	return &List{Of: t}
+}
+
+// NonNullOf returns t!
+func NonNullOf(t Type) *NonNull {
+	return &NonNull{Of: t}
+}
+
+// namedType returns the type without its list and non-null wrappers
+func namedType(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *List:
+			t = w.Of
+		case *NonNull:
+			t = w.Of
+		default:
+			return t
+		}
+	}
+}
+
+// typeName returns the name of a named type
+func typeName(t Type) string {
+	switch t := t.(type) {
+	case *Scalar:
+		return t.Name
+	case *Enum:
+		return t.Name
+	case *Object:
+		return t.Name
+	case *Union:
+		return t.Name
+	}
+	return ""
+}
+
+// Scalar is a leaf type. Serialize converts a resolved Go value to its JSON
+// result and ParseValue converts an argument or variable value, which has been
+// decoded from JSON or a literal, to the value resolvers receive.
+type Scalar struct {
+	Name       string
+	Serialize  func(value interface{}) (interface{}, error)
+	ParseValue func(value interface{}) (interface{}, error)
+}
+
+func (t *Scalar) String() string { return t.Name }
+
+// Enum is a leaf type with a fixed set of values. Values serialize from
+// strings or named string types and are passed to resolvers as strings.
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+func (t *Enum) String() string { return t.Name }
+
+func (t *Enum) has(value string) bool {
+	for _, v := range t.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Object is an object type. Key lists the fields identifying an entity of the
+// type across subgraphs, e.g. "id", and makes the type a member of _Entity.
+// An object owned by another subgraph sets Extends and marks the key fields it
+// copies as External.
+type Object struct {
+	Name    string
+	Key     string
+	Extends bool
+	Fields  []*Field
+	// ResolveEntities returns the entities of the representations, which hold
+	// __typename and the key fields, in the same order; a nil entity is
+	// returned as null
+	ResolveEntities func(ctx context.Context, representations []map[string]interface{}) ([]interface{}, error)
+}
+
+func (t *Object) String() string { return t.Name }
+
+// Field returns the field called name, or nil
+func (t *Object) Field(name string) *Field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// ResolveFunc resolves a field of the Source object
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+// ResolveParams are the inputs of a resolver
+type ResolveParams struct {
+	Context context.Context
+	Source  interface{}
+	Args    map[string]interface{}
+}
+
+// Field is a field of an object type. Fields without a resolver read the
+// property of the source named by the field in snake case, see DefaultResolver.
+type Field struct {
+	Name     string
+	Type     Type
+	Args     []*Arg
+	External bool
+	Resolve  ResolveFunc
+}
+
+// Arg is an argument of a field, Default is used when the argument is omitted
+type Arg struct {
+	Name    string
+	Type    Type
+	Default interface{}
+}
+
+// Union is a union of object types. ResolveType returns the member type of a
+// resolved value.
+type Union struct {
+	Name        string
+	Types       []*Object
+	ResolveType func(value interface{}) *Object
+}
+
+func (t *Union) String() string { return t.Name }
+
+// Built-in scalars
+var (
+	// ID serializes strings and integers as strings
+	ID = &Scalar{Name: "ID", Serialize: serializeID, ParseValue: parseID}
+	// String serializes strings, named string types and times in RFC 3339
+	String = &Scalar{Name: "String", Serialize: serializeString, ParseValue: parseString}
+	// Int serializes integers in the 32-bit range
+	Int = &Scalar{Name: "Int", Serialize: serializeInt, ParseValue: parseInt}
+	// Float serializes numbers and values with a Float64 method such as
+	// currency.Money
+	Float = &Scalar{Name: "Float", Serialize: serializeFloat, ParseValue: parseFloat}
+	// Boolean serializes booleans
+	Boolean = &Scalar{Name: "Boolean", Serialize: serializeBoolean, ParseValue: parseBoolean}
+)
+
+func serializeID(value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	return nil, fmt.Errorf("ID cannot represent %T", value)
+}
+
+func parseID(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		if v == math.Trunc(v) {
+			return strconv.FormatInt(int64(v), 10), nil
+		}
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	}
+	return nil, fmt.Errorf("ID cannot represent %v", value)
+}
+
+func serializeString(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	return nil, fmt.Errorf("String cannot represent %T", value)
+}
+
+func parseString(value interface{}) (interface{}, error) {
+	if v, ok := value.(string); ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("String cannot represent %v", value)
+}
+
+func serializeInt(value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	var n int64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent %d", v.Uint())
+		}
+		n = int64(v.Uint())
+	default:
+		return nil, fmt.Errorf("Int cannot represent %T", value)
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return nil, fmt.Errorf("Int cannot represent %d", n)
+	}
+	return n, nil
+}
+
+func parseInt(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int(v), nil
+		}
+	case int64:
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int(v), nil
+		}
+	}
+	return nil, fmt.Errorf("Int cannot represent %v", value)
+}
+
+func serializeFloat(value interface{}) (interface{}, error) {
+	if v, ok := value.(interface{ Float64() float64 }); ok {
+		return v.Float64(), nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	}
+	return nil, fmt.Errorf("Float cannot represent %T", value)
+}
+
+func parseFloat(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	}
+	return nil, fmt.Errorf("Float cannot represent %v", value)
+}
+
+func serializeBoolean(value interface{}) (interface{}, error) {
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Bool {
+		return v.Bool(), nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent %T", value)
+}
+
+func parseBoolean(value interface{}) (interface{}, error) {
+	if v, ok := value.(bool); ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent %v", value)
+}
+
+// Schema is an executable schema
+type Schema struct {
+	query    *Object
+	mutation *Object
+	types    map[string]Type
+	order    []string // type names in definition order, for printing
+}
+
+// NewSchema creates a schema from the root types, mutation may be nil. Every
+// type reachable from the roots is collected; two different types with the
+// same name are an error.
+func NewSchema(query, mutation *Object) (*Schema, error) {
+	s := &Schema{query: query, mutation: mutation, types: map[string]Type{}}
+	for _, t := range []Type{String, Int, Float, Boolean, ID} {
+		s.types[typeName(t)] = t
+	}
+	if query == nil {
+		return nil, fmt.Errorf("graphql: schema has no query type")
+	}
+	if err := s.collect(query); err != nil {
+		return nil, err
+	}
+	if mutation != nil {
+		if err := s.collect(mutation); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) collect(t Type) error {
+	t = namedType(t)
+	name := typeName(t)
+	if name == "" {
+		return fmt.Errorf("graphql: unsupported type %T", t)
+	}
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("graphql: type %s is defined more than once", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+	s.order = append(s.order, name)
+
+	switch t := t.(type) {
+	case *Object:
+		if len(t.Fields) == 0 {
+			return fmt.Errorf("graphql: type %s has no fields", t.Name)
+		}
+		for _, f := range t.Fields {
+			if err := s.collect(f.Type); err != nil {
+				return err
+			}
+			for _, arg := range f.Args {
+				switch namedType(arg.Type).(type) {
+				case *Scalar, *Enum:
+				default:
+					return fmt.Errorf("graphql: argument %s.%s(%s) must be a scalar or enum", t.Name, f.Name, arg.Name)
+				}
+				if err := s.collect(arg.Type); err != nil {
+					return err
+				}
+			}
+		}
+	case *Union:
+		for _, member := range t.Types {
+			if err := s.collect(member); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Type returns the named type, or nil
+func (s *Schema) Type(name string) Type {
+	return s.types[name]
+}
@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Any is the _Any scalar of entity representations: an object holding
+// __typename and the key fields of the entity
+var Any = &Scalar{
+	Name: "_Any",
+	Serialize: func(value interface{}) (interface{}, error) {
+		return value, nil
+	},
+	ParseValue: func(value interface{}) (interface{}, error) {
+		rep, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("_Any must be an object")
+		}
+		if _, ok := rep["__typename"].(string); !ok {
+			return nil, fmt.Errorf("_Any must have a __typename")
+		}
+		return rep, nil
+	},
+}
+
+// entity is a value resolved by _entities together with its type
+type entity struct {
+	object *Object
+	value  interface{}
+}
+
+// NewSubgraph creates the schema of a federated subgraph. Besides the fields of
+// query it serves _service, returning the SDL the federation router composes,
+// and _entities, resolving the entities of the given types from their
+// representations. Entities must set Key and ResolveEntities; types this
+// subgraph extends are only reachable through _entities and must be listed.
+func NewSubgraph(query *Object, entities ...*Object) (*Schema, error) {
+	base, err := NewSchema(query, nil)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]*Object{}
+	for _, obj := range entities {
+		if obj.Key == "" || obj.ResolveEntities == nil {
+			return nil, fmt.Errorf("graphql: entity %s needs a key and ResolveEntities", obj.Name)
+		}
+		if err := base.collect(obj); err != nil {
+			return nil, err
+		}
+		byName[obj.Name] = obj
+	}
+	sdl := base.SDL()
+
+	service := &Object{
+		Name:   "_Service",
+		Fields: []*Field{{Name: "sdl", Type: NonNullOf(String)}},
+	}
+	root := &Object{
+		Name:   query.Name,
+		Fields: append([]*Field{}, query.Fields...),
+	}
+	root.Fields = append(root.Fields, &Field{
+		Name: "_service",
+		Type: NonNullOf(service),
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			return map[string]interface{}{"sdl": sdl}, nil
+		},
+	})
+	if len(entities) > 0 {
+		root.Fields = append(root.Fields, &Field{
+			Name:    "_entities",
+			Type:    NonNullOf(ListOf(&Union{Name: "_Entity", Types: entities})),
+			Args:    []*Arg{{Name: "representations", Type: NonNullOf(ListOf(NonNullOf(Any)))}},
+			Resolve: resolveEntities(byName),
+		})
+	}
+	return NewSchema(root, base.mutation)
+}
+
+// resolveEntities batches the representations by type, so each type resolves
+// all its entities with one call
+func resolveEntities(entities map[string]*Object) ResolveFunc {
+	return func(p ResolveParams) (interface{}, error) {
+		reps, _ := p.Args["representations"].([]interface{})
+		results := make([]interface{}, len(reps))
+
+		var order []string
+		batches := map[string][]int{}
+		for i, rep := range reps {
+			typename := rep.(map[string]interface{})["__typename"].(string)
+			if _, ok := entities[typename]; !ok {
+				return nil, fmt.Errorf("%s is not an entity of this subgraph", typename)
+			}
+			if _, ok := batches[typename]; !ok {
+				order = append(order, typename)
+			}
+			batches[typename] = append(batches[typename], i)
+		}
+
+		for _, typename := range order {
+			obj := entities[typename]
+			indexes := batches[typename]
+			batch := make([]map[string]interface{}, len(indexes))
+			for j, i := range indexes {
+				batch[j] = reps[i].(map[string]interface{})
+			}
+			values, err := obj.ResolveEntities(p.Context, batch)
+			if err != nil {
+				return nil, err
+			}
+			if len(values) != len(batch) {
+				return nil, fmt.Errorf("%s resolved %d entities for %d representations", typename, len(values), len(batch))
+			}
+			for j, i := range indexes {
+				if !isNull(values[j]) {
+					results[i] = entity{object: obj, value: values[j]}
+				}
+			}
+		}
+		return results, nil
+	}
+}
+
+// RepresentationIDs returns the key field of each representation parsed as an
+// unsigned integer ID, 0 when it is missing or invalid, for entity resolvers
+// loading by primary key
+func RepresentationIDs(representations []map[string]interface{}, key string) []uint {
+	ids := make([]uint, len(representations))
+	for i, rep := range representations {
+		switch v := rep[key].(type) {
+		case string:
+			if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+				ids[i] = uint(id)
+			}
+		case float64:
+			if v > 0 {
+				ids[i] = uint(v)
+			}
+		}
+	}
+	return ids
+}
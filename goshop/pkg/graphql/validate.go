@@ -0,0 +1,135 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxDepth is the deepest nesting of fields a document may select, counting
+// the fields of fragments where they are spread. Deeper documents are
+// rejected before they are planned or executed.
+const MaxDepth = 15
+
+// validate applies the rules every document must pass before it is used:
+// spread fragments must be defined, fragments must not spread themselves
+// directly or through other fragments (NoFragmentCycles), and operations must
+// not nest fields deeper than MaxDepth. Without them a single query makes the
+// planner and the executor recurse without bound.
+func validate(doc *Document) error {
+	if err := checkFragmentCycles(doc); err != nil {
+		return err
+	}
+	depths := map[string]int{}
+	for _, op := range doc.Operations {
+		depth, field := selectionDepth(doc, op.SelectionSet, depths)
+		if depth > MaxDepth {
+			err := &Error{Message: fmt.Sprintf("query is nested %d levels deep, at most %d are allowed", depth, MaxDepth)}
+			if field != nil {
+				err.Locations = []Position{field.Pos}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFragmentCycles reports undefined fragments and fragments spreading
+// themselves, walking the spreads of each fragment depth first
+func checkFragmentCycles(doc *Document) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return &Error{Message: fmt.Sprintf("cannot spread fragment %q within itself via %s", name, strings.Join(path, ", "))}
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		for _, spread := range fragmentSpreads(doc.Fragments[name].SelectionSet, nil) {
+			if _, ok := doc.Fragments[spread]; !ok {
+				return &Error{Message: fmt.Sprintf("unknown fragment %q", spread)}
+			}
+			if err := visit(spread, append(path, spread)); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		return nil
+	}
+
+	// Visit the fragments in a fixed order so that the same document always
+	// reports the same cycle
+	names := make([]string, 0, len(doc.Fragments))
+	for name := range doc.Fragments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name, []string{name}); err != nil {
+			return err
+		}
+	}
+	for _, op := range doc.Operations {
+		for _, spread := range fragmentSpreads(op.SelectionSet, nil) {
+			if _, ok := doc.Fragments[spread]; !ok {
+				return &Error{Message: fmt.Sprintf("unknown fragment %q", spread)}
+			}
+		}
+	}
+	return nil
+}
+
+// fragmentSpreads appends the names of the fragments spread in selections to
+// names, including those in nested fields and inline fragments
+func fragmentSpreads(selections []Selection, names []string) []string {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *FieldSelection:
+			names = fragmentSpreads(s.SelectionSet, names)
+		case *InlineFragment:
+			names = fragmentSpreads(s.SelectionSet, names)
+		case *FragmentSpread:
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// selectionDepth returns the depth of the deepest field in selections and
+// that field. Fragments are free of cycles at this point; their depths are
+// memoized in depths so that fragments spread many times are walked once.
+func selectionDepth(doc *Document, selections []Selection, depths map[string]int) (int, *FieldSelection) {
+	var max int
+	var deepest *FieldSelection
+	for _, selection := range selections {
+		var depth int
+		var field *FieldSelection
+		switch s := selection.(type) {
+		case *FieldSelection:
+			depth, field = selectionDepth(doc, s.SelectionSet, depths)
+			depth++
+			if field == nil {
+				field = s
+			}
+		case *InlineFragment:
+			depth, field = selectionDepth(doc, s.SelectionSet, depths)
+		case *FragmentSpread:
+			cached, ok := depths[s.Name]
+			if !ok {
+				cached, _ = selectionDepth(doc, doc.Fragments[s.Name].SelectionSet, depths)
+				depths[s.Name] = cached
+			}
+			depth = cached
+		}
+		if depth > max {
+			max, deepest = depth, field
+		}
+	}
+	return max, deepest
+}
@@ -0,0 +1,136 @@
+// Package grpcclient creates the gRPC client connections used for calls between
+// services. Connections resolve their target through DNS and balance calls
+// round-robin across the resolved addresses, keep idle connections alive, apply a
+// default deadline, retry transient failures, optionally hedge idempotent calls and
+// propagate the trace and request IDs of the caller. Errors returned by calls are
+// converted back into the pkg/errors errors raised by the called service.
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// serviceConfig balances calls across every address the target resolves to,
+// instead of the gRPC default of pinning the first one
+const serviceConfig = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+
+// Option configures a Factory
+type Option func(*Factory)
+
+// WithHedgedMethods enables hedging for the given full method names, e.g.
+// "/user.UserService/GetUser". Only idempotent methods may be hedged since the
+// server may execute a hedged call twice.
+func WithHedgedMethods(methods ...string) Option {
+	return func(f *Factory) {
+		for _, method := range methods {
+			f.hedged[method] = true
+		}
+	}
+}
+
+// WithDialOptions appends dial options to every connection created by the factory
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(f *Factory) {
+		f.dialOpts = append(f.dialOpts, opts...)
+	}
+}
+
+// Factory creates and caches one client connection per service
+type Factory struct {
+	cfg      config.GRPCConfig
+	log      *logger.Logger
+	hedged   map[string]bool
+	dialOpts []grpc.DialOption
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// NewFactory creates a connection factory from the gRPC configuration
+func NewFactory(cfg config.GRPCConfig, log *logger.Logger, opts ...Option) *Factory {
+	f := &Factory{
+		cfg:    cfg,
+		log:    log,
+		hedged: make(map[string]bool),
+		conns:  make(map[string]*grpc.ClientConn),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Conn returns the connection to service, creating it on first use. Connecting is
+// lazy: an unreachable service fails the calls made on the connection, not Conn.
+func (f *Factory) Conn(service string) (*grpc.ClientConn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if conn, ok := f.conns[service]; ok {
+		return conn, nil
+	}
+	target, ok := f.cfg.Targets[service]
+	if !ok || target == "" {
+		return nil, fmt.Errorf("no gRPC target configured for service %s", service)
+	}
+
+	conn, err := grpc.Dial(target, f.options()...)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s at %s: %w", service, target, err)
+	}
+	f.conns[service] = conn
+	return conn, nil
+}
+
+// Close closes every connection created by the factory
+func (f *Factory) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for service, conn := range f.conns {
+		if err := conn.Close(); err != nil {
+			f.log.Warn(context.Background(), "Failed to close gRPC connection", zap.String("service", service), zap.Error(err))
+		}
+		delete(f.conns, service)
+	}
+}
+
+func (f *Factory) options() []grpc.DialOption {
+	unary := []grpc.UnaryClientInterceptor{
+		apperrors.UnaryClientInterceptor(),
+		metadataUnaryInterceptor(),
+		deadlineInterceptor(time.Duration(f.cfg.CallTimeout) * time.Millisecond),
+	}
+	if f.cfg.HedgingDelay > 0 && len(f.hedged) > 0 {
+		unary = append(unary, hedgingInterceptor(f.hedged, time.Duration(f.cfg.HedgingDelay)*time.Millisecond))
+	}
+	if f.cfg.MaxRetries > 0 {
+		unary = append(unary, retryInterceptor(f.cfg.MaxRetries))
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithChainUnaryInterceptor(unary...),
+		grpc.WithChainStreamInterceptor(metadataStreamInterceptor()),
+	}
+	if f.cfg.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                time.Duration(f.cfg.KeepaliveTime) * time.Second,
+			Timeout:             10 * time.Second,
+			PermitWithoutStream: true,
+		}))
+	}
+	return append(opts, f.dialOpts...)
+}
@@ -0,0 +1,122 @@
+package grpcclient
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryBackoff is the delay before the first retry, doubled on each attempt
+const retryBackoff = 50 * time.Millisecond
+
+// deadlineInterceptor applies timeout to calls whose context has no deadline
+func deadlineInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// retryInterceptor retries calls failing with a transient error, waiting an
+// exponential backoff with jitter between attempts, within the call deadline
+func retryInterceptor(maxRetries int) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var err error
+		for attempt := 0; ; attempt++ {
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= maxRetries || !retryable(err) {
+				return err
+			}
+
+			backoff := retryBackoff << attempt
+			backoff += time.Duration(rand.Int63n(int64(backoff)))
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// retryable reports whether err is transient: the server was unreachable or shed
+// the call, so it was not executed
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// hedgingInterceptor sends a second copy of calls to the hedged methods when the
+// first has not completed after delay, and returns whichever succeeds first. It
+// cuts tail latency caused by a slow instance at the cost of extra load.
+func hedgingInterceptor(methods map[string]bool, delay time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !methods[method] {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type result struct {
+			reply interface{}
+			err   error
+		}
+		// Each attempt decodes into its own reply, the winner is copied into reply
+		results := make(chan result, 2)
+		attempt := func() {
+			r := reflect.New(reflect.TypeOf(reply).Elem()).Interface()
+			results <- result{reply: r, err: invoker(ctx, method, req, r, cc, opts...)}
+		}
+
+		go attempt()
+		pending, hedged := 1, false
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedge := func() {
+			hedged = true
+			pending++
+			go attempt()
+		}
+
+		for {
+			select {
+			case <-timer.C:
+				if !hedged {
+					hedge()
+				}
+			case res := <-results:
+				pending--
+				if res.err == nil {
+					reflect.ValueOf(reply).Elem().Set(reflect.ValueOf(res.reply).Elem())
+					return nil
+				}
+				// A definitive error is returned at once, a transient one gives the hedge
+				// a chance, sending it right away if it was not sent yet
+				if !retryable(res.err) {
+					return res.err
+				}
+				if !hedged {
+					hedge()
+				} else if pending == 0 {
+					return res.err
+				}
+			}
+		}
+	}
+}
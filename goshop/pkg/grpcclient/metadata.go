@@ -0,0 +1,102 @@
+package grpcclient
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/yourusername/goshop/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys carrying the caller context between services. Services trust
+// UserIDKey as they trust the X-User-ID header: it is set by the gateway and by
+// services calling each other only, so gRPC ports must not be exposed to clients.
+const (
+	TraceIDKey   = "x-trace-id"
+	RequestIDKey = "x-request-id"
+	UserIDKey    = "x-user-id"
+)
+
+type requestIDKey struct{}
+
+type userIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID, which is sent along
+// with every gRPC call made with the context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// GetRequestID returns the request ID carried by ctx
+func GetRequestID(ctx context.Context) string {
+	if requestID, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return requestID
+	}
+	return ""
+}
+
+// WithUserID returns a context carrying the ID of the user on whose behalf the
+// request is made, like the X-User-ID header set by the gateway on HTTP
+// requests. It is sent along with every gRPC call made with the context.
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// GetUserID returns the user ID carried by ctx, false for anonymous requests
+func GetUserID(ctx context.Context) (uint, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(uint)
+	return userID, ok && userID != 0
+}
+
+// outgoing adds the trace, request and user IDs of ctx to the outgoing metadata
+func outgoing(ctx context.Context) context.Context {
+	var kv []string
+	if traceID := logger.GetTraceID(ctx); traceID != "" {
+		kv = append(kv, TraceIDKey, traceID)
+	}
+	if requestID := GetRequestID(ctx); requestID != "" {
+		kv = append(kv, RequestIDKey, requestID)
+	}
+	if userID, ok := GetUserID(ctx); ok {
+		kv = append(kv, UserIDKey, strconv.FormatUint(uint64(userID), 10))
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+func metadataUnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoing(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+func metadataStreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoing(ctx), desc, cc, method, opts...)
+	}
+}
+
+// UnaryServerInterceptor restores the trace, request and user IDs sent by the
+// caller into the handler context, so that the logs of both services can be
+// correlated and the user's permissions checked
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(TraceIDKey); len(values) > 0 {
+				ctx = logger.WithTraceID(ctx, values[0])
+			}
+			if values := md.Get(RequestIDKey); len(values) > 0 {
+				ctx = WithRequestID(ctx, values[0])
+			}
+			if values := md.Get(UserIDKey); len(values) > 0 {
+				if userID, err := strconv.ParseUint(values[0], 10, 64); err == nil {
+					ctx = WithUserID(ctx, uint(userID))
+				}
+			}
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,57 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"gorm.io/gorm"
+)
+
+// Database checks that the database answers a ping
+func Database(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Redis checks that Redis answers a ping
+func Redis(rdb redis.UniversalClient) CheckFunc {
+	return func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	}
+}
+
+// NATS checks that the connection to NATS is established. It does not round
+// trip to the server since the client already tracks the connection state.
+func NATS(nc *nats.Conn) CheckFunc {
+	return func(ctx context.Context) error {
+		if status := nc.Status(); status != nats.CONNECTED {
+			return fmt.Errorf("nats connection is %v", status)
+		}
+		return nil
+	}
+}
+
+// GRPC checks a downstream service through the gRPC health protocol, service
+// is the name of the checked gRPC service or empty for the whole server
+func GRPC(conn grpc.ClientConnInterface, service string) CheckFunc {
+	client := grpc_health_v1.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+			return fmt.Errorf("service is %v", resp.GetStatus())
+		}
+		return nil
+	}
+}
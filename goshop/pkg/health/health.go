@@ -0,0 +1,156 @@
+// Package health runs the dependency checks of a service and exposes them as
+// liveness and readiness probes. Liveness only tells that the process serves
+// requests, readiness that every registered dependency answers, so that
+// orchestrators restart hung processes but merely stop routing traffic to
+// instances whose database or broker is unreachable.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// defaultTimeout bounds each check so that a hung dependency cannot hang the probe
+const defaultTimeout = 2 * time.Second
+
+// Status of a check or of the whole service
+type Status string
+
+const (
+	StatusUp   Status = "UP"
+	StatusDown Status = "DOWN"
+)
+
+// CheckFunc checks a dependency, returning an error when it is unusable
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of a check
+type Result struct {
+	Status    Status  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of a probe
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Option configures a Health
+type Option func(*Health)
+
+// WithTimeout sets the time each check may take, 2 seconds by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *Health) {
+		h.timeout = timeout
+	}
+}
+
+// Health holds the dependency checks of a service
+type Health struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []check
+
+	shuttingDown atomic.Bool
+	grpc         *grpchealth.Server
+}
+
+// New creates an empty Health, ready as long as no check is added
+func New(opts ...Option) *Health {
+	h := &Health{timeout: defaultTimeout}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Add registers a dependency check run by the readiness probe
+func (h *Health) Add(name string, fn CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, check{name: name, fn: fn})
+}
+
+// Live reports whether the process is alive, which it is when it can answer
+func (h *Health) Live() Report {
+	return Report{Status: StatusUp}
+}
+
+// Ready runs every check concurrently and reports the service down when any of
+// them fails or when the service is shutting down
+func (h *Health) Ready(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := append([]check(nil), h.checks...)
+	h.mu.RUnlock()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	if h.shuttingDown.Load() {
+		report.Status = StatusDown
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c check) {
+			defer wg.Done()
+			result := h.run(ctx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = result
+			if result.Status == StatusDown {
+				report.Status = StatusDown
+			}
+		}(c)
+	}
+	wg.Wait()
+	return report
+}
+
+func (h *Health) run(ctx context.Context, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.fn(ctx)
+	result := Result{
+		Status:    StatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// RegisterGRPC serves the standard gRPC health protocol on s, so that other
+// services can check this one with GRPC
+func (h *Health) RegisterGRPC(s grpc.ServiceRegistrar) {
+	h.grpc = grpchealth.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, h.grpc)
+}
+
+// Shutdown marks the service as not ready, letting load balancers drain it
+// before the servers stop
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+	if h.grpc != nil {
+		h.grpc.Shutdown()
+	}
+}
@@ -0,0 +1,26 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register exposes the probes on router: GET /health/live, GET /health/ready and
+// GET /health, kept as an alias of the liveness probe for existing monitors.
+// Failed probes answer 503.
+func (h *Health) Register(router gin.IRouter) {
+	live := func(c *gin.Context) {
+		c.JSON(http.StatusOK, h.Live())
+	}
+	router.GET("/health", live)
+	router.GET("/health/live", live)
+	router.GET("/health/ready", func(c *gin.Context) {
+		report := h.Ready(c.Request.Context())
+		status := http.StatusOK
+		if report.Status != StatusUp {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	})
+}
@@ -0,0 +1,116 @@
+// Package idgen generates unique 64-bit IDs across service instances with a
+// snowflake layout, and business numbers such as order or refund numbers built on
+// top of them.
+//
+// An ID is made of 41 bits of milliseconds since Epoch, 10 bits of worker ID and
+// 12 bits of sequence, so that each worker generates up to 4096 IDs per
+// millisecond for about 69 years. IDs of one worker are strictly increasing.
+//
+// Worker IDs must be unique across the processes of all services. Services
+// lease theirs in Redis with LeaseWorkerID at startup, unless the deployment
+// assigns one in GOSHOP_WORKER_ID.
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	workerBits   = 10
+	sequenceBits = 12
+
+	// MaxWorkerID is the largest worker ID
+	MaxWorkerID = 1<<workerBits - 1
+
+	maxSequence = 1<<sequenceBits - 1
+	workerShift = sequenceBits
+	timeShift   = sequenceBits + workerBits
+
+	// maxBackwards is the largest clock step back waited out rather than failed,
+	// small steps happen when NTP slews the clock
+	maxBackwards = 10 * time.Millisecond
+)
+
+// Epoch is the start of the ID timestamps
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ErrClockBackwards is returned when the clock moved back further than the
+// generator is willing to wait, generating would risk duplicate IDs
+var ErrClockBackwards = errors.New("idgen: clock moved backwards")
+
+// Generator generates IDs for one worker. It is safe for concurrent use.
+type Generator struct {
+	mu       sync.Mutex
+	workerID int64
+	lastMs   int64
+	sequence int64
+	now      func() time.Time
+}
+
+// New creates a generator for workerID, which must be unique among the running
+// instances of all services sharing the IDs
+func New(workerID int64) (*Generator, error) {
+	if workerID < 0 || workerID > MaxWorkerID {
+		return nil, fmt.Errorf("idgen: worker ID %d out of range [0, %d]", workerID, MaxWorkerID)
+	}
+	return &Generator{
+		workerID: workerID,
+		now:      time.Now,
+	}, nil
+}
+
+// WorkerID returns the worker ID of the generator
+func (g *Generator) WorkerID() int64 {
+	return g.workerID
+}
+
+// Next returns a new ID
+func (g *Generator) Next() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.millis()
+	if ms < g.lastMs {
+		behind := time.Duration(g.lastMs-ms) * time.Millisecond
+		if behind > maxBackwards {
+			return 0, fmt.Errorf("%w by %s", ErrClockBackwards, behind)
+		}
+		time.Sleep(behind)
+		if ms = g.millis(); ms < g.lastMs {
+			return 0, fmt.Errorf("%w by %s", ErrClockBackwards, time.Duration(g.lastMs-ms)*time.Millisecond)
+		}
+	}
+
+	if ms == g.lastMs {
+		g.sequence = (g.sequence + 1) & maxSequence
+		if g.sequence == 0 {
+			// Sequence exhausted for this millisecond, wait for the next one
+			for ms <= g.lastMs {
+				time.Sleep(100 * time.Microsecond)
+				ms = g.millis()
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMs = ms
+
+	return ms<<timeShift | g.workerID<<workerShift | g.sequence, nil
+}
+
+func (g *Generator) millis() int64 {
+	return g.now().Sub(Epoch).Milliseconds()
+}
+
+// Time returns the time an ID was generated at, with millisecond precision
+func Time(id int64) time.Time {
+	return Epoch.Add(time.Duration(id>>timeShift) * time.Millisecond)
+}
+
+// Worker returns the worker ID an ID was generated by
+func Worker(id int64) int64 {
+	return id >> workerShift & MaxWorkerID
+}
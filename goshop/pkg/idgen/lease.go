@@ -0,0 +1,141 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yourusername/goshop/pkg/locks"
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// leaseNamespace is the lock namespace of the leased worker IDs. It is shared
+// by all services so that no two processes of any service hold the same ID.
+const leaseNamespace = "idgen"
+
+// LeaseTTL is how long a leased worker ID outlives its process. A crashed
+// process's ID is free again after LeaseTTL.
+const LeaseTTL = 30 * time.Second
+
+// ErrLeaseExpired is returned by the default generator once its worker ID lease
+// could not be refreshed in time, since another process may hold the ID by then
+var ErrLeaseExpired = errors.New("idgen: worker ID lease expired")
+
+// workerLease is a worker ID held in Redis and the generator using it
+type workerLease struct {
+	id   int64
+	lock *locks.Lock
+	gen  *Generator
+}
+
+// LeaseWorkerID makes the default generator use the lowest worker ID not
+// leased by another process in Redis, unless GOSHOP_WORKER_ID is set. The lease
+// is refreshed in the background until the returned release function is
+// called; when it is lost another ID is leased, and the default generator fails
+// with ErrLeaseExpired while none is held.
+func LeaseWorkerID(ctx context.Context, rdb *redis.Client, log *logger.Logger) (release func(), err error) {
+	if os.Getenv(WorkerIDEnv) != "" {
+		return func() {}, nil
+	}
+
+	locker := locks.New(rdb, leaseNamespace)
+	lease, err := obtainWorkerID(ctx, locker)
+	if err != nil {
+		return nil, err
+	}
+	setLease(lease)
+	log.Info(ctx, "Leased worker ID", zap.Int64("worker_id", lease.id))
+
+	leaseCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		keepLease(leaseCtx, locker, lease, log)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}, nil
+}
+
+// obtainWorkerID leases the lowest free worker ID
+func obtainWorkerID(ctx context.Context, locker *locks.Locker) (*workerLease, error) {
+	for id := int64(0); id <= MaxWorkerID; id++ {
+		lock, err := locker.Obtain(ctx, "worker:"+strconv.FormatInt(id, 10), LeaseTTL)
+		if errors.Is(err, locks.ErrNotObtained) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("idgen: lease worker ID: %w", err)
+		}
+		gen, err := New(id)
+		if err != nil {
+			return nil, err
+		}
+		return &workerLease{id: id, lock: lock, gen: gen}, nil
+	}
+	return nil, errors.New("idgen: all worker IDs are leased")
+}
+
+// keepLease refreshes the lease until ctx is done, then releases it. A lost
+// lease is replaced by a new one, possibly with another worker ID.
+func keepLease(ctx context.Context, locker *locks.Locker, lease *workerLease, log *logger.Logger) {
+	ticker := time.NewTicker(LeaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			releaseLease(lease, log)
+			return
+		case <-ticker.C:
+		}
+
+		err := lease.lock.Refresh(ctx, LeaseTTL)
+		if err == nil {
+			continue
+		}
+		log.Warn(ctx, "Failed to refresh worker ID lease", zap.Int64("worker_id", lease.id), zap.Error(err))
+		if !errors.Is(err, locks.ErrNotHeld) {
+			// Redis may be unreachable for a moment, the lease stays usable
+			// until it expires locally
+			continue
+		}
+		next, err := obtainWorkerID(ctx, locker)
+		if err != nil {
+			log.Error(ctx, "Failed to lease a new worker ID", zap.Error(err))
+			continue
+		}
+		lease = next
+		setLease(lease)
+		log.Info(ctx, "Leased worker ID", zap.Int64("worker_id", lease.id))
+	}
+}
+
+// releaseLease stops the default generator and frees its worker ID
+func releaseLease(lease *workerLease, log *logger.Logger) {
+	defaultMu.Lock()
+	if defaultLease == lease {
+		defaultLease = nil
+		defaultErr = ErrLeaseExpired
+	}
+	defaultMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := lease.lock.Release(ctx); err != nil {
+		log.Warn(ctx, "Failed to release worker ID lease", zap.Int64("worker_id", lease.id), zap.Error(err))
+	}
+}
+
+func setLease(lease *workerLease) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLease = lease
+	defaultGen = nil
+	defaultErr = nil
+}
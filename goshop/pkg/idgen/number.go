@@ -0,0 +1,38 @@
+package idgen
+
+import (
+	"strconv"
+	"time"
+)
+
+// Prefixes of the business numbers, so that a number tells what it refers to
+const (
+	PrefixOrder    = "ORD"
+	PrefixPayment  = "PAY"
+	PrefixRefund   = "RFD"
+	PrefixShipment = "SHP"
+	PrefixReturn   = "RMA"
+	PrefixInvoice  = "CI"
+	PrefixTicket   = "TKT"
+)
+
+// NewNumber returns a business number made of prefix, the generation date and a
+// new ID, e.g. "ORD241015" followed by the ID digits. The date makes numbers
+// readable by support staff, the ID makes them unique.
+func NewNumber(prefix string) (string, error) {
+	id, err := Next()
+	if err != nil {
+		return "", err
+	}
+	return prefix + Time(id).In(time.Local).Format("060102") + strconv.FormatInt(id, 10), nil
+}
+
+// NewRequestID returns an ID for a request entering the system, in base 36 to
+// keep headers and logs short
+func NewRequestID() (string, error) {
+	id, err := Next()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 36), nil
+}
@@ -0,0 +1,68 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// WorkerIDEnv is the environment variable setting the worker ID explicitly
+const WorkerIDEnv = "GOSHOP_WORKER_ID"
+
+// ErrNoWorkerID is returned when the process has no worker ID: deriving one
+// from the pod or host name is not safe, as the replicas of different services
+// share StatefulSet ordinals and host name hashes collide
+var ErrNoWorkerID = errors.New("idgen: no worker ID, set " + WorkerIDEnv + " or call LeaseWorkerID")
+
+// WorkerIDFromEnv returns the worker ID set explicitly by the deployment in
+// GOSHOP_WORKER_ID, which must be unique across the processes of all services
+func WorkerIDFromEnv() (int64, error) {
+	raw := os.Getenv(WorkerIDEnv)
+	if raw == "" {
+		return 0, ErrNoWorkerID
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 || id > MaxWorkerID {
+		return 0, fmt.Errorf("idgen: invalid %s %q", WorkerIDEnv, raw)
+	}
+	return id, nil
+}
+
+var (
+	defaultMu    sync.Mutex
+	defaultLease *workerLease
+	defaultGen   *Generator
+	defaultErr   error
+)
+
+// Default returns the process wide generator, whose worker ID is leased with
+// LeaseWorkerID or comes from WorkerIDFromEnv
+func Default() (*Generator, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultLease != nil {
+		if defaultLease.lock.TTL() <= 0 {
+			return nil, ErrLeaseExpired
+		}
+		return defaultLease.gen, nil
+	}
+	if defaultGen == nil && defaultErr == nil {
+		var workerID int64
+		if workerID, defaultErr = WorkerIDFromEnv(); defaultErr != nil {
+			return nil, defaultErr
+		}
+		defaultGen, defaultErr = New(workerID)
+	}
+	return defaultGen, defaultErr
+}
+
+// Next returns a new ID from the default generator
+func Next() (int64, error) {
+	g, err := Default()
+	if err != nil {
+		return 0, err
+	}
+	return g.Next()
+}
@@ -0,0 +1,108 @@
+package locks
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Election elects one leader among the replicas of a service through a lock,
+// for singleton background jobs
+type Election struct {
+	locker *Locker
+	name   string
+	ttl    time.Duration
+	log    *logger.Logger
+	leader atomic.Bool
+}
+
+// NewElection creates an election named name. The leadership lock expires after
+// ttl when the leader stops refreshing it, so a crashed leader is replaced
+// within ttl.
+func NewElection(locker *Locker, name string, ttl time.Duration, log *logger.Logger) *Election {
+	return &Election{locker: locker, name: name, ttl: ttl, log: log}
+}
+
+// IsLeader reports whether this replica currently leads
+func (e *Election) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run campaigns until ctx is done, calling fn whenever this replica becomes the
+// leader. The context passed to fn is canceled when the leadership is lost, fn
+// must then return promptly since another replica may already lead.
+func (e *Election) Run(ctx context.Context, fn func(ctx context.Context)) {
+	interval := e.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		lock, err := e.locker.Obtain(ctx, e.name, e.ttl)
+		switch {
+		case err == nil:
+			e.lead(ctx, lock, fn)
+		case !errors.Is(err, ErrNotObtained) && ctx.Err() == nil:
+			e.log.Warn(ctx, "Failed to campaign for leadership", zap.String("election", e.name), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead runs fn while refreshing the leadership lock
+func (e *Election) lead(ctx context.Context, lock *Lock, fn func(ctx context.Context)) {
+	e.leader.Store(true)
+	defer e.leader.Store(false)
+	e.log.Info(ctx, "Became leader", zap.String("election", e.name), zap.Int64("token", lock.Token()))
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			cancel()
+			e.release(lock)
+			return
+		case <-ctx.Done():
+			cancel()
+			<-done
+			e.release(lock)
+			return
+		case <-ticker.C:
+			if err := lock.Refresh(ctx, e.ttl); err != nil {
+				// Stop before the lock expires for good: either it was taken
+				// over or Redis is unreachable and the lock will expire
+				e.log.Warn(ctx, "Lost leadership", zap.String("election", e.name), zap.Error(err))
+				cancel()
+				<-done
+				return
+			}
+		}
+	}
+}
+
+// release gives up the leadership so that another replica takes over at once
+func (e *Election) release(lock *Lock) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := lock.Release(ctx); err != nil {
+		e.log.Warn(ctx, "Failed to release leadership", zap.String("election", e.name), zap.Error(err))
+		return
+	}
+	e.log.Info(ctx, "Released leadership", zap.String("election", e.name))
+}
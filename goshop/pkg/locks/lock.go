@@ -0,0 +1,156 @@
+// Package locks provides Redis based distributed locks and leader election, so
+// that work meant to run once across the replicas of a service, such as
+// scheduled jobs, does.
+//
+// Locks expire after their TTL to survive crashed holders, which means a holder
+// paused for longer than the TTL may still believe it holds the lock. Each lock
+// carries a fencing token, increasing with every acquisition of the key; storage
+// written under a lock can reject writes with a token lower than the last seen.
+package locks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNotObtained is returned when the lock is held by someone else
+	ErrNotObtained = errors.New("locks: lock not obtained")
+	// ErrNotHeld is returned when the lock expired or was taken over
+	ErrNotHeld = errors.New("locks: lock not held")
+)
+
+// obtainScript takes the lock when it is free and returns the next fencing token
+// of the key, 0 when the lock is taken
+var obtainScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`)
+
+// refreshScript extends the lock if it is still held by the caller
+var refreshScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lock if it is still held by the caller
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Locker obtains locks stored in Redis
+type Locker struct {
+	rdb       *redis.Client
+	namespace string
+}
+
+// New creates a locker whose keys are prefixed with namespace, usually the
+// service name
+func New(rdb *redis.Client, namespace string) *Locker {
+	return &Locker{rdb: rdb, namespace: namespace}
+}
+
+// Lock is a held lock
+type Lock struct {
+	rdb      *redis.Client
+	key      string
+	value    string
+	token    int64
+	deadline time.Time
+}
+
+// Obtain takes the lock named key for ttl, failing with ErrNotObtained when it is
+// held by someone else
+func (l *Locker) Obtain(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	value, err := randomValue()
+	if err != nil {
+		return nil, err
+	}
+	lockKey := fmt.Sprintf("lock:%s:%s", l.namespace, key)
+	start := time.Now()
+	token, err := obtainScript.Run(ctx, l.rdb, []string{lockKey, lockKey + ":fence"}, value, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("obtain lock %s: %w", key, err)
+	}
+	if token == 0 {
+		return nil, ErrNotObtained
+	}
+	return &Lock{rdb: l.rdb, key: lockKey, value: value, token: token, deadline: start.Add(ttl)}, nil
+}
+
+// ObtainWait takes the lock named key for ttl, retrying every retry until it is
+// free or ctx is done
+func (l *Locker) ObtainWait(ctx context.Context, key string, ttl, retry time.Duration) (*Lock, error) {
+	ticker := time.NewTicker(retry)
+	defer ticker.Stop()
+	for {
+		lock, err := l.Obtain(ctx, key, ttl)
+		if !errors.Is(err, ErrNotObtained) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Token returns the fencing token of the lock
+func (lk *Lock) Token() int64 {
+	return lk.token
+}
+
+// TTL returns the time left before the lock expires, as measured locally
+func (lk *Lock) TTL() time.Duration {
+	return time.Until(lk.deadline)
+}
+
+// Refresh extends the lock to ttl from now, failing with ErrNotHeld when it
+// expired in the meantime
+func (lk *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	start := time.Now()
+	ok, err := refreshScript.Run(ctx, lk.rdb, []string{lk.key}, lk.value, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("refresh lock: %w", err)
+	}
+	if ok == 0 {
+		return ErrNotHeld
+	}
+	lk.deadline = start.Add(ttl)
+	return nil
+}
+
+// Release frees the lock, failing with ErrNotHeld when it already expired
+func (lk *Lock) Release(ctx context.Context) error {
+	ok, err := releaseScript.Run(ctx, lk.rdb, []string{lk.key}, lk.value).Int64()
+	if err != nil {
+		return fmt.Errorf("release lock: %w", err)
+	}
+	if ok == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// randomValue identifies the holder of a lock, so that only it can release it
+func randomValue() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate lock value: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
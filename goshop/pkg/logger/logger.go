@@ -2,7 +2,6 @@ package logger
 
 import (
 	"context"
-	"os"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -11,23 +10,32 @@ import (
 // Logger wraps zap.Logger
 type Logger struct {
 	*zap.Logger
+	level zap.AtomicLevel
 }
 
-// Key type for retrieving traceID from context
+// Key type for retrieving traceID and spanID from context
 type contextKey string
 
 const (
 	traceIDKey contextKey = "traceID"
+	spanIDKey  contextKey = "spanID"
 )
 
-// New creates a new logger
-func New(serviceName string, level string) (*Logger, error) {
-	// Set log level
+// New creates a new logger, writing JSON to stdout unless configured otherwise
+// with options
+func New(serviceName string, level string, opts ...Option) (*Logger, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Set log level, kept atomic so that it can be changed at runtime
 	var logLevel zapcore.Level
 	err := logLevel.UnmarshalText([]byte(level))
 	if err != nil {
 		logLevel = zapcore.InfoLevel
 	}
+	atomicLevel := zap.NewAtomicLevelAt(logLevel)
 
 	// Create configuration
 	encoderConfig := zapcore.EncoderConfig{
@@ -45,11 +53,11 @@ func New(serviceName string, level string) (*Logger, error) {
 	}
 
 	// Create core
-	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig),
-		zapcore.AddSync(os.Stdout),
-		logLevel,
-	)
+	sink, err := o.sink(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	core := o.core(o.encoder(encoderConfig), sink, atomicLevel)
 
 	// Create logger
 	zapLogger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
@@ -57,7 +65,18 @@ func New(serviceName string, level string) (*Logger, error) {
 	// Add service name field
 	zapLogger = zapLogger.With(zap.String("service", serviceName))
 	
-	return &Logger{zapLogger}, nil
+	return &Logger{Logger: zapLogger, level: atomicLevel}, nil
+}
+
+// SetLevel changes the log level at runtime, for this logger and every logger
+// derived from it with With
+func (l *Logger) SetLevel(level string) error {
+	var logLevel zapcore.Level
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	l.level.SetLevel(logLevel)
+	return nil
 }
 
 // WithTraceID adds traceID to context
@@ -73,12 +92,32 @@ func GetTraceID(ctx context.Context) string {
 	return ""
 }
 
-// WithContext gets traceID from context and adds it to the log
+// WithSpanID adds spanID to context
+func WithSpanID(ctx context.Context, spanID string) context.Context {
+	return context.WithValue(ctx, spanIDKey, spanID)
+}
+
+// GetSpanID gets spanID from context
+func GetSpanID(ctx context.Context) string {
+	if spanID, ok := ctx.Value(spanIDKey).(string); ok {
+		return spanID
+	}
+	return ""
+}
+
+// WithContext gets traceID and spanID from context and adds them to the log
 func (l *Logger) WithContext(ctx context.Context) *zap.Logger {
+	var fields []zap.Field
 	if traceID := GetTraceID(ctx); traceID != "" {
-		return l.With(zap.String("trace_id", traceID))
+		fields = append(fields, zap.String("trace_id", traceID))
+	}
+	if spanID := GetSpanID(ctx); spanID != "" {
+		fields = append(fields, zap.String("span_id", spanID))
+	}
+	if len(fields) == 0 {
+		return l.Logger
 	}
-	return l.Logger
+	return l.Logger.With(fields...)
 }
 
 // Info logs at info level
@@ -108,5 +147,5 @@ func (l *Logger) Fatal(ctx context.Context, msg string, fields ...zap.Field) {
 
 // With creates a logger with additional fields
 func (l *Logger) With(fields ...zap.Field) *Logger {
-	return &Logger{l.Logger.With(fields...)}
+	return &Logger{Logger: l.Logger.With(fields...), level: l.level}
 }
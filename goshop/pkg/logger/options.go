@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Log outputs
+const (
+	OutputStdout = "stdout"
+	OutputFile   = "file"
+	OutputSyslog = "syslog"
+)
+
+// Log encodings
+const (
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+)
+
+// options holds the optional settings of New
+type options struct {
+	cfg config.LoggerConfig
+}
+
+// Option configures New
+type Option func(*options)
+
+func defaultOptions() *options {
+	return &options{cfg: config.LoggerConfig{
+		Outputs:  []string{OutputStdout},
+		Encoding: EncodingJSON,
+	}}
+}
+
+// WithConfig configures the outputs, encoding and sampling of the logger. An
+// empty encoding selects console output in development and JSON elsewhere.
+func WithConfig(cfg config.LoggerConfig, environment string) Option {
+	return func(o *options) {
+		o.cfg = cfg
+		if len(o.cfg.Outputs) == 0 {
+			o.cfg.Outputs = []string{OutputStdout}
+		}
+		if o.cfg.Encoding == "" {
+			o.cfg.Encoding = EncodingJSON
+			if environment == config.EnvDevelopment {
+				o.cfg.Encoding = EncodingConsole
+			}
+		}
+	}
+}
+
+// encoder creates the encoder of the configured encoding, console output is meant
+// for humans and gets colored levels
+func (o *options) encoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	if o.cfg.Encoding == EncodingConsole {
+		cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		return zapcore.NewConsoleEncoder(cfg)
+	}
+	return zapcore.NewJSONEncoder(cfg)
+}
+
+// sink opens the configured outputs
+func (o *options) sink(serviceName string) (zapcore.WriteSyncer, error) {
+	var sinks []zapcore.WriteSyncer
+	for _, output := range o.cfg.Outputs {
+		switch output {
+		case OutputStdout:
+			sinks = append(sinks, zapcore.Lock(os.Stdout))
+		case OutputFile:
+			if o.cfg.File == "" {
+				return nil, fmt.Errorf("log file output requires a file path")
+			}
+			if err := os.MkdirAll(filepath.Dir(o.cfg.File), 0o755); err != nil {
+				return nil, fmt.Errorf("create log directory: %w", err)
+			}
+			sinks = append(sinks, zapcore.AddSync(&lumberjack.Logger{
+				Filename:   o.cfg.File,
+				MaxSize:    o.cfg.MaxSize,
+				MaxBackups: o.cfg.MaxBackups,
+				MaxAge:     o.cfg.MaxAge,
+				Compress:   o.cfg.Compress,
+				LocalTime:  true,
+			}))
+		case OutputSyslog:
+			w, err := newSyslogWriter(o.cfg.SyslogNetwork, o.cfg.SyslogAddr, serviceName)
+			if err != nil {
+				return nil, fmt.Errorf("connect to syslog: %w", err)
+			}
+			sinks = append(sinks, zapcore.AddSync(w))
+		default:
+			return nil, fmt.Errorf("unknown log output %q", output)
+		}
+	}
+	return zapcore.NewMultiWriteSyncer(sinks...), nil
+}
+
+// core builds the logging core. With sampling enabled, debug and info entries are
+// sampled per message each second while warnings and errors are always logged,
+// so that a hot path logging at info level cannot flood the outputs.
+func (o *options) core(enc zapcore.Encoder, sink zapcore.WriteSyncer, level zap.AtomicLevel) zapcore.Core {
+	if o.cfg.SampleInitial <= 0 {
+		return o.newCore(enc, sink, level)
+	}
+
+	low := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return level.Enabled(l) && l < zapcore.WarnLevel
+	})
+	high := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return level.Enabled(l) && l >= zapcore.WarnLevel
+	})
+	thereafter := o.cfg.SampleThereafter
+	if thereafter <= 0 {
+		thereafter = 1
+	}
+	return zapcore.NewTee(
+		zapcore.NewSamplerWithOptions(o.newCore(enc, sink, low), time.Second, o.cfg.SampleInitial, thereafter),
+		o.newCore(enc.Clone(), sink, high),
+	)
+}
+
+// newCore creates a core that masks personal data and credentials before
+// encoding. It sits below the sampler so that sampling still sees the original
+// messages.
+func (o *options) newCore(enc zapcore.Encoder, sink zapcore.WriteSyncer, level zapcore.LevelEnabler) zapcore.Core {
+	return &redactCore{Core: zapcore.NewCore(enc, sink, level), r: newRedactor(o.cfg.RedactKeys)}
+}
@@ -0,0 +1,273 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redacted replaces values that cannot be partially shown
+const redacted = "[REDACTED]"
+
+// masker hides a sensitive value, possibly keeping a part useful for support
+type masker func(string) string
+
+// sensitiveKeys maps normalized field key fragments to their masker. A field is
+// sensitive when its normalized key contains one of them, e.g. "user_email" or
+// "newPassword".
+var sensitiveKeys = map[string]masker{
+	"password":      redactAll,
+	"passwd":        redactAll,
+	"secret":        redactAll,
+	"token":         redactAll,
+	"authorization": redactAll,
+	"apikey":        redactAll,
+	"cookie":        redactAll,
+	"cvv":           redactAll,
+	"idnumber":      redactAll,
+	"idcard":        redactAll,
+	"phone":         maskPhone,
+	"mobile":        maskPhone,
+	"email":         maskEmail,
+	"cardnumber":    maskCard,
+	"pan":           maskCard,
+}
+
+// Patterns of sensitive values found in messages and free text fields
+var (
+	bearerPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	cardPattern   = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	phonePattern  = regexp.MustCompile(`\b1[3-9]\d{9}\b`)
+)
+
+// redactor masks sensitive fields and values before entries are encoded
+type redactor struct {
+	keys map[string]masker
+}
+
+// newRedactor creates a redactor for the built-in sensitive keys and extra, which
+// are fully redacted
+func newRedactor(extra []string) *redactor {
+	keys := make(map[string]masker, len(sensitiveKeys)+len(extra))
+	for key, m := range sensitiveKeys {
+		keys[key] = m
+	}
+	for _, key := range extra {
+		if key = normalizeKey(key); key != "" {
+			keys[key] = redactAll
+		}
+	}
+	return &redactor{keys: keys}
+}
+
+// masker returns the masker of a sensitive key, nil for other keys
+func (r *redactor) masker(key string) masker {
+	key = normalizeKey(key)
+	for fragment, m := range r.keys {
+		if strings.Contains(key, fragment) {
+			return m
+		}
+	}
+	return nil
+}
+
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "", " ", "").Replace(strings.ToLower(key))
+}
+
+// text masks the sensitive values recognizable by their shape in free text
+func (r *redactor) text(s string) string {
+	s = bearerPattern.ReplaceAllString(s, "Bearer "+redacted)
+	s = jwtPattern.ReplaceAllString(s, redacted)
+	s = emailPattern.ReplaceAllStringFunc(s, maskEmail)
+	s = cardPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if !luhn(match) {
+			return match
+		}
+		return maskCard(match)
+	})
+	return phonePattern.ReplaceAllStringFunc(s, maskPhone)
+}
+
+// fields returns fields with the sensitive values masked, the slice is copied
+// only when a field changes
+func (r *redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	out := fields
+	for i, f := range fields {
+		g, changed := r.field(f)
+		if !changed {
+			continue
+		}
+		if &out[0] == &fields[0] {
+			out = append([]zapcore.Field(nil), fields...)
+		}
+		out[i] = g
+	}
+	return out
+}
+
+func (r *redactor) field(f zapcore.Field) (zapcore.Field, bool) {
+	m := r.masker(f.Key)
+	switch f.Type {
+	case zapcore.StringType:
+		if m != nil {
+			return zap.String(f.Key, m(f.String)), true
+		}
+		if s := r.text(f.String); s != f.String {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.ByteStringType, zapcore.StringerType, zapcore.ErrorType:
+		raw := fieldText(f)
+		if m != nil {
+			return zap.String(f.Key, m(raw)), true
+		}
+		if s := r.text(raw); s != raw {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.ReflectType:
+		if m != nil {
+			return zap.String(f.Key, redacted), true
+		}
+		if v, ok := r.value(f.Interface); ok {
+			return zap.Any(f.Key, v), true
+		}
+	default:
+		// Numbers and booleans are only sensitive by key, e.g. a phone logged as int
+		if m != nil && f.Type != zapcore.UnknownType {
+			return zap.String(f.Key, redacted), true
+		}
+	}
+	return f, false
+}
+
+func fieldText(f zapcore.Field) string {
+	switch v := f.Interface.(type) {
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// value masks the sensitive keys of a structured value such as a request
+// payload, by walking its JSON form. It reports false when nothing changed.
+func (r *redactor) value(v interface{}) (interface{}, bool) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var tree interface{}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil, false
+	}
+	changed := false
+	tree = r.walk(tree, &changed)
+	return tree, changed
+}
+
+func (r *redactor) walk(v interface{}, changed *bool) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, child := range t {
+			if m := r.masker(key); m != nil && child != nil {
+				t[key] = m(fmt.Sprint(child))
+				*changed = true
+				continue
+			}
+			t[key] = r.walk(child, changed)
+		}
+	case []interface{}:
+		for i, child := range t {
+			t[i] = r.walk(child, changed)
+		}
+	case string:
+		if s := r.text(t); s != t {
+			*changed = true
+			return s
+		}
+	}
+	return v
+}
+
+func redactAll(string) string {
+	return redacted
+}
+
+// maskPhone keeps the first 3 and last 4 digits, e.g. 138****5678
+func maskPhone(s string) string {
+	if len(s) < 8 {
+		return redacted
+	}
+	return s[:3] + strings.Repeat("*", len(s)-7) + s[len(s)-4:]
+}
+
+// maskEmail keeps the first character of the local part and the domain
+func maskEmail(s string) string {
+	at := strings.LastIndexByte(s, '@')
+	if at <= 0 {
+		return redacted
+	}
+	return s[:1] + "***" + s[at:]
+}
+
+// maskCard keeps the last 4 digits of a card number
+func maskCard(s string) string {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	if len(digits) < 8 {
+		return redacted
+	}
+	return "****" + digits[len(digits)-4:]
+}
+
+// luhn reports whether the digits of s pass the Luhn checksum of card numbers,
+// which avoids masking order numbers and timestamps of the same length
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactCore masks sensitive data of the entries written to the wrapped core
+type redactCore struct {
+	zapcore.Core
+	r *redactor
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.r.fields(fields)), r: c.r}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.r.text(ent.Message)
+	return c.Core.Write(ent, c.r.fields(fields))
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+// newSyslogWriter reports that syslog is not available on this platform
+func newSyslogWriter(network, addr, tag string) (io.Writer, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
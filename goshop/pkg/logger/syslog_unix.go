@@ -0,0 +1,15 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// newSyslogWriter connects to the local syslog daemon, or to addr over network
+// when given, tagging entries with the service name. Levels are carried by the
+// encoded entries, all of them are sent with the info priority.
+func newSyslogWriter(network, addr, tag string) (io.Writer, error) {
+	return syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_LOCAL0, tag)
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor records the count and latency of the gRPC calls handled
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.grpcHandled.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		m.grpcDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor records the count and latency of the gRPC calls made,
+// install it with grpcclient.WithDialOptions(grpc.WithChainUnaryInterceptor(...))
+func (m *Metrics) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.grpcClientCall.WithLabelValues(method, status.Code(err).String()).Inc()
+		m.grpcClientTime.WithLabelValues(method).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
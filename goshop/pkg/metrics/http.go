@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests matching no route, so that scanners probing
+// random paths cannot explode the label cardinality
+const unmatchedRoute = "unmatched"
+
+// routeKey is the context key of the route pattern set with SetRoute
+const routeKey = "metrics.route"
+
+// SetRoute labels the request with the route pattern, for handlers matching
+// routes themselves, e.g. a NoRoute handler, for which gin has no pattern
+func SetRoute(c *gin.Context, pattern string) {
+	c.Set(routeKey, pattern)
+}
+
+// GinMiddleware records the count, latency and concurrency of HTTP requests,
+// labelled with the route pattern rather than the raw path
+func (m *Metrics) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.httpInFlight.Inc()
+		defer m.httpInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.GetString(routeKey)
+		}
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		m.httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Register serves the metrics on GET /metrics of router
+func (m *Metrics) Register(router gin.IRouter) {
+	router.GET("/metrics", gin.WrapH(m.Handler()))
+}
@@ -0,0 +1,172 @@
+// Package metrics exposes Prometheus metrics of a service: HTTP and gRPC
+// server/client instrumentation, database and Redis pool gauges and business
+// counters, served on /metrics.
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the name of every metric
+const namespace = "goshop"
+
+// Metrics holds the registry of a service and its standard collectors. Every
+// metric carries a constant service label.
+type Metrics struct {
+	registry   *prometheus.Registry
+	registerer prometheus.Registerer
+
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	httpInFlight   prometheus.Gauge
+	grpcHandled    *prometheus.CounterVec
+	grpcDuration   *prometheus.HistogramVec
+	grpcClientCall *prometheus.CounterVec
+	grpcClientTime *prometheus.HistogramVec
+
+	mu         sync.Mutex
+	counters   map[string]*prometheus.CounterVec
+	gauges     map[string]*prometheus.GaugeVec
+	histograms map[string]*prometheus.HistogramVec
+}
+
+// New creates the metrics of service, registering the Go runtime and process
+// collectors
+func New(service string) *Metrics {
+	registry := prometheus.NewRegistry()
+	m := &Metrics{
+		registry:   registry,
+		registerer: prometheus.WrapRegistererWith(prometheus.Labels{"service": service}, registry),
+		counters:   make(map[string]*prometheus.CounterVec),
+		gauges:     make(map[string]*prometheus.GaugeVec),
+		histograms: make(map[string]*prometheus.HistogramVec),
+	}
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	m.httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests handled, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+	m.httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	m.httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests being handled.",
+	})
+	m.grpcHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_server",
+		Name:      "handled_total",
+		Help:      "gRPC calls handled, by method and status code.",
+	}, []string{"method", "code"})
+	m.grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_server",
+		Name:      "handling_seconds",
+		Help:      "gRPC call handling latency, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	m.grpcClientCall = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "handled_total",
+		Help:      "gRPC calls made, by method and status code.",
+	}, []string{"method", "code"})
+	m.grpcClientTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "handling_seconds",
+		Help:      "gRPC call latency seen by the caller, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	m.registerer.MustRegister(
+		m.httpRequests, m.httpDuration, m.httpInFlight,
+		m.grpcHandled, m.grpcDuration,
+		m.grpcClientCall, m.grpcClientTime,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Counter returns the business counter called name, creating it on first use,
+// e.g. Counter("orders_created_total", "Orders created.", "channel").
+// Later calls with the same name return the same counter.
+func (m *Metrics) Counter(name, help string, labels ...string) *prometheus.CounterVec {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c, ok := m.counters[name]; ok {
+		return c
+	}
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	m.registerer.MustRegister(c)
+	m.counters[name] = c
+	return c
+}
+
+// Gauge returns the business gauge called name, creating it on first use
+func (m *Metrics) Gauge(name, help string, labels ...string) *prometheus.GaugeVec {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if g, ok := m.gauges[name]; ok {
+		return g
+	}
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	m.registerer.MustRegister(g)
+	m.gauges[name] = g
+	return g
+}
+
+// Histogram returns the business histogram called name, creating it on first
+// use. buckets are the upper bounds of the buckets, prometheus.DefBuckets when nil.
+func (m *Metrics) Histogram(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if h, ok := m.histograms[name]; ok {
+		return h
+	}
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labels)
+	m.registerer.MustRegister(h)
+	m.histograms[name] = h
+	return h
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// RegisterDB exports the connection pool statistics of db, labelled with name
+func (m *Metrics) RegisterDB(db *gorm.DB, name string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("get database handle: %w", err)
+	}
+	return m.registerer.Register(collectors.NewDBStatsCollector(sqlDB, name))
+}
+
+// RegisterRedis exports the connection pool statistics of rdb
+func (m *Metrics) RegisterRedis(rdb *redis.Client) error {
+	return m.registerer.Register(&redisPoolCollector{rdb: rdb})
+}
+
+var (
+	redisHits = prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", "hits_total"),
+		"Times a free connection was found in the pool.", nil, nil)
+	redisMisses = prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", "misses_total"),
+		"Times a free connection was not found in the pool.", nil, nil)
+	redisTimeouts = prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", "timeouts_total"),
+		"Times waiting for a connection timed out.", nil, nil)
+	redisTotalConns = prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", "connections"),
+		"Connections in the pool.", nil, nil)
+	redisIdleConns = prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", "idle_connections"),
+		"Idle connections in the pool.", nil, nil)
+	redisStaleConns = prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", "stale_connections_total"),
+		"Stale connections removed from the pool.", nil, nil)
+)
+
+// redisPoolCollector reads the pool statistics of a Redis client on each scrape
+type redisPoolCollector struct {
+	rdb *redis.Client
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisHits
+	ch <- redisMisses
+	ch <- redisTimeouts
+	ch <- redisTotalConns
+	ch <- redisIdleConns
+	ch <- redisStaleConns
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.rdb.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisHits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(redisMisses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(redisTimeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisTotalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(redisIdleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(redisStaleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers of a job callback
+const (
+	HeaderToken       = "X-Scheduler-Token"        // shared secret configured as scheduler.callbackToken
+	HeaderJob         = "X-Scheduler-Job"          // name of the job
+	HeaderExecutionID = "X-Scheduler-Execution-Id" // ID of the execution, the same across the attempts of a run
+	HeaderAttempt     = "X-Scheduler-Attempt"      // attempt of the run, starting at 1
+	HeaderScheduledAt = "X-Scheduler-Scheduled-At" // RFC 3339 time the run was due
+)
+
+// RunFunc runs a job with the payload it was registered with. Returning an error
+// fails the run, which the scheduler retries according to the job's policy.
+type RunFunc func(ctx context.Context, payload json.RawMessage) error
+
+// Callback serves the callback of a job. It rejects requests not carrying token
+// unless token is empty, and responds 204 when run succeeds and 500 otherwise.
+// Runs may be repeated, e.g. when the response is lost, so run must be
+// idempotent.
+func Callback(token string, run RunFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader(HeaderToken)), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid scheduler token"})
+			return
+		}
+		payload, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := run(c.Request.Context(), json.RawMessage(payload)); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,86 @@
+// Package scheduler lets services register jobs with the scheduler service and
+// serve the callbacks that run them. A job runs on a cron schedule or once at a
+// given time; when it is due the scheduler POSTs its payload to a path of the
+// owning service and retries failed runs according to the job's retry policy.
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RetryPolicy controls how a failed run is retried. The delay before retry n is
+// Backoff doubled n-1 times.
+type RetryPolicy struct {
+	MaxAttempts    int `json:"max_attempts"`    // attempts of one run including the first, at least 1
+	BackoffSeconds int `json:"backoff_seconds"` // delay before the first retry
+}
+
+// Job describes a job registered by a service. Exactly one of Schedule and
+// RunAt is set.
+type Job struct {
+	Schedule       string          `json:"schedule,omitempty"` // cron expression, see package cron
+	Timezone       string          `json:"timezone,omitempty"` // IANA zone the schedule is evaluated in, UTC by default
+	RunAt          *time.Time      `json:"run_at,omitempty"`   // time of a one-off job
+	Service        string          `json:"service"`            // service receiving the callback
+	Path           string          `json:"path"`               // callback path on that service, e.g. /internal/jobs/expire-points
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	TimeoutSeconds int             `json:"timeout_seconds,omitempty"`
+	Retry          *RetryPolicy    `json:"retry,omitempty"`
+}
+
+// Client registers jobs with the scheduler service over HTTP
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a client of the scheduler service at baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Register creates or replaces the job called name. Names are global, services
+// prefix them with their own name, e.g. "marketing.expire-points". Registering
+// an unchanged job on every startup is safe and keeps its history.
+func (c *Client) Register(ctx context.Context, name string, job *Job) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPut, name, body)
+}
+
+// Delete removes the job called name and its pending runs
+func (c *Client) Delete(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, name, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, name string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api/v1/scheduler/jobs/"+url.PathEscape(name), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("scheduler returned status %d for job %s", resp.StatusCode, name)
+	}
+	return nil
+}
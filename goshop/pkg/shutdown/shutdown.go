@@ -0,0 +1,255 @@
+// Package shutdown coordinates the graceful shutdown of a service. Resources
+// register hooks in a phase as they are created, and on SIGINT or SIGTERM the
+// phases run in order: traffic stops being routed to the instance, servers drain
+// their in-flight requests, background work and buffered data are flushed, and
+// finally connections are closed.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// Phase orders shutdown hooks
+type Phase int
+
+const (
+	// PhaseTraffic stops new traffic, e.g. by failing readiness probes
+	PhaseTraffic Phase = iota
+	// PhaseServers drains the HTTP and gRPC servers
+	PhaseServers
+	// PhaseFlush stops workers and flushes outboxes, NATS and traces
+	PhaseFlush
+	// PhaseClose closes database and Redis connections
+	PhaseClose
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseTraffic:
+		return "traffic"
+	case PhaseServers:
+		return "servers"
+	case PhaseFlush:
+		return "flush"
+	case PhaseClose:
+		return "close"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// Default timeouts
+const (
+	defaultHookTimeout = 5 * time.Second
+	defaultTimeout     = 30 * time.Second
+)
+
+type hook struct {
+	phase   Phase
+	name    string
+	timeout time.Duration
+	fn      func(ctx context.Context) error
+}
+
+// Option configures a Coordinator
+type Option func(*Coordinator)
+
+// WithTimeout bounds the whole shutdown, 30 seconds by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Coordinator) {
+		c.timeout = timeout
+	}
+}
+
+// WithHookTimeout sets the timeout of hooks added without one, 5 seconds by
+// default
+func WithHookTimeout(timeout time.Duration) Option {
+	return func(c *Coordinator) {
+		c.hookTimeout = timeout
+	}
+}
+
+// WithDrainDelay waits after the traffic phase so that load balancers notice
+// the failing readiness probe before the servers stop accepting connections
+func WithDrainDelay(delay time.Duration) Option {
+	return func(c *Coordinator) {
+		c.drainDelay = delay
+	}
+}
+
+// Coordinator runs the shutdown hooks of a service
+type Coordinator struct {
+	log         *logger.Logger
+	timeout     time.Duration
+	hookTimeout time.Duration
+	drainDelay  time.Duration
+
+	mu    sync.Mutex
+	hooks []hook
+	once  sync.Once
+	err   error
+}
+
+// New creates a Coordinator without hooks
+func New(log *logger.Logger, opts ...Option) *Coordinator {
+	c := &Coordinator{
+		log:         log,
+		timeout:     defaultTimeout,
+		hookTimeout: defaultHookTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Add registers fn to run in phase with at most timeout, 0 selecting the default
+// hook timeout. Within a phase hooks run one at a time in reverse order of
+// registration, like deferred calls, so a resource is released before the ones
+// it was built on.
+func (c *Coordinator) Add(phase Phase, name string, timeout time.Duration, fn func(ctx context.Context) error) {
+	if timeout <= 0 {
+		timeout = c.hookTimeout
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, hook{phase: phase, name: name, timeout: timeout, fn: fn})
+}
+
+// Wait blocks until one of signals is received, SIGINT and SIGTERM when none
+// is given, then runs the shutdown
+func (c *Coordinator) Wait(signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, signals...)
+	sig := <-quit
+	signal.Stop(quit)
+
+	ctx := context.Background()
+	c.log.Info(ctx, "Received shutdown signal", zap.String("signal", sig.String()))
+	return c.Shutdown(ctx)
+}
+
+// Shutdown runs every hook once, even when some fail, and returns their errors
+// joined. Later calls return the result of the first one.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.once.Do(func() {
+		c.err = c.run(ctx)
+	})
+	return c.err
+}
+
+func (c *Coordinator) run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	c.mu.Lock()
+	hooks := make([]hook, len(c.hooks))
+	for i, h := range c.hooks {
+		hooks[len(hooks)-1-i] = h
+	}
+	c.mu.Unlock()
+	sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].phase < hooks[j].phase })
+
+	start := time.Now()
+	var errs []error
+	for i, h := range hooks {
+		if err := c.runHook(ctx, h); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+		}
+		lastOfTraffic := h.phase == PhaseTraffic && (i+1 == len(hooks) || hooks[i+1].phase != PhaseTraffic)
+		if lastOfTraffic && c.drainDelay > 0 {
+			select {
+			case <-time.After(c.drainDelay):
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		c.log.Warn(ctx, "Shutdown completed with errors", zap.Duration("duration", time.Since(start)), zap.Error(err))
+	} else {
+		c.log.Info(ctx, "Shutdown completed", zap.Duration("duration", time.Since(start)))
+	}
+	return err
+}
+
+func (c *Coordinator) runHook(ctx context.Context, h hook) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// The hook ignored its context, leave it behind rather than blocking
+		// the remaining hooks
+		err = ctx.Err()
+	}
+	if err != nil {
+		c.log.Warn(ctx, "Shutdown hook failed", zap.String("phase", h.phase.String()), zap.String("hook", h.name), zap.Error(err))
+	} else {
+		c.log.Debug(ctx, "Shutdown hook completed", zap.String("phase", h.phase.String()), zap.String("hook", h.name))
+	}
+	return err
+}
+
+// Func adapts a function without context or error to a hook
+func Func(fn func()) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		fn()
+		return nil
+	}
+}
+
+// Close adapts a Close method to a hook
+func Close(fn func() error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return fn()
+	}
+}
+
+// HTTPServer drains srv, waiting for in-flight requests until the hook times out
+func HTTPServer(srv *http.Server) func(ctx context.Context) error {
+	return srv.Shutdown
+}
+
+// GRPCServer drains s, and stops it abruptly when in-flight calls outlast the
+// hook timeout
+func GRPCServer(s *grpc.Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			s.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package storage stores uploaded files in S3 compatible object storage, AWS S3
+// in production and MinIO in development. Objects are written with a key and
+// read by clients through their public URL.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/yourusername/goshop/pkg/config"
+)
+
+// Store is an object store
+type Store interface {
+	// Put writes the object at key, replacing any previous one
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	// Delete removes the object at key, a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of the object at key
+	URL(key string) string
+	// Key returns the key of the object at url, false when url is not an
+	// object of the store
+	Key(url string) (string, bool)
+}
+
+// S3 stores objects in a bucket of an S3 compatible service
+type S3 struct {
+	client    *s3.Client
+	bucket    string
+	publicURL string
+}
+
+// New creates the store of cfg. Credentials come from cfg when set, from the
+// default AWS chain otherwise: environment, shared files or the instance role.
+func New(ctx context.Context, cfg config.StorageConfig) (*S3, error) {
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(cfg.Region)}
+	if cfg.AccessKeyID != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load AWS configuration: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
+	return &S3{
+		client:    client,
+		bucket:    cfg.Bucket,
+		publicURL: strings.TrimSuffix(cfg.PublicURL, "/"),
+	}, nil
+}
+
+// Put writes the object at key. Objects are immutable once written, callers
+// give new content a new key, so clients and CDNs may cache them for long.
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:       aws.String(s.bucket),
+		Key:          aws.String(key),
+		Body:         body,
+		ContentType:  aws.String(contentType),
+		CacheControl: aws.String("public, max-age=31536000, immutable"),
+	})
+	if err != nil {
+		return fmt.Errorf("put object %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the object at key
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return errors.New("empty object key")
+	}
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("delete object %s: %w", key, err)
+	}
+	return nil
+}
+
+// URL returns the public URL of the object at key
+func (s *S3) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+// Key returns the key of the object at url
+func (s *S3) Key(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, s.publicURL+"/")
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
+}
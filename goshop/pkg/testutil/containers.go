@@ -0,0 +1,161 @@
+// Package testutil helps writing integration tests of the services. It starts
+// throwaway Postgres, Redis and NATS containers with testcontainers, inserts
+// fixture rows and calls HTTP handlers and gRPC servers in process. Containers
+// are removed when the test ends; tests using them are skipped with -short or
+// when Docker is not available.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/database"
+	"github.com/yourusername/goshop/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Container images, matching the versions of docker-compose.yml
+const (
+	postgresImage = "postgres:16-alpine"
+	redisImage    = "redis:7-alpine"
+	natsImage     = "nats:2.9-alpine"
+)
+
+// startupTimeout bounds the time a container may take to become ready
+const startupTimeout = time.Minute
+
+// startContainer starts req and returns the host address of port, the container
+// is terminated when the test ends
+func startContainer(t testing.TB, req testcontainers.ContainerRequest, port nat.Port) (string, int) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("integration test skipped with -short")
+	}
+
+	ctx := context.Background()
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err == nil {
+		err = provider.Health(ctx)
+	}
+	if err != nil {
+		t.Skipf("integration test skipped without Docker: %v", err)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("start %s: %v", req.Image, err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(context.Background()); err != nil {
+			t.Logf("terminate %s: %v", req.Image, err)
+		}
+	})
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("get host of %s: %v", req.Image, err)
+	}
+	mapped, err := container.MappedPort(ctx, port)
+	if err != nil {
+		t.Fatalf("get port of %s: %v", req.Image, err)
+	}
+	var p int
+	if _, err := fmt.Sscan(mapped.Port(), &p); err != nil {
+		t.Fatalf("parse port of %s: %v", req.Image, err)
+	}
+	return host, p
+}
+
+// Postgres starts a Postgres container and returns its connection settings
+func Postgres(t testing.TB) config.DatabaseConfig {
+	t.Helper()
+	cfg := config.DatabaseConfig{
+		User:     "goshop",
+		Password: "goshop",
+		DBName:   "goshop_test",
+		SSLMode:  "disable",
+	}
+	cfg.Host, cfg.Port = startContainer(t, testcontainers.ContainerRequest{
+		Image:        postgresImage,
+		ExposedPorts: []string{"5432/tcp"},
+		Env: map[string]string{
+			"POSTGRES_USER":     cfg.User,
+			"POSTGRES_PASSWORD": cfg.Password,
+			"POSTGRES_DB":       cfg.DBName,
+		},
+		// Postgres restarts once after running the init scripts
+		WaitingFor: wait.ForLog("database system is ready to accept connections").
+			WithOccurrence(2).
+			WithStartupTimeout(startupTimeout),
+	}, "5432/tcp")
+	return cfg
+}
+
+// DB starts a Postgres container and opens it with database.Open, so that opts
+// apply the SQL migrations or AutoMigrate the models of the service under test
+func DB(t testing.TB, opts ...database.Option) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(Postgres(t), Logger(t), opts...)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		database.Close(db)
+	})
+	return db
+}
+
+// Redis starts a Redis container and returns a client connected to it
+func Redis(t testing.TB) *redis.Client {
+	t.Helper()
+	host, port := startContainer(t, testcontainers.ContainerRequest{
+		Image:        redisImage,
+		ExposedPorts: []string{"6379/tcp"},
+		WaitingFor:   wait.ForLog("Ready to accept connections").WithStartupTimeout(startupTimeout),
+	}, "6379/tcp")
+
+	rdb := redis.NewClient(&redis.Options{Addr: fmt.Sprintf("%s:%d", host, port)})
+	t.Cleanup(func() {
+		rdb.Close()
+	})
+	return rdb
+}
+
+// NATS starts a NATS server with JetStream enabled and returns a connection to it
+func NATS(t testing.TB) *nats.Conn {
+	t.Helper()
+	host, port := startContainer(t, testcontainers.ContainerRequest{
+		Image:        natsImage,
+		ExposedPorts: []string{"4222/tcp"},
+		Cmd:          []string{"-js"},
+		WaitingFor:   wait.ForLog("Server is ready").WithStartupTimeout(startupTimeout),
+	}, "4222/tcp")
+
+	nc, err := nats.Connect(fmt.Sprintf("nats://%s:%d", host, port))
+	if err != nil {
+		t.Fatalf("connect to nats: %v", err)
+	}
+	t.Cleanup(nc.Close)
+	return nc
+}
+
+// Logger returns a logger for the code under test, logging at debug level
+func Logger(t testing.TB) *logger.Logger {
+	t.Helper()
+	log, err := logger.New("test", "debug")
+	if err != nil {
+		t.Fatalf("create logger: %v", err)
+	}
+	return log
+}
@@ -0,0 +1,45 @@
+package testutil
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufSize is the size of the in-memory connection buffer
+const bufSize = 1 << 20
+
+// GRPC serves the services registered by register on an in-memory listener and
+// returns a client connection to it, both closed when the test ends. Pass the
+// interceptors of the service main in opts to test them along.
+func GRPC(t testing.TB, register func(s *grpc.Server), opts ...grpc.ServerOption) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(bufSize)
+	server := grpc.NewServer(opts...)
+	register(server)
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			t.Logf("serve gRPC: %v", err)
+		}
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial gRPC: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return conn
+}
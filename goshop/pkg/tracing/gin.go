@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDHeader returns the trace ID of the request to the client, so that a
+// failing request reported by a user can be found in the tracing backend
+const TraceIDHeader = "X-Trace-ID"
+
+// GinMiddleware creates a server span for each request, continuing the trace of
+// the caller when the request carries trace context headers
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method + " " + route
+		if route == "" {
+			name = c.Request.Method
+		}
+		ctx, span := Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethod(c.Request.Method),
+				semconv.HTTPRoute(route),
+			),
+		)
+		defer span.End()
+
+		ctx = bridge(ctx, span)
+		c.Request = c.Request.WithContext(ctx)
+		if sc := span.SpanContext(); sc.IsValid() {
+			c.Writer.Header().Set(TraceIDHeader, sc.TraceID().String())
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}
+}
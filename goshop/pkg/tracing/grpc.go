@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataCarrier adapts gRPC metadata to the OpenTelemetry propagators
+type metadataCarrier metadata.MD
+
+func (mc metadataCarrier) Get(key string) string {
+	values := metadata.MD(mc).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (mc metadataCarrier) Set(key, value string) {
+	metadata.MD(mc).Set(key, value)
+}
+
+func (mc metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for key := range mc {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// UnaryServerInterceptor creates a server span for each gRPC call, continuing the
+// trace of the caller
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			md = metadata.MD{}
+		}
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+		ctx, span := Tracer().Start(ctx, info.FullMethod,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.RPCSystemKey.String("grpc")),
+		)
+		defer span.End()
+
+		resp, err := handler(bridge(ctx, span), req)
+		recordStatus(span, err)
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor creates a client span for each gRPC call made and sends
+// the trace context along, install it with
+// grpcclient.WithDialOptions(grpc.WithChainUnaryInterceptor(...))
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := Tracer().Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.RPCSystemKey.String("grpc")),
+		)
+		defer span.End()
+
+		md, ok := metadata.FromOutgoingContext(ctx)
+		if ok {
+			md = md.Copy()
+		} else {
+			md = metadata.MD{}
+		}
+		otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+
+		err := invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
+		recordStatus(span, err)
+		return err
+	}
+}
+
+func recordStatus(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, code.String())
+	}
+}
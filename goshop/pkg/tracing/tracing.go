@@ -0,0 +1,133 @@
+// Package tracing configures OpenTelemetry for a service from TraceConfig: spans
+// are exported over OTLP/HTTP with the service name and environment as resource
+// attributes, Gin and gRPC middleware create a span per request, and the trace and
+// span IDs are copied into the pkg/logger context so that logs carry them.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/url"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation names the tracer of the spans created by this package
+const instrumentation = "github.com/yourusername/goshop/pkg/tracing"
+
+// ShutdownFunc flushes the pending spans and stops the exporter
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup installs the global tracer provider and W3C trace context propagation.
+// When tracing is disabled only the propagation is installed, so that trace
+// headers still flow through the service, and spans are not recorded.
+func Setup(ctx context.Context, cfg config.TraceConfig, serviceName, environment string) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.DeploymentEnvironment(environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create trace resource: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// newExporter creates an OTLP/HTTP exporter sending to endpoint, a URL such as
+// http://collector:4318/v1/traces
+func newExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid trace endpoint %q", endpoint)
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	if u.Path != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+	}
+	if u.Scheme != "https" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create trace exporter: %w", err)
+	}
+	return exporter, nil
+}
+
+// Tracer returns the tracer used by the package middleware
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Start starts a span as a child of the span in ctx and bridges its IDs into the
+// logger context. Use it to trace a unit of work inside a request, e.g. an event
+// handler or a call to an external API.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(ctx, name, opts...)
+	return bridge(ctx, span), span
+}
+
+// EnsureTrace returns ctx with a trace context, starting a new unsampled trace
+// with random IDs when ctx has none, i.e. when tracing is disabled and the
+// caller sent no traceparent header. The IDs are bridged into the logger
+// context, and propagators send the trace along to the services called with ctx.
+func EnsureTrace(ctx context.Context) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	if _, err := rand.Read(traceID[:]); err != nil {
+		return ctx
+	}
+	if _, err := rand.Read(spanID[:]); err != nil {
+		return ctx
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, Remote: true})
+	ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+	ctx = logger.WithTraceID(ctx, traceID.String())
+	return logger.WithSpanID(ctx, spanID.String())
+}
+
+// bridge copies the trace and span IDs of span into the logger context keys. An
+// unsampled or no-op span leaves ctx untouched, keeping any trace ID already set
+// from an incoming request header.
+func bridge(ctx context.Context, span trace.Span) context.Context {
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		return ctx
+	}
+	ctx = logger.WithTraceID(ctx, sc.TraceID().String())
+	return logger.WithSpanID(ctx, sc.SpanID().String())
+}
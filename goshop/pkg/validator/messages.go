@@ -0,0 +1,98 @@
+package validator
+
+import "strings"
+
+// DefaultLocale is used when the client accepts none of the supported locales
+const DefaultLocale = "zh-CN"
+
+// messages is the message catalog of a locale, templates use {field} and {param}
+type messages struct {
+	invalid      string
+	emptyBody    string
+	typeMismatch string
+	fallback     string
+	rules        map[string]string
+}
+
+var catalogs = map[string]*messages{
+	"zh-CN": {
+		invalid:      "请求参数错误",
+		emptyBody:    "请求体不能为空",
+		typeMismatch: "{field} 必须是 {param} 类型",
+		fallback:     "{field} 格式不正确",
+		rules: map[string]string{
+			"required": "{field} 为必填项",
+			"min":      "{field} 不能小于 {param}",
+			"max":      "{field} 不能大于 {param}",
+			"len":      "{field} 必须等于 {param}",
+			"min_len":  "{field} 长度不能少于 {param}",
+			"max_len":  "{field} 长度不能超过 {param}",
+			"len_len":  "{field} 长度必须为 {param}",
+			"gt":       "{field} 必须大于 {param}",
+			"gte":      "{field} 不能小于 {param}",
+			"lt":       "{field} 必须小于 {param}",
+			"lte":      "{field} 不能大于 {param}",
+			"oneof":    "{field} 必须是 [{param}] 之一",
+			"email":    "{field} 不是有效的邮箱地址",
+			"url":      "{field} 不是有效的 URL",
+			"phone":    "{field} 不是有效的手机号",
+			"cnid":     "{field} 不是有效的身份证号",
+			"sku":      "{field} 不是有效的 SKU 编码",
+		},
+	},
+	"en-US": {
+		invalid:      "Invalid request parameters",
+		emptyBody:    "Request body is required",
+		typeMismatch: "{field} must be of type {param}",
+		fallback:     "{field} is invalid",
+		rules: map[string]string{
+			"required": "{field} is required",
+			"min":      "{field} must be at least {param}",
+			"max":      "{field} must be at most {param}",
+			"len":      "{field} must be {param}",
+			"min_len":  "{field} must be at least {param} characters or items long",
+			"max_len":  "{field} must be at most {param} characters or items long",
+			"len_len":  "{field} must be exactly {param} characters or items long",
+			"gt":       "{field} must be greater than {param}",
+			"gte":      "{field} must be at least {param}",
+			"lt":       "{field} must be less than {param}",
+			"lte":      "{field} must be at most {param}",
+			"oneof":    "{field} must be one of [{param}]",
+			"email":    "{field} must be a valid email address",
+			"url":      "{field} must be a valid URL",
+			"phone":    "{field} must be a valid mobile number",
+			"cnid":     "{field} must be a valid identity number",
+			"sku":      "{field} must be a valid SKU code",
+		},
+	},
+}
+
+// catalog returns the messages of locale, falling back to the default locale
+func catalog(locale string) *messages {
+	if msgs, ok := catalogs[locale]; ok {
+		return msgs
+	}
+	return catalogs[DefaultLocale]
+}
+
+// Locale picks the supported locale preferred by an Accept-Language header, e.g.
+// "en-GB,en;q=0.9" gives "en-US". Languages are taken in header order, quality
+// values are not used to reorder them.
+func Locale(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if tag == "" {
+			continue
+		}
+		if _, ok := catalogs[tag]; ok {
+			return tag
+		}
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		for locale := range catalogs {
+			if strings.HasPrefix(strings.ToLower(locale), lang+"-") {
+				return locale
+			}
+		}
+	}
+	return DefaultLocale
+}
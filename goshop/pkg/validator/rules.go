@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"regexp"
+	"time"
+
+	playground "github.com/go-playground/validator/v10"
+)
+
+var (
+	// phonePattern matches mainland China mobile numbers, with an optional +86 prefix
+	phonePattern = regexp.MustCompile(`^(\+86)?1[3-9]\d{9}$`)
+	// skuPattern matches SKU codes: upper case letters, digits and dashes,
+	// starting with a letter or digit, 4 to 32 characters
+	skuPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9-]{3,31}$`)
+	// cnIDPattern matches the shape of an 18 character resident identity number
+	cnIDPattern = regexp.MustCompile(`^\d{17}[\dX]$`)
+)
+
+// rules are the custom rules shared by the services, usable in binding tags,
+// e.g. `binding:"required,phone"`
+var rules = map[string]playground.Func{
+	"phone": stringRule(phonePattern.MatchString),
+	"sku":   stringRule(skuPattern.MatchString),
+	"cnid":  stringRule(validCNID),
+}
+
+// stringRule adapts a string check to a validator rule, empty values pass so that
+// the rule can be combined with omitempty or required
+func stringRule(valid func(string) bool) playground.Func {
+	return func(fl playground.FieldLevel) bool {
+		s := fl.Field().String()
+		return s == "" || valid(s)
+	}
+}
+
+// cnIDWeights are the checksum weights of the first 17 digits of an identity number
+var cnIDWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+// cnIDCheck maps the weighted sum modulo 11 to the check character
+const cnIDCheck = "10X98765432"
+
+// validCNID checks an 18 character resident identity number: its birth date and
+// its ISO 7064 MOD 11-2 check character
+func validCNID(id string) bool {
+	if !cnIDPattern.MatchString(id) {
+		return false
+	}
+	birth, err := time.Parse("20060102", id[6:14])
+	if err != nil || birth.After(time.Now()) {
+		return false
+	}
+	sum := 0
+	for i, w := range cnIDWeights {
+		sum += int(id[i]-'0') * w
+	}
+	return id[17] == cnIDCheck[sum%11]
+}
@@ -0,0 +1,154 @@
+// Package validator validates request payloads with struct tags and a set of
+// shared custom rules, and converts failures into pkg/errors BadRequest errors
+// carrying one message per field, localized from the Accept-Language header.
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	playground "github.com/go-playground/validator/v10"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+)
+
+var setupOnce sync.Once
+
+// engine returns the validator used by Gin binding, with the custom rules
+// registered and fields named after their JSON key
+func engine() *playground.Validate {
+	v, ok := binding.Validator.Engine().(*playground.Validate)
+	if !ok {
+		// Gin always uses go-playground/validator, only a replaced
+		// binding.Validator could get here
+		panic("validator: gin binding does not use go-playground/validator")
+	}
+	setupOnce.Do(func() {
+		v.RegisterTagNameFunc(jsonName)
+		for tag, rule := range rules {
+			if err := v.RegisterValidation(tag, rule); err != nil {
+				panic("validator: register rule " + tag + ": " + err.Error())
+			}
+		}
+	})
+	return v
+}
+
+// jsonName names a field after its JSON key, so that error fields match the
+// request payload
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
+
+// BindJSON decodes the JSON body of the request into obj and validates it. The
+// returned error is a BadRequest localized for the client, ready to be
+// responded as is.
+func BindJSON(c *gin.Context, obj interface{}) error {
+	engine()
+	if err := c.ShouldBindJSON(obj); err != nil {
+		return Translate(err, Locale(c.GetHeader("Accept-Language")))
+	}
+	return nil
+}
+
+// BindQuery decodes the query string of the request into obj and validates it
+func BindQuery(c *gin.Context, obj interface{}) error {
+	engine()
+	if err := c.ShouldBindQuery(obj); err != nil {
+		return Translate(err, Locale(c.GetHeader("Accept-Language")))
+	}
+	return nil
+}
+
+// Struct validates obj outside of a request, e.g. in a service or a consumer
+func Struct(obj interface{}, locale string) error {
+	if err := engine().Struct(obj); err != nil {
+		return Translate(err, locale)
+	}
+	return nil
+}
+
+// ErrorTranslator converts validation errors pushed with c.Error, for the
+// error middleware of pkg/errors. Errors that are already application errors are
+// left as is.
+func ErrorTranslator(c *gin.Context, err error) (*apperrors.Error, bool) {
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		return nil, false
+	}
+	var verrs playground.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &verrs) || errors.As(err, &typeErr) {
+		return Translate(err, Locale(c.GetHeader("Accept-Language"))), true
+	}
+	return nil, false
+}
+
+// Translate converts a binding or validation error into a BadRequest with
+// per-field messages in locale
+func Translate(err error, locale string) *apperrors.Error {
+	msgs := catalog(locale)
+
+	var verrs playground.ValidationErrors
+	if errors.As(err, &verrs) {
+		fields := make(map[string]string, len(verrs))
+		for _, fe := range verrs {
+			fields[fieldPath(fe)] = fieldMessage(msgs, fe)
+		}
+		return apperrors.NewBadRequest(msgs.invalid, err).WithFields(fields)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return apperrors.NewBadRequest(msgs.invalid, err).WithFields(map[string]string{
+			typeErr.Field: format(msgs.typeMismatch, typeErr.Field, typeErr.Type.String()),
+		})
+	}
+	if errors.Is(err, io.EOF) {
+		return apperrors.NewBadRequest(msgs.emptyBody, err)
+	}
+	return apperrors.NewBadRequest(msgs.invalid, err)
+}
+
+// fieldPath returns the path of the failing field without the struct name, e.g.
+// "items[0].sku" for ContentRequest.items[0].sku
+func fieldPath(fe playground.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.IndexByte(ns, '.'); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+func fieldMessage(msgs *messages, fe playground.FieldError) string {
+	tmpl, ok := msgs.rules[fe.Tag()]
+	if !ok {
+		tmpl = msgs.fallback
+	}
+	// Length rules read differently for strings and collections than for numbers
+	switch fe.Tag() {
+	case "min", "max", "len":
+		switch fe.Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+			tmpl = msgs.rules[fe.Tag()+"_len"]
+		}
+	}
+	return format(tmpl, fe.Field(), fe.Param())
+}
+
+func format(tmpl, field, param string) string {
+	return strings.NewReplacer("{field}", field, "{param}", param).Replace(tmpl)
+}
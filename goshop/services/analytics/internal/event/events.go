@@ -0,0 +1,87 @@
+package event
+
+import "time"
+
+// 统计服务订阅的事件类型
+const (
+	OrderCompleted   = "order.completed"
+	OrderRefunded    = "order.refunded"
+	PaymentSucceeded = "payment.succeeded"
+	PaymentRefunded  = "payment.refunded"
+	PageViewed       = "traffic.page_viewed"
+)
+
+// NamedRef 表示被引用的品牌或分类
+type NamedRef struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// OrderItem 表示订单事件中的商品行
+type OrderItem struct {
+	ProductID   uint       `json:"product_id"`
+	ProductName string     `json:"product_name"`
+	SKUID       uint       `json:"sku_id"`
+	Brand       *NamedRef  `json:"brand"`
+	Categories  []NamedRef `json:"categories"`
+	Quantity    int        `json:"quantity"`
+	Total       float64    `json:"total"` // 商品行实付金额
+}
+
+// OrderEvent 是 order.completed 事件的数据
+type OrderEvent struct {
+	OrderID       uint        `json:"order_id"`
+	OrderNumber   string      `json:"order_number"`
+	UserID        uint        `json:"user_id"`
+	Subtotal      float64     `json:"subtotal"`
+	Discount      float64     `json:"discount"`
+	ShippingFee   float64     `json:"shipping_fee"`
+	GrandTotal    float64     `json:"grand_total"`
+	PaymentMethod string      `json:"payment_method"`
+	Items         []OrderItem `json:"items"`
+	IsFirstOrder  bool        `json:"is_first_order"`
+	PlacedAt      time.Time   `json:"placed_at"`
+}
+
+// OrderRefundEvent 是 order.refunded 事件的数据，部分退款时 RefundAmount 小于 GrandTotal
+type OrderRefundEvent struct {
+	OrderID      uint    `json:"order_id"`
+	OrderNumber  string  `json:"order_number"`
+	UserID       uint    `json:"user_id"`
+	RefundID     string  `json:"refund_id"`
+	RefundAmount float64 `json:"refund_amount"`
+	GrandTotal   float64 `json:"grand_total"`
+}
+
+// PaymentEvent 是 payment.succeeded 事件的数据
+type PaymentEvent struct {
+	PaymentID     uint      `json:"payment_id"`
+	OrderID       uint      `json:"order_id"`
+	OrderNumber   string    `json:"order_number"`
+	UserID        uint      `json:"user_id"`
+	PaymentMethod string    `json:"payment_method"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency"`
+	PaidAt        time.Time `json:"paid_at"`
+}
+
+// PaymentRefundEvent 是 payment.refunded 事件的数据
+type PaymentRefundEvent struct {
+	PaymentID     uint      `json:"payment_id"`
+	RefundID      string    `json:"refund_id"`
+	OrderID       uint      `json:"order_id"`
+	PaymentMethod string    `json:"payment_method"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency"`
+	RefundedAt    time.Time `json:"refunded_at"`
+}
+
+// PageViewEvent 是 traffic.page_viewed 事件的数据，由网关在页面请求时发布
+type PageViewEvent struct {
+	SessionID string    `json:"session_id"`
+	UserID    *uint     `json:"user_id"`
+	Path      string    `json:"path"`
+	ProductID *uint     `json:"product_id"` // 商品详情页的商品 ID
+	Referrer  string    `json:"referrer"`
+	ViewedAt  time.Time `json:"viewed_at"`
+}
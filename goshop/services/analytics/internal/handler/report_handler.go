@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/services/analytics/internal/service"
+)
+
+// ReportHandler 处理统计报表和财务导出相关的 HTTP 请求
+type ReportHandler struct {
+	reportService *service.ReportService
+}
+
+// NewReportHandler 创建统计报表处理器
+func NewReportHandler(reportService *service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// RegisterRoutes 注册统计报表路由，from 和 to 为日期，默认最近 30 天
+func (h *ReportHandler) RegisterRoutes(api *gin.RouterGroup) {
+	admin := api.Group("/analytics/admin")
+	{
+		admin.GET("/reports/sales", h.SalesReport)
+		admin.GET("/reports/categories", h.CategorySales)
+		admin.GET("/reports/brands", h.BrandSales)
+		admin.GET("/reports/products", h.TopProducts)
+		admin.GET("/reports/repeat-purchase", h.RepeatPurchase)
+		admin.GET("/reports/cohorts", h.CohortRetention)
+
+		admin.GET("/exports/sales.csv", h.ExportSales)
+		admin.GET("/exports/orders.csv", h.ExportOrders)
+		admin.GET("/exports/payments.csv", h.ExportPayments)
+	}
+}
+
+// SalesReport 获取销售报表，group_by 为 day、week 或 month
+func (h *ReportHandler) SalesReport(c *gin.Context) {
+	from, to, ok := parseRangeQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.SalesReport(c.Request.Context(), from, to, c.Query("group_by"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// CategorySales 获取分类销售排行
+func (h *ReportHandler) CategorySales(c *gin.Context) {
+	from, to, ok := parseRangeQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.CategorySales(c.Request.Context(), from, to, parseIntQuery(c, "limit", 0))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// BrandSales 获取品牌销售排行
+func (h *ReportHandler) BrandSales(c *gin.Context) {
+	from, to, ok := parseRangeQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.BrandSales(c.Request.Context(), from, to, parseIntQuery(c, "limit", 0))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// TopProducts 获取热门商品，sort 为 revenue、units 或 views
+func (h *ReportHandler) TopProducts(c *gin.Context) {
+	from, to, ok := parseRangeQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.TopProducts(c.Request.Context(), from, to, c.Query("sort"), parseIntQuery(c, "limit", 0))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// RepeatPurchase 获取复购率
+func (h *ReportHandler) RepeatPurchase(c *gin.Context) {
+	from, to, ok := parseRangeQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.RepeatPurchase(c.Request.Context(), from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// CohortRetention 获取同期群留存，from 和 to 所在月份为同期群的范围，默认最近 12 个月
+func (h *ReportHandler) CohortRetention(c *gin.Context) {
+	from, to, ok := parseRangeQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.CohortRetention(c.Request.Context(), from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// ExportSales 以 CSV 文件导出销售汇总
+func (h *ReportHandler) ExportSales(c *gin.Context) {
+	from, to, ok := parseRangeQuery(c)
+	if !ok {
+		return
+	}
+	setCSVHeaders(c, "sales.csv")
+	if err := h.reportService.ExportSales(c.Request.Context(), from, to, c.Query("group_by"), c.Writer); err != nil {
+		respondError(c, err)
+		return
+	}
+}
+
+// ExportOrders 以 CSV 文件导出订单明细
+func (h *ReportHandler) ExportOrders(c *gin.Context) {
+	from, to, ok := parseRangeQuery(c)
+	if !ok {
+		return
+	}
+	setCSVHeaders(c, "orders.csv")
+	if err := h.reportService.ExportOrders(c.Request.Context(), from, to, c.Writer); err != nil {
+		respondError(c, err)
+		return
+	}
+}
+
+// ExportPayments 以 CSV 文件导出按支付方式汇总的收款
+func (h *ReportHandler) ExportPayments(c *gin.Context) {
+	from, to, ok := parseRangeQuery(c)
+	if !ok {
+		return
+	}
+	setCSVHeaders(c, "payments.csv")
+	if err := h.reportService.ExportPayments(c.Request.Context(), from, to, c.Writer); err != nil {
+		respondError(c, err)
+		return
+	}
+}
+
+func setCSVHeaders(c *gin.Context, filename string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+}
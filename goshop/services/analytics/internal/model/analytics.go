@@ -0,0 +1,113 @@
+package model
+
+import (
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+)
+
+// ProcessedEvent 记录已统计的业务事件，同一订单、退款、支付或访问事件重复投递时只统计一次
+type ProcessedEvent struct {
+	Key         string    `json:"key" gorm:"primaryKey;size:150"` // 如 order.completed:123
+	ProcessedAt time.Time `json:"processed_at" gorm:"not null"`
+}
+
+// OrderFact 表示一笔已完成的订单，用于复购率统计和财务明细导出
+type OrderFact struct {
+	OrderID       uint           `json:"order_id" gorm:"primaryKey;autoIncrement:false"`
+	OrderNumber   string         `json:"order_number" gorm:"size:50;not null"`
+	UserID        uint           `json:"user_id" gorm:"index;not null"`
+	Subtotal      currency.Money `json:"subtotal" gorm:"type:decimal(12,2);not null"`
+	Discount      currency.Money `json:"discount" gorm:"type:decimal(12,2);not null"`
+	ShippingFee   currency.Money `json:"shipping_fee" gorm:"type:decimal(12,2);not null"`
+	GrandTotal    currency.Money `json:"grand_total" gorm:"type:decimal(12,2);not null"`
+	Refunded      currency.Money `json:"refunded" gorm:"type:decimal(12,2);not null;default:0"`
+	Units         int            `json:"units" gorm:"not null"`
+	PaymentMethod string         `json:"payment_method" gorm:"size:20"`
+	IsFirstOrder  bool           `json:"is_first_order"`
+	CompletedAt   time.Time      `json:"completed_at" gorm:"index;not null"`
+}
+
+// DailySales 表示全店按天汇总的销售数据
+type DailySales struct {
+	Date               time.Time      `json:"date" gorm:"type:date;primaryKey"`
+	Orders             int            `json:"orders" gorm:"not null;default:0"`
+	Units              int            `json:"units" gorm:"not null;default:0"`
+	Revenue            currency.Money `json:"revenue" gorm:"type:decimal(14,2);not null;default:0"` // 订单实付金额
+	Discount           currency.Money `json:"discount" gorm:"type:decimal(14,2);not null;default:0"`
+	ShippingFee        currency.Money `json:"shipping_fee" gorm:"type:decimal(14,2);not null;default:0"`
+	Refunds            currency.Money `json:"refunds" gorm:"type:decimal(14,2);not null;default:0"` // 按退款发生日统计
+	NewCustomers       int            `json:"new_customers" gorm:"not null;default:0"`
+	ReturningCustomers int            `json:"returning_customers" gorm:"not null;default:0"`
+}
+
+// DailyCategorySales 表示分类按天汇总的销售数据。属于多个分类的商品计入每个分类，
+// 各分类之和可能大于全店销售额
+type DailyCategorySales struct {
+	Date       time.Time      `json:"date" gorm:"type:date;primaryKey"`
+	CategoryID uint           `json:"category_id" gorm:"primaryKey;autoIncrement:false"`
+	Name       string         `json:"name" gorm:"size:100"` // 最近一次销售时的分类名称
+	Orders     int            `json:"orders" gorm:"not null;default:0"`
+	Units      int            `json:"units" gorm:"not null;default:0"`
+	Revenue    currency.Money `json:"revenue" gorm:"type:decimal(14,2);not null;default:0"`
+}
+
+// DailyBrandSales 表示品牌按天汇总的销售数据
+type DailyBrandSales struct {
+	Date    time.Time      `json:"date" gorm:"type:date;primaryKey"`
+	BrandID uint           `json:"brand_id" gorm:"primaryKey;autoIncrement:false"`
+	Name    string         `json:"name" gorm:"size:100"`
+	Orders  int            `json:"orders" gorm:"not null;default:0"`
+	Units   int            `json:"units" gorm:"not null;default:0"`
+	Revenue currency.Money `json:"revenue" gorm:"type:decimal(14,2);not null;default:0"`
+}
+
+// DailyProductSales 表示商品按天汇总的销量和浏览量
+type DailyProductSales struct {
+	Date      time.Time      `json:"date" gorm:"type:date;primaryKey"`
+	ProductID uint           `json:"product_id" gorm:"primaryKey;autoIncrement:false"`
+	Name      string         `json:"name" gorm:"size:255"`
+	Orders    int            `json:"orders" gorm:"not null;default:0"`
+	Units     int            `json:"units" gorm:"not null;default:0"`
+	Revenue   currency.Money `json:"revenue" gorm:"type:decimal(14,2);not null;default:0"`
+	Views     int            `json:"views" gorm:"not null;default:0"`
+}
+
+// DailyPayments 表示按天和支付方式汇总的收款和退款，供财务对账
+type DailyPayments struct {
+	Date           time.Time      `json:"date" gorm:"type:date;primaryKey"`
+	Method         string         `json:"method" gorm:"size:20;primaryKey"`
+	Payments       int            `json:"payments" gorm:"not null;default:0"`
+	Amount         currency.Money `json:"amount" gorm:"type:decimal(14,2);not null;default:0"`
+	Refunds        int            `json:"refunds" gorm:"not null;default:0"`
+	RefundedAmount currency.Money `json:"refunded_amount" gorm:"type:decimal(14,2);not null;default:0"`
+}
+
+// DailyTraffic 表示按天汇总的访问量
+type DailyTraffic struct {
+	Date         time.Time `json:"date" gorm:"type:date;primaryKey"`
+	PageViews    int       `json:"page_views" gorm:"not null;default:0"`
+	ProductViews int       `json:"product_views" gorm:"not null;default:0"`
+	Sessions     int       `json:"sessions" gorm:"not null;default:0"` // 当天不同的访问会话数
+}
+
+// TrafficSession 记录每天出现过的访问会话，用于统计会话数
+type TrafficSession struct {
+	Date      time.Time `json:"date" gorm:"type:date;primaryKey"`
+	SessionID string    `json:"session_id" gorm:"size:100;primaryKey"`
+}
+
+// Customer 表示下过单的用户，按首单月份划分留存分析的同期群
+type Customer struct {
+	UserID       uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	CohortMonth  time.Time `json:"cohort_month" gorm:"type:date;index;not null"` // 首单所在月份的第一天
+	FirstOrderAt time.Time `json:"first_order_at" gorm:"not null"`
+	LastOrderAt  time.Time `json:"last_order_at" gorm:"not null"`
+	Orders       int       `json:"orders" gorm:"not null;default:0"`
+}
+
+// CustomerMonth 记录用户在某个月份下过单，用于计算同期群留存
+type CustomerMonth struct {
+	UserID uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Month  time.Time `json:"month" gorm:"type:date;primaryKey"`
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	"github.com/yourusername/goshop/services/analytics/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderRecord 表示一笔完成订单对各汇总表的贡献
+type OrderRecord struct {
+	Fact       *model.OrderFact
+	Daily      *model.DailySales
+	Categories []*model.DailyCategorySales
+	Brands     []*model.DailyBrandSales
+	Products   []*model.DailyProductSales
+	Customer   *model.Customer
+	Month      *model.CustomerMonth
+}
+
+// IngestRepository 定义统计数据写入的仓库接口，每个方法在一个事务中更新所有相关的汇总表
+type IngestRepository interface {
+	RecordOrder(ctx context.Context, key string, record *OrderRecord) (bool, error)
+	RecordRefund(ctx context.Context, key string, orderID uint, date time.Time, amount currency.Money) (bool, error)
+	RecordPayment(ctx context.Context, key string, payments *model.DailyPayments) (bool, error)
+	RecordPageView(ctx context.Context, date time.Time, sessionID string, productID *uint) error
+}
+
+// GormIngestRepository 实现 IngestRepository 接口的 GORM 仓库
+type GormIngestRepository struct {
+	db *gorm.DB
+}
+
+// NewIngestRepository 创建统计数据写入仓库实例
+func NewIngestRepository(db *gorm.DB) IngestRepository {
+	return &GormIngestRepository{
+		db: db,
+	}
+}
+
+// RecordOrder 记录完成的订单并累加到各汇总表，订单已统计过时返回 false
+func (r *GormIngestRepository) RecordOrder(ctx context.Context, key string, record *OrderRecord) (bool, error) {
+	recorded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		claimed, err := claim(tx, key)
+		if err != nil || !claimed {
+			return err
+		}
+
+		if err := tx.Create(record.Fact).Error; err != nil {
+			return err
+		}
+		if err := accumulate(tx, record.Daily, []string{"date"},
+			[]string{"orders", "units", "revenue", "discount", "shipping_fee", "new_customers", "returning_customers"}); err != nil {
+			return err
+		}
+		for _, row := range record.Categories {
+			if err := accumulate(tx, row, []string{"date", "category_id"}, []string{"orders", "units", "revenue"}, "name"); err != nil {
+				return err
+			}
+		}
+		for _, row := range record.Brands {
+			if err := accumulate(tx, row, []string{"date", "brand_id"}, []string{"orders", "units", "revenue"}, "name"); err != nil {
+				return err
+			}
+		}
+		for _, row := range record.Products {
+			if err := accumulate(tx, row, []string{"date", "product_id"}, []string{"orders", "units", "revenue"}, "name"); err != nil {
+				return err
+			}
+		}
+
+		// 乱序到达的订单可能早于已记录的首单，同期群取最早的月份
+		err = tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"orders":         gorm.Expr("customers.orders + 1"),
+				"cohort_month":   gorm.Expr("LEAST(customers.cohort_month, excluded.cohort_month)"),
+				"first_order_at": gorm.Expr("LEAST(customers.first_order_at, excluded.first_order_at)"),
+				"last_order_at":  gorm.Expr("GREATEST(customers.last_order_at, excluded.last_order_at)"),
+			}),
+		}).Create(record.Customer).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record.Month).Error; err != nil {
+			return err
+		}
+		recorded = true
+		return nil
+	})
+	return recorded, err
+}
+
+// RecordRefund 把退款计入退款发生日的汇总和订单明细，退款已统计过时返回 false
+func (r *GormIngestRepository) RecordRefund(ctx context.Context, key string, orderID uint, date time.Time, amount currency.Money) (bool, error) {
+	recorded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		claimed, err := claim(tx, key)
+		if err != nil || !claimed {
+			return err
+		}
+		if err := accumulate(tx, &model.DailySales{Date: date, Refunds: amount}, []string{"date"}, []string{"refunds"}); err != nil {
+			return err
+		}
+		err = tx.Model(&model.OrderFact{}).
+			Where("order_id = ?", orderID).
+			Update("refunded", gorm.Expr("refunded + ?", amount)).Error
+		if err != nil {
+			return err
+		}
+		recorded = true
+		return nil
+	})
+	return recorded, err
+}
+
+// RecordPayment 累加按支付方式汇总的收款或退款，事件已统计过时返回 false
+func (r *GormIngestRepository) RecordPayment(ctx context.Context, key string, payments *model.DailyPayments) (bool, error) {
+	recorded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		claimed, err := claim(tx, key)
+		if err != nil || !claimed {
+			return err
+		}
+		if err := accumulate(tx, payments, []string{"date", "method"},
+			[]string{"payments", "amount", "refunds", "refunded_amount"}); err != nil {
+			return err
+		}
+		recorded = true
+		return nil
+	})
+	return recorded, err
+}
+
+// RecordPageView 累加访问量，会话当天第一次出现时计入会话数。访问事件量大且允许误差，不做去重
+func (r *GormIngestRepository) RecordPageView(ctx context.Context, date time.Time, sessionID string, productID *uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		traffic := &model.DailyTraffic{Date: date, PageViews: 1}
+		if sessionID != "" {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&model.TrafficSession{Date: date, SessionID: sessionID})
+			if result.Error != nil {
+				return result.Error
+			}
+			traffic.Sessions = int(result.RowsAffected)
+		}
+		if productID != nil {
+			traffic.ProductViews = 1
+			if err := accumulate(tx, &model.DailyProductSales{Date: date, ProductID: *productID, Views: 1},
+				[]string{"date", "product_id"}, []string{"views"}); err != nil {
+				return err
+			}
+		}
+		return accumulate(tx, traffic, []string{"date"}, []string{"page_views", "product_views", "sessions"})
+	})
+}
+
+// claim 记录事件已处理，事件已处理过时返回 false
+func claim(tx *gorm.DB, key string) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.ProcessedEvent{Key: key, ProcessedAt: time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// accumulate 插入汇总行，行已存在时把 sums 列累加到原有值上，并用新值覆盖 replace 列
+func accumulate(tx *gorm.DB, row interface{}, keys, sums []string, replace ...string) error {
+	columns := make([]clause.Column, len(keys))
+	for i, key := range keys {
+		columns[i] = clause.Column{Name: key}
+	}
+	assignments := make(map[string]interface{}, len(sums)+len(replace))
+	for _, col := range sums {
+		assignments[col] = gorm.Expr("? + ?",
+			clause.Column{Table: clause.CurrentTable, Name: col},
+			clause.Column{Table: "excluded", Name: col})
+	}
+	for _, col := range replace {
+		assignments[col] = clause.Column{Table: "excluded", Name: col}
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   columns,
+		DoUpdates: clause.Assignments(assignments),
+	}).Create(row).Error
+}
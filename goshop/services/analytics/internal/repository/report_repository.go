@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	"github.com/yourusername/goshop/services/analytics/internal/model"
+	"gorm.io/gorm"
+)
+
+// GroupSales 表示分类、品牌或商品在统计区间内的销售汇总
+type GroupSales struct {
+	ID      uint           `json:"id"`
+	Name    string         `json:"name"`
+	Orders  int            `json:"orders"`
+	Units   int            `json:"units"`
+	Revenue currency.Money `json:"revenue"`
+	Views   int            `json:"views,omitempty"` // 只有商品统计浏览量
+}
+
+// CohortActivity 表示同期群在某个月份下单的用户数
+type CohortActivity struct {
+	CohortMonth time.Time
+	Month       time.Time
+	Customers   int
+}
+
+// ReportRepository 定义报表查询的仓库接口，日期范围均为闭区间
+type ReportRepository interface {
+	ListDailySales(ctx context.Context, from, to time.Time) ([]*model.DailySales, error)
+	ListDailyTraffic(ctx context.Context, from, to time.Time) ([]*model.DailyTraffic, error)
+	ListDailyPayments(ctx context.Context, from, to time.Time) ([]*model.DailyPayments, error)
+	SumCategorySales(ctx context.Context, from, to time.Time, limit int) ([]*GroupSales, error)
+	SumBrandSales(ctx context.Context, from, to time.Time, limit int) ([]*GroupSales, error)
+	SumProductSales(ctx context.Context, from, to time.Time, orderBy string, limit int) ([]*GroupSales, error)
+	CountRepeatCustomers(ctx context.Context, from, until time.Time) (customers, repeat int64, err error)
+	ListCohortActivity(ctx context.Context, fromMonth, toMonth time.Time) ([]*CohortActivity, error)
+	FindOrdersInBatches(ctx context.Context, from, until time.Time, size int, fn func([]*model.OrderFact) error) error
+}
+
+// GormReportRepository 实现 ReportRepository 接口的 GORM 仓库
+type GormReportRepository struct {
+	db *gorm.DB
+}
+
+// NewReportRepository 创建报表查询仓库实例
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &GormReportRepository{
+		db: db,
+	}
+}
+
+// ListDailySales 获取 [from, to] 日期范围内的每日销售汇总
+func (r *GormReportRepository) ListDailySales(ctx context.Context, from, to time.Time) ([]*model.DailySales, error) {
+	var rows []*model.DailySales
+	err := r.db.WithContext(ctx).Where("date >= ? AND date <= ?", from, to).Order("date ASC").Find(&rows).Error
+	return rows, err
+}
+
+// ListDailyTraffic 获取 [from, to] 日期范围内的每日访问量
+func (r *GormReportRepository) ListDailyTraffic(ctx context.Context, from, to time.Time) ([]*model.DailyTraffic, error) {
+	var rows []*model.DailyTraffic
+	err := r.db.WithContext(ctx).Where("date >= ? AND date <= ?", from, to).Order("date ASC").Find(&rows).Error
+	return rows, err
+}
+
+// ListDailyPayments 获取 [from, to] 日期范围内按天和支付方式汇总的收款
+func (r *GormReportRepository) ListDailyPayments(ctx context.Context, from, to time.Time) ([]*model.DailyPayments, error) {
+	var rows []*model.DailyPayments
+	err := r.db.WithContext(ctx).Where("date >= ? AND date <= ?", from, to).Order("date ASC, method ASC").Find(&rows).Error
+	return rows, err
+}
+
+// SumCategorySales 按销售额从高到低汇总各分类的销售
+func (r *GormReportRepository) SumCategorySales(ctx context.Context, from, to time.Time, limit int) ([]*GroupSales, error) {
+	return r.sumGroups(ctx, &model.DailyCategorySales{}, "category_id", "SUM(orders) AS orders, SUM(units) AS units, SUM(revenue) AS revenue", from, to, "revenue DESC", limit)
+}
+
+// SumBrandSales 按销售额从高到低汇总各品牌的销售
+func (r *GormReportRepository) SumBrandSales(ctx context.Context, from, to time.Time, limit int) ([]*GroupSales, error) {
+	return r.sumGroups(ctx, &model.DailyBrandSales{}, "brand_id", "SUM(orders) AS orders, SUM(units) AS units, SUM(revenue) AS revenue", from, to, "revenue DESC", limit)
+}
+
+// SumProductSales 汇总各商品的销量和浏览量，orderBy 为 revenue、units 或 views
+func (r *GormReportRepository) SumProductSales(ctx context.Context, from, to time.Time, orderBy string, limit int) ([]*GroupSales, error) {
+	return r.sumGroups(ctx, &model.DailyProductSales{}, "product_id", "SUM(orders) AS orders, SUM(units) AS units, SUM(revenue) AS revenue, SUM(views) AS views", from, to, orderBy+" DESC", limit)
+}
+
+// sumGroups 按分组列汇总 [from, to] 日期范围内的每日数据，名称取区间内最后记录的名称
+func (r *GormReportRepository) sumGroups(ctx context.Context, table interface{}, idColumn, sums string, from, to time.Time, order string, limit int) ([]*GroupSales, error) {
+	var rows []*GroupSales
+	err := r.db.WithContext(ctx).Model(table).
+		Select(idColumn+" AS id, (ARRAY_AGG(name ORDER BY date DESC))[1] AS name, "+sums).
+		Where("date >= ? AND date <= ?", from, to).
+		Group(idColumn).
+		Order(order + ", " + idColumn).
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CountRepeatCustomers 统计 [from, until) 内下单的用户数，以及其中下单两次及以上的用户数
+func (r *GormReportRepository) CountRepeatCustomers(ctx context.Context, from, until time.Time) (int64, int64, error) {
+	var result struct {
+		Customers       int64
+		RepeatCustomers int64
+	}
+	perUser := r.db.Model(&model.OrderFact{}).
+		Select("user_id, COUNT(*) AS orders").
+		Where("completed_at >= ? AND completed_at < ?", from, until).
+		Group("user_id")
+	err := r.db.WithContext(ctx).
+		Table("(?) AS per_user", perUser).
+		Select("COUNT(*) AS customers, COUNT(*) FILTER (WHERE orders >= 2) AS repeat_customers").
+		Scan(&result).Error
+	return result.Customers, result.RepeatCustomers, err
+}
+
+// ListCohortActivity 统计首单月份在 [fromMonth, toMonth] 内的同期群在之后每个月的下单用户数
+func (r *GormReportRepository) ListCohortActivity(ctx context.Context, fromMonth, toMonth time.Time) ([]*CohortActivity, error) {
+	var rows []*CohortActivity
+	err := r.db.WithContext(ctx).
+		Table("customers").
+		Select("customers.cohort_month, customer_months.month, COUNT(*) AS customers").
+		Joins("JOIN customer_months ON customer_months.user_id = customers.user_id").
+		Where("customers.cohort_month >= ? AND customers.cohort_month <= ?", fromMonth, toMonth).
+		Group("customers.cohort_month, customer_months.month").
+		Order("customers.cohort_month, customer_months.month").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// FindOrdersInBatches 按订单 ID 分批读取 [from, until) 内完成的订单
+func (r *GormReportRepository) FindOrdersInBatches(ctx context.Context, from, until time.Time, size int, fn func([]*model.OrderFact) error) error {
+	var lastID uint
+	for {
+		var orders []*model.OrderFact
+		err := r.db.WithContext(ctx).
+			Where("completed_at >= ? AND completed_at < ? AND order_id > ?", from, until, lastID).
+			Order("order_id ASC").
+			Limit(size).
+			Find(&orders).Error
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+		if err := fn(orders); err != nil {
+			return err
+		}
+		lastID = orders[len(orders)-1].OrderID
+	}
+}
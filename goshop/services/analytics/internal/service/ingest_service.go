@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/analytics/internal/event"
+	"github.com/yourusername/goshop/services/analytics/internal/model"
+	"github.com/yourusername/goshop/services/analytics/internal/repository"
+	"go.uber.org/zap"
+)
+
+// IngestService 消费订单、支付和访问事件，增量更新各汇总表
+type IngestService struct {
+	ingestRepo repository.IngestRepository
+	log        *logger.Logger
+}
+
+// NewIngestService 创建统计数据写入服务
+func NewIngestService(ingestRepo repository.IngestRepository, log *logger.Logger) *IngestService {
+	return &IngestService{
+		ingestRepo: ingestRepo,
+		log:        log,
+	}
+}
+
+// Subscribe 订阅订单完成、退款、支付和页面访问事件
+func (s *IngestService) Subscribe(consumer *events.Consumer) error {
+	handlers := map[string]events.Handler{
+		event.OrderCompleted:   s.handleOrderCompleted,
+		event.OrderRefunded:    s.handleOrderRefunded,
+		event.PaymentSucceeded: s.handlePaymentSucceeded,
+		event.PaymentRefunded:  s.handlePaymentRefunded,
+		event.PageViewed:       s.handlePageViewed,
+	}
+	for eventType, handler := range handlers {
+		if err := consumer.Subscribe(eventType, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleOrderCompleted 以订单完成时间所在的日期统计销售额，重复投递的订单只统计一次
+func (s *IngestService) handleOrderCompleted(ctx context.Context, env *events.Envelope) error {
+	var evt event.OrderEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	completedAt := env.OccurredAt
+	date := dayOf(completedAt)
+
+	record := &repository.OrderRecord{
+		Fact: &model.OrderFact{
+			OrderID:       evt.OrderID,
+			OrderNumber:   evt.OrderNumber,
+			UserID:        evt.UserID,
+			Subtotal:      amount(evt.Subtotal),
+			Discount:      amount(evt.Discount),
+			ShippingFee:   amount(evt.ShippingFee),
+			GrandTotal:    amount(evt.GrandTotal),
+			Refunded:      currency.Zero(currency.Default),
+			PaymentMethod: evt.PaymentMethod,
+			IsFirstOrder:  evt.IsFirstOrder,
+			CompletedAt:   completedAt,
+		},
+		Customer: &model.Customer{
+			UserID:       evt.UserID,
+			CohortMonth:  monthOf(completedAt),
+			FirstOrderAt: completedAt,
+			LastOrderAt:  completedAt,
+			Orders:       1,
+		},
+		Month: &model.CustomerMonth{UserID: evt.UserID, Month: monthOf(completedAt)},
+	}
+
+	categories := make(map[uint]*model.DailyCategorySales)
+	brands := make(map[uint]*model.DailyBrandSales)
+	products := make(map[uint]*model.DailyProductSales)
+	for _, item := range evt.Items {
+		total := amount(item.Total)
+		record.Fact.Units += item.Quantity
+
+		p, ok := products[item.ProductID]
+		if !ok {
+			p = &model.DailyProductSales{Date: date, ProductID: item.ProductID, Name: item.ProductName, Orders: 1, Revenue: currency.Zero(currency.Default)}
+			products[item.ProductID] = p
+			record.Products = append(record.Products, p)
+		}
+		p.Units += item.Quantity
+		p.Revenue = p.Revenue.Add(total)
+
+		if item.Brand != nil {
+			b, ok := brands[item.Brand.ID]
+			if !ok {
+				b = &model.DailyBrandSales{Date: date, BrandID: item.Brand.ID, Name: item.Brand.Name, Orders: 1, Revenue: currency.Zero(currency.Default)}
+				brands[item.Brand.ID] = b
+				record.Brands = append(record.Brands, b)
+			}
+			b.Units += item.Quantity
+			b.Revenue = b.Revenue.Add(total)
+		}
+
+		for _, category := range item.Categories {
+			c, ok := categories[category.ID]
+			if !ok {
+				c = &model.DailyCategorySales{Date: date, CategoryID: category.ID, Name: category.Name, Orders: 1, Revenue: currency.Zero(currency.Default)}
+				categories[category.ID] = c
+				record.Categories = append(record.Categories, c)
+			}
+			c.Units += item.Quantity
+			c.Revenue = c.Revenue.Add(total)
+		}
+	}
+
+	record.Daily = &model.DailySales{
+		Date:        date,
+		Orders:      1,
+		Units:       record.Fact.Units,
+		Revenue:     record.Fact.GrandTotal,
+		Discount:    record.Fact.Discount,
+		ShippingFee: record.Fact.ShippingFee,
+	}
+	if evt.IsFirstOrder {
+		record.Daily.NewCustomers = 1
+	} else {
+		record.Daily.ReturningCustomers = 1
+	}
+
+	recorded, err := s.ingestRepo.RecordOrder(ctx, fmt.Sprintf("%s:%d", event.OrderCompleted, evt.OrderID), record)
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		s.log.Info(ctx, "Order already recorded", zap.Uint("order_id", evt.OrderID))
+	}
+	return nil
+}
+
+// handleOrderRefunded 以退款发生日期统计退款金额
+func (s *IngestService) handleOrderRefunded(ctx context.Context, env *events.Envelope) error {
+	var evt event.OrderRefundEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	recorded, err := s.ingestRepo.RecordRefund(ctx, fmt.Sprintf("%s:%s", event.OrderRefunded, evt.RefundID),
+		evt.OrderID, dayOf(env.OccurredAt), amount(evt.RefundAmount))
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		s.log.Info(ctx, "Refund already recorded", zap.String("refund_id", evt.RefundID))
+	}
+	return nil
+}
+
+func (s *IngestService) handlePaymentSucceeded(ctx context.Context, env *events.Envelope) error {
+	var evt event.PaymentEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	if !s.defaultCurrency(ctx, evt.Currency, env) {
+		return nil
+	}
+	_, err := s.ingestRepo.RecordPayment(ctx, fmt.Sprintf("%s:%d", event.PaymentSucceeded, evt.PaymentID), &model.DailyPayments{
+		Date:     dayOf(evt.PaidAt),
+		Method:   evt.PaymentMethod,
+		Payments: 1,
+		Amount:   amount(evt.Amount),
+	})
+	return err
+}
+
+func (s *IngestService) handlePaymentRefunded(ctx context.Context, env *events.Envelope) error {
+	var evt event.PaymentRefundEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	if !s.defaultCurrency(ctx, evt.Currency, env) {
+		return nil
+	}
+	_, err := s.ingestRepo.RecordPayment(ctx, fmt.Sprintf("%s:%s", event.PaymentRefunded, evt.RefundID), &model.DailyPayments{
+		Date:           dayOf(evt.RefundedAt),
+		Method:         evt.PaymentMethod,
+		Refunds:        1,
+		RefundedAmount: amount(evt.Amount),
+	})
+	return err
+}
+
+func (s *IngestService) handlePageViewed(ctx context.Context, env *events.Envelope) error {
+	var evt event.PageViewEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	viewedAt := evt.ViewedAt
+	if viewedAt.IsZero() {
+		viewedAt = env.OccurredAt
+	}
+	return s.ingestRepo.RecordPageView(ctx, dayOf(viewedAt), evt.SessionID, evt.ProductID)
+}
+
+// defaultCurrency 检查支付币种，汇总表只以默认币种记账，其他币种的支付记录日志后跳过
+func (s *IngestService) defaultCurrency(ctx context.Context, code string, env *events.Envelope) bool {
+	if code == "" || currency.Code(code) == currency.Default {
+		return true
+	}
+	s.log.Warn(ctx, "Skipped payment in unsupported currency",
+		zap.String("event_id", env.ID),
+		zap.String("currency", code),
+	)
+	return false
+}
+
+// amount 将事件中的金额转换为默认币种的 Money
+func amount(v float64) currency.Money {
+	return currency.FromMajor(v, currency.Default)
+}
+
+// dayOf 返回时间在本地时区所在的日期
+func dayOf(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// monthOf 返回时间在本地时区所在月份的第一天
+func monthOf(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,476 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/analytics/internal/model"
+	"github.com/yourusername/goshop/services/analytics/internal/repository"
+)
+
+// 报表默认统计最近 30 天，最长一年；同期群默认最近 12 个月
+const (
+	defaultReportDays   = 30
+	maxReportDays       = 366
+	defaultCohortMonths = 12
+	maxCohortMonths     = 36
+	defaultRankingLimit = 20
+	maxRankingLimit     = 100
+	exportPageSize      = 1000
+)
+
+// 销售报表的汇总粒度
+const (
+	GroupByDay   = "day"
+	GroupByWeek  = "week"
+	GroupByMonth = "month"
+)
+
+// 商品排行的排序方式
+const (
+	SortByRevenue = "revenue"
+	SortByUnits   = "units"
+	SortByViews   = "views"
+)
+
+// SalesRow 表示一个统计周期的销售数据
+type SalesRow struct {
+	Period             time.Time      `json:"period"` // 周期的第一天，按周汇总时为周一
+	Orders             int            `json:"orders"`
+	Units              int            `json:"units"`
+	Revenue            currency.Money `json:"revenue"`
+	Discount           currency.Money `json:"discount"`
+	ShippingFee        currency.Money `json:"shipping_fee"`
+	Refunds            currency.Money `json:"refunds"`
+	NetRevenue         currency.Money `json:"net_revenue"` // 销售额减去退款
+	AverageOrderValue  currency.Money `json:"average_order_value"`
+	NewCustomers       int            `json:"new_customers"`
+	ReturningCustomers int            `json:"returning_customers"`
+	Sessions           int            `json:"sessions"`
+	PageViews          int            `json:"page_views"`
+	ConversionRate     float64        `json:"conversion_rate"` // 订单数占访问会话数的比例
+}
+
+// SalesReport 表示按天、周或月汇总的销售报表
+type SalesReport struct {
+	From    time.Time   `json:"from"`
+	To      time.Time   `json:"to"`
+	GroupBy string      `json:"group_by"`
+	Totals  SalesRow    `json:"totals"`
+	Rows    []*SalesRow `json:"rows"`
+}
+
+// RankingReport 表示分类、品牌或商品的销售排行
+type RankingReport struct {
+	From  time.Time                `json:"from"`
+	To    time.Time                `json:"to"`
+	Items []*repository.GroupSales `json:"items"`
+}
+
+// RepeatPurchaseReport 表示统计区间内的复购率
+type RepeatPurchaseReport struct {
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	Customers       int64     `json:"customers"`        // 区间内下单的用户数
+	RepeatCustomers int64     `json:"repeat_customers"` // 区间内下单两次及以上的用户数
+	RepeatRate      float64   `json:"repeat_rate"`
+}
+
+// Cohort 表示首单在同一个月的用户在之后各月的留存，Retained[0] 即同期群人数
+type Cohort struct {
+	Month    time.Time `json:"month"`
+	Size     int       `json:"size"`
+	Retained []int     `json:"retained"`
+	Rates    []float64 `json:"rates"`
+}
+
+// CohortReport 表示按首单月份划分的同期群留存报表
+type CohortReport struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Cohorts []*Cohort `json:"cohorts"`
+}
+
+// ReportService 提供销售、商品、复购和留存报表，以及供财务使用的 CSV 导出
+type ReportService struct {
+	reportRepo repository.ReportRepository
+}
+
+// NewReportService 创建报表服务
+func NewReportService(reportRepo repository.ReportRepository) *ReportService {
+	return &ReportService{
+		reportRepo: reportRepo,
+	}
+}
+
+// SalesReport 获取 [from, to] 内按 groupBy 汇总的销售报表，没有销售的周期也会返回
+func (s *ReportService) SalesReport(ctx context.Context, from, to time.Time, groupBy string) (*SalesReport, error) {
+	from, to, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if groupBy == "" {
+		groupBy = GroupByDay
+	}
+	if groupBy != GroupByDay && groupBy != GroupByWeek && groupBy != GroupByMonth {
+		return nil, apperrors.NewBadRequest("无效的汇总粒度", nil)
+	}
+
+	rows, err := s.salesRows(ctx, from, to, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	report := &SalesReport{From: from, To: to, GroupBy: groupBy, Rows: rows}
+	report.Totals.Period = from
+	for _, row := range rows {
+		report.Totals.add(row)
+	}
+	report.Totals.finish()
+	return report, nil
+}
+
+// CategorySales 获取 [from, to] 内按销售额排序的分类销售
+func (s *ReportService) CategorySales(ctx context.Context, from, to time.Time, limit int) (*RankingReport, error) {
+	return s.ranking(ctx, from, to, limit, s.reportRepo.SumCategorySales)
+}
+
+// BrandSales 获取 [from, to] 内按销售额排序的品牌销售
+func (s *ReportService) BrandSales(ctx context.Context, from, to time.Time, limit int) (*RankingReport, error) {
+	return s.ranking(ctx, from, to, limit, s.reportRepo.SumBrandSales)
+}
+
+// TopProducts 获取 [from, to] 内按销售额、销量或浏览量排序的热门商品
+func (s *ReportService) TopProducts(ctx context.Context, from, to time.Time, sortBy string, limit int) (*RankingReport, error) {
+	if sortBy == "" {
+		sortBy = SortByRevenue
+	}
+	if sortBy != SortByRevenue && sortBy != SortByUnits && sortBy != SortByViews {
+		return nil, apperrors.NewBadRequest("无效的排序方式", nil)
+	}
+	return s.ranking(ctx, from, to, limit, func(ctx context.Context, from, to time.Time, limit int) ([]*repository.GroupSales, error) {
+		return s.reportRepo.SumProductSales(ctx, from, to, sortBy, limit)
+	})
+}
+
+func (s *ReportService) ranking(ctx context.Context, from, to time.Time, limit int,
+	sum func(ctx context.Context, from, to time.Time, limit int) ([]*repository.GroupSales, error)) (*RankingReport, error) {
+	from, to, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultRankingLimit
+	}
+	limit = min(limit, maxRankingLimit)
+
+	items, err := sum(ctx, from, to, limit)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取销售排行失败", err)
+	}
+	return &RankingReport{From: from, To: to, Items: items}, nil
+}
+
+// RepeatPurchase 获取 [from, to] 内下单用户中下单两次及以上的比例
+func (s *ReportService) RepeatPurchase(ctx context.Context, from, to time.Time) (*RepeatPurchaseReport, error) {
+	from, to, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	customers, repeat, err := s.reportRepo.CountRepeatCustomers(ctx, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取复购统计失败", err)
+	}
+	report := &RepeatPurchaseReport{From: from, To: to, Customers: customers, RepeatCustomers: repeat}
+	if customers > 0 {
+		report.RepeatRate = float64(repeat) / float64(customers)
+	}
+	return report, nil
+}
+
+// CohortRetention 获取首单月份在 [from, to] 所在月份内的同期群在之后各月的留存，默认最近 12 个月
+func (s *ReportService) CohortRetention(ctx context.Context, from, to time.Time) (*CohortReport, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	to = monthOf(to)
+	if from.IsZero() {
+		from = to.AddDate(0, -(defaultCohortMonths - 1), 0)
+	}
+	from = monthOf(from)
+	if from.After(to) {
+		return nil, apperrors.NewBadRequest("开始日期不能晚于结束日期", nil)
+	}
+	if monthsBetween(from, to) >= maxCohortMonths {
+		return nil, apperrors.NewBadRequest("同期群区间不能超过三年", nil)
+	}
+
+	activity, err := s.reportRepo.ListCohortActivity(ctx, from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取留存统计失败", err)
+	}
+
+	// 同期群之后到本月为止的每个月都有一列，没有下单的月份留存为 0
+	current := monthOf(time.Now())
+	var cohorts []*Cohort
+	byMonth := make(map[time.Time]*Cohort)
+	for month := from; !month.After(to); month = month.AddDate(0, 1, 0) {
+		cohort := &Cohort{Month: month, Retained: make([]int, max(monthsBetween(month, current), 0)+1)}
+		cohorts = append(cohorts, cohort)
+		byMonth[month] = cohort
+	}
+	for _, a := range activity {
+		cohort, ok := byMonth[calendarDay(a.CohortMonth)]
+		if !ok {
+			continue
+		}
+		offset := monthsBetween(cohort.Month, a.Month)
+		if offset < 0 || offset >= len(cohort.Retained) {
+			continue
+		}
+		cohort.Retained[offset] = a.Customers
+	}
+	for _, cohort := range cohorts {
+		cohort.Size = cohort.Retained[0]
+		cohort.Rates = make([]float64, len(cohort.Retained))
+		if cohort.Size == 0 {
+			continue
+		}
+		for i, n := range cohort.Retained {
+			cohort.Rates[i] = float64(n) / float64(cohort.Size)
+		}
+	}
+	return &CohortReport{From: from, To: to, Cohorts: cohorts}, nil
+}
+
+// ExportSales 以 CSV 导出 [from, to] 内按 groupBy 汇总的销售数据
+func (s *ReportService) ExportSales(ctx context.Context, from, to time.Time, groupBy string, w io.Writer) error {
+	report, err := s.SalesReport(ctx, from, to, groupBy)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{"period", "orders", "units", "revenue", "discount", "shipping_fee", "refunds", "net_revenue",
+		"average_order_value", "new_customers", "returning_customers", "sessions", "conversion_rate"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		err := cw.Write([]string{
+			row.Period.Format("2006-01-02"),
+			strconv.Itoa(row.Orders),
+			strconv.Itoa(row.Units),
+			row.Revenue.Decimal(),
+			row.Discount.Decimal(),
+			row.ShippingFee.Decimal(),
+			row.Refunds.Decimal(),
+			row.NetRevenue.Decimal(),
+			row.AverageOrderValue.Decimal(),
+			strconv.Itoa(row.NewCustomers),
+			strconv.Itoa(row.ReturningCustomers),
+			strconv.Itoa(row.Sessions),
+			strconv.FormatFloat(row.ConversionRate, 'f', 4, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportOrders 以 CSV 导出 [from, to] 内完成的订单明细，供财务对账
+func (s *ReportService) ExportOrders(ctx context.Context, from, to time.Time, w io.Writer) error {
+	from, to, err := reportRange(from, to)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{"order_number", "user_id", "completed_at", "payment_method", "units",
+		"subtotal", "discount", "shipping_fee", "grand_total", "refunded"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	err = s.reportRepo.FindOrdersInBatches(ctx, from, to.AddDate(0, 0, 1), exportPageSize, func(orders []*model.OrderFact) error {
+		for _, order := range orders {
+			err := cw.Write([]string{
+				order.OrderNumber,
+				strconv.FormatUint(uint64(order.UserID), 10),
+				order.CompletedAt.In(time.Local).Format(time.RFC3339),
+				order.PaymentMethod,
+				strconv.Itoa(order.Units),
+				order.Subtotal.Decimal(),
+				order.Discount.Decimal(),
+				order.ShippingFee.Decimal(),
+				order.GrandTotal.Decimal(),
+				order.Refunded.Decimal(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return apperrors.NewInternalServerError("导出订单明细失败", err)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportPayments 以 CSV 导出 [from, to] 内按天和支付方式汇总的收款和退款
+func (s *ReportService) ExportPayments(ctx context.Context, from, to time.Time, w io.Writer) error {
+	from, to, err := reportRange(from, to)
+	if err != nil {
+		return err
+	}
+	rows, err := s.reportRepo.ListDailyPayments(ctx, from, to)
+	if err != nil {
+		return apperrors.NewInternalServerError("导出收款汇总失败", err)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "method", "payments", "amount", "refunds", "refunded_amount", "net_amount"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		err := cw.Write([]string{
+			row.Date.Format("2006-01-02"),
+			row.Method,
+			strconv.Itoa(row.Payments),
+			row.Amount.Decimal(),
+			strconv.Itoa(row.Refunds),
+			row.RefundedAmount.Decimal(),
+			row.Amount.Sub(row.RefundedAmount).Decimal(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// salesRows 把每日销售和访问量按 groupBy 汇总成连续的周期
+func (s *ReportService) salesRows(ctx context.Context, from, to time.Time, groupBy string) ([]*SalesRow, error) {
+	sales, err := s.reportRepo.ListDailySales(ctx, from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取销售统计失败", err)
+	}
+	traffic, err := s.reportRepo.ListDailyTraffic(ctx, from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取访问统计失败", err)
+	}
+
+	var rows []*SalesRow
+	byPeriod := make(map[time.Time]*SalesRow)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		period := periodOf(day, groupBy)
+		if _, ok := byPeriod[period]; !ok {
+			row := &SalesRow{Period: period}
+			byPeriod[period] = row
+			rows = append(rows, row)
+		}
+	}
+	for _, day := range sales {
+		row, ok := byPeriod[periodOf(day.Date, groupBy)]
+		if !ok {
+			continue
+		}
+		row.add(&SalesRow{
+			Orders:             day.Orders,
+			Units:              day.Units,
+			Revenue:            day.Revenue,
+			Discount:           day.Discount,
+			ShippingFee:        day.ShippingFee,
+			Refunds:            day.Refunds,
+			NewCustomers:       day.NewCustomers,
+			ReturningCustomers: day.ReturningCustomers,
+		})
+	}
+	for _, day := range traffic {
+		row, ok := byPeriod[periodOf(day.Date, groupBy)]
+		if !ok {
+			continue
+		}
+		row.Sessions += day.Sessions
+		row.PageViews += day.PageViews
+	}
+	for _, row := range rows {
+		row.finish()
+	}
+	return rows, nil
+}
+
+// add 累加另一个周期的计数和金额
+func (r *SalesRow) add(o *SalesRow) {
+	r.Orders += o.Orders
+	r.Units += o.Units
+	r.Revenue = r.Revenue.Add(o.Revenue)
+	r.Discount = r.Discount.Add(o.Discount)
+	r.ShippingFee = r.ShippingFee.Add(o.ShippingFee)
+	r.Refunds = r.Refunds.Add(o.Refunds)
+	r.NewCustomers += o.NewCustomers
+	r.ReturningCustomers += o.ReturningCustomers
+	r.Sessions += o.Sessions
+	r.PageViews += o.PageViews
+}
+
+// finish 根据累加结果计算净销售额、客单价和转化率
+func (r *SalesRow) finish() {
+	r.NetRevenue = r.Revenue.Sub(r.Refunds)
+	if r.Orders > 0 {
+		r.AverageOrderValue = r.Revenue.MulRate(1/float64(r.Orders), currency.HalfUp)
+	}
+	if r.Sessions > 0 {
+		r.ConversionRate = float64(r.Orders) / float64(r.Sessions)
+	}
+}
+
+// reportRange 补全报表日期范围，默认截止到今天、统计最近 30 天
+func reportRange(from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		now := time.Now()
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(defaultReportDays - 1))
+	}
+	if from.After(to) {
+		return from, to, apperrors.NewBadRequest("开始日期不能晚于结束日期", nil)
+	}
+	if to.Sub(from) > maxReportDays*24*time.Hour {
+		return from, to, apperrors.NewBadRequest("统计区间不能超过一年", nil)
+	}
+	return from, to, nil
+}
+
+// periodOf 返回日期所在汇总周期的第一天
+func periodOf(date time.Time, groupBy string) time.Time {
+	date = calendarDay(date)
+	switch groupBy {
+	case GroupByWeek:
+		return date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+	case GroupByMonth:
+		return monthOf(date)
+	default:
+		return date
+	}
+}
+
+// calendarDay 把数据库 date 列读出的日期转换为本地时区的同一天，驱动返回的 date 值不带时区
+func calendarDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// monthsBetween 返回两个月份之间相差的月数
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/audit/internal/repository"
+	"github.com/yourusername/goshop/services/audit/internal/service"
+)
+
+// AuditHandler 处理审计记录查询和保留策略相关的 HTTP 请求
+type AuditHandler struct {
+	recordService    *service.RecordService
+	retentionService *service.RetentionService
+}
+
+// NewAuditHandler 创建审计处理器
+func NewAuditHandler(recordService *service.RecordService, retentionService *service.RetentionService) *AuditHandler {
+	return &AuditHandler{
+		recordService:    recordService,
+		retentionService: retentionService,
+	}
+}
+
+// RegisterRoutes 注册审计路由，审计记录只能查询，不提供修改和删除接口
+func (h *AuditHandler) RegisterRoutes(api *gin.RouterGroup) {
+	records := api.Group("/audit/admin/records")
+	{
+		records.GET("", h.ListRecords)
+		records.GET("/:id", h.GetRecord)
+	}
+
+	policies := api.Group("/audit/admin/retention-policies")
+	{
+		policies.GET("", h.ListPolicies)
+		policies.POST("", h.CreatePolicy)
+		policies.PUT("/:id", h.UpdatePolicy)
+		policies.DELETE("/:id", h.DeletePolicy)
+	}
+}
+
+// ListRecords 分页查询审计记录，可按 actor_type、actor_id、entity_type、entity_id、
+// action（以 . 结尾时按前缀匹配）、source、trace_id 和 from、to 时间范围过滤
+func (h *AuditHandler) ListRecords(c *gin.Context) {
+	from, ok := parseTimeQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseTimeQuery(c, "to")
+	if !ok {
+		return
+	}
+	filter := repository.RecordFilter{
+		ActorType:  c.Query("actor_type"),
+		ActorID:    c.Query("actor_id"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		Action:     c.Query("action"),
+		Source:     c.Query("source"),
+		TraceID:    c.Query("trace_id"),
+		From:       from,
+		To:         to,
+	}
+	list, err := h.recordService.List(c.Request.Context(), filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetRecord 获取审计记录
+func (h *AuditHandler) GetRecord(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	record, err := h.recordService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": record})
+}
+
+// ListPolicies 获取所有保留策略
+func (h *AuditHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.retentionService.List(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": policies})
+}
+
+// CreatePolicy 创建保留策略
+func (h *AuditHandler) CreatePolicy(c *gin.Context) {
+	var req service.RetentionPolicyRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	policy, err := h.retentionService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": policy})
+}
+
+// UpdatePolicy 更新保留策略
+func (h *AuditHandler) UpdatePolicy(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.RetentionPolicyRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	policy, err := h.retentionService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": policy})
+}
+
+// DeletePolicy 删除保留策略
+func (h *AuditHandler) DeletePolicy(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.retentionService.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Record 表示一条审计记录。记录只追加，数据库触发器拒绝更新，
+// 只有保留策略的清理任务可以删除过期的记录
+type Record struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	EventID    string    `json:"event_id" gorm:"uniqueIndex;size:100;not null"` // 审计事件 ID，重复投递的事件只保存一次
+	Source     string    `json:"source" gorm:"index;size:50;not null"`          // 发布事件的服务
+	Action     string    `json:"action" gorm:"index;size:100;not null"`         // 如 product.price_changed
+	ActorType  string    `json:"actor_type" gorm:"index:idx_record_actor;size:20;not null"`
+	ActorID    string    `json:"actor_id" gorm:"index:idx_record_actor;size:100"`
+	ActorName  string    `json:"actor_name" gorm:"size:100"`
+	ActorIP    string    `json:"actor_ip" gorm:"size:64"`
+	UserAgent  string    `json:"user_agent" gorm:"size:255"`
+	EntityType string    `json:"entity_type" gorm:"index:idx_record_entity;size:50;not null"`
+	EntityID   string    `json:"entity_id" gorm:"index:idx_record_entity;size:100"`
+	Changes    JSONMap   `json:"changes,omitempty" gorm:"type:jsonb"`
+	Reason     string    `json:"reason,omitempty" gorm:"size:1000"`
+	Metadata   JSONMap   `json:"metadata,omitempty" gorm:"type:jsonb"`
+	TraceID    string    `json:"trace_id,omitempty" gorm:"index;size:64"`
+	OccurredAt time.Time `json:"occurred_at" gorm:"index;not null"` // 操作发生的时间
+	CreatedAt  time.Time `json:"created_at"`                        // 审计服务保存记录的时间
+}
+
+// RetentionPolicy 表示一类操作的保留期限。策略按操作名前缀匹配，
+// 多个策略匹配时前缀最长的生效，没有策略匹配的记录使用默认保留期限
+type RetentionPolicy struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ActionPrefix string    `json:"action_prefix" gorm:"uniqueIndex;size:100;not null"` // 如 payment. 或 payment.refunded
+	Days         int       `json:"days" gorm:"not null"`
+	Description  string    `json:"description" gorm:"size:255"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// JSONMap 是一个自定义类型，用于存储 JSON 对象
+type JSONMap map[string]interface{}
+
+// Value 实现 driver.Valuer 接口
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (m *JSONMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("类型断言为 []byte 失败")
+	}
+	return json.Unmarshal(b, m)
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/services/audit/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordFilter 表示查询审计记录的过滤条件，零值字段不参与过滤
+type RecordFilter struct {
+	ActorType  string
+	ActorID    string
+	EntityType string
+	EntityID   string
+	Action     string // 以 . 结尾时按前缀匹配，如 payment.
+	Source     string
+	TraceID    string
+	From       time.Time // 包含
+	To         time.Time // 不包含
+}
+
+// RecordRepository 定义审计记录仓库接口
+type RecordRepository interface {
+	Create(ctx context.Context, record *model.Record) (bool, error)
+	GetByID(ctx context.Context, id uint) (*model.Record, error)
+	List(ctx context.Context, filter RecordFilter, offset, limit int) ([]*model.Record, int64, error)
+	Purge(ctx context.Context, prefix string, excluded []string, cutoff time.Time, limit int) (int64, error)
+	InstallAppendOnlyGuard(ctx context.Context) error
+}
+
+// GormRecordRepository 实现 RecordRepository 接口的 GORM 仓库
+type GormRecordRepository struct {
+	db *gorm.DB
+}
+
+// NewRecordRepository 创建审计记录仓库实例
+func NewRecordRepository(db *gorm.DB) RecordRepository {
+	return &GormRecordRepository{
+		db: db,
+	}
+}
+
+// Create 保存审计记录，同一事件已保存时不重复保存并返回 false
+func (r *GormRecordRepository) Create(ctx context.Context, record *model.Record) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetByID 根据 ID 获取审计记录
+func (r *GormRecordRepository) GetByID(ctx context.Context, id uint) (*model.Record, error) {
+	var record model.Record
+	if err := r.db.WithContext(ctx).First(&record, id).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// List 按过滤条件分页获取审计记录，按发生时间倒序
+func (r *GormRecordRepository) List(ctx context.Context, filter RecordFilter, offset, limit int) ([]*model.Record, int64, error) {
+	var records []*model.Record
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Record{})
+	if filter.ActorType != "" {
+		query = query.Where("actor_type = ?", filter.ActorType)
+	}
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if strings.HasSuffix(filter.Action, ".") {
+		query = query.Where("action LIKE ?", escapeLike(filter.Action)+"%")
+	} else if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.TraceID != "" {
+		query = query.Where("trace_id = ?", filter.TraceID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("occurred_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("occurred_at < ?", filter.To)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("occurred_at DESC, id DESC").Offset(offset).Limit(limit).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return records, total, nil
+}
+
+// Purge 删除一批发生在 cutoff 之前、操作名以 prefix 开头但不以 excluded 中任一前缀开头的记录，
+// prefix 为空时匹配所有操作。删除在设置了 audit.allow_purge 的事务中执行，
+// 否则会被只追加触发器拒绝。返回删除的记录数
+func (r *GormRecordRepository) Purge(ctx context.Context, prefix string, excluded []string, cutoff time.Time, limit int) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL audit.allow_purge = 'on'").Error; err != nil {
+			return err
+		}
+		ids := tx.Model(&model.Record{}).Select("id").Where("occurred_at < ?", cutoff)
+		if prefix != "" {
+			ids = ids.Where("action LIKE ?", escapeLike(prefix)+"%")
+		}
+		for _, p := range excluded {
+			ids = ids.Where("action NOT LIKE ?", escapeLike(p)+"%")
+		}
+		result := tx.Where("id IN (?)", ids.Order("id ASC").Limit(limit)).Delete(&model.Record{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return nil
+	})
+	return deleted, err
+}
+
+// appendOnlyGuard 拒绝对审计记录的更新和清空，删除只允许在设置了 audit.allow_purge 的事务中进行
+var appendOnlyGuard = []string{
+	`CREATE OR REPLACE FUNCTION audit_records_append_only() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'DELETE' AND current_setting('audit.allow_purge', true) = 'on' THEN
+		RETURN OLD;
+	END IF;
+	RAISE EXCEPTION 'audit records are append-only (%)', TG_OP;
+END;
+$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS records_append_only ON records`,
+	`CREATE TRIGGER records_append_only BEFORE UPDATE OR DELETE ON records
+	FOR EACH ROW EXECUTE FUNCTION audit_records_append_only()`,
+	`DROP TRIGGER IF EXISTS records_no_truncate ON records`,
+	`CREATE TRIGGER records_no_truncate BEFORE TRUNCATE ON records
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_records_append_only()`,
+}
+
+// InstallAppendOnlyGuard 安装只追加触发器，服务启动时在迁移后调用
+func (r *GormRecordRepository) InstallAppendOnlyGuard(ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range appendOnlyGuard {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/audit/internal/model"
+	"gorm.io/gorm"
+)
+
+// RetentionRepository 定义保留策略仓库接口
+type RetentionRepository interface {
+	Create(ctx context.Context, policy *model.RetentionPolicy) error
+	GetByID(ctx context.Context, id uint) (*model.RetentionPolicy, error)
+	GetByPrefix(ctx context.Context, prefix string) (*model.RetentionPolicy, error)
+	Update(ctx context.Context, policy *model.RetentionPolicy) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context) ([]*model.RetentionPolicy, error)
+}
+
+// GormRetentionRepository 实现 RetentionRepository 接口的 GORM 仓库
+type GormRetentionRepository struct {
+	db *gorm.DB
+}
+
+// NewRetentionRepository 创建保留策略仓库实例
+func NewRetentionRepository(db *gorm.DB) RetentionRepository {
+	return &GormRetentionRepository{
+		db: db,
+	}
+}
+
+// Create 创建保留策略
+func (r *GormRetentionRepository) Create(ctx context.Context, policy *model.RetentionPolicy) error {
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// GetByID 根据 ID 获取保留策略
+func (r *GormRetentionRepository) GetByID(ctx context.Context, id uint) (*model.RetentionPolicy, error) {
+	var policy model.RetentionPolicy
+	if err := r.db.WithContext(ctx).First(&policy, id).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetByPrefix 根据操作名前缀获取保留策略
+func (r *GormRetentionRepository) GetByPrefix(ctx context.Context, prefix string) (*model.RetentionPolicy, error) {
+	var policy model.RetentionPolicy
+	if err := r.db.WithContext(ctx).Where("action_prefix = ?", prefix).First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Update 更新保留策略
+func (r *GormRetentionRepository) Update(ctx context.Context, policy *model.RetentionPolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// Delete 删除保留策略
+func (r *GormRetentionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.RetentionPolicy{}, id).Error
+}
+
+// List 获取所有保留策略，按前缀排序
+func (r *GormRetentionRepository) List(ctx context.Context) ([]*model.RetentionPolicy, error) {
+	var policies []*model.RetentionPolicy
+	err := r.db.WithContext(ctx).Order("action_prefix ASC").Find(&policies).Error
+	return policies, err
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/goshop/pkg/audit"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/audit/internal/model"
+	"github.com/yourusername/goshop/services/audit/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RecordList 表示分页的审计记录列表
+type RecordList struct {
+	Items    []*model.Record `json:"items"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// RecordService 负责保存各服务发布的审计事件并提供查询
+type RecordService struct {
+	recordRepo repository.RecordRepository
+	log        *logger.Logger
+}
+
+// NewRecordService 创建审计记录服务
+func NewRecordService(recordRepo repository.RecordRepository, log *logger.Logger) *RecordService {
+	return &RecordService{
+		recordRepo: recordRepo,
+		log:        log,
+	}
+}
+
+// Subscribe 订阅所有服务的审计事件
+func (s *RecordService) Subscribe(consumer *events.Consumer) error {
+	return consumer.Subscribe(audit.Subjects, s.handleEntry)
+}
+
+// handleEntry 保存一条审计事件，同一事件重复投递时只保存一次。
+// 无法解析或缺少操作名、操作人和对象的事件直接转入死信
+func (s *RecordService) handleEntry(ctx context.Context, env *events.Envelope) error {
+	if env.Version > audit.Version {
+		return events.Permanent(fmt.Errorf("unsupported audit event version %d", env.Version))
+	}
+	var entry audit.Entry
+	if err := env.Decode(&entry); err != nil {
+		return events.Permanent(err)
+	}
+	if entry.Action == "" || entry.Actor.Type == "" || entry.Entity.Type == "" {
+		return events.Permanent(errors.New("audit event requires action, actor type and entity type"))
+	}
+
+	occurredAt := entry.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = env.OccurredAt
+	}
+	record := &model.Record{
+		EventID:    env.ID,
+		Source:     env.Source,
+		Action:     entry.Action,
+		ActorType:  entry.Actor.Type,
+		ActorID:    entry.Actor.ID,
+		ActorName:  entry.Actor.Name,
+		ActorIP:    entry.Actor.IP,
+		UserAgent:  truncate(entry.Actor.UserAgent, 255),
+		EntityType: entry.Entity.Type,
+		EntityID:   entry.Entity.ID,
+		Reason:     truncate(entry.Reason, 1000),
+		Metadata:   model.JSONMap(entry.Metadata),
+		TraceID:    env.TraceID,
+		OccurredAt: occurredAt,
+	}
+	if len(entry.Changes) > 0 {
+		record.Changes = make(model.JSONMap, len(entry.Changes))
+		for field, change := range entry.Changes {
+			record.Changes[field] = change
+		}
+	}
+
+	created, err := s.recordRepo.Create(ctx, record)
+	if err != nil {
+		return err
+	}
+	if !created {
+		s.log.Info(ctx, "Skipping duplicate audit event", zap.String("event_id", env.ID))
+	}
+	return nil
+}
+
+// List 按操作人、对象、操作和时间范围分页查询审计记录
+func (s *RecordService) List(ctx context.Context, filter repository.RecordFilter, page, pageSize int) (*RecordList, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, apperrors.NewBadRequest("开始时间必须早于结束时间", nil)
+	}
+	page, pageSize = normalizePage(page, pageSize)
+	records, total, err := s.recordRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("查询审计记录失败", err)
+	}
+	return &RecordList{Items: records, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Get 获取审计记录
+func (s *RecordService) Get(ctx context.Context, id uint) (*model.Record, error) {
+	record, err := s.recordRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("审计记录 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取审计记录失败", err)
+	}
+	return record, nil
+}
+
+// normalizePage 规范化分页参数
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// truncate 截断过长的字符串，避免超出列宽导致整条记录无法保存，截断处不完整的字符被丢弃
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "")
+}
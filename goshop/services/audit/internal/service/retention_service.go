@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/audit/internal/model"
+	"github.com/yourusername/goshop/services/audit/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// purgeBatchSize 是每个事务最多删除的记录数，避免长时间锁表
+const purgeBatchSize = 1000
+
+// actionPrefixPattern 匹配操作名或以 . 结尾的操作名前缀，如 payment.refunded 或 payment.
+var actionPrefixPattern = regexp.MustCompile(`^[a-z_]+(\.[a-z_]+)*\.?$`)
+
+// RetentionConfig 表示审计记录的默认保留期限和策略允许的最短保留期限
+type RetentionConfig struct {
+	DefaultDays int
+	MinDays     int
+}
+
+// RetentionPolicyRequest 表示创建或更新保留策略的请求
+type RetentionPolicyRequest struct {
+	ActionPrefix string `json:"action_prefix" binding:"required,max=100"`
+	Days         int    `json:"days" binding:"required,min=1"`
+	Description  string `json:"description" binding:"max=255"`
+}
+
+// RetentionService 负责管理保留策略并定期清理过期的审计记录
+type RetentionService struct {
+	retentionRepo repository.RetentionRepository
+	recordRepo    repository.RecordRepository
+	cfg           RetentionConfig
+	log           *logger.Logger
+}
+
+// NewRetentionService 创建保留策略服务
+func NewRetentionService(retentionRepo repository.RetentionRepository, recordRepo repository.RecordRepository, cfg RetentionConfig, log *logger.Logger) *RetentionService {
+	return &RetentionService{
+		retentionRepo: retentionRepo,
+		recordRepo:    recordRepo,
+		cfg:           cfg,
+		log:           log,
+	}
+}
+
+// List 获取所有保留策略
+func (s *RetentionService) List(ctx context.Context) ([]*model.RetentionPolicy, error) {
+	policies, err := s.retentionRepo.List(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取保留策略失败", err)
+	}
+	return policies, nil
+}
+
+// Create 创建保留策略，同一前缀只能有一个策略
+func (s *RetentionService) Create(ctx context.Context, req *RetentionPolicyRequest) (*model.RetentionPolicy, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkPrefixFree(ctx, req.ActionPrefix, 0); err != nil {
+		return nil, err
+	}
+	policy := &model.RetentionPolicy{
+		ActionPrefix: req.ActionPrefix,
+		Days:         req.Days,
+		Description:  req.Description,
+	}
+	if err := s.retentionRepo.Create(ctx, policy); err != nil {
+		return nil, apperrors.NewInternalServerError("创建保留策略失败", err)
+	}
+	return policy, nil
+}
+
+// Update 更新保留策略
+func (s *RetentionService) Update(ctx context.Context, id uint, req *RetentionPolicyRequest) (*model.RetentionPolicy, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+	policy, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPrefixFree(ctx, req.ActionPrefix, id); err != nil {
+		return nil, err
+	}
+	policy.ActionPrefix = req.ActionPrefix
+	policy.Days = req.Days
+	policy.Description = req.Description
+	if err := s.retentionRepo.Update(ctx, policy); err != nil {
+		return nil, apperrors.NewInternalServerError("更新保留策略失败", err)
+	}
+	return policy, nil
+}
+
+// Delete 删除保留策略，匹配的记录改为使用更短前缀的策略或默认保留期限
+func (s *RetentionService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.get(ctx, id); err != nil {
+		return err
+	}
+	if err := s.retentionRepo.Delete(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除保留策略失败", err)
+	}
+	return nil
+}
+
+// Run 定期清理过期的审计记录，直到 ctx 取消
+func (s *RetentionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Purge(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge 删除超出保留期限的记录。每条记录按前缀最长的匹配策略计算保留期限，
+// 没有策略匹配时使用默认保留期限
+func (s *RetentionService) Purge(ctx context.Context, now time.Time) {
+	policies, err := s.retentionRepo.List(ctx)
+	if err != nil {
+		s.log.Error(ctx, "Failed to list retention policies", zap.Error(err))
+		return
+	}
+
+	prefixes := make([]string, 0, len(policies))
+	for _, p := range policies {
+		prefixes = append(prefixes, p.ActionPrefix)
+	}
+	for _, p := range policies {
+		// 更长的前缀由各自的策略处理
+		var excluded []string
+		for _, other := range prefixes {
+			if len(other) > len(p.ActionPrefix) && strings.HasPrefix(other, p.ActionPrefix) {
+				excluded = append(excluded, other)
+			}
+		}
+		s.purge(ctx, p.ActionPrefix, excluded, now.AddDate(0, 0, -p.Days))
+	}
+	s.purge(ctx, "", prefixes, now.AddDate(0, 0, -s.cfg.DefaultDays))
+}
+
+// purge 分批删除匹配前缀且早于 cutoff 的记录
+func (s *RetentionService) purge(ctx context.Context, prefix string, excluded []string, cutoff time.Time) {
+	var total int64
+	for ctx.Err() == nil {
+		deleted, err := s.recordRepo.Purge(ctx, prefix, excluded, cutoff, purgeBatchSize)
+		if err != nil {
+			s.log.Error(ctx, "Failed to purge audit records", zap.String("action_prefix", prefix), zap.Error(err))
+			break
+		}
+		total += deleted
+		if deleted < purgeBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		s.log.Info(ctx, "Purged expired audit records",
+			zap.String("action_prefix", prefix),
+			zap.Time("cutoff", cutoff),
+			zap.Int64("deleted", total),
+		)
+	}
+}
+
+// validate 校验前缀格式和保留期限
+func (s *RetentionService) validate(req *RetentionPolicyRequest) error {
+	if !actionPrefixPattern.MatchString(req.ActionPrefix) {
+		return apperrors.NewBadRequest(fmt.Sprintf("无效的操作名前缀 %s", req.ActionPrefix), nil)
+	}
+	if req.Days < s.cfg.MinDays {
+		return apperrors.NewBadRequest(fmt.Sprintf("保留期限不能少于 %d 天", s.cfg.MinDays), nil)
+	}
+	return nil
+}
+
+// checkPrefixFree 检查前缀没有被 exceptID 以外的策略使用
+func (s *RetentionService) checkPrefixFree(ctx context.Context, prefix string, exceptID uint) error {
+	existing, err := s.retentionRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return apperrors.NewInternalServerError("获取保留策略失败", err)
+	}
+	if existing.ID != exceptID {
+		return apperrors.NewConflict(fmt.Sprintf("前缀 %s 已有保留策略", prefix), nil)
+	}
+	return nil
+}
+
+func (s *RetentionService) get(ctx context.Context, id uint) (*model.RetentionPolicy, error) {
+	policy, err := s.retentionRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("保留策略 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取保留策略失败", err)
+	}
+	return policy, nil
+}
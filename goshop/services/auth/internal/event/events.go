@@ -0,0 +1,12 @@
+package event
+
+// 认证服务订阅的事件类型
+const (
+	SellerApproved = "seller.approved"
+)
+
+// SellerEvent 是 seller.approved 事件中认证服务关心的部分
+type SellerEvent struct {
+	SellerID uint `json:"seller_id"`
+	UserID   uint `json:"user_id"`
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/auth/internal/model"
+	"github.com/yourusername/goshop/services/auth/internal/service"
+	"github.com/yourusername/goshop/services/auth/rpc"
+)
+
+// GRPCHandler 实现认证服务的 gRPC 接口，供网关验证合作方的 API 密钥和查询角色的权限，
+// 以及用户服务在登录后签发令牌、签发和使用验证令牌、修改密码后作废刷新令牌
+type GRPCHandler struct {
+	apiKeyService *service.APIKeyService
+	roleService   *service.RoleService
+	tokenService  *service.TokenService
+}
+
+// NewGRPCHandler 创建 gRPC 处理器
+func NewGRPCHandler(apiKeyService *service.APIKeyService, roleService *service.RoleService, tokenService *service.TokenService) *GRPCHandler {
+	return &GRPCHandler{
+		apiKeyService: apiKeyService,
+		roleService:   roleService,
+		tokenService:  tokenService,
+	}
+}
+
+// VerifyAPIKey 验证 API 密钥和请求签名，返回密钥的权限
+func (h *GRPCHandler) VerifyAPIKey(ctx context.Context, req *rpc.VerifyAPIKeyRequest) (*rpc.APIKeyReply, error) {
+	apiKey, err := h.apiKeyService.Verify(ctx, req.Key, req.Payload, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+	reply := &rpc.APIKeyReply{
+		ID:          apiKey.ID,
+		UserID:      apiKey.UserID,
+		Name:        apiKey.Name,
+		Permissions: make([]string, 0, len(apiKey.Permissions)),
+	}
+	for _, permission := range apiKey.Permissions {
+		reply.Permissions = append(reply.Permissions, permission.Code)
+	}
+	return reply, nil
+}
+
+// GetRolePermissions 返回角色拥有的权限代码
+func (h *GRPCHandler) GetRolePermissions(ctx context.Context, req *rpc.RolePermissionsRequest) (*rpc.RolePermissionsReply, error) {
+	permissions, err := h.roleService.Permissions(ctx, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.RolePermissionsReply{Role: req.Role, Permissions: permissions}, nil
+}
+
+// GetUserPermissions 返回用户的角色和权限代码
+func (h *GRPCHandler) GetUserPermissions(ctx context.Context, req *rpc.UserPermissionsRequest) (*rpc.UserPermissionsReply, error) {
+	permissions, err := h.roleService.UserPermissions(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.UserPermissionsReply{UserID: req.UserID, Roles: permissions.Roles, Permissions: permissions.Permissions}, nil
+}
+
+// IssueTokens 为登录的用户签发访问令牌和刷新令牌
+func (h *GRPCHandler) IssueTokens(ctx context.Context, req *rpc.IssueTokensRequest) (*rpc.TokensReply, error) {
+	pair, err := h.tokenService.Issue(ctx, &service.TokenSession{
+		UserID:      req.UserID,
+		Name:        req.Name,
+		Role:        req.Role,
+		MemberLevel: req.MemberLevel,
+		IP:          req.IP,
+		UserAgent:   req.UserAgent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokensReply(pair), nil
+}
+
+// IssueVerification 签发一次性验证令牌
+func (h *GRPCHandler) IssueVerification(ctx context.Context, req *rpc.IssueVerificationRequest) (*rpc.VerificationReply, error) {
+	token, expiresAt, err := h.tokenService.IssueVerification(ctx, req.UserID, model.TokenType(req.Type),
+		time.Duration(req.TTL)*time.Second, time.Duration(req.ResendInterval)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.VerificationReply{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// ConsumeVerification 使用一次性验证令牌
+func (h *GRPCHandler) ConsumeVerification(ctx context.Context, req *rpc.ConsumeVerificationRequest) (*rpc.ConsumedVerificationReply, error) {
+	userID, err := h.tokenService.ConsumeVerification(ctx, req.Token, model.TokenType(req.Type))
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.ConsumedVerificationReply{UserID: userID}, nil
+}
+
+// RevokeTokens 作废用户的全部刷新令牌
+func (h *GRPCHandler) RevokeTokens(ctx context.Context, req *rpc.RevokeTokensRequest) (*rpc.RevokeTokensReply, error) {
+	if err := h.tokenService.RevokeRefreshTokens(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	return &rpc.RevokeTokensReply{}, nil
+}
+
+// tokensReply 将签发的令牌转换为 gRPC 响应
+func tokensReply(pair *service.TokenPair) *rpc.TokensReply {
+	return &rpc.TokensReply{
+		AccessToken:      pair.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(time.Until(pair.ExpiresAt).Seconds()),
+		ExpiresAt:        pair.ExpiresAt,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt,
+	}
+}
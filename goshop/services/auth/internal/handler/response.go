@@ -0,0 +1,11 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// respondError 中止请求，由错误中间件将错误转换为统一的 JSON 响应
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/auth/internal/service"
+)
+
+// RefreshTokenRequest 表示刷新令牌和退出登录的请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenHandler 处理刷新令牌和退出登录的 HTTP 请求
+type TokenHandler struct {
+	tokenService *service.TokenService
+}
+
+// NewTokenHandler 创建令牌处理器
+func NewTokenHandler(tokenService *service.TokenService) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// RegisterRoutes 注册令牌路由
+func (h *TokenHandler) RegisterRoutes(auth *gin.RouterGroup) {
+	auth.POST("/token/refresh", h.Refresh)
+	auth.POST("/logout", h.Logout)
+}
+
+// Refresh 使用刷新令牌换取新的访问令牌和刷新令牌，原刷新令牌随之作废
+func (h *TokenHandler) Refresh(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	pair, err := h.tokenService.Refresh(c.Request.Context(), req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tokensReply(pair)})
+}
+
+// Logout 作废刷新令牌所属登录会话的所有刷新令牌，已签发的访问令牌在过期前仍然有效
+func (h *TokenHandler) Logout(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := h.tokenService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -20,6 +20,8 @@ const (
 	TokenTypePasswordReset TokenType = "password_reset"
 	// TokenTypePhoneVerification 手机验证令牌
 	TokenTypePhoneVerification TokenType = "phone_verification"
+	// TokenTypeAccountUnlock 解锁因多次登录失败而锁定的账号的令牌
+	TokenTypeAccountUnlock TokenType = "account_unlock"
 )
 
 // Token 表示认证令牌
@@ -30,6 +32,7 @@ type Token struct {
 	Type      TokenType `json:"type" gorm:"size:30;not null"`
 	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
 	IsRevoked bool      `json:"is_revoked" gorm:"default:false"`
+	Family    string    `json:"family" gorm:"size:64;index"` // 刷新令牌所属的登录会话，轮换签发的令牌沿用同一个 family
 	IP        *string   `json:"ip" gorm:"size:50"`
 	UserAgent *string   `json:"user_agent" gorm:"size:255"`
 	CreatedAt time.Time `json:"created_at"`
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/auth/internal/model"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository 定义 API 密钥仓库接口
+type APIKeyRepository interface {
+	GetByKey(ctx context.Context, key string) (*model.APIKey, error)
+	UpdateLastUsed(ctx context.Context, id uint, at time.Time) error
+}
+
+// GormAPIKeyRepository 实现 APIKeyRepository 接口的 GORM 仓库
+type GormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository 创建 API 密钥仓库实例
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &GormAPIKeyRepository{
+		db: db,
+	}
+}
+
+// GetByKey 按密钥获取 API 密钥及其权限，不存在时返回 gorm.ErrRecordNotFound
+func (r *GormAPIKeyRepository) GetByKey(ctx context.Context, key string) (*model.APIKey, error) {
+	var apiKey model.APIKey
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("key = ?", key).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// UpdateLastUsed 更新 API 密钥的最后使用时间
+func (r *GormAPIKeyRepository) UpdateLastUsed(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
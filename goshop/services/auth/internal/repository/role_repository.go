@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/auth/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleRepository 定义角色仓库接口
+type RoleRepository interface {
+	GetByName(ctx context.Context, name string) (*model.Role, error)
+	ListByUser(ctx context.Context, userID uint) ([]model.Role, error)
+	Assign(ctx context.Context, userID uint, name string) error
+}
+
+// GormRoleRepository 实现 RoleRepository 接口的 GORM 仓库
+type GormRoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository 创建角色仓库实例
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &GormRoleRepository{
+		db: db,
+	}
+}
+
+// GetByName 按名称获取角色及其权限，不存在时返回 gorm.ErrRecordNotFound
+func (r *GormRoleRepository) GetByName(ctx context.Context, name string) (*model.Role, error) {
+	var role model.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// ListByUser 获取通过 UserRole 分配给用户的角色及其权限
+func (r *GormRoleRepository) ListByUser(ctx context.Context, userID uint) ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Find(&roles).Error
+	return roles, err
+}
+
+// Assign 将角色分配给用户，角色不存在时创建，已分配的角色不重复分配
+func (r *GormRoleRepository) Assign(ctx context.Context, userID uint, name string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role := model.Role{Name: name}
+		if err := tx.Where("name = ?", name).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+		userRole := model.UserRole{UserID: userID, RoleID: role.ID}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&userRole).Error
+	})
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/auth/internal/model"
+	"gorm.io/gorm"
+)
+
+// TokenRepository 定义令牌仓库接口
+type TokenRepository interface {
+	Create(ctx context.Context, token *model.Token) error
+	GetByToken(ctx context.Context, token string) (*model.Token, error)
+	GetLatest(ctx context.Context, userID uint, tokenType model.TokenType) (*model.Token, error)
+	Revoke(ctx context.Context, id uint) (bool, error)
+	RevokeByUser(ctx context.Context, userID uint, tokenType model.TokenType) error
+	RevokeFamily(ctx context.Context, family string) (int64, error)
+}
+
+// GormTokenRepository 实现 TokenRepository 接口的 GORM 仓库
+type GormTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRepository 创建令牌仓库实例
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &GormTokenRepository{
+		db: db,
+	}
+}
+
+// Create 保存令牌
+func (r *GormTokenRepository) Create(ctx context.Context, token *model.Token) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// GetByToken 按令牌摘要获取令牌
+func (r *GormTokenRepository) GetByToken(ctx context.Context, token string) (*model.Token, error) {
+	var t model.Token
+	err := r.db.WithContext(ctx).Where("token = ?", token).First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetLatest 获取用户最近签发的指定类型的令牌
+func (r *GormTokenRepository) GetLatest(ctx context.Context, userID uint, tokenType model.TokenType) (*model.Token, error) {
+	var t model.Token
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ?", userID, tokenType).
+		Order("created_at DESC").
+		First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Revoke 作废未作废的令牌，返回是否由本次调用作废，并发使用同一令牌时只有一次成功
+func (r *GormTokenRepository) Revoke(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Token{}).
+		Where("id = ? AND is_revoked = ?", id, false).
+		Update("is_revoked", true)
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeByUser 作废用户指定类型的所有令牌
+func (r *GormTokenRepository) RevokeByUser(ctx context.Context, userID uint, tokenType model.TokenType) error {
+	return r.db.WithContext(ctx).Model(&model.Token{}).
+		Where("user_id = ? AND type = ? AND is_revoked = ?", userID, tokenType, false).
+		Update("is_revoked", true).Error
+}
+
+// RevokeFamily 作废同一登录会话的所有令牌，返回本次作废的数量
+func (r *GormTokenRepository) RevokeFamily(ctx context.Context, family string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.Token{}).
+		Where("family = ? AND is_revoked = ?", family, false).
+		Update("is_revoked", true)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/auth/internal/model"
+	"github.com/yourusername/goshop/services/auth/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// lastUsedInterval 是更新 API 密钥最后使用时间的最小间隔，避免每个请求都写数据库
+const lastUsedInterval = time.Minute
+
+// APIKeyService 验证合作方的 API 密钥和请求签名。
+// 请求签名为待签名内容以密钥的 Secret 计算的 HMAC-SHA256，十六进制编码
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+	log        *logger.Logger
+	now        func() time.Time
+}
+
+// NewAPIKeyService 创建 API 密钥服务
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, log *logger.Logger) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		log:        log,
+		now:        time.Now,
+	}
+}
+
+// Verify 验证 key 对 payload 的签名，返回启用且未过期的 API 密钥及其权限。
+// 密钥不存在和签名错误返回相同的错误，不向调用方透露密钥是否存在
+func (s *APIKeyService) Verify(ctx context.Context, key, payload, signature string) (*model.APIKey, error) {
+	invalid := apperrors.NewUnauthorized("API 密钥或签名无效", nil)
+	if key == "" || signature == "" {
+		return nil, invalid
+	}
+	apiKey, err := s.apiKeyRepo.GetByKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, apperrors.NewInternalServerError("获取 API 密钥失败", err)
+	}
+	if !validSignature(apiKey.Secret, payload, signature) {
+		s.log.Warn(ctx, "API 密钥签名错误", zap.Uint("api_key_id", apiKey.ID))
+		return nil, invalid
+	}
+
+	now := s.now()
+	if !apiKey.IsActive {
+		return nil, apperrors.NewUnauthorized("API 密钥已停用", nil)
+	}
+	if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+		return nil, apperrors.NewUnauthorized("API 密钥已过期", nil)
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedInterval {
+		if err := s.apiKeyRepo.UpdateLastUsed(ctx, apiKey.ID, now); err != nil {
+			s.log.Warn(ctx, "更新 API 密钥使用时间失败", zap.Uint("api_key_id", apiKey.ID), zap.Error(err))
+		}
+	}
+	return apiKey, nil
+}
+
+// validSignature 以常量时间比较签名，避免通过响应时间猜测签名
+func validSignature(secret, payload, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/services/auth/internal/event"
+	"github.com/yourusername/goshop/services/auth/internal/model"
+	"github.com/yourusername/goshop/services/auth/internal/repository"
+	userrpc "github.com/yourusername/goshop/services/user/rpc"
+	"gorm.io/gorm"
+)
+
+// UserPermissions 是用户拥有的角色和这些角色的全部权限
+type UserPermissions struct {
+	Roles       []string
+	Permissions []string
+}
+
+// RoleService 查询角色和用户拥有的权限。用户的角色包括用户服务中的主角色（即访问令牌中的角色）
+// 和通过 UserRole 分配的其他角色
+type RoleService struct {
+	roleRepo repository.RoleRepository
+	users    *userrpc.Client
+}
+
+// NewRoleService 创建角色服务，通过 users 获取用户的主角色
+func NewRoleService(roleRepo repository.RoleRepository, users *userrpc.Client) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		users:    users,
+	}
+}
+
+// sellerRole 是分配给入驻商家的角色，网关据此允许商家访问商家接口
+const sellerRole = "seller"
+
+// Subscribe 订阅商家审核通过事件
+func (s *RoleService) Subscribe(consumer *events.Consumer) error {
+	return consumer.Subscribe(event.SellerApproved, s.handleSellerApproved)
+}
+
+// handleSellerApproved 为审核通过的商家的用户分配 seller 角色
+func (s *RoleService) handleSellerApproved(ctx context.Context, env *events.Envelope) error {
+	var evt event.SellerEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	if evt.UserID == 0 {
+		return events.Permanent(errors.New("seller event without user_id"))
+	}
+	return s.roleRepo.Assign(ctx, evt.UserID, sellerRole)
+}
+
+// Permissions 返回角色拥有的权限代码，不存在的角色没有任何权限
+func (s *RoleService) Permissions(ctx context.Context, name string) ([]string, error) {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return []string{}, nil
+		}
+		return nil, apperrors.NewInternalServerError("获取角色失败", err)
+	}
+	codes := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		codes = append(codes, permission.Code)
+	}
+	return codes, nil
+}
+
+// UserPermissions 返回用户的角色和权限，不存在或已停用的用户没有任何角色
+func (s *RoleService) UserPermissions(ctx context.Context, userID uint) (*UserPermissions, error) {
+	result := &UserPermissions{Roles: []string{}, Permissions: []string{}}
+	user, err := s.users.ValidateUser(ctx, &userrpc.ValidateUserRequest{ID: userID})
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable("暂时无法获取用户角色", err)
+	}
+	if !user.Valid {
+		return result, nil
+	}
+
+	roles, err := s.roleRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取用户角色失败", err)
+	}
+	if user.Role != "" {
+		role, err := s.roleRepo.GetByName(ctx, user.Role)
+		switch {
+		case err == nil:
+			roles = append(roles, *role)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// 主角色没有配置权限时仍然返回角色，按角色授权的接口可以使用
+			roles = append(roles, model.Role{Name: user.Role})
+		default:
+			return nil, apperrors.NewInternalServerError("获取角色失败", err)
+		}
+	}
+
+	seenRoles := make(map[string]bool, len(roles))
+	seenPermissions := make(map[string]bool)
+	for _, role := range roles {
+		if !seenRoles[role.Name] {
+			seenRoles[role.Name] = true
+			result.Roles = append(result.Roles, role.Name)
+		}
+		for _, permission := range role.Permissions {
+			if !seenPermissions[permission.Code] {
+				seenPermissions[permission.Code] = true
+				result.Permissions = append(result.Permissions, permission.Code)
+			}
+		}
+	}
+	return result, nil
+}
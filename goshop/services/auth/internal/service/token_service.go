@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/auth/internal/model"
+	"github.com/yourusername/goshop/services/auth/internal/repository"
+	userrpc "github.com/yourusername/goshop/services/user/rpc"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// tokenBytes 是刷新令牌和验证令牌的随机字节数
+const tokenBytes = 32
+
+// verificationTypes 是可以签发的一次性验证令牌类型
+var verificationTypes = map[model.TokenType]bool{
+	model.TokenTypeEmailVerification: true,
+	model.TokenTypeAccountUnlock:     true,
+}
+
+// AccessClaims 是访问令牌中的声明，网关和各服务按这些声明识别用户、角色和会员等级
+type AccessClaims struct {
+	UserID      uint   `json:"user_id"`
+	Name        string `json:"name"`
+	Role        string `json:"role"`
+	MemberLevel int    `json:"member_level"`
+	jwt.RegisteredClaims
+}
+
+// TokenSession 表示登录的客户端
+type TokenSession struct {
+	UserID      uint
+	Name        string
+	Role        string
+	MemberLevel int
+	IP          string
+	UserAgent   string
+}
+
+// TokenPair 是签发的访问令牌和刷新令牌
+type TokenPair struct {
+	AccessToken      string
+	ExpiresAt        time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// TokenService 签发访问令牌、刷新令牌和一次性验证令牌。访问令牌是以 HS256 签名的 JWT，
+// 刷新令牌和验证令牌是随机字符串，数据库中只保存其 SHA-256 摘要。
+// 刷新令牌只能使用一次，每次刷新签发新的刷新令牌；同一次登录轮换出的刷新令牌属于同一个 family，
+// 已作废的刷新令牌再次被使用时说明令牌可能已经泄露，整个 family 随之作废
+type TokenService struct {
+	tokenRepo       repository.TokenRepository
+	users           *userrpc.Client
+	secret          []byte
+	accessDuration  time.Duration
+	refreshDuration time.Duration
+	log             *logger.Logger
+	now             func() time.Time
+}
+
+// NewTokenService 创建令牌服务，secret 用于签名访问令牌，刷新时通过 users 获取用户当前的角色和会员等级
+func NewTokenService(tokenRepo repository.TokenRepository, users *userrpc.Client, secret string, accessDuration, refreshDuration time.Duration, log *logger.Logger) *TokenService {
+	return &TokenService{
+		tokenRepo:       tokenRepo,
+		users:           users,
+		secret:          []byte(secret),
+		accessDuration:  accessDuration,
+		refreshDuration: refreshDuration,
+		log:             log,
+		now:             time.Now,
+	}
+}
+
+// Issue 为已验证身份的用户签发访问令牌和刷新令牌，开始新的登录会话
+func (s *TokenService) Issue(ctx context.Context, session *TokenSession) (*TokenPair, error) {
+	if session.UserID == 0 {
+		return nil, apperrors.NewBadRequest("缺少用户", nil)
+	}
+	family, err := randomToken()
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成刷新令牌失败", err)
+	}
+	return s.issue(ctx, session, family)
+}
+
+// Refresh 使用刷新令牌签发新的访问令牌和刷新令牌，使用过的刷新令牌随之作废。
+// 新的访问令牌使用用户当前的名称、角色和会员等级，已停用的用户不能刷新
+func (s *TokenService) Refresh(ctx context.Context, raw, ip, userAgent string) (*TokenPair, error) {
+	invalid := apperrors.NewUnauthorized("登录已过期，请重新登录", nil)
+	if raw == "" {
+		return nil, invalid
+	}
+	token, err := s.tokenRepo.GetByToken(ctx, hashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, apperrors.NewInternalServerError("获取刷新令牌失败", err)
+	}
+	if token.Type != model.TokenTypeRefresh || !s.now().Before(token.ExpiresAt) {
+		return nil, invalid
+	}
+	if token.IsRevoked {
+		s.revokeReused(ctx, token)
+		return nil, invalid
+	}
+
+	// 先确认用户仍然有效，用户服务不可用时刷新令牌保持有效，客户端可以重试
+	user, err := s.users.ValidateUser(ctx, &userrpc.ValidateUserRequest{ID: token.UserID})
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable("暂时无法刷新登录，请稍后再试", err)
+	}
+	if !user.Valid {
+		if err := s.revokeFamily(ctx, token); err != nil {
+			return nil, err
+		}
+		if user.Reason == userrpc.InvalidInactive {
+			return nil, apperrors.NewForbidden("账号已停用", nil)
+		}
+		return nil, invalid
+	}
+
+	revoked, err := s.tokenRepo.Revoke(ctx, token.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("作废刷新令牌失败", err)
+	}
+	if !revoked {
+		// 同一刷新令牌被并发使用，与重复使用同样处理
+		s.revokeReused(ctx, token)
+		return nil, invalid
+	}
+
+	family := token.Family
+	if family == "" {
+		// 轮换之前签发的刷新令牌没有 family，从这次刷新开始
+		if family, err = randomToken(); err != nil {
+			return nil, apperrors.NewInternalServerError("生成刷新令牌失败", err)
+		}
+	}
+	return s.issue(ctx, &TokenSession{
+		UserID:      token.UserID,
+		Name:        user.Name,
+		Role:        user.Role,
+		MemberLevel: user.MemberLevel,
+		IP:          ip,
+		UserAgent:   userAgent,
+	}, family)
+}
+
+// Logout 作废刷新令牌所属登录会话的所有刷新令牌。令牌无效或已作废时不返回错误，退出登录可以重复调用
+func (s *TokenService) Logout(ctx context.Context, raw string) error {
+	if raw == "" {
+		return nil
+	}
+	token, err := s.tokenRepo.GetByToken(ctx, hashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return apperrors.NewInternalServerError("获取刷新令牌失败", err)
+	}
+	if token.Type != model.TokenTypeRefresh {
+		return nil
+	}
+	return s.revokeFamily(ctx, token)
+}
+
+// revokeReused 在已作废的刷新令牌被再次使用时作废整个登录会话，攻击者和用户都需要重新登录
+func (s *TokenService) revokeReused(ctx context.Context, token *model.Token) {
+	if err := s.revokeFamily(ctx, token); err != nil {
+		s.log.Error(ctx, "作废登录会话失败", zap.Uint("user_id", token.UserID), zap.Error(err))
+		return
+	}
+	s.log.Warn(ctx, "刷新令牌被重复使用，已作废登录会话", zap.Uint("user_id", token.UserID), zap.Uint("token_id", token.ID))
+}
+
+// revokeFamily 作废令牌所属登录会话的所有令牌，没有 family 的令牌只作废自身
+func (s *TokenService) revokeFamily(ctx context.Context, token *model.Token) error {
+	var err error
+	if token.Family == "" {
+		_, err = s.tokenRepo.Revoke(ctx, token.ID)
+	} else {
+		_, err = s.tokenRepo.RevokeFamily(ctx, token.Family)
+	}
+	if err != nil {
+		return apperrors.NewInternalServerError("作废刷新令牌失败", err)
+	}
+	return nil
+}
+
+// issue 签发访问令牌和属于 family 的刷新令牌
+func (s *TokenService) issue(ctx context.Context, session *TokenSession, family string) (*TokenPair, error) {
+	now := s.now()
+	pair := &TokenPair{
+		ExpiresAt:        now.Add(s.accessDuration),
+		RefreshExpiresAt: now.Add(s.refreshDuration),
+	}
+
+	claims := &AccessClaims{
+		UserID:      session.UserID,
+		Name:        session.Name,
+		Role:        session.Role,
+		MemberLevel: session.MemberLevel,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(pair.ExpiresAt),
+		},
+	}
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("签发访问令牌失败", err)
+	}
+	pair.AccessToken = access
+
+	refresh, err := randomToken()
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成刷新令牌失败", err)
+	}
+	pair.RefreshToken = refresh
+	token := &model.Token{
+		UserID:    session.UserID,
+		Token:     hashToken(pair.RefreshToken),
+		Type:      model.TokenTypeRefresh,
+		ExpiresAt: pair.RefreshExpiresAt,
+		Family:    family,
+		IP:        optional(session.IP),
+		UserAgent: optional(session.UserAgent),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, apperrors.NewInternalServerError("保存刷新令牌失败", err)
+	}
+	return pair, nil
+}
+
+// IssueVerification 签发一次性验证令牌，用户之前签发的同类令牌随之作废。
+// 上一个令牌签发后未超过 resendInterval 时拒绝签发，避免频繁重发验证邮件
+func (s *TokenService) IssueVerification(ctx context.Context, userID uint, tokenType model.TokenType, ttl, resendInterval time.Duration) (string, time.Time, error) {
+	if userID == 0 || !verificationTypes[tokenType] {
+		return "", time.Time{}, apperrors.NewBadRequest("无效的验证令牌类型", nil)
+	}
+	now := s.now()
+	latest, err := s.tokenRepo.GetLatest(ctx, userID, tokenType)
+	switch {
+	case err == nil:
+		if wait := latest.CreatedAt.Add(resendInterval).Sub(now); wait > 0 {
+			return "", time.Time{}, apperrors.NewTooManyRequests(fmt.Sprintf("请在 %d 秒后重试", int(wait.Seconds())+1), nil)
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return "", time.Time{}, apperrors.NewInternalServerError("获取验证令牌失败", err)
+	}
+	if err := s.tokenRepo.RevokeByUser(ctx, userID, tokenType); err != nil {
+		return "", time.Time{}, apperrors.NewInternalServerError("作废验证令牌失败", err)
+	}
+
+	raw, err := randomToken()
+	if err != nil {
+		return "", time.Time{}, apperrors.NewInternalServerError("生成验证令牌失败", err)
+	}
+	expiresAt := now.Add(ttl)
+	if err := s.tokenRepo.Create(ctx, &model.Token{
+		UserID:    userID,
+		Token:     hashToken(raw),
+		Type:      tokenType,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return "", time.Time{}, apperrors.NewInternalServerError("保存验证令牌失败", err)
+	}
+	return raw, expiresAt, nil
+}
+
+// ConsumeVerification 使用一次性验证令牌，返回令牌所属的用户。
+// 令牌不存在、类型不符、已使用和已过期返回相同的错误
+func (s *TokenService) ConsumeVerification(ctx context.Context, raw string, tokenType model.TokenType) (uint, error) {
+	invalid := apperrors.NewBadRequest("验证链接无效或已过期", nil)
+	if raw == "" {
+		return 0, invalid
+	}
+	token, err := s.tokenRepo.GetByToken(ctx, hashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, invalid
+		}
+		return 0, apperrors.NewInternalServerError("获取验证令牌失败", err)
+	}
+	if token.Type != tokenType || token.IsRevoked || !s.now().Before(token.ExpiresAt) {
+		return 0, invalid
+	}
+	revoked, err := s.tokenRepo.Revoke(ctx, token.ID)
+	if err != nil {
+		return 0, apperrors.NewInternalServerError("使用验证令牌失败", err)
+	}
+	if !revoked {
+		return 0, invalid
+	}
+	return token.UserID, nil
+}
+
+// RevokeRefreshTokens 作废用户的全部刷新令牌，用户在所有设备上都需要重新登录。
+// 已签发的访问令牌在过期前仍然有效
+func (s *TokenService) RevokeRefreshTokens(ctx context.Context, userID uint) error {
+	if userID == 0 {
+		return apperrors.NewBadRequest("缺少用户", nil)
+	}
+	if err := s.tokenRepo.RevokeByUser(ctx, userID, model.TokenTypeRefresh); err != nil {
+		return apperrors.NewInternalServerError("作废刷新令牌失败", err)
+	}
+	return nil
+}
+
+// randomToken 生成随机令牌，以 URL 安全的 base64 编码
+func randomToken() (string, error) {
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashToken 返回令牌的 SHA-256 摘要，十六进制编码
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// optional 将空字符串转换为 nil
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,281 @@
+// Package rpc defines the gRPC API of the auth service, shared by the service
+// and its clients. Messages are plain Go structs encoded with the JSON codec of
+// pkg/grpcclient rather than generated protobuf types.
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/pkg/rbac"
+	"google.golang.org/grpc"
+)
+
+// Full method names of the auth service
+const (
+	ServiceName               = "auth.AuthService"
+	VerifyAPIKeyMethod        = "/" + ServiceName + "/VerifyAPIKey"
+	GetRolePermissionsMethod  = "/" + ServiceName + "/GetRolePermissions"
+	GetUserPermissionsMethod  = "/" + ServiceName + "/GetUserPermissions"
+	IssueTokensMethod         = "/" + ServiceName + "/IssueTokens"
+	IssueVerificationMethod   = "/" + ServiceName + "/IssueVerification"
+	ConsumeVerificationMethod = "/" + ServiceName + "/ConsumeVerification"
+	RevokeTokensMethod        = "/" + ServiceName + "/RevokeTokens"
+)
+
+// VerifyAPIKeyRequest verifies that Signature is the hex-encoded HMAC-SHA256
+// of Payload with the secret of Key. The secret never leaves the auth service.
+type VerifyAPIKeyRequest struct {
+	Key       string `json:"key"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// APIKeyReply is an active API key whose signature was verified
+type APIKeyReply struct {
+	ID          uint     `json:"id"`
+	UserID      uint     `json:"user_id"` // owner of the key
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"` // permission codes granted to the key
+}
+
+// HasPermission reports whether the key was granted the permission code
+func (r *APIKeyReply) HasPermission(code string) bool {
+	for _, permission := range r.Permissions {
+		if permission == code {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermissionsRequest asks for the permissions of a user role, as found in
+// the role claim of access tokens
+type RolePermissionsRequest struct {
+	Role string `json:"role"`
+}
+
+// RolePermissionsReply lists the permission codes granted to a role, empty for
+// unknown roles
+type RolePermissionsReply struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserPermissionsRequest asks for the roles and permissions of a user
+type UserPermissionsRequest struct {
+	UserID uint `json:"user_id"`
+}
+
+// UserPermissionsReply lists the roles of a user, the role of the user's
+// access tokens and the roles assigned to the user, and the permission codes
+// granted to any of them. Both are empty for unknown or inactive users.
+type UserPermissionsReply struct {
+	UserID      uint     `json:"user_id"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// IssueTokensRequest asks for the tokens of a user whose credentials were
+// verified by the user service. UserID, Name, Role and MemberLevel become the
+// claims of the access token.
+type IssueTokensRequest struct {
+	UserID      uint   `json:"user_id"`
+	Name        string `json:"name"`
+	Role        string `json:"role"`
+	MemberLevel int    `json:"member_level"`
+	IP          string `json:"ip"`
+	UserAgent   string `json:"user_agent"`
+}
+
+// TokensReply is a signed access token and the opaque refresh token exchanged
+// for new tokens once it expires
+type TokensReply struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"` // always Bearer
+	ExpiresIn        int64     `json:"expires_in"` // seconds until the access token expires
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// Types of single-use verification tokens
+const (
+	VerificationEmail         = "email_verification"
+	VerificationAccountUnlock = "account_unlock"
+)
+
+// IssueVerificationRequest asks for a single-use token of Type for the user,
+// valid for TTL seconds. Earlier tokens of the same type are revoked. The
+// request is refused while the previous token is younger than ResendInterval
+// seconds.
+type IssueVerificationRequest struct {
+	UserID         uint   `json:"user_id"`
+	Type           string `json:"type"`
+	TTL            int    `json:"ttl"`
+	ResendInterval int    `json:"resend_interval"`
+}
+
+// VerificationReply is an issued verification token
+type VerificationReply struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ConsumeVerificationRequest uses a verification token of Type
+type ConsumeVerificationRequest struct {
+	Token string `json:"token"`
+	Type  string `json:"type"`
+}
+
+// ConsumedVerificationReply is the user a verification token was issued for
+type ConsumedVerificationReply struct {
+	UserID uint `json:"user_id"`
+}
+
+// RevokeTokensRequest revokes every refresh token of the user, e.g. after a
+// password change. Access tokens already issued stay valid until they expire.
+type RevokeTokensRequest struct {
+	UserID uint `json:"user_id"`
+}
+
+// RevokeTokensReply acknowledges that the refresh tokens were revoked
+type RevokeTokensReply struct{}
+
+// AuthServer is the server API of the auth service
+type AuthServer interface {
+	VerifyAPIKey(ctx context.Context, req *VerifyAPIKeyRequest) (*APIKeyReply, error)
+	GetRolePermissions(ctx context.Context, req *RolePermissionsRequest) (*RolePermissionsReply, error)
+	GetUserPermissions(ctx context.Context, req *UserPermissionsRequest) (*UserPermissionsReply, error)
+	IssueTokens(ctx context.Context, req *IssueTokensRequest) (*TokensReply, error)
+	IssueVerification(ctx context.Context, req *IssueVerificationRequest) (*VerificationReply, error)
+	ConsumeVerification(ctx context.Context, req *ConsumeVerificationRequest) (*ConsumedVerificationReply, error)
+	RevokeTokens(ctx context.Context, req *RevokeTokensRequest) (*RevokeTokensReply, error)
+}
+
+// serviceDesc describes the auth service to the gRPC server
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AuthServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("VerifyAPIKey", VerifyAPIKeyMethod, AuthServer.VerifyAPIKey),
+		unary("GetRolePermissions", GetRolePermissionsMethod, AuthServer.GetRolePermissions),
+		unary("GetUserPermissions", GetUserPermissionsMethod, AuthServer.GetUserPermissions),
+		unary("IssueTokens", IssueTokensMethod, AuthServer.IssueTokens),
+		unary("IssueVerification", IssueVerificationMethod, AuthServer.IssueVerification),
+		unary("ConsumeVerification", ConsumeVerificationMethod, AuthServer.ConsumeVerification),
+		unary("RevokeTokens", RevokeTokensMethod, AuthServer.RevokeTokens),
+	},
+}
+
+// RegisterAuthServer registers srv with the gRPC server s
+func RegisterAuthServer(s grpc.ServiceRegistrar, srv AuthServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// unary describes a unary method calling fn on the server, through the
+// interceptors of the server when it has some
+func unary[Req, Reply any](name, fullMethod string, fn func(AuthServer, context.Context, *Req) (*Reply, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(Req)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return fn(srv.(AuthServer), ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return fn(srv.(AuthServer), ctx, req.(*Req))
+			}
+			return interceptor(ctx, in, info, handler)
+		},
+	}
+}
+
+// Client calls the auth service
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient creates a client of the auth service on conn, usually obtained
+// from grpcclient.Factory.Conn("auth")
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// VerifyAPIKey verifies the signature of a request made with an API key
+func (c *Client) VerifyAPIKey(ctx context.Context, req *VerifyAPIKeyRequest) (*APIKeyReply, error) {
+	out := new(APIKeyReply)
+	if err := c.conn.Invoke(ctx, VerifyAPIKeyMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetRolePermissions returns the permission codes granted to a role
+func (c *Client) GetRolePermissions(ctx context.Context, req *RolePermissionsRequest) (*RolePermissionsReply, error) {
+	out := new(RolePermissionsReply)
+	if err := c.conn.Invoke(ctx, GetRolePermissionsMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetUserPermissions returns the roles and permission codes of a user
+func (c *Client) GetUserPermissions(ctx context.Context, req *UserPermissionsRequest) (*UserPermissionsReply, error) {
+	out := new(UserPermissionsReply)
+	if err := c.conn.Invoke(ctx, GetUserPermissionsMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UserGrants returns the roles and permission codes of a user, making the
+// client an rbac.Source
+func (c *Client) UserGrants(ctx context.Context, userID uint) (*rbac.Grants, error) {
+	reply, err := c.GetUserPermissions(ctx, &UserPermissionsRequest{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return &rbac.Grants{UserID: userID, Roles: reply.Roles, Permissions: reply.Permissions}, nil
+}
+
+// IssueTokens issues the access and refresh tokens of a user who logged in
+func (c *Client) IssueTokens(ctx context.Context, req *IssueTokensRequest) (*TokensReply, error) {
+	out := new(TokensReply)
+	if err := c.conn.Invoke(ctx, IssueTokensMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IssueVerification issues a single-use verification token
+func (c *Client) IssueVerification(ctx context.Context, req *IssueVerificationRequest) (*VerificationReply, error) {
+	out := new(VerificationReply)
+	if err := c.conn.Invoke(ctx, IssueVerificationMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ConsumeVerification uses a verification token and returns its user
+func (c *Client) ConsumeVerification(ctx context.Context, req *ConsumeVerificationRequest) (*ConsumedVerificationReply, error) {
+	out := new(ConsumedVerificationReply)
+	if err := c.conn.Invoke(ctx, ConsumeVerificationMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RevokeTokens revokes every refresh token of a user
+func (c *Client) RevokeTokens(ctx context.Context, req *RevokeTokensRequest) (*RevokeTokensReply, error) {
+	out := new(RevokeTokensReply)
+	if err := c.conn.Invoke(ctx, RevokeTokensMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
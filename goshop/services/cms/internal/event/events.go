@@ -0,0 +1,15 @@
+package event
+
+// CMS 服务发布的事件类型
+const (
+	// BannersChanged 在横幅创建、更新或删除后发布，网关据此清除横幅接口的响应缓存。
+	// 横幅按投放时间自动上下线时不发布，由缓存过期时间兜底
+	BannersChanged = "cms.banners_changed"
+)
+
+// BannersChangedEvent 是 cms.banners_changed 事件的数据
+type BannersChangedEvent struct {
+	BannerID uint   `json:"banner_id"`
+	Position string `json:"position"`
+	Action   string `json:"action"` // created、updated 或 deleted
+}
@@ -0,0 +1,76 @@
+// Package graph 将已发布的内容作为联邦 GraphQL 子图提供给网关
+package graph
+
+import (
+	"github.com/yourusername/goshop/pkg/graphql"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/service"
+)
+
+// NewSchema 创建内容子图，只包含前台可见的已发布页面和博文
+func NewSchema(contentService *service.ContentService) (*graphql.Schema, error) {
+	content := &graphql.Object{
+		Name: "Content",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID)},
+			{Name: "type", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "title", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "slug", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "content", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "excerpt", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "coverImage", Type: graphql.String},
+			{Name: "author", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "tags", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(graphql.String)))},
+			{Name: "publishedAt", Type: graphql.String},
+			{Name: "metaTitle", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "metaDescription", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "locale", Type: graphql.NonNullOf(graphql.String)},
+		},
+	}
+	contentList := &graphql.Object{
+		Name: "ContentList",
+		Fields: []*graphql.Field{
+			{Name: "items", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(content)))},
+			{Name: "total", Type: graphql.NonNullOf(graphql.Int)},
+			{Name: "page", Type: graphql.NonNullOf(graphql.Int)},
+			{Name: "pageSize", Type: graphql.NonNullOf(graphql.Int)},
+		},
+	}
+
+	bySlug := func(contentType model.ContentType) graphql.ResolveFunc {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			return contentService.GetPublished(p.Context, contentType, p.Args["slug"].(string), locale(contentService, p))
+		}
+	}
+	slugArgs := []*graphql.Arg{
+		{Name: "slug", Type: graphql.NonNullOf(graphql.String)},
+		{Name: "locale", Type: graphql.String},
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{Name: "page", Type: content, Args: slugArgs, Resolve: bySlug(model.ContentTypePage)},
+			{Name: "post", Type: content, Args: slugArgs, Resolve: bySlug(model.ContentTypePost)},
+			{
+				Name: "posts",
+				Type: graphql.NonNullOf(contentList),
+				Args: []*graphql.Arg{
+					{Name: "locale", Type: graphql.String},
+					{Name: "page", Type: graphql.Int, Default: 1},
+					{Name: "pageSize", Type: graphql.Int, Default: 20},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return contentService.ListPublished(p.Context, model.ContentTypePost, locale(contentService, p), p.Args["page"].(int), p.Args["pageSize"].(int))
+				},
+			},
+		},
+	}
+	return graphql.NewSubgraph(query)
+}
+
+// locale 按 locale 参数和客户端的 Accept-Language 协商内容语言
+func locale(contentService *service.ContentService, p graphql.ResolveParams) string {
+	requested, _ := p.Args["locale"].(string)
+	return contentService.NegotiateLocale(requested, graphql.Header(p.Context).Get("Accept-Language"))
+}
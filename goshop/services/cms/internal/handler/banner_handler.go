@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/cms/internal/service"
+)
+
+// BannerHandler 处理横幅相关的 HTTP 请求
+type BannerHandler struct {
+	bannerService  *service.BannerService
+	contentService *service.ContentService
+	jwtSecret      string
+}
+
+// NewBannerHandler 创建横幅处理器，contentService 用于协商访客语言
+func NewBannerHandler(bannerService *service.BannerService, contentService *service.ContentService, jwtSecret string) *BannerHandler {
+	return &BannerHandler{
+		bannerService:  bannerService,
+		contentService: contentService,
+		jwtSecret:      jwtSecret,
+	}
+}
+
+// RegisterRoutes 注册横幅路由
+func (h *BannerHandler) RegisterRoutes(api *gin.RouterGroup) {
+	banners := api.Group("/cms/banners")
+	{
+		banners.GET("", h.ListActive)
+		banners.POST("/impressions", h.RecordImpressions)
+		banners.POST("/:id/clicks", h.RecordClick)
+	}
+
+	admin := api.Group("/cms/admin/banners", requireEditor(h.jwtSecret))
+	{
+		admin.GET("", h.List)
+		admin.POST("", h.Create)
+		admin.GET("/stats", h.Report)
+		admin.GET("/:id", h.Get)
+		admin.PUT("/:id", h.Update)
+		admin.DELETE("/:id", h.Delete)
+		admin.GET("/:id/stats", h.BannerReport)
+	}
+}
+
+// ListActive 获取当前展示给访客的横幅，可按 position 过滤。
+// 定向信息来自 member_level、segments（逗号分隔）和 device 参数，未指定 device 时根据 User-Agent 判断
+func (h *BannerHandler) ListActive(c *gin.Context) {
+	audience := &service.BannerAudience{
+		MemberLevel: parseIntQuery(c, "member_level", 0),
+		Device:      c.Query("device"),
+		Locale:      h.contentService.NegotiateLocale(c.Query("locale"), c.GetHeader("Accept-Language")),
+	}
+	if audience.Device == "" {
+		audience.Device = service.DetectDevice(c.Request.UserAgent())
+	}
+	for _, segment := range strings.Split(c.Query("segments"), ",") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			audience.Segments = append(audience.Segments, segment)
+		}
+	}
+
+	banners, err := h.bannerService.ListActive(c.Request.Context(), c.Query("position"), audience)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("Vary", "Accept-Language, User-Agent")
+	c.JSON(http.StatusOK, gin.H{"data": banners})
+}
+
+// RecordImpressions 上报横幅曝光
+func (h *BannerHandler) RecordImpressions(c *gin.Context) {
+	var req struct {
+		BannerIDs []uint `json:"banner_ids" binding:"required"`
+	}
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := h.bannerService.RecordImpressions(c.Request.Context(), req.BannerIDs); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RecordClick 上报横幅点击
+func (h *BannerHandler) RecordClick(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.bannerService.RecordClick(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// List 分页获取横幅，可按 position 过滤
+func (h *BannerHandler) List(c *gin.Context) {
+	list, err := h.bannerService.List(c.Request.Context(), c.Query("position"),
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// Get 获取横幅
+func (h *BannerHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	banner, err := h.bannerService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": banner})
+}
+
+// Create 创建横幅
+func (h *BannerHandler) Create(c *gin.Context) {
+	var req service.BannerRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	banner, err := h.bannerService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": banner})
+}
+
+// Update 更新横幅
+func (h *BannerHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.BannerRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	banner, err := h.bannerService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": banner})
+}
+
+// Delete 删除横幅
+func (h *BannerHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.bannerService.Delete(c.Request.Context(), currentEditor(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Report 获取横幅效果报表，可按 position 过滤，from 和 to 为日期，默认最近 30 天
+func (h *BannerHandler) Report(c *gin.Context) {
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		return
+	}
+	report, err := h.bannerService.Report(c.Request.Context(), c.Query("position"), from, to,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// BannerReport 获取单个横幅的效果报表，包含每日曝光和点击
+func (h *BannerHandler) BannerReport(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		return
+	}
+	report, err := h.bannerService.BannerReport(c.Request.Context(), id, from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
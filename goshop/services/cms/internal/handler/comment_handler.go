@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/service"
+)
+
+// CommentHandler 处理博文评论相关的 HTTP 请求
+type CommentHandler struct {
+	commentService *service.CommentService
+	jwtSecret      string
+}
+
+// NewCommentHandler 创建评论处理器
+func NewCommentHandler(commentService *service.CommentService, jwtSecret string) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		jwtSecret:      jwtSecret,
+	}
+}
+
+// RegisterRoutes 注册评论路由
+func (h *CommentHandler) RegisterRoutes(api *gin.RouterGroup) {
+	posts := api.Group("/cms/posts")
+	{
+		posts.GET("/:slug/comments", h.ListForPost)
+		posts.POST("/:slug/comments", optionalCommenter(h.jwtSecret), h.Submit)
+	}
+
+	admin := api.Group("/cms/admin/comments", requireEditor(h.jwtSecret))
+	{
+		admin.GET("", h.List)
+		admin.POST("/:id/approve", h.Approve)
+		admin.POST("/:id/reject", h.Reject)
+		admin.DELETE("/:id", h.Delete)
+	}
+}
+
+// ListForPost 分页获取博文下已通过的评论及其回复
+func (h *CommentHandler) ListForPost(c *gin.Context) {
+	list, err := h.commentService.ListForPost(c.Request.Context(), c.Param("slug"),
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// Submit 发表评论或回复，游客和登录用户均可发表。
+// 未直接通过的评论返回 202，表示需要等待审核
+func (h *CommentHandler) Submit(c *gin.Context) {
+	var req service.CommentRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	source := &service.CommentSource{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	comment, err := h.commentService.Submit(c.Request.Context(), c.Param("slug"), currentCommenter(c), source, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if comment.Status != model.CommentStatusApproved {
+		c.JSON(http.StatusAccepted, gin.H{"data": comment})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": comment})
+}
+
+// List 分页获取评论，可按 status 和 content_id 过滤，供后台审核使用
+func (h *CommentHandler) List(c *gin.Context) {
+	contentID, ok := parseIDQuery(c, "content_id")
+	if !ok {
+		return
+	}
+	list, err := h.commentService.List(c.Request.Context(), &service.CommentQuery{
+		ContentID: contentID,
+		Status:    model.CommentStatus(c.Query("status")),
+		Page:      parseIntQuery(c, "page", 1),
+		PageSize:  parseIntQuery(c, "page_size", 20),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// Approve 审核通过评论
+func (h *CommentHandler) Approve(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	comment, err := h.commentService.Approve(c.Request.Context(), currentEditor(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": comment})
+}
+
+// Reject 拒绝评论
+func (h *CommentHandler) Reject(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	comment, err := h.commentService.Reject(c.Request.Context(), currentEditor(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": comment})
+}
+
+// Delete 删除评论
+func (h *CommentHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.commentService.Delete(c.Request.Context(), currentEditor(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/service"
+)
+
+// ContentHandler 处理内容相关的 HTTP 请求
+type ContentHandler struct {
+	contentService *service.ContentService
+	jwtSecret      string
+}
+
+// NewContentHandler 创建内容处理器，jwtSecret 用于校验后台接口的访问令牌
+func NewContentHandler(contentService *service.ContentService, jwtSecret string) *ContentHandler {
+	return &ContentHandler{
+		contentService: contentService,
+		jwtSecret:      jwtSecret,
+	}
+}
+
+// RegisterRoutes 注册内容路由
+func (h *ContentHandler) RegisterRoutes(api *gin.RouterGroup) {
+	cms := api.Group("/cms")
+	{
+		cms.GET("/pages/:slug", h.GetPage)
+		cms.GET("/pages/:slug/layout", h.GetPageLayout)
+		cms.GET("/posts", h.ListPosts)
+		cms.GET("/posts/:slug", h.GetPost)
+	}
+
+	admin := api.Group("/cms/admin/contents", requireEditor(h.jwtSecret))
+	{
+		admin.GET("", h.List)
+		admin.POST("", h.Create)
+		admin.GET("/:id", h.Get)
+		admin.PUT("/:id", h.Update)
+		admin.DELETE("/:id", h.Delete)
+		admin.POST("/:id/publish", h.Publish)
+		admin.POST("/:id/archive", h.Archive)
+		admin.POST("/:id/submit", h.SubmitForReview)
+		admin.POST("/:id/approve", h.Approve)
+		admin.POST("/:id/reject", h.Reject)
+		admin.GET("/:id/reviews", h.ListReviews)
+		admin.GET("/:id/revisions", h.ListRevisions)
+		admin.GET("/:id/revisions/:version", h.GetRevision)
+		admin.POST("/:id/revisions/:version/restore", h.Restore)
+		admin.GET("/:id/translations", h.ListTranslations)
+		admin.GET("/:id/translations/:locale", h.GetTranslation)
+		admin.PUT("/:id/translations/:locale", h.SaveTranslation)
+		admin.DELETE("/:id/translations/:locale", h.DeleteTranslation)
+	}
+
+	api.GET("/cms/admin/translations", requireEditor(h.jwtSecret), h.TranslationProgress)
+	api.GET("/cms/admin/workflow", requireEditor(h.jwtSecret), h.Workflow)
+
+	layouts := api.Group("/cms/admin/layouts", requireEditor(h.jwtSecret))
+	{
+		layouts.POST("/validate", h.ValidateLayout)
+	}
+}
+
+// GetPage 根据 slug 获取已发布的页面
+func (h *ContentHandler) GetPage(c *gin.Context) {
+	h.getPublished(c, model.ContentTypePage)
+}
+
+// GetPost 根据 slug 获取已发布的博文
+func (h *ContentHandler) GetPost(c *gin.Context) {
+	h.getPublished(c, model.ContentTypePost)
+}
+
+// GetPageLayout 根据 slug 获取已发布页面的区块布局
+func (h *ContentHandler) GetPageLayout(c *gin.Context) {
+	page, err := h.contentService.GetPageLayout(c.Request.Context(), c.Param("slug"), h.negotiateLocale(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("Content-Language", page.Locale)
+	c.JSON(http.StatusOK, gin.H{"data": page})
+}
+
+func (h *ContentHandler) getPublished(c *gin.Context, contentType model.ContentType) {
+	content, err := h.contentService.GetPublished(c.Request.Context(), contentType, c.Param("slug"), h.negotiateLocale(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("Content-Language", content.Locale)
+	c.JSON(http.StatusOK, gin.H{"data": content})
+}
+
+// ListPosts 分页获取已发布的博文
+func (h *ContentHandler) ListPosts(c *gin.Context) {
+	list, err := h.contentService.ListPublished(c.Request.Context(), model.ContentTypePost, h.negotiateLocale(c),
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// negotiateLocale 根据 locale 参数和 Accept-Language 请求头选择前台接口使用的语言
+func (h *ContentHandler) negotiateLocale(c *gin.Context) string {
+	c.Header("Vary", "Accept-Language")
+	return h.contentService.NegotiateLocale(c.Query("locale"), c.GetHeader("Accept-Language"))
+}
+
+// List 分页获取内容，可按 type、status、author_id 和 keyword 过滤
+func (h *ContentHandler) List(c *gin.Context) {
+	authorID, ok := parseIDQuery(c, "author_id")
+	if !ok {
+		return
+	}
+	list, err := h.contentService.List(c.Request.Context(), &service.ContentQuery{
+		Type:     model.ContentType(c.Query("type")),
+		Status:   model.ContentStatus(c.Query("status")),
+		AuthorID: authorID,
+		Keyword:  c.Query("keyword"),
+		Page:     parseIntQuery(c, "page", 1),
+		PageSize: parseIntQuery(c, "page_size", 20),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// Get 获取内容
+func (h *ContentHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	content, err := h.contentService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": content})
+}
+
+// Create 创建内容
+func (h *ContentHandler) Create(c *gin.Context) {
+	var req service.ContentRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	content, err := h.contentService.Create(c.Request.Context(), currentEditor(c), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": content})
+}
+
+// Update 更新内容
+func (h *ContentHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.ContentRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	content, err := h.contentService.Update(c.Request.Context(), currentEditor(c), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": content})
+}
+
+// Publish 发布内容
+func (h *ContentHandler) Publish(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	content, err := h.contentService.Publish(c.Request.Context(), currentEditor(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": content})
+}
+
+// Archive 归档内容
+func (h *ContentHandler) Archive(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	content, err := h.contentService.Archive(c.Request.Context(), currentEditor(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": content})
+}
+
+// Delete 删除内容
+func (h *ContentHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.contentService.Delete(c.Request.Context(), currentEditor(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListRevisions 分页获取内容的修订版本
+func (h *ContentHandler) ListRevisions(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	list, err := h.contentService.ListRevisions(c.Request.Context(), id,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetRevision 获取内容的指定版本，包含完整正文
+func (h *ContentHandler) GetRevision(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	version, ok := parseVersionParam(c)
+	if !ok {
+		return
+	}
+	revision, err := h.contentService.GetRevision(c.Request.Context(), id, version)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": revision})
+}
+
+// Restore 将指定版本恢复为当前草稿
+func (h *ContentHandler) Restore(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	version, ok := parseVersionParam(c)
+	if !ok {
+		return
+	}
+	content, err := h.contentService.Restore(c.Request.Context(), currentEditor(c), id, version)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": content})
+}
+
+func parseVersionParam(c *gin.Context) (int, bool) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		respondError(c, apperrors.NewBadRequest("无效的 version", err))
+		return 0, false
+	}
+	return version, true
+}
+
+// ValidateLayout 校验区块布局，返回规范化后的布局供编辑器预览
+func (h *ContentHandler) ValidateLayout(c *gin.Context) {
+	var layout model.Layout
+	if err := validator.BindJSON(c, &layout); err != nil {
+		respondError(c, err)
+		return
+	}
+	normalized, err := h.contentService.ValidateLayout(&layout)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if normalized == nil {
+		normalized = &model.Layout{Blocks: []model.LayoutBlock{}}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": normalized})
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/cms/internal/service"
+)
+
+// FAQHandler 处理常见问题相关的 HTTP 请求
+type FAQHandler struct {
+	faqService *service.FAQService
+	jwtSecret  string
+}
+
+// NewFAQHandler 创建常见问题处理器
+func NewFAQHandler(faqService *service.FAQService, jwtSecret string) *FAQHandler {
+	return &FAQHandler{
+		faqService: faqService,
+		jwtSecret:  jwtSecret,
+	}
+}
+
+// RegisterRoutes 注册常见问题路由
+func (h *FAQHandler) RegisterRoutes(api *gin.RouterGroup) {
+	faqs := api.Group("/cms/faqs")
+	{
+		faqs.GET("", h.ListGroups)
+		faqs.GET("/:id", h.GetPublished)
+		faqs.POST("/:id/feedback", h.Feedback)
+	}
+
+	categories := api.Group("/cms/admin/faq-categories", requireEditor(h.jwtSecret))
+	{
+		categories.GET("", h.ListCategories)
+		categories.POST("", h.CreateCategory)
+		categories.PUT("/:id", h.UpdateCategory)
+		categories.DELETE("/:id", h.DeleteCategory)
+	}
+
+	admin := api.Group("/cms/admin/faqs", requireEditor(h.jwtSecret))
+	{
+		admin.GET("", h.List)
+		admin.POST("", h.Create)
+		admin.GET("/report", h.Report)
+		admin.GET("/:id", h.Get)
+		admin.PUT("/:id", h.Update)
+		admin.DELETE("/:id", h.Delete)
+	}
+}
+
+// ListGroups 获取按分类分组的常见问题，可用 q 参数搜索
+func (h *FAQHandler) ListGroups(c *gin.Context) {
+	groups, err := h.faqService.ListGroups(c.Request.Context(), c.Query("q"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": groups})
+}
+
+// GetPublished 获取已发布的常见问题
+func (h *FAQHandler) GetPublished(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	faq, err := h.faqService.GetPublished(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": faq})
+}
+
+// Feedback 反馈答案是否有帮助，同一访客按 IP 去重
+func (h *FAQHandler) Feedback(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Helpful *bool `json:"helpful" binding:"required"`
+	}
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := h.faqService.Feedback(c.Request.Context(), id, c.ClientIP(), *req.Helpful); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListCategories 获取所有常见问题分类
+func (h *FAQHandler) ListCategories(c *gin.Context) {
+	categories, err := h.faqService.ListCategories(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+// CreateCategory 创建常见问题分类
+func (h *FAQHandler) CreateCategory(c *gin.Context) {
+	var req service.FAQCategoryRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	category, err := h.faqService.CreateCategory(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": category})
+}
+
+// UpdateCategory 更新常见问题分类
+func (h *FAQHandler) UpdateCategory(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.FAQCategoryRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	category, err := h.faqService.UpdateCategory(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": category})
+}
+
+// DeleteCategory 删除常见问题分类
+func (h *FAQHandler) DeleteCategory(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.faqService.DeleteCategory(c.Request.Context(), currentEditor(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// List 分页获取常见问题，可按 category_id 和 keyword 过滤
+func (h *FAQHandler) List(c *gin.Context) {
+	categoryID, ok := parseIDQuery(c, "category_id")
+	if !ok {
+		return
+	}
+	list, err := h.faqService.List(c.Request.Context(), &service.FAQQuery{
+		CategoryID: categoryID,
+		Keyword:    c.Query("keyword"),
+		Page:       parseIntQuery(c, "page", 1),
+		PageSize:   parseIntQuery(c, "page_size", 20),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// Get 获取常见问题
+func (h *FAQHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	faq, err := h.faqService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": faq})
+}
+
+// Create 创建常见问题
+func (h *FAQHandler) Create(c *gin.Context) {
+	var req service.FAQRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	faq, err := h.faqService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": faq})
+}
+
+// Update 更新常见问题
+func (h *FAQHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.FAQRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	faq, err := h.faqService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": faq})
+}
+
+// Delete 删除常见问题
+func (h *FAQHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.faqService.Delete(c.Request.Context(), currentEditor(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Report 获取低分答案报表，min_votes 为最少反馈次数，max_rate 为有帮助比例上限（0 到 1）
+func (h *FAQHandler) Report(c *gin.Context) {
+	var maxRate float64
+	if raw := c.Query("max_rate"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || v > 1 {
+			respondError(c, apperrors.NewBadRequest("无效的 max_rate", err))
+			return
+		}
+		maxRate = v
+	}
+	report, err := h.faqService.Report(c.Request.Context(), &service.FAQReportQuery{
+		MinVotes: parseIntQuery(c, "min_votes", 0),
+		MaxRate:  maxRate,
+		Page:     parseIntQuery(c, "page", 1),
+		PageSize: parseIntQuery(c, "page_size", 20),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/cms/internal/service"
+)
+
+// MenuHandler 处理导航菜单相关的 HTTP 请求
+type MenuHandler struct {
+	menuService *service.MenuService
+	jwtSecret   string
+}
+
+// NewMenuHandler 创建导航菜单处理器
+func NewMenuHandler(menuService *service.MenuService, jwtSecret string) *MenuHandler {
+	return &MenuHandler{
+		menuService: menuService,
+		jwtSecret:   jwtSecret,
+	}
+}
+
+// RegisterRoutes 注册导航菜单路由
+func (h *MenuHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.GET("/cms/menus/:location", h.GetTree)
+
+	admin := api.Group("/cms/admin/menus", requireEditor(h.jwtSecret))
+	{
+		admin.GET("", h.List)
+		admin.POST("", h.Create)
+		admin.GET("/:id", h.Get)
+		admin.PUT("/:id", h.Update)
+		admin.DELETE("/:id", h.Delete)
+		admin.POST("/:id/items", h.CreateItem)
+		admin.PUT("/:id/items/order", h.Reorder)
+		admin.PUT("/:id/items/:item_id", h.UpdateItem)
+		admin.DELETE("/:id/items/:item_id", h.DeleteItem)
+	}
+}
+
+// GetTree 获取位置上的菜单树，如 header、footer
+func (h *MenuHandler) GetTree(c *gin.Context) {
+	tree, err := h.menuService.GetTree(c.Request.Context(), c.Param("location"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, gin.H{"data": tree})
+}
+
+// List 获取所有菜单
+func (h *MenuHandler) List(c *gin.Context) {
+	menus, err := h.menuService.ListMenus(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": menus})
+}
+
+// Get 获取菜单及其菜单项树
+func (h *MenuHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	menu, err := h.menuService.GetMenu(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": menu})
+}
+
+// Create 创建菜单
+func (h *MenuHandler) Create(c *gin.Context) {
+	var req service.MenuRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	menu, err := h.menuService.CreateMenu(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": menu})
+}
+
+// Update 更新菜单
+func (h *MenuHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.MenuRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	menu, err := h.menuService.UpdateMenu(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": menu})
+}
+
+// Delete 删除菜单
+func (h *MenuHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.menuService.DeleteMenu(c.Request.Context(), currentEditor(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// CreateItem 添加菜单项
+func (h *MenuHandler) CreateItem(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.MenuItemRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	item, err := h.menuService.CreateItem(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": item})
+}
+
+// UpdateItem 更新菜单项
+func (h *MenuHandler) UpdateItem(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := parseIDParam(c, "item_id")
+	if !ok {
+		return
+	}
+	var req service.MenuItemRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	item, err := h.menuService.UpdateItem(c.Request.Context(), id, itemID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// DeleteItem 删除菜单项及其子项
+func (h *MenuHandler) DeleteItem(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := parseIDParam(c, "item_id")
+	if !ok {
+		return
+	}
+	if err := h.menuService.DeleteItem(c.Request.Context(), id, itemID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Reorder 批量调整菜单项的父级和排序
+func (h *MenuHandler) Reorder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.ReorderMenuRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	menu, err := h.menuService.Reorder(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": menu})
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+)
+
+// respondError 中止请求，由错误中间件将错误转换为统一的 JSON 响应
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// parseIDParam 解析路径中的 ID 参数
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		respondError(c, apperrors.NewBadRequest("无效的 "+name, err))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseIDQuery 解析可选的 ID 查询参数，未提供时返回 0
+func parseIDQuery(c *gin.Context, name string) (uint, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		respondError(c, apperrors.NewBadRequest("无效的 "+name, err))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseIntQuery 解析整数查询参数，未提供或格式错误时返回默认值
+func parseIntQuery(c *gin.Context, name string, def int) int {
+	v, err := strconv.Atoi(c.Query(name))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// parseDateQuery 解析可选的日期查询参数（格式 2006-01-02），未提供时返回零值
+func parseDateQuery(c *gin.Context, name string) (time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return time.Time{}, true
+	}
+	date, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		respondError(c, apperrors.NewBadRequest("无效的 "+name, err))
+		return time.Time{}, false
+	}
+	return date, true
+}
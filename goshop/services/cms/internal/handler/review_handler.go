@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/service"
+)
+
+// reviewAction 是审核操作的服务方法
+type reviewAction func(ctx context.Context, editor *service.Editor, id uint, req *service.ReviewRequest) (*model.Content, error)
+
+// Workflow 获取内容审核流程的配置
+func (h *ContentHandler) Workflow(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.contentService.Workflow()})
+}
+
+// SubmitForReview 提交内容审核，可附带说明
+func (h *ContentHandler) SubmitForReview(c *gin.Context) {
+	h.review(c, h.contentService.SubmitForReview)
+}
+
+// Approve 审核通过内容，可附带审核意见
+func (h *ContentHandler) Approve(c *gin.Context) {
+	h.review(c, h.contentService.Approve)
+}
+
+// Reject 驳回内容，必须附带审核意见
+func (h *ContentHandler) Reject(c *gin.Context) {
+	h.review(c, h.contentService.Reject)
+}
+
+// ListReviews 获取内容的审核记录
+func (h *ContentHandler) ListReviews(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	reviews, err := h.contentService.ListReviews(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": reviews})
+}
+
+func (h *ContentHandler) review(c *gin.Context, action reviewAction) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	// 请求体可以为空，只有驳回时必须填写审核意见
+	var req service.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, validator.Translate(err, validator.Locale(c.GetHeader("Accept-Language"))))
+		return
+	}
+	content, err := action(c.Request.Context(), currentEditor(c), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": content})
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/service"
+)
+
+// TemplateHandler 处理消息模板相关的 HTTP 请求
+type TemplateHandler struct {
+	templateService *service.TemplateService
+	jwtSecret       string
+}
+
+// NewTemplateHandler 创建消息模板处理器
+func NewTemplateHandler(templateService *service.TemplateService, jwtSecret string) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: templateService,
+		jwtSecret:       jwtSecret,
+	}
+}
+
+// RegisterRoutes 注册消息模板路由。渲染接口只供内部的通知服务调用，不经过网关暴露
+func (h *TemplateHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.POST("/cms/templates/render", h.Render)
+
+	admin := api.Group("/cms/admin/templates", requireEditor(h.jwtSecret))
+	{
+		admin.GET("", h.List)
+		admin.POST("", h.Create)
+		admin.GET("/:id", h.Get)
+		admin.PUT("/:id", h.Update)
+		admin.DELETE("/:id", h.Delete)
+		admin.POST("/:id/preview", h.Preview)
+		admin.GET("/:id/versions", h.ListVersions)
+		admin.GET("/:id/versions/:version", h.GetVersion)
+		admin.POST("/:id/versions/:version/restore", h.Restore)
+	}
+}
+
+// Render 使用变量渲染消息模板
+func (h *TemplateHandler) Render(c *gin.Context) {
+	var req service.RenderRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	message, err := h.templateService.Render(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": message})
+}
+
+// List 分页获取消息模板，可按 key 前缀、channel 和 locale 过滤
+func (h *TemplateHandler) List(c *gin.Context) {
+	list, err := h.templateService.List(c.Request.Context(), &service.TemplateQuery{
+		Key:      c.Query("key"),
+		Channel:  model.TemplateChannel(c.Query("channel")),
+		Locale:   c.Query("locale"),
+		Page:     parseIntQuery(c, "page", 1),
+		PageSize: parseIntQuery(c, "page_size", 20),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// Get 获取消息模板
+func (h *TemplateHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	template, err := h.templateService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": template})
+}
+
+// Create 创建消息模板
+func (h *TemplateHandler) Create(c *gin.Context) {
+	var req service.TemplateRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	template, err := h.templateService.Create(c.Request.Context(), currentEditor(c), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": template})
+}
+
+// Update 更新消息模板
+func (h *TemplateHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.TemplateRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	template, err := h.templateService.Update(c.Request.Context(), currentEditor(c), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": template})
+}
+
+// Delete 删除消息模板
+func (h *TemplateHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.templateService.Delete(c.Request.Context(), currentEditor(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Preview 使用示例变量预览消息模板
+func (h *TemplateHandler) Preview(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.PreviewRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	message, err := h.templateService.Preview(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": message})
+}
+
+// ListVersions 分页获取消息模板的历史版本
+func (h *TemplateHandler) ListVersions(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	list, err := h.templateService.ListVersions(c.Request.Context(), id,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetVersion 获取消息模板的指定版本
+func (h *TemplateHandler) GetVersion(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	version, ok := parseVersionParam(c)
+	if !ok {
+		return
+	}
+	v, err := h.templateService.GetVersion(c.Request.Context(), id, version)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": v})
+}
+
+// Restore 将消息模板恢复为指定版本的内容
+func (h *TemplateHandler) Restore(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	version, ok := parseVersionParam(c)
+	if !ok {
+		return
+	}
+	template, err := h.templateService.Restore(c.Request.Context(), currentEditor(c), id, version)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": template})
+}
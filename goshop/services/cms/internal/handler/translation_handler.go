@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/service"
+)
+
+// TranslationProgress 分页获取内容的翻译进度，可按 type、status 和 keyword 过滤
+func (h *ContentHandler) TranslationProgress(c *gin.Context) {
+	list, err := h.contentService.TranslationProgress(c.Request.Context(), &service.ContentQuery{
+		Type:     model.ContentType(c.Query("type")),
+		Status:   model.ContentStatus(c.Query("status")),
+		Keyword:  c.Query("keyword"),
+		Page:     parseIntQuery(c, "page", 1),
+		PageSize: parseIntQuery(c, "page_size", 20),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// ListTranslations 获取内容在各语言下的翻译进度
+func (h *ContentHandler) ListTranslations(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	statuses, err := h.contentService.ListTranslations(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": statuses})
+}
+
+// GetTranslation 获取内容在某个语言下的译文
+func (h *ContentHandler) GetTranslation(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	translation, err := h.contentService.GetTranslation(c.Request.Context(), id, c.Param("locale"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": translation})
+}
+
+// SaveTranslation 保存内容在某个语言下的译文
+func (h *ContentHandler) SaveTranslation(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.TranslationRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	translation, err := h.contentService.SaveTranslation(c.Request.Context(), currentEditor(c), id, c.Param("locale"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": translation})
+}
+
+// DeleteTranslation 删除内容在某个语言下的译文
+func (h *ContentHandler) DeleteTranslation(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.contentService.DeleteTranslation(c.Request.Context(), currentEditor(c), id, c.Param("locale")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// BannerDailyStat 表示横幅按天汇总的曝光和点击次数
+type BannerDailyStat struct {
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	BannerID    uint      `json:"banner_id" gorm:"uniqueIndex:idx_banner_day;not null"`
+	Date        time.Time `json:"date" gorm:"type:date;uniqueIndex:idx_banner_day;not null"`
+	Impressions int       `json:"impressions" gorm:"not null;default:0"`
+	Clicks      int       `json:"clicks" gorm:"not null;default:0"`
+	UpdatedAt   time.Time `json:"-"`
+}
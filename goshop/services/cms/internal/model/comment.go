@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CommentStatus 表示评论的审核状态
+type CommentStatus string
+
+const (
+	// CommentStatusPending 待审核
+	CommentStatusPending CommentStatus = "pending"
+	// CommentStatusApproved 已通过，前台展示
+	CommentStatusApproved CommentStatus = "approved"
+	// CommentStatusRejected 已拒绝
+	CommentStatusRejected CommentStatus = "rejected"
+	// CommentStatusSpam 被识别为垃圾评论
+	CommentStatusSpam CommentStatus = "spam"
+)
+
+// Comment 表示博文下的评论，游客和登录用户都可以发表，回复通过 ParentID 关联
+type Comment struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	ContentID   uint           `json:"content_id" gorm:"index;not null"`
+	ParentID    *uint          `json:"parent_id" gorm:"index"`
+	RootID      *uint          `json:"root_id" gorm:"index"` // 所属的第一级评论，第一级评论为空
+	UserID      *uint          `json:"user_id" gorm:"index"` // 游客评论为空
+	AuthorName  string         `json:"author_name" gorm:"size:50;not null"`
+	AuthorEmail string         `json:"-" gorm:"size:100"`
+	Body        string         `json:"body" gorm:"type:text;not null"`
+	Status      CommentStatus  `json:"status" gorm:"size:20;not null;default:'pending';index"`
+	SpamScore   int            `json:"spam_score" gorm:"not null;default:0"`
+	SpamReasons StringArray    `json:"spam_reasons" gorm:"type:jsonb"`
+	IP          string         `json:"-" gorm:"size:45;index"`
+	UserAgent   string         `json:"-" gorm:"size:255"`
+	ModeratorID *uint          `json:"moderator_id"`
+	ModeratedAt *time.Time     `json:"moderated_at"`
+	Replies     []*Comment     `json:"replies,omitempty" gorm:"-"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
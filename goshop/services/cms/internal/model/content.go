@@ -27,6 +27,10 @@ type ContentStatus string
 const (
 	// ContentStatusDraft 草稿
 	ContentStatusDraft ContentStatus = "draft"
+	// ContentStatusInReview 已提交审核，审核期间不能修改
+	ContentStatusInReview ContentStatus = "in_review"
+	// ContentStatusApproved 已审核通过，等待发布
+	ContentStatusApproved ContentStatus = "approved"
 	// ContentStatusPublished 已发布
 	ContentStatusPublished ContentStatus = "published"
 	// ContentStatusArchived 已归档
@@ -57,6 +61,7 @@ type Content struct {
 	Title           string         `json:"title" gorm:"size:255;not null"`
 	Slug            string         `json:"slug" gorm:"size:255;uniqueIndex;not null"`
 	Content         string         `json:"content" gorm:"type:text"`
+	Layout          *Layout        `json:"layout,omitempty" gorm:"type:jsonb"` // 区块布局，设置后页面按区块渲染
 	Excerpt         string         `json:"excerpt" gorm:"size:500"`
 	CoverImage      *string        `json:"cover_image" gorm:"size:255"`
 	Author          string         `json:"author" gorm:"size:50"`
@@ -66,11 +71,13 @@ type Content struct {
 	Categories      []Category     `json:"categories" gorm:"many2many:content_categories"`
 	PublishedAt     *time.Time     `json:"published_at"`
 	ViewCount       int            `json:"view_count" gorm:"default:0"`
-	IsSticky        bool           `json:"is_sticky" gorm:"default:false"`   // 是否置顶
-	SortOrder       int            `json:"sort_order" gorm:"default:0"`      // 排序顺序
-	MetaTitle       string         `json:"meta_title" gorm:"size:255"`       // SEO标题
-	MetaKeywords    string         `json:"meta_keywords" gorm:"size:255"`    // SEO关键词
-	MetaDescription string         `json:"meta_description" gorm:"size:500"` // SEO描述
+	IsSticky        bool           `json:"is_sticky" gorm:"default:false"`       // 是否置顶
+	CommentsClosed  bool           `json:"comments_closed" gorm:"default:false"` // 是否关闭评论
+	SortOrder       int            `json:"sort_order" gorm:"default:0"`          // 排序顺序
+	MetaTitle       string         `json:"meta_title" gorm:"size:255"`           // SEO标题
+	MetaKeywords    string         `json:"meta_keywords" gorm:"size:255"`        // SEO关键词
+	MetaDescription string         `json:"meta_description" gorm:"size:500"`     // SEO描述
+	Locale          string         `json:"locale,omitempty" gorm:"-"`            // 前台接口返回内容时实际使用的语言
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
@@ -121,19 +128,23 @@ type MenuItem struct {
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
-// Banner 表示广告横幅
+// Banner 表示广告横幅。定向规则为空表示不限，设置多个规则时需要全部满足
 type Banner struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Title       string         `json:"title" gorm:"size:100;not null"`
-	Image       string         `json:"image" gorm:"size:255;not null"`
-	URL         string         `json:"url" gorm:"size:255"`
-	Position    string         `json:"position" gorm:"size:50;not null"` // 位置：如home_top, sidebar
-	Description string         `json:"description" gorm:"size:255"`
-	StartAt     time.Time      `json:"start_at" gorm:"not null"`
-	EndAt       time.Time      `json:"end_at" gorm:"not null"`
-	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	SortOrder   int            `json:"sort_order" gorm:"default:0"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	Title          string         `json:"title" gorm:"size:100;not null"`
+	Image          string         `json:"image" gorm:"size:255;not null"`
+	URL            string         `json:"url" gorm:"size:255"`
+	Position       string         `json:"position" gorm:"size:50;not null"` // 位置：如home_top, sidebar
+	Description    string         `json:"description" gorm:"size:255"`
+	StartAt        time.Time      `json:"start_at" gorm:"not null"`
+	EndAt          time.Time      `json:"end_at" gorm:"not null"`
+	IsActive       bool           `json:"is_active" gorm:"default:true"`
+	SortOrder      int            `json:"sort_order" gorm:"default:0"`
+	MinMemberLevel *int           `json:"min_member_level"`           // 最低会员等级
+	Segments       StringArray    `json:"segments" gorm:"type:jsonb"` // 用户分群，属于任一分群即可展示
+	Devices        StringArray    `json:"devices" gorm:"type:jsonb"`  // 设备类型：mobile, tablet, desktop
+	Locales        StringArray    `json:"locales" gorm:"type:jsonb"`  // 语言，如 zh-CN、en-US
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
 }
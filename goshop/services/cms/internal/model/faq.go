@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FAQCategory 表示常见问题分类，分类下还有问题时不能删除
+type FAQCategory struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"size:50;not null"`
+	Slug        string    `json:"slug" gorm:"size:50;uniqueIndex;not null"`
+	Description string    `json:"description" gorm:"size:255"`
+	Icon        *string   `json:"icon" gorm:"size:50"`
+	SortOrder   int       `json:"sort_order" gorm:"default:0"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// FAQ 表示常见问题，访客可以反馈答案是否有帮助
+type FAQ struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	CategoryID      uint           `json:"category_id" gorm:"index;not null"`
+	Question        string         `json:"question" gorm:"size:255;not null"`
+	Answer          string         `json:"answer" gorm:"type:text;not null"`
+	Keywords        StringArray    `json:"keywords" gorm:"type:jsonb"` // 搜索时额外匹配的关键词，如问题的其他问法
+	SortOrder       int            `json:"sort_order" gorm:"default:0"`
+	IsPublished     bool           `json:"is_published" gorm:"default:false"`
+	HelpfulCount    int            `json:"helpful_count" gorm:"not null;default:0"`     // 反馈有帮助的次数
+	NotHelpfulCount int            `json:"not_helpful_count" gorm:"not null;default:0"` // 反馈没有帮助的次数
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
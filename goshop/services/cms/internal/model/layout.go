@@ -0,0 +1,103 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// BlockType 表示页面区块类型
+type BlockType string
+
+const (
+	// BlockTypeHero 首屏大图
+	BlockTypeHero BlockType = "hero"
+	// BlockTypeProductCarousel 商品轮播
+	BlockTypeProductCarousel BlockType = "product_carousel"
+	// BlockTypeRichText 富文本
+	BlockTypeRichText BlockType = "rich_text"
+	// BlockTypeBannerGrid 横幅宫格
+	BlockTypeBannerGrid BlockType = "banner_grid"
+	// BlockTypeMarkdown Markdown 文本
+	BlockTypeMarkdown BlockType = "markdown"
+)
+
+// Layout 表示由有序区块组成的页面布局，保存前由服务层校验并规范化每个区块的数据
+type Layout struct {
+	Blocks []LayoutBlock `json:"blocks"`
+}
+
+// LayoutBlock 表示页面中的一个区块，Data 的结构由 Type 决定
+type LayoutBlock struct {
+	ID   string          `json:"id"` // 区块标识，在同一页面内唯一，供前端作为渲染键
+	Type BlockType       `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Value 实现 driver.Valuer 接口
+func (l Layout) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (l *Layout) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("类型断言为 []byte 失败")
+	}
+	return json.Unmarshal(b, l)
+}
+
+// HeroBlock 首屏大图区块
+type HeroBlock struct {
+	Title       string `json:"title"`
+	Subtitle    string `json:"subtitle,omitempty"`
+	Image       string `json:"image"`
+	MobileImage string `json:"mobile_image,omitempty"`
+	LinkURL     string `json:"link_url,omitempty"`
+	LinkText    string `json:"link_text,omitempty"`
+}
+
+// ProductCarouselBlock 商品轮播区块，只保存商品 ID，价格和库存由前端从商品服务实时获取
+type ProductCarouselBlock struct {
+	Title      string `json:"title,omitempty"`
+	ProductIDs []uint `json:"product_ids"`
+	MoreURL    string `json:"more_url,omitempty"`
+}
+
+// RichTextBlock 富文本区块，以结构化的段落保存，不接受原始 HTML
+type RichTextBlock struct {
+	Paragraphs []RichTextParagraph `json:"paragraphs"`
+}
+
+// RichTextParagraph 富文本段落，Style 为 paragraph、heading、quote 或 list_item
+type RichTextParagraph struct {
+	Style string         `json:"style"`
+	Spans []RichTextSpan `json:"spans"`
+}
+
+// RichTextSpan 段落中格式相同的一段文字
+type RichTextSpan struct {
+	Text   string `json:"text"`
+	Bold   bool   `json:"bold,omitempty"`
+	Italic bool   `json:"italic,omitempty"`
+	Link   string `json:"link,omitempty"`
+}
+
+// BannerGridBlock 横幅宫格区块
+type BannerGridBlock struct {
+	Columns int              `json:"columns"`
+	Items   []BannerGridItem `json:"items"`
+}
+
+// BannerGridItem 宫格中的一张横幅
+type BannerGridItem struct {
+	Image string `json:"image"`
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// MarkdownBlock Markdown 文本区块，由前端渲染
+type MarkdownBlock struct {
+	Markdown string `json:"markdown"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// ContentReview 表示审核流程中的一次操作记录，包括提交、通过和驳回，用于审计谁审核了哪个版本
+type ContentReview struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	ContentID    uint           `json:"content_id" gorm:"index;not null"`
+	Version      int            `json:"version" gorm:"not null"` // 操作产生的修订版本号，对应被审核的内容快照
+	Action       RevisionAction `json:"action" gorm:"size:20;not null"`
+	FromStatus   ContentStatus  `json:"from_status" gorm:"size:20;not null"`
+	ToStatus     ContentStatus  `json:"to_status" gorm:"size:20;not null"`
+	ReviewerID   uint           `json:"reviewer_id" gorm:"index;not null"`
+	ReviewerName string         `json:"reviewer_name" gorm:"size:50"`
+	ReviewerRole string         `json:"reviewer_role" gorm:"size:20"`
+	Comment      string         `json:"comment" gorm:"type:text"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
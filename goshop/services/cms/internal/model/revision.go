@@ -0,0 +1,76 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// RevisionAction 表示产生修订版本的操作
+type RevisionAction string
+
+const (
+	// RevisionActionCreate 创建内容
+	RevisionActionCreate RevisionAction = "create"
+	// RevisionActionUpdate 编辑内容
+	RevisionActionUpdate RevisionAction = "update"
+	// RevisionActionPublish 发布内容
+	RevisionActionPublish RevisionAction = "publish"
+	// RevisionActionArchive 归档内容
+	RevisionActionArchive RevisionAction = "archive"
+	// RevisionActionRestore 从历史版本恢复
+	RevisionActionRestore RevisionAction = "restore"
+	// RevisionActionSubmit 提交审核
+	RevisionActionSubmit RevisionAction = "submit"
+	// RevisionActionApprove 审核通过
+	RevisionActionApprove RevisionAction = "approve"
+	// RevisionActionReject 审核驳回
+	RevisionActionReject RevisionAction = "reject"
+)
+
+// UintArray 是一个自定义类型，用于存储 ID 数组
+type UintArray []uint
+
+// Value 实现 driver.Valuer 接口
+func (a UintArray) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (a *UintArray) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("类型断言为 []byte 失败")
+	}
+	return json.Unmarshal(b, &a)
+}
+
+// ContentRevision 表示内容每次保存后的完整快照，用于查看历史版本和回滚
+type ContentRevision struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	ContentID       uint           `json:"content_id" gorm:"uniqueIndex:idx_content_revision;not null"`
+	Version         int            `json:"version" gorm:"uniqueIndex:idx_content_revision;not null"` // 从 1 开始递增
+	Action          RevisionAction `json:"action" gorm:"size:20;not null"`
+	EditorID        uint           `json:"editor_id" gorm:"index;not null"`
+	EditorName      string         `json:"editor_name" gorm:"size:50"`
+	ChangedFields   StringArray    `json:"changed_fields" gorm:"type:jsonb"`        // 与上一版本相比发生变化的字段
+	ContentDelta    int            `json:"content_delta" gorm:"not null;default:0"` // 正文字符数相对上一版本的变化
+	RestoredFrom    *int           `json:"restored_from"`                           // 恢复操作来源的版本号
+	Type            ContentType    `json:"type" gorm:"size:20;not null"`
+	Title           string         `json:"title" gorm:"size:255;not null"`
+	Slug            string         `json:"slug" gorm:"size:255;not null"`
+	Content         string         `json:"content,omitempty" gorm:"type:text"`
+	Layout          *Layout        `json:"layout,omitempty" gorm:"type:jsonb"`
+	Excerpt         string         `json:"excerpt" gorm:"size:500"`
+	CoverImage      *string        `json:"cover_image" gorm:"size:255"`
+	Status          ContentStatus  `json:"status" gorm:"size:20;not null"`
+	Tags            StringArray    `json:"tags" gorm:"type:jsonb"`
+	CategoryIDs     UintArray      `json:"category_ids" gorm:"type:jsonb"`
+	IsSticky        bool           `json:"is_sticky"`
+	SortOrder       int            `json:"sort_order"`
+	MetaTitle       string         `json:"meta_title" gorm:"size:255"`
+	MetaKeywords    string         `json:"meta_keywords" gorm:"size:255"`
+	MetaDescription string         `json:"meta_description" gorm:"size:500"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
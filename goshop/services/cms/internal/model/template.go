@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// TemplateChannel 表示消息模板的发送渠道
+type TemplateChannel string
+
+const (
+	// TemplateChannelEmail 邮件
+	TemplateChannelEmail TemplateChannel = "email"
+	// TemplateChannelSMS 短信
+	TemplateChannelSMS TemplateChannel = "sms"
+	// TemplateChannelPush App 推送，主题作为推送标题
+	TemplateChannelPush TemplateChannel = "push"
+)
+
+// Template 表示事务性邮件、短信或推送的消息模板，同一 Key 在每个渠道和语言下各有一份。
+// 主题和正文中使用 {{variable}} 引用变量，由通知服务渲染时传入
+type Template struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	Key         string          `json:"key" gorm:"uniqueIndex:idx_template_variant;size:100;not null"` // 业务标识，如 order.shipped
+	Channel     TemplateChannel `json:"channel" gorm:"uniqueIndex:idx_template_variant;size:20;not null"`
+	Locale      string          `json:"locale" gorm:"uniqueIndex:idx_template_variant;size:10;not null"`
+	Description string          `json:"description" gorm:"size:255"`
+	Subject     string          `json:"subject" gorm:"size:255"` // 邮件主题，短信模板为空
+	Body        string          `json:"body" gorm:"type:text;not null"`
+	Variables   StringArray     `json:"variables" gorm:"type:jsonb"` // 主题和正文中引用的变量，保存时自动提取
+	Version     int             `json:"version" gorm:"not null;default:1"`
+	EditorID    uint            `json:"editor_id" gorm:"index"`
+	EditorName  string          `json:"editor_name" gorm:"size:50"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// TemplateVersion 表示消息模板每次保存后的快照，用于查看历史版本和回滚
+type TemplateVersion struct {
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	TemplateID   uint        `json:"template_id" gorm:"uniqueIndex:idx_template_version;not null"`
+	Version      int         `json:"version" gorm:"uniqueIndex:idx_template_version;not null"`
+	Subject      string      `json:"subject" gorm:"size:255"`
+	Body         string      `json:"body,omitempty" gorm:"type:text;not null"`
+	Variables    StringArray `json:"variables" gorm:"type:jsonb"`
+	RestoredFrom *int        `json:"restored_from"` // 恢复操作来源的版本号
+	EditorID     uint        `json:"editor_id" gorm:"index;not null"`
+	EditorName   string      `json:"editor_name" gorm:"size:50"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// TranslationStatus 表示译文的状态
+type TranslationStatus string
+
+const (
+	// TranslationStatusMissing 尚未翻译，只用于翻译进度展示，不会保存到数据库
+	TranslationStatusMissing TranslationStatus = "missing"
+	// TranslationStatusDraft 译文草稿，前台不展示
+	TranslationStatusDraft TranslationStatus = "draft"
+	// TranslationStatusOutdated 译文已发布，但原文在翻译后又有修改，只用于翻译进度展示
+	TranslationStatusOutdated TranslationStatus = "outdated"
+	// TranslationStatusPublished 译文已发布
+	TranslationStatusPublished TranslationStatus = "published"
+)
+
+// ContentTranslation 表示内容在某个语言下的译文，原文保存在 Content 中，使用默认语言
+type ContentTranslation struct {
+	ID              uint              `json:"id" gorm:"primaryKey"`
+	ContentID       uint              `json:"content_id" gorm:"uniqueIndex:idx_content_translation;not null"`
+	Locale          string            `json:"locale" gorm:"uniqueIndex:idx_content_translation;size:10;not null"`
+	Title           string            `json:"title" gorm:"size:255;not null"`
+	Content         string            `json:"content" gorm:"type:text"`
+	Excerpt         string            `json:"excerpt" gorm:"size:500"`
+	Layout          *Layout           `json:"layout,omitempty" gorm:"type:jsonb"` // 为空时使用原文的区块布局
+	MetaTitle       string            `json:"meta_title" gorm:"size:255"`
+	MetaKeywords    string            `json:"meta_keywords" gorm:"size:255"`
+	MetaDescription string            `json:"meta_description" gorm:"size:500"`
+	Status          TranslationStatus `json:"status" gorm:"size:20;not null;default:'draft'"`
+	SourceHash      string            `json:"-" gorm:"size:64;not null"` // 翻译时原文的摘要，用于判断译文是否过期
+	TranslatorID    uint              `json:"translator_id" gorm:"index"`
+	TranslatorName  string            `json:"translator_name" gorm:"size:50"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BannerTotals 表示横幅在统计区间内的曝光和点击汇总
+type BannerTotals struct {
+	BannerID    uint
+	Impressions int
+	Clicks      int
+}
+
+// BannerRepository 定义横幅仓库接口
+type BannerRepository interface {
+	Create(ctx context.Context, banner *model.Banner) error
+	GetByID(ctx context.Context, id uint) (*model.Banner, error)
+	List(ctx context.Context, position string, offset, limit int) ([]*model.Banner, int64, error)
+	Update(ctx context.Context, banner *model.Banner) error
+	Delete(ctx context.Context, id uint) error
+	ListActive(ctx context.Context, position string, at time.Time) ([]*model.Banner, error)
+	FilterExisting(ctx context.Context, ids []uint) ([]uint, error)
+	RecordImpressions(ctx context.Context, ids []uint, date time.Time) error
+	RecordClick(ctx context.Context, id uint, date time.Time) error
+	SumStats(ctx context.Context, ids []uint, from, to time.Time) ([]*BannerTotals, error)
+	ListDailyStats(ctx context.Context, id uint, from, to time.Time) ([]*model.BannerDailyStat, error)
+}
+
+// GormBannerRepository 实现 BannerRepository 接口的 GORM 仓库
+type GormBannerRepository struct {
+	db *gorm.DB
+}
+
+// NewBannerRepository 创建横幅仓库
+func NewBannerRepository(db *gorm.DB) BannerRepository {
+	return &GormBannerRepository{
+		db: db,
+	}
+}
+
+// Create 创建横幅
+func (r *GormBannerRepository) Create(ctx context.Context, banner *model.Banner) error {
+	return r.db.WithContext(ctx).Create(banner).Error
+}
+
+// GetByID 根据 ID 获取横幅
+func (r *GormBannerRepository) GetByID(ctx context.Context, id uint) (*model.Banner, error) {
+	var banner model.Banner
+	if err := r.db.WithContext(ctx).First(&banner, id).Error; err != nil {
+		return nil, err
+	}
+	return &banner, nil
+}
+
+// List 分页获取横幅，position 为空时返回全部位置
+func (r *GormBannerRepository) List(ctx context.Context, position string, offset, limit int) ([]*model.Banner, int64, error) {
+	var banners []*model.Banner
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Banner{})
+	if position != "" {
+		query = query.Where("position = ?", position)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("position ASC, sort_order DESC, id ASC").Offset(offset).Limit(limit).Find(&banners).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return banners, total, nil
+}
+
+// Update 更新横幅
+func (r *GormBannerRepository) Update(ctx context.Context, banner *model.Banner) error {
+	return r.db.WithContext(ctx).Save(banner).Error
+}
+
+// Delete 软删除横幅
+func (r *GormBannerRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Banner{}, id).Error
+}
+
+// ListActive 获取在给定时间展示的横幅，position 为空时返回全部位置
+func (r *GormBannerRepository) ListActive(ctx context.Context, position string, at time.Time) ([]*model.Banner, error) {
+	var banners []*model.Banner
+	query := r.db.WithContext(ctx).Where("is_active = ? AND start_at <= ? AND end_at > ?", true, at, at)
+	if position != "" {
+		query = query.Where("position = ?", position)
+	}
+	err := query.Order("sort_order DESC, id ASC").Find(&banners).Error
+	return banners, err
+}
+
+// FilterExisting 返回 ids 中存在的横幅 ID
+func (r *GormBannerRepository) FilterExisting(ctx context.Context, ids []uint) ([]uint, error) {
+	var existing []uint
+	err := r.db.WithContext(ctx).Model(&model.Banner{}).Where("id IN ?", ids).Pluck("id", &existing).Error
+	return existing, err
+}
+
+// RecordImpressions 为每个横幅在 date 当天的曝光次数加一
+func (r *GormBannerRepository) RecordImpressions(ctx context.Context, ids []uint, date time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureDailyStats(tx, ids, date); err != nil {
+			return err
+		}
+		return tx.Model(&model.BannerDailyStat{}).
+			Where("banner_id IN ? AND date = ?", ids, date).
+			Update("impressions", gorm.Expr("impressions + 1")).Error
+	})
+}
+
+// RecordClick 为横幅在 date 当天的点击次数加一
+func (r *GormBannerRepository) RecordClick(ctx context.Context, id uint, date time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureDailyStats(tx, []uint{id}, date); err != nil {
+			return err
+		}
+		return tx.Model(&model.BannerDailyStat{}).
+			Where("banner_id = ? AND date = ?", id, date).
+			Update("clicks", gorm.Expr("clicks + 1")).Error
+	})
+}
+
+// ensureDailyStats 确保横幅在 date 当天的统计行存在
+func ensureDailyStats(tx *gorm.DB, ids []uint, date time.Time) error {
+	stats := make([]*model.BannerDailyStat, len(ids))
+	for i, id := range ids {
+		stats[i] = &model.BannerDailyStat{BannerID: id, Date: date}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&stats).Error
+}
+
+// SumStats 汇总横幅在 [from, to] 期间的曝光和点击次数，ids 为空时汇总所有横幅
+func (r *GormBannerRepository) SumStats(ctx context.Context, ids []uint, from, to time.Time) ([]*BannerTotals, error) {
+	var totals []*BannerTotals
+	query := r.db.WithContext(ctx).Model(&model.BannerDailyStat{}).
+		Select("banner_id, SUM(impressions) AS impressions, SUM(clicks) AS clicks").
+		Where("date >= ? AND date <= ?", from, to)
+	if len(ids) > 0 {
+		query = query.Where("banner_id IN ?", ids)
+	}
+	err := query.Group("banner_id").Scan(&totals).Error
+	return totals, err
+}
+
+// ListDailyStats 获取横幅在 [from, to] 期间的每日统计，按日期升序
+func (r *GormBannerRepository) ListDailyStats(ctx context.Context, id uint, from, to time.Time) ([]*model.BannerDailyStat, error) {
+	var stats []*model.BannerDailyStat
+	err := r.db.WithContext(ctx).
+		Where("banner_id = ? AND date >= ? AND date <= ?", id, from, to).
+		Order("date ASC").
+		Find(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"gorm.io/gorm"
+)
+
+// CommentFilter 表示后台评论列表的过滤条件，零值字段不参与过滤
+type CommentFilter struct {
+	ContentID uint
+	Status    model.CommentStatus
+}
+
+// CommentRepository 定义评论仓库接口
+type CommentRepository interface {
+	Create(ctx context.Context, comment *model.Comment) error
+	GetByID(ctx context.Context, id uint) (*model.Comment, error)
+	ListApproved(ctx context.Context, contentID uint, offset, limit int) ([]*model.Comment, int64, error)
+	ListApprovedReplies(ctx context.Context, rootIDs []uint) ([]*model.Comment, error)
+	List(ctx context.Context, filter CommentFilter, offset, limit int) ([]*model.Comment, int64, error)
+	UpdateStatus(ctx context.Context, comment *model.Comment) error
+	Delete(ctx context.Context, id uint) error
+	CountRecentByIP(ctx context.Context, ip string, since time.Time) (int64, error)
+	CountDuplicates(ctx context.Context, body string, since time.Time) (int64, error)
+}
+
+// GormCommentRepository 实现 CommentRepository 接口的 GORM 仓库
+type GormCommentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository 创建评论仓库
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &GormCommentRepository{
+		db: db,
+	}
+}
+
+// Create 创建评论
+func (r *GormCommentRepository) Create(ctx context.Context, comment *model.Comment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+// GetByID 根据 ID 获取评论
+func (r *GormCommentRepository) GetByID(ctx context.Context, id uint) (*model.Comment, error) {
+	var comment model.Comment
+	if err := r.db.WithContext(ctx).First(&comment, id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListApproved 分页获取内容下已通过的第一级评论，按发表时间倒序
+func (r *GormCommentRepository) ListApproved(ctx context.Context, contentID uint, offset, limit int) ([]*model.Comment, int64, error) {
+	var comments []*model.Comment
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Comment{}).
+		Where("content_id = ? AND root_id IS NULL AND status = ?", contentID, model.CommentStatusApproved)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&comments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// ListApprovedReplies 获取第一级评论下所有已通过的回复，按发表时间升序
+func (r *GormCommentRepository) ListApprovedReplies(ctx context.Context, rootIDs []uint) ([]*model.Comment, error) {
+	var replies []*model.Comment
+	if len(rootIDs) == 0 {
+		return replies, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("root_id IN ? AND status = ?", rootIDs, model.CommentStatusApproved).
+		Order("created_at ASC, id ASC").
+		Find(&replies).Error
+	return replies, err
+}
+
+// List 分页获取评论，供后台审核使用，按发表时间倒序
+func (r *GormCommentRepository) List(ctx context.Context, filter CommentFilter, offset, limit int) ([]*model.Comment, int64, error) {
+	var comments []*model.Comment
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Comment{})
+	if filter.ContentID != 0 {
+		query = query.Where("content_id = ?", filter.ContentID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&comments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// UpdateStatus 更新评论的审核状态和审核人
+func (r *GormCommentRepository) UpdateStatus(ctx context.Context, comment *model.Comment) error {
+	return r.db.WithContext(ctx).Model(comment).Updates(map[string]interface{}{
+		"status":       comment.Status,
+		"moderator_id": comment.ModeratorID,
+		"moderated_at": comment.ModeratedAt,
+	}).Error
+}
+
+// Delete 软删除评论
+func (r *GormCommentRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Comment{}, id).Error
+}
+
+// CountRecentByIP 统计 IP 自 since 以来发表的评论数
+func (r *GormCommentRepository) CountRecentByIP(ctx context.Context, ip string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Comment{}).
+		Where("ip = ? AND created_at >= ?", ip, since).
+		Count(&count).Error
+	return count, err
+}
+
+// CountDuplicates 统计自 since 以来内容完全相同的评论数
+func (r *GormCommentRepository) CountDuplicates(ctx context.Context, body string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Comment{}).
+		Where("body = ? AND created_at >= ?", body, since).
+		Count(&count).Error
+	return count, err
+}
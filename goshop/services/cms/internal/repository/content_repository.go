@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrContentStatusChanged 表示审核操作时内容状态已被其他操作修改
+var ErrContentStatusChanged = errors.New("content status changed")
+
+// ContentFilter 表示内容列表的过滤条件，零值字段不参与过滤
+type ContentFilter struct {
+	Type     model.ContentType
+	Status   model.ContentStatus
+	AuthorID uint
+	Keyword  string // 按标题模糊匹配
+}
+
+// ContentRepository 定义内容仓库接口
+type ContentRepository interface {
+	Create(ctx context.Context, content *model.Content, revision *model.ContentRevision) error
+	GetByID(ctx context.Context, id uint) (*model.Content, error)
+	GetPublishedBySlug(ctx context.Context, contentType model.ContentType, slug string) (*model.Content, error)
+	SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error)
+	List(ctx context.Context, filter ContentFilter, offset, limit int) ([]*model.Content, int64, error)
+	Update(ctx context.Context, content *model.Content, revision *model.ContentRevision) error
+	Delete(ctx context.Context, id uint) error
+	IncrementViewCount(ctx context.Context, id uint) error
+	GetCategories(ctx context.Context, ids []uint) ([]model.Category, error)
+	GetLinks(ctx context.Context, ids []uint) ([]*model.Content, error)
+	ListRevisions(ctx context.Context, contentID uint, offset, limit int) ([]*model.ContentRevision, int64, error)
+	GetRevision(ctx context.Context, contentID uint, version int) (*model.ContentRevision, error)
+	Review(ctx context.Context, content *model.Content, revision *model.ContentRevision, review *model.ContentReview) error
+	ListReviews(ctx context.Context, contentID uint) ([]*model.ContentReview, error)
+}
+
+// GormContentRepository 实现 ContentRepository 接口的 GORM 仓库
+type GormContentRepository struct {
+	db *gorm.DB
+}
+
+// NewContentRepository 创建内容仓库实例
+func NewContentRepository(db *gorm.DB) ContentRepository {
+	return &GormContentRepository{
+		db: db,
+	}
+}
+
+// Create 创建内容及其分类关联，并在同一事务中保存第一个修订版本
+func (r *GormContentRepository) Create(ctx context.Context, content *model.Content, revision *model.ContentRevision) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(content).Error; err != nil {
+			return err
+		}
+		return addRevision(tx, content.ID, revision)
+	})
+}
+
+// GetByID 根据 ID 获取内容及其分类
+func (r *GormContentRepository) GetByID(ctx context.Context, id uint) (*model.Content, error) {
+	var content model.Content
+	if err := r.db.WithContext(ctx).Preload("Categories").First(&content, id).Error; err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+// GetPublishedBySlug 根据 slug 获取已发布的内容
+func (r *GormContentRepository) GetPublishedBySlug(ctx context.Context, contentType model.ContentType, slug string) (*model.Content, error) {
+	var content model.Content
+	err := r.db.WithContext(ctx).
+		Preload("Categories").
+		Where("type = ? AND slug = ? AND status = ?", contentType, slug, model.ContentStatusPublished).
+		First(&content).Error
+	if err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+// SlugExists 检查 slug 是否已被其他内容占用，已删除的内容仍占用 slug
+func (r *GormContentRepository) SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().
+		Model(&model.Content{}).
+		Where("slug = ? AND id <> ?", slug, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List 分页获取内容，置顶内容优先，其余按排序值和创建时间倒序
+func (r *GormContentRepository) List(ctx context.Context, filter ContentFilter, offset, limit int) ([]*model.Content, int64, error) {
+	var contents []*model.Content
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Content{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.AuthorID != 0 {
+		query = query.Where("author_id = ?", filter.AuthorID)
+	}
+	if filter.Keyword != "" {
+		query = query.Where("title ILIKE ?", "%"+filter.Keyword+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Preload("Categories").
+		Order("is_sticky DESC, sort_order DESC, created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&contents).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return contents, total, nil
+}
+
+// Update 更新内容并替换其分类关联，同时保存新的修订版本。浏览次数只由 IncrementViewCount 维护，不会被覆盖
+func (r *GormContentRepository) Update(ctx context.Context, content *model.Content, revision *model.ContentRevision) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("view_count", "Categories").Save(content).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(content).Association("Categories").Replace(content.Categories); err != nil {
+			return err
+		}
+		return addRevision(tx, content.ID, revision)
+	})
+}
+
+// addRevision 为内容分配下一个版本号并保存修订版本。更新内容时已锁定内容行，同一内容的版本号不会冲突
+func addRevision(tx *gorm.DB, contentID uint, revision *model.ContentRevision) error {
+	var latest int
+	err := tx.Model(&model.ContentRevision{}).
+		Where("content_id = ?", contentID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+	revision.ContentID = contentID
+	revision.Version = latest + 1
+	return tx.Create(revision).Error
+}
+
+// Delete 软删除内容
+func (r *GormContentRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Content{}, id).Error
+}
+
+// IncrementViewCount 增加内容的浏览次数
+func (r *GormContentRepository) IncrementViewCount(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Content{}).
+		Where("id = ?", id).
+		UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
+}
+
+// GetLinks 根据 ID 批量获取内容的类型、slug 和状态，用于生成链接，不包含正文
+func (r *GormContentRepository) GetLinks(ctx context.Context, ids []uint) ([]*model.Content, error) {
+	var contents []*model.Content
+	err := r.db.WithContext(ctx).
+		Select("id", "type", "title", "slug", "status").
+		Where("id IN ?", ids).
+		Find(&contents).Error
+	return contents, err
+}
+
+// GetCategories 根据 ID 获取内容分类
+func (r *GormContentRepository) GetCategories(ctx context.Context, ids []uint) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+// ListRevisions 分页获取内容的修订版本，按版本号倒序，不包含正文和区块布局
+func (r *GormContentRepository) ListRevisions(ctx context.Context, contentID uint, offset, limit int) ([]*model.ContentRevision, int64, error) {
+	var revisions []*model.ContentRevision
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.ContentRevision{}).Where("content_id = ?", contentID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Omit("content", "layout").Order("version DESC").Offset(offset).Limit(limit).Find(&revisions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return revisions, total, nil
+}
+
+// GetRevision 获取内容的指定版本
+func (r *GormContentRepository) GetRevision(ctx context.Context, contentID uint, version int) (*model.ContentRevision, error) {
+	var revision model.ContentRevision
+	err := r.db.WithContext(ctx).
+		Where("content_id = ? AND version = ?", contentID, version).
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Review 在同一事务中更新内容的审核状态、保存修订版本和审核记录。
+// 只有内容仍处于 review.FromStatus 时才会更新，避免重复审核
+func (r *GormContentRepository) Review(ctx context.Context, content *model.Content, revision *model.ContentRevision, review *model.ContentReview) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Content{}).
+			Where("id = ? AND status = ?", content.ID, review.FromStatus).
+			Updates(map[string]interface{}{"status": content.Status, "updated_at": content.UpdatedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrContentStatusChanged
+		}
+		if err := addRevision(tx, content.ID, revision); err != nil {
+			return err
+		}
+		review.ContentID = content.ID
+		review.Version = revision.Version
+		return tx.Create(review).Error
+	})
+}
+
+// ListReviews 获取内容的审核记录，按时间倒序
+func (r *GormContentRepository) ListReviews(ctx context.Context, contentID uint) ([]*model.ContentReview, error) {
+	var reviews []*model.ContentReview
+	err := r.db.WithContext(ctx).
+		Where("content_id = ?", contentID).
+		Order("id DESC").
+		Find(&reviews).Error
+	return reviews, err
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"gorm.io/gorm"
+)
+
+// FAQFilter 表示常见问题列表的过滤条件，零值字段不参与过滤
+type FAQFilter struct {
+	CategoryID uint
+	Published  *bool
+	Keyword    string // 按问题、答案和关键词模糊匹配
+}
+
+// FAQScore 表示常见问题的反馈统计
+type FAQScore struct {
+	model.FAQ
+	HelpfulRate float64 `json:"helpful_rate"`
+}
+
+// FAQRepository 定义常见问题仓库接口
+type FAQRepository interface {
+	CreateCategory(ctx context.Context, category *model.FAQCategory) error
+	GetCategory(ctx context.Context, id uint) (*model.FAQCategory, error)
+	ListCategories(ctx context.Context) ([]*model.FAQCategory, error)
+	CategorySlugExists(ctx context.Context, slug string, excludeID uint) (bool, error)
+	UpdateCategory(ctx context.Context, category *model.FAQCategory) error
+	DeleteCategory(ctx context.Context, id uint) error
+	CountByCategory(ctx context.Context, categoryID uint) (int64, error)
+	Create(ctx context.Context, faq *model.FAQ) error
+	GetByID(ctx context.Context, id uint) (*model.FAQ, error)
+	List(ctx context.Context, filter FAQFilter, offset, limit int) ([]*model.FAQ, int64, error)
+	Update(ctx context.Context, faq *model.FAQ) error
+	Delete(ctx context.Context, id uint) error
+	AddFeedback(ctx context.Context, id uint, helpful bool) error
+	ListLowScoring(ctx context.Context, minVotes int, maxRate float64, offset, limit int) ([]*FAQScore, int64, error)
+}
+
+// GormFAQRepository 实现 FAQRepository 接口的 GORM 仓库
+type GormFAQRepository struct {
+	db *gorm.DB
+}
+
+// NewFAQRepository 创建常见问题仓库
+func NewFAQRepository(db *gorm.DB) FAQRepository {
+	return &GormFAQRepository{
+		db: db,
+	}
+}
+
+// CreateCategory 创建常见问题分类
+func (r *GormFAQRepository) CreateCategory(ctx context.Context, category *model.FAQCategory) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+// GetCategory 根据 ID 获取常见问题分类
+func (r *GormFAQRepository) GetCategory(ctx context.Context, id uint) (*model.FAQCategory, error) {
+	var category model.FAQCategory
+	if err := r.db.WithContext(ctx).First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// ListCategories 获取所有常见问题分类，按排序顺序排列
+func (r *GormFAQRepository) ListCategories(ctx context.Context) ([]*model.FAQCategory, error) {
+	var categories []*model.FAQCategory
+	err := r.db.WithContext(ctx).Order("sort_order ASC, id ASC").Find(&categories).Error
+	return categories, err
+}
+
+// CategorySlugExists 判断 slug 是否已被 excludeID 以外的分类使用
+func (r *GormFAQRepository) CategorySlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.FAQCategory{}).
+		Where("slug = ? AND id <> ?", slug, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateCategory 更新常见问题分类
+func (r *GormFAQRepository) UpdateCategory(ctx context.Context, category *model.FAQCategory) error {
+	return r.db.WithContext(ctx).Save(category).Error
+}
+
+// DeleteCategory 删除常见问题分类
+func (r *GormFAQRepository) DeleteCategory(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.FAQCategory{}, id).Error
+}
+
+// CountByCategory 统计分类下的常见问题数量，包括未发布的问题
+func (r *GormFAQRepository) CountByCategory(ctx context.Context, categoryID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.FAQ{}).
+		Where("category_id = ?", categoryID).
+		Count(&count).Error
+	return count, err
+}
+
+// Create 创建常见问题
+func (r *GormFAQRepository) Create(ctx context.Context, faq *model.FAQ) error {
+	return r.db.WithContext(ctx).Create(faq).Error
+}
+
+// GetByID 根据 ID 获取常见问题
+func (r *GormFAQRepository) GetByID(ctx context.Context, id uint) (*model.FAQ, error) {
+	var faq model.FAQ
+	if err := r.db.WithContext(ctx).First(&faq, id).Error; err != nil {
+		return nil, err
+	}
+	return &faq, nil
+}
+
+// List 分页获取常见问题，按分类和排序顺序排列
+func (r *GormFAQRepository) List(ctx context.Context, filter FAQFilter, offset, limit int) ([]*model.FAQ, int64, error) {
+	var faqs []*model.FAQ
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.FAQ{})
+	if filter.CategoryID != 0 {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.Published != nil {
+		query = query.Where("is_published = ?", *filter.Published)
+	}
+	if filter.Keyword != "" {
+		pattern := "%" + filter.Keyword + "%"
+		query = query.Where("question ILIKE ? OR answer ILIKE ? OR keywords::text ILIKE ?", pattern, pattern, pattern)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("category_id ASC, sort_order ASC, id ASC").
+		Offset(offset).Limit(limit).
+		Find(&faqs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return faqs, total, nil
+}
+
+// Update 更新常见问题。反馈次数只由 AddFeedback 维护，不会被覆盖
+func (r *GormFAQRepository) Update(ctx context.Context, faq *model.FAQ) error {
+	return r.db.WithContext(ctx).Omit("helpful_count", "not_helpful_count").Save(faq).Error
+}
+
+// Delete 软删除常见问题
+func (r *GormFAQRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.FAQ{}, id).Error
+}
+
+// AddFeedback 记录一次答案是否有帮助的反馈
+func (r *GormFAQRepository) AddFeedback(ctx context.Context, id uint, helpful bool) error {
+	column := "not_helpful_count"
+	if helpful {
+		column = "helpful_count"
+	}
+	return r.db.WithContext(ctx).
+		Model(&model.FAQ{}).
+		Where("id = ?", id).
+		UpdateColumn(column, gorm.Expr(column+" + 1")).Error
+}
+
+// ListLowScoring 分页获取反馈次数不少于 minVotes 且有帮助比例不高于 maxRate 的已发布问题，按有帮助比例升序排列
+func (r *GormFAQRepository) ListLowScoring(ctx context.Context, minVotes int, maxRate float64, offset, limit int) ([]*FAQScore, int64, error) {
+	var scores []*FAQScore
+	var total int64
+	const rate = "helpful_count::float / (helpful_count + not_helpful_count)"
+	query := r.db.WithContext(ctx).Model(&model.FAQ{}).
+		Where("is_published = ?", true).
+		Where("helpful_count + not_helpful_count >= ?", minVotes).
+		Where("helpful_count + not_helpful_count > 0").
+		Where(rate+" <= ?", maxRate)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Select("*, " + rate + " AS helpful_rate").
+		Order("helpful_rate ASC, not_helpful_count DESC").
+		Offset(offset).Limit(limit).
+		Scan(&scores).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return scores, total, nil
+}
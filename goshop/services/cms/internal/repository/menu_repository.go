@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"gorm.io/gorm"
+)
+
+// MenuItemPosition 表示菜单项在菜单树中的位置
+type MenuItemPosition struct {
+	ID        uint
+	ParentID  *uint
+	SortOrder int
+}
+
+// MenuRepository 定义导航菜单仓库接口
+type MenuRepository interface {
+	CreateMenu(ctx context.Context, menu *model.Menu) error
+	GetMenu(ctx context.Context, id uint) (*model.Menu, error)
+	GetMenuByLocation(ctx context.Context, location string) (*model.Menu, error)
+	LocationExists(ctx context.Context, location string, excludeID uint) (bool, error)
+	ListMenus(ctx context.Context) ([]*model.Menu, error)
+	UpdateMenu(ctx context.Context, menu *model.Menu) error
+	DeleteMenu(ctx context.Context, id uint) error
+	ListItems(ctx context.Context, menuID uint) ([]*model.MenuItem, error)
+	CreateItem(ctx context.Context, item *model.MenuItem) error
+	GetItem(ctx context.Context, menuID, itemID uint) (*model.MenuItem, error)
+	UpdateItem(ctx context.Context, item *model.MenuItem) error
+	DeleteItems(ctx context.Context, menuID uint, ids []uint) error
+	UpdatePositions(ctx context.Context, menuID uint, positions []MenuItemPosition) error
+}
+
+// GormMenuRepository 实现 MenuRepository 接口的 GORM 仓库
+type GormMenuRepository struct {
+	db *gorm.DB
+}
+
+// NewMenuRepository 创建导航菜单仓库
+func NewMenuRepository(db *gorm.DB) MenuRepository {
+	return &GormMenuRepository{
+		db: db,
+	}
+}
+
+// CreateMenu 创建菜单
+func (r *GormMenuRepository) CreateMenu(ctx context.Context, menu *model.Menu) error {
+	return r.db.WithContext(ctx).Omit("Items").Create(menu).Error
+}
+
+// GetMenu 根据 ID 获取菜单，不包含菜单项
+func (r *GormMenuRepository) GetMenu(ctx context.Context, id uint) (*model.Menu, error) {
+	var menu model.Menu
+	if err := r.db.WithContext(ctx).First(&menu, id).Error; err != nil {
+		return nil, err
+	}
+	return &menu, nil
+}
+
+// GetMenuByLocation 根据位置获取菜单，不包含菜单项
+func (r *GormMenuRepository) GetMenuByLocation(ctx context.Context, location string) (*model.Menu, error) {
+	var menu model.Menu
+	if err := r.db.WithContext(ctx).Where("location = ?", location).First(&menu).Error; err != nil {
+		return nil, err
+	}
+	return &menu, nil
+}
+
+// LocationExists 判断位置是否已被 excludeID 以外的菜单使用
+func (r *GormMenuRepository) LocationExists(ctx context.Context, location string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Menu{}).
+		Where("location = ? AND id <> ?", location, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListMenus 获取所有菜单，不包含菜单项
+func (r *GormMenuRepository) ListMenus(ctx context.Context) ([]*model.Menu, error) {
+	var menus []*model.Menu
+	err := r.db.WithContext(ctx).Order("location ASC, id ASC").Find(&menus).Error
+	return menus, err
+}
+
+// UpdateMenu 更新菜单名称和位置
+func (r *GormMenuRepository) UpdateMenu(ctx context.Context, menu *model.Menu) error {
+	return r.db.WithContext(ctx).Omit("Items").Save(menu).Error
+}
+
+// DeleteMenu 软删除菜单及其所有菜单项
+func (r *GormMenuRepository) DeleteMenu(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("menu_id = ?", id).Delete(&model.MenuItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Menu{}, id).Error
+	})
+}
+
+// ListItems 获取菜单的所有菜单项，按排序顺序升序
+func (r *GormMenuRepository) ListItems(ctx context.Context, menuID uint) ([]*model.MenuItem, error) {
+	var items []*model.MenuItem
+	err := r.db.WithContext(ctx).
+		Where("menu_id = ?", menuID).
+		Order("sort_order ASC, id ASC").
+		Find(&items).Error
+	return items, err
+}
+
+// CreateItem 创建菜单项
+func (r *GormMenuRepository) CreateItem(ctx context.Context, item *model.MenuItem) error {
+	return r.db.WithContext(ctx).Omit("Parent", "Children").Create(item).Error
+}
+
+// GetItem 获取菜单中的菜单项
+func (r *GormMenuRepository) GetItem(ctx context.Context, menuID, itemID uint) (*model.MenuItem, error) {
+	var item model.MenuItem
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND menu_id = ?", itemID, menuID).
+		First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateItem 更新菜单项
+func (r *GormMenuRepository) UpdateItem(ctx context.Context, item *model.MenuItem) error {
+	return r.db.WithContext(ctx).Omit("Parent", "Children").Save(item).Error
+}
+
+// DeleteItems 软删除菜单中的多个菜单项
+func (r *GormMenuRepository) DeleteItems(ctx context.Context, menuID uint, ids []uint) error {
+	return r.db.WithContext(ctx).
+		Where("menu_id = ? AND id IN ?", menuID, ids).
+		Delete(&model.MenuItem{}).Error
+}
+
+// UpdatePositions 在同一事务中批量更新菜单项的父级和排序
+func (r *GormMenuRepository) UpdatePositions(ctx context.Context, menuID uint, positions []MenuItemPosition) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, p := range positions {
+			err := tx.Model(&model.MenuItem{}).
+				Where("id = ? AND menu_id = ?", p.ID, menuID).
+				Updates(map[string]interface{}{"parent_id": p.ParentID, "sort_order": p.SortOrder}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrTemplateVersionConflict 表示保存时消息模板已被其他人修改
+var ErrTemplateVersionConflict = errors.New("template version conflict")
+
+// TemplateFilter 表示消息模板列表的过滤条件
+type TemplateFilter struct {
+	Key     string
+	Channel model.TemplateChannel
+	Locale  string
+}
+
+// TemplateRepository 定义消息模板仓库接口
+type TemplateRepository interface {
+	Create(ctx context.Context, template *model.Template, version *model.TemplateVersion) error
+	GetByID(ctx context.Context, id uint) (*model.Template, error)
+	Find(ctx context.Context, key string, channel model.TemplateChannel, locale string) (*model.Template, error)
+	Exists(ctx context.Context, key string, channel model.TemplateChannel, locale string, excludeID uint) (bool, error)
+	List(ctx context.Context, filter TemplateFilter, offset, limit int) ([]*model.Template, int64, error)
+	Update(ctx context.Context, template *model.Template, version *model.TemplateVersion) error
+	Delete(ctx context.Context, id uint) error
+	ListVersions(ctx context.Context, templateID uint, offset, limit int) ([]*model.TemplateVersion, int64, error)
+	GetVersion(ctx context.Context, templateID uint, version int) (*model.TemplateVersion, error)
+}
+
+// GormTemplateRepository 实现 TemplateRepository 接口的 GORM 仓库
+type GormTemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewTemplateRepository 创建消息模板仓库
+func NewTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &GormTemplateRepository{
+		db: db,
+	}
+}
+
+// Create 创建消息模板，并在同一事务中保存第一个版本
+func (r *GormTemplateRepository) Create(ctx context.Context, template *model.Template, version *model.TemplateVersion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(template).Error; err != nil {
+			return err
+		}
+		version.TemplateID = template.ID
+		return tx.Create(version).Error
+	})
+}
+
+// GetByID 根据 ID 获取消息模板
+func (r *GormTemplateRepository) GetByID(ctx context.Context, id uint) (*model.Template, error) {
+	var template model.Template
+	if err := r.db.WithContext(ctx).First(&template, id).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// Find 根据业务标识、渠道和语言获取消息模板
+func (r *GormTemplateRepository) Find(ctx context.Context, key string, channel model.TemplateChannel, locale string) (*model.Template, error) {
+	var template model.Template
+	err := r.db.WithContext(ctx).
+		Where("key = ? AND channel = ? AND locale = ?", key, channel, locale).
+		First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// Exists 检查业务标识、渠道和语言相同的模板是否已存在，excludeID 用于排除自身
+func (r *GormTemplateRepository) Exists(ctx context.Context, key string, channel model.TemplateChannel, locale string, excludeID uint) (bool, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&model.Template{}).
+		Where("key = ? AND channel = ? AND locale = ?", key, channel, locale)
+	if excludeID > 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// List 分页获取消息模板，按业务标识、渠道和语言排序，不包含正文
+func (r *GormTemplateRepository) List(ctx context.Context, filter TemplateFilter, offset, limit int) ([]*model.Template, int64, error) {
+	var templates []*model.Template
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Template{})
+	if filter.Key != "" {
+		query = query.Where("key LIKE ?", filter.Key+"%")
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	if filter.Locale != "" {
+		query = query.Where("locale = ?", filter.Locale)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Omit("body").
+		Order("key ASC, channel ASC, locale ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&templates).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return templates, total, nil
+}
+
+// Update 更新消息模板并保存新的版本。只有模板仍为上一版本时才会更新，避免并发编辑互相覆盖
+func (r *GormTemplateRepository) Update(ctx context.Context, template *model.Template, version *model.TemplateVersion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(template).
+			Where("version = ?", template.Version-1).
+			Select("description", "subject", "body", "variables", "version", "editor_id", "editor_name", "updated_at").
+			Updates(template)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTemplateVersionConflict
+		}
+		version.TemplateID = template.ID
+		return tx.Create(version).Error
+	})
+}
+
+// Delete 删除消息模板及其所有版本
+func (r *GormTemplateRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&model.TemplateVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Template{}, id).Error
+	})
+}
+
+// ListVersions 分页获取消息模板的历史版本，按版本号倒序，不包含正文
+func (r *GormTemplateRepository) ListVersions(ctx context.Context, templateID uint, offset, limit int) ([]*model.TemplateVersion, int64, error) {
+	var versions []*model.TemplateVersion
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.TemplateVersion{}).Where("template_id = ?", templateID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Omit("body").Order("version DESC").Offset(offset).Limit(limit).Find(&versions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return versions, total, nil
+}
+
+// GetVersion 获取消息模板的指定版本
+func (r *GormTemplateRepository) GetVersion(ctx context.Context, templateID uint, version int) (*model.TemplateVersion, error) {
+	var v model.TemplateVersion
+	err := r.db.WithContext(ctx).
+		Where("template_id = ? AND version = ?", templateID, version).
+		First(&v).Error
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TranslationRepository 定义内容译文仓库接口
+type TranslationRepository interface {
+	Save(ctx context.Context, translation *model.ContentTranslation) error
+	Get(ctx context.Context, contentID uint, locale string) (*model.ContentTranslation, error)
+	ListByContent(ctx context.Context, contentID uint) ([]*model.ContentTranslation, error)
+	ListByLocale(ctx context.Context, contentIDs []uint, locale string) ([]*model.ContentTranslation, error)
+	Delete(ctx context.Context, contentID uint, locale string) (bool, error)
+}
+
+// GormTranslationRepository 实现 TranslationRepository 接口的 GORM 仓库
+type GormTranslationRepository struct {
+	db *gorm.DB
+}
+
+// NewTranslationRepository 创建内容译文仓库
+func NewTranslationRepository(db *gorm.DB) TranslationRepository {
+	return &GormTranslationRepository{
+		db: db,
+	}
+}
+
+// Save 保存内容在某个语言下的译文，已存在时覆盖
+func (r *GormTranslationRepository) Save(ctx context.Context, translation *model.ContentTranslation) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "content_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"title", "content", "excerpt", "layout", "meta_title", "meta_keywords", "meta_description",
+			"status", "source_hash", "translator_id", "translator_name", "updated_at",
+		}),
+	}).Create(translation).Error
+}
+
+// Get 获取内容在某个语言下的译文
+func (r *GormTranslationRepository) Get(ctx context.Context, contentID uint, locale string) (*model.ContentTranslation, error) {
+	var translation model.ContentTranslation
+	err := r.db.WithContext(ctx).
+		Where("content_id = ? AND locale = ?", contentID, locale).
+		First(&translation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &translation, nil
+}
+
+// ListByContent 获取内容的所有译文
+func (r *GormTranslationRepository) ListByContent(ctx context.Context, contentID uint) ([]*model.ContentTranslation, error) {
+	var translations []*model.ContentTranslation
+	err := r.db.WithContext(ctx).
+		Where("content_id = ?", contentID).
+		Order("locale ASC").
+		Find(&translations).Error
+	return translations, err
+}
+
+// ListByLocale 批量获取多个内容在某个语言下的译文
+func (r *GormTranslationRepository) ListByLocale(ctx context.Context, contentIDs []uint, locale string) ([]*model.ContentTranslation, error) {
+	var translations []*model.ContentTranslation
+	if len(contentIDs) == 0 {
+		return translations, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("content_id IN ? AND locale = ?", contentIDs, locale).
+		Find(&translations).Error
+	return translations, err
+}
+
+// Delete 删除内容在某个语言下的译文，返回译文是否存在
+func (r *GormTranslationRepository) Delete(ctx context.Context, contentID uint, locale string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("content_id = ? AND locale = ?", contentID, locale).
+		Delete(&model.ContentTranslation{})
+	return result.RowsAffected > 0, result.Error
+}
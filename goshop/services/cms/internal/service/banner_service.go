@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/cms/internal/event"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 访客的设备类型
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+)
+
+const (
+	// 单次最多上报的横幅曝光数
+	maxImpressionBatch = 50
+	// 报表默认统计最近 30 天，最长一年
+	defaultReportDays = 30
+	maxReportDays     = 366
+)
+
+// BannerAudience 表示请求横幅的访客，用于匹配横幅的定向规则
+type BannerAudience struct {
+	MemberLevel int
+	Segments    []string
+	Device      string
+	Locale      string
+}
+
+// BannerRequest 表示创建或更新横幅的请求
+type BannerRequest struct {
+	Title          string    `json:"title" binding:"required,max=100"`
+	Image          string    `json:"image" binding:"required,max=255"`
+	URL            string    `json:"url" binding:"max=255"`
+	Position       string    `json:"position" binding:"required,max=50"`
+	Description    string    `json:"description" binding:"max=255"`
+	StartAt        time.Time `json:"start_at" binding:"required"`
+	EndAt          time.Time `json:"end_at" binding:"required"`
+	IsActive       *bool     `json:"is_active"`
+	SortOrder      int       `json:"sort_order"`
+	MinMemberLevel *int      `json:"min_member_level" binding:"omitempty,min=0"`
+	Segments       []string  `json:"segments"`
+	Devices        []string  `json:"devices" binding:"dive,oneof=mobile tablet desktop"`
+	Locales        []string  `json:"locales"`
+}
+
+// BannerList 表示分页的横幅列表
+type BannerList struct {
+	Items    []*model.Banner `json:"items"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// BannerStats 表示横幅在统计区间内的曝光、点击和点击率
+type BannerStats struct {
+	BannerID    uint    `json:"banner_id"`
+	Title       string  `json:"title"`
+	Position    string  `json:"position"`
+	Impressions int     `json:"impressions"`
+	Clicks      int     `json:"clicks"`
+	CTR         float64 `json:"ctr"` // 点击次数 / 曝光次数
+}
+
+// BannerReport 表示分页的横幅效果报表
+type BannerReport struct {
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Items    []*BannerStats `json:"items"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
+
+// BannerDetailReport 表示单个横幅的效果报表，包含每日数据
+type BannerDetailReport struct {
+	BannerStats
+	From  time.Time                `json:"from"`
+	To    time.Time                `json:"to"`
+	Daily []*model.BannerDailyStat `json:"daily"`
+}
+
+// BannerService 负责横幅的管理、按访客定向投放以及曝光点击统计，横幅变更后发布事件
+type BannerService struct {
+	bannerRepo repository.BannerRepository
+	publisher  event.Publisher
+	log        *logger.Logger
+}
+
+// NewBannerService 创建横幅服务
+func NewBannerService(bannerRepo repository.BannerRepository, publisher event.Publisher, log *logger.Logger) *BannerService {
+	return &BannerService{
+		bannerRepo: bannerRepo,
+		publisher:  publisher,
+		log:        log,
+	}
+}
+
+// ListActive 获取当前展示给访客的横幅，position 为空时返回全部位置
+func (s *BannerService) ListActive(ctx context.Context, position string, audience *BannerAudience) ([]*model.Banner, error) {
+	banners, err := s.bannerRepo.ListActive(ctx, position, time.Now())
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取横幅失败", err)
+	}
+	targeted := make([]*model.Banner, 0, len(banners))
+	for _, banner := range banners {
+		if matchesAudience(banner, audience) {
+			targeted = append(targeted, banner)
+		}
+	}
+	return targeted, nil
+}
+
+// RecordImpressions 记录横幅曝光，重复和不存在的横幅 ID 会被忽略
+func (s *BannerService) RecordImpressions(ctx context.Context, ids []uint) error {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return apperrors.NewBadRequest("缺少 banner_ids", nil)
+	}
+	if len(ids) > maxImpressionBatch {
+		return apperrors.NewBadRequest(fmt.Sprintf("一次最多上报 %d 个横幅", maxImpressionBatch), nil)
+	}
+	existing, err := s.bannerRepo.FilterExisting(ctx, ids)
+	if err != nil {
+		return apperrors.NewInternalServerError("获取横幅失败", err)
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+	if err := s.bannerRepo.RecordImpressions(ctx, existing, today()); err != nil {
+		return apperrors.NewInternalServerError("记录横幅曝光失败", err)
+	}
+	return nil
+}
+
+// RecordClick 记录横幅点击
+func (s *BannerService) RecordClick(ctx context.Context, id uint) error {
+	if _, err := s.getBanner(ctx, id); err != nil {
+		return err
+	}
+	if err := s.bannerRepo.RecordClick(ctx, id, today()); err != nil {
+		return apperrors.NewInternalServerError("记录横幅点击失败", err)
+	}
+	return nil
+}
+
+// List 分页获取横幅，供后台管理使用
+func (s *BannerService) List(ctx context.Context, position string, page, pageSize int) (*BannerList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	banners, total, err := s.bannerRepo.List(ctx, position, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取横幅失败", err)
+	}
+	return &BannerList{Items: banners, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Get 获取横幅
+func (s *BannerService) Get(ctx context.Context, id uint) (*model.Banner, error) {
+	return s.getBanner(ctx, id)
+}
+
+// Create 创建横幅
+func (s *BannerService) Create(ctx context.Context, req *BannerRequest) (*model.Banner, error) {
+	banner := &model.Banner{IsActive: true}
+	if err := fillBanner(banner, req); err != nil {
+		return nil, err
+	}
+	if err := s.bannerRepo.Create(ctx, banner); err != nil {
+		return nil, apperrors.NewInternalServerError("创建横幅失败", err)
+	}
+	s.publishChanged(ctx, banner, "created")
+	return banner, nil
+}
+
+// Update 更新横幅
+func (s *BannerService) Update(ctx context.Context, id uint, req *BannerRequest) (*model.Banner, error) {
+	banner, err := s.getBanner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := fillBanner(banner, req); err != nil {
+		return nil, err
+	}
+	if err := s.bannerRepo.Update(ctx, banner); err != nil {
+		return nil, apperrors.NewInternalServerError("更新横幅失败", err)
+	}
+	s.publishChanged(ctx, banner, "updated")
+	return banner, nil
+}
+
+// Delete 删除横幅，已有的统计数据保留
+func (s *BannerService) Delete(ctx context.Context, editor *Editor, id uint) error {
+	banner, err := s.getBanner(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.bannerRepo.Delete(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除横幅失败", err)
+	}
+	s.log.Info(ctx, "Banner deleted", zap.Uint("banner_id", id), zap.Uint("editor_id", editor.ID))
+	s.publishChanged(ctx, banner, "deleted")
+	return nil
+}
+
+// publishChanged 发布横幅变更事件，发布失败不影响变更结果，订阅方的缓存在过期后更新
+func (s *BannerService) publishChanged(ctx context.Context, banner *model.Banner, action string) {
+	err := s.publisher.Publish(ctx, event.BannersChanged, &event.BannersChangedEvent{
+		BannerID: banner.ID,
+		Position: banner.Position,
+		Action:   action,
+	})
+	if err != nil {
+		s.log.Warn(ctx, "Failed to publish banners changed event", zap.Uint("banner_id", banner.ID), zap.Error(err))
+	}
+}
+
+// Report 分页获取横幅在统计区间内的曝光、点击和点击率，from 和 to 为日期，默认最近 30 天
+func (s *BannerService) Report(ctx context.Context, position string, from, to time.Time, page, pageSize int) (*BannerReport, error) {
+	from, to, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.List(ctx, position, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(list.Items))
+	for i, banner := range list.Items {
+		ids[i] = banner.ID
+	}
+	totals := make(map[uint]*repository.BannerTotals, len(ids))
+	if len(ids) > 0 {
+		sums, err := s.bannerRepo.SumStats(ctx, ids, from, to)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取横幅统计失败", err)
+		}
+		for _, sum := range sums {
+			totals[sum.BannerID] = sum
+		}
+	}
+
+	report := &BannerReport{
+		From:     from,
+		To:       to,
+		Items:    make([]*BannerStats, 0, len(list.Items)),
+		Total:    list.Total,
+		Page:     list.Page,
+		PageSize: list.PageSize,
+	}
+	for _, banner := range list.Items {
+		stats := &BannerStats{BannerID: banner.ID, Title: banner.Title, Position: banner.Position}
+		if sum, ok := totals[banner.ID]; ok {
+			stats.Impressions, stats.Clicks = sum.Impressions, sum.Clicks
+			stats.CTR = clickThroughRate(sum.Impressions, sum.Clicks)
+		}
+		report.Items = append(report.Items, stats)
+	}
+	return report, nil
+}
+
+// BannerReport 获取单个横幅的效果报表，包含每日曝光和点击
+func (s *BannerService) BannerReport(ctx context.Context, id uint, from, to time.Time) (*BannerDetailReport, error) {
+	from, to, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	banner, err := s.getBanner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := s.bannerRepo.ListDailyStats(ctx, id, from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取横幅统计失败", err)
+	}
+
+	report := &BannerDetailReport{
+		BannerStats: BannerStats{BannerID: banner.ID, Title: banner.Title, Position: banner.Position},
+		From:        from,
+		To:          to,
+		Daily:       daily,
+	}
+	for _, stat := range daily {
+		report.Impressions += stat.Impressions
+		report.Clicks += stat.Clicks
+	}
+	report.CTR = clickThroughRate(report.Impressions, report.Clicks)
+	return report, nil
+}
+
+func (s *BannerService) getBanner(ctx context.Context, id uint) (*model.Banner, error) {
+	banner, err := s.bannerRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("横幅 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取横幅失败", err)
+	}
+	return banner, nil
+}
+
+// fillBanner 校验请求并写入横幅
+func fillBanner(banner *model.Banner, req *BannerRequest) error {
+	if !req.EndAt.After(req.StartAt) {
+		return apperrors.NewBadRequest("结束时间必须晚于开始时间", nil)
+	}
+	if !safeURL(req.Image) || !safeURL(req.URL) {
+		return apperrors.NewBadRequest("横幅包含无效的链接", nil)
+	}
+
+	banner.Title = req.Title
+	banner.Image = req.Image
+	banner.URL = req.URL
+	banner.Position = req.Position
+	banner.Description = req.Description
+	banner.StartAt = req.StartAt
+	banner.EndAt = req.EndAt
+	if req.IsActive != nil {
+		banner.IsActive = *req.IsActive
+	}
+	banner.SortOrder = req.SortOrder
+	banner.MinMemberLevel = req.MinMemberLevel
+	banner.Segments = req.Segments
+	banner.Devices = req.Devices
+	banner.Locales = req.Locales
+	return nil
+}
+
+// matchesAudience 判断访客是否满足横幅的全部定向规则
+func matchesAudience(banner *model.Banner, audience *BannerAudience) bool {
+	if banner.MinMemberLevel != nil && audience.MemberLevel < *banner.MinMemberLevel {
+		return false
+	}
+	if len(banner.Segments) > 0 && !containsAny(banner.Segments, audience.Segments) {
+		return false
+	}
+	if len(banner.Devices) > 0 && !containsAny(banner.Devices, []string{audience.Device}) {
+		return false
+	}
+	if len(banner.Locales) > 0 && !matchesLocale(banner.Locales, audience.Locale) {
+		return false
+	}
+	return true
+}
+
+// matchesLocale 判断访客语言是否在横幅的语言列表中，只写语言（如 en）的规则匹配该语言的所有地区
+func matchesLocale(locales []string, locale string) bool {
+	lang := strings.SplitN(locale, "-", 2)[0]
+	for _, l := range locales {
+		if strings.EqualFold(l, locale) || strings.EqualFold(l, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, v := range values {
+		for _, c := range candidates {
+			if strings.EqualFold(v, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DetectDevice 根据 User-Agent 判断访客的设备类型
+func DetectDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		return DeviceTablet
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "android"):
+		return DeviceMobile
+	}
+	return DeviceDesktop
+}
+
+func clickThroughRate(impressions, clicks int) float64 {
+	if impressions == 0 {
+		return 0
+	}
+	return float64(clicks) / float64(impressions)
+}
+
+// reportRange 补全报表日期范围，默认截止到今天、统计最近 30 天
+func reportRange(from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = today()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(defaultReportDays - 1))
+	}
+	if from.After(to) {
+		return from, to, apperrors.NewBadRequest("开始日期不能晚于结束日期", nil)
+	}
+	if to.Sub(from) > maxReportDays*24*time.Hour {
+		return from, to, apperrors.NewBadRequest("统计区间不能超过一年", nil)
+	}
+	return from, to, nil
+}
+
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
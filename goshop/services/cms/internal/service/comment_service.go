@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// 垃圾评论分数达到该值时直接标记为垃圾评论
+	spamThreshold = 5
+	// 同一 IP 在 commentRateWindow 内最多正常发表的评论数，超过后计入垃圾评论分数
+	commentRateLimit  = 5
+	commentRateWindow = 10 * time.Minute
+	// 在该时间内出现过完全相同的评论视为重复内容
+	duplicateCommentWindow = 24 * time.Hour
+)
+
+// spamKeywords 常见的垃圾评论关键词
+var spamKeywords = []string{
+	"加微信", "加v", "代开发票", "刷单", "兼职日结", "博彩", "赌场", "裸聊",
+	"casino", "viagra", "crypto giveaway", "free money", "buy followers",
+}
+
+// Commenter 表示登录后发表评论的用户，由访问令牌解析得到
+type Commenter struct {
+	UserID uint
+	Name   string
+}
+
+// CommentRequest 表示发表评论的请求，游客需要填写昵称
+type CommentRequest struct {
+	ParentID    *uint  `json:"parent_id"`
+	AuthorName  string `json:"author_name" binding:"max=50"`
+	AuthorEmail string `json:"author_email" binding:"omitempty,email,max=100"`
+	Body        string `json:"body" binding:"required,max=5000"`
+}
+
+// CommentSource 表示评论请求的来源，用于垃圾评论识别
+type CommentSource struct {
+	IP        string
+	UserAgent string
+}
+
+// CommentQuery 表示后台评论列表的查询条件
+type CommentQuery struct {
+	ContentID uint
+	Status    model.CommentStatus
+	Page      int
+	PageSize  int
+}
+
+// CommentList 表示分页的评论列表
+type CommentList struct {
+	Items    []*model.Comment `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// CommentService 负责博文评论的发表、垃圾评论识别和审核
+type CommentService struct {
+	commentRepo repository.CommentRepository
+	contentRepo repository.ContentRepository
+	log         *logger.Logger
+}
+
+// NewCommentService 创建评论服务
+func NewCommentService(commentRepo repository.CommentRepository, contentRepo repository.ContentRepository, log *logger.Logger) *CommentService {
+	return &CommentService{
+		commentRepo: commentRepo,
+		contentRepo: contentRepo,
+		log:         log,
+	}
+}
+
+// Submit 在已发布的博文下发表评论或回复。疑似垃圾评论直接标记为垃圾评论，
+// 登录用户且没有任何可疑特征的评论直接通过，其余评论进入审核队列
+func (s *CommentService) Submit(ctx context.Context, slug string, commenter *Commenter, source *CommentSource, req *CommentRequest) (*model.Comment, error) {
+	post, err := s.getPost(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if post.CommentsClosed {
+		return nil, apperrors.NewForbidden("该博文已关闭评论", nil)
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, apperrors.NewBadRequest("评论内容不能为空", nil)
+	}
+	comment := &model.Comment{
+		ContentID:   post.ID,
+		AuthorName:  strings.TrimSpace(req.AuthorName),
+		AuthorEmail: req.AuthorEmail,
+		Body:        body,
+		IP:          source.IP,
+		UserAgent:   truncate(source.UserAgent, 255),
+	}
+	if commenter != nil {
+		comment.UserID = &commenter.UserID
+		if comment.AuthorName == "" {
+			comment.AuthorName = truncate(commenter.Name, 50)
+		}
+	}
+	if comment.AuthorName == "" {
+		return nil, apperrors.NewBadRequest("请填写昵称", nil)
+	}
+
+	if req.ParentID != nil {
+		parent, err := s.commentRepo.GetByID(ctx, *req.ParentID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewInternalServerError("获取评论失败", err)
+		}
+		if err != nil || parent.ContentID != post.ID || parent.Status != model.CommentStatusApproved {
+			return nil, apperrors.NewBadRequest("回复的评论不存在", err)
+		}
+		comment.ParentID = &parent.ID
+		comment.RootID = parent.RootID
+		if comment.RootID == nil {
+			comment.RootID = &parent.ID
+		}
+	}
+
+	score, reasons, err := s.spamScore(ctx, comment)
+	if err != nil {
+		return nil, err
+	}
+	comment.SpamScore = score
+	comment.SpamReasons = reasons
+	switch {
+	case score >= spamThreshold:
+		comment.Status = model.CommentStatusSpam
+	case score == 0 && comment.UserID != nil:
+		comment.Status = model.CommentStatusApproved
+	default:
+		comment.Status = model.CommentStatusPending
+	}
+
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		return nil, apperrors.NewInternalServerError("发表评论失败", err)
+	}
+	if comment.Status == model.CommentStatusSpam {
+		s.log.Info(ctx, "Comment marked as spam",
+			zap.Uint("comment_id", comment.ID),
+			zap.Int("spam_score", score),
+			zap.Strings("reasons", reasons),
+		)
+	}
+	return comment, nil
+}
+
+// ListForPost 分页获取博文下已通过的第一级评论，每条评论附带所有已通过的回复
+func (s *CommentService) ListForPost(ctx context.Context, slug string, page, pageSize int) (*CommentList, error) {
+	post, err := s.getPost(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	page, pageSize = normalizePage(page, pageSize)
+	comments, total, err := s.commentRepo.ListApproved(ctx, post.ID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取评论失败", err)
+	}
+
+	roots := make(map[uint]*model.Comment, len(comments))
+	ids := make([]uint, len(comments))
+	for i, comment := range comments {
+		comment.Replies = []*model.Comment{}
+		roots[comment.ID] = comment
+		ids[i] = comment.ID
+	}
+	replies, err := s.commentRepo.ListApprovedReplies(ctx, ids)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取评论回复失败", err)
+	}
+	for _, reply := range replies {
+		if root, ok := roots[*reply.RootID]; ok {
+			root.Replies = append(root.Replies, reply)
+		}
+	}
+	return &CommentList{Items: comments, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// List 分页获取评论，供后台审核使用
+func (s *CommentService) List(ctx context.Context, q *CommentQuery) (*CommentList, error) {
+	switch q.Status {
+	case "", model.CommentStatusPending, model.CommentStatusApproved, model.CommentStatusRejected, model.CommentStatusSpam:
+	default:
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("无效的评论状态 %s", q.Status), nil)
+	}
+	page, pageSize := normalizePage(q.Page, q.PageSize)
+	filter := repository.CommentFilter{ContentID: q.ContentID, Status: q.Status}
+	comments, total, err := s.commentRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取评论失败", err)
+	}
+	return &CommentList{Items: comments, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Approve 审核通过评论，包括被识别为垃圾评论的误判
+func (s *CommentService) Approve(ctx context.Context, editor *Editor, id uint) (*model.Comment, error) {
+	return s.moderate(ctx, editor, id, model.CommentStatusApproved)
+}
+
+// Reject 拒绝评论，已通过的评论会从前台隐藏
+func (s *CommentService) Reject(ctx context.Context, editor *Editor, id uint) (*model.Comment, error) {
+	return s.moderate(ctx, editor, id, model.CommentStatusRejected)
+}
+
+// Delete 删除评论
+func (s *CommentService) Delete(ctx context.Context, editor *Editor, id uint) error {
+	if _, err := s.getComment(ctx, id); err != nil {
+		return err
+	}
+	if err := s.commentRepo.Delete(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除评论失败", err)
+	}
+	s.log.Info(ctx, "Comment deleted", zap.Uint("comment_id", id), zap.Uint("editor_id", editor.ID))
+	return nil
+}
+
+func (s *CommentService) moderate(ctx context.Context, editor *Editor, id uint, status model.CommentStatus) (*model.Comment, error) {
+	comment, err := s.getComment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	comment.Status = status
+	comment.ModeratorID = &editor.ID
+	comment.ModeratedAt = &now
+	if err := s.commentRepo.UpdateStatus(ctx, comment); err != nil {
+		return nil, apperrors.NewInternalServerError("审核评论失败", err)
+	}
+	return comment, nil
+}
+
+// spamScore 根据链接数量、关键词、重复字符、发表频率和重复内容计算垃圾评论分数
+func (s *CommentService) spamScore(ctx context.Context, comment *model.Comment) (int, model.StringArray, error) {
+	score, reasons := 0, model.StringArray{}
+	add := func(points int, reason string) {
+		score += points
+		reasons = append(reasons, reason)
+	}
+
+	lower := strings.ToLower(comment.Body)
+	links := strings.Count(lower, "http://") + strings.Count(lower, "https://") + strings.Count(lower, "www.")
+	switch {
+	case links >= 4:
+		add(4, "包含大量链接")
+	case links >= 2:
+		add(2, "包含多个链接")
+	}
+	for _, keyword := range spamKeywords {
+		if strings.Contains(lower, keyword) || strings.Contains(strings.ToLower(comment.AuthorName), keyword) {
+			add(3, "包含垃圾关键词")
+			break
+		}
+	}
+	if hasRepeatedRunes(comment.Body, 10) {
+		add(1, "包含大量重复字符")
+	}
+	if mostlyUppercase(comment.Body) {
+		add(1, "大部分为大写字母")
+	}
+
+	if comment.IP != "" {
+		recent, err := s.commentRepo.CountRecentByIP(ctx, comment.IP, time.Now().Add(-commentRateWindow))
+		if err != nil {
+			return 0, nil, apperrors.NewInternalServerError("检查评论频率失败", err)
+		}
+		if recent >= commentRateLimit {
+			add(3, "发表过于频繁")
+		}
+	}
+	duplicates, err := s.commentRepo.CountDuplicates(ctx, comment.Body, time.Now().Add(-duplicateCommentWindow))
+	if err != nil {
+		return 0, nil, apperrors.NewInternalServerError("检查重复评论失败", err)
+	}
+	if duplicates > 0 {
+		add(3, "重复内容")
+	}
+	return score, reasons, nil
+}
+
+func (s *CommentService) getPost(ctx context.Context, slug string) (*model.Content, error) {
+	post, err := s.contentRepo.GetPublishedBySlug(ctx, model.ContentTypePost, slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("博文 %s 不存在", slug), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取博文失败", err)
+	}
+	return post, nil
+}
+
+func (s *CommentService) getComment(ctx context.Context, id uint) (*model.Comment, error) {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("评论 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取评论失败", err)
+	}
+	return comment, nil
+}
+
+// hasRepeatedRunes 判断文本中是否有连续重复 n 次以上的字符
+func hasRepeatedRunes(text string, n int) bool {
+	var last rune
+	count := 0
+	for _, r := range text {
+		if r == last {
+			count++
+			if count >= n {
+				return true
+			}
+			continue
+		}
+		last, count = r, 1
+	}
+	return false
+}
+
+// mostlyUppercase 判断较长的英文评论是否大部分为大写字母
+func mostlyUppercase(text string) bool {
+	letters, upper := 0, 0
+	for _, r := range text {
+		if r <= unicode.MaxASCII && unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= 20 && upper*10 >= letters*7
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
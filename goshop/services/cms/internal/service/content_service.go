@@ -0,0 +1,460 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// slug 的最大长度（字符数），为冲突时追加的序号预留空间
+	maxSlugLength = 200
+	// 自动生成 slug 冲突时最多尝试的序号
+	maxSlugSuffix = 100
+)
+
+// Editor 表示发起内容管理操作的后台用户，由认证令牌解析得到
+type Editor struct {
+	ID   uint
+	Name string
+	Role string
+}
+
+// ContentRequest 表示创建或更新内容的请求。Slug 为空时创建根据标题自动生成，更新时保持不变
+type ContentRequest struct {
+	Type            model.ContentType `json:"type" binding:"required,oneof=page post banner"`
+	Title           string            `json:"title" binding:"required,max=255"`
+	Slug            string            `json:"slug" binding:"max=255"`
+	Content         string            `json:"content"`
+	Layout          *model.Layout     `json:"layout"` // 仅页面支持区块布局
+	Excerpt         string            `json:"excerpt" binding:"max=500"`
+	CoverImage      *string           `json:"cover_image" binding:"omitempty,max=255"`
+	Tags            []string          `json:"tags"`
+	CategoryIDs     []uint            `json:"category_ids"`
+	IsSticky        bool              `json:"is_sticky"`
+	CommentsClosed  bool              `json:"comments_closed"`
+	SortOrder       int               `json:"sort_order"`
+	MetaTitle       string            `json:"meta_title" binding:"max=255"`
+	MetaKeywords    string            `json:"meta_keywords" binding:"max=255"`
+	MetaDescription string            `json:"meta_description" binding:"max=500"`
+}
+
+// ContentQuery 表示后台内容列表的查询条件
+type ContentQuery struct {
+	Type     model.ContentType
+	Status   model.ContentStatus
+	AuthorID uint
+	Keyword  string
+	Page     int
+	PageSize int
+}
+
+// ContentList 表示分页的内容列表
+type ContentList struct {
+	Items    []*model.Content `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// RevisionList 表示分页的修订版本列表
+type RevisionList struct {
+	Items    []*model.ContentRevision `json:"items"`
+	Total    int64                    `json:"total"`
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"page_size"`
+}
+
+// ContentService 负责内容的管理、发布和多语言译文，每次保存都会记录修订版本
+type ContentService struct {
+	contentRepo     repository.ContentRepository
+	translationRepo repository.TranslationRepository
+	defaultLocale   string
+	locales         []string
+	workflow        Workflow
+	log             *logger.Logger
+}
+
+// NewContentService 创建内容服务。原文使用 defaultLocale，locales 为支持的语言，未包含默认语言时自动加入；
+// workflow 指定哪些内容类型发布前需要审核
+func NewContentService(
+	contentRepo repository.ContentRepository,
+	translationRepo repository.TranslationRepository,
+	defaultLocale string,
+	locales []string,
+	workflow Workflow,
+	log *logger.Logger,
+) *ContentService {
+	return &ContentService{
+		contentRepo:     contentRepo,
+		translationRepo: translationRepo,
+		defaultLocale:   defaultLocale,
+		locales:         supportedLocales(defaultLocale, locales),
+		workflow:        workflow,
+		log:             log,
+	}
+}
+
+// List 分页获取内容，供后台管理使用，包含草稿和已归档的内容
+func (s *ContentService) List(ctx context.Context, q *ContentQuery) (*ContentList, error) {
+	page, pageSize := normalizePage(q.Page, q.PageSize)
+	filter := repository.ContentFilter{Type: q.Type, Status: q.Status, AuthorID: q.AuthorID, Keyword: q.Keyword}
+	contents, total, err := s.contentRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取内容失败", err)
+	}
+	return &ContentList{Items: contents, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Get 获取内容，供后台管理使用
+func (s *ContentService) Get(ctx context.Context, id uint) (*model.Content, error) {
+	return s.getContent(ctx, id)
+}
+
+// Create 创建草稿内容，作者为当前编辑
+func (s *ContentService) Create(ctx context.Context, editor *Editor, req *ContentRequest) (*model.Content, error) {
+	content := &model.Content{
+		Status:   model.ContentStatusDraft,
+		Author:   editor.Name,
+		AuthorID: editor.ID,
+	}
+	if err := s.fill(ctx, content, req); err != nil {
+		return nil, err
+	}
+	if err := s.contentRepo.Create(ctx, content, snapshot(content, model.RevisionActionCreate, editor)); err != nil {
+		return nil, apperrors.NewInternalServerError("创建内容失败", err)
+	}
+	return content, nil
+}
+
+// Update 更新内容，作者和发布状态保持不变。已审核通过的内容修改后回到草稿，需要重新提交审核
+func (s *ContentService) Update(ctx context.Context, editor *Editor, id uint, req *ContentRequest) (*model.Content, error) {
+	content, err := s.getContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEditable(editor, content, req.Type); err != nil {
+		return nil, err
+	}
+	before := snapshot(content, "", nil)
+	if err := s.fill(ctx, content, req); err != nil {
+		return nil, err
+	}
+	if content.Status == model.ContentStatusApproved {
+		content.Status = model.ContentStatusDraft
+	}
+	if err := s.save(ctx, content, before, model.RevisionActionUpdate, editor); err != nil {
+		return nil, apperrors.NewInternalServerError("更新内容失败", err)
+	}
+	return content, nil
+}
+
+// Publish 发布内容，首次发布时记录发布时间。需要审核的内容必须先审核通过
+func (s *ContentService) Publish(ctx context.Context, editor *Editor, id uint) (*model.Content, error) {
+	content, err := s.getContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPublishable(content); err != nil {
+		return nil, err
+	}
+	before := snapshot(content, "", nil)
+	content.Status = model.ContentStatusPublished
+	if content.PublishedAt == nil {
+		now := time.Now()
+		content.PublishedAt = &now
+	}
+	if err := s.save(ctx, content, before, model.RevisionActionPublish, editor); err != nil {
+		return nil, apperrors.NewInternalServerError("发布内容失败", err)
+	}
+	return content, nil
+}
+
+// Archive 归档内容，归档后前台不再展示
+func (s *ContentService) Archive(ctx context.Context, editor *Editor, id uint) (*model.Content, error) {
+	content, err := s.getContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(content, "", nil)
+	content.Status = model.ContentStatusArchived
+	if err := s.save(ctx, content, before, model.RevisionActionArchive, editor); err != nil {
+		return nil, apperrors.NewInternalServerError("归档内容失败", err)
+	}
+	return content, nil
+}
+
+// Delete 删除内容
+func (s *ContentService) Delete(ctx context.Context, editor *Editor, id uint) error {
+	if _, err := s.getContent(ctx, id); err != nil {
+		return err
+	}
+	if err := s.contentRepo.Delete(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除内容失败", err)
+	}
+	s.log.Info(ctx, "Content deleted", zap.Uint("content_id", id), zap.Uint("editor_id", editor.ID))
+	return nil
+}
+
+// ListRevisions 分页获取内容的修订版本
+func (s *ContentService) ListRevisions(ctx context.Context, id uint, page, pageSize int) (*RevisionList, error) {
+	if _, err := s.getContent(ctx, id); err != nil {
+		return nil, err
+	}
+	page, pageSize = normalizePage(page, pageSize)
+	revisions, total, err := s.contentRepo.ListRevisions(ctx, id, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取修订版本失败", err)
+	}
+	return &RevisionList{Items: revisions, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// GetRevision 获取内容的指定版本
+func (s *ContentService) GetRevision(ctx context.Context, id uint, version int) (*model.ContentRevision, error) {
+	revision, err := s.contentRepo.GetRevision(ctx, id, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("内容 %d 的版本 %d 不存在", id, version), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取修订版本失败", err)
+	}
+	return revision, nil
+}
+
+// Restore 将历史版本恢复为当前草稿。恢复后内容回到草稿状态，需要重新发布才会在前台展示；
+// 历史版本的 slug 已被其他内容占用时保留当前 slug，已删除的分类会被忽略
+func (s *ContentService) Restore(ctx context.Context, editor *Editor, id uint, version int) (*model.Content, error) {
+	content, err := s.getContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := s.GetRevision(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEditable(editor, content, revision.Type); err != nil {
+		return nil, err
+	}
+	before := snapshot(content, "", nil)
+
+	if revision.Slug != content.Slug {
+		exists, err := s.contentRepo.SlugExists(ctx, revision.Slug, content.ID)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("检查 slug 失败", err)
+		}
+		if !exists {
+			content.Slug = revision.Slug
+		}
+	}
+	content.Categories = []model.Category{}
+	if len(revision.CategoryIDs) > 0 {
+		if content.Categories, err = s.contentRepo.GetCategories(ctx, revision.CategoryIDs); err != nil {
+			return nil, apperrors.NewInternalServerError("获取内容分类失败", err)
+		}
+	}
+	content.Type = revision.Type
+	content.Title = revision.Title
+	content.Content = revision.Content
+	content.Layout = revision.Layout
+	content.Excerpt = revision.Excerpt
+	content.CoverImage = revision.CoverImage
+	content.Tags = revision.Tags
+	content.IsSticky = revision.IsSticky
+	content.SortOrder = revision.SortOrder
+	content.MetaTitle = revision.MetaTitle
+	content.MetaKeywords = revision.MetaKeywords
+	content.MetaDescription = revision.MetaDescription
+	content.Status = model.ContentStatusDraft
+
+	restored := snapshot(content, model.RevisionActionRestore, editor)
+	restored.RestoredFrom = &version
+	diffRevision(before, restored)
+	if err := s.contentRepo.Update(ctx, content, restored); err != nil {
+		return nil, apperrors.NewInternalServerError("恢复内容失败", err)
+	}
+	return content, nil
+}
+
+// GetPublished 根据 slug 获取已发布的页面或博文，并增加浏览次数。
+// locale 没有已发布的译文时返回默认语言的原文
+func (s *ContentService) GetPublished(ctx context.Context, contentType model.ContentType, slug, locale string) (*model.Content, error) {
+	content, err := s.contentRepo.GetPublishedBySlug(ctx, contentType, slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("内容 %s 不存在", slug), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取内容失败", err)
+	}
+	if err := s.contentRepo.IncrementViewCount(ctx, content.ID); err != nil {
+		s.log.Warn(ctx, "Failed to increment content view count", zap.Uint("content_id", content.ID), zap.Error(err))
+	}
+	if err := s.localize(ctx, []*model.Content{content}, locale); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// ListPublished 分页获取已发布的内容，有已发布译文的内容使用 locale 的译文
+func (s *ContentService) ListPublished(ctx context.Context, contentType model.ContentType, locale string, page, pageSize int) (*ContentList, error) {
+	list, err := s.List(ctx, &ContentQuery{Type: contentType, Status: model.ContentStatusPublished, Page: page, PageSize: pageSize})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.localize(ctx, list.Items, locale); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// save 保存内容并记录相对于 before 的修订版本
+func (s *ContentService) save(ctx context.Context, content *model.Content, before *model.ContentRevision, action model.RevisionAction, editor *Editor) error {
+	revision := snapshot(content, action, editor)
+	diffRevision(before, revision)
+	return s.contentRepo.Update(ctx, content, revision)
+}
+
+// fill 校验请求并写入内容，处理 slug 的生成和唯一性以及区块布局的规范化
+func (s *ContentService) fill(ctx context.Context, content *model.Content, req *ContentRequest) error {
+	layout, err := normalizeLayout(req.Layout)
+	if err != nil {
+		return err
+	}
+	if layout != nil && req.Type != model.ContentTypePage {
+		return apperrors.NewBadRequest("只有页面支持区块布局", nil)
+	}
+
+	switch {
+	case req.Slug != "":
+		slug := slugify(req.Slug)
+		if slug == "" {
+			return apperrors.NewBadRequest("slug 只能包含字母、数字和连字符", nil)
+		}
+		if slug != content.Slug {
+			exists, err := s.contentRepo.SlugExists(ctx, slug, content.ID)
+			if err != nil {
+				return apperrors.NewInternalServerError("检查 slug 失败", err)
+			}
+			if exists {
+				return apperrors.NewConflict(fmt.Sprintf("slug %s 已被使用", slug), nil)
+			}
+		}
+		content.Slug = slug
+	case content.Slug == "":
+		slug, err := s.uniqueSlug(ctx, req.Type, req.Title)
+		if err != nil {
+			return err
+		}
+		content.Slug = slug
+	}
+
+	content.Categories = []model.Category{}
+	if len(req.CategoryIDs) > 0 {
+		categories, err := s.contentRepo.GetCategories(ctx, req.CategoryIDs)
+		if err != nil {
+			return apperrors.NewInternalServerError("获取内容分类失败", err)
+		}
+		if len(categories) != len(uniqueIDs(req.CategoryIDs)) {
+			return apperrors.NewBadRequest("内容分类不存在", nil)
+		}
+		content.Categories = categories
+	}
+
+	content.Type = req.Type
+	content.Title = req.Title
+	content.Content = req.Content
+	content.Layout = layout
+	content.Excerpt = req.Excerpt
+	content.CoverImage = req.CoverImage
+	content.Tags = req.Tags
+	content.IsSticky = req.IsSticky
+	content.CommentsClosed = req.CommentsClosed
+	content.SortOrder = req.SortOrder
+	content.MetaTitle = req.MetaTitle
+	content.MetaKeywords = req.MetaKeywords
+	content.MetaDescription = req.MetaDescription
+	return nil
+}
+
+// uniqueSlug 根据标题生成未被占用的 slug，冲突时依次追加 -2、-3 等序号
+func (s *ContentService) uniqueSlug(ctx context.Context, contentType model.ContentType, title string) (string, error) {
+	base := slugify(title)
+	if base == "" {
+		base = string(contentType)
+	}
+	slug := base
+	for i := 2; i <= maxSlugSuffix+1; i++ {
+		exists, err := s.contentRepo.SlugExists(ctx, slug, 0)
+		if err != nil {
+			return "", apperrors.NewInternalServerError("检查 slug 失败", err)
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	return "", apperrors.NewConflict("无法生成唯一的 slug，请手动指定", nil)
+}
+
+func (s *ContentService) getContent(ctx context.Context, id uint) (*model.Content, error) {
+	content, err := s.contentRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("内容 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取内容失败", err)
+	}
+	return content, nil
+}
+
+// slugify 将文本转换为 slug：字母小写，保留字母和数字（包括中文），其他字符合并为单个连字符
+func slugify(text string) string {
+	var b strings.Builder
+	n, dash := 0, false
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		if n >= maxSlugLength {
+			break
+		}
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			n, dash = n+1, false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			n, dash = n+1, true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// uniqueIDs 去除重复的 ID 并保持原有顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
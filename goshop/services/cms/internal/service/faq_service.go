@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// 前台一次最多返回的常见问题数量
+	maxPublicFAQs = 500
+	// 同一访客对同一问题在该时间内只记录一次反馈
+	faqFeedbackWindow = 24 * time.Hour
+	// 低分报表的默认条件：至少有 10 次反馈且有帮助比例不超过 50%
+	defaultFAQMinVotes = 10
+	defaultFAQMaxRate  = 0.5
+	// 常见问题分类 slug 的最大长度
+	maxFAQCategorySlugLength = 50
+)
+
+// FAQCategoryRequest 表示创建或更新常见问题分类的请求，Slug 为空时根据名称生成
+type FAQCategoryRequest struct {
+	Name        string  `json:"name" binding:"required,max=50"`
+	Slug        string  `json:"slug" binding:"max=50"`
+	Description string  `json:"description" binding:"max=255"`
+	Icon        *string `json:"icon" binding:"omitempty,max=50"`
+	SortOrder   int     `json:"sort_order"`
+}
+
+// FAQRequest 表示创建或更新常见问题的请求
+type FAQRequest struct {
+	CategoryID  uint     `json:"category_id" binding:"required"`
+	Question    string   `json:"question" binding:"required,max=255"`
+	Answer      string   `json:"answer" binding:"required"`
+	Keywords    []string `json:"keywords"`
+	SortOrder   int      `json:"sort_order"`
+	IsPublished bool     `json:"is_published"`
+}
+
+// FAQQuery 表示后台常见问题列表的查询条件
+type FAQQuery struct {
+	CategoryID uint
+	Keyword    string
+	Page       int
+	PageSize   int
+}
+
+// FAQList 表示分页的常见问题列表
+type FAQList struct {
+	Items    []*model.FAQ `json:"items"`
+	Total    int64        `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
+
+// FAQGroup 表示前台按分类分组的常见问题
+type FAQGroup struct {
+	Category *model.FAQCategory `json:"category"`
+	Items    []*model.FAQ       `json:"items"`
+}
+
+// FAQReportQuery 表示低分答案报表的查询条件，零值使用默认条件
+type FAQReportQuery struct {
+	MinVotes int
+	MaxRate  float64
+	Page     int
+	PageSize int
+}
+
+// FAQReport 表示分页的低分答案报表
+type FAQReport struct {
+	MinVotes int                    `json:"min_votes"`
+	MaxRate  float64                `json:"max_rate"`
+	Items    []*repository.FAQScore `json:"items"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+}
+
+// FAQService 负责常见问题的管理、前台展示和答案反馈统计
+type FAQService struct {
+	faqRepo repository.FAQRepository
+	rdb     *redis.Client
+	log     *logger.Logger
+}
+
+// NewFAQService 创建常见问题服务
+func NewFAQService(faqRepo repository.FAQRepository, rdb *redis.Client, log *logger.Logger) *FAQService {
+	return &FAQService{
+		faqRepo: faqRepo,
+		rdb:     rdb,
+		log:     log,
+	}
+}
+
+// ListGroups 获取按分类分组的已发布常见问题，keyword 不为空时只返回匹配的问题，没有问题的分类不返回
+func (s *FAQService) ListGroups(ctx context.Context, keyword string) ([]*FAQGroup, error) {
+	categories, err := s.faqRepo.ListCategories(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取常见问题分类失败", err)
+	}
+	published := true
+	filter := repository.FAQFilter{Published: &published, Keyword: strings.TrimSpace(keyword)}
+	faqs, _, err := s.faqRepo.List(ctx, filter, 0, maxPublicFAQs)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取常见问题失败", err)
+	}
+
+	byCategory := make(map[uint][]*model.FAQ)
+	for _, faq := range faqs {
+		byCategory[faq.CategoryID] = append(byCategory[faq.CategoryID], faq)
+	}
+	groups := []*FAQGroup{}
+	for _, category := range categories {
+		if items := byCategory[category.ID]; len(items) > 0 {
+			groups = append(groups, &FAQGroup{Category: category, Items: items})
+		}
+	}
+	return groups, nil
+}
+
+// GetPublished 获取已发布的常见问题
+func (s *FAQService) GetPublished(ctx context.Context, id uint) (*model.FAQ, error) {
+	faq, err := s.getFAQ(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !faq.IsPublished {
+		return nil, apperrors.NewNotFound(fmt.Sprintf("常见问题 %d 不存在", id), nil)
+	}
+	return faq, nil
+}
+
+// Feedback 记录访客对答案是否有帮助的反馈，同一访客在 faqFeedbackWindow 内重复反馈会被忽略
+func (s *FAQService) Feedback(ctx context.Context, id uint, visitor string, helpful bool) error {
+	if _, err := s.GetPublished(ctx, id); err != nil {
+		return err
+	}
+	if visitor != "" {
+		key := fmt.Sprintf("cms:faq:feedback:%d:%s", id, visitor)
+		first, err := s.rdb.SetNX(ctx, key, 1, faqFeedbackWindow).Result()
+		if err != nil {
+			s.log.Warn(ctx, "Failed to check FAQ feedback", zap.Uint("faq_id", id), zap.Error(err))
+		} else if !first {
+			return nil
+		}
+	}
+	if err := s.faqRepo.AddFeedback(ctx, id, helpful); err != nil {
+		return apperrors.NewInternalServerError("记录反馈失败", err)
+	}
+	return nil
+}
+
+// ListCategories 获取所有常见问题分类
+func (s *FAQService) ListCategories(ctx context.Context) ([]*model.FAQCategory, error) {
+	categories, err := s.faqRepo.ListCategories(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取常见问题分类失败", err)
+	}
+	return categories, nil
+}
+
+// CreateCategory 创建常见问题分类
+func (s *FAQService) CreateCategory(ctx context.Context, req *FAQCategoryRequest) (*model.FAQCategory, error) {
+	category := &model.FAQCategory{}
+	if err := s.fillCategory(ctx, category, req); err != nil {
+		return nil, err
+	}
+	if err := s.faqRepo.CreateCategory(ctx, category); err != nil {
+		return nil, apperrors.NewInternalServerError("创建常见问题分类失败", err)
+	}
+	return category, nil
+}
+
+// UpdateCategory 更新常见问题分类
+func (s *FAQService) UpdateCategory(ctx context.Context, id uint, req *FAQCategoryRequest) (*model.FAQCategory, error) {
+	category, err := s.getCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillCategory(ctx, category, req); err != nil {
+		return nil, err
+	}
+	if err := s.faqRepo.UpdateCategory(ctx, category); err != nil {
+		return nil, apperrors.NewInternalServerError("更新常见问题分类失败", err)
+	}
+	return category, nil
+}
+
+// DeleteCategory 删除常见问题分类，分类下还有问题时不能删除
+func (s *FAQService) DeleteCategory(ctx context.Context, editor *Editor, id uint) error {
+	if _, err := s.getCategory(ctx, id); err != nil {
+		return err
+	}
+	count, err := s.faqRepo.CountByCategory(ctx, id)
+	if err != nil {
+		return apperrors.NewInternalServerError("统计常见问题失败", err)
+	}
+	if count > 0 {
+		return apperrors.NewConflict(fmt.Sprintf("分类下还有 %d 个常见问题，请先移动或删除", count), nil)
+	}
+	if err := s.faqRepo.DeleteCategory(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除常见问题分类失败", err)
+	}
+	s.log.Info(ctx, "FAQ category deleted", zap.Uint("category_id", id), zap.Uint("editor_id", editor.ID))
+	return nil
+}
+
+// List 分页获取常见问题，包括未发布的问题，供后台管理使用
+func (s *FAQService) List(ctx context.Context, q *FAQQuery) (*FAQList, error) {
+	page, pageSize := normalizePage(q.Page, q.PageSize)
+	filter := repository.FAQFilter{CategoryID: q.CategoryID, Keyword: strings.TrimSpace(q.Keyword)}
+	faqs, total, err := s.faqRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取常见问题失败", err)
+	}
+	return &FAQList{Items: faqs, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Get 获取常见问题，包括未发布的问题
+func (s *FAQService) Get(ctx context.Context, id uint) (*model.FAQ, error) {
+	return s.getFAQ(ctx, id)
+}
+
+// Create 创建常见问题
+func (s *FAQService) Create(ctx context.Context, req *FAQRequest) (*model.FAQ, error) {
+	faq := &model.FAQ{}
+	if err := s.fill(ctx, faq, req); err != nil {
+		return nil, err
+	}
+	if err := s.faqRepo.Create(ctx, faq); err != nil {
+		return nil, apperrors.NewInternalServerError("创建常见问题失败", err)
+	}
+	return faq, nil
+}
+
+// Update 更新常见问题，反馈统计保持不变
+func (s *FAQService) Update(ctx context.Context, id uint, req *FAQRequest) (*model.FAQ, error) {
+	faq, err := s.getFAQ(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fill(ctx, faq, req); err != nil {
+		return nil, err
+	}
+	if err := s.faqRepo.Update(ctx, faq); err != nil {
+		return nil, apperrors.NewInternalServerError("更新常见问题失败", err)
+	}
+	return faq, nil
+}
+
+// Delete 删除常见问题
+func (s *FAQService) Delete(ctx context.Context, editor *Editor, id uint) error {
+	if _, err := s.getFAQ(ctx, id); err != nil {
+		return err
+	}
+	if err := s.faqRepo.Delete(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除常见问题失败", err)
+	}
+	s.log.Info(ctx, "FAQ deleted", zap.Uint("faq_id", id), zap.Uint("editor_id", editor.ID))
+	return nil
+}
+
+// Report 获取低分答案报表，供客服团队改进答案：反馈次数不少于 MinVotes 且有帮助比例不超过 MaxRate 的问题，
+// 按有帮助比例从低到高排列
+func (s *FAQService) Report(ctx context.Context, q *FAQReportQuery) (*FAQReport, error) {
+	minVotes, maxRate := q.MinVotes, q.MaxRate
+	if minVotes <= 0 {
+		minVotes = defaultFAQMinVotes
+	}
+	if maxRate <= 0 || maxRate > 1 {
+		maxRate = defaultFAQMaxRate
+	}
+	page, pageSize := normalizePage(q.Page, q.PageSize)
+	scores, total, err := s.faqRepo.ListLowScoring(ctx, minVotes, maxRate, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取常见问题报表失败", err)
+	}
+	return &FAQReport{
+		MinVotes: minVotes,
+		MaxRate:  maxRate,
+		Items:    scores,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (s *FAQService) fillCategory(ctx context.Context, category *model.FAQCategory, req *FAQCategoryRequest) error {
+	source := req.Slug
+	if source == "" {
+		source = req.Name
+	}
+	slug := truncate(slugify(source), maxFAQCategorySlugLength)
+	slug = strings.TrimSuffix(slug, "-")
+	if slug == "" {
+		return apperrors.NewBadRequest("slug 只能包含字母、数字和连字符", nil)
+	}
+	exists, err := s.faqRepo.CategorySlugExists(ctx, slug, category.ID)
+	if err != nil {
+		return apperrors.NewInternalServerError("检查 slug 失败", err)
+	}
+	if exists {
+		return apperrors.NewConflict(fmt.Sprintf("slug %s 已被使用", slug), nil)
+	}
+
+	category.Name = req.Name
+	category.Slug = slug
+	category.Description = req.Description
+	category.Icon = req.Icon
+	category.SortOrder = req.SortOrder
+	return nil
+}
+
+func (s *FAQService) fill(ctx context.Context, faq *model.FAQ, req *FAQRequest) error {
+	if _, err := s.faqRepo.GetCategory(ctx, req.CategoryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NewBadRequest(fmt.Sprintf("常见问题分类 %d 不存在", req.CategoryID), err)
+		}
+		return apperrors.NewInternalServerError("获取常见问题分类失败", err)
+	}
+	keywords := model.StringArray{}
+	for _, keyword := range req.Keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+
+	faq.CategoryID = req.CategoryID
+	faq.Question = strings.TrimSpace(req.Question)
+	faq.Answer = req.Answer
+	faq.Keywords = keywords
+	faq.SortOrder = req.SortOrder
+	faq.IsPublished = req.IsPublished
+	return nil
+}
+
+func (s *FAQService) getCategory(ctx context.Context, id uint) (*model.FAQCategory, error) {
+	category, err := s.faqRepo.GetCategory(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("常见问题分类 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取常见问题分类失败", err)
+	}
+	return category, nil
+}
+
+func (s *FAQService) getFAQ(ctx context.Context, id uint) (*model.FAQ, error) {
+	faq, err := s.faqRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("常见问题 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取常见问题失败", err)
+	}
+	return faq, nil
+}
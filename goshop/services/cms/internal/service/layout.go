@@ -0,0 +1,331 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"go.uber.org/zap"
+)
+
+const (
+	// 单个页面最多包含的区块数
+	maxLayoutBlocks = 50
+	// 商品轮播最多包含的商品数
+	maxCarouselProducts = 50
+	// 横幅宫格最多包含的横幅数
+	maxGridItems = 12
+	// 横幅宫格最多的列数
+	maxGridColumns = 4
+	// 富文本区块最多包含的段落数
+	maxRichTextParagraphs = 200
+	// Markdown 区块的最大长度（字符数）
+	maxMarkdownLength = 20000
+	// 区块标识的最大长度
+	maxBlockIDLength = 50
+)
+
+// richTextStyles 富文本段落支持的样式
+var richTextStyles = map[string]bool{
+	"paragraph": true,
+	"heading":   true,
+	"quote":     true,
+	"list_item": true,
+}
+
+// htmlTagPattern 匹配 Markdown 中内嵌的 HTML 标签和注释
+var htmlTagPattern = regexp.MustCompile(`<\s*[a-zA-Z!/?]`)
+
+// blockIDPattern 区块标识只允许字母、数字、连字符和下划线
+var blockIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// layoutError 表示布局中某个区块校验失败的原因
+type layoutError struct {
+	Index   int
+	Type    model.BlockType
+	Message string
+}
+
+func (e *layoutError) Error() string {
+	return fmt.Sprintf("第 %d 个区块（%s）%s", e.Index+1, e.Type, e.Message)
+}
+
+// PageLayout 表示前端按区块渲染页面所需的数据
+type PageLayout struct {
+	ID              uint                `json:"id"`
+	Locale          string              `json:"locale"`
+	Slug            string              `json:"slug"`
+	Title           string              `json:"title"`
+	MetaTitle       string              `json:"meta_title"`
+	MetaKeywords    string              `json:"meta_keywords"`
+	MetaDescription string              `json:"meta_description"`
+	Blocks          []model.LayoutBlock `json:"blocks"`
+	ProductIDs      []uint              `json:"product_ids"` // 所有商品轮播引用的商品，供前端批量查询
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+// GetPageLayout 根据 slug 获取已发布页面的区块布局，并增加浏览次数
+func (s *ContentService) GetPageLayout(ctx context.Context, slug, locale string) (*PageLayout, error) {
+	content, err := s.GetPublished(ctx, model.ContentTypePage, slug, locale)
+	if err != nil {
+		return nil, err
+	}
+	if content.Layout == nil {
+		return nil, apperrors.NewNotFound(fmt.Sprintf("页面 %s 未使用区块布局", slug), nil)
+	}
+
+	page := &PageLayout{
+		ID:              content.ID,
+		Locale:          content.Locale,
+		Slug:            content.Slug,
+		Title:           content.Title,
+		MetaTitle:       content.MetaTitle,
+		MetaKeywords:    content.MetaKeywords,
+		MetaDescription: content.MetaDescription,
+		Blocks:          content.Layout.Blocks,
+		ProductIDs:      []uint{},
+		UpdatedAt:       content.UpdatedAt,
+	}
+	for _, block := range content.Layout.Blocks {
+		if block.Type != model.BlockTypeProductCarousel {
+			continue
+		}
+		var carousel model.ProductCarouselBlock
+		if err := json.Unmarshal(block.Data, &carousel); err != nil {
+			s.log.Warn(ctx, "Failed to decode product carousel block", zap.Uint("content_id", content.ID), zap.Error(err))
+			continue
+		}
+		page.ProductIDs = append(page.ProductIDs, carousel.ProductIDs...)
+	}
+	page.ProductIDs = uniqueIDs(page.ProductIDs)
+	return page, nil
+}
+
+// ValidateLayout 校验页面布局并返回规范化后的布局，供编辑器保存前预览
+func (s *ContentService) ValidateLayout(layout *model.Layout) (*model.Layout, error) {
+	return normalizeLayout(layout)
+}
+
+// normalizeLayout 按区块类型严格解析并重新序列化区块数据：未知字段、缺失的必填字段和不安全的链接都会被拒绝，
+// 未指定标识的区块会生成随机标识。布局为空或不包含区块时返回 nil
+func normalizeLayout(layout *model.Layout) (*model.Layout, error) {
+	if layout == nil || len(layout.Blocks) == 0 {
+		return nil, nil
+	}
+	if len(layout.Blocks) > maxLayoutBlocks {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("页面最多包含 %d 个区块", maxLayoutBlocks), nil)
+	}
+
+	normalized := &model.Layout{Blocks: make([]model.LayoutBlock, 0, len(layout.Blocks))}
+	seen := make(map[string]bool, len(layout.Blocks))
+	for i, block := range layout.Blocks {
+		data, err := normalizeBlock(block)
+		if err != nil {
+			err.Index, err.Type = i, block.Type
+			return nil, apperrors.NewBadRequest(err.Error(), err)
+		}
+
+		id := block.ID
+		switch {
+		case id == "":
+			id = newBlockID()
+		case len(id) > maxBlockIDLength || !blockIDPattern.MatchString(id):
+			err := &layoutError{Index: i, Type: block.Type, Message: "的标识只能包含字母、数字、连字符和下划线"}
+			return nil, apperrors.NewBadRequest(err.Error(), err)
+		case seen[id]:
+			err := &layoutError{Index: i, Type: block.Type, Message: fmt.Sprintf("的标识 %s 重复", id)}
+			return nil, apperrors.NewBadRequest(err.Error(), err)
+		}
+		seen[id] = true
+
+		normalized.Blocks = append(normalized.Blocks, model.LayoutBlock{ID: id, Type: block.Type, Data: data})
+	}
+	return normalized, nil
+}
+
+// normalizeBlock 校验区块数据并返回规范化后的 JSON
+func normalizeBlock(block model.LayoutBlock) (json.RawMessage, *layoutError) {
+	var (
+		data    interface{}
+		message string
+	)
+	switch block.Type {
+	case model.BlockTypeHero:
+		hero := &model.HeroBlock{}
+		if err := decodeBlock(block.Data, hero); err != nil {
+			return nil, err
+		}
+		message, data = validateHero(hero), hero
+	case model.BlockTypeProductCarousel:
+		carousel := &model.ProductCarouselBlock{}
+		if err := decodeBlock(block.Data, carousel); err != nil {
+			return nil, err
+		}
+		message, data = validateCarousel(carousel), carousel
+	case model.BlockTypeRichText:
+		text := &model.RichTextBlock{}
+		if err := decodeBlock(block.Data, text); err != nil {
+			return nil, err
+		}
+		message, data = validateRichText(text), text
+	case model.BlockTypeBannerGrid:
+		grid := &model.BannerGridBlock{}
+		if err := decodeBlock(block.Data, grid); err != nil {
+			return nil, err
+		}
+		message, data = validateBannerGrid(grid), grid
+	case model.BlockTypeMarkdown:
+		md := &model.MarkdownBlock{}
+		if err := decodeBlock(block.Data, md); err != nil {
+			return nil, err
+		}
+		message, data = validateMarkdown(md), md
+	default:
+		message = "的类型不受支持"
+	}
+	if message != "" {
+		return nil, &layoutError{Message: message}
+	}
+	normalized, err := json.Marshal(data)
+	if err != nil {
+		return nil, &layoutError{Message: "的数据无法序列化：" + err.Error()}
+	}
+	return normalized, nil
+}
+
+// decodeBlock 严格解析区块数据，拒绝未知字段
+func decodeBlock(raw json.RawMessage, v interface{}) *layoutError {
+	if len(raw) == 0 {
+		return &layoutError{Message: "缺少数据"}
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &layoutError{Message: "的数据格式错误：" + err.Error()}
+	}
+	return nil
+}
+
+func validateHero(hero *model.HeroBlock) string {
+	hero.Title = strings.TrimSpace(hero.Title)
+	switch {
+	case hero.Title == "":
+		return "缺少标题"
+	case hero.Image == "":
+		return "缺少图片"
+	case !safeURL(hero.Image) || !safeURL(hero.MobileImage) || !safeURL(hero.LinkURL):
+		return "包含无效的链接"
+	case hero.LinkText != "" && hero.LinkURL == "":
+		return "设置了按钮文字但缺少链接"
+	}
+	return ""
+}
+
+func validateCarousel(carousel *model.ProductCarouselBlock) string {
+	carousel.ProductIDs = uniqueIDs(carousel.ProductIDs)
+	switch {
+	case len(carousel.ProductIDs) == 0:
+		return "至少需要一个商品"
+	case len(carousel.ProductIDs) > maxCarouselProducts:
+		return fmt.Sprintf("最多包含 %d 个商品", maxCarouselProducts)
+	case !safeURL(carousel.MoreURL):
+		return "包含无效的链接"
+	}
+	for _, id := range carousel.ProductIDs {
+		if id == 0 {
+			return "包含无效的商品 ID"
+		}
+	}
+	return ""
+}
+
+func validateRichText(text *model.RichTextBlock) string {
+	if len(text.Paragraphs) == 0 {
+		return "至少需要一个段落"
+	}
+	if len(text.Paragraphs) > maxRichTextParagraphs {
+		return fmt.Sprintf("最多包含 %d 个段落", maxRichTextParagraphs)
+	}
+	for i := range text.Paragraphs {
+		p := &text.Paragraphs[i]
+		if p.Style == "" {
+			p.Style = "paragraph"
+		}
+		if !richTextStyles[p.Style] {
+			return fmt.Sprintf("的第 %d 段样式 %s 不受支持", i+1, p.Style)
+		}
+		for _, span := range p.Spans {
+			if !safeURL(span.Link) {
+				return fmt.Sprintf("的第 %d 段包含无效的链接", i+1)
+			}
+		}
+	}
+	return ""
+}
+
+func validateBannerGrid(grid *model.BannerGridBlock) string {
+	if len(grid.Items) == 0 {
+		return "至少需要一张横幅"
+	}
+	if len(grid.Items) > maxGridItems {
+		return fmt.Sprintf("最多包含 %d 张横幅", maxGridItems)
+	}
+	if grid.Columns == 0 {
+		grid.Columns = len(grid.Items)
+		if grid.Columns > maxGridColumns {
+			grid.Columns = maxGridColumns
+		}
+	}
+	if grid.Columns < 1 || grid.Columns > maxGridColumns {
+		return fmt.Sprintf("的列数必须在 1 到 %d 之间", maxGridColumns)
+	}
+	for i, item := range grid.Items {
+		if item.Image == "" {
+			return fmt.Sprintf("的第 %d 张横幅缺少图片", i+1)
+		}
+		if !safeURL(item.Image) || !safeURL(item.URL) {
+			return fmt.Sprintf("的第 %d 张横幅包含无效的链接", i+1)
+		}
+	}
+	return ""
+}
+
+func validateMarkdown(md *model.MarkdownBlock) string {
+	switch {
+	case strings.TrimSpace(md.Markdown) == "":
+		return "缺少内容"
+	case utf8.RuneCountInString(md.Markdown) > maxMarkdownLength:
+		return fmt.Sprintf("最多 %d 个字符", maxMarkdownLength)
+	case htmlTagPattern.MatchString(md.Markdown):
+		return "不能包含 HTML 标签"
+	}
+	return ""
+}
+
+// safeURL 校验链接只能是站内路径或 http(s) 地址，空链接视为有效
+func safeURL(raw string) bool {
+	if raw == "" {
+		return true
+	}
+	if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") {
+		return true
+	}
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func newBlockID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
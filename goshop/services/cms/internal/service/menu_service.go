@@ -0,0 +1,517 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// 菜单最多的层级数
+	maxMenuDepth = 3
+	// 前台菜单树的缓存时间。菜单变更时立即清除缓存，关联内容的 slug 或状态变更最多延迟该时间生效
+	menuCacheTTL = 5 * time.Minute
+)
+
+// MenuRequest 表示创建或更新菜单的请求
+type MenuRequest struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	Location string `json:"location" binding:"required,max=50"`
+}
+
+// MenuItemRequest 表示创建或更新菜单项的请求。设置 ContentID 时链接指向该内容，忽略 URL
+type MenuItemRequest struct {
+	ParentID  *uint   `json:"parent_id"`
+	Title     string  `json:"title" binding:"required,max=50"`
+	URL       string  `json:"url" binding:"max=255"`
+	Target    string  `json:"target" binding:"omitempty,oneof=_self _blank"`
+	Icon      *string `json:"icon" binding:"omitempty,max=50"`
+	SortOrder int     `json:"sort_order"`
+	IsActive  *bool   `json:"is_active"`
+	ContentID *uint   `json:"content_id"`
+}
+
+// MenuItemPosition 表示调整后菜单项的父级和排序，ParentID 为空表示移动到第一级
+type MenuItemPosition struct {
+	ID        uint  `json:"id" binding:"required"`
+	ParentID  *uint `json:"parent_id"`
+	SortOrder int   `json:"sort_order"`
+}
+
+// ReorderMenuRequest 表示批量调整菜单项位置的请求，未包含的菜单项保持不变
+type ReorderMenuRequest struct {
+	Items []MenuItemPosition `json:"items" binding:"required,min=1,dive"`
+}
+
+// MenuNode 表示前台菜单树中的节点，链接已根据关联内容解析
+type MenuNode struct {
+	ID        uint        `json:"id"`
+	Title     string      `json:"title"`
+	URL       string      `json:"url"`
+	Target    string      `json:"target"`
+	Icon      *string     `json:"icon,omitempty"`
+	ContentID *uint       `json:"content_id,omitempty"`
+	Children  []*MenuNode `json:"children"`
+}
+
+// MenuTree 表示前台展示的菜单
+type MenuTree struct {
+	ID       uint        `json:"id"`
+	Name     string      `json:"name"`
+	Location string      `json:"location"`
+	Items    []*MenuNode `json:"items"`
+}
+
+// MenuService 负责导航菜单的管理和前台菜单树的生成
+type MenuService struct {
+	menuRepo    repository.MenuRepository
+	contentRepo repository.ContentRepository
+	rdb         *redis.Client
+	log         *logger.Logger
+}
+
+// NewMenuService 创建导航菜单服务
+func NewMenuService(menuRepo repository.MenuRepository, contentRepo repository.ContentRepository, rdb *redis.Client, log *logger.Logger) *MenuService {
+	return &MenuService{
+		menuRepo:    menuRepo,
+		contentRepo: contentRepo,
+		rdb:         rdb,
+		log:         log,
+	}
+}
+
+// GetTree 获取位置上的菜单树，只包含启用的菜单项；关联内容未发布或已删除的菜单项及其子项不会展示
+func (s *MenuService) GetTree(ctx context.Context, location string) (*MenuTree, error) {
+	key := menuCacheKey(location)
+	if data, err := s.rdb.Get(ctx, key).Bytes(); err == nil {
+		var tree MenuTree
+		if err := json.Unmarshal(data, &tree); err == nil {
+			return &tree, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		s.log.Warn(ctx, "Failed to read menu cache", zap.String("location", location), zap.Error(err))
+	}
+
+	menu, err := s.menuRepo.GetMenuByLocation(ctx, location)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("位置 %s 没有菜单", location), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取菜单失败", err)
+	}
+	items, err := s.menuRepo.ListItems(ctx, menu.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取菜单项失败", err)
+	}
+	links, err := s.contentLinks(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &MenuTree{
+		ID:       menu.ID,
+		Name:     menu.Name,
+		Location: menu.Location,
+		Items:    buildMenuNodes(groupByParent(items), 0, links),
+	}
+	if data, err := json.Marshal(tree); err == nil {
+		if err := s.rdb.Set(ctx, key, data, menuCacheTTL).Err(); err != nil {
+			s.log.Warn(ctx, "Failed to write menu cache", zap.String("location", location), zap.Error(err))
+		}
+	}
+	return tree, nil
+}
+
+// ListMenus 获取所有菜单，不包含菜单项
+func (s *MenuService) ListMenus(ctx context.Context) ([]*model.Menu, error) {
+	menus, err := s.menuRepo.ListMenus(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取菜单失败", err)
+	}
+	return menus, nil
+}
+
+// GetMenu 获取菜单及其完整的菜单项树，包含未启用的菜单项，供后台管理使用
+func (s *MenuService) GetMenu(ctx context.Context, id uint) (*model.Menu, error) {
+	menu, err := s.getMenu(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.menuRepo.ListItems(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取菜单项失败", err)
+	}
+	menu.Items = buildMenuItems(groupByParent(items), 0)
+	return menu, nil
+}
+
+// CreateMenu 创建菜单，每个位置只能有一个菜单
+func (s *MenuService) CreateMenu(ctx context.Context, req *MenuRequest) (*model.Menu, error) {
+	if err := s.checkLocation(ctx, req.Location, 0); err != nil {
+		return nil, err
+	}
+	menu := &model.Menu{Name: req.Name, Location: req.Location}
+	if err := s.menuRepo.CreateMenu(ctx, menu); err != nil {
+		return nil, apperrors.NewInternalServerError("创建菜单失败", err)
+	}
+	s.invalidate(ctx, menu.Location)
+	return menu, nil
+}
+
+// UpdateMenu 更新菜单名称和位置
+func (s *MenuService) UpdateMenu(ctx context.Context, id uint, req *MenuRequest) (*model.Menu, error) {
+	menu, err := s.getMenu(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLocation(ctx, req.Location, id); err != nil {
+		return nil, err
+	}
+	oldLocation := menu.Location
+	menu.Name = req.Name
+	menu.Location = req.Location
+	if err := s.menuRepo.UpdateMenu(ctx, menu); err != nil {
+		return nil, apperrors.NewInternalServerError("更新菜单失败", err)
+	}
+	s.invalidate(ctx, oldLocation, menu.Location)
+	return menu, nil
+}
+
+// DeleteMenu 删除菜单及其所有菜单项
+func (s *MenuService) DeleteMenu(ctx context.Context, editor *Editor, id uint) error {
+	menu, err := s.getMenu(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.menuRepo.DeleteMenu(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除菜单失败", err)
+	}
+	s.invalidate(ctx, menu.Location)
+	s.log.Info(ctx, "Menu deleted", zap.Uint("menu_id", id), zap.Uint("editor_id", editor.ID))
+	return nil
+}
+
+// CreateItem 在菜单中添加菜单项
+func (s *MenuService) CreateItem(ctx context.Context, menuID uint, req *MenuItemRequest) (*model.MenuItem, error) {
+	menu, err := s.getMenu(ctx, menuID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.menuRepo.ListItems(ctx, menuID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取菜单项失败", err)
+	}
+
+	item := &model.MenuItem{MenuID: menuID, Target: "_self", IsActive: true}
+	if err := s.fillItem(ctx, item, req); err != nil {
+		return nil, err
+	}
+	if err := validateMenuTree(append(items, item)); err != nil {
+		return nil, err
+	}
+	if err := s.menuRepo.CreateItem(ctx, item); err != nil {
+		return nil, apperrors.NewInternalServerError("创建菜单项失败", err)
+	}
+	s.invalidate(ctx, menu.Location)
+	return item, nil
+}
+
+// UpdateItem 更新菜单项，修改 ParentID 可以把菜单项连同子项移动到其他父级下
+func (s *MenuService) UpdateItem(ctx context.Context, menuID, itemID uint, req *MenuItemRequest) (*model.MenuItem, error) {
+	menu, err := s.getMenu(ctx, menuID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.menuRepo.ListItems(ctx, menuID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取菜单项失败", err)
+	}
+	item := findMenuItem(items, itemID)
+	if item == nil {
+		return nil, apperrors.NewNotFound(fmt.Sprintf("菜单项 %d 不存在", itemID), nil)
+	}
+
+	if err := s.fillItem(ctx, item, req); err != nil {
+		return nil, err
+	}
+	if err := validateMenuTree(items); err != nil {
+		return nil, err
+	}
+	if err := s.menuRepo.UpdateItem(ctx, item); err != nil {
+		return nil, apperrors.NewInternalServerError("更新菜单项失败", err)
+	}
+	s.invalidate(ctx, menu.Location)
+	return item, nil
+}
+
+// DeleteItem 删除菜单项及其所有子项
+func (s *MenuService) DeleteItem(ctx context.Context, menuID, itemID uint) error {
+	menu, err := s.getMenu(ctx, menuID)
+	if err != nil {
+		return err
+	}
+	items, err := s.menuRepo.ListItems(ctx, menuID)
+	if err != nil {
+		return apperrors.NewInternalServerError("获取菜单项失败", err)
+	}
+	if findMenuItem(items, itemID) == nil {
+		return apperrors.NewNotFound(fmt.Sprintf("菜单项 %d 不存在", itemID), nil)
+	}
+
+	byParent := groupByParent(items)
+	ids := []uint{itemID}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range byParent[ids[i]] {
+			ids = append(ids, child.ID)
+		}
+	}
+	if err := s.menuRepo.DeleteItems(ctx, menuID, ids); err != nil {
+		return apperrors.NewInternalServerError("删除菜单项失败", err)
+	}
+	s.invalidate(ctx, menu.Location)
+	return nil
+}
+
+// Reorder 批量调整菜单项的父级和排序，调整后的菜单树整体校验通过才会保存
+func (s *MenuService) Reorder(ctx context.Context, menuID uint, req *ReorderMenuRequest) (*model.Menu, error) {
+	menu, err := s.getMenu(ctx, menuID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.menuRepo.ListItems(ctx, menuID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取菜单项失败", err)
+	}
+
+	positions := make([]repository.MenuItemPosition, 0, len(req.Items))
+	seen := make(map[uint]bool, len(req.Items))
+	for _, p := range req.Items {
+		if seen[p.ID] {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("菜单项 %d 重复", p.ID), nil)
+		}
+		seen[p.ID] = true
+		item := findMenuItem(items, p.ID)
+		if item == nil {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("菜单项 %d 不属于该菜单", p.ID), nil)
+		}
+		item.ParentID = p.ParentID
+		item.SortOrder = p.SortOrder
+		positions = append(positions, repository.MenuItemPosition{ID: p.ID, ParentID: p.ParentID, SortOrder: p.SortOrder})
+	}
+	if err := validateMenuTree(items); err != nil {
+		return nil, err
+	}
+	if err := s.menuRepo.UpdatePositions(ctx, menuID, positions); err != nil {
+		return nil, apperrors.NewInternalServerError("调整菜单项顺序失败", err)
+	}
+	s.invalidate(ctx, menu.Location)
+	return s.GetMenu(ctx, menuID)
+}
+
+// fillItem 校验请求并写入菜单项，关联的内容必须是页面或博文
+func (s *MenuService) fillItem(ctx context.Context, item *model.MenuItem, req *MenuItemRequest) error {
+	if req.ContentID != nil {
+		links, err := s.contentRepo.GetLinks(ctx, []uint{*req.ContentID})
+		if err != nil {
+			return apperrors.NewInternalServerError("获取关联内容失败", err)
+		}
+		if len(links) == 0 {
+			return apperrors.NewBadRequest(fmt.Sprintf("关联的内容 %d 不存在", *req.ContentID), nil)
+		}
+		if contentPath(links[0]) == "" {
+			return apperrors.NewBadRequest("菜单只能关联页面或博文", nil)
+		}
+	} else if req.URL == "" {
+		return apperrors.NewBadRequest("菜单项需要设置链接或关联内容", nil)
+	}
+	if !safeURL(req.URL) {
+		return apperrors.NewBadRequest("无效的链接", nil)
+	}
+
+	item.ParentID = req.ParentID
+	item.Title = req.Title
+	item.URL = req.URL
+	if req.Target != "" {
+		item.Target = req.Target
+	}
+	item.Icon = req.Icon
+	item.SortOrder = req.SortOrder
+	if req.IsActive != nil {
+		item.IsActive = *req.IsActive
+	}
+	item.ContentID = req.ContentID
+	return nil
+}
+
+// contentLinks 获取菜单项关联的已发布内容的链接
+func (s *MenuService) contentLinks(ctx context.Context, items []*model.MenuItem) (map[uint]string, error) {
+	ids := make([]uint, 0)
+	for _, item := range items {
+		if item.ContentID != nil {
+			ids = append(ids, *item.ContentID)
+		}
+	}
+	links := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return links, nil
+	}
+	contents, err := s.contentRepo.GetLinks(ctx, uniqueIDs(ids))
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取关联内容失败", err)
+	}
+	for _, content := range contents {
+		if content.Status == model.ContentStatusPublished {
+			links[content.ID] = contentPath(content)
+		}
+	}
+	return links, nil
+}
+
+func (s *MenuService) checkLocation(ctx context.Context, location string, excludeID uint) error {
+	exists, err := s.menuRepo.LocationExists(ctx, location, excludeID)
+	if err != nil {
+		return apperrors.NewInternalServerError("检查菜单位置失败", err)
+	}
+	if exists {
+		return apperrors.NewConflict(fmt.Sprintf("位置 %s 已有菜单", location), nil)
+	}
+	return nil
+}
+
+func (s *MenuService) getMenu(ctx context.Context, id uint) (*model.Menu, error) {
+	menu, err := s.menuRepo.GetMenu(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("菜单 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取菜单失败", err)
+	}
+	return menu, nil
+}
+
+// invalidate 清除位置上的菜单树缓存
+func (s *MenuService) invalidate(ctx context.Context, locations ...string) {
+	keys := make([]string, len(locations))
+	for i, location := range locations {
+		keys[i] = menuCacheKey(location)
+	}
+	if err := s.rdb.Del(ctx, keys...).Err(); err != nil {
+		s.log.Warn(ctx, "Failed to invalidate menu cache", zap.Strings("locations", locations), zap.Error(err))
+	}
+}
+
+// validateMenuTree 校验菜单项的父级都属于同一菜单、不存在循环引用，且层级不超过 maxMenuDepth
+func validateMenuTree(items []*model.MenuItem) error {
+	parents := make(map[uint]*uint, len(items))
+	for _, item := range items {
+		if item.ID != 0 {
+			parents[item.ID] = item.ParentID
+		}
+	}
+	for _, item := range items {
+		depth := 1
+		for parent := item.ParentID; parent != nil; depth++ {
+			if item.ID != 0 && *parent == item.ID {
+				return apperrors.NewBadRequest(fmt.Sprintf("菜单项 %d 不能移动到自身或其子项下", item.ID), nil)
+			}
+			next, ok := parents[*parent]
+			if !ok {
+				return apperrors.NewBadRequest(fmt.Sprintf("上级菜单项 %d 不属于该菜单", *parent), nil)
+			}
+			if depth > len(items) {
+				return apperrors.NewBadRequest("菜单项存在循环引用", nil)
+			}
+			parent = next
+		}
+		if depth > maxMenuDepth {
+			return apperrors.NewBadRequest(fmt.Sprintf("菜单最多 %d 级", maxMenuDepth), nil)
+		}
+	}
+	return nil
+}
+
+// groupByParent 按父级分组菜单项，第一级菜单项的键为 0
+func groupByParent(items []*model.MenuItem) map[uint][]*model.MenuItem {
+	byParent := make(map[uint][]*model.MenuItem)
+	for _, item := range items {
+		var parentID uint
+		if item.ParentID != nil {
+			parentID = *item.ParentID
+		}
+		byParent[parentID] = append(byParent[parentID], item)
+	}
+	return byParent
+}
+
+// buildMenuItems 生成后台使用的菜单项树
+func buildMenuItems(byParent map[uint][]*model.MenuItem, parentID uint) []model.MenuItem {
+	items := make([]model.MenuItem, 0, len(byParent[parentID]))
+	for _, item := range byParent[parentID] {
+		node := *item
+		node.Children = buildMenuItems(byParent, item.ID)
+		items = append(items, node)
+	}
+	return items
+}
+
+// buildMenuNodes 生成前台菜单树，跳过未启用和关联内容不可见的菜单项
+func buildMenuNodes(byParent map[uint][]*model.MenuItem, parentID uint, links map[uint]string) []*MenuNode {
+	nodes := make([]*MenuNode, 0, len(byParent[parentID]))
+	for _, item := range byParent[parentID] {
+		if !item.IsActive {
+			continue
+		}
+		url := item.URL
+		if item.ContentID != nil {
+			path, ok := links[*item.ContentID]
+			if !ok {
+				continue
+			}
+			url = path
+		}
+		nodes = append(nodes, &MenuNode{
+			ID:        item.ID,
+			Title:     item.Title,
+			URL:       url,
+			Target:    item.Target,
+			Icon:      item.Icon,
+			ContentID: item.ContentID,
+			Children:  buildMenuNodes(byParent, item.ID, links),
+		})
+	}
+	return nodes
+}
+
+func findMenuItem(items []*model.MenuItem, id uint) *model.MenuItem {
+	for _, item := range items {
+		if item.ID == id {
+			return item
+		}
+	}
+	return nil
+}
+
+// contentPath 返回内容在前台的路径，只有页面和博文有独立的路径
+func contentPath(content *model.Content) string {
+	switch content.Type {
+	case model.ContentTypePage:
+		return "/pages/" + content.Slug
+	case model.ContentTypePost:
+		return "/posts/" + content.Slug
+	}
+	return ""
+}
+
+func menuCacheKey(location string) string {
+	return "cms:menu:" + location
+}
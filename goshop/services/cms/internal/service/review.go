@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Workflow 表示内容审核流程的配置。ReviewTypes 中的内容类型需要经过 草稿 → 审核中 → 审核通过 → 已发布 的流程，
+// 编辑提交审核，ApproverRoles 中的角色审核通过或驳回
+type Workflow struct {
+	ReviewTypes   []string `json:"review_types"`   // 发布前需要审核通过的内容类型
+	ApproverRoles []string `json:"approver_roles"` // 可以审核内容、以及直接修改已发布内容的角色
+}
+
+// ReviewRequest 表示审核操作的请求，驳回时必须填写审核意见
+type ReviewRequest struct {
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+// Workflow 获取内容审核流程的配置，供后台编辑器决定展示哪些操作
+func (s *ContentService) Workflow() *Workflow {
+	return &s.workflow
+}
+
+// SubmitForReview 将草稿提交审核，审核期间内容不能修改
+func (s *ContentService) SubmitForReview(ctx context.Context, editor *Editor, id uint, req *ReviewRequest) (*model.Content, error) {
+	content, err := s.getContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.requiresReview(content.Type) {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("%s 类型的内容无需审核，可以直接发布", content.Type), nil)
+	}
+	if content.Status != model.ContentStatusDraft {
+		return nil, apperrors.NewConflict("只有草稿可以提交审核", nil)
+	}
+	return s.review(ctx, editor, content, model.ContentStatusInReview, model.RevisionActionSubmit, req.Comment)
+}
+
+// Approve 审核通过内容，审核通过后由编辑发布
+func (s *ContentService) Approve(ctx context.Context, editor *Editor, id uint, req *ReviewRequest) (*model.Content, error) {
+	content, err := s.getReviewing(ctx, editor, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.review(ctx, editor, content, model.ContentStatusApproved, model.RevisionActionApprove, req.Comment)
+}
+
+// Reject 驳回内容，内容回到草稿状态，编辑根据审核意见修改后重新提交
+func (s *ContentService) Reject(ctx context.Context, editor *Editor, id uint, req *ReviewRequest) (*model.Content, error) {
+	if strings.TrimSpace(req.Comment) == "" {
+		return nil, apperrors.NewBadRequest("驳回时必须填写审核意见", nil)
+	}
+	content, err := s.getReviewing(ctx, editor, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.review(ctx, editor, content, model.ContentStatusDraft, model.RevisionActionReject, req.Comment)
+}
+
+// ListReviews 获取内容的审核记录
+func (s *ContentService) ListReviews(ctx context.Context, id uint) ([]*model.ContentReview, error) {
+	if _, err := s.getContent(ctx, id); err != nil {
+		return nil, err
+	}
+	reviews, err := s.contentRepo.ListReviews(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取审核记录失败", err)
+	}
+	return reviews, nil
+}
+
+// checkEditable 检查编辑是否可以修改内容：需要审核的内容在审核期间不能修改，
+// 已发布的内容修改后立即生效，只有审核人可以直接修改
+func (s *ContentService) checkEditable(editor *Editor, content *model.Content, types ...model.ContentType) error {
+	reviewed := s.requiresReview(content.Type)
+	for _, t := range types {
+		reviewed = reviewed || s.requiresReview(t)
+	}
+	if !reviewed {
+		return nil
+	}
+	switch content.Status {
+	case model.ContentStatusInReview:
+		return apperrors.NewConflict("内容审核中，不能修改", nil)
+	case model.ContentStatusPublished:
+		if !s.isApprover(editor) {
+			return apperrors.NewForbidden("已发布的内容只能由审核人修改，请先归档后重新提交审核", nil)
+		}
+	}
+	return nil
+}
+
+// checkPublishable 检查内容是否可以发布，需要审核的内容必须先审核通过
+func (s *ContentService) checkPublishable(content *model.Content) error {
+	if !s.requiresReview(content.Type) {
+		return nil
+	}
+	if content.Status != model.ContentStatusApproved && content.Status != model.ContentStatusPublished {
+		return apperrors.NewConflict("内容需要审核通过后才能发布", nil)
+	}
+	return nil
+}
+
+func (s *ContentService) getReviewing(ctx context.Context, editor *Editor, id uint) (*model.Content, error) {
+	if !s.isApprover(editor) {
+		return nil, apperrors.NewForbidden("没有审核内容的权限", nil)
+	}
+	content, err := s.getContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if content.Status != model.ContentStatusInReview {
+		return nil, apperrors.NewConflict("内容不在审核中", nil)
+	}
+	return content, nil
+}
+
+// review 变更内容的审核状态，同时记录修订版本和审核记录
+func (s *ContentService) review(ctx context.Context, editor *Editor, content *model.Content, to model.ContentStatus, action model.RevisionAction, comment string) (*model.Content, error) {
+	before := snapshot(content, "", nil)
+	record := &model.ContentReview{
+		Action:       action,
+		FromStatus:   content.Status,
+		ToStatus:     to,
+		ReviewerID:   editor.ID,
+		ReviewerName: editor.Name,
+		ReviewerRole: editor.Role,
+		Comment:      strings.TrimSpace(comment),
+	}
+	content.Status = to
+	content.UpdatedAt = time.Now()
+	revision := snapshot(content, action, editor)
+	diffRevision(before, revision)
+
+	err := s.contentRepo.Review(ctx, content, revision, record)
+	if errors.Is(err, repository.ErrContentStatusChanged) {
+		return nil, apperrors.NewConflict("内容状态已变化，请刷新后重试", err)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("保存审核结果失败", err)
+	}
+	s.log.Info(ctx, "Content review recorded",
+		zap.Uint("content_id", content.ID),
+		zap.String("action", string(action)),
+		zap.Uint("reviewer_id", editor.ID),
+		zap.Int("version", revision.Version),
+	)
+	return content, nil
+}
+
+func (s *ContentService) requiresReview(contentType model.ContentType) bool {
+	for _, t := range s.workflow.ReviewTypes {
+		if strings.EqualFold(t, string(contentType)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ContentService) isApprover(editor *Editor) bool {
+	for _, role := range s.workflow.ApproverRoles {
+		if role == editor.Role {
+			return true
+		}
+	}
+	return false
+}
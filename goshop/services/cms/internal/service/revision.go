@@ -0,0 +1,87 @@
+package service
+
+import (
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/yourusername/goshop/services/cms/internal/model"
+)
+
+// snapshot 生成内容当前状态的修订版本快照，editor 为空时只用于比较
+func snapshot(content *model.Content, action model.RevisionAction, editor *Editor) *model.ContentRevision {
+	revision := &model.ContentRevision{
+		ContentID:       content.ID,
+		Action:          action,
+		Type:            content.Type,
+		Title:           content.Title,
+		Slug:            content.Slug,
+		Content:         content.Content,
+		Layout:          content.Layout,
+		Excerpt:         content.Excerpt,
+		CoverImage:      content.CoverImage,
+		Status:          content.Status,
+		Tags:            content.Tags,
+		CategoryIDs:     make(model.UintArray, 0, len(content.Categories)),
+		IsSticky:        content.IsSticky,
+		SortOrder:       content.SortOrder,
+		MetaTitle:       content.MetaTitle,
+		MetaKeywords:    content.MetaKeywords,
+		MetaDescription: content.MetaDescription,
+	}
+	for _, category := range content.Categories {
+		revision.CategoryIDs = append(revision.CategoryIDs, category.ID)
+	}
+	if editor != nil {
+		revision.EditorID = editor.ID
+		revision.EditorName = editor.Name
+	}
+	return revision
+}
+
+// diffRevision 记录 next 相对于 prev 发生变化的字段和正文字符数的变化
+func diffRevision(prev, next *model.ContentRevision) {
+	fields := []struct {
+		name      string
+		prev, cur interface{}
+	}{
+		{"type", prev.Type, next.Type},
+		{"title", prev.Title, next.Title},
+		{"slug", prev.Slug, next.Slug},
+		{"content", prev.Content, next.Content},
+		{"layout", prev.Layout, next.Layout},
+		{"excerpt", prev.Excerpt, next.Excerpt},
+		{"cover_image", stringValue(prev.CoverImage), stringValue(next.CoverImage)},
+		{"status", prev.Status, next.Status},
+		{"tags", []string(prev.Tags), []string(next.Tags)},
+		{"category_ids", []uint(prev.CategoryIDs), []uint(next.CategoryIDs)},
+		{"is_sticky", prev.IsSticky, next.IsSticky},
+		{"sort_order", prev.SortOrder, next.SortOrder},
+		{"meta_title", prev.MetaTitle, next.MetaTitle},
+		{"meta_keywords", prev.MetaKeywords, next.MetaKeywords},
+		{"meta_description", prev.MetaDescription, next.MetaDescription},
+	}
+
+	next.ChangedFields = model.StringArray{}
+	for _, f := range fields {
+		if !equalValues(f.prev, f.cur) {
+			next.ChangedFields = append(next.ChangedFields, f.name)
+		}
+	}
+	next.ContentDelta = utf8.RuneCountInString(next.Content) - utf8.RuneCountInString(prev.Content)
+}
+
+// equalValues 比较字段值，空切片和 nil 视为相等
+func equalValues(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == reflect.Slice && vb.Kind() == reflect.Slice && va.Len() == 0 && vb.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
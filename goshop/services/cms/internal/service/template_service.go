@@ -0,0 +1,445 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"github.com/yourusername/goshop/services/cms/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxSMSLength 是短信模板正文的最大字符数，渲染后超过 70 个字符的短信会按多条计费
+const maxSMSLength = 500
+
+// maxPushLength 是推送模板正文的最大字符数，过长的推送在通知栏中会被截断
+const maxPushLength = 200
+
+var (
+	// templateKeyPattern 模板业务标识由小写字母、数字和 . _ - 分隔的片段组成，如 order.shipped
+	templateKeyPattern = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`)
+	// placeholderPattern 匹配 {{variable}} 形式的变量引用，变量名可以用 . 分隔层级
+	placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)\s*\}\}`)
+)
+
+// TemplateRequest 表示创建或更新消息模板的请求，更新时 Key、Channel 和 Locale 不可修改
+type TemplateRequest struct {
+	Key         string                `json:"key" binding:"required,max=100"`
+	Channel     model.TemplateChannel `json:"channel" binding:"required,oneof=email sms push"`
+	Locale      string                `json:"locale" binding:"required,max=10"`
+	Description string                `json:"description" binding:"max=255"`
+	Subject     string                `json:"subject" binding:"max=255"`
+	Body        string                `json:"body" binding:"required"`
+}
+
+// TemplateQuery 表示消息模板列表的查询条件
+type TemplateQuery struct {
+	Key      string
+	Channel  model.TemplateChannel
+	Locale   string
+	Page     int
+	PageSize int
+}
+
+// TemplateList 表示分页的消息模板列表
+type TemplateList struct {
+	Items    []*model.Template `json:"items"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// TemplateVersionList 表示分页的消息模板历史版本列表
+type TemplateVersionList struct {
+	Items    []*model.TemplateVersion `json:"items"`
+	Total    int64                    `json:"total"`
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"page_size"`
+}
+
+// RenderRequest 表示渲染消息模板的请求，Locale 没有对应模板时使用默认语言的模板
+type RenderRequest struct {
+	Key       string                 `json:"key" binding:"required"`
+	Channel   model.TemplateChannel  `json:"channel" binding:"required,oneof=email sms push"`
+	Locale    string                 `json:"locale"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// PreviewRequest 表示在后台预览消息模板的请求，Version 为 0 时预览当前版本
+type PreviewRequest struct {
+	Version   int                    `json:"version" binding:"min=0"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// RenderedMessage 表示渲染后的消息
+type RenderedMessage struct {
+	Key     string                `json:"key"`
+	Channel model.TemplateChannel `json:"channel"`
+	Locale  string                `json:"locale"`
+	Version int                   `json:"version"`
+	Subject string                `json:"subject,omitempty"`
+	Body    string                `json:"body"`
+}
+
+// TemplateService 负责事务性邮件、短信和推送模板的管理、版本记录和渲染
+type TemplateService struct {
+	templateRepo  repository.TemplateRepository
+	defaultLocale string
+	locales       []string
+	log           *logger.Logger
+}
+
+// NewTemplateService 创建消息模板服务，locales 为支持的语言，渲染时找不到对应语言的模板则使用 defaultLocale
+func NewTemplateService(templateRepo repository.TemplateRepository, defaultLocale string, locales []string, log *logger.Logger) *TemplateService {
+	return &TemplateService{
+		templateRepo:  templateRepo,
+		defaultLocale: defaultLocale,
+		locales:       supportedLocales(defaultLocale, locales),
+		log:           log,
+	}
+}
+
+// List 分页获取消息模板，Key 按前缀匹配
+func (s *TemplateService) List(ctx context.Context, q *TemplateQuery) (*TemplateList, error) {
+	page, pageSize := normalizePage(q.Page, q.PageSize)
+	filter := repository.TemplateFilter{Key: q.Key, Channel: q.Channel, Locale: q.Locale}
+	templates, total, err := s.templateRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取消息模板失败", err)
+	}
+	return &TemplateList{Items: templates, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Get 获取消息模板
+func (s *TemplateService) Get(ctx context.Context, id uint) (*model.Template, error) {
+	return s.getTemplate(ctx, id)
+}
+
+// Create 创建消息模板，作为第一个版本
+func (s *TemplateService) Create(ctx context.Context, editor *Editor, req *TemplateRequest) (*model.Template, error) {
+	locale := matchLocale(s.locales, req.Locale)
+	if locale == "" || !strings.EqualFold(locale, req.Locale) {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("不支持的语言 %s", req.Locale), nil)
+	}
+	if !templateKeyPattern.MatchString(req.Key) {
+		return nil, apperrors.NewBadRequest("模板标识只能包含小写字母、数字和 . _ - 分隔符", nil)
+	}
+	exists, err := s.templateRepo.Exists(ctx, req.Key, req.Channel, locale, 0)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("检查消息模板失败", err)
+	}
+	if exists {
+		return nil, apperrors.NewConflict(fmt.Sprintf("模板 %s 的 %s %s 版本已存在", req.Key, req.Channel, locale), nil)
+	}
+
+	template := &model.Template{Key: req.Key, Channel: req.Channel, Locale: locale, Version: 1}
+	if err := fillTemplate(template, req.Description, req.Subject, req.Body); err != nil {
+		return nil, err
+	}
+	template.EditorID = editor.ID
+	template.EditorName = editor.Name
+	if err := s.templateRepo.Create(ctx, template, templateVersion(template, nil)); err != nil {
+		return nil, apperrors.NewInternalServerError("创建消息模板失败", err)
+	}
+	s.log.Info(ctx, "Template created",
+		zap.Uint("template_id", template.ID),
+		zap.String("key", template.Key),
+		zap.String("channel", string(template.Channel)),
+		zap.String("locale", template.Locale),
+	)
+	return template, nil
+}
+
+// Update 更新消息模板的描述、主题和正文，内容有变化时生成新版本
+func (s *TemplateService) Update(ctx context.Context, editor *Editor, id uint, req *TemplateRequest) (*model.Template, error) {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Key != template.Key || req.Channel != template.Channel || !strings.EqualFold(req.Locale, template.Locale) {
+		return nil, apperrors.NewBadRequest("模板标识、渠道和语言不可修改", nil)
+	}
+	if template.Description == req.Description && template.Subject == req.Subject && template.Body == req.Body {
+		return template, nil
+	}
+	if err := fillTemplate(template, req.Description, req.Subject, req.Body); err != nil {
+		return nil, err
+	}
+	return s.save(ctx, editor, template, nil)
+}
+
+// Delete 删除消息模板及其所有版本
+func (s *TemplateService) Delete(ctx context.Context, editor *Editor, id uint) error {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.templateRepo.Delete(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除消息模板失败", err)
+	}
+	s.log.Info(ctx, "Template deleted",
+		zap.Uint("template_id", id),
+		zap.String("key", template.Key),
+		zap.Uint("editor_id", editor.ID),
+	)
+	return nil
+}
+
+// ListVersions 分页获取消息模板的历史版本
+func (s *TemplateService) ListVersions(ctx context.Context, id uint, page, pageSize int) (*TemplateVersionList, error) {
+	if _, err := s.getTemplate(ctx, id); err != nil {
+		return nil, err
+	}
+	page, pageSize = normalizePage(page, pageSize)
+	versions, total, err := s.templateRepo.ListVersions(ctx, id, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取模板版本失败", err)
+	}
+	return &TemplateVersionList{Items: versions, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// GetVersion 获取消息模板的指定版本
+func (s *TemplateService) GetVersion(ctx context.Context, id uint, version int) (*model.TemplateVersion, error) {
+	if _, err := s.getTemplate(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.getVersion(ctx, id, version)
+}
+
+// Restore 将消息模板恢复为指定版本的内容，恢复结果作为新版本保存
+func (s *TemplateService) Restore(ctx context.Context, editor *Editor, id uint, version int) (*model.Template, error) {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.getVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := fillTemplate(template, template.Description, v.Subject, v.Body); err != nil {
+		return nil, err
+	}
+	return s.save(ctx, editor, template, &v.Version)
+}
+
+// Render 使用变量渲染消息模板，供通知服务发送邮件和短信。
+// 请求的语言没有对应模板时使用默认语言的模板，模板引用的变量未提供时返回 400
+func (s *TemplateService) Render(ctx context.Context, req *RenderRequest) (*RenderedMessage, error) {
+	template, err := s.findTemplate(ctx, req.Key, req.Channel, req.Locale)
+	if err != nil {
+		return nil, err
+	}
+	return renderTemplate(template.Key, template.Channel, template.Locale, template.Version, template.Subject, template.Body, req.Variables)
+}
+
+// Preview 使用示例变量渲染消息模板的当前版本或历史版本，供后台编辑时预览
+func (s *TemplateService) Preview(ctx context.Context, id uint, req *PreviewRequest) (*RenderedMessage, error) {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Version == 0 || req.Version == template.Version {
+		return renderTemplate(template.Key, template.Channel, template.Locale, template.Version, template.Subject, template.Body, req.Variables)
+	}
+	v, err := s.getVersion(ctx, id, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	return renderTemplate(template.Key, template.Channel, template.Locale, v.Version, v.Subject, v.Body, req.Variables)
+}
+
+// save 保存消息模板的修改并记录新版本
+func (s *TemplateService) save(ctx context.Context, editor *Editor, template *model.Template, restoredFrom *int) (*model.Template, error) {
+	template.Version++
+	template.EditorID = editor.ID
+	template.EditorName = editor.Name
+	err := s.templateRepo.Update(ctx, template, templateVersion(template, restoredFrom))
+	if errors.Is(err, repository.ErrTemplateVersionConflict) {
+		return nil, apperrors.NewConflict("消息模板已被其他人修改，请刷新后重试", err)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("保存消息模板失败", err)
+	}
+	return template, nil
+}
+
+// findTemplate 查找指定语言的模板，找不到时依次尝试匹配的支持语言和默认语言
+func (s *TemplateService) findTemplate(ctx context.Context, key string, channel model.TemplateChannel, locale string) (*model.Template, error) {
+	candidates := []string{}
+	if matched := matchLocale(s.locales, locale); matched != "" {
+		candidates = append(candidates, matched)
+	}
+	if len(candidates) == 0 || candidates[0] != s.defaultLocale {
+		candidates = append(candidates, s.defaultLocale)
+	}
+	for _, candidate := range candidates {
+		template, err := s.templateRepo.Find(ctx, key, channel, candidate)
+		if err == nil {
+			return template, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewInternalServerError("获取消息模板失败", err)
+		}
+	}
+	return nil, apperrors.NewNotFound(fmt.Sprintf("模板 %s 没有 %s 版本", key, channel), nil)
+}
+
+func (s *TemplateService) getTemplate(ctx context.Context, id uint) (*model.Template, error) {
+	template, err := s.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("消息模板 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取消息模板失败", err)
+	}
+	return template, nil
+}
+
+func (s *TemplateService) getVersion(ctx context.Context, id uint, version int) (*model.TemplateVersion, error) {
+	v, err := s.templateRepo.GetVersion(ctx, id, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("消息模板 %d 的版本 %d 不存在", id, version), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取模板版本失败", err)
+	}
+	return v, nil
+}
+
+// fillTemplate 校验并填充模板的主题和正文，同时提取引用的变量。
+// 邮件模板必须有主题，短信模板不能有主题且正文不能超过 maxSMSLength 个字符，
+// 推送模板必须有标题且正文不能超过 maxPushLength 个字符
+func fillTemplate(template *model.Template, description, subject, body string) error {
+	subject = strings.TrimSpace(subject)
+	switch template.Channel {
+	case model.TemplateChannelEmail:
+		if subject == "" {
+			return apperrors.NewBadRequest("邮件模板必须填写主题", nil)
+		}
+	case model.TemplateChannelSMS:
+		if subject != "" {
+			return apperrors.NewBadRequest("短信模板不能填写主题", nil)
+		}
+		if utf8.RuneCountInString(body) > maxSMSLength {
+			return apperrors.NewBadRequest(fmt.Sprintf("短信模板正文不能超过 %d 个字符", maxSMSLength), nil)
+		}
+	case model.TemplateChannelPush:
+		if subject == "" {
+			return apperrors.NewBadRequest("推送模板必须填写标题", nil)
+		}
+		if utf8.RuneCountInString(body) > maxPushLength {
+			return apperrors.NewBadRequest(fmt.Sprintf("推送模板正文不能超过 %d 个字符", maxPushLength), nil)
+		}
+	}
+	if strings.TrimSpace(body) == "" {
+		return apperrors.NewBadRequest("模板正文不能为空", nil)
+	}
+	for _, text := range []string{subject, body} {
+		if rest := placeholderPattern.ReplaceAllString(text, ""); strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+			return apperrors.NewBadRequest("模板中有格式错误的变量，变量格式为 {{name}}", nil)
+		}
+	}
+
+	template.Description = description
+	template.Subject = subject
+	template.Body = body
+	template.Variables = templateVariables(subject, body)
+	return nil
+}
+
+// templateVersion 生成模板当前内容的版本快照
+func templateVersion(template *model.Template, restoredFrom *int) *model.TemplateVersion {
+	return &model.TemplateVersion{
+		TemplateID:   template.ID,
+		Version:      template.Version,
+		Subject:      template.Subject,
+		Body:         template.Body,
+		Variables:    template.Variables,
+		RestoredFrom: restoredFrom,
+		EditorID:     template.EditorID,
+		EditorName:   template.EditorName,
+	}
+}
+
+// templateVariables 按名称排序返回主题和正文中引用的变量，去除重复
+func templateVariables(texts ...string) model.StringArray {
+	seen := make(map[string]bool)
+	variables := model.StringArray{}
+	for _, text := range texts {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				variables = append(variables, match[1])
+			}
+		}
+	}
+	sort.Strings(variables)
+	return variables
+}
+
+// renderTemplate 替换主题和正文中的变量。邮件正文按 HTML 渲染，变量值会被转义
+func renderTemplate(key string, channel model.TemplateChannel, locale string, version int, subject, body string, variables map[string]interface{}) (*RenderedMessage, error) {
+	var missing []string
+	for _, name := range templateVariables(subject, body) {
+		if _, ok := lookupVariable(variables, name); !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("缺少模板变量: %s", strings.Join(missing, ", ")), nil)
+	}
+
+	replace := func(text string, escape bool) string {
+		return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+			name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+			value, _ := lookupVariable(variables, name)
+			if escape {
+				return html.EscapeString(value)
+			}
+			return value
+		})
+	}
+	return &RenderedMessage{
+		Key:     key,
+		Channel: channel,
+		Locale:  locale,
+		Version: version,
+		Subject: replace(subject, false),
+		Body:    replace(body, channel == model.TemplateChannelEmail),
+	}, nil
+}
+
+// lookupVariable 按 . 分隔的层级查找变量值，如 order.number 查找 variables["order"]["number"]
+func lookupVariable(variables map[string]interface{}, name string) (string, bool) {
+	var value interface{} = variables
+	for _, part := range strings.Split(name, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = m[part]; !ok || value == nil {
+			return "", false
+		}
+	}
+	switch v := value.(type) {
+	case map[string]interface{}, []interface{}:
+		return "", false
+	case float64:
+		// JSON 数字解码为 float64，整数按整数格式输出
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v)), true
+		}
+		return fmt.Sprint(v), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
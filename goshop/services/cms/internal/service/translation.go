@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/cms/internal/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TranslationRequest 表示保存译文的请求，Status 为空时保存为草稿
+type TranslationRequest struct {
+	Title           string                  `json:"title" binding:"required,max=255"`
+	Content         string                  `json:"content"`
+	Excerpt         string                  `json:"excerpt" binding:"max=500"`
+	Layout          *model.Layout           `json:"layout"`
+	MetaTitle       string                  `json:"meta_title" binding:"max=255"`
+	MetaKeywords    string                  `json:"meta_keywords" binding:"max=255"`
+	MetaDescription string                  `json:"meta_description" binding:"max=500"`
+	Status          model.TranslationStatus `json:"status" binding:"omitempty,oneof=draft published"`
+}
+
+// LocaleStatus 表示内容在某个语言下的翻译进度
+type LocaleStatus struct {
+	Locale         string                  `json:"locale"`
+	Status         model.TranslationStatus `json:"status"`
+	TranslatorName string                  `json:"translator_name,omitempty"`
+	UpdatedAt      *time.Time              `json:"updated_at,omitempty"`
+}
+
+// TranslationProgress 表示一条内容在所有语言下的翻译进度
+type TranslationProgress struct {
+	ContentID uint                `json:"content_id"`
+	Type      model.ContentType   `json:"type"`
+	Title     string              `json:"title"`
+	Slug      string              `json:"slug"`
+	Status    model.ContentStatus `json:"status"`
+	Locales   []LocaleStatus      `json:"locales"`
+}
+
+// TranslationProgressList 表示分页的翻译进度列表
+type TranslationProgressList struct {
+	Items    []*TranslationProgress `json:"items"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+}
+
+// NegotiateLocale 选择前台接口使用的语言：优先使用请求参数指定的语言，其次按 Accept-Language 的权重匹配，
+// 只匹配到语言（如 en 匹配 en-US）也视为匹配，都不支持时使用默认语言
+func (s *ContentService) NegotiateLocale(requested, acceptLanguage string) string {
+	if locale := matchLocale(s.locales, requested); locale != "" {
+		return locale
+	}
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if locale := matchLocale(s.locales, tag); locale != "" {
+			return locale
+		}
+	}
+	return s.defaultLocale
+}
+
+// ListTranslations 获取内容在除默认语言外所有支持语言下的翻译进度
+func (s *ContentService) ListTranslations(ctx context.Context, id uint) ([]LocaleStatus, error) {
+	content, err := s.getContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := s.translationRepo.ListByContent(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取译文失败", err)
+	}
+	return s.localeStatuses(content, translations), nil
+}
+
+// GetTranslation 获取内容在某个语言下的译文
+func (s *ContentService) GetTranslation(ctx context.Context, id uint, locale string) (*model.ContentTranslation, error) {
+	locale, err := s.translatableLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	translation, err := s.translationRepo.Get(ctx, id, locale)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("内容 %d 没有 %s 的译文", id, locale), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取译文失败", err)
+	}
+	return translation, nil
+}
+
+// SaveTranslation 保存内容在某个语言下的译文，并记录当前原文的摘要用于判断译文是否过期
+func (s *ContentService) SaveTranslation(ctx context.Context, editor *Editor, id uint, locale string, req *TranslationRequest) (*model.ContentTranslation, error) {
+	locale, err := s.translatableLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	content, err := s.getContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := normalizeLayout(req.Layout)
+	if err != nil {
+		return nil, err
+	}
+	if layout != nil && content.Type != model.ContentTypePage {
+		return nil, apperrors.NewBadRequest("只有页面支持区块布局", nil)
+	}
+
+	status := req.Status
+	if status == "" {
+		status = model.TranslationStatusDraft
+	}
+	translation := &model.ContentTranslation{
+		ContentID:       content.ID,
+		Locale:          locale,
+		Title:           req.Title,
+		Content:         req.Content,
+		Excerpt:         req.Excerpt,
+		Layout:          layout,
+		MetaTitle:       req.MetaTitle,
+		MetaKeywords:    req.MetaKeywords,
+		MetaDescription: req.MetaDescription,
+		Status:          status,
+		SourceHash:      sourceHash(content),
+		TranslatorID:    editor.ID,
+		TranslatorName:  editor.Name,
+	}
+	if err := s.translationRepo.Save(ctx, translation); err != nil {
+		return nil, apperrors.NewInternalServerError("保存译文失败", err)
+	}
+	return s.GetTranslation(ctx, id, locale)
+}
+
+// DeleteTranslation 删除内容在某个语言下的译文，删除后前台回退到默认语言的原文
+func (s *ContentService) DeleteTranslation(ctx context.Context, editor *Editor, id uint, locale string) error {
+	locale, err := s.translatableLocale(locale)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.translationRepo.Delete(ctx, id, locale)
+	if err != nil {
+		return apperrors.NewInternalServerError("删除译文失败", err)
+	}
+	if !deleted {
+		return apperrors.NewNotFound(fmt.Sprintf("内容 %d 没有 %s 的译文", id, locale), nil)
+	}
+	s.log.Info(ctx, "Content translation deleted",
+		zap.Uint("content_id", id),
+		zap.String("locale", locale),
+		zap.Uint("editor_id", editor.ID),
+	)
+	return nil
+}
+
+// TranslationProgress 分页获取内容的翻译进度，供编辑查看哪些内容还需要翻译
+func (s *ContentService) TranslationProgress(ctx context.Context, q *ContentQuery) (*TranslationProgressList, error) {
+	list, err := s.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(list.Items))
+	for i, content := range list.Items {
+		ids[i] = content.ID
+	}
+	byContent := make(map[uint][]*model.ContentTranslation, len(ids))
+	for _, locale := range s.locales[1:] {
+		translations, err := s.translationRepo.ListByLocale(ctx, ids, locale)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取译文失败", err)
+		}
+		for _, t := range translations {
+			byContent[t.ContentID] = append(byContent[t.ContentID], t)
+		}
+	}
+
+	progress := &TranslationProgressList{
+		Items:    make([]*TranslationProgress, 0, len(list.Items)),
+		Total:    list.Total,
+		Page:     list.Page,
+		PageSize: list.PageSize,
+	}
+	for _, content := range list.Items {
+		progress.Items = append(progress.Items, &TranslationProgress{
+			ContentID: content.ID,
+			Type:      content.Type,
+			Title:     content.Title,
+			Slug:      content.Slug,
+			Status:    content.Status,
+			Locales:   s.localeStatuses(content, byContent[content.ID]),
+		})
+	}
+	return progress, nil
+}
+
+// localize 使用 locale 下已发布的译文替换内容的标题、正文和 SEO 字段，没有译文的内容保持默认语言
+func (s *ContentService) localize(ctx context.Context, contents []*model.Content, locale string) error {
+	for _, content := range contents {
+		content.Locale = s.defaultLocale
+	}
+	if locale == "" || locale == s.defaultLocale || len(contents) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(contents))
+	for i, content := range contents {
+		ids[i] = content.ID
+	}
+	translations, err := s.translationRepo.ListByLocale(ctx, ids, locale)
+	if err != nil {
+		return apperrors.NewInternalServerError("获取译文失败", err)
+	}
+	published := make(map[uint]*model.ContentTranslation, len(translations))
+	for _, t := range translations {
+		if t.Status == model.TranslationStatusPublished {
+			published[t.ContentID] = t
+		}
+	}
+
+	for _, content := range contents {
+		t, ok := published[content.ID]
+		if !ok {
+			continue
+		}
+		content.Locale = t.Locale
+		content.Title = t.Title
+		content.Content = t.Content
+		content.Excerpt = t.Excerpt
+		content.MetaTitle = t.MetaTitle
+		content.MetaKeywords = t.MetaKeywords
+		content.MetaDescription = t.MetaDescription
+		if t.Layout != nil {
+			content.Layout = t.Layout
+		}
+	}
+	return nil
+}
+
+// localeStatuses 计算内容在除默认语言外所有支持语言下的翻译状态，已发布的译文在原文修改后标记为过期
+func (s *ContentService) localeStatuses(content *model.Content, translations []*model.ContentTranslation) []LocaleStatus {
+	byLocale := make(map[string]*model.ContentTranslation, len(translations))
+	for _, t := range translations {
+		byLocale[t.Locale] = t
+	}
+
+	hash := sourceHash(content)
+	statuses := make([]LocaleStatus, 0, len(s.locales)-1)
+	for _, locale := range s.locales[1:] {
+		t, ok := byLocale[locale]
+		if !ok {
+			statuses = append(statuses, LocaleStatus{Locale: locale, Status: model.TranslationStatusMissing})
+			continue
+		}
+		status := t.Status
+		if status == model.TranslationStatusPublished && t.SourceHash != hash {
+			status = model.TranslationStatusOutdated
+		}
+		updatedAt := t.UpdatedAt
+		statuses = append(statuses, LocaleStatus{
+			Locale:         locale,
+			Status:         status,
+			TranslatorName: t.TranslatorName,
+			UpdatedAt:      &updatedAt,
+		})
+	}
+	return statuses
+}
+
+// translatableLocale 校验可以保存译文的语言并返回其规范写法，默认语言的内容需要直接编辑原文
+func (s *ContentService) translatableLocale(locale string) (string, error) {
+	for _, supported := range s.locales {
+		if strings.EqualFold(locale, supported) {
+			if supported == s.defaultLocale {
+				return "", apperrors.NewBadRequest(fmt.Sprintf("%s 是默认语言，请直接编辑原文", supported), nil)
+			}
+			return supported, nil
+		}
+	}
+	return "", apperrors.NewBadRequest(fmt.Sprintf("不支持的语言 %s", locale), nil)
+}
+
+// supportedLocales 返回支持的语言列表，默认语言排在第一位，locales 中未包含默认语言时自动加入
+func supportedLocales(defaultLocale string, locales []string) []string {
+	supported := []string{defaultLocale}
+	for _, locale := range locales {
+		if !strings.EqualFold(locale, defaultLocale) {
+			supported = append(supported, locale)
+		}
+	}
+	return supported
+}
+
+// matchLocale 返回与语言标签匹配的支持语言，优先完全匹配，其次只匹配语言部分，不匹配时返回空字符串
+func matchLocale(locales []string, tag string) string {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" || tag == "*" {
+		return ""
+	}
+	for _, locale := range locales {
+		if strings.EqualFold(tag, locale) {
+			return locale
+		}
+	}
+	lang := strings.SplitN(tag, "-", 2)[0]
+	for _, locale := range locales {
+		if strings.EqualFold(lang, strings.SplitN(locale, "-", 2)[0]) {
+			return locale
+		}
+	}
+	return ""
+}
+
+// parseAcceptLanguage 解析 Accept-Language 请求头，按权重从高到低返回语言标签，忽略权重为 0 的标签
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// sourceHash 计算原文中需要翻译的字段的摘要
+func sourceHash(content *model.Content) string {
+	layout, _ := json.Marshal(content.Layout)
+	h := sha256.New()
+	for _, field := range []string{
+		content.Title,
+		content.Content,
+		content.Excerpt,
+		string(layout),
+		content.MetaTitle,
+		content.MetaKeywords,
+		content.MetaDescription,
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
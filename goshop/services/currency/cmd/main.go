@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/currency"
+	"github.com/yourusername/goshop/pkg/database"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/pkg/health"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/pkg/metrics"
+	"github.com/yourusername/goshop/pkg/scheduler"
+	"github.com/yourusername/goshop/pkg/shutdown"
+	"github.com/yourusername/goshop/pkg/tracing"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/currency/internal/event"
+	"github.com/yourusername/goshop/services/currency/internal/handler"
+	"github.com/yourusername/goshop/services/currency/internal/model"
+	"github.com/yourusername/goshop/services/currency/internal/provider"
+	"github.com/yourusername/goshop/services/currency/internal/repository"
+	"github.com/yourusername/goshop/services/currency/internal/service"
+	"github.com/yourusername/goshop/services/currency/rpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const serviceName = "currency"
+
+func main() {
+	// Load configuration and watch it for changes
+	watcher, err := config.Watch(serviceName, "")
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	cfg := watcher.Current()
+
+	// Initialize logger
+	log, err := logger.New(serviceName, cfg.Service.LogLevel, logger.WithConfig(cfg.Log, cfg.Service.Environment))
+	if err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	ctx := context.Background()
+	log.Info(ctx, "Starting currency service",
+		zap.String("environment", cfg.Service.Environment),
+		zap.Int("http_port", cfg.HTTP.Port),
+		zap.Int("grpc_port", cfg.GRPC.Port),
+	)
+
+	// Apply configuration changes at runtime, SIGHUP forces a reload and periodic
+	// refreshes pick up rotated secrets
+	watcher.OnError(func(err error) {
+		log.Error(ctx, "Failed to reload configuration", zap.Error(err))
+	})
+	watcher.OnChange(func(old, new *config.Config) {
+		if new.Service.LogLevel == old.Service.LogLevel {
+			return
+		}
+		if err := log.SetLevel(new.Service.LogLevel); err != nil {
+			log.Warn(ctx, "Invalid log level", zap.String("level", new.Service.LogLevel), zap.Error(err))
+			return
+		}
+		log.Info(ctx, "Log level changed", zap.String("level", new.Service.LogLevel))
+	})
+	watcher.ReloadOnSignal(syscall.SIGHUP)
+	watcher.RefreshSecrets(ctx, time.Duration(cfg.Secrets.CacheTTL)*time.Second)
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Trace, serviceName, cfg.Service.Environment)
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize tracing", zap.Error(err))
+	}
+
+	// Shutdown hooks are registered as resources are created and run in phases
+	// on SIGINT or SIGTERM
+	lc := shutdown.New(log)
+	lc.Add(shutdown.PhaseFlush, "tracing", 0, shutdownTracing)
+
+	// Initialize database
+	db, err := database.Open(cfg.Database, log, database.WithAutoMigrate(
+		&model.ExchangeRate{},
+	))
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize database", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseClose, "postgres", 0, shutdown.Close(func() error { return database.Close(db) }))
+
+	// Initialize NATS connection
+	nc, err := nats.Connect(cfg.NATS.URL, nats.Name(serviceName))
+	if err != nil {
+		log.Fatal(ctx, "Failed to connect to NATS", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseFlush, "nats", 0, shutdown.Close(nc.Drain))
+
+	// Publish rate updates to the CURRENCY stream
+	js, err := nc.JetStream()
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize JetStream", zap.Error(err))
+	}
+	if err := events.EnsureStream(js, events.DomainStream(event.RatesUpdated, time.Duration(cfg.NATS.StreamMaxAge)*time.Hour)); err != nil {
+		log.Fatal(ctx, "Failed to create currency event stream", zap.Error(err))
+	}
+
+	// Initialize the rate provider
+	base := currency.Code(cfg.Currency.Base)
+	var rateProvider provider.Provider
+	switch cfg.Currency.Provider {
+	case "openexchangerates":
+		rateProvider = provider.NewOpenExchangeRatesProvider(cfg.Currency.ProviderURL, cfg.Currency.APIKey)
+	default:
+		rateProvider = provider.NewStaticProvider(base, cfg.Currency.StaticRates)
+	}
+	currencies := make([]currency.Code, len(cfg.Currency.Currencies))
+	for i, code := range cfg.Currency.Currencies {
+		currencies[i] = currency.Code(code)
+	}
+
+	// Initialize repositories and services
+	rateRepo := repository.NewRateRepository(db)
+	publisher := events.NewPublisher(js, serviceName)
+	rateService := service.NewRateService(rateRepo, rateProvider, publisher, service.RateConfig{
+		Base:       base,
+		Currencies: currencies,
+		MaxAge:     time.Duration(cfg.Currency.MaxAge) * time.Hour,
+	}, log)
+
+	// Load the latest rates, fetching them when none were saved yet. A provider
+	// failure leaves the service running until the next scheduled refresh.
+	if err := rateService.Load(ctx); err != nil {
+		log.Error(ctx, "Failed to load exchange rates", zap.Error(err))
+	}
+
+	// Rates are refreshed by a scheduler job calling back this service, the
+	// scheduler may start after us so registration is retried in the background
+	jobCtx, stopJobs := context.WithCancel(ctx)
+	lc.Add(shutdown.PhaseFlush, "jobs", 0, shutdown.Func(stopJobs))
+	go registerJobs(jobCtx, scheduler.NewClient(cfg.Endpoints["scheduler"]), map[string]*scheduler.Job{
+		"currency.refresh-rates": {
+			Schedule: cfg.Currency.RefreshSchedule,
+			Service:  serviceName,
+			Path:     "/api/v1" + handler.RefreshJobPath,
+			Retry:    &scheduler.RetryPolicy{MaxAttempts: 5, BackoffSeconds: 300},
+		},
+	}, log)
+
+	// Initialize metrics
+	m := metrics.New(serviceName)
+	if err := m.RegisterDB(db, cfg.Database.DBName); err != nil {
+		log.Warn(ctx, "Failed to register database metrics", zap.Error(err))
+	}
+
+	// Initialize health checks
+	h := health.New()
+	lc.Add(shutdown.PhaseTraffic, "health", 0, shutdown.Func(h.Shutdown))
+	h.Add("postgres", health.Database(db))
+	h.Add("nats", health.NATS(nc))
+
+	// Initialize HTTP server
+	router := gin.Default()
+	router.Use(tracing.GinMiddleware(), m.GinMiddleware())
+	router.Use(apperrors.Middleware(log, cfg.Service.Environment, apperrors.WithTranslator(validator.ErrorTranslator)))
+	m.Register(router)
+	h.Register(router)
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.HTTP.Port),
+		Handler: router,
+	}
+	lc.Add(shutdown.PhaseServers, "http", 0, shutdown.HTTPServer(httpServer))
+
+	// Register HTTP routes
+	setupHTTPRoutes(router,
+		handler.NewCurrencyHandler(rateService),
+		handler.NewJobHandler(rateService, cfg.Scheduler.CallbackToken),
+	)
+
+	// Initialize gRPC server
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(grpcclient.UnaryServerInterceptor(), tracing.UnaryServerInterceptor(), m.UnaryServerInterceptor(), apperrors.UnaryServerInterceptor()))
+	// Register gRPC services
+	h.RegisterGRPC(grpcServer)
+	rpc.RegisterCurrencyServer(grpcServer, handler.NewGRPCHandler(rateService))
+	lc.Add(shutdown.PhaseServers, "grpc", 0, shutdown.GRPCServer(grpcServer))
+
+	// Start HTTP server
+	go func() {
+		log.Info(ctx, "Starting HTTP server", zap.Int("port", cfg.HTTP.Port))
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(ctx, "HTTP server failed", zap.Error(err))
+		}
+	}()
+
+	// Start gRPC server
+	go func() {
+		log.Info(ctx, "Starting gRPC server", zap.Int("port", cfg.GRPC.Port))
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+		if err != nil {
+			log.Fatal(ctx, "Failed to listen on gRPC port", zap.Error(err))
+		}
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatal(ctx, "gRPC server failed", zap.Error(err))
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown the service, failed hooks
+	// are logged by the coordinator
+	lc.Wait()
+}
+
+// Setup HTTP routes
+func setupHTTPRoutes(router *gin.Engine, currencyHandler *handler.CurrencyHandler, jobHandler *handler.JobHandler) {
+	api := router.Group("/api/v1")
+	currencyHandler.RegisterRoutes(api)
+	jobHandler.RegisterRoutes(api)
+}
+
+// registerJobs registers the jobs with the scheduler, retrying every 30 seconds
+// until all are registered or ctx is cancelled
+func registerJobs(ctx context.Context, client *scheduler.Client, jobs map[string]*scheduler.Job, log *logger.Logger) {
+	for name, job := range jobs {
+		for {
+			err := client.Register(ctx, name, job)
+			if err == nil {
+				break
+			}
+			log.Warn(ctx, "Failed to register job, retrying", zap.String("job", name), zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(30 * time.Second):
+			}
+		}
+	}
+	log.Info(ctx, "Registered jobs with the scheduler", zap.Int("jobs", len(jobs)))
+}
@@ -0,0 +1,15 @@
+package event
+
+import "time"
+
+// 货币服务发布的事件类型，商品服务据此换算展示价格，支付服务据此换算结算金额
+const (
+	RatesUpdated = "currency.rates_updated"
+)
+
+// RatesUpdatedEvent 是 currency.rates_updated 事件的数据，与 currency.Table 的 JSON 格式一致
+type RatesUpdatedEvent struct {
+	Base  string             `json:"base"`
+	Date  time.Time          `json:"date"`
+	Rates map[string]float64 `json:"rates"` // 一单位基准货币可兑换的各货币数量
+}
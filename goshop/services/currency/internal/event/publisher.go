@@ -0,0 +1,11 @@
+package event
+
+import "context"
+
+// EventVersion 是货币服务发布的事件数据的版本，数据不兼容地变更时递增
+const EventVersion = 1
+
+// Publisher 定义事件发布接口，由 events.Publisher 实现
+type Publisher interface {
+	Publish(ctx context.Context, eventType string, version int, data interface{}) error
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/currency/internal/service"
+)
+
+// CurrencyHandler 处理汇率查询、货币换算和金额格式化的 HTTP 请求
+type CurrencyHandler struct {
+	rateService *service.RateService
+}
+
+// NewCurrencyHandler 创建货币处理器
+func NewCurrencyHandler(rateService *service.RateService) *CurrencyHandler {
+	return &CurrencyHandler{
+		rateService: rateService,
+	}
+}
+
+// RegisterRoutes 注册货币路由
+func (h *CurrencyHandler) RegisterRoutes(api *gin.RouterGroup) {
+	currency := api.Group("/currency")
+	{
+		currency.GET("/rates", h.GetRates)
+		currency.GET("/rates/history", h.History)
+		currency.GET("/convert", h.Convert)
+		currency.GET("/format", h.Format)
+	}
+
+	admin := api.Group("/currency/admin")
+	{
+		admin.POST("/rates/refresh", h.Refresh)
+	}
+}
+
+// GetRates 获取当前汇率，stale 为 true 时汇率已超过配置的最大时长未更新
+func (h *CurrencyHandler) GetRates(c *gin.Context) {
+	rates, err := h.rateService.Rates(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rates})
+}
+
+// History 获取一种货币 from 到 to 日期每日的汇率，默认最近 30 天
+func (h *CurrencyHandler) History(c *gin.Context) {
+	code := c.Query("currency")
+	if code == "" {
+		respondError(c, apperrors.NewBadRequest("缺少 currency 参数", nil))
+		return
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, ok := parseDateQuery(c, "from", today.AddDate(0, 0, -30))
+	if !ok {
+		return
+	}
+	to, ok := parseDateQuery(c, "to", today)
+	if !ok {
+		return
+	}
+	rates, err := h.rateService.History(c.Request.Context(), code, from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rates})
+}
+
+// Convert 按当前汇率换算金额，例如 ?amount=99.90&from=CNY&to=USD
+func (h *CurrencyHandler) Convert(c *gin.Context) {
+	conversion, err := h.rateService.Convert(c.Request.Context(), c.Query("amount"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": conversion})
+}
+
+// Format 按语言格式化金额，例如 ?amount=1234.5&currency=EUR&locale=de-DE，
+// 未提供 locale 时使用 Accept-Language 请求头
+func (h *CurrencyHandler) Format(c *gin.Context) {
+	locale := c.Query("locale")
+	if locale == "" {
+		locale = acceptLanguage(c.GetHeader("Accept-Language"))
+	}
+	formatted, err := h.rateService.Format(c.Request.Context(), c.Query("amount"), c.Query("currency"), locale)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": formatted})
+}
+
+// Refresh 立即从提供方获取最新汇率
+func (h *CurrencyHandler) Refresh(c *gin.Context) {
+	table, err := h.rateService.Refresh(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": table})
+}
+
+// acceptLanguage 返回 Accept-Language 请求头中的第一个语言
+func acceptLanguage(header string) string {
+	for i, ch := range header {
+		if ch == ',' || ch == ';' {
+			return header[:i]
+		}
+	}
+	return header
+}
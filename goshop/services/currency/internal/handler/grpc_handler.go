@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/currency/internal/service"
+	"github.com/yourusername/goshop/services/currency/rpc"
+)
+
+// GRPCHandler 实现货币服务的 gRPC 接口，供商品和支付等服务调用
+type GRPCHandler struct {
+	rateService *service.RateService
+}
+
+// NewGRPCHandler 创建 gRPC 处理器
+func NewGRPCHandler(rateService *service.RateService) *GRPCHandler {
+	return &GRPCHandler{
+		rateService: rateService,
+	}
+}
+
+// GetRates 获取当前汇率
+func (h *GRPCHandler) GetRates(ctx context.Context, _ *rpc.GetRatesRequest) (*rpc.RatesReply, error) {
+	table, err := h.rateService.Rates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply := &rpc.RatesReply{
+		Base:  string(table.Base),
+		Date:  table.Date,
+		Rates: make(map[string]float64, len(table.Rates)),
+		Stale: table.Stale,
+	}
+	for code, rate := range table.Rates {
+		reply.Rates[string(code)] = rate
+	}
+	return reply, nil
+}
+
+// Convert 按当前汇率换算金额
+func (h *GRPCHandler) Convert(ctx context.Context, req *rpc.ConvertRequest) (*rpc.ConvertReply, error) {
+	conversion, err := h.rateService.Convert(ctx, req.Amount, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.ConvertReply{
+		From: conversion.From,
+		To:   conversion.To,
+		Rate: conversion.Rate,
+		Date: conversion.Date,
+	}, nil
+}
+
+// Format 按语言格式化金额
+func (h *GRPCHandler) Format(ctx context.Context, req *rpc.FormatRequest) (*rpc.FormatReply, error) {
+	formatted, err := h.rateService.Format(ctx, req.Amount, req.Currency, req.Locale)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.FormatReply{
+		Text:     formatted.Text,
+		Symbol:   formatted.Symbol,
+		Decimals: formatted.Decimals,
+	}, nil
+}
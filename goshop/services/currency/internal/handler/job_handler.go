@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/scheduler"
+	"github.com/yourusername/goshop/services/currency/internal/service"
+)
+
+// RefreshJobPath 是获取每日汇率任务回调相对于 /api/v1 的路径，注册任务时使用
+const RefreshJobPath = "/currency/internal/jobs/refresh"
+
+// JobHandler 处理调度服务的任务回调，不经过网关
+type JobHandler struct {
+	rateService *service.RateService
+	token       string
+}
+
+// NewJobHandler 创建任务回调处理器，token 为空时不校验回调密钥，只用于开发环境
+func NewJobHandler(rateService *service.RateService, token string) *JobHandler {
+	return &JobHandler{
+		rateService: rateService,
+		token:       token,
+	}
+}
+
+// RegisterRoutes 注册任务回调路由
+func (h *JobHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.POST(RefreshJobPath, scheduler.Callback(h.token, func(ctx context.Context, _ json.RawMessage) error {
+		_, err := h.rateService.Refresh(ctx)
+		return err
+	}))
+}
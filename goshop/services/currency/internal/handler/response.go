@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+)
+
+// respondError 中止请求，由错误中间件将错误转换为统一的 JSON 响应
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// parseDateQuery 解析日期查询参数（格式 2006-01-02，UTC），未提供时返回默认值
+func parseDateQuery(c *gin.Context, name string, def time.Time) (time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return def, true
+	}
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		respondError(c, apperrors.NewBadRequest("无效的 "+name, err))
+		return time.Time{}, false
+	}
+	return date, true
+}
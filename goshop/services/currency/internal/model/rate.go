@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// ExchangeRate 表示某日一单位基准货币可兑换的目标货币数量
+type ExchangeRate struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Base      string    `json:"base" gorm:"size:3;not null;uniqueIndex:idx_exchange_rate_day"`
+	Currency  string    `json:"currency" gorm:"size:3;not null;uniqueIndex:idx_exchange_rate_day"`
+	Date      time.Time `json:"date" gorm:"type:date;not null;uniqueIndex:idx_exchange_rate_day"` // 汇率日期（UTC）
+	Rate      float64   `json:"rate" gorm:"type:decimal(20,10);not null"`
+	Source    string    `json:"source" gorm:"size:50;not null"` // 汇率来源
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+)
+
+// Provider 定义汇率提供方接口
+type Provider interface {
+	// Name 返回提供方名称，记录为汇率来源
+	Name() string
+	// Latest 返回 base 对 currencies 的最新汇率
+	Latest(ctx context.Context, base currency.Code, currencies []currency.Code) (*currency.Table, error)
+}
+
+// StaticProvider 返回配置中的固定汇率，用于开发环境和未接入汇率提供方时
+type StaticProvider struct {
+	base  currency.Code
+	rates map[currency.Code]float64
+}
+
+// NewStaticProvider 创建固定汇率提供方，rates 为一单位 base 可兑换的各货币数量
+func NewStaticProvider(base currency.Code, rates map[string]float64) *StaticProvider {
+	normalized := make(map[currency.Code]float64, len(rates))
+	for code, rate := range rates {
+		normalized[currency.Code(strings.ToUpper(code))] = rate
+	}
+	return &StaticProvider{base: base, rates: normalized}
+}
+
+// Name 返回提供方名称
+func (p *StaticProvider) Name() string {
+	return "static"
+}
+
+// Latest 返回当日的固定汇率
+func (p *StaticProvider) Latest(ctx context.Context, base currency.Code, currencies []currency.Code) (*currency.Table, error) {
+	source := currency.Table{Base: p.base, Rates: p.rates}
+	return rebase(source, base, currencies, today())
+}
+
+// OpenExchangeRatesProvider 通过 Open Exchange Rates 的 latest 接口获取汇率
+type OpenExchangeRatesProvider struct {
+	url        string
+	appID      string
+	httpClient *http.Client
+}
+
+// NewOpenExchangeRatesProvider 创建 Open Exchange Rates 提供方
+func NewOpenExchangeRatesProvider(url, appID string) *OpenExchangeRatesProvider {
+	return &OpenExchangeRatesProvider{
+		url:        url,
+		appID:      appID,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name 返回提供方名称
+func (p *OpenExchangeRatesProvider) Name() string {
+	return "openexchangerates"
+}
+
+// Latest 获取最新汇率。免费套餐只支持以美元为基准，因此按返回的基准货币换算到 base
+func (p *OpenExchangeRatesProvider) Latest(ctx context.Context, base currency.Code, currencies []currency.Code) (*currency.Table, error) {
+	symbols := make([]string, 0, len(currencies)+1)
+	for _, code := range currencies {
+		symbols = append(symbols, string(code))
+	}
+	symbols = append(symbols, string(base))
+
+	query := url.Values{}
+	query.Set("app_id", p.appID)
+	query.Set("symbols", strings.Join(symbols, ","))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openexchangerates returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Timestamp int64              `json:"timestamp"`
+		Base      string             `json:"base"`
+		Rates     map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode openexchangerates response: %w", err)
+	}
+	source := currency.Table{Base: currency.Code(body.Base), Rates: make(map[currency.Code]float64, len(body.Rates))}
+	for code, rate := range body.Rates {
+		source.Rates[currency.Code(code)] = rate
+	}
+	date := today()
+	if body.Timestamp > 0 {
+		date = truncateDay(time.Unix(body.Timestamp, 0))
+	}
+	return rebase(source, base, currencies, date)
+}
+
+// rebase 将 source 的汇率换算为以 base 为基准，只保留 currencies
+func rebase(source currency.Table, base currency.Code, currencies []currency.Code, date time.Time) (*currency.Table, error) {
+	table := &currency.Table{Base: base, Date: date, Rates: make(map[currency.Code]float64, len(currencies))}
+	for _, code := range currencies {
+		if code == base {
+			continue
+		}
+		rate, err := source.Rate(base, code)
+		if err != nil {
+			return nil, err
+		}
+		table.Rates[code] = rate
+	}
+	return table, nil
+}
+
+func today() time.Time {
+	return truncateDay(time.Now())
+}
+
+// truncateDay 返回 t 所在的 UTC 日期
+func truncateDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
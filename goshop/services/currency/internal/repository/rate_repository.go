@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/currency/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RateRepository 定义汇率仓库接口
+type RateRepository interface {
+	Save(ctx context.Context, rates []*model.ExchangeRate) error
+	LatestDate(ctx context.Context, base string) (time.Time, error)
+	ListByDate(ctx context.Context, base string, date time.Time) ([]*model.ExchangeRate, error)
+	History(ctx context.Context, base, currency string, from, to time.Time) ([]*model.ExchangeRate, error)
+}
+
+// GormRateRepository 实现 RateRepository 接口的 GORM 仓库
+type GormRateRepository struct {
+	db *gorm.DB
+}
+
+// NewRateRepository 创建汇率仓库实例
+func NewRateRepository(db *gorm.DB) RateRepository {
+	return &GormRateRepository{
+		db: db,
+	}
+}
+
+// Save 保存一日的汇率，同一日重复获取时覆盖之前的汇率
+func (r *GormRateRepository) Save(ctx context.Context, rates []*model.ExchangeRate) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base"}, {Name: "currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).Create(&rates).Error
+}
+
+// LatestDate 返回最近一次保存汇率的日期，没有汇率时返回 gorm.ErrRecordNotFound
+func (r *GormRateRepository) LatestDate(ctx context.Context, base string) (time.Time, error) {
+	var rate model.ExchangeRate
+	err := r.db.WithContext(ctx).Where("base = ?", base).Order("date DESC").First(&rate).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+	return rate.Date, nil
+}
+
+// ListByDate 获取某日的全部汇率
+func (r *GormRateRepository) ListByDate(ctx context.Context, base string, date time.Time) ([]*model.ExchangeRate, error) {
+	var rates []*model.ExchangeRate
+	err := r.db.WithContext(ctx).Where("base = ? AND date = ?", base, date).Order("currency ASC").Find(&rates).Error
+	return rates, err
+}
+
+// History 按日期顺序获取一种货币在 [from, to] 期间的汇率
+func (r *GormRateRepository) History(ctx context.Context, base, currency string, from, to time.Time) ([]*model.ExchangeRate, error) {
+	var rates []*model.ExchangeRate
+	err := r.db.WithContext(ctx).
+		Where("base = ? AND currency = ? AND date BETWEEN ? AND ?", base, currency, from, to).
+		Order("date ASC").
+		Find(&rates).Error
+	return rates, err
+}
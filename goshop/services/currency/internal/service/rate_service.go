@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/currency/internal/event"
+	"github.com/yourusername/goshop/services/currency/internal/model"
+	"github.com/yourusername/goshop/services/currency/internal/provider"
+	"github.com/yourusername/goshop/services/currency/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxHistoryDays 是一次查询历史汇率的最大天数
+const maxHistoryDays = 366
+
+// RateConfig 表示汇率服务的配置
+type RateConfig struct {
+	Base       currency.Code
+	Currencies []currency.Code // 保存汇率的货币，包含基准货币
+	MaxAge     time.Duration   // 超过该时长未更新的汇率标记为过期
+}
+
+// RateTable 表示当前汇率，Stale 表示汇率已超过 MaxAge 未更新
+type RateTable struct {
+	currency.Table
+	Stale bool `json:"stale"`
+}
+
+// Conversion 表示一次货币换算的结果
+type Conversion struct {
+	From currency.Money `json:"from"`
+	To   currency.Money `json:"to"`
+	Rate float64        `json:"rate"` // 一单位 From 货币可兑换的 To 货币数量
+	Date time.Time      `json:"date"` // 使用的汇率日期
+}
+
+// Formatted 表示按语言格式化的金额
+type Formatted struct {
+	Amount   currency.Money `json:"amount"`
+	Locale   string         `json:"locale"`
+	Symbol   string         `json:"symbol"`
+	Text     string         `json:"text"`
+	Decimals int            `json:"decimals"` // 货币的小数位数
+}
+
+// RateService 从提供方获取每日汇率并缓存在内存中，提供货币换算和格式化，
+// 汇率更新后发布事件通知其他服务
+type RateService struct {
+	rateRepo  repository.RateRepository
+	provider  provider.Provider
+	publisher event.Publisher
+	cfg       RateConfig
+	rates     currency.LiveRates
+	log       *logger.Logger
+}
+
+// NewRateService 创建汇率服务
+func NewRateService(rateRepo repository.RateRepository, provider provider.Provider, publisher event.Publisher, cfg RateConfig, log *logger.Logger) *RateService {
+	return &RateService{
+		rateRepo:  rateRepo,
+		provider:  provider,
+		publisher: publisher,
+		cfg:       cfg,
+		log:       log,
+	}
+}
+
+// Load 将最近一日保存的汇率加载到缓存，还没有汇率时立即从提供方获取
+func (s *RateService) Load(ctx context.Context) error {
+	date, err := s.rateRepo.LatestDate(ctx, string(s.cfg.Base))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_, err = s.Refresh(ctx)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load exchange rates: %w", err)
+	}
+	rates, err := s.rateRepo.ListByDate(ctx, string(s.cfg.Base), date)
+	if err != nil {
+		return fmt.Errorf("failed to load exchange rates: %w", err)
+	}
+	table := currency.Table{Base: s.cfg.Base, Date: date, Rates: make(map[currency.Code]float64, len(rates))}
+	for _, rate := range rates {
+		table.Rates[currency.Code(rate.Currency)] = rate.Rate
+	}
+	s.rates.Set(table)
+	return nil
+}
+
+// Refresh 从提供方获取最新汇率，保存后更新缓存并发布 currency.rates_updated 事件。
+// 同一日重复获取时覆盖当日汇率，调度任务重试是安全的
+func (s *RateService) Refresh(ctx context.Context) (*currency.Table, error) {
+	table, err := s.provider.Latest(ctx, s.cfg.Base, s.cfg.Currencies)
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable("获取汇率失败", err)
+	}
+	rates := make([]*model.ExchangeRate, 0, len(table.Rates))
+	for code, rate := range table.Rates {
+		if rate <= 0 {
+			return nil, apperrors.NewServiceUnavailable(fmt.Sprintf("汇率提供方返回了无效的 %s 汇率", code), nil)
+		}
+		rates = append(rates, &model.ExchangeRate{
+			Base:     string(table.Base),
+			Currency: string(code),
+			Date:     table.Date,
+			Rate:     rate,
+			Source:   s.provider.Name(),
+		})
+	}
+	if err := s.rateRepo.Save(ctx, rates); err != nil {
+		return nil, apperrors.NewInternalServerError("保存汇率失败", err)
+	}
+	s.rates.Set(*table)
+
+	// 事件发布失败不影响本服务使用新汇率，其他服务在下次更新或重启时获取
+	if err := s.publisher.Publish(ctx, event.RatesUpdated, event.EventVersion, ratesUpdatedEvent(table)); err != nil {
+		s.log.Warn(ctx, "Failed to publish rates updated event", zap.Error(err))
+	}
+	s.log.Info(ctx, "Exchange rates updated",
+		zap.String("base", string(table.Base)),
+		zap.Time("date", table.Date),
+		zap.String("source", s.provider.Name()),
+	)
+	return table, nil
+}
+
+// Rates 返回缓存的当前汇率
+func (s *RateService) Rates(ctx context.Context) (*RateTable, error) {
+	table, ok := s.rates.Table()
+	if !ok {
+		return nil, apperrors.NewServiceUnavailable("汇率尚未加载", nil)
+	}
+	return &RateTable{Table: table, Stale: time.Since(table.Date) > s.cfg.MaxAge}, nil
+}
+
+// Convert 按当前汇率将 amount 从 from 货币换算为 to 货币，结果按 to 货币的小数位数四舍五入
+func (s *RateService) Convert(ctx context.Context, amount, from, to string) (*Conversion, error) {
+	fromCode, err := s.supported(from)
+	if err != nil {
+		return nil, err
+	}
+	toCode, err := s.supported(to)
+	if err != nil {
+		return nil, err
+	}
+	money, err := currency.Parse(amount, fromCode)
+	if err != nil {
+		return nil, apperrors.NewBadRequest("无效的金额", err)
+	}
+
+	table, err := s.Rates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := table.Rate(fromCode, toCode)
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable(fmt.Sprintf("没有 %s 到 %s 的汇率", fromCode, toCode), err)
+	}
+	converted, err := currency.Convert(money, toCode, table, currency.HalfUp)
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable(fmt.Sprintf("没有 %s 到 %s 的汇率", fromCode, toCode), err)
+	}
+	return &Conversion{From: money, To: converted, Rate: rate, Date: table.Date}, nil
+}
+
+// Format 按 locale 的货币符号、千分位和小数点格式化金额，不支持的语言使用 en-US 格式
+func (s *RateService) Format(ctx context.Context, amount, code, locale string) (*Formatted, error) {
+	c, err := s.supported(code)
+	if err != nil {
+		return nil, err
+	}
+	money, err := currency.Parse(amount, c)
+	if err != nil {
+		return nil, apperrors.NewBadRequest("无效的金额", err)
+	}
+	return &Formatted{
+		Amount:   money,
+		Locale:   locale,
+		Symbol:   currency.Symbol(c, locale),
+		Text:     currency.Format(money, locale),
+		Decimals: c.Digits(),
+	}, nil
+}
+
+// History 获取一种货币在 [from, to] 期间每日的汇率
+func (s *RateService) History(ctx context.Context, code string, from, to time.Time) ([]*model.ExchangeRate, error) {
+	c, err := s.supported(code)
+	if err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, apperrors.NewBadRequest("结束日期不能早于开始日期", nil)
+	}
+	if to.Sub(from) > maxHistoryDays*24*time.Hour {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("一次最多查询 %d 天的汇率", maxHistoryDays), nil)
+	}
+	rates, err := s.rateRepo.History(ctx, string(s.cfg.Base), string(c), from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取历史汇率失败", err)
+	}
+	return rates, nil
+}
+
+// supported 检查货币代码是否在配置的货币中
+func (s *RateService) supported(code string) (currency.Code, error) {
+	c := currency.Code(strings.ToUpper(strings.TrimSpace(code)))
+	for _, supported := range s.cfg.Currencies {
+		if c == supported {
+			return c, nil
+		}
+	}
+	return "", apperrors.NewBadRequest(fmt.Sprintf("不支持的货币 %s", code), nil)
+}
+
+func ratesUpdatedEvent(table *currency.Table) *event.RatesUpdatedEvent {
+	evt := &event.RatesUpdatedEvent{
+		Base:  string(table.Base),
+		Date:  table.Date,
+		Rates: make(map[string]float64, len(table.Rates)),
+	}
+	for code, rate := range table.Rates {
+		evt.Rates[string(code)] = rate
+	}
+	return evt
+}
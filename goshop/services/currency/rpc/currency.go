@@ -0,0 +1,153 @@
+// Package rpc defines the gRPC API of the currency service, shared by the
+// service and its clients. Messages are plain Go structs encoded with the JSON
+// codec of pkg/grpcclient rather than generated protobuf types.
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"google.golang.org/grpc"
+)
+
+// Full method names of the currency service
+const (
+	ServiceName    = "currency.CurrencyService"
+	GetRatesMethod = "/" + ServiceName + "/GetRates"
+	ConvertMethod  = "/" + ServiceName + "/Convert"
+	FormatMethod   = "/" + ServiceName + "/Format"
+)
+
+// GetRatesRequest requests the current exchange rates
+type GetRatesRequest struct{}
+
+// RatesReply holds the current exchange rates against the base currency
+type RatesReply struct {
+	Base  string             `json:"base"`
+	Date  time.Time          `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+	Stale bool               `json:"stale"` // the rates were not refreshed within the configured max age
+}
+
+// Table returns the rates as a currency.Table
+func (r *RatesReply) Table() currency.Table {
+	t := currency.Table{Base: currency.Code(r.Base), Date: r.Date, Rates: make(map[currency.Code]float64, len(r.Rates))}
+	for code, rate := range r.Rates {
+		t.Rates[currency.Code(code)] = rate
+	}
+	return t
+}
+
+// ConvertRequest converts Amount, a decimal string, from one currency to another
+type ConvertRequest struct {
+	Amount string `json:"amount"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// ConvertReply is the result of a conversion at the current rates
+type ConvertReply struct {
+	From currency.Money `json:"from"`
+	To   currency.Money `json:"to"`
+	Rate float64        `json:"rate"`
+	Date time.Time      `json:"date"` // date of the rates used
+}
+
+// FormatRequest formats Amount, a decimal string, in Currency for Locale
+type FormatRequest struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+	Locale   string `json:"locale"`
+}
+
+// FormatReply is an amount formatted for display
+type FormatReply struct {
+	Text     string `json:"text"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+}
+
+// CurrencyServer is the server API of the currency service
+type CurrencyServer interface {
+	GetRates(ctx context.Context, req *GetRatesRequest) (*RatesReply, error)
+	Convert(ctx context.Context, req *ConvertRequest) (*ConvertReply, error)
+	Format(ctx context.Context, req *FormatRequest) (*FormatReply, error)
+}
+
+// serviceDesc describes the currency service to the gRPC server
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*CurrencyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("GetRates", GetRatesMethod, CurrencyServer.GetRates),
+		unary("Convert", ConvertMethod, CurrencyServer.Convert),
+		unary("Format", FormatMethod, CurrencyServer.Format),
+	},
+}
+
+// RegisterCurrencyServer registers srv with the gRPC server s
+func RegisterCurrencyServer(s grpc.ServiceRegistrar, srv CurrencyServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// unary describes a unary method calling fn on the server, through the
+// interceptors of the server when it has some
+func unary[Req, Reply any](name, fullMethod string, fn func(CurrencyServer, context.Context, *Req) (*Reply, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(Req)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return fn(srv.(CurrencyServer), ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return fn(srv.(CurrencyServer), ctx, req.(*Req))
+			}
+			return interceptor(ctx, in, info, handler)
+		},
+	}
+}
+
+// Client calls the currency service
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient creates a client of the currency service on conn, usually
+// obtained from grpcclient.Factory.Conn("currency")
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// GetRates returns the current exchange rates
+func (c *Client) GetRates(ctx context.Context) (*RatesReply, error) {
+	out := new(RatesReply)
+	if err := c.conn.Invoke(ctx, GetRatesMethod, &GetRatesRequest{}, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Convert converts an amount at the current exchange rates
+func (c *Client) Convert(ctx context.Context, req *ConvertRequest) (*ConvertReply, error) {
+	out := new(ConvertReply)
+	if err := c.conn.Invoke(ctx, ConvertMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Format formats an amount for display in a locale
+func (c *Client) Format(ctx context.Context, req *FormatRequest) (*FormatReply, error) {
+	out := new(FormatReply)
+	if err := c.conn.Invoke(ctx, FormatMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
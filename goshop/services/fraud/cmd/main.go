@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
+	"github.com/yourusername/goshop/pkg/audit"
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/database"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/pkg/health"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/pkg/metrics"
+	"github.com/yourusername/goshop/pkg/shutdown"
+	"github.com/yourusername/goshop/pkg/tracing"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/fraud/internal/event"
+	"github.com/yourusername/goshop/services/fraud/internal/handler"
+	"github.com/yourusername/goshop/services/fraud/internal/model"
+	"github.com/yourusername/goshop/services/fraud/internal/repository"
+	"github.com/yourusername/goshop/services/fraud/internal/reputation"
+	"github.com/yourusername/goshop/services/fraud/internal/service"
+	"github.com/yourusername/goshop/services/fraud/rpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const serviceName = "fraud"
+
+func main() {
+	// Load configuration and watch it for changes
+	watcher, err := config.Watch(serviceName, "")
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	cfg := watcher.Current()
+
+	// Initialize logger
+	log, err := logger.New(serviceName, cfg.Service.LogLevel, logger.WithConfig(cfg.Log, cfg.Service.Environment))
+	if err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	ctx := context.Background()
+	log.Info(ctx, "Starting fraud service",
+		zap.String("environment", cfg.Service.Environment),
+		zap.Int("http_port", cfg.HTTP.Port),
+		zap.Int("grpc_port", cfg.GRPC.Port),
+	)
+
+	// Apply configuration changes at runtime, SIGHUP forces a reload and periodic
+	// refreshes pick up rotated secrets
+	watcher.OnError(func(err error) {
+		log.Error(ctx, "Failed to reload configuration", zap.Error(err))
+	})
+	watcher.OnChange(func(old, new *config.Config) {
+		if new.Service.LogLevel == old.Service.LogLevel {
+			return
+		}
+		if err := log.SetLevel(new.Service.LogLevel); err != nil {
+			log.Warn(ctx, "Invalid log level", zap.String("level", new.Service.LogLevel), zap.Error(err))
+			return
+		}
+		log.Info(ctx, "Log level changed", zap.String("level", new.Service.LogLevel))
+	})
+	watcher.ReloadOnSignal(syscall.SIGHUP)
+	watcher.RefreshSecrets(ctx, time.Duration(cfg.Secrets.CacheTTL)*time.Second)
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Trace, serviceName, cfg.Service.Environment)
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize tracing", zap.Error(err))
+	}
+
+	// Shutdown hooks are registered as resources are created and run in phases
+	// on SIGINT or SIGTERM
+	lc := shutdown.New(log)
+	lc.Add(shutdown.PhaseFlush, "tracing", 0, shutdownTracing)
+
+	// Initialize database
+	db, err := database.Open(cfg.Database, log, database.WithAutoMigrate(
+		&model.Assessment{},
+		&model.DenyListEntry{},
+	))
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize database", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseClose, "postgres", 0, shutdown.Close(func() error { return database.Close(db) }))
+
+	// Initialize NATS connection
+	nc, err := nats.Connect(cfg.NATS.URL, nats.Name(serviceName))
+	if err != nil {
+		log.Fatal(ctx, "Failed to connect to NATS", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseFlush, "nats", 0, shutdown.Close(nc.Drain))
+
+	// Publish admin actions to the audit service and fraud events to the
+	// FRAUD stream
+	js, err := nc.JetStream()
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize JetStream", zap.Error(err))
+	}
+	if err := audit.EnsureStream(js, time.Duration(cfg.Audit.StreamMaxAge)*time.Hour); err != nil {
+		log.Fatal(ctx, "Failed to create audit stream", zap.Error(err))
+	}
+	if err := events.EnsureStream(js, events.DomainStream(event.TransactionFlagged, time.Duration(cfg.NATS.StreamMaxAge)*time.Hour)); err != nil {
+		log.Fatal(ctx, "Failed to create fraud event stream", zap.Error(err))
+	}
+
+	// Look up IP reputation when a provider is configured
+	var checker reputation.Checker = reputation.NoopChecker{}
+	if cfg.Fraud.ReputationURL != "" {
+		checker = reputation.NewHTTPChecker(cfg.Fraud.ReputationURL, cfg.Fraud.ReputationAPIKey, time.Duration(cfg.Fraud.ReputationTimeout)*time.Millisecond)
+	}
+
+	// Initialize repositories and services
+	assessmentRepo := repository.NewAssessmentRepository(db)
+	denyListRepo := repository.NewDenyListRepository(db)
+
+	publisher := events.NewPublisher(js, serviceName)
+	scoreService := service.NewScoreService(assessmentRepo, denyListRepo, checker, publisher, service.ScoreConfig{
+		ReviewScore:           cfg.Fraud.ReviewScore,
+		BlockScore:            cfg.Fraud.BlockScore,
+		UserAttemptsPerHour:   cfg.Fraud.UserAttemptsPerHour,
+		IPAttemptsPerHour:     cfg.Fraud.IPAttemptsPerHour,
+		DeviceAttemptsPerHour: cfg.Fraud.DeviceAttemptsPerHour,
+		DeviceUsersPerDay:     cfg.Fraud.DeviceUsersPerDay,
+		HighAmount:            cfg.Fraud.HighAmount,
+		NewAccountAge:         time.Duration(cfg.Fraud.NewAccountHours) * time.Hour,
+		DisposableDomains:     cfg.Fraud.DisposableDomains,
+		ReputationTimeout:     time.Duration(cfg.Fraud.ReputationTimeout) * time.Millisecond,
+	}, log)
+	denyListService := service.NewDenyListService(denyListRepo)
+	reviewService := service.NewReviewService(assessmentRepo, denyListService, publisher, log)
+
+	// Initialize metrics
+	m := metrics.New(serviceName)
+	if err := m.RegisterDB(db, cfg.Database.DBName); err != nil {
+		log.Warn(ctx, "Failed to register database metrics", zap.Error(err))
+	}
+
+	// Initialize health checks
+	h := health.New()
+	lc.Add(shutdown.PhaseTraffic, "health", 0, shutdown.Func(h.Shutdown))
+	h.Add("postgres", health.Database(db))
+	h.Add("nats", health.NATS(nc))
+
+	// Initialize HTTP server
+	router := gin.Default()
+	router.Use(tracing.GinMiddleware(), m.GinMiddleware())
+	router.Use(apperrors.Middleware(log, cfg.Service.Environment, apperrors.WithTranslator(validator.ErrorTranslator)))
+	router.Use(audit.Middleware(audit.NewRecorder(js, serviceName), log))
+	m.Register(router)
+	h.Register(router)
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.HTTP.Port),
+		Handler: router,
+	}
+	lc.Add(shutdown.PhaseServers, "http", 0, shutdown.HTTPServer(httpServer))
+
+	// Register HTTP routes
+	setupHTTPRoutes(router, handler.NewAdminHandler(reviewService, denyListService))
+
+	// Initialize gRPC server
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(grpcclient.UnaryServerInterceptor(), tracing.UnaryServerInterceptor(), m.UnaryServerInterceptor(), apperrors.UnaryServerInterceptor()))
+	// Register gRPC services
+	h.RegisterGRPC(grpcServer)
+	rpc.RegisterFraudServer(grpcServer, handler.NewGRPCHandler(scoreService))
+	lc.Add(shutdown.PhaseServers, "grpc", 0, shutdown.GRPCServer(grpcServer))
+
+	// Start HTTP server
+	go func() {
+		log.Info(ctx, "Starting HTTP server", zap.Int("port", cfg.HTTP.Port))
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(ctx, "HTTP server failed", zap.Error(err))
+		}
+	}()
+
+	// Start gRPC server
+	go func() {
+		log.Info(ctx, "Starting gRPC server", zap.Int("port", cfg.GRPC.Port))
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+		if err != nil {
+			log.Fatal(ctx, "Failed to listen on gRPC port", zap.Error(err))
+		}
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatal(ctx, "gRPC server failed", zap.Error(err))
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown the service, failed hooks
+	// are logged by the coordinator
+	lc.Wait()
+}
+
+// Setup HTTP routes
+func setupHTTPRoutes(router *gin.Engine, adminHandler *handler.AdminHandler) {
+	api := router.Group("/api/v1")
+	adminHandler.RegisterRoutes(api)
+}
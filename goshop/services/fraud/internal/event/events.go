@@ -0,0 +1,24 @@
+package event
+
+// 风控服务发布的事件类型。通知服务据此提醒审核人员；订单和支付服务据此放行或取消等待审核的交易
+const (
+	TransactionFlagged = "fraud.transaction_flagged"
+	ReviewApproved     = "fraud.review_approved"
+	ReviewRejected     = "fraud.review_rejected"
+)
+
+// AssessmentEvent 是风控事件的数据，Decision 为评估结论，ReviewStatus 只在审核事件中提供
+type AssessmentEvent struct {
+	AssessmentID uint     `json:"assessment_id"`
+	Kind         string   `json:"kind"`
+	OrderID      uint     `json:"order_id,omitempty"`
+	OrderNumber  string   `json:"order_number,omitempty"`
+	PaymentID    uint     `json:"payment_id,omitempty"`
+	UserID       uint     `json:"user_id"`
+	Amount       float64  `json:"amount"`
+	Currency     string   `json:"currency"`
+	Score        int      `json:"score"`
+	Decision     string   `json:"decision"`
+	Reasons      []string `json:"reasons"` // 命中规则的代码
+	ReviewStatus string   `json:"review_status,omitempty"`
+}
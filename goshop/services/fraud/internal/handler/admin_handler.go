@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/fraud/internal/model"
+	"github.com/yourusername/goshop/services/fraud/internal/repository"
+	"github.com/yourusername/goshop/services/fraud/internal/service"
+)
+
+// AdminHandler 处理后台风险评估审核和黑名单管理的 HTTP 请求
+type AdminHandler struct {
+	reviewService   *service.ReviewService
+	denyListService *service.DenyListService
+}
+
+// NewAdminHandler 创建后台风控处理器
+func NewAdminHandler(reviewService *service.ReviewService, denyListService *service.DenyListService) *AdminHandler {
+	return &AdminHandler{
+		reviewService:   reviewService,
+		denyListService: denyListService,
+	}
+}
+
+// RegisterRoutes 注册后台风控路由
+func (h *AdminHandler) RegisterRoutes(api *gin.RouterGroup) {
+	admin := api.Group("/fraud/admin")
+	{
+		admin.GET("/assessments", h.ListAssessments)
+		admin.GET("/assessments/:id", h.GetAssessment)
+		admin.GET("/reviews", h.ListReviews)
+		admin.POST("/reviews/:id/approve", h.Approve)
+		admin.POST("/reviews/:id/reject", h.Reject)
+
+		admin.GET("/deny-list", h.ListDenyList)
+		admin.POST("/deny-list", h.AddDenyListEntry)
+		admin.DELETE("/deny-list/:id", h.RemoveDenyListEntry)
+	}
+}
+
+// ListAssessments 分页查询风险评估，可按 decision、review_status、user_id 和 order_id 过滤
+func (h *AdminHandler) ListAssessments(c *gin.Context) {
+	filter := repository.AssessmentFilter{
+		Decision:     c.Query("decision"),
+		ReviewStatus: c.Query("review_status"),
+		UserID:       parseUintQuery(c, "user_id"),
+		OrderID:      parseUintQuery(c, "order_id"),
+	}
+	list, err := h.reviewService.List(c.Request.Context(), filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetAssessment 获取风险评估及命中的规则
+func (h *AdminHandler) GetAssessment(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	assessment, err := h.reviewService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": assessment})
+}
+
+// ListReviews 分页获取等待人工审核的交易
+func (h *AdminHandler) ListReviews(c *gin.Context) {
+	filter := repository.AssessmentFilter{ReviewStatus: model.ReviewPending}
+	list, err := h.reviewService.List(c.Request.Context(), filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// Approve 放行等待审核的交易
+func (h *AdminHandler) Approve(c *gin.Context) {
+	h.review(c, h.reviewService.Approve)
+}
+
+// Reject 拒绝等待审核的交易，deny 为 true 时将交易的邮箱和设备加入黑名单
+func (h *AdminHandler) Reject(c *gin.Context) {
+	h.review(c, h.reviewService.Reject)
+}
+
+func (h *AdminHandler) review(c *gin.Context, decide func(ctx context.Context, id, reviewerID uint, req *service.ReviewRequest) (*model.Assessment, error)) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	reviewerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.ReviewRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	assessment, err := decide(c.Request.Context(), id, reviewerID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": assessment})
+}
+
+// ListDenyList 分页查询黑名单，可按 type 过滤
+func (h *AdminHandler) ListDenyList(c *gin.Context) {
+	list, err := h.denyListService.List(c.Request.Context(), c.Query("type"),
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// AddDenyListEntry 添加黑名单条目
+func (h *AdminHandler) AddDenyListEntry(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.DenyRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	entry, err := h.denyListService.Add(c.Request.Context(), adminID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": entry})
+}
+
+// RemoveDenyListEntry 删除黑名单条目
+func (h *AdminHandler) RemoveDenyListEntry(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.denyListService.Remove(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/fraud/internal/service"
+	"github.com/yourusername/goshop/services/fraud/rpc"
+)
+
+// GRPCHandler 实现风控服务的 gRPC 接口，供订单和支付服务在结算和支付时调用
+type GRPCHandler struct {
+	scoreService *service.ScoreService
+}
+
+// NewGRPCHandler 创建 gRPC 处理器
+func NewGRPCHandler(scoreService *service.ScoreService) *GRPCHandler {
+	return &GRPCHandler{
+		scoreService: scoreService,
+	}
+}
+
+// Score 评估一次结算或支付尝试
+func (h *GRPCHandler) Score(ctx context.Context, req *rpc.ScoreRequest) (*rpc.ScoreReply, error) {
+	assessment, err := h.scoreService.Score(ctx, &service.ScoreRequest{
+		Kind:             req.Kind,
+		OrderID:          req.OrderID,
+		OrderNumber:      req.OrderNumber,
+		PaymentID:        req.PaymentID,
+		UserID:           req.UserID,
+		Email:            req.Email,
+		IP:               req.IP,
+		DeviceID:         req.DeviceID,
+		UserAgent:        req.UserAgent,
+		Amount:           req.Amount,
+		Currency:         req.Currency,
+		AccountCreatedAt: req.AccountCreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	reply := &rpc.ScoreReply{
+		AssessmentID: assessment.ID,
+		Score:        assessment.Score,
+		Decision:     assessment.Decision,
+		Reasons:      make([]rpc.Reason, len(assessment.Reasons)),
+	}
+	for i, reason := range assessment.Reasons {
+		reply.Reasons[i] = rpc.Reason{Code: reason.Code, Score: reason.Score, Detail: reason.Detail}
+	}
+	return reply, nil
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 风险评估的交易类型
+const (
+	KindCheckout = "checkout" // 下单结算
+	KindPayment  = "payment"  // 发起支付
+)
+
+// 风险评估的结论
+const (
+	DecisionAllow  = "allow"  // 放行
+	DecisionReview = "review" // 暂缓处理，等待人工审核
+	DecisionBlock  = "block"  // 拒绝
+)
+
+// 人工审核状态，只有结论为 review 的评估需要审核
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// Reason 表示一条命中的风控规则
+type Reason struct {
+	Code   string `json:"code"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Reasons 是命中规则的列表，以 JSON 保存
+type Reasons []Reason
+
+// Value 实现 driver.Valuer 接口
+func (r Reasons) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (r *Reasons) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("类型断言为 []byte 失败")
+	}
+	return json.Unmarshal(b, r)
+}
+
+// Assessment 表示一次结算或支付尝试的风险评估。每次评估都会保存，用于计算后续评估的频率规则
+type Assessment struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	Kind        string  `json:"kind" gorm:"size:20;not null"`
+	OrderID     uint    `json:"order_id,omitempty" gorm:"index"`
+	OrderNumber string  `json:"order_number,omitempty" gorm:"size:50"`
+	PaymentID   uint    `json:"payment_id,omitempty" gorm:"index"`
+	UserID      uint    `json:"user_id" gorm:"index:idx_assessment_user_time"`
+	Email       string  `json:"email" gorm:"size:255"`
+	IP          string  `json:"ip" gorm:"size:45;index:idx_assessment_ip_time"`
+	DeviceID    string  `json:"device_id" gorm:"size:128;index:idx_assessment_device_time"` // 客户端生成的设备指纹
+	UserAgent   string  `json:"user_agent,omitempty" gorm:"size:500"`
+	Amount      float64 `json:"amount" gorm:"type:decimal(10,2);not null"`
+	Currency    string  `json:"currency" gorm:"size:3;not null"`
+
+	Score    int     `json:"score" gorm:"not null"`
+	Decision string  `json:"decision" gorm:"index;size:20;not null"`
+	Reasons  Reasons `json:"reasons" gorm:"type:jsonb"`
+
+	ReviewStatus string     `json:"review_status,omitempty" gorm:"index;size:20"`
+	ReviewedBy   *uint      `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote   string     `json:"review_note,omitempty" gorm:"size:1000"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_assessment_user_time;index:idx_assessment_ip_time;index:idx_assessment_device_time"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// 黑名单条目的类型
+const (
+	DenyEmail       = "email"
+	DenyEmailDomain = "email_domain"
+	DenyIP          = "ip"
+	DenyDevice      = "device"
+	DenyUser        = "user"
+)
+
+// DenyListEntry 表示黑名单中的一项。黑名单由结算和支付的评估共用，命中时直接拒绝；
+// 审核人员拒绝交易时可以同时将其邮箱和设备加入黑名单
+type DenyListEntry struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Type      string     `json:"type" gorm:"uniqueIndex:idx_deny_list_value;size:20;not null"`
+	Value     string     `json:"value" gorm:"uniqueIndex:idx_deny_list_value;size:255;not null"` // 邮箱、域名和 IP 保存为小写
+	Reason    string     `json:"reason" gorm:"size:500"`
+	CreatedBy uint       `json:"created_by"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // 为空时永久有效
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
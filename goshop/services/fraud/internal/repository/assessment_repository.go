@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/fraud/internal/model"
+	"gorm.io/gorm"
+)
+
+// AssessmentFilter 表示查询风险评估的过滤条件，零值字段不参与过滤
+type AssessmentFilter struct {
+	Decision     string
+	ReviewStatus string
+	UserID       uint
+	OrderID      uint
+}
+
+// AssessmentRepository 定义风险评估仓库接口
+type AssessmentRepository interface {
+	Create(ctx context.Context, assessment *model.Assessment) error
+	GetByID(ctx context.Context, id uint) (*model.Assessment, error)
+	Update(ctx context.Context, assessment *model.Assessment) error
+	List(ctx context.Context, filter AssessmentFilter, offset, limit int) ([]*model.Assessment, int64, error)
+	CountByUser(ctx context.Context, userID uint, since time.Time) (int64, error)
+	CountByIP(ctx context.Context, ip string, since time.Time) (int64, error)
+	CountByDevice(ctx context.Context, deviceID string, since time.Time) (int64, error)
+	CountDeviceUsers(ctx context.Context, deviceID string, since time.Time) (int64, error)
+}
+
+// GormAssessmentRepository 实现 AssessmentRepository 接口的 GORM 仓库
+type GormAssessmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAssessmentRepository 创建风险评估仓库实例
+func NewAssessmentRepository(db *gorm.DB) AssessmentRepository {
+	return &GormAssessmentRepository{
+		db: db,
+	}
+}
+
+// Create 保存风险评估
+func (r *GormAssessmentRepository) Create(ctx context.Context, assessment *model.Assessment) error {
+	return r.db.WithContext(ctx).Create(assessment).Error
+}
+
+// GetByID 根据 ID 获取风险评估
+func (r *GormAssessmentRepository) GetByID(ctx context.Context, id uint) (*model.Assessment, error) {
+	var assessment model.Assessment
+	if err := r.db.WithContext(ctx).First(&assessment, id).Error; err != nil {
+		return nil, err
+	}
+	return &assessment, nil
+}
+
+// Update 更新风险评估
+func (r *GormAssessmentRepository) Update(ctx context.Context, assessment *model.Assessment) error {
+	return r.db.WithContext(ctx).Save(assessment).Error
+}
+
+// List 分页查询风险评估，按创建时间倒序
+func (r *GormAssessmentRepository) List(ctx context.Context, filter AssessmentFilter, offset, limit int) ([]*model.Assessment, int64, error) {
+	var assessments []*model.Assessment
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Assessment{})
+	if filter.Decision != "" {
+		query = query.Where("decision = ?", filter.Decision)
+	}
+	if filter.ReviewStatus != "" {
+		query = query.Where("review_status = ?", filter.ReviewStatus)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.OrderID != 0 {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&assessments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return assessments, total, nil
+}
+
+// CountByUser 统计用户自 since 起的评估次数
+func (r *GormAssessmentRepository) CountByUser(ctx context.Context, userID uint, since time.Time) (int64, error) {
+	return r.count(ctx, "user_id = ? AND created_at >= ?", userID, since)
+}
+
+// CountByIP 统计 IP 地址自 since 起的评估次数
+func (r *GormAssessmentRepository) CountByIP(ctx context.Context, ip string, since time.Time) (int64, error) {
+	return r.count(ctx, "ip = ? AND created_at >= ?", ip, since)
+}
+
+// CountByDevice 统计设备自 since 起的评估次数
+func (r *GormAssessmentRepository) CountByDevice(ctx context.Context, deviceID string, since time.Time) (int64, error) {
+	return r.count(ctx, "device_id = ? AND created_at >= ?", deviceID, since)
+}
+
+// CountDeviceUsers 统计设备自 since 起出现过的不同用户数
+func (r *GormAssessmentRepository) CountDeviceUsers(ctx context.Context, deviceID string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Assessment{}).
+		Where("device_id = ? AND created_at >= ? AND user_id <> 0", deviceID, since).
+		Distinct("user_id").
+		Count(&count).Error
+	return count, err
+}
+
+func (r *GormAssessmentRepository) count(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Assessment{}).Where(query, args...).Count(&count).Error
+	return count, err
+}
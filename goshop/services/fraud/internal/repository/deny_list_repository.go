@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/fraud/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DenyKey 表示要在黑名单中查找的一个值
+type DenyKey struct {
+	Type  string
+	Value string
+}
+
+// DenyListRepository 定义黑名单仓库接口
+type DenyListRepository interface {
+	Save(ctx context.Context, entry *model.DenyListEntry) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, entryType string, offset, limit int) ([]*model.DenyListEntry, int64, error)
+	Match(ctx context.Context, keys []DenyKey, now time.Time) ([]*model.DenyListEntry, error)
+}
+
+// GormDenyListRepository 实现 DenyListRepository 接口的 GORM 仓库
+type GormDenyListRepository struct {
+	db *gorm.DB
+}
+
+// NewDenyListRepository 创建黑名单仓库实例
+func NewDenyListRepository(db *gorm.DB) DenyListRepository {
+	return &GormDenyListRepository{
+		db: db,
+	}
+}
+
+// Save 添加黑名单条目，条目已存在时更新原因和有效期
+func (r *GormDenyListRepository) Save(ctx context.Context, entry *model.DenyListEntry) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "type"}, {Name: "value"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "created_by", "expires_at", "updated_at"}),
+	}).Create(entry).Error
+}
+
+// Delete 删除黑名单条目，条目不存在时返回 gorm.ErrRecordNotFound
+func (r *GormDenyListRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&model.DenyListEntry{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// List 分页查询黑名单，entryType 为空时查询所有类型
+func (r *GormDenyListRepository) List(ctx context.Context, entryType string, offset, limit int) ([]*model.DenyListEntry, int64, error) {
+	var entries []*model.DenyListEntry
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.DenyListEntry{})
+	if entryType != "" {
+		query = query.Where("type = ?", entryType)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// Match 返回 keys 中在 now 时仍然有效的黑名单条目
+func (r *GormDenyListRepository) Match(ctx context.Context, keys []DenyKey, now time.Time) ([]*model.DenyListEntry, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	pairs := make([][]interface{}, len(keys))
+	for i, key := range keys {
+		pairs[i] = []interface{}{key.Type, key.Value}
+	}
+	var entries []*model.DenyListEntry
+	err := r.db.WithContext(ctx).
+		Where("(type, value) IN ?", pairs).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,86 @@
+// Package reputation 查询 IP 地址的信誉，识别代理、VPN、Tor 出口节点和机房 IP
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Reputation 表示 IP 地址的信誉
+type Reputation struct {
+	Proxy   bool `json:"proxy"`
+	VPN     bool `json:"vpn"`
+	Tor     bool `json:"tor"`
+	Hosting bool `json:"hosting"` // 机房或云服务器 IP
+	Risk    int  `json:"risk"`    // 提供方给出的 0-100 风险分
+}
+
+// Checker 查询 IP 地址的信誉
+type Checker interface {
+	Check(ctx context.Context, ip string) (*Reputation, error)
+}
+
+// NoopChecker 不查询外部服务，所有 IP 都没有风险，用于未配置信誉服务的环境
+type NoopChecker struct{}
+
+// Check 返回空的信誉
+func (NoopChecker) Check(ctx context.Context, ip string) (*Reputation, error) {
+	return &Reputation{}, nil
+}
+
+// HTTPChecker 通过 HTTP 接口查询 IP 信誉。请求为 GET {url}?ip={ip}&key={apiKey}，
+// 响应为 Reputation 的 JSON
+type HTTPChecker struct {
+	url        string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewHTTPChecker 创建 HTTP 信誉查询，timeout 为单次查询的超时时间
+func NewHTTPChecker(url, apiKey string, timeout time.Duration) *HTTPChecker {
+	return &HTTPChecker{
+		url:        url,
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Check 查询 IP 信誉，私有地址和回环地址不查询
+func (c *HTTPChecker) Check(ctx context.Context, ip string) (*Reputation, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return nil, fmt.Errorf("invalid IP address %q", ip)
+	}
+	if addr.IsPrivate() || addr.IsLoopback() {
+		return &Reputation{}, nil
+	}
+
+	query := url.Values{}
+	query.Set("ip", ip)
+	if c.apiKey != "" {
+		query.Set("key", c.apiKey)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reputation service returned status %d", resp.StatusCode)
+	}
+
+	var rep Reputation
+	if err := json.NewDecoder(resp.Body).Decode(&rep); err != nil {
+		return nil, fmt.Errorf("failed to decode reputation response: %w", err)
+	}
+	return &rep, nil
+}
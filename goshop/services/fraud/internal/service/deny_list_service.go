@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/fraud/internal/model"
+	"github.com/yourusername/goshop/services/fraud/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DenyRequest 表示添加黑名单条目的请求
+type DenyRequest struct {
+	Type      string     `json:"type" binding:"required,oneof=email email_domain ip device user"`
+	Value     string     `json:"value" binding:"required,max=255"`
+	Reason    string     `json:"reason" binding:"max=500"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// DenyListEntryList 表示分页的黑名单
+type DenyListEntryList struct {
+	Items    []*model.DenyListEntry `json:"items"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+}
+
+// DenyListService 负责管理结算和支付评估共用的黑名单
+type DenyListService struct {
+	denyListRepo repository.DenyListRepository
+}
+
+// NewDenyListService 创建黑名单服务
+func NewDenyListService(denyListRepo repository.DenyListRepository) *DenyListService {
+	return &DenyListService{
+		denyListRepo: denyListRepo,
+	}
+}
+
+// List 分页查询黑名单
+func (s *DenyListService) List(ctx context.Context, entryType string, page, pageSize int) (*DenyListEntryList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	entries, total, err := s.denyListRepo.List(ctx, entryType, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("查询黑名单失败", err)
+	}
+	return &DenyListEntryList{Items: entries, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Add 添加黑名单条目，值按类型规范化后保存，已存在时更新原因和有效期
+func (s *DenyListService) Add(ctx context.Context, createdBy uint, req *DenyRequest) (*model.DenyListEntry, error) {
+	value, err := normalizeDenyValue(req.Type, req.Value)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, apperrors.NewBadRequest("过期时间必须晚于当前时间", nil)
+	}
+	entry := &model.DenyListEntry{
+		Type:      req.Type,
+		Value:     value,
+		Reason:    req.Reason,
+		CreatedBy: createdBy,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.denyListRepo.Save(ctx, entry); err != nil {
+		return nil, apperrors.NewInternalServerError("保存黑名单失败", err)
+	}
+	return entry, nil
+}
+
+// Remove 删除黑名单条目
+func (s *DenyListService) Remove(ctx context.Context, id uint) error {
+	if err := s.denyListRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NewNotFound(fmt.Sprintf("黑名单条目 %d 不存在", id), err)
+		}
+		return apperrors.NewInternalServerError("删除黑名单失败", err)
+	}
+	return nil
+}
+
+// normalizeDenyValue 校验并规范化黑名单的值，使其与评估时查找的值一致
+func normalizeDenyValue(entryType, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch entryType {
+	case model.DenyEmail:
+		value = strings.ToLower(value)
+		if emailDomain(value) == "" {
+			return "", apperrors.NewBadRequest("无效的邮箱", nil)
+		}
+	case model.DenyEmailDomain:
+		value = strings.ToLower(strings.TrimPrefix(value, "@"))
+	case model.DenyIP:
+		ip, err := normalizeIP(value)
+		if err != nil {
+			return "", err
+		}
+		value = ip
+	case model.DenyUser:
+		if id, err := strconv.ParseUint(value, 10, 64); err != nil || id == 0 {
+			return "", apperrors.NewBadRequest("无效的用户 ID", err)
+		}
+	case model.DenyDevice:
+	default:
+		return "", apperrors.NewBadRequest(fmt.Sprintf("无效的黑名单类型 %s", entryType), nil)
+	}
+	if value == "" {
+		return "", apperrors.NewBadRequest("黑名单的值不能为空", nil)
+	}
+	return value, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/fraud/internal/event"
+	"github.com/yourusername/goshop/services/fraud/internal/model"
+	"github.com/yourusername/goshop/services/fraud/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AssessmentList 表示分页的风险评估列表
+type AssessmentList struct {
+	Items    []*model.Assessment `json:"items"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
+
+// ReviewRequest 表示审核人员对等待审核交易的处理
+type ReviewRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+	// Deny 只在拒绝时生效，将交易的邮箱和设备加入黑名单
+	Deny bool `json:"deny"`
+}
+
+// ReviewService 负责风险评估的查询和人工审核
+type ReviewService struct {
+	assessmentRepo  repository.AssessmentRepository
+	denyListService *DenyListService
+	publisher       event.Publisher
+	log             *logger.Logger
+}
+
+// NewReviewService 创建审核服务
+func NewReviewService(assessmentRepo repository.AssessmentRepository, denyListService *DenyListService, publisher event.Publisher, log *logger.Logger) *ReviewService {
+	return &ReviewService{
+		assessmentRepo:  assessmentRepo,
+		denyListService: denyListService,
+		publisher:       publisher,
+		log:             log,
+	}
+}
+
+// List 分页查询风险评估，审核队列使用 review_status=pending 过滤
+func (s *ReviewService) List(ctx context.Context, filter repository.AssessmentFilter, page, pageSize int) (*AssessmentList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	assessments, total, err := s.assessmentRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("查询风险评估失败", err)
+	}
+	return &AssessmentList{Items: assessments, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Get 获取风险评估
+func (s *ReviewService) Get(ctx context.Context, id uint) (*model.Assessment, error) {
+	assessment, err := s.assessmentRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("风险评估 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取风险评估失败", err)
+	}
+	return assessment, nil
+}
+
+// Approve 放行等待审核的交易
+func (s *ReviewService) Approve(ctx context.Context, id, reviewerID uint, req *ReviewRequest) (*model.Assessment, error) {
+	return s.decide(ctx, id, reviewerID, model.ReviewApproved, req)
+}
+
+// Reject 拒绝等待审核的交易，req.Deny 为 true 时将交易的邮箱和设备加入黑名单
+func (s *ReviewService) Reject(ctx context.Context, id, reviewerID uint, req *ReviewRequest) (*model.Assessment, error) {
+	return s.decide(ctx, id, reviewerID, model.ReviewRejected, req)
+}
+
+func (s *ReviewService) decide(ctx context.Context, id, reviewerID uint, status string, req *ReviewRequest) (*model.Assessment, error) {
+	assessment, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.ReviewStatus != model.ReviewPending {
+		return nil, apperrors.NewConflict("该交易不在审核队列中", nil)
+	}
+
+	now := time.Now()
+	assessment.ReviewStatus = status
+	assessment.ReviewedBy = &reviewerID
+	assessment.ReviewedAt = &now
+	assessment.ReviewNote = req.Note
+	if err := s.assessmentRepo.Update(ctx, assessment); err != nil {
+		return nil, apperrors.NewInternalServerError("保存审核结果失败", err)
+	}
+
+	if status == model.ReviewRejected && req.Deny {
+		reason := fmt.Sprintf("审核拒绝风险评估 %d", assessment.ID)
+		for _, entry := range []*DenyRequest{
+			{Type: model.DenyEmail, Value: assessment.Email, Reason: reason},
+			{Type: model.DenyDevice, Value: assessment.DeviceID, Reason: reason},
+		} {
+			if entry.Value == "" {
+				continue
+			}
+			if _, err := s.denyListService.Add(ctx, reviewerID, entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	eventType := event.ReviewApproved
+	if status == model.ReviewRejected {
+		eventType = event.ReviewRejected
+	}
+	if err := s.publisher.Publish(ctx, eventType, event.EventVersion, assessmentEvent(assessment)); err != nil {
+		s.log.Warn(ctx, "Failed to publish review event", zap.Uint("assessment_id", assessment.ID), zap.Error(err))
+	}
+	return assessment, nil
+}
+
+// normalizePage 规范化分页参数
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/fraud/internal/event"
+	"github.com/yourusername/goshop/services/fraud/internal/model"
+	"github.com/yourusername/goshop/services/fraud/internal/repository"
+	"github.com/yourusername/goshop/services/fraud/internal/reputation"
+	"go.uber.org/zap"
+)
+
+// 风控规则的代码和分值，评估分为命中规则的分值之和，最高 100
+const (
+	ReasonDenyList         = "deny_list"
+	ReasonUserVelocity     = "user_velocity"
+	ReasonIPVelocity       = "ip_velocity"
+	ReasonDeviceVelocity   = "device_velocity"
+	ReasonSharedDevice     = "shared_device"
+	ReasonMissingDevice    = "missing_device"
+	ReasonDisposableEmail  = "disposable_email"
+	ReasonTorIP            = "tor_ip"
+	ReasonProxyIP          = "proxy_ip"
+	ReasonHostingIP        = "hosting_ip"
+	ReasonRiskyIP          = "risky_ip"
+	ReasonNewAccountAmount = "new_account_high_amount"
+)
+
+var ruleScores = map[string]int{
+	ReasonDenyList:         100,
+	ReasonUserVelocity:     30,
+	ReasonIPVelocity:       25,
+	ReasonDeviceVelocity:   25,
+	ReasonSharedDevice:     30,
+	ReasonMissingDevice:    10,
+	ReasonDisposableEmail:  30,
+	ReasonTorIP:            50,
+	ReasonProxyIP:          30,
+	ReasonHostingIP:        15,
+	ReasonRiskyIP:          25,
+	ReasonNewAccountAmount: 20,
+}
+
+// riskyIPScore 是信誉服务风险分达到后命中 risky_ip 规则的分数
+const riskyIPScore = 75
+
+// ScoreConfig 表示风险评分的配置
+type ScoreConfig struct {
+	ReviewScore           int
+	BlockScore            int
+	UserAttemptsPerHour   int
+	IPAttemptsPerHour     int
+	DeviceAttemptsPerHour int
+	DeviceUsersPerDay     int
+	HighAmount            float64 // 默认货币的金额
+	NewAccountAge         time.Duration
+	DisposableDomains     []string
+	ReputationTimeout     time.Duration
+}
+
+// ScoreRequest 表示一次结算或支付尝试，由订单服务和支付服务通过 gRPC 提交
+type ScoreRequest struct {
+	Kind             string
+	OrderID          uint
+	OrderNumber      string
+	PaymentID        uint
+	UserID           uint
+	Email            string
+	IP               string
+	DeviceID         string // 客户端生成的设备指纹
+	UserAgent        string
+	Amount           float64
+	Currency         string
+	AccountCreatedAt *time.Time
+}
+
+// ScoreService 实时评估结算和支付尝试的风险，评估结果保存后用于频率规则和人工审核
+type ScoreService struct {
+	assessmentRepo repository.AssessmentRepository
+	denyListRepo   repository.DenyListRepository
+	checker        reputation.Checker
+	publisher      event.Publisher
+	cfg            ScoreConfig
+	disposable     map[string]bool
+	log            *logger.Logger
+}
+
+// NewScoreService 创建风险评分服务
+func NewScoreService(assessmentRepo repository.AssessmentRepository, denyListRepo repository.DenyListRepository, checker reputation.Checker, publisher event.Publisher, cfg ScoreConfig, log *logger.Logger) *ScoreService {
+	disposable := make(map[string]bool, len(cfg.DisposableDomains))
+	for _, domain := range cfg.DisposableDomains {
+		disposable[strings.ToLower(domain)] = true
+	}
+	return &ScoreService{
+		assessmentRepo: assessmentRepo,
+		denyListRepo:   denyListRepo,
+		checker:        checker,
+		publisher:      publisher,
+		cfg:            cfg,
+		disposable:     disposable,
+		log:            log,
+	}
+}
+
+// Score 评估一次结算或支付尝试。命中黑名单时直接拒绝；其余规则的分数之和达到审核分时
+// 等待人工审核，达到拒绝分时拒绝。信誉服务不可用时跳过 IP 信誉规则，不影响评估
+func (s *ScoreService) Score(ctx context.Context, req *ScoreRequest) (*model.Assessment, error) {
+	if req.Kind != model.KindCheckout && req.Kind != model.KindPayment {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("无效的交易类型 %s", req.Kind), nil)
+	}
+	if req.Amount < 0 {
+		return nil, apperrors.NewBadRequest("金额不能为负数", nil)
+	}
+	ip, err := normalizeIP(req.IP)
+	if err != nil {
+		return nil, err
+	}
+	assessment := &model.Assessment{
+		Kind:        req.Kind,
+		OrderID:     req.OrderID,
+		OrderNumber: req.OrderNumber,
+		PaymentID:   req.PaymentID,
+		UserID:      req.UserID,
+		Email:       strings.ToLower(strings.TrimSpace(req.Email)),
+		IP:          ip,
+		DeviceID:    strings.TrimSpace(req.DeviceID),
+		UserAgent:   truncate(req.UserAgent, 500),
+		Amount:      req.Amount,
+		Currency:    strings.ToUpper(req.Currency),
+	}
+	if assessment.Currency == "" {
+		assessment.Currency = string(currency.Default)
+	}
+
+	reasons, err := s.evaluate(ctx, assessment, req.AccountCreatedAt)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("风险评估失败", err)
+	}
+	assessment.Reasons = reasons
+	for _, reason := range reasons {
+		assessment.Score += reason.Score
+	}
+	if assessment.Score > 100 {
+		assessment.Score = 100
+	}
+	switch {
+	case assessment.Score >= s.cfg.BlockScore:
+		assessment.Decision = model.DecisionBlock
+	case assessment.Score >= s.cfg.ReviewScore:
+		assessment.Decision = model.DecisionReview
+		assessment.ReviewStatus = model.ReviewPending
+	default:
+		assessment.Decision = model.DecisionAllow
+	}
+
+	if err := s.assessmentRepo.Create(ctx, assessment); err != nil {
+		return nil, apperrors.NewInternalServerError("保存风险评估失败", err)
+	}
+	if assessment.Decision != model.DecisionAllow {
+		if err := s.publisher.Publish(ctx, event.TransactionFlagged, event.EventVersion, assessmentEvent(assessment)); err != nil {
+			s.log.Warn(ctx, "Failed to publish transaction flagged event", zap.Uint("assessment_id", assessment.ID), zap.Error(err))
+		}
+	}
+	return assessment, nil
+}
+
+// evaluate 依次执行风控规则，返回命中的规则
+func (s *ScoreService) evaluate(ctx context.Context, a *model.Assessment, accountCreatedAt *time.Time) (model.Reasons, error) {
+	var reasons model.Reasons
+	hit := func(code, detail string) {
+		reasons = append(reasons, model.Reason{Code: code, Score: ruleScores[code], Detail: detail})
+	}
+	now := time.Now()
+
+	// 黑名单
+	denied, err := s.denyListRepo.Match(ctx, denyKeys(a), now)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range denied {
+		hit(ReasonDenyList, fmt.Sprintf("%s %s", entry.Type, entry.Value))
+	}
+
+	// 频率规则，统计一小时内的尝试次数，不含本次
+	hour := now.Add(-time.Hour)
+	if a.UserID != 0 {
+		count, err := s.assessmentRepo.CountByUser(ctx, a.UserID, hour)
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(s.cfg.UserAttemptsPerHour) {
+			hit(ReasonUserVelocity, fmt.Sprintf("%d attempts of the user in the last hour", count))
+		}
+	}
+	if a.IP != "" {
+		count, err := s.assessmentRepo.CountByIP(ctx, a.IP, hour)
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(s.cfg.IPAttemptsPerHour) {
+			hit(ReasonIPVelocity, fmt.Sprintf("%d attempts from the IP in the last hour", count))
+		}
+	}
+
+	// 设备指纹
+	if a.DeviceID == "" {
+		hit(ReasonMissingDevice, "")
+	} else {
+		count, err := s.assessmentRepo.CountByDevice(ctx, a.DeviceID, hour)
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(s.cfg.DeviceAttemptsPerHour) {
+			hit(ReasonDeviceVelocity, fmt.Sprintf("%d attempts from the device in the last hour", count))
+		}
+		users, err := s.assessmentRepo.CountDeviceUsers(ctx, a.DeviceID, now.Add(-24*time.Hour))
+		if err != nil {
+			return nil, err
+		}
+		if users >= int64(s.cfg.DeviceUsersPerDay) {
+			hit(ReasonSharedDevice, fmt.Sprintf("%d users on the device in the last day", users))
+		}
+	}
+
+	// 一次性邮箱
+	if domain := emailDomain(a.Email); domain != "" && s.disposable[domain] {
+		hit(ReasonDisposableEmail, domain)
+	}
+
+	// IP 信誉
+	if a.IP != "" {
+		lookupCtx, cancel := context.WithTimeout(ctx, s.cfg.ReputationTimeout)
+		rep, err := s.checker.Check(lookupCtx, a.IP)
+		cancel()
+		if err != nil {
+			s.log.Warn(ctx, "IP reputation lookup failed, skipping", zap.String("ip", a.IP), zap.Error(err))
+		} else {
+			switch {
+			case rep.Tor:
+				hit(ReasonTorIP, "")
+			case rep.Proxy || rep.VPN:
+				hit(ReasonProxyIP, "")
+			case rep.Hosting:
+				hit(ReasonHostingIP, "")
+			}
+			if rep.Risk >= riskyIPScore {
+				hit(ReasonRiskyIP, fmt.Sprintf("risk %d", rep.Risk))
+			}
+		}
+	}
+
+	// 新账户的大额交易
+	if accountCreatedAt != nil && now.Sub(*accountCreatedAt) < s.cfg.NewAccountAge && a.Amount >= s.cfg.HighAmount {
+		hit(ReasonNewAccountAmount, "")
+	}
+	return reasons, nil
+}
+
+// denyKeys 返回评估中需要检查黑名单的值
+func denyKeys(a *model.Assessment) []repository.DenyKey {
+	var keys []repository.DenyKey
+	if a.Email != "" {
+		keys = append(keys, repository.DenyKey{Type: model.DenyEmail, Value: a.Email})
+		if domain := emailDomain(a.Email); domain != "" {
+			keys = append(keys, repository.DenyKey{Type: model.DenyEmailDomain, Value: domain})
+		}
+	}
+	if a.IP != "" {
+		keys = append(keys, repository.DenyKey{Type: model.DenyIP, Value: a.IP})
+	}
+	if a.DeviceID != "" {
+		keys = append(keys, repository.DenyKey{Type: model.DenyDevice, Value: a.DeviceID})
+	}
+	if a.UserID != 0 {
+		keys = append(keys, repository.DenyKey{Type: model.DenyUser, Value: fmt.Sprint(a.UserID)})
+	}
+	return keys
+}
+
+// normalizeIP 将 IP 地址转换为标准格式，未提供时返回空字符串
+func normalizeIP(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return "", apperrors.NewBadRequest(fmt.Sprintf("无效的 IP 地址 %s", raw), nil)
+	}
+	return ip.String(), nil
+}
+
+func emailDomain(email string) string {
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(domain)
+}
+
+func assessmentEvent(a *model.Assessment) *event.AssessmentEvent {
+	codes := make([]string, len(a.Reasons))
+	for i, reason := range a.Reasons {
+		codes[i] = reason.Code
+	}
+	return &event.AssessmentEvent{
+		AssessmentID: a.ID,
+		Kind:         a.Kind,
+		OrderID:      a.OrderID,
+		OrderNumber:  a.OrderNumber,
+		PaymentID:    a.PaymentID,
+		UserID:       a.UserID,
+		Amount:       a.Amount,
+		Currency:     a.Currency,
+		Score:        a.Score,
+		Decision:     a.Decision,
+		Reasons:      codes,
+		ReviewStatus: a.ReviewStatus,
+	}
+}
+
+// truncate 截断过长的字符串，截断处不完整的字符被丢弃
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "")
+}
@@ -0,0 +1,121 @@
+// Package rpc defines the gRPC API of the fraud service, shared by the service
+// and its clients. Messages are plain Go structs encoded with the JSON codec of
+// pkg/grpcclient rather than generated protobuf types.
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"google.golang.org/grpc"
+)
+
+// Full method names of the fraud service
+const (
+	ServiceName = "fraud.FraudService"
+	ScoreMethod = "/" + ServiceName + "/Score"
+)
+
+// Kinds of scored attempts
+const (
+	KindCheckout = "checkout"
+	KindPayment  = "payment"
+)
+
+// Decisions of an assessment. Callers proceed with allowed attempts, hold
+// attempts under review until the fraud.review_approved or
+// fraud.review_rejected event and refuse blocked ones.
+const (
+	DecisionAllow  = "allow"
+	DecisionReview = "review"
+	DecisionBlock  = "block"
+)
+
+// ScoreRequest describes a checkout or payment attempt
+type ScoreRequest struct {
+	Kind             string     `json:"kind"`
+	OrderID          uint       `json:"order_id,omitempty"`
+	OrderNumber      string     `json:"order_number,omitempty"`
+	PaymentID        uint       `json:"payment_id,omitempty"`
+	UserID           uint       `json:"user_id"`
+	Email            string     `json:"email"`
+	IP               string     `json:"ip"`
+	DeviceID         string     `json:"device_id"` // fingerprint computed by the client
+	UserAgent        string     `json:"user_agent,omitempty"`
+	Amount           float64    `json:"amount"`
+	Currency         string     `json:"currency"`
+	AccountCreatedAt *time.Time `json:"account_created_at,omitempty"`
+}
+
+// Reason is a rule matched by the attempt
+type Reason struct {
+	Code   string `json:"code"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ScoreReply is the assessment of an attempt, Score ranges from 0 to 100
+type ScoreReply struct {
+	AssessmentID uint     `json:"assessment_id"`
+	Score        int      `json:"score"`
+	Decision     string   `json:"decision"`
+	Reasons      []Reason `json:"reasons"`
+}
+
+// FraudServer is the server API of the fraud service
+type FraudServer interface {
+	Score(ctx context.Context, req *ScoreRequest) (*ScoreReply, error)
+}
+
+// serviceDesc describes the fraud service to the gRPC server
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*FraudServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Score",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(ScoreRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(FraudServer).Score(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: ScoreMethod}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(FraudServer).Score(ctx, req.(*ScoreRequest))
+				}
+				return interceptor(ctx, in, info, handler)
+			},
+		},
+	},
+}
+
+// RegisterFraudServer registers srv with the gRPC server s
+func RegisterFraudServer(s grpc.ServiceRegistrar, srv FraudServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// Client calls the fraud service
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient creates a client of the fraud service on conn, usually obtained
+// from grpcclient.Factory.Conn("fraud")
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// Score scores a checkout or payment attempt. Score is not idempotent, every
+// call is recorded and counts towards the velocity rules, so it must not be
+// hedged.
+func (c *Client) Score(ctx context.Context, req *ScoreRequest) (*ScoreReply, error) {
+	out := new(ScoreReply)
+	if err := c.conn.Invoke(ctx, ScoreMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
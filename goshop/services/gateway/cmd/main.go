@@ -5,28 +5,60 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"os/signal"
+	"reflect"
+	"sort"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+	"github.com/yourusername/goshop/pkg/audit"
+	"github.com/yourusername/goshop/pkg/breaker"
+	"github.com/yourusername/goshop/pkg/cache"
 	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/discovery"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/graphql"
+	"github.com/yourusername/goshop/pkg/graphql/federation"
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/pkg/health"
 	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/pkg/metrics"
+	"github.com/yourusername/goshop/pkg/shutdown"
+	"github.com/yourusername/goshop/pkg/tracing"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/gateway/internal/apikey"
+	"github.com/yourusername/goshop/services/gateway/internal/auditlog"
+	"github.com/yourusername/goshop/services/gateway/internal/compress"
+	"github.com/yourusername/goshop/services/gateway/internal/cors"
+	"github.com/yourusername/goshop/services/gateway/internal/ipfilter"
+	"github.com/yourusername/goshop/services/gateway/internal/proxy"
+	"github.com/yourusername/goshop/services/gateway/internal/rbac"
+	"github.com/yourusername/goshop/services/gateway/internal/responsecache"
+	"github.com/yourusername/goshop/services/gateway/internal/routes"
+	"github.com/yourusername/goshop/services/gateway/internal/transcode"
+	orderrpc "github.com/yourusername/goshop/services/order/rpc"
+	productrpc "github.com/yourusername/goshop/services/product/rpc"
+	userrpc "github.com/yourusername/goshop/services/user/rpc"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 const serviceName = "gateway"
 
 func main() {
-	// 加载配置
-	cfg, err := config.Load(serviceName, "")
+	// 加载配置并监听变更
+	watcher, err := config.Watch(serviceName, "")
 	if err != nil {
 		fmt.Printf("无法加载配置: %v\n", err)
 		os.Exit(1)
 	}
+	cfg := watcher.Current()
 
 	// 初始化日志
-	log, err := logger.New(serviceName, cfg.Service.LogLevel)
+	log, err := logger.New(serviceName, cfg.Service.LogLevel, logger.WithConfig(cfg.Log, cfg.Service.Environment))
 	if err != nil {
 		fmt.Printf("无法初始化日志: %v\n", err)
 		os.Exit(1)
@@ -38,23 +70,242 @@ func main() {
 		zap.Int("port", cfg.HTTP.Port),
 	)
 
-	// 初始化 Gin 路由
+	// 配置变更时动态调整日志级别，也可发送 SIGHUP 手动重新加载；定期刷新以获取轮换后的密钥
+	watcher.OnError(func(err error) {
+		log.Error(ctx, "重新加载配置失败", zap.Error(err))
+	})
+	watcher.OnChange(func(old, new *config.Config) {
+		if new.Service.LogLevel == old.Service.LogLevel {
+			return
+		}
+		if err := log.SetLevel(new.Service.LogLevel); err != nil {
+			log.Warn(ctx, "无效的日志级别", zap.String("level", new.Service.LogLevel), zap.Error(err))
+			return
+		}
+		log.Info(ctx, "日志级别已更新", zap.String("level", new.Service.LogLevel))
+	})
+	watcher.ReloadOnSignal(syscall.SIGHUP)
+	watcher.RefreshSecrets(ctx, time.Duration(cfg.Secrets.CacheTTL)*time.Second)
+
+	// 初始化链路追踪
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Trace, serviceName, cfg.Service.Environment)
+	if err != nil {
+		log.Fatal(ctx, "链路追踪初始化失败", zap.Error(err))
+	}
+
+	// 关闭钩子随资源创建注册，收到 SIGINT 或 SIGTERM 后分阶段执行
+	lc := shutdown.New(log)
+	lc.Add(shutdown.PhaseFlush, "tracing", 0, shutdownTracing)
+
+	// 连接 NATS：被拒绝的请求记录到审计流，启用响应缓存时接收缓存清除事件
+	nc, err := nats.Connect(cfg.NATS.URL, nats.Name(serviceName))
+	if err != nil {
+		log.Fatal(ctx, "连接 NATS 失败", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseFlush, "nats", 0, shutdown.Close(nc.Drain))
+	js, err := nc.JetStream()
+	if err != nil {
+		log.Fatal(ctx, "初始化 JetStream 失败", zap.Error(err))
+	}
+	if err := audit.EnsureStream(js, time.Duration(cfg.Audit.StreamMaxAge)*time.Hour); err != nil {
+		log.Fatal(ctx, "创建审计流失败", zap.Error(err))
+	}
+	recorder := audit.NewRecorder(js, serviceName)
+
+	// 初始化 Gin 路由，客户端 IP 只从受信任的负载均衡转发的 X-Forwarded-For 中获取
 	if cfg.Service.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	router := gin.Default()
+	if err := router.SetTrustedProxies(cfg.Gateway.TrustedProxies); err != nil {
+		log.Fatal(ctx, "无效的受信任代理", zap.Error(err))
+	}
+
+	// 指标采集，/metrics 由网关自身提供，不转发
+	m := metrics.New(serviceName)
+	router.Use(tracing.GinMiddleware(), m.GinMiddleware())
+	router.Use(apperrors.Middleware(log, cfg.Service.Environment, apperrors.WithTranslator(validator.ErrorTranslator)))
+	m.Register(router)
+
+	// 健康检查，网关自身不依赖存储，就绪探针仅在关闭时失败
+	h := health.New()
+	lc.Add(shutdown.PhaseTraffic, "health", 0, shutdown.Func(h.Shutdown))
+	h.Register(router)
+
+	// 设置全局中间件，跨域策略在配置变更后立即生效
+	corsPolicy := cors.New(cfg.Gateway.CORS)
+	watcher.OnChange(func(old, new *config.Config) {
+		if reflect.DeepEqual(old.Gateway.CORS, new.Gateway.CORS) {
+			return
+		}
+		corsPolicy.Update(new.Gateway.CORS)
+		log.Info(ctx, "跨域策略已更新", zap.Strings("origins", new.Gateway.CORS.AllowedOrigins))
+	})
+	ipFilter, err := ipfilter.New(cfg.Gateway.IPFilter, recorder, log)
+	if err != nil {
+		log.Fatal(ctx, "加载 IP 规则失败", zap.Error(err))
+	}
+	watcher.OnChange(func(old, new *config.Config) {
+		if reflect.DeepEqual(old.Gateway.IPFilter, new.Gateway.IPFilter) {
+			return
+		}
+		if err := ipFilter.Update(new.Gateway.IPFilter); err != nil {
+			log.Error(ctx, "更新 IP 规则失败，继续使用原规则", zap.Error(err))
+			return
+		}
+		log.Info(ctx, "IP 规则已更新", zap.Int("rules", len(new.Gateway.IPFilter.Rules)))
+	})
+	compressor := compress.New(cfg.Gateway.Compression)
+	watcher.OnChange(func(old, new *config.Config) {
+		if reflect.DeepEqual(old.Gateway.Compression, new.Gateway.Compression) {
+			return
+		}
+		compressor.Update(new.Gateway.Compression)
+		log.Info(ctx, "响应压缩配置已更新", zap.Bool("enabled", new.Gateway.Compression.Enabled))
+	})
+	auditor := auditlog.New(cfg.Gateway.RequestAudit, recorder, log)
+	watcher.OnChange(func(old, new *config.Config) {
+		if reflect.DeepEqual(old.Gateway.RequestAudit, new.Gateway.RequestAudit) {
+			return
+		}
+		auditor.Update(new.Gateway.RequestAudit)
+		log.Info(ctx, "请求审计规则已更新", zap.Int("rules", len(new.Gateway.RequestAudit.Rules)))
+	})
+	setupMiddlewares(router, corsPolicy, ipFilter, auditor, compressor, int64(cfg.Gateway.MaxBodySize))
+
+	// 服务发现：定期从注册中心刷新服务实例并探测健康状态，连续失败的实例不再转发请求
+	services := make([]string, 0, len(cfg.Endpoints))
+	static := make(discovery.StaticRegistry, len(cfg.Endpoints))
+	for name, endpoint := range cfg.Endpoints {
+		services = append(services, name)
+		static[name] = []string{endpoint}
+	}
+	sort.Strings(services)
+	var registry discovery.Registry = static
+	if cfg.Discovery.Provider == "consul" {
+		registry = discovery.NewConsul(cfg.Discovery.ConsulAddress, cfg.Discovery.ConsulToken, cfg.Discovery.Datacenter)
+	}
+	resolver := discovery.NewResolver(registry, services,
+		discovery.WithRefreshInterval(time.Duration(cfg.Discovery.RefreshInterval)*time.Second),
+		discovery.WithHealthCheck(cfg.Discovery.HealthPath, time.Duration(cfg.Discovery.HealthCheckInterval)*time.Second, cfg.Discovery.UnhealthyThreshold),
+	)
+	if err := resolver.Refresh(ctx); err != nil {
+		log.Warn(ctx, "获取服务实例失败", zap.Error(err))
+	}
+	discoveryCtx, stopDiscovery := context.WithCancel(ctx)
+	lc.Add(shutdown.PhaseFlush, "discovery", 0, shutdown.Func(stopDiscovery))
+	go resolver.Run(discoveryCtx, func(err error) {
+		log.Warn(ctx, "刷新服务实例失败", zap.Error(err))
+	})
 
-	// 设置全局中间件
-	setupMiddlewares(router)
+	// Redis 缓存用户的权限和启用时的响应，缓存不可用时直接查询认证服务和转发请求
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.RedisAddr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	lc.Add(shutdown.PhaseClose, "redis", 0, shutdown.Close(rdb.Close))
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		log.Warn(ctx, "连接 Redis 失败，缓存不可用时请求直接转发", zap.Error(err))
+	}
 
 	// 注册路由
-	setupRoutes(router)
+	// 响应缓存：启用后读接口的响应缓存在 Redis 中，相关 NATS 事件到达时清除
+	var responses *responsecache.Cache
+	if rcfg := cfg.Gateway.ResponseCache; rcfg.Enabled {
+		rules := make(map[string]responsecache.Rule, len(rcfg.Rules))
+		for name, rule := range rcfg.Rules {
+			rules[name] = responsecache.Rule{
+				TTL:          time.Duration(rule.TTL) * time.Second,
+				InvalidateOn: rule.InvalidateOn,
+			}
+		}
+		responses = responsecache.New(cache.New(rdb, serviceName), rules, rcfg.MaxEntrySize, log)
+		if err := responses.Subscribe(nc); err != nil {
+			log.Fatal(ctx, "订阅缓存清除事件失败", zap.Error(err))
+		}
+	}
+
+	// gRPC 转码：商品、用户和订单的查询接口直接调用服务的 gRPC 接口
+	clients := grpcclient.NewFactory(cfg.GRPC, log, grpcclient.WithDialOptions(grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor(), m.UnaryClientInterceptor())))
+	lc.Add(shutdown.PhaseClose, "grpc-clients", 0, shutdown.Func(clients.Close))
+
+	// 每个上游服务一个熔断器，连续失败达到阈值后在 openTimeout 内直接返回 503；
+	// 幂等请求失败时在全局重试预算内换一个实例重试
+	cb := cfg.Gateway.CircuitBreaker
+	upstream := proxy.New(resolver, proxy.Settings{
+		Breaker: breaker.Settings{
+			FailureThreshold: cb.FailureThreshold,
+			OpenTimeout:      time.Duration(cb.OpenTimeout) * time.Second,
+			HalfOpenRequests: cb.HalfOpenRequests,
+		},
+		Timeout:             time.Duration(cfg.Gateway.UpstreamTimeout) * time.Second,
+		MaxRetries:          cfg.Gateway.Retry.MaxRetries,
+		RetryBudget:         cfg.Gateway.Retry.Budget,
+		MinRetriesPerSecond: cfg.Gateway.Retry.MinRetriesPerSecond,
+	}, m, log)
+
+	// 角色和权限：用户的角色来自访问令牌和认证服务，权限由认证服务决定并与各服务共用缓存，规则在配置变更后立即生效
+	authz := rbac.New(cfg.Auth.JWTSecret, clients, rdb, time.Duration(cfg.Auth.PermissionCacheTTL)*time.Second, cfg.Gateway.RBAC, log)
+	watcher.OnChange(func(old, new *config.Config) {
+		if reflect.DeepEqual(old.Gateway.RBAC, new.Gateway.RBAC) {
+			return
+		}
+		authz.Update(new.Gateway.RBAC)
+		log.Info(ctx, "权限规则已更新", zap.Int("rules", len(new.Gateway.RBAC.Rules)))
+	})
+	router.Use(authz.Handler())
+	setupRoutes(router, upstream, transcode.New(clients), responses, authz)
+
+	// 后台路由组：/api/v1/admin/ 下的请求转发到各服务的后台接口，只接受后台角色并按用户限流
+	admin := routes.NewAdmin(upstream, authz, cfg.Gateway.Admin)
+	router.Any(routes.AdminPath, admin.Handle)
+	watcher.OnChange(func(old, new *config.Config) {
+		if reflect.DeepEqual(old.Gateway.Admin, new.Gateway.Admin) {
+			return
+		}
+		admin.Update(new.Gateway.Admin)
+		log.Info(ctx, "后台路由已更新", zap.Int("services", len(new.Gateway.Admin.Services)))
+	})
+
+	// 声明式路由表：配置中的路由在内置路由之外转发，配置文件变更后立即生效。
+	// 合作方通过签名的 API 密钥调用接受 API 密钥的路由，密钥由认证服务验证，同一签名只接受一次
+	apiKeys := apikey.New(clients, rdb, time.Duration(cfg.Gateway.APIKeys.SignatureWindow)*time.Second, log)
+	table := routes.New(upstream, authz, apiKeys)
+	if err := table.Update(cfg.Gateway.Routes); err != nil {
+		log.Fatal(ctx, "加载路由表失败", zap.Error(err))
+	}
+	router.NoRoute(table.Handle)
+	watcher.OnChange(func(old, new *config.Config) {
+		if reflect.DeepEqual(old.Gateway.Routes, new.Gateway.Routes) {
+			return
+		}
+		if err := table.Update(new.Gateway.Routes); err != nil {
+			log.Error(ctx, "更新路由表失败，继续使用原路由表", zap.Error(err))
+			return
+		}
+		log.Info(ctx, "路由表已更新", zap.Int("routes", table.Len()))
+	})
+
+	// GraphQL 联邦网关：定期拉取各子图的 schema 重新组合，子图发布新字段后无需重启网关
+	subgraphs := make([]federation.Subgraph, 0, len(cfg.GraphQL.Subgraphs))
+	for _, name := range cfg.GraphQL.Subgraphs {
+		subgraphs = append(subgraphs, federation.Subgraph{Name: name, URL: cfg.Endpoints[name] + cfg.GraphQL.Path})
+	}
+	graph := federation.New(subgraphs, federation.WithTimeout(time.Duration(cfg.GraphQL.Timeout)*time.Second))
+	graphCtx, stopGraph := context.WithCancel(ctx)
+	lc.Add(shutdown.PhaseFlush, "graphql", 0, shutdown.Func(stopGraph))
+	go graph.Run(graphCtx, time.Duration(cfg.GraphQL.RefreshInterval)*time.Second, func(err error) {
+		log.Warn(ctx, "组合 GraphQL 子图失败", zap.Error(err))
+	})
+	setupGraphQLRoutes(router, graph, authz)
 
 	// 创建 HTTP 服务器
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.HTTP.Port),
 		Handler: router,
 	}
+	lc.Add(shutdown.PhaseServers, "http", 0, shutdown.HTTPServer(server))
 
 	// 优雅启动服务器
 	go func() {
@@ -64,28 +315,14 @@ func main() {
 		}
 	}()
 
-	// 等待中断信号终止服务器
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Info(ctx, "接收到关闭信号")
-
-	// 优雅关闭服务器
-	log.Info(ctx, "正在关闭服务器")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatal(ctx, "服务器强制关闭", zap.Error(err))
-	}
-
-	log.Info(ctx, "服务器已成功关闭")
+	// 等待中断信号后优雅关闭，失败的钩子由协调器记录日志
+	lc.Wait()
 }
 
 // 设置中间件
-func setupMiddlewares(router *gin.Engine) {
+func setupMiddlewares(router *gin.Engine, corsPolicy *cors.Policy, ipFilter *ipfilter.Filter, auditor *auditlog.Auditor, compressor *compress.Compressor, maxBodySize int64) {
 	// 跨域设置
-	router.Use(corsMiddleware())
+	router.Use(corsPolicy.Handler())
 
 	// 安全中间件
 	router.Use(securityMiddleware())
@@ -93,23 +330,19 @@ func setupMiddlewares(router *gin.Engine) {
 	// 请求ID
 	router.Use(requestIDMiddleware())
 
-	// 其他中间件...
-}
+	// IP 和国家限制
+	router.Use(ipFilter.Handler())
 
-// CORS 中间件
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+	// 请求体大小限制
+	router.Use(bodyLimitMiddleware(maxBodySize))
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+	// 敏感路由的请求审计
+	router.Use(auditor.Handler())
 
-		c.Next()
-	}
+	// 响应压缩
+	router.Use(compressor.Handler())
+
+	// 其他中间件...
 }
 
 // 安全中间件
@@ -124,14 +357,21 @@ func securityMiddleware() gin.HandlerFunc {
 	}
 }
 
-// 请求ID中间件
+// 请求ID中间件：沿用客户端 traceparent 请求头中的链路，没有链路时生成新的链路 ID。
+// 链路 ID 记录到日志上下文，并通过 traceparent 请求头随转发的请求传给服务，各服务的日志可按链路 ID 关联。
+// 客户端没有携带有效的 X-Request-ID 时以链路 ID 作为请求ID
 func requestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 从请求头中获取 X-Request-ID
+		ctx := tracing.EnsureTrace(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		traceID := logger.GetTraceID(ctx)
+		if traceID != "" {
+			c.Writer.Header().Set(tracing.TraceIDHeader, traceID)
+		}
+
 		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			// 生成新的请求ID
-			requestID = fmt.Sprintf("%d", time.Now().UnixNano())
+		if !validRequestID(requestID) {
+			requestID = traceID
 		}
 
 		// 设置请求ID到上下文
@@ -142,45 +382,101 @@ func requestIDMiddleware() gin.HandlerFunc {
 	}
 }
 
-// 设置路由
-func setupRoutes(router *gin.Engine) {
-	// 健康检查
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "UP",
-		})
-	})
+// validRequestID 判断客户端携带的请求ID是否可以沿用：不超过 128 个字符，只包含字母、数字和 -_.:，
+// 避免请求头注入和超长日志字段
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// 请求体大小限制中间件：声明的长度超过限制的请求直接返回 413，未声明长度的请求体在读取超过限制时中止
+func bodyLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.Error(apperrors.NewPayloadTooLarge(fmt.Sprintf("请求体不能超过 %d 字节", maxBytes), nil))
+			c.Abort()
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+		c.Next()
+	}
+}
+
+// 设置路由，请求经 upstream 转发到服务实例或经 rpc 转码为 gRPC 调用，cached 按规则缓存读接口的响应
+func setupRoutes(router *gin.Engine, upstream *proxy.Proxy, rpc *transcode.Transcoder, responses *responsecache.Cache, authz *rbac.Authorizer) {
+	forwardToService := upstream.Forward
+	cached := responses.Handler
+	authMiddleware := authz.Authenticate
+	requirePermission := func(permission string) gin.HandlerFunc { return authz.Require(nil, permission) }
+	// 各服务的 /admin/ 接口自身不检查角色，只允许后台角色访问
+	backOffice := authz.Require([]string{"admin", "staff"}, "")
+	adminOnly := authz.Require([]string{"admin"}, "")
+	// Webhook 端点只能由入驻商家（认证服务在商家审核通过时分配 seller 角色）和后台人员管理
+	merchants := authz.Require([]string{"seller", "admin", "staff"}, "")
 
 	// API 版本路由
 	v1 := router.Group("/api/v1")
 	{
+		// 认证服务路由，刷新令牌和退出登录使用刷新令牌而不是访问令牌
+		authRoutes := v1.Group("/auth")
+		{
+			authRoutes.POST("/token/refresh", forwardToService("auth", "/api/v1/auth/token/refresh"))
+			authRoutes.POST("/logout", forwardToService("auth", "/api/v1/auth/logout"))
+		}
+
 		// 用户服务路由
 		userRoutes := v1.Group("/users")
 		{
 			userRoutes.POST("/register", forwardToService("user", "/api/v1/users/register"))
 			userRoutes.POST("/login", forwardToService("user", "/api/v1/users/login"))
+			userRoutes.GET("/verify-email", forwardToService("user", "/api/v1/users/verify-email"))
+			userRoutes.GET("/unlock", forwardToService("user", "/api/v1/users/unlock"))
+			userRoutes.POST("/me/verification-email", authMiddleware(), forwardToService("user", "/api/v1/users/me/verification-email"))
+			userRoutes.GET("/oauth/:provider/authorize", authz.Identify(), forwardToService("user", "/api/v1/users/oauth/:provider/authorize"))
+			userRoutes.GET("/oauth/:provider/callback", forwardToService("user", "/api/v1/users/oauth/:provider/callback"))
 			userRoutes.POST("/reset-password", forwardToService("user", "/api/v1/users/reset-password"))
-			userRoutes.GET("/me", authMiddleware(), forwardToService("user", "/api/v1/users/me"))
+			userRoutes.GET("/me", authMiddleware(), transcode.Unary[userrpc.GetUserRequest](rpc, "user", userrpc.GetUserMethod))
 			userRoutes.PUT("/me", authMiddleware(), forwardToService("user", "/api/v1/users/me"))
+			userRoutes.DELETE("/me", authMiddleware(), forwardToService("user", "/api/v1/users/me"))
+			userRoutes.PUT("/me/password", authMiddleware(), forwardToService("user", "/api/v1/users/me/password"))
 			userRoutes.GET("/me/addresses", authMiddleware(), forwardToService("user", "/api/v1/users/me/addresses"))
 			userRoutes.POST("/me/addresses", authMiddleware(), forwardToService("user", "/api/v1/users/me/addresses"))
+			userRoutes.PUT("/me/addresses/:id", authMiddleware(), forwardToService("user", "/api/v1/users/me/addresses/:id"))
+			userRoutes.DELETE("/me/addresses/:id", authMiddleware(), forwardToService("user", "/api/v1/users/me/addresses/:id"))
+			userRoutes.PUT("/me/addresses/:id/default", authMiddleware(), forwardToService("user", "/api/v1/users/me/addresses/:id/default"))
+			userRoutes.GET("/me/preferences", authMiddleware(), forwardToService("user", "/api/v1/users/me/preferences"))
+			userRoutes.PATCH("/me/preferences", authMiddleware(), forwardToService("user", "/api/v1/users/me/preferences"))
+			userRoutes.POST("/me/avatar", authMiddleware(), forwardToService("user", "/api/v1/users/me/avatar"))
+			userRoutes.GET("/me/coupons", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/users/me/coupons"))
+			userRoutes.GET("/me/points", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/users/me/points"))
 		}
 
 		// 商品服务路由
 		productRoutes := v1.Group("/products")
 		{
-			productRoutes.GET("", forwardToService("product", "/api/v1/products"))
-			productRoutes.GET("/:id", forwardToService("product", "/api/v1/products/:id"))
-			productRoutes.GET("/categories", forwardToService("product", "/api/v1/products/categories"))
-			productRoutes.GET("/search", forwardToService("product", "/api/v1/products/search"))
+			productRoutes.GET("", cached("products"), transcode.Unary[productrpc.ListProductsRequest](rpc, "product", productrpc.ListProductsMethod))
+			productRoutes.GET("/:id", cached("products"), transcode.Unary[productrpc.GetProductRequest](rpc, "product", productrpc.GetProductMethod))
+			productRoutes.GET("/categories", cached("products"), forwardToService("product", "/api/v1/products/categories"))
+			productRoutes.GET("/search", forwardToService("search", "/api/v1/search/products"))
 		}
 
 		// 订单与购物车服务路由
 		orderRoutes := v1.Group("/orders")
 		{
 			orderRoutes.POST("", authMiddleware(), forwardToService("order", "/api/v1/orders"))
-			orderRoutes.GET("", authMiddleware(), forwardToService("order", "/api/v1/orders"))
-			orderRoutes.GET("/:id", authMiddleware(), forwardToService("order", "/api/v1/orders/:id"))
+			orderRoutes.GET("", authMiddleware(), transcode.Unary[orderrpc.ListOrdersRequest](rpc, "order", orderrpc.ListOrdersMethod))
+			orderRoutes.GET("/:id", authMiddleware(), transcode.Unary[orderrpc.GetOrderRequest](rpc, "order", orderrpc.GetOrderMethod))
 		}
 
 		cartRoutes := v1.Group("/cart")
@@ -196,7 +492,15 @@ func setupRoutes(router *gin.Engine) {
 		{
 			paymentRoutes.POST("", authMiddleware(), forwardToService("payment", "/api/v1/payments"))
 			paymentRoutes.GET("/:id", authMiddleware(), forwardToService("payment", "/api/v1/payments/:id"))
-			paymentRoutes.POST("/:id/refund", authMiddleware(), forwardToService("payment", "/api/v1/payments/:id/refund"))
+			paymentRoutes.POST("/:id/refund", authMiddleware(), requirePermission("payments.refund"), forwardToService("payment", "/api/v1/payments/:id/refund"))
+		}
+
+		// 物流服务路由
+		shippingRoutes := v1.Group("/shipping")
+		{
+			shippingRoutes.POST("/rates/quote", authz.Identify(), forwardToService("shipping", "/api/v1/shipping/rates/quote"))
+			shippingRoutes.GET("/shipments", authMiddleware(), forwardToService("shipping", "/api/v1/shipping/shipments"))
+			shippingRoutes.GET("/shipments/:id", authMiddleware(), forwardToService("shipping", "/api/v1/shipping/shipments/:id"))
 		}
 
 		// 营销服务路由
@@ -204,45 +508,291 @@ func setupRoutes(router *gin.Engine) {
 		{
 			marketingRoutes.GET("/coupons", forwardToService("marketing", "/api/v1/marketing/coupons"))
 			marketingRoutes.POST("/coupons/validate", forwardToService("marketing", "/api/v1/marketing/coupons/validate"))
+			marketingRoutes.POST("/coupons/:id/claim", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/coupons/:id/claim"))
 			marketingRoutes.GET("/promotions", forwardToService("marketing", "/api/v1/marketing/promotions"))
+			marketingRoutes.GET("/prices", forwardToService("marketing", "/api/v1/marketing/prices"))
+			marketingRoutes.GET("/flash-sales", forwardToService("marketing", "/api/v1/marketing/flash-sales"))
+			marketingRoutes.GET("/flash-sales/:id", forwardToService("marketing", "/api/v1/marketing/flash-sales/:id"))
+			marketingRoutes.POST("/flash-sales/items/:id/token", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/flash-sales/items/:id/token"))
+			marketingRoutes.POST("/flash-sales/purchase", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/flash-sales/purchase"))
+			marketingRoutes.GET("/affiliates/r/:code", forwardToService("marketing", "/api/v1/marketing/affiliates/r/:code"))
+			marketingRoutes.POST("/affiliates/identify", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/affiliates/identify"))
+			marketingRoutes.POST("/affiliates", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/affiliates"))
+			marketingRoutes.GET("/affiliates/me", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/affiliates/me"))
+			marketingRoutes.GET("/affiliates/me/stats", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/affiliates/me/stats"))
+			marketingRoutes.GET("/affiliates/me/links", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/affiliates/me/links"))
+			marketingRoutes.POST("/affiliates/me/links", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/affiliates/me/links"))
+			marketingRoutes.GET("/affiliates/me/ledger", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/affiliates/me/ledger"))
+			marketingRoutes.GET("/affiliates/me/payouts", authMiddleware(), forwardToService("marketing", "/api/v1/marketing/affiliates/me/payouts"))
 		}
 
 		// 内容管理服务路由
 		cmsRoutes := v1.Group("/cms")
 		{
 			cmsRoutes.GET("/pages/:slug", forwardToService("cms", "/api/v1/cms/pages/:slug"))
+			cmsRoutes.GET("/pages/:slug/layout", forwardToService("cms", "/api/v1/cms/pages/:slug/layout"))
 			cmsRoutes.GET("/posts", forwardToService("cms", "/api/v1/cms/posts"))
 			cmsRoutes.GET("/posts/:slug", forwardToService("cms", "/api/v1/cms/posts/:slug"))
-			cmsRoutes.GET("/banners", forwardToService("cms", "/api/v1/cms/banners"))
+			cmsRoutes.GET("/posts/:slug/comments", forwardToService("cms", "/api/v1/cms/posts/:slug/comments"))
+			cmsRoutes.POST("/posts/:slug/comments", forwardToService("cms", "/api/v1/cms/posts/:slug/comments"))
+			cmsRoutes.GET("/banners", cached("banners"), forwardToService("cms", "/api/v1/cms/banners"))
+			cmsRoutes.POST("/banners/impressions", forwardToService("cms", "/api/v1/cms/banners/impressions"))
+			cmsRoutes.POST("/banners/:id/clicks", forwardToService("cms", "/api/v1/cms/banners/:id/clicks"))
+			cmsRoutes.GET("/menus/:location", forwardToService("cms", "/api/v1/cms/menus/:location"))
+			cmsRoutes.GET("/faqs", forwardToService("cms", "/api/v1/cms/faqs"))
+			cmsRoutes.GET("/faqs/:id", forwardToService("cms", "/api/v1/cms/faqs/:id"))
+			cmsRoutes.POST("/faqs/:id/feedback", forwardToService("cms", "/api/v1/cms/faqs/:id/feedback"))
+			cmsRoutes.GET("/admin/contents", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents"))
+			cmsRoutes.POST("/admin/contents", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents"))
+			cmsRoutes.GET("/admin/contents/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id"))
+			cmsRoutes.PUT("/admin/contents/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id"))
+			cmsRoutes.DELETE("/admin/contents/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id"))
+			cmsRoutes.POST("/admin/contents/:id/publish", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/publish"))
+			cmsRoutes.POST("/admin/contents/:id/archive", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/archive"))
+			cmsRoutes.POST("/admin/contents/:id/submit", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/submit"))
+			cmsRoutes.POST("/admin/contents/:id/approve", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/approve"))
+			cmsRoutes.POST("/admin/contents/:id/reject", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/reject"))
+			cmsRoutes.GET("/admin/contents/:id/reviews", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/reviews"))
+			cmsRoutes.GET("/admin/contents/:id/revisions", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/revisions"))
+			cmsRoutes.GET("/admin/contents/:id/revisions/:version", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/revisions/:version"))
+			cmsRoutes.POST("/admin/contents/:id/revisions/:version/restore", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/revisions/:version/restore"))
+			cmsRoutes.POST("/admin/layouts/validate", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/layouts/validate"))
+			cmsRoutes.GET("/admin/translations", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/translations"))
+			cmsRoutes.GET("/admin/workflow", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/workflow"))
+			cmsRoutes.GET("/admin/contents/:id/translations", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/translations"))
+			cmsRoutes.GET("/admin/contents/:id/translations/:locale", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/translations/:locale"))
+			cmsRoutes.PUT("/admin/contents/:id/translations/:locale", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/translations/:locale"))
+			cmsRoutes.DELETE("/admin/contents/:id/translations/:locale", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/contents/:id/translations/:locale"))
+			cmsRoutes.GET("/admin/banners", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/banners"))
+			cmsRoutes.POST("/admin/banners", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/banners"))
+			cmsRoutes.GET("/admin/banners/stats", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/banners/stats"))
+			cmsRoutes.GET("/admin/banners/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/banners/:id"))
+			cmsRoutes.PUT("/admin/banners/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/banners/:id"))
+			cmsRoutes.DELETE("/admin/banners/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/banners/:id"))
+			cmsRoutes.GET("/admin/banners/:id/stats", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/banners/:id/stats"))
+			cmsRoutes.GET("/admin/menus", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/menus"))
+			cmsRoutes.POST("/admin/menus", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/menus"))
+			cmsRoutes.GET("/admin/menus/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/menus/:id"))
+			cmsRoutes.PUT("/admin/menus/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/menus/:id"))
+			cmsRoutes.DELETE("/admin/menus/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/menus/:id"))
+			cmsRoutes.POST("/admin/menus/:id/items", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/menus/:id/items"))
+			cmsRoutes.PUT("/admin/menus/:id/items/order", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/menus/:id/items/order"))
+			cmsRoutes.PUT("/admin/menus/:id/items/:item_id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/menus/:id/items/:item_id"))
+			cmsRoutes.DELETE("/admin/menus/:id/items/:item_id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/menus/:id/items/:item_id"))
+			cmsRoutes.GET("/admin/comments", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/comments"))
+			cmsRoutes.POST("/admin/comments/:id/approve", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/comments/:id/approve"))
+			cmsRoutes.POST("/admin/comments/:id/reject", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/comments/:id/reject"))
+			cmsRoutes.DELETE("/admin/comments/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/comments/:id"))
+			cmsRoutes.GET("/admin/templates", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/templates"))
+			cmsRoutes.POST("/admin/templates", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/templates"))
+			cmsRoutes.GET("/admin/templates/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/templates/:id"))
+			cmsRoutes.PUT("/admin/templates/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/templates/:id"))
+			cmsRoutes.DELETE("/admin/templates/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/templates/:id"))
+			cmsRoutes.POST("/admin/templates/:id/preview", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/templates/:id/preview"))
+			cmsRoutes.GET("/admin/templates/:id/versions", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/templates/:id/versions"))
+			cmsRoutes.GET("/admin/templates/:id/versions/:version", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/templates/:id/versions/:version"))
+			cmsRoutes.POST("/admin/templates/:id/versions/:version/restore", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/templates/:id/versions/:version/restore"))
+			cmsRoutes.GET("/admin/faq-categories", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/faq-categories"))
+			cmsRoutes.POST("/admin/faq-categories", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/faq-categories"))
+			cmsRoutes.PUT("/admin/faq-categories/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/faq-categories/:id"))
+			cmsRoutes.DELETE("/admin/faq-categories/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/faq-categories/:id"))
+			cmsRoutes.GET("/admin/faqs", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/faqs"))
+			cmsRoutes.POST("/admin/faqs", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/faqs"))
+			cmsRoutes.GET("/admin/faqs/report", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/faqs/report"))
+			cmsRoutes.GET("/admin/faqs/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/faqs/:id"))
+			cmsRoutes.PUT("/admin/faqs/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/faqs/:id"))
+			cmsRoutes.DELETE("/admin/faqs/:id", authMiddleware(), forwardToService("cms", "/api/v1/cms/admin/faqs/:id"))
+		}
+
+		// Webhook 服务路由
+		webhookRoutes := v1.Group("/webhooks", authMiddleware(), merchants)
+		{
+			webhookRoutes.GET("/endpoints", forwardToService("webhook", "/api/v1/webhooks/endpoints"))
+			webhookRoutes.POST("/endpoints", forwardToService("webhook", "/api/v1/webhooks/endpoints"))
+			webhookRoutes.GET("/endpoints/:id", forwardToService("webhook", "/api/v1/webhooks/endpoints/:id"))
+			webhookRoutes.PUT("/endpoints/:id", forwardToService("webhook", "/api/v1/webhooks/endpoints/:id"))
+			webhookRoutes.DELETE("/endpoints/:id", forwardToService("webhook", "/api/v1/webhooks/endpoints/:id"))
+			webhookRoutes.POST("/endpoints/:id/rotate-secret", forwardToService("webhook", "/api/v1/webhooks/endpoints/:id/rotate-secret"))
+			webhookRoutes.GET("/deliveries", forwardToService("webhook", "/api/v1/webhooks/deliveries"))
+			webhookRoutes.GET("/deliveries/:id", forwardToService("webhook", "/api/v1/webhooks/deliveries/:id"))
+			webhookRoutes.POST("/deliveries/:id/redeliver", forwardToService("webhook", "/api/v1/webhooks/deliveries/:id/redeliver"))
 		}
+
+		// 评价服务路由
+		reviewRoutes := v1.Group("/reviews")
+		{
+			reviewRoutes.GET("/products/:id", forwardToService("review", "/api/v1/reviews/products/:id"))
+			reviewRoutes.GET("/products/:id/summary", forwardToService("review", "/api/v1/reviews/products/:id/summary"))
+			reviewRoutes.POST("", authMiddleware(), forwardToService("review", "/api/v1/reviews"))
+			reviewRoutes.GET("/me", authMiddleware(), forwardToService("review", "/api/v1/reviews/me"))
+			reviewRoutes.GET("/me/pending", authMiddleware(), forwardToService("review", "/api/v1/reviews/me/pending"))
+			reviewRoutes.PUT("/:id", authMiddleware(), forwardToService("review", "/api/v1/reviews/:id"))
+			reviewRoutes.DELETE("/:id", authMiddleware(), forwardToService("review", "/api/v1/reviews/:id"))
+			reviewRoutes.POST("/:id/reports", authMiddleware(), forwardToService("review", "/api/v1/reviews/:id/reports"))
+			reviewRoutes.GET("/admin/reviews", authMiddleware(), backOffice, forwardToService("review", "/api/v1/reviews/admin/reviews"))
+			reviewRoutes.PUT("/admin/reviews/:id/reply", authMiddleware(), backOffice, forwardToService("review", "/api/v1/reviews/admin/reviews/:id/reply"))
+			reviewRoutes.POST("/admin/reviews/:id/resolve", authMiddleware(), backOffice, forwardToService("review", "/api/v1/reviews/admin/reviews/:id/resolve"))
+			reviewRoutes.GET("/admin/reports", authMiddleware(), backOffice, forwardToService("review", "/api/v1/reviews/admin/reports"))
+		}
+
+		// 客服服务路由，收信回调由邮件服务商携带共享密钥调用，不经过用户认证
+		supportRoutes := v1.Group("/support")
+		{
+			supportRoutes.GET("/tickets", authMiddleware(), forwardToService("support", "/api/v1/support/tickets"))
+			supportRoutes.POST("/tickets", authMiddleware(), forwardToService("support", "/api/v1/support/tickets"))
+			supportRoutes.GET("/tickets/:id", authMiddleware(), forwardToService("support", "/api/v1/support/tickets/:id"))
+			supportRoutes.POST("/tickets/:id/messages", authMiddleware(), forwardToService("support", "/api/v1/support/tickets/:id/messages"))
+			supportRoutes.POST("/tickets/:id/close", authMiddleware(), forwardToService("support", "/api/v1/support/tickets/:id/close"))
+			supportRoutes.GET("/admin/tickets", authMiddleware(), backOffice, forwardToService("support", "/api/v1/support/admin/tickets"))
+			supportRoutes.GET("/admin/tickets/:id", authMiddleware(), backOffice, forwardToService("support", "/api/v1/support/admin/tickets/:id"))
+			supportRoutes.PUT("/admin/tickets/:id", authMiddleware(), backOffice, forwardToService("support", "/api/v1/support/admin/tickets/:id"))
+			supportRoutes.POST("/admin/tickets/:id/messages", authMiddleware(), backOffice, forwardToService("support", "/api/v1/support/admin/tickets/:id/messages"))
+			supportRoutes.POST("/admin/tickets/:id/assign", authMiddleware(), backOffice, forwardToService("support", "/api/v1/support/admin/tickets/:id/assign"))
+			supportRoutes.GET("/admin/canned-replies", authMiddleware(), backOffice, forwardToService("support", "/api/v1/support/admin/canned-replies"))
+			supportRoutes.POST("/admin/canned-replies", authMiddleware(), backOffice, forwardToService("support", "/api/v1/support/admin/canned-replies"))
+			supportRoutes.PUT("/admin/canned-replies/:id", authMiddleware(), backOffice, forwardToService("support", "/api/v1/support/admin/canned-replies/:id"))
+			supportRoutes.DELETE("/admin/canned-replies/:id", authMiddleware(), backOffice, forwardToService("support", "/api/v1/support/admin/canned-replies/:id"))
+			supportRoutes.POST("/inbound/email", forwardToService("support", "/api/v1/support/inbound/email"))
+		}
+
+		// 审计服务路由，审计记录只读，只允许管理员查看记录和修改保留策略
+		auditRoutes := v1.Group("/audit")
+		{
+			auditRoutes.GET("/admin/records", authMiddleware(), adminOnly, forwardToService("audit", "/api/v1/audit/admin/records"))
+			auditRoutes.GET("/admin/records/:id", authMiddleware(), adminOnly, forwardToService("audit", "/api/v1/audit/admin/records/:id"))
+			auditRoutes.GET("/admin/retention-policies", authMiddleware(), adminOnly, forwardToService("audit", "/api/v1/audit/admin/retention-policies"))
+			auditRoutes.POST("/admin/retention-policies", authMiddleware(), adminOnly, forwardToService("audit", "/api/v1/audit/admin/retention-policies"))
+			auditRoutes.PUT("/admin/retention-policies/:id", authMiddleware(), adminOnly, forwardToService("audit", "/api/v1/audit/admin/retention-policies/:id"))
+			auditRoutes.DELETE("/admin/retention-policies/:id", authMiddleware(), adminOnly, forwardToService("audit", "/api/v1/audit/admin/retention-policies/:id"))
+		}
+
+		// 调度服务路由，任务由各服务在内部注册，网关只开放管理接口
+		schedulerRoutes := v1.Group("/scheduler")
+		{
+			schedulerRoutes.GET("/admin/jobs", authMiddleware(), backOffice, forwardToService("scheduler", "/api/v1/scheduler/admin/jobs"))
+			schedulerRoutes.GET("/admin/jobs/:name", authMiddleware(), backOffice, forwardToService("scheduler", "/api/v1/scheduler/admin/jobs/:name"))
+			schedulerRoutes.POST("/admin/jobs/:name/pause", authMiddleware(), backOffice, forwardToService("scheduler", "/api/v1/scheduler/admin/jobs/:name/pause"))
+			schedulerRoutes.POST("/admin/jobs/:name/resume", authMiddleware(), backOffice, forwardToService("scheduler", "/api/v1/scheduler/admin/jobs/:name/resume"))
+			schedulerRoutes.POST("/admin/jobs/:name/trigger", authMiddleware(), backOffice, forwardToService("scheduler", "/api/v1/scheduler/admin/jobs/:name/trigger"))
+			schedulerRoutes.GET("/admin/jobs/:name/executions", authMiddleware(), backOffice, forwardToService("scheduler", "/api/v1/scheduler/admin/jobs/:name/executions"))
+			schedulerRoutes.GET("/admin/executions", authMiddleware(), backOffice, forwardToService("scheduler", "/api/v1/scheduler/admin/executions"))
+			schedulerRoutes.GET("/admin/executions/:id", authMiddleware(), backOffice, forwardToService("scheduler", "/api/v1/scheduler/admin/executions/:id"))
+		}
+
+		// 商家服务路由，店铺和商品归属的公开资料无需登录，结算和打款任务由调度服务在内部回调
+		sellerRoutes := v1.Group("/sellers")
+		{
+			sellerRoutes.POST("/apply", authMiddleware(), forwardToService("seller", "/api/v1/sellers/apply"))
+			sellerRoutes.GET("/shops/:id", forwardToService("seller", "/api/v1/sellers/shops/:id"))
+			sellerRoutes.GET("/products/:product_id", forwardToService("seller", "/api/v1/sellers/products/:product_id"))
+			sellerRoutes.GET("/me", authMiddleware(), forwardToService("seller", "/api/v1/sellers/me"))
+			sellerRoutes.PUT("/me", authMiddleware(), forwardToService("seller", "/api/v1/sellers/me"))
+			sellerRoutes.GET("/me/products", authMiddleware(), forwardToService("seller", "/api/v1/sellers/me/products"))
+			sellerRoutes.GET("/me/orders", authMiddleware(), forwardToService("seller", "/api/v1/sellers/me/orders"))
+			sellerRoutes.GET("/me/orders/:id", authMiddleware(), forwardToService("seller", "/api/v1/sellers/me/orders/:id"))
+			sellerRoutes.POST("/me/orders/:id/ship", authMiddleware(), forwardToService("seller", "/api/v1/sellers/me/orders/:id/ship"))
+			sellerRoutes.GET("/me/ledger", authMiddleware(), forwardToService("seller", "/api/v1/sellers/me/ledger"))
+			sellerRoutes.GET("/me/payouts", authMiddleware(), forwardToService("seller", "/api/v1/sellers/me/payouts"))
+			sellerRoutes.GET("/admin/sellers", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/sellers"))
+			sellerRoutes.GET("/admin/sellers/:id", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/sellers/:id"))
+			sellerRoutes.POST("/admin/sellers/:id/approve", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/sellers/:id/approve"))
+			sellerRoutes.POST("/admin/sellers/:id/reject", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/sellers/:id/reject"))
+			sellerRoutes.POST("/admin/sellers/:id/suspend", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/sellers/:id/suspend"))
+			sellerRoutes.PUT("/admin/sellers/:id/commission", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/sellers/:id/commission"))
+			sellerRoutes.GET("/admin/sellers/:id/ledger", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/sellers/:id/ledger"))
+			sellerRoutes.POST("/admin/sellers/:id/adjustments", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/sellers/:id/adjustments"))
+			sellerRoutes.PUT("/admin/products/:product_id", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/products/:product_id"))
+			sellerRoutes.DELETE("/admin/products/:product_id", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/products/:product_id"))
+			sellerRoutes.GET("/admin/commission-rules", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/commission-rules"))
+			sellerRoutes.PUT("/admin/commission-rules", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/commission-rules"))
+			sellerRoutes.DELETE("/admin/commission-rules/:category_id", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/commission-rules/:category_id"))
+			sellerRoutes.GET("/admin/orders", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/orders"))
+			sellerRoutes.GET("/admin/orders/:id", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/orders/:id"))
+			sellerRoutes.POST("/admin/orders/:id/deliver", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/orders/:id/deliver"))
+			sellerRoutes.GET("/admin/payouts", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/payouts"))
+			sellerRoutes.POST("/admin/payouts/:id/paid", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/payouts/:id/paid"))
+			sellerRoutes.POST("/admin/payouts/:id/failed", authMiddleware(), backOffice, forwardToService("seller", "/api/v1/sellers/admin/payouts/:id/failed"))
+		}
+
+		// 货币服务路由
+		currencyRoutes := v1.Group("/currency")
+		{
+			currencyRoutes.GET("/rates", forwardToService("currency", "/api/v1/currency/rates"))
+			currencyRoutes.GET("/rates/history", forwardToService("currency", "/api/v1/currency/rates/history"))
+			currencyRoutes.GET("/convert", forwardToService("currency", "/api/v1/currency/convert"))
+			currencyRoutes.GET("/format", forwardToService("currency", "/api/v1/currency/format"))
+			currencyRoutes.POST("/admin/rates/refresh", authMiddleware(), backOffice, forwardToService("currency", "/api/v1/currency/admin/rates/refresh"))
+		}
+
+		// 风控服务路由，只开放后台审核和黑名单管理，评分接口通过 gRPC 供订单和支付服务调用
+		fraudRoutes := v1.Group("/fraud/admin", authMiddleware(), backOffice)
+		{
+			fraudRoutes.GET("/assessments", forwardToService("fraud", "/api/v1/fraud/admin/assessments"))
+			fraudRoutes.GET("/assessments/:id", forwardToService("fraud", "/api/v1/fraud/admin/assessments/:id"))
+			fraudRoutes.GET("/reviews", forwardToService("fraud", "/api/v1/fraud/admin/reviews"))
+			fraudRoutes.POST("/reviews/:id/approve", forwardToService("fraud", "/api/v1/fraud/admin/reviews/:id/approve"))
+			fraudRoutes.POST("/reviews/:id/reject", forwardToService("fraud", "/api/v1/fraud/admin/reviews/:id/reject"))
+			fraudRoutes.GET("/deny-list", forwardToService("fraud", "/api/v1/fraud/admin/deny-list"))
+			fraudRoutes.POST("/deny-list", forwardToService("fraud", "/api/v1/fraud/admin/deny-list"))
+			fraudRoutes.DELETE("/deny-list/:id", forwardToService("fraud", "/api/v1/fraud/admin/deny-list/:id"))
+		}
+	}
+
+	// WebSocket 路由，升级后的连接由网关双向转发，用户身份与 HTTP 请求一样通过 X-User-ID 传给服务
+	wsRoutes := router.Group("/ws", websocketTokenMiddleware(), authMiddleware())
+	{
+		wsRoutes.GET("/notifications", upstream.WebSocket("notification", "/ws/notifications"))
+		wsRoutes.GET("/order-status", upstream.WebSocket("order", "/ws/order-status"))
 	}
 }
 
-// 转发请求到对应服务
-func forwardToService(service, path string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// 在实际实现中，这里应使用反向代理将请求转发到对应微服务
-		// 这里只是一个简化的示例
-		c.JSON(http.StatusOK, gin.H{
-			"message": fmt.Sprintf("请求已转发到 %s 服务，路径为 %s", service, path),
-		})
+// 设置 GraphQL 路由，客户端通过一个端点查询所有子图并在服务端完成跨服务关联。
+// 店面通过 /graphql 一次请求获取商品、分类、购物车、订单和促销，/api/v1/graphql 保留给已有的客户端
+func setupGraphQLRoutes(router *gin.Engine, graph *federation.Router, authz *rbac.Authorizer) {
+	for _, path := range []string{"/graphql", "/api/v1/graphql"} {
+		graphqlRoutes := router.Group(path, authz.Identify(), graphqlUserMiddleware())
+		{
+			graphqlRoutes.GET("", graphql.Handler(graph))
+			graphqlRoutes.POST("", graphql.Handler(graph))
+			graphqlRoutes.GET("/schema", func(c *gin.Context) {
+				sdl := graph.SDL()
+				if sdl == "" {
+					c.JSON(http.StatusServiceUnavailable, gin.H{"error": "GraphQL schema 尚未加载"})
+					return
+				}
+				c.String(http.StatusOK, sdl)
+			})
+		}
 	}
 }
 
-// 身份验证中间件
-func authMiddleware() gin.HandlerFunc {
+// WebSocket 令牌中间件：浏览器无法为 WebSocket 握手设置请求头，令牌可通过 access_token 查询参数传递。
+// 令牌移入 Authorization 请求头后从查询参数中删除，不再转发给服务
+func websocketTokenMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token := c.GetHeader("Authorization")
+		query := c.Request.URL.Query()
+		token := query.Get("access_token")
 		if token == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "未提供认证令牌",
-			})
+			c.Next()
 			return
 		}
+		if c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		query.Del("access_token")
+		c.Request.URL.RawQuery = query.Encode()
+		c.Next()
+	}
+}
 
-		// 在真实实现中，这里应验证 JWT 令牌
-		// 这里只是一个简化的示例
-		c.Set("UserID", uint(1))
+// GraphQL 用户中间件：未登录也可以查询商品和内容，登录用户通过 X-User-ID 转发给子图
+func graphqlUserMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 用户身份只能由网关设置，忽略客户端自行携带的值
+		c.Request.Header.Del("X-User-ID")
+		if userID, ok := c.Get("UserID"); ok {
+			c.Request.Header.Set("X-User-ID", strconv.FormatUint(uint64(userID.(uint)), 10))
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,170 @@
+// Package apikey 实现合作方的 API 密钥认证。合作方请求携带以下请求头：
+//
+//	X-API-Key:   API 密钥
+//	X-Timestamp: 签名时的 Unix 时间戳（秒）
+//	X-Signature: 待签名内容以密钥的 Secret 计算的 HMAC-SHA256，十六进制编码
+//
+// 待签名内容为请求方法、路径、原始查询字符串、X-Timestamp 和请求体 SHA-256 的十六进制编码，以换行符连接。
+// 签名由认证服务验证，密钥的 Secret 不离开认证服务。时间戳超出签名有效期的请求被拒绝，
+// 有效期内验证通过的签名记录在 Redis 中，同一签名的请求只接受一次，防止请求被重放；
+// Redis 不可用时只记录日志，仍然只按时间戳拒绝过期的签名
+package apikey
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/pkg/logger"
+	authrpc "github.com/yourusername/goshop/services/auth/rpc"
+	"go.uber.org/zap"
+)
+
+// 合作方请求的认证请求头
+const (
+	HeaderKey       = "X-API-Key"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderSignature = "X-Signature"
+)
+
+// Authenticator 通过认证服务验证 API 密钥
+type Authenticator struct {
+	conns  *grpcclient.Factory
+	rdb    *redis.Client
+	window time.Duration
+	log    *logger.Logger
+	now    func() time.Time
+}
+
+// New 创建 API 密钥认证器，conns 用于连接认证服务，rdb 记录已使用的签名，window 为签名有效期
+func New(conns *grpcclient.Factory, rdb *redis.Client, window time.Duration, log *logger.Logger) *Authenticator {
+	return &Authenticator{conns: conns, rdb: rdb, window: window, log: log, now: time.Now}
+}
+
+// Handler 返回 API 密钥认证中间件，permission 不为空时密钥必须拥有该权限。
+// 没有携带 API 密钥的请求交给 fallback 认证，fallback 为 nil 时返回 401。
+// 认证通过后以密钥所属用户转发请求，并设置 APIKeyID 和密钥的 Permissions
+func (a *Authenticator) Handler(permission string, fallback gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderKey)
+		if key == "" {
+			if fallback != nil {
+				fallback(c)
+				return
+			}
+			c.Error(apperrors.NewUnauthorized("未提供 API 密钥", nil))
+			c.Abort()
+			return
+		}
+
+		reply, err := a.verify(c, key)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if permission != "" && !reply.HasPermission(permission) {
+			c.Error(apperrors.NewForbidden("API 密钥没有 "+permission+" 权限", nil))
+			c.Abort()
+			return
+		}
+		c.Set("UserID", reply.UserID)
+		c.Set("APIKeyID", reply.ID)
+		c.Set("Permissions", reply.Permissions)
+		c.Next()
+	}
+}
+
+// verify 校验时间戳后由认证服务验证签名，并拒绝已经使用过的签名
+func (a *Authenticator) verify(c *gin.Context, key string) (*authrpc.APIKeyReply, error) {
+	timestamp := c.GetHeader(HeaderTimestamp)
+	signature := c.GetHeader(HeaderSignature)
+	if timestamp == "" || signature == "" {
+		return nil, apperrors.NewUnauthorized("请求缺少签名", nil)
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, apperrors.NewUnauthorized("无效的请求时间戳", err)
+	}
+	if skew := a.now().Sub(time.Unix(seconds, 0)); skew > a.window || skew < -a.window {
+		return nil, apperrors.NewUnauthorized("请求签名已过期", nil)
+	}
+
+	payload, err := stringToSign(c, timestamp)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, apperrors.NewPayloadTooLarge(fmt.Sprintf("请求体不能超过 %d 字节", tooLarge.Limit), err)
+		}
+		return nil, apperrors.NewBadRequest("读取请求体失败", err)
+	}
+	conn, err := a.conns.Conn("auth")
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable("auth 服务暂不可用", err)
+	}
+	ctx := grpcclient.WithRequestID(c.Request.Context(), c.GetString("RequestID"))
+	reply, err := authrpc.NewClient(conn).VerifyAPIKey(ctx, &authrpc.VerifyAPIKeyRequest{
+		Key:       key,
+		Payload:   payload,
+		Signature: signature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := a.useSignature(ctx, key, signature); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// useSignature 记录验证通过的签名，签名已被使用时返回 401。时间戳在前后 window 内都有效，
+// 签名最多在 2*window 内被接受，记录保留同样长的时间
+func (a *Authenticator) useSignature(ctx context.Context, key, signature string) error {
+	first, err := a.rdb.SetNX(ctx, signatureKey(key, signature), 1, 2*a.window).Result()
+	if err != nil {
+		a.log.Warn(ctx, "记录 API 密钥签名失败", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	if !first {
+		a.log.Warn(ctx, "API 密钥签名被重放", zap.String("key", key))
+		return apperrors.NewUnauthorized("请求签名已被使用", nil)
+	}
+	return nil
+}
+
+func signatureKey(key, signature string) string {
+	return "gateway:apikey:signature:" + key + ":" + signature
+}
+
+// stringToSign 返回请求的待签名内容。读取请求体后重新设置，转发时请求体保持不变
+func stringToSign(c *gin.Context, timestamp string) (string, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			return "", err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		c.Request.Method,
+		c.Request.URL.Path,
+		c.Request.URL.RawQuery,
+		timestamp,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n"), nil
+}
@@ -0,0 +1,226 @@
+// Package auditlog 为合规调查记录敏感路由的请求，例如支付、退款和后台操作。匹配规则的请求在处理完成后
+// 以审计事件记录请求方法、路径、用户、状态码和耗时，规则要求时同时记录请求体。
+// JSON 和表单请求体中的密码、卡号等字段在记录前替换为 [REDACTED]，其他格式的请求体只记录类型和长度，
+// 超过长度上限的请求体不记录内容。规则在配置变更后立即生效
+package auditlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/audit"
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// action 是请求审计事件的动作
+const action = "gateway.request"
+
+// redacted 替换被隐藏字段的值
+const redacted = "[REDACTED]"
+
+// rule 是编译后的规则
+type rule struct {
+	pathPrefix  string
+	methods     map[string]bool // 为空时匹配所有方法
+	captureBody bool
+}
+
+// settings 是编译后的配置
+type settings struct {
+	rules        []*rule
+	redactFields map[string]bool
+	maxBodySize  int
+}
+
+// Auditor 记录敏感路由的请求，可并发读取和替换规则
+type Auditor struct {
+	recorder *audit.Recorder
+	log      *logger.Logger
+	current  atomic.Pointer[settings]
+}
+
+// New 按 cfg 创建审计器，请求通过 recorder 记录到审计事件流
+func New(cfg config.RequestAuditConfig, recorder *audit.Recorder, log *logger.Logger) *Auditor {
+	a := &Auditor{recorder: recorder, log: log}
+	a.Update(cfg)
+	return a
+}
+
+// Update 用 cfg 替换规则
+func (a *Auditor) Update(cfg config.RequestAuditConfig) {
+	s := &settings{
+		redactFields: make(map[string]bool, len(cfg.RedactFields)),
+		maxBodySize:  cfg.MaxBodySize,
+	}
+	for _, field := range cfg.RedactFields {
+		s.redactFields[strings.ToLower(field)] = true
+	}
+	for _, rc := range cfg.Rules {
+		r := &rule{
+			pathPrefix:  rc.PathPrefix,
+			methods:     make(map[string]bool, len(rc.Methods)),
+			captureBody: rc.CaptureBody,
+		}
+		for _, method := range rc.Methods {
+			r.methods[strings.ToUpper(method)] = true
+		}
+		s.rules = append(s.rules, r)
+	}
+	a.current.Store(s)
+}
+
+// Handler 返回审计中间件。用户由之后的认证中间件设置，因此在请求处理完成后记录；
+// 被拒绝的请求同样记录，记录失败不影响请求的处理
+func (a *Auditor) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s := a.current.Load()
+		r := s.match(c.Request.Method, c.Request.URL.Path)
+		if r == nil {
+			c.Next()
+			return
+		}
+
+		var body interface{}
+		if r.captureBody {
+			body = s.captureBody(c)
+		}
+		start := time.Now()
+		c.Next()
+		a.record(c, r, body, time.Since(start))
+	}
+}
+
+// match 返回请求匹配的第一条规则，没有匹配的规则时返回 nil
+func (s *settings) match(method, path string) *rule {
+	for _, r := range s.rules {
+		if strings.HasPrefix(path, r.pathPrefix) && (len(r.methods) == 0 || r.methods[method]) {
+			return r
+		}
+	}
+	return nil
+}
+
+// record 记录请求的审计事件
+func (a *Auditor) record(c *gin.Context, r *rule, body interface{}, latency time.Duration) {
+	ctx := c.Request.Context()
+	actor := audit.Actor{
+		Type:      audit.ActorUser,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if userID, ok := c.Get("UserID"); ok {
+		actor.ID = fmt.Sprint(userID)
+	}
+	switch c.GetString("Role") {
+	case "admin", "staff":
+		actor.Type = audit.ActorAdmin
+	}
+
+	metadata := map[string]interface{}{
+		"method":     c.Request.Method,
+		"path":       c.Request.URL.Path,
+		"status":     c.Writer.Status(),
+		"latency_ms": latency.Milliseconds(),
+		"request_id": c.GetString("RequestID"),
+	}
+	if query := c.Request.URL.RawQuery; query != "" {
+		metadata["query"] = query
+	}
+	if role := c.GetString("Role"); role != "" {
+		metadata["role"] = role
+	}
+	if apiKeyID, ok := c.Get("APIKeyID"); ok {
+		actor.Type = audit.ActorService
+		metadata["api_key_id"] = apiKeyID
+	}
+	if body != nil {
+		metadata["body"] = body
+	}
+
+	err := a.recorder.Record(ctx, &audit.Entry{
+		Action:   action,
+		Actor:    actor,
+		Entity:   audit.Entity{Type: "route", ID: r.pathPrefix},
+		Metadata: metadata,
+	})
+	if err != nil {
+		a.log.Error(ctx, "记录审计事件失败", zap.String("action", action), zap.String("path", c.Request.URL.Path), zap.Error(err))
+	}
+}
+
+// captureBody 读取请求体并返回隐藏敏感字段后的内容，读取后重新设置请求体，转发时请求体保持不变。
+// 无法解析的请求体只返回类型和长度，超过长度上限的请求体不记录内容
+func (s *settings) captureBody(c *gin.Context) interface{} {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(s.maxBodySize)+1))
+	c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(data), c.Request.Body), c.Request.Body}
+	if err != nil {
+		return nil
+	}
+	if len(data) > s.maxBodySize {
+		return map[string]interface{}{"truncated": true}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err == nil {
+			return s.redact(value)
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(string(data)); err == nil {
+			form := make(map[string]interface{}, len(values))
+			for name, v := range values {
+				if s.redactFields[strings.ToLower(name)] {
+					form[name] = redacted
+				} else {
+					form[name] = v
+				}
+			}
+			return form
+		}
+	}
+	return map[string]interface{}{"content_type": mediaType, "size": len(data)}
+}
+
+// redact 替换 JSON 值中任意层级的敏感字段
+func (s *settings) redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if s.redactFields[strings.ToLower(name)] {
+				v[name] = redacted
+			} else {
+				v[name] = s.redact(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = s.redact(item)
+		}
+	}
+	return value
+}
+
+// readCloser 读取已缓冲的请求体和剩余部分，关闭时关闭原请求体
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,267 @@
+// Package compress 以 gzip 压缩网关的响应，商品列表和 CMS 内容等较大的 JSON 响应压缩后可减少大部分流量。
+// 只压缩客户端接受 gzip、Content-Type 在白名单中且响应体达到最小长度的响应；响应体先缓冲到最小长度再决定是否压缩。
+// 配置变更后立即生效
+package compress
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/config"
+)
+
+// gin.Context 中的键
+const (
+	skipKey    = "compress.skip"    // 跳过压缩
+	encodedKey = "compress.encoded" // 响应已压缩
+)
+
+// settings 是编译后的压缩配置
+type settings struct {
+	enabled       bool
+	minSize       int
+	contentTypes  map[string]bool
+	excludedPaths []string
+	pool          sync.Pool // *gzip.Writer，按配置的压缩级别创建
+}
+
+// Compressor 压缩响应，可并发读取和替换配置
+type Compressor struct {
+	current atomic.Pointer[settings]
+}
+
+// New 按 cfg 创建压缩器
+func New(cfg config.CompressionConfig) *Compressor {
+	c := &Compressor{}
+	c.Update(cfg)
+	return c
+}
+
+// Update 用 cfg 替换压缩配置
+func (c *Compressor) Update(cfg config.CompressionConfig) {
+	s := &settings{
+		enabled:       cfg.Enabled,
+		minSize:       cfg.MinSize,
+		contentTypes:  make(map[string]bool, len(cfg.ContentTypes)),
+		excludedPaths: cfg.ExcludedPaths,
+	}
+	for _, contentType := range cfg.ContentTypes {
+		s.contentTypes[strings.ToLower(contentType)] = true
+	}
+	level := cfg.Level
+	s.pool.New = func() interface{} {
+		gz, err := gzip.NewWriterLevel(nil, level)
+		if err != nil {
+			gz = gzip.NewWriter(nil)
+		}
+		return gz
+	}
+	c.current.Store(s)
+}
+
+// Skip 返回不压缩响应的中间件，用于单独关闭某些路由的压缩
+func Skip() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(skipKey, true)
+		c.Next()
+	}
+}
+
+// Encoded 判断请求的响应是否由网关压缩。响应头的 Content-Encoding 由网关设置时，
+// 内层中间件看到的响应体仍是未压缩的
+func Encoded(c *gin.Context) bool {
+	return c.GetBool(encodedKey)
+}
+
+// Handler 返回压缩中间件
+func (c *Compressor) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		s := c.current.Load()
+		if !s.enabled || !s.applies(ctx.Request) {
+			ctx.Next()
+			return
+		}
+		w := &writer{ResponseWriter: ctx.Writer, settings: s, ctx: ctx}
+		ctx.Writer = w
+		defer w.finish()
+		ctx.Next()
+	}
+}
+
+// applies 判断是否可以压缩请求的响应
+func (s *settings) applies(req *http.Request) bool {
+	if req.Method == http.MethodHead || req.Header.Get("Upgrade") != "" || !acceptsGzip(req.Header.Get("Accept-Encoding")) {
+		return false
+	}
+	for _, prefix := range s.excludedPaths {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptsGzip 判断客户端是否接受 gzip 编码，q=0 表示不接受
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// 响应的压缩状态
+const (
+	undecided   = iota // 响应体未达到最小长度，仍在缓冲
+	passthrough        // 不压缩
+	compressing        // 压缩
+)
+
+// writer 缓冲响应体直到可以决定是否压缩。状态码和响应头照常写入底层的 gin.ResponseWriter，
+// gin 在第一次写入响应体时才发送响应头，因此决定压缩时仍可以修改响应头
+type writer struct {
+	gin.ResponseWriter
+	settings *settings
+	ctx      *gin.Context
+	state    int
+	checked  bool // 已判断响应是否可以压缩
+	buf      []byte
+	gz       *gzip.Writer
+}
+
+// Write 实现 http.ResponseWriter
+func (w *writer) Write(p []byte) (int, error) {
+	switch w.state {
+	case passthrough:
+		return w.ResponseWriter.Write(p)
+	case compressing:
+		return w.gz.Write(p)
+	}
+	if !w.checked {
+		w.checked = true
+		if !w.compressible() {
+			if err := w.passthrough(); err != nil {
+				return 0, err
+			}
+			return w.ResponseWriter.Write(p)
+		}
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.settings.minSize {
+		if err := w.compress(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// WriteString 实现 gin.ResponseWriter
+func (w *writer) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow 实现 gin.ResponseWriter，缓冲期间推迟到决定是否压缩后再发送响应头
+func (w *writer) WriteHeaderNow() {
+	if w.state != undecided {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Flush 实现 http.Flusher，缓冲期间不发送数据
+func (w *writer) Flush() {
+	switch w.state {
+	case passthrough:
+		w.ResponseWriter.Flush()
+	case compressing:
+		w.gz.Flush()
+		w.ResponseWriter.Flush()
+	}
+}
+
+// compressible 判断响应是否可以压缩：状态码有响应体、没有其他编码且 Content-Type 在白名单中
+func (w *writer) compressible() bool {
+	if w.ctx.GetBool(skipKey) {
+		return false
+	}
+	status := w.ResponseWriter.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || !w.settings.contentTypes[strings.ToLower(mediaType)] {
+		return false
+	}
+	// 响应是否压缩取决于 Accept-Encoding，缓存需要按 Accept-Encoding 区分
+	if !varies(header, "Accept-Encoding") {
+		header.Add("Vary", "Accept-Encoding")
+	}
+	return true
+}
+
+// passthrough 不压缩响应，发送已缓冲的响应体
+func (w *writer) passthrough() error {
+	w.state = passthrough
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// compress 开始压缩响应，压缩已缓冲的响应体
+func (w *writer) compress() error {
+	w.state = compressing
+	w.ctx.Set(encodedKey, true)
+	header := w.Header()
+	header.Set("Content-Encoding", "gzip")
+	header.Del("Content-Length")
+	w.gz = w.settings.pool.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+	_, err := w.gz.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// finish 在处理完请求后发送未达到最小长度的响应体，或结束压缩
+func (w *writer) finish() {
+	switch w.state {
+	case undecided:
+		w.passthrough()
+	case compressing:
+		w.gz.Close()
+		w.gz.Reset(nil)
+		w.settings.pool.Put(w.gz)
+	}
+}
+
+// varies 判断 Vary 响应头是否已包含 name
+func varies(header http.Header, name string) bool {
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+// Package cors 实现网关的跨域资源共享策略。允许的来源、方法、请求头、暴露的响应头、是否允许携带凭据
+// 和预检结果的缓存时间由配置决定，配置变更后立即生效
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+)
+
+// policy 是编译后的跨域策略
+type policy struct {
+	anyOrigin        bool
+	origins          map[string]bool
+	suffixes         []string // 通配子域名的来源，如 https://*.example.com 保存为 https:// 和 .example.com
+	schemes          []string
+	allowMethods     string
+	allowHeaders     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+// Policy 是可并发读取和替换的跨域策略
+type Policy struct {
+	current atomic.Pointer[policy]
+}
+
+// New 按 cfg 创建跨域策略
+func New(cfg config.CORSConfig) *Policy {
+	p := &Policy{}
+	p.Update(cfg)
+	return p
+}
+
+// Update 用 cfg 替换跨域策略
+func (p *Policy) Update(cfg config.CORSConfig) {
+	compiled := &policy{
+		origins:          make(map[string]bool, len(cfg.AllowedOrigins)),
+		allowMethods:     strings.Join(cfg.AllowedMethods, ", "),
+		allowHeaders:     strings.Join(cfg.AllowedHeaders, ", "),
+		exposeHeaders:    strings.Join(cfg.ExposedHeaders, ", "),
+		allowCredentials: cfg.AllowCredentials,
+	}
+	if cfg.MaxAge > 0 {
+		compiled.maxAge = strconv.Itoa(cfg.MaxAge)
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		scheme, host, wildcard := strings.Cut(origin, "://*.")
+		switch {
+		case origin == "*":
+			compiled.anyOrigin = true
+		case wildcard:
+			compiled.schemes = append(compiled.schemes, scheme+"://")
+			compiled.suffixes = append(compiled.suffixes, "."+strings.ToLower(host))
+		default:
+			compiled.origins[strings.ToLower(origin)] = true
+		}
+	}
+	p.current.Store(compiled)
+}
+
+// Handler 返回跨域中间件。允许的来源的预检请求直接返回 204，不允许的来源的预检请求返回 403；
+// 不允许的来源的其他请求照常处理但不返回跨域响应头，由浏览器拒绝脚本读取响应
+func (p *Policy) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cp := p.current.Load()
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		header := c.Writer.Header()
+		if !cp.anyOrigin || cp.allowCredentials {
+			// 响应随 Origin 变化，缓存不能把一个来源的响应返回给另一个来源
+			header.Add("Vary", "Origin")
+		}
+		if origin == "" {
+			c.Next()
+			return
+		}
+		if !cp.allowed(origin) {
+			if preflight {
+				c.Error(apperrors.NewForbidden("不允许的跨域来源", nil))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if cp.anyOrigin && !cp.allowCredentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cp.allowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if cp.exposeHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", cp.exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", cp.allowMethods)
+		header.Set("Access-Control-Allow-Headers", cp.allowHeaders)
+		if cp.maxAge != "" {
+			header.Set("Access-Control-Max-Age", cp.maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// allowed 判断是否允许 origin 跨域访问
+func (p *policy) allowed(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for i, suffix := range p.suffixes {
+		if strings.HasPrefix(origin, p.schemes[i]) && strings.HasSuffix(origin, suffix) && len(origin) > len(p.schemes[i])+len(suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+// Package proxy 将网关收到的请求反向代理到服务实例，实例由服务发现按轮询从健康实例中选择。
+// 每个上游服务有一个熔断器，服务持续失败时网关直接返回 503，不再转发请求；
+// 没有请求体的幂等请求失败时在重试预算内换一个实例重试
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yourusername/goshop/pkg/breaker"
+	"github.com/yourusername/goshop/pkg/discovery"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/pkg/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+)
+
+// 转发结果，作为上游请求指标的 outcome 标签。上游返回响应时为状态码类别，如 2xx、5xx
+const (
+	outcomeCircuitOpen = "circuit_open" // 熔断器打开，未转发
+	outcomeNoInstance  = "no_instance"  // 没有可用的服务实例，未转发
+	outcomeTimeout     = "timeout"      // 上游超时
+	outcomeError       = "error"        // 连接失败等传输错误
+)
+
+// upstreamMetrics 是转发到上游服务的请求指标
+type upstreamMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+	breaker  *prometheus.GaugeVec
+	retries  *prometheus.CounterVec
+}
+
+func newUpstreamMetrics(m *metrics.Metrics) *upstreamMetrics {
+	return &upstreamMetrics{
+		requests: m.Counter("gateway_upstream_requests_total", "Requests forwarded to upstream services, by service and outcome.", "service", "outcome"),
+		duration: m.Histogram("gateway_upstream_request_duration_seconds", "Latency of upstream services seen by the gateway, by service.", nil, "service"),
+		inFlight: m.Gauge("gateway_upstream_requests_in_flight", "Requests being forwarded to upstream services, by service.", "service"),
+		breaker:  m.Gauge("gateway_circuit_breaker_state", "State of the circuit breaker of upstream services: 0 closed, 1 open, 2 half-open.", "service"),
+		retries:  m.Counter("gateway_upstream_retries_total", "Requests retried on another instance of upstream services, by service.", "service"),
+	}
+}
+
+// Settings 是转发请求的配置
+type Settings struct {
+	Breaker             breaker.Settings // 每个上游服务的熔断器配置
+	Timeout             time.Duration    // 请求没有截止时间时上游的超时，0 表示不限制
+	MaxRetries          int              // 每个请求的最大重试次数，0 表示不重试
+	RetryBudget         float64          // 重试数占请求数的最大比例
+	MinRetriesPerSecond int              // 不受 RetryBudget 限制的每秒重试数
+}
+
+// Proxy 将请求转发到服务实例
+type Proxy struct {
+	resolver  *discovery.Resolver
+	transport http.RoundTripper
+	settings  Settings
+	budget    *retryBudget
+	metrics   *upstreamMetrics
+	log       *logger.Logger
+
+	mu       sync.Mutex
+	breakers map[string]*breaker.Breaker
+}
+
+// New 创建反向代理，转发的请求记录到 m
+func New(resolver *discovery.Resolver, settings Settings, m *metrics.Metrics, log *logger.Logger) *Proxy {
+	p := &Proxy{
+		resolver:  resolver,
+		transport: http.DefaultTransport,
+		settings:  settings,
+		budget:    newRetryBudget(settings.RetryBudget, settings.MinRetriesPerSecond),
+		metrics:   newUpstreamMetrics(m),
+		log:       log,
+		breakers:  make(map[string]*breaker.Breaker),
+	}
+	p.settings.Breaker.OnStateChange = func(name string, from, to breaker.State) {
+		p.metrics.breaker.WithLabelValues(name).Set(float64(to))
+		p.log.Warn(context.Background(), "熔断器状态变化",
+			zap.String("service", name),
+			zap.String("from", from.String()),
+			zap.String("to", to.String()),
+		)
+	}
+	return p
+}
+
+// breaker 返回 service 的熔断器，首次使用时创建
+func (p *Proxy) breaker(service string) *breaker.Breaker {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b, ok := p.breakers[service]
+	if !ok {
+		b = breaker.New(service, p.settings.Breaker)
+		p.breakers[service] = b
+		p.metrics.breaker.WithLabelValues(service).Set(float64(breaker.Closed))
+	}
+	return b
+}
+
+// Forward 返回将请求转发到 service 的 path 的处理函数。path 中的 :name 和 *name 参数替换为路由参数的值，
+// 查询参数原样转发。用户身份只能由网关设置：认证中间件设置了 UserID 时通过 X-User-ID 和 X-Member-Level 请求头转发，
+// 客户端自行携带的这两个请求头一律删除。
+// 连接失败和 5xx 响应计为上游失败，熔断器打开期间直接返回 503 并通过 Retry-After 提示重试时间。
+// 请求没有截止时间时使用配置的上游超时，超时返回 504；超过请求体大小限制的请求返回 413。
+// WebSocket 等协议升级请求由 ReverseProxy 在收到 101 响应后接管连接双向转发，不设置超时
+func (p *Proxy) Forward(service, path string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done, retryAt, err := p.breaker(service).Allow()
+		if errors.Is(err, breaker.ErrOpen) {
+			p.metrics.requests.WithLabelValues(service, outcomeCircuitOpen).Inc()
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(retryAt)))
+			c.Error(apperrors.NewServiceUnavailable(service+" 服务暂不可用", err))
+			c.Abort()
+			return
+		}
+		// 只有上游的连接失败和 5xx 响应计为失败，客户端取消的请求不算上游失败
+		failed := false
+		defer func() {
+			done(!failed || errors.Is(c.Request.Context().Err(), context.Canceled))
+		}()
+
+		instance, err := p.resolver.Pick(service)
+		if err != nil {
+			p.metrics.requests.WithLabelValues(service, outcomeNoInstance).Inc()
+			p.log.Warn(c.Request.Context(), "没有可用的服务实例", zap.String("service", service), zap.Error(err))
+			c.Error(apperrors.NewServiceUnavailable(service+" 服务暂不可用", err))
+			c.Abort()
+			return
+		}
+		upstreamPath := expandPath(path, c.Params)
+		target, err := p.upstreamURL(instance, upstreamPath)
+		if err != nil {
+			p.metrics.requests.WithLabelValues(service, outcomeNoInstance).Inc()
+			p.log.Error(c.Request.Context(), "无效的服务实例地址", zap.String("service", service), zap.String("url", instance.URL), zap.Error(err))
+			c.Error(apperrors.New(apperrors.ErrServiceUnavailable, service+" 服务暂不可用", http.StatusBadGateway, err))
+			c.Abort()
+			return
+		}
+
+		if _, ok := c.Request.Context().Deadline(); !ok && p.settings.Timeout > 0 && !isWebSocketUpgrade(c.Request) {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), p.settings.Timeout)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		start := time.Now()
+		transport := &retryTransport{proxy: p, service: service, path: upstreamPath, instance: instance}
+		rp := &httputil.ReverseProxy{
+			Transport: transport,
+			Director: func(req *http.Request) {
+				req.URL.Scheme = target.Scheme
+				req.URL.Host = target.Host
+				req.URL.Path = target.Path
+				req.URL.RawPath = ""
+				req.Host = target.Host
+
+				req.Header.Del("X-User-ID")
+				req.Header.Del("X-Member-Level")
+				if userID, ok := c.Get("UserID"); ok {
+					if id, ok := userID.(uint); ok {
+						req.Header.Set("X-User-ID", strconv.FormatUint(uint64(id), 10))
+						req.Header.Set("X-Member-Level", strconv.Itoa(c.GetInt("MemberLevel")))
+					}
+				}
+				if requestID := c.GetString("RequestID"); requestID != "" {
+					req.Header.Set("X-Request-ID", requestID)
+				}
+				// 以网关的链路上下文替换客户端携带的 traceparent，服务的日志和 span 归入同一链路
+				otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+			},
+			ModifyResponse: func(resp *http.Response) error {
+				p.metrics.requests.WithLabelValues(service, strconv.Itoa(resp.StatusCode/100)+"xx").Inc()
+				p.metrics.duration.WithLabelValues(service).Observe(time.Since(start).Seconds())
+				switch {
+				case resp.StatusCode == http.StatusSwitchingProtocols:
+					// 升级后的连接会长时间保持，握手成功即记录结果，不占用半开状态的探测名额
+					done(true)
+				case resp.StatusCode >= http.StatusInternalServerError:
+					failed = true
+				}
+				return nil
+			},
+			ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+				// 请求体超过大小限制是客户端的问题，不计为上游失败
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					c.Error(apperrors.NewPayloadTooLarge(fmt.Sprintf("请求体不能超过 %d 字节", tooLarge.Limit), err))
+					c.Abort()
+					return
+				}
+				failed = true
+				// 连接失败的实例计入失败次数，连续失败的实例在下次健康检查前即被剔除
+				p.resolver.ReportFailure(transport.instance)
+				p.log.Warn(req.Context(), "转发请求失败",
+					zap.String("service", service),
+					zap.String("instance", transport.instance.URL),
+					zap.Error(err),
+				)
+				if errors.Is(err, context.DeadlineExceeded) {
+					p.metrics.requests.WithLabelValues(service, outcomeTimeout).Inc()
+					c.Error(apperrors.NewGatewayTimeout(service+" 服务响应超时", err))
+				} else {
+					p.metrics.requests.WithLabelValues(service, outcomeError).Inc()
+					c.Error(apperrors.New(apperrors.ErrServiceUnavailable, service+" 服务暂不可用", http.StatusBadGateway, err))
+				}
+				c.Abort()
+			},
+		}
+		inFlight := p.metrics.inFlight.WithLabelValues(service)
+		inFlight.Inc()
+		defer inFlight.Dec()
+		rp.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// upstreamURL 返回实例上 path 的地址
+func (p *Proxy) upstreamURL(instance discovery.Instance, path string) (*url.URL, error) {
+	target, err := url.Parse(instance.URL)
+	if err != nil {
+		return nil, err
+	}
+	target.Path += path
+	return target, nil
+}
+
+// WebSocket 返回将 WebSocket 升级请求转发到 service 的 path 的处理函数，升级后双向转发连接上的数据。
+// 转发规则与 Forward 相同，用户身份同样通过 X-User-ID 请求头传给服务；非升级请求返回 400
+func (p *Proxy) WebSocket(service, path string) gin.HandlerFunc {
+	forward := p.Forward(service, path)
+	return func(c *gin.Context) {
+		if !isWebSocketUpgrade(c.Request) {
+			c.Error(apperrors.NewBadRequest("需要 WebSocket 升级请求", nil))
+			c.Abort()
+			return
+		}
+		forward(c)
+	}
+}
+
+// isWebSocketUpgrade 判断请求是否为 WebSocket 升级请求
+func isWebSocketUpgrade(req *http.Request) bool {
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, value := range req.Header.Values("Connection") {
+		for _, token := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// retryAfterSeconds 返回到 retryAt 的秒数，向上取整且至少为 1 秒
+func retryAfterSeconds(retryAt time.Time) int {
+	seconds := int(math.Ceil(time.Until(retryAt).Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// expandPath 将 path 中的 :name 和 *name 参数替换为路由参数的值
+func expandPath(path string, params gin.Params) string {
+	if !strings.ContainsAny(path, ":*") {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			if value, ok := params.Get(segment[1:]); ok {
+				// *name 参数的值以 / 开头
+				segments[i] = strings.TrimPrefix(value, "/")
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
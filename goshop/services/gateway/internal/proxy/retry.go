@@ -0,0 +1,127 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/discovery"
+)
+
+// retryBudgetWindow 是重试预算的统计窗口
+const retryBudgetWindow = 10 * time.Second
+
+// retryBudget 限制网关所有上游请求的重试总量：每个窗口内的重试数不超过请求数的 ratio 倍，
+// 另外每秒总允许 minPerSecond 次重试。上游故障时重试不会成倍放大请求量，避免形成重试风暴
+type retryBudget struct {
+	ratio        float64
+	minPerSecond int
+	now          func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	requests    int
+	retries     int
+}
+
+func newRetryBudget(ratio float64, minPerSecond int) *retryBudget {
+	return &retryBudget{ratio: ratio, minPerSecond: minPerSecond, now: time.Now}
+}
+
+// request 记录一个转发的请求
+func (b *retryBudget) request() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll()
+	b.requests++
+}
+
+// withdraw 在预算内时记录一次重试并返回 true
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll()
+	allowed := b.ratio*float64(b.requests) + float64(b.minPerSecond)*retryBudgetWindow.Seconds()
+	if float64(b.retries+1) > allowed {
+		return false
+	}
+	b.retries++
+	return true
+}
+
+// roll 在窗口结束后开始新的窗口
+func (b *retryBudget) roll() {
+	if now := b.now(); now.Sub(b.windowStart) >= retryBudgetWindow {
+		b.windowStart = now
+		b.requests = 0
+		b.retries = 0
+	}
+}
+
+// retryTransport 转发一个请求，连接失败或上游返回 502、503、504 时换一个实例重试。
+// 只重试没有请求体的幂等请求，协议升级请求不重试
+type retryTransport struct {
+	proxy    *Proxy
+	service  string
+	path     string             // 展开参数后的上游路径
+	instance discovery.Instance // 最近一次转发的实例
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.proxy.budget.request()
+	resp, err := t.proxy.transport.RoundTrip(req)
+	for attempt := 0; attempt < t.proxy.settings.MaxRetries && t.retryable(req, resp, err); attempt++ {
+		instance, pickErr := t.proxy.resolver.Pick(t.service)
+		if pickErr != nil || !t.proxy.budget.withdraw() {
+			break
+		}
+		next, urlErr := t.proxy.upstreamURL(instance, t.path)
+		if urlErr != nil {
+			break
+		}
+		if err != nil {
+			t.proxy.resolver.ReportFailure(t.instance)
+		} else {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		t.proxy.metrics.retries.WithLabelValues(t.service).Inc()
+
+		req = req.Clone(req.Context())
+		next.RawQuery = req.URL.RawQuery
+		req.URL = next
+		req.Host = next.Host
+		t.instance = instance
+		resp, err = t.proxy.transport.RoundTrip(req)
+	}
+	return resp, err
+}
+
+// retryable 判断转发结果是否可以重试
+func (t *retryTransport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if !idempotent(req.Method) || (req.Body != nil && req.Body != http.NoBody) || req.Header.Get("Upgrade") != "" {
+		return false
+	}
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// idempotent 判断请求方法是否幂等
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+// Package rbac 在网关按角色和权限限制请求。用户的身份和角色来自认证服务签发的访问令牌，
+// 用户拥有的权限由 pkg/rbac 的 Enforcer 向认证服务查询并缓存在 Redis 中，与服务检查权限的方式相同，
+// 分配给用户的其他角色也一并生效。
+// 规则按路径前缀和请求方法匹配，例如 /api/v1/cms/admin/ 只允许 admin 和 staff 角色；单个路由也可以声明需要的角色和权限。
+// 规则在配置变更后立即生效
+package rbac
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/pkg/logger"
+	pkgrbac "github.com/yourusername/goshop/pkg/rbac"
+	authrpc "github.com/yourusername/goshop/services/auth/rpc"
+	"go.uber.org/zap"
+)
+
+// claimsKey 是已验证的访问令牌声明在 gin.Context 中的键
+const claimsKey = "rbac.claims"
+
+// Claims 是认证服务签发的访问令牌中的声明
+type Claims struct {
+	UserID      uint   `json:"user_id"`
+	Name        string `json:"name"`
+	Role        string `json:"role"`
+	MemberLevel int    `json:"member_level"`
+	jwt.RegisteredClaims
+}
+
+// rule 是编译后的规则
+type rule struct {
+	pathPrefix string
+	methods    map[string]bool // 为空时匹配所有方法
+	requirement
+}
+
+// requirement 是请求需要满足的角色和权限
+type requirement struct {
+	roles      map[string]bool // 为空时允许所有角色
+	permission string
+}
+
+// settings 是编译后的配置
+type settings struct {
+	rules []*rule
+}
+
+// Authorizer 验证访问令牌并检查角色和权限，可并发读取和替换规则
+type Authorizer struct {
+	secret   []byte
+	enforcer *pkgrbac.Enforcer
+	log      *logger.Logger
+	current  atomic.Pointer[settings]
+}
+
+// New 创建授权器，secret 用于验证访问令牌，conns 用于连接认证服务查询用户的权限，
+// 权限在 rdb 中缓存 cacheTTL，rdb 为 nil 时不缓存
+func New(secret string, conns *grpcclient.Factory, rdb *redis.Client, cacheTTL time.Duration, cfg config.RBACConfig, log *logger.Logger) *Authorizer {
+	a := &Authorizer{
+		secret:   []byte(secret),
+		enforcer: pkgrbac.New(&authSource{conns: conns}, rdb, cacheTTL, log),
+		log:      log,
+	}
+	a.Update(cfg)
+	return a
+}
+
+// Update 用 cfg 替换规则
+func (a *Authorizer) Update(cfg config.RBACConfig) {
+	s := &settings{}
+	for _, rc := range cfg.Rules {
+		r := &rule{
+			pathPrefix:  rc.PathPrefix,
+			methods:     make(map[string]bool, len(rc.Methods)),
+			requirement: newRequirement(rc.Roles, rc.Permission),
+		}
+		for _, method := range rc.Methods {
+			r.methods[strings.ToUpper(method)] = true
+		}
+		s.rules = append(s.rules, r)
+	}
+	a.current.Store(s)
+}
+
+// Authenticate 返回认证中间件，验证 Authorization 请求头中的 Bearer 访问令牌，
+// 通过后设置 UserID、Role 和 MemberLevel，未携带令牌或令牌无效时返回 401
+func (a *Authorizer) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := a.claims(c); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Identify 返回可选的认证中间件：携带令牌的请求验证令牌并设置 UserID、Role 和 MemberLevel，令牌无效时返回 401；
+// 未携带令牌的请求作为匿名请求继续处理
+func (a *Authorizer) Identify() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			if _, err := a.claims(c); err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// Require 返回授权中间件，roles 不为空时用户的角色必须是其中之一，permission 不为空时必须拥有该权限。
+// 用于认证中间件之后；API 密钥认证的请求只检查密钥的权限，声明了角色的路由不接受 API 密钥
+func (a *Authorizer) Require(roles []string, permission string) gin.HandlerFunc {
+	req := newRequirement(roles, permission)
+	return func(c *gin.Context) {
+		if err := a.check(c, &req); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Handler 返回按规则授权的中间件，请求必须满足所有匹配的规则。
+// 规则只接受用户的访问令牌，匹配规则的请求先验证访问令牌
+func (a *Authorizer) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, r := range a.current.Load().rules {
+			if !strings.HasPrefix(c.Request.URL.Path, r.pathPrefix) || (len(r.methods) > 0 && !r.methods[c.Request.Method]) {
+				continue
+			}
+			if err := a.check(c, &r.requirement); err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// check 检查请求是否满足 req
+func (a *Authorizer) check(c *gin.Context, req *requirement) error {
+	if _, ok := c.Get("APIKeyID"); ok {
+		if len(req.roles) > 0 {
+			return apperrors.NewForbidden("该接口不接受 API 密钥", nil)
+		}
+		permissions, _ := c.Get("Permissions")
+		codes, _ := permissions.([]string)
+		if req.permission != "" && !containsString(codes, req.permission) {
+			return apperrors.NewForbidden("API 密钥没有 "+req.permission+" 权限", nil)
+		}
+		return nil
+	}
+
+	claims, err := a.claims(c)
+	if err != nil {
+		return err
+	}
+	allowed := len(req.roles) == 0 || req.roles[claims.Role]
+	if allowed && req.permission == "" {
+		return nil
+	}
+
+	// 查询结果由等待同一用户的请求共享，不随发起查询的请求取消
+	ctx := context.WithoutCancel(grpcclient.WithRequestID(c.Request.Context(), c.GetString("RequestID")))
+	grants, err := a.enforcer.Grants(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	// 令牌中的角色不满足时，分配给用户的其他角色也可以满足
+	for role := range req.roles {
+		if allowed {
+			break
+		}
+		allowed = grants.HasRole(role)
+	}
+	if !allowed {
+		a.log.Warn(c.Request.Context(), "角色不允许访问",
+			zap.Uint("user_id", claims.UserID),
+			zap.String("role", claims.Role),
+			zap.String("path", c.Request.URL.Path),
+		)
+		return apperrors.NewForbidden("没有访问权限", nil)
+	}
+	if req.permission != "" && !grants.HasPermission(req.permission) {
+		a.log.Warn(c.Request.Context(), "用户没有所需权限",
+			zap.Uint("user_id", claims.UserID),
+			zap.String("role", claims.Role),
+			zap.String("permission", req.permission),
+		)
+		return apperrors.NewForbidden("没有 "+req.permission+" 权限", nil)
+	}
+	return nil
+}
+
+// claims 返回请求的访问令牌声明，同一请求只验证一次令牌
+func (a *Authorizer) claims(c *gin.Context) (*Claims, error) {
+	if v, ok := c.Get(claimsKey); ok {
+		return v.(*Claims), nil
+	}
+	raw, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || raw == "" {
+		return nil, apperrors.NewUnauthorized("未提供认证令牌", nil)
+	}
+	var claims Claims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(*jwt.Token) (interface{}, error) {
+		return a.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || claims.UserID == 0 {
+		return nil, apperrors.NewUnauthorized("认证令牌无效或已过期", err)
+	}
+	c.Set(claimsKey, &claims)
+	c.Set("UserID", claims.UserID)
+	c.Set("Role", claims.Role)
+	c.Set("MemberLevel", claims.MemberLevel)
+	return &claims, nil
+}
+
+// authSource 向认证服务查询用户的角色和权限，认证服务的连接在第一次查询时建立
+type authSource struct {
+	conns *grpcclient.Factory
+}
+
+// UserGrants 实现 pkgrbac.Source
+func (s *authSource) UserGrants(ctx context.Context, userID uint) (*pkgrbac.Grants, error) {
+	conn, err := s.conns.Conn("auth")
+	if err != nil {
+		return nil, err
+	}
+	return authrpc.NewClient(conn).UserGrants(ctx, userID)
+}
+
+func newRequirement(roles []string, permission string) requirement {
+	req := requirement{permission: permission}
+	if len(roles) > 0 {
+		req.roles = make(map[string]bool, len(roles))
+		for _, role := range roles {
+			req.roles[role] = true
+		}
+	}
+	return req
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+// Package responsecache 在 Redis 中缓存网关 GET 接口的响应。路由按规则名启用缓存，
+// 缓存键由路径、排序后的查询参数和 Accept-Language 组成，规则的响应在配置的 NATS 事件到达时清除
+package responsecache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
+	"github.com/yourusername/goshop/pkg/cache"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/gateway/internal/compress"
+	"go.uber.org/zap"
+)
+
+const (
+	// invalidateQueue 是清除缓存的队列组，缓存保存在共享的 Redis 中，每个事件只需一个网关实例处理
+	invalidateQueue = "gateway-response-cache"
+	// invalidateTimeout 是处理单个清除事件的超时时间
+	invalidateTimeout = 10 * time.Second
+)
+
+// Rule 表示一类响应的缓存规则
+type Rule struct {
+	TTL          time.Duration
+	InvalidateOn []string // 清除该规则全部响应的 NATS subject
+}
+
+// entry 是缓存的响应
+type entry struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// Cache 缓存网关的响应
+type Cache struct {
+	store        *cache.Cache
+	rules        map[string]Rule
+	maxEntrySize int
+	log          *logger.Logger
+	subs         []*nats.Subscription
+}
+
+// New 创建响应缓存，maxEntrySize 为可缓存的最大响应字节数
+func New(store *cache.Cache, rules map[string]Rule, maxEntrySize int, log *logger.Logger) *Cache {
+	return &Cache{
+		store:        store,
+		rules:        rules,
+		maxEntrySize: maxEntrySize,
+		log:          log,
+	}
+}
+
+// Handler 返回按 rule 缓存响应的中间件，未配置 rule 或缓存未启用时直接转发。
+// 只缓存匿名的 GET 请求的 200 响应；客户端发送 Cache-Control: no-cache 时跳过缓存读取，
+// 上游响应设置了 Cookie 或 Cache-Control: private/no-store 时不缓存。Redis 不可用时直接转发
+func (rc *Cache) Handler(rule string) gin.HandlerFunc {
+	if rc == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	r, ok := rc.rules[rule]
+	if !ok {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || c.GetHeader("Authorization") != "" {
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+		key := cache.Key("response", rule, requestKey(c.Request))
+
+		if !hasDirective(c.GetHeader("Cache-Control"), "no-cache") {
+			var cached entry
+			err := rc.store.Get(ctx, key, &cached)
+			if err == nil {
+				writeEntry(c, &cached)
+				return
+			}
+			if !errors.Is(err, cache.ErrMiss) {
+				rc.log.Warn(ctx, "读取响应缓存失败", zap.String("rule", rule), zap.Error(err))
+			}
+		}
+
+		w := &recorder{ResponseWriter: c.Writer, limit: rc.maxEntrySize}
+		c.Writer = w
+		c.Header("X-Cache", "MISS")
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.Status() != http.StatusOK || w.overflow || !cacheable(w.Header()) {
+			return
+		}
+		e := &entry{
+			Status:   w.Status(),
+			Header:   storedHeader(w.Header(), compress.Encoded(c)),
+			Body:     w.body.Bytes(),
+			StoredAt: time.Now(),
+		}
+		if err := rc.store.Set(ctx, key, e, r.TTL, rule); err != nil {
+			rc.log.Warn(ctx, "写入响应缓存失败", zap.String("rule", rule), zap.Error(err))
+		}
+	}
+}
+
+// Subscribe 订阅各规则的清除事件，事件到达时清除该规则的全部响应
+func (rc *Cache) Subscribe(nc *nats.Conn) error {
+	rulesBySubject := make(map[string][]string)
+	for name, rule := range rc.rules {
+		for _, subject := range rule.InvalidateOn {
+			rulesBySubject[subject] = append(rulesBySubject[subject], name)
+		}
+	}
+	for subject, rules := range rulesBySubject {
+		rules := rules
+		sub, err := nc.QueueSubscribe(subject, invalidateQueue, func(msg *nats.Msg) {
+			ctx, cancel := context.WithTimeout(context.Background(), invalidateTimeout)
+			defer cancel()
+			if err := rc.store.InvalidateTags(ctx, rules...); err != nil {
+				rc.log.Error(ctx, "清除响应缓存失败", zap.String("subject", msg.Subject), zap.Strings("rules", rules), zap.Error(err))
+				return
+			}
+			rc.log.Info(ctx, "已清除响应缓存", zap.String("subject", msg.Subject), zap.Strings("rules", rules))
+		})
+		if err != nil {
+			rc.Close()
+			return err
+		}
+		rc.subs = append(rc.subs, sub)
+	}
+	return nil
+}
+
+// Close 取消清除事件的订阅
+func (rc *Cache) Close() {
+	for _, sub := range rc.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			rc.log.Warn(context.Background(), "取消订阅失败", zap.String("subject", sub.Subject), zap.Error(err))
+		}
+	}
+	rc.subs = nil
+}
+
+// requestKey 返回请求的缓存键：路径、按参数名排序的查询参数和 Accept-Language 的哈希
+func requestKey(req *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(req.URL.Query().Encode()))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Header.Get("Accept-Language")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeEntry 返回缓存的响应，缓存的响应头覆盖网关中间件已设置的同名响应头
+func writeEntry(c *gin.Context, e *entry) {
+	for name, values := range e.Header {
+		c.Writer.Header()[name] = values
+	}
+	c.Header("X-Cache", "HIT")
+	c.Header("Age", strconv.Itoa(int(time.Since(e.StoredAt).Seconds())))
+	c.Data(e.Status, e.Header.Get("Content-Type"), e.Body)
+	c.Abort()
+}
+
+// cacheable 判断上游响应是否允许缓存
+func cacheable(header http.Header) bool {
+	if header.Get("Set-Cookie") != "" {
+		return false
+	}
+	cc := header.Get("Cache-Control")
+	return !hasDirective(cc, "private") && !hasDirective(cc, "no-store")
+}
+
+// storedHeader 返回需要缓存的响应头，不含逐跳头和本次请求特有的头。
+// encoded 表示响应由网关压缩，缓存的是未压缩的响应体，不保存网关设置的 Content-Encoding，
+// 命中缓存时由压缩中间件按请求重新决定是否压缩
+func storedHeader(header http.Header, encoded bool) http.Header {
+	stored := header.Clone()
+	for _, name := range []string{"Connection", "Keep-Alive", "Transfer-Encoding", "Date", "Content-Length", "X-Request-ID", "X-Trace-ID", "X-Cache", "Age"} {
+		stored.Del(name)
+	}
+	if encoded {
+		stored.Del("Content-Encoding")
+	}
+	return stored
+}
+
+// hasDirective 判断 Cache-Control 头是否包含 directive
+func hasDirective(cacheControl, directive string) bool {
+	for _, d := range strings.Split(cacheControl, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// recorder 在写出响应的同时记录响应体，超过 limit 的响应不再记录
+type recorder struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *recorder) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *recorder) record(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > w.limit {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
@@ -0,0 +1,104 @@
+package routes
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+)
+
+// idleBucketTTL 是客户端令牌桶的保留时间，超过该时长没有请求的客户端的令牌桶被清理
+const idleBucketTTL = 10 * time.Minute
+
+// bucket 是一个客户端的令牌桶
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter 按客户端限制每分钟的请求数，令牌桶容量为每分钟的请求数。
+// 计数保存在网关实例的内存中，多个网关实例时每个实例分别限流
+type limiter struct {
+	perMinute int
+	key       func(c *gin.Context) string // 区分客户端的键
+	now       func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+func newLimiter(perMinute int, key func(c *gin.Context) string) *limiter {
+	return &limiter{
+		perMinute: perMinute,
+		key:       key,
+		now:       time.Now,
+		buckets:   make(map[string]*bucket),
+	}
+}
+
+// Handler 返回限流中间件，超过限制的请求返回 429 并通过 Retry-After 提示重试时间
+func (l *limiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wait := l.take(l.key(c))
+		if wait <= 0 {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.Error(apperrors.NewTooManyRequests("请求过于频繁，请稍后再试", nil))
+		c.Abort()
+	}
+}
+
+// byClientIP 按客户端 IP 区分客户端
+func byClientIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// byUser 按认证后的用户区分客户端，用于认证中间件之后
+func byUser(c *gin.Context) string {
+	if userID, ok := c.Get("UserID"); ok {
+		return fmt.Sprint("user:", userID)
+	}
+	return c.ClientIP()
+}
+
+// take 从 key 的令牌桶取一个令牌，没有令牌时返回需要等待的时间
+func (l *limiter) take(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+	rate := float64(l.perMinute) / time.Minute.Seconds() // 每秒补充的令牌数
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.perMinute), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.perMinute), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+// prune 清理长时间没有请求的客户端的令牌桶，每个保留周期最多清理一次
+func (l *limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < idleBucketTTL {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
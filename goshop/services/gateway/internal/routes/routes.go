@@ -0,0 +1,200 @@
+// Package routes 实现网关的声明式路由表。路由从配置加载，每条路由声明路径、方法、上游服务、
+// 是否需要认证、是否接受合作方 API 密钥、需要的角色和权限、限流、超时和是否压缩响应；配置变更时整体替换路由表，新增服务接口无需重新编译网关
+// 后台路由组将 /api/v1/admin/ 下的请求转发到各服务的后台接口，只接受后台角色的用户并按用户限流
+package routes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/metrics"
+	"github.com/yourusername/goshop/services/gateway/internal/apikey"
+	"github.com/yourusername/goshop/services/gateway/internal/compress"
+	"github.com/yourusername/goshop/services/gateway/internal/proxy"
+	"github.com/yourusername/goshop/services/gateway/internal/rbac"
+)
+
+// methodAny 匹配所有请求方法
+const methodAny = "ANY"
+
+// route 是编译后的路由
+type route struct {
+	pattern  string
+	method   string
+	segments []string
+	timeout  time.Duration
+	handlers []gin.HandlerFunc
+}
+
+// Table 是网关的路由表，可并发读取和替换
+type Table struct {
+	upstream *proxy.Proxy
+	authz    *rbac.Authorizer
+	auth     gin.HandlerFunc
+	apiKeys  *apikey.Authenticator
+	routes   atomic.Pointer[[]*route]
+}
+
+// New 创建空路由表，authz 认证用户并检查路由需要的角色和权限，apiKeys 认证接受 API 密钥的路由的合作方请求
+func New(upstream *proxy.Proxy, authz *rbac.Authorizer, apiKeys *apikey.Authenticator) *Table {
+	t := &Table{upstream: upstream, authz: authz, auth: authz.Authenticate(), apiKeys: apiKeys}
+	t.routes.Store(&[]*route{})
+	return t
+}
+
+// Update 用 routes 替换路由表。路由按具体程度排序，静态路径段多的路由优先匹配。
+// 替换后各路由的限流计数重新开始
+func (t *Table) Update(routes []config.RouteConfig) error {
+	compiled := make([]*route, 0, len(routes))
+	for _, rc := range routes {
+		r, err := t.compile(rc)
+		if err != nil {
+			return err
+		}
+		compiled = append(compiled, r)
+	}
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return specificity(compiled[i]) > specificity(compiled[j])
+	})
+	t.routes.Store(&compiled)
+	return nil
+}
+
+// Len 返回路由数
+func (t *Table) Len() int {
+	return len(*t.routes.Load())
+}
+
+// Handle 按路由表转发请求，作为网关的 NoRoute 处理函数，只处理内置路由未匹配的请求
+func (t *Table) Handle(c *gin.Context) {
+	r, params := t.match(c.Request.Method, c.Request.URL.Path)
+	if r == nil {
+		c.Error(apperrors.NewNotFound("接口不存在", nil))
+		c.Abort()
+		return
+	}
+	c.Params = params
+	metrics.SetRoute(c, r.pattern)
+	if r.timeout > 0 {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), r.timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+	}
+	// Handle 是处理链的最后一个处理函数，中间件调用 c.Next 不会执行其他处理函数，
+	// 因此按顺序调用路由的处理函数，任一处理函数中止请求后不再继续
+	for _, handler := range r.handlers {
+		handler(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+}
+
+// compile 编译路由，处理链依次为限流、认证和转发。接受 API 密钥的路由优先按 API 密钥认证，
+// 请求没有携带 API 密钥时，需要认证的路由再按用户令牌认证
+func (t *Table) compile(rc config.RouteConfig) (*route, error) {
+	segments := split(rc.Path)
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "*") && i != len(segments)-1 {
+			return nil, fmt.Errorf("route %s %s: *%s must be the last path segment", rc.Method, rc.Path, segment[1:])
+		}
+	}
+	upstreamPath := rc.Upstream
+	if upstreamPath == "" {
+		upstreamPath = rc.Path
+	}
+
+	r := &route{
+		pattern:  rc.Path,
+		method:   strings.ToUpper(rc.Method),
+		segments: segments,
+		timeout:  time.Duration(rc.Timeout) * time.Second,
+	}
+	if rc.NoCompress {
+		r.handlers = append(r.handlers, compress.Skip())
+	}
+	if rc.RateLimit > 0 {
+		r.handlers = append(r.handlers, newLimiter(rc.RateLimit, byClientIP).Handler())
+	}
+	switch {
+	case rc.APIKey:
+		var fallback gin.HandlerFunc
+		if rc.Auth {
+			fallback = t.auth
+		}
+		r.handlers = append(r.handlers, t.apiKeys.Handler(rc.Permission, fallback))
+	case rc.Auth:
+		r.handlers = append(r.handlers, t.auth)
+	}
+	if len(rc.Roles) > 0 || rc.Permission != "" {
+		r.handlers = append(r.handlers, t.authz.Require(rc.Roles, rc.Permission))
+	}
+	r.handlers = append(r.handlers, t.upstream.Forward(rc.Service, upstreamPath))
+	return r, nil
+}
+
+// match 返回匹配请求的路由和路径参数
+func (t *Table) match(method, path string) (*route, gin.Params) {
+	segments := split(path)
+	for _, r := range *t.routes.Load() {
+		if r.method != methodAny && r.method != method {
+			continue
+		}
+		if params, ok := r.matchPath(segments); ok {
+			return r, params
+		}
+	}
+	return nil, nil
+}
+
+// matchPath 判断路径是否匹配路由，:name 匹配一个路径段，*name 匹配剩余的路径
+func (r *route) matchPath(segments []string) (gin.Params, bool) {
+	var params gin.Params
+	for i, pattern := range r.segments {
+		if strings.HasPrefix(pattern, "*") {
+			params = append(params, gin.Param{Key: pattern[1:], Value: "/" + strings.Join(segments[i:], "/")})
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(pattern, ":"):
+			params = append(params, gin.Param{Key: pattern[1:], Value: segments[i]})
+		case pattern != segments[i]:
+			return nil, false
+		}
+	}
+	return params, len(segments) == len(r.segments)
+}
+
+// specificity 返回路由的具体程度：静态路径段越多越具体，包含 *name 的路由最后匹配
+func specificity(r *route) int {
+	score := 0
+	for _, segment := range r.segments {
+		switch {
+		case strings.HasPrefix(segment, "*"):
+			score -= 1000
+		case !strings.HasPrefix(segment, ":"):
+			score += 2
+		default:
+			score++
+		}
+	}
+	return score
+}
+
+func split(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
@@ -0,0 +1,84 @@
+// Package transcode 将网关收到的 HTTP 请求转码为服务的 gRPC 调用，省去服务 HTTP 接口的一次转发。
+// 请求消息按结构体标签从 HTTP 请求绑定：uri 标签绑定路由参数，form 标签绑定查询参数，有请求体时按 json 标签绑定请求体
+package transcode
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/grpcclient"
+)
+
+// userScoped 是属于认证用户的请求消息，网关将认证中间件设置的 UserID 填入消息
+type userScoped interface {
+	SetUserID(id uint)
+}
+
+// Transcoder 通过 gRPC 连接调用服务
+type Transcoder struct {
+	conns *grpcclient.Factory
+}
+
+// New 创建转码器，conns 按服务名创建 gRPC 连接
+func New(conns *grpcclient.Factory) *Transcoder {
+	return &Transcoder{conns: conns}
+}
+
+// Unary 返回将请求转码为 service 的 method 调用的处理函数，Req 为 method 的请求消息。
+// 回复作为 data 原样返回，服务返回的错误还原为 pkg/errors 错误后由错误中间件输出
+func Unary[Req any](t *Transcoder, service, method string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := new(Req)
+		if err := bind(c, req); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		conn, err := t.conns.Conn(service)
+		if err != nil {
+			c.Error(apperrors.NewServiceUnavailable(service+" 服务暂不可用", err))
+			c.Abort()
+			return
+		}
+		ctx := grpcclient.WithRequestID(c.Request.Context(), c.GetString("RequestID"))
+		if userID, ok := c.Get("UserID"); ok {
+			if id, ok := userID.(uint); ok {
+				ctx = grpcclient.WithUserID(ctx, id)
+			}
+		}
+		var reply json.RawMessage
+		if err := conn.Invoke(ctx, method, req, &reply, grpcclient.JSON()); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": reply})
+	}
+}
+
+// bind 从路由参数、查询参数和请求体绑定请求消息，属于认证用户的消息填入 UserID
+func bind(c *gin.Context, req interface{}) error {
+	if err := c.ShouldBindUri(req); err != nil {
+		return apperrors.NewBadRequest("无效的路径参数", err)
+	}
+	if err := c.ShouldBindQuery(req); err != nil {
+		return apperrors.NewBadRequest("无效的查询参数", err)
+	}
+	if c.Request.ContentLength != 0 && c.Request.Method != http.MethodGet {
+		if err := c.ShouldBindJSON(req); err != nil {
+			return apperrors.NewBadRequest("无效的请求体", err)
+		}
+	}
+	if scoped, ok := req.(userScoped); ok {
+		userID, ok := c.Get("UserID")
+		id, isUint := userID.(uint)
+		if !ok || !isUint || id == 0 {
+			return apperrors.NewUnauthorized("请先登录", nil)
+		}
+		scoped.SetUserID(id)
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+	"github.com/yourusername/goshop/pkg/audit"
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/database"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/featureflags"
+	"github.com/yourusername/goshop/pkg/graphql"
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/pkg/health"
+	"github.com/yourusername/goshop/pkg/idgen"
+	"github.com/yourusername/goshop/pkg/locks"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/pkg/metrics"
+	"github.com/yourusername/goshop/pkg/shutdown"
+	"github.com/yourusername/goshop/pkg/tracing"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/client"
+	"github.com/yourusername/goshop/services/marketing/internal/event"
+	"github.com/yourusername/goshop/services/marketing/internal/graph"
+	"github.com/yourusername/goshop/services/marketing/internal/handler"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const serviceName = "marketing"
+
+func main() {
+	// Load configuration and watch it for changes
+	watcher, err := config.Watch(serviceName, "")
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	cfg := watcher.Current()
+
+	// Initialize logger
+	log, err := logger.New(serviceName, cfg.Service.LogLevel, logger.WithConfig(cfg.Log, cfg.Service.Environment))
+	if err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	ctx := context.Background()
+	log.Info(ctx, "Starting marketing service",
+		zap.String("environment", cfg.Service.Environment),
+		zap.Int("http_port", cfg.HTTP.Port),
+		zap.Int("grpc_port", cfg.GRPC.Port),
+	)
+
+	// Apply configuration changes at runtime, SIGHUP forces a reload and periodic
+	// refreshes pick up rotated secrets
+	watcher.OnError(func(err error) {
+		log.Error(ctx, "Failed to reload configuration", zap.Error(err))
+	})
+	watcher.OnChange(func(old, new *config.Config) {
+		if new.Service.LogLevel == old.Service.LogLevel {
+			return
+		}
+		if err := log.SetLevel(new.Service.LogLevel); err != nil {
+			log.Warn(ctx, "Invalid log level", zap.String("level", new.Service.LogLevel), zap.Error(err))
+			return
+		}
+		log.Info(ctx, "Log level changed", zap.String("level", new.Service.LogLevel))
+	})
+	watcher.ReloadOnSignal(syscall.SIGHUP)
+	watcher.RefreshSecrets(ctx, time.Duration(cfg.Secrets.CacheTTL)*time.Second)
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Trace, serviceName, cfg.Service.Environment)
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize tracing", zap.Error(err))
+	}
+
+	// Shutdown hooks are registered as resources are created and run in phases
+	// on SIGINT or SIGTERM
+	lc := shutdown.New(log)
+	lc.Add(shutdown.PhaseFlush, "tracing", 0, shutdownTracing)
+
+	// Initialize database
+	db, err := database.Open(cfg.Database, log, database.WithAutoMigrate(
+		&model.Coupon{},
+		&model.CouponUsage{},
+		&model.CouponCodeBatch{},
+		&model.CouponCode{},
+		&model.UserCoupon{},
+		&model.Promotion{},
+		&model.PromotionUsage{},
+		&model.FlashSaleSession{},
+		&model.FlashSaleItem{},
+		&model.FlashSaleReservation{},
+		&model.LoyaltyPointRule{},
+		&model.LoyaltyPointTransaction{},
+		&model.LoyaltyAccount{},
+		&model.MemberLevel{},
+		&model.CampaignOrder{},
+		&model.CampaignDailyStat{},
+		&model.Experiment{},
+		&model.ExperimentVariant{},
+		&model.ExperimentExposure{},
+		&model.ExperimentConversion{},
+		&model.Affiliate{},
+		&model.AffiliateLink{},
+		&model.AffiliateClick{},
+		&model.AffiliateCommissionRule{},
+		&model.AffiliateConversion{},
+		&model.AffiliateLedgerEntry{},
+		&model.AffiliatePayout{},
+		&model.CelebrationCampaign{},
+		&model.CelebrationGrant{},
+	))
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize database", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseClose, "postgres", 0, shutdown.Close(func() error { return database.Close(db) }))
+
+	// Initialize Redis
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.RedisAddr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	lc.Add(shutdown.PhaseClose, "redis", 0, shutdown.Close(rdb.Close))
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		log.Fatal(ctx, "Failed to connect to Redis", zap.Error(err))
+	}
+
+	// Lease a worker ID unique across all services for the generated IDs
+	releaseWorkerID, err := idgen.LeaseWorkerID(ctx, rdb, log)
+	if err != nil {
+		log.Fatal(ctx, "Failed to lease worker ID", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseFlush, "worker-id", 0, shutdown.Func(releaseWorkerID))
+
+	// Initialize NATS connection
+	nc, err := nats.Connect(cfg.NATS.URL, nats.Name(serviceName))
+	if err != nil {
+		log.Fatal(ctx, "Failed to connect to NATS", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseFlush, "nats", 0, shutdown.Close(nc.Drain))
+
+	// Publish admin actions to the audit service
+	js, err := nc.JetStream()
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize JetStream", zap.Error(err))
+	}
+	if err := audit.EnsureStream(js, time.Duration(cfg.Audit.StreamMaxAge)*time.Hour); err != nil {
+		log.Fatal(ctx, "Failed to create audit stream", zap.Error(err))
+	}
+
+	// Initialize repositories and services
+	couponRepo := repository.NewCouponRepository(db)
+	codeRepo := repository.NewCouponCodeRepository(db)
+	userCouponRepo := repository.NewUserCouponRepository(db)
+	promotionRepo := repository.NewPromotionRepository(db)
+	flashSaleRepo := repository.NewFlashSaleRepository(db)
+	loyaltyRepo := repository.NewLoyaltyRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	experimentRepo := repository.NewExperimentRepository(db)
+	affiliateRepo := repository.NewAffiliateRepository(db)
+	celebrationRepo := repository.NewCelebrationRepository(db)
+
+	// Feature flags come from the configuration, overridden at runtime in Redis
+	flags := featureflags.New(rdb, cfg.FeatureFlags, log)
+	flagsCtx, stopFlags := context.WithCancel(ctx)
+	lc.Add(shutdown.PhaseFlush, "feature-flags", 0, shutdown.Func(stopFlags))
+	if err := flags.Start(flagsCtx); err != nil {
+		log.Warn(ctx, "Failed to load feature flags, serving the configured ones", zap.Error(err))
+	}
+
+	experimentService := service.NewExperimentService(experimentRepo, log)
+	couponService := service.NewCouponService(couponRepo, codeRepo, experimentService)
+	codeService := service.NewCouponCodeService(couponRepo, codeRepo)
+	walletService := service.NewWalletService(couponRepo, userCouponRepo)
+	inventoryClient := client.NewInventoryClient(cfg.Endpoints["inventory"])
+	promotionService := service.NewPromotionService(promotionRepo, inventoryClient, experimentService, flags, log)
+	productClient := client.NewProductClient(cfg.Endpoints["product"])
+	priceService := service.NewPriceService(promotionRepo, productClient, experimentService, rdb, log)
+	flashSaleService := service.NewFlashSaleService(flashSaleRepo, rdb, log)
+	publisher := event.NewNATSPublisher(nc, serviceName)
+	loyaltyService := service.NewLoyaltyService(loyaltyRepo, publisher, log)
+	scheduleService := service.NewScheduleService(promotionRepo, couponRepo, publisher, log)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, couponRepo, promotionRepo, log)
+	redemptionService := service.NewRedemptionService(couponRepo, codeRepo, promotionRepo, log)
+	adminService := service.NewAdminService(couponRepo, codeRepo, promotionRepo)
+	affiliateService := service.NewAffiliateService(affiliateRepo, log)
+	userClient := client.NewUserClient(cfg.Endpoints["user"])
+	celebrationService := service.NewCelebrationService(celebrationRepo, couponRepo, userClient, walletService, loyaltyService, publisher, log)
+
+	// Subscribe to order events
+	subscriber := event.NewSubscriber(nc, serviceName, log)
+	lc.Add(shutdown.PhaseFlush, "subscriber", 0, shutdown.Func(subscriber.Close))
+	if err := loyaltyService.Subscribe(subscriber); err != nil {
+		log.Fatal(ctx, "Failed to subscribe to order events", zap.Error(err))
+	}
+	// Analytics, redemptions, experiments and affiliates use their own queue groups so they receive every order event alongside loyalty
+	analyticsSubscriber := event.NewSubscriber(nc, serviceName+"-analytics", log)
+	lc.Add(shutdown.PhaseFlush, "analytics-subscriber", 0, shutdown.Func(analyticsSubscriber.Close))
+	if err := analyticsService.Subscribe(analyticsSubscriber); err != nil {
+		log.Fatal(ctx, "Failed to subscribe to order events for analytics", zap.Error(err))
+	}
+	redemptionSubscriber := event.NewSubscriber(nc, serviceName+"-redemption", log)
+	lc.Add(shutdown.PhaseFlush, "redemption-subscriber", 0, shutdown.Func(redemptionSubscriber.Close))
+	if err := redemptionService.Subscribe(redemptionSubscriber); err != nil {
+		log.Fatal(ctx, "Failed to subscribe to order events for redemptions", zap.Error(err))
+	}
+	experimentSubscriber := event.NewSubscriber(nc, serviceName+"-experiments", log)
+	lc.Add(shutdown.PhaseFlush, "experiment-subscriber", 0, shutdown.Func(experimentSubscriber.Close))
+	if err := experimentService.Subscribe(experimentSubscriber); err != nil {
+		log.Fatal(ctx, "Failed to subscribe to order events for experiments", zap.Error(err))
+	}
+	affiliateSubscriber := event.NewSubscriber(nc, serviceName+"-affiliates", log)
+	lc.Add(shutdown.PhaseFlush, "affiliate-subscriber", 0, shutdown.Func(affiliateSubscriber.Close))
+	if err := affiliateService.Subscribe(affiliateSubscriber); err != nil {
+		log.Fatal(ctx, "Failed to subscribe to order events for affiliates", zap.Error(err))
+	}
+
+	// Start background workers on the elected leader only, so that scheduled jobs
+	// run once across the replicas
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	lc.Add(shutdown.PhaseFlush, "workers", 0, shutdown.Func(stopWorkers))
+	election := locks.NewElection(locks.New(rdb, serviceName), "workers", 30*time.Second, log)
+	go election.Run(workerCtx, func(ctx context.Context) {
+		var wg sync.WaitGroup
+		for _, run := range []func(){
+			func() { flashSaleService.Run(ctx, 30*time.Second) },
+			func() { loyaltyService.Run(ctx, time.Hour) },
+			func() { scheduleService.Run(ctx, time.Minute) },
+			func() { celebrationService.Run(ctx, time.Hour) },
+		} {
+			wg.Add(1)
+			go func(run func()) {
+				defer wg.Done()
+				run()
+			}(run)
+		}
+		wg.Wait()
+	})
+
+	// Initialize metrics
+	m := metrics.New(serviceName)
+	if err := m.RegisterDB(db, cfg.Database.DBName); err != nil {
+		log.Warn(ctx, "Failed to register database metrics", zap.Error(err))
+	}
+	if err := m.RegisterRedis(rdb); err != nil {
+		log.Warn(ctx, "Failed to register Redis metrics", zap.Error(err))
+	}
+
+	// Initialize health checks
+	h := health.New()
+	lc.Add(shutdown.PhaseTraffic, "health", 0, shutdown.Func(h.Shutdown))
+	h.Add("postgres", health.Database(db))
+	h.Add("redis", health.Redis(rdb))
+	h.Add("nats", health.NATS(nc))
+
+	// Initialize HTTP server
+	router := gin.Default()
+	router.Use(tracing.GinMiddleware(), m.GinMiddleware())
+	router.Use(apperrors.Middleware(log, cfg.Service.Environment, apperrors.WithTranslator(validator.ErrorTranslator)))
+	router.Use(audit.Middleware(audit.NewRecorder(js, serviceName), log))
+	m.Register(router)
+	h.Register(router)
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.HTTP.Port),
+		Handler: router,
+	}
+	lc.Add(shutdown.PhaseServers, "http", 0, shutdown.HTTPServer(httpServer))
+
+	// Register HTTP routes
+	setupHTTPRoutes(router,
+		handler.NewCouponHandler(couponService),
+		handler.NewCouponCodeHandler(codeService),
+		handler.NewWalletHandler(walletService),
+		handler.NewPromotionHandler(promotionService),
+		handler.NewFlashSaleHandler(flashSaleService),
+		handler.NewLoyaltyHandler(loyaltyService),
+		handler.NewScheduleHandler(scheduleService),
+		handler.NewAnalyticsHandler(analyticsService),
+		handler.NewRedemptionHandler(redemptionService),
+		handler.NewExperimentHandler(experimentService),
+		handler.NewAdminHandler(adminService),
+		handler.NewAffiliateHandler(affiliateService),
+		handler.NewCelebrationHandler(celebrationService),
+		handler.NewPriceHandler(priceService),
+	)
+
+	// Serve the active promotions as a subgraph of the gateway's GraphQL graph
+	schema, err := graph.NewSchema(promotionService)
+	if err != nil {
+		log.Fatal(ctx, "Failed to build GraphQL schema", zap.Error(err))
+	}
+	router.GET(cfg.GraphQL.Path, graphql.Handler(schema))
+	router.POST(cfg.GraphQL.Path, graphql.Handler(schema))
+
+	// Initialize gRPC server
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(grpcclient.UnaryServerInterceptor(), tracing.UnaryServerInterceptor(), m.UnaryServerInterceptor(), apperrors.UnaryServerInterceptor()))
+	// Register gRPC services
+	h.RegisterGRPC(grpcServer)
+	lc.Add(shutdown.PhaseServers, "grpc", 0, shutdown.GRPCServer(grpcServer))
+
+	// Start HTTP server
+	go func() {
+		log.Info(ctx, "Starting HTTP server", zap.Int("port", cfg.HTTP.Port))
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(ctx, "HTTP server failed", zap.Error(err))
+		}
+	}()
+
+	// Start gRPC server
+	go func() {
+		log.Info(ctx, "Starting gRPC server", zap.Int("port", cfg.GRPC.Port))
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+		if err != nil {
+			log.Fatal(ctx, "Failed to listen on gRPC port", zap.Error(err))
+		}
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatal(ctx, "gRPC server failed", zap.Error(err))
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown the service, failed hooks
+	// are logged by the coordinator
+	lc.Wait()
+}
+
+// Setup HTTP routes
+func setupHTTPRoutes(router *gin.Engine, couponHandler *handler.CouponHandler, codeHandler *handler.CouponCodeHandler, walletHandler *handler.WalletHandler, promotionHandler *handler.PromotionHandler, flashSaleHandler *handler.FlashSaleHandler, loyaltyHandler *handler.LoyaltyHandler, scheduleHandler *handler.ScheduleHandler, analyticsHandler *handler.AnalyticsHandler, redemptionHandler *handler.RedemptionHandler, experimentHandler *handler.ExperimentHandler, adminHandler *handler.AdminHandler, affiliateHandler *handler.AffiliateHandler, celebrationHandler *handler.CelebrationHandler, priceHandler *handler.PriceHandler) {
+	api := router.Group("/api/v1")
+	couponHandler.RegisterRoutes(api)
+	codeHandler.RegisterRoutes(api)
+	walletHandler.RegisterRoutes(api)
+	promotionHandler.RegisterRoutes(api)
+	flashSaleHandler.RegisterRoutes(api)
+	loyaltyHandler.RegisterRoutes(api)
+	scheduleHandler.RegisterRoutes(api)
+	analyticsHandler.RegisterRoutes(api)
+	redemptionHandler.RegisterRoutes(api)
+	experimentHandler.RegisterRoutes(api)
+	adminHandler.RegisterRoutes(api)
+	affiliateHandler.RegisterRoutes(api)
+	celebrationHandler.RegisterRoutes(api)
+	priceHandler.RegisterRoutes(api)
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SKUStock 表示 SKU 的可用库存
+type SKUStock struct {
+	SKUID          uint `json:"sku_id"`
+	AvailableStock int  `json:"available_stock"`
+	IsInfinite     bool `json:"is_infinite"`
+}
+
+// InventoryClient 通过 HTTP 调用库存服务
+type InventoryClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewInventoryClient 创建库存服务客户端
+func NewInventoryClient(baseURL string) *InventoryClient {
+	return &InventoryClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// stockResponse 对应库存服务 GET /api/v1/inventory/skus/:sku_id 的响应
+type stockResponse struct {
+	Data SKUStock `json:"data"`
+}
+
+// GetStock 获取 SKU 的可用库存
+func (c *InventoryClient) GetStock(ctx context.Context, skuID uint) (*SKUStock, error) {
+	url := fmt.Sprintf("%s/api/v1/inventory/skus/%d", c.baseURL, skuID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("inventory service returned status %d", resp.StatusCode)
+	}
+
+	var body stockResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return &body.Data, nil
+}
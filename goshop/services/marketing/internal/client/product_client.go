@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrProductNotFound 表示商品服务中不存在该商品
+var ErrProductNotFound = errors.New("product not found")
+
+// ProductCategory 表示商品所属的分类
+type ProductCategory struct {
+	ID uint `json:"id"`
+}
+
+// ProductInfo 表示计算促销价所需的商品信息
+type ProductInfo struct {
+	ID            uint              `json:"id"`
+	Name          string            `json:"name"`
+	RegularPrice  float64           `json:"regular_price"`
+	SalePrice     *float64          `json:"sale_price"`
+	SaleStartDate *time.Time        `json:"sale_start_date"`
+	SaleEndDate   *time.Time        `json:"sale_end_date"`
+	Categories    []ProductCategory `json:"categories"`
+}
+
+// ProductClient 通过 HTTP 调用商品服务
+type ProductClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewProductClient 创建商品服务客户端
+func NewProductClient(baseURL string) *ProductClient {
+	return &ProductClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// productResponse 对应商品服务 GET /api/v1/products/:id 的响应
+type productResponse struct {
+	Data ProductInfo `json:"data"`
+}
+
+// GetProduct 获取商品的价格和分类，商品不存在时返回 ErrProductNotFound
+func (c *ProductClient) GetProduct(ctx context.Context, productID uint) (*ProductInfo, error) {
+	url := fmt.Sprintf("%s/api/v1/products/%d", c.baseURL, productID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrProductNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("product service returned status %d", resp.StatusCode)
+	}
+
+	var body productResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return &body.Data, nil
+}
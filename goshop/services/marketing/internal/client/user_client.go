@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// UserProfile 表示发放纪念日奖励所需的用户资料
+type UserProfile struct {
+	ID        uint       `json:"id"`
+	Email     string     `json:"email"`
+	FirstName string     `json:"first_name"`
+	Birthday  *time.Time `json:"birthday"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// UserClient 通过 HTTP 调用用户服务
+type UserClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewUserClient 创建用户服务客户端
+func NewUserClient(baseURL string) *UserClient {
+	return &UserClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// celebrantsResponse 对应用户服务 GET /api/v1/users/celebrants 的响应
+type celebrantsResponse struct {
+	Data []*UserProfile `json:"data"`
+}
+
+// ListCelebrants 按 ID 顺序分页获取在指定月日过生日或注册周年的用户，
+// kind 为 birthday 或 anniversary，注册周年只返回在 before 之前注册的用户
+func (c *UserClient) ListCelebrants(ctx context.Context, kind string, month, day int, before time.Time, afterID uint, limit int) ([]*UserProfile, error) {
+	query := url.Values{}
+	query.Set("type", kind)
+	query.Set("month", strconv.Itoa(month))
+	query.Set("day", strconv.Itoa(day))
+	query.Set("after_id", strconv.FormatUint(uint64(afterID), 10))
+	query.Set("limit", strconv.Itoa(limit))
+	if !before.IsZero() {
+		query.Set("before", before.Format("2006-01-02"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/users/celebrants?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user service returned status %d", resp.StatusCode)
+	}
+
+	var body celebrantsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Data, nil
+}
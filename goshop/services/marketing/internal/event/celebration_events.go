@@ -0,0 +1,23 @@
+package event
+
+import "time"
+
+// 纪念日奖励发放后发布的事件类型，通知服务据此向用户发送祝福和奖励提醒
+const CelebrationRewardGranted = "marketing.celebration_reward_granted"
+
+// CelebrationRewardEvent 是 marketing.celebration_reward_granted 事件的数据
+type CelebrationRewardEvent struct {
+	UserID          uint       `json:"user_id"`
+	Email           string     `json:"email"`
+	FirstName       string     `json:"first_name"`
+	Type            string     `json:"type"` // birthday, anniversary
+	CampaignID      uint       `json:"campaign_id"`
+	CampaignName    string     `json:"campaign_name"`
+	Message         string     `json:"message"`
+	CelebrationDate time.Time  `json:"celebration_date"`
+	Years           int        `json:"years,omitempty"` // 注册周年数
+	UserCouponID    *uint      `json:"user_coupon_id,omitempty"`
+	CouponCode      string     `json:"coupon_code,omitempty"`
+	CouponExpiresAt *time.Time `json:"coupon_expires_at,omitempty"`
+	Points          int        `json:"points,omitempty"`
+}
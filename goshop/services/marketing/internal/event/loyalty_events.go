@@ -0,0 +1,23 @@
+package event
+
+import "time"
+
+// 积分服务发布的事件类型
+const (
+	PointsExpiring = "loyalty.points_expiring"
+	PointsExpired  = "loyalty.points_expired"
+)
+
+// PointsExpiringEvent 是 loyalty.points_expiring 事件的数据，供通知服务提醒用户使用积分
+type PointsExpiringEvent struct {
+	UserID    uint      `json:"user_id"`
+	Points    int       `json:"points"`     // 即将过期的积分
+	ExpiresAt time.Time `json:"expires_at"` // 最早的过期时间
+}
+
+// PointsExpiredEvent 是 loyalty.points_expired 事件的数据
+type PointsExpiredEvent struct {
+	UserID  uint `json:"user_id"`
+	Points  int  `json:"points"`
+	Balance int  `json:"balance"` // 过期后的积分余额
+}
@@ -0,0 +1,51 @@
+package event
+
+import "time"
+
+// 订单服务发布的事件类型
+const (
+	OrderCompleted = "order.completed"
+	OrderCancelled = "order.cancelled"
+	OrderRefunded  = "order.refunded"
+)
+
+// OrderItem 表示订单事件中的商品行
+type OrderItem struct {
+	ProductID   uint    `json:"product_id"`
+	SKUID       uint    `json:"sku_id"`
+	CategoryIDs []uint  `json:"category_ids"`
+	Quantity    int     `json:"quantity"`
+	Total       float64 `json:"total"` // 商品行实付金额
+}
+
+// AppliedDiscount 表示订单使用的优惠券或促销活动及其优惠金额
+type AppliedDiscount struct {
+	ID       uint    `json:"id"`
+	Discount float64 `json:"discount"`
+}
+
+// OrderEvent 是 order.completed 和 order.cancelled 事件的数据
+type OrderEvent struct {
+	OrderID      uint              `json:"order_id"`
+	OrderNumber  string            `json:"order_number"`
+	UserID       uint              `json:"user_id"`
+	Subtotal     float64           `json:"subtotal"`
+	Discount     float64           `json:"discount"`
+	ShippingFee  float64           `json:"shipping_fee"`
+	GrandTotal   float64           `json:"grand_total"`
+	Items        []OrderItem       `json:"items"`
+	IsFirstOrder bool              `json:"is_first_order"` // 是否为用户首单
+	Coupons      []AppliedDiscount `json:"coupons"`        // 使用的优惠券
+	Promotions   []AppliedDiscount `json:"promotions"`     // 命中的促销活动
+	PlacedAt     time.Time         `json:"placed_at"`      // 下单时间，用于推广点击归因
+}
+
+// OrderRefundEvent 是 order.refunded 事件的数据，部分退款时 RefundAmount 小于 GrandTotal
+type OrderRefundEvent struct {
+	OrderID      uint    `json:"order_id"`
+	OrderNumber  string  `json:"order_number"`
+	UserID       uint    `json:"user_id"`
+	RefundID     string  `json:"refund_id"`
+	RefundAmount float64 `json:"refund_amount"`
+	GrandTotal   float64 `json:"grand_total"`
+}
@@ -0,0 +1,56 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/yourusername/goshop/pkg/idgen"
+	"github.com/yourusername/goshop/pkg/logger"
+)
+
+// Publisher 定义事件发布接口
+type Publisher interface {
+	Publish(ctx context.Context, eventType string, data interface{}) error
+}
+
+// NATSPublisher 通过 NATS 发布事件，事件类型即为 subject
+type NATSPublisher struct {
+	conn   *nats.Conn
+	source string
+}
+
+// NewNATSPublisher 创建 NATS 事件发布者
+func NewNATSPublisher(conn *nats.Conn, source string) *NATSPublisher {
+	return &NATSPublisher{
+		conn:   conn,
+		source: source,
+	}
+}
+
+// Publish 发布事件
+func (p *NATSPublisher) Publish(ctx context.Context, eventType string, data interface{}) error {
+	id, err := idgen.Next()
+	if err != nil {
+		return fmt.Errorf("failed to generate event id: %w", err)
+	}
+	now := time.Now()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event %s: %w", eventType, err)
+	}
+	payload, err := json.Marshal(Envelope{
+		ID:         fmt.Sprintf("%s-%d", p.source, id),
+		Type:       eventType,
+		Source:     p.source,
+		TraceID:    logger.GetTraceID(ctx),
+		OccurredAt: now,
+		Data:       raw,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event %s: %w", eventType, err)
+	}
+	return p.conn.Publish(eventType, payload)
+}
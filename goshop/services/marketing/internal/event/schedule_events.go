@@ -0,0 +1,32 @@
+package event
+
+import "time"
+
+// 排期任务发布的事件类型，商品和搜索服务据此刷新缓存的促销价和活动标签
+const (
+	PromotionActivated   = "promotion.activated"
+	PromotionDeactivated = "promotion.deactivated"
+	CouponActivated      = "coupon.activated"
+	CouponDeactivated    = "coupon.deactivated"
+)
+
+// PromotionScheduleEvent 是促销活动开始或结束事件的数据
+type PromotionScheduleEvent struct {
+	PromotionID uint      `json:"promotion_id"`
+	Type        string    `json:"type"`
+	ProductIDs  []uint    `json:"product_ids"`  // 为空表示适用于全部商品
+	CategoryIDs []uint    `json:"category_ids"` // 为空表示适用于全部分类
+	StartAt     time.Time `json:"start_at"`
+	EndAt       time.Time `json:"end_at"`
+}
+
+// CouponScheduleEvent 是优惠券开始或结束事件的数据
+type CouponScheduleEvent struct {
+	CouponID    uint      `json:"coupon_id"`
+	Code        string    `json:"code"`
+	Type        string    `json:"type"`
+	ProductIDs  []uint    `json:"product_ids"`
+	CategoryIDs []uint    `json:"category_ids"`
+	StartAt     time.Time `json:"start_at"`
+	EndAt       time.Time `json:"end_at"`
+}
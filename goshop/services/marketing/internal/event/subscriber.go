@@ -0,0 +1,83 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/yourusername/goshop/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// handleTimeout 是处理单条事件的超时时间
+const handleTimeout = 30 * time.Second
+
+// Envelope 是 NATS 事件的外层结构
+type Envelope struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Source     string          `json:"source"`
+	TraceID    string          `json:"trace_id,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Handler 处理事件数据
+type Handler func(ctx context.Context, data json.RawMessage) error
+
+// Subscriber 通过 NATS 队列订阅事件，同一队列组内的多个服务实例只有一个会收到某条事件
+type Subscriber struct {
+	conn  *nats.Conn
+	queue string
+	log   *logger.Logger
+	subs  []*nats.Subscription
+}
+
+// NewSubscriber 创建 NATS 事件订阅者
+func NewSubscriber(conn *nats.Conn, queue string, log *logger.Logger) *Subscriber {
+	return &Subscriber{
+		conn:  conn,
+		queue: queue,
+		log:   log,
+	}
+}
+
+// Subscribe 订阅事件类型，事件处理失败时记录错误日志
+func (s *Subscriber) Subscribe(eventType string, handler Handler) error {
+	sub, err := s.conn.QueueSubscribe(eventType, s.queue, func(msg *nats.Msg) {
+		var env Envelope
+		if err := json.Unmarshal(msg.Data, &env); err != nil {
+			s.log.Error(context.Background(), "Failed to decode event",
+				zap.String("subject", msg.Subject),
+				zap.Error(err),
+			)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(logger.WithTraceID(context.Background(), env.TraceID), handleTimeout)
+		defer cancel()
+		if err := handler(ctx, env.Data); err != nil {
+			s.log.Error(ctx, "Failed to handle event",
+				zap.String("event_id", env.ID),
+				zap.String("event_type", env.Type),
+				zap.Error(err),
+			)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+	}
+	s.subs = append(s.subs, sub)
+	return nil
+}
+
+// Close 取消所有订阅
+func (s *Subscriber) Close() {
+	for _, sub := range s.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			s.log.Warn(context.Background(), "Failed to unsubscribe", zap.String("subject", sub.Subject), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Package graph 将当前生效的促销活动作为联邦 GraphQL 子图提供给网关。活动的适用商品和赠品引用商品子图的 Product，
+// 由网关批量向商品子图查询
+package graph
+
+import (
+	"github.com/yourusername/goshop/pkg/graphql"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// NewSchema 创建促销活动子图
+func NewSchema(promotionService *service.PromotionService) (*graphql.Schema, error) {
+	// 商品由商品子图解析，这里只返回商品 ID
+	product := &graphql.Object{
+		Name:    "Product",
+		Key:     "id",
+		Extends: true,
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID), External: true},
+		},
+	}
+
+	promotion := &graphql.Object{
+		Name: "Promotion",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID)},
+			{Name: "name", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "description", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "type", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "startAt", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "endAt", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "discountType", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "discountValue", Type: graphql.NonNullOf(graphql.Float)},
+			{Name: "minOrderAmount", Type: graphql.Float},
+			{Name: "minQuantity", Type: graphql.Int},
+			{Name: "stackable", Type: graphql.NonNullOf(graphql.Boolean)},
+			{Name: "image", Type: graphql.String},
+			{
+				// 适用商品，为空表示按分类或全场适用
+				Name: "products",
+				Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(product))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ids := p.Source.(*model.Promotion).ProductIDs
+					products := make([]map[string]interface{}, len(ids))
+					for i, id := range ids {
+						products[i] = reference(id)
+					}
+					return products, nil
+				},
+			},
+			{Name: "categoryIds", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(graphql.ID)))},
+			{
+				Name: "freeProduct",
+				Type: product,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if id := p.Source.(*model.Promotion).FreeProductID; id != nil {
+						return reference(*id), nil
+					}
+					return nil, nil
+				},
+			},
+			{Name: "freeProductQty", Type: graphql.Int},
+		},
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{
+				Name: "promotions",
+				Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(promotion))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return promotionService.ListActive(p.Context)
+				},
+			},
+		},
+	}
+	return graphql.NewSubgraph(query)
+}
+
+// reference 返回由其他子图解析的实体的引用
+func reference(id uint) map[string]interface{} {
+	return map[string]interface{}{"id": id}
+}
@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// AdminHandler 处理优惠券和促销活动后台管理相关的 HTTP 请求
+type AdminHandler struct {
+	adminService *service.AdminService
+}
+
+// NewAdminHandler 创建后台管理处理器
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// RegisterRoutes 注册后台管理路由
+func (h *AdminHandler) RegisterRoutes(api *gin.RouterGroup) {
+	coupons := api.Group("/marketing/admin/coupons")
+	{
+		coupons.GET("", h.ListCoupons)
+		coupons.POST("", h.CreateCoupon)
+		coupons.POST("/dry-run", h.DryRunCoupon)
+		coupons.GET("/:id", h.GetCoupon)
+		coupons.PUT("/:id", h.UpdateCoupon)
+		coupons.POST("/:id/pause", h.PauseCoupon)
+		coupons.POST("/:id/resume", h.ResumeCoupon)
+		coupons.POST("/:id/archive", h.ArchiveCoupon)
+		coupons.POST("/:id/duplicate", h.DuplicateCoupon)
+	}
+
+	promotions := api.Group("/marketing/admin/promotions")
+	{
+		promotions.GET("", h.ListPromotions)
+		promotions.POST("", h.CreatePromotion)
+		promotions.POST("/dry-run", h.DryRunPromotion)
+		promotions.GET("/:id", h.GetPromotion)
+		promotions.PUT("/:id", h.UpdatePromotion)
+		promotions.POST("/:id/pause", h.PausePromotion)
+		promotions.POST("/:id/resume", h.ResumePromotion)
+		promotions.POST("/:id/archive", h.ArchivePromotion)
+		promotions.POST("/:id/duplicate", h.DuplicatePromotion)
+	}
+}
+
+// ListCoupons 分页获取优惠券
+func (h *AdminHandler) ListCoupons(c *gin.Context) {
+	list, err := h.adminService.ListCoupons(c.Request.Context(),
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetCoupon 获取优惠券
+func (h *AdminHandler) GetCoupon(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	coupon, err := h.adminService.GetCoupon(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": coupon})
+}
+
+// CreateCoupon 创建优惠券
+func (h *AdminHandler) CreateCoupon(c *gin.Context) {
+	var req service.CouponRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	coupon, err := h.adminService.CreateCoupon(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": coupon})
+}
+
+// UpdateCoupon 更新优惠券
+func (h *AdminHandler) UpdateCoupon(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.CouponRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	coupon, err := h.adminService.UpdateCoupon(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": coupon})
+}
+
+// PauseCoupon 暂停优惠券
+func (h *AdminHandler) PauseCoupon(c *gin.Context) {
+	h.setCouponActive(c, false)
+}
+
+// ResumeCoupon 恢复优惠券
+func (h *AdminHandler) ResumeCoupon(c *gin.Context) {
+	h.setCouponActive(c, true)
+}
+
+func (h *AdminHandler) setCouponActive(c *gin.Context, active bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	coupon, err := h.adminService.SetCouponActive(c.Request.Context(), id, active)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": coupon})
+}
+
+// ArchiveCoupon 归档优惠券
+func (h *AdminHandler) ArchiveCoupon(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.adminService.ArchiveCoupon(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DuplicateCoupon 复制优惠券
+func (h *AdminHandler) DuplicateCoupon(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.DuplicateCouponRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	coupon, err := h.adminService.DuplicateCoupon(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": coupon})
+}
+
+// DryRunCoupon 预览优惠券在示例购物车上的效果
+func (h *AdminHandler) DryRunCoupon(c *gin.Context) {
+	var req service.CouponDryRunRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	result, err := h.adminService.DryRunCoupon(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// ListPromotions 分页获取促销活动
+func (h *AdminHandler) ListPromotions(c *gin.Context) {
+	list, err := h.adminService.ListPromotions(c.Request.Context(),
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetPromotion 获取促销活动
+func (h *AdminHandler) GetPromotion(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	promotion, err := h.adminService.GetPromotion(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": promotion})
+}
+
+// CreatePromotion 创建促销活动
+func (h *AdminHandler) CreatePromotion(c *gin.Context) {
+	var req service.PromotionRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	promotion, err := h.adminService.CreatePromotion(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": promotion})
+}
+
+// UpdatePromotion 更新促销活动
+func (h *AdminHandler) UpdatePromotion(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.PromotionRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	promotion, err := h.adminService.UpdatePromotion(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": promotion})
+}
+
+// PausePromotion 暂停促销活动
+func (h *AdminHandler) PausePromotion(c *gin.Context) {
+	h.setPromotionActive(c, false)
+}
+
+// ResumePromotion 恢复促销活动
+func (h *AdminHandler) ResumePromotion(c *gin.Context) {
+	h.setPromotionActive(c, true)
+}
+
+func (h *AdminHandler) setPromotionActive(c *gin.Context, active bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	promotion, err := h.adminService.SetPromotionActive(c.Request.Context(), id, active)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": promotion})
+}
+
+// ArchivePromotion 归档促销活动
+func (h *AdminHandler) ArchivePromotion(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.adminService.ArchivePromotion(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DuplicatePromotion 复制促销活动
+func (h *AdminHandler) DuplicatePromotion(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	promotion, err := h.adminService.DuplicatePromotion(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": promotion})
+}
+
+// DryRunPromotion 预览促销活动在示例购物车上的效果
+func (h *AdminHandler) DryRunPromotion(c *gin.Context) {
+	var req service.PromotionDryRunRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	result, err := h.adminService.DryRunPromotion(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
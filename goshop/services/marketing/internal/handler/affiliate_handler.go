@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// affiliateCookie 保存推广访客标识的 Cookie 名称
+const affiliateCookie = "goshop_aff"
+
+// AffiliateHandler 处理推广员相关的 HTTP 请求
+type AffiliateHandler struct {
+	affiliateService *service.AffiliateService
+}
+
+// NewAffiliateHandler 创建推广员处理器
+func NewAffiliateHandler(affiliateService *service.AffiliateService) *AffiliateHandler {
+	return &AffiliateHandler{
+		affiliateService: affiliateService,
+	}
+}
+
+// RegisterRoutes 注册推广员路由
+func (h *AffiliateHandler) RegisterRoutes(api *gin.RouterGroup) {
+	affiliates := api.Group("/marketing/affiliates")
+	{
+		affiliates.GET("/r/:code", h.Redirect)
+		affiliates.POST("/identify", h.Identify)
+		affiliates.POST("", h.Apply)
+		affiliates.GET("/me", h.Me)
+		affiliates.GET("/me/stats", h.Stats)
+		affiliates.GET("/me/links", h.ListLinks)
+		affiliates.POST("/me/links", h.CreateLink)
+		affiliates.GET("/me/ledger", h.Ledger)
+		affiliates.GET("/me/payouts", h.Payouts)
+	}
+
+	admin := api.Group("/marketing/admin/affiliates")
+	{
+		admin.GET("", h.List)
+		admin.PUT("/:id", h.Update)
+		admin.GET("/:id/ledger", h.AdminLedger)
+		admin.POST("/:id/payouts", h.CreatePayout)
+	}
+
+	rules := api.Group("/marketing/admin/affiliate-commission-rules")
+	{
+		rules.GET("", h.ListCommissionRules)
+		rules.PUT("", h.SaveCommissionRule)
+		rules.DELETE("/:category_id", h.DeleteCommissionRule)
+	}
+}
+
+// Redirect 记录推广链接点击，写入推广 Cookie 后跳转到落地页
+func (h *AffiliateHandler) Redirect(c *gin.Context) {
+	visitorID, _ := c.Cookie(affiliateCookie)
+	req := &service.AffiliateClickRequest{
+		LinkCode:  c.Param("code"),
+		VisitorID: visitorID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Referer:   c.Request.Referer(),
+	}
+	if id, err := strconv.ParseUint(c.GetHeader("X-User-ID"), 10, 64); err == nil {
+		req.UserID = uint(id)
+	}
+
+	result, err := h.affiliateService.Click(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if result.CookieDays > 0 {
+		c.SetCookie(affiliateCookie, result.VisitorID, result.CookieDays*24*3600, "/", "", false, true)
+	}
+	c.Redirect(http.StatusFound, result.RedirectURL)
+}
+
+// Identify 用户登录后将推广 Cookie 中的访客点击关联到当前用户
+func (h *AffiliateHandler) Identify(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	visitorID, _ := c.Cookie(affiliateCookie)
+	if err := h.affiliateService.Identify(c.Request.Context(), visitorID, userID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Apply 申请成为推广员
+func (h *AffiliateHandler) Apply(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.ApplyAffiliateRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	affiliate, err := h.affiliateService.Apply(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": affiliate})
+}
+
+// Me 获取当前用户的推广员账户
+func (h *AffiliateHandler) Me(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	affiliate, err := h.affiliateService.Me(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": affiliate})
+}
+
+// Stats 获取推广统计，from 和 to 为日期，默认最近 30 天
+func (h *AffiliateHandler) Stats(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		return
+	}
+	stats, err := h.affiliateService.Stats(c.Request.Context(), userID, from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+// ListLinks 获取推广链接
+func (h *AffiliateHandler) ListLinks(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	links, err := h.affiliateService.ListLinks(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": links})
+}
+
+// CreateLink 创建推广链接
+func (h *AffiliateHandler) CreateLink(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.AffiliateLinkRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	link, err := h.affiliateService.CreateLink(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": link})
+}
+
+// Ledger 获取佣金明细
+func (h *AffiliateHandler) Ledger(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	ledger, err := h.affiliateService.Ledger(c.Request.Context(), userID,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ledger})
+}
+
+// Payouts 获取结算记录
+func (h *AffiliateHandler) Payouts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	payouts, err := h.affiliateService.Payouts(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": payouts})
+}
+
+// List 分页获取推广员，可按 status 过滤
+func (h *AffiliateHandler) List(c *gin.Context) {
+	list, err := h.affiliateService.List(c.Request.Context(), c.Query("status"),
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// Update 审核或调整推广员
+func (h *AffiliateHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.UpdateAffiliateRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	affiliate, err := h.affiliateService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": affiliate})
+}
+
+// AdminLedger 后台查看推广员佣金明细
+func (h *AffiliateHandler) AdminLedger(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	ledger, err := h.affiliateService.AdminLedger(c.Request.Context(), id,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ledger})
+}
+
+// CreatePayout 登记佣金结算打款
+func (h *AffiliateHandler) CreatePayout(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.AffiliatePayoutRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	payout, err := h.affiliateService.CreatePayout(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": payout})
+}
+
+// ListCommissionRules 获取分类佣金规则
+func (h *AffiliateHandler) ListCommissionRules(c *gin.Context) {
+	rules, err := h.affiliateService.ListCommissionRules(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// SaveCommissionRule 设置分类佣金比例
+func (h *AffiliateHandler) SaveCommissionRule(c *gin.Context) {
+	var req service.CommissionRuleRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	rule, err := h.affiliateService.SaveCommissionRule(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rule})
+}
+
+// DeleteCommissionRule 删除分类佣金规则
+func (h *AffiliateHandler) DeleteCommissionRule(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("category_id"), 10, 64)
+	if err != nil {
+		respondError(c, apperrors.NewBadRequest("无效的 category_id", err))
+		return
+	}
+	if err := h.affiliateService.DeleteCommissionRule(c.Request.Context(), uint(categoryID)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
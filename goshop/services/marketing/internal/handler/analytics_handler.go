@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// AnalyticsHandler 处理活动效果报表相关的 HTTP 请求
+type AnalyticsHandler struct {
+	analyticsService *service.AnalyticsService
+}
+
+// NewAnalyticsHandler 创建活动效果报表处理器
+func NewAnalyticsHandler(analyticsService *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// RegisterRoutes 注册活动效果报表路由
+func (h *AnalyticsHandler) RegisterRoutes(api *gin.RouterGroup) {
+	analytics := api.Group("/marketing/admin/analytics")
+	{
+		analytics.GET("/coupons/:id", h.CouponReport)
+		analytics.GET("/promotions/:id", h.PromotionReport)
+	}
+}
+
+// CouponReport 获取优惠券效果报表，from 和 to 为日期，默认最近 30 天
+func (h *AnalyticsHandler) CouponReport(c *gin.Context) {
+	id, from, to, ok := parseReportQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.analyticsService.CouponReport(c.Request.Context(), id, from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// PromotionReport 获取促销活动效果报表，from 和 to 为日期，默认最近 30 天
+func (h *AnalyticsHandler) PromotionReport(c *gin.Context) {
+	id, from, to, ok := parseReportQuery(c)
+	if !ok {
+		return
+	}
+	report, err := h.analyticsService.PromotionReport(c.Request.Context(), id, from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+func parseReportQuery(c *gin.Context) (uint, time.Time, time.Time, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return 0, time.Time{}, time.Time{}, false
+	}
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		return 0, time.Time{}, time.Time{}, false
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		return 0, time.Time{}, time.Time{}, false
+	}
+	return id, from, to, true
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// CelebrationHandler 处理纪念日奖励活动相关的 HTTP 请求
+type CelebrationHandler struct {
+	celebrationService *service.CelebrationService
+}
+
+// NewCelebrationHandler 创建纪念日奖励处理器
+func NewCelebrationHandler(celebrationService *service.CelebrationService) *CelebrationHandler {
+	return &CelebrationHandler{
+		celebrationService: celebrationService,
+	}
+}
+
+// RegisterRoutes 注册纪念日奖励路由
+func (h *CelebrationHandler) RegisterRoutes(api *gin.RouterGroup) {
+	celebrations := api.Group("/marketing/admin/celebrations")
+	{
+		celebrations.GET("", h.ListCampaigns)
+		celebrations.POST("", h.CreateCampaign)
+		celebrations.POST("/run", h.Run)
+		celebrations.PUT("/:id", h.UpdateCampaign)
+		celebrations.DELETE("/:id", h.DeleteCampaign)
+		celebrations.GET("/:id/grants", h.ListGrants)
+	}
+}
+
+// ListCampaigns 获取纪念日奖励活动
+func (h *CelebrationHandler) ListCampaigns(c *gin.Context) {
+	campaigns, err := h.celebrationService.ListCampaigns(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": campaigns})
+}
+
+// CreateCampaign 创建纪念日奖励活动
+func (h *CelebrationHandler) CreateCampaign(c *gin.Context) {
+	var req service.CelebrationCampaignRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	campaign, err := h.celebrationService.CreateCampaign(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": campaign})
+}
+
+// UpdateCampaign 更新纪念日奖励活动
+func (h *CelebrationHandler) UpdateCampaign(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.CelebrationCampaignRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	campaign, err := h.celebrationService.UpdateCampaign(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": campaign})
+}
+
+// DeleteCampaign 删除纪念日奖励活动
+func (h *CelebrationHandler) DeleteCampaign(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.celebrationService.DeleteCampaign(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListGrants 分页获取活动的发放记录
+func (h *CelebrationHandler) ListGrants(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	grants, err := h.celebrationService.ListGrants(c.Request.Context(), id,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": grants})
+}
+
+// Run 手动触发纪念日奖励发放，date 为发放日期，默认今天，已发放的用户会被跳过
+func (h *CelebrationHandler) Run(c *gin.Context) {
+	date, ok := parseDateQuery(c, "date")
+	if !ok {
+		return
+	}
+	if date.IsZero() {
+		date = time.Now()
+	}
+	result, err := h.celebrationService.Process(c.Request.Context(), date)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// CouponCodeHandler 处理批量优惠码相关的 HTTP 请求
+type CouponCodeHandler struct {
+	codeService *service.CouponCodeService
+}
+
+// NewCouponCodeHandler 创建批量优惠码处理器
+func NewCouponCodeHandler(codeService *service.CouponCodeService) *CouponCodeHandler {
+	return &CouponCodeHandler{
+		codeService: codeService,
+	}
+}
+
+// RegisterRoutes 注册批量优惠码路由
+func (h *CouponCodeHandler) RegisterRoutes(api *gin.RouterGroup) {
+	admin := api.Group("/marketing/admin/coupons/:id")
+	{
+		admin.POST("/codes", h.GenerateCodes)
+		admin.GET("/codes", h.ListCodes)
+		admin.GET("/codes/export", h.ExportCodes)
+		admin.GET("/code-batches", h.ListBatches)
+	}
+
+	api.POST("/marketing/coupon-codes/use", h.UseCode)
+}
+
+// GenerateCodes 批量生成优惠码
+func (h *CouponCodeHandler) GenerateCodes(c *gin.Context) {
+	couponID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.GenerateCodesRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	batch, err := h.codeService.Generate(c.Request.Context(), couponID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": batch})
+}
+
+// ListBatches 获取优惠码批次列表
+func (h *CouponCodeHandler) ListBatches(c *gin.Context) {
+	couponID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	batches, err := h.codeService.ListBatches(c.Request.Context(), couponID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": batches})
+}
+
+// ListCodes 分页获取优惠码，支持 batch_id 过滤
+func (h *CouponCodeHandler) ListCodes(c *gin.Context) {
+	couponID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	batchID, ok := parseIDQuery(c, "batch_id")
+	if !ok {
+		return
+	}
+
+	list, err := h.codeService.ListCodes(c.Request.Context(), couponID, batchID,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 50))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// ExportCodes 以 CSV 文件导出优惠码
+func (h *CouponCodeHandler) ExportCodes(c *gin.Context) {
+	couponID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	batchID, ok := parseIDQuery(c, "batch_id")
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=coupon-%d-codes.csv", couponID))
+	if err := h.codeService.Export(c.Request.Context(), couponID, batchID, c.Writer); err != nil {
+		respondError(c, err)
+		return
+	}
+}
+
+// UseCode 核销一次性优惠码
+func (h *CouponCodeHandler) UseCode(c *gin.Context) {
+	var req service.UseCodeRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	code, err := h.codeService.UseCode(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": code})
+}
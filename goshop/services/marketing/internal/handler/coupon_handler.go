@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// CouponHandler 处理优惠券校验相关的 HTTP 请求
+type CouponHandler struct {
+	couponService *service.CouponService
+}
+
+// NewCouponHandler 创建优惠券处理器
+func NewCouponHandler(couponService *service.CouponService) *CouponHandler {
+	return &CouponHandler{
+		couponService: couponService,
+	}
+}
+
+// RegisterRoutes 注册优惠券路由
+func (h *CouponHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.POST("/marketing/coupons/validate", h.Validate)
+}
+
+// Validate 校验优惠码并计算优惠
+func (h *CouponHandler) Validate(c *gin.Context) {
+	var req service.ValidateCouponRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	result, err := h.couponService.Validate(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// ExperimentHandler 处理 A/B 实验相关的 HTTP 请求
+type ExperimentHandler struct {
+	experimentService *service.ExperimentService
+}
+
+// NewExperimentHandler 创建 A/B 实验处理器
+func NewExperimentHandler(experimentService *service.ExperimentService) *ExperimentHandler {
+	return &ExperimentHandler{
+		experimentService: experimentService,
+	}
+}
+
+// RegisterRoutes 注册 A/B 实验路由
+func (h *ExperimentHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.GET("/marketing/experiments/:key/assignment", h.Assign)
+
+	admin := api.Group("/marketing/admin/experiments")
+	{
+		admin.GET("", h.List)
+		admin.POST("", h.Create)
+		admin.GET("/:id", h.Get)
+		admin.GET("/:id/results", h.Results)
+		admin.POST("/:id/start", h.transition(h.experimentService.Start))
+		admin.POST("/:id/pause", h.transition(h.experimentService.Pause))
+		admin.POST("/:id/complete", h.transition(h.experimentService.Complete))
+	}
+}
+
+// Assign 获取当前用户在实验中的分组并记录曝光
+func (h *ExperimentHandler) Assign(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	assignment, err := h.experimentService.Assign(c.Request.Context(), c.Param("key"), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": assignment})
+}
+
+// List 获取所有实验
+func (h *ExperimentHandler) List(c *gin.Context) {
+	experiments, err := h.experimentService.List(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": experiments})
+}
+
+// Create 创建实验
+func (h *ExperimentHandler) Create(c *gin.Context) {
+	var req service.CreateExperimentRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	experiment, err := h.experimentService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": experiment})
+}
+
+// Get 获取实验详情
+func (h *ExperimentHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	experiment, err := h.experimentService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": experiment})
+}
+
+// Results 获取实验各变体的效果对比
+func (h *ExperimentHandler) Results(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	results, err := h.experimentService.Results(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+// transition 返回切换实验状态的处理函数
+func (h *ExperimentHandler) transition(fn func(ctx context.Context, id uint) (*model.Experiment, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		experiment, err := fn(c.Request.Context(), id)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": experiment})
+	}
+}
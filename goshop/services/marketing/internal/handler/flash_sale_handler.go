@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// FlashSaleHandler 处理秒杀相关的 HTTP 请求
+type FlashSaleHandler struct {
+	flashSaleService *service.FlashSaleService
+}
+
+// NewFlashSaleHandler 创建秒杀处理器
+func NewFlashSaleHandler(flashSaleService *service.FlashSaleService) *FlashSaleHandler {
+	return &FlashSaleHandler{
+		flashSaleService: flashSaleService,
+	}
+}
+
+// RegisterRoutes 注册秒杀路由
+func (h *FlashSaleHandler) RegisterRoutes(api *gin.RouterGroup) {
+	marketing := api.Group("/marketing")
+	{
+		marketing.GET("/flash-sales", h.ListSessions)
+		marketing.GET("/flash-sales/:id", h.GetSession)
+		marketing.POST("/flash-sales/items/:id/token", h.AcquireToken)
+		marketing.POST("/flash-sales/purchase", h.Purchase)
+
+		marketing.GET("/flash-sale-reservations/:id", h.GetReservation)
+		marketing.POST("/flash-sale-reservations/:id/confirm", h.ConfirmReservation)
+		marketing.POST("/flash-sale-reservations/:id/release", h.ReleaseReservation)
+
+		marketing.POST("/admin/flash-sales", h.CreateSession)
+		marketing.POST("/admin/flash-sales/:id/preload", h.Preload)
+	}
+}
+
+// ListSessions 获取进行中和即将开始的秒杀场次
+func (h *FlashSaleHandler) ListSessions(c *gin.Context) {
+	sessions, err := h.flashSaleService.ListSessions(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+// GetSession 获取秒杀场次
+func (h *FlashSaleHandler) GetSession(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	session, err := h.flashSaleService.GetSession(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": session})
+}
+
+// AcquireToken 获取抢购令牌
+func (h *FlashSaleHandler) AcquireToken(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	itemID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	token, err := h.flashSaleService.AcquireToken(c.Request.Context(), userID, itemID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": token})
+}
+
+// Purchase 凭抢购令牌抢购
+func (h *FlashSaleHandler) Purchase(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.FlashSalePurchaseRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	reservation, err := h.flashSaleService.Purchase(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": reservation})
+}
+
+// GetReservation 获取秒杀预占
+func (h *FlashSaleHandler) GetReservation(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	reservation, err := h.flashSaleService.GetReservation(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": reservation})
+}
+
+// ConfirmReservation 确认秒杀预占，由订单服务在下单成功后调用
+func (h *FlashSaleHandler) ConfirmReservation(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.ConfirmReservationRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	reservation, err := h.flashSaleService.ConfirmReservation(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": reservation})
+}
+
+// ReleaseReservation 释放秒杀预占
+func (h *FlashSaleHandler) ReleaseReservation(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.flashSaleService.ReleaseReservation(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// CreateSession 创建秒杀场次
+func (h *FlashSaleHandler) CreateSession(c *gin.Context) {
+	var req service.CreateFlashSaleRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	session, err := h.flashSaleService.CreateSession(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": session})
+}
+
+// Preload 预热秒杀场次库存
+func (h *FlashSaleHandler) Preload(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.flashSaleService.Preload(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
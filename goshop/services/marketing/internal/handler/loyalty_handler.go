@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// LoyaltyHandler 处理积分相关的 HTTP 请求
+type LoyaltyHandler struct {
+	loyaltyService *service.LoyaltyService
+}
+
+// NewLoyaltyHandler 创建积分处理器
+func NewLoyaltyHandler(loyaltyService *service.LoyaltyService) *LoyaltyHandler {
+	return &LoyaltyHandler{
+		loyaltyService: loyaltyService,
+	}
+}
+
+// RegisterRoutes 注册积分路由
+func (h *LoyaltyHandler) RegisterRoutes(api *gin.RouterGroup) {
+	marketing := api.Group("/marketing")
+	{
+		marketing.GET("/users/me/points", h.GetMyPoints)
+		marketing.POST("/points/quote", h.Quote)
+		marketing.POST("/points/redeem", h.Redeem)
+	}
+}
+
+// GetMyPoints 获取当前用户的积分余额和明细
+func (h *LoyaltyHandler) GetMyPoints(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	summary, err := h.loyaltyService.Summary(c.Request.Context(), userID, parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// Quote 试算积分抵扣金额，由订单服务在结算页调用
+func (h *LoyaltyHandler) Quote(c *gin.Context) {
+	var req service.RedeemQuoteRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	quote, err := h.loyaltyService.Quote(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": quote})
+}
+
+// Redeem 使用积分抵现，由订单服务在下单时调用
+func (h *LoyaltyHandler) Redeem(c *gin.Context) {
+	var req service.RedeemRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	result, err := h.loyaltyService.Redeem(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
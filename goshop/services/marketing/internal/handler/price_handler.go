@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// PriceHandler 处理促销价预览相关的 HTTP 请求
+type PriceHandler struct {
+	priceService *service.PriceService
+}
+
+// NewPriceHandler 创建促销价预览处理器
+func NewPriceHandler(priceService *service.PriceService) *PriceHandler {
+	return &PriceHandler{
+		priceService: priceService,
+	}
+}
+
+// RegisterRoutes 注册促销价预览路由
+func (h *PriceHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.GET("/marketing/prices", h.Preview)
+}
+
+// Preview 获取商品的促销价和活动标签，product_ids 为逗号分隔的商品 ID，
+// 已登录用户按 X-User-ID 计算其可参与的活动
+func (h *PriceHandler) Preview(c *gin.Context) {
+	var productIDs []uint
+	for _, raw := range strings.Split(c.Query("product_ids"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			respondError(c, apperrors.NewBadRequest("无效的 product_ids", err))
+			return
+		}
+		productIDs = append(productIDs, uint(id))
+	}
+	var userID uint
+	if id, err := strconv.ParseUint(c.GetHeader("X-User-ID"), 10, 64); err == nil {
+		userID = uint(id)
+	}
+
+	previews, err := h.priceService.Preview(c.Request.Context(), userID, productIDs)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": previews})
+}
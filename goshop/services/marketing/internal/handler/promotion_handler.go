@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// PromotionHandler 处理促销活动相关的 HTTP 请求
+type PromotionHandler struct {
+	promotionService *service.PromotionService
+}
+
+// NewPromotionHandler 创建促销活动处理器
+func NewPromotionHandler(promotionService *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+// RegisterRoutes 注册促销活动路由
+func (h *PromotionHandler) RegisterRoutes(api *gin.RouterGroup) {
+	promotions := api.Group("/marketing/promotions")
+	{
+		promotions.GET("", h.ListPromotions)
+		promotions.POST("/evaluate", h.Evaluate)
+	}
+}
+
+// ListPromotions 获取当前生效的促销活动
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	promotions, err := h.promotionService.ListActive(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": promotions})
+}
+
+// Evaluate 计算购物车促销优惠
+func (h *PromotionHandler) Evaluate(c *gin.Context) {
+	var req service.EvaluateRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	result, err := h.promotionService.Evaluate(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
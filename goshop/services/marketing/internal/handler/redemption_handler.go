@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// RedemptionHandler 处理下单时优惠核销相关的 HTTP 请求，由订单服务调用
+type RedemptionHandler struct {
+	redemptionService *service.RedemptionService
+}
+
+// NewRedemptionHandler 创建核销处理器
+func NewRedemptionHandler(redemptionService *service.RedemptionService) *RedemptionHandler {
+	return &RedemptionHandler{
+		redemptionService: redemptionService,
+	}
+}
+
+// RegisterRoutes 注册核销路由
+func (h *RedemptionHandler) RegisterRoutes(api *gin.RouterGroup) {
+	marketing := api.Group("/marketing")
+	{
+		marketing.POST("/coupons/redeem", h.RedeemCoupon)
+		marketing.POST("/promotions/redeem", h.RedeemPromotions)
+		marketing.POST("/orders/:id/release", h.ReleaseOrder)
+	}
+}
+
+// RedeemCoupon 下单时核销优惠券
+func (h *RedemptionHandler) RedeemCoupon(c *gin.Context) {
+	var req service.RedeemCouponRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	usage, err := h.redemptionService.RedeemCoupon(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": usage})
+}
+
+// RedeemPromotions 下单时记录促销活动参与
+func (h *RedemptionHandler) RedeemPromotions(c *gin.Context) {
+	var req service.RedeemPromotionsRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	usages, err := h.redemptionService.RedeemPromotions(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": usages})
+}
+
+// ReleaseOrder 下单失败时回退订单占用的优惠券和活动名额
+func (h *RedemptionHandler) ReleaseOrder(c *gin.Context) {
+	orderID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	result, err := h.redemptionService.ReleaseOrder(c.Request.Context(), orderID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
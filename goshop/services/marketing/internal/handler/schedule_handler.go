@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// ScheduleHandler 处理活动排期相关的 HTTP 请求
+type ScheduleHandler struct {
+	scheduleService *service.ScheduleService
+}
+
+// NewScheduleHandler 创建活动排期处理器
+func NewScheduleHandler(scheduleService *service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// RegisterRoutes 注册活动排期路由
+func (h *ScheduleHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.GET("/marketing/admin/schedule", h.Overview)
+}
+
+// Overview 获取即将开始和即将结束的促销活动和优惠券，hours 默认为 24，最多 720
+func (h *ScheduleHandler) Overview(c *gin.Context) {
+	hours := parseIntQuery(c, "hours", 24)
+	if hours < 1 || hours > 720 {
+		hours = 24
+	}
+	overview, err := h.scheduleService.Overview(c.Request.Context(), time.Duration(hours)*time.Hour)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": overview})
+}
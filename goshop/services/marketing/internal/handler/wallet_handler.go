@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/marketing/internal/service"
+)
+
+// WalletHandler 处理用户券包相关的 HTTP 请求
+type WalletHandler struct {
+	walletService *service.WalletService
+}
+
+// NewWalletHandler 创建券包处理器
+func NewWalletHandler(walletService *service.WalletService) *WalletHandler {
+	return &WalletHandler{
+		walletService: walletService,
+	}
+}
+
+// RegisterRoutes 注册券包路由
+func (h *WalletHandler) RegisterRoutes(api *gin.RouterGroup) {
+	marketing := api.Group("/marketing")
+	{
+		marketing.GET("/coupons", h.ListClaimable)
+		marketing.POST("/coupons/:id/claim", h.Claim)
+		marketing.GET("/users/me/coupons", h.ListMyCoupons)
+		marketing.POST("/admin/user-coupons", h.Issue)
+	}
+}
+
+// ListClaimable 获取领券中心的优惠券
+func (h *WalletHandler) ListClaimable(c *gin.Context) {
+	coupons, err := h.walletService.ListClaimable(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": coupons})
+}
+
+// Claim 领取优惠券
+func (h *WalletHandler) Claim(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	couponID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userCoupon, err := h.walletService.Claim(c.Request.Context(), userID, couponID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": userCoupon})
+}
+
+// ListMyCoupons 获取当前用户的券包
+func (h *WalletHandler) ListMyCoupons(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.walletService.ListWallet(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": wallet})
+}
+
+// Issue 向指定用户定向发放优惠券
+func (h *WalletHandler) Issue(c *gin.Context) {
+	var req service.IssueCouponRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	result, err := h.walletService.Issue(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+)
+
+// 推广员状态
+const (
+	AffiliateStatusPending   = "pending"   // 已申请，待审核
+	AffiliateStatusActive    = "active"    // 已通过，可以推广
+	AffiliateStatusSuspended = "suspended" // 已停用，新的点击和订单不再计佣
+)
+
+// 推广员账本交易类型
+const (
+	AffiliateEntryCommission = "commission" // 订单完成计入佣金
+	AffiliateEntryReversal   = "reversal"   // 订单取消或退款扣回佣金
+	AffiliateEntryPayout     = "payout"     // 佣金结算打款
+)
+
+// 推广员账本交易关联类型
+const (
+	AffiliateRefOrder  = "order"
+	AffiliateRefRefund = "refund"
+	AffiliateRefPayout = "payout"
+)
+
+// 推广订单状态
+const (
+	AffiliateConversionApproved = "approved" // 已计佣
+	AffiliateConversionReversed = "reversed" // 佣金已全部扣回
+)
+
+// Affiliate 表示推广员账户，Balance 为所有账本交易的累计值
+type Affiliate struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	UserID          uint           `json:"user_id" gorm:"uniqueIndex;not null"`
+	Code            string         `json:"code" gorm:"size:20;uniqueIndex;not null"` // 推广员编码，用于默认推广链接
+	Name            string         `json:"name" gorm:"size:100;not null"`
+	Email           string         `json:"email" gorm:"size:100"`
+	Website         string         `json:"website" gorm:"size:255"`
+	Status          string         `json:"status" gorm:"size:20;index;not null;default:'pending'"`
+	CookieDays      int            `json:"cookie_days" gorm:"not null;default:30"`                        // 点击归因窗口天数
+	Balance         currency.Money `json:"balance" gorm:"type:decimal(12,2);not null;default:0"`          // 待结算佣金，退款扣回时可能为负
+	TotalCommission currency.Money `json:"total_commission" gorm:"type:decimal(12,2);not null;default:0"` // 累计佣金，已扣除退款扣回的部分
+	TotalPaid       currency.Money `json:"total_paid" gorm:"type:decimal(12,2);not null;default:0"`       // 累计已结算
+	ApprovedAt      *time.Time     `json:"approved_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// AffiliateLink 表示推广员创建的推广链接，跳转时附加 UTM 参数
+type AffiliateLink struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	AffiliateID uint      `json:"affiliate_id" gorm:"index;not null"`
+	Code        string    `json:"code" gorm:"size:32;uniqueIndex;not null"` // 链接编码，出现在跳转地址中
+	Name        string    `json:"name" gorm:"size:100"`
+	TargetURL   string    `json:"target_url" gorm:"size:500;not null"` // 站内落地页路径
+	UTMSource   string    `json:"utm_source" gorm:"size:50"`
+	UTMMedium   string    `json:"utm_medium" gorm:"size:50"`
+	UTMCampaign string    `json:"utm_campaign" gorm:"size:100"`
+	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AffiliateClick 表示推广链接的一次点击，访客登录后关联到用户用于订单归因
+type AffiliateClick struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	AffiliateID uint      `json:"affiliate_id" gorm:"index;not null"`
+	LinkID      uint      `json:"link_id" gorm:"index;not null"`
+	VisitorID   string    `json:"visitor_id" gorm:"size:64;index;not null"` // 推广 Cookie 中的访客标识
+	UserID      *uint     `json:"user_id" gorm:"index"`
+	IP          string    `json:"ip" gorm:"size:45"`
+	UserAgent   string    `json:"user_agent" gorm:"size:255"`
+	Referer     string    `json:"referer" gorm:"size:500"`
+	ClickedAt   time.Time `json:"clicked_at" gorm:"index;not null"`
+}
+
+// AffiliateCommissionRule 表示分类佣金比例，CategoryID 为 0 的规则适用于未单独配置的分类
+type AffiliateCommissionRule struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CategoryID uint      `json:"category_id" gorm:"uniqueIndex;not null"`
+	Rate       float64   `json:"rate" gorm:"type:decimal(5,2);not null"` // 佣金百分比，如 5 表示商品实付金额的 5%
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AffiliateConversion 表示归因到推广员的订单及其佣金
+type AffiliateConversion struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	AffiliateID    uint           `json:"affiliate_id" gorm:"index;not null"`
+	LinkID         uint           `json:"link_id" gorm:"index;not null"`
+	ClickID        uint           `json:"click_id" gorm:"not null"`
+	OrderID        uint           `json:"order_id" gorm:"uniqueIndex;not null"`
+	OrderNumber    string         `json:"order_number" gorm:"size:50;not null"`
+	UserID         uint           `json:"user_id" gorm:"index;not null"`
+	OrderAmount    currency.Money `json:"order_amount" gorm:"type:decimal(12,2);not null"`
+	Commission     currency.Money `json:"commission" gorm:"type:decimal(12,2);not null"`
+	ReversedAmount currency.Money `json:"reversed_amount" gorm:"type:decimal(12,2);not null;default:0"`
+	Status         string         `json:"status" gorm:"size:20;not null"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"index"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// AffiliateLedgerEntry 表示推广员佣金账本中的一笔交易，Amount 为正表示入账，为负表示扣回或结算
+type AffiliateLedgerEntry struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	AffiliateID   uint           `json:"affiliate_id" gorm:"index;not null"`
+	Type          string         `json:"type" gorm:"size:20;uniqueIndex:idx_affiliate_entry_ref;not null"`
+	Amount        currency.Money `json:"amount" gorm:"type:decimal(12,2);not null"`
+	Balance       currency.Money `json:"balance" gorm:"type:decimal(12,2);not null"` // 交易后的余额
+	ReferenceType string         `json:"reference_type" gorm:"size:20;uniqueIndex:idx_affiliate_entry_ref;not null"`
+	ReferenceID   string         `json:"reference_id" gorm:"size:64;uniqueIndex:idx_affiliate_entry_ref;not null"`
+	OrderID       *uint          `json:"order_id" gorm:"index"`
+	Description   string         `json:"description" gorm:"size:255"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// AffiliatePayout 表示一次佣金结算打款
+type AffiliatePayout struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	AffiliateID uint           `json:"affiliate_id" gorm:"index;not null"`
+	Amount      currency.Money `json:"amount" gorm:"type:decimal(12,2);not null"`
+	Method      string         `json:"method" gorm:"size:30;not null"` // 打款方式，如 bank_transfer、alipay
+	Reference   string         `json:"reference" gorm:"size:100"`      // 打款流水号
+	Note        string         `json:"note" gorm:"size:255"`
+	PaidAt      time.Time      `json:"paid_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
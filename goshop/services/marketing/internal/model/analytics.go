@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+)
+
+// 活动统计的活动类型
+const (
+	CampaignTypeCoupon    = "coupon"
+	CampaignTypePromotion = "promotion"
+	CampaignTypeStore     = "store" // 全店订单，CampaignID 为 0，用作活动订单占比的基数
+)
+
+// CampaignOrder 记录订单归因到的活动，每个活动每个订单只记录一次，用于重复事件去重
+type CampaignOrder struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CampaignType  string         `json:"campaign_type" gorm:"size:20;uniqueIndex:idx_campaign_order;not null"`
+	CampaignID    uint           `json:"campaign_id" gorm:"uniqueIndex:idx_campaign_order;not null"`
+	OrderID       uint           `json:"order_id" gorm:"uniqueIndex:idx_campaign_order;not null"`
+	UserID        uint           `json:"user_id" gorm:"index;not null"`
+	Revenue       currency.Money `json:"revenue" gorm:"type:decimal(12,2);not null"`  // 订单实付金额
+	Discount      currency.Money `json:"discount" gorm:"type:decimal(10,2);not null"` // 该活动的优惠金额
+	IsNewCustomer bool           `json:"is_new_customer"`
+	CompletedAt   time.Time      `json:"completed_at" gorm:"index;not null"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// CampaignDailyStat 表示活动按天汇总的效果数据，由订单完成事件增量累加
+type CampaignDailyStat struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	CampaignType       string         `json:"campaign_type" gorm:"size:20;uniqueIndex:idx_campaign_day;not null"`
+	CampaignID         uint           `json:"campaign_id" gorm:"uniqueIndex:idx_campaign_day;not null"`
+	Date               time.Time      `json:"date" gorm:"type:date;uniqueIndex:idx_campaign_day;not null"`
+	Redemptions        int            `json:"redemptions" gorm:"not null;default:0"`                      // 使用该活动的订单数
+	Revenue            currency.Money `json:"revenue" gorm:"type:decimal(12,2);not null;default:0"`       // 归因的订单实付金额
+	DiscountCost       currency.Money `json:"discount_cost" gorm:"type:decimal(12,2);not null;default:0"` // 优惠成本
+	NewCustomers       int            `json:"new_customers" gorm:"not null;default:0"`                    // 首单用户数
+	ReturningCustomers int            `json:"returning_customers" gorm:"not null;default:0"`              // 复购用户数
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// 纪念日奖励活动类型
+const (
+	CelebrationBirthday    = "birthday"    // 用户生日
+	CelebrationAnniversary = "anniversary" // 注册周年
+)
+
+// 纪念日奖励发放状态
+const (
+	CelebrationGrantPending = "pending" // 已占用当年名额，正在发放，中断后下次运行时继续
+	CelebrationGrantGranted = "granted" // 已发放
+	CelebrationGrantFailed  = "failed"  // 发放失败，如优惠券已领完或已过期，当年不再重试
+)
+
+// CelebrationCampaign 表示在用户生日或注册周年自动发放优惠券或积分的营销活动
+type CelebrationCampaign struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:100;not null"`
+	Type      string    `json:"type" gorm:"size:20;index;not null"`   // birthday, anniversary
+	CouponID  *uint     `json:"coupon_id"`                            // 发放的优惠券，为空表示不发券
+	Points    int       `json:"points" gorm:"not null;default:0"`     // 发放的积分，0 表示不发积分
+	DaysAhead int       `json:"days_ahead" gorm:"not null;default:0"` // 提前发放天数，便于用户在纪念日当天使用
+	Message   string    `json:"message" gorm:"size:255"`              // 通知文案，由通知服务展示给用户
+	IsActive  bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CelebrationGrant 表示一次纪念日奖励发放，每个活动每个用户每年只发放一次
+type CelebrationGrant struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	CampaignID      uint      `json:"campaign_id" gorm:"uniqueIndex:idx_celebration_grant;not null"`
+	UserID          uint      `json:"user_id" gorm:"uniqueIndex:idx_celebration_grant;index;not null"`
+	Year            int       `json:"year" gorm:"uniqueIndex:idx_celebration_grant;not null"` // 纪念日所在年份
+	CelebrationDate time.Time `json:"celebration_date" gorm:"type:date;not null"`
+	Status          string    `json:"status" gorm:"size:20;not null"`
+	UserCouponID    *uint     `json:"user_coupon_id"`
+	Points          int       `json:"points" gorm:"not null;default:0"`
+	Reason          string    `json:"reason" gorm:"size:255"` // 发放失败原因
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/yourusername/goshop/pkg/currency"
 	"gorm.io/gorm"
 )
 
@@ -63,38 +64,47 @@ func (a *UintSlice) Scan(value interface{}) error {
 
 // Coupon 表示优惠券
 type Coupon struct {
-	ID                   uint           `json:"id" gorm:"primaryKey"`
-	Code                 string         `json:"code" gorm:"size:50;uniqueIndex;not null"`             // 优惠码
-	Name                 string         `json:"name" gorm:"size:100;not null"`                        // 优惠券名称
-	Description          string         `json:"description" gorm:"size:255"`                          // 优惠券描述
-	Type                 CouponType     `json:"type" gorm:"size:20;not null"`                         // 优惠券类型
-	Value                float64        `json:"value" gorm:"type:decimal(10,2);not null"`             // 优惠金额或折扣百分比
-	MinOrderAmount       float64        `json:"min_order_amount" gorm:"type:decimal(10,2);default:0"` // 最低订单金额
-	MaxDiscountAmount    *float64       `json:"max_discount_amount" gorm:"type:decimal(10,2)"`        // 最大折扣金额（对于百分比折扣）
-	StartAt              time.Time      `json:"start_at" gorm:"not null"`                             // 生效时间
-	EndAt                time.Time      `json:"end_at" gorm:"not null"`                               // 失效时间
-	TotalQuantity        int            `json:"total_quantity" gorm:"default:0"`                      // 发行量，0表示不限量
-	UsedQuantity         int            `json:"used_quantity" gorm:"default:0"`                       // 已使用数量
-	UserLimit            int            `json:"user_limit" gorm:"default:1"`                          // 每个用户可使用次数，0表示不限制
-	IsActive             bool           `json:"is_active" gorm:"default:true"`                        // 是否激活
-	ApplicableProducts   UintSlice      `json:"applicable_products" gorm:"type:jsonb"`                // 适用商品ID
-	ApplicableCategories UintSlice      `json:"applicable_categories" gorm:"type:jsonb"`              // 适用分类ID
-	ExcludedProducts     UintSlice      `json:"excluded_products" gorm:"type:jsonb"`                  // 排除商品ID
-	ExcludedCategories   UintSlice      `json:"excluded_categories" gorm:"type:jsonb"`                // 排除分类ID
-	IsForNewUser         bool           `json:"is_for_new_user" gorm:"default:false"`                 // 是否仅限新用户使用
-	CreatedAt            time.Time      `json:"created_at"`
-	UpdatedAt            time.Time      `json:"updated_at"`
-	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                   uint            `json:"id" gorm:"primaryKey"`
+	Code                 string          `json:"code" gorm:"size:50;uniqueIndex;not null"`                 // 优惠码
+	Name                 string          `json:"name" gorm:"size:100;not null"`                            // 优惠券名称
+	Description          string          `json:"description" gorm:"size:255"`                              // 优惠券描述
+	Type                 CouponType      `json:"type" gorm:"size:20;not null"`                             // 优惠券类型
+	Value                currency.Money  `json:"value" gorm:"type:decimal(10,2);not null"`                 // 优惠金额，折扣券为折扣百分比
+	MinOrderAmount       currency.Money  `json:"min_order_amount" gorm:"type:decimal(10,2);default:0"`     // 最低订单金额
+	MaxDiscountAmount    *currency.Money `json:"max_discount_amount" gorm:"type:decimal(10,2)"`            // 最大折扣金额（对于百分比折扣），包邮券为最多减免的运费
+	StartAt              time.Time       `json:"start_at" gorm:"not null"`                                 // 生效时间
+	EndAt                time.Time       `json:"end_at" gorm:"not null"`                                   // 失效时间
+	TotalQuantity        int             `json:"total_quantity" gorm:"default:0"`                          // 发行量，0表示不限量
+	UsedQuantity         int             `json:"used_quantity" gorm:"default:0"`                           // 已使用数量
+	UserLimit            int             `json:"user_limit" gorm:"default:1"`                              // 每个用户可使用次数，0表示不限制
+	IsActive             bool            `json:"is_active" gorm:"default:true"`                            // 是否激活
+	ScheduleStatus       string          `json:"schedule_status" gorm:"size:20;index;default:'scheduled'"` // 排期状态：scheduled, running, ended
+	ApplicableProducts   UintSlice       `json:"applicable_products" gorm:"type:jsonb"`                    // 适用商品ID
+	ApplicableCategories UintSlice       `json:"applicable_categories" gorm:"type:jsonb"`                  // 适用分类ID
+	ExcludedProducts     UintSlice       `json:"excluded_products" gorm:"type:jsonb"`                      // 排除商品ID
+	ExcludedCategories   UintSlice       `json:"excluded_categories" gorm:"type:jsonb"`                    // 排除分类ID
+	ShippingMethodIDs    UintSlice       `json:"shipping_method_ids" gorm:"type:jsonb"`                    // 包邮券适用的配送方式ID，为空表示不限
+	ShippingZoneIDs      UintSlice       `json:"shipping_zone_ids" gorm:"type:jsonb"`                      // 包邮券适用的配送区域ID，为空表示不限
+	ExcludedRegions      StringSlice     `json:"excluded_regions" gorm:"type:jsonb"`                       // 包邮券排除的偏远地区，省份名称或国家代码
+	IsForNewUser         bool            `json:"is_for_new_user" gorm:"default:false"`                     // 是否仅限新用户使用
+	IsClaimable          bool            `json:"is_claimable" gorm:"default:false"`                        // 是否允许用户在领券中心领取
+	ClaimedQuantity      int             `json:"claimed_quantity" gorm:"default:0"`                        // 已发放数量（领取和定向发放）
+	ValidDays            *int            `json:"valid_days"`                                               // 领取后有效天数，null表示以EndAt为准
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt  `json:"-" gorm:"index"`
 }
 
 // CouponUsage 表示优惠券使用记录
 type CouponUsage struct {
-	ID             uint      `json:"id" gorm:"primaryKey"`
-	CouponID       uint      `json:"coupon_id" gorm:"index;not null"`
-	UserID         uint      `json:"user_id" gorm:"index;not null"`
-	OrderID        uint      `json:"order_id" gorm:"index;not null"`
-	OrderNumber    string    `json:"order_number" gorm:"size:50;not null"`
-	UsedAt         time.Time `json:"used_at"`
-	DiscountAmount float64   `json:"discount_amount" gorm:"type:decimal(10,2);not null"` // 优惠金额
-	CreatedAt      time.Time `json:"created_at"`
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	CouponID       uint           `json:"coupon_id" gorm:"index;uniqueIndex:idx_coupon_usage_order;not null"`
+	UserID         uint           `json:"user_id" gorm:"index;not null"`
+	OrderID        uint           `json:"order_id" gorm:"index;uniqueIndex:idx_coupon_usage_order;not null"`
+	OrderNumber    string         `json:"order_number" gorm:"size:50;not null"`
+	CouponCodeID   *uint          `json:"coupon_code_id"` // 使用的一次性优惠码ID
+	UserCouponID   *uint          `json:"user_coupon_id"` // 使用的券包优惠券ID
+	UsedAt         time.Time      `json:"used_at"`
+	DiscountAmount currency.Money `json:"discount_amount" gorm:"type:decimal(10,2);not null"` // 优惠金额
+	CreatedAt      time.Time      `json:"created_at"`
 }
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// CouponCodeBatch 表示一次批量生成的优惠码
+type CouponCodeBatch struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CouponID     uint      `json:"coupon_id" gorm:"index;not null"` // 所属优惠券活动
+	Prefix       string    `json:"prefix" gorm:"size:20"`           // 优惠码前缀
+	SuffixLength int       `json:"suffix_length" gorm:"not null"`   // 随机后缀长度
+	Quantity     int       `json:"quantity" gorm:"not null"`        // 生成数量
+	Note         string    `json:"note" gorm:"size:255"`            // 备注，如投放渠道
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CouponCode 表示一个一次性使用的优惠码，归属于某个优惠券活动
+type CouponCode struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	CouponID    uint       `json:"coupon_id" gorm:"index;not null"`
+	BatchID     uint       `json:"batch_id" gorm:"index;not null"`
+	Code        string     `json:"code" gorm:"size:50;uniqueIndex;not null"`
+	UserID      *uint      `json:"user_id" gorm:"index"`        // 使用者ID
+	OrderID     *uint      `json:"order_id"`                    // 使用的订单ID
+	OrderNumber *string    `json:"order_number" gorm:"size:50"` // 使用的订单号
+	UsedAt      *time.Time `json:"used_at" gorm:"index"`        // 使用时间，null表示未使用
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+)
+
+// 实验状态
+const (
+	ExperimentStatusDraft     = "draft"     // 草稿，不分流
+	ExperimentStatusRunning   = "running"   // 进行中
+	ExperimentStatusPaused    = "paused"    // 已暂停，不分流，已曝光的数据保留
+	ExperimentStatusCompleted = "completed" // 已结束
+)
+
+// Experiment 表示一个 A/B 实验，用户按 Key 和用户 ID 哈希后稳定地分到某个变体
+type Experiment struct {
+	ID             uint                 `json:"id" gorm:"primaryKey"`
+	Key            string               `json:"key" gorm:"size:50;uniqueIndex;not null"` // 实验标识，参与分桶哈希，创建后不可修改
+	Name           string               `json:"name" gorm:"size:100;not null"`
+	Description    string               `json:"description" gorm:"size:500"`
+	Status         string               `json:"status" gorm:"size:20;index;not null;default:'draft'"`
+	TrafficPercent int                  `json:"traffic_percent" gorm:"not null;default:100"` // 进入实验的用户比例（0-100）
+	StartAt        time.Time            `json:"start_at" gorm:"not null"`
+	EndAt          *time.Time           `json:"end_at"` // null表示手动结束
+	Variants       []*ExperimentVariant `json:"variants,omitempty" gorm:"foreignKey:ExperimentID"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+// IsRunning 判断实验在给定时间是否在分流
+func (e *Experiment) IsRunning(at time.Time) bool {
+	return e.Status == ExperimentStatusRunning && !at.Before(e.StartAt) && (e.EndAt == nil || at.Before(*e.EndAt))
+}
+
+// ExperimentVariant 表示实验的一个变体及其投放的优惠，PromotionID 和 CouponID 指向的活动只对该变体的用户生效
+type ExperimentVariant struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	ExperimentID uint            `json:"experiment_id" gorm:"index;not null"`
+	Key          string          `json:"key" gorm:"size:50;not null"` // 变体标识，如 control、b
+	Name         string          `json:"name" gorm:"size:100"`
+	Weight       int             `json:"weight" gorm:"not null;default:1"`       // 流量权重
+	IsControl    bool            `json:"is_control" gorm:"default:false"`        // 是否为对照组
+	PromotionID  *uint           `json:"promotion_id"`                           // 投放的促销活动
+	CouponID     *uint           `json:"coupon_id"`                              // 投放的优惠券
+	CouponValue  *currency.Money `json:"coupon_value" gorm:"type:decimal(10,2)"` // 覆盖优惠券的优惠金额或折扣百分比
+	BannerID     *uint           `json:"banner_id"`                              // 展示的内容服务横幅
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// ExperimentExposure 表示用户首次看到实验变体，每个用户每个实验只记录一次
+type ExperimentExposure struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ExperimentID uint      `json:"experiment_id" gorm:"uniqueIndex:idx_experiment_exposure_user;not null"`
+	VariantID    uint      `json:"variant_id" gorm:"index;not null"`
+	UserID       uint      `json:"user_id" gorm:"uniqueIndex:idx_experiment_exposure_user;index;not null"`
+	ExposedAt    time.Time `json:"exposed_at" gorm:"not null"`
+}
+
+// ExperimentConversion 表示已曝光用户完成的订单，每个订单在每个实验只记录一次
+type ExperimentConversion struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	ExperimentID uint           `json:"experiment_id" gorm:"uniqueIndex:idx_experiment_conversion_order;not null"`
+	VariantID    uint           `json:"variant_id" gorm:"index;not null"`
+	UserID       uint           `json:"user_id" gorm:"index;not null"`
+	OrderID      uint           `json:"order_id" gorm:"uniqueIndex:idx_experiment_conversion_order;not null"`
+	Revenue      currency.Money `json:"revenue" gorm:"type:decimal(12,2);not null"`
+	ConvertedAt  time.Time      `json:"converted_at" gorm:"not null"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+)
+
+// FlashSaleSession 表示一个秒杀场次
+type FlashSaleSession struct {
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	Name      string           `json:"name" gorm:"size:100;not null"`
+	StartAt   time.Time        `json:"start_at" gorm:"index;not null"`
+	EndAt     time.Time        `json:"end_at" gorm:"index;not null"`
+	IsActive  bool             `json:"is_active" gorm:"default:true"`
+	Items     []*FlashSaleItem `json:"items,omitempty" gorm:"foreignKey:SessionID"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// IsOpen 判断场次在给定时间是否开放抢购
+func (s *FlashSaleSession) IsOpen(now time.Time) bool {
+	return s.IsActive && !now.Before(s.StartAt) && now.Before(s.EndAt)
+}
+
+// FlashSaleItem 表示秒杀场次中的商品，SaleStock 为独立于商品库存的秒杀专用库存
+type FlashSaleItem struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	SessionID     uint           `json:"session_id" gorm:"index;not null"`
+	ProductID     uint           `json:"product_id" gorm:"index;not null"`
+	SKUID         uint           `json:"sku_id" gorm:"index"`
+	FlashPrice    currency.Money `json:"flash_price" gorm:"type:decimal(10,2);not null"`    // 秒杀价
+	OriginalPrice currency.Money `json:"original_price" gorm:"type:decimal(10,2);not null"` // 原价
+	SaleStock     int            `json:"sale_stock" gorm:"not null"`                        // 秒杀库存
+	SoldCount     int            `json:"sold_count" gorm:"default:0"`                       // 已确认下单数量
+	PerUserLimit  int            `json:"per_user_limit" gorm:"default:1"`                   // 每人限购数量，0 表示不限
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// 秒杀预占状态
+const (
+	FlashSaleReservationReserved  = "reserved"
+	FlashSaleReservationConfirmed = "confirmed"
+	FlashSaleReservationReleased  = "released"
+)
+
+// FlashSaleReservation 表示用户抢购成功后对秒杀库存的预占，需在过期前由订单服务确认
+type FlashSaleReservation struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	SessionID   uint           `json:"session_id" gorm:"index;not null"`
+	ItemID      uint           `json:"item_id" gorm:"index:idx_flash_sale_item_user;not null"`
+	UserID      uint           `json:"user_id" gorm:"index:idx_flash_sale_item_user;not null"`
+	ProductID   uint           `json:"product_id" gorm:"not null"`
+	SKUID       uint           `json:"sku_id"`
+	Quantity    int            `json:"quantity" gorm:"not null"`
+	FlashPrice  currency.Money `json:"flash_price" gorm:"type:decimal(10,2);not null"`
+	Status      string         `json:"status" gorm:"size:20;index;not null;default:'reserved'"` // reserved, confirmed, released
+	OrderID     *uint          `json:"order_id"`
+	OrderNumber *string        `json:"order_number" gorm:"size:50"`
+	ExpiresAt   time.Time      `json:"expires_at" gorm:"index;not null"` // 预占过期时间，过期未确认则释放库存
+	ConfirmedAt *time.Time     `json:"confirmed_at"`
+	ReleasedAt  *time.Time     `json:"released_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// 积分交易类型
+const (
+	LoyaltyTxEarn    = "earn"    // 订单完成获得积分
+	LoyaltyTxRedeem  = "redeem"  // 下单时积分抵现
+	LoyaltyTxExpire  = "expire"  // 积分过期
+	LoyaltyTxAdjust  = "adjust"  // 后台调整
+	LoyaltyTxReverse = "reverse" // 退款扣回已获得的积分
+	LoyaltyTxRefund  = "refund"  // 退款或取消订单退还抵现积分
+	LoyaltyTxReward  = "reward"  // 生日、注册周年等营销奖励
+)
+
+// 积分交易关联类型
+const (
+	LoyaltyRefOrder  = "order"
+	LoyaltyRefRefund = "refund"
+	LoyaltyRefLot    = "lot"   // 过期交易关联到被过期的获得积分交易
+	LoyaltyRefGrant  = "grant" // 奖励交易关联到纪念日奖励发放记录
+)
+
+// LoyaltyAccount 表示用户的积分账户，Balance 为所有积分交易的累计值
+type LoyaltyAccount struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"uniqueIndex;not null"`
+	Balance     int       `json:"balance" gorm:"not null;default:0"`      // 可用积分，退款扣回时可能为负
+	TotalEarned int       `json:"total_earned" gorm:"not null;default:0"` // 累计获得积分
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"time"
 
+	"github.com/yourusername/goshop/pkg/currency"
 	"gorm.io/gorm"
 )
 
@@ -24,73 +25,92 @@ const (
 	PromotionTypeQuantityDiscount PromotionType = "quantity_discount"
 )
 
+// 促销折扣类型
+const (
+	DiscountTypeAmount     = "amount"     // 每件减免金额
+	DiscountTypePercentage = "percentage" // 按百分比减免，如 20 表示减 20%
+	DiscountTypePrice      = "price"      // 特价，DiscountValue 为活动价
+)
+
 // Promotion 表示促销活动
 type Promotion struct {
-	ID             uint           `json:"id" gorm:"primaryKey"`
-	Name           string         `json:"name" gorm:"size:100;not null"`
-	Description    string         `json:"description" gorm:"size:500"`
-	Type           PromotionType  `json:"type" gorm:"size:30;not null"`
-	StartAt        time.Time      `json:"start_at" gorm:"not null"`
-	EndAt          time.Time      `json:"end_at" gorm:"not null"`
-	IsActive       bool           `json:"is_active" gorm:"default:true"`
-	Priority       int            `json:"priority" gorm:"default:0"`                  // 优先级，越高越优先
-	ProductIDs     UintSlice      `json:"product_ids" gorm:"type:jsonb"`              // 适用商品ID
-	CategoryIDs    UintSlice      `json:"category_ids" gorm:"type:jsonb"`             // 适用分类ID
-	DiscountValue  float64        `json:"discount_value" gorm:"type:decimal(10,2)"`   // 折扣值（金额或百分比）
-	DiscountType   string         `json:"discount_type" gorm:"size:20"`               // amount或percentage
-	MinOrderAmount *float64       `json:"min_order_amount" gorm:"type:decimal(10,2)"` // 最低订单金额
-	MinQuantity    *int           `json:"min_quantity"`                               // 最低购买数量
-	MaxUsesPerUser *int           `json:"max_uses_per_user"`                          // 每个用户最大使用次数
-	TotalUses      int            `json:"total_uses" gorm:"default:0"`                // 总使用次数
-	MaxUses        *int           `json:"max_uses"`                                   // 最大使用次数，null表示不限
-	FreeProductID  *uint          `json:"free_product_id"`                            // 赠品ID
-	FreeProductQty *int           `json:"free_product_qty"`                           // 赠品数量
-	Rules          StringSlice    `json:"rules" gorm:"type:jsonb"`                    // 促销规则，例如阶梯式优惠规则
-	Image          *string        `json:"image" gorm:"size:255"`                      // 活动图片
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	Name           string          `json:"name" gorm:"size:100;not null"`
+	Description    string          `json:"description" gorm:"size:500"`
+	Type           PromotionType   `json:"type" gorm:"size:30;not null"`
+	StartAt        time.Time       `json:"start_at" gorm:"not null"`
+	EndAt          time.Time       `json:"end_at" gorm:"not null"`
+	IsActive       bool            `json:"is_active" gorm:"default:true"`
+	ScheduleStatus string          `json:"schedule_status" gorm:"size:20;index;default:'scheduled'"` // 排期状态：scheduled, running, ended
+	Priority       int             `json:"priority" gorm:"default:0"`                                // 优先级，越高越优先
+	Stackable      bool            `json:"stackable" gorm:"default:false"`                           // 是否可与其他活动叠加
+	ProductIDs     UintSlice       `json:"product_ids" gorm:"type:jsonb"`                            // 适用商品ID
+	CategoryIDs    UintSlice       `json:"category_ids" gorm:"type:jsonb"`                           // 适用分类ID
+	DiscountValue  currency.Money  `json:"discount_value" gorm:"type:decimal(10,2)"`                 // 折扣值（金额或百分比）
+	DiscountType   string          `json:"discount_type" gorm:"size:20"`                             // amount、percentage或price（特价）
+	MinOrderAmount *currency.Money `json:"min_order_amount" gorm:"type:decimal(10,2)"`               // 最低订单金额
+	MinQuantity    *int            `json:"min_quantity"`                                             // 最低购买数量
+	MaxUsesPerUser *int            `json:"max_uses_per_user"`                                        // 每个用户最大使用次数
+	TotalUses      int             `json:"total_uses" gorm:"default:0"`                              // 总使用次数
+	MaxUses        *int            `json:"max_uses"`                                                 // 最大使用次数，null表示不限
+	FreeProductID  *uint           `json:"free_product_id"`                                          // 赠品ID
+	FreeProductQty *int            `json:"free_product_qty"`                                         // 赠品数量
+	FreeSKUID      *uint           `json:"free_sku_id"`                                              // 赠品SKU，用于检查赠品库存
+	GiftPrice      *currency.Money `json:"gift_price" gorm:"type:decimal(10,2)"`                     // 赠品单价，null表示免费，设置时为加价购
+	Rules          StringSlice     `json:"rules" gorm:"type:jsonb"`                                  // 促销规则，例如阶梯式优惠规则
+	Image          *string         `json:"image" gorm:"size:255"`                                    // 活动图片
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt  `json:"-" gorm:"index"`
 }
 
 // PromotionUsage 表示促销活动使用记录
 type PromotionUsage struct {
-	ID             uint      `json:"id" gorm:"primaryKey"`
-	PromotionID    uint      `json:"promotion_id" gorm:"index;not null"`
-	UserID         uint      `json:"user_id" gorm:"index;not null"`
-	OrderID        uint      `json:"order_id" gorm:"index;not null"`
-	OrderNumber    string    `json:"order_number" gorm:"size:50;not null"`
-	DiscountAmount float64   `json:"discount_amount" gorm:"type:decimal(10,2);not null"` // 优惠金额
-	UsedAt         time.Time `json:"used_at"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	PromotionID    uint           `json:"promotion_id" gorm:"index;uniqueIndex:idx_promotion_usage_order;not null"`
+	UserID         uint           `json:"user_id" gorm:"index;not null"`
+	OrderID        uint           `json:"order_id" gorm:"index;uniqueIndex:idx_promotion_usage_order;not null"`
+	OrderNumber    string         `json:"order_number" gorm:"size:50;not null"`
+	DiscountAmount currency.Money `json:"discount_amount" gorm:"type:decimal(10,2);not null"` // 优惠金额
+	UsedAt         time.Time      `json:"used_at"`
+	CreatedAt      time.Time      `json:"created_at"`
 }
 
 // LoyaltyPointRule 表示积分规则
 type LoyaltyPointRule struct {
-	ID             uint           `json:"id" gorm:"primaryKey"`
-	Name           string         `json:"name" gorm:"size:100;not null"`
-	Description    string         `json:"description" gorm:"size:500"`
-	PointsPerSpend int            `json:"points_per_spend" gorm:"default:1"`                    // 每消费1元获得的积分
-	MinOrderAmount float64        `json:"min_order_amount" gorm:"type:decimal(10,2);default:0"` // 最低订单金额
-	IsActive       bool           `json:"is_active" gorm:"default:true"`
-	StartAt        *time.Time     `json:"start_at"` // 生效时间，null表示永久有效
-	EndAt          *time.Time     `json:"end_at"`   // 失效时间，null表示永久有效
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                  uint           `json:"id" gorm:"primaryKey"`
+	Name                string         `json:"name" gorm:"size:100;not null"`
+	Description         string         `json:"description" gorm:"size:500"`
+	PointsPerSpend      int            `json:"points_per_spend" gorm:"default:1"`                     // 每消费1元获得的积分
+	MinOrderAmount      currency.Money `json:"min_order_amount" gorm:"type:decimal(10,2);default:0"`  // 最低订单金额
+	ExcludedProductIDs  UintSlice      `json:"excluded_product_ids" gorm:"type:jsonb"`                // 不累计积分的商品ID
+	ExcludedCategoryIDs UintSlice      `json:"excluded_category_ids" gorm:"type:jsonb"`               // 不累计积分的分类ID
+	RedeemRate          int            `json:"redeem_rate" gorm:"default:100"`                        // 积分抵现比例，多少积分抵扣1元
+	MaxRedeemRatio      float64        `json:"max_redeem_ratio" gorm:"type:decimal(5,2);default:0.5"` // 积分最多抵扣订单金额的比例
+	PointsValidDays     *int           `json:"points_valid_days"`                                     // 获得积分的有效天数，null表示永不过期
+	IsActive            bool           `json:"is_active" gorm:"default:true"`
+	StartAt             *time.Time     `json:"start_at"` // 生效时间，null表示永久有效
+	EndAt               *time.Time     `json:"end_at"`   // 失效时间，null表示永久有效
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // LoyaltyPointTransaction 表示积分交易
 type LoyaltyPointTransaction struct {
-	ID            uint       `json:"id" gorm:"primaryKey"`
-	UserID        uint       `json:"user_id" gorm:"index;not null"`
-	Points        int        `json:"points" gorm:"not null"`        // 正值为获得，负值为使用
-	Balance       int        `json:"balance" gorm:"not null"`       // 交易后的积分余额
-	Type          string     `json:"type" gorm:"size:20;not null"`  // earn, redeem, expire, adjust
-	ReferenceID   *string    `json:"reference_id" gorm:"size:50"`   // 关联ID（如订单ID）
-	ReferenceType *string    `json:"reference_type" gorm:"size:20"` // 关联类型（如order）
-	Description   string     `json:"description" gorm:"size:255"`
-	ExpiresAt     *time.Time `json:"expires_at"` // 过期时间，null表示永不过期
-	CreatedAt     time.Time  `json:"created_at"`
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	UserID           uint       `json:"user_id" gorm:"index;not null"`
+	Points           int        `json:"points" gorm:"not null"`                                             // 正值为获得，负值为使用
+	Balance          int        `json:"balance" gorm:"not null"`                                            // 交易后的积分余额
+	Type             string     `json:"type" gorm:"size:20;not null;uniqueIndex:idx_loyalty_tx_reference"`  // earn, redeem, expire, adjust, reverse, refund, reward
+	ReferenceID      *string    `json:"reference_id" gorm:"size:50;uniqueIndex:idx_loyalty_tx_reference"`   // 关联ID（如订单ID）
+	ReferenceType    *string    `json:"reference_type" gorm:"size:20;uniqueIndex:idx_loyalty_tx_reference"` // 关联类型（如order）
+	OrderID          *uint      `json:"order_id" gorm:"index"`                                              // 关联订单ID，用于按订单汇总积分
+	Description      string     `json:"description" gorm:"size:255"`
+	ExpiresAt        *time.Time `json:"expires_at" gorm:"index"`             // 过期时间，null表示永不过期
+	Remaining        int        `json:"remaining" gorm:"not null;default:0"` // 获得积分中尚未使用或过期的部分，按先进先出扣减
+	ExpiryNotifiedAt *time.Time `json:"-"`                                   // 已发送即将过期通知的时间
+	CreatedAt        time.Time  `json:"created_at"`
 }
 
 // MemberLevel 表示会员等级
@@ -0,0 +1,8 @@
+package model
+
+// 促销活动和优惠券的排期状态，由排期任务在 StartAt 和 EndAt 时切换
+const (
+	ScheduleStatusScheduled = "scheduled" // 未开始
+	ScheduleStatusRunning   = "running"   // 进行中
+	ScheduleStatusEnded     = "ended"     // 已结束
+)
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// UserCouponSource 表示用户优惠券的来源
+type UserCouponSource string
+
+const (
+	// UserCouponSourceClaim 用户在领券中心领取
+	UserCouponSourceClaim UserCouponSource = "claim"
+	// UserCouponSourceWelcome 新用户注册礼包
+	UserCouponSourceWelcome UserCouponSource = "welcome"
+	// UserCouponSourceCompensation 售后补偿
+	UserCouponSourceCompensation UserCouponSource = "compensation"
+	// UserCouponSourceBirthday 生日礼券
+	UserCouponSourceBirthday UserCouponSource = "birthday"
+	// UserCouponSourceAnniversary 注册周年礼券
+	UserCouponSourceAnniversary UserCouponSource = "anniversary"
+	// UserCouponSourceAdmin 后台手动发放
+	UserCouponSourceAdmin UserCouponSource = "admin"
+)
+
+// 用户优惠券状态
+const (
+	UserCouponStatusUnused = "unused"
+	UserCouponStatusUsed   = "used"
+)
+
+// UserCoupon 表示发放到用户券包中的优惠券
+type UserCoupon struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	UserID      uint             `json:"user_id" gorm:"index:idx_user_coupon;not null"`
+	CouponID    uint             `json:"coupon_id" gorm:"index:idx_user_coupon;not null"`
+	Coupon      *Coupon          `json:"coupon,omitempty" gorm:"foreignKey:CouponID"`
+	Source      UserCouponSource `json:"source" gorm:"size:20;not null"`
+	Status      string           `json:"status" gorm:"size:20;not null;default:'unused'"` // unused, used
+	Note        string           `json:"note" gorm:"size:255"`                            // 发放备注，如补偿原因
+	StartAt     time.Time        `json:"start_at" gorm:"not null"`                        // 生效时间
+	ExpiresAt   time.Time        `json:"expires_at" gorm:"index;not null"`                // 过期时间
+	OrderID     *uint            `json:"order_id"`                                        // 使用的订单ID
+	OrderNumber *string          `json:"order_number" gorm:"size:50"`
+	UsedAt      *time.Time       `json:"used_at"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// IsUsable 判断用户优惠券在给定时间是否可用
+func (uc *UserCoupon) IsUsable(now time.Time) bool {
+	if uc.Status != UserCouponStatusUnused || now.Before(uc.StartAt) || !now.Before(uc.ExpiresAt) {
+		return false
+	}
+	return uc.Coupon == nil || uc.Coupon.IsActive
+}
@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrDuplicateAffiliateConversion 表示订单已归因过推广员
+	ErrDuplicateAffiliateConversion = errors.New("duplicate affiliate conversion")
+	// ErrDuplicateAffiliateEntry 表示相同关联的账本交易已存在
+	ErrDuplicateAffiliateEntry = errors.New("duplicate affiliate ledger entry")
+	// ErrInsufficientAffiliateBalance 表示推广员待结算佣金不足
+	ErrInsufficientAffiliateBalance = errors.New("insufficient affiliate balance")
+)
+
+// AffiliateLinkStats 表示推广链接在一段时间内的点击和转化汇总
+type AffiliateLinkStats struct {
+	LinkID      uint
+	Clicks      int64
+	Visitors    int64 // 去重访客数
+	Orders      int64
+	OrderAmount currency.Money
+	Commission  currency.Money // 扣除退款扣回后的佣金
+}
+
+// AffiliateRepository 定义推广员仓库接口
+type AffiliateRepository interface {
+	Create(ctx context.Context, affiliate *model.Affiliate) error
+	GetByID(ctx context.Context, id uint) (*model.Affiliate, error)
+	GetByUserID(ctx context.Context, userID uint) (*model.Affiliate, error)
+	List(ctx context.Context, status string, offset, limit int) ([]*model.Affiliate, int64, error)
+	Update(ctx context.Context, affiliate *model.Affiliate) error
+	CreateLink(ctx context.Context, link *model.AffiliateLink) error
+	GetLinkByCode(ctx context.Context, code string) (*model.AffiliateLink, error)
+	ListLinks(ctx context.Context, affiliateID uint) ([]*model.AffiliateLink, error)
+	RecordClick(ctx context.Context, click *model.AffiliateClick) error
+	IdentifyVisitor(ctx context.Context, visitorID string, userID uint) (int64, error)
+	LastClick(ctx context.Context, userID uint, before time.Time) (*model.AffiliateClick, error)
+	ListCommissionRules(ctx context.Context) ([]*model.AffiliateCommissionRule, error)
+	SaveCommissionRule(ctx context.Context, rule *model.AffiliateCommissionRule) error
+	DeleteCommissionRule(ctx context.Context, categoryID uint) error
+	RecordConversion(ctx context.Context, conversion *model.AffiliateConversion, entry *model.AffiliateLedgerEntry) error
+	ReverseConversion(ctx context.Context, orderID uint, ratio float64, entry *model.AffiliateLedgerEntry) (*model.AffiliateConversion, error)
+	CreatePayout(ctx context.Context, payout *model.AffiliatePayout, entry *model.AffiliateLedgerEntry) error
+	ListPayouts(ctx context.Context, affiliateID uint) ([]*model.AffiliatePayout, error)
+	ListLedger(ctx context.Context, affiliateID uint, offset, limit int) ([]*model.AffiliateLedgerEntry, int64, error)
+	LinkStats(ctx context.Context, affiliateID uint, from, to time.Time) (map[uint]*AffiliateLinkStats, error)
+}
+
+// GormAffiliateRepository 实现 AffiliateRepository 接口的 GORM 仓库
+type GormAffiliateRepository struct {
+	db *gorm.DB
+}
+
+// NewAffiliateRepository 创建推广员仓库实例
+func NewAffiliateRepository(db *gorm.DB) AffiliateRepository {
+	return &GormAffiliateRepository{
+		db: db,
+	}
+}
+
+// Create 创建推广员账户
+func (r *GormAffiliateRepository) Create(ctx context.Context, affiliate *model.Affiliate) error {
+	return r.db.WithContext(ctx).Create(affiliate).Error
+}
+
+// GetByID 根据 ID 获取推广员
+func (r *GormAffiliateRepository) GetByID(ctx context.Context, id uint) (*model.Affiliate, error) {
+	var affiliate model.Affiliate
+	err := r.db.WithContext(ctx).First(&affiliate, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &affiliate, nil
+}
+
+// GetByUserID 获取用户的推广员账户
+func (r *GormAffiliateRepository) GetByUserID(ctx context.Context, userID uint) (*model.Affiliate, error) {
+	var affiliate model.Affiliate
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&affiliate).Error
+	if err != nil {
+		return nil, err
+	}
+	return &affiliate, nil
+}
+
+// List 分页获取推广员，status 为空时不按状态过滤
+func (r *GormAffiliateRepository) List(ctx context.Context, status string, offset, limit int) ([]*model.Affiliate, int64, error) {
+	var affiliates []*model.Affiliate
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Affiliate{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&affiliates).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return affiliates, total, nil
+}
+
+// Update 更新推广员资料和状态，余额和累计金额只由账本交易维护，不会被覆盖
+func (r *GormAffiliateRepository) Update(ctx context.Context, affiliate *model.Affiliate) error {
+	return r.db.WithContext(ctx).Omit("balance", "total_commission", "total_paid").Save(affiliate).Error
+}
+
+// CreateLink 创建推广链接
+func (r *GormAffiliateRepository) CreateLink(ctx context.Context, link *model.AffiliateLink) error {
+	return r.db.WithContext(ctx).Create(link).Error
+}
+
+// GetLinkByCode 根据链接编码获取推广链接
+func (r *GormAffiliateRepository) GetLinkByCode(ctx context.Context, code string) (*model.AffiliateLink, error) {
+	var link model.AffiliateLink
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ListLinks 获取推广员的所有推广链接
+func (r *GormAffiliateRepository) ListLinks(ctx context.Context, affiliateID uint) ([]*model.AffiliateLink, error) {
+	var links []*model.AffiliateLink
+	err := r.db.WithContext(ctx).
+		Where("affiliate_id = ?", affiliateID).
+		Order("id ASC").
+		Find(&links).Error
+	return links, err
+}
+
+// RecordClick 记录推广链接点击
+func (r *GormAffiliateRepository) RecordClick(ctx context.Context, click *model.AffiliateClick) error {
+	return r.db.WithContext(ctx).Create(click).Error
+}
+
+// IdentifyVisitor 将访客登录前的点击关联到用户，返回关联的点击数
+func (r *GormAffiliateRepository) IdentifyVisitor(ctx context.Context, visitorID string, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.AffiliateClick{}).
+		Where("visitor_id = ? AND user_id IS NULL", visitorID).
+		Update("user_id", userID)
+	return result.RowsAffected, result.Error
+}
+
+// LastClick 获取用户在指定时间之前的最后一次点击
+func (r *GormAffiliateRepository) LastClick(ctx context.Context, userID uint, before time.Time) (*model.AffiliateClick, error) {
+	var click model.AffiliateClick
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND clicked_at <= ?", userID, before).
+		Order("clicked_at DESC, id DESC").
+		First(&click).Error
+	if err != nil {
+		return nil, err
+	}
+	return &click, nil
+}
+
+// ListCommissionRules 获取所有分类佣金规则
+func (r *GormAffiliateRepository) ListCommissionRules(ctx context.Context) ([]*model.AffiliateCommissionRule, error) {
+	var rules []*model.AffiliateCommissionRule
+	err := r.db.WithContext(ctx).Order("category_id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// SaveCommissionRule 按分类创建或更新佣金规则
+func (r *GormAffiliateRepository) SaveCommissionRule(ctx context.Context, rule *model.AffiliateCommissionRule) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "category_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+		}).
+		Create(rule).Error
+}
+
+// DeleteCommissionRule 删除分类佣金规则
+func (r *GormAffiliateRepository) DeleteCommissionRule(ctx context.Context, categoryID uint) error {
+	return r.db.WithContext(ctx).
+		Where("category_id = ?", categoryID).
+		Delete(&model.AffiliateCommissionRule{}).Error
+}
+
+// RecordConversion 在事务中记录推广订单并计入佣金。订单已归因过时返回 ErrDuplicateAffiliateConversion
+func (r *GormAffiliateRepository) RecordConversion(ctx context.Context, conversion *model.AffiliateConversion, entry *model.AffiliateLedgerEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(conversion)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDuplicateAffiliateConversion
+		}
+		return addLedgerEntry(tx, entry, true)
+	})
+}
+
+// ReverseConversion 按比例扣回推广订单的佣金，扣回金额不超过尚未扣回的部分。
+// entry.Amount 会被设置为实际扣回的金额（负数），订单未归因或佣金已全部扣回时返回 nil
+func (r *GormAffiliateRepository) ReverseConversion(ctx context.Context, orderID uint, ratio float64, entry *model.AffiliateLedgerEntry) (*model.AffiliateConversion, error) {
+	var reversed *model.AffiliateConversion
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var conversion model.AffiliateConversion
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ?", orderID).
+			First(&conversion).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		amount := conversion.Commission.MulRate(ratio, currency.HalfUp)
+		remaining := conversion.Commission.Sub(conversion.ReversedAmount)
+		if ratio >= 1 || amount.GreaterThan(remaining) {
+			amount = remaining
+		}
+		if !amount.IsPositive() {
+			return nil
+		}
+
+		conversion.ReversedAmount = conversion.ReversedAmount.Add(amount)
+		if !conversion.ReversedAmount.LessThan(conversion.Commission) {
+			conversion.Status = model.AffiliateConversionReversed
+		}
+		err = tx.Model(&conversion).Updates(map[string]interface{}{
+			"reversed_amount": conversion.ReversedAmount,
+			"status":          conversion.Status,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		entry.AffiliateID = conversion.AffiliateID
+		entry.Amount = amount.Neg()
+		if err := addLedgerEntry(tx, entry, true); err != nil {
+			return err
+		}
+		reversed = &conversion
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reversed, nil
+}
+
+// CreatePayout 在事务中记录结算打款并从待结算佣金中扣减，余额不足时返回 ErrInsufficientAffiliateBalance
+func (r *GormAffiliateRepository) CreatePayout(ctx context.Context, payout *model.AffiliatePayout, entry *model.AffiliateLedgerEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(payout).Error; err != nil {
+			return err
+		}
+		entry.ReferenceID = strconv.FormatUint(uint64(payout.ID), 10)
+		return addLedgerEntry(tx, entry, false)
+	})
+}
+
+// ListPayouts 获取推广员的结算记录，最新的在前
+func (r *GormAffiliateRepository) ListPayouts(ctx context.Context, affiliateID uint) ([]*model.AffiliatePayout, error) {
+	var payouts []*model.AffiliatePayout
+	err := r.db.WithContext(ctx).
+		Where("affiliate_id = ?", affiliateID).
+		Order("id DESC").
+		Find(&payouts).Error
+	return payouts, err
+}
+
+// ListLedger 分页获取推广员的账本交易，最新的在前
+func (r *GormAffiliateRepository) ListLedger(ctx context.Context, affiliateID uint, offset, limit int) ([]*model.AffiliateLedgerEntry, int64, error) {
+	var entries []*model.AffiliateLedgerEntry
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.AffiliateLedgerEntry{}).Where("affiliate_id = ?", affiliateID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// LinkStats 按推广链接汇总 [from, to) 内的点击和转化
+func (r *GormAffiliateRepository) LinkStats(ctx context.Context, affiliateID uint, from, to time.Time) (map[uint]*AffiliateLinkStats, error) {
+	stats := make(map[uint]*AffiliateLinkStats)
+	get := func(linkID uint) *AffiliateLinkStats {
+		s, ok := stats[linkID]
+		if !ok {
+			s = &AffiliateLinkStats{LinkID: linkID}
+			stats[linkID] = s
+		}
+		return s
+	}
+
+	var clicks []struct {
+		LinkID   uint
+		Clicks   int64
+		Visitors int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.AffiliateClick{}).
+		Select("link_id, COUNT(*) AS clicks, COUNT(DISTINCT visitor_id) AS visitors").
+		Where("affiliate_id = ? AND clicked_at >= ? AND clicked_at < ?", affiliateID, from, to).
+		Group("link_id").
+		Scan(&clicks).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range clicks {
+		s := get(row.LinkID)
+		s.Clicks = row.Clicks
+		s.Visitors = row.Visitors
+	}
+
+	var conversions []struct {
+		LinkID      uint
+		Orders      int64
+		OrderAmount currency.Money
+		Commission  currency.Money
+	}
+	err = r.db.WithContext(ctx).
+		Model(&model.AffiliateConversion{}).
+		Select("link_id, COUNT(*) AS orders, COALESCE(SUM(order_amount), 0) AS order_amount, COALESCE(SUM(commission - reversed_amount), 0) AS commission").
+		Where("affiliate_id = ? AND created_at >= ? AND created_at < ?", affiliateID, from, to).
+		Group("link_id").
+		Scan(&conversions).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range conversions {
+		s := get(row.LinkID)
+		s.Orders = row.Orders
+		s.OrderAmount = row.OrderAmount
+		s.Commission = row.Commission
+	}
+	return stats, nil
+}
+
+// addLedgerEntry 更新推广员余额并写入账本交易，entry.Balance 会被设置为交易后的余额。
+// allowNegative 为 false 时余额不足返回 ErrInsufficientAffiliateBalance；相同关联的交易已存在时返回 ErrDuplicateAffiliateEntry
+func addLedgerEntry(tx *gorm.DB, entry *model.AffiliateLedgerEntry, allowNegative bool) error {
+	updates := map[string]interface{}{
+		"balance": gorm.Expr("balance + ?", entry.Amount),
+	}
+	switch entry.Type {
+	case model.AffiliateEntryCommission, model.AffiliateEntryReversal:
+		updates["total_commission"] = gorm.Expr("total_commission + ?", entry.Amount)
+	case model.AffiliateEntryPayout:
+		updates["total_paid"] = gorm.Expr("total_paid - ?", entry.Amount)
+	}
+	query := tx.Model(&model.Affiliate{}).Where("id = ?", entry.AffiliateID)
+	if !allowNegative {
+		query = query.Where("balance + ? >= 0", entry.Amount)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientAffiliateBalance
+	}
+
+	var affiliate model.Affiliate
+	if err := tx.Select("balance").First(&affiliate, entry.AffiliateID).Error; err != nil {
+		return err
+	}
+	entry.Balance = affiliate.Balance
+
+	result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDuplicateAffiliateEntry
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AnalyticsRepository 定义活动效果统计仓库接口
+type AnalyticsRepository interface {
+	RecordOrder(ctx context.Context, order *model.CampaignOrder) (bool, error)
+	ListDailyStats(ctx context.Context, campaignType string, campaignID uint, from, to time.Time) ([]*model.CampaignDailyStat, error)
+}
+
+// GormAnalyticsRepository 实现 AnalyticsRepository 接口的 GORM 仓库
+type GormAnalyticsRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsRepository 创建活动效果统计仓库实例
+func NewAnalyticsRepository(db *gorm.DB) AnalyticsRepository {
+	return &GormAnalyticsRepository{
+		db: db,
+	}
+}
+
+// RecordOrder 记录订单归因并累加到当天的汇总数据，订单已记录过时返回 false
+func (r *GormAnalyticsRepository) RecordOrder(ctx context.Context, order *model.CampaignOrder) (bool, error) {
+	recorded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "campaign_type"}, {Name: "campaign_id"}, {Name: "order_id"}},
+			DoNothing: true,
+		}).Create(order)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		day := order.CompletedAt
+		date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.CampaignDailyStat{CampaignType: order.CampaignType, CampaignID: order.CampaignID, Date: date}).Error
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"redemptions":   gorm.Expr("redemptions + 1"),
+			"revenue":       gorm.Expr("revenue + ?", order.Revenue),
+			"discount_cost": gorm.Expr("discount_cost + ?", order.Discount),
+		}
+		if order.IsNewCustomer {
+			updates["new_customers"] = gorm.Expr("new_customers + 1")
+		} else {
+			updates["returning_customers"] = gorm.Expr("returning_customers + 1")
+		}
+		err = tx.Model(&model.CampaignDailyStat{}).
+			Where("campaign_type = ? AND campaign_id = ? AND date = ?", order.CampaignType, order.CampaignID, date).
+			Updates(updates).Error
+		if err != nil {
+			return err
+		}
+		recorded = true
+		return nil
+	})
+	return recorded, err
+}
+
+// ListDailyStats 获取活动在 [from, to] 日期范围内的每日汇总数据
+func (r *GormAnalyticsRepository) ListDailyStats(ctx context.Context, campaignType string, campaignID uint, from, to time.Time) ([]*model.CampaignDailyStat, error) {
+	var stats []*model.CampaignDailyStat
+	err := r.db.WithContext(ctx).
+		Where("campaign_type = ? AND campaign_id = ? AND date >= ? AND date <= ?", campaignType, campaignID, from, to).
+		Order("date ASC").
+		Find(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
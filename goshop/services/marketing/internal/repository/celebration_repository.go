@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CelebrationRepository 定义纪念日奖励仓库接口
+type CelebrationRepository interface {
+	CreateCampaign(ctx context.Context, campaign *model.CelebrationCampaign) error
+	GetCampaign(ctx context.Context, id uint) (*model.CelebrationCampaign, error)
+	ListCampaigns(ctx context.Context) ([]*model.CelebrationCampaign, error)
+	ListActiveCampaigns(ctx context.Context) ([]*model.CelebrationCampaign, error)
+	UpdateCampaign(ctx context.Context, campaign *model.CelebrationCampaign) error
+	DeleteCampaign(ctx context.Context, id uint) error
+	CreateGrant(ctx context.Context, grant *model.CelebrationGrant) (bool, error)
+	UpdateGrant(ctx context.Context, grant *model.CelebrationGrant) error
+	GetGrant(ctx context.Context, campaignID, userID uint, year int) (*model.CelebrationGrant, error)
+	ListGrants(ctx context.Context, campaignID uint, offset, limit int) ([]*model.CelebrationGrant, int64, error)
+}
+
+// GormCelebrationRepository 实现 CelebrationRepository 接口的 GORM 仓库
+type GormCelebrationRepository struct {
+	db *gorm.DB
+}
+
+// NewCelebrationRepository 创建纪念日奖励仓库实例
+func NewCelebrationRepository(db *gorm.DB) CelebrationRepository {
+	return &GormCelebrationRepository{
+		db: db,
+	}
+}
+
+// CreateCampaign 创建纪念日奖励活动
+func (r *GormCelebrationRepository) CreateCampaign(ctx context.Context, campaign *model.CelebrationCampaign) error {
+	return r.db.WithContext(ctx).Create(campaign).Error
+}
+
+// GetCampaign 根据 ID 获取纪念日奖励活动
+func (r *GormCelebrationRepository) GetCampaign(ctx context.Context, id uint) (*model.CelebrationCampaign, error) {
+	var campaign model.CelebrationCampaign
+	if err := r.db.WithContext(ctx).First(&campaign, id).Error; err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// ListCampaigns 获取全部纪念日奖励活动
+func (r *GormCelebrationRepository) ListCampaigns(ctx context.Context) ([]*model.CelebrationCampaign, error) {
+	var campaigns []*model.CelebrationCampaign
+	err := r.db.WithContext(ctx).Order("id DESC").Find(&campaigns).Error
+	return campaigns, err
+}
+
+// ListActiveCampaigns 获取启用中的纪念日奖励活动
+func (r *GormCelebrationRepository) ListActiveCampaigns(ctx context.Context) ([]*model.CelebrationCampaign, error) {
+	var campaigns []*model.CelebrationCampaign
+	err := r.db.WithContext(ctx).Where("is_active = ?", true).Order("id ASC").Find(&campaigns).Error
+	return campaigns, err
+}
+
+// UpdateCampaign 更新纪念日奖励活动
+func (r *GormCelebrationRepository) UpdateCampaign(ctx context.Context, campaign *model.CelebrationCampaign) error {
+	return r.db.WithContext(ctx).Save(campaign).Error
+}
+
+// DeleteCampaign 删除纪念日奖励活动，已发放记录保留
+func (r *GormCelebrationRepository) DeleteCampaign(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.CelebrationCampaign{}, id).Error
+}
+
+// CreateGrant 占用用户当年的发放名额，已存在同一活动、用户和年份的记录时返回 false
+func (r *GormCelebrationRepository) CreateGrant(ctx context.Context, grant *model.CelebrationGrant) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "campaign_id"}, {Name: "user_id"}, {Name: "year"}},
+			DoNothing: true,
+		}).
+		Create(grant)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateGrant 更新发放记录的状态和发放结果
+func (r *GormCelebrationRepository) UpdateGrant(ctx context.Context, grant *model.CelebrationGrant) error {
+	return r.db.WithContext(ctx).Save(grant).Error
+}
+
+// GetGrant 获取用户某年在活动中的发放记录
+func (r *GormCelebrationRepository) GetGrant(ctx context.Context, campaignID, userID uint, year int) (*model.CelebrationGrant, error) {
+	var grant model.CelebrationGrant
+	err := r.db.WithContext(ctx).
+		Where("campaign_id = ? AND user_id = ? AND year = ?", campaignID, userID, year).
+		First(&grant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// ListGrants 分页获取活动的发放记录
+func (r *GormCelebrationRepository) ListGrants(ctx context.Context, campaignID uint, offset, limit int) ([]*model.CelebrationGrant, int64, error) {
+	var grants []*model.CelebrationGrant
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.CelebrationGrant{}).Where("campaign_id = ?", campaignID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&grants).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return grants, total, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CouponCodeRepository 定义批量优惠码仓库接口
+type CouponCodeRepository interface {
+	CreateBatch(ctx context.Context, batch *model.CouponCodeBatch) error
+	GetBatch(ctx context.Context, id uint) (*model.CouponCodeBatch, error)
+	ListBatches(ctx context.Context, couponID uint) ([]*model.CouponCodeBatch, error)
+	InsertCodes(ctx context.Context, codes []*model.CouponCode) (int64, error)
+	ListCodes(ctx context.Context, couponID, batchID uint, offset, limit int) ([]*model.CouponCode, int64, error)
+	FindCodesInBatches(ctx context.Context, couponID, batchID uint, size int, fn func([]*model.CouponCode) error) error
+	GetByCode(ctx context.Context, code string) (*model.CouponCode, error)
+	MarkUsed(ctx context.Context, code string, userID, orderID uint, orderNumber string, usedAt time.Time) (bool, error)
+}
+
+// GormCouponCodeRepository 实现 CouponCodeRepository 接口的 GORM 仓库
+type GormCouponCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewCouponCodeRepository 创建批量优惠码仓库实例
+func NewCouponCodeRepository(db *gorm.DB) CouponCodeRepository {
+	return &GormCouponCodeRepository{
+		db: db,
+	}
+}
+
+// CreateBatch 创建优惠码批次
+func (r *GormCouponCodeRepository) CreateBatch(ctx context.Context, batch *model.CouponCodeBatch) error {
+	return r.db.WithContext(ctx).Create(batch).Error
+}
+
+// GetBatch 根据 ID 获取优惠码批次
+func (r *GormCouponCodeRepository) GetBatch(ctx context.Context, id uint) (*model.CouponCodeBatch, error) {
+	var batch model.CouponCodeBatch
+	err := r.db.WithContext(ctx).First(&batch, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// ListBatches 获取优惠券活动的所有优惠码批次
+func (r *GormCouponCodeRepository) ListBatches(ctx context.Context, couponID uint) ([]*model.CouponCodeBatch, error) {
+	var batches []*model.CouponCodeBatch
+	err := r.db.WithContext(ctx).
+		Where("coupon_id = ?", couponID).
+		Order("created_at DESC").
+		Find(&batches).Error
+	if err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// InsertCodes 批量插入优惠码，已存在的优惠码会被跳过，返回实际插入的数量
+func (r *GormCouponCodeRepository) InsertCodes(ctx context.Context, codes []*model.CouponCode) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).
+		CreateInBatches(codes, 1000)
+	return result.RowsAffected, result.Error
+}
+
+// ListCodes 分页获取优惠码，batchID 为 0 时返回活动下所有批次的优惠码
+func (r *GormCouponCodeRepository) ListCodes(ctx context.Context, couponID, batchID uint, offset, limit int) ([]*model.CouponCode, int64, error) {
+	var codes []*model.CouponCode
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.CouponCode{}).Where("coupon_id = ?", couponID)
+	if batchID != 0 {
+		query = query.Where("batch_id = ?", batchID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id ASC").Offset(offset).Limit(limit).Find(&codes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return codes, total, nil
+}
+
+// FindCodesInBatches 按批次遍历优惠码，用于导出大量数据
+func (r *GormCouponCodeRepository) FindCodesInBatches(ctx context.Context, couponID, batchID uint, size int, fn func([]*model.CouponCode) error) error {
+	var lastID uint
+	for {
+		var codes []*model.CouponCode
+		query := r.db.WithContext(ctx).Where("coupon_id = ? AND id > ?", couponID, lastID)
+		if batchID != 0 {
+			query = query.Where("batch_id = ?", batchID)
+		}
+		if err := query.Order("id ASC").Limit(size).Find(&codes).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		if err := fn(codes); err != nil {
+			return err
+		}
+		lastID = codes[len(codes)-1].ID
+	}
+}
+
+// GetByCode 根据优惠码获取记录
+func (r *GormCouponCodeRepository) GetByCode(ctx context.Context, code string) (*model.CouponCode, error) {
+	var couponCode model.CouponCode
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&couponCode).Error
+	if err != nil {
+		return nil, err
+	}
+	return &couponCode, nil
+}
+
+// MarkUsed 将未使用的优惠码标记为已使用，优惠码已被使用时返回 false
+func (r *GormCouponCodeRepository) MarkUsed(ctx context.Context, code string, userID, orderID uint, orderNumber string, usedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.CouponCode{}).
+		Where("code = ? AND used_at IS NULL", code).
+		Updates(map[string]interface{}{
+			"user_id":      userID,
+			"order_id":     orderID,
+			"order_number": orderNumber,
+			"used_at":      usedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrCouponExhausted 表示优惠券已达到发行量
+	ErrCouponExhausted = errors.New("coupon exhausted")
+	// ErrCouponUserLimit 表示用户已达到优惠券的使用次数上限
+	ErrCouponUserLimit = errors.New("coupon user limit reached")
+	// ErrCouponCodeUsed 表示一次性优惠码已被使用
+	ErrCouponCodeUsed = errors.New("coupon code already used")
+	// ErrUserCouponUnavailable 表示券包中的优惠券不属于该用户、已使用或已过期
+	ErrUserCouponUnavailable = errors.New("user coupon unavailable")
+)
+
+// CouponRepository 定义优惠券仓库接口
+type CouponRepository interface {
+	Create(ctx context.Context, coupon *model.Coupon) error
+	Update(ctx context.Context, coupon *model.Coupon) error
+	SetActive(ctx context.Context, id uint, active bool) error
+	Archive(ctx context.Context, id uint) error
+	List(ctx context.Context, offset, limit int) ([]*model.Coupon, int64, error)
+	GetByID(ctx context.Context, id uint) (*model.Coupon, error)
+	GetByCode(ctx context.Context, code string) (*model.Coupon, error)
+	ListClaimable(ctx context.Context, at time.Time) ([]*model.Coupon, error)
+	CountUserUsage(ctx context.Context, couponID, userID uint) (int64, error)
+	Redeem(ctx context.Context, usage *model.CouponUsage, userLimit int) error
+	ReleaseOrder(ctx context.Context, orderID uint) ([]*model.CouponUsage, error)
+	ListDueToStart(ctx context.Context, at time.Time) ([]*model.Coupon, error)
+	ListDueToEnd(ctx context.Context, at time.Time) ([]*model.Coupon, error)
+	UpdateScheduleStatus(ctx context.Context, id uint, from []string, to string) (bool, error)
+	ListStartingBetween(ctx context.Context, from, to time.Time) ([]*model.Coupon, error)
+	ListEndingBetween(ctx context.Context, from, to time.Time) ([]*model.Coupon, error)
+}
+
+// GormCouponRepository 实现 CouponRepository 接口的 GORM 仓库
+type GormCouponRepository struct {
+	db *gorm.DB
+}
+
+// NewCouponRepository 创建优惠券仓库实例
+func NewCouponRepository(db *gorm.DB) CouponRepository {
+	return &GormCouponRepository{
+		db: db,
+	}
+}
+
+// Create 创建优惠券
+func (r *GormCouponRepository) Create(ctx context.Context, coupon *model.Coupon) error {
+	return r.db.WithContext(ctx).Create(coupon).Error
+}
+
+// Update 更新优惠券，已使用和已发放数量由核销和发放流程原子维护，不会被覆盖
+func (r *GormCouponRepository) Update(ctx context.Context, coupon *model.Coupon) error {
+	return r.db.WithContext(ctx).Omit("used_quantity", "claimed_quantity").Save(coupon).Error
+}
+
+// SetActive 启用或暂停优惠券
+func (r *GormCouponRepository) SetActive(ctx context.Context, id uint, active bool) error {
+	return r.db.WithContext(ctx).Model(&model.Coupon{}).Where("id = ?", id).Update("is_active", active).Error
+}
+
+// Archive 归档（软删除）优惠券，归档后不能再领取和使用
+func (r *GormCouponRepository) Archive(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Coupon{}, id).Error
+}
+
+// List 分页获取优惠券，最新创建的在前
+func (r *GormCouponRepository) List(ctx context.Context, offset, limit int) ([]*model.Coupon, int64, error) {
+	var coupons []*model.Coupon
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Coupon{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&coupons).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return coupons, total, nil
+}
+
+// GetByID 根据 ID 获取优惠券
+func (r *GormCouponRepository) GetByID(ctx context.Context, id uint) (*model.Coupon, error) {
+	var coupon model.Coupon
+	err := r.db.WithContext(ctx).First(&coupon, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// GetByCode 根据优惠码获取优惠券
+func (r *GormCouponRepository) GetByCode(ctx context.Context, code string) (*model.Coupon, error) {
+	var coupon model.Coupon
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&coupon).Error
+	if err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// ListClaimable 获取在给定时间可领取的优惠券
+func (r *GormCouponRepository) ListClaimable(ctx context.Context, at time.Time) ([]*model.Coupon, error) {
+	var coupons []*model.Coupon
+	err := r.db.WithContext(ctx).
+		Where("is_active = ? AND is_claimable = ? AND start_at <= ? AND end_at > ?", true, true, at, at).
+		Order("end_at ASC").
+		Find(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
+
+// CountUserUsage 统计用户使用某优惠券的次数
+func (r *GormCouponRepository) CountUserUsage(ctx context.Context, couponID, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.CouponUsage{}).
+		Where("coupon_id = ? AND user_id = ?", couponID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+// Redeem 在事务中核销优惠券：按发行量条件递增已使用数量，检查每人使用次数，
+// 标记一次性优惠码和券包优惠券为已使用，并写入使用记录。
+// 同一订单重复核销时返回已有的使用记录，usage 会被替换为已有记录
+func (r *GormCouponRepository) Redeem(ctx context.Context, usage *model.CouponUsage, userLimit int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing model.CouponUsage
+		err := tx.Where("coupon_id = ? AND order_id = ?", usage.CouponID, usage.OrderID).First(&existing).Error
+		if err == nil {
+			*usage = existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// 条件更新持有优惠券的行锁直到事务结束，同一优惠券的核销在此串行，发行量和每人次数都不会并发超限
+		result := tx.Model(&model.Coupon{}).
+			Where("id = ? AND (total_quantity = 0 OR used_quantity < total_quantity)", usage.CouponID).
+			Update("used_quantity", gorm.Expr("used_quantity + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCouponExhausted
+		}
+
+		if userLimit > 0 {
+			var count int64
+			err := tx.Model(&model.CouponUsage{}).
+				Where("coupon_id = ? AND user_id = ?", usage.CouponID, usage.UserID).
+				Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count >= int64(userLimit) {
+				return ErrCouponUserLimit
+			}
+		}
+
+		if usage.CouponCodeID != nil {
+			result := tx.Model(&model.CouponCode{}).
+				Where("id = ? AND used_at IS NULL", *usage.CouponCodeID).
+				Updates(map[string]interface{}{
+					"user_id":      usage.UserID,
+					"order_id":     usage.OrderID,
+					"order_number": usage.OrderNumber,
+					"used_at":      usage.UsedAt,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrCouponCodeUsed
+			}
+		}
+
+		if usage.UserCouponID != nil {
+			result := tx.Model(&model.UserCoupon{}).
+				Where("id = ? AND user_id = ? AND coupon_id = ? AND status = ? AND start_at <= ? AND expires_at > ?",
+					*usage.UserCouponID, usage.UserID, usage.CouponID, "unused", usage.UsedAt, usage.UsedAt).
+				Updates(map[string]interface{}{
+					"status":       "used",
+					"order_id":     usage.OrderID,
+					"order_number": usage.OrderNumber,
+					"used_at":      usage.UsedAt,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrUserCouponUnavailable
+			}
+		}
+
+		return tx.Create(usage).Error
+	})
+}
+
+// ReleaseOrder 释放订单核销的优惠券：删除使用记录、回退已使用数量，并恢复一次性优惠码和券包优惠券。
+// 返回本次释放的使用记录，订单没有核销记录或已释放时返回空
+func (r *GormCouponRepository) ReleaseOrder(ctx context.Context, orderID uint) ([]*model.CouponUsage, error) {
+	var released []*model.CouponUsage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var usages []*model.CouponUsage
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ?", orderID).
+			Find(&usages).Error
+		if err != nil {
+			return err
+		}
+
+		for _, usage := range usages {
+			result := tx.Delete(&model.CouponUsage{}, usage.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			err := tx.Model(&model.Coupon{}).
+				Where("id = ? AND used_quantity > 0", usage.CouponID).
+				Update("used_quantity", gorm.Expr("used_quantity - 1")).Error
+			if err != nil {
+				return err
+			}
+			if usage.CouponCodeID != nil {
+				err := tx.Model(&model.CouponCode{}).
+					Where("id = ? AND order_id = ?", *usage.CouponCodeID, orderID).
+					Updates(map[string]interface{}{
+						"user_id":      nil,
+						"order_id":     nil,
+						"order_number": nil,
+						"used_at":      nil,
+					}).Error
+				if err != nil {
+					return err
+				}
+			}
+			if usage.UserCouponID != nil {
+				err := tx.Model(&model.UserCoupon{}).
+					Where("id = ? AND order_id = ?", *usage.UserCouponID, orderID).
+					Updates(map[string]interface{}{
+						"status":       "unused",
+						"order_id":     nil,
+						"order_number": nil,
+						"used_at":      nil,
+					}).Error
+				if err != nil {
+					return err
+				}
+			}
+			released = append(released, usage)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return released, nil
+}
+
+// ListDueToStart 获取已到开始时间但尚未切换为进行中的优惠券
+func (r *GormCouponRepository) ListDueToStart(ctx context.Context, at time.Time) ([]*model.Coupon, error) {
+	var coupons []*model.Coupon
+	err := r.db.WithContext(ctx).
+		Where("schedule_status = ? AND start_at <= ? AND end_at > ?", model.ScheduleStatusScheduled, at, at).
+		Find(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
+
+// ListDueToEnd 获取已到结束时间但尚未切换为已结束的优惠券
+func (r *GormCouponRepository) ListDueToEnd(ctx context.Context, at time.Time) ([]*model.Coupon, error) {
+	var coupons []*model.Coupon
+	err := r.db.WithContext(ctx).
+		Where("schedule_status <> ? AND end_at <= ?", model.ScheduleStatusEnded, at).
+		Find(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
+
+// UpdateScheduleStatus 将优惠券的排期状态从 from 中的任一状态切换为 to，状态已被其他实例切换时返回 false
+func (r *GormCouponRepository) UpdateScheduleStatus(ctx context.Context, id uint, from []string, to string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Coupon{}).
+		Where("id = ? AND schedule_status IN ?", id, from).
+		Update("schedule_status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListStartingBetween 获取在 [from, to) 内开始的有效优惠券
+func (r *GormCouponRepository) ListStartingBetween(ctx context.Context, from, to time.Time) ([]*model.Coupon, error) {
+	var coupons []*model.Coupon
+	err := r.db.WithContext(ctx).
+		Where("is_active = ? AND start_at >= ? AND start_at < ?", true, from, to).
+		Order("start_at ASC").
+		Find(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
+
+// ListEndingBetween 获取在 [from, to) 内结束的有效优惠券
+func (r *GormCouponRepository) ListEndingBetween(ctx context.Context, from, to time.Time) ([]*model.Coupon, error) {
+	var coupons []*model.Coupon
+	err := r.db.WithContext(ctx).
+		Where("is_active = ? AND end_at >= ? AND end_at < ?", true, from, to).
+		Order("end_at ASC").
+		Find(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
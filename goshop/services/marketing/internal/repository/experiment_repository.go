@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VariantStats 表示实验变体的曝光和转化汇总
+type VariantStats struct {
+	VariantID      uint
+	Exposures      int64
+	Conversions    int64 // 转化订单数
+	ConvertedUsers int64 // 下单用户数
+	Revenue        currency.Money
+}
+
+// ExperimentRepository 定义 A/B 实验仓库接口
+type ExperimentRepository interface {
+	Create(ctx context.Context, experiment *model.Experiment) error
+	GetByID(ctx context.Context, id uint) (*model.Experiment, error)
+	GetByKey(ctx context.Context, key string) (*model.Experiment, error)
+	List(ctx context.Context) ([]*model.Experiment, error)
+	ListUnfinished(ctx context.Context) ([]*model.Experiment, error)
+	UpdateStatus(ctx context.Context, id uint, from []string, to string) (bool, error)
+	RecordExposure(ctx context.Context, exposure *model.ExperimentExposure) error
+	ListUserExposures(ctx context.Context, userID uint, experimentIDs []uint) ([]*model.ExperimentExposure, error)
+	RecordConversion(ctx context.Context, conversion *model.ExperimentConversion) (bool, error)
+	VariantStats(ctx context.Context, experimentID uint) (map[uint]*VariantStats, error)
+}
+
+// GormExperimentRepository 实现 ExperimentRepository 接口的 GORM 仓库
+type GormExperimentRepository struct {
+	db *gorm.DB
+}
+
+// NewExperimentRepository 创建 A/B 实验仓库实例
+func NewExperimentRepository(db *gorm.DB) ExperimentRepository {
+	return &GormExperimentRepository{
+		db: db,
+	}
+}
+
+// Create 创建实验及其变体
+func (r *GormExperimentRepository) Create(ctx context.Context, experiment *model.Experiment) error {
+	return r.db.WithContext(ctx).Create(experiment).Error
+}
+
+// GetByID 根据 ID 获取实验及其变体
+func (r *GormExperimentRepository) GetByID(ctx context.Context, id uint) (*model.Experiment, error) {
+	var experiment model.Experiment
+	err := r.db.WithContext(ctx).Preload("Variants").First(&experiment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// GetByKey 根据标识获取实验及其变体
+func (r *GormExperimentRepository) GetByKey(ctx context.Context, key string) (*model.Experiment, error) {
+	var experiment model.Experiment
+	err := r.db.WithContext(ctx).Preload("Variants").Where("key = ?", key).First(&experiment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// List 获取所有实验，最新创建的在前
+func (r *GormExperimentRepository) List(ctx context.Context) ([]*model.Experiment, error) {
+	var experiments []*model.Experiment
+	err := r.db.WithContext(ctx).Preload("Variants").Order("id DESC").Find(&experiments).Error
+	if err != nil {
+		return nil, err
+	}
+	return experiments, nil
+}
+
+// ListUnfinished 获取尚未结束的实验（草稿、进行中和已暂停）
+func (r *GormExperimentRepository) ListUnfinished(ctx context.Context) ([]*model.Experiment, error) {
+	var experiments []*model.Experiment
+	err := r.db.WithContext(ctx).
+		Preload("Variants").
+		Where("status IN ?", []string{model.ExperimentStatusDraft, model.ExperimentStatusRunning, model.ExperimentStatusPaused}).
+		Find(&experiments).Error
+	if err != nil {
+		return nil, err
+	}
+	return experiments, nil
+}
+
+// UpdateStatus 将实验状态从 from 中的任一状态切换为 to，当前状态不在 from 中时返回 false
+func (r *GormExperimentRepository) UpdateStatus(ctx context.Context, id uint, from []string, to string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Experiment{}).
+		Where("id = ? AND status IN ?", id, from).
+		Update("status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RecordExposure 记录用户曝光，用户已曝光过该实验时保留首次曝光
+func (r *GormExperimentRepository) RecordExposure(ctx context.Context, exposure *model.ExperimentExposure) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "experiment_id"}, {Name: "user_id"}},
+			DoNothing: true,
+		}).
+		Create(exposure).Error
+}
+
+// ListUserExposures 获取用户在给定实验中的曝光记录
+func (r *GormExperimentRepository) ListUserExposures(ctx context.Context, userID uint, experimentIDs []uint) ([]*model.ExperimentExposure, error) {
+	var exposures []*model.ExperimentExposure
+	if len(experimentIDs) == 0 {
+		return exposures, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND experiment_id IN ?", userID, experimentIDs).
+		Find(&exposures).Error
+	if err != nil {
+		return nil, err
+	}
+	return exposures, nil
+}
+
+// RecordConversion 记录转化订单，订单已记录过时返回 false
+func (r *GormExperimentRepository) RecordConversion(ctx context.Context, conversion *model.ExperimentConversion) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "experiment_id"}, {Name: "order_id"}},
+			DoNothing: true,
+		}).
+		Create(conversion)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// VariantStats 按变体汇总实验的曝光人数、转化订单数、下单人数和转化金额
+func (r *GormExperimentRepository) VariantStats(ctx context.Context, experimentID uint) (map[uint]*VariantStats, error) {
+	stats := make(map[uint]*VariantStats)
+	get := func(variantID uint) *VariantStats {
+		s, ok := stats[variantID]
+		if !ok {
+			s = &VariantStats{VariantID: variantID}
+			stats[variantID] = s
+		}
+		return s
+	}
+
+	var exposures []struct {
+		VariantID uint
+		Count     int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.ExperimentExposure{}).
+		Select("variant_id, COUNT(*) AS count").
+		Where("experiment_id = ?", experimentID).
+		Group("variant_id").
+		Scan(&exposures).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range exposures {
+		get(row.VariantID).Exposures = row.Count
+	}
+
+	var conversions []struct {
+		VariantID uint
+		Orders    int64
+		Users     int64
+		Revenue   currency.Money
+	}
+	err = r.db.WithContext(ctx).
+		Model(&model.ExperimentConversion{}).
+		Select("variant_id, COUNT(*) AS orders, COUNT(DISTINCT user_id) AS users, COALESCE(SUM(revenue), 0) AS revenue").
+		Where("experiment_id = ?", experimentID).
+		Group("variant_id").
+		Scan(&conversions).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range conversions {
+		s := get(row.VariantID)
+		s.Conversions = row.Orders
+		s.ConvertedUsers = row.Users
+		s.Revenue = row.Revenue
+	}
+	return stats, nil
+}
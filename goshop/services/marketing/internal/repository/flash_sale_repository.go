@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"gorm.io/gorm"
+)
+
+// FlashSaleRepository 定义秒杀仓库接口
+type FlashSaleRepository interface {
+	CreateSession(ctx context.Context, session *model.FlashSaleSession) error
+	GetSession(ctx context.Context, id uint) (*model.FlashSaleSession, error)
+	ListSessions(ctx context.Context, from time.Time) ([]*model.FlashSaleSession, error)
+	GetItem(ctx context.Context, id uint) (*model.FlashSaleItem, error)
+
+	CreateReservation(ctx context.Context, reservation *model.FlashSaleReservation) error
+	GetReservation(ctx context.Context, id uint) (*model.FlashSaleReservation, error)
+	ConfirmReservation(ctx context.Context, reservation *model.FlashSaleReservation, orderID uint, orderNumber string, at time.Time) (bool, error)
+	ReleaseReservation(ctx context.Context, id uint, at time.Time) (bool, error)
+	ListExpiredReservations(ctx context.Context, at time.Time, limit int) ([]*model.FlashSaleReservation, error)
+	SumUserReserved(ctx context.Context, itemID uint) (map[uint]int, error)
+	SumReserved(ctx context.Context, itemID uint) (int, error)
+}
+
+// GormFlashSaleRepository 实现 FlashSaleRepository 接口的 GORM 仓库
+type GormFlashSaleRepository struct {
+	db *gorm.DB
+}
+
+// NewFlashSaleRepository 创建秒杀仓库实例
+func NewFlashSaleRepository(db *gorm.DB) FlashSaleRepository {
+	return &GormFlashSaleRepository{
+		db: db,
+	}
+}
+
+// CreateSession 创建秒杀场次及其商品
+func (r *GormFlashSaleRepository) CreateSession(ctx context.Context, session *model.FlashSaleSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// GetSession 根据 ID 获取秒杀场次及其商品
+func (r *GormFlashSaleRepository) GetSession(ctx context.Context, id uint) (*model.FlashSaleSession, error) {
+	var session model.FlashSaleSession
+	err := r.db.WithContext(ctx).Preload("Items").First(&session, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListSessions 获取结束时间晚于 from 的有效秒杀场次
+func (r *GormFlashSaleRepository) ListSessions(ctx context.Context, from time.Time) ([]*model.FlashSaleSession, error) {
+	var sessions []*model.FlashSaleSession
+	err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("is_active = ? AND end_at > ?", true, from).
+		Order("start_at ASC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// GetItem 根据 ID 获取秒杀商品
+func (r *GormFlashSaleRepository) GetItem(ctx context.Context, id uint) (*model.FlashSaleItem, error) {
+	var item model.FlashSaleItem
+	err := r.db.WithContext(ctx).First(&item, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// CreateReservation 创建秒杀库存预占记录
+func (r *GormFlashSaleRepository) CreateReservation(ctx context.Context, reservation *model.FlashSaleReservation) error {
+	return r.db.WithContext(ctx).Create(reservation).Error
+}
+
+// GetReservation 根据 ID 获取秒杀库存预占记录
+func (r *GormFlashSaleRepository) GetReservation(ctx context.Context, id uint) (*model.FlashSaleReservation, error) {
+	var reservation model.FlashSaleReservation
+	err := r.db.WithContext(ctx).First(&reservation, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// ConfirmReservation 在事务中确认预占并累加秒杀商品的已售数量，预占已确认或已释放时返回 false
+func (r *GormFlashSaleRepository) ConfirmReservation(ctx context.Context, reservation *model.FlashSaleReservation, orderID uint, orderNumber string, at time.Time) (bool, error) {
+	confirmed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.FlashSaleReservation{}).
+			Where("id = ? AND status = ?", reservation.ID, model.FlashSaleReservationReserved).
+			Updates(map[string]interface{}{
+				"status":       model.FlashSaleReservationConfirmed,
+				"order_id":     orderID,
+				"order_number": orderNumber,
+				"confirmed_at": at,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		confirmed = true
+		return tx.Model(&model.FlashSaleItem{}).
+			Where("id = ?", reservation.ItemID).
+			Update("sold_count", gorm.Expr("sold_count + ?", reservation.Quantity)).Error
+	})
+	return confirmed, err
+}
+
+// ReleaseReservation 释放尚未确认的预占，预占已确认或已释放时返回 false
+func (r *GormFlashSaleRepository) ReleaseReservation(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.FlashSaleReservation{}).
+		Where("id = ? AND status = ?", id, model.FlashSaleReservationReserved).
+		Updates(map[string]interface{}{
+			"status":      model.FlashSaleReservationReleased,
+			"released_at": at,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListExpiredReservations 获取已过期但尚未确认的预占
+func (r *GormFlashSaleRepository) ListExpiredReservations(ctx context.Context, at time.Time, limit int) ([]*model.FlashSaleReservation, error) {
+	var reservations []*model.FlashSaleReservation
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", model.FlashSaleReservationReserved, at).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// SumUserReserved 统计秒杀商品每个用户未释放的购买数量
+func (r *GormFlashSaleRepository) SumUserReserved(ctx context.Context, itemID uint) (map[uint]int, error) {
+	var rows []struct {
+		UserID   uint
+		Quantity int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.FlashSaleReservation{}).
+		Select("user_id, SUM(quantity) AS quantity").
+		Where("item_id = ? AND status <> ?", itemID, model.FlashSaleReservationReleased).
+		Group("user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	bought := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		bought[row.UserID] = row.Quantity
+	}
+	return bought, nil
+}
+
+// SumReserved 统计秒杀商品未释放的预占数量
+func (r *GormFlashSaleRepository) SumReserved(ctx context.Context, itemID uint) (int, error) {
+	var total int
+	err := r.db.WithContext(ctx).
+		Model(&model.FlashSaleReservation{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("item_id = ? AND status <> ?", itemID, model.FlashSaleReservationReleased).
+		Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInsufficientPoints 表示积分余额不足
+	ErrInsufficientPoints = errors.New("insufficient loyalty points")
+	// ErrDuplicatePointsTransaction 表示相同关联的积分交易已存在
+	ErrDuplicatePointsTransaction = errors.New("duplicate loyalty point transaction")
+)
+
+// LoyaltyRepository 定义积分仓库接口
+type LoyaltyRepository interface {
+	GetActiveRule(ctx context.Context, at time.Time) (*model.LoyaltyPointRule, error)
+	GetAccount(ctx context.Context, userID uint) (*model.LoyaltyAccount, error)
+	ListTransactions(ctx context.Context, userID uint, offset, limit int) ([]*model.LoyaltyPointTransaction, int64, error)
+	FindTransaction(ctx context.Context, txType, refType, refID string) (*model.LoyaltyPointTransaction, error)
+	SumOrderPoints(ctx context.Context, orderID uint) (map[string]int, error)
+	Record(ctx context.Context, transaction *model.LoyaltyPointTransaction, allowNegative bool) error
+
+	ListExpiredLots(ctx context.Context, at time.Time, limit int) ([]*model.LoyaltyPointTransaction, error)
+	ExpireLot(ctx context.Context, lotID uint) (*model.LoyaltyPointTransaction, error)
+	ListExpiringLots(ctx context.Context, from, to time.Time, limit int) ([]*model.LoyaltyPointTransaction, error)
+	MarkExpiryNotified(ctx context.Context, ids []uint, at time.Time) error
+}
+
+// GormLoyaltyRepository 实现 LoyaltyRepository 接口的 GORM 仓库
+type GormLoyaltyRepository struct {
+	db *gorm.DB
+}
+
+// NewLoyaltyRepository 创建积分仓库实例
+func NewLoyaltyRepository(db *gorm.DB) LoyaltyRepository {
+	return &GormLoyaltyRepository{
+		db: db,
+	}
+}
+
+// GetActiveRule 获取在给定时间生效的积分规则，有多条时取最新创建的
+func (r *GormLoyaltyRepository) GetActiveRule(ctx context.Context, at time.Time) (*model.LoyaltyPointRule, error) {
+	var rule model.LoyaltyPointRule
+	err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Where("start_at IS NULL OR start_at <= ?", at).
+		Where("end_at IS NULL OR end_at > ?", at).
+		Order("id DESC").
+		First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetAccount 获取用户积分账户
+func (r *GormLoyaltyRepository) GetAccount(ctx context.Context, userID uint) (*model.LoyaltyAccount, error) {
+	var account model.LoyaltyAccount
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ListTransactions 分页获取用户的积分交易，按时间倒序
+func (r *GormLoyaltyRepository) ListTransactions(ctx context.Context, userID uint, offset, limit int) ([]*model.LoyaltyPointTransaction, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.LoyaltyPointTransaction{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transactions []*model.LoyaltyPointTransaction
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&transactions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return transactions, total, nil
+}
+
+// FindTransaction 根据交易类型和关联查找积分交易
+func (r *GormLoyaltyRepository) FindTransaction(ctx context.Context, txType, refType, refID string) (*model.LoyaltyPointTransaction, error) {
+	var transaction model.LoyaltyPointTransaction
+	err := r.db.WithContext(ctx).
+		Where("type = ? AND reference_type = ? AND reference_id = ?", txType, refType, refID).
+		First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// SumOrderPoints 按交易类型汇总订单相关的积分
+func (r *GormLoyaltyRepository) SumOrderPoints(ctx context.Context, orderID uint) (map[string]int, error) {
+	var rows []struct {
+		Type   string
+		Points int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.LoyaltyPointTransaction{}).
+		Select("type, SUM(points) AS points").
+		Where("order_id = ?", orderID).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	sums := make(map[string]int, len(rows))
+	for _, row := range rows {
+		sums[row.Type] = row.Points
+	}
+	return sums, nil
+}
+
+// Record 在事务中更新积分账户余额并写入积分交易，transaction.Balance 会被设置为交易后的余额。
+// 获得积分的交易作为一个积分批次，扣减积分时按先进先出扣减各批次的剩余积分。
+// allowNegative 为 false 时余额不足返回 ErrInsufficientPoints；相同关联的交易已存在时返回 ErrDuplicatePointsTransaction
+func (r *GormLoyaltyRepository) Record(ctx context.Context, transaction *model.LoyaltyPointTransaction, allowNegative bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if transaction.ReferenceType != nil && transaction.ReferenceID != nil {
+			var count int64
+			err := tx.Model(&model.LoyaltyPointTransaction{}).
+				Where("type = ? AND reference_type = ? AND reference_id = ?", transaction.Type, *transaction.ReferenceType, *transaction.ReferenceID).
+				Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrDuplicatePointsTransaction
+			}
+		}
+
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoyaltyAccount{UserID: transaction.UserID}).Error
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"balance": gorm.Expr("balance + ?", transaction.Points),
+		}
+		if transaction.Type == model.LoyaltyTxEarn || transaction.Type == model.LoyaltyTxReward {
+			updates["total_earned"] = gorm.Expr("total_earned + ?", transaction.Points)
+		}
+		query := tx.Model(&model.LoyaltyAccount{}).Where("user_id = ?", transaction.UserID)
+		if !allowNegative {
+			query = query.Where("balance + ? >= 0", transaction.Points)
+		}
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientPoints
+		}
+
+		var account model.LoyaltyAccount
+		if err := tx.Where("user_id = ?", transaction.UserID).First(&account).Error; err != nil {
+			return err
+		}
+		transaction.Balance = account.Balance
+
+		if transaction.Points > 0 {
+			// 余额为负时，新获得的积分先抵消欠款，只有抵消后剩余的部分计入批次
+			transaction.Remaining = transaction.Points
+			if account.Balance < transaction.Remaining {
+				transaction.Remaining = account.Balance
+			}
+			if transaction.Remaining < 0 {
+				transaction.Remaining = 0
+			}
+		} else if err := consumeLots(tx, transaction.UserID, -transaction.Points); err != nil {
+			return err
+		}
+		return tx.Create(transaction).Error
+	})
+}
+
+// consumeLots 按先进先出扣减用户积分批次的剩余积分，批次不足时只扣到 0
+func consumeLots(tx *gorm.DB, userID uint, points int) error {
+	var lots []*model.LoyaltyPointTransaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND remaining > 0", userID).
+		Order("id ASC").
+		Find(&lots).Error
+	if err != nil {
+		return err
+	}
+	for _, lot := range lots {
+		if points <= 0 {
+			break
+		}
+		n := lot.Remaining
+		if n > points {
+			n = points
+		}
+		err := tx.Model(&model.LoyaltyPointTransaction{}).
+			Where("id = ?", lot.ID).
+			Update("remaining", gorm.Expr("remaining - ?", n)).Error
+		if err != nil {
+			return err
+		}
+		points -= n
+	}
+	return nil
+}
+
+// ListExpiredLots 获取已过期但仍有剩余积分的批次
+func (r *GormLoyaltyRepository) ListExpiredLots(ctx context.Context, at time.Time, limit int) ([]*model.LoyaltyPointTransaction, error) {
+	var lots []*model.LoyaltyPointTransaction
+	err := r.db.WithContext(ctx).
+		Where("remaining > 0 AND expires_at <= ?", at).
+		Order("expires_at ASC, id ASC").
+		Limit(limit).
+		Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+// ExpireLot 在事务中将批次的剩余积分过期，写入过期交易并按交易流水重新计算账户余额。
+// 批次已没有剩余积分时返回 nil
+func (r *GormLoyaltyRepository) ExpireLot(ctx context.Context, lotID uint) (*model.LoyaltyPointTransaction, error) {
+	var expired *model.LoyaltyPointTransaction
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var lot model.LoyaltyPointTransaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lot, lotID).Error
+		if err != nil {
+			return err
+		}
+		if lot.Remaining <= 0 {
+			return nil
+		}
+
+		var account model.LoyaltyAccount
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", lot.UserID).First(&account).Error
+		if err != nil {
+			return err
+		}
+		var sum int
+		err = tx.Model(&model.LoyaltyPointTransaction{}).
+			Select("COALESCE(SUM(points), 0)").
+			Where("user_id = ?", lot.UserID).
+			Scan(&sum).Error
+		if err != nil {
+			return err
+		}
+
+		refType, refID := model.LoyaltyRefLot, strconv.FormatUint(uint64(lot.ID), 10)
+		expired = &model.LoyaltyPointTransaction{
+			UserID:        lot.UserID,
+			Points:        -lot.Remaining,
+			Balance:       sum - lot.Remaining,
+			Type:          model.LoyaltyTxExpire,
+			ReferenceID:   &refID,
+			ReferenceType: &refType,
+			OrderID:       lot.OrderID,
+			Description:   "积分过期",
+		}
+		if err := tx.Create(expired).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&lot).Update("remaining", 0).Error; err != nil {
+			return err
+		}
+		return tx.Model(&account).Update("balance", expired.Balance).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// ListExpiringLots 获取在 (from, to] 内过期且尚未发送过期提醒的批次
+func (r *GormLoyaltyRepository) ListExpiringLots(ctx context.Context, from, to time.Time, limit int) ([]*model.LoyaltyPointTransaction, error) {
+	var lots []*model.LoyaltyPointTransaction
+	err := r.db.WithContext(ctx).
+		Where("remaining > 0 AND expires_at > ? AND expires_at <= ? AND expiry_notified_at IS NULL", from, to).
+		Order("user_id ASC, expires_at ASC").
+		Limit(limit).
+		Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+// MarkExpiryNotified 记录批次已发送过期提醒
+func (r *GormLoyaltyRepository) MarkExpiryNotified(ctx context.Context, ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Model(&model.LoyaltyPointTransaction{}).
+		Where("id IN ?", ids).
+		Update("expiry_notified_at", at).Error
+}
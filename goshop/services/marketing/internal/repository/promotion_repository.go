@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrPromotionExhausted 表示促销活动已达到总参与次数上限
+	ErrPromotionExhausted = errors.New("promotion exhausted")
+	// ErrPromotionUserLimit 表示用户已达到促销活动的参与次数上限
+	ErrPromotionUserLimit = errors.New("promotion user limit reached")
+)
+
+// PromotionRepository 定义促销活动仓库接口
+type PromotionRepository interface {
+	Create(ctx context.Context, promotion *model.Promotion) error
+	Update(ctx context.Context, promotion *model.Promotion) error
+	SetActive(ctx context.Context, id uint, active bool) error
+	Archive(ctx context.Context, id uint) error
+	List(ctx context.Context, offset, limit int) ([]*model.Promotion, int64, error)
+	GetByID(ctx context.Context, id uint) (*model.Promotion, error)
+	ListActive(ctx context.Context, at time.Time) ([]*model.Promotion, error)
+	CountUserUsage(ctx context.Context, userID uint, promotionIDs []uint) (map[uint]int, error)
+	Redeem(ctx context.Context, usages []*model.PromotionUsage) error
+	ReleaseOrder(ctx context.Context, orderID uint) ([]*model.PromotionUsage, error)
+	ListDueToStart(ctx context.Context, at time.Time) ([]*model.Promotion, error)
+	ListDueToEnd(ctx context.Context, at time.Time) ([]*model.Promotion, error)
+	UpdateScheduleStatus(ctx context.Context, id uint, from []string, to string) (bool, error)
+	ListStartingBetween(ctx context.Context, from, to time.Time) ([]*model.Promotion, error)
+	ListEndingBetween(ctx context.Context, from, to time.Time) ([]*model.Promotion, error)
+}
+
+// GormPromotionRepository 实现 PromotionRepository 接口的 GORM 仓库
+type GormPromotionRepository struct {
+	db *gorm.DB
+}
+
+// NewPromotionRepository 创建促销活动仓库实例
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &GormPromotionRepository{
+		db: db,
+	}
+}
+
+// Create 创建促销活动
+func (r *GormPromotionRepository) Create(ctx context.Context, promotion *model.Promotion) error {
+	return r.db.WithContext(ctx).Create(promotion).Error
+}
+
+// Update 更新促销活动，总使用次数由核销流程原子维护，不会被覆盖
+func (r *GormPromotionRepository) Update(ctx context.Context, promotion *model.Promotion) error {
+	return r.db.WithContext(ctx).Omit("total_uses").Save(promotion).Error
+}
+
+// SetActive 启用或暂停促销活动
+func (r *GormPromotionRepository) SetActive(ctx context.Context, id uint, active bool) error {
+	return r.db.WithContext(ctx).Model(&model.Promotion{}).Where("id = ?", id).Update("is_active", active).Error
+}
+
+// Archive 归档（软删除）促销活动
+func (r *GormPromotionRepository) Archive(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Promotion{}, id).Error
+}
+
+// List 分页获取促销活动，最新创建的在前
+func (r *GormPromotionRepository) List(ctx context.Context, offset, limit int) ([]*model.Promotion, int64, error) {
+	var promotions []*model.Promotion
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Promotion{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&promotions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return promotions, total, nil
+}
+
+// GetByID 根据 ID 获取促销活动
+func (r *GormPromotionRepository) GetByID(ctx context.Context, id uint) (*model.Promotion, error) {
+	var promotion model.Promotion
+	err := r.db.WithContext(ctx).First(&promotion, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// ListActive 获取在给定时间生效的促销活动，按优先级从高到低排序
+func (r *GormPromotionRepository) ListActive(ctx context.Context, at time.Time) ([]*model.Promotion, error) {
+	var promotions []*model.Promotion
+	err := r.db.WithContext(ctx).
+		Where("is_active = ? AND start_at <= ? AND end_at > ?", true, at, at).
+		Order("priority DESC, id ASC").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// CountUserUsage 统计用户在各促销活动上的参与次数
+func (r *GormPromotionRepository) CountUserUsage(ctx context.Context, userID uint, promotionIDs []uint) (map[uint]int, error) {
+	usage := make(map[uint]int, len(promotionIDs))
+	if userID == 0 || len(promotionIDs) == 0 {
+		return usage, nil
+	}
+
+	var rows []struct {
+		PromotionID uint
+		Count       int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.PromotionUsage{}).
+		Select("promotion_id, COUNT(DISTINCT order_id) AS count").
+		Where("user_id = ? AND promotion_id IN ?", userID, promotionIDs).
+		Group("promotion_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		usage[row.PromotionID] = row.Count
+	}
+	return usage, nil
+}
+
+// ListDueToStart 获取已到开始时间但尚未切换为进行中的促销活动
+func (r *GormPromotionRepository) ListDueToStart(ctx context.Context, at time.Time) ([]*model.Promotion, error) {
+	var promotions []*model.Promotion
+	err := r.db.WithContext(ctx).
+		Where("schedule_status = ? AND start_at <= ? AND end_at > ?", model.ScheduleStatusScheduled, at, at).
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// ListDueToEnd 获取已到结束时间但尚未切换为已结束的促销活动
+func (r *GormPromotionRepository) ListDueToEnd(ctx context.Context, at time.Time) ([]*model.Promotion, error) {
+	var promotions []*model.Promotion
+	err := r.db.WithContext(ctx).
+		Where("schedule_status <> ? AND end_at <= ?", model.ScheduleStatusEnded, at).
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// Redeem 在事务中记录订单参与的促销活动：按总次数上限条件递增参与次数，检查每人参与次数，并写入参与记录。
+// 任一活动超限时整单回滚，返回的错误包含活动 ID；同一订单已记录的活动会被跳过
+func (r *GormPromotionRepository) Redeem(ctx context.Context, usages []*model.PromotionUsage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, usage := range usages {
+			var count int64
+			err := tx.Model(&model.PromotionUsage{}).
+				Where("promotion_id = ? AND order_id = ?", usage.PromotionID, usage.OrderID).
+				Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			// 条件更新持有活动的行锁直到事务结束，同一活动的参与在此串行
+			result := tx.Model(&model.Promotion{}).
+				Where("id = ? AND (max_uses IS NULL OR total_uses < max_uses)", usage.PromotionID).
+				Update("total_uses", gorm.Expr("total_uses + 1"))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("promotion %d: %w", usage.PromotionID, ErrPromotionExhausted)
+			}
+
+			var promotion model.Promotion
+			if err := tx.Select("id", "max_uses_per_user").First(&promotion, usage.PromotionID).Error; err != nil {
+				return err
+			}
+			if promotion.MaxUsesPerUser != nil {
+				err := tx.Model(&model.PromotionUsage{}).
+					Where("promotion_id = ? AND user_id = ?", usage.PromotionID, usage.UserID).
+					Count(&count).Error
+				if err != nil {
+					return err
+				}
+				if count >= int64(*promotion.MaxUsesPerUser) {
+					return fmt.Errorf("promotion %d: %w", usage.PromotionID, ErrPromotionUserLimit)
+				}
+			}
+
+			if err := tx.Create(usage).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReleaseOrder 释放订单参与的促销活动：删除参与记录并回退参与次数，返回本次释放的记录
+func (r *GormPromotionRepository) ReleaseOrder(ctx context.Context, orderID uint) ([]*model.PromotionUsage, error) {
+	var released []*model.PromotionUsage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var usages []*model.PromotionUsage
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ?", orderID).
+			Find(&usages).Error
+		if err != nil {
+			return err
+		}
+
+		for _, usage := range usages {
+			result := tx.Delete(&model.PromotionUsage{}, usage.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			err := tx.Model(&model.Promotion{}).
+				Where("id = ? AND total_uses > 0", usage.PromotionID).
+				Update("total_uses", gorm.Expr("total_uses - 1")).Error
+			if err != nil {
+				return err
+			}
+			released = append(released, usage)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return released, nil
+}
+
+// UpdateScheduleStatus 将促销活动的排期状态从 from 中的任一状态切换为 to，状态已被其他实例切换时返回 false
+func (r *GormPromotionRepository) UpdateScheduleStatus(ctx context.Context, id uint, from []string, to string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Promotion{}).
+		Where("id = ? AND schedule_status IN ?", id, from).
+		Update("schedule_status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListStartingBetween 获取在 [from, to) 内开始的有效促销活动
+func (r *GormPromotionRepository) ListStartingBetween(ctx context.Context, from, to time.Time) ([]*model.Promotion, error) {
+	var promotions []*model.Promotion
+	err := r.db.WithContext(ctx).
+		Where("is_active = ? AND start_at >= ? AND start_at < ?", true, from, to).
+		Order("start_at ASC").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// ListEndingBetween 获取在 [from, to) 内结束的有效促销活动
+func (r *GormPromotionRepository) ListEndingBetween(ctx context.Context, from, to time.Time) ([]*model.Promotion, error) {
+	var promotions []*model.Promotion
+	err := r.db.WithContext(ctx).
+		Where("is_active = ? AND end_at >= ? AND end_at < ?", true, from, to).
+		Order("end_at ASC").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
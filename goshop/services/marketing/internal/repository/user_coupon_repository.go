@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCouponSoldOut 表示优惠券已发放完
+	ErrCouponSoldOut = errors.New("coupon sold out")
+	// ErrCouponLimitReached 表示用户持有该优惠券的数量已达上限
+	ErrCouponLimitReached = errors.New("coupon per-user limit reached")
+)
+
+// UserCouponRepository 定义用户券包仓库接口
+type UserCouponRepository interface {
+	Issue(ctx context.Context, userCoupon *model.UserCoupon, userLimit int) error
+	GetByID(ctx context.Context, id uint) (*model.UserCoupon, error)
+	ListByUser(ctx context.Context, userID uint) ([]*model.UserCoupon, error)
+}
+
+// GormUserCouponRepository 实现 UserCouponRepository 接口的 GORM 仓库
+type GormUserCouponRepository struct {
+	db *gorm.DB
+}
+
+// NewUserCouponRepository 创建用户券包仓库实例
+func NewUserCouponRepository(db *gorm.DB) UserCouponRepository {
+	return &GormUserCouponRepository{
+		db: db,
+	}
+}
+
+// Issue 在事务中扣减优惠券库存并发放到用户券包，userLimit 为 0 时不限制每人持有数量
+func (r *GormUserCouponRepository) Issue(ctx context.Context, userCoupon *model.UserCoupon, userLimit int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Coupon{}).
+			Where("id = ? AND (total_quantity = 0 OR claimed_quantity < total_quantity)", userCoupon.CouponID).
+			UpdateColumn("claimed_quantity", gorm.Expr("claimed_quantity + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCouponSoldOut
+		}
+
+		if userLimit > 0 {
+			var held int64
+			err := tx.Model(&model.UserCoupon{}).
+				Where("user_id = ? AND coupon_id = ?", userCoupon.UserID, userCoupon.CouponID).
+				Count(&held).Error
+			if err != nil {
+				return err
+			}
+			if held >= int64(userLimit) {
+				return ErrCouponLimitReached
+			}
+		}
+
+		return tx.Create(userCoupon).Error
+	})
+}
+
+// GetByID 根据 ID 获取用户优惠券
+func (r *GormUserCouponRepository) GetByID(ctx context.Context, id uint) (*model.UserCoupon, error) {
+	var userCoupon model.UserCoupon
+	err := r.db.WithContext(ctx).Preload("Coupon").First(&userCoupon, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &userCoupon, nil
+}
+
+// ListByUser 获取用户券包中的所有优惠券
+func (r *GormUserCouponRepository) ListByUser(ctx context.Context, userID uint) ([]*model.UserCoupon, error) {
+	var userCoupons []*model.UserCoupon
+	err := r.db.WithContext(ctx).
+		Preload("Coupon").
+		Where("user_id = ?", userID).
+		Order("expires_at ASC, id DESC").
+		Find(&userCoupons).Error
+	if err != nil {
+		return nil, err
+	}
+	return userCoupons, nil
+}
@@ -0,0 +1,536 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"gorm.io/gorm"
+)
+
+// CouponRequest 表示创建或更新优惠券的请求
+type CouponRequest struct {
+	Code                 string           `json:"code" binding:"required,max=50"`
+	Name                 string           `json:"name" binding:"required,max=100"`
+	Description          string           `json:"description" binding:"max=255"`
+	Type                 model.CouponType `json:"type" binding:"required,oneof=fixed_amount percentage free_shipping product_specific category_specific first_order"`
+	Value                float64          `json:"value" binding:"min=0"`
+	MinOrderAmount       float64          `json:"min_order_amount" binding:"min=0"`
+	MaxDiscountAmount    *float64         `json:"max_discount_amount" binding:"omitempty,gt=0"`
+	StartAt              time.Time        `json:"start_at" binding:"required"`
+	EndAt                time.Time        `json:"end_at" binding:"required"`
+	TotalQuantity        int              `json:"total_quantity" binding:"min=0"`
+	UserLimit            *int             `json:"user_limit" binding:"omitempty,min=0"` // 为空时每人限用 1 次
+	ApplicableProducts   []uint           `json:"applicable_products"`
+	ApplicableCategories []uint           `json:"applicable_categories"`
+	ExcludedProducts     []uint           `json:"excluded_products"`
+	ExcludedCategories   []uint           `json:"excluded_categories"`
+	ShippingMethodIDs    []uint           `json:"shipping_method_ids"`
+	ShippingZoneIDs      []uint           `json:"shipping_zone_ids"`
+	ExcludedRegions      []string         `json:"excluded_regions"`
+	IsForNewUser         bool             `json:"is_for_new_user"`
+	IsClaimable          bool             `json:"is_claimable"`
+	ValidDays            *int             `json:"valid_days" binding:"omitempty,min=1"`
+}
+
+// PromotionRequest 表示创建或更新促销活动的请求
+type PromotionRequest struct {
+	Name           string              `json:"name" binding:"required,max=100"`
+	Description    string              `json:"description" binding:"max=500"`
+	Type           model.PromotionType `json:"type" binding:"required,oneof=flash_sale bundle_sale buy_x_get_y second_half_price spend_get_free quantity_discount"`
+	StartAt        time.Time           `json:"start_at" binding:"required"`
+	EndAt          time.Time           `json:"end_at" binding:"required"`
+	Priority       int                 `json:"priority"`
+	Stackable      bool                `json:"stackable"`
+	ProductIDs     []uint              `json:"product_ids"`
+	CategoryIDs    []uint              `json:"category_ids"`
+	DiscountValue  float64             `json:"discount_value" binding:"min=0"`
+	DiscountType   string              `json:"discount_type" binding:"omitempty,oneof=amount percentage price"`
+	MinOrderAmount *float64            `json:"min_order_amount" binding:"omitempty,min=0"`
+	MinQuantity    *int                `json:"min_quantity" binding:"omitempty,min=1"`
+	MaxUsesPerUser *int                `json:"max_uses_per_user" binding:"omitempty,min=1"`
+	MaxUses        *int                `json:"max_uses" binding:"omitempty,min=1"`
+	FreeProductID  *uint               `json:"free_product_id"`
+	FreeProductQty *int                `json:"free_product_qty" binding:"omitempty,min=1"`
+	FreeSKUID      *uint               `json:"free_sku_id"`
+	GiftPrice      *float64            `json:"gift_price" binding:"omitempty,min=0"`
+	Rules          []string            `json:"rules"`
+	Image          *string             `json:"image" binding:"omitempty,max=255"`
+}
+
+// DuplicateCouponRequest 表示复制优惠券的请求，副本需要新的优惠码
+type DuplicateCouponRequest struct {
+	Code string `json:"code" binding:"required,max=50"`
+}
+
+// CouponDryRunRequest 表示优惠券试算请求，coupon_id 和 coupon 二选一，
+// 传入 coupon 时可在保存前预览配置效果
+type CouponDryRunRequest struct {
+	CouponID    *uint                `json:"coupon_id"`
+	Coupon      *CouponRequest       `json:"coupon"`
+	Items       []CartItem           `json:"items" binding:"required,min=1,dive"`
+	Destination *ShippingDestination `json:"destination"`
+}
+
+// PromotionDryRunRequest 表示促销活动试算请求，promotion_id 和 promotion 二选一
+type PromotionDryRunRequest struct {
+	PromotionID *uint             `json:"promotion_id"`
+	Promotion   *PromotionRequest `json:"promotion"`
+	Items       []CartItem        `json:"items" binding:"required,min=1,dive"`
+}
+
+// CouponList 表示分页的优惠券列表
+type CouponList struct {
+	Items    []*model.Coupon `json:"items"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// PromotionList 表示分页的促销活动列表
+type PromotionList struct {
+	Items    []*model.Promotion `json:"items"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
+
+// AdminService 负责优惠券和促销活动的后台管理
+type AdminService struct {
+	couponRepo    repository.CouponRepository
+	codeRepo      repository.CouponCodeRepository
+	promotionRepo repository.PromotionRepository
+}
+
+// NewAdminService 创建后台管理服务
+func NewAdminService(couponRepo repository.CouponRepository, codeRepo repository.CouponCodeRepository, promotionRepo repository.PromotionRepository) *AdminService {
+	return &AdminService{
+		couponRepo:    couponRepo,
+		codeRepo:      codeRepo,
+		promotionRepo: promotionRepo,
+	}
+}
+
+// ListCoupons 分页获取优惠券，包含已暂停和已结束的优惠券
+func (s *AdminService) ListCoupons(ctx context.Context, page, pageSize int) (*CouponList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	coupons, total, err := s.couponRepo.List(ctx, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取优惠券失败", err)
+	}
+	return &CouponList{Items: coupons, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// GetCoupon 获取优惠券
+func (s *AdminService) GetCoupon(ctx context.Context, id uint) (*model.Coupon, error) {
+	return getCoupon(ctx, s.couponRepo, id)
+}
+
+// CreateCoupon 创建优惠券
+func (s *AdminService) CreateCoupon(ctx context.Context, req *CouponRequest) (*model.Coupon, error) {
+	coupon := &model.Coupon{IsActive: true, ScheduleStatus: model.ScheduleStatusScheduled}
+	if err := s.applyCoupon(ctx, coupon, req); err != nil {
+		return nil, err
+	}
+	if err := s.couponRepo.Create(ctx, coupon); err != nil {
+		return nil, apperrors.NewInternalServerError("创建优惠券失败", err)
+	}
+	return coupon, nil
+}
+
+// UpdateCoupon 更新优惠券
+func (s *AdminService) UpdateCoupon(ctx context.Context, id uint, req *CouponRequest) (*model.Coupon, error) {
+	coupon, err := getCoupon(ctx, s.couponRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.TotalQuantity > 0 && req.TotalQuantity < coupon.UsedQuantity {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("发行量不能少于已使用数量 %d", coupon.UsedQuantity), nil)
+	}
+	if err := s.applyCoupon(ctx, coupon, req); err != nil {
+		return nil, err
+	}
+	coupon.ScheduleStatus = reschedule(coupon.ScheduleStatus, coupon.StartAt, coupon.EndAt, time.Now())
+	if err := s.couponRepo.Update(ctx, coupon); err != nil {
+		return nil, apperrors.NewInternalServerError("更新优惠券失败", err)
+	}
+	return coupon, nil
+}
+
+// SetCouponActive 暂停或恢复优惠券，暂停期间不能领取和使用
+func (s *AdminService) SetCouponActive(ctx context.Context, id uint, active bool) (*model.Coupon, error) {
+	coupon, err := getCoupon(ctx, s.couponRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.couponRepo.SetActive(ctx, id, active); err != nil {
+		return nil, apperrors.NewInternalServerError("更新优惠券状态失败", err)
+	}
+	coupon.IsActive = active
+	return coupon, nil
+}
+
+// ArchiveCoupon 归档优惠券，已核销的使用记录保留
+func (s *AdminService) ArchiveCoupon(ctx context.Context, id uint) error {
+	if _, err := getCoupon(ctx, s.couponRepo, id); err != nil {
+		return err
+	}
+	if err := s.couponRepo.Archive(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("归档优惠券失败", err)
+	}
+	return nil
+}
+
+// DuplicateCoupon 复制优惠券配置，副本使用新的优惠码，默认为暂停状态，计数清零
+func (s *AdminService) DuplicateCoupon(ctx context.Context, id uint, req *DuplicateCouponRequest) (*model.Coupon, error) {
+	source, err := getCoupon(ctx, s.couponRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	code := strings.TrimSpace(req.Code)
+	if err := s.checkCouponCode(ctx, 0, code); err != nil {
+		return nil, err
+	}
+
+	coupon := *source
+	coupon.ID = 0
+	coupon.Code = code
+	coupon.Name = source.Name + "（副本）"
+	coupon.IsActive = false
+	coupon.ScheduleStatus = model.ScheduleStatusScheduled
+	coupon.UsedQuantity = 0
+	coupon.ClaimedQuantity = 0
+	coupon.CreatedAt = time.Time{}
+	coupon.UpdatedAt = time.Time{}
+	if err := s.couponRepo.Create(ctx, &coupon); err != nil {
+		return nil, apperrors.NewInternalServerError("复制优惠券失败", err)
+	}
+	return &coupon, nil
+}
+
+// DryRunCoupon 预览优惠券在示例购物车上的效果，不检查有效期、发行量和使用次数
+func (s *AdminService) DryRunCoupon(ctx context.Context, req *CouponDryRunRequest) (*CouponValidation, error) {
+	var coupon *model.Coupon
+	switch {
+	case req.CouponID != nil && req.Coupon == nil:
+		var err error
+		if coupon, err = getCoupon(ctx, s.couponRepo, *req.CouponID); err != nil {
+			return nil, err
+		}
+	case req.Coupon != nil && req.CouponID == nil:
+		if err := validateCoupon(req.Coupon); err != nil {
+			return nil, err
+		}
+		coupon = &model.Coupon{}
+		fillCoupon(coupon, req.Coupon)
+	default:
+		return nil, apperrors.NewBadRequest("coupon_id 和 coupon 必须且只能提供一个", nil)
+	}
+	return evaluateCoupon(coupon, coupon.Code, req.Items, req.Destination), nil
+}
+
+// ListPromotions 分页获取促销活动，包含已暂停和已结束的活动
+func (s *AdminService) ListPromotions(ctx context.Context, page, pageSize int) (*PromotionList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	promotions, total, err := s.promotionRepo.List(ctx, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取促销活动失败", err)
+	}
+	return &PromotionList{Items: promotions, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// GetPromotion 获取促销活动
+func (s *AdminService) GetPromotion(ctx context.Context, id uint) (*model.Promotion, error) {
+	return getPromotion(ctx, s.promotionRepo, id)
+}
+
+// CreatePromotion 创建促销活动
+func (s *AdminService) CreatePromotion(ctx context.Context, req *PromotionRequest) (*model.Promotion, error) {
+	if err := validatePromotion(req); err != nil {
+		return nil, err
+	}
+	promotion := &model.Promotion{IsActive: true, ScheduleStatus: model.ScheduleStatusScheduled}
+	fillPromotion(promotion, req)
+	if err := s.promotionRepo.Create(ctx, promotion); err != nil {
+		return nil, apperrors.NewInternalServerError("创建促销活动失败", err)
+	}
+	return promotion, nil
+}
+
+// UpdatePromotion 更新促销活动
+func (s *AdminService) UpdatePromotion(ctx context.Context, id uint, req *PromotionRequest) (*model.Promotion, error) {
+	promotion, err := getPromotion(ctx, s.promotionRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePromotion(req); err != nil {
+		return nil, err
+	}
+	if req.MaxUses != nil && *req.MaxUses < promotion.TotalUses {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("最大使用次数不能少于已使用次数 %d", promotion.TotalUses), nil)
+	}
+	fillPromotion(promotion, req)
+	promotion.ScheduleStatus = reschedule(promotion.ScheduleStatus, promotion.StartAt, promotion.EndAt, time.Now())
+	if err := s.promotionRepo.Update(ctx, promotion); err != nil {
+		return nil, apperrors.NewInternalServerError("更新促销活动失败", err)
+	}
+	return promotion, nil
+}
+
+// SetPromotionActive 暂停或恢复促销活动
+func (s *AdminService) SetPromotionActive(ctx context.Context, id uint, active bool) (*model.Promotion, error) {
+	promotion, err := getPromotion(ctx, s.promotionRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.promotionRepo.SetActive(ctx, id, active); err != nil {
+		return nil, apperrors.NewInternalServerError("更新促销活动状态失败", err)
+	}
+	promotion.IsActive = active
+	return promotion, nil
+}
+
+// ArchivePromotion 归档促销活动，已有的参与记录保留
+func (s *AdminService) ArchivePromotion(ctx context.Context, id uint) error {
+	if _, err := getPromotion(ctx, s.promotionRepo, id); err != nil {
+		return err
+	}
+	if err := s.promotionRepo.Archive(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("归档促销活动失败", err)
+	}
+	return nil
+}
+
+// DuplicatePromotion 复制促销活动配置，副本默认为暂停状态，使用次数清零
+func (s *AdminService) DuplicatePromotion(ctx context.Context, id uint) (*model.Promotion, error) {
+	source, err := getPromotion(ctx, s.promotionRepo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	promotion := *source
+	promotion.ID = 0
+	promotion.Name = source.Name + "（副本）"
+	promotion.IsActive = false
+	promotion.ScheduleStatus = model.ScheduleStatusScheduled
+	promotion.TotalUses = 0
+	promotion.CreatedAt = time.Time{}
+	promotion.UpdatedAt = time.Time{}
+	if err := s.promotionRepo.Create(ctx, &promotion); err != nil {
+		return nil, apperrors.NewInternalServerError("复制促销活动失败", err)
+	}
+	return &promotion, nil
+}
+
+// DryRunPromotion 预览单个促销活动在示例购物车上的效果，不检查活动时间、状态和参与次数
+func (s *AdminService) DryRunPromotion(ctx context.Context, req *PromotionDryRunRequest) (*PromotionResult, error) {
+	var promotion *model.Promotion
+	switch {
+	case req.PromotionID != nil && req.Promotion == nil:
+		var err error
+		if promotion, err = getPromotion(ctx, s.promotionRepo, *req.PromotionID); err != nil {
+			return nil, err
+		}
+	case req.Promotion != nil && req.PromotionID == nil:
+		if err := validatePromotion(req.Promotion); err != nil {
+			return nil, err
+		}
+		promotion = &model.Promotion{}
+		fillPromotion(promotion, req.Promotion)
+	default:
+		return nil, apperrors.NewBadRequest("promotion_id 和 promotion 必须且只能提供一个", nil)
+	}
+	return evaluatePromotions([]*model.Promotion{promotion}, req.Items, nil), nil
+}
+
+// applyCoupon 校验请求并写入优惠券，优惠码不能与其他优惠券或一次性优惠码重复
+func (s *AdminService) applyCoupon(ctx context.Context, coupon *model.Coupon, req *CouponRequest) error {
+	if err := validateCoupon(req); err != nil {
+		return err
+	}
+	if err := s.checkCouponCode(ctx, coupon.ID, strings.TrimSpace(req.Code)); err != nil {
+		return err
+	}
+	fillCoupon(coupon, req)
+	return nil
+}
+
+// checkCouponCode 检查优惠码是否已被占用，excludeID 为当前编辑的优惠券
+func (s *AdminService) checkCouponCode(ctx context.Context, excludeID uint, code string) error {
+	existing, err := s.couponRepo.GetByCode(ctx, code)
+	if err == nil && existing.ID != excludeID {
+		return apperrors.NewConflict(fmt.Sprintf("优惠码 %s 已存在", code), nil)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NewInternalServerError("获取优惠券失败", err)
+	}
+
+	if _, err := s.codeRepo.GetByCode(ctx, strings.ToUpper(code)); err == nil {
+		return apperrors.NewConflict(fmt.Sprintf("优惠码 %s 与已生成的一次性优惠码重复", code), nil)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NewInternalServerError("获取优惠码失败", err)
+	}
+	return nil
+}
+
+// validateCoupon 校验优惠券的有效期、面额和适用范围
+func validateCoupon(req *CouponRequest) error {
+	if !req.EndAt.After(req.StartAt) {
+		return apperrors.NewBadRequest("结束时间必须晚于开始时间", nil)
+	}
+	switch req.Type {
+	case model.CouponTypePercentage:
+		if req.Value <= 0 || req.Value > 100 {
+			return apperrors.NewBadRequest("折扣券的折扣百分比必须在 0 到 100 之间", nil)
+		}
+	case model.CouponTypeFreeShipping:
+	default:
+		if req.Value <= 0 {
+			return apperrors.NewBadRequest("优惠金额必须大于 0", nil)
+		}
+	}
+	if req.Type == model.CouponTypeProductSpecific && len(req.ApplicableProducts) == 0 {
+		return apperrors.NewBadRequest("指定商品券必须配置适用商品", nil)
+	}
+	if req.Type == model.CouponTypeCategorySpecific && len(req.ApplicableCategories) == 0 {
+		return apperrors.NewBadRequest("指定分类券必须配置适用分类", nil)
+	}
+	return nil
+}
+
+// validatePromotion 校验促销活动的时间范围和各类型必需的规则
+func validatePromotion(req *PromotionRequest) error {
+	if !req.EndAt.After(req.StartAt) {
+		return apperrors.NewBadRequest("结束时间必须晚于开始时间", nil)
+	}
+	if req.DiscountType == model.DiscountTypePercentage && req.DiscountValue > 100 {
+		return apperrors.NewBadRequest("折扣百分比不能超过 100", nil)
+	}
+
+	switch req.Type {
+	case model.PromotionTypeFlashSale, model.PromotionTypeBundleSale:
+		if req.DiscountType == "" || req.DiscountValue <= 0 {
+			return apperrors.NewBadRequest("该活动类型必须配置折扣类型和折扣值", nil)
+		}
+	case model.PromotionTypeSecondHalfPrice:
+		if req.DiscountValue > 100 {
+			return apperrors.NewBadRequest("第二件折扣百分比不能超过 100", nil)
+		}
+	case model.PromotionTypeSpendGetFree:
+		if req.FreeProductID == nil {
+			return apperrors.NewBadRequest("满赠活动必须配置赠品", nil)
+		}
+		if req.MinOrderAmount == nil && req.MinQuantity == nil {
+			return apperrors.NewBadRequest("满赠活动必须配置最低订单金额或最低购买数量", nil)
+		}
+	case model.PromotionTypeQuantityDiscount:
+		if req.DiscountType == "" {
+			return apperrors.NewBadRequest("阶梯式优惠必须配置折扣类型", nil)
+		}
+		return validateTiers(req.Rules, req.DiscountType)
+	}
+	return nil
+}
+
+// validateTiers 校验阶梯规则，每档为 "最低件数:优惠值"，最低件数不能重复
+func validateTiers(rules []string, discountType string) error {
+	if len(rules) == 0 {
+		return apperrors.NewBadRequest("阶梯式优惠至少需要一档规则", nil)
+	}
+	seen := make(map[int]bool, len(rules))
+	for _, rule := range rules {
+		minQty, value, err := parseTier(rule)
+		if err != nil {
+			return apperrors.NewBadRequest(err.Error(), nil)
+		}
+		if minQty < 1 {
+			return apperrors.NewBadRequest(fmt.Sprintf("阶梯规则 %q 的最低件数必须大于 0", rule), nil)
+		}
+		if value <= 0 || (discountType == model.DiscountTypePercentage && value > 100) {
+			return apperrors.NewBadRequest(fmt.Sprintf("阶梯规则 %q 的优惠值超出范围", rule), nil)
+		}
+		if seen[minQty] {
+			return apperrors.NewBadRequest(fmt.Sprintf("阶梯规则的最低件数 %d 重复", minQty), nil)
+		}
+		seen[minQty] = true
+	}
+	return nil
+}
+
+func fillCoupon(coupon *model.Coupon, req *CouponRequest) {
+	coupon.Code = strings.TrimSpace(req.Code)
+	coupon.Name = req.Name
+	coupon.Description = req.Description
+	coupon.Type = req.Type
+	coupon.Value = money(req.Value)
+	coupon.MinOrderAmount = money(req.MinOrderAmount)
+	coupon.MaxDiscountAmount = optionalMoney(req.MaxDiscountAmount)
+	coupon.StartAt = req.StartAt
+	coupon.EndAt = req.EndAt
+	coupon.TotalQuantity = req.TotalQuantity
+	coupon.UserLimit = 1
+	if req.UserLimit != nil {
+		coupon.UserLimit = *req.UserLimit
+	}
+	coupon.ApplicableProducts = req.ApplicableProducts
+	coupon.ApplicableCategories = req.ApplicableCategories
+	coupon.ExcludedProducts = req.ExcludedProducts
+	coupon.ExcludedCategories = req.ExcludedCategories
+	coupon.ShippingMethodIDs = req.ShippingMethodIDs
+	coupon.ShippingZoneIDs = req.ShippingZoneIDs
+	coupon.ExcludedRegions = req.ExcludedRegions
+	coupon.IsForNewUser = req.IsForNewUser
+	coupon.IsClaimable = req.IsClaimable
+	coupon.ValidDays = req.ValidDays
+}
+
+func fillPromotion(promotion *model.Promotion, req *PromotionRequest) {
+	promotion.Name = req.Name
+	promotion.Description = req.Description
+	promotion.Type = req.Type
+	promotion.StartAt = req.StartAt
+	promotion.EndAt = req.EndAt
+	promotion.Priority = req.Priority
+	promotion.Stackable = req.Stackable
+	promotion.ProductIDs = req.ProductIDs
+	promotion.CategoryIDs = req.CategoryIDs
+	promotion.DiscountValue = money(req.DiscountValue)
+	promotion.DiscountType = req.DiscountType
+	promotion.MinOrderAmount = optionalMoney(req.MinOrderAmount)
+	promotion.MinQuantity = req.MinQuantity
+	promotion.MaxUsesPerUser = req.MaxUsesPerUser
+	promotion.MaxUses = req.MaxUses
+	promotion.FreeProductID = req.FreeProductID
+	promotion.FreeProductQty = req.FreeProductQty
+	promotion.FreeSKUID = req.FreeSKUID
+	promotion.GiftPrice = optionalMoney(req.GiftPrice)
+	promotion.Rules = req.Rules
+	promotion.Image = req.Image
+}
+
+// reschedule 在修改活动时间后重置排期状态：已结束的活动延期后、进行中的活动推迟开始后
+// 回到未开始，由排期任务重新推进并发布开始事件
+func reschedule(status string, startAt, endAt, now time.Time) string {
+	switch {
+	case status == model.ScheduleStatusEnded && endAt.After(now):
+		return model.ScheduleStatusScheduled
+	case status == model.ScheduleStatusRunning && startAt.After(now):
+		return model.ScheduleStatusScheduled
+	}
+	return status
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
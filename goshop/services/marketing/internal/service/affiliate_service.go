@@ -0,0 +1,664 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/marketing/internal/event"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	affiliateCodeLength = 8
+	affiliateLinkLength = 10
+	defaultCookieDays   = 30
+)
+
+// ApplyAffiliateRequest 表示申请成为推广员的请求
+type ApplyAffiliateRequest struct {
+	Name    string `json:"name" binding:"required,max=100"`
+	Email   string `json:"email" binding:"omitempty,email,max=100"`
+	Website string `json:"website" binding:"omitempty,url,max=255"`
+}
+
+// UpdateAffiliateRequest 表示后台审核或调整推广员的请求
+type UpdateAffiliateRequest struct {
+	Status     string `json:"status" binding:"required,oneof=pending active suspended"`
+	CookieDays *int   `json:"cookie_days" binding:"omitempty,min=1,max=90"`
+}
+
+// AffiliateLinkRequest 表示创建推广链接的请求，target_url 必须是站内路径，如 /products/42
+type AffiliateLinkRequest struct {
+	Name        string `json:"name" binding:"max=100"`
+	TargetURL   string `json:"target_url" binding:"required,max=500"`
+	UTMSource   string `json:"utm_source" binding:"max=50"`
+	UTMMedium   string `json:"utm_medium" binding:"max=50"`
+	UTMCampaign string `json:"utm_campaign" binding:"max=100"`
+}
+
+// AffiliateClickRequest 表示一次推广链接点击
+type AffiliateClickRequest struct {
+	LinkCode  string
+	VisitorID string // 推广 Cookie 中已有的访客标识，为空时生成新的标识
+	UserID    uint   // 已登录用户
+	IP        string
+	UserAgent string
+	Referer   string
+}
+
+// AffiliateClickResult 表示点击记录结果，处理器据此写入推广 Cookie 并跳转
+type AffiliateClickResult struct {
+	RedirectURL string
+	VisitorID   string
+	CookieDays  int
+}
+
+// CommissionRuleRequest 表示设置分类佣金比例的请求，category_id 为 0 时设置默认比例
+type CommissionRuleRequest struct {
+	CategoryID uint    `json:"category_id"`
+	Rate       float64 `json:"rate" binding:"min=0,max=100"`
+}
+
+// AffiliatePayoutRequest 表示后台登记佣金结算打款的请求
+type AffiliatePayoutRequest struct {
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
+	Method    string  `json:"method" binding:"required,max=30"`
+	Reference string  `json:"reference" binding:"max=100"`
+	Note      string  `json:"note" binding:"max=255"`
+}
+
+// AffiliateList 表示分页的推广员列表
+type AffiliateList struct {
+	Items    []*model.Affiliate `json:"items"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
+
+// AffiliateLedger 表示推广员的余额和分页的账本交易
+type AffiliateLedger struct {
+	Balance   currency.Money                `json:"balance"`
+	TotalPaid currency.Money                `json:"total_paid"`
+	Items     []*model.AffiliateLedgerEntry `json:"items"`
+	Total     int64                         `json:"total"`
+	Page      int                           `json:"page"`
+	PageSize  int                           `json:"page_size"`
+}
+
+// AffiliateLinkReport 表示推广链接在统计区间内的表现
+type AffiliateLinkReport struct {
+	Link           *model.AffiliateLink `json:"link"`
+	Clicks         int64                `json:"clicks"`
+	Visitors       int64                `json:"visitors"`
+	Orders         int64                `json:"orders"`
+	OrderAmount    currency.Money       `json:"order_amount"`
+	Commission     currency.Money       `json:"commission"`
+	ConversionRate float64              `json:"conversion_rate"` // 订单数 / 去重访客数
+}
+
+// AffiliateStats 表示推广员在统计区间内的汇总和各链接明细
+type AffiliateStats struct {
+	From           time.Time              `json:"from"`
+	To             time.Time              `json:"to"`
+	Clicks         int64                  `json:"clicks"`
+	Visitors       int64                  `json:"visitors"`
+	Orders         int64                  `json:"orders"`
+	OrderAmount    currency.Money         `json:"order_amount"`
+	Commission     currency.Money         `json:"commission"`
+	ConversionRate float64                `json:"conversion_rate"`
+	Balance        currency.Money         `json:"balance"`
+	Links          []*AffiliateLinkReport `json:"links"`
+}
+
+// AffiliateService 负责推广员、推广链接、点击归因和佣金结算。
+// 订单按最后一次点击归因，点击需在推广员的归因窗口内
+type AffiliateService struct {
+	affiliateRepo repository.AffiliateRepository
+	log           *logger.Logger
+}
+
+// NewAffiliateService 创建推广服务
+func NewAffiliateService(affiliateRepo repository.AffiliateRepository, log *logger.Logger) *AffiliateService {
+	return &AffiliateService{
+		affiliateRepo: affiliateRepo,
+		log:           log,
+	}
+}
+
+// Subscribe 订阅订单事件：订单完成时归因并计入佣金，取消或退款时扣回佣金
+func (s *AffiliateService) Subscribe(sub *event.Subscriber) error {
+	if err := sub.Subscribe(event.OrderCompleted, func(ctx context.Context, data json.RawMessage) error {
+		var evt event.OrderEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		return s.HandleOrderCompleted(ctx, &evt)
+	}); err != nil {
+		return err
+	}
+	if err := sub.Subscribe(event.OrderCancelled, func(ctx context.Context, data json.RawMessage) error {
+		var evt event.OrderEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		return s.reverse(ctx, evt.OrderID, 1, model.AffiliateRefOrder, strconv.FormatUint(uint64(evt.OrderID), 10),
+			fmt.Sprintf("订单 %s 取消扣回佣金", evt.OrderNumber))
+	}); err != nil {
+		return err
+	}
+	return sub.Subscribe(event.OrderRefunded, func(ctx context.Context, data json.RawMessage) error {
+		var evt event.OrderRefundEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		ratio := 1.0
+		if evt.GrandTotal > 0 && evt.RefundAmount < evt.GrandTotal {
+			ratio = evt.RefundAmount / evt.GrandTotal
+		}
+		return s.reverse(ctx, evt.OrderID, ratio, model.AffiliateRefRefund, evt.RefundID,
+			fmt.Sprintf("订单 %s 退款扣回佣金", evt.OrderNumber))
+	})
+}
+
+// Apply 申请成为推广员，审核通过后才能创建推广链接
+func (s *AffiliateService) Apply(ctx context.Context, userID uint, req *ApplyAffiliateRequest) (*model.Affiliate, error) {
+	if _, err := s.affiliateRepo.GetByUserID(ctx, userID); err == nil {
+		return nil, apperrors.NewConflict("已提交过推广员申请", nil)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NewInternalServerError("获取推广员失败", err)
+	}
+
+	code, err := randomCode("", affiliateCodeLength)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成推广员编码失败", err)
+	}
+	affiliate := &model.Affiliate{
+		UserID:     userID,
+		Code:       code,
+		Name:       req.Name,
+		Email:      req.Email,
+		Website:    req.Website,
+		Status:     model.AffiliateStatusPending,
+		CookieDays: defaultCookieDays,
+	}
+	if err := s.affiliateRepo.Create(ctx, affiliate); err != nil {
+		return nil, apperrors.NewInternalServerError("提交推广员申请失败", err)
+	}
+	return affiliate, nil
+}
+
+// Me 获取用户的推广员账户
+func (s *AffiliateService) Me(ctx context.Context, userID uint) (*model.Affiliate, error) {
+	affiliate, err := s.affiliateRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("尚未申请成为推广员", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取推广员失败", err)
+	}
+	return affiliate, nil
+}
+
+// CreateLink 为推广员创建推广链接
+func (s *AffiliateService) CreateLink(ctx context.Context, userID uint, req *AffiliateLinkRequest) (*model.AffiliateLink, error) {
+	affiliate, err := s.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if affiliate.Status != model.AffiliateStatusActive {
+		return nil, apperrors.NewForbidden("推广员尚未审核通过或已停用", nil)
+	}
+	target := strings.TrimSpace(req.TargetURL)
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		return nil, apperrors.NewBadRequest("落地页必须是站内路径，如 /products/42", nil)
+	}
+	if _, err := url.Parse(target); err != nil {
+		return nil, apperrors.NewBadRequest("落地页地址无效", err)
+	}
+
+	code, err := randomCode("", affiliateLinkLength)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成推广链接失败", err)
+	}
+	link := &model.AffiliateLink{
+		AffiliateID: affiliate.ID,
+		Code:        code,
+		Name:        req.Name,
+		TargetURL:   target,
+		UTMSource:   req.UTMSource,
+		UTMMedium:   req.UTMMedium,
+		UTMCampaign: req.UTMCampaign,
+		IsActive:    true,
+	}
+	if err := s.affiliateRepo.CreateLink(ctx, link); err != nil {
+		return nil, apperrors.NewInternalServerError("创建推广链接失败", err)
+	}
+	return link, nil
+}
+
+// ListLinks 获取推广员的推广链接
+func (s *AffiliateService) ListLinks(ctx context.Context, userID uint) ([]*model.AffiliateLink, error) {
+	affiliate, err := s.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.affiliateRepo.ListLinks(ctx, affiliate.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取推广链接失败", err)
+	}
+	return links, nil
+}
+
+// Click 记录推广链接点击，返回附加 UTM 参数后的落地页地址
+func (s *AffiliateService) Click(ctx context.Context, req *AffiliateClickRequest) (*AffiliateClickResult, error) {
+	link, err := s.affiliateRepo.GetLinkByCode(ctx, req.LinkCode)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("推广链接不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取推广链接失败", err)
+	}
+	affiliate, err := s.affiliateRepo.GetByID(ctx, link.AffiliateID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取推广员失败", err)
+	}
+	redirect := linkTarget(link, affiliate)
+	if !link.IsActive || affiliate.Status != model.AffiliateStatusActive {
+		// 失效的链接仍然跳转到落地页，但不记录点击
+		return &AffiliateClickResult{RedirectURL: redirect, VisitorID: req.VisitorID}, nil
+	}
+
+	visitorID := req.VisitorID
+	if visitorID == "" {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, apperrors.NewInternalServerError("生成访客标识失败", err)
+		}
+		visitorID = hex.EncodeToString(buf)
+	}
+	click := &model.AffiliateClick{
+		AffiliateID: affiliate.ID,
+		LinkID:      link.ID,
+		VisitorID:   visitorID,
+		IP:          req.IP,
+		UserAgent:   truncate(req.UserAgent, 255),
+		Referer:     truncate(req.Referer, 500),
+		ClickedAt:   time.Now(),
+	}
+	if req.UserID != 0 {
+		click.UserID = &req.UserID
+	}
+	if err := s.affiliateRepo.RecordClick(ctx, click); err != nil {
+		return nil, apperrors.NewInternalServerError("记录推广点击失败", err)
+	}
+	return &AffiliateClickResult{RedirectURL: redirect, VisitorID: visitorID, CookieDays: affiliate.CookieDays}, nil
+}
+
+// Identify 访客登录后将其登录前的推广点击关联到用户
+func (s *AffiliateService) Identify(ctx context.Context, visitorID string, userID uint) error {
+	if visitorID == "" {
+		return nil
+	}
+	if _, err := s.affiliateRepo.IdentifyVisitor(ctx, visitorID, userID); err != nil {
+		return apperrors.NewInternalServerError("关联推广点击失败", err)
+	}
+	return nil
+}
+
+// Stats 获取推广员在统计区间内的点击、订单和佣金，from 和 to 为日期，默认最近 30 天
+func (s *AffiliateService) Stats(ctx context.Context, userID uint, from, to time.Time) (*AffiliateStats, error) {
+	affiliate, err := s.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err = reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.affiliateRepo.ListLinks(ctx, affiliate.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取推广链接失败", err)
+	}
+	linkStats, err := s.affiliateRepo.LinkStats(ctx, affiliate.ID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取推广统计失败", err)
+	}
+
+	stats := &AffiliateStats{
+		From:    from,
+		To:      to,
+		Balance: affiliate.Balance,
+		Links:   make([]*AffiliateLinkReport, 0, len(links)),
+	}
+	for _, link := range links {
+		report := &AffiliateLinkReport{Link: link}
+		if ls, ok := linkStats[link.ID]; ok {
+			report.Clicks = ls.Clicks
+			report.Visitors = ls.Visitors
+			report.Orders = ls.Orders
+			report.OrderAmount = ls.OrderAmount
+			report.Commission = ls.Commission
+		}
+		if report.Visitors > 0 {
+			report.ConversionRate = float64(report.Orders) / float64(report.Visitors)
+		}
+		stats.Clicks += report.Clicks
+		stats.Visitors += report.Visitors
+		stats.Orders += report.Orders
+		stats.OrderAmount = stats.OrderAmount.Add(report.OrderAmount)
+		stats.Commission = stats.Commission.Add(report.Commission)
+		stats.Links = append(stats.Links, report)
+	}
+	sort.SliceStable(stats.Links, func(i, j int) bool {
+		return stats.Links[i].Commission.GreaterThan(stats.Links[j].Commission)
+	})
+	if stats.Visitors > 0 {
+		stats.ConversionRate = float64(stats.Orders) / float64(stats.Visitors)
+	}
+	return stats, nil
+}
+
+// Ledger 获取推广员的佣金账本
+func (s *AffiliateService) Ledger(ctx context.Context, userID uint, page, pageSize int) (*AffiliateLedger, error) {
+	affiliate, err := s.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledger(ctx, affiliate, page, pageSize)
+}
+
+// Payouts 获取推广员的结算记录
+func (s *AffiliateService) Payouts(ctx context.Context, userID uint) ([]*model.AffiliatePayout, error) {
+	affiliate, err := s.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	payouts, err := s.affiliateRepo.ListPayouts(ctx, affiliate.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取结算记录失败", err)
+	}
+	return payouts, nil
+}
+
+// List 分页获取推广员，status 为空时返回全部
+func (s *AffiliateService) List(ctx context.Context, status string, page, pageSize int) (*AffiliateList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	affiliates, total, err := s.affiliateRepo.List(ctx, status, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取推广员失败", err)
+	}
+	return &AffiliateList{Items: affiliates, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Update 审核或调整推广员，首次审核通过时记录通过时间
+func (s *AffiliateService) Update(ctx context.Context, id uint, req *UpdateAffiliateRequest) (*model.Affiliate, error) {
+	affiliate, err := s.getAffiliate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status == model.AffiliateStatusActive && affiliate.ApprovedAt == nil {
+		now := time.Now()
+		affiliate.ApprovedAt = &now
+	}
+	affiliate.Status = req.Status
+	if req.CookieDays != nil {
+		affiliate.CookieDays = *req.CookieDays
+	}
+	if err := s.affiliateRepo.Update(ctx, affiliate); err != nil {
+		return nil, apperrors.NewInternalServerError("更新推广员失败", err)
+	}
+	return affiliate, nil
+}
+
+// AdminLedger 后台查看推广员的佣金账本
+func (s *AffiliateService) AdminLedger(ctx context.Context, id uint, page, pageSize int) (*AffiliateLedger, error) {
+	affiliate, err := s.getAffiliate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledger(ctx, affiliate, page, pageSize)
+}
+
+// CreatePayout 登记佣金结算打款，金额不能超过待结算佣金
+func (s *AffiliateService) CreatePayout(ctx context.Context, id uint, req *AffiliatePayoutRequest) (*model.AffiliatePayout, error) {
+	affiliate, err := s.getAffiliate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	amount := currency.FromMajor(req.Amount, currency.Default)
+	payout := &model.AffiliatePayout{
+		AffiliateID: affiliate.ID,
+		Amount:      amount,
+		Method:      req.Method,
+		Reference:   req.Reference,
+		Note:        req.Note,
+		PaidAt:      time.Now(),
+	}
+	entry := &model.AffiliateLedgerEntry{
+		AffiliateID:   affiliate.ID,
+		Type:          model.AffiliateEntryPayout,
+		Amount:        amount.Neg(),
+		ReferenceType: model.AffiliateRefPayout,
+		Description:   fmt.Sprintf("佣金结算 %s 元", amount.Decimal()),
+	}
+	err = s.affiliateRepo.CreatePayout(ctx, payout, entry)
+	if errors.Is(err, repository.ErrInsufficientAffiliateBalance) {
+		return nil, apperrors.NewBadRequest("待结算佣金不足", err)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("登记结算失败", err)
+	}
+	return payout, nil
+}
+
+// ListCommissionRules 获取分类佣金规则
+func (s *AffiliateService) ListCommissionRules(ctx context.Context) ([]*model.AffiliateCommissionRule, error) {
+	rules, err := s.affiliateRepo.ListCommissionRules(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取佣金规则失败", err)
+	}
+	return rules, nil
+}
+
+// SaveCommissionRule 设置分类佣金比例，只影响之后完成的订单
+func (s *AffiliateService) SaveCommissionRule(ctx context.Context, req *CommissionRuleRequest) (*model.AffiliateCommissionRule, error) {
+	rule := &model.AffiliateCommissionRule{CategoryID: req.CategoryID, Rate: req.Rate}
+	if err := s.affiliateRepo.SaveCommissionRule(ctx, rule); err != nil {
+		return nil, apperrors.NewInternalServerError("保存佣金规则失败", err)
+	}
+	return rule, nil
+}
+
+// DeleteCommissionRule 删除分类佣金规则，该分类改按默认比例计佣
+func (s *AffiliateService) DeleteCommissionRule(ctx context.Context, categoryID uint) error {
+	if err := s.affiliateRepo.DeleteCommissionRule(ctx, categoryID); err != nil {
+		return apperrors.NewInternalServerError("删除佣金规则失败", err)
+	}
+	return nil
+}
+
+// HandleOrderCompleted 将订单归因到下单前最后一次点击的推广员，点击超出归因窗口、
+// 推广员已停用或推广员本人下单时不计佣
+func (s *AffiliateService) HandleOrderCompleted(ctx context.Context, evt *event.OrderEvent) error {
+	placedAt := evt.PlacedAt
+	if placedAt.IsZero() {
+		placedAt = time.Now()
+	}
+	click, err := s.affiliateRepo.LastClick(ctx, evt.UserID, placedAt)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	affiliate, err := s.affiliateRepo.GetByID(ctx, click.AffiliateID)
+	if err != nil {
+		return err
+	}
+	if affiliate.Status != model.AffiliateStatusActive || affiliate.UserID == evt.UserID {
+		return nil
+	}
+	if placedAt.Sub(click.ClickedAt) > time.Duration(affiliate.CookieDays)*24*time.Hour {
+		return nil
+	}
+
+	rules, err := s.affiliateRepo.ListCommissionRules(ctx)
+	if err != nil {
+		return err
+	}
+	// 佣金按商品逐行计算并取整到分，合计与账本明细一致
+	var orderAmount, commission currency.Money
+	for _, item := range evt.Items {
+		total := currency.FromMajor(item.Total, currency.Default)
+		orderAmount = orderAmount.Add(total)
+		commission = commission.Add(total.Percent(commissionRate(rules, item.CategoryIDs), currency.HalfUp))
+	}
+	if !commission.IsPositive() {
+		return nil
+	}
+
+	conversion := &model.AffiliateConversion{
+		AffiliateID: affiliate.ID,
+		LinkID:      click.LinkID,
+		ClickID:     click.ID,
+		OrderID:     evt.OrderID,
+		OrderNumber: evt.OrderNumber,
+		UserID:      evt.UserID,
+		OrderAmount: orderAmount,
+		Commission:  commission,
+		Status:      model.AffiliateConversionApproved,
+	}
+	entry := &model.AffiliateLedgerEntry{
+		AffiliateID:   affiliate.ID,
+		Type:          model.AffiliateEntryCommission,
+		Amount:        commission,
+		ReferenceType: model.AffiliateRefOrder,
+		ReferenceID:   strconv.FormatUint(uint64(evt.OrderID), 10),
+		OrderID:       &evt.OrderID,
+		Description:   fmt.Sprintf("订单 %s 推广佣金", evt.OrderNumber),
+	}
+	err = s.affiliateRepo.RecordConversion(ctx, conversion, entry)
+	if errors.Is(err, repository.ErrDuplicateAffiliateConversion) || errors.Is(err, repository.ErrDuplicateAffiliateEntry) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.log.Info(ctx, "Attributed order to affiliate",
+		zap.Uint("order_id", evt.OrderID),
+		zap.Uint("affiliate_id", affiliate.ID),
+		zap.Uint("link_id", click.LinkID),
+		zap.String("commission", commission.Decimal()),
+	)
+	return nil
+}
+
+// reverse 按比例扣回订单佣金，同一关联重复处理时忽略
+func (s *AffiliateService) reverse(ctx context.Context, orderID uint, ratio float64, refType, refID, description string) error {
+	entry := &model.AffiliateLedgerEntry{
+		Type:          model.AffiliateEntryReversal,
+		ReferenceType: refType,
+		ReferenceID:   refID,
+		OrderID:       &orderID,
+		Description:   description,
+	}
+	_, err := s.affiliateRepo.ReverseConversion(ctx, orderID, ratio, entry)
+	if errors.Is(err, repository.ErrDuplicateAffiliateEntry) {
+		return nil
+	}
+	return err
+}
+
+func (s *AffiliateService) ledger(ctx context.Context, affiliate *model.Affiliate, page, pageSize int) (*AffiliateLedger, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	entries, total, err := s.affiliateRepo.ListLedger(ctx, affiliate.ID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取佣金明细失败", err)
+	}
+	return &AffiliateLedger{
+		Balance:   affiliate.Balance,
+		TotalPaid: affiliate.TotalPaid,
+		Items:     entries,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+	}, nil
+}
+
+func (s *AffiliateService) getAffiliate(ctx context.Context, id uint) (*model.Affiliate, error) {
+	affiliate, err := s.affiliateRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("推广员 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取推广员失败", err)
+	}
+	return affiliate, nil
+}
+
+// commissionRate 返回商品适用的佣金比例：商品所属分类中比例最高的规则，都未配置时使用默认规则
+func commissionRate(rules []*model.AffiliateCommissionRule, categoryIDs []uint) float64 {
+	rate, matched := 0.0, false
+	var fallback float64
+	for _, rule := range rules {
+		if rule.CategoryID == 0 {
+			fallback = rule.Rate
+			continue
+		}
+		for _, id := range categoryIDs {
+			if id == rule.CategoryID && (!matched || rule.Rate > rate) {
+				rate, matched = rule.Rate, true
+			}
+		}
+	}
+	if !matched {
+		return fallback
+	}
+	return rate
+}
+
+// linkTarget 在落地页地址上附加 UTM 参数，未配置的参数使用推广员默认值
+func linkTarget(link *model.AffiliateLink, affiliate *model.Affiliate) string {
+	target, err := url.Parse(link.TargetURL)
+	if err != nil {
+		return "/"
+	}
+	query := target.Query()
+	query.Set("utm_source", firstNonEmpty(link.UTMSource, "affiliate"))
+	query.Set("utm_medium", firstNonEmpty(link.UTMMedium, "referral"))
+	query.Set("utm_campaign", firstNonEmpty(link.UTMCampaign, affiliate.Code))
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/marketing/internal/event"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"go.uber.org/zap"
+)
+
+// 报表默认统计最近 30 天，最长一年
+const (
+	defaultReportDays = 30
+	maxReportDays     = 366
+)
+
+// CampaignTotals 表示活动在统计区间内的效果汇总
+type CampaignTotals struct {
+	Redemptions        int            `json:"redemptions"`
+	Revenue            currency.Money `json:"revenue"`
+	DiscountCost       currency.Money `json:"discount_cost"`
+	NewCustomers       int            `json:"new_customers"`
+	ReturningCustomers int            `json:"returning_customers"`
+	AverageOrderValue  currency.Money `json:"average_order_value"`
+	StoreOrders        int            `json:"store_orders"`    // 同期全店完成订单数
+	ConversionRate     float64        `json:"conversion_rate"` // 使用该活动的订单占同期全店订单的比例
+}
+
+// CampaignReport 表示优惠券或促销活动的效果报表
+type CampaignReport struct {
+	CampaignType string                     `json:"campaign_type"`
+	CampaignID   uint                       `json:"campaign_id"`
+	Name         string                     `json:"name"`
+	From         time.Time                  `json:"from"`
+	To           time.Time                  `json:"to"`
+	Totals       CampaignTotals             `json:"totals"`
+	Daily        []*model.CampaignDailyStat `json:"daily"`
+}
+
+// AnalyticsService 负责活动效果统计：消费订单完成事件汇总数据，并提供活动报表
+type AnalyticsService struct {
+	analyticsRepo repository.AnalyticsRepository
+	couponRepo    repository.CouponRepository
+	promotionRepo repository.PromotionRepository
+	log           *logger.Logger
+}
+
+// NewAnalyticsService 创建活动效果统计服务
+func NewAnalyticsService(
+	analyticsRepo repository.AnalyticsRepository,
+	couponRepo repository.CouponRepository,
+	promotionRepo repository.PromotionRepository,
+	log *logger.Logger,
+) *AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+		couponRepo:    couponRepo,
+		promotionRepo: promotionRepo,
+		log:           log,
+	}
+}
+
+// Subscribe 订阅订单完成事件，把订单归因到使用的优惠券和促销活动
+func (s *AnalyticsService) Subscribe(sub *event.Subscriber) error {
+	return sub.Subscribe(event.OrderCompleted, func(ctx context.Context, data json.RawMessage) error {
+		var evt event.OrderEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		return s.HandleOrderCompleted(ctx, &evt)
+	})
+}
+
+// HandleOrderCompleted 记录订单对全店和各活动的贡献，重复投递的事件只统计一次
+func (s *AnalyticsService) HandleOrderCompleted(ctx context.Context, evt *event.OrderEvent) error {
+	now := time.Now()
+	record := func(campaignType string, campaignID uint, discount float64) error {
+		recorded, err := s.analyticsRepo.RecordOrder(ctx, &model.CampaignOrder{
+			CampaignType:  campaignType,
+			CampaignID:    campaignID,
+			OrderID:       evt.OrderID,
+			UserID:        evt.UserID,
+			Revenue:       money(evt.GrandTotal),
+			Discount:      money(discount),
+			IsNewCustomer: evt.IsFirstOrder,
+			CompletedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+		if !recorded {
+			s.log.Info(ctx, "Order already attributed",
+				zap.String("campaign_type", campaignType),
+				zap.Uint("campaign_id", campaignID),
+				zap.Uint("order_id", evt.OrderID),
+			)
+		}
+		return nil
+	}
+
+	if err := record(model.CampaignTypeStore, 0, evt.Discount); err != nil {
+		return err
+	}
+	for _, c := range evt.Coupons {
+		if err := record(model.CampaignTypeCoupon, c.ID, c.Discount); err != nil {
+			return err
+		}
+	}
+	for _, p := range evt.Promotions {
+		if err := record(model.CampaignTypePromotion, p.ID, p.Discount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CouponReport 获取优惠券的效果报表
+func (s *AnalyticsService) CouponReport(ctx context.Context, couponID uint, from, to time.Time) (*CampaignReport, error) {
+	coupon, err := getCoupon(ctx, s.couponRepo, couponID)
+	if err != nil {
+		return nil, err
+	}
+	return s.report(ctx, model.CampaignTypeCoupon, coupon.ID, coupon.Name, from, to)
+}
+
+// PromotionReport 获取促销活动的效果报表
+func (s *AnalyticsService) PromotionReport(ctx context.Context, promotionID uint, from, to time.Time) (*CampaignReport, error) {
+	promotion, err := getPromotion(ctx, s.promotionRepo, promotionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.report(ctx, model.CampaignTypePromotion, promotion.ID, promotion.Name, from, to)
+}
+
+func (s *AnalyticsService) report(ctx context.Context, campaignType string, campaignID uint, name string, from, to time.Time) (*CampaignReport, error) {
+	from, to, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	daily, err := s.analyticsRepo.ListDailyStats(ctx, campaignType, campaignID, from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取活动统计失败", err)
+	}
+	store, err := s.analyticsRepo.ListDailyStats(ctx, model.CampaignTypeStore, 0, from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取订单统计失败", err)
+	}
+
+	var totals CampaignTotals
+	for _, stat := range daily {
+		totals.Redemptions += stat.Redemptions
+		totals.Revenue = totals.Revenue.Add(stat.Revenue)
+		totals.DiscountCost = totals.DiscountCost.Add(stat.DiscountCost)
+		totals.NewCustomers += stat.NewCustomers
+		totals.ReturningCustomers += stat.ReturningCustomers
+	}
+	for _, stat := range store {
+		totals.StoreOrders += stat.Redemptions
+	}
+	if totals.Redemptions > 0 {
+		totals.AverageOrderValue = totals.Revenue.MulRate(1/float64(totals.Redemptions), currency.HalfUp)
+	}
+	if totals.StoreOrders > 0 {
+		totals.ConversionRate = float64(totals.Redemptions) / float64(totals.StoreOrders)
+	}
+
+	return &CampaignReport{
+		CampaignType: campaignType,
+		CampaignID:   campaignID,
+		Name:         name,
+		From:         from,
+		To:           to,
+		Totals:       totals,
+		Daily:        daily,
+	}, nil
+}
+
+// reportRange 补全报表日期范围，默认截止到今天、统计最近 30 天
+func reportRange(from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		now := time.Now()
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(defaultReportDays - 1))
+	}
+	if from.After(to) {
+		return from, to, apperrors.NewBadRequest("开始日期不能晚于结束日期", nil)
+	}
+	if to.Sub(from) > maxReportDays*24*time.Hour {
+		return from, to, apperrors.NewBadRequest("统计区间不能超过一年", nil)
+	}
+	return from, to, nil
+}
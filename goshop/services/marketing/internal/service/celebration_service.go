@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/marketing/internal/client"
+	"github.com/yourusername/goshop/services/marketing/internal/event"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// celebrantPageSize 每次从用户服务拉取的用户数
+const celebrantPageSize = 200
+
+// CelebrationCampaignRequest 表示创建或更新纪念日奖励活动的请求，优惠券和积分至少配置一项
+type CelebrationCampaignRequest struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	Type      string `json:"type" binding:"required,oneof=birthday anniversary"`
+	CouponID  *uint  `json:"coupon_id"`
+	Points    int    `json:"points" binding:"min=0"`
+	DaysAhead int    `json:"days_ahead" binding:"min=0,max=30"`
+	Message   string `json:"message" binding:"max=255"`
+	IsActive  *bool  `json:"is_active"`
+}
+
+// CelebrationGrantList 表示分页的纪念日奖励发放记录
+type CelebrationGrantList struct {
+	Items    []*model.CelebrationGrant `json:"items"`
+	Total    int64                     `json:"total"`
+	Page     int                       `json:"page"`
+	PageSize int                       `json:"page_size"`
+}
+
+// CelebrationRunResult 表示一次纪念日奖励发放的汇总
+type CelebrationRunResult struct {
+	Date    time.Time `json:"date"`
+	Granted int       `json:"granted"` // 本次发放成功
+	Skipped int       `json:"skipped"` // 当年已发放过
+	Failed  int       `json:"failed"`  // 优惠券已领完或已过期等业务原因失败，当年不再重试
+	Errors  int       `json:"errors"`  // 内部错误，下次运行时重试
+}
+
+// CelebrationService 在用户生日或注册周年自动发放优惠券或积分，每个活动每个用户每年只发放一次
+type CelebrationService struct {
+	celebrationRepo repository.CelebrationRepository
+	couponRepo      repository.CouponRepository
+	userClient      *client.UserClient
+	walletService   *WalletService
+	loyaltyService  *LoyaltyService
+	publisher       event.Publisher
+	log             *logger.Logger
+
+	mu       sync.Mutex // 避免定时任务和手动触发并发发放同一用户
+	lastDate time.Time  // 最近一次完整处理的日期
+}
+
+// NewCelebrationService 创建纪念日奖励服务
+func NewCelebrationService(
+	celebrationRepo repository.CelebrationRepository,
+	couponRepo repository.CouponRepository,
+	userClient *client.UserClient,
+	walletService *WalletService,
+	loyaltyService *LoyaltyService,
+	publisher event.Publisher,
+	log *logger.Logger,
+) *CelebrationService {
+	return &CelebrationService{
+		celebrationRepo: celebrationRepo,
+		couponRepo:      couponRepo,
+		userClient:      userClient,
+		walletService:   walletService,
+		loyaltyService:  loyaltyService,
+		publisher:       publisher,
+		log:             log,
+	}
+}
+
+// ListCampaigns 获取全部纪念日奖励活动
+func (s *CelebrationService) ListCampaigns(ctx context.Context) ([]*model.CelebrationCampaign, error) {
+	campaigns, err := s.celebrationRepo.ListCampaigns(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取纪念日奖励活动失败", err)
+	}
+	return campaigns, nil
+}
+
+// CreateCampaign 创建纪念日奖励活动
+func (s *CelebrationService) CreateCampaign(ctx context.Context, req *CelebrationCampaignRequest) (*model.CelebrationCampaign, error) {
+	campaign := &model.CelebrationCampaign{IsActive: true}
+	if err := s.fillCampaign(ctx, campaign, req); err != nil {
+		return nil, err
+	}
+	if err := s.celebrationRepo.CreateCampaign(ctx, campaign); err != nil {
+		return nil, apperrors.NewInternalServerError("创建纪念日奖励活动失败", err)
+	}
+	return campaign, nil
+}
+
+// UpdateCampaign 更新纪念日奖励活动，已发放的奖励不受影响
+func (s *CelebrationService) UpdateCampaign(ctx context.Context, id uint, req *CelebrationCampaignRequest) (*model.CelebrationCampaign, error) {
+	campaign, err := s.getCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillCampaign(ctx, campaign, req); err != nil {
+		return nil, err
+	}
+	if err := s.celebrationRepo.UpdateCampaign(ctx, campaign); err != nil {
+		return nil, apperrors.NewInternalServerError("更新纪念日奖励活动失败", err)
+	}
+	return campaign, nil
+}
+
+// DeleteCampaign 删除纪念日奖励活动
+func (s *CelebrationService) DeleteCampaign(ctx context.Context, id uint) error {
+	if _, err := s.getCampaign(ctx, id); err != nil {
+		return err
+	}
+	if err := s.celebrationRepo.DeleteCampaign(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除纪念日奖励活动失败", err)
+	}
+	return nil
+}
+
+// ListGrants 分页获取活动的发放记录
+func (s *CelebrationService) ListGrants(ctx context.Context, campaignID uint, page, pageSize int) (*CelebrationGrantList, error) {
+	if _, err := s.getCampaign(ctx, campaignID); err != nil {
+		return nil, err
+	}
+	page, pageSize = normalizePage(page, pageSize)
+	grants, total, err := s.celebrationRepo.ListGrants(ctx, campaignID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取发放记录失败", err)
+	}
+	return &CelebrationGrantList{Items: grants, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Run 每天发放一次纪念日奖励，当天有内部错误时在下个周期重试，直到 ctx 被取消
+func (s *CelebrationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		today := truncateDay(time.Now())
+		if !today.Equal(s.lastDate) {
+			result, err := s.Process(ctx, today)
+			if err != nil {
+				s.log.Error(ctx, "Failed to process celebration rewards", zap.Error(err))
+			} else if result.Errors == 0 {
+				s.lastDate = today
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process 按 date 为当天发放所有启用活动的奖励，活动配置了提前天数时处理 date 之后对应日期的纪念日。
+// 已发放的用户会被跳过，因此可以对同一天重复执行
+func (s *CelebrationService) Process(ctx context.Context, date time.Time) (*CelebrationRunResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	date = truncateDay(date)
+	campaigns, err := s.celebrationRepo.ListActiveCampaigns(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取纪念日奖励活动失败", err)
+	}
+
+	result := &CelebrationRunResult{Date: date}
+	for _, campaign := range campaigns {
+		if err := s.processCampaign(ctx, campaign, date.AddDate(0, 0, campaign.DaysAhead), result); err != nil {
+			s.log.Error(ctx, "Failed to list celebrants",
+				zap.Uint("campaign_id", campaign.ID),
+				zap.Error(err),
+			)
+			result.Errors++
+		}
+	}
+
+	s.log.Info(ctx, "Processed celebration rewards",
+		zap.Time("date", date),
+		zap.Int("granted", result.Granted),
+		zap.Int("skipped", result.Skipped),
+		zap.Int("failed", result.Failed),
+		zap.Int("errors", result.Errors),
+	)
+	return result, nil
+}
+
+// processCampaign 为纪念日在 target 当天的用户发放活动奖励，平年的 2 月 28 日同时处理 2 月 29 日的生日
+func (s *CelebrationService) processCampaign(ctx context.Context, campaign *model.CelebrationCampaign, target time.Time, result *CelebrationRunResult) error {
+	days := []int{target.Day()}
+	if target.Month() == time.February && target.Day() == 28 && !isLeapYear(target.Year()) {
+		days = append(days, 29)
+	}
+	// 注册周年只发给往年注册的用户
+	var before time.Time
+	if campaign.Type == model.CelebrationAnniversary {
+		before = time.Date(target.Year(), time.January, 1, 0, 0, 0, 0, target.Location())
+	}
+
+	for _, day := range days {
+		var afterID uint
+		for {
+			users, err := s.userClient.ListCelebrants(ctx, campaign.Type, int(target.Month()), day, before, afterID, celebrantPageSize)
+			if err != nil {
+				return err
+			}
+			for _, user := range users {
+				s.grant(ctx, campaign, user, target, result)
+			}
+			if len(users) < celebrantPageSize {
+				break
+			}
+			afterID = users[len(users)-1].ID
+		}
+	}
+	return nil
+}
+
+// grant 向用户发放当年的活动奖励。先占用当年名额，优惠券发放后立即记录，
+// 积分按发放记录去重，因此中断的发放在下次运行时继续而不会重复发放
+func (s *CelebrationService) grant(ctx context.Context, campaign *model.CelebrationCampaign, user *client.UserProfile, target time.Time, result *CelebrationRunResult) {
+	grant := &model.CelebrationGrant{
+		CampaignID:      campaign.ID,
+		UserID:          user.ID,
+		Year:            target.Year(),
+		CelebrationDate: target,
+		Status:          model.CelebrationGrantPending,
+	}
+	created, err := s.celebrationRepo.CreateGrant(ctx, grant)
+	if err == nil && !created {
+		grant, err = s.celebrationRepo.GetGrant(ctx, campaign.ID, user.ID, target.Year())
+	}
+	if err != nil {
+		s.logGrantError(ctx, campaign, user, "Failed to reserve celebration grant", err)
+		result.Errors++
+		return
+	}
+	if grant.Status != model.CelebrationGrantPending {
+		result.Skipped++
+		return
+	}
+
+	evt := &event.CelebrationRewardEvent{
+		UserID:          user.ID,
+		Email:           user.Email,
+		FirstName:       user.FirstName,
+		Type:            campaign.Type,
+		CampaignID:      campaign.ID,
+		CampaignName:    campaign.Name,
+		Message:         campaign.Message,
+		CelebrationDate: target,
+	}
+	if campaign.Type == model.CelebrationAnniversary {
+		evt.Years = target.Year() - user.CreatedAt.Year()
+	}
+
+	if campaign.CouponID != nil && grant.UserCouponID == nil {
+		userCoupon, err := s.walletService.IssueReward(ctx, *campaign.CouponID, user.ID, celebrationCouponSource(campaign.Type), campaign.Name)
+		if err != nil {
+			var appErr *apperrors.Error
+			if !errors.As(err, &appErr) || appErr.HTTPCode >= http.StatusInternalServerError {
+				s.logGrantError(ctx, campaign, user, "Failed to issue celebration coupon", err)
+				result.Errors++
+				return
+			}
+			grant.Status = model.CelebrationGrantFailed
+			grant.Reason = appErr.Message
+			if err := s.celebrationRepo.UpdateGrant(ctx, grant); err != nil {
+				s.logGrantError(ctx, campaign, user, "Failed to update celebration grant", err)
+				result.Errors++
+				return
+			}
+			result.Failed++
+			return
+		}
+		grant.UserCouponID = &userCoupon.ID
+		if err := s.celebrationRepo.UpdateGrant(ctx, grant); err != nil {
+			s.logGrantError(ctx, campaign, user, "Failed to update celebration grant", err)
+			result.Errors++
+			return
+		}
+		evt.CouponCode = userCoupon.Coupon.Code
+		evt.CouponExpiresAt = &userCoupon.ExpiresAt
+	}
+	evt.UserCouponID = grant.UserCouponID
+
+	if campaign.Points > 0 {
+		description := fmt.Sprintf("%s奖励积分", campaign.Name)
+		err := s.loyaltyService.Reward(ctx, user.ID, campaign.Points, model.LoyaltyRefGrant, strconv.FormatUint(uint64(grant.ID), 10), description)
+		if err != nil {
+			s.logGrantError(ctx, campaign, user, "Failed to reward celebration points", err)
+			result.Errors++
+			return
+		}
+		grant.Points = campaign.Points
+		evt.Points = campaign.Points
+	}
+
+	grant.Status = model.CelebrationGrantGranted
+	if err := s.celebrationRepo.UpdateGrant(ctx, grant); err != nil {
+		s.logGrantError(ctx, campaign, user, "Failed to update celebration grant", err)
+		result.Errors++
+		return
+	}
+	result.Granted++
+
+	if err := s.publisher.Publish(ctx, event.CelebrationRewardGranted, evt); err != nil {
+		s.log.Error(ctx, "Failed to publish celebration reward event",
+			zap.Uint("campaign_id", campaign.ID),
+			zap.Uint("user_id", user.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *CelebrationService) logGrantError(ctx context.Context, campaign *model.CelebrationCampaign, user *client.UserProfile, msg string, err error) {
+	s.log.Error(ctx, msg,
+		zap.Uint("campaign_id", campaign.ID),
+		zap.Uint("user_id", user.ID),
+		zap.Error(err),
+	)
+}
+
+// fillCampaign 校验请求并写入活动
+func (s *CelebrationService) fillCampaign(ctx context.Context, campaign *model.CelebrationCampaign, req *CelebrationCampaignRequest) error {
+	if req.CouponID == nil && req.Points == 0 {
+		return apperrors.NewBadRequest("优惠券和积分至少配置一项", nil)
+	}
+	if req.CouponID != nil {
+		if _, err := getCoupon(ctx, s.couponRepo, *req.CouponID); err != nil {
+			return err
+		}
+	}
+
+	campaign.Name = req.Name
+	campaign.Type = req.Type
+	campaign.CouponID = req.CouponID
+	campaign.Points = req.Points
+	campaign.DaysAhead = req.DaysAhead
+	campaign.Message = req.Message
+	if req.IsActive != nil {
+		campaign.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+func (s *CelebrationService) getCampaign(ctx context.Context, id uint) (*model.CelebrationCampaign, error) {
+	campaign, err := s.celebrationRepo.GetCampaign(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("纪念日奖励活动 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取纪念日奖励活动失败", err)
+	}
+	return campaign, nil
+}
+
+// celebrationCouponSource 返回纪念日奖励优惠券在券包中的来源
+func celebrationCouponSource(kind string) model.UserCouponSource {
+	if kind == model.CelebrationBirthday {
+		return model.UserCouponSourceBirthday
+	}
+	return model.UserCouponSourceAnniversary
+}
+
+// truncateDay 返回 t 所在日期的零点
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
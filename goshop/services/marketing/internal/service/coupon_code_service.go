@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"gorm.io/gorm"
+)
+
+// 优惠码字符集，去掉了容易混淆的 0/O、1/I/L
+const codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const (
+	// 碰撞后重新生成的最大轮数
+	maxGenerateRounds = 10
+	// 优惠码空间至少是生成数量的倍数，保证碰撞概率足够低
+	codeSpaceFactor = 100
+	// 导出时每次读取的优惠码数量
+	exportPageSize = 1000
+)
+
+// GenerateCodesRequest 表示批量生成优惠码的请求
+type GenerateCodesRequest struct {
+	Prefix       string `json:"prefix" binding:"omitempty,max=20,alphanum"`
+	SuffixLength int    `json:"suffix_length" binding:"required,min=6,max=20"`
+	Quantity     int    `json:"quantity" binding:"required,min=1,max=100000"`
+	Note         string `json:"note" binding:"max=255"`
+}
+
+// UseCodeRequest 表示使用优惠码的请求，由订单服务在下单时调用
+type UseCodeRequest struct {
+	Code        string `json:"code" binding:"required"`
+	UserID      uint   `json:"user_id" binding:"required"`
+	OrderID     uint   `json:"order_id" binding:"required"`
+	OrderNumber string `json:"order_number" binding:"required"`
+}
+
+// CouponCodeList 表示分页的优惠码列表
+type CouponCodeList struct {
+	Items    []*model.CouponCode `json:"items"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
+
+// CouponCodeService 负责优惠券活动下一次性优惠码的生成、导出和核销
+type CouponCodeService struct {
+	couponRepo repository.CouponRepository
+	codeRepo   repository.CouponCodeRepository
+}
+
+// NewCouponCodeService 创建优惠码服务
+func NewCouponCodeService(couponRepo repository.CouponRepository, codeRepo repository.CouponCodeRepository) *CouponCodeService {
+	return &CouponCodeService{
+		couponRepo: couponRepo,
+		codeRepo:   codeRepo,
+	}
+}
+
+// Generate 在优惠券活动下批量生成唯一优惠码，格式为前缀加随机后缀
+func (s *CouponCodeService) Generate(ctx context.Context, couponID uint, req *GenerateCodesRequest) (*model.CouponCodeBatch, error) {
+	coupon, err := getCoupon(ctx, s.couponRepo, couponID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.ToUpper(req.Prefix)
+	if len(prefix)+req.SuffixLength > 50 {
+		return nil, apperrors.NewBadRequest("优惠码总长度不能超过 50", nil)
+	}
+	if float64(req.Quantity)*codeSpaceFactor > math.Pow(float64(len(codeAlphabet)), float64(req.SuffixLength)) {
+		return nil, apperrors.NewBadRequest("随机后缀长度不足以生成该数量的优惠码", nil)
+	}
+	if coupon.TotalQuantity > 0 {
+		_, issued, err := s.codeRepo.ListCodes(ctx, couponID, 0, 0, 1)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取优惠码失败", err)
+		}
+		if int(issued)+req.Quantity > coupon.TotalQuantity {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("超出优惠券发行量，最多还可生成 %d 个", coupon.TotalQuantity-int(issued)), nil)
+		}
+	}
+
+	batch := &model.CouponCodeBatch{
+		CouponID:     couponID,
+		Prefix:       prefix,
+		SuffixLength: req.SuffixLength,
+		Quantity:     req.Quantity,
+		Note:         req.Note,
+	}
+	if err := s.codeRepo.CreateBatch(ctx, batch); err != nil {
+		return nil, apperrors.NewInternalServerError("创建优惠码批次失败", err)
+	}
+
+	// 插入时跳过与已有优惠码冲突的记录，再为冲突的部分重新生成，直到数量凑齐
+	seen := make(map[string]struct{}, req.Quantity)
+	var inserted int64
+	for round := 0; inserted < int64(req.Quantity); round++ {
+		if round == maxGenerateRounds {
+			return nil, apperrors.NewInternalServerError(fmt.Sprintf("优惠码碰撞过多，已生成 %d 个", inserted), nil)
+		}
+
+		need := req.Quantity - int(inserted)
+		codes := make([]*model.CouponCode, 0, need)
+		for len(codes) < need {
+			code, err := randomCode(prefix, req.SuffixLength)
+			if err != nil {
+				return nil, apperrors.NewInternalServerError("生成优惠码失败", err)
+			}
+			if _, ok := seen[code]; ok {
+				continue
+			}
+			seen[code] = struct{}{}
+			codes = append(codes, &model.CouponCode{CouponID: couponID, BatchID: batch.ID, Code: code})
+		}
+
+		n, err := s.codeRepo.InsertCodes(ctx, codes)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("保存优惠码失败", err)
+		}
+		inserted += n
+	}
+
+	return batch, nil
+}
+
+// ListBatches 获取优惠券活动的优惠码批次
+func (s *CouponCodeService) ListBatches(ctx context.Context, couponID uint) ([]*model.CouponCodeBatch, error) {
+	if _, err := getCoupon(ctx, s.couponRepo, couponID); err != nil {
+		return nil, err
+	}
+	batches, err := s.codeRepo.ListBatches(ctx, couponID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取优惠码批次失败", err)
+	}
+	return batches, nil
+}
+
+// ListCodes 分页获取优惠码及其使用状态
+func (s *CouponCodeService) ListCodes(ctx context.Context, couponID, batchID uint, page, pageSize int) (*CouponCodeList, error) {
+	if _, err := getCoupon(ctx, s.couponRepo, couponID); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 500 {
+		pageSize = 50
+	}
+
+	codes, total, err := s.codeRepo.ListCodes(ctx, couponID, batchID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取优惠码失败", err)
+	}
+	return &CouponCodeList{Items: codes, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Export 以 CSV 格式导出优惠码，batchID 为 0 时导出活动下所有批次
+func (s *CouponCodeService) Export(ctx context.Context, couponID, batchID uint, w io.Writer) error {
+	if _, err := getCoupon(ctx, s.couponRepo, couponID); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"code", "batch_id", "status", "user_id", "order_number", "used_at"}); err != nil {
+		return err
+	}
+	err := s.codeRepo.FindCodesInBatches(ctx, couponID, batchID, exportPageSize, func(codes []*model.CouponCode) error {
+		for _, code := range codes {
+			if err := cw.Write(codeRecord(code)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return apperrors.NewInternalServerError("导出优惠码失败", err)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// UseCode 核销一次性优惠码，每个优惠码只能使用一次
+func (s *CouponCodeService) UseCode(ctx context.Context, req *UseCodeRequest) (*model.CouponCode, error) {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	couponCode, err := s.codeRepo.GetByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrCouponNotFound, "优惠码不存在", http.StatusNotFound, err)
+		}
+		return nil, apperrors.NewInternalServerError("获取优惠码失败", err)
+	}
+
+	coupon, err := getCoupon(ctx, s.couponRepo, couponCode.CouponID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !coupon.IsActive || now.Before(coupon.StartAt) || now.After(coupon.EndAt) {
+		return nil, apperrors.NewBadRequest("优惠券不在有效期内", nil)
+	}
+
+	ok, err := s.codeRepo.MarkUsed(ctx, code, req.UserID, req.OrderID, req.OrderNumber, now)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("核销优惠码失败", err)
+	}
+	if !ok {
+		return nil, apperrors.New(apperrors.ErrCouponCodeUsed, "优惠码已被使用", http.StatusConflict, nil)
+	}
+
+	couponCode.UserID = &req.UserID
+	couponCode.OrderID = &req.OrderID
+	couponCode.OrderNumber = &req.OrderNumber
+	couponCode.UsedAt = &now
+	return couponCode, nil
+}
+
+// randomCode 生成前缀加随机后缀的优惠码
+func randomCode(prefix string, n int) (string, error) {
+	var sb strings.Builder
+	sb.Grow(len(prefix) + n)
+	sb.WriteString(prefix)
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := 0; i < n; i++ {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(codeAlphabet[idx.Int64()])
+	}
+	return sb.String(), nil
+}
+
+func codeRecord(code *model.CouponCode) []string {
+	status, userID, orderNumber, usedAt := "unused", "", "", ""
+	if code.UsedAt != nil {
+		status = "used"
+		usedAt = code.UsedAt.Format(time.RFC3339)
+	}
+	if code.UserID != nil {
+		userID = strconv.FormatUint(uint64(*code.UserID), 10)
+	}
+	if code.OrderNumber != nil {
+		orderNumber = *code.OrderNumber
+	}
+	return []string{code.Code, strconv.FormatUint(uint64(code.BatchID), 10), status, userID, orderNumber, usedAt}
+}
+
+// getCoupon 获取优惠券，不存在时返回 COUPON_NOT_FOUND
+func getCoupon(ctx context.Context, repo repository.CouponRepository, id uint) (*model.Coupon, error) {
+	coupon, err := repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrCouponNotFound, fmt.Sprintf("优惠券 %d 不存在", id), http.StatusNotFound, err)
+		}
+		return nil, apperrors.NewInternalServerError("获取优惠券失败", err)
+	}
+	return coupon, nil
+}
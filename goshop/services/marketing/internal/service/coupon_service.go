@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ShippingDestination 表示收货地址中与包邮券相关的部分
+type ShippingDestination struct {
+	Country  string `json:"country"` // ISO 3166-1 二位代码，为空表示境内
+	Province string `json:"province"`
+}
+
+// ValidateCouponRequest 表示校验优惠券的请求，由订单服务在结算页调用
+type ValidateCouponRequest struct {
+	Code         string               `json:"code" binding:"required"`
+	UserID       uint                 `json:"user_id"`
+	IsFirstOrder bool                 `json:"is_first_order"` // 由订单服务判断是否为用户首单
+	Items        []CartItem           `json:"items" binding:"required,min=1,dive"`
+	Destination  *ShippingDestination `json:"destination"` // 收货地址，提供时提前排除偏远地区
+}
+
+// ShippingWaiver 表示包邮券的运费减免指令，订单服务询价时原样传给物流服务，
+// 由物流服务按配送方式和区域判断是否减免
+type ShippingWaiver struct {
+	CouponID        uint     `json:"coupon_id"`
+	Code            string   `json:"code"`
+	MethodIDs       []uint   `json:"method_ids,omitempty"`       // 适用的配送方式，为空表示不限
+	ZoneIDs         []uint   `json:"zone_ids,omitempty"`         // 适用的配送区域，为空表示不限
+	MaxFee          *float64 `json:"max_fee,omitempty"`          // 最多减免的运费，为空表示全免
+	ExcludedRegions []string `json:"excluded_regions,omitempty"` // 不参与包邮的省份名称或国家代码
+}
+
+// CouponValidation 表示优惠券校验结果
+type CouponValidation struct {
+	Valid            bool             `json:"valid"`
+	CouponID         uint             `json:"coupon_id"`
+	Code             string           `json:"code"`
+	Type             model.CouponType `json:"type"`
+	EligibleSubtotal float64          `json:"eligible_subtotal"` // 适用商品的小计
+	Discount         float64          `json:"discount"`          // 商品金额减免，包邮券为 0
+	ShippingWaiver   *ShippingWaiver  `json:"shipping_waiver,omitempty"`
+	Message          string           `json:"message,omitempty"` // 不可用时的原因
+}
+
+// CouponService 负责结算时的优惠券校验和优惠计算
+type CouponService struct {
+	couponRepo  repository.CouponRepository
+	codeRepo    repository.CouponCodeRepository
+	experiments *ExperimentService
+}
+
+// NewCouponService 创建优惠券服务，experiments 为空时不按实验分组投放优惠券
+func NewCouponService(couponRepo repository.CouponRepository, codeRepo repository.CouponCodeRepository, experiments *ExperimentService) *CouponService {
+	return &CouponService{
+		couponRepo:  couponRepo,
+		codeRepo:    codeRepo,
+		experiments: experiments,
+	}
+}
+
+// Validate 校验优惠码能否用于当前购物车，并计算优惠；包邮券返回运费减免指令
+func (s *CouponService) Validate(ctx context.Context, req *ValidateCouponRequest) (*CouponValidation, error) {
+	coupon, couponCode, err := findCouponByCode(ctx, s.couponRepo, s.codeRepo, strings.TrimSpace(req.Code))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CouponValidation{CouponID: coupon.ID, Code: req.Code, Type: coupon.Type}
+	if couponCode != nil && couponCode.UsedAt != nil {
+		result.Message = "优惠码已被使用"
+		return result, nil
+	}
+
+	// 参与实验的优惠券只对投放该券的变体用户可用，变体可覆盖优惠金额或折扣
+	if s.experiments != nil {
+		offers, err := s.experiments.offers(ctx, req.UserID)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取实验分组失败", err)
+		}
+		if offers.hiddenCoupons[coupon.ID] {
+			result.Message = "该优惠券不适用于当前用户"
+			return result, nil
+		}
+		if value, ok := offers.couponValues[coupon.ID]; ok {
+			override := *coupon
+			override.Value = value
+			coupon = &override
+		}
+	}
+
+	reason, err := s.checkUsable(ctx, coupon, req)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		result.Message = reason
+		return result, nil
+	}
+
+	return evaluateCoupon(coupon, req.Code, req.Items, req.Destination), nil
+}
+
+// evaluateCoupon 计算优惠券在购物车上的优惠，只检查适用商品、门槛和收货地区，
+// 不检查有效期和使用次数
+func evaluateCoupon(coupon *model.Coupon, code string, items []CartItem, dest *ShippingDestination) *CouponValidation {
+	result := &CouponValidation{CouponID: coupon.ID, Code: code, Type: coupon.Type}
+	result.EligibleSubtotal = couponSubtotal(coupon, items)
+	if result.EligibleSubtotal == 0 {
+		result.Message = "购物车中没有适用该优惠券的商品"
+		return result
+	}
+	if eligible := money(result.EligibleSubtotal); eligible.LessThan(coupon.MinOrderAmount) {
+		result.Message = fmt.Sprintf("再购买 %s 元可使用该优惠券", coupon.MinOrderAmount.Sub(eligible).Decimal())
+		return result
+	}
+
+	if coupon.Type == model.CouponTypeFreeShipping {
+		if dest != nil && regionExcluded(coupon.ExcludedRegions, dest) {
+			result.Message = "该收货地区不支持使用包邮券"
+			return result
+		}
+		result.ShippingWaiver = &ShippingWaiver{
+			CouponID:        coupon.ID,
+			Code:            code,
+			MethodIDs:       coupon.ShippingMethodIDs,
+			ZoneIDs:         coupon.ShippingZoneIDs,
+			MaxFee:          optionalFloat(coupon.MaxDiscountAmount),
+			ExcludedRegions: coupon.ExcludedRegions,
+		}
+	} else {
+		result.Discount = couponDiscount(coupon, result.EligibleSubtotal)
+	}
+
+	result.Valid = true
+	return result
+}
+
+// findCouponByCode 按优惠码查找优惠券，先匹配活动的通用码，再匹配一次性优惠码；
+// 匹配到一次性优惠码时同时返回该优惠码
+func findCouponByCode(ctx context.Context, couponRepo repository.CouponRepository, codeRepo repository.CouponCodeRepository, code string) (*model.Coupon, *model.CouponCode, error) {
+	coupon, err := couponRepo.GetByCode(ctx, code)
+	if err == nil {
+		return coupon, nil, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, apperrors.NewInternalServerError("获取优惠券失败", err)
+	}
+
+	couponCode, err := codeRepo.GetByCode(ctx, strings.ToUpper(code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, apperrors.New(apperrors.ErrCouponNotFound, "优惠码不存在", http.StatusNotFound, err)
+		}
+		return nil, nil, apperrors.NewInternalServerError("获取优惠码失败", err)
+	}
+	coupon, err = getCoupon(ctx, couponRepo, couponCode.CouponID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return coupon, couponCode, nil
+}
+
+// checkUsable 检查有效期、发行量、新用户和每人使用次数限制，返回不可用的原因
+func (s *CouponService) checkUsable(ctx context.Context, coupon *model.Coupon, req *ValidateCouponRequest) (string, error) {
+	now := time.Now()
+	if !coupon.IsActive || now.Before(coupon.StartAt) || now.After(coupon.EndAt) {
+		return "优惠券不在有效期内", nil
+	}
+	if coupon.TotalQuantity > 0 && coupon.UsedQuantity >= coupon.TotalQuantity {
+		return "优惠券已被抢光", nil
+	}
+	if (coupon.IsForNewUser || coupon.Type == model.CouponTypeFirstOrder) && !req.IsFirstOrder {
+		return "该优惠券仅限首单使用", nil
+	}
+	if coupon.UserLimit > 0 && req.UserID != 0 {
+		used, err := s.couponRepo.CountUserUsage(ctx, coupon.ID, req.UserID)
+		if err != nil {
+			return "", apperrors.NewInternalServerError("获取优惠券使用记录失败", err)
+		}
+		if used >= int64(coupon.UserLimit) {
+			return "已达到每人使用次数上限", nil
+		}
+	}
+	return "", nil
+}
+
+// couponSubtotal 计算购物车中适用优惠券的商品小计，赠品行不计入
+func couponSubtotal(coupon *model.Coupon, items []CartItem) float64 {
+	var subtotal float64
+	for i := range items {
+		item := &items[i]
+		if item.GiftPromotionID != nil || !couponCovers(coupon, item) {
+			continue
+		}
+		subtotal += item.Price * float64(item.Quantity)
+	}
+	return roundAmount(subtotal)
+}
+
+// couponCovers 判断商品是否适用优惠券，排除规则优先于适用范围
+func couponCovers(coupon *model.Coupon, item *CartItem) bool {
+	for _, id := range coupon.ExcludedProducts {
+		if id == item.ProductID {
+			return false
+		}
+	}
+	for _, id := range coupon.ExcludedCategories {
+		for _, cid := range item.CategoryIDs {
+			if id == cid {
+				return false
+			}
+		}
+	}
+	if len(coupon.ApplicableProducts) == 0 && len(coupon.ApplicableCategories) == 0 {
+		return true
+	}
+	for _, id := range coupon.ApplicableProducts {
+		if id == item.ProductID {
+			return true
+		}
+	}
+	for _, id := range coupon.ApplicableCategories {
+		for _, cid := range item.CategoryIDs {
+			if id == cid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// couponDiscount 计算商品金额减免，不超过适用商品小计
+func couponDiscount(coupon *model.Coupon, subtotal float64) float64 {
+	eligible := money(subtotal)
+	off := coupon.Value
+	if coupon.Type == model.CouponTypePercentage {
+		off = eligible.Percent(coupon.Value.Float64(), currency.HalfUp)
+		if coupon.MaxDiscountAmount != nil {
+			off = off.Min(*coupon.MaxDiscountAmount)
+		}
+	}
+	return off.Min(eligible).Max(currency.Zero(currency.Default)).Float64()
+}
+
+// regionExcluded 判断收货地址是否在包邮券排除的偏远地区
+func regionExcluded(regions model.StringSlice, dest *ShippingDestination) bool {
+	for _, region := range regions {
+		if strings.EqualFold(region, dest.Country) || region == dest.Province {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/marketing/internal/event"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// VariantRequest 表示实验变体的配置
+type VariantRequest struct {
+	Key         string   `json:"key" binding:"required,max=50"`
+	Name        string   `json:"name" binding:"max=100"`
+	Weight      int      `json:"weight" binding:"required,min=1"`
+	IsControl   bool     `json:"is_control"`
+	PromotionID *uint    `json:"promotion_id"`
+	CouponID    *uint    `json:"coupon_id"`
+	CouponValue *float64 `json:"coupon_value" binding:"omitempty,min=0"`
+	BannerID    *uint    `json:"banner_id"`
+}
+
+// CreateExperimentRequest 表示创建 A/B 实验的请求
+type CreateExperimentRequest struct {
+	Key            string           `json:"key" binding:"required,max=50"`
+	Name           string           `json:"name" binding:"required,max=100"`
+	Description    string           `json:"description" binding:"max=500"`
+	TrafficPercent *int             `json:"traffic_percent" binding:"omitempty,min=0,max=100"` // 默认 100
+	StartAt        time.Time        `json:"start_at" binding:"required"`
+	EndAt          *time.Time       `json:"end_at"`
+	Variants       []VariantRequest `json:"variants" binding:"required,min=2,dive"`
+}
+
+// Assignment 表示用户在实验中的分组
+type Assignment struct {
+	ExperimentID  uint            `json:"experiment_id"`
+	ExperimentKey string          `json:"experiment_key"`
+	Enrolled      bool            `json:"enrolled"` // 未进入实验的用户看到对照组的内容，不记录曝光
+	VariantID     uint            `json:"variant_id,omitempty"`
+	VariantKey    string          `json:"variant_key,omitempty"`
+	IsControl     bool            `json:"is_control"`
+	PromotionID   *uint           `json:"promotion_id,omitempty"`
+	CouponID      *uint           `json:"coupon_id,omitempty"`
+	CouponValue   *currency.Money `json:"coupon_value,omitempty"`
+	BannerID      *uint           `json:"banner_id,omitempty"`
+}
+
+// VariantResult 表示实验变体的效果
+type VariantResult struct {
+	VariantID      uint           `json:"variant_id"`
+	Key            string         `json:"key"`
+	Name           string         `json:"name"`
+	IsControl      bool           `json:"is_control"`
+	Exposures      int64          `json:"exposures"`       // 曝光人数
+	Conversions    int64          `json:"conversions"`     // 转化订单数
+	ConvertedUsers int64          `json:"converted_users"` // 下单人数
+	Revenue        currency.Money `json:"revenue"`
+	ConversionRate float64        `json:"conversion_rate"`  // 下单人数 / 曝光人数
+	RevenuePerUser currency.Money `json:"revenue_per_user"` // 转化金额 / 曝光人数
+	Lift           *float64       `json:"lift,omitempty"`   // 转化率相对对照组的提升
+}
+
+// ExperimentResults 表示实验的效果对比
+type ExperimentResults struct {
+	Experiment *model.Experiment `json:"experiment"`
+	Variants   []*VariantResult  `json:"variants"`
+}
+
+// experimentOffers 表示实验对某个用户生效的优惠：其他变体投放的活动和优惠券对该用户不可用
+type experimentOffers struct {
+	hiddenPromotions map[uint]bool
+	hiddenCoupons    map[uint]bool
+	couponValues     map[uint]currency.Money
+}
+
+// ExperimentService 负责 A/B 实验的管理、分流、曝光和转化统计
+type ExperimentService struct {
+	experimentRepo repository.ExperimentRepository
+	log            *logger.Logger
+}
+
+// NewExperimentService 创建 A/B 实验服务
+func NewExperimentService(experimentRepo repository.ExperimentRepository, log *logger.Logger) *ExperimentService {
+	return &ExperimentService{
+		experimentRepo: experimentRepo,
+		log:            log,
+	}
+}
+
+// Subscribe 订阅订单完成事件，为已曝光的用户记录转化
+func (s *ExperimentService) Subscribe(sub *event.Subscriber) error {
+	return sub.Subscribe(event.OrderCompleted, func(ctx context.Context, data json.RawMessage) error {
+		var evt event.OrderEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		return s.HandleOrderCompleted(ctx, &evt)
+	})
+}
+
+// Create 创建实验，创建后为草稿状态
+func (s *ExperimentService) Create(ctx context.Context, req *CreateExperimentRequest) (*model.Experiment, error) {
+	if req.EndAt != nil && !req.EndAt.After(req.StartAt) {
+		return nil, apperrors.NewBadRequest("结束时间必须晚于开始时间", nil)
+	}
+	controls := 0
+	keys := make(map[string]bool, len(req.Variants))
+	for _, v := range req.Variants {
+		if keys[v.Key] {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("变体标识 %s 重复", v.Key), nil)
+		}
+		keys[v.Key] = true
+		if v.IsControl {
+			controls++
+		}
+	}
+	if controls != 1 {
+		return nil, apperrors.NewBadRequest("实验必须有且只有一个对照组", nil)
+	}
+
+	if _, err := s.experimentRepo.GetByKey(ctx, req.Key); err == nil {
+		return nil, apperrors.NewConflict(fmt.Sprintf("实验标识 %s 已存在", req.Key), nil)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NewInternalServerError("获取实验失败", err)
+	}
+
+	experiment := &model.Experiment{
+		Key:            req.Key,
+		Name:           req.Name,
+		Description:    req.Description,
+		Status:         model.ExperimentStatusDraft,
+		TrafficPercent: intOr(req.TrafficPercent, 100),
+		StartAt:        req.StartAt,
+		EndAt:          req.EndAt,
+	}
+	for _, v := range req.Variants {
+		experiment.Variants = append(experiment.Variants, &model.ExperimentVariant{
+			Key:         v.Key,
+			Name:        v.Name,
+			Weight:      v.Weight,
+			IsControl:   v.IsControl,
+			PromotionID: v.PromotionID,
+			CouponID:    v.CouponID,
+			CouponValue: optionalMoney(v.CouponValue),
+			BannerID:    v.BannerID,
+		})
+	}
+	if err := s.experimentRepo.Create(ctx, experiment); err != nil {
+		return nil, apperrors.NewInternalServerError("创建实验失败", err)
+	}
+	return experiment, nil
+}
+
+// List 获取所有实验
+func (s *ExperimentService) List(ctx context.Context) ([]*model.Experiment, error) {
+	experiments, err := s.experimentRepo.List(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取实验失败", err)
+	}
+	return experiments, nil
+}
+
+// Get 获取实验详情
+func (s *ExperimentService) Get(ctx context.Context, id uint) (*model.Experiment, error) {
+	experiment, err := s.experimentRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("实验 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取实验失败", err)
+	}
+	return experiment, nil
+}
+
+// Start 开始或恢复实验分流
+func (s *ExperimentService) Start(ctx context.Context, id uint) (*model.Experiment, error) {
+	return s.transition(ctx, id, []string{model.ExperimentStatusDraft, model.ExperimentStatusPaused}, model.ExperimentStatusRunning)
+}
+
+// Pause 暂停实验分流，暂停期间用户看到对照组的内容
+func (s *ExperimentService) Pause(ctx context.Context, id uint) (*model.Experiment, error) {
+	return s.transition(ctx, id, []string{model.ExperimentStatusRunning}, model.ExperimentStatusPaused)
+}
+
+// Complete 结束实验，结束后不再分流和记录转化
+func (s *ExperimentService) Complete(ctx context.Context, id uint) (*model.Experiment, error) {
+	return s.transition(ctx, id, []string{model.ExperimentStatusRunning, model.ExperimentStatusPaused}, model.ExperimentStatusCompleted)
+}
+
+func (s *ExperimentService) transition(ctx context.Context, id uint, from []string, to string) (*model.Experiment, error) {
+	experiment, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.experimentRepo.UpdateStatus(ctx, id, from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("更新实验状态失败", err)
+	}
+	if !ok {
+		return nil, apperrors.NewConflict(fmt.Sprintf("实验当前状态为 %s，无法切换为 %s", experiment.Status, to), nil)
+	}
+	experiment.Status = to
+	return experiment, nil
+}
+
+// Assign 获取用户在实验中的分组并记录曝光，由前台在展示实验内容时调用
+func (s *ExperimentService) Assign(ctx context.Context, key string, userID uint) (*Assignment, error) {
+	experiment, err := s.experimentRepo.GetByKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("实验 %s 不存在", key), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取实验失败", err)
+	}
+
+	assignment := &Assignment{ExperimentID: experiment.ID, ExperimentKey: experiment.Key}
+	now := time.Now()
+	var variant *model.ExperimentVariant
+	if experiment.IsRunning(now) {
+		variant = assignVariant(experiment, userID)
+	}
+	if variant != nil {
+		assignment.Enrolled = true
+		err := s.experimentRepo.RecordExposure(ctx, &model.ExperimentExposure{
+			ExperimentID: experiment.ID,
+			VariantID:    variant.ID,
+			UserID:       userID,
+			ExposedAt:    now,
+		})
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("记录实验曝光失败", err)
+		}
+	} else {
+		variant = controlVariant(experiment)
+	}
+
+	if variant != nil {
+		assignment.VariantID = variant.ID
+		assignment.VariantKey = variant.Key
+		assignment.IsControl = variant.IsControl
+		assignment.PromotionID = variant.PromotionID
+		assignment.CouponID = variant.CouponID
+		assignment.CouponValue = variant.CouponValue
+		assignment.BannerID = variant.BannerID
+	}
+	return assignment, nil
+}
+
+// Results 获取实验各变体的曝光、转化和相对对照组的提升
+func (s *ExperimentService) Results(ctx context.Context, id uint) (*ExperimentResults, error) {
+	experiment, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.experimentRepo.VariantStats(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取实验数据失败", err)
+	}
+
+	results := &ExperimentResults{Experiment: experiment}
+	var control *VariantResult
+	for _, v := range experiment.Variants {
+		r := &VariantResult{VariantID: v.ID, Key: v.Key, Name: v.Name, IsControl: v.IsControl}
+		if st, ok := stats[v.ID]; ok {
+			r.Exposures = st.Exposures
+			r.Conversions = st.Conversions
+			r.ConvertedUsers = st.ConvertedUsers
+			r.Revenue = st.Revenue
+		}
+		if r.Exposures > 0 {
+			r.ConversionRate = float64(r.ConvertedUsers) / float64(r.Exposures)
+			r.RevenuePerUser = r.Revenue.MulRate(1/float64(r.Exposures), currency.HalfUp)
+		}
+		if v.IsControl {
+			control = r
+		}
+		results.Variants = append(results.Variants, r)
+	}
+	if control != nil && control.ConversionRate > 0 {
+		for _, r := range results.Variants {
+			if r != control {
+				lift := (r.ConversionRate - control.ConversionRate) / control.ConversionRate
+				r.Lift = &lift
+			}
+		}
+	}
+	return results, nil
+}
+
+// HandleOrderCompleted 为进行中实验里已曝光的用户记录转化订单
+func (s *ExperimentService) HandleOrderCompleted(ctx context.Context, evt *event.OrderEvent) error {
+	now := time.Now()
+	experiments, err := s.experimentRepo.ListUnfinished(ctx)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(experiments))
+	for _, e := range experiments {
+		if e.IsRunning(now) {
+			ids = append(ids, e.ID)
+		}
+	}
+	exposures, err := s.experimentRepo.ListUserExposures(ctx, evt.UserID, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, exposure := range exposures {
+		recorded, err := s.experimentRepo.RecordConversion(ctx, &model.ExperimentConversion{
+			ExperimentID: exposure.ExperimentID,
+			VariantID:    exposure.VariantID,
+			UserID:       evt.UserID,
+			OrderID:      evt.OrderID,
+			Revenue:      money(evt.GrandTotal),
+			ConvertedAt:  now,
+		})
+		if err != nil {
+			return err
+		}
+		if recorded {
+			s.log.Info(ctx, "Recorded experiment conversion",
+				zap.Uint("experiment_id", exposure.ExperimentID),
+				zap.Uint("variant_id", exposure.VariantID),
+				zap.Uint("order_id", evt.OrderID),
+			)
+		}
+	}
+	return nil
+}
+
+// offers 计算未结束的实验对用户生效的优惠。实验未在分流（草稿、暂停或不在时间范围内）
+// 或用户不在实验流量内时按对照组处理；实验结束后其投放的活动和优惠券不再受实验限制
+func (s *ExperimentService) offers(ctx context.Context, userID uint) (*experimentOffers, error) {
+	experiments, err := s.experimentRepo.ListUnfinished(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	offers := &experimentOffers{
+		hiddenPromotions: make(map[uint]bool),
+		hiddenCoupons:    make(map[uint]bool),
+		couponValues:     make(map[uint]currency.Money),
+	}
+	for _, experiment := range experiments {
+		var variant *model.ExperimentVariant
+		if experiment.IsRunning(now) {
+			variant = assignVariant(experiment, userID)
+		}
+		if variant == nil {
+			variant = controlVariant(experiment)
+		}
+		for _, v := range experiment.Variants {
+			if v.PromotionID != nil && (variant == nil || variant.PromotionID == nil || *variant.PromotionID != *v.PromotionID) {
+				offers.hiddenPromotions[*v.PromotionID] = true
+			}
+			if v.CouponID != nil && (variant == nil || variant.CouponID == nil || *variant.CouponID != *v.CouponID) {
+				offers.hiddenCoupons[*v.CouponID] = true
+			}
+		}
+		if variant != nil && variant.CouponID != nil && variant.CouponValue != nil {
+			offers.couponValues[*variant.CouponID] = *variant.CouponValue
+		}
+	}
+	return offers, nil
+}
+
+// assignVariant 按实验标识和用户 ID 的哈希稳定地分配变体，用户不在实验流量内时返回 nil
+func assignVariant(experiment *model.Experiment, userID uint) *model.ExperimentVariant {
+	if userID == 0 || len(experiment.Variants) == 0 {
+		return nil
+	}
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s:%d", experiment.Key, userID)
+	sum := h.Sum32()
+	if int(sum%100) >= experiment.TrafficPercent {
+		return nil
+	}
+
+	variants := make([]*model.ExperimentVariant, len(experiment.Variants))
+	copy(variants, experiment.Variants)
+	sort.Slice(variants, func(i, j int) bool { return variants[i].ID < variants[j].ID })
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+	point := int((sum / 100) % uint32(total))
+	for _, v := range variants {
+		if point < v.Weight {
+			return v
+		}
+		point -= v.Weight
+	}
+	return nil
+}
+
+func controlVariant(experiment *model.Experiment) *model.ExperimentVariant {
+	for _, v := range experiment.Variants {
+		if v.IsControl {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,508 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// 抢购令牌有效期，也是排队准入计数的统计窗口
+	flashSaleTokenTTL = 30 * time.Second
+	// 每个统计窗口内准入的人数最多为剩余库存的倍数，超出的请求直接返回繁忙
+	flashSaleAdmitFactor = 2
+	// 抢购成功后等待订单服务确认的时间，超时未确认则释放库存
+	flashSaleReservationTTL = 15 * time.Minute
+	// 场次开始前提前预热 Redis 库存的时间
+	flashSalePreloadLead = 10 * time.Minute
+	// 场次结束后 Redis 数据的保留时间
+	flashSaleKeyRetention = time.Hour
+	// 每次释放过期预占的最大数量
+	flashSaleReleaseBatch = 500
+)
+
+// Redis 脚本返回的错误码
+const (
+	flashSaleNotLoaded    = -1
+	flashSaleNotStarted   = -2
+	flashSaleEnded        = -3
+	flashSaleSoldOut      = -4
+	flashSaleLimitReached = -5
+	flashSaleBusy         = -6
+	flashSaleBadToken     = -7
+)
+
+// preloadScript 写入场次时间、每人限购、秒杀库存和已购数量，库存已存在时不覆盖，保证重复预热是幂等的
+var preloadScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then return 0 end
+redis.call('HSET', KEYS[1], 'start_at', ARGV[1], 'end_at', ARGV[2], 'per_user_limit', ARGV[3])
+redis.call('SET', KEYS[2], ARGV[4])
+redis.call('DEL', KEYS[3])
+for i = 6, #ARGV, 2 do
+	redis.call('HSET', KEYS[3], ARGV[i], ARGV[i + 1])
+end
+for i = 1, 3 do
+	redis.call('EXPIREAT', KEYS[i], ARGV[5])
+end
+return 1
+`)
+
+// acquireScript 检查场次时间、库存、限购和准入人数，通过后发放抢购令牌
+var acquireScript = redis.NewScript(`
+local meta = redis.call('HMGET', KEYS[1], 'start_at', 'end_at', 'per_user_limit')
+if not meta[1] then return -1 end
+local now = tonumber(ARGV[1])
+if now < tonumber(meta[1]) then return -2 end
+if now >= tonumber(meta[2]) then return -3 end
+local stock = tonumber(redis.call('GET', KEYS[2]) or '0')
+if stock <= 0 then return -4 end
+local limit = tonumber(meta[3])
+if limit > 0 and tonumber(redis.call('HGET', KEYS[3], ARGV[2]) or '0') >= limit then return -5 end
+local admitted = redis.call('INCR', KEYS[4])
+if admitted == 1 then redis.call('EXPIRE', KEYS[4], ARGV[3]) end
+if admitted > stock * tonumber(ARGV[4]) then return -6 end
+redis.call('SET', KEYS[5], ARGV[2], 'EX', ARGV[3])
+return stock
+`)
+
+// purchaseScript 校验并消耗抢购令牌，原子扣减秒杀库存并累加用户已购数量
+var purchaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[4]) ~= ARGV[2] then return -7 end
+local meta = redis.call('HMGET', KEYS[1], 'start_at', 'end_at', 'per_user_limit')
+if not meta[1] then return -1 end
+local now = tonumber(ARGV[1])
+if now < tonumber(meta[1]) then return -2 end
+if now >= tonumber(meta[2]) then return -3 end
+local qty = tonumber(ARGV[3])
+local stock = tonumber(redis.call('GET', KEYS[2]) or '0')
+if stock < qty then return -4 end
+local limit = tonumber(meta[3])
+local bought = tonumber(redis.call('HGET', KEYS[3], ARGV[2]) or '0')
+if limit > 0 and bought + qty > limit then return -5 end
+redis.call('DECRBY', KEYS[2], qty)
+redis.call('HINCRBY', KEYS[3], ARGV[2], qty)
+local ttl = redis.call('TTL', KEYS[2])
+if ttl > 0 then redis.call('EXPIRE', KEYS[3], ttl) end
+redis.call('DEL', KEYS[4])
+return stock - qty
+`)
+
+// releaseScript 归还秒杀库存和用户已购数量，场次数据已过期时不做处理
+var releaseScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then return 0 end
+redis.call('INCRBY', KEYS[1], ARGV[2])
+if redis.call('HINCRBY', KEYS[2], ARGV[1], -tonumber(ARGV[2])) <= 0 then
+	redis.call('HDEL', KEYS[2], ARGV[1])
+end
+return 1
+`)
+
+// FlashSaleItemRequest 表示秒杀商品配置
+type FlashSaleItemRequest struct {
+	ProductID     uint    `json:"product_id" binding:"required"`
+	SKUID         uint    `json:"sku_id"`
+	FlashPrice    float64 `json:"flash_price" binding:"required,gt=0"`
+	OriginalPrice float64 `json:"original_price" binding:"required,gtfield=FlashPrice"`
+	SaleStock     int     `json:"sale_stock" binding:"required,min=1"`
+	PerUserLimit  int     `json:"per_user_limit" binding:"min=0"`
+}
+
+// CreateFlashSaleRequest 表示创建秒杀场次的请求
+type CreateFlashSaleRequest struct {
+	Name    string                 `json:"name" binding:"required,max=100"`
+	StartAt time.Time              `json:"start_at" binding:"required"`
+	EndAt   time.Time              `json:"end_at" binding:"required,gtfield=StartAt"`
+	Items   []FlashSaleItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// FlashSaleToken 表示抢购令牌，用户需在有效期内凭令牌下单
+type FlashSaleToken struct {
+	ItemID    uint      `json:"item_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FlashSalePurchaseRequest 表示凭令牌抢购的请求
+type FlashSalePurchaseRequest struct {
+	ItemID   uint   `json:"item_id" binding:"required"`
+	Token    string `json:"token" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+}
+
+// ConfirmReservationRequest 表示订单服务确认秒杀预占的请求
+type ConfirmReservationRequest struct {
+	OrderID     uint   `json:"order_id" binding:"required"`
+	OrderNumber string `json:"order_number" binding:"required"`
+}
+
+// FlashSaleService 负责秒杀场次管理和秒杀库存扣减。秒杀库存和用户已购数量保存在 Redis 中，
+// 通过 Lua 脚本原子扣减；用户需先获取抢购令牌，准入人数按剩余库存限流，以应对流量高峰。
+type FlashSaleService struct {
+	flashSaleRepo repository.FlashSaleRepository
+	rdb           *redis.Client
+	log           *logger.Logger
+}
+
+// NewFlashSaleService 创建秒杀服务
+func NewFlashSaleService(flashSaleRepo repository.FlashSaleRepository, rdb *redis.Client, log *logger.Logger) *FlashSaleService {
+	return &FlashSaleService{
+		flashSaleRepo: flashSaleRepo,
+		rdb:           rdb,
+		log:           log,
+	}
+}
+
+// CreateSession 创建秒杀场次，开始时间临近的场次会立即预热库存
+func (s *FlashSaleService) CreateSession(ctx context.Context, req *CreateFlashSaleRequest) (*model.FlashSaleSession, error) {
+	if !req.EndAt.After(time.Now()) {
+		return nil, apperrors.NewBadRequest("结束时间必须晚于当前时间", nil)
+	}
+
+	session := &model.FlashSaleSession{
+		Name:     req.Name,
+		StartAt:  req.StartAt,
+		EndAt:    req.EndAt,
+		IsActive: true,
+	}
+	for _, item := range req.Items {
+		session.Items = append(session.Items, &model.FlashSaleItem{
+			ProductID:     item.ProductID,
+			SKUID:         item.SKUID,
+			FlashPrice:    money(item.FlashPrice),
+			OriginalPrice: money(item.OriginalPrice),
+			SaleStock:     item.SaleStock,
+			PerUserLimit:  item.PerUserLimit,
+		})
+	}
+	if err := s.flashSaleRepo.CreateSession(ctx, session); err != nil {
+		return nil, apperrors.NewInternalServerError("创建秒杀场次失败", err)
+	}
+
+	if time.Until(session.StartAt) <= flashSalePreloadLead {
+		if err := s.preload(ctx, session); err != nil {
+			return nil, apperrors.NewInternalServerError("预热秒杀库存失败", err)
+		}
+	}
+	return session, nil
+}
+
+// GetSession 获取秒杀场次
+func (s *FlashSaleService) GetSession(ctx context.Context, id uint) (*model.FlashSaleSession, error) {
+	session, err := s.flashSaleRepo.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("秒杀场次不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取秒杀场次失败", err)
+	}
+	return session, nil
+}
+
+// ListSessions 获取进行中和即将开始的秒杀场次
+func (s *FlashSaleService) ListSessions(ctx context.Context) ([]*model.FlashSaleSession, error) {
+	sessions, err := s.flashSaleRepo.ListSessions(ctx, time.Now())
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取秒杀场次失败", err)
+	}
+	return sessions, nil
+}
+
+// Preload 预热场次的秒杀库存，已预热的商品不会被覆盖
+func (s *FlashSaleService) Preload(ctx context.Context, sessionID uint) error {
+	session, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if !session.EndAt.After(time.Now()) {
+		return apperrors.NewBadRequest("秒杀场次已结束", nil)
+	}
+	if err := s.preload(ctx, session); err != nil {
+		return apperrors.NewInternalServerError("预热秒杀库存失败", err)
+	}
+	return nil
+}
+
+// AcquireToken 进入抢购队列并获取抢购令牌
+func (s *FlashSaleService) AcquireToken(ctx context.Context, userID, itemID uint) (*FlashSaleToken, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, apperrors.NewInternalServerError("生成抢购令牌失败", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	now := time.Now()
+	keys := []string{metaKey(itemID), stockKey(itemID), buyersKey(itemID), admitKey(itemID), tokenKey(itemID, token)}
+	code, err := acquireScript.Run(ctx, s.rdb, keys, now.Unix(), userID, int(flashSaleTokenTTL.Seconds()), flashSaleAdmitFactor).Int64()
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable("秒杀服务繁忙，请稍后再试", err)
+	}
+	if code < 0 {
+		return nil, flashSaleError(code)
+	}
+	return &FlashSaleToken{ItemID: itemID, Token: token, ExpiresAt: now.Add(flashSaleTokenTTL)}, nil
+}
+
+// Purchase 凭抢购令牌扣减秒杀库存并创建预占记录，订单服务需在预占过期前确认
+func (s *FlashSaleService) Purchase(ctx context.Context, userID uint, req *FlashSalePurchaseRequest) (*model.FlashSaleReservation, error) {
+	now := time.Now()
+	keys := []string{metaKey(req.ItemID), stockKey(req.ItemID), buyersKey(req.ItemID), tokenKey(req.ItemID, req.Token)}
+	code, err := purchaseScript.Run(ctx, s.rdb, keys, now.Unix(), userID, req.Quantity).Int64()
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable("秒杀服务繁忙，请稍后再试", err)
+	}
+	if code < 0 {
+		return nil, flashSaleError(code)
+	}
+
+	// 库存扣减成功后才访问数据库，失败时归还 Redis 中已扣减的库存
+	item, err := s.flashSaleRepo.GetItem(ctx, req.ItemID)
+	if err != nil {
+		s.restockOrLog(ctx, req.ItemID, userID, req.Quantity)
+		return nil, apperrors.NewInternalServerError("获取秒杀商品失败", err)
+	}
+
+	reservation := &model.FlashSaleReservation{
+		SessionID:  item.SessionID,
+		ItemID:     item.ID,
+		UserID:     userID,
+		ProductID:  item.ProductID,
+		SKUID:      item.SKUID,
+		Quantity:   req.Quantity,
+		FlashPrice: item.FlashPrice,
+		Status:     model.FlashSaleReservationReserved,
+		ExpiresAt:  now.Add(flashSaleReservationTTL),
+	}
+	if err := s.flashSaleRepo.CreateReservation(ctx, reservation); err != nil {
+		s.restockOrLog(ctx, item.ID, userID, req.Quantity)
+		return nil, apperrors.NewInternalServerError("创建秒杀预占失败", err)
+	}
+	return reservation, nil
+}
+
+// GetReservation 获取秒杀预占记录
+func (s *FlashSaleService) GetReservation(ctx context.Context, id uint) (*model.FlashSaleReservation, error) {
+	reservation, err := s.flashSaleRepo.GetReservation(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("秒杀预占不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取秒杀预占失败", err)
+	}
+	return reservation, nil
+}
+
+// ConfirmReservation 订单创建成功后确认秒杀预占
+func (s *FlashSaleService) ConfirmReservation(ctx context.Context, id uint, req *ConfirmReservationRequest) (*model.FlashSaleReservation, error) {
+	reservation, err := s.GetReservation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if reservation.Status == model.FlashSaleReservationReserved && !now.Before(reservation.ExpiresAt) {
+		return nil, apperrors.NewConflict("秒杀预占已过期", nil)
+	}
+
+	ok, err := s.flashSaleRepo.ConfirmReservation(ctx, reservation, req.OrderID, req.OrderNumber, now)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("确认秒杀预占失败", err)
+	}
+	if !ok {
+		return nil, apperrors.NewConflict(fmt.Sprintf("秒杀预占状态为 %s，不能确认", reservation.Status), nil)
+	}
+
+	reservation.Status = model.FlashSaleReservationConfirmed
+	reservation.OrderID = &req.OrderID
+	reservation.OrderNumber = &req.OrderNumber
+	reservation.ConfirmedAt = &now
+	return reservation, nil
+}
+
+// ReleaseReservation 释放未确认的秒杀预占并归还库存，如用户取消下单
+func (s *FlashSaleService) ReleaseReservation(ctx context.Context, id uint) error {
+	reservation, err := s.GetReservation(ctx, id)
+	if err != nil {
+		return err
+	}
+	ok, err := s.release(ctx, reservation)
+	if err != nil {
+		return apperrors.NewInternalServerError("释放秒杀预占失败", err)
+	}
+	if !ok {
+		return apperrors.NewConflict(fmt.Sprintf("秒杀预占状态为 %s，不能释放", reservation.Status), nil)
+	}
+	return nil
+}
+
+// Run 定期释放过期的秒杀预占并预热即将开始的场次，直到 ctx 被取消
+func (s *FlashSaleService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.releaseExpired(ctx)
+		s.preloadUpcoming(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *FlashSaleService) releaseExpired(ctx context.Context) {
+	reservations, err := s.flashSaleRepo.ListExpiredReservations(ctx, time.Now(), flashSaleReleaseBatch)
+	if err != nil {
+		s.log.Error(ctx, "Failed to list expired flash sale reservations", zap.Error(err))
+		return
+	}
+	for _, reservation := range reservations {
+		if _, err := s.release(ctx, reservation); err != nil {
+			s.log.Error(ctx, "Failed to release flash sale reservation",
+				zap.Uint("reservation_id", reservation.ID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func (s *FlashSaleService) preloadUpcoming(ctx context.Context) {
+	now := time.Now()
+	sessions, err := s.flashSaleRepo.ListSessions(ctx, now)
+	if err != nil {
+		s.log.Error(ctx, "Failed to list flash sale sessions", zap.Error(err))
+		return
+	}
+	for _, session := range sessions {
+		if session.StartAt.Sub(now) > flashSalePreloadLead {
+			continue
+		}
+		if err := s.preload(ctx, session); err != nil {
+			s.log.Error(ctx, "Failed to preload flash sale session",
+				zap.Uint("session_id", session.ID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// preload 将场次商品的剩余秒杀库存和用户已购数量写入 Redis，库存扣除了数据库中未释放的预占
+func (s *FlashSaleService) preload(ctx context.Context, session *model.FlashSaleSession) error {
+	expireAt := session.EndAt.Add(flashSaleReservationTTL + flashSaleKeyRetention).Unix()
+	for _, item := range session.Items {
+		exists, err := s.rdb.Exists(ctx, stockKey(item.ID)).Result()
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			continue
+		}
+
+		reserved, err := s.flashSaleRepo.SumReserved(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+		bought, err := s.flashSaleRepo.SumUserReserved(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+		stock := item.SaleStock - reserved
+		if stock < 0 {
+			stock = 0
+		}
+
+		args := []interface{}{session.StartAt.Unix(), session.EndAt.Unix(), item.PerUserLimit, stock, expireAt}
+		for userID, qty := range bought {
+			args = append(args, userID, qty)
+		}
+		keys := []string{metaKey(item.ID), stockKey(item.ID), buyersKey(item.ID)}
+		if err := preloadScript.Run(ctx, s.rdb, keys, args...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release 将预占标记为已释放并归还 Redis 库存，预占已确认或已释放时返回 false
+func (s *FlashSaleService) release(ctx context.Context, reservation *model.FlashSaleReservation) (bool, error) {
+	ok, err := s.flashSaleRepo.ReleaseReservation(ctx, reservation.ID, time.Now())
+	if err != nil || !ok {
+		return ok, err
+	}
+	return true, s.restock(ctx, reservation.ItemID, reservation.UserID, reservation.Quantity)
+}
+
+func (s *FlashSaleService) restock(ctx context.Context, itemID, userID uint, quantity int) error {
+	keys := []string{stockKey(itemID), buyersKey(itemID)}
+	return releaseScript.Run(ctx, s.rdb, keys, userID, quantity).Err()
+}
+
+func (s *FlashSaleService) restockOrLog(ctx context.Context, itemID, userID uint, quantity int) {
+	if err := s.restock(ctx, itemID, userID, quantity); err != nil {
+		s.log.Error(ctx, "Failed to restock flash sale item",
+			zap.Uint("item_id", itemID),
+			zap.Uint("user_id", userID),
+			zap.Int("quantity", quantity),
+			zap.Error(err),
+		)
+	}
+}
+
+// flashSaleError 将 Redis 脚本错误码转换为业务错误
+func flashSaleError(code int64) error {
+	switch code {
+	case flashSaleNotLoaded:
+		return apperrors.NewServiceUnavailable("秒杀商品尚未开放", nil)
+	case flashSaleNotStarted:
+		return apperrors.NewBadRequest("秒杀尚未开始", nil)
+	case flashSaleEnded:
+		return apperrors.NewBadRequest("秒杀已结束", nil)
+	case flashSaleSoldOut:
+		return apperrors.New(apperrors.ErrFlashSaleSoldOut, "秒杀商品已抢完", http.StatusConflict, nil)
+	case flashSaleLimitReached:
+		return apperrors.NewBadRequest("已达到每人限购数量", nil)
+	case flashSaleBusy:
+		return apperrors.New(apperrors.ErrFlashSaleBusy, "排队人数过多，请稍后再试", http.StatusTooManyRequests, nil)
+	case flashSaleBadToken:
+		return apperrors.NewBadRequest("抢购令牌无效或已过期", nil)
+	default:
+		return apperrors.NewInternalServerError("秒杀失败", fmt.Errorf("unexpected flash sale code %d", code))
+	}
+}
+
+// Redis 键使用 {itemID} 作为哈希标签，保证同一商品的键落在同一个集群槽位，以便在 Lua 脚本中一起操作
+func flashSaleKey(itemID uint, suffix string) string {
+	return fmt.Sprintf("flash_sale:{%d}:%s", itemID, suffix)
+}
+
+func metaKey(itemID uint) string {
+	return flashSaleKey(itemID, "meta")
+}
+
+func stockKey(itemID uint) string {
+	return flashSaleKey(itemID, "stock")
+}
+
+func buyersKey(itemID uint) string {
+	return flashSaleKey(itemID, "buyers")
+}
+
+func admitKey(itemID uint) string {
+	return flashSaleKey(itemID, "admitted")
+}
+
+func tokenKey(itemID uint, token string) string {
+	return flashSaleKey(itemID, "token:"+token)
+}
@@ -0,0 +1,548 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/marketing/internal/event"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// 提前多久提醒用户积分即将过期
+	pointsExpiryNoticeLead = 7 * 24 * time.Hour
+	// 每轮处理的积分批次数量
+	pointsExpiryBatch = 500
+)
+
+// PointsSummary 表示用户积分概览
+type PointsSummary struct {
+	Balance      int                              `json:"balance"`
+	TotalEarned  int                              `json:"total_earned"`
+	RedeemRate   int                              `json:"redeem_rate,omitempty"` // 多少积分抵扣1元，未开放抵现时为 0
+	Transactions []*model.LoyaltyPointTransaction `json:"transactions"`
+	Total        int64                            `json:"total"`
+	Page         int                              `json:"page"`
+	PageSize     int                              `json:"page_size"`
+}
+
+// RedeemQuoteRequest 表示结算时查询积分可抵扣金额的请求，Points 为 0 时按最多可用积分计算
+type RedeemQuoteRequest struct {
+	UserID      uint    `json:"user_id" binding:"required"`
+	OrderAmount float64 `json:"order_amount" binding:"required,gt=0"`
+	Points      int     `json:"points" binding:"min=0"`
+}
+
+// RedeemQuote 表示积分抵扣试算结果
+type RedeemQuote struct {
+	Balance    int     `json:"balance"`
+	MaxPoints  int     `json:"max_points"` // 本单最多可用积分
+	Points     int     `json:"points"`     // 实际使用积分
+	Amount     float64 `json:"amount"`     // 抵扣金额
+	RedeemRate int     `json:"redeem_rate"`
+}
+
+// RedeemRequest 表示下单时使用积分抵现的请求，由订单服务调用
+type RedeemRequest struct {
+	UserID      uint    `json:"user_id" binding:"required"`
+	OrderID     uint    `json:"order_id" binding:"required"`
+	OrderNumber string  `json:"order_number" binding:"required"`
+	OrderAmount float64 `json:"order_amount" binding:"required,gt=0"`
+	Points      int     `json:"points" binding:"required,min=1"`
+}
+
+// RedeemResult 表示积分抵现结果
+type RedeemResult struct {
+	TransactionID uint    `json:"transaction_id"`
+	Points        int     `json:"points"`
+	Amount        float64 `json:"amount"`
+	Balance       int     `json:"balance"`
+}
+
+// LoyaltyService 负责积分的累计、抵现和退款扣回
+type LoyaltyService struct {
+	loyaltyRepo repository.LoyaltyRepository
+	publisher   event.Publisher
+	log         *logger.Logger
+}
+
+// NewLoyaltyService 创建积分服务
+func NewLoyaltyService(loyaltyRepo repository.LoyaltyRepository, publisher event.Publisher, log *logger.Logger) *LoyaltyService {
+	return &LoyaltyService{
+		loyaltyRepo: loyaltyRepo,
+		publisher:   publisher,
+		log:         log,
+	}
+}
+
+// Subscribe 订阅订单事件：订单完成时累计积分，取消时退还抵现积分，退款时扣回积分
+func (s *LoyaltyService) Subscribe(sub *event.Subscriber) error {
+	if err := sub.Subscribe(event.OrderCompleted, func(ctx context.Context, data json.RawMessage) error {
+		var evt event.OrderEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		return s.HandleOrderCompleted(ctx, &evt)
+	}); err != nil {
+		return err
+	}
+	if err := sub.Subscribe(event.OrderCancelled, func(ctx context.Context, data json.RawMessage) error {
+		var evt event.OrderEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		return s.HandleOrderCancelled(ctx, &evt)
+	}); err != nil {
+		return err
+	}
+	return sub.Subscribe(event.OrderRefunded, func(ctx context.Context, data json.RawMessage) error {
+		var evt event.OrderRefundEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		return s.HandleOrderRefunded(ctx, &evt)
+	})
+}
+
+// Summary 获取用户积分余额和积分明细
+func (s *LoyaltyService) Summary(ctx context.Context, userID uint, page, pageSize int) (*PointsSummary, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	summary := &PointsSummary{Page: page, PageSize: pageSize}
+	account, err := s.loyaltyRepo.GetAccount(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NewInternalServerError("获取积分账户失败", err)
+	}
+	if account != nil {
+		summary.Balance = account.Balance
+		summary.TotalEarned = account.TotalEarned
+	}
+
+	transactions, total, err := s.loyaltyRepo.ListTransactions(ctx, userID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取积分明细失败", err)
+	}
+	summary.Transactions = transactions
+	summary.Total = total
+
+	if rule, err := s.loyaltyRepo.GetActiveRule(ctx, time.Now()); err == nil && rule.RedeemRate > 0 {
+		summary.RedeemRate = rule.RedeemRate
+	}
+	return summary, nil
+}
+
+// Quote 试算积分可抵扣的金额
+func (s *LoyaltyService) Quote(ctx context.Context, req *RedeemQuoteRequest) (*RedeemQuote, error) {
+	rule, err := s.redeemRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance int
+	account, err := s.loyaltyRepo.GetAccount(ctx, req.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NewInternalServerError("获取积分账户失败", err)
+	}
+	if account != nil {
+		balance = account.Balance
+	}
+
+	maxPoints := maxRedeemPoints(rule, req.OrderAmount)
+	if balance < maxPoints {
+		maxPoints = balance
+	}
+	if maxPoints < 0 {
+		maxPoints = 0
+	}
+	points := req.Points
+	if points == 0 || points > maxPoints {
+		points = maxPoints
+	}
+
+	return &RedeemQuote{
+		Balance:    balance,
+		MaxPoints:  maxPoints,
+		Points:     points,
+		Amount:     pointsAmount(points, rule.RedeemRate),
+		RedeemRate: rule.RedeemRate,
+	}, nil
+}
+
+// Redeem 下单时扣减积分抵现，同一订单重复调用返回首次的结果
+func (s *LoyaltyService) Redeem(ctx context.Context, req *RedeemRequest) (*RedeemResult, error) {
+	rule, err := s.redeemRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if maxPoints := maxRedeemPoints(rule, req.OrderAmount); req.Points > maxPoints {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("本单最多可使用 %d 积分", maxPoints), nil)
+	}
+
+	orderRef := strconv.FormatUint(uint64(req.OrderID), 10)
+	transaction := &model.LoyaltyPointTransaction{
+		UserID:        req.UserID,
+		Points:        -req.Points,
+		Type:          model.LoyaltyTxRedeem,
+		ReferenceID:   &orderRef,
+		ReferenceType: stringPtr(model.LoyaltyRefOrder),
+		OrderID:       &req.OrderID,
+		Description:   fmt.Sprintf("订单 %s 积分抵现", req.OrderNumber),
+	}
+	err = s.loyaltyRepo.Record(ctx, transaction, false)
+	switch {
+	case errors.Is(err, repository.ErrInsufficientPoints):
+		return nil, apperrors.NewBadRequest("积分余额不足", err)
+	case errors.Is(err, repository.ErrDuplicatePointsTransaction):
+		existing, ferr := s.loyaltyRepo.FindTransaction(ctx, model.LoyaltyTxRedeem, model.LoyaltyRefOrder, orderRef)
+		if ferr != nil {
+			return nil, apperrors.NewInternalServerError("获取积分交易失败", ferr)
+		}
+		if existing.UserID != req.UserID || -existing.Points != req.Points {
+			return nil, apperrors.NewConflict("订单已使用过积分抵现", err)
+		}
+		transaction = existing
+	case err != nil:
+		return nil, apperrors.NewInternalServerError("积分抵现失败", err)
+	}
+
+	return &RedeemResult{
+		TransactionID: transaction.ID,
+		Points:        -transaction.Points,
+		Amount:        pointsAmount(-transaction.Points, rule.RedeemRate),
+		Balance:       transaction.Balance,
+	}, nil
+}
+
+// HandleOrderCompleted 订单完成后按积分规则累计积分，排除商品和积分抵现部分不累计积分
+func (s *LoyaltyService) HandleOrderCompleted(ctx context.Context, evt *event.OrderEvent) error {
+	rule, err := s.loyaltyRepo.GetActiveRule(ctx, time.Now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if money(evt.GrandTotal).LessThan(rule.MinOrderAmount) {
+		return nil
+	}
+
+	var itemsTotal, eligible float64
+	for _, item := range evt.Items {
+		itemsTotal += item.Total
+		if !excludedFromPoints(rule, &item) {
+			eligible += item.Total
+		}
+	}
+	if eligible <= 0 {
+		return nil
+	}
+
+	sums, err := s.loyaltyRepo.SumOrderPoints(ctx, evt.OrderID)
+	if err != nil {
+		return err
+	}
+	if redeemed := -sums[model.LoyaltyTxRedeem] - sums[model.LoyaltyTxRefund]; redeemed > 0 && rule.RedeemRate > 0 {
+		eligible -= pointsAmount(redeemed, rule.RedeemRate) * eligible / itemsTotal
+	}
+	points := int(math.Floor(eligible * float64(rule.PointsPerSpend)))
+	if points <= 0 {
+		return nil
+	}
+
+	transaction := &model.LoyaltyPointTransaction{
+		UserID:        evt.UserID,
+		Points:        points,
+		Type:          model.LoyaltyTxEarn,
+		ReferenceID:   stringPtr(strconv.FormatUint(uint64(evt.OrderID), 10)),
+		ReferenceType: stringPtr(model.LoyaltyRefOrder),
+		OrderID:       &evt.OrderID,
+		Description:   fmt.Sprintf("订单 %s 完成获得积分", evt.OrderNumber),
+	}
+	if rule.PointsValidDays != nil && *rule.PointsValidDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, *rule.PointsValidDays)
+		transaction.ExpiresAt = &expiresAt
+	}
+	return s.record(ctx, transaction, false)
+}
+
+// HandleOrderCancelled 订单取消后退还抵现积分
+func (s *LoyaltyService) HandleOrderCancelled(ctx context.Context, evt *event.OrderEvent) error {
+	sums, err := s.loyaltyRepo.SumOrderPoints(ctx, evt.OrderID)
+	if err != nil {
+		return err
+	}
+	restore := -sums[model.LoyaltyTxRedeem] - sums[model.LoyaltyTxRefund]
+	if restore <= 0 {
+		return nil
+	}
+
+	return s.record(ctx, &model.LoyaltyPointTransaction{
+		UserID:        evt.UserID,
+		Points:        restore,
+		Type:          model.LoyaltyTxRefund,
+		ReferenceID:   stringPtr(strconv.FormatUint(uint64(evt.OrderID), 10)),
+		ReferenceType: stringPtr(model.LoyaltyRefOrder),
+		OrderID:       &evt.OrderID,
+		Description:   fmt.Sprintf("订单 %s 取消退还积分", evt.OrderNumber),
+	}, false)
+}
+
+// HandleOrderRefunded 按退款比例扣回订单获得的积分并退还抵现积分，扣回时积分余额允许为负
+func (s *LoyaltyService) HandleOrderRefunded(ctx context.Context, evt *event.OrderRefundEvent) error {
+	sums, err := s.loyaltyRepo.SumOrderPoints(ctx, evt.OrderID)
+	if err != nil {
+		return err
+	}
+	ratio := 1.0
+	if evt.GrandTotal > 0 && evt.RefundAmount < evt.GrandTotal {
+		ratio = evt.RefundAmount / evt.GrandTotal
+	}
+
+	earned, reversed := sums[model.LoyaltyTxEarn], -sums[model.LoyaltyTxReverse]
+	if reverse := proportionalPoints(earned, reversed, ratio); reverse > 0 {
+		err := s.record(ctx, &model.LoyaltyPointTransaction{
+			UserID:        evt.UserID,
+			Points:        -reverse,
+			Type:          model.LoyaltyTxReverse,
+			ReferenceID:   stringPtr(evt.RefundID),
+			ReferenceType: stringPtr(model.LoyaltyRefRefund),
+			OrderID:       &evt.OrderID,
+			Description:   fmt.Sprintf("订单 %s 退款扣回积分", evt.OrderNumber),
+		}, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	redeemed, refunded := -sums[model.LoyaltyTxRedeem], sums[model.LoyaltyTxRefund]
+	if restore := proportionalPoints(redeemed, refunded, ratio); restore > 0 {
+		return s.record(ctx, &model.LoyaltyPointTransaction{
+			UserID:        evt.UserID,
+			Points:        restore,
+			Type:          model.LoyaltyTxRefund,
+			ReferenceID:   stringPtr(evt.RefundID),
+			ReferenceType: stringPtr(model.LoyaltyRefRefund),
+			OrderID:       &evt.OrderID,
+			Description:   fmt.Sprintf("订单 %s 退款退还抵现积分", evt.OrderNumber),
+		}, false)
+	}
+	return nil
+}
+
+// Reward 发放营销奖励积分，同一奖励只记一次，有效期与订单获得的积分相同
+func (s *LoyaltyService) Reward(ctx context.Context, userID uint, points int, referenceType, referenceID, description string) error {
+	transaction := &model.LoyaltyPointTransaction{
+		UserID:        userID,
+		Points:        points,
+		Type:          model.LoyaltyTxReward,
+		ReferenceID:   stringPtr(referenceID),
+		ReferenceType: stringPtr(referenceType),
+		Description:   description,
+	}
+	rule, err := s.loyaltyRepo.GetActiveRule(ctx, time.Now())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if rule != nil && rule.PointsValidDays != nil && *rule.PointsValidDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, *rule.PointsValidDays)
+		transaction.ExpiresAt = &expiresAt
+	}
+	return s.record(ctx, transaction, false)
+}
+
+// Run 定期过期到期的积分批次并发送即将过期提醒，直到 ctx 被取消
+func (s *LoyaltyService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.expirePoints(ctx)
+		s.notifyExpiring(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expirePoints 按过期时间先后过期积分批次的剩余积分，每个用户汇总发布一条过期事件
+func (s *LoyaltyService) expirePoints(ctx context.Context) {
+	for {
+		lots, err := s.loyaltyRepo.ListExpiredLots(ctx, time.Now(), pointsExpiryBatch)
+		if err != nil {
+			s.log.Error(ctx, "Failed to list expired loyalty point lots", zap.Error(err))
+			return
+		}
+
+		expired := make(map[uint]*event.PointsExpiredEvent)
+		failed := 0
+		for _, lot := range lots {
+			transaction, err := s.loyaltyRepo.ExpireLot(ctx, lot.ID)
+			if err != nil {
+				failed++
+				s.log.Error(ctx, "Failed to expire loyalty point lot", zap.Uint("lot_id", lot.ID), zap.Error(err))
+				continue
+			}
+			if transaction == nil {
+				continue
+			}
+			evt, ok := expired[lot.UserID]
+			if !ok {
+				evt = &event.PointsExpiredEvent{UserID: lot.UserID}
+				expired[lot.UserID] = evt
+			}
+			evt.Points += -transaction.Points
+			evt.Balance = transaction.Balance
+		}
+
+		for _, evt := range expired {
+			if err := s.publisher.Publish(ctx, event.PointsExpired, evt); err != nil {
+				s.log.Error(ctx, "Failed to publish points expired event", zap.Uint("user_id", evt.UserID), zap.Error(err))
+			}
+		}
+
+		// 本轮全部失败时不再重试，避免同一批次反复失败造成死循环
+		if len(lots) < pointsExpiryBatch || failed == len(lots) {
+			return
+		}
+	}
+}
+
+// notifyExpiring 为即将过期的积分发送提醒，每个批次只提醒一次
+func (s *LoyaltyService) notifyExpiring(ctx context.Context) {
+	now := time.Now()
+	for {
+		lots, err := s.loyaltyRepo.ListExpiringLots(ctx, now, now.Add(pointsExpiryNoticeLead), pointsExpiryBatch)
+		if err != nil {
+			s.log.Error(ctx, "Failed to list expiring loyalty point lots", zap.Error(err))
+			return
+		}
+
+		expiring := make(map[uint]*event.PointsExpiringEvent)
+		lotIDs := make(map[uint][]uint)
+		for _, lot := range lots {
+			evt, ok := expiring[lot.UserID]
+			if !ok {
+				evt = &event.PointsExpiringEvent{UserID: lot.UserID, ExpiresAt: *lot.ExpiresAt}
+				expiring[lot.UserID] = evt
+			}
+			evt.Points += lot.Remaining
+			if lot.ExpiresAt.Before(evt.ExpiresAt) {
+				evt.ExpiresAt = *lot.ExpiresAt
+			}
+			lotIDs[lot.UserID] = append(lotIDs[lot.UserID], lot.ID)
+		}
+
+		notified := 0
+		for userID, evt := range expiring {
+			if err := s.publisher.Publish(ctx, event.PointsExpiring, evt); err != nil {
+				s.log.Error(ctx, "Failed to publish points expiring event", zap.Uint("user_id", userID), zap.Error(err))
+				continue
+			}
+			if err := s.loyaltyRepo.MarkExpiryNotified(ctx, lotIDs[userID], now); err != nil {
+				s.log.Error(ctx, "Failed to mark loyalty point lots notified", zap.Uint("user_id", userID), zap.Error(err))
+				continue
+			}
+			notified++
+		}
+
+		if len(lots) < pointsExpiryBatch || notified == 0 {
+			return
+		}
+	}
+}
+
+// record 写入积分交易，重复投递的事件会因关联已存在而被忽略
+func (s *LoyaltyService) record(ctx context.Context, transaction *model.LoyaltyPointTransaction, allowNegative bool) error {
+	err := s.loyaltyRepo.Record(ctx, transaction, allowNegative)
+	if errors.Is(err, repository.ErrDuplicatePointsTransaction) {
+		s.log.Info(ctx, "Skipping duplicate loyalty point transaction",
+			zap.String("type", transaction.Type),
+			zap.Stringp("reference_id", transaction.ReferenceID),
+		)
+		return nil
+	}
+	return err
+}
+
+// redeemRule 获取当前生效且开放积分抵现的积分规则
+func (s *LoyaltyService) redeemRule(ctx context.Context) (*model.LoyaltyPointRule, error) {
+	rule, err := s.loyaltyRepo.GetActiveRule(ctx, time.Now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewBadRequest("积分抵现未开放", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取积分规则失败", err)
+	}
+	if rule.RedeemRate <= 0 || rule.MaxRedeemRatio <= 0 {
+		return nil, apperrors.NewBadRequest("积分抵现未开放", nil)
+	}
+	return rule, nil
+}
+
+// excludedFromPoints 判断商品是否被积分规则排除
+func excludedFromPoints(rule *model.LoyaltyPointRule, item *event.OrderItem) bool {
+	for _, id := range rule.ExcludedProductIDs {
+		if id == item.ProductID {
+			return true
+		}
+	}
+	for _, id := range rule.ExcludedCategoryIDs {
+		for _, cid := range item.CategoryIDs {
+			if id == cid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maxRedeemPoints 计算订单金额最多可抵扣的积分
+func maxRedeemPoints(rule *model.LoyaltyPointRule, orderAmount float64) int {
+	return int(math.Floor(orderAmount * rule.MaxRedeemRatio * float64(rule.RedeemRate)))
+}
+
+// pointsAmount 计算积分可抵扣的金额，精确到分并向下取整
+func pointsAmount(points, rate int) float64 {
+	if rate <= 0 {
+		return 0
+	}
+	return math.Floor(float64(points)*100/float64(rate)) / 100
+}
+
+// proportionalPoints 按比例计算还需处理的积分，不超过 total 中尚未处理的部分
+func proportionalPoints(total, done int, ratio float64) int {
+	remaining := total - done
+	if remaining <= 0 {
+		return 0
+	}
+	if ratio >= 1 {
+		return remaining
+	}
+	n := int(math.Round(float64(total) * ratio))
+	if n > remaining {
+		n = remaining
+	}
+	return n
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/marketing/internal/client"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// 单次最多预览的商品数
+	maxPricePreviewProducts = 50
+	// 促销价预览缓存时间，活动变更会生成新的缓存键，商品调价最多延迟该时间生效
+	pricePreviewTTL = time.Minute
+)
+
+// ProductCatalog 查询商品服务中的商品价格和分类
+type ProductCatalog interface {
+	GetProduct(ctx context.Context, productID uint) (*client.ProductInfo, error)
+}
+
+// PriceBadge 表示商品列表页展示的活动标签，如 "第二件半价"
+type PriceBadge struct {
+	PromotionID uint                `json:"promotion_id"`
+	Type        model.PromotionType `json:"type"`
+	Text        string              `json:"text"`
+}
+
+// PricePreview 表示商品当前的促销价预览
+type PricePreview struct {
+	ProductID   uint         `json:"product_id"`
+	Price       float64      `json:"price"`       // 商品当前售价
+	PromoPrice  float64      `json:"promo_price"` // 单件购买时的促销价，没有单件优惠时等于 Price
+	Discount    float64      `json:"discount"`
+	PromotionID *uint        `json:"promotion_id,omitempty"` // 提供单件优惠最多的活动
+	EndAt       *time.Time   `json:"end_at,omitempty"`       // 该活动的结束时间，用于展示倒计时
+	Badge       string       `json:"badge,omitempty"`        // 优先级最高的活动标签
+	Badges      []PriceBadge `json:"badges"`
+}
+
+// PriceService 计算商品列表页展示的促销价和活动标签
+type PriceService struct {
+	promotionRepo repository.PromotionRepository
+	products      ProductCatalog
+	experiments   *ExperimentService
+	rdb           *redis.Client
+	log           *logger.Logger
+}
+
+// NewPriceService 创建促销价预览服务，experiments 为空时不按实验分组投放活动
+func NewPriceService(promotionRepo repository.PromotionRepository, products ProductCatalog, experiments *ExperimentService, rdb *redis.Client, log *logger.Logger) *PriceService {
+	return &PriceService{
+		promotionRepo: promotionRepo,
+		products:      products,
+		experiments:   experiments,
+		rdb:           rdb,
+		log:           log,
+	}
+}
+
+// Preview 按用户可参与的活动计算商品的最优促销价和活动标签，userID 为 0 表示未登录用户。
+// 可参与的活动相同的用户共享缓存；商品不存在或商品服务不可用时该商品不出现在结果中
+func (s *PriceService) Preview(ctx context.Context, userID uint, productIDs []uint) ([]*PricePreview, error) {
+	if len(productIDs) == 0 {
+		return nil, apperrors.NewBadRequest("缺少 product_ids 参数", nil)
+	}
+	if len(productIDs) > maxPricePreviewProducts {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("一次最多查询 %d 个商品", maxPricePreviewProducts), nil)
+	}
+	productIDs = uniqueIDs(productIDs)
+
+	now := time.Now()
+	promotions, err := s.eligiblePromotions(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	segment := promotionsDigest(promotions)
+
+	keys := make([]string, len(productIDs))
+	for i, id := range productIDs {
+		keys[i] = pricePreviewKey(segment, id)
+	}
+	cached, err := s.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		s.log.Warn(ctx, "Failed to read price preview cache", zap.Error(err))
+		cached = make([]interface{}, len(keys))
+	}
+
+	previews := make([]*PricePreview, 0, len(productIDs))
+	pipe := s.rdb.Pipeline()
+	for i, id := range productIDs {
+		if raw, ok := cached[i].(string); ok {
+			var preview PricePreview
+			if err := json.Unmarshal([]byte(raw), &preview); err == nil {
+				previews = append(previews, &preview)
+				continue
+			}
+		}
+
+		product, err := s.products.GetProduct(ctx, id)
+		if err != nil {
+			if !errors.Is(err, client.ErrProductNotFound) {
+				s.log.Warn(ctx, "Failed to get product for price preview",
+					zap.Uint("product_id", id),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+		preview := previewPrice(product, promotions, now)
+		previews = append(previews, preview)
+		if data, err := json.Marshal(preview); err == nil {
+			pipe.Set(ctx, keys[i], data, pricePreviewTTL)
+		}
+	}
+	if pipe.Len() > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			s.log.Warn(ctx, "Failed to write price preview cache", zap.Error(err))
+		}
+	}
+	return previews, nil
+}
+
+// eligiblePromotions 获取用户当前可参与的活动，排除实验中未投放给用户的活动和已达到参与次数上限的活动
+func (s *PriceService) eligiblePromotions(ctx context.Context, userID uint, now time.Time) ([]*model.Promotion, error) {
+	promotions, err := s.promotionRepo.ListActive(ctx, now)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取促销活动失败", err)
+	}
+
+	var hidden map[uint]bool
+	if s.experiments != nil {
+		offers, err := s.experiments.offers(ctx, userID)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取实验分组失败", err)
+		}
+		hidden = offers.hiddenPromotions
+	}
+
+	var usage map[uint]int
+	if userID != 0 {
+		ids := make([]uint, 0, len(promotions))
+		for _, p := range promotions {
+			if p.MaxUsesPerUser != nil {
+				ids = append(ids, p.ID)
+			}
+		}
+		if usage, err = s.promotionRepo.CountUserUsage(ctx, userID, ids); err != nil {
+			return nil, apperrors.NewInternalServerError("获取活动参与记录失败", err)
+		}
+	}
+
+	eligible := promotions[:0]
+	for _, p := range promotions {
+		if !hidden[p.ID] && usageLimitReason(p, usage) == "" {
+			eligible = append(eligible, p)
+		}
+	}
+	return eligible, nil
+}
+
+// previewPrice 计算单件购买时的促销价，并为覆盖该商品的活动生成标签
+func previewPrice(product *client.ProductInfo, promotions []*model.Promotion, now time.Time) *PricePreview {
+	item := CartItem{
+		ProductID: product.ID,
+		Quantity:  1,
+		Price:     currentPrice(product, now),
+	}
+	for _, c := range product.Categories {
+		item.CategoryIDs = append(item.CategoryIDs, c.ID)
+	}
+
+	result := evaluatePromotions(promotions, []CartItem{item}, nil)
+	line := result.Lines[0]
+	preview := &PricePreview{
+		ProductID:  product.ID,
+		Price:      line.Subtotal,
+		PromoPrice: line.Total,
+		Discount:   line.Discount,
+		Badges:     []PriceBadge{},
+	}
+
+	var best *AppliedPromotion
+	for i := range result.Applied {
+		if a := &result.Applied[i]; a.Discount > 0 && (best == nil || a.Discount > best.Discount) {
+			best = a
+		}
+	}
+	for _, promo := range promotions {
+		if best != nil && promo.ID == best.PromotionID {
+			preview.PromotionID = &promo.ID
+			preview.EndAt = &promo.EndAt
+		}
+		if !promotionCovers(promo, &item) {
+			continue
+		}
+		if text := badgeText(promo); text != "" {
+			preview.Badges = append(preview.Badges, PriceBadge{PromotionID: promo.ID, Type: promo.Type, Text: text})
+		}
+	}
+	if len(preview.Badges) > 0 {
+		preview.Badge = preview.Badges[0].Text
+	}
+	return preview
+}
+
+// currentPrice 返回商品当前售价，促销价在有效期内时使用促销价
+func currentPrice(product *client.ProductInfo, now time.Time) float64 {
+	if product.SalePrice == nil {
+		return product.RegularPrice
+	}
+	if product.SaleStartDate != nil && now.Before(*product.SaleStartDate) {
+		return product.RegularPrice
+	}
+	if product.SaleEndDate != nil && !now.Before(*product.SaleEndDate) {
+		return product.RegularPrice
+	}
+	return *product.SalePrice
+}
+
+// badgeText 生成活动在商品列表页展示的标签
+func badgeText(promo *model.Promotion) string {
+	switch promo.Type {
+	case model.PromotionTypeFlashSale:
+		return "限时" + discountText(promo.DiscountType, promo.DiscountValue.Float64())
+
+	case model.PromotionTypeSecondHalfPrice:
+		pct := promo.DiscountValue.Float64()
+		switch {
+		case pct <= 0 || pct == 50:
+			return "第二件半价"
+		case pct >= 100:
+			return "第二件免费"
+		}
+		return "第二件" + foldText(100-pct)
+
+	case model.PromotionTypeBuyXGetY:
+		x, y := intOr(promo.MinQuantity, 1), intOr(promo.FreeProductQty, 1)
+		if promo.FreeProductID != nil {
+			return fmt.Sprintf("买%d件送赠品", x)
+		}
+		return fmt.Sprintf("买%d送%d", x, y)
+
+	case model.PromotionTypeQuantityDiscount:
+		minQty, value, found := 0, 0.0, false
+		for _, rule := range promo.Rules {
+			q, v, err := parseTier(rule)
+			if err == nil && (!found || q < minQty) {
+				minQty, value, found = q, v, true
+			}
+		}
+		if !found {
+			return ""
+		}
+		return fmt.Sprintf("满%d件", minQty) + tierText(promo.DiscountType, value)
+
+	case model.PromotionTypeSpendGetFree:
+		text := "购买即"
+		if promo.MinOrderAmount != nil && promo.MinOrderAmount.IsPositive() {
+			text = "满" + formatAmount(promo.MinOrderAmount.Float64()) + "元"
+		}
+		if promo.GiftPrice != nil {
+			return text + "加" + formatAmount(promo.GiftPrice.Float64()) + "元换购"
+		}
+		return text + "送赠品"
+
+	case model.PromotionTypeBundleSale:
+		return "组合优惠"
+	}
+	return ""
+}
+
+// discountText 生成单件折扣的描述，如 "8折"、"直降10元"、"特价99元"
+func discountText(discountType string, value float64) string {
+	switch discountType {
+	case model.DiscountTypePercentage:
+		return foldText(100 - value)
+	case model.DiscountTypePrice:
+		return "特价" + formatAmount(value) + "元"
+	}
+	return "直降" + formatAmount(value) + "元"
+}
+
+// tierText 生成阶梯优惠的描述，如 "8折"、"每件减10元"、"每件99元"
+func tierText(discountType string, value float64) string {
+	switch discountType {
+	case model.DiscountTypePercentage:
+		return foldText(100 - value)
+	case model.DiscountTypePrice:
+		return "每件" + formatAmount(value) + "元"
+	}
+	return "每件减" + formatAmount(value) + "元"
+}
+
+// foldText 将支付比例转换为中文折扣，如 80 表示 "8折"
+func foldText(paidPct float64) string {
+	return strconv.FormatFloat(roundAmount(paidPct/10), 'f', -1, 64) + "折"
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(roundAmount(v), 'f', -1, 64)
+}
+
+// promotionsDigest 根据活动及其更新时间生成缓存分组，可参与活动相同的用户共享缓存，活动变更后自动使用新的缓存
+func promotionsDigest(promotions []*model.Promotion) string {
+	h := fnv.New64a()
+	for _, p := range promotions {
+		fmt.Fprintf(h, "%d:%d;", p.ID, p.UpdatedAt.UnixNano())
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func pricePreviewKey(segment string, productID uint) string {
+	return fmt.Sprintf("marketing:price:%s:%d", segment, productID)
+}
+
+// uniqueIDs 去除重复的 ID 并保持原有顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
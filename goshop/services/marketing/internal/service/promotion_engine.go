@@ -0,0 +1,461 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+)
+
+// CartItem 表示参与促销计算的购物车商品行
+type CartItem struct {
+	ProductID   uint    `json:"product_id" binding:"required"`
+	SKUID       uint    `json:"sku_id"`
+	CategoryIDs []uint  `json:"category_ids"`
+	Quantity    int     `json:"quantity" binding:"required,min=1"`
+	Price       float64 `json:"price" binding:"min=0"` // 单价
+	// GiftPromotionID 不为空表示该行是之前由活动加入购物车的赠品，赠品行不参与活动计算
+	GiftPromotionID *uint `json:"gift_promotion_id,omitempty"`
+}
+
+// ItemDiscount 表示某个活动在某个商品行上的优惠
+type ItemDiscount struct {
+	PromotionID   uint                `json:"promotion_id"`
+	PromotionName string              `json:"promotion_name"`
+	PromotionType model.PromotionType `json:"promotion_type"`
+	LineIndex     int                 `json:"line_index"` // 对应请求中 Items 的下标
+	ProductID     uint                `json:"product_id"`
+	SKUID         uint                `json:"sku_id"`
+	Amount        float64             `json:"amount"`
+}
+
+// GiftLine 表示由活动加入购物车的赠品行，Price 为 0 表示免费赠送
+type GiftLine struct {
+	PromotionID uint    `json:"promotion_id"`
+	ProductID   uint    `json:"product_id"`
+	SKUID       uint    `json:"sku_id"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"`
+	Total       float64 `json:"total"`
+	Message     string  `json:"message,omitempty"` // 如赠品库存不足时的提示
+}
+
+// RemovedGift 表示需要从购物车移除的赠品行
+type RemovedGift struct {
+	PromotionID uint   `json:"promotion_id"`
+	ProductID   uint   `json:"product_id"`
+	SKUID       uint   `json:"sku_id"`
+	Reason      string `json:"reason"`
+}
+
+// LineResult 表示单个商品行的计算结果
+type LineResult struct {
+	CartItem
+	Subtotal     float64 `json:"subtotal"` // 优惠前金额
+	Discount     float64 `json:"discount"`
+	Total        float64 `json:"total"` // 优惠后金额
+	PromotionIDs []uint  `json:"promotion_ids"`
+}
+
+// AppliedPromotion 表示命中的活动及其优惠汇总
+type AppliedPromotion struct {
+	PromotionID uint                `json:"promotion_id"`
+	Name        string              `json:"name"`
+	Type        model.PromotionType `json:"type"`
+	Discount    float64             `json:"discount"`
+}
+
+// SkippedPromotion 表示未命中的活动及原因
+type SkippedPromotion struct {
+	PromotionID uint   `json:"promotion_id"`
+	Name        string `json:"name"`
+	Reason      string `json:"reason"`
+}
+
+// PromotionResult 表示购物车的促销计算结果。Lines 不包含请求中的赠品行，
+// 当前应在购物车中的赠品见 Gifts，不再满足条件的赠品见 RemovedGifts
+type PromotionResult struct {
+	Subtotal     float64            `json:"subtotal"`
+	Discount     float64            `json:"discount"`
+	GiftTotal    float64            `json:"gift_total"` // 加价购赠品金额
+	Total        float64            `json:"total"`
+	Lines        []*LineResult      `json:"lines"`
+	Discounts    []ItemDiscount     `json:"discounts"`
+	Gifts        []GiftLine         `json:"gifts"`
+	RemovedGifts []RemovedGift      `json:"removed_gifts"`
+	Applied      []AppliedPromotion `json:"applied"`
+	Skipped      []SkippedPromotion `json:"skipped,omitempty"`
+}
+
+func (r *PromotionResult) skip(promo *model.Promotion, reason string) {
+	r.Skipped = append(r.Skipped, SkippedPromotion{PromotionID: promo.ID, Name: promo.Name, Reason: reason})
+}
+
+// lineState 记录商品行在计算过程中的状态
+type lineState struct {
+	result   *LineResult
+	locked   bool // 已被不可叠加的活动占用
+	promoted bool // 已参与过活动
+}
+
+func (l *lineState) remaining() float64 {
+	return l.result.Subtotal - l.result.Discount
+}
+
+// evaluatePromotions 按优先级依次计算活动：优先级高的先计算，同一商品行被不可叠加的活动占用后，
+// 其他活动不再作用于该商品行；不可叠加的活动也不会作用于已参与其他活动的商品行。
+// usage 为当前用户在各活动上的已使用次数。
+func evaluatePromotions(promotions []*model.Promotion, items []CartItem, usage map[uint]int) *PromotionResult {
+	result := &PromotionResult{
+		Lines:        make([]*LineResult, 0, len(items)),
+		Discounts:    []ItemDiscount{},
+		Gifts:        []GiftLine{},
+		RemovedGifts: []RemovedGift{},
+		Applied:      []AppliedPromotion{},
+	}
+	lines := make([]*lineState, 0, len(items))
+	for _, item := range items {
+		lr := &LineResult{
+			CartItem:     item,
+			Subtotal:     roundAmount(item.Price * float64(item.Quantity)),
+			PromotionIDs: []uint{},
+		}
+		if item.GiftPromotionID != nil {
+			// 赠品行保留下标以便 LineIndex 与请求对应，但不计入金额也不参与活动
+			lines = append(lines, &lineState{result: lr, locked: true})
+			continue
+		}
+		result.Lines = append(result.Lines, lr)
+		result.Subtotal += lr.Subtotal
+		lines = append(lines, &lineState{result: lr})
+	}
+
+	sorted := make([]*model.Promotion, len(promotions))
+	copy(sorted, promotions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	for _, promo := range sorted {
+		if reason := usageLimitReason(promo, usage); reason != "" {
+			result.skip(promo, reason)
+			continue
+		}
+
+		eligible := make([]int, 0, len(lines))
+		var eligibleAmount float64
+		var eligibleQty int
+		for i, line := range lines {
+			if !promotionCovers(promo, &line.result.CartItem) || line.locked || (!promo.Stackable && line.promoted) {
+				continue
+			}
+			eligible = append(eligible, i)
+			eligibleAmount += line.remaining()
+			eligibleQty += line.result.Quantity
+		}
+		if len(eligible) == 0 {
+			result.skip(promo, "购物车中没有可参与活动的商品")
+			continue
+		}
+		if promo.MinOrderAmount != nil && money(eligibleAmount).LessThan(*promo.MinOrderAmount) {
+			result.skip(promo, "未达到活动最低金额")
+			continue
+		}
+		if promo.Type != model.PromotionTypeBuyXGetY && promo.MinQuantity != nil && eligibleQty < *promo.MinQuantity {
+			result.skip(promo, "未达到活动最低件数")
+			continue
+		}
+
+		discounts, gifts := calculatePromotion(promo, lines, eligible, eligibleQty)
+		applied := AppliedPromotion{PromotionID: promo.ID, Name: promo.Name, Type: promo.Type}
+		for _, i := range eligible {
+			amount := roundAmount(math.Min(discounts[i], lines[i].remaining()))
+			if amount <= 0 {
+				continue
+			}
+			lr := lines[i].result
+			lr.Discount = roundAmount(lr.Discount + amount)
+			applied.Discount += amount
+			result.Discounts = append(result.Discounts, ItemDiscount{
+				PromotionID:   promo.ID,
+				PromotionName: promo.Name,
+				PromotionType: promo.Type,
+				LineIndex:     i,
+				ProductID:     lr.ProductID,
+				SKUID:         lr.SKUID,
+				Amount:        amount,
+			})
+		}
+		if applied.Discount == 0 && len(gifts) == 0 {
+			result.skip(promo, "未满足活动条件")
+			continue
+		}
+
+		for _, i := range eligible {
+			lines[i].promoted = true
+			lines[i].locked = lines[i].locked || !promo.Stackable
+			lines[i].result.PromotionIDs = append(lines[i].result.PromotionIDs, promo.ID)
+		}
+		applied.Discount = roundAmount(applied.Discount)
+		result.Discount += applied.Discount
+		result.Gifts = append(result.Gifts, gifts...)
+		result.Applied = append(result.Applied, applied)
+	}
+
+	for _, line := range lines {
+		line.result.Total = roundAmount(line.remaining())
+	}
+	result.Subtotal = roundAmount(result.Subtotal)
+	result.Discount = roundAmount(result.Discount)
+	result.reconcileGifts(items)
+	return result
+}
+
+// reconcileGifts 找出请求中已不再满足活动条件的赠品行，并重新计算赠品金额和应付总额。
+// 赠品库存变化后需要再次调用
+func (r *PromotionResult) reconcileGifts(items []CartItem) {
+	r.GiftTotal = 0
+	for _, gift := range r.Gifts {
+		r.GiftTotal += gift.Total
+	}
+	r.GiftTotal = roundAmount(r.GiftTotal)
+	r.Total = roundAmount(r.Subtotal - r.Discount + r.GiftTotal)
+
+	for _, item := range items {
+		if item.GiftPromotionID == nil || r.hasGift(*item.GiftPromotionID, item.ProductID) || r.giftRemoved(*item.GiftPromotionID, item.ProductID) {
+			continue
+		}
+		r.RemovedGifts = append(r.RemovedGifts, RemovedGift{
+			PromotionID: *item.GiftPromotionID,
+			ProductID:   item.ProductID,
+			SKUID:       item.SKUID,
+			Reason:      "不再满足活动条件",
+		})
+	}
+}
+
+func (r *PromotionResult) hasGift(promotionID, productID uint) bool {
+	for _, gift := range r.Gifts {
+		if gift.PromotionID == promotionID && gift.ProductID == productID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *PromotionResult) giftRemoved(promotionID, productID uint) bool {
+	for _, gift := range r.RemovedGifts {
+		if gift.PromotionID == promotionID && gift.ProductID == productID {
+			return true
+		}
+	}
+	return false
+}
+
+// calculatePromotion 计算活动在各商品行上的优惠金额和赠品
+func calculatePromotion(promo *model.Promotion, lines []*lineState, eligible []int, eligibleQty int) (map[int]float64, []GiftLine) {
+	discounts := make(map[int]float64, len(eligible))
+	var gifts []GiftLine
+
+	switch promo.Type {
+	case model.PromotionTypeFlashSale:
+		for _, i := range eligible {
+			item := lines[i].result
+			discounts[i] = unitDiscount(promo.DiscountType, promo.DiscountValue.Float64(), item.Price) * float64(item.Quantity)
+		}
+
+	case model.PromotionTypeSecondHalfPrice:
+		// 同一商品每两件中第二件按 DiscountValue 百分比减免，未配置时为半价
+		pct := promo.DiscountValue.Float64()
+		if pct <= 0 {
+			pct = 50
+		}
+		for _, i := range eligible {
+			item := lines[i].result
+			discounts[i] = float64(item.Quantity/2) * item.Price * pct / 100
+		}
+
+	case model.PromotionTypeBuyXGetY:
+		x, y := intOr(promo.MinQuantity, 1), intOr(promo.FreeProductQty, 1)
+		if promo.FreeProductID != nil {
+			// 每买 X 件赠送 Y 件指定赠品
+			if sets := eligibleQty / x; sets > 0 {
+				gifts = append(gifts, giftLine(promo, sets*y))
+			}
+			break
+		}
+		// 活动范围内每 X+Y 件中价格最低的 Y 件免费
+		free := eligibleQty / (x + y) * y
+		for _, unit := range cheapestUnits(lines, eligible, free) {
+			discounts[unit] += lines[unit].result.Price
+		}
+
+	case model.PromotionTypeQuantityDiscount:
+		value, ok := quantityTier(promo.Rules, eligibleQty)
+		if !ok {
+			break
+		}
+		for _, i := range eligible {
+			item := lines[i].result
+			discounts[i] = unitDiscount(promo.DiscountType, value, item.Price) * float64(item.Quantity)
+		}
+
+	case model.PromotionTypeSpendGetFree:
+		if promo.FreeProductID != nil {
+			gifts = append(gifts, giftLine(promo, intOr(promo.FreeProductQty, 1)))
+		}
+	}
+
+	return discounts, gifts
+}
+
+// giftLine 创建活动的赠品行，配置了 GiftPrice 时按加价购价格计价
+func giftLine(promo *model.Promotion, quantity int) GiftLine {
+	gift := GiftLine{PromotionID: promo.ID, ProductID: *promo.FreeProductID, Quantity: quantity}
+	if promo.FreeSKUID != nil {
+		gift.SKUID = *promo.FreeSKUID
+	}
+	if promo.GiftPrice != nil {
+		gift.Price = promo.GiftPrice.Float64()
+		gift.Total = promo.GiftPrice.Mul(int64(quantity)).Float64()
+	}
+	return gift
+}
+
+// promotionCovers 判断商品是否在活动范围内，未配置商品和分类时适用于全部商品
+func promotionCovers(promo *model.Promotion, item *CartItem) bool {
+	if len(promo.ProductIDs) == 0 && len(promo.CategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range promo.ProductIDs {
+		if id == item.ProductID {
+			return true
+		}
+	}
+	for _, id := range promo.CategoryIDs {
+		for _, cid := range item.CategoryIDs {
+			if id == cid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// usageLimitReason 检查活动总次数和每人次数限制
+func usageLimitReason(promo *model.Promotion, usage map[uint]int) string {
+	if promo.MaxUses != nil && promo.TotalUses >= *promo.MaxUses {
+		return "活动名额已用完"
+	}
+	if promo.MaxUsesPerUser != nil && usage[promo.ID] >= *promo.MaxUsesPerUser {
+		return "已达到每人参与次数上限"
+	}
+	return ""
+}
+
+// unitDiscount 计算单件商品的减免金额
+func unitDiscount(discountType string, value, price float64) float64 {
+	var off float64
+	switch discountType {
+	case model.DiscountTypePercentage:
+		off = price * value / 100
+	case model.DiscountTypePrice:
+		off = price - value
+	default:
+		off = value
+	}
+	return math.Max(0, math.Min(off, price))
+}
+
+// quantityTier 解析阶梯规则并返回满足件数的最高档优惠值，规则格式为 "最低件数:优惠值"，如 "3:10"
+func quantityTier(rules model.StringSlice, qty int) (float64, bool) {
+	best, bestMin, found := 0.0, 0, false
+	for _, rule := range rules {
+		minQty, value, err := parseTier(rule)
+		if err != nil {
+			continue
+		}
+		if qty >= minQty && (!found || minQty > bestMin) {
+			best, bestMin, found = value, minQty, true
+		}
+	}
+	return best, found
+}
+
+// parseTier 解析一条 "最低件数:优惠值" 格式的阶梯规则
+func parseTier(rule string) (int, float64, error) {
+	parts := strings.SplitN(rule, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("阶梯规则 %q 格式应为 最低件数:优惠值", rule)
+	}
+	minQty, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("阶梯规则 %q 的最低件数无效", rule)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("阶梯规则 %q 的优惠值无效", rule)
+	}
+	return minQty, value, nil
+}
+
+// cheapestUnits 返回价格最低的 n 件商品所在的商品行下标，每件一个元素
+func cheapestUnits(lines []*lineState, eligible []int, n int) []int {
+	if n <= 0 {
+		return nil
+	}
+	var units []int
+	for _, i := range eligible {
+		for q := 0; q < lines[i].result.Quantity; q++ {
+			units = append(units, i)
+		}
+	}
+	sort.SliceStable(units, func(a, b int) bool {
+		return lines[units[a]].result.Price < lines[units[b]].result.Price
+	})
+	if n > len(units) {
+		n = len(units)
+	}
+	return units[:n]
+}
+
+func intOr(v *int, def int) int {
+	if v == nil || *v <= 0 {
+		return def
+	}
+	return *v
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// money 将请求和事件中以元为单位的金额转换为默认货币的 Money
+func money(v float64) currency.Money {
+	return currency.FromMajor(v, currency.Default)
+}
+
+// optionalMoney 转换可选的金额，nil 表示未设置
+func optionalMoney(v *float64) *currency.Money {
+	if v == nil {
+		return nil
+	}
+	m := money(*v)
+	return &m
+}
+
+// optionalFloat 将可选的金额转换为以元为单位的 float64，用于其他服务仍按 float64 读取的响应
+func optionalFloat(m *currency.Money) *float64 {
+	if m == nil {
+		return nil
+	}
+	v := m.Float64()
+	return &v
+}
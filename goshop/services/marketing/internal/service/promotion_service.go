@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/featureflags"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/marketing/internal/client"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// FlagGiftStockCheck 控制计算促销时是否检查赠品库存。默认开启，库存服务负载过高时可以关闭，
+// 赠品库存由下单时锁定库存兜底
+const FlagGiftStockCheck = "gift-stock-check"
+
+// StockChecker 查询库存服务中的 SKU 可用库存
+type StockChecker interface {
+	GetStock(ctx context.Context, skuID uint) (*client.SKUStock, error)
+}
+
+// EvaluateRequest 表示购物车促销计算请求，由订单服务在结算和下单时调用
+type EvaluateRequest struct {
+	UserID uint       `json:"user_id"`
+	Items  []CartItem `json:"items" binding:"required,min=1,dive"`
+}
+
+// PromotionService 负责促销活动查询和购物车促销计算
+type PromotionService struct {
+	promotionRepo repository.PromotionRepository
+	stock         StockChecker
+	experiments   *ExperimentService
+	flags         *featureflags.Client
+	log           *logger.Logger
+}
+
+// NewPromotionService 创建促销活动服务，stock 为空时不检查赠品库存，experiments 为空时不按实验分组投放活动
+func NewPromotionService(promotionRepo repository.PromotionRepository, stock StockChecker, experiments *ExperimentService, flags *featureflags.Client, log *logger.Logger) *PromotionService {
+	return &PromotionService{
+		promotionRepo: promotionRepo,
+		stock:         stock,
+		experiments:   experiments,
+		flags:         flags,
+		log:           log,
+	}
+}
+
+// ListActive 获取当前生效的促销活动
+func (s *PromotionService) ListActive(ctx context.Context) ([]*model.Promotion, error) {
+	promotions, err := s.promotionRepo.ListActive(ctx, time.Now())
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取促销活动失败", err)
+	}
+	return promotions, nil
+}
+
+// Evaluate 计算所有生效的促销活动在购物车上的优惠，返回逐行优惠明细和赠品
+func (s *PromotionService) Evaluate(ctx context.Context, req *EvaluateRequest) (*PromotionResult, error) {
+	promotions, err := s.promotionRepo.ListActive(ctx, time.Now())
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取促销活动失败", err)
+	}
+	if s.experiments != nil {
+		offers, err := s.experiments.offers(ctx, req.UserID)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取实验分组失败", err)
+		}
+		visible := promotions[:0]
+		for _, p := range promotions {
+			if !offers.hiddenPromotions[p.ID] {
+				visible = append(visible, p)
+			}
+		}
+		promotions = visible
+	}
+
+	ids := make([]uint, 0, len(promotions))
+	for _, p := range promotions {
+		if p.MaxUsesPerUser != nil {
+			ids = append(ids, p.ID)
+		}
+	}
+	usage, err := s.promotionRepo.CountUserUsage(ctx, req.UserID, ids)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取活动参与记录失败", err)
+	}
+
+	result := evaluatePromotions(promotions, req.Items, usage)
+	if s.flags.IsEnabled(FlagGiftStockCheck, flagTarget(req.UserID)) {
+		s.checkGiftStock(ctx, result, req.Items)
+	}
+	return result, nil
+}
+
+// checkGiftStock 检查赠品库存，库存不足时减少赠品数量，无库存时移除赠品。
+// 库存服务不可用时保留赠品，下单时由订单服务锁定库存兜底
+func (s *PromotionService) checkGiftStock(ctx context.Context, result *PromotionResult, items []CartItem) {
+	if s.stock == nil || len(result.Gifts) == 0 {
+		return
+	}
+
+	gifts := result.Gifts[:0]
+	for _, gift := range result.Gifts {
+		if gift.SKUID == 0 {
+			gifts = append(gifts, gift)
+			continue
+		}
+		stock, err := s.stock.GetStock(ctx, gift.SKUID)
+		if err != nil {
+			s.log.Warn(ctx, "Failed to check gift stock",
+				zap.Uint("promotion_id", gift.PromotionID),
+				zap.Uint("sku_id", gift.SKUID),
+				zap.Error(err),
+			)
+			gifts = append(gifts, gift)
+			continue
+		}
+		if stock.IsInfinite || stock.AvailableStock >= gift.Quantity {
+			gifts = append(gifts, gift)
+			continue
+		}
+		if stock.AvailableStock <= 0 {
+			result.RemovedGifts = append(result.RemovedGifts, RemovedGift{
+				PromotionID: gift.PromotionID,
+				ProductID:   gift.ProductID,
+				SKUID:       gift.SKUID,
+				Reason:      "赠品已赠完",
+			})
+			continue
+		}
+		gift.Quantity = stock.AvailableStock
+		gift.Total = roundAmount(gift.Price * float64(gift.Quantity))
+		gift.Message = fmt.Sprintf("赠品库存不足，仅剩 %d 件", stock.AvailableStock)
+		gifts = append(gifts, gift)
+	}
+	result.Gifts = gifts
+	result.reconcileGifts(items)
+}
+
+// flagTarget 返回按用户计算功能开关的对象，userID 为 0 表示匿名用户
+func flagTarget(userID uint) featureflags.Target {
+	if userID == 0 {
+		return featureflags.Target{}
+	}
+	return featureflags.Target{UserID: strconv.FormatUint(uint64(userID), 10)}
+}
+
+// getPromotion 获取促销活动，不存在时返回 NOT_FOUND
+func getPromotion(ctx context.Context, repo repository.PromotionRepository, id uint) (*model.Promotion, error) {
+	promotion, err := repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("促销活动 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取促销活动失败", err)
+	}
+	return promotion, nil
+}
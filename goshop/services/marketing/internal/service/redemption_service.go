@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/marketing/internal/event"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"go.uber.org/zap"
+)
+
+// RedeemCouponRequest 表示下单时核销优惠券的请求，由订单服务在创建订单时调用
+type RedeemCouponRequest struct {
+	Code           string  `json:"code" binding:"required"`
+	UserID         uint    `json:"user_id" binding:"required"`
+	OrderID        uint    `json:"order_id" binding:"required"`
+	OrderNumber    string  `json:"order_number" binding:"required"`
+	DiscountAmount float64 `json:"discount_amount" binding:"min=0"`
+	UserCouponID   *uint   `json:"user_coupon_id"` // 使用券包中的优惠券时传入
+}
+
+// PromotionRedemption 表示订单命中的一个促销活动
+type PromotionRedemption struct {
+	PromotionID    uint    `json:"promotion_id" binding:"required"`
+	DiscountAmount float64 `json:"discount_amount" binding:"min=0"`
+}
+
+// RedeemPromotionsRequest 表示下单时记录促销活动参与的请求
+type RedeemPromotionsRequest struct {
+	UserID      uint                  `json:"user_id" binding:"required"`
+	OrderID     uint                  `json:"order_id" binding:"required"`
+	OrderNumber string                `json:"order_number" binding:"required"`
+	Promotions  []PromotionRedemption `json:"promotions" binding:"required,min=1,dive"`
+}
+
+// ReleaseResult 表示释放订单优惠的结果
+type ReleaseResult struct {
+	Coupons    []*model.CouponUsage    `json:"coupons"`
+	Promotions []*model.PromotionUsage `json:"promotions"`
+}
+
+// RedemptionService 负责下单时优惠券和促销活动的核销计数，以及订单取消后的回退。
+// 计数通过带条件的 UPDATE 原子递增，高并发下也不会超出发行量和活动名额
+type RedemptionService struct {
+	couponRepo    repository.CouponRepository
+	codeRepo      repository.CouponCodeRepository
+	promotionRepo repository.PromotionRepository
+	log           *logger.Logger
+}
+
+// NewRedemptionService 创建核销服务
+func NewRedemptionService(
+	couponRepo repository.CouponRepository,
+	codeRepo repository.CouponCodeRepository,
+	promotionRepo repository.PromotionRepository,
+	log *logger.Logger,
+) *RedemptionService {
+	return &RedemptionService{
+		couponRepo:    couponRepo,
+		codeRepo:      codeRepo,
+		promotionRepo: promotionRepo,
+		log:           log,
+	}
+}
+
+// Subscribe 订阅订单取消事件，回退订单占用的优惠券和活动名额
+func (s *RedemptionService) Subscribe(sub *event.Subscriber) error {
+	return sub.Subscribe(event.OrderCancelled, func(ctx context.Context, data json.RawMessage) error {
+		var evt event.OrderEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return err
+		}
+		_, err := s.ReleaseOrder(ctx, evt.OrderID)
+		return err
+	})
+}
+
+// RedeemCoupon 核销优惠券，同一订单重复调用返回已有的核销记录
+func (s *RedemptionService) RedeemCoupon(ctx context.Context, req *RedeemCouponRequest) (*model.CouponUsage, error) {
+	coupon, couponCode, err := findCouponByCode(ctx, s.couponRepo, s.codeRepo, strings.TrimSpace(req.Code))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !coupon.IsActive || now.Before(coupon.StartAt) || now.After(coupon.EndAt) {
+		return nil, apperrors.NewBadRequest("优惠券不在有效期内", nil)
+	}
+
+	usage := &model.CouponUsage{
+		CouponID:       coupon.ID,
+		UserID:         req.UserID,
+		OrderID:        req.OrderID,
+		OrderNumber:    req.OrderNumber,
+		UserCouponID:   req.UserCouponID,
+		UsedAt:         now,
+		DiscountAmount: money(req.DiscountAmount),
+	}
+	if couponCode != nil {
+		usage.CouponCodeID = &couponCode.ID
+	}
+
+	err = s.couponRepo.Redeem(ctx, usage, coupon.UserLimit)
+	switch {
+	case err == nil:
+		return usage, nil
+	case errors.Is(err, repository.ErrCouponExhausted):
+		return nil, apperrors.New(apperrors.ErrCouponExhausted, "优惠券已被抢光", http.StatusConflict, err)
+	case errors.Is(err, repository.ErrCouponUserLimit):
+		return nil, apperrors.NewConflict("已达到每人使用次数上限", err)
+	case errors.Is(err, repository.ErrCouponCodeUsed):
+		return nil, apperrors.New(apperrors.ErrCouponCodeUsed, "优惠码已被使用", http.StatusConflict, err)
+	case errors.Is(err, repository.ErrUserCouponUnavailable):
+		return nil, apperrors.NewConflict("券包中的优惠券不可用", err)
+	default:
+		return nil, apperrors.NewInternalServerError("核销优惠券失败", err)
+	}
+}
+
+// RedeemPromotions 记录订单参与的促销活动，任一活动名额已满时整单失败
+func (s *RedemptionService) RedeemPromotions(ctx context.Context, req *RedeemPromotionsRequest) ([]*model.PromotionUsage, error) {
+	now := time.Now()
+	usages := make([]*model.PromotionUsage, 0, len(req.Promotions))
+	for _, p := range req.Promotions {
+		usages = append(usages, &model.PromotionUsage{
+			PromotionID:    p.PromotionID,
+			UserID:         req.UserID,
+			OrderID:        req.OrderID,
+			OrderNumber:    req.OrderNumber,
+			DiscountAmount: money(p.DiscountAmount),
+			UsedAt:         now,
+		})
+	}
+
+	err := s.promotionRepo.Redeem(ctx, usages)
+	switch {
+	case err == nil:
+		return usages, nil
+	case errors.Is(err, repository.ErrPromotionExhausted):
+		return nil, apperrors.New(apperrors.ErrPromotionExhausted, "活动名额已用完", http.StatusConflict, err)
+	case errors.Is(err, repository.ErrPromotionUserLimit):
+		return nil, apperrors.NewConflict("已达到每人参与次数上限", err)
+	default:
+		return nil, apperrors.NewInternalServerError("记录活动参与失败", err)
+	}
+}
+
+// ReleaseOrder 回退订单核销的优惠券和活动名额，用于下单失败或订单取消，重复调用不会重复回退
+func (s *RedemptionService) ReleaseOrder(ctx context.Context, orderID uint) (*ReleaseResult, error) {
+	coupons, err := s.couponRepo.ReleaseOrder(ctx, orderID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("回退优惠券失败", err)
+	}
+	promotions, err := s.promotionRepo.ReleaseOrder(ctx, orderID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("回退活动名额失败", err)
+	}
+
+	if len(coupons) > 0 || len(promotions) > 0 {
+		s.log.Info(ctx, "Released order redemptions",
+			zap.Uint("order_id", orderID),
+			zap.Int("coupons", len(coupons)),
+			zap.Int("promotions", len(promotions)),
+		)
+	}
+	return &ReleaseResult{Coupons: coupons, Promotions: promotions}, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/marketing/internal/event"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ScheduleOverview 表示即将开始和即将结束的促销活动和优惠券
+type ScheduleOverview struct {
+	From               time.Time          `json:"from"`
+	To                 time.Time          `json:"to"`
+	UpcomingPromotions []*model.Promotion `json:"upcoming_promotions"`
+	EndingPromotions   []*model.Promotion `json:"ending_promotions"`
+	UpcomingCoupons    []*model.Coupon    `json:"upcoming_coupons"`
+	EndingCoupons      []*model.Coupon    `json:"ending_coupons"`
+}
+
+// ScheduleService 在促销活动和优惠券的开始、结束时间切换排期状态并发布事件
+type ScheduleService struct {
+	promotionRepo repository.PromotionRepository
+	couponRepo    repository.CouponRepository
+	publisher     event.Publisher
+	log           *logger.Logger
+}
+
+// NewScheduleService 创建排期服务
+func NewScheduleService(
+	promotionRepo repository.PromotionRepository,
+	couponRepo repository.CouponRepository,
+	publisher event.Publisher,
+	log *logger.Logger,
+) *ScheduleService {
+	return &ScheduleService{
+		promotionRepo: promotionRepo,
+		couponRepo:    couponRepo,
+		publisher:     publisher,
+		log:           log,
+	}
+}
+
+// Overview 获取 within 时间内即将开始和即将结束的促销活动和优惠券
+func (s *ScheduleService) Overview(ctx context.Context, within time.Duration) (*ScheduleOverview, error) {
+	now := time.Now()
+	overview := &ScheduleOverview{From: now, To: now.Add(within)}
+
+	var err error
+	if overview.UpcomingPromotions, err = s.promotionRepo.ListStartingBetween(ctx, overview.From, overview.To); err != nil {
+		return nil, apperrors.NewInternalServerError("获取即将开始的促销活动失败", err)
+	}
+	if overview.EndingPromotions, err = s.promotionRepo.ListEndingBetween(ctx, overview.From, overview.To); err != nil {
+		return nil, apperrors.NewInternalServerError("获取即将结束的促销活动失败", err)
+	}
+	if overview.UpcomingCoupons, err = s.couponRepo.ListStartingBetween(ctx, overview.From, overview.To); err != nil {
+		return nil, apperrors.NewInternalServerError("获取即将开始的优惠券失败", err)
+	}
+	if overview.EndingCoupons, err = s.couponRepo.ListEndingBetween(ctx, overview.From, overview.To); err != nil {
+		return nil, apperrors.NewInternalServerError("获取即将结束的优惠券失败", err)
+	}
+	return overview, nil
+}
+
+// Run 定期切换到期的排期状态，直到 ctx 被取消
+func (s *ScheduleService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ScheduleService) tick(ctx context.Context, now time.Time) {
+	// 先处理结束再处理开始，保证同一轮内错过整个活动期的记录直接结束
+	if promotions, err := s.promotionRepo.ListDueToEnd(ctx, now); err != nil {
+		s.log.Error(ctx, "Failed to list promotions due to end", zap.Error(err))
+	} else {
+		for _, p := range promotions {
+			s.transitionPromotion(ctx, p, []string{model.ScheduleStatusScheduled, model.ScheduleStatusRunning}, model.ScheduleStatusEnded)
+		}
+	}
+	if promotions, err := s.promotionRepo.ListDueToStart(ctx, now); err != nil {
+		s.log.Error(ctx, "Failed to list promotions due to start", zap.Error(err))
+	} else {
+		for _, p := range promotions {
+			s.transitionPromotion(ctx, p, []string{model.ScheduleStatusScheduled}, model.ScheduleStatusRunning)
+		}
+	}
+
+	if coupons, err := s.couponRepo.ListDueToEnd(ctx, now); err != nil {
+		s.log.Error(ctx, "Failed to list coupons due to end", zap.Error(err))
+	} else {
+		for _, c := range coupons {
+			s.transitionCoupon(ctx, c, []string{model.ScheduleStatusScheduled, model.ScheduleStatusRunning}, model.ScheduleStatusEnded)
+		}
+	}
+	if coupons, err := s.couponRepo.ListDueToStart(ctx, now); err != nil {
+		s.log.Error(ctx, "Failed to list coupons due to start", zap.Error(err))
+	} else {
+		for _, c := range coupons {
+			s.transitionCoupon(ctx, c, []string{model.ScheduleStatusScheduled}, model.ScheduleStatusRunning)
+		}
+	}
+}
+
+// transitionPromotion 切换促销活动的排期状态，只有启用的活动才发布事件；
+// 未开始就结束的活动不发布下线事件
+func (s *ScheduleService) transitionPromotion(ctx context.Context, p *model.Promotion, from []string, to string) {
+	ok, err := s.promotionRepo.UpdateScheduleStatus(ctx, p.ID, from, to)
+	if err != nil {
+		s.log.Error(ctx, "Failed to update promotion schedule status", zap.Uint("promotion_id", p.ID), zap.Error(err))
+		return
+	}
+	if !ok || !p.IsActive || (to == model.ScheduleStatusEnded && p.ScheduleStatus != model.ScheduleStatusRunning) {
+		return
+	}
+
+	eventType := event.PromotionActivated
+	if to == model.ScheduleStatusEnded {
+		eventType = event.PromotionDeactivated
+	}
+	err = s.publisher.Publish(ctx, eventType, &event.PromotionScheduleEvent{
+		PromotionID: p.ID,
+		Type:        string(p.Type),
+		ProductIDs:  p.ProductIDs,
+		CategoryIDs: p.CategoryIDs,
+		StartAt:     p.StartAt,
+		EndAt:       p.EndAt,
+	})
+	if err != nil {
+		s.log.Error(ctx, "Failed to publish promotion schedule event",
+			zap.Uint("promotion_id", p.ID),
+			zap.String("event_type", eventType),
+			zap.Error(err),
+		)
+	}
+}
+
+// transitionCoupon 切换优惠券的排期状态，只有启用的优惠券才发布事件；
+// 未开始就结束的优惠券不发布下线事件
+func (s *ScheduleService) transitionCoupon(ctx context.Context, c *model.Coupon, from []string, to string) {
+	ok, err := s.couponRepo.UpdateScheduleStatus(ctx, c.ID, from, to)
+	if err != nil {
+		s.log.Error(ctx, "Failed to update coupon schedule status", zap.Uint("coupon_id", c.ID), zap.Error(err))
+		return
+	}
+	if !ok || !c.IsActive || (to == model.ScheduleStatusEnded && c.ScheduleStatus != model.ScheduleStatusRunning) {
+		return
+	}
+
+	eventType := event.CouponActivated
+	if to == model.ScheduleStatusEnded {
+		eventType = event.CouponDeactivated
+	}
+	err = s.publisher.Publish(ctx, eventType, &event.CouponScheduleEvent{
+		CouponID:    c.ID,
+		Code:        c.Code,
+		Type:        string(c.Type),
+		ProductIDs:  c.ApplicableProducts,
+		CategoryIDs: c.ApplicableCategories,
+		StartAt:     c.StartAt,
+		EndAt:       c.EndAt,
+	})
+	if err != nil {
+		s.log.Error(ctx, "Failed to publish coupon schedule event",
+			zap.Uint("coupon_id", c.ID),
+			zap.String("event_type", eventType),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/marketing/internal/model"
+	"github.com/yourusername/goshop/services/marketing/internal/repository"
+)
+
+// IssueCouponRequest 表示定向发放优惠券的请求，用于新人礼包、售后补偿、生日礼券等场景
+type IssueCouponRequest struct {
+	CouponID uint                   `json:"coupon_id" binding:"required"`
+	UserIDs  []uint                 `json:"user_ids" binding:"required,min=1,max=1000"`
+	Source   model.UserCouponSource `json:"source" binding:"required,oneof=welcome compensation birthday admin"`
+	Note     string                 `json:"note" binding:"max=255"`
+}
+
+// IssueResult 表示定向发放的结果
+type IssueResult struct {
+	Issued []*model.UserCoupon `json:"issued"`
+	Failed map[uint]string     `json:"failed,omitempty"` // 发放失败的用户及原因
+}
+
+// Wallet 表示按状态分组的用户券包
+type Wallet struct {
+	Usable  []*model.UserCoupon `json:"usable"`
+	Used    []*model.UserCoupon `json:"used"`
+	Expired []*model.UserCoupon `json:"expired"`
+}
+
+// WalletService 负责用户券包：领券、定向发放和券包查询
+type WalletService struct {
+	couponRepo     repository.CouponRepository
+	userCouponRepo repository.UserCouponRepository
+}
+
+// NewWalletService 创建券包服务
+func NewWalletService(couponRepo repository.CouponRepository, userCouponRepo repository.UserCouponRepository) *WalletService {
+	return &WalletService{
+		couponRepo:     couponRepo,
+		userCouponRepo: userCouponRepo,
+	}
+}
+
+// ListClaimable 获取领券中心当前可领取的优惠券
+func (s *WalletService) ListClaimable(ctx context.Context) ([]*model.Coupon, error) {
+	coupons, err := s.couponRepo.ListClaimable(ctx, time.Now())
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取优惠券失败", err)
+	}
+	return coupons, nil
+}
+
+// Claim 用户领取公开推广的优惠券，受发行量和每人限领数量限制
+func (s *WalletService) Claim(ctx context.Context, userID, couponID uint) (*model.UserCoupon, error) {
+	coupon, err := getCoupon(ctx, s.couponRepo, couponID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !coupon.IsClaimable || !coupon.IsActive || now.Before(coupon.StartAt) || !now.Before(coupon.EndAt) {
+		return nil, apperrors.NewBadRequest("该优惠券当前不可领取", nil)
+	}
+
+	return s.issue(ctx, coupon, userID, model.UserCouponSourceClaim, "", coupon.UserLimit, now)
+}
+
+// Issue 向指定用户定向发放优惠券，不要求优惠券开放领取
+func (s *WalletService) Issue(ctx context.Context, req *IssueCouponRequest) (*IssueResult, error) {
+	coupon, err := getCoupon(ctx, s.couponRepo, req.CouponID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !coupon.IsActive || !now.Before(coupon.EndAt) {
+		return nil, apperrors.NewBadRequest("优惠券已停用或已过期", nil)
+	}
+
+	result := &IssueResult{Issued: make([]*model.UserCoupon, 0, len(req.UserIDs))}
+	for _, userID := range req.UserIDs {
+		userCoupon, err := s.issue(ctx, coupon, userID, req.Source, req.Note, coupon.UserLimit, now)
+		if err != nil {
+			var appErr *apperrors.Error
+			if !errors.As(err, &appErr) || appErr.HTTPCode >= http.StatusInternalServerError {
+				return nil, err
+			}
+			if result.Failed == nil {
+				result.Failed = make(map[uint]string)
+			}
+			result.Failed[userID] = appErr.Message
+			continue
+		}
+		result.Issued = append(result.Issued, userCoupon)
+	}
+	return result, nil
+}
+
+// ListWallet 获取用户券包，按可用、已使用、已过期分组
+func (s *WalletService) ListWallet(ctx context.Context, userID uint) (*Wallet, error) {
+	userCoupons, err := s.userCouponRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取券包失败", err)
+	}
+
+	now := time.Now()
+	wallet := &Wallet{
+		Usable:  []*model.UserCoupon{},
+		Used:    []*model.UserCoupon{},
+		Expired: []*model.UserCoupon{},
+	}
+	for _, uc := range userCoupons {
+		switch {
+		case uc.Status == model.UserCouponStatusUsed:
+			wallet.Used = append(wallet.Used, uc)
+		case uc.IsUsable(now):
+			wallet.Usable = append(wallet.Usable, uc)
+		case now.Before(uc.StartAt):
+			// 未到生效时间的优惠券也展示在可用分组，由前端提示生效时间
+			wallet.Usable = append(wallet.Usable, uc)
+		default:
+			wallet.Expired = append(wallet.Expired, uc)
+		}
+	}
+	return wallet, nil
+}
+
+// IssueReward 发放营销奖励优惠券，如生日礼券。奖励按发放记录去重，因此不受每人限领数量限制
+func (s *WalletService) IssueReward(ctx context.Context, couponID, userID uint, source model.UserCouponSource, note string) (*model.UserCoupon, error) {
+	coupon, err := getCoupon(ctx, s.couponRepo, couponID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !coupon.IsActive || !now.Before(coupon.EndAt) {
+		return nil, apperrors.NewBadRequest("优惠券已停用或已过期", nil)
+	}
+	return s.issue(ctx, coupon, userID, source, note, 0, now)
+}
+
+// issue 向用户发放优惠券，userLimit 为每人限领数量，0 表示不限
+func (s *WalletService) issue(ctx context.Context, coupon *model.Coupon, userID uint, source model.UserCouponSource, note string, userLimit int, now time.Time) (*model.UserCoupon, error) {
+	userCoupon := &model.UserCoupon{
+		UserID:    userID,
+		CouponID:  coupon.ID,
+		Source:    source,
+		Status:    model.UserCouponStatusUnused,
+		Note:      note,
+		StartAt:   coupon.StartAt,
+		ExpiresAt: coupon.EndAt,
+	}
+	// 设置了领取后有效天数的优惠券，从发放时开始计算有效期
+	if coupon.ValidDays != nil && *coupon.ValidDays > 0 {
+		userCoupon.StartAt = now
+		userCoupon.ExpiresAt = now.AddDate(0, 0, *coupon.ValidDays)
+	}
+
+	if err := s.userCouponRepo.Issue(ctx, userCoupon, userLimit); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCouponSoldOut):
+			return nil, apperrors.NewConflict("优惠券已领完", err)
+		case errors.Is(err, repository.ErrCouponLimitReached):
+			return nil, apperrors.NewConflict("已达到该优惠券的领取上限", err)
+		}
+		return nil, apperrors.NewInternalServerError("发放优惠券失败", err)
+	}
+
+	userCoupon.Coupon = coupon
+	return userCoupon, nil
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+	"github.com/yourusername/goshop/pkg/audit"
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/database"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/pkg/health"
+	"github.com/yourusername/goshop/pkg/locks"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/pkg/metrics"
+	"github.com/yourusername/goshop/pkg/shutdown"
+	"github.com/yourusername/goshop/pkg/tracing"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/notification/internal/client"
+	"github.com/yourusername/goshop/services/notification/internal/handler"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+	"github.com/yourusername/goshop/services/notification/internal/provider"
+	"github.com/yourusername/goshop/services/notification/internal/repository"
+	"github.com/yourusername/goshop/services/notification/internal/service"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const serviceName = "notification"
+
+func main() {
+	// Load configuration and watch it for changes
+	watcher, err := config.Watch(serviceName, "")
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	cfg := watcher.Current()
+
+	// Initialize logger
+	log, err := logger.New(serviceName, cfg.Service.LogLevel, logger.WithConfig(cfg.Log, cfg.Service.Environment))
+	if err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	ctx := context.Background()
+	log.Info(ctx, "Starting notification service",
+		zap.String("environment", cfg.Service.Environment),
+		zap.Int("http_port", cfg.HTTP.Port),
+		zap.Int("grpc_port", cfg.GRPC.Port),
+	)
+
+	// Apply configuration changes at runtime, SIGHUP forces a reload and periodic
+	// refreshes pick up rotated secrets
+	watcher.OnError(func(err error) {
+		log.Error(ctx, "Failed to reload configuration", zap.Error(err))
+	})
+	watcher.OnChange(func(old, new *config.Config) {
+		if new.Service.LogLevel == old.Service.LogLevel {
+			return
+		}
+		if err := log.SetLevel(new.Service.LogLevel); err != nil {
+			log.Warn(ctx, "Invalid log level", zap.String("level", new.Service.LogLevel), zap.Error(err))
+			return
+		}
+		log.Info(ctx, "Log level changed", zap.String("level", new.Service.LogLevel))
+	})
+	watcher.ReloadOnSignal(syscall.SIGHUP)
+	watcher.RefreshSecrets(ctx, time.Duration(cfg.Secrets.CacheTTL)*time.Second)
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Trace, serviceName, cfg.Service.Environment)
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize tracing", zap.Error(err))
+	}
+
+	// Shutdown hooks are registered as resources are created and run in phases
+	// on SIGINT or SIGTERM
+	lc := shutdown.New(log)
+	lc.Add(shutdown.PhaseFlush, "tracing", 0, shutdownTracing)
+
+	// Initialize database
+	db, err := database.Open(cfg.Database, log, database.WithAutoMigrate(
+		&model.Notification{},
+		&model.Preference{},
+		&model.Contact{},
+		&model.Device{},
+	))
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize database", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseClose, "postgres", 0, shutdown.Close(func() error { return database.Close(db) }))
+
+	// Initialize Redis
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.RedisAddr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	lc.Add(shutdown.PhaseClose, "redis", 0, shutdown.Close(rdb.Close))
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		log.Fatal(ctx, "Failed to connect to Redis", zap.Error(err))
+	}
+
+	// Initialize NATS connection
+	nc, err := nats.Connect(cfg.NATS.URL, nats.Name(serviceName))
+	if err != nil {
+		log.Fatal(ctx, "Failed to connect to NATS", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseFlush, "nats", 0, shutdown.Close(nc.Drain))
+
+	// Publish admin actions to the audit service and consume domain events
+	// through durable JetStream consumers
+	js, err := nc.JetStream()
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize JetStream", zap.Error(err))
+	}
+	if err := audit.EnsureStream(js, time.Duration(cfg.Audit.StreamMaxAge)*time.Hour); err != nil {
+		log.Fatal(ctx, "Failed to create audit stream", zap.Error(err))
+	}
+	if err := events.EnsureDeadLetterStream(js, 30*24*time.Hour); err != nil {
+		log.Fatal(ctx, "Failed to create dead-letter stream", zap.Error(err))
+	}
+
+	// Initialize delivery providers, channels without a configured provider are skipped
+	providers, err := provider.FromConfig(ctx, cfg.Notification)
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize notification providers", zap.Error(err))
+	}
+
+	// Initialize repositories and services
+	notificationRepo := repository.NewNotificationRepository(db)
+	preferenceRepo := repository.NewPreferenceRepository(db)
+
+	templateClient := client.NewTemplateClient(cfg.Endpoints["cms"])
+	notificationService := service.NewNotificationService(notificationRepo, preferenceRepo, templateClient, providers, service.DeliveryConfig{
+		DefaultLocale: cfg.I18n.DefaultLocale,
+		AlertEmails:   cfg.Notification.AlertEmails,
+		MaxAttempts:   cfg.Notification.MaxAttempts,
+		RetryInterval: time.Duration(cfg.Notification.RetryInterval) * time.Second,
+	}, log)
+	preferenceService := service.NewPreferenceService(preferenceRepo)
+
+	// Subscribe to order, shipment, user, inventory and support events
+	consumer := events.NewConsumer(js, events.ConsumerConfig{
+		Durable:      serviceName,
+		StreamMaxAge: time.Duration(cfg.NATS.StreamMaxAge) * time.Hour,
+	}, log)
+	lc.Add(shutdown.PhaseFlush, "consumer", 0, shutdown.Func(consumer.Close))
+	if err := notificationService.Subscribe(consumer); err != nil {
+		log.Fatal(ctx, "Failed to subscribe to events", zap.Error(err))
+	}
+
+	// Retry failed deliveries on the elected leader only, so that each
+	// notification is picked up by a single replica
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	lc.Add(shutdown.PhaseFlush, "workers", 0, shutdown.Func(stopWorkers))
+	election := locks.NewElection(locks.New(rdb, serviceName), "workers", 30*time.Second, log)
+	go election.Run(workerCtx, func(ctx context.Context) {
+		notificationService.Run(ctx, 30*time.Second)
+	})
+
+	// Initialize metrics
+	m := metrics.New(serviceName)
+	if err := m.RegisterDB(db, cfg.Database.DBName); err != nil {
+		log.Warn(ctx, "Failed to register database metrics", zap.Error(err))
+	}
+	if err := m.RegisterRedis(rdb); err != nil {
+		log.Warn(ctx, "Failed to register Redis metrics", zap.Error(err))
+	}
+
+	// Initialize health checks
+	h := health.New()
+	lc.Add(shutdown.PhaseTraffic, "health", 0, shutdown.Func(h.Shutdown))
+	h.Add("postgres", health.Database(db))
+	h.Add("redis", health.Redis(rdb))
+	h.Add("nats", health.NATS(nc))
+
+	// Initialize HTTP server
+	router := gin.Default()
+	router.Use(tracing.GinMiddleware(), m.GinMiddleware())
+	router.Use(apperrors.Middleware(log, cfg.Service.Environment, apperrors.WithTranslator(validator.ErrorTranslator)))
+	router.Use(audit.Middleware(audit.NewRecorder(js, serviceName), log))
+	m.Register(router)
+	h.Register(router)
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.HTTP.Port),
+		Handler: router,
+	}
+	lc.Add(shutdown.PhaseServers, "http", 0, shutdown.HTTPServer(httpServer))
+
+	// Register HTTP routes
+	setupHTTPRoutes(router, handler.NewNotificationHandler(notificationService, preferenceService))
+
+	// Initialize gRPC server
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(grpcclient.UnaryServerInterceptor(), tracing.UnaryServerInterceptor(), m.UnaryServerInterceptor(), apperrors.UnaryServerInterceptor()))
+	// Register gRPC services
+	h.RegisterGRPC(grpcServer)
+	lc.Add(shutdown.PhaseServers, "grpc", 0, shutdown.GRPCServer(grpcServer))
+
+	// Start HTTP server
+	go func() {
+		log.Info(ctx, "Starting HTTP server", zap.Int("port", cfg.HTTP.Port))
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(ctx, "HTTP server failed", zap.Error(err))
+		}
+	}()
+
+	// Start gRPC server
+	go func() {
+		log.Info(ctx, "Starting gRPC server", zap.Int("port", cfg.GRPC.Port))
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+		if err != nil {
+			log.Fatal(ctx, "Failed to listen on gRPC port", zap.Error(err))
+		}
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatal(ctx, "gRPC server failed", zap.Error(err))
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown the service, failed hooks
+	// are logged by the coordinator
+	lc.Wait()
+}
+
+// Setup HTTP routes
+func setupHTTPRoutes(router *gin.Engine, notificationHandler *handler.NotificationHandler) {
+	api := router.Group("/api/v1")
+	notificationHandler.RegisterRoutes(api)
+}
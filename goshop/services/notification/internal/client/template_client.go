@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/goshop/services/notification/internal/model"
+)
+
+var (
+	// ErrTemplateNotFound 表示 CMS 中没有对应渠道的消息模板
+	ErrTemplateNotFound = errors.New("message template not found")
+	// ErrInvalidVariables 表示模板变量缺失，CMS 拒绝渲染
+	ErrInvalidVariables = errors.New("invalid template variables")
+)
+
+// RenderedMessage 表示 CMS 渲染后的消息
+type RenderedMessage struct {
+	Key     string `json:"key"`
+	Locale  string `json:"locale"`
+	Version int    `json:"version"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// TemplateClient 通过 HTTP 调用 CMS 服务渲染消息模板
+type TemplateClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewTemplateClient 创建 CMS 模板客户端
+func NewTemplateClient(baseURL string) *TemplateClient {
+	return &TemplateClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// renderRequest 对应 CMS 服务 POST /api/v1/cms/templates/render 的请求
+type renderRequest struct {
+	Key       string                 `json:"key"`
+	Channel   model.Channel          `json:"channel"`
+	Locale    string                 `json:"locale"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// Render 渲染消息模板，语言没有对应模板时 CMS 使用默认语言的模板
+func (c *TemplateClient) Render(ctx context.Context, key string, channel model.Channel, locale string, variables map[string]interface{}) (*RenderedMessage, error) {
+	payload, err := json.Marshal(renderRequest{
+		Key:       key,
+		Channel:   channel,
+		Locale:    locale,
+		Variables: variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/cms/templates/render", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s (%s)", ErrTemplateNotFound, key, channel)
+	case http.StatusBadRequest:
+		return nil, fmt.Errorf("%w: %s (%s)", ErrInvalidVariables, key, channel)
+	default:
+		return nil, fmt.Errorf("cms service returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data RenderedMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return &body.Data, nil
+}
@@ -0,0 +1,160 @@
+package event
+
+import "time"
+
+// 通知服务订阅的事件类型
+const (
+	OrderPaid              = "order.paid"
+	ShipmentShipped        = "shipment.shipped"
+	ShipmentOutForDelivery = "shipment.out_for_delivery"
+	ShipmentDelivered      = "shipment.delivered"
+	ShipmentException      = "shipment.exception"
+	UserRegistered         = "user.registered"
+	UserUpdated            = "user.updated"
+	UserDeleted            = "user.deleted"
+	UserEmailVerification  = "user.email_verification_requested"
+	UserAccountLocked      = "user.account_locked"
+	UserPreferencesUpdated = "user.preferences_updated"
+	CelebrationReward      = "marketing.celebration_reward_granted"
+	InventoryLowStock      = "inventory.low_stock"
+	SupportTicketCreated   = "support.ticket_created"
+	SupportTicketReplied   = "support.ticket_replied"
+	SupportTicketResolved  = "support.ticket_resolved"
+	SupportSLABreached     = "support.sla_breached"
+)
+
+// OrderPaidEvent 是 order.paid 事件的数据
+type OrderPaidEvent struct {
+	OrderID       uint      `json:"order_id"`
+	OrderNumber   string    `json:"order_number"`
+	UserID        uint      `json:"user_id"`
+	GrandTotal    float64   `json:"grand_total"`
+	Currency      string    `json:"currency"`
+	PaymentMethod string    `json:"payment_method"`
+	PaidAt        time.Time `json:"paid_at"`
+}
+
+// CheckpointSummary 表示物流轨迹节点摘要
+type CheckpointSummary struct {
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// ShipmentEvent 是配送服务发布的配送单事件的数据
+type ShipmentEvent struct {
+	ShipmentID          uint                `json:"shipment_id"`
+	OrderID             uint                `json:"order_id"`
+	OrderNumber         string              `json:"order_number"`
+	UserID              uint                `json:"user_id"`
+	Status              string              `json:"status"`
+	CarrierName         *string             `json:"carrier_name,omitempty"`
+	TrackingNumber      *string             `json:"tracking_number,omitempty"`
+	TrackingURL         *string             `json:"tracking_url,omitempty"`
+	EstimatedDeliveryAt *time.Time          `json:"estimated_delivery_at,omitempty"`
+	Checkpoints         []CheckpointSummary `json:"checkpoints,omitempty"`
+	Reason              string              `json:"reason,omitempty"`
+}
+
+// UserRegisteredEvent 是 user.registered 事件的数据
+type UserRegisteredEvent struct {
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone,omitempty"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Locale    string `json:"locale,omitempty"`
+}
+
+// UserUpdatedEvent 是 user.updated 事件的数据，包含更新后的用户资料
+type UserUpdatedEvent struct {
+	UserID    uint     `json:"user_id"`
+	Email     string   `json:"email"`
+	Phone     string   `json:"phone,omitempty"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Fields    []string `json:"fields"`
+}
+
+// UserDeletedEvent 是 user.deleted 事件的数据
+type UserDeletedEvent struct {
+	UserID uint `json:"user_id"`
+}
+
+// LowStockEvent 是库存服务在可售库存低于预警值时发布的 inventory.low_stock 事件的数据
+type LowStockEvent struct {
+	ProductID   uint   `json:"product_id"`
+	SKUID       uint   `json:"sku_id"`
+	SKU         string `json:"sku"`
+	ProductName string `json:"product_name"`
+	WarehouseID uint   `json:"warehouse_id"`
+	Available   int    `json:"available"`
+	Threshold   int    `json:"threshold"`
+}
+
+// EmailVerificationEvent 是 user.email_verification_requested 事件的数据
+type EmailVerificationEvent struct {
+	UserID    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	VerifyURL string    `json:"verify_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AccountLockedEvent 是 user.account_locked 事件的数据
+type AccountLockedEvent struct {
+	UserID      uint      `json:"user_id"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	IP          string    `json:"ip"`
+	UnlockURL   string    `json:"unlock_url"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// PreferencesUpdatedEvent 是 user.preferences_updated 事件的数据
+type PreferencesUpdatedEvent struct {
+	UserID            uint   `json:"user_id"`
+	Locale            string `json:"locale"`
+	Currency          string `json:"currency"`
+	MarketingEmails   bool   `json:"marketing_emails"`
+	PushNotifications bool   `json:"push_notifications"`
+}
+
+// CelebrationRewardEvent 是 marketing.celebration_reward_granted 事件的数据
+type CelebrationRewardEvent struct {
+	UserID          uint       `json:"user_id"`
+	FirstName       string     `json:"first_name"`
+	Type            string     `json:"type"` // birthday, anniversary
+	CampaignName    string     `json:"campaign_name"`
+	Message         string     `json:"message"`
+	Years           int        `json:"years,omitempty"`
+	CouponCode      string     `json:"coupon_code,omitempty"`
+	CouponExpiresAt *time.Time `json:"coupon_expires_at,omitempty"`
+	Points          int        `json:"points,omitempty"`
+}
+
+// TicketEvent 是客服服务发布的工单事件的数据，UserID 为 0 表示通过邮件提交工单的访客，
+// 通知直接发送到 Email
+type TicketEvent struct {
+	TicketID     uint   `json:"ticket_id"`
+	Number       string `json:"number"`
+	UserID       uint   `json:"user_id"`
+	Email        string `json:"email,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Subject      string `json:"subject"`
+	Status       string `json:"status"`
+	Message      string `json:"message,omitempty"`
+	ReplyAddress string `json:"reply_address"`
+}
+
+// SLABreachedEvent 是 support.sla_breached 事件的数据
+type SLABreachedEvent struct {
+	TicketID   uint      `json:"ticket_id"`
+	Number     string    `json:"number"`
+	Subject    string    `json:"subject"`
+	Priority   string    `json:"priority"`
+	AssigneeID *uint     `json:"assignee_id,omitempty"`
+	SLA        string    `json:"sla"`
+	DueAt      time.Time `json:"due_at"`
+}
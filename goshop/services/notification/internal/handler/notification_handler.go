@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+	"github.com/yourusername/goshop/services/notification/internal/repository"
+	"github.com/yourusername/goshop/services/notification/internal/service"
+)
+
+// NotificationHandler 处理通知记录、通知偏好和推送设备相关的 HTTP 请求
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+	preferenceService   *service.PreferenceService
+}
+
+// NewNotificationHandler 创建通知处理器
+func NewNotificationHandler(notificationService *service.NotificationService, preferenceService *service.PreferenceService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		preferenceService:   preferenceService,
+	}
+}
+
+// RegisterRoutes 注册通知路由
+func (h *NotificationHandler) RegisterRoutes(api *gin.RouterGroup) {
+	me := api.Group("/notifications/me")
+	{
+		me.GET("", h.ListMine)
+		me.GET("/preferences", h.GetPreferences)
+		me.PUT("/preferences", h.UpdatePreferences)
+		me.GET("/contact", h.GetContact)
+		me.PUT("/contact", h.UpdateContact)
+		me.GET("/devices", h.ListDevices)
+		me.POST("/devices", h.RegisterDevice)
+		me.DELETE("/devices/:token", h.RemoveDevice)
+	}
+
+	admin := api.Group("/notifications/admin/notifications")
+	{
+		admin.GET("", h.List)
+		admin.GET("/:id", h.Get)
+		admin.POST("/:id/retry", h.Retry)
+	}
+}
+
+// ListMine 分页获取当前用户收到的通知
+func (h *NotificationHandler) ListMine(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	list, err := h.notificationService.List(c.Request.Context(), repository.NotificationFilter{UserID: userID},
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetPreferences 获取当前用户每类通知在每个渠道上的接收设置
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	items, err := h.preferenceService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// UpdatePreferences 更新当前用户的通知偏好
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.UpdatePreferencesRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	items, err := h.preferenceService.UpdatePreferences(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// GetContact 获取当前用户接收通知的联系方式
+func (h *NotificationHandler) GetContact(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	contact, err := h.preferenceService.GetContact(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": contact})
+}
+
+// UpdateContact 更新当前用户接收通知的联系方式
+func (h *NotificationHandler) UpdateContact(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.ContactRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	contact, err := h.preferenceService.UpdateContact(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": contact})
+}
+
+// ListDevices 获取当前用户登记的推送设备
+func (h *NotificationHandler) ListDevices(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	devices, err := h.preferenceService.ListDevices(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": devices})
+}
+
+// RegisterDevice 登记当前用户的推送设备
+func (h *NotificationHandler) RegisterDevice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.DeviceRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	device, err := h.preferenceService.RegisterDevice(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": device})
+}
+
+// RemoveDevice 删除当前用户的推送设备
+func (h *NotificationHandler) RemoveDevice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.preferenceService.RemoveDevice(c.Request.Context(), userID, c.Param("token")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// List 分页查询通知的投递记录，可按 user_id、status、channel 和 event_type 过滤
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID, ok := parseIDQuery(c, "user_id")
+	if !ok {
+		return
+	}
+	filter := repository.NotificationFilter{
+		UserID:    userID,
+		Status:    c.Query("status"),
+		Channel:   model.Channel(c.Query("channel")),
+		EventType: c.Query("event_type"),
+	}
+	list, err := h.notificationService.List(c.Request.Context(), filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// Get 获取通知的投递记录
+func (h *NotificationHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	n, err := h.notificationService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": n})
+}
+
+// Retry 重新投递失败的通知
+func (h *NotificationHandler) Retry(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	n, err := h.notificationService.Retry(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": n})
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Channel 表示通知的发送渠道
+type Channel string
+
+const (
+	// ChannelEmail 邮件
+	ChannelEmail Channel = "email"
+	// ChannelSMS 短信
+	ChannelSMS Channel = "sms"
+	// ChannelPush App 推送
+	ChannelPush Channel = "push"
+)
+
+// Channels 是所有支持的发送渠道
+var Channels = []Channel{ChannelEmail, ChannelSMS, ChannelPush}
+
+// 通知类别，用户按类别和渠道设置是否接收
+const (
+	CategoryOrder     = "order"     // 订单支付等交易通知
+	CategoryShipping  = "shipping"  // 发货、派送和签收通知
+	CategoryAccount   = "account"   // 注册欢迎等账户通知
+	CategorySupport   = "support"   // 客服工单的回复和处理进度
+	CategoryMarketing = "marketing" // 生日礼遇等营销通知，邮件只发给订阅了营销邮件的用户
+	CategoryAlert     = "alert"     // 发给运营人员的库存预警等告警，不受用户偏好限制
+)
+
+// Categories 是用户可以设置偏好的通知类别
+var Categories = []string{CategoryOrder, CategoryShipping, CategoryAccount, CategorySupport, CategoryMarketing}
+
+// 通知发送状态
+const (
+	StatusPending = "pending" // 等待发送或等待重试
+	StatusSent    = "sent"    // 已被服务商接收
+	StatusFailed  = "failed"  // 重试次数用尽或被服务商拒绝
+)
+
+// Notification 表示发给一个接收方的一条通知及其投递状态。
+// 同一事件在同一渠道对同一接收方只生成一条通知，事件重复投递时不会重复发送
+type Notification struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	EventID           string     `json:"event_id" gorm:"uniqueIndex:idx_notification_dedup;size:100;not null"`
+	EventType         string     `json:"event_type" gorm:"index;size:100;not null"`
+	UserID            uint       `json:"user_id" gorm:"index"` // 运营告警为 0
+	Category          string     `json:"category" gorm:"size:20;not null"`
+	Channel           Channel    `json:"channel" gorm:"uniqueIndex:idx_notification_dedup;size:20;not null"`
+	Recipient         string     `json:"recipient" gorm:"uniqueIndex:idx_notification_dedup;size:255;not null"` // 邮箱、手机号或设备令牌
+	TemplateKey       string     `json:"template_key" gorm:"size:100;not null"`
+	Locale            string     `json:"locale" gorm:"size:10"`
+	Variables         JSONMap    `json:"variables" gorm:"type:jsonb"`
+	Subject           string     `json:"subject" gorm:"size:255"` // 渲染后的主题，发送前为空
+	Body              string     `json:"body" gorm:"type:text"`   // 渲染后的正文，发送前为空
+	Status            string     `json:"status" gorm:"index:idx_notification_due;size:20;not null;default:'pending'"`
+	Provider          string     `json:"provider" gorm:"size:20"`
+	ProviderMessageID string     `json:"provider_message_id" gorm:"size:255"`
+	Attempts          int        `json:"attempts" gorm:"not null;default:0"`
+	LastError         string     `json:"last_error" gorm:"size:1000"`
+	NextAttemptAt     time.Time  `json:"next_attempt_at" gorm:"index:idx_notification_due;not null"`
+	SentAt            *time.Time `json:"sent_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// Preference 表示用户对某类通知在某个渠道上的接收设置，没有记录时默认接收
+type Preference struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_preference;not null"`
+	Category  string    `json:"category" gorm:"uniqueIndex:idx_preference;size:20;not null"`
+	Channel   Channel   `json:"channel" gorm:"uniqueIndex:idx_preference;size:20;not null"`
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Contact 表示用户的联系方式，由 user.registered 和 user.updated 事件维护，用户注销时删除。
+// 语言、营销邮件订阅和推送开关同步自用户服务的用户偏好
+type Contact struct {
+	UserID          uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Name            string    `json:"name" gorm:"size:100"`
+	Email           string    `json:"email" gorm:"size:255"`
+	Phone           string    `json:"phone" gorm:"size:20"`
+	Locale          string    `json:"locale" gorm:"size:10"`
+	MarketingEmails bool      `json:"marketing_emails"` // 订阅了营销邮件
+	PushDisabled    bool      `json:"push_disabled"`    // 关闭了所有推送通知
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// 推送设备平台
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWeb     = "web"
+)
+
+// Device 表示用户登记的推送设备，服务商返回令牌失效时自动删除
+type Device struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"index;not null"`
+	Token      string    `json:"token" gorm:"uniqueIndex;size:255;not null"`
+	Platform   string    `json:"platform" gorm:"size:20;not null"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// JSONMap 是一个自定义类型，用于存储 JSON 对象
+type JSONMap map[string]interface{}
+
+// Value 实现 driver.Valuer 接口
+func (j JSONMap) Value() (driver.Value, error) {
+	return json.Marshal(j)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (j *JSONMap) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("类型断言为 []byte 失败")
+	}
+	return json.Unmarshal(b, &j)
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+)
+
+// aliyunSMSURL 是阿里云短信 RPC 接口地址
+const aliyunSMSURL = "https://dysmsapi.aliyuncs.com/"
+
+// aliyunRetryableCodes 是阿里云短信可以重试的错误码，其余错误码视为拒绝
+var aliyunRetryableCodes = map[string]bool{
+	"isv.BUSINESS_LIMIT_CONTROL": true, // 触发发送频率限制
+	"isp.SYSTEM_ERROR":           true,
+}
+
+// AliyunSMS 通过阿里云短信服务发送短信。阿里云只能发送审核通过的模板，
+// 消息按模板标识查找配置的模板代码，模板变量作为 TemplateParam 传入
+type AliyunSMS struct {
+	cfg        config.AliyunSMSConfig
+	httpClient *http.Client
+}
+
+// NewAliyunSMS 创建阿里云短信服务商
+func NewAliyunSMS(cfg config.AliyunSMSConfig) *AliyunSMS {
+	return &AliyunSMS{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name 返回服务商名称
+func (a *AliyunSMS) Name() string {
+	return "aliyun"
+}
+
+// Channel 返回短信渠道
+func (a *AliyunSMS) Channel() model.Channel {
+	return model.ChannelSMS
+}
+
+// Send 发送短信，模板标识没有配置阿里云模板代码时视为拒绝
+func (a *AliyunSMS) Send(ctx context.Context, msg *Message) (*Result, error) {
+	templateCode, ok := a.cfg.Templates[strings.ReplaceAll(msg.TemplateKey, ".", "_")]
+	if !ok {
+		return nil, fmt.Errorf("%w: no aliyun template code for %s", ErrRejected, msg.TemplateKey)
+	}
+	params := make(map[string]string, len(msg.Variables))
+	for name, value := range msg.Variables {
+		params[name] = fmt.Sprint(value)
+	}
+	templateParam, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate signature nonce: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("AccessKeyId", a.cfg.AccessKeyID)
+	query.Set("Action", "SendSms")
+	query.Set("Format", "JSON")
+	query.Set("PhoneNumbers", msg.To)
+	query.Set("RegionId", "cn-hangzhou")
+	query.Set("SignName", a.cfg.SignName)
+	query.Set("SignatureMethod", "HMAC-SHA1")
+	query.Set("SignatureNonce", hex.EncodeToString(nonce))
+	query.Set("SignatureVersion", "1.0")
+	query.Set("TemplateCode", templateCode)
+	query.Set("TemplateParam", string(templateParam))
+	query.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	query.Set("Version", "2017-05-25")
+	canonical := canonicalQuery(query)
+	signature := a.sign(http.MethodGet, canonical)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, aliyunSMSURL+"?Signature="+percentEncode(signature)+"&"+canonical, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, httpError("aliyun", resp)
+	}
+	var body struct {
+		Code    string `json:"Code"`
+		Message string `json:"Message"`
+		BizID   string `json:"BizId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Code != "OK" {
+		err := fmt.Errorf("aliyun returned %s: %s", body.Code, body.Message)
+		if aliyunRetryableCodes[body.Code] {
+			return nil, err
+		}
+		if body.Code == "isv.MOBILE_NUMBER_ILLEGAL" {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	return &Result{MessageID: body.BizID}, nil
+}
+
+// sign 按阿里云 RPC 签名算法计算签名
+func (a *AliyunSMS) sign(method, canonical string) string {
+	stringToSign := method + "&" + percentEncode("/") + "&" + percentEncode(canonical)
+	mac := hmac.New(sha1.New, []byte(a.cfg.AccessKeySecret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// canonicalQuery 按参数名排序并编码请求参数
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, percentEncode(k)+"="+percentEncode(query.Get(k)))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// percentEncode 按 RFC 3986 编码，阿里云要求空格编码为 %20、保留 ~
+func percentEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	return strings.ReplaceAll(s, "%7E", "~")
+}
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// fcmURL 是 FCM HTTP v1 发送接口，%s 为 Firebase 项目 ID
+	fcmURL = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	// fcmScope 是调用 FCM 所需的 OAuth2 权限
+	fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+// FCM 通过 Firebase Cloud Messaging HTTP v1 接口发送 App 推送
+type FCM struct {
+	projectID  string
+	httpClient *http.Client
+}
+
+// NewFCM 创建 FCM 推送服务商，使用服务账号密钥文件或默认凭据获取访问令牌
+func NewFCM(ctx context.Context, cfg config.FCMConfig) (*FCM, error) {
+	var creds *google.Credentials
+	if cfg.CredentialsFile != "" {
+		data, err := os.ReadFile(cfg.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("read fcm credentials: %w", err)
+		}
+		if creds, err = google.CredentialsFromJSON(ctx, data, fcmScope); err != nil {
+			return nil, fmt.Errorf("parse fcm credentials: %w", err)
+		}
+	} else {
+		var err error
+		if creds, err = google.FindDefaultCredentials(ctx, fcmScope); err != nil {
+			return nil, fmt.Errorf("find fcm credentials: %w", err)
+		}
+	}
+
+	httpClient := oauth2.NewClient(ctx, creds.TokenSource)
+	httpClient.Timeout = 10 * time.Second
+	return &FCM{
+		projectID:  cfg.ProjectID,
+		httpClient: httpClient,
+	}, nil
+}
+
+// Name 返回服务商名称
+func (f *FCM) Name() string {
+	return "fcm"
+}
+
+// Channel 返回推送渠道
+func (f *FCM) Channel() model.Channel {
+	return model.ChannelPush
+}
+
+// Send 向设备令牌发送推送，令牌未注册或已失效时返回 ErrInvalidRecipient
+func (f *FCM) Send(ctx context.Context, msg *Message) (*Result, error) {
+	data := map[string]string{"template": msg.TemplateKey}
+	payload, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": msg.To,
+			"notification": map[string]string{
+				"title": msg.Subject,
+				"body":  msg.Body,
+			},
+			"data": data,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(fcmURL, f.projectID), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if strings.Contains(string(body), "UNREGISTERED") || strings.Contains(string(body), "registration token is not a valid") {
+			return nil, fmt.Errorf("%w: fcm returned status %d: %s", ErrInvalidRecipient, resp.StatusCode, body)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return nil, httpError("fcm", resp)
+	}
+	var body struct {
+		Name string `json:"name"` // projects/{project}/messages/{id}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return &Result{MessageID: body.Name}, nil
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/yourusername/goshop/services/notification/internal/model"
+)
+
+var (
+	// ErrRejected 表示服务商拒绝了消息，重试也无法成功，如参数错误或模板未审核
+	ErrRejected = errors.New("message rejected by provider")
+	// ErrInvalidRecipient 表示接收方无效，如邮箱格式错误或推送令牌已失效
+	ErrInvalidRecipient = fmt.Errorf("%w: invalid recipient", ErrRejected)
+)
+
+// Message 表示一条待发送的消息
+type Message struct {
+	To          string                 // 邮箱、手机号或设备令牌
+	Subject     string                 // 邮件主题或推送标题，短信为空
+	Body        string                 // 邮件正文为 HTML
+	TemplateKey string                 // 模板标识，阿里云短信按其查找审核过的模板
+	Variables   map[string]interface{} // 模板变量，只能发送预审模板的服务商使用
+}
+
+// Result 表示服务商接收消息后的结果
+type Result struct {
+	MessageID string // 服务商侧的消息 ID，用于对账和排查
+}
+
+// Provider 定义消息服务商适配器接口，每个渠道配置一个服务商
+type Provider interface {
+	// Name 返回服务商名称，如 smtp、twilio
+	Name() string
+	// Channel 返回服务商负责的渠道
+	Channel() model.Channel
+	// Send 发送消息。返回包装了 ErrRejected 的错误时不再重试
+	Send(ctx context.Context, msg *Message) (*Result, error)
+}
+
+// httpError 根据服务商的 HTTP 响应生成错误，4xx（429 除外）视为拒绝
+func httpError(name string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err := fmt.Errorf("%s returned status %d: %s", name, resp.StatusCode, body)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	return err
+}
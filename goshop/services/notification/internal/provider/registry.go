@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+)
+
+// ErrProviderNotFound 表示渠道没有配置服务商
+var ErrProviderNotFound = errors.New("notification provider not found")
+
+// Registry 按渠道管理服务商适配器
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[model.Channel]Provider
+}
+
+// NewRegistry 创建服务商注册表
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{
+		providers: make(map[model.Channel]Provider),
+	}
+	for _, p := range providers {
+		r.Register(p)
+	}
+	return r
+}
+
+// FromConfig 按配置创建各渠道的服务商，未配置服务商的渠道不发送
+func FromConfig(ctx context.Context, cfg config.NotificationConfig) (*Registry, error) {
+	r := NewRegistry()
+	switch cfg.EmailProvider {
+	case "smtp":
+		r.Register(NewSMTP(cfg.SMTP, cfg.FromEmail, cfg.FromName))
+	case "sendgrid":
+		r.Register(NewSendGrid(cfg.SendGrid, cfg.FromEmail, cfg.FromName))
+	}
+	switch cfg.SMSProvider {
+	case "twilio":
+		r.Register(NewTwilio(cfg.Twilio))
+	case "aliyun":
+		r.Register(NewAliyunSMS(cfg.Aliyun))
+	}
+	if cfg.PushProvider == "fcm" {
+		fcm, err := NewFCM(ctx, cfg.FCM)
+		if err != nil {
+			return nil, err
+		}
+		r.Register(fcm)
+	}
+	return r, nil
+}
+
+// Register 注册服务商适配器，同一渠道重复注册时后者覆盖前者
+func (r *Registry) Register(p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Channel()] = p
+}
+
+// Get 获取渠道的服务商适配器
+func (r *Registry) Get(channel model.Channel) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[channel]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, channel)
+	}
+	return p, nil
+}
+
+// Enabled 判断渠道是否配置了服务商
+func (r *Registry) Enabled(channel model.Channel) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.providers[channel]
+	return ok
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+)
+
+// sendGridURL 是 SendGrid v3 发送邮件接口
+const sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGrid 通过 SendGrid API 发送 HTML 邮件
+type SendGrid struct {
+	apiKey     string
+	from       sendGridAddress
+	httpClient *http.Client
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+// NewSendGrid 创建 SendGrid 邮件服务商
+func NewSendGrid(cfg config.SendGridConfig, fromEmail, fromName string) *SendGrid {
+	return &SendGrid{
+		apiKey:     cfg.APIKey,
+		from:       sendGridAddress{Email: fromEmail, Name: fromName},
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name 返回服务商名称
+func (s *SendGrid) Name() string {
+	return "sendgrid"
+}
+
+// Channel 返回邮件渠道
+func (s *SendGrid) Channel() model.Channel {
+	return model.ChannelEmail
+}
+
+// Send 发送邮件，SendGrid 接收后返回 202 和 X-Message-Id 响应头
+func (s *SendGrid) Send(ctx context.Context, msg *Message) (*Result, error) {
+	payload, err := json.Marshal(sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: msg.To}}}},
+		From:             s.from,
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/html", Value: msg.Body}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return nil, httpError("sendgrid", resp)
+	}
+	return &Result{MessageID: resp.Header.Get("X-Message-Id")}, nil
+}
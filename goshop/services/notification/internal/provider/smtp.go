@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+)
+
+// smtpTimeout 是未设置截止时间时单次投递的超时时间
+const smtpTimeout = 30 * time.Second
+
+// SMTP 通过 SMTP 中继发送 HTML 邮件，服务器支持时使用 STARTTLS
+type SMTP struct {
+	cfg  config.SMTPConfig
+	from mail.Address
+}
+
+// NewSMTP 创建 SMTP 邮件服务商
+func NewSMTP(cfg config.SMTPConfig, fromEmail, fromName string) *SMTP {
+	return &SMTP{
+		cfg:  cfg,
+		from: mail.Address{Name: fromName, Address: fromEmail},
+	}
+}
+
+// Name 返回服务商名称
+func (s *SMTP) Name() string {
+	return "smtp"
+}
+
+// Channel 返回邮件渠道
+func (s *SMTP) Channel() model.Channel {
+	return model.ChannelEmail
+}
+
+// Send 发送邮件，服务器返回 5xx 时视为拒绝
+func (s *SMTP) Send(ctx context.Context, msg *Message) (*Result, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+	}
+	messageID, err := newMessageID(s.from.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial smtp %s: %w", addr, err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return nil, fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return nil, fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := c.Mail(s.from.Address); err != nil {
+		return nil, smtpError(err)
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return nil, smtpError(err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return nil, smtpError(err)
+	}
+	if _, err := w.Write(s.compose(to, messageID, msg)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, smtpError(err)
+	}
+	if err := c.Quit(); err != nil {
+		return nil, smtpError(err)
+	}
+	return &Result{MessageID: messageID}, nil
+}
+
+// compose 生成 MIME 邮件，主题和发件人名称按 RFC 2047 编码，正文按 base64 编码
+func (s *SMTP) compose(to *mail.Address, messageID string, msg *Message) []byte {
+	var b bytes.Buffer
+	header := func(key, value string) {
+		b.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", s.from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID)
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/html; charset=UTF-8")
+	header("Content-Transfer-Encoding", "base64")
+	b.WriteString("\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.Bytes()
+}
+
+// smtpError 将 5xx 永久性错误视为拒绝，4xx 临时性错误可以重试
+func smtpError(err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	return err
+}
+
+// newMessageID 生成 Message-ID，域名取发件地址的域名
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate message id: %w", err)
+	}
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain), nil
+}
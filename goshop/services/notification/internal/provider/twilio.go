@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+)
+
+// twilioURL 是 Twilio 发送短信接口，%s 为账户 SID
+const twilioURL = "https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json"
+
+// Twilio 通过 Twilio API 发送短信
+type Twilio struct {
+	cfg        config.TwilioConfig
+	httpClient *http.Client
+}
+
+// NewTwilio 创建 Twilio 短信服务商
+func NewTwilio(cfg config.TwilioConfig) *Twilio {
+	return &Twilio{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name 返回服务商名称
+func (t *Twilio) Name() string {
+	return "twilio"
+}
+
+// Channel 返回短信渠道
+func (t *Twilio) Channel() model.Channel {
+	return model.ChannelSMS
+}
+
+// Send 发送短信，手机号需为 E.164 格式。From 以 MG 开头时视为消息服务 SID
+func (t *Twilio) Send(ctx context.Context, msg *Message) (*Result, error) {
+	form := url.Values{}
+	form.Set("To", msg.To)
+	form.Set("Body", msg.Body)
+	if strings.HasPrefix(t.cfg.From, "MG") {
+		form.Set("MessagingServiceSid", t.cfg.From)
+	} else {
+		form.Set("From", t.cfg.From)
+	}
+
+	endpoint := fmt.Sprintf(twilioURL, url.PathEscape(t.cfg.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(t.cfg.AccountSID, t.cfg.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, httpError("twilio", resp)
+	}
+	var body struct {
+		SID string `json:"sid"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return &Result{MessageID: body.SID}, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/notification/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationFilter 表示后台查询通知的过滤条件，零值字段不参与过滤
+type NotificationFilter struct {
+	UserID    uint
+	Status    string
+	Channel   model.Channel
+	EventType string
+}
+
+// NotificationRepository 定义通知仓库接口
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *model.Notification) (bool, error)
+	GetByID(ctx context.Context, id uint) (*model.Notification, error)
+	Update(ctx context.Context, notification *model.Notification) error
+	List(ctx context.Context, filter NotificationFilter, offset, limit int) ([]*model.Notification, int64, error)
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*model.Notification, error)
+	Claim(ctx context.Context, id uint, now, until time.Time) (bool, error)
+}
+
+// GormNotificationRepository 实现 NotificationRepository 接口的 GORM 仓库
+type GormNotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建通知仓库实例
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &GormNotificationRepository{
+		db: db,
+	}
+}
+
+// Create 创建通知，同一事件、渠道和接收方的通知已存在时不创建并返回 false
+func (r *GormNotificationRepository) Create(ctx context.Context, notification *model.Notification) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetByID 根据 ID 获取通知
+func (r *GormNotificationRepository) GetByID(ctx context.Context, id uint) (*model.Notification, error) {
+	var notification model.Notification
+	err := r.db.WithContext(ctx).First(&notification, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// Update 保存通知的渲染结果和投递状态
+func (r *GormNotificationRepository) Update(ctx context.Context, notification *model.Notification) error {
+	return r.db.WithContext(ctx).Save(notification).Error
+}
+
+// List 按过滤条件分页获取通知，按创建时间倒序
+func (r *GormNotificationRepository) List(ctx context.Context, filter NotificationFilter, offset, limit int) ([]*model.Notification, int64, error) {
+	var notifications []*model.Notification
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Notification{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&notifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+// ListDue 获取到期待发送的通知，按到期时间升序
+func (r *GormNotificationRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*model.Notification, error) {
+	var notifications []*model.Notification
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", model.StatusPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// Claim 将到期的通知顺延到 until，防止发送过程中被再次取出。
+// 通知已被其他进程领取或已不再待发送时返回 false
+func (r *GormNotificationRepository) Claim(ctx context.Context, id uint, now, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, model.StatusPending, now).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/notification/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PreferenceRepository 定义用户通知偏好、联系方式和推送设备的仓库接口
+type PreferenceRepository interface {
+	ListPreferences(ctx context.Context, userID uint) ([]*model.Preference, error)
+	SavePreferences(ctx context.Context, preferences []*model.Preference) error
+	GetContact(ctx context.Context, userID uint) (*model.Contact, error)
+	SaveContact(ctx context.Context, contact *model.Contact) error
+	SaveContactSettings(ctx context.Context, contact *model.Contact) error
+	SaveContactDetails(ctx context.Context, contact *model.Contact) error
+	DeleteUser(ctx context.Context, userID uint) error
+	ListDevices(ctx context.Context, userID uint) ([]*model.Device, error)
+	SaveDevice(ctx context.Context, device *model.Device) error
+	DeleteDevice(ctx context.Context, userID uint, token string) (bool, error)
+	DeleteDeviceByToken(ctx context.Context, token string) error
+}
+
+// GormPreferenceRepository 实现 PreferenceRepository 接口的 GORM 仓库
+type GormPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewPreferenceRepository 创建通知偏好仓库实例
+func NewPreferenceRepository(db *gorm.DB) PreferenceRepository {
+	return &GormPreferenceRepository{
+		db: db,
+	}
+}
+
+// ListPreferences 获取用户设置过的通知偏好
+func (r *GormPreferenceRepository) ListPreferences(ctx context.Context, userID uint) ([]*model.Preference, error) {
+	var preferences []*model.Preference
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&preferences).Error
+	return preferences, err
+}
+
+// SavePreferences 按用户、类别和渠道创建或更新通知偏好
+func (r *GormPreferenceRepository) SavePreferences(ctx context.Context, preferences []*model.Preference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "category"}, {Name: "channel"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+		}).
+		Create(&preferences).Error
+}
+
+// GetContact 获取用户的联系方式
+func (r *GormPreferenceRepository) GetContact(ctx context.Context, userID uint) (*model.Contact, error) {
+	var contact model.Contact
+	err := r.db.WithContext(ctx).First(&contact, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// SaveContact 创建或更新用户的联系方式
+func (r *GormPreferenceRepository) SaveContact(ctx context.Context, contact *model.Contact) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "email", "phone", "locale", "updated_at"}),
+		}).
+		Create(contact).Error
+}
+
+// SaveContactSettings 创建或更新用户的语言、营销邮件订阅和推送开关，不修改联系方式
+func (r *GormPreferenceRepository) SaveContactSettings(ctx context.Context, contact *model.Contact) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"locale", "marketing_emails", "push_disabled", "updated_at"}),
+		}).
+		Create(contact).Error
+}
+
+// SaveContactDetails 创建或更新用户的姓名、邮箱和手机号，不修改语言和订阅设置
+func (r *GormPreferenceRepository) SaveContactDetails(ctx context.Context, contact *model.Contact) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "email", "phone", "updated_at"}),
+		}).
+		Create(contact).Error
+}
+
+// DeleteUser 在事务中删除用户的联系方式、通知偏好和推送设备
+func (r *GormPreferenceRepository) DeleteUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, m := range []interface{}{&model.Contact{}, &model.Preference{}, &model.Device{}} {
+			if err := tx.Where("user_id = ?", userID).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListDevices 获取用户登记的推送设备
+func (r *GormPreferenceRepository) ListDevices(ctx context.Context, userID uint) ([]*model.Device, error) {
+	var devices []*model.Device
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error
+	return devices, err
+}
+
+// SaveDevice 按令牌登记推送设备，令牌已登记时转移到当前用户并刷新活跃时间
+func (r *GormPreferenceRepository) SaveDevice(ctx context.Context, device *model.Device) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "token"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "last_seen_at"}),
+		}).
+		Create(device).Error
+}
+
+// DeleteDevice 删除用户的推送设备，设备不存在时返回 false
+func (r *GormPreferenceRepository) DeleteDevice(ctx context.Context, userID uint, token string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND token = ?", userID, token).
+		Delete(&model.Device{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteDeviceByToken 删除服务商返回已失效的推送令牌
+func (r *GormPreferenceRepository) DeleteDeviceByToken(ctx context.Context, token string) error {
+	return r.db.WithContext(ctx).Where("token = ?", token).Delete(&model.Device{}).Error
+}
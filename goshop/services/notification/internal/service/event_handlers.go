@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/services/notification/internal/event"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+)
+
+// allChannels 是用户通知尝试的渠道，未配置服务商、用户关闭或缺少联系方式的渠道会被跳过
+var allChannels = []model.Channel{model.ChannelEmail, model.ChannelSMS, model.ChannelPush}
+
+// Subscribe 订阅订单支付、配送、用户注册、资料变更和注销、邮箱验证、账号锁定和偏好变更、纪念日奖励、库存预警和客服工单事件。模板标识即事件类型，
+// 如 order.paid 事件使用 CMS 中 key 为 order.paid 的各渠道模板
+func (s *NotificationService) Subscribe(consumer *events.Consumer) error {
+	handlers := map[string]events.Handler{
+		event.OrderPaid:              s.handleOrderPaid,
+		event.ShipmentShipped:        s.handleShipment,
+		event.ShipmentOutForDelivery: s.handleShipment,
+		event.ShipmentDelivered:      s.handleShipment,
+		event.ShipmentException:      s.handleShipment,
+		event.UserRegistered:         s.handleUserRegistered,
+		event.UserUpdated:            s.handleUserUpdated,
+		event.UserDeleted:            s.handleUserDeleted,
+		event.UserEmailVerification:  s.handleEmailVerification,
+		event.UserAccountLocked:      s.handleAccountLocked,
+		event.UserPreferencesUpdated: s.handlePreferencesUpdated,
+		event.CelebrationReward:      s.handleCelebrationReward,
+		event.InventoryLowStock:      s.handleLowStock,
+		event.SupportTicketCreated:   s.handleTicket,
+		event.SupportTicketReplied:   s.handleTicket,
+		event.SupportTicketResolved:  s.handleTicket,
+		event.SupportSLABreached:     s.handleSLABreached,
+	}
+	for eventType, handler := range handlers {
+		if err := consumer.Subscribe(eventType, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *NotificationService) handleOrderPaid(ctx context.Context, env *events.Envelope) error {
+	var evt event.OrderPaidEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	return s.Notify(ctx, &Notice{
+		EventID:     env.ID,
+		EventType:   env.Type,
+		UserID:      evt.UserID,
+		Category:    model.CategoryOrder,
+		TemplateKey: env.Type,
+		Variables: map[string]interface{}{
+			"order_number":   evt.OrderNumber,
+			"order_total":    formatAmount(evt.GrandTotal),
+			"currency":       evt.Currency,
+			"payment_method": evt.PaymentMethod,
+			"paid_at":        evt.PaidAt.Format("2006-01-02 15:04"),
+		},
+		Channels: allChannels,
+	})
+}
+
+func (s *NotificationService) handleShipment(ctx context.Context, env *events.Envelope) error {
+	var evt event.ShipmentEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	variables := map[string]interface{}{
+		"order_number":    evt.OrderNumber,
+		"carrier_name":    valueOf(evt.CarrierName),
+		"tracking_number": valueOf(evt.TrackingNumber),
+		"tracking_url":    valueOf(evt.TrackingURL),
+		"reason":          evt.Reason,
+	}
+	if evt.EstimatedDeliveryAt != nil {
+		variables["estimated_delivery"] = evt.EstimatedDeliveryAt.Format("2006-01-02")
+	}
+	if len(evt.Checkpoints) > 0 {
+		variables["latest_status"] = evt.Checkpoints[0].Description
+		variables["latest_location"] = evt.Checkpoints[0].Location
+	}
+	return s.Notify(ctx, &Notice{
+		EventID:     env.ID,
+		EventType:   env.Type,
+		UserID:      evt.UserID,
+		Category:    model.CategoryShipping,
+		TemplateKey: env.Type,
+		Variables:   variables,
+		Channels:    allChannels,
+	})
+}
+
+// handleUserRegistered 保存新用户的联系方式并发送欢迎邮件，新用户还没有推送设备
+func (s *NotificationService) handleUserRegistered(ctx context.Context, env *events.Envelope) error {
+	var evt event.UserRegisteredEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	name := strings.TrimSpace(evt.FirstName + " " + evt.LastName)
+	if err := s.preferenceRepo.SaveContact(ctx, &model.Contact{
+		UserID: evt.UserID,
+		Name:   name,
+		Email:  evt.Email,
+		Phone:  evt.Phone,
+		Locale: evt.Locale,
+	}); err != nil {
+		return err
+	}
+	return s.Notify(ctx, &Notice{
+		EventID:     env.ID,
+		EventType:   env.Type,
+		UserID:      evt.UserID,
+		Category:    model.CategoryAccount,
+		TemplateKey: env.Type,
+		Variables: map[string]interface{}{
+			"name":       name,
+			"first_name": evt.FirstName,
+			"email":      evt.Email,
+		},
+		Channels: []model.Channel{model.ChannelEmail},
+	})
+}
+
+// handleUserUpdated 同步用户变更后的姓名、邮箱和手机号
+func (s *NotificationService) handleUserUpdated(ctx context.Context, env *events.Envelope) error {
+	var evt event.UserUpdatedEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	return s.preferenceRepo.SaveContactDetails(ctx, &model.Contact{
+		UserID: evt.UserID,
+		Name:   strings.TrimSpace(evt.FirstName + " " + evt.LastName),
+		Email:  evt.Email,
+		Phone:  evt.Phone,
+	})
+}
+
+// handleUserDeleted 删除注销用户的联系方式、通知偏好和推送设备，之后不再向其发送通知
+func (s *NotificationService) handleUserDeleted(ctx context.Context, env *events.Envelope) error {
+	var evt event.UserDeletedEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	return s.preferenceRepo.DeleteUser(ctx, evt.UserID)
+}
+
+// handleEmailVerification 向待验证的邮箱发送验证链接。验证邮件发送到事件中的邮箱而不是用户已保存的联系方式，
+// 不受用户偏好限制
+func (s *NotificationService) handleEmailVerification(ctx context.Context, env *events.Envelope) error {
+	var evt event.EmailVerificationEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	return s.NotifyEmail(ctx, &Notice{
+		EventID:     env.ID,
+		EventType:   env.Type,
+		UserID:      evt.UserID,
+		Category:    model.CategoryAccount,
+		TemplateKey: env.Type,
+		Variables: map[string]interface{}{
+			"name":       evt.Name,
+			"verify_url": evt.VerifyURL,
+			"expires_at": evt.ExpiresAt.Format("2006-01-02 15:04"),
+		},
+	}, evt.Email)
+}
+
+// handleAccountLocked 告知用户账号因多次密码错误被暂时锁定，邮件包含解锁链接。
+// 账号安全邮件不受用户偏好限制
+func (s *NotificationService) handleAccountLocked(ctx context.Context, env *events.Envelope) error {
+	var evt event.AccountLockedEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	return s.NotifyEmail(ctx, &Notice{
+		EventID:     env.ID,
+		EventType:   env.Type,
+		UserID:      evt.UserID,
+		Category:    model.CategoryAccount,
+		TemplateKey: env.Type,
+		Variables: map[string]interface{}{
+			"name":         evt.Name,
+			"ip":           evt.IP,
+			"unlock_url":   evt.UnlockURL,
+			"locked_until": evt.LockedUntil.Format("2006-01-02 15:04"),
+		},
+	}, evt.Email)
+}
+
+// handlePreferencesUpdated 同步用户偏好中的语言、营销邮件订阅和推送开关
+func (s *NotificationService) handlePreferencesUpdated(ctx context.Context, env *events.Envelope) error {
+	var evt event.PreferencesUpdatedEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	return s.preferenceRepo.SaveContactSettings(ctx, &model.Contact{
+		UserID:          evt.UserID,
+		Locale:          evt.Locale,
+		MarketingEmails: evt.MarketingEmails,
+		PushDisabled:    !evt.PushNotifications,
+	})
+}
+
+// handleCelebrationReward 向获得生日或注册周年奖励的用户发送祝福和奖励提醒，
+// 属于营销通知，邮件只发给订阅了营销邮件的用户
+func (s *NotificationService) handleCelebrationReward(ctx context.Context, env *events.Envelope) error {
+	var evt event.CelebrationRewardEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	variables := map[string]interface{}{
+		"first_name":    evt.FirstName,
+		"type":          evt.Type,
+		"campaign_name": evt.CampaignName,
+		"message":       evt.Message,
+	}
+	if evt.Years > 0 {
+		variables["years"] = evt.Years
+	}
+	if evt.CouponCode != "" {
+		variables["coupon_code"] = evt.CouponCode
+	}
+	if evt.CouponExpiresAt != nil {
+		variables["coupon_expires_at"] = evt.CouponExpiresAt.Format("2006-01-02")
+	}
+	if evt.Points > 0 {
+		variables["points"] = evt.Points
+	}
+	return s.Notify(ctx, &Notice{
+		EventID:     env.ID,
+		EventType:   env.Type,
+		UserID:      evt.UserID,
+		Category:    model.CategoryMarketing,
+		TemplateKey: env.Type,
+		Variables:   variables,
+		Channels:    []model.Channel{model.ChannelEmail, model.ChannelPush},
+	})
+}
+
+func (s *NotificationService) handleLowStock(ctx context.Context, env *events.Envelope) error {
+	var evt event.LowStockEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	return s.Alert(ctx, env.ID, env.Type, env.Type, map[string]interface{}{
+		"product_id":   evt.ProductID,
+		"product_name": evt.ProductName,
+		"sku":          evt.SKU,
+		"warehouse_id": evt.WarehouseID,
+		"available":    evt.Available,
+		"threshold":    evt.Threshold,
+	})
+}
+
+// handleTicket 向客户发送工单确认、客服回复和解决通知。邮件主题应包含 [ticket_number]，
+// 客户直接回复邮件即可回复工单；没有账户的客户按工单邮箱发送
+func (s *NotificationService) handleTicket(ctx context.Context, env *events.Envelope) error {
+	var evt event.TicketEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	notice := &Notice{
+		EventID:     env.ID,
+		EventType:   env.Type,
+		UserID:      evt.UserID,
+		Category:    model.CategorySupport,
+		TemplateKey: env.Type,
+		Variables: map[string]interface{}{
+			"ticket_number": evt.Number,
+			"subject":       evt.Subject,
+			"status":        evt.Status,
+			"message":       evt.Message,
+			"reply_address": evt.ReplyAddress,
+		},
+		Channels: []model.Channel{model.ChannelEmail, model.ChannelPush},
+	}
+	if evt.Name != "" {
+		notice.Variables["name"] = evt.Name
+	}
+	if evt.UserID == 0 {
+		return s.NotifyEmail(ctx, notice, evt.Email)
+	}
+	return s.Notify(ctx, notice)
+}
+
+func (s *NotificationService) handleSLABreached(ctx context.Context, env *events.Envelope) error {
+	var evt event.SLABreachedEvent
+	if err := decode(env, &evt); err != nil {
+		return err
+	}
+	variables := map[string]interface{}{
+		"ticket_number": evt.Number,
+		"subject":       evt.Subject,
+		"priority":      evt.Priority,
+		"sla":           evt.SLA,
+		"due_at":        evt.DueAt.Format("2006-01-02 15:04"),
+	}
+	if evt.AssigneeID != nil {
+		variables["assignee_id"] = *evt.AssigneeID
+	}
+	return s.Alert(ctx, env.ID, env.Type, env.Type, variables)
+}
+
+// decode 解析事件数据，无法解析的事件重试也不会成功，直接转入死信
+func decode(env *events.Envelope, v interface{}) error {
+	if err := env.Decode(v); err != nil {
+		return events.Permanent(err)
+	}
+	return nil
+}
+
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/notification/internal/client"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+	"github.com/yourusername/goshop/services/notification/internal/provider"
+	"github.com/yourusername/goshop/services/notification/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// sendLease 是领取一条通知后的发送时限，进程在发送中退出时通知会在到期后被重新取出
+	sendLease = 2 * time.Minute
+	// dueBatchSize 是每轮最多取出的到期通知数
+	dueBatchSize = 100
+	// maxErrorLength 是记录的最后一次错误的最大长度
+	maxErrorLength = 1000
+)
+
+// TemplateRenderer 定义消息模板渲染接口，由 CMS 服务实现
+type TemplateRenderer interface {
+	Render(ctx context.Context, key string, channel model.Channel, locale string, variables map[string]interface{}) (*client.RenderedMessage, error)
+}
+
+// Notice 表示由一个业务事件触发、发给一个用户的通知
+type Notice struct {
+	EventID     string
+	EventType   string
+	UserID      uint
+	Category    string
+	TemplateKey string
+	Variables   map[string]interface{}
+	Channels    []model.Channel // 尝试发送的渠道，用户关闭或缺少联系方式的渠道会被跳过
+}
+
+// NotificationList 表示分页的通知列表
+type NotificationList struct {
+	Items    []*model.Notification `json:"items"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+// DeliveryConfig 表示通知投递的重试设置
+type DeliveryConfig struct {
+	DefaultLocale string
+	AlertEmails   []string      // 运营告警的收件人
+	MaxAttempts   int           // 投递次数上限，用尽后标记为失败
+	RetryInterval time.Duration // 首次重试的间隔，之后每次失败翻倍
+}
+
+// NotificationService 负责按用户偏好生成通知、通过 CMS 渲染模板并经服务商投递，
+// 投递失败的通知按指数退避重试，每次投递的结果都记录在通知上
+type NotificationService struct {
+	notificationRepo repository.NotificationRepository
+	preferenceRepo   repository.PreferenceRepository
+	templates        TemplateRenderer
+	providers        *provider.Registry
+	cfg              DeliveryConfig
+	log              *logger.Logger
+}
+
+// NewNotificationService 创建通知服务
+func NewNotificationService(notificationRepo repository.NotificationRepository, preferenceRepo repository.PreferenceRepository, templates TemplateRenderer, providers *provider.Registry, cfg DeliveryConfig, log *logger.Logger) *NotificationService {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = time.Minute
+	}
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
+		templates:        templates,
+		providers:        providers,
+		cfg:              cfg,
+		log:              log,
+	}
+}
+
+// Notify 按用户的偏好和联系方式在各渠道生成通知并立即投递，投递失败的由 Run 重试。
+// 同一事件重复处理时不会重复生成通知
+func (s *NotificationService) Notify(ctx context.Context, notice *Notice) error {
+	contact, err := s.preferenceRepo.GetContact(ctx, notice.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if contact == nil {
+		contact = &model.Contact{UserID: notice.UserID}
+	}
+	preferences, err := s.preferenceRepo.ListPreferences(ctx, notice.UserID)
+	if err != nil {
+		return err
+	}
+	enabled := preferenceMap(preferences)
+	locale := contact.Locale
+	if locale == "" {
+		locale = s.cfg.DefaultLocale
+	}
+	variables := notice.Variables
+	if variables == nil {
+		variables = make(map[string]interface{})
+	}
+	if _, ok := variables["name"]; !ok && contact.Name != "" {
+		variables["name"] = contact.Name
+	}
+
+	for _, channel := range notice.Channels {
+		if !s.providers.Enabled(channel) || !isEnabled(enabled, notice.Category, channel) || !accepts(contact, notice.Category, channel) {
+			continue
+		}
+		recipients, err := s.recipients(ctx, contact, channel)
+		if err != nil {
+			return err
+		}
+		for _, recipient := range recipients {
+			if err := s.enqueue(ctx, &model.Notification{
+				EventID:     notice.EventID,
+				EventType:   notice.EventType,
+				UserID:      notice.UserID,
+				Category:    notice.Category,
+				Channel:     channel,
+				Recipient:   recipient,
+				TemplateKey: notice.TemplateKey,
+				Locale:      locale,
+				Variables:   variables,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// NotifyEmail 向没有账户的收件人发送邮件，只使用通知中的变量，不受用户偏好限制
+func (s *NotificationService) NotifyEmail(ctx context.Context, notice *Notice, email string) error {
+	if email == "" || !s.providers.Enabled(model.ChannelEmail) {
+		return nil
+	}
+	return s.enqueue(ctx, &model.Notification{
+		EventID:     notice.EventID,
+		EventType:   notice.EventType,
+		Category:    notice.Category,
+		Channel:     model.ChannelEmail,
+		Recipient:   email,
+		TemplateKey: notice.TemplateKey,
+		Locale:      s.cfg.DefaultLocale,
+		Variables:   notice.Variables,
+	})
+}
+
+// Alert 向配置的运营人员邮箱发送告警，不受用户偏好限制
+func (s *NotificationService) Alert(ctx context.Context, eventID, eventType, templateKey string, variables map[string]interface{}) error {
+	if !s.providers.Enabled(model.ChannelEmail) {
+		return nil
+	}
+	for _, email := range s.cfg.AlertEmails {
+		if err := s.enqueue(ctx, &model.Notification{
+			EventID:     eventID,
+			EventType:   eventType,
+			Category:    model.CategoryAlert,
+			Channel:     model.ChannelEmail,
+			Recipient:   email,
+			TemplateKey: templateKey,
+			Locale:      s.cfg.DefaultLocale,
+			Variables:   variables,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run 定期投递到期的通知，直到 ctx 结束
+func (s *NotificationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *NotificationService) tick(ctx context.Context, now time.Time) {
+	due, err := s.notificationRepo.ListDue(ctx, now, dueBatchSize)
+	if err != nil {
+		s.log.Error(ctx, "Failed to list due notifications", zap.Error(err))
+		return
+	}
+	for _, n := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		claimed, err := s.notificationRepo.Claim(ctx, n.ID, now, now.Add(sendLease))
+		if err != nil {
+			s.log.Error(ctx, "Failed to claim notification", zap.Uint("notification_id", n.ID), zap.Error(err))
+			continue
+		}
+		if claimed {
+			s.deliver(ctx, n)
+		}
+	}
+}
+
+// List 分页查询通知的投递记录
+func (s *NotificationService) List(ctx context.Context, filter repository.NotificationFilter, page, pageSize int) (*NotificationList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	items, total, err := s.notificationRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取通知失败", err)
+	}
+	return &NotificationList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Get 获取通知的投递记录
+func (s *NotificationService) Get(ctx context.Context, id uint) (*model.Notification, error) {
+	n, err := s.notificationRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("通知 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取通知失败", err)
+	}
+	return n, nil
+}
+
+// Retry 重新投递失败的通知，投递次数从零开始计算
+func (s *NotificationService) Retry(ctx context.Context, id uint) (*model.Notification, error) {
+	n, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if n.Status != model.StatusFailed {
+		return nil, apperrors.NewBadRequest("只能重新投递失败的通知", nil)
+	}
+	n.Status = model.StatusPending
+	n.Attempts = 0
+	n.LastError = ""
+	n.NextAttemptAt = time.Now().Add(sendLease)
+	if err := s.notificationRepo.Update(ctx, n); err != nil {
+		return nil, apperrors.NewInternalServerError("重新投递通知失败", err)
+	}
+	s.deliver(ctx, n)
+	return n, nil
+}
+
+// enqueue 保存通知并立即投递。保存时即领取通知，避免与 Run 同时投递
+func (s *NotificationService) enqueue(ctx context.Context, n *model.Notification) error {
+	n.Status = model.StatusPending
+	n.NextAttemptAt = time.Now().Add(sendLease)
+	created, err := s.notificationRepo.Create(ctx, n)
+	if err != nil {
+		return err
+	}
+	if created {
+		s.deliver(ctx, n)
+	}
+	return nil
+}
+
+// deliver 渲染并发送一条已领取的通知，保存投递结果
+func (s *NotificationService) deliver(ctx context.Context, n *model.Notification) {
+	n.Attempts++
+	result, p, err := s.send(ctx, n)
+	now := time.Now()
+	switch {
+	case err == nil:
+		n.Status = model.StatusSent
+		n.Provider = p.Name()
+		n.ProviderMessageID = result.MessageID
+		n.LastError = ""
+		n.SentAt = &now
+	case isPermanent(err) || n.Attempts >= s.cfg.MaxAttempts:
+		n.Status = model.StatusFailed
+		n.LastError = truncate(err.Error(), maxErrorLength)
+		if p != nil {
+			n.Provider = p.Name()
+		}
+		s.log.Warn(ctx, "Notification delivery failed",
+			zap.Uint("notification_id", n.ID),
+			zap.String("channel", string(n.Channel)),
+			zap.Int("attempts", n.Attempts),
+			zap.Error(err),
+		)
+		if n.Channel == model.ChannelPush && errors.Is(err, provider.ErrInvalidRecipient) {
+			if err := s.preferenceRepo.DeleteDeviceByToken(ctx, n.Recipient); err != nil {
+				s.log.Warn(ctx, "Failed to delete invalid push token", zap.Uint("notification_id", n.ID), zap.Error(err))
+			}
+		}
+	default:
+		n.LastError = truncate(err.Error(), maxErrorLength)
+		n.NextAttemptAt = now.Add(s.cfg.RetryInterval << (n.Attempts - 1))
+		s.log.Info(ctx, "Notification delivery will be retried",
+			zap.Uint("notification_id", n.ID),
+			zap.Int("attempts", n.Attempts),
+			zap.Time("next_attempt_at", n.NextAttemptAt),
+			zap.Error(err),
+		)
+	}
+	if err := s.notificationRepo.Update(ctx, n); err != nil {
+		s.log.Error(ctx, "Failed to save notification delivery", zap.Uint("notification_id", n.ID), zap.Error(err))
+	}
+}
+
+// send 渲染尚未渲染的通知并交给渠道的服务商发送
+func (s *NotificationService) send(ctx context.Context, n *model.Notification) (*provider.Result, provider.Provider, error) {
+	p, err := s.providers.Get(n.Channel)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n.Body == "" {
+		message, err := s.templates.Render(ctx, n.TemplateKey, n.Channel, n.Locale, n.Variables)
+		if err != nil {
+			return nil, p, fmt.Errorf("render template %s: %w", n.TemplateKey, err)
+		}
+		n.Subject = message.Subject
+		n.Body = message.Body
+	}
+	result, err := p.Send(ctx, &provider.Message{
+		To:          n.Recipient,
+		Subject:     n.Subject,
+		Body:        n.Body,
+		TemplateKey: n.TemplateKey,
+		Variables:   n.Variables,
+	})
+	return result, p, err
+}
+
+// recipients 返回用户在渠道上的接收方：邮箱、手机号或全部推送设备
+func (s *NotificationService) recipients(ctx context.Context, contact *model.Contact, channel model.Channel) ([]string, error) {
+	switch channel {
+	case model.ChannelEmail:
+		if contact.Email != "" {
+			return []string{contact.Email}, nil
+		}
+	case model.ChannelSMS:
+		if contact.Phone != "" {
+			return []string{contact.Phone}, nil
+		}
+	case model.ChannelPush:
+		devices, err := s.preferenceRepo.ListDevices(ctx, contact.UserID)
+		if err != nil {
+			return nil, err
+		}
+		tokens := make([]string, 0, len(devices))
+		for _, d := range devices {
+			tokens = append(tokens, d.Token)
+		}
+		return tokens, nil
+	}
+	return nil, nil
+}
+
+// isPermanent 判断投递错误是否重试也无法成功
+func isPermanent(err error) bool {
+	return errors.Is(err, provider.ErrRejected) ||
+		errors.Is(err, provider.ErrProviderNotFound) ||
+		errors.Is(err, client.ErrTemplateNotFound) ||
+		errors.Is(err, client.ErrInvalidVariables)
+}
+
+// truncate 截断到 n 个字符
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// formatAmount 按两位小数格式化金额
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/notification/internal/model"
+	"github.com/yourusername/goshop/services/notification/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PreferenceItem 表示某类通知在某个渠道上是否接收
+type PreferenceItem struct {
+	Category string        `json:"category" binding:"required,oneof=order shipping account support marketing"`
+	Channel  model.Channel `json:"channel" binding:"required,oneof=email sms push"`
+	Enabled  bool          `json:"enabled"`
+}
+
+// UpdatePreferencesRequest 表示更新通知偏好的请求，未列出的类别和渠道保持不变
+type UpdatePreferencesRequest struct {
+	Items []PreferenceItem `json:"items" binding:"required,min=1,dive"`
+}
+
+// ContactRequest 表示更新联系方式的请求
+type ContactRequest struct {
+	Name   string `json:"name" binding:"max=100"`
+	Email  string `json:"email" binding:"omitempty,email,max=255"`
+	Phone  string `json:"phone" binding:"omitempty,max=20"`
+	Locale string `json:"locale" binding:"max=10"`
+}
+
+// DeviceRequest 表示登记推送设备的请求
+type DeviceRequest struct {
+	Token    string `json:"token" binding:"required,max=255"`
+	Platform string `json:"platform" binding:"required,oneof=ios android web"`
+}
+
+// PreferenceService 负责用户的通知偏好、联系方式和推送设备
+type PreferenceService struct {
+	preferenceRepo repository.PreferenceRepository
+}
+
+// NewPreferenceService 创建通知偏好服务
+func NewPreferenceService(preferenceRepo repository.PreferenceRepository) *PreferenceService {
+	return &PreferenceService{
+		preferenceRepo: preferenceRepo,
+	}
+}
+
+// GetPreferences 返回用户每类通知在每个渠道上的接收设置，没有设置过的默认接收
+func (s *PreferenceService) GetPreferences(ctx context.Context, userID uint) ([]PreferenceItem, error) {
+	preferences, err := s.preferenceRepo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取通知偏好失败", err)
+	}
+	enabled := preferenceMap(preferences)
+	items := make([]PreferenceItem, 0, len(model.Categories)*len(model.Channels))
+	for _, category := range model.Categories {
+		for _, channel := range model.Channels {
+			items = append(items, PreferenceItem{
+				Category: category,
+				Channel:  channel,
+				Enabled:  isEnabled(enabled, category, channel),
+			})
+		}
+	}
+	return items, nil
+}
+
+// UpdatePreferences 更新用户的通知偏好
+func (s *PreferenceService) UpdatePreferences(ctx context.Context, userID uint, req *UpdatePreferencesRequest) ([]PreferenceItem, error) {
+	now := time.Now()
+	preferences := make([]*model.Preference, 0, len(req.Items))
+	for _, item := range req.Items {
+		preferences = append(preferences, &model.Preference{
+			UserID:    userID,
+			Category:  item.Category,
+			Channel:   item.Channel,
+			Enabled:   item.Enabled,
+			UpdatedAt: now,
+		})
+	}
+	if err := s.preferenceRepo.SavePreferences(ctx, preferences); err != nil {
+		return nil, apperrors.NewInternalServerError("保存通知偏好失败", err)
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+// GetContact 获取用户的联系方式，没有记录时返回空的联系方式
+func (s *PreferenceService) GetContact(ctx context.Context, userID uint) (*model.Contact, error) {
+	contact, err := s.preferenceRepo.GetContact(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.Contact{UserID: userID}, nil
+		}
+		return nil, apperrors.NewInternalServerError("获取联系方式失败", err)
+	}
+	return contact, nil
+}
+
+// UpdateContact 更新用户接收通知的联系方式
+func (s *PreferenceService) UpdateContact(ctx context.Context, userID uint, req *ContactRequest) (*model.Contact, error) {
+	contact := &model.Contact{
+		UserID: userID,
+		Name:   req.Name,
+		Email:  req.Email,
+		Phone:  req.Phone,
+		Locale: req.Locale,
+	}
+	if err := s.preferenceRepo.SaveContact(ctx, contact); err != nil {
+		return nil, apperrors.NewInternalServerError("保存联系方式失败", err)
+	}
+	return contact, nil
+}
+
+// ListDevices 获取用户登记的推送设备
+func (s *PreferenceService) ListDevices(ctx context.Context, userID uint) ([]*model.Device, error) {
+	devices, err := s.preferenceRepo.ListDevices(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取推送设备失败", err)
+	}
+	return devices, nil
+}
+
+// RegisterDevice 登记推送设备，App 每次启动时调用以刷新令牌
+func (s *PreferenceService) RegisterDevice(ctx context.Context, userID uint, req *DeviceRequest) (*model.Device, error) {
+	device := &model.Device{
+		UserID:     userID,
+		Token:      req.Token,
+		Platform:   req.Platform,
+		LastSeenAt: time.Now(),
+	}
+	if err := s.preferenceRepo.SaveDevice(ctx, device); err != nil {
+		return nil, apperrors.NewInternalServerError("登记推送设备失败", err)
+	}
+	return device, nil
+}
+
+// RemoveDevice 删除推送设备，用户退出登录时调用
+func (s *PreferenceService) RemoveDevice(ctx context.Context, userID uint, token string) error {
+	deleted, err := s.preferenceRepo.DeleteDevice(ctx, userID, token)
+	if err != nil {
+		return apperrors.NewInternalServerError("删除推送设备失败", err)
+	}
+	if !deleted {
+		return apperrors.NewNotFound("推送设备不存在", nil)
+	}
+	return nil
+}
+
+// preferenceMap 按类别和渠道索引用户设置过的偏好
+func preferenceMap(preferences []*model.Preference) map[string]bool {
+	enabled := make(map[string]bool, len(preferences))
+	for _, p := range preferences {
+		enabled[p.Category+"/"+string(p.Channel)] = p.Enabled
+	}
+	return enabled
+}
+
+// isEnabled 判断用户是否接收某类通知，没有设置过的默认接收
+func isEnabled(enabled map[string]bool, category string, channel model.Channel) bool {
+	v, ok := enabled[category+"/"+string(channel)]
+	return !ok || v
+}
+
+// accepts 按用户偏好判断是否发送：关闭了推送的用户不发送推送，营销邮件只发给订阅了的用户
+func accepts(contact *model.Contact, category string, channel model.Channel) bool {
+	switch {
+	case channel == model.ChannelPush && contact.PushDisabled:
+		return false
+	case channel == model.ChannelEmail && category == model.CategoryMarketing:
+		return contact.MarketingEmails
+	}
+	return true
+}
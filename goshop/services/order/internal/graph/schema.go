@@ -0,0 +1,225 @@
+// Package graph 将订单和购物车作为联邦 GraphQL 子图提供给网关。订单项和购物车项引用商品子图的 Product，
+// 并为用户子图的 User 扩展 orders 字段
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/graphql"
+	"github.com/yourusername/goshop/services/order/internal/model"
+	"github.com/yourusername/goshop/services/order/internal/service"
+)
+
+// NewSchema 创建订单子图，所有查询都只返回当前用户自己的订单和购物车
+func NewSchema(orderService *service.OrderService, cartService *service.CartService) (*graphql.Schema, error) {
+	// 商品和用户由其他子图解析，这里只返回它们的 ID
+	product := &graphql.Object{
+		Name:    "Product",
+		Key:     "id",
+		Extends: true,
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID), External: true},
+		},
+	}
+	user := &graphql.Object{
+		Name:    "User",
+		Key:     "id",
+		Extends: true,
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID), External: true},
+		},
+		ResolveEntities: func(ctx context.Context, representations []map[string]interface{}) ([]interface{}, error) {
+			result := make([]interface{}, len(representations))
+			for i, id := range graphql.RepresentationIDs(representations, "id") {
+				if id != 0 {
+					result[i] = reference(id)
+				}
+			}
+			return result, nil
+		},
+	}
+
+	address := &graphql.Object{
+		Name: "OrderAddress",
+		Fields: []*graphql.Field{
+			{Name: "name", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "phone", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "province", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "city", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "district", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "detailedInfo", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "postalCode", Type: graphql.NonNullOf(graphql.String)},
+		},
+	}
+	item := &graphql.Object{
+		Name: "OrderItem",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID)},
+			{
+				Name: "product",
+				Type: graphql.NonNullOf(product),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return reference(p.Source.(model.OrderItem).ProductID), nil
+				},
+			},
+			{Name: "productName", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "skuCode", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "variantName", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "image", Type: graphql.String},
+			{Name: "price", Type: graphql.NonNullOf(graphql.Float)},
+			{Name: "quantity", Type: graphql.NonNullOf(graphql.Int)},
+			{Name: "discount", Type: graphql.NonNullOf(graphql.Float)},
+			{Name: "total", Type: graphql.NonNullOf(graphql.Float)},
+		},
+	}
+	order := &graphql.Object{
+		Name: "Order",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID)},
+			{Name: "orderNumber", Type: graphql.NonNullOf(graphql.String)},
+			{
+				Name: "user",
+				Type: graphql.NonNullOf(user),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return reference(p.Source.(*model.Order).UserID), nil
+				},
+			},
+			{Name: "status", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "paymentStatus", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "items", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(item)))},
+			{Name: "shippingAddress", Type: graphql.NonNullOf(address)},
+			{Name: "shippingMethod", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "trackingNumber", Type: graphql.String},
+			{Name: "subtotal", Type: graphql.NonNullOf(graphql.Float)},
+			{Name: "shippingFee", Type: graphql.NonNullOf(graphql.Float)},
+			{Name: "tax", Type: graphql.NonNullOf(graphql.Float)},
+			{Name: "discount", Type: graphql.NonNullOf(graphql.Float)},
+			{Name: "grandTotal", Type: graphql.NonNullOf(graphql.Float)},
+			{Name: "paidAt", Type: graphql.String},
+			{Name: "shippedAt", Type: graphql.String},
+			{Name: "createdAt", Type: graphql.NonNullOf(graphql.String)},
+		},
+	}
+	orderList := &graphql.Object{
+		Name: "OrderList",
+		Fields: []*graphql.Field{
+			{Name: "items", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(order)))},
+			{Name: "total", Type: graphql.NonNullOf(graphql.Int)},
+			{Name: "page", Type: graphql.NonNullOf(graphql.Int)},
+			{Name: "pageSize", Type: graphql.NonNullOf(graphql.Int)},
+		},
+	}
+	cartItem := &graphql.Object{
+		Name: "CartItem",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID)},
+			{
+				Name: "product",
+				Type: graphql.NonNullOf(product),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return reference(p.Source.(model.CartItem).ProductID), nil
+				},
+			},
+			{Name: "skuId", Type: graphql.NonNullOf(graphql.ID)},
+			{Name: "quantity", Type: graphql.NonNullOf(graphql.Int)},
+			{Name: "createdAt", Type: graphql.NonNullOf(graphql.String)},
+		},
+	}
+	cart := &graphql.Object{
+		Name: "Cart",
+		Fields: []*graphql.Field{
+			{Name: "items", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(cartItem)))},
+			{
+				Name: "totalQuantity",
+				Type: graphql.NonNullOf(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					total := 0
+					for _, item := range p.Source.(*model.Cart).Items {
+						total += item.Quantity
+					}
+					return total, nil
+				},
+			},
+		},
+	}
+	pageArgs := []*graphql.Arg{
+		{Name: "page", Type: graphql.Int, Default: 1},
+		{Name: "pageSize", Type: graphql.Int, Default: 20},
+	}
+
+	// 用户的订单只对用户本人可见
+	user.Fields = append(user.Fields, &graphql.Field{
+		Name: "orders",
+		Type: graphql.NonNullOf(orderList),
+		Args: pageArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			userID := p.Source.(map[string]interface{})["id"].(uint)
+			if userID != currentUserID(p.Context) {
+				return nil, apperrors.NewForbidden("无权查看该用户的订单", nil)
+			}
+			return orderService.ListUserOrders(p.Context, userID, p.Args["page"].(int), p.Args["pageSize"].(int))
+		},
+	})
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{
+				Name: "order",
+				Type: order,
+				Args: []*graphql.Arg{{Name: "id", Type: graphql.NonNullOf(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := currentUserID(p.Context)
+					if userID == 0 {
+						return nil, apperrors.NewUnauthorized("请先登录", nil)
+					}
+					id, err := strconv.ParseUint(p.Args["id"].(string), 10, 64)
+					if err != nil {
+						return nil, apperrors.NewBadRequest("无效的订单 ID", err)
+					}
+					return orderService.GetUserOrder(p.Context, userID, uint(id))
+				},
+			},
+			{
+				Name: "myOrders",
+				Type: graphql.NonNullOf(orderList),
+				Args: pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := currentUserID(p.Context)
+					if userID == 0 {
+						return nil, apperrors.NewUnauthorized("请先登录", nil)
+					}
+					return orderService.ListUserOrders(p.Context, userID, p.Args["page"].(int), p.Args["pageSize"].(int))
+				},
+			},
+			{
+				Name: "cart",
+				Type: graphql.NonNullOf(cart),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := currentUserID(p.Context)
+					if userID == 0 {
+						return nil, apperrors.NewUnauthorized("请先登录", nil)
+					}
+					return cartService.GetUserCart(p.Context, userID)
+				},
+			},
+		},
+	}
+	return graphql.NewSubgraph(query, user)
+}
+
+// reference 返回由其他子图解析的实体的引用
+func reference(id uint) map[string]interface{} {
+	return map[string]interface{}{"id": id}
+}
+
+// currentUserID 返回网关认证后通过 X-User-ID 转发的用户 ID，未登录时为 0
+func currentUserID(ctx context.Context) uint {
+	id, err := strconv.ParseUint(graphql.Header(ctx).Get("X-User-ID"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/order/internal/model"
+	"github.com/yourusername/goshop/services/order/internal/service"
+	"github.com/yourusername/goshop/services/order/rpc"
+)
+
+// GRPCHandler 实现订单服务的 gRPC 接口，网关将订单查询接口转码为 gRPC 调用
+type GRPCHandler struct {
+	orderService *service.OrderService
+}
+
+// NewGRPCHandler 创建 gRPC 处理器
+func NewGRPCHandler(orderService *service.OrderService) *GRPCHandler {
+	return &GRPCHandler{
+		orderService: orderService,
+	}
+}
+
+// GetOrder 获取用户的订单
+func (h *GRPCHandler) GetOrder(ctx context.Context, req *rpc.GetOrderRequest) (*model.Order, error) {
+	return h.orderService.GetUserOrder(ctx, req.UserID, req.ID)
+}
+
+// ListOrders 分页获取用户的订单
+func (h *GRPCHandler) ListOrders(ctx context.Context, req *rpc.ListOrdersRequest) (*rpc.OrderList, error) {
+	list, err := h.orderService.ListUserOrders(ctx, req.UserID, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.OrderList{
+		Items:    list.Items,
+		Total:    list.Total,
+		Page:     list.Page,
+		PageSize: list.PageSize,
+	}, nil
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"time"
 
+	"github.com/yourusername/goshop/pkg/currency"
 	"gorm.io/gorm"
 )
 
@@ -64,11 +65,11 @@ type Order struct {
 	CouponCode      *string        `json:"coupon_code" gorm:"size:50"`                                // 优惠券码
 	ShippingAddress Address        `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"` // 收货地址
 	BillingAddress  Address        `json:"billing_address" gorm:"embedded;embeddedPrefix:billing_"`   // 账单地址
-	Subtotal        float64        `json:"subtotal" gorm:"type:decimal(10,2);not null"`               // 小计（未含税、运费）
-	ShippingFee     float64        `json:"shipping_fee" gorm:"type:decimal(10,2);not null"`           // 运费
-	Tax             float64        `json:"tax" gorm:"type:decimal(10,2);not null"`                    // 税费
-	Discount        float64        `json:"discount" gorm:"type:decimal(10,2);not null"`               // 优惠金额
-	GrandTotal      float64        `json:"grand_total" gorm:"type:decimal(10,2);not null"`            // 总计
+	Subtotal        currency.Money `json:"subtotal" gorm:"type:decimal(10,2);not null"`               // 小计（未含税、运费）
+	ShippingFee     currency.Money `json:"shipping_fee" gorm:"type:decimal(10,2);not null"`           // 运费
+	Tax             currency.Money `json:"tax" gorm:"type:decimal(10,2);not null"`                    // 税费
+	Discount        currency.Money `json:"discount" gorm:"type:decimal(10,2);not null"`               // 优惠金额
+	GrandTotal      currency.Money `json:"grand_total" gorm:"type:decimal(10,2);not null"`            // 总计
 	Note            *string        `json:"note" gorm:"type:text"`                                     // 订单备注
 	CustomerNote    *string        `json:"customer_note" gorm:"type:text"`                            // 客户备注
 	InternalNote    *string        `json:"internal_note" gorm:"type:text"`                            // 内部备注
@@ -86,24 +87,24 @@ type Order struct {
 
 // OrderItem 表示订单项
 type OrderItem struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	OrderID       uint      `json:"order_id" gorm:"index;not null"`
-	ProductID     uint      `json:"product_id" gorm:"index;not null"`
-	SKUID         uint      `json:"sku_id" gorm:"index;not null"`
-	ProductName   string    `json:"product_name" gorm:"size:255;not null"`
-	SKUCode       string    `json:"sku_code" gorm:"size:50;not null"`
-	VariantName   string    `json:"variant_name" gorm:"size:255"`
-	Price         float64   `json:"price" gorm:"type:decimal(10,2);not null"`    // 单价
-	OriginalPrice float64   `json:"original_price" gorm:"type:decimal(10,2)"`    // 原价
-	Quantity      int       `json:"quantity" gorm:"not null"`                    // 数量
-	Subtotal      float64   `json:"subtotal" gorm:"type:decimal(10,2);not null"` // 小计
-	Tax           float64   `json:"tax" gorm:"type:decimal(10,2);not null"`      // 税费
-	Discount      float64   `json:"discount" gorm:"type:decimal(10,2);not null"` // 折扣
-	Total         float64   `json:"total" gorm:"type:decimal(10,2);not null"`    // 总计
-	Weight        *float64  `json:"weight" gorm:"type:decimal(10,2)"`            // 重量
-	Image         *string   `json:"image" gorm:"size:255"`                       // 图片
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	OrderID       uint           `json:"order_id" gorm:"index;not null"`
+	ProductID     uint           `json:"product_id" gorm:"index;not null"`
+	SKUID         uint           `json:"sku_id" gorm:"index;not null"`
+	ProductName   string         `json:"product_name" gorm:"size:255;not null"`
+	SKUCode       string         `json:"sku_code" gorm:"size:50;not null"`
+	VariantName   string         `json:"variant_name" gorm:"size:255"`
+	Price         currency.Money `json:"price" gorm:"type:decimal(10,2);not null"`    // 单价
+	OriginalPrice currency.Money `json:"original_price" gorm:"type:decimal(10,2)"`    // 原价
+	Quantity      int            `json:"quantity" gorm:"not null"`                    // 数量
+	Subtotal      currency.Money `json:"subtotal" gorm:"type:decimal(10,2);not null"` // 小计
+	Tax           currency.Money `json:"tax" gorm:"type:decimal(10,2);not null"`      // 税费
+	Discount      currency.Money `json:"discount" gorm:"type:decimal(10,2);not null"` // 折扣
+	Total         currency.Money `json:"total" gorm:"type:decimal(10,2);not null"`    // 总计
+	Weight        *float64       `json:"weight" gorm:"type:decimal(10,2)"`            // 重量
+	Image         *string        `json:"image" gorm:"size:255"`                       // 图片
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
 }
 
 // Address 表示地址
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/order/internal/model"
+	"gorm.io/gorm"
+)
+
+// CartRepository 定义购物车仓库接口
+type CartRepository interface {
+	GetByUser(ctx context.Context, userID uint) (*model.Cart, error)
+}
+
+// GormCartRepository 实现 CartRepository 接口的 GORM 仓库
+type GormCartRepository struct {
+	db *gorm.DB
+}
+
+// NewCartRepository 创建购物车仓库实例
+func NewCartRepository(db *gorm.DB) CartRepository {
+	return &GormCartRepository{
+		db: db,
+	}
+}
+
+// GetByUser 获取用户的购物车及其商品，按加入顺序排列，不存在时返回 gorm.ErrRecordNotFound
+func (r *GormCartRepository) GetByUser(ctx context.Context, userID uint) (*model.Cart, error) {
+	var cart model.Cart
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		First(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/order/internal/model"
+	"gorm.io/gorm"
+)
+
+// OrderRepository 定义订单仓库接口
+type OrderRepository interface {
+	GetByID(ctx context.Context, id uint) (*model.Order, error)
+	ListByUser(ctx context.Context, userID uint, offset, limit int) ([]*model.Order, int64, error)
+}
+
+// GormOrderRepository 实现 OrderRepository 接口的 GORM 仓库
+type GormOrderRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderRepository 创建订单仓库实例
+func NewOrderRepository(db *gorm.DB) OrderRepository {
+	return &GormOrderRepository{
+		db: db,
+	}
+}
+
+// GetByID 根据 ID 获取订单及其订单项
+func (r *GormOrderRepository) GetByID(ctx context.Context, id uint) (*model.Order, error) {
+	var order model.Order
+	err := r.db.WithContext(ctx).Preload("Items").First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ListByUser 分页获取用户的订单，最新的在前
+func (r *GormOrderRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]*model.Order, int64, error) {
+	var orders []*model.Order
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&model.Order{}).Where("user_id = ?", userID)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Preload("Items").Order("id DESC").Offset(offset).Limit(limit).Find(&orders).Error
+	return orders, total, err
+}
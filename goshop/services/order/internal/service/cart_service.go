@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/order/internal/model"
+	"github.com/yourusername/goshop/services/order/internal/repository"
+	"gorm.io/gorm"
+)
+
+// CartService 提供顾客的购物车查询
+type CartService struct {
+	cartRepo repository.CartRepository
+}
+
+// NewCartService 创建购物车服务
+func NewCartService(cartRepo repository.CartRepository) *CartService {
+	return &CartService{
+		cartRepo: cartRepo,
+	}
+}
+
+// GetUserCart 获取用户的购物车，用户还没有购物车时返回空购物车
+func (s *CartService) GetUserCart(ctx context.Context, userID uint) (*model.Cart, error) {
+	cart, err := s.cartRepo.GetByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.Cart{UserID: &userID, Items: []model.CartItem{}}, nil
+		}
+		return nil, apperrors.NewInternalServerError("获取购物车失败", err)
+	}
+	return cart, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/order/internal/model"
+	"github.com/yourusername/goshop/services/order/internal/repository"
+	"gorm.io/gorm"
+)
+
+// OrderList 表示分页的订单列表
+type OrderList struct {
+	Items    []*model.Order `json:"items"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
+
+// OrderService 提供顾客的订单查询，顾客只能查看自己的订单
+type OrderService struct {
+	orderRepo repository.OrderRepository
+}
+
+// NewOrderService 创建订单服务
+func NewOrderService(orderRepo repository.OrderRepository) *OrderService {
+	return &OrderService{
+		orderRepo: orderRepo,
+	}
+}
+
+// GetUserOrder 获取用户的订单，其他用户的订单视为不存在
+func (s *OrderService) GetUserOrder(ctx context.Context, userID, id uint) (*model.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("订单不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取订单失败", err)
+	}
+	if order.UserID != userID {
+		return nil, apperrors.NewNotFound("订单不存在", nil)
+	}
+	return order, nil
+}
+
+// ListUserOrders 分页获取用户的订单
+func (s *OrderService) ListUserOrders(ctx context.Context, userID uint, page, pageSize int) (*OrderList, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	orders, total, err := s.orderRepo.ListByUser(ctx, userID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取订单列表失败", err)
+	}
+	return &OrderList{Items: orders, Total: total, Page: page, PageSize: pageSize}, nil
+}
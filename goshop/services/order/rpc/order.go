@@ -0,0 +1,125 @@
+// Package rpc defines the gRPC API of the order service, shared by the service
+// and its clients. Messages are plain Go structs encoded with the JSON codec of
+// pkg/grpcclient rather than generated protobuf types. Request fields carry uri
+// and form tags mapping them to the path and query parameters of the gateway
+// routes transcoded to the methods.
+package rpc
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/services/order/internal/model"
+	"google.golang.org/grpc"
+)
+
+// Full method names of the order service
+const (
+	ServiceName      = "order.OrderService"
+	GetOrderMethod   = "/" + ServiceName + "/GetOrder"
+	ListOrdersMethod = "/" + ServiceName + "/ListOrders"
+)
+
+// GetOrderRequest requests an order of a user, orders of other users are
+// reported not found
+type GetOrderRequest struct {
+	UserID uint `json:"user_id"`
+	ID     uint `json:"id" uri:"id" binding:"required"`
+}
+
+// SetUserID sets the user the order must belong to
+func (r *GetOrderRequest) SetUserID(id uint) {
+	r.UserID = id
+}
+
+// ListOrdersRequest requests a page of the orders of a user
+type ListOrdersRequest struct {
+	UserID   uint `json:"user_id"`
+	Page     int  `json:"page" form:"page"`
+	PageSize int  `json:"page_size" form:"page_size"`
+}
+
+// SetUserID sets the user whose orders are listed
+func (r *ListOrdersRequest) SetUserID(id uint) {
+	r.UserID = id
+}
+
+// OrderList is a page of orders
+type OrderList struct {
+	Items    []*model.Order `json:"items"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
+
+// OrderServer is the server API of the order service
+type OrderServer interface {
+	GetOrder(ctx context.Context, req *GetOrderRequest) (*model.Order, error)
+	ListOrders(ctx context.Context, req *ListOrdersRequest) (*OrderList, error)
+}
+
+// serviceDesc describes the order service to the gRPC server
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*OrderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("GetOrder", GetOrderMethod, OrderServer.GetOrder),
+		unary("ListOrders", ListOrdersMethod, OrderServer.ListOrders),
+	},
+}
+
+// RegisterOrderServer registers srv with the gRPC server s
+func RegisterOrderServer(s grpc.ServiceRegistrar, srv OrderServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// unary describes a unary method calling fn on the server, through the
+// interceptors of the server when it has some
+func unary[Req, Reply any](name, fullMethod string, fn func(OrderServer, context.Context, *Req) (*Reply, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(Req)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return fn(srv.(OrderServer), ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return fn(srv.(OrderServer), ctx, req.(*Req))
+			}
+			return interceptor(ctx, in, info, handler)
+		},
+	}
+}
+
+// Client calls the order service
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient creates a client of the order service on conn, usually obtained
+// from grpcclient.Factory.Conn("order")
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// GetOrder returns an order of a user
+func (c *Client) GetOrder(ctx context.Context, req *GetOrderRequest) (*model.Order, error) {
+	out := new(model.Order)
+	if err := c.conn.Invoke(ctx, GetOrderMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListOrders returns a page of the orders of a user
+func (c *Client) ListOrders(ctx context.Context, req *ListOrdersRequest) (*OrderList, error) {
+	out := new(OrderList)
+	if err := c.conn.Invoke(ctx, ListOrdersMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
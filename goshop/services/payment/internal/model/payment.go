@@ -6,6 +6,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/yourusername/goshop/pkg/currency"
 	"gorm.io/gorm"
 )
 
@@ -75,8 +76,8 @@ type Payment struct {
 	OrderNumber       string         `json:"order_number" gorm:"size:50;index;not null"`
 	UserID            uint           `json:"user_id" gorm:"index"`
 	PaymentMethod     PaymentMethod  `json:"payment_method" gorm:"size:20;not null"`
-	Amount            float64        `json:"amount" gorm:"type:decimal(10,2);not null"`
-	Currency          string         `json:"currency" gorm:"size:3;not null;default:'CNY'"`
+	Amount            currency.Money `json:"amount" gorm:"type:decimal(10,2);not null"`
+	Currency          string         `json:"currency" gorm:"size:3;not null;default:'CNY'"` // 与 Amount 的货币一致，保存时设置
 	Status            PaymentStatus  `json:"status" gorm:"size:20;not null;default:'pending'"`
 	TransactionID     *string        `json:"transaction_id" gorm:"size:100;index"` // 支付平台的交易ID
 	PaymentGatewayRef *string        `json:"payment_gateway_ref" gorm:"size:100"`  // 支付网关的引用ID
@@ -92,14 +93,26 @@ type Payment struct {
 	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// BeforeSave 使 Currency 与 Amount 的货币一致
+func (p *Payment) BeforeSave(tx *gorm.DB) error {
+	p.Currency = string(p.Amount.Currency())
+	return nil
+}
+
+// AfterFind 按 Currency 恢复 Amount 的货币，金额列只保存数值
+func (p *Payment) AfterFind(tx *gorm.DB) (err error) {
+	p.Amount, err = p.Amount.WithCurrency(currency.Code(p.Currency))
+	return err
+}
+
 // Refund 退款记录
 type Refund struct {
 	ID            uint           `json:"id" gorm:"primaryKey"`
 	PaymentID     uint           `json:"payment_id" gorm:"index;not null"`
 	OrderID       uint           `json:"order_id" gorm:"index;not null"`
 	UserID        uint           `json:"user_id" gorm:"index"`
-	Amount        float64        `json:"amount" gorm:"type:decimal(10,2);not null"`
-	Currency      string         `json:"currency" gorm:"size:3;not null;default:'CNY'"`
+	Amount        currency.Money `json:"amount" gorm:"type:decimal(10,2);not null"`
+	Currency      string         `json:"currency" gorm:"size:3;not null;default:'CNY'"` // 与 Amount 的货币一致，保存时设置
 	Reason        string         `json:"reason" gorm:"size:255"`
 	Status        PaymentStatus  `json:"status" gorm:"size:20;not null;default:'processing'"`
 	TransactionID *string        `json:"transaction_id" gorm:"size:100;index"` // 退款交易ID
@@ -112,6 +125,18 @@ type Refund struct {
 	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// BeforeSave 使 Currency 与 Amount 的货币一致
+func (r *Refund) BeforeSave(tx *gorm.DB) error {
+	r.Currency = string(r.Amount.Currency())
+	return nil
+}
+
+// AfterFind 按 Currency 恢复 Amount 的货币，金额列只保存数值
+func (r *Refund) AfterFind(tx *gorm.DB) (err error) {
+	r.Amount, err = r.Amount.WithCurrency(currency.Code(r.Currency))
+	return err
+}
+
 // PaymentGateway 支付网关配置
 type PaymentGateway struct {
 	ID                  uint           `json:"id" gorm:"primaryKey"`
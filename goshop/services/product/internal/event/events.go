@@ -0,0 +1,15 @@
+package event
+
+import "time"
+
+// 商品服务订阅的事件类型
+const (
+	RatesUpdated = "currency.rates_updated"
+)
+
+// RatesUpdatedEvent 是 currency.rates_updated 事件的数据，Rates 为一单位基准货币可兑换的各货币数量
+type RatesUpdatedEvent struct {
+	Base  string             `json:"base"`
+	Date  time.Time          `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
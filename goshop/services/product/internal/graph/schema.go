@@ -0,0 +1,151 @@
+// Package graph 将商品作为联邦 GraphQL 子图的实体提供给网关，订单等子图通过 ID 引用商品；另外提供商品分类的查询
+package graph
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/graphql"
+	"github.com/yourusername/goshop/services/product/internal/model"
+	"github.com/yourusername/goshop/services/product/internal/service"
+)
+
+// NewSchema 创建商品子图
+func NewSchema(productService *service.ProductService, priceService *service.PriceService) (*graphql.Schema, error) {
+	sku := &graphql.Object{
+		Name: "SKU",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID)},
+			{Name: "skuCode", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "variantName", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "price", Type: graphql.NonNullOf(graphql.Float)},
+			{Name: "salePrice", Type: graphql.Float},
+			{Name: "stockQty", Type: graphql.NonNullOf(graphql.Int)},
+			{Name: "image", Type: graphql.String},
+			{Name: "isDefault", Type: graphql.NonNullOf(graphql.Boolean)},
+		},
+	}
+	category := &graphql.Object{
+		Name: "Category",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID)},
+			{Name: "name", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "slug", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "description", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "image", Type: graphql.String},
+			{Name: "parentId", Type: graphql.ID},
+			{Name: "level", Type: graphql.NonNullOf(graphql.Int)},
+		},
+	}
+	brand := &graphql.Object{
+		Name: "Brand",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID)},
+			{Name: "name", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "logo", Type: graphql.String},
+		},
+	}
+
+	product := &graphql.Object{
+		Name: "Product",
+		Key:  "id",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID)},
+			{Name: "name", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "description", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "shortDescription", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "type", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "status", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "regularPrice", Type: graphql.NonNullOf(graphql.Float)},
+			{Name: "salePrice", Type: graphql.Float},
+			{
+				// currency 指定展示价格的货币，按货币服务的当日汇率换算
+				Name: "price",
+				Type: graphql.NonNullOf(graphql.Float),
+				Args: []*graphql.Arg{{Name: "currency", Type: graphql.String}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					code, _ := p.Args["currency"].(string)
+					return priceService.Convert(currentPrice(p.Source.(*model.Product), time.Now()), code)
+				},
+			},
+			{Name: "images", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(graphql.String)))},
+			{Name: "tags", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(graphql.String)))},
+			{Name: "skus", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(sku)))},
+			{Name: "categories", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(category)))},
+			{Name: "brand", Type: brand},
+		},
+		ResolveEntities: func(ctx context.Context, representations []map[string]interface{}) ([]interface{}, error) {
+			products, err := productService.GetProducts(ctx, graphql.RepresentationIDs(representations, "id"))
+			if err != nil {
+				return nil, err
+			}
+			result := make([]interface{}, len(products))
+			for i, product := range products {
+				result[i] = product
+			}
+			return result, nil
+		},
+	}
+	productList := &graphql.Object{
+		Name: "ProductList",
+		Fields: []*graphql.Field{
+			{Name: "items", Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(product)))},
+			{Name: "total", Type: graphql.NonNullOf(graphql.Int)},
+			{Name: "page", Type: graphql.NonNullOf(graphql.Int)},
+			{Name: "pageSize", Type: graphql.NonNullOf(graphql.Int)},
+		},
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{
+				Name: "product",
+				Type: product,
+				Args: []*graphql.Arg{{Name: "id", Type: graphql.NonNullOf(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := strconv.ParseUint(p.Args["id"].(string), 10, 64)
+					if err != nil {
+						return nil, apperrors.NewBadRequest("无效的商品 ID", err)
+					}
+					return productService.GetProduct(p.Context, uint(id))
+				},
+			},
+			{
+				Name: "products",
+				Type: graphql.NonNullOf(productList),
+				Args: []*graphql.Arg{
+					{Name: "page", Type: graphql.Int, Default: 1},
+					{Name: "pageSize", Type: graphql.Int, Default: 20},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return productService.ListProducts(p.Context, p.Args["page"].(int), p.Args["pageSize"].(int))
+				},
+			},
+			{
+				Name: "categories",
+				Type: graphql.NonNullOf(graphql.ListOf(graphql.NonNullOf(category))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return productService.ListCategories(p.Context)
+				},
+			},
+		},
+	}
+	return graphql.NewSubgraph(query, product)
+}
+
+// currentPrice 返回商品当前的售价，促销价只在促销期内生效
+func currentPrice(product *model.Product, now time.Time) float64 {
+	if product.SalePrice == nil {
+		return product.RegularPrice
+	}
+	if product.SaleStartDate != nil && now.Before(*product.SaleStartDate) {
+		return product.RegularPrice
+	}
+	if product.SaleEndDate != nil && now.After(*product.SaleEndDate) {
+		return product.RegularPrice
+	}
+	return *product.SalePrice
+}
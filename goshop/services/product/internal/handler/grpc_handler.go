@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/product/internal/model"
+	"github.com/yourusername/goshop/services/product/internal/service"
+	"github.com/yourusername/goshop/services/product/rpc"
+)
+
+// GRPCHandler 实现商品服务的 gRPC 接口，网关将商品查询接口转码为 gRPC 调用
+type GRPCHandler struct {
+	productService *service.ProductService
+}
+
+// NewGRPCHandler 创建 gRPC 处理器
+func NewGRPCHandler(productService *service.ProductService) *GRPCHandler {
+	return &GRPCHandler{
+		productService: productService,
+	}
+}
+
+// GetProduct 获取已上架的商品
+func (h *GRPCHandler) GetProduct(ctx context.Context, req *rpc.GetProductRequest) (*model.Product, error) {
+	return h.productService.GetProduct(ctx, req.ID)
+}
+
+// ListProducts 分页获取已上架的商品
+func (h *GRPCHandler) ListProducts(ctx context.Context, req *rpc.ListProductsRequest) (*rpc.ProductList, error) {
+	list, err := h.productService.ListProducts(ctx, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.ProductList{
+		Items:    list.Items,
+		Total:    list.Total,
+		Page:     list.Page,
+		PageSize: list.PageSize,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/product/internal/model"
+	"gorm.io/gorm"
+)
+
+// ProductRepository 定义商品仓库接口
+type ProductRepository interface {
+	GetByID(ctx context.Context, id uint) (*model.Product, error)
+	ListByIDs(ctx context.Context, ids []uint) ([]*model.Product, error)
+	ListByStatus(ctx context.Context, status model.ProductStatus, offset, limit int) ([]*model.Product, int64, error)
+	ListCategories(ctx context.Context) ([]*model.Category, error)
+}
+
+// GormProductRepository 实现 ProductRepository 接口的 GORM 仓库
+type GormProductRepository struct {
+	db *gorm.DB
+}
+
+// NewProductRepository 创建商品仓库实例
+func NewProductRepository(db *gorm.DB) ProductRepository {
+	return &GormProductRepository{
+		db: db,
+	}
+}
+
+// withDetails 预加载商品的规格、分类和品牌
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("SKUs").Preload("Categories").Preload("Brand")
+}
+
+// GetByID 根据 ID 获取商品及其规格、分类和品牌
+func (r *GormProductRepository) GetByID(ctx context.Context, id uint) (*model.Product, error) {
+	var product model.Product
+	err := withDetails(r.db.WithContext(ctx)).First(&product, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ListByIDs 根据 ID 批量获取商品，不存在的商品不在结果中
+func (r *GormProductRepository) ListByIDs(ctx context.Context, ids []uint) ([]*model.Product, error) {
+	var products []*model.Product
+	err := withDetails(r.db.WithContext(ctx)).Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
+// ListByStatus 分页获取指定状态的商品，最新的在前
+func (r *GormProductRepository) ListByStatus(ctx context.Context, status model.ProductStatus, offset, limit int) ([]*model.Product, int64, error) {
+	var products []*model.Product
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&model.Product{}).Where("status = ?", status)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := withDetails(db).Order("id DESC").Offset(offset).Limit(limit).Find(&products).Error
+	return products, total, err
+}
+
+// ListCategories 获取所有分类，按层级和排序值排列
+func (r *GormProductRepository) ListCategories(ctx context.Context) ([]*model.Category, error) {
+	var categories []*model.Category
+	err := r.db.WithContext(ctx).Order("level, sort, id").Find(&categories).Error
+	return categories, err
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/services/currency/rpc"
+	"github.com/yourusername/goshop/services/product/internal/event"
+)
+
+// PriceService 将以默认货币保存的商品价格换算为其他货币展示。汇率启动时从货币服务获取，
+// 之后随 currency.rates_updated 事件更新
+type PriceService struct {
+	client *rpc.Client
+	rates  currency.LiveRates
+}
+
+// NewPriceService 创建价格服务
+func NewPriceService(client *rpc.Client) *PriceService {
+	return &PriceService{
+		client: client,
+	}
+}
+
+// Load 从货币服务获取当前汇率
+func (s *PriceService) Load(ctx context.Context) error {
+	reply, err := s.client.GetRates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+	s.rates.Set(reply.Table())
+	return nil
+}
+
+// Subscribe 订阅汇率更新事件
+func (s *PriceService) Subscribe(consumer *events.Consumer) error {
+	return consumer.Subscribe(event.RatesUpdated, func(ctx context.Context, env *events.Envelope) error {
+		var evt event.RatesUpdatedEvent
+		if err := env.Decode(&evt); err != nil {
+			return events.Permanent(err)
+		}
+		table := currency.Table{Base: currency.Code(evt.Base), Date: evt.Date, Rates: make(map[currency.Code]float64, len(evt.Rates))}
+		for code, rate := range evt.Rates {
+			table.Rates[currency.Code(code)] = rate
+		}
+		s.rates.Set(table)
+		return nil
+	})
+}
+
+// Convert 将默认货币的价格换算为 code 货币，按该货币的小数位数四舍五入，code 为空时返回原价
+func (s *PriceService) Convert(price float64, code string) (float64, error) {
+	if code == "" {
+		return price, nil
+	}
+	to := currency.Code(strings.ToUpper(code))
+	converted, err := currency.Convert(currency.FromMajor(price, currency.Default), to, &s.rates, currency.HalfUp)
+	if errors.Is(err, currency.ErrNoRates) {
+		return 0, apperrors.NewServiceUnavailable("汇率尚未加载", err)
+	}
+	if err != nil {
+		return 0, apperrors.NewBadRequest(fmt.Sprintf("不支持的货币 %s", code), err)
+	}
+	return converted.Float64(), nil
+}
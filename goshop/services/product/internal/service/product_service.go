@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/product/internal/model"
+	"github.com/yourusername/goshop/services/product/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ProductList 表示分页的商品列表
+type ProductList struct {
+	Items    []*model.Product `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// ProductService 提供前台商品查询，未上架的商品对顾客不可见
+type ProductService struct {
+	productRepo repository.ProductRepository
+}
+
+// NewProductService 创建商品服务
+func NewProductService(productRepo repository.ProductRepository) *ProductService {
+	return &ProductService{
+		productRepo: productRepo,
+	}
+}
+
+// GetProduct 获取已上架的商品
+func (s *ProductService) GetProduct(ctx context.Context, id uint) (*model.Product, error) {
+	product, err := s.productRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("商品不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取商品失败", err)
+	}
+	if product.Status != model.ProductStatusActive {
+		return nil, apperrors.NewNotFound("商品不存在", nil)
+	}
+	return product, nil
+}
+
+// GetProducts 批量获取商品，结果与 ids 一一对应，不存在的商品为 nil。
+// 订单中的商品下架后仍然可以查看，因此不过滤状态
+func (s *ProductService) GetProducts(ctx context.Context, ids []uint) ([]*model.Product, error) {
+	products, err := s.productRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取商品失败", err)
+	}
+	byID := make(map[uint]*model.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	result := make([]*model.Product, len(ids))
+	for i, id := range ids {
+		result[i] = byID[id]
+	}
+	return result, nil
+}
+
+// ListProducts 分页获取已上架的商品
+func (s *ProductService) ListProducts(ctx context.Context, page, pageSize int) (*ProductList, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	products, total, err := s.productRepo.ListByStatus(ctx, model.ProductStatusActive, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取商品列表失败", err)
+	}
+	return &ProductList{Items: products, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// ListCategories 获取所有分类
+func (s *ProductService) ListCategories(ctx context.Context) ([]*model.Category, error) {
+	categories, err := s.productRepo.ListCategories(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取分类失败", err)
+	}
+	return categories, nil
+}
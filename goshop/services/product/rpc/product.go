@@ -0,0 +1,112 @@
+// Package rpc defines the gRPC API of the product service, shared by the
+// service and its clients. Messages are plain Go structs encoded with the JSON
+// codec of pkg/grpcclient rather than generated protobuf types. Request fields
+// carry uri and form tags mapping them to the path and query parameters of the
+// gateway routes transcoded to the methods.
+package rpc
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/services/product/internal/model"
+	"google.golang.org/grpc"
+)
+
+// Full method names of the product service
+const (
+	ServiceName        = "product.ProductService"
+	GetProductMethod   = "/" + ServiceName + "/GetProduct"
+	ListProductsMethod = "/" + ServiceName + "/ListProducts"
+)
+
+// GetProductRequest requests a product on sale
+type GetProductRequest struct {
+	ID uint `json:"id" uri:"id" binding:"required"`
+}
+
+// ListProductsRequest requests a page of the products on sale
+type ListProductsRequest struct {
+	Page     int `json:"page" form:"page"`
+	PageSize int `json:"page_size" form:"page_size"`
+}
+
+// ProductList is a page of products
+type ProductList struct {
+	Items    []*model.Product `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// ProductServer is the server API of the product service
+type ProductServer interface {
+	GetProduct(ctx context.Context, req *GetProductRequest) (*model.Product, error)
+	ListProducts(ctx context.Context, req *ListProductsRequest) (*ProductList, error)
+}
+
+// serviceDesc describes the product service to the gRPC server
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*ProductServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("GetProduct", GetProductMethod, ProductServer.GetProduct),
+		unary("ListProducts", ListProductsMethod, ProductServer.ListProducts),
+	},
+}
+
+// RegisterProductServer registers srv with the gRPC server s
+func RegisterProductServer(s grpc.ServiceRegistrar, srv ProductServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// unary describes a unary method calling fn on the server, through the
+// interceptors of the server when it has some
+func unary[Req, Reply any](name, fullMethod string, fn func(ProductServer, context.Context, *Req) (*Reply, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(Req)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return fn(srv.(ProductServer), ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return fn(srv.(ProductServer), ctx, req.(*Req))
+			}
+			return interceptor(ctx, in, info, handler)
+		},
+	}
+}
+
+// Client calls the product service
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient creates a client of the product service on conn, usually
+// obtained from grpcclient.Factory.Conn("product")
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// GetProduct returns a product on sale
+func (c *Client) GetProduct(ctx context.Context, id uint) (*model.Product, error) {
+	out := new(model.Product)
+	if err := c.conn.Invoke(ctx, GetProductMethod, &GetProductRequest{ID: id}, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListProducts returns a page of the products on sale
+func (c *Client) ListProducts(ctx context.Context, req *ListProductsRequest) (*ProductList, error) {
+	out := new(ProductList)
+	if err := c.conn.Invoke(ctx, ListProductsMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
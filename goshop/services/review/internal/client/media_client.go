@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var (
+	// ErrMediaNotFound 表示媒体服务中没有该文件
+	ErrMediaNotFound = errors.New("media not found")
+	// ErrMediaUnavailable 表示没有配置媒体服务
+	ErrMediaUnavailable = errors.New("media service not configured")
+)
+
+// Media 表示媒体服务中已上传的文件
+type Media struct {
+	ID          string `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	OwnerID     uint   `json:"owner_id"` // 上传文件的用户
+}
+
+// MediaClient 通过 HTTP 调用媒体服务查询已上传的文件
+type MediaClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewMediaClient 创建媒体服务客户端，baseURL 为空时所有查询返回 ErrMediaUnavailable
+func NewMediaClient(baseURL string) *MediaClient {
+	return &MediaClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// Get 查询文件，对应媒体服务 GET /api/v1/media/:id
+func (c *MediaClient) Get(ctx context.Context, id string) (*Media, error) {
+	if c.baseURL == "" {
+		return nil, ErrMediaUnavailable
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/media/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrMediaNotFound, id)
+	default:
+		return nil, fmt.Errorf("media service returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data Media `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return &body.Data, nil
+}
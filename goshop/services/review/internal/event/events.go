@@ -0,0 +1,36 @@
+package event
+
+import "time"
+
+// 评价服务订阅和发布的事件类型
+const (
+	// OrderDelivered 由订单服务在订单全部送达后发布，送达后的商品才能评价
+	OrderDelivered = "order.delivered"
+	// ReviewSummaryUpdated 在商品评分汇总变化后发布，搜索服务据此更新商品索引
+	ReviewSummaryUpdated = "review.summary_updated"
+)
+
+// DeliveredItem 表示送达订单中的商品行
+type DeliveredItem struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	SKUID       uint   `json:"sku_id"`
+	Quantity    int    `json:"quantity"`
+}
+
+// OrderDeliveredEvent 是 order.delivered 事件的数据
+type OrderDeliveredEvent struct {
+	OrderID     uint            `json:"order_id"`
+	OrderNumber string          `json:"order_number"`
+	UserID      uint            `json:"user_id"`
+	Items       []DeliveredItem `json:"items"`
+	DeliveredAt time.Time       `json:"delivered_at"`
+}
+
+// ReviewSummaryEvent 是 review.summary_updated 事件的数据
+type ReviewSummaryEvent struct {
+	ProductID   uint      `json:"product_id"`
+	Average     float64   `json:"average"`
+	ReviewCount int       `json:"review_count"`
+	UpdatedAt   time.Time `json:"updated_at"` // 用于丢弃乱序到达的旧汇总
+}
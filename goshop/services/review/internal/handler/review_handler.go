@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/review/internal/repository"
+	"github.com/yourusername/goshop/services/review/internal/service"
+)
+
+// ReviewHandler 处理评价相关的 HTTP 请求
+type ReviewHandler struct {
+	reviewService     *service.ReviewService
+	purchaseService   *service.PurchaseService
+	moderationService *service.ModerationService
+}
+
+// NewReviewHandler 创建评价处理器
+func NewReviewHandler(reviewService *service.ReviewService, purchaseService *service.PurchaseService, moderationService *service.ModerationService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService:     reviewService,
+		purchaseService:   purchaseService,
+		moderationService: moderationService,
+	}
+}
+
+// RegisterRoutes 注册评价路由
+func (h *ReviewHandler) RegisterRoutes(api *gin.RouterGroup) {
+	products := api.Group("/reviews/products")
+	{
+		products.GET("/:id", h.ListProductReviews)
+		products.GET("/:id/summary", h.GetSummary)
+	}
+
+	reviews := api.Group("/reviews")
+	{
+		reviews.POST("", h.CreateReview)
+		reviews.GET("/me", h.ListMyReviews)
+		reviews.GET("/me/pending", h.ListPending)
+		reviews.PUT("/:id", h.UpdateReview)
+		reviews.DELETE("/:id", h.DeleteReview)
+		reviews.POST("/:id/reports", h.ReportReview)
+	}
+
+	admin := api.Group("/reviews/admin")
+	{
+		admin.GET("/reviews", h.AdminListReviews)
+		admin.PUT("/reviews/:id/reply", h.ReplyReview)
+		admin.POST("/reviews/:id/resolve", h.ResolveReports)
+		admin.GET("/reports", h.ListReports)
+	}
+}
+
+// ListProductReviews 分页获取商品已发布的评价，支持按星级和是否有图筛选，
+// sort 可选 newest、rating_desc、rating_asc
+func (h *ReviewHandler) ListProductReviews(c *gin.Context) {
+	productID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	filter := repository.ReviewFilter{
+		Rating:     parseIntQuery(c, "rating", 0),
+		WithPhotos: c.Query("with_photos") == "true",
+		Sort:       c.Query("sort"),
+	}
+	list, err := h.reviewService.ListProduct(c.Request.Context(), productID, filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetSummary 获取商品的评分汇总
+func (h *ReviewHandler) GetSummary(c *gin.Context) {
+	productID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	summary, err := h.reviewService.Summary(c.Request.Context(), productID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// CreateReview 提交评价
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.CreateReviewRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	review, err := h.reviewService.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": review})
+}
+
+// ListMyReviews 分页获取当前用户的评价
+func (h *ReviewHandler) ListMyReviews(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	list, err := h.reviewService.ListMine(c.Request.Context(), userID,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// ListPending 分页获取当前用户已送达但尚未评价的商品
+func (h *ReviewHandler) ListPending(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	list, err := h.purchaseService.ListPending(c.Request.Context(), userID,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// UpdateReview 修改当前用户的评价
+func (h *ReviewHandler) UpdateReview(c *gin.Context) {
+	userID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	var req service.UpdateReviewRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	review, err := h.reviewService.Update(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": review})
+}
+
+// DeleteReview 删除当前用户的评价
+func (h *ReviewHandler) DeleteReview(c *gin.Context) {
+	userID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	if err := h.reviewService.Delete(c.Request.Context(), userID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ReportReview 举报评价
+func (h *ReviewHandler) ReportReview(c *gin.Context) {
+	userID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	var req service.ReportRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := h.moderationService.Report(c.Request.Context(), userID, id, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// AdminListReviews 分页获取评价，可按 status=hidden 查询等待审核的评价
+func (h *ReviewHandler) AdminListReviews(c *gin.Context) {
+	productID, ok := parseIDQuery(c, "product_id")
+	if !ok {
+		return
+	}
+	userID, ok := parseIDQuery(c, "user_id")
+	if !ok {
+		return
+	}
+	filter := repository.ReviewFilter{
+		Type:      c.Query("type"),
+		ProductID: productID,
+		UserID:    userID,
+		Status:    c.Query("status"),
+		Rating:    parseIntQuery(c, "rating", 0),
+		Sort:      c.Query("sort"),
+	}
+	list, err := h.reviewService.AdminList(c.Request.Context(), filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// ReplyReview 以商家身份回复评价
+func (h *ReviewHandler) ReplyReview(c *gin.Context) {
+	adminID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	var req service.ReplyRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	review, err := h.reviewService.Reply(c.Request.Context(), adminID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": review})
+}
+
+// ResolveReports 处理评价的举报
+func (h *ReviewHandler) ResolveReports(c *gin.Context) {
+	adminID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	var req service.ResolveRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	review, err := h.moderationService.Resolve(c.Request.Context(), adminID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": review})
+}
+
+// ListReports 分页获取举报，可按 status=open 查询未处理的举报
+func (h *ReviewHandler) ListReports(c *gin.Context) {
+	reviewID, ok := parseIDQuery(c, "review_id")
+	if !ok {
+		return
+	}
+	filter := repository.ReportFilter{
+		ReviewID: reviewID,
+		Status:   c.Query("status"),
+		Reason:   c.Query("reason"),
+	}
+	list, err := h.moderationService.ListReports(c.Request.Context(), filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// parseOwnedID 解析当前用户 ID 和路径中的 ID
+func parseOwnedID(c *gin.Context) (uint, uint, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	return userID, id, true
+}
@@ -0,0 +1,97 @@
+package model
+
+import "time"
+
+// 评价对象类型
+const (
+	TypeProduct = "product" // 对订单中某个商品的评价
+	TypeOrder   = "order"   // 对整笔订单的物流和服务的评价
+)
+
+// 评价状态
+const (
+	StatusPublished = "published"
+	StatusHidden    = "hidden"  // 被举报次数达到阈值后自动隐藏，等待审核
+	StatusRemoved   = "removed" // 审核确认违规后移除
+)
+
+// 举报原因
+const (
+	ReasonSpam      = "spam"
+	ReasonOffensive = "offensive"
+	ReasonFake      = "fake"
+	ReasonOther     = "other"
+)
+
+// 举报处理状态
+const (
+	ReportOpen      = "open"
+	ReportUpheld    = "upheld"    // 举报成立，评价被移除
+	ReportDismissed = "dismissed" // 举报不成立
+)
+
+// Purchase 表示已送达订单中的一个商品，由 order.delivered 事件写入，用户只能评价已送达的购买
+type Purchase struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	OrderID     uint      `json:"order_id" gorm:"uniqueIndex:idx_purchase;not null"`
+	ProductID   uint      `json:"product_id" gorm:"uniqueIndex:idx_purchase;not null"`
+	UserID      uint      `json:"user_id" gorm:"index;not null"`
+	OrderNumber string    `json:"order_number" gorm:"size:50;not null"`
+	ProductName string    `json:"product_name" gorm:"size:255"`
+	DeliveredAt time.Time `json:"delivered_at" gorm:"not null"`
+}
+
+// Review 表示用户对已购商品或订单的评价，同一订单的同一商品只能评价一次，订单评价的 ProductID 为 0
+type Review struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Type        string         `json:"type" gorm:"uniqueIndex:idx_review_subject;size:20;not null"`
+	OrderID     uint           `json:"order_id" gorm:"uniqueIndex:idx_review_subject;not null"`
+	ProductID   uint           `json:"product_id" gorm:"uniqueIndex:idx_review_subject;index:idx_review_product;not null"`
+	UserID      uint           `json:"user_id" gorm:"index;not null"`
+	Rating      int            `json:"rating" gorm:"index:idx_review_product;not null"`
+	Title       string         `json:"title" gorm:"size:100"`
+	Content     string         `json:"content" gorm:"type:text"`
+	Status      string         `json:"status" gorm:"index;size:20;not null"`
+	ReportCount int            `json:"report_count" gorm:"not null;default:0"` // 未处理的举报数
+	Reply       string         `json:"reply,omitempty" gorm:"type:text"`       // 商家回复
+	RepliedBy   *uint          `json:"replied_by,omitempty"`
+	RepliedAt   *time.Time     `json:"replied_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Photos      []*ReviewPhoto `json:"photos" gorm:"foreignKey:ReviewID"`
+}
+
+// ReviewPhoto 表示评价的图片，图片由媒体服务存储
+type ReviewPhoto struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	ReviewID uint   `json:"review_id" gorm:"index;not null"`
+	MediaID  string `json:"media_id" gorm:"size:64;not null"`
+	URL      string `json:"url" gorm:"size:500;not null"`
+	Position int    `json:"position" gorm:"not null"`
+}
+
+// AbuseReport 表示用户对评价的举报，同一用户对同一评价只能举报一次
+type AbuseReport struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ReviewID   uint       `json:"review_id" gorm:"uniqueIndex:idx_report_reporter;not null"`
+	ReporterID uint       `json:"reporter_id" gorm:"uniqueIndex:idx_report_reporter;not null"`
+	Reason     string     `json:"reason" gorm:"size:20;not null"`
+	Detail     string     `json:"detail" gorm:"size:500"`
+	Status     string     `json:"status" gorm:"index;size:20;not null"`
+	ResolvedBy *uint      `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ProductRating 表示商品已发布评价的评分汇总，评价变更后重新计算
+type ProductRating struct {
+	ProductID uint      `json:"product_id" gorm:"primaryKey;autoIncrement:false"`
+	Count     int       `json:"count" gorm:"not null"`
+	Average   float64   `json:"average" gorm:"type:decimal(3,2);not null"`
+	Star1     int       `json:"star_1" gorm:"not null"`
+	Star2     int       `json:"star_2" gorm:"not null"`
+	Star3     int       `json:"star_3" gorm:"not null"`
+	Star4     int       `json:"star_4" gorm:"not null"`
+	Star5     int       `json:"star_5" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/review/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PurchaseRepository 定义已送达购买记录的仓库接口
+type PurchaseRepository interface {
+	Record(ctx context.Context, purchases []*model.Purchase) error
+	Get(ctx context.Context, orderID, productID uint) (*model.Purchase, error)
+	ExistsForOrder(ctx context.Context, orderID, userID uint) (bool, error)
+	ListUnreviewed(ctx context.Context, userID uint, offset, limit int) ([]*model.Purchase, int64, error)
+}
+
+// GormPurchaseRepository 实现 PurchaseRepository 接口的 GORM 仓库
+type GormPurchaseRepository struct {
+	db *gorm.DB
+}
+
+// NewPurchaseRepository 创建购买记录仓库实例
+func NewPurchaseRepository(db *gorm.DB) PurchaseRepository {
+	return &GormPurchaseRepository{
+		db: db,
+	}
+}
+
+// Record 保存送达的购买记录，已存在的记录会被忽略，事件重复投递时不会重复写入
+func (r *GormPurchaseRepository) Record(ctx context.Context, purchases []*model.Purchase) error {
+	if len(purchases) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&purchases).Error
+}
+
+// Get 获取订单中某个商品的购买记录
+func (r *GormPurchaseRepository) Get(ctx context.Context, orderID, productID uint) (*model.Purchase, error) {
+	var purchase model.Purchase
+	err := r.db.WithContext(ctx).Where("order_id = ? AND product_id = ?", orderID, productID).First(&purchase).Error
+	if err != nil {
+		return nil, err
+	}
+	return &purchase, nil
+}
+
+// ExistsForOrder 判断用户的订单是否已送达
+func (r *GormPurchaseRepository) ExistsForOrder(ctx context.Context, orderID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Purchase{}).
+		Where("order_id = ? AND user_id = ?", orderID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListUnreviewed 分页获取用户已送达但尚未评价的商品，按送达时间倒序
+func (r *GormPurchaseRepository) ListUnreviewed(ctx context.Context, userID uint, offset, limit int) ([]*model.Purchase, int64, error) {
+	var purchases []*model.Purchase
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Purchase{}).
+		Where("purchases.user_id = ?", userID).
+		Where("NOT EXISTS (SELECT 1 FROM reviews WHERE reviews.type = ? AND reviews.order_id = purchases.order_id AND reviews.product_id = purchases.product_id)", model.TypeProduct)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("delivered_at DESC, id DESC").Offset(offset).Limit(limit).Find(&purchases).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return purchases, total, nil
+}
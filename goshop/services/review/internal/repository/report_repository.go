@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/review/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReportFilter 表示查询举报的过滤条件，零值字段不参与过滤
+type ReportFilter struct {
+	ReviewID uint
+	Status   string
+	Reason   string
+}
+
+// ReportRepository 定义评价举报仓库接口
+type ReportRepository interface {
+	Create(ctx context.Context, report *model.AbuseReport, hideThreshold int) (bool, error)
+	List(ctx context.Context, filter ReportFilter, offset, limit int) ([]*model.AbuseReport, int64, error)
+	Resolve(ctx context.Context, reviewID uint, upheld bool, resolvedBy uint, now time.Time) error
+}
+
+// GormReportRepository 实现 ReportRepository 接口的 GORM 仓库
+type GormReportRepository struct {
+	db *gorm.DB
+}
+
+// NewReportRepository 创建举报仓库实例
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &GormReportRepository{
+		db: db,
+	}
+}
+
+// Create 保存举报并累加评价未处理的举报数，达到 hideThreshold 的已发布评价被自动隐藏。
+// 用户已举报过该评价时不重复计数并返回 false
+func (r *GormReportRepository) Create(ctx context.Context, report *model.AbuseReport, hideThreshold int) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		err := tx.Model(&model.Review{}).Where("id = ?", report.ReviewID).Updates(map[string]interface{}{
+			"report_count": gorm.Expr("report_count + 1"),
+			"status": gorm.Expr("CASE WHEN status = ? AND report_count + 1 >= ? THEN ? ELSE status END",
+				model.StatusPublished, hideThreshold, model.StatusHidden),
+		}).Error
+		if err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// List 按过滤条件分页获取举报，按创建时间倒序
+func (r *GormReportRepository) List(ctx context.Context, filter ReportFilter, offset, limit int) ([]*model.AbuseReport, int64, error) {
+	var reports []*model.AbuseReport
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.AbuseReport{})
+	if filter.ReviewID != 0 {
+		query = query.Where("review_id = ?", filter.ReviewID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Reason != "" {
+		query = query.Where("reason = ?", filter.Reason)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&reports).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return reports, total, nil
+}
+
+// Resolve 处理评价的全部未处理举报。举报成立时移除评价，不成立时恢复被自动隐藏的评价
+func (r *GormReportRepository) Resolve(ctx context.Context, reviewID uint, upheld bool, resolvedBy uint, now time.Time) error {
+	reportStatus, reviewStatus := model.ReportDismissed, gorm.Expr("CASE WHEN status = ? THEN ? ELSE status END", model.StatusHidden, model.StatusPublished)
+	if upheld {
+		reportStatus, reviewStatus = model.ReportUpheld, gorm.Expr("?", model.StatusRemoved)
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.AbuseReport{}).
+			Where("review_id = ? AND status = ?", reviewID, model.ReportOpen).
+			Updates(map[string]interface{}{"status": reportStatus, "resolved_by": resolvedBy, "resolved_at": now}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.Review{}).Where("id = ?", reviewID).
+			Updates(map[string]interface{}{"status": reviewStatus, "report_count": 0}).Error
+	})
+}
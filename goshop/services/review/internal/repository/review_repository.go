@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/review/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 评价列表的排序方式
+const (
+	SortNewest     = "newest"
+	SortRatingDesc = "rating_desc"
+	SortRatingAsc  = "rating_asc"
+)
+
+// ReviewFilter 表示查询评价的过滤条件，零值字段不参与过滤
+type ReviewFilter struct {
+	Type       string
+	ProductID  uint
+	OrderID    uint
+	UserID     uint
+	Status     string
+	Rating     int
+	WithPhotos bool
+	Sort       string
+}
+
+// ReviewRepository 定义评价仓库接口
+type ReviewRepository interface {
+	Create(ctx context.Context, review *model.Review) error
+	GetByID(ctx context.Context, id uint) (*model.Review, error)
+	Update(ctx context.Context, review *model.Review, photos []*model.ReviewPhoto) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter ReviewFilter, offset, limit int) ([]*model.Review, int64, error)
+	RecomputeRating(ctx context.Context, productID uint) (*model.ProductRating, error)
+	GetRating(ctx context.Context, productID uint) (*model.ProductRating, error)
+}
+
+// GormReviewRepository 实现 ReviewRepository 接口的 GORM 仓库
+type GormReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository 创建评价仓库实例
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &GormReviewRepository{
+		db: db,
+	}
+}
+
+// Create 创建评价及其图片
+func (r *GormReviewRepository) Create(ctx context.Context, review *model.Review) error {
+	return r.db.WithContext(ctx).Create(review).Error
+}
+
+// GetByID 根据 ID 获取评价及其图片
+func (r *GormReviewRepository) GetByID(ctx context.Context, id uint) (*model.Review, error) {
+	var review model.Review
+	err := r.db.WithContext(ctx).
+		Preload("Photos", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// Update 保存评价，photos 不为 nil 时替换评价的全部图片
+func (r *GormReviewRepository) Update(ctx context.Context, review *model.Review, photos []*model.ReviewPhoto) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(review).Error; err != nil {
+			return err
+		}
+		if photos == nil {
+			return nil
+		}
+		if err := tx.Where("review_id = ?", review.ID).Delete(&model.ReviewPhoto{}).Error; err != nil {
+			return err
+		}
+		for _, photo := range photos {
+			photo.ReviewID = review.ID
+		}
+		if len(photos) > 0 {
+			if err := tx.Create(&photos).Error; err != nil {
+				return err
+			}
+		}
+		review.Photos = photos
+		return nil
+	})
+}
+
+// Delete 删除评价及其图片和举报
+func (r *GormReviewRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("review_id = ?", id).Delete(&model.ReviewPhoto{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("review_id = ?", id).Delete(&model.AbuseReport{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Review{}, id).Error
+	})
+}
+
+// List 按过滤条件分页获取评价及其图片
+func (r *GormReviewRepository) List(ctx context.Context, filter ReviewFilter, offset, limit int) ([]*model.Review, int64, error) {
+	var reviews []*model.Review
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Review{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.ProductID != 0 {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.OrderID != 0 {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Rating != 0 {
+		query = query.Where("rating = ?", filter.Rating)
+	}
+	if filter.WithPhotos {
+		query = query.Where("EXISTS (SELECT 1 FROM review_photos WHERE review_photos.review_id = reviews.id)")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	switch filter.Sort {
+	case SortRatingDesc:
+		query = query.Order("rating DESC, id DESC")
+	case SortRatingAsc:
+		query = query.Order("rating ASC, id DESC")
+	default:
+		query = query.Order("id DESC")
+	}
+	err := query.
+		Preload("Photos", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Offset(offset).Limit(limit).Find(&reviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// RecomputeRating 根据商品已发布的评价重新计算评分汇总并保存
+func (r *GormReviewRepository) RecomputeRating(ctx context.Context, productID uint) (*model.ProductRating, error) {
+	rating := &model.ProductRating{ProductID: productID}
+	err := r.db.WithContext(ctx).Model(&model.Review{}).
+		Select(`COUNT(*) AS count, COALESCE(ROUND(AVG(rating), 2), 0) AS average,
+			COUNT(*) FILTER (WHERE rating = 1) AS star1, COUNT(*) FILTER (WHERE rating = 2) AS star2,
+			COUNT(*) FILTER (WHERE rating = 3) AS star3, COUNT(*) FILTER (WHERE rating = 4) AS star4,
+			COUNT(*) FILTER (WHERE rating = 5) AS star5`).
+		Where("type = ? AND product_id = ? AND status = ?", model.TypeProduct, productID, model.StatusPublished).
+		Scan(rating).Error
+	if err != nil {
+		return nil, err
+	}
+	rating.ProductID = productID
+	rating.UpdatedAt = time.Now()
+	err = r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(rating).Error
+	return rating, err
+}
+
+// GetRating 获取商品的评分汇总，没有评价时返回零值汇总
+func (r *GormReviewRepository) GetRating(ctx context.Context, productID uint) (*model.ProductRating, error) {
+	rating := &model.ProductRating{ProductID: productID}
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).Limit(1).Find(rating).Error
+	return rating, err
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/review/internal/event"
+	"github.com/yourusername/goshop/services/review/internal/model"
+	"github.com/yourusername/goshop/services/review/internal/repository"
+)
+
+// autoHideThreshold 是自动隐藏评价的未处理举报数
+const autoHideThreshold = 3
+
+// ReportRequest 表示举报评价的请求
+type ReportRequest struct {
+	Reason string `json:"reason" binding:"required,oneof=spam offensive fake other"`
+	Detail string `json:"detail" binding:"max=500"`
+}
+
+// ResolveRequest 表示处理评价举报的请求，uphold 移除评价，dismiss 驳回举报并恢复评价
+type ResolveRequest struct {
+	Action string `json:"action" binding:"required,oneof=uphold dismiss"`
+}
+
+// ReportList 表示分页的举报列表
+type ReportList struct {
+	Items    []*model.AbuseReport `json:"items"`
+	Total    int64                `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+}
+
+// ModerationService 负责评价举报的提交和审核。未处理的举报达到阈值时评价被自动隐藏，
+// 等待管理员确认移除或恢复
+type ModerationService struct {
+	reviewRepo repository.ReviewRepository
+	reportRepo repository.ReportRepository
+	ratings    *ratingRefresher
+}
+
+// NewModerationService 创建评价审核服务
+func NewModerationService(reviewRepo repository.ReviewRepository, reportRepo repository.ReportRepository, publisher event.Publisher, log *logger.Logger) *ModerationService {
+	return &ModerationService{
+		reviewRepo: reviewRepo,
+		reportRepo: reportRepo,
+		ratings:    newRatingRefresher(reviewRepo, publisher, log),
+	}
+}
+
+// Report 举报评价，不能举报自己的评价，重复举报不会重复计数
+func (s *ModerationService) Report(ctx context.Context, userID, reviewID uint, req *ReportRequest) error {
+	review, err := getReview(ctx, s.reviewRepo, reviewID)
+	if err != nil {
+		return err
+	}
+	if review.Status == model.StatusRemoved {
+		return apperrors.NewNotFound("评价已被移除", nil)
+	}
+	if review.UserID == userID {
+		return apperrors.NewBadRequest("不能举报自己的评价", nil)
+	}
+
+	report := &model.AbuseReport{
+		ReviewID:   reviewID,
+		ReporterID: userID,
+		Reason:     req.Reason,
+		Detail:     req.Detail,
+		Status:     model.ReportOpen,
+	}
+	created, err := s.reportRepo.Create(ctx, report, autoHideThreshold)
+	if err != nil {
+		return apperrors.NewInternalServerError("举报评价失败", err)
+	}
+	if !created || review.Status != model.StatusPublished {
+		return nil
+	}
+	// 举报使评价被自动隐藏时，隐藏的评价不再计入商品评分
+	updated, err := getReview(ctx, s.reviewRepo, reviewID)
+	if err != nil {
+		return err
+	}
+	if updated.Status == model.StatusHidden {
+		s.ratings.refresh(ctx, updated)
+	}
+	return nil
+}
+
+// ListReports 按条件分页获取举报
+func (s *ModerationService) ListReports(ctx context.Context, filter repository.ReportFilter, page, pageSize int) (*ReportList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	items, total, err := s.reportRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取举报失败", err)
+	}
+	return &ReportList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Resolve 处理评价的全部未处理举报，并重新计算商品评分
+func (s *ModerationService) Resolve(ctx context.Context, adminID, reviewID uint, req *ResolveRequest) (*model.Review, error) {
+	if _, err := getReview(ctx, s.reviewRepo, reviewID); err != nil {
+		return nil, err
+	}
+	if err := s.reportRepo.Resolve(ctx, reviewID, req.Action == "uphold", adminID, time.Now()); err != nil {
+		return nil, apperrors.NewInternalServerError("处理举报失败", err)
+	}
+	review, err := getReview(ctx, s.reviewRepo, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	s.ratings.refresh(ctx, review)
+	return review, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/review/internal/event"
+	"github.com/yourusername/goshop/services/review/internal/model"
+	"github.com/yourusername/goshop/services/review/internal/repository"
+	"go.uber.org/zap"
+)
+
+// PurchaseList 表示分页的待评价购买记录列表
+type PurchaseList struct {
+	Items    []*model.Purchase `json:"items"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// PurchaseService 消费订单送达事件，记录可以评价的购买
+type PurchaseService struct {
+	purchaseRepo repository.PurchaseRepository
+	log          *logger.Logger
+}
+
+// NewPurchaseService 创建购买记录服务
+func NewPurchaseService(purchaseRepo repository.PurchaseRepository, log *logger.Logger) *PurchaseService {
+	return &PurchaseService{
+		purchaseRepo: purchaseRepo,
+		log:          log,
+	}
+}
+
+// Subscribe 订阅订单送达事件
+func (s *PurchaseService) Subscribe(consumer *events.Consumer) error {
+	return consumer.Subscribe(event.OrderDelivered, s.handleOrderDelivered)
+}
+
+// handleOrderDelivered 为送达订单的每个商品写入购买记录，同一商品的多个 SKU 合并为一条
+func (s *PurchaseService) handleOrderDelivered(ctx context.Context, env *events.Envelope) error {
+	var evt event.OrderDeliveredEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	deliveredAt := evt.DeliveredAt
+	if deliveredAt.IsZero() {
+		deliveredAt = env.OccurredAt
+	}
+
+	seen := make(map[uint]bool, len(evt.Items))
+	purchases := make([]*model.Purchase, 0, len(evt.Items))
+	for _, item := range evt.Items {
+		if item.ProductID == 0 || seen[item.ProductID] {
+			continue
+		}
+		seen[item.ProductID] = true
+		purchases = append(purchases, &model.Purchase{
+			OrderID:     evt.OrderID,
+			ProductID:   item.ProductID,
+			UserID:      evt.UserID,
+			OrderNumber: evt.OrderNumber,
+			ProductName: item.ProductName,
+			DeliveredAt: deliveredAt,
+		})
+	}
+	if err := s.purchaseRepo.Record(ctx, purchases); err != nil {
+		return err
+	}
+	s.log.Info(ctx, "已记录送达的购买",
+		zap.Uint("order_id", evt.OrderID),
+		zap.Int("products", len(purchases)),
+	)
+	return nil
+}
+
+// ListPending 分页获取用户已送达但尚未评价的商品
+func (s *PurchaseService) ListPending(ctx context.Context, userID uint, page, pageSize int) (*PurchaseList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	items, total, err := s.purchaseRepo.ListUnreviewed(ctx, userID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取待评价商品失败", err)
+	}
+	return &PurchaseList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
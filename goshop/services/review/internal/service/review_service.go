@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/review/internal/client"
+	"github.com/yourusername/goshop/services/review/internal/event"
+	"github.com/yourusername/goshop/services/review/internal/model"
+	"github.com/yourusername/goshop/services/review/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxPhotos 是每条评价最多附带的图片数
+const maxPhotos = 9
+
+// CreateReviewRequest 表示提交评价的请求，评价订单时不需要 product_id
+type CreateReviewRequest struct {
+	Type      string   `json:"type" binding:"required,oneof=product order"`
+	OrderID   uint     `json:"order_id" binding:"required"`
+	ProductID uint     `json:"product_id"`
+	Rating    int      `json:"rating" binding:"required,min=1,max=5"`
+	Title     string   `json:"title" binding:"max=100"`
+	Content   string   `json:"content" binding:"max=2000"`
+	PhotoIDs  []string `json:"photo_ids" binding:"max=9,dive,required,max=64"`
+}
+
+// UpdateReviewRequest 表示修改评价的请求，photo_ids 未提供时保留原有图片
+type UpdateReviewRequest struct {
+	Rating   int      `json:"rating" binding:"required,min=1,max=5"`
+	Title    string   `json:"title" binding:"max=100"`
+	Content  string   `json:"content" binding:"max=2000"`
+	PhotoIDs []string `json:"photo_ids" binding:"max=9,dive,required,max=64"`
+}
+
+// ReplyRequest 表示商家回复评价的请求，内容为空时删除回复
+type ReplyRequest struct {
+	Content string `json:"content" binding:"max=2000"`
+}
+
+// ReviewList 表示分页的评价列表
+type ReviewList struct {
+	Items    []*model.Review `json:"items"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// ReviewService 负责评价的提交、修改、查询和商家回复。只有已送达的购买才能评价，
+// 商品评价变化后重新计算商品评分并通知搜索服务
+type ReviewService struct {
+	reviewRepo   repository.ReviewRepository
+	purchaseRepo repository.PurchaseRepository
+	media        *client.MediaClient
+	ratings      *ratingRefresher
+}
+
+// NewReviewService 创建评价服务
+func NewReviewService(reviewRepo repository.ReviewRepository, purchaseRepo repository.PurchaseRepository, media *client.MediaClient, publisher event.Publisher, log *logger.Logger) *ReviewService {
+	return &ReviewService{
+		reviewRepo:   reviewRepo,
+		purchaseRepo: purchaseRepo,
+		media:        media,
+		ratings:      newRatingRefresher(reviewRepo, publisher, log),
+	}
+}
+
+// Create 提交评价。商品评价要求用户的订单中该商品已送达，订单评价要求订单已送达
+func (s *ReviewService) Create(ctx context.Context, userID uint, req *CreateReviewRequest) (*model.Review, error) {
+	productID := req.ProductID
+	if req.Type == model.TypeOrder {
+		productID = 0
+	}
+	if err := s.verifyPurchase(ctx, userID, req.Type, req.OrderID, productID); err != nil {
+		return nil, err
+	}
+
+	_, total, err := s.reviewRepo.List(ctx, repository.ReviewFilter{Type: req.Type, OrderID: req.OrderID, ProductID: productID}, 0, 1)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("查询评价失败", err)
+	}
+	if total > 0 {
+		return nil, apperrors.NewConflict("已经评价过了", nil)
+	}
+
+	photos, err := s.resolvePhotos(ctx, userID, req.PhotoIDs)
+	if err != nil {
+		return nil, err
+	}
+	review := &model.Review{
+		Type:      req.Type,
+		OrderID:   req.OrderID,
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    req.Rating,
+		Title:     strings.TrimSpace(req.Title),
+		Content:   strings.TrimSpace(req.Content),
+		Status:    model.StatusPublished,
+		Photos:    photos,
+	}
+	if err := s.reviewRepo.Create(ctx, review); err != nil {
+		return nil, apperrors.NewInternalServerError("提交评价失败", err)
+	}
+	s.ratings.refresh(ctx, review)
+	return review, nil
+}
+
+// Update 修改自己的评价，已被移除的评价不能修改
+func (s *ReviewService) Update(ctx context.Context, userID, id uint, req *UpdateReviewRequest) (*model.Review, error) {
+	review, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status == model.StatusRemoved {
+		return nil, apperrors.NewForbidden("评价已被移除，不能修改", nil)
+	}
+
+	var photos []*model.ReviewPhoto
+	if req.PhotoIDs != nil {
+		if photos, err = s.resolvePhotos(ctx, userID, req.PhotoIDs); err != nil {
+			return nil, err
+		}
+	}
+	review.Rating = req.Rating
+	review.Title = strings.TrimSpace(req.Title)
+	review.Content = strings.TrimSpace(req.Content)
+	if err := s.reviewRepo.Update(ctx, review, photos); err != nil {
+		return nil, apperrors.NewInternalServerError("修改评价失败", err)
+	}
+	s.ratings.refresh(ctx, review)
+	return review, nil
+}
+
+// Delete 删除自己的评价
+func (s *ReviewService) Delete(ctx context.Context, userID, id uint) error {
+	review, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.reviewRepo.Delete(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除评价失败", err)
+	}
+	s.ratings.refresh(ctx, review)
+	return nil
+}
+
+// ListProduct 分页获取商品已发布的评价
+func (s *ReviewService) ListProduct(ctx context.Context, productID uint, filter repository.ReviewFilter, page, pageSize int) (*ReviewList, error) {
+	filter.Type = model.TypeProduct
+	filter.ProductID = productID
+	filter.Status = model.StatusPublished
+	return s.list(ctx, filter, page, pageSize)
+}
+
+// ListMine 分页获取用户自己的评价，包括被隐藏和移除的评价
+func (s *ReviewService) ListMine(ctx context.Context, userID uint, page, pageSize int) (*ReviewList, error) {
+	return s.list(ctx, repository.ReviewFilter{UserID: userID}, page, pageSize)
+}
+
+// AdminList 按任意条件分页获取评价
+func (s *ReviewService) AdminList(ctx context.Context, filter repository.ReviewFilter, page, pageSize int) (*ReviewList, error) {
+	return s.list(ctx, filter, page, pageSize)
+}
+
+// Summary 获取商品的评分汇总
+func (s *ReviewService) Summary(ctx context.Context, productID uint) (*model.ProductRating, error) {
+	rating, err := s.reviewRepo.GetRating(ctx, productID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取评分汇总失败", err)
+	}
+	return rating, nil
+}
+
+// Reply 以商家身份回复评价，内容为空时删除回复
+func (s *ReviewService) Reply(ctx context.Context, adminID, id uint, req *ReplyRequest) (*model.Review, error) {
+	review, err := getReview(ctx, s.reviewRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		review.Reply, review.RepliedBy, review.RepliedAt = "", nil, nil
+	} else {
+		now := time.Now()
+		review.Reply, review.RepliedBy, review.RepliedAt = content, &adminID, &now
+	}
+	if err := s.reviewRepo.Update(ctx, review, nil); err != nil {
+		return nil, apperrors.NewInternalServerError("回复评价失败", err)
+	}
+	return review, nil
+}
+
+func (s *ReviewService) list(ctx context.Context, filter repository.ReviewFilter, page, pageSize int) (*ReviewList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	items, total, err := s.reviewRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取评价失败", err)
+	}
+	return &ReviewList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// verifyPurchase 校验用户的订单或订单中的商品已送达
+func (s *ReviewService) verifyPurchase(ctx context.Context, userID uint, reviewType string, orderID, productID uint) error {
+	if reviewType == model.TypeOrder {
+		ok, err := s.purchaseRepo.ExistsForOrder(ctx, orderID, userID)
+		if err != nil {
+			return apperrors.NewInternalServerError("查询购买记录失败", err)
+		}
+		if !ok {
+			return apperrors.NewForbidden("只能评价已送达的订单", nil)
+		}
+		return nil
+	}
+
+	if productID == 0 {
+		return apperrors.NewBadRequest("评价商品时 product_id 不能为空", nil)
+	}
+	purchase, err := s.purchaseRepo.Get(ctx, orderID, productID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NewInternalServerError("查询购买记录失败", err)
+	}
+	if purchase == nil || purchase.UserID != userID {
+		return apperrors.NewForbidden("只能评价已送达的商品", err)
+	}
+	return nil
+}
+
+// resolvePhotos 向媒体服务查询图片，图片必须是用户自己上传的
+func (s *ReviewService) resolvePhotos(ctx context.Context, userID uint, mediaIDs []string) ([]*model.ReviewPhoto, error) {
+	if len(mediaIDs) > maxPhotos {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("最多上传 %d 张图片", maxPhotos), nil)
+	}
+	photos := make([]*model.ReviewPhoto, 0, len(mediaIDs))
+	for i, id := range mediaIDs {
+		media, err := s.media.Get(ctx, id)
+		switch {
+		case errors.Is(err, client.ErrMediaNotFound):
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("图片 %s 不存在", id), err)
+		case errors.Is(err, client.ErrMediaUnavailable):
+			return nil, apperrors.NewServiceUnavailable("暂不支持上传图片", err)
+		case err != nil:
+			return nil, apperrors.NewServiceUnavailable("媒体服务不可用", err)
+		}
+		if media.OwnerID != userID {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("图片 %s 不存在", id), nil)
+		}
+		if !strings.HasPrefix(media.ContentType, "image/") {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("文件 %s 不是图片", id), nil)
+		}
+		photos = append(photos, &model.ReviewPhoto{MediaID: media.ID, URL: media.URL, Position: i})
+	}
+	return photos, nil
+}
+
+// getOwned 获取用户自己的评价
+func (s *ReviewService) getOwned(ctx context.Context, userID, id uint) (*model.Review, error) {
+	review, err := getReview(ctx, s.reviewRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != userID {
+		return nil, apperrors.NewNotFound(fmt.Sprintf("评价 %d 不存在", id), nil)
+	}
+	return review, nil
+}
+
+// getReview 获取评价
+func getReview(ctx context.Context, reviewRepo repository.ReviewRepository, id uint) (*model.Review, error) {
+	review, err := reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("评价 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取评价失败", err)
+	}
+	return review, nil
+}
+
+// ratingRefresher 在商品评价变化后重新计算评分汇总并发布 review.summary_updated 事件
+type ratingRefresher struct {
+	reviewRepo repository.ReviewRepository
+	publisher  event.Publisher
+	log        *logger.Logger
+}
+
+func newRatingRefresher(reviewRepo repository.ReviewRepository, publisher event.Publisher, log *logger.Logger) *ratingRefresher {
+	return &ratingRefresher{
+		reviewRepo: reviewRepo,
+		publisher:  publisher,
+		log:        log,
+	}
+}
+
+// refresh 重新计算评价所属商品的评分。评价已经保存，失败只记录日志，下次评价变化时会重新计算
+func (r *ratingRefresher) refresh(ctx context.Context, review *model.Review) {
+	if review.Type != model.TypeProduct {
+		return
+	}
+	rating, err := r.reviewRepo.RecomputeRating(ctx, review.ProductID)
+	if err != nil {
+		r.log.Error(ctx, "重新计算商品评分失败", zap.Uint("product_id", review.ProductID), zap.Error(err))
+		return
+	}
+	err = r.publisher.Publish(ctx, event.ReviewSummaryUpdated, event.EventVersion, &event.ReviewSummaryEvent{
+		ProductID:   rating.ProductID,
+		Average:     rating.Average,
+		ReviewCount: rating.Count,
+		UpdatedAt:   rating.UpdatedAt,
+	})
+	if err != nil {
+		r.log.Warn(ctx, "发布评分汇总事件失败", zap.Uint("product_id", review.ProductID), zap.Error(err))
+	}
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/scheduler/internal/repository"
+	"github.com/yourusername/goshop/services/scheduler/internal/service"
+)
+
+// JobHandler 处理任务注册、管理和运行记录相关的 HTTP 请求
+type JobHandler struct {
+	jobService *service.JobService
+}
+
+// NewJobHandler 创建任务处理器
+func NewJobHandler(jobService *service.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// RegisterRoutes 注册任务路由。注册和删除任务由其他服务在内部调用，不经过网关
+func (h *JobHandler) RegisterRoutes(api *gin.RouterGroup) {
+	jobs := api.Group("/scheduler/jobs")
+	{
+		jobs.PUT("/:name", h.RegisterJob)
+		jobs.DELETE("/:name", h.DeleteJob)
+	}
+
+	admin := api.Group("/scheduler/admin")
+	{
+		admin.GET("/jobs", h.ListJobs)
+		admin.GET("/jobs/:name", h.GetJob)
+		admin.POST("/jobs/:name/pause", h.PauseJob)
+		admin.POST("/jobs/:name/resume", h.ResumeJob)
+		admin.POST("/jobs/:name/trigger", h.TriggerJob)
+		admin.GET("/jobs/:name/executions", h.ListJobExecutions)
+		admin.GET("/executions", h.ListExecutions)
+		admin.GET("/executions/:id", h.GetExecution)
+	}
+}
+
+// RegisterJob 创建或更新任务，新建时返回 201
+func (h *JobHandler) RegisterJob(c *gin.Context) {
+	var req service.JobRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	job, created, err := h.jobService.Register(c.Request.Context(), c.Param("name"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{"data": job})
+}
+
+// DeleteJob 删除任务
+func (h *JobHandler) DeleteJob(c *gin.Context) {
+	if err := h.jobService.Delete(c.Request.Context(), c.Param("name")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListJobs 分页获取任务，可按 service 和 status 过滤
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	filter := repository.JobFilter{
+		Service: c.Query("service"),
+		Status:  c.Query("status"),
+	}
+	list, err := h.jobService.List(c.Request.Context(), filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetJob 获取任务
+func (h *JobHandler) GetJob(c *gin.Context) {
+	job, err := h.jobService.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// PauseJob 暂停任务
+func (h *JobHandler) PauseJob(c *gin.Context) {
+	job, err := h.jobService.Pause(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// ResumeJob 恢复暂停的任务
+func (h *JobHandler) ResumeJob(c *gin.Context) {
+	job, err := h.jobService.Resume(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// TriggerJob 立即运行一次任务，返回运行中的运行记录
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	execution, err := h.jobService.Trigger(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": execution})
+}
+
+// ListJobExecutions 分页获取任务的运行记录
+func (h *JobHandler) ListJobExecutions(c *gin.Context) {
+	h.listExecutions(c, c.Param("name"))
+}
+
+// ListExecutions 分页获取所有任务的运行记录，可按 status=failed 查询失败的运行
+func (h *JobHandler) ListExecutions(c *gin.Context) {
+	h.listExecutions(c, "")
+}
+
+// GetExecution 获取运行记录
+func (h *JobHandler) GetExecution(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	execution, err := h.jobService.GetExecution(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": execution})
+}
+
+func (h *JobHandler) listExecutions(c *gin.Context, job string) {
+	from, ok := parseTimeQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseTimeQuery(c, "to")
+	if !ok {
+		return
+	}
+	filter := repository.ExecutionFilter{
+		Status: c.Query("status"),
+		From:   from,
+		To:     to,
+	}
+	list, err := h.jobService.ListExecutions(c.Request.Context(), job, filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// 任务状态
+const (
+	JobActive    = "active"    // 等待下次运行
+	JobPaused    = "paused"    // 已暂停，恢复后从当前时间起计算下次运行时间
+	JobCompleted = "completed" // 一次性任务已成功运行
+	JobFailed    = "failed"    // 一次性任务重试次数用尽
+)
+
+// 运行记录状态
+const (
+	ExecutionRunning   = "running"
+	ExecutionRetrying  = "retrying" // 尝试失败，等待重试
+	ExecutionSucceeded = "succeeded"
+	ExecutionFailed    = "failed"
+)
+
+// Job 表示其他服务注册的定时任务，按 cron 表达式周期运行或在 RunAt 运行一次。
+// 任务到期时调度器向所属服务的回调路径发送 POST 请求，回调返回 2xx 表示成功
+type Job struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	Name           string     `json:"name" gorm:"uniqueIndex;size:100;not null"` // 以服务名为前缀，如 marketing.expire-points
+	Schedule       string     `json:"schedule,omitempty" gorm:"size:100"`        // cron 表达式，一次性任务为空
+	Timezone       string     `json:"timezone" gorm:"size:50;not null"`
+	RunAt          *time.Time `json:"run_at,omitempty"` // 一次性任务的运行时间
+	Service        string     `json:"service" gorm:"index;size:50;not null"`
+	Path           string     `json:"path" gorm:"size:255;not null"`
+	Payload        string     `json:"payload,omitempty" gorm:"type:text"` // 回调请求体，JSON
+	TimeoutSeconds int        `json:"timeout_seconds" gorm:"not null"`
+	MaxAttempts    int        `json:"max_attempts" gorm:"not null"` // 每次运行最多尝试的次数，包括第一次
+	BackoffSeconds int        `json:"backoff_seconds" gorm:"not null"`
+	Status         string     `json:"status" gorm:"index:idx_job_due;size:20;not null"`
+	NextRunAt      *time.Time `json:"next_run_at" gorm:"index:idx_job_due"` // 下次运行或重试的时间
+	Attempt        int        `json:"attempt" gorm:"not null;default:0"`    // 正在重试的运行已尝试的次数，不在重试时为 0
+	ExecutionID    *uint      `json:"execution_id,omitempty"`               // 正在重试的运行
+	LastRunAt      *time.Time `json:"last_run_at"`
+	LastStatus     string     `json:"last_status" gorm:"size:20"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Execution 表示任务的一次运行，失败重试的各次尝试属于同一条运行记录
+type Execution struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	JobID       uint       `json:"job_id" gorm:"index:idx_execution_job;not null"`
+	JobName     string     `json:"job_name" gorm:"size:100;not null"`
+	ScheduledAt time.Time  `json:"scheduled_at" gorm:"not null"` // 运行到期的时间
+	Manual      bool       `json:"manual"`                       // 手动触发
+	Status      string     `json:"status" gorm:"index;size:20;not null"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	StatusCode  int        `json:"status_code"` // 最后一次尝试的响应状态码，未得到响应时为 0
+	Response    string     `json:"response" gorm:"size:1000"`
+	Error       string     `json:"error" gorm:"size:1000"`
+	DurationMs  int64      `json:"duration_ms"` // 最后一次尝试的耗时
+	StartedAt   time.Time  `json:"started_at" gorm:"index:idx_execution_job;not null"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
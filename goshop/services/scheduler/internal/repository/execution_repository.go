@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/scheduler/internal/model"
+	"gorm.io/gorm"
+)
+
+// ExecutionFilter 表示查询运行记录的过滤条件，零值字段不参与过滤
+type ExecutionFilter struct {
+	JobID  uint
+	Status string
+	From   time.Time
+	To     time.Time
+}
+
+// ExecutionRepository 定义运行记录仓库接口
+type ExecutionRepository interface {
+	Create(ctx context.Context, execution *model.Execution) error
+	GetByID(ctx context.Context, id uint) (*model.Execution, error)
+	Update(ctx context.Context, execution *model.Execution) error
+	List(ctx context.Context, filter ExecutionFilter, offset, limit int) ([]*model.Execution, int64, error)
+	DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// GormExecutionRepository 实现 ExecutionRepository 接口的 GORM 仓库
+type GormExecutionRepository struct {
+	db *gorm.DB
+}
+
+// NewExecutionRepository 创建运行记录仓库实例
+func NewExecutionRepository(db *gorm.DB) ExecutionRepository {
+	return &GormExecutionRepository{
+		db: db,
+	}
+}
+
+// Create 创建运行记录
+func (r *GormExecutionRepository) Create(ctx context.Context, execution *model.Execution) error {
+	return r.db.WithContext(ctx).Create(execution).Error
+}
+
+// GetByID 根据 ID 获取运行记录
+func (r *GormExecutionRepository) GetByID(ctx context.Context, id uint) (*model.Execution, error) {
+	var execution model.Execution
+	if err := r.db.WithContext(ctx).First(&execution, id).Error; err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// Update 保存运行记录
+func (r *GormExecutionRepository) Update(ctx context.Context, execution *model.Execution) error {
+	return r.db.WithContext(ctx).Save(execution).Error
+}
+
+// List 按过滤条件分页获取运行记录，按开始时间倒序
+func (r *GormExecutionRepository) List(ctx context.Context, filter ExecutionFilter, offset, limit int) ([]*model.Execution, int64, error) {
+	var executions []*model.Execution
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Execution{})
+	if filter.JobID != 0 {
+		query = query.Where("job_id = ?", filter.JobID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("started_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("started_at < ?", filter.To)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("started_at DESC, id DESC").Offset(offset).Limit(limit).Find(&executions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return executions, total, nil
+}
+
+// DeleteFinishedBefore 删除在 cutoff 之前结束的运行记录，返回删除的记录数
+func (r *GormExecutionRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("finished_at < ?", cutoff).Delete(&model.Execution{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/scheduler/internal/model"
+	"gorm.io/gorm"
+)
+
+// JobFilter 表示查询任务的过滤条件，零值字段不参与过滤
+type JobFilter struct {
+	Service string
+	Status  string
+}
+
+// JobRepository 定义任务仓库接口
+type JobRepository interface {
+	Create(ctx context.Context, job *model.Job) error
+	GetByName(ctx context.Context, name string) (*model.Job, error)
+	Update(ctx context.Context, job *model.Job) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter JobFilter, offset, limit int) ([]*model.Job, int64, error)
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*model.Job, error)
+	Claim(ctx context.Context, id uint, dueAt, until time.Time) (bool, error)
+	Finish(ctx context.Context, id uint, claimedUntil time.Time, updates map[string]interface{}) (bool, error)
+}
+
+// GormJobRepository 实现 JobRepository 接口的 GORM 仓库
+type GormJobRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRepository 创建任务仓库实例
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &GormJobRepository{
+		db: db,
+	}
+}
+
+// Create 创建任务
+func (r *GormJobRepository) Create(ctx context.Context, job *model.Job) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// GetByName 根据名称获取任务
+func (r *GormJobRepository) GetByName(ctx context.Context, name string) (*model.Job, error) {
+	var job model.Job
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Update 保存任务
+func (r *GormJobRepository) Update(ctx context.Context, job *model.Job) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
+
+// Delete 删除任务，运行记录保留到过期清理
+func (r *GormJobRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Job{}, id).Error
+}
+
+// List 按过滤条件分页获取任务，按名称排序
+func (r *GormJobRepository) List(ctx context.Context, filter JobFilter, offset, limit int) ([]*model.Job, int64, error) {
+	var jobs []*model.Job
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Job{})
+	if filter.Service != "" {
+		query = query.Where("service = ?", filter.Service)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("name ASC").Offset(offset).Limit(limit).Find(&jobs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// ListDue 获取到期的任务，按到期时间升序
+func (r *GormJobRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*model.Job, error) {
+	var jobs []*model.Job
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_run_at <= ?", model.JobActive, now).
+		Order("next_run_at ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// Claim 将到期的任务顺延到 until，防止运行过程中被再次取出。
+// 任务已被领取、重新注册或暂停时 next_run_at 已改变，返回 false
+func (r *GormJobRepository) Claim(ctx context.Context, id uint, dueAt, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ? AND status = ? AND next_run_at = ?", id, model.JobActive, dueAt).
+		Update("next_run_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Finish 保存一次运行后的任务状态。任务在运行期间被重新注册或暂停时
+// next_run_at 不再等于 claimedUntil，不覆盖新的设置并返回 false
+func (r *GormJobRepository) Finish(ctx context.Context, id uint, claimedUntil time.Time, updates map[string]interface{}) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ? AND next_run_at = ?", id, claimedUntil).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/cron"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/scheduler/internal/model"
+	"github.com/yourusername/goshop/services/scheduler/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTimeoutSeconds = 60
+	maxTimeoutSeconds     = 3600
+	defaultMaxAttempts    = 3
+	defaultBackoffSeconds = 30
+)
+
+// jobNamePattern 匹配任务名称，如 marketing.expire-points
+var jobNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*(\.[a-z0-9_-]+)+$`)
+
+// RetryPolicy 表示失败运行的重试策略
+type RetryPolicy struct {
+	MaxAttempts    int `json:"max_attempts" binding:"min=1,max=20"`
+	BackoffSeconds int `json:"backoff_seconds" binding:"min=1,max=86400"`
+}
+
+// JobRequest 表示注册任务的请求，schedule 和 run_at 必须且只能设置一个
+type JobRequest struct {
+	Schedule       string          `json:"schedule" binding:"max=100"`
+	Timezone       string          `json:"timezone" binding:"max=50"`
+	RunAt          *time.Time      `json:"run_at"`
+	Service        string          `json:"service" binding:"required,max=50"`
+	Path           string          `json:"path" binding:"required,max=255"`
+	Payload        json.RawMessage `json:"payload"`
+	TimeoutSeconds int             `json:"timeout_seconds" binding:"min=0"`
+	Retry          *RetryPolicy    `json:"retry"`
+}
+
+// JobList 表示分页的任务列表
+type JobList struct {
+	Items    []*model.Job `json:"items"`
+	Total    int64        `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
+
+// ExecutionList 表示分页的运行记录列表
+type ExecutionList struct {
+	Items    []*model.Execution `json:"items"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
+
+// JobService 负责任务的注册和管理，以及运行记录的查询
+type JobService struct {
+	jobRepo       repository.JobRepository
+	executionRepo repository.ExecutionRepository
+	runner        *Runner
+	endpoints     map[string]string
+	log           *logger.Logger
+}
+
+// NewJobService 创建任务服务，endpoints 是服务名到 HTTP 地址的映射，只能为其中的服务注册任务
+func NewJobService(jobRepo repository.JobRepository, executionRepo repository.ExecutionRepository, runner *Runner, endpoints map[string]string, log *logger.Logger) *JobService {
+	return &JobService{
+		jobRepo:       jobRepo,
+		executionRepo: executionRepo,
+		runner:        runner,
+		endpoints:     endpoints,
+		log:           log,
+	}
+}
+
+// Register 创建或更新任务并返回是否新建。定义未改变时不做修改，
+// 因此服务可以在每次启动时重复注册；定义改变时重新计算下次运行时间
+func (s *JobService) Register(ctx context.Context, name string, req *JobRequest) (*model.Job, bool, error) {
+	def, err := s.define(name, req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	job, err := s.jobRepo.GetByName(ctx, name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, apperrors.NewInternalServerError("获取任务失败", err)
+	}
+	if job == nil {
+		job = def
+		job.Status = model.JobActive
+		if job.NextRunAt, err = nextRun(job, time.Now()); err != nil {
+			return nil, false, apperrors.NewBadRequest(err.Error(), err)
+		}
+		if err := s.jobRepo.Create(ctx, job); err != nil {
+			return nil, false, apperrors.NewInternalServerError("创建任务失败", err)
+		}
+		return job, true, nil
+	}
+
+	if sameDefinition(job, def) {
+		return job, false, nil
+	}
+	job.Schedule = def.Schedule
+	job.Timezone = def.Timezone
+	job.RunAt = def.RunAt
+	job.Service = def.Service
+	job.Path = def.Path
+	job.Payload = def.Payload
+	job.TimeoutSeconds = def.TimeoutSeconds
+	job.MaxAttempts = def.MaxAttempts
+	job.BackoffSeconds = def.BackoffSeconds
+	// 正在重试的运行不再继续，暂停的任务保持暂停
+	job.Attempt = 0
+	job.ExecutionID = nil
+	if job.Status == model.JobPaused {
+		job.NextRunAt = nil
+	} else {
+		job.Status = model.JobActive
+		if job.NextRunAt, err = nextRun(job, time.Now()); err != nil {
+			return nil, false, apperrors.NewBadRequest(err.Error(), err)
+		}
+	}
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return nil, false, apperrors.NewInternalServerError("更新任务失败", err)
+	}
+	return job, false, nil
+}
+
+// Delete 删除任务
+func (s *JobService) Delete(ctx context.Context, name string) error {
+	job, err := s.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := s.jobRepo.Delete(ctx, job.ID); err != nil {
+		return apperrors.NewInternalServerError("删除任务失败", err)
+	}
+	return nil
+}
+
+// Get 获取任务
+func (s *JobService) Get(ctx context.Context, name string) (*model.Job, error) {
+	job, err := s.jobRepo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("任务 %s 不存在", name), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取任务失败", err)
+	}
+	return job, nil
+}
+
+// List 分页获取任务
+func (s *JobService) List(ctx context.Context, filter repository.JobFilter, page, pageSize int) (*JobList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	jobs, total, err := s.jobRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取任务失败", err)
+	}
+	return &JobList{Items: jobs, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Pause 暂停任务，正在等待重试的运行记为失败
+func (s *JobService) Pause(ctx context.Context, name string) (*model.Job, error) {
+	job, err := s.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != model.JobActive {
+		return nil, apperrors.NewConflict("只有等待运行的任务可以暂停", nil)
+	}
+	if job.ExecutionID != nil {
+		s.abandon(ctx, *job.ExecutionID, "任务已暂停")
+	}
+	job.Status = model.JobPaused
+	job.NextRunAt = nil
+	job.Attempt = 0
+	job.ExecutionID = nil
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return nil, apperrors.NewInternalServerError("暂停任务失败", err)
+	}
+	return job, nil
+}
+
+// Resume 恢复暂停的任务，周期任务错过的运行不再补跑
+func (s *JobService) Resume(ctx context.Context, name string) (*model.Job, error) {
+	job, err := s.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != model.JobPaused {
+		return nil, apperrors.NewConflict("只有暂停的任务可以恢复", nil)
+	}
+	job.Status = model.JobActive
+	if job.NextRunAt, err = nextRun(job, time.Now()); err != nil {
+		return nil, apperrors.NewInternalServerError("计算下次运行时间失败", err)
+	}
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return nil, apperrors.NewInternalServerError("恢复任务失败", err)
+	}
+	return job, nil
+}
+
+// Trigger 立即运行一次任务，只尝试一次且不影响任务的排期
+func (s *JobService) Trigger(ctx context.Context, name string) (*model.Execution, error) {
+	job, err := s.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	execution, err := s.runner.Trigger(ctx, job)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("触发任务失败", err)
+	}
+	return execution, nil
+}
+
+// ListExecutions 分页获取运行记录，job 不为空时只获取该任务的记录
+func (s *JobService) ListExecutions(ctx context.Context, job string, filter repository.ExecutionFilter, page, pageSize int) (*ExecutionList, error) {
+	if job != "" {
+		j, err := s.Get(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+		filter.JobID = j.ID
+	}
+	page, pageSize = normalizePage(page, pageSize)
+	executions, total, err := s.executionRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取运行记录失败", err)
+	}
+	return &ExecutionList{Items: executions, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// GetExecution 获取运行记录
+func (s *JobService) GetExecution(ctx context.Context, id uint) (*model.Execution, error) {
+	execution, err := s.executionRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("运行记录 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取运行记录失败", err)
+	}
+	return execution, nil
+}
+
+// define 校验注册请求并转换为任务定义
+func (s *JobService) define(name string, req *JobRequest) (*model.Job, error) {
+	if !jobNamePattern.MatchString(name) || len(name) > 100 {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("无效的任务名称 %s，应以服务名为前缀，如 marketing.expire-points", name), nil)
+	}
+	if (req.Schedule == "") == (req.RunAt == nil) {
+		return nil, apperrors.NewBadRequest("schedule 和 run_at 必须且只能设置一个", nil)
+	}
+	if _, ok := s.endpoints[req.Service]; !ok {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("未知的服务 %s", req.Service), nil)
+	}
+	if !strings.HasPrefix(req.Path, "/") {
+		return nil, apperrors.NewBadRequest("回调路径必须以 / 开头", nil)
+	}
+
+	job := &model.Job{
+		Name:           name,
+		Schedule:       strings.TrimSpace(req.Schedule),
+		Timezone:       req.Timezone,
+		Service:        req.Service,
+		Path:           req.Path,
+		Payload:        string(req.Payload),
+		TimeoutSeconds: req.TimeoutSeconds,
+		MaxAttempts:    defaultMaxAttempts,
+		BackoffSeconds: defaultBackoffSeconds,
+	}
+	if job.Timezone == "" {
+		job.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(job.Timezone); err != nil {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("无效的时区 %s", job.Timezone), err)
+	}
+	if job.Schedule != "" {
+		if _, err := cron.Parse(job.Schedule); err != nil {
+			return nil, apperrors.NewBadRequest("无效的 cron 表达式", err)
+		}
+	}
+	if req.RunAt != nil {
+		runAt := req.RunAt.UTC()
+		job.RunAt = &runAt
+	}
+	if job.TimeoutSeconds == 0 {
+		job.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	if job.TimeoutSeconds > maxTimeoutSeconds {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("超时时间不能超过 %d 秒", maxTimeoutSeconds), nil)
+	}
+	if req.Retry != nil {
+		job.MaxAttempts = req.Retry.MaxAttempts
+		job.BackoffSeconds = req.Retry.BackoffSeconds
+	}
+	return job, nil
+}
+
+// abandon 将等待重试的运行记为失败
+func (s *JobService) abandon(ctx context.Context, executionID uint, reason string) {
+	execution, err := s.executionRepo.GetByID(ctx, executionID)
+	if err != nil || execution.Status != model.ExecutionRetrying {
+		return
+	}
+	now := time.Now()
+	execution.Status = model.ExecutionFailed
+	execution.Error = reason
+	execution.FinishedAt = &now
+	if err := s.executionRepo.Update(ctx, execution); err != nil {
+		s.log.Warn(ctx, "Failed to abandon execution", zap.Uint("execution_id", executionID), zap.Error(err))
+	}
+}
+
+// sameDefinition 判断任务定义是否未改变
+func sameDefinition(job, def *model.Job) bool {
+	sameRunAt := (job.RunAt == nil && def.RunAt == nil) ||
+		(job.RunAt != nil && def.RunAt != nil && job.RunAt.Equal(*def.RunAt))
+	return sameRunAt &&
+		job.Schedule == def.Schedule &&
+		job.Timezone == def.Timezone &&
+		job.Service == def.Service &&
+		job.Path == def.Path &&
+		job.Payload == def.Payload &&
+		job.TimeoutSeconds == def.TimeoutSeconds &&
+		job.MaxAttempts == def.MaxAttempts &&
+		job.BackoffSeconds == def.BackoffSeconds
+}
+
+// nextRun 计算任务在 after 之后的下次运行时间，一次性任务返回 RunAt，
+// 永远不会匹配的 cron 表达式返回 nil
+func nextRun(job *model.Job, after time.Time) (*time.Time, error) {
+	if job.Schedule == "" {
+		return job.RunAt, nil
+	}
+	schedule, err := cron.Parse(job.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(job.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	next := schedule.Next(after.In(loc))
+	if next.IsZero() {
+		return nil, nil
+	}
+	next = next.UTC()
+	return &next, nil
+}
+
+// normalizePage 规范化分页参数
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/pkg/scheduler"
+	"github.com/yourusername/goshop/services/scheduler/internal/model"
+	"github.com/yourusername/goshop/services/scheduler/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// claimGrace 是任务超时之外额外保留的领取时间，进程在运行中退出时任务会在到期后被重新取出
+	claimGrace = time.Minute
+	// dueBatchSize 是每轮最多取出的到期任务数
+	dueBatchSize = 100
+	// maxResponseLength 是记录的响应体和错误的最大长度
+	maxResponseLength = 1000
+	// purgeInterval 是清理过期运行记录的间隔
+	purgeInterval = time.Hour
+)
+
+// RunnerConfig 表示任务运行的设置
+type RunnerConfig struct {
+	CallbackToken  string // 回调请求携带的共享密钥
+	MaxConcurrency int    // 同时运行的任务数
+	HistoryDays    int    // 运行记录的保留天数
+}
+
+// Runner 在选举出的主节点上运行到期的任务：向所属服务的回调路径发送请求，
+// 失败的运行按任务的重试策略以指数退避重试，每次运行都记录在运行记录中
+type Runner struct {
+	jobRepo       repository.JobRepository
+	executionRepo repository.ExecutionRepository
+	endpoints     map[string]string
+	client        *http.Client
+	cfg           RunnerConfig
+	log           *logger.Logger
+}
+
+// NewRunner 创建任务运行器，endpoints 是服务名到 HTTP 地址的映射
+func NewRunner(jobRepo repository.JobRepository, executionRepo repository.ExecutionRepository, endpoints map[string]string, cfg RunnerConfig, log *logger.Logger) *Runner {
+	if cfg.MaxConcurrency <= 0 {
+		cfg.MaxConcurrency = 10
+	}
+	return &Runner{
+		jobRepo:       jobRepo,
+		executionRepo: executionRepo,
+		endpoints:     endpoints,
+		client:        &http.Client{},
+		cfg:           cfg,
+		log:           log,
+	}
+}
+
+// Run 定期运行到期的任务并清理过期的运行记录，直到 ctx 取消。
+// 返回前等待正在运行的任务结束
+func (r *Runner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	slots := make(chan struct{}, r.cfg.MaxConcurrency)
+	var lastPurge time.Time
+
+	for {
+		now := time.Now()
+		r.tick(ctx, now, slots, &wg)
+		if now.Sub(lastPurge) >= purgeInterval {
+			r.purge(ctx, now)
+			lastPurge = now
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick 领取到期的任务并在空闲的并发槽中运行，没有空闲槽时剩余的任务留到下一轮
+func (r *Runner) tick(ctx context.Context, now time.Time, slots chan struct{}, wg *sync.WaitGroup) {
+	free := cap(slots) - len(slots)
+	if free == 0 {
+		return
+	}
+	due, err := r.jobRepo.ListDue(ctx, now, min(free, dueBatchSize))
+	if err != nil {
+		r.log.Error(ctx, "Failed to list due jobs", zap.Error(err))
+		return
+	}
+	for _, job := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		dueAt := *job.NextRunAt
+		until := now.Add(time.Duration(job.TimeoutSeconds)*time.Second + claimGrace)
+		claimed, err := r.jobRepo.Claim(ctx, job.ID, dueAt, until)
+		if err != nil {
+			r.log.Error(ctx, "Failed to claim job", zap.String("job", job.Name), zap.Error(err))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(job *model.Job) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			// 失去主节点身份时让正在运行的任务完成，领取期限内其他节点不会重复运行
+			r.runScheduled(context.WithoutCancel(ctx), job, dueAt, until)
+		}(job)
+	}
+}
+
+// runScheduled 运行一次到期的任务并计算下次运行时间
+func (r *Runner) runScheduled(ctx context.Context, job *model.Job, dueAt, claimedUntil time.Time) {
+	execution, err := r.startExecution(ctx, job, dueAt)
+	if err != nil {
+		r.log.Error(ctx, "Failed to start execution", zap.String("job", job.Name), zap.Error(err))
+		return
+	}
+
+	attempt := job.Attempt + 1
+	ok := r.attempt(ctx, job, execution, attempt)
+	now := time.Now()
+
+	updates := map[string]interface{}{
+		"last_run_at":  execution.StartedAt,
+		"attempt":      0,
+		"execution_id": nil,
+	}
+	switch {
+	case ok:
+		execution.Status = model.ExecutionSucceeded
+		execution.FinishedAt = &now
+		updates["last_status"] = model.ExecutionSucceeded
+		r.advance(job, now, model.JobCompleted, updates)
+	case attempt < job.MaxAttempts:
+		execution.Status = model.ExecutionRetrying
+		updates["attempt"] = attempt
+		updates["execution_id"] = execution.ID
+		updates["next_run_at"] = now.Add(time.Duration(job.BackoffSeconds) * time.Second << (attempt - 1))
+	default:
+		execution.Status = model.ExecutionFailed
+		execution.FinishedAt = &now
+		updates["last_status"] = model.ExecutionFailed
+		r.advance(job, now, model.JobFailed, updates)
+		r.log.Warn(ctx, "Job failed after retries",
+			zap.String("job", job.Name),
+			zap.Uint("execution_id", execution.ID),
+			zap.Int("attempts", attempt),
+			zap.String("error", execution.Error),
+		)
+	}
+
+	if err := r.executionRepo.Update(ctx, execution); err != nil {
+		r.log.Error(ctx, "Failed to save execution", zap.Uint("execution_id", execution.ID), zap.Error(err))
+	}
+	saved, err := r.jobRepo.Finish(ctx, job.ID, claimedUntil, updates)
+	if err != nil {
+		r.log.Error(ctx, "Failed to save job", zap.String("job", job.Name), zap.Error(err))
+		return
+	}
+	if !saved {
+		r.log.Info(ctx, "Job changed while running, keeping the new settings", zap.String("job", job.Name))
+	}
+}
+
+// Trigger 立即在后台运行一次任务，只尝试一次且不影响任务的排期
+func (r *Runner) Trigger(ctx context.Context, job *model.Job) (*model.Execution, error) {
+	now := time.Now()
+	execution := &model.Execution{
+		JobID:       job.ID,
+		JobName:     job.Name,
+		ScheduledAt: now,
+		Manual:      true,
+		Status:      model.ExecutionRunning,
+		StartedAt:   now,
+	}
+	if err := r.executionRepo.Create(ctx, execution); err != nil {
+		return nil, err
+	}
+
+	result := *execution
+	go func() {
+		// 请求结束后继续运行
+		ctx := context.WithoutCancel(ctx)
+		if r.attempt(ctx, job, execution, 1) {
+			execution.Status = model.ExecutionSucceeded
+		} else {
+			execution.Status = model.ExecutionFailed
+		}
+		finished := time.Now()
+		execution.FinishedAt = &finished
+		if err := r.executionRepo.Update(ctx, execution); err != nil {
+			r.log.Error(ctx, "Failed to save execution", zap.Uint("execution_id", execution.ID), zap.Error(err))
+		}
+	}()
+	return &result, nil
+}
+
+// startExecution 创建运行记录，重试时继续使用第一次尝试的记录
+func (r *Runner) startExecution(ctx context.Context, job *model.Job, dueAt time.Time) (*model.Execution, error) {
+	if job.ExecutionID != nil {
+		execution, err := r.executionRepo.GetByID(ctx, *job.ExecutionID)
+		if err == nil {
+			execution.Status = model.ExecutionRunning
+			return execution, nil
+		}
+		r.log.Warn(ctx, "Failed to get execution being retried, starting a new one", zap.Uint("execution_id", *job.ExecutionID), zap.Error(err))
+	}
+	execution := &model.Execution{
+		JobID:       job.ID,
+		JobName:     job.Name,
+		ScheduledAt: dueAt,
+		Status:      model.ExecutionRunning,
+		StartedAt:   time.Now(),
+	}
+	if err := r.executionRepo.Create(ctx, execution); err != nil {
+		return nil, err
+	}
+	return execution, nil
+}
+
+// attempt 发送一次回调请求，将结果记入运行记录并返回是否成功
+func (r *Runner) attempt(ctx context.Context, job *model.Job, execution *model.Execution, attempt int) bool {
+	execution.Attempts = attempt
+	execution.StatusCode = 0
+	execution.Response = ""
+	execution.Error = ""
+
+	start := time.Now()
+	statusCode, body, err := r.call(ctx, job, execution, attempt)
+	execution.DurationMs = time.Since(start).Milliseconds()
+	execution.StatusCode = statusCode
+	execution.Response = truncate(body, maxResponseLength)
+	if err != nil {
+		execution.Error = truncate(err.Error(), maxResponseLength)
+		return false
+	}
+	return true
+}
+
+// call 向任务所属服务发送回调请求，非 2xx 响应视为失败
+func (r *Runner) call(ctx context.Context, job *model.Job, execution *model.Execution, attempt int) (int, string, error) {
+	baseURL, ok := r.endpoints[job.Service]
+	if !ok {
+		return 0, "", fmt.Errorf("unknown service %s", job.Service)
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(job.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	payload := job.Payload
+	if payload == "" {
+		payload = "{}"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+job.Path, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(scheduler.HeaderToken, r.cfg.CallbackToken)
+	req.Header.Set(scheduler.HeaderJob, job.Name)
+	req.Header.Set(scheduler.HeaderExecutionID, strconv.FormatUint(uint64(execution.ID), 10))
+	req.Header.Set(scheduler.HeaderAttempt, strconv.Itoa(attempt))
+	req.Header.Set(scheduler.HeaderScheduledAt, execution.ScheduledAt.UTC().Format(time.RFC3339))
+	if traceID := logger.GetTraceID(ctx); traceID != "" {
+		req.Header.Set("X-Trace-ID", traceID)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseLength))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// advance 计算运行结束后的下次运行时间，周期任务错过的运行不再补跑，
+// 一次性任务结束后转为 finalStatus
+func (r *Runner) advance(job *model.Job, now time.Time, finalStatus string, updates map[string]interface{}) {
+	if job.Schedule == "" {
+		updates["status"] = finalStatus
+		updates["next_run_at"] = nil
+		return
+	}
+	next, err := nextRun(job, now)
+	if err != nil {
+		// 注册时已校验过表达式和时区，不应发生
+		r.log.Error(context.Background(), "Failed to compute next run", zap.String("job", job.Name), zap.Error(err))
+	}
+	updates["next_run_at"] = next
+}
+
+// purge 清理过期的运行记录
+func (r *Runner) purge(ctx context.Context, now time.Time) {
+	deleted, err := r.executionRepo.DeleteFinishedBefore(ctx, now.AddDate(0, 0, -r.cfg.HistoryDays))
+	if err != nil {
+		r.log.Error(ctx, "Failed to purge executions", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		r.log.Info(ctx, "Purged expired executions", zap.Int64("deleted", deleted))
+	}
+}
+
+// truncate 截断过长的字符串，截断处不完整的字符被丢弃
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "")
+}
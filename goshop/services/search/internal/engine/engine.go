@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/goshop/pkg/config"
+)
+
+// ErrUnavailable 表示搜索引擎无法访问或返回了服务端错误
+var ErrUnavailable = errors.New("search engine unavailable")
+
+// Document 是写入索引的文档，必须包含字符串类型的 id 字段
+type Document map[string]interface{}
+
+// ID 返回文档 ID
+func (d Document) ID() string {
+	id, _ := d["id"].(string)
+	return id
+}
+
+// FilterOp 表示过滤条件的比较方式
+type FilterOp string
+
+const (
+	// FilterIn 字段值等于任一给定值
+	FilterIn FilterOp = "in"
+	// FilterNotIn 字段值不等于任何给定值
+	FilterNotIn FilterOp = "not_in"
+	// FilterGTE 字段值大于等于给定值
+	FilterGTE FilterOp = "gte"
+	// FilterLTE 字段值小于等于给定值
+	FilterLTE FilterOp = "lte"
+)
+
+// Filter 表示一个过滤条件，多个条件之间为且的关系
+type Filter struct {
+	Field  string
+	Op     FilterOp
+	Values []interface{}
+}
+
+// Query 表示一次搜索请求
+type Query struct {
+	Text   string
+	Filter []Filter
+	Sort   []string // 如 price:asc，为空时按相关度排序
+	Facets []string // 需要统计取值分布的字段
+	Offset int
+	Limit  int
+}
+
+// Result 表示搜索结果
+type Result struct {
+	Hits   []Document                `json:"hits"`
+	Total  int                       `json:"total"` // 命中总数，引擎可能返回估算值
+	Facets map[string]map[string]int `json:"facets,omitempty"`
+}
+
+// TypoTolerance 表示拼写容错设置
+type TypoTolerance struct {
+	Enabled           bool
+	OneTypoMinLength  int // 达到该长度的词允许一个拼写错误
+	TwoTyposMinLength int // 达到该长度的词允许两个拼写错误
+}
+
+// IndexSettings 表示索引的字段和相关度设置
+type IndexSettings struct {
+	Searchable []string // 参与全文检索的字段，按权重从高到低
+	Filterable []string
+	Sortable   []string
+	Typo       TypoTolerance
+}
+
+// Engine 定义搜索引擎适配器接口，调用方只依赖该接口，更换引擎时不需要修改
+type Engine interface {
+	// Name 返回引擎名称
+	Name() string
+	// Ping 检查引擎是否可用
+	Ping(ctx context.Context) error
+	// EnsureIndex 创建索引（已存在时忽略）并应用设置
+	EnsureIndex(ctx context.Context, index string, settings IndexSettings) error
+	// SetSynonyms 替换索引的全部同义词，key 为搜索词，value 为同时匹配的词
+	SetSynonyms(ctx context.Context, index string, synonyms map[string][]string) error
+	// Upsert 写入文档，相同 ID 的文档会被整体替换
+	Upsert(ctx context.Context, index string, docs []Document) error
+	// Update 更新文档中的部分字段，其他字段保持不变，不存在的文档会被创建
+	Update(ctx context.Context, index string, docs []Document) error
+	// Delete 按 ID 删除文档，不存在的 ID 会被忽略
+	Delete(ctx context.Context, index string, ids []string) error
+	// Search 搜索文档
+	Search(ctx context.Context, index string, query *Query) (*Result, error)
+}
+
+// New 根据配置创建搜索引擎适配器
+func New(cfg config.SearchConfig) (Engine, error) {
+	switch cfg.Engine {
+	case "meilisearch":
+		return NewMeilisearch(cfg.URL, cfg.APIKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported search engine %q", cfg.Engine)
+	}
+}
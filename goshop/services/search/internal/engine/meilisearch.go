@@ -0,0 +1,222 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Meilisearch 通过 HTTP API 访问 Meilisearch。写操作在 Meilisearch 中是异步任务，
+// 返回成功只表示任务已入队，同一索引的任务按提交顺序执行
+type Meilisearch struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+type meiliTypoTolerance struct {
+	Enabled             bool           `json:"enabled"`
+	MinWordSizeForTypos map[string]int `json:"minWordSizeForTypos,omitempty"`
+}
+
+type meiliSettings struct {
+	SearchableAttributes []string           `json:"searchableAttributes"`
+	FilterableAttributes []string           `json:"filterableAttributes"`
+	SortableAttributes   []string           `json:"sortableAttributes"`
+	TypoTolerance        meiliTypoTolerance `json:"typoTolerance"`
+}
+
+type meiliSearchRequest struct {
+	Q      string   `json:"q"`
+	Offset int      `json:"offset"`
+	Limit  int      `json:"limit"`
+	Filter string   `json:"filter,omitempty"`
+	Sort   []string `json:"sort,omitempty"`
+	Facets []string `json:"facets,omitempty"`
+}
+
+type meiliSearchResponse struct {
+	Hits               []Document                `json:"hits"`
+	EstimatedTotalHits int                       `json:"estimatedTotalHits"`
+	FacetDistribution  map[string]map[string]int `json:"facetDistribution"`
+}
+
+type meiliError struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+// NewMeilisearch 创建 Meilisearch 适配器
+func NewMeilisearch(baseURL, apiKey string) *Meilisearch {
+	return &Meilisearch{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Name 返回引擎名称
+func (m *Meilisearch) Name() string {
+	return "meilisearch"
+}
+
+// Ping 检查 Meilisearch 是否可用
+func (m *Meilisearch) Ping(ctx context.Context) error {
+	return m.do(ctx, http.MethodGet, "/health", nil, nil)
+}
+
+// EnsureIndex 创建索引并应用设置，索引已存在时创建任务会失败，不影响后续的设置任务
+func (m *Meilisearch) EnsureIndex(ctx context.Context, index string, settings IndexSettings) error {
+	if err := m.do(ctx, http.MethodPost, "/indexes", map[string]string{"uid": index, "primaryKey": "id"}, nil); err != nil {
+		return err
+	}
+	typo := meiliTypoTolerance{Enabled: settings.Typo.Enabled}
+	if settings.Typo.Enabled {
+		typo.MinWordSizeForTypos = map[string]int{
+			"oneTypo":  settings.Typo.OneTypoMinLength,
+			"twoTypos": settings.Typo.TwoTyposMinLength,
+		}
+	}
+	return m.do(ctx, http.MethodPatch, "/indexes/"+url.PathEscape(index)+"/settings", meiliSettings{
+		SearchableAttributes: settings.Searchable,
+		FilterableAttributes: settings.Filterable,
+		SortableAttributes:   settings.Sortable,
+		TypoTolerance:        typo,
+	}, nil)
+}
+
+// SetSynonyms 替换索引的全部同义词
+func (m *Meilisearch) SetSynonyms(ctx context.Context, index string, synonyms map[string][]string) error {
+	return m.do(ctx, http.MethodPut, "/indexes/"+url.PathEscape(index)+"/settings/synonyms", synonyms, nil)
+}
+
+// Upsert 写入文档
+func (m *Meilisearch) Upsert(ctx context.Context, index string, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	return m.do(ctx, http.MethodPost, "/indexes/"+url.PathEscape(index)+"/documents?primaryKey=id", docs, nil)
+}
+
+// Update 部分更新文档，只替换提供的字段
+func (m *Meilisearch) Update(ctx context.Context, index string, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	return m.do(ctx, http.MethodPut, "/indexes/"+url.PathEscape(index)+"/documents?primaryKey=id", docs, nil)
+}
+
+// Delete 按 ID 删除文档
+func (m *Meilisearch) Delete(ctx context.Context, index string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return m.do(ctx, http.MethodPost, "/indexes/"+url.PathEscape(index)+"/documents/delete-batch", ids, nil)
+}
+
+// Search 搜索文档，Meilisearch 默认开启前缀匹配和拼写容错
+func (m *Meilisearch) Search(ctx context.Context, index string, query *Query) (*Result, error) {
+	req := meiliSearchRequest{
+		Q:      query.Text,
+		Offset: query.Offset,
+		Limit:  query.Limit,
+		Filter: meiliFilter(query.Filter),
+		Sort:   query.Sort,
+		Facets: query.Facets,
+	}
+	var resp meiliSearchResponse
+	if err := m.do(ctx, http.MethodPost, "/indexes/"+url.PathEscape(index)+"/search", req, &resp); err != nil {
+		return nil, err
+	}
+	return &Result{
+		Hits:   resp.Hits,
+		Total:  resp.EstimatedTotalHits,
+		Facets: resp.FacetDistribution,
+	}, nil
+}
+
+func (m *Meilisearch) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e meiliError
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(raw, &e) != nil || e.Message == "" {
+			e.Message = strings.TrimSpace(string(raw))
+		}
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("%w: status %d: %s", ErrUnavailable, resp.StatusCode, e.Message)
+		}
+		return fmt.Errorf("meilisearch %s %s: status %d %s: %s", method, path, resp.StatusCode, e.Code, e.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// meiliFilter 将过滤条件转换为 Meilisearch 过滤表达式
+func meiliFilter(filters []Filter) string {
+	parts := make([]string, 0, len(filters))
+	for _, f := range filters {
+		if len(f.Values) == 0 {
+			continue
+		}
+		switch f.Op {
+		case FilterIn:
+			parts = append(parts, fmt.Sprintf("%s IN [%s]", f.Field, meiliValues(f.Values)))
+		case FilterNotIn:
+			parts = append(parts, fmt.Sprintf("%s NOT IN [%s]", f.Field, meiliValues(f.Values)))
+		case FilterGTE:
+			parts = append(parts, fmt.Sprintf("%s >= %s", f.Field, meiliValue(f.Values[0])))
+		case FilterLTE:
+			parts = append(parts, fmt.Sprintf("%s <= %s", f.Field, meiliValue(f.Values[0])))
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func meiliValues(values []interface{}) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = meiliValue(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// meiliValue 格式化过滤值，字符串加引号并转义，避免用户输入改变表达式结构
+func meiliValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,80 @@
+package event
+
+import "time"
+
+// 搜索服务订阅的事件类型，商品和 CMS 服务在数据变更后发布
+const (
+	ProductCreated     = "product.created"
+	ProductUpdated     = "product.updated"
+	ProductDeleted     = "product.deleted"
+	ContentPublished   = "content.published"
+	ContentUnpublished = "content.unpublished"
+	// ReviewSummaryUpdated 由评价服务在商品评分汇总变化后发布
+	ReviewSummaryUpdated = "review.summary_updated"
+)
+
+// NamedRef 表示被引用的品牌或分类
+type NamedRef struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// SKURef 表示商品的规格单元
+type SKURef struct {
+	SKUCode  string `json:"sku_code"`
+	StockQty int    `json:"stock_qty"`
+}
+
+// ProductEvent 是 product.created 和 product.updated 事件的数据，包含商品的完整快照
+type ProductEvent struct {
+	ID               uint       `json:"id"`
+	Name             string     `json:"name"`
+	Description      string     `json:"description"`
+	ShortDescription string     `json:"short_description"`
+	Type             string     `json:"type"`
+	Status           string     `json:"status"`
+	RegularPrice     float64    `json:"regular_price"`
+	SalePrice        *float64   `json:"sale_price"`
+	Brand            *NamedRef  `json:"brand"`
+	Categories       []NamedRef `json:"categories"`
+	Tags             []string   `json:"tags"`
+	SKUs             []SKURef   `json:"skus"`
+	Images           []string   `json:"images"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"` // 用于丢弃乱序到达的旧快照
+}
+
+// ProductDeletedEvent 是 product.deleted 事件的数据
+type ProductDeletedEvent struct {
+	ID        uint      `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// ContentEvent 是 content.published 事件的数据
+type ContentEvent struct {
+	ID          uint       `json:"id"`
+	Type        string     `json:"type"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	Excerpt     string     `json:"excerpt"`
+	Content     string     `json:"content"`
+	Tags        []string   `json:"tags"`
+	Categories  []NamedRef `json:"categories"`
+	CoverImage  *string    `json:"cover_image"`
+	PublishedAt time.Time  `json:"published_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ContentUnpublishedEvent 是 content.unpublished 事件的数据，内容被撤回、归档或删除时发布
+type ContentUnpublishedEvent struct {
+	ID        uint      `json:"id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReviewSummaryEvent 是 review.summary_updated 事件的数据
+type ReviewSummaryEvent struct {
+	ProductID   uint      `json:"product_id"`
+	Average     float64   `json:"average"`
+	ReviewCount int       `json:"review_count"`
+	UpdatedAt   time.Time `json:"updated_at"` // 用于丢弃乱序到达的旧汇总
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/search/internal/model"
+	"github.com/yourusername/goshop/services/search/internal/service"
+)
+
+// AdminHandler 处理同义词、运营规则和索引设置相关的后台 HTTP 请求
+type AdminHandler struct {
+	merchandisingService *service.MerchandisingService
+	indexService         *service.IndexService
+}
+
+// NewAdminHandler 创建搜索后台处理器
+func NewAdminHandler(merchandisingService *service.MerchandisingService, indexService *service.IndexService) *AdminHandler {
+	return &AdminHandler{
+		merchandisingService: merchandisingService,
+		indexService:         indexService,
+	}
+}
+
+// RegisterRoutes 注册搜索后台路由
+func (h *AdminHandler) RegisterRoutes(api *gin.RouterGroup) {
+	admin := api.Group("/search/admin")
+	{
+		admin.GET("/synonyms", h.ListSynonyms)
+		admin.POST("/synonyms", h.CreateSynonym)
+		admin.PUT("/synonyms/:id", h.UpdateSynonym)
+		admin.DELETE("/synonyms/:id", h.DeleteSynonym)
+
+		admin.GET("/rules", h.ListRules)
+		admin.POST("/rules", h.CreateRule)
+		admin.GET("/rules/:id", h.GetRule)
+		admin.PUT("/rules/:id", h.UpdateRule)
+		admin.DELETE("/rules/:id", h.DeleteRule)
+
+		admin.POST("/indexes/sync", h.SyncIndexes)
+	}
+}
+
+// ListSynonyms 获取同义词，可按 index 过滤
+func (h *AdminHandler) ListSynonyms(c *gin.Context) {
+	synonyms, err := h.merchandisingService.ListSynonyms(c.Request.Context(), c.Query("index"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": synonyms})
+}
+
+// CreateSynonym 创建同义词
+func (h *AdminHandler) CreateSynonym(c *gin.Context) {
+	var req service.SynonymRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	synonym, err := h.merchandisingService.CreateSynonym(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": synonym})
+}
+
+// UpdateSynonym 更新同义词
+func (h *AdminHandler) UpdateSynonym(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.SynonymRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	synonym, err := h.merchandisingService.UpdateSynonym(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": synonym})
+}
+
+// DeleteSynonym 删除同义词
+func (h *AdminHandler) DeleteSynonym(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.merchandisingService.DeleteSynonym(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListRules 获取运营规则，可按 index 过滤
+func (h *AdminHandler) ListRules(c *gin.Context) {
+	rules, err := h.merchandisingService.ListRules(c.Request.Context(), c.Query("index"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// CreateRule 创建运营规则
+func (h *AdminHandler) CreateRule(c *gin.Context) {
+	var req service.RuleRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	rule, err := h.merchandisingService.CreateRule(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": rule})
+}
+
+// GetRule 获取运营规则
+func (h *AdminHandler) GetRule(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	rule, err := h.merchandisingService.GetRule(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rule})
+}
+
+// UpdateRule 更新运营规则
+func (h *AdminHandler) UpdateRule(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.RuleRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	rule, err := h.merchandisingService.UpdateRule(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rule})
+}
+
+// DeleteRule 删除运营规则
+func (h *AdminHandler) DeleteRule(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.merchandisingService.DeleteRule(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SyncIndexes 重新应用所有索引的字段设置和同义词，用于搜索引擎数据丢失或更换引擎后恢复
+func (h *AdminHandler) SyncIndexes(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := h.indexService.EnsureIndexes(ctx); err != nil {
+		respondError(c, apperrors.NewServiceUnavailable("同步索引设置失败", err))
+		return
+	}
+	for _, index := range model.Indexes {
+		if err := h.merchandisingService.SyncSynonyms(ctx, index); err != nil {
+			respondError(c, apperrors.NewServiceUnavailable("同步同义词失败", err))
+			return
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/search/internal/engine"
+	"github.com/yourusername/goshop/services/search/internal/model"
+	"github.com/yourusername/goshop/services/search/internal/service"
+)
+
+// 前台可选的排序方式，未指定时按相关度排序
+var (
+	productSorts = map[string][]string{
+		"price_asc":  {"price:asc"},
+		"price_desc": {"price:desc"},
+		"newest":     {"created_at:desc"},
+		"rating":     {"rating:desc", "review_count:desc"},
+		"reviews":    {"review_count:desc"},
+	}
+	contentSorts = map[string][]string{
+		"newest": {"published_at:desc"},
+	}
+)
+
+// SearchHandler 处理商品和内容搜索的 HTTP 请求
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+// NewSearchHandler 创建搜索处理器
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// RegisterRoutes 注册搜索路由
+func (h *SearchHandler) RegisterRoutes(api *gin.RouterGroup) {
+	search := api.Group("/search")
+	{
+		search.GET("/products", h.SearchProducts)
+		search.GET("/contents", h.SearchContents)
+	}
+}
+
+// SearchProducts 搜索上架商品，支持按分类、品牌、类型、标签、价格区间、最低评分和是否有货过滤
+func (h *SearchHandler) SearchProducts(c *gin.Context) {
+	sort, ok := parseSort(c, productSorts)
+	if !ok {
+		return
+	}
+	var filter []engine.Filter
+	if filter, ok = appendIDFilter(c, filter, "category_id", "category_ids"); !ok {
+		return
+	}
+	if filter, ok = appendIDFilter(c, filter, "brand_id", "brand_id"); !ok {
+		return
+	}
+	filter = appendInFilter(filter, "type", c.QueryArray("type"))
+	filter = appendInFilter(filter, "tags", c.QueryArray("tag"))
+	if filter, ok = appendRangeFilter(c, filter, "min_price", "price", engine.FilterGTE); !ok {
+		return
+	}
+	if filter, ok = appendRangeFilter(c, filter, "max_price", "price", engine.FilterLTE); !ok {
+		return
+	}
+	if filter, ok = appendRangeFilter(c, filter, "min_rating", "rating", engine.FilterGTE); !ok {
+		return
+	}
+	if c.Query("in_stock") == "true" {
+		filter = append(filter, engine.Filter{Field: "in_stock", Op: engine.FilterIn, Values: []interface{}{true}})
+	}
+
+	h.search(c, model.IndexProducts, &service.SearchRequest{
+		Text:     c.Query("q"),
+		Filter:   filter,
+		Sort:     sort,
+		Facets:   []string{"brand", "categories", "type"},
+		Page:     parseIntQuery(c, "page", 1),
+		PageSize: parseIntQuery(c, "page_size", 20),
+	})
+}
+
+// SearchContents 搜索已发布的 CMS 内容，支持按类型、标签和分类过滤
+func (h *SearchHandler) SearchContents(c *gin.Context) {
+	sort, ok := parseSort(c, contentSorts)
+	if !ok {
+		return
+	}
+	var filter []engine.Filter
+	filter = appendInFilter(filter, "type", c.QueryArray("type"))
+	filter = appendInFilter(filter, "tags", c.QueryArray("tag"))
+	if filter, ok = appendIDFilter(c, filter, "category_id", "category_ids"); !ok {
+		return
+	}
+
+	h.search(c, model.IndexContents, &service.SearchRequest{
+		Text:     c.Query("q"),
+		Filter:   filter,
+		Sort:     sort,
+		Facets:   []string{"type", "tags"},
+		Page:     parseIntQuery(c, "page", 1),
+		PageSize: parseIntQuery(c, "page_size", 20),
+	})
+}
+
+func (h *SearchHandler) search(c *gin.Context, index string, req *service.SearchRequest) {
+	resp, err := h.searchService.Search(c.Request.Context(), index, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// parseSort 解析 sort 查询参数，只接受预定义的排序方式
+func parseSort(c *gin.Context, sorts map[string][]string) ([]string, bool) {
+	name := c.Query("sort")
+	if name == "" || name == "relevance" {
+		return nil, true
+	}
+	sort, ok := sorts[name]
+	if !ok {
+		respondError(c, apperrors.NewBadRequest("不支持的排序方式 "+name, nil))
+		return nil, false
+	}
+	return sort, true
+}
+
+// appendIDFilter 将可重复的 ID 查询参数追加为过滤条件
+func appendIDFilter(c *gin.Context, filter []engine.Filter, param, field string) ([]engine.Filter, bool) {
+	raw := c.QueryArray(param)
+	if len(raw) == 0 {
+		return filter, true
+	}
+	values := make([]interface{}, 0, len(raw))
+	for _, r := range raw {
+		id, err := strconv.ParseUint(r, 10, 64)
+		if err != nil {
+			respondError(c, apperrors.NewBadRequest("无效的 "+param, err))
+			return nil, false
+		}
+		values = append(values, id)
+	}
+	return append(filter, engine.Filter{Field: field, Op: engine.FilterIn, Values: values}), true
+}
+
+// appendInFilter 将可重复的字符串查询参数追加为过滤条件
+func appendInFilter(filter []engine.Filter, field string, raw []string) []engine.Filter {
+	if len(raw) == 0 {
+		return filter
+	}
+	values := make([]interface{}, len(raw))
+	for i, r := range raw {
+		values[i] = r
+	}
+	return append(filter, engine.Filter{Field: field, Op: engine.FilterIn, Values: values})
+}
+
+// appendRangeFilter 将数值查询参数追加为范围过滤条件
+func appendRangeFilter(c *gin.Context, filter []engine.Filter, param, field string, op engine.FilterOp) ([]engine.Filter, bool) {
+	raw := c.Query(param)
+	if raw == "" {
+		return filter, true
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		respondError(c, apperrors.NewBadRequest("无效的 "+param, err))
+		return nil, false
+	}
+	return append(filter, engine.Filter{Field: field, Op: op, Values: []interface{}{v}}), true
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// 搜索服务维护的索引
+const (
+	IndexProducts = "products" // 上架商品
+	IndexContents = "contents" // 已发布的 CMS 内容
+)
+
+// Indexes 是所有支持的索引
+var Indexes = []string{IndexProducts, IndexContents}
+
+// 运营规则的查询匹配方式
+const (
+	MatchExact    = "exact"    // 规范化后的查询与规则查询完全相同
+	MatchContains = "contains" // 规范化后的查询包含规则查询
+)
+
+// StringArray 是一个自定义类型，用于存储字符串数组
+type StringArray []string
+
+// Value 实现 driver.Valuer 接口
+func (a StringArray) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (a *StringArray) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("类型断言为 []byte 失败")
+	}
+	return json.Unmarshal(b, &a)
+}
+
+// Synonym 表示一组同义词。Root 为空时组内的词互为同义词，
+// 否则为单向同义词，搜索 Root 时同时匹配 Terms，反之不成立
+type Synonym struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	Index     string      `json:"index" gorm:"column:index_name;size:20;not null;index"`
+	Root      string      `json:"root" gorm:"size:100"`
+	Terms     StringArray `json:"terms" gorm:"type:jsonb;not null"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// MerchandisingRule 表示运营规则，查询命中规则时置顶的文档按顺序排在最前，
+// 沉底的文档排在所有其他结果之后
+type MerchandisingRule struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	Name      string      `json:"name" gorm:"size:100;not null"`
+	Index     string      `json:"index" gorm:"column:index_name;size:20;not null;index"`
+	Query     string      `json:"query" gorm:"size:100;not null"` // 保存时已规范化
+	Match     string      `json:"match" gorm:"size:20;not null;default:'exact'"`
+	Pinned    StringArray `json:"pinned" gorm:"type:jsonb"` // 置顶的文档 ID，按展示顺序
+	Buried    StringArray `json:"buried" gorm:"type:jsonb"` // 沉底的文档 ID
+	Priority  int         `json:"priority" gorm:"not null;default:0"`
+	Enabled   bool        `json:"enabled" gorm:"not null"`
+	StartsAt  *time.Time  `json:"starts_at"`
+	EndsAt    *time.Time  `json:"ends_at"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Matches 判断规则在给定时间是否对规范化后的查询生效
+func (r *MerchandisingRule) Matches(index, query string, now time.Time) bool {
+	if !r.Enabled || r.Index != index {
+		return false
+	}
+	if r.StartsAt != nil && now.Before(*r.StartsAt) {
+		return false
+	}
+	if r.EndsAt != nil && !now.Before(*r.EndsAt) {
+		return false
+	}
+	if r.Match == MatchContains {
+		return strings.Contains(query, r.Query)
+	}
+	return query == r.Query
+}
+
+// IndexedDocument 记录已写入搜索引擎的文档版本。事件可能乱序到达，
+// 比已索引版本旧的事件会被忽略
+type IndexedDocument struct {
+	Index      string    `json:"index" gorm:"column:index_name;primaryKey;size:20"`
+	DocumentID string    `json:"document_id" gorm:"primaryKey;size:50"`
+	Version    time.Time `json:"version" gorm:"not null"`
+	Deleted    bool      `json:"deleted" gorm:"not null;default:false"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ProductRating 保存评价服务发布的商品评分汇总，商品重新索引时写入文档
+type ProductRating struct {
+	ProductID   uint      `json:"product_id" gorm:"primaryKey;autoIncrement:false"`
+	Average     float64   `json:"average" gorm:"type:decimal(3,2);not null"`
+	ReviewCount int       `json:"review_count" gorm:"not null"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null"` // 评价服务计算汇总的时间
+}
+
+// NormalizeQuery 规范化查询词：去掉首尾空白、合并连续空白并转为小写
+func NormalizeQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
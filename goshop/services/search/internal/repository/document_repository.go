@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yourusername/goshop/services/search/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DocumentRepository 定义已索引文档版本的仓库接口
+type DocumentRepository interface {
+	IsStale(ctx context.Context, index, documentID string, version time.Time) (bool, error)
+	Record(ctx context.Context, doc *model.IndexedDocument) error
+	IsIndexed(ctx context.Context, index, documentID string) (bool, error)
+}
+
+// GormDocumentRepository 实现 DocumentRepository 接口的 GORM 仓库
+type GormDocumentRepository struct {
+	db *gorm.DB
+}
+
+// NewDocumentRepository 创建已索引文档仓库实例
+func NewDocumentRepository(db *gorm.DB) DocumentRepository {
+	return &GormDocumentRepository{
+		db: db,
+	}
+}
+
+// IsStale 判断给定版本是否不比已索引的版本新，文档从未索引过时返回 false
+func (r *GormDocumentRepository) IsStale(ctx context.Context, index, documentID string, version time.Time) (bool, error) {
+	var doc model.IndexedDocument
+	err := r.db.WithContext(ctx).
+		Where("index_name = ? AND document_id = ?", index, documentID).
+		First(&doc).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return !version.After(doc.Version), nil
+}
+
+// Record 记录文档的已索引版本，只会用更新的版本覆盖已有记录
+func (r *GormDocumentRepository) Record(ctx context.Context, doc *model.IndexedDocument) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "index_name"}, {Name: "document_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"version", "deleted", "updated_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "indexed_documents.version < excluded.version"},
+			}},
+		}).
+		Create(doc).Error
+}
+
+// IsIndexed 判断文档当前是否在索引中
+func (r *GormDocumentRepository) IsIndexed(ctx context.Context, index, documentID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.IndexedDocument{}).
+		Where("index_name = ? AND document_id = ? AND deleted = ?", index, documentID, false).
+		Count(&count).Error
+	return count > 0, err
+}
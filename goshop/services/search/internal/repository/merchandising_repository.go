@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/search/internal/model"
+	"gorm.io/gorm"
+)
+
+// MerchandisingRepository 定义同义词和运营规则的仓库接口
+type MerchandisingRepository interface {
+	ListSynonyms(ctx context.Context, index string) ([]*model.Synonym, error)
+	GetSynonym(ctx context.Context, id uint) (*model.Synonym, error)
+	SaveSynonym(ctx context.Context, synonym *model.Synonym) error
+	DeleteSynonym(ctx context.Context, id uint) (bool, error)
+	ListRules(ctx context.Context, index string) ([]*model.MerchandisingRule, error)
+	ListEnabledRules(ctx context.Context) ([]*model.MerchandisingRule, error)
+	GetRule(ctx context.Context, id uint) (*model.MerchandisingRule, error)
+	SaveRule(ctx context.Context, rule *model.MerchandisingRule) error
+	DeleteRule(ctx context.Context, id uint) (bool, error)
+}
+
+// GormMerchandisingRepository 实现 MerchandisingRepository 接口的 GORM 仓库
+type GormMerchandisingRepository struct {
+	db *gorm.DB
+}
+
+// NewMerchandisingRepository 创建同义词和运营规则仓库实例
+func NewMerchandisingRepository(db *gorm.DB) MerchandisingRepository {
+	return &GormMerchandisingRepository{
+		db: db,
+	}
+}
+
+// ListSynonyms 获取索引的全部同义词，index 为空时返回所有索引的同义词
+func (r *GormMerchandisingRepository) ListSynonyms(ctx context.Context, index string) ([]*model.Synonym, error) {
+	var synonyms []*model.Synonym
+	query := r.db.WithContext(ctx).Order("id")
+	if index != "" {
+		query = query.Where("index_name = ?", index)
+	}
+	err := query.Find(&synonyms).Error
+	return synonyms, err
+}
+
+// GetSynonym 根据 ID 获取同义词
+func (r *GormMerchandisingRepository) GetSynonym(ctx context.Context, id uint) (*model.Synonym, error) {
+	var synonym model.Synonym
+	err := r.db.WithContext(ctx).First(&synonym, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &synonym, nil
+}
+
+// SaveSynonym 创建或更新同义词
+func (r *GormMerchandisingRepository) SaveSynonym(ctx context.Context, synonym *model.Synonym) error {
+	return r.db.WithContext(ctx).Save(synonym).Error
+}
+
+// DeleteSynonym 删除同义词，不存在时返回 false
+func (r *GormMerchandisingRepository) DeleteSynonym(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&model.Synonym{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListRules 获取索引的运营规则，index 为空时返回所有索引的规则
+func (r *GormMerchandisingRepository) ListRules(ctx context.Context, index string) ([]*model.MerchandisingRule, error) {
+	var rules []*model.MerchandisingRule
+	query := r.db.WithContext(ctx).Order("priority DESC, id")
+	if index != "" {
+		query = query.Where("index_name = ?", index)
+	}
+	err := query.Find(&rules).Error
+	return rules, err
+}
+
+// ListEnabledRules 获取所有启用的运营规则，优先级高的在前
+func (r *GormMerchandisingRepository) ListEnabledRules(ctx context.Context) ([]*model.MerchandisingRule, error) {
+	var rules []*model.MerchandisingRule
+	err := r.db.WithContext(ctx).Where("enabled = ?", true).Order("priority DESC, id").Find(&rules).Error
+	return rules, err
+}
+
+// GetRule 根据 ID 获取运营规则
+func (r *GormMerchandisingRepository) GetRule(ctx context.Context, id uint) (*model.MerchandisingRule, error) {
+	var rule model.MerchandisingRule
+	err := r.db.WithContext(ctx).First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// SaveRule 创建或更新运营规则
+func (r *GormMerchandisingRepository) SaveRule(ctx context.Context, rule *model.MerchandisingRule) error {
+	return r.db.WithContext(ctx).Save(rule).Error
+}
+
+// DeleteRule 删除运营规则，不存在时返回 false
+func (r *GormMerchandisingRepository) DeleteRule(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&model.MerchandisingRule{}, id)
+	return result.RowsAffected > 0, result.Error
+}
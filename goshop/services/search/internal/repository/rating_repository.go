@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yourusername/goshop/services/search/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RatingRepository 定义商品评分汇总的仓库接口
+type RatingRepository interface {
+	Get(ctx context.Context, productID uint) (*model.ProductRating, error)
+	Save(ctx context.Context, rating *model.ProductRating) (bool, error)
+}
+
+// GormRatingRepository 实现 RatingRepository 接口的 GORM 仓库
+type GormRatingRepository struct {
+	db *gorm.DB
+}
+
+// NewRatingRepository 创建评分汇总仓库实例
+func NewRatingRepository(db *gorm.DB) RatingRepository {
+	return &GormRatingRepository{
+		db: db,
+	}
+}
+
+// Get 获取商品的评分汇总，商品还没有评价时返回 nil
+func (r *GormRatingRepository) Get(ctx context.Context, productID uint) (*model.ProductRating, error) {
+	var rating model.ProductRating
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).First(&rating).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// Save 保存评分汇总，只会用更新的汇总覆盖已有记录，汇总比已保存的旧时返回 false
+func (r *GormRatingRepository) Save(ctx context.Context, rating *model.ProductRating) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"average", "review_count", "updated_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "product_ratings.updated_at < excluded.updated_at"},
+			}},
+		}).
+		Create(rating)
+	return result.RowsAffected > 0, result.Error
+}
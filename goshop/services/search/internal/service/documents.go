@@ -0,0 +1,122 @@
+package service
+
+import (
+	"strconv"
+
+	"github.com/yourusername/goshop/services/search/internal/engine"
+	"github.com/yourusername/goshop/services/search/internal/event"
+	"github.com/yourusername/goshop/services/search/internal/model"
+)
+
+// indexSettings 返回各索引的字段设置，可检索字段按权重从高到低排列
+func indexSettings(typo engine.TypoTolerance) map[string]engine.IndexSettings {
+	return map[string]engine.IndexSettings{
+		model.IndexProducts: {
+			Searchable: []string{"name", "brand", "categories", "tags", "sku_codes", "short_description", "description"},
+			Filterable: []string{"id", "type", "brand", "brand_id", "categories", "category_ids", "tags", "price", "in_stock", "rating"},
+			Sortable:   []string{"price", "created_at", "rating", "review_count"},
+			Typo:       typo,
+		},
+		model.IndexContents: {
+			Searchable: []string{"title", "tags", "categories", "excerpt", "content"},
+			Filterable: []string{"id", "type", "tags", "categories", "category_ids"},
+			Sortable:   []string{"published_at"},
+			Typo:       typo,
+		},
+	}
+}
+
+// productDocument 将商品快照和评分汇总转换为索引文档，价格为当前生效的售价，
+// 商品还没有评价时 rating 为 nil
+func productDocument(evt *event.ProductEvent, rating *model.ProductRating) engine.Document {
+	price := evt.RegularPrice
+	if evt.SalePrice != nil {
+		price = *evt.SalePrice
+	}
+	skuCodes := make([]string, 0, len(evt.SKUs))
+	inStock := false
+	for _, sku := range evt.SKUs {
+		skuCodes = append(skuCodes, sku.SKUCode)
+		if sku.StockQty > 0 {
+			inStock = true
+		}
+	}
+	names, ids := splitRefs(evt.Categories)
+	doc := engine.Document{
+		"id":                documentID(evt.ID),
+		"name":              evt.Name,
+		"short_description": evt.ShortDescription,
+		"description":       evt.Description,
+		"type":              evt.Type,
+		"price":             price,
+		"regular_price":     evt.RegularPrice,
+		"on_sale":           evt.SalePrice != nil,
+		"categories":        names,
+		"category_ids":      ids,
+		"tags":              nonNil(evt.Tags),
+		"sku_codes":         skuCodes,
+		"in_stock":          inStock,
+		"created_at":        evt.CreatedAt.Unix(),
+	}
+	for k, v := range ratingFields(rating) {
+		doc[k] = v
+	}
+	if evt.Brand != nil {
+		doc["brand"] = evt.Brand.Name
+		doc["brand_id"] = evt.Brand.ID
+	}
+	if len(evt.Images) > 0 {
+		doc["image"] = evt.Images[0]
+	}
+	return doc
+}
+
+// ratingFields 返回商品文档中的评分字段
+func ratingFields(rating *model.ProductRating) engine.Document {
+	if rating == nil {
+		return engine.Document{"rating": 0, "review_count": 0}
+	}
+	return engine.Document{"rating": rating.Average, "review_count": rating.ReviewCount}
+}
+
+// contentDocument 将已发布的内容转换为索引文档
+func contentDocument(evt *event.ContentEvent) engine.Document {
+	names, ids := splitRefs(evt.Categories)
+	doc := engine.Document{
+		"id":           documentID(evt.ID),
+		"type":         evt.Type,
+		"title":        evt.Title,
+		"slug":         evt.Slug,
+		"excerpt":      evt.Excerpt,
+		"content":      evt.Content,
+		"tags":         nonNil(evt.Tags),
+		"categories":   names,
+		"category_ids": ids,
+		"published_at": evt.PublishedAt.Unix(),
+	}
+	if evt.CoverImage != nil {
+		doc["cover_image"] = *evt.CoverImage
+	}
+	return doc
+}
+
+func documentID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func splitRefs(refs []event.NamedRef) ([]string, []uint) {
+	names := make([]string, 0, len(refs))
+	ids := make([]uint, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, ref.Name)
+		ids = append(ids, ref.ID)
+	}
+	return names, ids
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
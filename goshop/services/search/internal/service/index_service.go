@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/search/internal/engine"
+	"github.com/yourusername/goshop/services/search/internal/event"
+	"github.com/yourusername/goshop/services/search/internal/model"
+	"github.com/yourusername/goshop/services/search/internal/repository"
+	"go.uber.org/zap"
+)
+
+// productStatusActive 是商品上架状态，只有上架商品会出现在搜索结果中
+const productStatusActive = "active"
+
+// IndexService 负责索引的创建和设置，并根据商品、CMS 和评价事件维护索引中的文档
+type IndexService struct {
+	engine       engine.Engine
+	documentRepo repository.DocumentRepository
+	ratingRepo   repository.RatingRepository
+	settings     map[string]engine.IndexSettings
+	log          *logger.Logger
+}
+
+// NewIndexService 创建索引服务
+func NewIndexService(eng engine.Engine, documentRepo repository.DocumentRepository, ratingRepo repository.RatingRepository, typo engine.TypoTolerance, log *logger.Logger) *IndexService {
+	return &IndexService{
+		engine:       eng,
+		documentRepo: documentRepo,
+		ratingRepo:   ratingRepo,
+		settings:     indexSettings(typo),
+		log:          log,
+	}
+}
+
+// EnsureIndexes 创建所有索引并应用字段设置，服务启动和设置变更后调用
+func (s *IndexService) EnsureIndexes(ctx context.Context) error {
+	for _, index := range model.Indexes {
+		if err := s.engine.EnsureIndex(ctx, index, s.settings[index]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe 订阅商品、内容和评分汇总变更事件
+func (s *IndexService) Subscribe(consumer *events.Consumer) error {
+	handlers := map[string]events.Handler{
+		event.ProductCreated:       s.handleProduct,
+		event.ProductUpdated:       s.handleProduct,
+		event.ProductDeleted:       s.handleProductDeleted,
+		event.ContentPublished:     s.handleContentPublished,
+		event.ContentUnpublished:   s.handleContentUnpublished,
+		event.ReviewSummaryUpdated: s.handleReviewSummary,
+	}
+	for eventType, handler := range handlers {
+		if err := consumer.Subscribe(eventType, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleProduct 写入上架商品，其他状态的商品从索引中移除
+func (s *IndexService) handleProduct(ctx context.Context, env *events.Envelope) error {
+	var evt event.ProductEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	if evt.Status != productStatusActive {
+		return s.apply(ctx, model.IndexProducts, documentID(evt.ID), evt.UpdatedAt, nil)
+	}
+	rating, err := s.ratingRepo.Get(ctx, evt.ID)
+	if err != nil {
+		return err
+	}
+	return s.apply(ctx, model.IndexProducts, documentID(evt.ID), evt.UpdatedAt, productDocument(&evt, rating))
+}
+
+func (s *IndexService) handleProductDeleted(ctx context.Context, env *events.Envelope) error {
+	var evt event.ProductDeletedEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	return s.apply(ctx, model.IndexProducts, documentID(evt.ID), evt.DeletedAt, nil)
+}
+
+func (s *IndexService) handleContentPublished(ctx context.Context, env *events.Envelope) error {
+	var evt event.ContentEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	return s.apply(ctx, model.IndexContents, documentID(evt.ID), evt.UpdatedAt, contentDocument(&evt))
+}
+
+func (s *IndexService) handleContentUnpublished(ctx context.Context, env *events.Envelope) error {
+	var evt event.ContentUnpublishedEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	return s.apply(ctx, model.IndexContents, documentID(evt.ID), evt.UpdatedAt, nil)
+}
+
+// handleReviewSummary 保存商品评分汇总，商品在索引中时只更新文档的评分字段。
+// 未上架的商品只保存汇总，上架索引时写入
+func (s *IndexService) handleReviewSummary(ctx context.Context, env *events.Envelope) error {
+	var evt event.ReviewSummaryEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	rating := &model.ProductRating{
+		ProductID:   evt.ProductID,
+		Average:     evt.Average,
+		ReviewCount: evt.ReviewCount,
+		UpdatedAt:   evt.UpdatedAt,
+	}
+	saved, err := s.ratingRepo.Save(ctx, rating)
+	if err != nil || !saved {
+		return err
+	}
+
+	id := documentID(evt.ProductID)
+	indexed, err := s.documentRepo.IsIndexed(ctx, model.IndexProducts, id)
+	if err != nil || !indexed {
+		return err
+	}
+	doc := ratingFields(rating)
+	doc["id"] = id
+	return s.engine.Update(ctx, model.IndexProducts, []engine.Document{doc})
+}
+
+// apply 写入文档，doc 为 nil 时删除文档。比已索引版本旧的变更会被跳过，
+// 避免乱序到达的事件用旧数据覆盖新数据
+func (s *IndexService) apply(ctx context.Context, index, id string, version time.Time, doc engine.Document) error {
+	stale, err := s.documentRepo.IsStale(ctx, index, id, version)
+	if err != nil {
+		return err
+	}
+	if stale {
+		s.log.Info(ctx, "Skipped stale document change",
+			zap.String("index", index),
+			zap.String("document_id", id),
+			zap.Time("version", version),
+		)
+		return nil
+	}
+
+	if doc == nil {
+		err = s.engine.Delete(ctx, index, []string{id})
+	} else {
+		err = s.engine.Upsert(ctx, index, []engine.Document{doc})
+	}
+	if err != nil {
+		return err
+	}
+	return s.documentRepo.Record(ctx, &model.IndexedDocument{
+		Index:      index,
+		DocumentID: id,
+		Version:    version,
+		Deleted:    doc == nil,
+		UpdatedAt:  time.Now(),
+	})
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/search/internal/engine"
+	"github.com/yourusername/goshop/services/search/internal/model"
+	"github.com/yourusername/goshop/services/search/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SynonymRequest 表示创建或更新同义词的请求，Root 为空时 Terms 互为同义词
+type SynonymRequest struct {
+	Index string   `json:"index" binding:"required,oneof=products contents"`
+	Root  string   `json:"root" binding:"max=100"`
+	Terms []string `json:"terms" binding:"required,min=1,max=20,dive,required,max=100"`
+}
+
+// RuleRequest 表示创建或更新运营规则的请求
+type RuleRequest struct {
+	Name     string     `json:"name" binding:"required,max=100"`
+	Index    string     `json:"index" binding:"required,oneof=products contents"`
+	Query    string     `json:"query" binding:"required,max=100"`
+	Match    string     `json:"match" binding:"omitempty,oneof=exact contains"` // 默认 exact
+	Pinned   []string   `json:"pinned" binding:"max=20,dive,required,max=50"`
+	Buried   []string   `json:"buried" binding:"max=50,dive,required,max=50"`
+	Priority int        `json:"priority"`
+	Enabled  bool       `json:"enabled"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+// MerchandisingService 负责同义词和运营规则的管理。启用的运营规则缓存在内存中，
+// 修改后立即刷新本实例的缓存，其他实例在下一次定时刷新时生效
+type MerchandisingService struct {
+	merchandisingRepo repository.MerchandisingRepository
+	engine            engine.Engine
+	log               *logger.Logger
+
+	mu    sync.RWMutex
+	rules []*model.MerchandisingRule
+}
+
+// NewMerchandisingService 创建同义词和运营规则服务
+func NewMerchandisingService(merchandisingRepo repository.MerchandisingRepository, eng engine.Engine, log *logger.Logger) *MerchandisingService {
+	return &MerchandisingService{
+		merchandisingRepo: merchandisingRepo,
+		engine:            eng,
+		log:               log,
+	}
+}
+
+// Run 定时刷新运营规则缓存，直到 ctx 被取消
+func (s *MerchandisingService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil {
+			s.log.Error(ctx, "Failed to refresh merchandising rules", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh 从数据库重新加载启用的运营规则
+func (s *MerchandisingService) Refresh(ctx context.Context) error {
+	rules, err := s.merchandisingRepo.ListEnabledRules(ctx)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.rules = rules
+	s.mu.Unlock()
+	return nil
+}
+
+// Match 返回对规范化后的查询生效的优先级最高的运营规则，没有时返回 nil
+func (s *MerchandisingService) Match(index, query string) *model.MerchandisingRule {
+	now := time.Now()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, rule := range s.rules {
+		if rule.Matches(index, query, now) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// SyncSynonyms 将数据库中的同义词同步到搜索引擎，服务启动和同义词变更后调用
+func (s *MerchandisingService) SyncSynonyms(ctx context.Context, index string) error {
+	synonyms, err := s.merchandisingRepo.ListSynonyms(ctx, index)
+	if err != nil {
+		return err
+	}
+	return s.engine.SetSynonyms(ctx, index, synonymMap(synonyms))
+}
+
+// ListSynonyms 获取同义词，index 为空时返回所有索引的同义词
+func (s *MerchandisingService) ListSynonyms(ctx context.Context, index string) ([]*model.Synonym, error) {
+	synonyms, err := s.merchandisingRepo.ListSynonyms(ctx, index)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取同义词失败", err)
+	}
+	return synonyms, nil
+}
+
+// CreateSynonym 创建同义词并同步到搜索引擎
+func (s *MerchandisingService) CreateSynonym(ctx context.Context, req *SynonymRequest) (*model.Synonym, error) {
+	synonym := &model.Synonym{}
+	if err := s.saveSynonym(ctx, synonym, req); err != nil {
+		return nil, err
+	}
+	return synonym, nil
+}
+
+// UpdateSynonym 更新同义词并同步到搜索引擎
+func (s *MerchandisingService) UpdateSynonym(ctx context.Context, id uint, req *SynonymRequest) (*model.Synonym, error) {
+	synonym, err := s.merchandisingRepo.GetSynonym(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("同义词不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取同义词失败", err)
+	}
+	previous := synonym.Index
+	if err := s.saveSynonym(ctx, synonym, req); err != nil {
+		return nil, err
+	}
+	if previous != synonym.Index {
+		if err := s.SyncSynonyms(ctx, previous); err != nil {
+			return nil, apperrors.NewServiceUnavailable("同步同义词失败", err)
+		}
+	}
+	return synonym, nil
+}
+
+// DeleteSynonym 删除同义词并同步到搜索引擎
+func (s *MerchandisingService) DeleteSynonym(ctx context.Context, id uint) error {
+	synonym, err := s.merchandisingRepo.GetSynonym(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NewNotFound("同义词不存在", err)
+		}
+		return apperrors.NewInternalServerError("获取同义词失败", err)
+	}
+	if _, err := s.merchandisingRepo.DeleteSynonym(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除同义词失败", err)
+	}
+	if err := s.SyncSynonyms(ctx, synonym.Index); err != nil {
+		return apperrors.NewServiceUnavailable("同步同义词失败", err)
+	}
+	return nil
+}
+
+func (s *MerchandisingService) saveSynonym(ctx context.Context, synonym *model.Synonym, req *SynonymRequest) error {
+	root := model.NormalizeQuery(req.Root)
+	terms := make(model.StringArray, 0, len(req.Terms))
+	seen := make(map[string]bool, len(req.Terms))
+	for _, term := range req.Terms {
+		term = model.NormalizeQuery(term)
+		if term == "" || term == root || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	if root == "" && len(terms) < 2 {
+		return apperrors.NewBadRequest("双向同义词至少需要两个不同的词", nil)
+	}
+	if root != "" && len(terms) == 0 {
+		return apperrors.NewBadRequest("单向同义词至少需要一个不同于 root 的词", nil)
+	}
+
+	synonym.Index = req.Index
+	synonym.Root = root
+	synonym.Terms = terms
+	if err := s.merchandisingRepo.SaveSynonym(ctx, synonym); err != nil {
+		return apperrors.NewInternalServerError("保存同义词失败", err)
+	}
+	if err := s.SyncSynonyms(ctx, synonym.Index); err != nil {
+		return apperrors.NewServiceUnavailable("同步同义词失败", err)
+	}
+	return nil
+}
+
+// ListRules 获取运营规则，index 为空时返回所有索引的规则
+func (s *MerchandisingService) ListRules(ctx context.Context, index string) ([]*model.MerchandisingRule, error) {
+	rules, err := s.merchandisingRepo.ListRules(ctx, index)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取运营规则失败", err)
+	}
+	return rules, nil
+}
+
+// GetRule 获取运营规则
+func (s *MerchandisingService) GetRule(ctx context.Context, id uint) (*model.MerchandisingRule, error) {
+	rule, err := s.merchandisingRepo.GetRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("运营规则不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取运营规则失败", err)
+	}
+	return rule, nil
+}
+
+// CreateRule 创建运营规则
+func (s *MerchandisingService) CreateRule(ctx context.Context, req *RuleRequest) (*model.MerchandisingRule, error) {
+	rule := &model.MerchandisingRule{}
+	if err := s.saveRule(ctx, rule, req); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule 更新运营规则
+func (s *MerchandisingService) UpdateRule(ctx context.Context, id uint, req *RuleRequest) (*model.MerchandisingRule, error) {
+	rule, err := s.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveRule(ctx, rule, req); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule 删除运营规则
+func (s *MerchandisingService) DeleteRule(ctx context.Context, id uint) error {
+	deleted, err := s.merchandisingRepo.DeleteRule(ctx, id)
+	if err != nil {
+		return apperrors.NewInternalServerError("删除运营规则失败", err)
+	}
+	if !deleted {
+		return apperrors.NewNotFound("运营规则不存在", nil)
+	}
+	s.refreshAfterWrite(ctx)
+	return nil
+}
+
+func (s *MerchandisingService) saveRule(ctx context.Context, rule *model.MerchandisingRule, req *RuleRequest) error {
+	if len(req.Pinned) == 0 && len(req.Buried) == 0 {
+		return apperrors.NewBadRequest("置顶和沉底的文档不能都为空", nil)
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return apperrors.NewBadRequest("结束时间必须晚于开始时间", nil)
+	}
+	query := model.NormalizeQuery(req.Query)
+	if query == "" {
+		return apperrors.NewBadRequest("查询词不能为空", nil)
+	}
+	pinned := make(map[string]bool, len(req.Pinned))
+	for _, id := range req.Pinned {
+		if pinned[id] {
+			return apperrors.NewBadRequest(fmt.Sprintf("置顶文档 %s 重复", id), nil)
+		}
+		pinned[id] = true
+	}
+	for _, id := range req.Buried {
+		if pinned[id] {
+			return apperrors.NewBadRequest(fmt.Sprintf("文档 %s 不能同时置顶和沉底", id), nil)
+		}
+	}
+	match := req.Match
+	if match == "" {
+		match = model.MatchExact
+	}
+
+	rule.Name = req.Name
+	rule.Index = req.Index
+	rule.Query = query
+	rule.Match = match
+	rule.Pinned = req.Pinned
+	rule.Buried = req.Buried
+	rule.Priority = req.Priority
+	rule.Enabled = req.Enabled
+	rule.StartsAt = req.StartsAt
+	rule.EndsAt = req.EndsAt
+	if err := s.merchandisingRepo.SaveRule(ctx, rule); err != nil {
+		return apperrors.NewInternalServerError("保存运营规则失败", err)
+	}
+	s.refreshAfterWrite(ctx)
+	return nil
+}
+
+// refreshAfterWrite 在规则变更后刷新本实例的缓存，失败时等待定时刷新
+func (s *MerchandisingService) refreshAfterWrite(ctx context.Context) {
+	if err := s.Refresh(ctx); err != nil {
+		s.log.Warn(ctx, "Failed to refresh merchandising rules", zap.Error(err))
+	}
+}
+
+// synonymMap 将同义词组转换为引擎使用的搜索词到同义词的映射
+func synonymMap(synonyms []*model.Synonym) map[string][]string {
+	m := make(map[string][]string)
+	add := func(word string, terms ...string) {
+		for _, term := range terms {
+			if term == word || containsString(m[word], term) {
+				continue
+			}
+			m[word] = append(m[word], term)
+		}
+	}
+	for _, synonym := range synonyms {
+		if synonym.Root != "" {
+			add(synonym.Root, synonym.Terms...)
+			continue
+		}
+		for _, term := range synonym.Terms {
+			add(term, synonym.Terms...)
+		}
+	}
+	return m
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/search/internal/engine"
+	"github.com/yourusername/goshop/services/search/internal/model"
+)
+
+// maxPageSize 是每页最多返回的结果数
+const maxPageSize = 100
+
+// SearchRequest 表示一次搜索，过滤条件和排序由调用方按索引的字段设置组装
+type SearchRequest struct {
+	Text     string
+	Filter   []engine.Filter
+	Sort     []string
+	Facets   []string
+	Page     int
+	PageSize int
+}
+
+// SearchResponse 表示搜索结果
+type SearchResponse struct {
+	Hits     []engine.Document         `json:"hits"`
+	Total    int                       `json:"total"`
+	Page     int                       `json:"page"`
+	PageSize int                       `json:"page_size"`
+	Facets   map[string]map[string]int `json:"facets,omitempty"`
+	RuleID   *uint                     `json:"rule_id,omitempty"` // 生效的运营规则
+}
+
+// SearchService 负责搜索查询，并在按相关度排序时应用运营规则
+type SearchService struct {
+	engine        engine.Engine
+	merchandising *MerchandisingService
+}
+
+// NewSearchService 创建搜索服务
+func NewSearchService(eng engine.Engine, merchandising *MerchandisingService) *SearchService {
+	return &SearchService{
+		engine:        eng,
+		merchandising: merchandising,
+	}
+}
+
+// Search 搜索索引。运营规则只在按相关度排序时生效：置顶的文档只要满足过滤条件就按规则顺序
+// 排在最前，沉底的文档排在其他结果之后。分面统计不包含置顶和沉底的文档
+func (s *SearchService) Search(ctx context.Context, index string, req *SearchRequest) (*SearchResponse, error) {
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+	offset := (page - 1) * pageSize
+
+	var rule *model.MerchandisingRule
+	if len(req.Sort) == 0 {
+		rule = s.merchandising.Match(index, model.NormalizeQuery(req.Text))
+	}
+	if rule == nil {
+		result, err := s.engine.Search(ctx, index, &engine.Query{
+			Text:   req.Text,
+			Filter: req.Filter,
+			Sort:   req.Sort,
+			Facets: req.Facets,
+			Offset: offset,
+			Limit:  pageSize,
+		})
+		if err != nil {
+			return nil, searchError(err)
+		}
+		return &SearchResponse{
+			Hits:     result.Hits,
+			Total:    result.Total,
+			Page:     page,
+			PageSize: pageSize,
+			Facets:   result.Facets,
+		}, nil
+	}
+
+	resp, err := s.searchWithRule(ctx, index, req, rule, offset, pageSize)
+	if err != nil {
+		return nil, searchError(err)
+	}
+	resp.Page = page
+	resp.PageSize = pageSize
+	resp.RuleID = &rule.ID
+	return resp, nil
+}
+
+// searchWithRule 将结果看作置顶、普通和沉底三段依次拼接，再从中截取当前页
+func (s *SearchService) searchWithRule(ctx context.Context, index string, req *SearchRequest, rule *model.MerchandisingRule, offset, limit int) (*SearchResponse, error) {
+	var pinned []engine.Document
+	if len(rule.Pinned) > 0 {
+		result, err := s.engine.Search(ctx, index, &engine.Query{
+			Filter: withIDs(req.Filter, engine.FilterIn, rule.Pinned),
+			Limit:  len(rule.Pinned),
+		})
+		if err != nil {
+			return nil, err
+		}
+		pinned = orderByIDs(result.Hits, rule.Pinned)
+	}
+
+	hits := make([]engine.Document, 0, limit)
+	if offset < len(pinned) {
+		hits = append(hits, pinned[offset:min(offset+limit, len(pinned))]...)
+	}
+
+	excluded := append(append([]string{}, rule.Pinned...), rule.Buried...)
+	regular, err := s.engine.Search(ctx, index, &engine.Query{
+		Text:   req.Text,
+		Filter: withIDs(req.Filter, engine.FilterNotIn, excluded),
+		Facets: req.Facets,
+		Offset: max(0, offset-len(pinned)),
+		Limit:  limit - len(hits),
+	})
+	if err != nil {
+		return nil, err
+	}
+	hits = append(hits, regular.Hits...)
+	total := len(pinned) + regular.Total
+
+	if len(rule.Buried) > 0 {
+		buried, err := s.engine.Search(ctx, index, &engine.Query{
+			Text:   req.Text,
+			Filter: withIDs(req.Filter, engine.FilterIn, rule.Buried),
+			Offset: max(0, offset-total),
+			Limit:  limit - len(hits),
+		})
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, buried.Hits...)
+		total += buried.Total
+	}
+
+	return &SearchResponse{
+		Hits:   hits,
+		Total:  total,
+		Facets: regular.Facets,
+	}, nil
+}
+
+// withIDs 在过滤条件中追加文档 ID 条件
+func withIDs(filter []engine.Filter, op engine.FilterOp, ids []string) []engine.Filter {
+	if len(ids) == 0 {
+		return filter
+	}
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	out := make([]engine.Filter, 0, len(filter)+1)
+	out = append(out, filter...)
+	return append(out, engine.Filter{Field: "id", Op: op, Values: values})
+}
+
+// orderByIDs 按给定 ID 的顺序排列文档，不在结果中的 ID 被跳过
+func orderByIDs(docs []engine.Document, ids []string) []engine.Document {
+	byID := make(map[string]engine.Document, len(docs))
+	for _, doc := range docs {
+		byID[doc.ID()] = doc
+	}
+	ordered := make([]engine.Document, 0, len(docs))
+	for _, id := range ids {
+		if doc, ok := byID[id]; ok {
+			ordered = append(ordered, doc)
+		}
+	}
+	return ordered
+}
+
+func searchError(err error) error {
+	if errors.Is(err, engine.ErrUnavailable) {
+		return apperrors.NewServiceUnavailable("搜索服务暂不可用", err)
+	}
+	return apperrors.NewInternalServerError("搜索失败", err)
+}
+
+// normalizePage 规范化分页参数
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
@@ -0,0 +1,100 @@
+package event
+
+import "time"
+
+// 商家服务订阅的事件类型
+const (
+	OrderPaid      = "order.paid"
+	OrderCancelled = "order.cancelled"
+	OrderDelivered = "order.delivered"
+	OrderRefunded  = "order.refunded"
+	ProductCreated = "product.created"
+	ProductDeleted = "product.deleted"
+)
+
+// 商家服务发布的事件类型，订单服务据此汇总各商家的履约进度，通知服务据此通知商家和买家
+const (
+	SellerApproved    = "seller.approved"
+	SubOrderCreated   = "seller.suborder_created"
+	SubOrderShipped   = "seller.suborder_shipped"
+	SubOrderDelivered = "seller.suborder_delivered"
+	SubOrderCancelled = "seller.suborder_cancelled"
+	PayoutCreated     = "seller.payout_created"
+)
+
+// OrderItem 表示订单事件中的商品行
+type OrderItem struct {
+	ProductID   uint    `json:"product_id"`
+	SKUID       uint    `json:"sku_id"`
+	CategoryIDs []uint  `json:"category_ids"`
+	Quantity    int     `json:"quantity"`
+	Total       float64 `json:"total"` // 商品行实付金额
+}
+
+// OrderEvent 是 order.paid、order.cancelled 和 order.delivered 事件的数据
+type OrderEvent struct {
+	OrderID     uint        `json:"order_id"`
+	OrderNumber string      `json:"order_number"`
+	UserID      uint        `json:"user_id"`
+	GrandTotal  float64     `json:"grand_total"`
+	Items       []OrderItem `json:"items"`
+}
+
+// RefundItem 表示退款中的商品行
+type RefundItem struct {
+	ProductID uint    `json:"product_id"`
+	Amount    float64 `json:"amount"`
+}
+
+// OrderRefundEvent 是 order.refunded 事件的数据。按商品退款时 Items 列出退款的商品行，
+// 为空时退款金额按各商家的实付金额占订单总额的比例分摊
+type OrderRefundEvent struct {
+	OrderID      uint         `json:"order_id"`
+	OrderNumber  string       `json:"order_number"`
+	UserID       uint         `json:"user_id"`
+	RefundID     string       `json:"refund_id"`
+	RefundAmount float64      `json:"refund_amount"`
+	GrandTotal   float64      `json:"grand_total"`
+	Items        []RefundItem `json:"items"`
+}
+
+// ProductEvent 是 product.created 事件中商家服务关心的部分，商家发布的商品带有 SellerID
+type ProductEvent struct {
+	ID       uint  `json:"id"`
+	SellerID *uint `json:"seller_id"`
+}
+
+// ProductDeletedEvent 是 product.deleted 事件的数据
+type ProductDeletedEvent struct {
+	ID uint `json:"id"`
+}
+
+// SellerEvent 是 seller.approved 事件的数据
+type SellerEvent struct {
+	SellerID     uint   `json:"seller_id"`
+	UserID       uint   `json:"user_id"`
+	ShopName     string `json:"shop_name"`
+	ContactEmail string `json:"contact_email"`
+}
+
+// SubOrderEvent 是子订单事件的数据
+type SubOrderEvent struct {
+	SubOrderID     uint      `json:"sub_order_id"`
+	OrderID        uint      `json:"order_id"`
+	OrderNumber    string    `json:"order_number"`
+	SellerID       uint      `json:"seller_id"`
+	UserID         uint      `json:"user_id"`
+	Status         string    `json:"status"`
+	Subtotal       float64   `json:"subtotal"`
+	Carrier        string    `json:"carrier,omitempty"`
+	TrackingNumber string    `json:"tracking_number,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// PayoutEvent 是 seller.payout_created 事件的数据
+type PayoutEvent struct {
+	PayoutID uint    `json:"payout_id"`
+	SellerID uint    `json:"seller_id"`
+	Amount   float64 `json:"amount"`
+	Method   string  `json:"method"`
+}
@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/seller/internal/repository"
+	"github.com/yourusername/goshop/services/seller/internal/service"
+)
+
+// AdminHandler 处理后台商家审核、商品归属、佣金规则、子订单和打款管理的 HTTP 请求
+type AdminHandler struct {
+	sellerService   *service.SellerService
+	subOrderService *service.SubOrderService
+	ledgerService   *service.LedgerService
+}
+
+// NewAdminHandler 创建后台商家处理器
+func NewAdminHandler(sellerService *service.SellerService, subOrderService *service.SubOrderService, ledgerService *service.LedgerService) *AdminHandler {
+	return &AdminHandler{
+		sellerService:   sellerService,
+		subOrderService: subOrderService,
+		ledgerService:   ledgerService,
+	}
+}
+
+// RegisterRoutes 注册后台商家路由
+func (h *AdminHandler) RegisterRoutes(api *gin.RouterGroup) {
+	admin := api.Group("/sellers/admin")
+	{
+		admin.GET("/sellers", h.ListSellers)
+		admin.GET("/sellers/:id", h.GetSeller)
+		admin.POST("/sellers/:id/approve", h.ApproveSeller)
+		admin.POST("/sellers/:id/reject", h.RejectSeller)
+		admin.POST("/sellers/:id/suspend", h.SuspendSeller)
+		admin.PUT("/sellers/:id/commission", h.SetCommissionRate)
+		admin.GET("/sellers/:id/ledger", h.Ledger)
+		admin.POST("/sellers/:id/adjustments", h.Adjust)
+
+		admin.PUT("/products/:product_id", h.AssignProduct)
+		admin.DELETE("/products/:product_id", h.RemoveProduct)
+
+		admin.GET("/commission-rules", h.ListCommissionRules)
+		admin.PUT("/commission-rules", h.SaveCommissionRule)
+		admin.DELETE("/commission-rules/:category_id", h.DeleteCommissionRule)
+
+		admin.GET("/orders", h.ListOrders)
+		admin.GET("/orders/:id", h.GetOrder)
+		admin.POST("/orders/:id/deliver", h.DeliverOrder)
+
+		admin.GET("/payouts", h.ListPayouts)
+		admin.POST("/payouts/:id/paid", h.MarkPayoutPaid)
+		admin.POST("/payouts/:id/failed", h.FailPayout)
+	}
+}
+
+// ListSellers 分页获取商家，status=pending 获取待审核的入驻申请
+func (h *AdminHandler) ListSellers(c *gin.Context) {
+	list, err := h.sellerService.List(c.Request.Context(), c.Query("status"),
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetSeller 获取商家
+func (h *AdminHandler) GetSeller(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	seller, err := h.sellerService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": seller})
+}
+
+// ApproveSeller 审核通过入驻申请或恢复停业的商家
+func (h *AdminHandler) ApproveSeller(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	seller, err := h.sellerService.Approve(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": seller})
+}
+
+// RejectSeller 拒绝入驻申请
+func (h *AdminHandler) RejectSeller(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.SellerStatusRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	seller, err := h.sellerService.Reject(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": seller})
+}
+
+// SuspendSeller 停业商家
+func (h *AdminHandler) SuspendSeller(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.SellerStatusRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	seller, err := h.sellerService.Suspend(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": seller})
+}
+
+// SetCommissionRate 设置商家单独约定的佣金比例
+func (h *AdminHandler) SetCommissionRate(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.SellerCommissionRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	seller, err := h.sellerService.SetCommissionRate(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": seller})
+}
+
+// Ledger 获取商家的账本
+func (h *AdminHandler) Ledger(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	ledger, err := h.ledgerService.AdminLedger(c.Request.Context(), id,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ledger})
+}
+
+// Adjust 为商家调账
+func (h *AdminHandler) Adjust(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.AdjustmentRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	entry, err := h.ledgerService.Adjust(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": entry})
+}
+
+// AssignProduct 登记商品归属的商家
+func (h *AdminHandler) AssignProduct(c *gin.Context) {
+	productID, ok := parseIDParam(c, "product_id")
+	if !ok {
+		return
+	}
+	var req service.AssignProductRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	product, err := h.sellerService.AssignProduct(c.Request.Context(), productID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": product})
+}
+
+// RemoveProduct 移除商品归属，商品改为平台自营
+func (h *AdminHandler) RemoveProduct(c *gin.Context) {
+	productID, ok := parseIDParam(c, "product_id")
+	if !ok {
+		return
+	}
+	if err := h.sellerService.RemoveProduct(c.Request.Context(), productID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListCommissionRules 获取分类佣金规则
+func (h *AdminHandler) ListCommissionRules(c *gin.Context) {
+	rules, err := h.sellerService.ListCommissionRules(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// SaveCommissionRule 设置分类佣金比例
+func (h *AdminHandler) SaveCommissionRule(c *gin.Context) {
+	var req service.CommissionRuleRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	rule, err := h.sellerService.SaveCommissionRule(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rule})
+}
+
+// DeleteCommissionRule 删除分类佣金规则，路径中的 category_id 为 0 时删除默认规则
+func (h *AdminHandler) DeleteCommissionRule(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("category_id"), 10, 64)
+	if err != nil {
+		respondError(c, apperrors.NewBadRequest("无效的 category_id", err))
+		return
+	}
+	if err := h.sellerService.DeleteCommissionRule(c.Request.Context(), uint(categoryID)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListOrders 分页获取子订单，可按 seller_id、order_id 和 status 过滤
+func (h *AdminHandler) ListOrders(c *gin.Context) {
+	filter := repository.SubOrderFilter{
+		SellerID: parseUintQuery(c, "seller_id"),
+		OrderID:  parseUintQuery(c, "order_id"),
+		Status:   c.Query("status"),
+	}
+	list, err := h.subOrderService.List(c.Request.Context(), filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetOrder 获取子订单
+func (h *AdminHandler) GetOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	subOrder, err := h.subOrderService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": subOrder})
+}
+
+// DeliverOrder 确认子订单已签收
+func (h *AdminHandler) DeliverOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	subOrder, err := h.subOrderService.Deliver(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": subOrder})
+}
+
+// ListPayouts 分页获取打款，status=pending 获取待财务打款的记录
+func (h *AdminHandler) ListPayouts(c *gin.Context) {
+	filter := repository.PayoutFilter{
+		SellerID: parseUintQuery(c, "seller_id"),
+		Status:   c.Query("status"),
+	}
+	list, err := h.ledgerService.ListPayouts(c.Request.Context(), filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// MarkPayoutPaid 登记打款完成
+func (h *AdminHandler) MarkPayoutPaid(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.PayoutPaidRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	payout, err := h.ledgerService.MarkPayoutPaid(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": payout})
+}
+
+// FailPayout 登记打款失败，金额退回商家余额
+func (h *AdminHandler) FailPayout(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.PayoutFailedRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	payout, err := h.ledgerService.FailPayout(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": payout})
+}
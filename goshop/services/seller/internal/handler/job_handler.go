@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/scheduler"
+	"github.com/yourusername/goshop/services/seller/internal/service"
+)
+
+// 任务回调相对于 /api/v1 的路径，注册任务时使用
+const (
+	SettleJobPath = "/sellers/internal/jobs/settle"
+	PayoutJobPath = "/sellers/internal/jobs/payouts"
+)
+
+// JobHandler 处理调度服务的任务回调，不经过网关
+type JobHandler struct {
+	ledgerService *service.LedgerService
+	token         string
+}
+
+// NewJobHandler 创建任务回调处理器，token 为空时不校验回调密钥，只用于开发环境
+func NewJobHandler(ledgerService *service.LedgerService, token string) *JobHandler {
+	return &JobHandler{
+		ledgerService: ledgerService,
+		token:         token,
+	}
+}
+
+// RegisterRoutes 注册任务回调路由
+func (h *JobHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.POST(SettleJobPath, scheduler.Callback(h.token, func(ctx context.Context, _ json.RawMessage) error {
+		return h.ledgerService.Settle(ctx)
+	}))
+	api.POST(PayoutJobPath, scheduler.Callback(h.token, func(ctx context.Context, _ json.RawMessage) error {
+		return h.ledgerService.CreatePayouts(ctx)
+	}))
+}
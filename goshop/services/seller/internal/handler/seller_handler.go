@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/seller/internal/service"
+)
+
+// SellerHandler 处理商家入驻、店铺管理、子订单履约和账本查询的 HTTP 请求，以及店铺公开资料
+type SellerHandler struct {
+	sellerService   *service.SellerService
+	subOrderService *service.SubOrderService
+	ledgerService   *service.LedgerService
+}
+
+// NewSellerHandler 创建商家处理器
+func NewSellerHandler(sellerService *service.SellerService, subOrderService *service.SubOrderService, ledgerService *service.LedgerService) *SellerHandler {
+	return &SellerHandler{
+		sellerService:   sellerService,
+		subOrderService: subOrderService,
+		ledgerService:   ledgerService,
+	}
+}
+
+// RegisterRoutes 注册商家路由，/sellers/me 下的路由只能访问当前用户自己的商家账户
+func (h *SellerHandler) RegisterRoutes(api *gin.RouterGroup) {
+	sellers := api.Group("/sellers")
+	{
+		sellers.POST("/apply", h.Apply)
+		sellers.GET("/shops/:id", h.GetShop)
+		sellers.GET("/products/:product_id", h.GetProductSeller)
+	}
+
+	me := api.Group("/sellers/me")
+	{
+		me.GET("", h.Me)
+		me.PUT("", h.UpdateProfile)
+		me.GET("/products", h.ListProducts)
+		me.GET("/orders", h.ListOrders)
+		me.GET("/orders/:id", h.GetOrder)
+		me.POST("/orders/:id/ship", h.ShipOrder)
+		me.GET("/ledger", h.Ledger)
+		me.GET("/payouts", h.ListPayouts)
+	}
+}
+
+// Apply 申请入驻
+func (h *SellerHandler) Apply(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.ApplySellerRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	seller, err := h.sellerService.Apply(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": seller})
+}
+
+// GetShop 获取店铺公开资料
+func (h *SellerHandler) GetShop(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	profile, err := h.sellerService.Profile(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": profile})
+}
+
+// GetProductSeller 获取商品所属店铺的公开资料
+func (h *SellerHandler) GetProductSeller(c *gin.Context) {
+	productID, ok := parseIDParam(c, "product_id")
+	if !ok {
+		return
+	}
+	profile, err := h.sellerService.ProductSeller(c.Request.Context(), productID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": profile})
+}
+
+// Me 获取当前用户的商家账户
+func (h *SellerHandler) Me(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	seller, err := h.sellerService.Me(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": seller})
+}
+
+// UpdateProfile 修改店铺资料和收款账户
+func (h *SellerHandler) UpdateProfile(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.UpdateSellerRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	seller, err := h.sellerService.UpdateProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": seller})
+}
+
+// ListProducts 分页获取商家的商品
+func (h *SellerHandler) ListProducts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	list, err := h.sellerService.ListProducts(c.Request.Context(), userID,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// ListOrders 分页获取商家的子订单，可按 status 过滤，如 status=awaiting_shipment 获取待发货的子订单
+func (h *SellerHandler) ListOrders(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	list, err := h.subOrderService.ListMine(c.Request.Context(), userID, c.Query("status"),
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetOrder 获取商家的子订单
+func (h *SellerHandler) GetOrder(c *gin.Context) {
+	userID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	subOrder, err := h.subOrderService.GetMine(c.Request.Context(), userID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": subOrder})
+}
+
+// ShipOrder 子订单发货
+func (h *SellerHandler) ShipOrder(c *gin.Context) {
+	userID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	var req service.ShipRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	subOrder, err := h.subOrderService.Ship(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": subOrder})
+}
+
+// Ledger 获取商家的余额、待结算货款和账本明细
+func (h *SellerHandler) Ledger(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	ledger, err := h.ledgerService.Ledger(c.Request.Context(), userID,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ledger})
+}
+
+// ListPayouts 分页获取商家的打款
+func (h *SellerHandler) ListPayouts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	list, err := h.ledgerService.MyPayouts(c.Request.Context(), userID,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+func parseOwnedID(c *gin.Context) (uint, uint, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	return userID, id, true
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+)
+
+// 商家账本交易类型
+const (
+	EntrySale         = "sale"          // 子订单结算，计入扣除佣金后的货款
+	EntryRefund       = "refund"        // 结算后的退款，扣回退款金额并退回相应佣金
+	EntryPayout       = "payout"        // 结算打款
+	EntryPayoutFailed = "payout_failed" // 打款失败，退回余额
+	EntryAdjustment   = "adjustment"    // 后台调账
+)
+
+// 商家账本交易关联类型
+const (
+	RefSubOrder   = "sub_order"
+	RefRefund     = "refund"
+	RefPayout     = "payout"
+	RefAdjustment = "adjustment"
+)
+
+// 打款状态
+const (
+	PayoutPending = "pending" // 已从余额扣除，待财务打款
+	PayoutPaid    = "paid"    // 已打款
+	PayoutFailed  = "failed"  // 打款失败，金额已退回余额
+)
+
+// LedgerEntry 表示商家账本中的一笔交易，Amount 为正表示入账，为负表示扣回或打款
+type LedgerEntry struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	SellerID      uint           `json:"seller_id" gorm:"index;not null"`
+	Type          string         `json:"type" gorm:"size:20;uniqueIndex:idx_seller_entry_ref;not null"`
+	Amount        currency.Money `json:"amount" gorm:"type:decimal(12,2);not null"`
+	Sales         currency.Money `json:"sales" gorm:"type:decimal(12,2);not null;default:0"`      // 交易涉及的销售额，退款时为负
+	Commission    currency.Money `json:"commission" gorm:"type:decimal(12,2);not null;default:0"` // 交易涉及的佣金，退款退回时为负
+	Balance       currency.Money `json:"balance" gorm:"type:decimal(12,2);not null"`              // 交易后的余额
+	ReferenceType string         `json:"reference_type" gorm:"size:20;uniqueIndex:idx_seller_entry_ref;not null"`
+	ReferenceID   string         `json:"reference_id" gorm:"size:64;uniqueIndex:idx_seller_entry_ref;not null"`
+	OrderID       *uint          `json:"order_id" gorm:"index"`
+	Description   string         `json:"description" gorm:"size:255"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// Payout 表示一次结算打款，创建时即从商家余额中扣除
+type Payout struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	SellerID      uint           `json:"seller_id" gorm:"index;not null"`
+	Amount        currency.Money `json:"amount" gorm:"type:decimal(12,2);not null"`
+	Method        string         `json:"method" gorm:"size:30;not null"`
+	Account       string         `json:"account" gorm:"size:100;not null"` // 创建时商家的收款账户
+	Status        string         `json:"status" gorm:"size:20;index;not null"`
+	Reference     string         `json:"reference" gorm:"size:100"` // 打款流水号
+	FailureReason string         `json:"failure_reason" gorm:"size:255"`
+	PaidAt        *time.Time     `json:"paid_at"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"index"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+)
+
+// 商家状态
+const (
+	SellerStatusPending   = "pending"   // 已申请入驻，待审核
+	SellerStatusActive    = "active"    // 已通过审核，可以经营
+	SellerStatusRejected  = "rejected"  // 入驻申请被拒绝，可修改资料后重新申请
+	SellerStatusSuspended = "suspended" // 已停业，停业期间不打款，已支付的订单照常拆单履约和结算
+)
+
+// Seller 表示入驻商家，Balance 为所有账本交易的累计值
+type Seller struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	UserID          uint           `json:"user_id" gorm:"uniqueIndex;not null"` // 商家账户所属用户
+	ShopName        string         `json:"shop_name" gorm:"size:100;uniqueIndex;not null"`
+	Description     string         `json:"description" gorm:"type:text"`
+	Logo            string         `json:"logo" gorm:"size:255"`
+	ContactName     string         `json:"contact_name" gorm:"size:50;not null"`
+	ContactEmail    string         `json:"contact_email" gorm:"size:100;not null"`
+	ContactPhone    string         `json:"contact_phone" gorm:"size:20"`
+	LicenseNumber   string         `json:"license_number" gorm:"size:50;not null"` // 营业执照号
+	PayoutMethod    string         `json:"payout_method" gorm:"size:30;not null"`  // 打款方式，如 bank_transfer、alipay
+	PayoutAccount   string         `json:"payout_account" gorm:"size:100;not null"`
+	CommissionRate  *float64       `json:"commission_rate" gorm:"type:decimal(5,2)"` // 商家单独约定的佣金百分比，为空时按分类规则计算
+	Status          string         `json:"status" gorm:"size:20;index;not null;default:'pending'"`
+	StatusReason    string         `json:"status_reason" gorm:"size:255"`                                 // 拒绝或停业原因
+	Balance         currency.Money `json:"balance" gorm:"type:decimal(12,2);not null;default:0"`          // 可结算余额，结算后退款时可能为负
+	TotalSales      currency.Money `json:"total_sales" gorm:"type:decimal(12,2);not null;default:0"`      // 累计已结算的销售额，已扣除退款
+	TotalCommission currency.Money `json:"total_commission" gorm:"type:decimal(12,2);not null;default:0"` // 累计平台佣金，已扣除退款退回的部分
+	TotalPaid       currency.Money `json:"total_paid" gorm:"type:decimal(12,2);not null;default:0"`       // 累计已打款
+	ApprovedAt      *time.Time     `json:"approved_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// SellerProduct 表示商品归属的商家，未登记的商品由平台自营
+type SellerProduct struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ProductID uint      `json:"product_id" gorm:"uniqueIndex;not null"`
+	SellerID  uint      `json:"seller_id" gorm:"index;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommissionRule 表示分类佣金比例，CategoryID 为 0 的规则适用于未单独配置的分类
+type CommissionRule struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CategoryID uint      `json:"category_id" gorm:"uniqueIndex;not null"`
+	Rate       float64   `json:"rate" gorm:"type:decimal(5,2);not null"` // 佣金百分比，如 8 表示商品实付金额的 8%
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+)
+
+// 子订单状态
+const (
+	SubOrderAwaitingShipment = "awaiting_shipment" // 已支付，待商家发货
+	SubOrderShipped          = "shipped"           // 商家已发货
+	SubOrderDelivered        = "delivered"         // 已签收，进入结算等待期
+	SubOrderCancelled        = "cancelled"         // 发货前订单取消
+)
+
+// SubOrder 表示订单中属于同一商家的部分，由商家独立发货。
+// 签收并过了结算等待期后，货款扣除佣金和退款计入商家余额
+type SubOrder struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	OrderID            uint           `json:"order_id" gorm:"uniqueIndex:idx_sub_order_seller;not null"`
+	SellerID           uint           `json:"seller_id" gorm:"uniqueIndex:idx_sub_order_seller;index;not null"`
+	OrderNumber        string         `json:"order_number" gorm:"size:50;not null"`
+	UserID             uint           `json:"user_id" gorm:"index;not null"`
+	Status             string         `json:"status" gorm:"size:20;index;not null"`
+	Subtotal           currency.Money `json:"subtotal" gorm:"type:decimal(12,2);not null"`                      // 商品实付金额合计
+	Commission         currency.Money `json:"commission" gorm:"type:decimal(12,2);not null"`                    // 平台佣金合计
+	RefundedAmount     currency.Money `json:"refunded_amount" gorm:"type:decimal(12,2);not null;default:0"`     // 已退款金额
+	CommissionReversed currency.Money `json:"commission_reversed" gorm:"type:decimal(12,2);not null;default:0"` // 退款退回的佣金
+	Carrier            string         `json:"carrier" gorm:"size:50"`
+	TrackingNumber     string         `json:"tracking_number" gorm:"size:100"`
+	ShippedAt          *time.Time     `json:"shipped_at"`
+	DeliveredAt        *time.Time     `json:"delivered_at" gorm:"index"`
+	SettledAt          *time.Time     `json:"settled_at"` // 货款计入商家余额的时间
+	CreatedAt          time.Time      `json:"created_at" gorm:"index"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	Items              []SubOrderItem `json:"items,omitempty" gorm:"foreignKey:SubOrderID"`
+}
+
+// Proceeds 返回商家在子订单上的应得货款：实付金额扣除退款和佣金
+func (o *SubOrder) Proceeds() currency.Money {
+	return o.Subtotal.Sub(o.RefundedAmount).Sub(o.Commission.Sub(o.CommissionReversed))
+}
+
+// SubOrderItem 表示子订单中的商品行及其佣金
+type SubOrderItem struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	SubOrderID     uint           `json:"sub_order_id" gorm:"index;not null"`
+	ProductID      uint           `json:"product_id" gorm:"index;not null"`
+	SKUID          uint           `json:"sku_id"`
+	Quantity       int            `json:"quantity" gorm:"not null"`
+	Total          currency.Money `json:"total" gorm:"type:decimal(12,2);not null"`
+	CommissionRate float64        `json:"commission_rate" gorm:"type:decimal(5,2);not null"`
+	Commission     currency.Money `json:"commission" gorm:"type:decimal(12,2);not null"`
+}
+
+// SubOrderRefund 表示分摊到子订单的一笔退款，用于防止重复处理同一退款
+type SubOrderRefund struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	SubOrderID uint           `json:"sub_order_id" gorm:"uniqueIndex:idx_sub_order_refund;not null"`
+	RefundID   string         `json:"refund_id" gorm:"size:64;uniqueIndex:idx_sub_order_refund;not null"`
+	Amount     currency.Money `json:"amount" gorm:"type:decimal(12,2);not null"`
+	Commission currency.Money `json:"commission" gorm:"type:decimal(12,2);not null"` // 退回的佣金
+	CreatedAt  time.Time      `json:"created_at"`
+}
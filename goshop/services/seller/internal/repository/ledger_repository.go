@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/goshop/services/seller/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrDuplicateEntry 表示相同关联的账本交易已存在
+	ErrDuplicateEntry = errors.New("duplicate seller ledger entry")
+	// ErrInsufficientBalance 表示商家可结算余额不足
+	ErrInsufficientBalance = errors.New("insufficient seller balance")
+	// ErrPayoutNotPending 表示打款已处理过
+	ErrPayoutNotPending = errors.New("payout is not pending")
+)
+
+// PayoutFilter 表示查询打款的过滤条件，零值字段不参与过滤
+type PayoutFilter struct {
+	SellerID uint
+	Status   string
+}
+
+// LedgerRepository 定义商家账本和打款仓库接口
+type LedgerRepository interface {
+	AddEntry(ctx context.Context, entry *model.LedgerEntry, allowNegative bool) error
+	ListEntries(ctx context.Context, sellerID uint, offset, limit int) ([]*model.LedgerEntry, int64, error)
+	CreatePayout(ctx context.Context, payout *model.Payout, entry *model.LedgerEntry) error
+	GetPayout(ctx context.Context, id uint) (*model.Payout, error)
+	ListPayouts(ctx context.Context, filter PayoutFilter, offset, limit int) ([]*model.Payout, int64, error)
+	MarkPayoutPaid(ctx context.Context, id uint, reference string, paidAt time.Time) error
+	FailPayout(ctx context.Context, id uint, reason string, entry *model.LedgerEntry) error
+}
+
+// GormLedgerRepository 实现 LedgerRepository 接口的 GORM 仓库
+type GormLedgerRepository struct {
+	db *gorm.DB
+}
+
+// NewLedgerRepository 创建账本仓库实例
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &GormLedgerRepository{
+		db: db,
+	}
+}
+
+// AddEntry 在事务中写入账本交易并更新商家余额
+func (r *GormLedgerRepository) AddEntry(ctx context.Context, entry *model.LedgerEntry, allowNegative bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return addLedgerEntry(tx, entry, allowNegative)
+	})
+}
+
+// ListEntries 分页获取商家的账本交易，最新的在前
+func (r *GormLedgerRepository) ListEntries(ctx context.Context, sellerID uint, offset, limit int) ([]*model.LedgerEntry, int64, error) {
+	var entries []*model.LedgerEntry
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.LedgerEntry{}).Where("seller_id = ?", sellerID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// CreatePayout 在事务中创建打款并从可结算余额中扣减，余额不足时返回 ErrInsufficientBalance
+func (r *GormLedgerRepository) CreatePayout(ctx context.Context, payout *model.Payout, entry *model.LedgerEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(payout).Error; err != nil {
+			return err
+		}
+		entry.ReferenceID = strconv.FormatUint(uint64(payout.ID), 10)
+		return addLedgerEntry(tx, entry, false)
+	})
+}
+
+// GetPayout 根据 ID 获取打款
+func (r *GormLedgerRepository) GetPayout(ctx context.Context, id uint) (*model.Payout, error) {
+	var payout model.Payout
+	if err := r.db.WithContext(ctx).First(&payout, id).Error; err != nil {
+		return nil, err
+	}
+	return &payout, nil
+}
+
+// ListPayouts 按过滤条件分页获取打款，最新的在前
+func (r *GormLedgerRepository) ListPayouts(ctx context.Context, filter PayoutFilter, offset, limit int) ([]*model.Payout, int64, error) {
+	var payouts []*model.Payout
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Payout{})
+	if filter.SellerID != 0 {
+		query = query.Where("seller_id = ?", filter.SellerID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&payouts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return payouts, total, nil
+}
+
+// MarkPayoutPaid 将待打款标记为已打款，打款已处理过时返回 ErrPayoutNotPending
+func (r *GormLedgerRepository) MarkPayoutPaid(ctx context.Context, id uint, reference string, paidAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&model.Payout{}).
+		Where("id = ? AND status = ?", id, model.PayoutPending).
+		Updates(map[string]interface{}{
+			"status":    model.PayoutPaid,
+			"reference": reference,
+			"paid_at":   paidAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPayoutNotPending
+	}
+	return nil
+}
+
+// FailPayout 在事务中将待打款标记为失败并将金额退回余额，打款已处理过时返回 ErrPayoutNotPending
+func (r *GormLedgerRepository) FailPayout(ctx context.Context, id uint, reason string, entry *model.LedgerEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Payout{}).
+			Where("id = ? AND status = ?", id, model.PayoutPending).
+			Updates(map[string]interface{}{
+				"status":         model.PayoutFailed,
+				"failure_reason": reason,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPayoutNotPending
+		}
+		return addLedgerEntry(tx, entry, true)
+	})
+}
+
+// addLedgerEntry 更新商家余额和累计金额并写入账本交易，entry.Balance 会被设置为交易后的余额。
+// allowNegative 为 false 时余额不足返回 ErrInsufficientBalance；相同关联的交易已存在时返回 ErrDuplicateEntry
+func addLedgerEntry(tx *gorm.DB, entry *model.LedgerEntry, allowNegative bool) error {
+	updates := map[string]interface{}{
+		"balance":          gorm.Expr("balance + ?", entry.Amount),
+		"total_sales":      gorm.Expr("total_sales + ?", entry.Sales),
+		"total_commission": gorm.Expr("total_commission + ?", entry.Commission),
+	}
+	switch entry.Type {
+	case model.EntryPayout, model.EntryPayoutFailed:
+		updates["total_paid"] = gorm.Expr("total_paid - ?", entry.Amount)
+	}
+	query := tx.Model(&model.Seller{}).Where("id = ?", entry.SellerID)
+	if !allowNegative {
+		query = query.Where("balance + ? >= 0", entry.Amount)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientBalance
+	}
+
+	var seller model.Seller
+	if err := tx.Select("balance").First(&seller, entry.SellerID).Error; err != nil {
+		return err
+	}
+	entry.Balance = seller.Balance
+
+	result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDuplicateEntry
+	}
+	return nil
+}
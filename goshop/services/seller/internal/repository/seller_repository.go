@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	"github.com/yourusername/goshop/services/seller/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SellerRepository 定义商家、商品归属和佣金规则仓库接口
+type SellerRepository interface {
+	Create(ctx context.Context, seller *model.Seller) error
+	GetByID(ctx context.Context, id uint) (*model.Seller, error)
+	GetByUserID(ctx context.Context, userID uint) (*model.Seller, error)
+	GetByShopName(ctx context.Context, shopName string) (*model.Seller, error)
+	List(ctx context.Context, status string, offset, limit int) ([]*model.Seller, int64, error)
+	ListPayable(ctx context.Context, minBalance currency.Money) ([]*model.Seller, error)
+	Update(ctx context.Context, seller *model.Seller) error
+	AssignProduct(ctx context.Context, productID, sellerID uint) (*model.SellerProduct, error)
+	RemoveProduct(ctx context.Context, productID uint) error
+	GetProduct(ctx context.Context, productID uint) (*model.SellerProduct, error)
+	ProductOwners(ctx context.Context, productIDs []uint) (map[uint]uint, error)
+	ListProducts(ctx context.Context, sellerID uint, offset, limit int) ([]*model.SellerProduct, int64, error)
+	ListCommissionRules(ctx context.Context) ([]*model.CommissionRule, error)
+	SaveCommissionRule(ctx context.Context, rule *model.CommissionRule) error
+	DeleteCommissionRule(ctx context.Context, categoryID uint) error
+}
+
+// GormSellerRepository 实现 SellerRepository 接口的 GORM 仓库
+type GormSellerRepository struct {
+	db *gorm.DB
+}
+
+// NewSellerRepository 创建商家仓库实例
+func NewSellerRepository(db *gorm.DB) SellerRepository {
+	return &GormSellerRepository{
+		db: db,
+	}
+}
+
+// Create 创建商家
+func (r *GormSellerRepository) Create(ctx context.Context, seller *model.Seller) error {
+	return r.db.WithContext(ctx).Create(seller).Error
+}
+
+// GetByID 根据 ID 获取商家
+func (r *GormSellerRepository) GetByID(ctx context.Context, id uint) (*model.Seller, error) {
+	var seller model.Seller
+	if err := r.db.WithContext(ctx).First(&seller, id).Error; err != nil {
+		return nil, err
+	}
+	return &seller, nil
+}
+
+// GetByUserID 根据用户 ID 获取商家
+func (r *GormSellerRepository) GetByUserID(ctx context.Context, userID uint) (*model.Seller, error) {
+	var seller model.Seller
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&seller).Error; err != nil {
+		return nil, err
+	}
+	return &seller, nil
+}
+
+// GetByShopName 根据店铺名称获取商家
+func (r *GormSellerRepository) GetByShopName(ctx context.Context, shopName string) (*model.Seller, error) {
+	var seller model.Seller
+	if err := r.db.WithContext(ctx).Where("shop_name = ?", shopName).First(&seller).Error; err != nil {
+		return nil, err
+	}
+	return &seller, nil
+}
+
+// List 分页获取商家，status 为空时不过滤，最新申请的在前
+func (r *GormSellerRepository) List(ctx context.Context, status string, offset, limit int) ([]*model.Seller, int64, error) {
+	var sellers []*model.Seller
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Seller{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&sellers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return sellers, total, nil
+}
+
+// ListPayable 获取余额不低于 minBalance 的经营中商家
+func (r *GormSellerRepository) ListPayable(ctx context.Context, minBalance currency.Money) ([]*model.Seller, error) {
+	var sellers []*model.Seller
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND balance > 0 AND balance >= ?", model.SellerStatusActive, minBalance).
+		Order("id ASC").
+		Find(&sellers).Error
+	return sellers, err
+}
+
+// Update 更新商家资料和状态，余额和累计金额只通过账本交易变更
+func (r *GormSellerRepository) Update(ctx context.Context, seller *model.Seller) error {
+	return r.db.WithContext(ctx).
+		Omit("balance", "total_sales", "total_commission", "total_paid").
+		Save(seller).Error
+}
+
+// AssignProduct 登记商品归属的商家，商品已有归属时改为新的商家
+func (r *GormSellerRepository) AssignProduct(ctx context.Context, productID, sellerID uint) (*model.SellerProduct, error) {
+	product := &model.SellerProduct{ProductID: productID, SellerID: sellerID}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"seller_id"}),
+		}).
+		Create(product).Error
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// RemoveProduct 删除商品归属，商品改为平台自营
+func (r *GormSellerRepository) RemoveProduct(ctx context.Context, productID uint) error {
+	return r.db.WithContext(ctx).
+		Where("product_id = ?", productID).
+		Delete(&model.SellerProduct{}).Error
+}
+
+// GetProduct 获取商品的归属
+func (r *GormSellerRepository) GetProduct(ctx context.Context, productID uint) (*model.SellerProduct, error) {
+	var product model.SellerProduct
+	if err := r.db.WithContext(ctx).Where("product_id = ?", productID).First(&product).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ProductOwners 返回商品 ID 到商家 ID 的映射，平台自营的商品不在结果中
+func (r *GormSellerRepository) ProductOwners(ctx context.Context, productIDs []uint) (map[uint]uint, error) {
+	owners := make(map[uint]uint)
+	if len(productIDs) == 0 {
+		return owners, nil
+	}
+	var products []*model.SellerProduct
+	if err := r.db.WithContext(ctx).Where("product_id IN ?", productIDs).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		owners[product.ProductID] = product.SellerID
+	}
+	return owners, nil
+}
+
+// ListProducts 分页获取商家的商品
+func (r *GormSellerRepository) ListProducts(ctx context.Context, sellerID uint, offset, limit int) ([]*model.SellerProduct, int64, error) {
+	var products []*model.SellerProduct
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.SellerProduct{}).Where("seller_id = ?", sellerID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("product_id ASC").Offset(offset).Limit(limit).Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// ListCommissionRules 获取所有分类佣金规则
+func (r *GormSellerRepository) ListCommissionRules(ctx context.Context) ([]*model.CommissionRule, error) {
+	var rules []*model.CommissionRule
+	err := r.db.WithContext(ctx).Order("category_id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// SaveCommissionRule 按分类创建或更新佣金规则
+func (r *GormSellerRepository) SaveCommissionRule(ctx context.Context, rule *model.CommissionRule) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "category_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+		}).
+		Create(rule).Error
+}
+
+// DeleteCommissionRule 删除分类佣金规则
+func (r *GormSellerRepository) DeleteCommissionRule(ctx context.Context, categoryID uint) error {
+	return r.db.WithContext(ctx).
+		Where("category_id = ?", categoryID).
+		Delete(&model.CommissionRule{}).Error
+}
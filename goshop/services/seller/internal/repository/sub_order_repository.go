@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	"github.com/yourusername/goshop/services/seller/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SubOrderFilter 表示查询子订单的过滤条件，零值字段不参与过滤
+type SubOrderFilter struct {
+	SellerID uint
+	OrderID  uint
+	Status   string
+}
+
+// SubOrderRepository 定义子订单仓库接口
+type SubOrderRepository interface {
+	CreateForOrder(ctx context.Context, subOrders []*model.SubOrder) ([]*model.SubOrder, error)
+	GetByID(ctx context.Context, id uint) (*model.SubOrder, error)
+	ListByOrder(ctx context.Context, orderID uint) ([]*model.SubOrder, error)
+	List(ctx context.Context, filter SubOrderFilter, offset, limit int) ([]*model.SubOrder, int64, error)
+	Transition(ctx context.Context, id uint, from []string, updates map[string]interface{}) (bool, error)
+	ListSettleable(ctx context.Context, deliveredBefore time.Time, afterID uint, limit int) ([]*model.SubOrder, error)
+	Settle(ctx context.Context, id uint, deliveredBefore time.Time, entry *model.LedgerEntry) (bool, error)
+	ApplyRefund(ctx context.Context, id uint, refund *model.SubOrderRefund, entry *model.LedgerEntry) (bool, error)
+	PendingProceeds(ctx context.Context, sellerID uint) (currency.Money, error)
+}
+
+// GormSubOrderRepository 实现 SubOrderRepository 接口的 GORM 仓库
+type GormSubOrderRepository struct {
+	db *gorm.DB
+}
+
+// NewSubOrderRepository 创建子订单仓库实例
+func NewSubOrderRepository(db *gorm.DB) SubOrderRepository {
+	return &GormSubOrderRepository{
+		db: db,
+	}
+}
+
+// CreateForOrder 在事务中创建订单的子订单及其商品行，已存在的子订单被跳过，返回新创建的子订单
+func (r *GormSubOrderRepository) CreateForOrder(ctx context.Context, subOrders []*model.SubOrder) ([]*model.SubOrder, error) {
+	var created []*model.SubOrder
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		created = created[:0]
+		for _, subOrder := range subOrders {
+			result := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(subOrder)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			for i := range subOrder.Items {
+				subOrder.Items[i].SubOrderID = subOrder.ID
+			}
+			if len(subOrder.Items) > 0 {
+				if err := tx.Create(&subOrder.Items).Error; err != nil {
+					return err
+				}
+			}
+			created = append(created, subOrder)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetByID 根据 ID 获取子订单及其商品行
+func (r *GormSubOrderRepository) GetByID(ctx context.Context, id uint) (*model.SubOrder, error) {
+	var subOrder model.SubOrder
+	if err := r.db.WithContext(ctx).Preload("Items").First(&subOrder, id).Error; err != nil {
+		return nil, err
+	}
+	return &subOrder, nil
+}
+
+// ListByOrder 获取订单的所有子订单
+func (r *GormSubOrderRepository) ListByOrder(ctx context.Context, orderID uint) ([]*model.SubOrder, error) {
+	var subOrders []*model.SubOrder
+	err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("id ASC").
+		Find(&subOrders).Error
+	return subOrders, err
+}
+
+// List 按过滤条件分页获取子订单，最新的在前
+func (r *GormSubOrderRepository) List(ctx context.Context, filter SubOrderFilter, offset, limit int) ([]*model.SubOrder, int64, error) {
+	var subOrders []*model.SubOrder
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.SubOrder{})
+	if filter.SellerID != 0 {
+		query = query.Where("seller_id = ?", filter.SellerID)
+	}
+	if filter.OrderID != 0 {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Preload("Items").Order("id DESC").Offset(offset).Limit(limit).Find(&subOrders).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return subOrders, total, nil
+}
+
+// Transition 在子订单处于 from 中的某个状态时更新，状态已被并发修改时返回 false
+func (r *GormSubOrderRepository) Transition(ctx context.Context, id uint, from []string, updates map[string]interface{}) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.SubOrder{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListSettleable 获取在 deliveredBefore 之前签收且尚未结算、ID 大于 afterID 的子订单，按 ID 升序
+func (r *GormSubOrderRepository) ListSettleable(ctx context.Context, deliveredBefore time.Time, afterID uint, limit int) ([]*model.SubOrder, error) {
+	var subOrders []*model.SubOrder
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND settled_at IS NULL AND delivered_at < ? AND id > ?", model.SubOrderDelivered, deliveredBefore, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&subOrders).Error
+	return subOrders, err
+}
+
+// Settle 在事务中结算子订单，将扣除佣金和退款后的货款计入商家余额。
+// entry 的金额字段由子订单计算，子订单不满足结算条件或已结算时返回 false
+func (r *GormSubOrderRepository) Settle(ctx context.Context, id uint, deliveredBefore time.Time, entry *model.LedgerEntry) (bool, error) {
+	settled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var subOrder model.SubOrder
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subOrder, id).Error
+		if err != nil {
+			return err
+		}
+		if subOrder.Status != model.SubOrderDelivered || subOrder.SettledAt != nil ||
+			subOrder.DeliveredAt == nil || !subOrder.DeliveredAt.Before(deliveredBefore) {
+			return nil
+		}
+
+		now := time.Now()
+		if err := tx.Model(&subOrder).Update("settled_at", now).Error; err != nil {
+			return err
+		}
+		entry.SellerID = subOrder.SellerID
+		entry.Amount = subOrder.Proceeds()
+		entry.Sales = subOrder.Subtotal.Sub(subOrder.RefundedAmount)
+		entry.Commission = subOrder.Commission.Sub(subOrder.CommissionReversed)
+		entry.ReferenceID = strconv.FormatUint(uint64(subOrder.ID), 10)
+		entry.OrderID = &subOrder.OrderID
+		if err := addLedgerEntry(tx, entry, true); err != nil {
+			return err
+		}
+		settled = true
+		return nil
+	})
+	return settled, err
+}
+
+// ApplyRefund 在事务中将退款分摊到子订单，refund.Amount 不超过尚未退款的金额，
+// 佣金按退款占实付金额的比例退回。子订单已结算时 entry 记入账本扣回货款，
+// 未结算时只调整子订单金额。退款已处理过或子订单已全部退款时返回 false
+func (r *GormSubOrderRepository) ApplyRefund(ctx context.Context, id uint, refund *model.SubOrderRefund, entry *model.LedgerEntry) (bool, error) {
+	applied := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var subOrder model.SubOrder
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subOrder, id).Error
+		if err != nil {
+			return err
+		}
+
+		remaining := subOrder.Subtotal.Sub(subOrder.RefundedAmount)
+		amount := refund.Amount.Min(remaining)
+		if !amount.IsPositive() {
+			return nil
+		}
+		commission := subOrder.Commission.Sub(subOrder.CommissionReversed)
+		if amount.LessThan(remaining) {
+			ratio := float64(amount.Amount()) / float64(subOrder.Subtotal.Amount())
+			commission = subOrder.Commission.MulRate(ratio, currency.HalfUp).Min(commission)
+		}
+
+		refund.SubOrderID = subOrder.ID
+		refund.Amount = amount
+		refund.Commission = commission
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(refund)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		subOrder.RefundedAmount = subOrder.RefundedAmount.Add(amount)
+		subOrder.CommissionReversed = subOrder.CommissionReversed.Add(commission)
+		err = tx.Model(&subOrder).Updates(map[string]interface{}{
+			"refunded_amount":     subOrder.RefundedAmount,
+			"commission_reversed": subOrder.CommissionReversed,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if subOrder.SettledAt != nil {
+			entry.SellerID = subOrder.SellerID
+			entry.Amount = amount.Sub(commission).Neg()
+			entry.Sales = amount.Neg()
+			entry.Commission = commission.Neg()
+			entry.ReferenceID = fmt.Sprintf("%s/%d", refund.RefundID, subOrder.ID)
+			entry.OrderID = &subOrder.OrderID
+			if err := addLedgerEntry(tx, entry, true); err != nil {
+				return err
+			}
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
+
+// PendingProceeds 汇总商家尚未结算的子订单货款，已取消的子订单不计入
+func (r *GormSubOrderRepository) PendingProceeds(ctx context.Context, sellerID uint) (currency.Money, error) {
+	var pending currency.Money
+	err := r.db.WithContext(ctx).
+		Model(&model.SubOrder{}).
+		Select("COALESCE(SUM(subtotal - refunded_amount - commission + commission_reversed), 0)").
+		Where("seller_id = ? AND settled_at IS NULL AND status <> ?", sellerID, model.SubOrderCancelled).
+		Scan(&pending).Error
+	return pending, err
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/idgen"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/seller/internal/event"
+	"github.com/yourusername/goshop/services/seller/internal/model"
+	"github.com/yourusername/goshop/services/seller/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// settleBatchSize 是结算任务每批处理的子订单数
+const settleBatchSize = 100
+
+// AdjustmentRequest 表示后台调账的请求，amount 为正表示补款，为负表示扣款
+type AdjustmentRequest struct {
+	Amount      float64 `json:"amount" binding:"required"`
+	Description string  `json:"description" binding:"required,max=255"`
+}
+
+// PayoutPaidRequest 表示登记打款完成的请求
+type PayoutPaidRequest struct {
+	Reference string `json:"reference" binding:"required,max=100"`
+}
+
+// PayoutFailedRequest 表示登记打款失败的请求
+type PayoutFailedRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+// SellerLedger 表示商家的余额汇总和分页的账本交易
+type SellerLedger struct {
+	Balance         currency.Money       `json:"balance"`
+	Pending         currency.Money       `json:"pending"` // 尚未结算的子订单货款
+	TotalSales      currency.Money       `json:"total_sales"`
+	TotalCommission currency.Money       `json:"total_commission"`
+	TotalPaid       currency.Money       `json:"total_paid"`
+	Items           []*model.LedgerEntry `json:"items"`
+	Total           int64                `json:"total"`
+	Page            int                  `json:"page"`
+	PageSize        int                  `json:"page_size"`
+}
+
+// PayoutList 表示分页的打款列表
+type PayoutList struct {
+	Items    []*model.Payout `json:"items"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// LedgerConfig 表示结算和打款的设置
+type LedgerConfig struct {
+	HoldDays  int            // 签收后货款进入可结算余额前的等待天数，留出售后退款的时间
+	MinPayout currency.Money // 可结算余额达到该金额才打款
+}
+
+// LedgerService 负责商家账本：签收的子订单过了等待期后结算入账，
+// 定期为余额达到起付金额的商家创建打款，财务打款后登记结果
+type LedgerService struct {
+	sellerRepo   repository.SellerRepository
+	subOrderRepo repository.SubOrderRepository
+	ledgerRepo   repository.LedgerRepository
+	publisher    event.Publisher
+	cfg          LedgerConfig
+	log          *logger.Logger
+}
+
+// NewLedgerService 创建账本服务
+func NewLedgerService(sellerRepo repository.SellerRepository, subOrderRepo repository.SubOrderRepository, ledgerRepo repository.LedgerRepository, publisher event.Publisher, cfg LedgerConfig, log *logger.Logger) *LedgerService {
+	return &LedgerService{
+		sellerRepo:   sellerRepo,
+		subOrderRepo: subOrderRepo,
+		ledgerRepo:   ledgerRepo,
+		publisher:    publisher,
+		cfg:          cfg,
+		log:          log,
+	}
+}
+
+// Ledger 获取商家自己的账本
+func (s *LedgerService) Ledger(ctx context.Context, userID uint, page, pageSize int) (*SellerLedger, error) {
+	seller, err := s.me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledger(ctx, seller, page, pageSize)
+}
+
+// MyPayouts 分页获取商家自己的打款
+func (s *LedgerService) MyPayouts(ctx context.Context, userID uint, page, pageSize int) (*PayoutList, error) {
+	seller, err := s.me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListPayouts(ctx, repository.PayoutFilter{SellerID: seller.ID}, page, pageSize)
+}
+
+// AdminLedger 后台查看商家的账本
+func (s *LedgerService) AdminLedger(ctx context.Context, sellerID uint, page, pageSize int) (*SellerLedger, error) {
+	seller, err := s.getSeller(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledger(ctx, seller, page, pageSize)
+}
+
+// Adjust 后台调账，用于线下赔付、罚款等，扣款可使余额为负并从之后的货款中抵扣
+func (s *LedgerService) Adjust(ctx context.Context, sellerID uint, req *AdjustmentRequest) (*model.LedgerEntry, error) {
+	seller, err := s.getSeller(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	amount := currency.FromMajor(req.Amount, currency.Default)
+	if amount.IsZero() {
+		return nil, apperrors.NewBadRequest("调账金额不能为 0", nil)
+	}
+	id, err := idgen.Next()
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成调账编号失败", err)
+	}
+	entry := &model.LedgerEntry{
+		SellerID:      seller.ID,
+		Type:          model.EntryAdjustment,
+		Amount:        amount,
+		ReferenceType: model.RefAdjustment,
+		ReferenceID:   strconv.FormatInt(id, 10),
+		Description:   req.Description,
+	}
+	if err := s.ledgerRepo.AddEntry(ctx, entry, true); err != nil {
+		return nil, apperrors.NewInternalServerError("调账失败", err)
+	}
+	return entry, nil
+}
+
+// ListPayouts 按过滤条件分页获取打款
+func (s *LedgerService) ListPayouts(ctx context.Context, filter repository.PayoutFilter, page, pageSize int) (*PayoutList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	payouts, total, err := s.ledgerRepo.ListPayouts(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取打款失败", err)
+	}
+	return &PayoutList{Items: payouts, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// MarkPayoutPaid 登记打款完成
+func (s *LedgerService) MarkPayoutPaid(ctx context.Context, id uint, req *PayoutPaidRequest) (*model.Payout, error) {
+	if _, err := s.getPayout(ctx, id); err != nil {
+		return nil, err
+	}
+	err := s.ledgerRepo.MarkPayoutPaid(ctx, id, req.Reference, time.Now())
+	if errors.Is(err, repository.ErrPayoutNotPending) {
+		return nil, apperrors.NewBadRequest("打款已处理", err)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("更新打款失败", err)
+	}
+	return s.getPayout(ctx, id)
+}
+
+// FailPayout 登记打款失败，金额退回商家余额，商家更正收款账户后随下次打款一起支付
+func (s *LedgerService) FailPayout(ctx context.Context, id uint, req *PayoutFailedRequest) (*model.Payout, error) {
+	payout, err := s.getPayout(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	entry := &model.LedgerEntry{
+		SellerID:      payout.SellerID,
+		Type:          model.EntryPayoutFailed,
+		Amount:        payout.Amount,
+		ReferenceType: model.RefPayout,
+		ReferenceID:   strconv.FormatUint(uint64(payout.ID), 10),
+		Description:   fmt.Sprintf("打款失败退回：%s", req.Reason),
+	}
+	err = s.ledgerRepo.FailPayout(ctx, id, req.Reason, entry)
+	if errors.Is(err, repository.ErrPayoutNotPending) {
+		return nil, apperrors.NewBadRequest("打款已处理", err)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("更新打款失败", err)
+	}
+	return s.getPayout(ctx, id)
+}
+
+// Settle 结算过了等待期的已签收子订单，将货款计入商家余额。
+// 由调度服务定期调用，单个子订单失败不影响其他子订单，失败的子订单在下次运行时重试
+func (s *LedgerService) Settle(ctx context.Context) error {
+	deliveredBefore := time.Now().AddDate(0, 0, -s.cfg.HoldDays)
+	var settled, failed int
+	var afterID uint
+	for {
+		subOrders, err := s.subOrderRepo.ListSettleable(ctx, deliveredBefore, afterID, settleBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, subOrder := range subOrders {
+			afterID = subOrder.ID
+			entry := &model.LedgerEntry{
+				Type:          model.EntrySale,
+				ReferenceType: model.RefSubOrder,
+				Description:   fmt.Sprintf("订单 %s 货款结算", subOrder.OrderNumber),
+			}
+			ok, err := s.subOrderRepo.Settle(ctx, subOrder.ID, deliveredBefore, entry)
+			if err != nil {
+				failed++
+				s.log.Error(ctx, "Failed to settle sub-order", zap.Uint("sub_order_id", subOrder.ID), zap.Error(err))
+				continue
+			}
+			if ok {
+				settled++
+			}
+		}
+		if len(subOrders) < settleBatchSize {
+			break
+		}
+	}
+
+	if settled > 0 || failed > 0 {
+		s.log.Info(ctx, "Settled sub-orders", zap.Int("settled", settled), zap.Int("failed", failed))
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to settle %d sub-orders", failed)
+	}
+	return nil
+}
+
+// CreatePayouts 为余额达到起付金额的经营中商家创建打款，打款金额为全部可结算余额。
+// 由调度服务定期调用，重复运行时余额已扣减，不会重复打款
+func (s *LedgerService) CreatePayouts(ctx context.Context) error {
+	sellers, err := s.sellerRepo.ListPayable(ctx, s.cfg.MinPayout)
+	if err != nil {
+		return err
+	}
+	var created, failed int
+	for _, seller := range sellers {
+		payout := &model.Payout{
+			SellerID: seller.ID,
+			Amount:   seller.Balance,
+			Method:   seller.PayoutMethod,
+			Account:  seller.PayoutAccount,
+			Status:   model.PayoutPending,
+		}
+		entry := &model.LedgerEntry{
+			SellerID:      seller.ID,
+			Type:          model.EntryPayout,
+			Amount:        seller.Balance.Neg(),
+			ReferenceType: model.RefPayout,
+			Description:   fmt.Sprintf("结算打款 %s 元", seller.Balance.Decimal()),
+		}
+		err := s.ledgerRepo.CreatePayout(ctx, payout, entry)
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			// 查询后发生了结算后退款，下次运行时按新的余额打款
+			continue
+		}
+		if err != nil {
+			failed++
+			s.log.Error(ctx, "Failed to create payout", zap.Uint("seller_id", seller.ID), zap.Error(err))
+			continue
+		}
+		created++
+		evt := &event.PayoutEvent{
+			PayoutID: payout.ID,
+			SellerID: payout.SellerID,
+			Amount:   payout.Amount.Float64(),
+			Method:   payout.Method,
+		}
+		if err := s.publisher.Publish(ctx, event.PayoutCreated, event.EventVersion, evt); err != nil {
+			s.log.Warn(ctx, "Failed to publish payout event", zap.Uint("payout_id", payout.ID), zap.Error(err))
+		}
+	}
+
+	if created > 0 || failed > 0 {
+		s.log.Info(ctx, "Created seller payouts", zap.Int("created", created), zap.Int("failed", failed))
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to create %d payouts", failed)
+	}
+	return nil
+}
+
+func (s *LedgerService) ledger(ctx context.Context, seller *model.Seller, page, pageSize int) (*SellerLedger, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	entries, total, err := s.ledgerRepo.ListEntries(ctx, seller.ID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取账本明细失败", err)
+	}
+	pending, err := s.subOrderRepo.PendingProceeds(ctx, seller.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取待结算货款失败", err)
+	}
+	return &SellerLedger{
+		Balance:         seller.Balance,
+		Pending:         pending,
+		TotalSales:      seller.TotalSales,
+		TotalCommission: seller.TotalCommission,
+		TotalPaid:       seller.TotalPaid,
+		Items:           entries,
+		Total:           total,
+		Page:            page,
+		PageSize:        pageSize,
+	}, nil
+}
+
+func (s *LedgerService) me(ctx context.Context, userID uint) (*model.Seller, error) {
+	seller, err := s.sellerRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("尚未申请入驻", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取商家失败", err)
+	}
+	return seller, nil
+}
+
+func (s *LedgerService) getSeller(ctx context.Context, id uint) (*model.Seller, error) {
+	seller, err := s.sellerRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("商家 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取商家失败", err)
+	}
+	return seller, nil
+}
+
+func (s *LedgerService) getPayout(ctx context.Context, id uint) (*model.Payout, error) {
+	payout, err := s.ledgerRepo.GetPayout(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("打款 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取打款失败", err)
+	}
+	return payout, nil
+}
@@ -0,0 +1,439 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/seller/internal/event"
+	"github.com/yourusername/goshop/services/seller/internal/model"
+	"github.com/yourusername/goshop/services/seller/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ApplySellerRequest 表示申请入驻的请求，被拒绝后可修改资料重新申请
+type ApplySellerRequest struct {
+	ShopName      string `json:"shop_name" binding:"required,max=100"`
+	Description   string `json:"description" binding:"max=2000"`
+	Logo          string `json:"logo" binding:"omitempty,url,max=255"`
+	ContactName   string `json:"contact_name" binding:"required,max=50"`
+	ContactEmail  string `json:"contact_email" binding:"required,email,max=100"`
+	ContactPhone  string `json:"contact_phone" binding:"max=20"`
+	LicenseNumber string `json:"license_number" binding:"required,max=50"`
+	PayoutMethod  string `json:"payout_method" binding:"required,oneof=bank_transfer alipay"`
+	PayoutAccount string `json:"payout_account" binding:"required,max=100"`
+}
+
+// UpdateSellerRequest 表示商家修改店铺资料的请求，空字段保持不变。
+// 店铺名称和营业执照号需重新审核，不能自行修改
+type UpdateSellerRequest struct {
+	Description   *string `json:"description" binding:"omitempty,max=2000"`
+	Logo          *string `json:"logo" binding:"omitempty,url,max=255"`
+	ContactName   *string `json:"contact_name" binding:"omitempty,max=50"`
+	ContactEmail  *string `json:"contact_email" binding:"omitempty,email,max=100"`
+	ContactPhone  *string `json:"contact_phone" binding:"omitempty,max=20"`
+	PayoutMethod  *string `json:"payout_method" binding:"omitempty,oneof=bank_transfer alipay"`
+	PayoutAccount *string `json:"payout_account" binding:"omitempty,max=100"`
+}
+
+// SellerStatusRequest 表示后台拒绝或停业商家的请求
+type SellerStatusRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+// SellerCommissionRequest 表示后台设置商家单独佣金比例的请求，rate 为空时恢复按分类规则计算
+type SellerCommissionRequest struct {
+	Rate *float64 `json:"rate" binding:"omitempty,min=0,max=100"`
+}
+
+// AssignProductRequest 表示后台登记商品归属的请求
+type AssignProductRequest struct {
+	SellerID uint `json:"seller_id" binding:"required"`
+}
+
+// CommissionRuleRequest 表示设置分类佣金比例的请求，category_id 为 0 时设置默认比例
+type CommissionRuleRequest struct {
+	CategoryID uint    `json:"category_id"`
+	Rate       float64 `json:"rate" binding:"min=0,max=100"`
+}
+
+// SellerProfile 表示商家的公开资料，展示在商品详情和店铺页
+type SellerProfile struct {
+	ID          uint      `json:"id"`
+	ShopName    string    `json:"shop_name"`
+	Description string    `json:"description"`
+	Logo        string    `json:"logo"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// SellerList 表示分页的商家列表
+type SellerList struct {
+	Items    []*model.Seller `json:"items"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// SellerProductList 表示分页的商家商品列表
+type SellerProductList struct {
+	Items    []*model.SellerProduct `json:"items"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+}
+
+// SellerService 负责商家入驻审核、店铺资料、商品归属和佣金规则
+type SellerService struct {
+	sellerRepo repository.SellerRepository
+	publisher  event.Publisher
+	log        *logger.Logger
+}
+
+// NewSellerService 创建商家服务
+func NewSellerService(sellerRepo repository.SellerRepository, publisher event.Publisher, log *logger.Logger) *SellerService {
+	return &SellerService{
+		sellerRepo: sellerRepo,
+		publisher:  publisher,
+		log:        log,
+	}
+}
+
+// Subscribe 订阅商品事件：商家发布的商品创建时登记归属，商品删除时移除归属
+func (s *SellerService) Subscribe(consumer *events.Consumer) error {
+	if err := consumer.Subscribe(event.ProductCreated, func(ctx context.Context, env *events.Envelope) error {
+		var evt event.ProductEvent
+		if err := env.Decode(&evt); err != nil {
+			return events.Permanent(err)
+		}
+		if evt.SellerID == nil {
+			return nil
+		}
+		_, err := s.sellerRepo.AssignProduct(ctx, evt.ID, *evt.SellerID)
+		return err
+	}); err != nil {
+		return err
+	}
+	return consumer.Subscribe(event.ProductDeleted, func(ctx context.Context, env *events.Envelope) error {
+		var evt event.ProductDeletedEvent
+		if err := env.Decode(&evt); err != nil {
+			return events.Permanent(err)
+		}
+		return s.sellerRepo.RemoveProduct(ctx, evt.ID)
+	})
+}
+
+// Apply 申请入驻，审核通过后才能登记商品。被拒绝的申请可修改资料后重新提交
+func (s *SellerService) Apply(ctx context.Context, userID uint, req *ApplySellerRequest) (*model.Seller, error) {
+	shopName := strings.TrimSpace(req.ShopName)
+	if shopName == "" {
+		return nil, apperrors.NewBadRequest("店铺名称不能为空", nil)
+	}
+
+	seller, err := s.sellerRepo.GetByUserID(ctx, userID)
+	switch {
+	case err == nil && seller.Status != model.SellerStatusRejected:
+		return nil, apperrors.NewConflict("已提交过入驻申请", nil)
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, apperrors.NewInternalServerError("获取商家失败", err)
+	}
+	if other, err := s.sellerRepo.GetByShopName(ctx, shopName); err == nil {
+		if seller == nil || other.ID != seller.ID {
+			return nil, apperrors.NewConflict("店铺名称已被使用", nil)
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NewInternalServerError("获取商家失败", err)
+	}
+
+	if seller == nil {
+		seller = &model.Seller{UserID: userID}
+	}
+	seller.ShopName = shopName
+	seller.Description = req.Description
+	seller.Logo = req.Logo
+	seller.ContactName = req.ContactName
+	seller.ContactEmail = req.ContactEmail
+	seller.ContactPhone = req.ContactPhone
+	seller.LicenseNumber = req.LicenseNumber
+	seller.PayoutMethod = req.PayoutMethod
+	seller.PayoutAccount = req.PayoutAccount
+	seller.Status = model.SellerStatusPending
+	seller.StatusReason = ""
+
+	if seller.ID == 0 {
+		err = s.sellerRepo.Create(ctx, seller)
+	} else {
+		err = s.sellerRepo.Update(ctx, seller)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("提交入驻申请失败", err)
+	}
+	return seller, nil
+}
+
+// Me 获取用户的商家账户
+func (s *SellerService) Me(ctx context.Context, userID uint) (*model.Seller, error) {
+	seller, err := s.sellerRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("尚未申请入驻", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取商家失败", err)
+	}
+	return seller, nil
+}
+
+// UpdateProfile 修改店铺资料和收款账户
+func (s *SellerService) UpdateProfile(ctx context.Context, userID uint, req *UpdateSellerRequest) (*model.Seller, error) {
+	seller, err := s.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Description != nil {
+		seller.Description = *req.Description
+	}
+	if req.Logo != nil {
+		seller.Logo = *req.Logo
+	}
+	if req.ContactName != nil {
+		seller.ContactName = *req.ContactName
+	}
+	if req.ContactEmail != nil {
+		seller.ContactEmail = *req.ContactEmail
+	}
+	if req.ContactPhone != nil {
+		seller.ContactPhone = *req.ContactPhone
+	}
+	if req.PayoutMethod != nil {
+		seller.PayoutMethod = *req.PayoutMethod
+	}
+	if req.PayoutAccount != nil {
+		seller.PayoutAccount = *req.PayoutAccount
+	}
+	if err := s.sellerRepo.Update(ctx, seller); err != nil {
+		return nil, apperrors.NewInternalServerError("更新店铺资料失败", err)
+	}
+	return seller, nil
+}
+
+// Profile 获取经营中商家的公开资料
+func (s *SellerService) Profile(ctx context.Context, id uint) (*SellerProfile, error) {
+	seller, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if seller.Status != model.SellerStatusActive {
+		return nil, apperrors.NewNotFound(fmt.Sprintf("商家 %d 不存在", id), nil)
+	}
+	return profile(seller), nil
+}
+
+// ProductSeller 获取商品所属商家的公开资料，平台自营或商家未在经营的商品返回 404
+func (s *SellerService) ProductSeller(ctx context.Context, productID uint) (*SellerProfile, error) {
+	product, err := s.sellerRepo.GetProduct(ctx, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("商品 %d 为平台自营", productID), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取商品归属失败", err)
+	}
+	return s.Profile(ctx, product.SellerID)
+}
+
+// ListProducts 分页获取商家的商品
+func (s *SellerService) ListProducts(ctx context.Context, userID uint, page, pageSize int) (*SellerProductList, error) {
+	seller, err := s.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	page, pageSize = normalizePage(page, pageSize)
+	products, total, err := s.sellerRepo.ListProducts(ctx, seller.ID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取商家商品失败", err)
+	}
+	return &SellerProductList{Items: products, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// List 分页获取商家，可按状态过滤
+func (s *SellerService) List(ctx context.Context, status string, page, pageSize int) (*SellerList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	sellers, total, err := s.sellerRepo.List(ctx, status, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取商家失败", err)
+	}
+	return &SellerList{Items: sellers, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Get 根据 ID 获取商家
+func (s *SellerService) Get(ctx context.Context, id uint) (*model.Seller, error) {
+	seller, err := s.sellerRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("商家 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取商家失败", err)
+	}
+	return seller, nil
+}
+
+// Approve 审核通过入驻申请或恢复停业的商家，首次通过时记录通过时间并通知商家
+func (s *SellerService) Approve(ctx context.Context, id uint) (*model.Seller, error) {
+	seller, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if seller.Status == model.SellerStatusActive {
+		return seller, nil
+	}
+	if seller.Status == model.SellerStatusRejected {
+		return nil, apperrors.NewBadRequest("入驻申请已被拒绝，需商家重新申请", nil)
+	}
+	firstApproval := seller.ApprovedAt == nil
+	if firstApproval {
+		now := time.Now()
+		seller.ApprovedAt = &now
+	}
+	seller.Status = model.SellerStatusActive
+	seller.StatusReason = ""
+	if err := s.sellerRepo.Update(ctx, seller); err != nil {
+		return nil, apperrors.NewInternalServerError("更新商家失败", err)
+	}
+
+	if firstApproval {
+		evt := &event.SellerEvent{
+			SellerID:     seller.ID,
+			UserID:       seller.UserID,
+			ShopName:     seller.ShopName,
+			ContactEmail: seller.ContactEmail,
+		}
+		if err := s.publisher.Publish(ctx, event.SellerApproved, event.EventVersion, evt); err != nil {
+			s.log.Warn(ctx, "Failed to publish seller event", zap.Uint("seller_id", seller.ID), zap.Error(err))
+		}
+	}
+	return seller, nil
+}
+
+// Reject 拒绝待审核的入驻申请
+func (s *SellerService) Reject(ctx context.Context, id uint, req *SellerStatusRequest) (*model.Seller, error) {
+	seller, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if seller.Status != model.SellerStatusPending {
+		return nil, apperrors.NewBadRequest("只能拒绝待审核的入驻申请", nil)
+	}
+	return s.setStatus(ctx, seller, model.SellerStatusRejected, req.Reason)
+}
+
+// Suspend 停业商家，停业期间不打款，已支付的订单照常履约和结算
+func (s *SellerService) Suspend(ctx context.Context, id uint, req *SellerStatusRequest) (*model.Seller, error) {
+	seller, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if seller.Status != model.SellerStatusActive {
+		return nil, apperrors.NewBadRequest("只能停业经营中的商家", nil)
+	}
+	return s.setStatus(ctx, seller, model.SellerStatusSuspended, req.Reason)
+}
+
+// SetCommissionRate 设置商家单独约定的佣金比例，只影响之后拆单的订单
+func (s *SellerService) SetCommissionRate(ctx context.Context, id uint, req *SellerCommissionRequest) (*model.Seller, error) {
+	seller, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	seller.CommissionRate = req.Rate
+	if err := s.sellerRepo.Update(ctx, seller); err != nil {
+		return nil, apperrors.NewInternalServerError("更新商家失败", err)
+	}
+	return seller, nil
+}
+
+// AssignProduct 登记商品归属的商家，只影响之后拆单的订单
+func (s *SellerService) AssignProduct(ctx context.Context, productID uint, req *AssignProductRequest) (*model.SellerProduct, error) {
+	seller, err := s.Get(ctx, req.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	if seller.Status == model.SellerStatusPending || seller.Status == model.SellerStatusRejected {
+		return nil, apperrors.NewBadRequest("商家尚未审核通过", nil)
+	}
+	product, err := s.sellerRepo.AssignProduct(ctx, productID, seller.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("登记商品归属失败", err)
+	}
+	return product, nil
+}
+
+// RemoveProduct 移除商品归属，商品改为平台自营
+func (s *SellerService) RemoveProduct(ctx context.Context, productID uint) error {
+	if err := s.sellerRepo.RemoveProduct(ctx, productID); err != nil {
+		return apperrors.NewInternalServerError("移除商品归属失败", err)
+	}
+	return nil
+}
+
+// ListCommissionRules 获取分类佣金规则
+func (s *SellerService) ListCommissionRules(ctx context.Context) ([]*model.CommissionRule, error) {
+	rules, err := s.sellerRepo.ListCommissionRules(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取佣金规则失败", err)
+	}
+	return rules, nil
+}
+
+// SaveCommissionRule 设置分类佣金比例，只影响之后拆单的订单
+func (s *SellerService) SaveCommissionRule(ctx context.Context, req *CommissionRuleRequest) (*model.CommissionRule, error) {
+	rule := &model.CommissionRule{CategoryID: req.CategoryID, Rate: req.Rate}
+	if err := s.sellerRepo.SaveCommissionRule(ctx, rule); err != nil {
+		return nil, apperrors.NewInternalServerError("保存佣金规则失败", err)
+	}
+	return rule, nil
+}
+
+// DeleteCommissionRule 删除分类佣金规则，该分类改按默认比例计佣
+func (s *SellerService) DeleteCommissionRule(ctx context.Context, categoryID uint) error {
+	if err := s.sellerRepo.DeleteCommissionRule(ctx, categoryID); err != nil {
+		return apperrors.NewInternalServerError("删除佣金规则失败", err)
+	}
+	return nil
+}
+
+func (s *SellerService) setStatus(ctx context.Context, seller *model.Seller, status, reason string) (*model.Seller, error) {
+	seller.Status = status
+	seller.StatusReason = reason
+	if err := s.sellerRepo.Update(ctx, seller); err != nil {
+		return nil, apperrors.NewInternalServerError("更新商家失败", err)
+	}
+	return seller, nil
+}
+
+func profile(seller *model.Seller) *SellerProfile {
+	p := &SellerProfile{
+		ID:          seller.ID,
+		ShopName:    seller.ShopName,
+		Description: seller.Description,
+		Logo:        seller.Logo,
+		JoinedAt:    seller.CreatedAt,
+	}
+	if seller.ApprovedAt != nil {
+		p.JoinedAt = *seller.ApprovedAt
+	}
+	return p
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
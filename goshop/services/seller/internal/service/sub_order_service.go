@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/currency"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/seller/internal/event"
+	"github.com/yourusername/goshop/services/seller/internal/model"
+	"github.com/yourusername/goshop/services/seller/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ShipRequest 表示商家发货的请求
+type ShipRequest struct {
+	Carrier        string `json:"carrier" binding:"required,max=50"`
+	TrackingNumber string `json:"tracking_number" binding:"required,max=100"`
+}
+
+// SubOrderList 表示分页的子订单列表
+type SubOrderList struct {
+	Items    []*model.SubOrder `json:"items"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// SubOrderService 负责按商家拆分订单、子订单履约以及取消和退款的分摊。
+// 订单支付后，属于入驻商家的商品按商家拆成子订单并逐行计算平台佣金，平台自营的商品不拆单
+type SubOrderService struct {
+	subOrderRepo repository.SubOrderRepository
+	sellerRepo   repository.SellerRepository
+	publisher    event.Publisher
+	defaultRate  float64
+	log          *logger.Logger
+}
+
+// NewSubOrderService 创建子订单服务，defaultRate 是没有任何佣金规则时的佣金百分比
+func NewSubOrderService(subOrderRepo repository.SubOrderRepository, sellerRepo repository.SellerRepository, publisher event.Publisher, defaultRate float64, log *logger.Logger) *SubOrderService {
+	return &SubOrderService{
+		subOrderRepo: subOrderRepo,
+		sellerRepo:   sellerRepo,
+		publisher:    publisher,
+		defaultRate:  defaultRate,
+		log:          log,
+	}
+}
+
+// Subscribe 订阅订单事件：支付后拆单，签收后子订单进入结算等待期，取消和退款时分摊到子订单
+func (s *SubOrderService) Subscribe(consumer *events.Consumer) error {
+	handlers := map[string]func(ctx context.Context, evt *event.OrderEvent) error{
+		event.OrderPaid:      s.HandleOrderPaid,
+		event.OrderCancelled: s.HandleOrderCancelled,
+		event.OrderDelivered: s.HandleOrderDelivered,
+	}
+	for eventType, handle := range handlers {
+		handle := handle
+		if err := consumer.Subscribe(eventType, func(ctx context.Context, env *events.Envelope) error {
+			var evt event.OrderEvent
+			if err := env.Decode(&evt); err != nil {
+				return events.Permanent(err)
+			}
+			return handle(ctx, &evt)
+		}); err != nil {
+			return err
+		}
+	}
+	return consumer.Subscribe(event.OrderRefunded, func(ctx context.Context, env *events.Envelope) error {
+		var evt event.OrderRefundEvent
+		if err := env.Decode(&evt); err != nil {
+			return events.Permanent(err)
+		}
+		return s.HandleOrderRefunded(ctx, &evt)
+	})
+}
+
+// HandleOrderPaid 将订单中属于入驻商家的商品按商家拆成子订单，重复的事件不会重复拆单
+func (s *SubOrderService) HandleOrderPaid(ctx context.Context, evt *event.OrderEvent) error {
+	productIDs := make([]uint, 0, len(evt.Items))
+	for _, item := range evt.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	owners, err := s.sellerRepo.ProductOwners(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+	rules, err := s.sellerRepo.ListCommissionRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 按商品在订单中的顺序生成子订单，佣金逐行计算并取整到分
+	bySeller := make(map[uint]*model.SubOrder)
+	overrides := make(map[uint]*float64)
+	var subOrders []*model.SubOrder
+	for _, item := range evt.Items {
+		sellerID, ok := owners[item.ProductID]
+		if !ok {
+			continue
+		}
+		subOrder, ok := bySeller[sellerID]
+		if !ok {
+			seller, err := s.sellerRepo.GetByID(ctx, sellerID)
+			if err != nil {
+				return err
+			}
+			if seller.Status != model.SellerStatusActive {
+				s.log.Warn(ctx, "Splitting order for seller not active",
+					zap.Uint("order_id", evt.OrderID),
+					zap.Uint("seller_id", sellerID),
+					zap.String("status", seller.Status),
+				)
+			}
+			subOrder = &model.SubOrder{
+				OrderID:     evt.OrderID,
+				SellerID:    sellerID,
+				OrderNumber: evt.OrderNumber,
+				UserID:      evt.UserID,
+				Status:      model.SubOrderAwaitingShipment,
+				Subtotal:    currency.Zero(currency.Default),
+				Commission:  currency.Zero(currency.Default),
+			}
+			bySeller[sellerID] = subOrder
+			overrides[sellerID] = seller.CommissionRate
+			subOrders = append(subOrders, subOrder)
+		}
+
+		total := currency.FromMajor(item.Total, currency.Default)
+		rate := commissionRate(overrides[sellerID], rules, item.CategoryIDs, s.defaultRate)
+		commission := total.Percent(rate, currency.HalfUp)
+		subOrder.Items = append(subOrder.Items, model.SubOrderItem{
+			ProductID:      item.ProductID,
+			SKUID:          item.SKUID,
+			Quantity:       item.Quantity,
+			Total:          total,
+			CommissionRate: rate,
+			Commission:     commission,
+		})
+		subOrder.Subtotal = subOrder.Subtotal.Add(total)
+		subOrder.Commission = subOrder.Commission.Add(commission)
+	}
+
+	created, err := s.subOrderRepo.CreateForOrder(ctx, subOrders)
+	if err != nil {
+		return err
+	}
+	for _, subOrder := range created {
+		s.publish(ctx, event.SubOrderCreated, subOrder)
+	}
+	if len(created) > 0 {
+		s.log.Info(ctx, "Split order by seller",
+			zap.Uint("order_id", evt.OrderID),
+			zap.Int("sub_orders", len(created)),
+		)
+	}
+	return nil
+}
+
+// HandleOrderCancelled 取消订单中待发货的子订单，已发货的子订单需走退款流程
+func (s *SubOrderService) HandleOrderCancelled(ctx context.Context, evt *event.OrderEvent) error {
+	subOrders, err := s.subOrderRepo.ListByOrder(ctx, evt.OrderID)
+	if err != nil {
+		return err
+	}
+	for _, subOrder := range subOrders {
+		if subOrder.Status == model.SubOrderCancelled {
+			continue
+		}
+		ok, err := s.subOrderRepo.Transition(ctx, subOrder.ID, []string{model.SubOrderAwaitingShipment}, map[string]interface{}{
+			"status": model.SubOrderCancelled,
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.log.Warn(ctx, "Order cancelled after seller shipped",
+				zap.Uint("order_id", evt.OrderID),
+				zap.Uint("sub_order_id", subOrder.ID),
+				zap.String("status", subOrder.Status),
+			)
+			continue
+		}
+		subOrder.Status = model.SubOrderCancelled
+		s.publish(ctx, event.SubOrderCancelled, subOrder)
+	}
+	return nil
+}
+
+// HandleOrderDelivered 将订单的子订单标记为已签收，结算等待期从此时开始计算
+func (s *SubOrderService) HandleOrderDelivered(ctx context.Context, evt *event.OrderEvent) error {
+	subOrders, err := s.subOrderRepo.ListByOrder(ctx, evt.OrderID)
+	if err != nil {
+		return err
+	}
+	for _, subOrder := range subOrders {
+		if _, err := s.deliver(ctx, subOrder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleOrderRefunded 将退款分摊到子订单：按商品退款时归到商品所在的子订单，
+// 否则按各子订单实付金额占订单总额的比例分摊，剩余部分由平台自营商品和运费承担
+func (s *SubOrderService) HandleOrderRefunded(ctx context.Context, evt *event.OrderRefundEvent) error {
+	subOrders, err := s.subOrderRepo.ListByOrder(ctx, evt.OrderID)
+	if err != nil {
+		return err
+	}
+	if len(subOrders) == 0 {
+		return nil
+	}
+	amounts, err := allocateRefund(evt, subOrders)
+	if err != nil {
+		return err
+	}
+
+	for i, subOrder := range subOrders {
+		if !amounts[i].IsPositive() {
+			continue
+		}
+		refund := &model.SubOrderRefund{RefundID: evt.RefundID, Amount: amounts[i]}
+		entry := &model.LedgerEntry{
+			Type:          model.EntryRefund,
+			ReferenceType: model.RefRefund,
+			Description:   fmt.Sprintf("订单 %s 退款", evt.OrderNumber),
+		}
+		applied, err := s.subOrderRepo.ApplyRefund(ctx, subOrder.ID, refund, entry)
+		if err != nil {
+			return err
+		}
+		if applied {
+			s.log.Info(ctx, "Applied refund to sub-order",
+				zap.Uint("order_id", evt.OrderID),
+				zap.Uint("sub_order_id", subOrder.ID),
+				zap.String("refund_id", evt.RefundID),
+				zap.String("amount", refund.Amount.Decimal()),
+				zap.String("commission", refund.Commission.Decimal()),
+			)
+		}
+	}
+	return nil
+}
+
+// ListMine 分页获取商家的子订单，可按状态过滤
+func (s *SubOrderService) ListMine(ctx context.Context, userID uint, status string, page, pageSize int) (*SubOrderList, error) {
+	seller, err := s.me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.List(ctx, repository.SubOrderFilter{SellerID: seller.ID, Status: status}, page, pageSize)
+}
+
+// GetMine 获取商家的子订单
+func (s *SubOrderService) GetMine(ctx context.Context, userID, id uint) (*model.SubOrder, error) {
+	seller, err := s.me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	subOrder, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if subOrder.SellerID != seller.ID {
+		return nil, apperrors.NewNotFound(fmt.Sprintf("子订单 %d 不存在", id), nil)
+	}
+	return subOrder, nil
+}
+
+// Ship 商家为待发货的子订单填写物流信息并发货
+func (s *SubOrderService) Ship(ctx context.Context, userID, id uint, req *ShipRequest) (*model.SubOrder, error) {
+	subOrder, err := s.GetMine(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	ok, err := s.subOrderRepo.Transition(ctx, subOrder.ID, []string{model.SubOrderAwaitingShipment}, map[string]interface{}{
+		"status":          model.SubOrderShipped,
+		"carrier":         req.Carrier,
+		"tracking_number": req.TrackingNumber,
+		"shipped_at":      now,
+	})
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("更新子订单失败", err)
+	}
+	if !ok {
+		return nil, apperrors.NewBadRequest("只能发货待发货的子订单", nil)
+	}
+	subOrder.Status = model.SubOrderShipped
+	subOrder.Carrier = req.Carrier
+	subOrder.TrackingNumber = req.TrackingNumber
+	subOrder.ShippedAt = &now
+	s.publish(ctx, event.SubOrderShipped, subOrder)
+	return subOrder, nil
+}
+
+// List 按过滤条件分页获取子订单
+func (s *SubOrderService) List(ctx context.Context, filter repository.SubOrderFilter, page, pageSize int) (*SubOrderList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	subOrders, total, err := s.subOrderRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取子订单失败", err)
+	}
+	return &SubOrderList{Items: subOrders, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Get 根据 ID 获取子订单
+func (s *SubOrderService) Get(ctx context.Context, id uint) (*model.SubOrder, error) {
+	subOrder, err := s.subOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("子订单 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取子订单失败", err)
+	}
+	return subOrder, nil
+}
+
+// Deliver 后台确认子订单已签收，用于买家未确认收货且物流已妥投的情况
+func (s *SubOrderService) Deliver(ctx context.Context, id uint) (*model.SubOrder, error) {
+	subOrder, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.deliver(ctx, subOrder)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("更新子订单失败", err)
+	}
+	if !ok {
+		return nil, apperrors.NewBadRequest("子订单已签收或已取消", nil)
+	}
+	return subOrder, nil
+}
+
+// deliver 将未签收的子订单标记为已签收，子订单已签收或已取消时返回 false
+func (s *SubOrderService) deliver(ctx context.Context, subOrder *model.SubOrder) (bool, error) {
+	now := time.Now()
+	ok, err := s.subOrderRepo.Transition(ctx, subOrder.ID, []string{model.SubOrderAwaitingShipment, model.SubOrderShipped}, map[string]interface{}{
+		"status":       model.SubOrderDelivered,
+		"delivered_at": now,
+	})
+	if err != nil || !ok {
+		return false, err
+	}
+	subOrder.Status = model.SubOrderDelivered
+	subOrder.DeliveredAt = &now
+	s.publish(ctx, event.SubOrderDelivered, subOrder)
+	return true, nil
+}
+
+func (s *SubOrderService) me(ctx context.Context, userID uint) (*model.Seller, error) {
+	seller, err := s.sellerRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("尚未申请入驻", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取商家失败", err)
+	}
+	return seller, nil
+}
+
+// publish 发布子订单事件，发布失败只记录日志
+func (s *SubOrderService) publish(ctx context.Context, eventType string, subOrder *model.SubOrder) {
+	evt := &event.SubOrderEvent{
+		SubOrderID:     subOrder.ID,
+		OrderID:        subOrder.OrderID,
+		OrderNumber:    subOrder.OrderNumber,
+		SellerID:       subOrder.SellerID,
+		UserID:         subOrder.UserID,
+		Status:         subOrder.Status,
+		Subtotal:       subOrder.Subtotal.Float64(),
+		Carrier:        subOrder.Carrier,
+		TrackingNumber: subOrder.TrackingNumber,
+		OccurredAt:     time.Now(),
+	}
+	if err := s.publisher.Publish(ctx, eventType, event.EventVersion, evt); err != nil {
+		s.log.Warn(ctx, "Failed to publish sub-order event",
+			zap.String("event_type", eventType),
+			zap.Uint("sub_order_id", subOrder.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// allocateRefund 计算退款分摊到各子订单的金额，顺序与 subOrders 一致
+func allocateRefund(evt *event.OrderRefundEvent, subOrders []*model.SubOrder) ([]currency.Money, error) {
+	amounts := make([]currency.Money, len(subOrders))
+	if len(evt.Items) > 0 {
+		index := make(map[uint]int)
+		for i, subOrder := range subOrders {
+			for _, item := range subOrder.Items {
+				index[item.ProductID] = i
+			}
+		}
+		for _, item := range evt.Items {
+			if i, ok := index[item.ProductID]; ok {
+				amounts[i] = amounts[i].Add(currency.FromMajor(item.Amount, currency.Default))
+			}
+		}
+		return amounts, nil
+	}
+
+	refund := currency.FromMajor(evt.RefundAmount, currency.Default)
+	grandTotal := currency.FromMajor(evt.GrandTotal, currency.Default)
+	if !grandTotal.IsPositive() || !refund.LessThan(grandTotal) {
+		for i, subOrder := range subOrders {
+			amounts[i] = subOrder.Subtotal
+		}
+		return amounts, nil
+	}
+
+	ratios := make([]int64, 0, len(subOrders)+1)
+	rest := grandTotal
+	for _, subOrder := range subOrders {
+		ratios = append(ratios, subOrder.Subtotal.Amount())
+		rest = rest.Sub(subOrder.Subtotal)
+	}
+	ratios = append(ratios, max(rest.Amount(), 0))
+	parts, err := refund.Allocate(ratios...)
+	if err != nil {
+		return nil, err
+	}
+	copy(amounts, parts)
+	return amounts, nil
+}
+
+// commissionRate 返回商品行适用的佣金比例：商家单独约定的比例优先，其次是商品所属分类中
+// 比例最高的规则，都未配置时使用默认规则，没有默认规则时使用 defaultRate
+func commissionRate(override *float64, rules []*model.CommissionRule, categoryIDs []uint, defaultRate float64) float64 {
+	if override != nil {
+		return *override
+	}
+	rate, matched := 0.0, false
+	fallback := defaultRate
+	for _, rule := range rules {
+		if rule.CategoryID == 0 {
+			fallback = rule.Rate
+			continue
+		}
+		for _, id := range categoryIDs {
+			if id == rule.CategoryID && (!matched || rule.Rate > rate) {
+				rate, matched = rule.Rate, true
+			}
+		}
+	}
+	if !matched {
+		return fallback
+	}
+	return rate
+}
@@ -0,0 +1,100 @@
+package carrier
+
+import (
+	"context"
+	"time"
+)
+
+// LabelFormat 表示面单文件格式
+type LabelFormat string
+
+const (
+	// LabelFormatPDF PDF 面单
+	LabelFormatPDF LabelFormat = "pdf"
+	// LabelFormatPNG PNG 面单
+	LabelFormatPNG LabelFormat = "png"
+	// LabelFormatZPL 热敏打印机 ZPL 面单
+	LabelFormatZPL LabelFormat = "zpl"
+	// LabelFormatQR 无纸化二维码，用户在承运商网点出示后由网点打印面单
+	LabelFormatQR LabelFormat = "qr"
+)
+
+// Address 表示寄件或收件地址
+type Address struct {
+	Name       string `json:"name"`
+	Phone      string `json:"phone"`
+	Country    string `json:"country"` // ISO 3166-1 二位代码
+	Province   string `json:"province"`
+	City       string `json:"city"`
+	District   string `json:"district"`
+	Street     string `json:"street"`
+	PostalCode string `json:"postal_code"`
+}
+
+// Parcel 表示一个包裹
+type Parcel struct {
+	Weight float64 `json:"weight"` // 重量（公斤）
+	Length float64 `json:"length"` // 长度（厘米）
+	Width  float64 `json:"width"`  // 宽度（厘米）
+	Height float64 `json:"height"` // 高度（厘米）
+}
+
+// ShipmentRequest 表示向承运商下单的请求
+type ShipmentRequest struct {
+	Reference     string   `json:"reference"`    // 业务单号，通常为订单号
+	ServiceCode   string   `json:"service_code"` // 承运商产品代码，为空时使用承运商默认产品
+	Sender        Address  `json:"sender"`
+	Recipient     Address  `json:"recipient"`
+	Parcels       []Parcel `json:"parcels"`
+	DeclaredValue float64  `json:"declared_value"` // 保价金额
+	Currency      string   `json:"currency"`
+}
+
+// ShipmentResult 表示承运商下单结果
+type ShipmentResult struct {
+	ProviderShipmentID string     `json:"provider_shipment_id"` // 承运商侧的运单 ID
+	TrackingNumber     string     `json:"tracking_number"`
+	EstimatedDelivery  *time.Time `json:"estimated_delivery,omitempty"`
+}
+
+// Label 表示承运商面单
+type Label struct {
+	TrackingNumber string      `json:"tracking_number"`
+	Format         LabelFormat `json:"format"`
+	URL            string      `json:"url,omitempty"`           // 承运商托管的面单地址
+	Data           []byte      `json:"data,omitempty"`          // 面单文件内容，未提供 URL 时返回
+	DropOffCode    string      `json:"drop_off_code,omitempty"` // 网点寄件码，仅二维码面单
+}
+
+// TrackingEvent 表示承运商返回的一条物流轨迹
+type TrackingEvent struct {
+	Status      string    `json:"status"` // 统一后的状态：in_transit, out_for_delivery, delivered, exception
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// ServiceOption 表示承运商提供的一种快递产品
+type ServiceOption struct {
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	EstimatedDays string `json:"estimated_days"`
+	International bool   `json:"international"`
+}
+
+// CarrierProvider 定义承运商适配器接口，每个承运商（如顺丰、FedEx、DHL）实现一个适配器，
+// 通过 ShippingCarrier.APICode 在 Registry 中查找
+type CarrierProvider interface {
+	// APICode 返回适配器对应的 ShippingCarrier.APICode
+	APICode() string
+	// CreateShipment 在承运商系统中下单并获取运单号
+	CreateShipment(ctx context.Context, req *ShipmentRequest) (*ShipmentResult, error)
+	// BuyLabel 购买并获取运单面单
+	BuyLabel(ctx context.Context, trackingNumber string, format LabelFormat) (*Label, error)
+	// Track 查询运单的物流轨迹，按发生时间升序返回
+	Track(ctx context.Context, trackingNumber string) ([]TrackingEvent, error)
+	// Cancel 取消尚未揽收的运单
+	Cancel(ctx context.Context, trackingNumber string) error
+	// ListServices 列出承运商可用的快递产品
+	ListServices(ctx context.Context) ([]ServiceOption, error)
+}
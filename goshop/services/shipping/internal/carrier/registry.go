@@ -0,0 +1,68 @@
+package carrier
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+)
+
+// ErrProviderNotFound 表示承运商没有注册适配器
+var ErrProviderNotFound = errors.New("carrier provider not found")
+
+// Registry 按 APICode 管理承运商适配器
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]CarrierProvider
+}
+
+// NewRegistry 创建承运商适配器注册表
+func NewRegistry(providers ...CarrierProvider) *Registry {
+	r := &Registry{
+		providers: make(map[string]CarrierProvider),
+	}
+	for _, p := range providers {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register 注册承运商适配器，同一 APICode 重复注册时后者覆盖前者
+func (r *Registry) Register(p CarrierProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.APICode()] = p
+}
+
+// Get 根据 APICode 获取承运商适配器
+func (r *Registry) Get(apiCode string) (CarrierProvider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[apiCode]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, apiCode)
+	}
+	return p, nil
+}
+
+// ForCarrier 获取物流公司对应的适配器，未配置 APICode 的物流公司视为人工录单
+func (r *Registry) ForCarrier(c *model.ShippingCarrier) (CarrierProvider, error) {
+	if c.APICode == nil || *c.APICode == "" {
+		return nil, fmt.Errorf("%w: carrier %s has no api code", ErrProviderNotFound, c.Code)
+	}
+	return r.Get(*c.APICode)
+}
+
+// APICodes 返回已注册的所有 APICode
+func (r *Registry) APICodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	codes := make([]string, 0, len(r.providers))
+	for code := range r.providers {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
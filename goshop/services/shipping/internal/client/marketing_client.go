@@ -0,0 +1,76 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CartItem 表示计算促销活动的购物车商品
+type CartItem struct {
+	ProductID   uint    `json:"product_id"`
+	SKUID       uint    `json:"sku_id"`
+	CategoryIDs []uint  `json:"category_ids"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"`
+}
+
+// MarketingClient 通过 HTTP 调用营销服务
+type MarketingClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewMarketingClient 创建营销服务客户端
+func NewMarketingClient(baseURL string) *MarketingClient {
+	return &MarketingClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// evaluateResponse 对应营销服务 POST /api/v1/marketing/promotions/evaluate 的响应
+type evaluateResponse struct {
+	Data struct {
+		Applied []struct {
+			PromotionID uint `json:"promotion_id"`
+		} `json:"applied"`
+	} `json:"data"`
+}
+
+// AppliedPromotions 由营销服务计算购物车实际享受的促销活动，userID 为 0 表示匿名用户
+func (c *MarketingClient) AppliedPromotions(ctx context.Context, userID uint, items []CartItem) ([]uint, error) {
+	body, err := json.Marshal(map[string]interface{}{"user_id": userID, "items": items})
+	if err != nil {
+		return nil, err
+	}
+	url := c.baseURL + "/api/v1/marketing/promotions/evaluate"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("marketing service returned status %d", resp.StatusCode)
+	}
+
+	var result evaluateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(result.Data.Applied))
+	for _, applied := range result.Data.Applied {
+		ids = append(ids, applied.PromotionID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ProductDimensions 表示商品的重量和尺寸
+type ProductDimensions struct {
+	Weight float64 `json:"weight"` // 重量（公斤）
+	Length float64 `json:"length"` // 长度（厘米）
+	Width  float64 `json:"width"`  // 宽度（厘米）
+	Height float64 `json:"height"` // 高度（厘米）
+}
+
+// ProductClient 通过 HTTP 调用商品服务
+type ProductClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewProductClient 创建商品服务客户端
+func NewProductClient(baseURL string) *ProductClient {
+	return &ProductClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// productResponse 对应商品服务 GET /api/v1/products/:id 的响应
+type productResponse struct {
+	Data struct {
+		Weight *float64 `json:"weight"`
+		Length *float64 `json:"length"`
+		Width  *float64 `json:"width"`
+		Height *float64 `json:"height"`
+		SKUs   []struct {
+			ID     uint     `json:"id"`
+			Weight *float64 `json:"weight"`
+		} `json:"skus"`
+	} `json:"data"`
+}
+
+// GetDimensions 获取商品的重量和尺寸，SKU 配置了重量时优先使用 SKU 重量
+func (c *ProductClient) GetDimensions(ctx context.Context, productID, skuID uint) (*ProductDimensions, error) {
+	url := fmt.Sprintf("%s/api/v1/products/%d", c.baseURL, productID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("product service returned status %d", resp.StatusCode)
+	}
+
+	var body productResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	p := body.Data
+	dims := &ProductDimensions{
+		Weight: valueOf(p.Weight),
+		Length: valueOf(p.Length),
+		Width:  valueOf(p.Width),
+		Height: valueOf(p.Height),
+	}
+	for _, sku := range p.SKUs {
+		if sku.ID == skuID && sku.Weight != nil {
+			dims.Weight = *sku.Weight
+		}
+	}
+	return dims, nil
+}
+
+func valueOf(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
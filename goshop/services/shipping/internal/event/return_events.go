@@ -0,0 +1,30 @@
+package event
+
+import "time"
+
+// 退货运单事件类型，通知服务向用户推送面单，仓库服务在退货在途时准备收货
+const (
+	ReturnLabelCreated = "return.label_created"
+	ReturnInbound      = "return.inbound"
+	ReturnReceived     = "return.received"
+	ReturnException    = "return.exception"
+)
+
+// ReturnEvent 是退货运单事件的数据
+type ReturnEvent struct {
+	ReturnID       uint                `json:"return_id"`
+	RMANumber      string              `json:"rma_number"`
+	OrderID        uint                `json:"order_id"`
+	OrderNumber    string              `json:"order_number"`
+	UserID         uint                `json:"user_id"`
+	WarehouseID    uint                `json:"warehouse_id"`
+	Status         string              `json:"status"`
+	CarrierName    string              `json:"carrier_name"`
+	TrackingNumber string              `json:"tracking_number"`
+	TrackingURL    *string             `json:"tracking_url,omitempty"`
+	LabelURL       *string             `json:"label_url,omitempty"`
+	DropOffCode    *string             `json:"drop_off_code,omitempty"`
+	ExpiresAt      time.Time           `json:"expires_at"`
+	Checkpoints    []CheckpointSummary `json:"checkpoints,omitempty"`
+	Reason         string              `json:"reason,omitempty"`
+}
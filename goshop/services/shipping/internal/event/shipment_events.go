@@ -0,0 +1,35 @@
+package event
+
+import "time"
+
+// 配送单事件类型，通知服务订阅后向用户发送邮件/短信
+const (
+	ShipmentCreated        = "shipment.created"
+	ShipmentShipped        = "shipment.shipped"
+	ShipmentOutForDelivery = "shipment.out_for_delivery"
+	ShipmentDelivered      = "shipment.delivered"
+	ShipmentException      = "shipment.exception"
+)
+
+// CheckpointSummary 表示物流轨迹节点摘要
+type CheckpointSummary struct {
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// ShipmentEvent 是配送单事件的数据
+type ShipmentEvent struct {
+	ShipmentID          uint                `json:"shipment_id"`
+	OrderID             uint                `json:"order_id"`
+	OrderNumber         string              `json:"order_number"`
+	UserID              uint                `json:"user_id"`
+	Status              string              `json:"status"`
+	CarrierName         *string             `json:"carrier_name,omitempty"`
+	TrackingNumber      *string             `json:"tracking_number,omitempty"`
+	TrackingURL         *string             `json:"tracking_url,omitempty"`
+	EstimatedDeliveryAt *time.Time          `json:"estimated_delivery_at,omitempty"`
+	Checkpoints         []CheckpointSummary `json:"checkpoints,omitempty"` // 最近的物流轨迹，按时间倒序
+	Reason              string              `json:"reason,omitempty"`      // 异常原因，仅 shipment.exception
+}
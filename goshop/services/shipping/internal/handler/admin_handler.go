@@ -0,0 +1,361 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/shipping/internal/service"
+)
+
+// AdminHandler 处理物流后台管理的 HTTP 请求
+type AdminHandler struct {
+	adminService *service.AdminService
+	rateService  *service.RateService
+}
+
+// NewAdminHandler 创建物流后台管理处理器
+func NewAdminHandler(adminService *service.AdminService, rateService *service.RateService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		rateService:  rateService,
+	}
+}
+
+// RegisterRoutes 注册物流后台管理路由
+func (h *AdminHandler) RegisterRoutes(api *gin.RouterGroup) {
+	admin := api.Group("/shipping/admin")
+	{
+		admin.GET("/methods", h.ListMethods)
+		admin.POST("/methods", h.CreateMethod)
+		admin.GET("/methods/:id", h.GetMethod)
+		admin.PUT("/methods/:id", h.UpdateMethod)
+		admin.DELETE("/methods/:id", h.DeleteMethod)
+
+		admin.GET("/carriers", h.ListCarriers)
+		admin.POST("/carriers", h.CreateCarrier)
+		admin.GET("/carriers/:id", h.GetCarrier)
+		admin.PUT("/carriers/:id", h.UpdateCarrier)
+		admin.DELETE("/carriers/:id", h.DeleteCarrier)
+
+		admin.GET("/zones", h.ListZones)
+		admin.POST("/zones", h.CreateZone)
+		admin.GET("/zones/:id", h.GetZone)
+		admin.PUT("/zones/:id", h.UpdateZone)
+		admin.DELETE("/zones/:id", h.DeleteZone)
+
+		admin.GET("/rates", h.ListRates)
+		admin.POST("/rates", h.CreateRate)
+		admin.POST("/rates/simulate", h.SimulateRates)
+		admin.GET("/rates/:id", h.GetRate)
+		admin.PUT("/rates/:id", h.UpdateRate)
+		admin.DELETE("/rates/:id", h.DeleteRate)
+	}
+}
+
+// ListMethods 获取配送方式列表
+func (h *AdminHandler) ListMethods(c *gin.Context) {
+	methods, err := h.adminService.ListMethods(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": methods})
+}
+
+// GetMethod 获取配送方式
+func (h *AdminHandler) GetMethod(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	method, err := h.adminService.GetMethod(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": method})
+}
+
+// CreateMethod 创建配送方式
+func (h *AdminHandler) CreateMethod(c *gin.Context) {
+	var req service.MethodRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	method, err := h.adminService.CreateMethod(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": method})
+}
+
+// UpdateMethod 更新配送方式
+func (h *AdminHandler) UpdateMethod(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.MethodRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	method, err := h.adminService.UpdateMethod(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": method})
+}
+
+// DeleteMethod 删除配送方式
+func (h *AdminHandler) DeleteMethod(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.adminService.DeleteMethod(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListCarriers 获取物流公司列表
+func (h *AdminHandler) ListCarriers(c *gin.Context) {
+	carriers, err := h.adminService.ListCarriers(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": carriers})
+}
+
+// GetCarrier 获取物流公司
+func (h *AdminHandler) GetCarrier(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	carrier, err := h.adminService.GetCarrier(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": carrier})
+}
+
+// CreateCarrier 创建物流公司
+func (h *AdminHandler) CreateCarrier(c *gin.Context) {
+	var req service.CarrierRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	carrier, err := h.adminService.CreateCarrier(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": carrier})
+}
+
+// UpdateCarrier 更新物流公司
+func (h *AdminHandler) UpdateCarrier(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.CarrierRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	carrier, err := h.adminService.UpdateCarrier(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": carrier})
+}
+
+// DeleteCarrier 删除物流公司
+func (h *AdminHandler) DeleteCarrier(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.adminService.DeleteCarrier(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListZones 获取运费区域列表
+func (h *AdminHandler) ListZones(c *gin.Context) {
+	zones, err := h.adminService.ListZones(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": zones})
+}
+
+// GetZone 获取运费区域
+func (h *AdminHandler) GetZone(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	zone, err := h.adminService.GetZone(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": zone})
+}
+
+// CreateZone 创建运费区域
+func (h *AdminHandler) CreateZone(c *gin.Context) {
+	var req service.ZoneRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	zone, err := h.adminService.CreateZone(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": zone})
+}
+
+// UpdateZone 更新运费区域
+func (h *AdminHandler) UpdateZone(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.ZoneRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	zone, err := h.adminService.UpdateZone(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": zone})
+}
+
+// DeleteZone 删除运费区域
+func (h *AdminHandler) DeleteZone(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.adminService.DeleteZone(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListRates 获取运费规则列表，支持 method_id 和 zone_id 过滤
+func (h *AdminHandler) ListRates(c *gin.Context) {
+	methodID, ok := parseIDQuery(c, "method_id")
+	if !ok {
+		return
+	}
+	zoneID, ok := parseIDQuery(c, "zone_id")
+	if !ok {
+		return
+	}
+	rates, err := h.adminService.ListRates(c.Request.Context(), methodID, zoneID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rates})
+}
+
+// GetRate 获取运费规则
+func (h *AdminHandler) GetRate(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	rate, err := h.adminService.GetRate(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rate})
+}
+
+// CreateRate 创建运费规则
+func (h *AdminHandler) CreateRate(c *gin.Context) {
+	var req service.RateRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	rate, err := h.adminService.CreateRate(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": rate})
+}
+
+// UpdateRate 更新运费规则
+func (h *AdminHandler) UpdateRate(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.RateRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	rate, err := h.adminService.UpdateRate(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rate})
+}
+
+// DeleteRate 删除运费规则
+func (h *AdminHandler) DeleteRate(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.adminService.DeleteRate(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SimulateRates 模拟运费计算，展示假设购物车命中的运费规则
+func (h *AdminHandler) SimulateRates(c *gin.Context) {
+	var req service.SimulateRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	sim, err := h.rateService.Simulate(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sim})
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/shipping/internal/service"
+)
+
+// CustomsHandler 处理跨境配送报关相关的 HTTP 请求
+type CustomsHandler struct {
+	customsService *service.CustomsService
+}
+
+// NewCustomsHandler 创建报关处理器
+func NewCustomsHandler(customsService *service.CustomsService) *CustomsHandler {
+	return &CustomsHandler{
+		customsService: customsService,
+	}
+}
+
+// RegisterRoutes 注册报关路由
+func (h *CustomsHandler) RegisterRoutes(api *gin.RouterGroup) {
+	customs := api.Group("/shipping/shipments/:id/customs")
+	{
+		customs.GET("", h.GetDeclaration)
+		customs.PUT("", h.Declare)
+		customs.GET("/documents/:type", h.GetDocument)
+	}
+}
+
+// Declare 填写或更新报关信息
+func (h *CustomsHandler) Declare(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.CustomsRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	declaration, err := h.customsService.Declare(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        declaration,
+		"postal_form": service.PostalFormFor(declaration),
+	})
+}
+
+// GetDeclaration 获取报关信息
+func (h *CustomsHandler) GetDeclaration(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	declaration, err := h.customsService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        declaration,
+		"postal_form": service.PostalFormFor(declaration),
+	})
+}
+
+// GetDocument 生成 CN22/CN23 报关单或商业发票
+func (h *CustomsHandler) GetDocument(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	doc, err := h.customsService.RenderDocument(c.Request.Context(), id, service.CustomsDocumentType(c.Param("type")))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", doc)
+}
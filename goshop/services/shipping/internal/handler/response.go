@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/shipping/internal/service"
+)
+
+// respondError 中止请求，由错误中间件将错误转换为统一的 JSON 响应
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// parseIDParam 解析路径中的 ID 参数
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		respondError(c, apperrors.NewBadRequest("无效的 "+name, err))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseIDQuery 解析可选的 ID 查询参数，未提供时返回 0
+func parseIDQuery(c *gin.Context, name string) (uint, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		respondError(c, apperrors.NewBadRequest("无效的 "+name, err))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// currentBuyer 返回网关根据访问令牌设置的用户和会员等级，未登录时返回零值。
+// 会员等级只在有用户时采用
+func currentBuyer(c *gin.Context) *service.Buyer {
+	userID, err := strconv.ParseUint(c.GetHeader("X-User-ID"), 10, 64)
+	if err != nil || userID == 0 {
+		return &service.Buyer{}
+	}
+	level, _ := strconv.Atoi(c.GetHeader("X-Member-Level"))
+	return &service.Buyer{UserID: uint(userID), MemberLevel: level}
+}
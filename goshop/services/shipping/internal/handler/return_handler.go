@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/shipping/internal/service"
+)
+
+// 面单格式对应的 Content-Type
+var labelContentTypes = map[string]string{
+	"pdf": "application/pdf",
+	"png": "image/png",
+	"qr":  "image/png",
+	"zpl": "application/zpl",
+}
+
+// ReturnHandler 处理售后退货运单的 HTTP 请求
+type ReturnHandler struct {
+	returnService *service.ReturnService
+}
+
+// NewReturnHandler 创建退货运单处理器
+func NewReturnHandler(returnService *service.ReturnService) *ReturnHandler {
+	return &ReturnHandler{
+		returnService: returnService,
+	}
+}
+
+// RegisterRoutes 注册退货运单路由
+func (h *ReturnHandler) RegisterRoutes(api *gin.RouterGroup) {
+	returns := api.Group("/shipping/returns")
+	{
+		returns.POST("", h.CreateReturn)
+		returns.GET("", h.ListReturns)
+		returns.GET("/:id", h.GetReturn)
+		returns.GET("/:id/label", h.GetLabel)
+		returns.POST("/:id/cancel", h.CancelReturn)
+		returns.GET("/:id/checkpoints", h.ListCheckpoints)
+		returns.POST("/:id/checkpoints", h.AddCheckpoint)
+	}
+}
+
+// CreateReturn 生成退货面单
+func (h *ReturnHandler) CreateReturn(c *gin.Context) {
+	var req service.CreateReturnRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	ret, err := h.returnService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": ret})
+}
+
+// ListReturns 按订单查询退货运单
+func (h *ReturnHandler) ListReturns(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Query("order_id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, apperrors.NewBadRequest("缺少有效的 order_id", err))
+		return
+	}
+
+	rets, err := h.returnService.ListByOrder(c.Request.Context(), uint(orderID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rets})
+}
+
+// GetReturn 获取退货运单详情
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	ret, err := h.returnService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": ret})
+}
+
+// GetLabel 下载退货面单，承运商托管的面单重定向到承运商地址
+func (h *ReturnHandler) GetLabel(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	ret, err := h.returnService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if ret.LabelURL != nil {
+		c.Redirect(http.StatusFound, *ret.LabelURL)
+		return
+	}
+	if len(ret.LabelData) == 0 {
+		respondError(c, apperrors.NewNotFound("退货面单不存在", nil))
+		return
+	}
+
+	contentType, ok := labelContentTypes[ret.LabelFormat]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	c.Data(http.StatusOK, contentType, ret.LabelData)
+}
+
+// CancelReturn 取消退货运单
+func (h *ReturnHandler) CancelReturn(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	ret, err := h.returnService.Cancel(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": ret})
+}
+
+// AddCheckpoint 回传退货物流轨迹
+func (h *ReturnHandler) AddCheckpoint(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.CheckpointRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	checkpoint, err := h.returnService.AddCheckpoint(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": checkpoint})
+}
+
+// ListCheckpoints 获取退货物流轨迹
+func (h *ReturnHandler) ListCheckpoints(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	checkpoints, err := h.returnService.ListCheckpoints(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": checkpoints})
+}
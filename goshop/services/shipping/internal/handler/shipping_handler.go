@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/shipping/internal/service"
+)
+
+// ShippingHandler 处理物流相关的 HTTP 请求
+type ShippingHandler struct {
+	rateService     *service.RateService
+	shipmentService *service.ShipmentService
+}
+
+// NewShippingHandler 创建物流处理器
+func NewShippingHandler(rateService *service.RateService, shipmentService *service.ShipmentService) *ShippingHandler {
+	return &ShippingHandler{
+		rateService:     rateService,
+		shipmentService: shipmentService,
+	}
+}
+
+// RegisterRoutes 注册物流路由
+func (h *ShippingHandler) RegisterRoutes(api *gin.RouterGroup) {
+	shipping := api.Group("/shipping")
+	{
+		shipping.POST("/rates/quote", h.QuoteRates)
+		shipping.POST("/shipments", h.CreateShipment)
+		shipping.GET("/shipments", h.ListShipments)
+		shipping.GET("/shipments/:id", h.GetShipment)
+		shipping.POST("/shipments/:id/ship", h.ShipShipment)
+		shipping.GET("/shipments/:id/checkpoints", h.ListCheckpoints)
+		shipping.POST("/shipments/:id/checkpoints", h.AddCheckpoint)
+	}
+}
+
+// QuoteRates 运费询价，返回每种配送方式的运费和预计送达时间
+func (h *ShippingHandler) QuoteRates(c *gin.Context) {
+	var req service.QuoteRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	quotes, err := h.rateService.Quote(c.Request.Context(), &req, currentBuyer(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": quotes})
+}
+
+// CreateShipment 创建配送单
+func (h *ShippingHandler) CreateShipment(c *gin.Context) {
+	var req service.CreateShipmentRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	shipment, err := h.shipmentService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": shipment})
+}
+
+// ListShipments 按订单查询配送单
+func (h *ShippingHandler) ListShipments(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Query("order_id"), 10, 64)
+	if err != nil || orderID == 0 {
+		respondError(c, apperrors.NewBadRequest("缺少有效的 order_id", err))
+		return
+	}
+
+	shipments, err := h.shipmentService.ListByOrder(c.Request.Context(), uint(orderID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": shipments})
+}
+
+// GetShipment 获取配送单详情
+func (h *ShippingHandler) GetShipment(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	shipment, err := h.shipmentService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": shipment})
+}
+
+// ShipShipment 登记运单号并发货
+func (h *ShippingHandler) ShipShipment(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.ShipRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	shipment, err := h.shipmentService.Ship(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": shipment})
+}
+
+// AddCheckpoint 回传物流轨迹
+func (h *ShippingHandler) AddCheckpoint(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.CheckpointRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	checkpoint, err := h.shipmentService.AddCheckpoint(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": checkpoint})
+}
+
+// ListCheckpoints 获取物流轨迹
+func (h *ShippingHandler) ListCheckpoints(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	checkpoints, err := h.shipmentService.ListCheckpoints(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": checkpoints})
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BusinessCalendar 表示工作日历，用于计算发货和运输的工作日
+type BusinessCalendar struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name" gorm:"size:50;not null"`
+	Code      string         `json:"code" gorm:"size:20;uniqueIndex;not null"`
+	TimeZone  string         `json:"time_zone" gorm:"size:50;not null;default:'Asia/Shanghai'"` // IANA 时区名称
+	WorkDays  UintSlice      `json:"work_days" gorm:"type:jsonb"`                               // 每周的工作日，0=周日，1=周一，以此类推
+	IsDefault bool           `json:"is_default" gorm:"default:false"`
+	Holidays  []Holiday      `json:"holidays" gorm:"foreignKey:CalendarID"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Holiday 表示日历中的节假日或调休补班日
+type Holiday struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CalendarID uint      `json:"calendar_id" gorm:"uniqueIndex:idx_calendar_date;not null"`
+	Date       string    `json:"date" gorm:"size:10;uniqueIndex:idx_calendar_date;not null"` // 日期，格式为 2006-01-02
+	Name       string    `json:"name" gorm:"size:50"`                                        // 节日名称，如"国庆节"
+	IsWorkday  bool      `json:"is_workday" gorm:"default:false"`                            // 是否为调休补班日
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WarehouseSchedule 表示仓库的发货安排
+type WarehouseSchedule struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	WarehouseID  uint           `json:"warehouse_id" gorm:"uniqueIndex;not null"` // 库存服务中的仓库ID
+	CalendarID   uint           `json:"calendar_id" gorm:"index;not null"`
+	CutoffTime   string         `json:"cutoff_time" gorm:"size:5;not null;default:'16:00'"` // 当日发货截单时间，格式为 15:04
+	HandlingDays int            `json:"handling_days" gorm:"default:0"`                     // 备货所需工作日
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
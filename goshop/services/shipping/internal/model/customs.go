@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Incoterm 表示国际贸易术语，决定关税和运输风险由哪一方承担
+type Incoterm string
+
+const (
+	// IncotermDAP 目的地交货，关税由收件人承担
+	IncotermDAP Incoterm = "DAP"
+	// IncotermDDP 完税后交货，关税由发件人承担
+	IncotermDDP Incoterm = "DDP"
+	// IncotermEXW 工厂交货
+	IncotermEXW Incoterm = "EXW"
+	// IncotermFCA 货交承运人
+	IncotermFCA Incoterm = "FCA"
+	// IncotermCPT 运费付至
+	IncotermCPT Incoterm = "CPT"
+	// IncotermCIP 运费和保险费付至
+	IncotermCIP Incoterm = "CIP"
+)
+
+// CustomsContentsType 表示报关内件类型
+type CustomsContentsType string
+
+const (
+	// CustomsContentsMerchandise 商品
+	CustomsContentsMerchandise CustomsContentsType = "merchandise"
+	// CustomsContentsGift 礼品
+	CustomsContentsGift CustomsContentsType = "gift"
+	// CustomsContentsDocuments 文件
+	CustomsContentsDocuments CustomsContentsType = "documents"
+	// CustomsContentsSample 样品
+	CustomsContentsSample CustomsContentsType = "sample"
+	// CustomsContentsReturn 退货
+	CustomsContentsReturn CustomsContentsType = "return"
+)
+
+// CustomsDeclaration 表示配送单的报关信息
+type CustomsDeclaration struct {
+	ID                 uint                `json:"id" gorm:"primaryKey"`
+	ShipmentID         uint                `json:"shipment_id" gorm:"uniqueIndex;not null"`
+	InvoiceNumber      string              `json:"invoice_number" gorm:"size:50;uniqueIndex;not null"` // 商业发票号
+	Incoterm           Incoterm            `json:"incoterm" gorm:"size:3;not null;default:'DAP'"`
+	ContentsType       CustomsContentsType `json:"contents_type" gorm:"size:20;not null;default:'merchandise'"`
+	Currency           string              `json:"currency" gorm:"size:3;not null;default:'CNY'"`
+	TotalDeclaredValue float64             `json:"total_declared_value" gorm:"type:decimal(10,2);not null"` // 申报总价值
+	TotalNetWeight     float64             `json:"total_net_weight" gorm:"type:decimal(10,3);not null"`     // 净重总计（公斤）
+	SenderName         string              `json:"sender_name" gorm:"size:100;not null"`
+	SenderAddress      string              `json:"sender_address" gorm:"size:255;not null"`
+	SenderCountry      string              `json:"sender_country" gorm:"size:2;not null;default:'CN'"` // ISO 3166-1 二位国家代码
+	ReceiverName       string              `json:"receiver_name" gorm:"size:100;not null"`
+	ReceiverAddress    string              `json:"receiver_address" gorm:"size:255;not null"`
+	ReceiverCountry    string              `json:"receiver_country" gorm:"size:2;not null"`
+	ReceiverTaxID      *string             `json:"receiver_tax_id" gorm:"size:50"` // 收件人税号，部分国家清关需要
+	Items              []CustomsItem       `json:"items" gorm:"foreignKey:DeclarationID"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt      `json:"-" gorm:"index"`
+}
+
+// CustomsItem 表示报关明细项
+type CustomsItem struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	DeclarationID uint      `json:"declaration_id" gorm:"index;not null"`
+	SKUID         *uint     `json:"sku_id"`
+	Description   string    `json:"description" gorm:"size:255;not null"` // 英文品名
+	HSCode        string    `json:"hs_code" gorm:"size:10;not null"`      // 海关编码（6-10位）
+	OriginCountry string    `json:"origin_country" gorm:"size:2;not null"`
+	Quantity      int       `json:"quantity" gorm:"not null"`
+	UnitValue     float64   `json:"unit_value" gorm:"type:decimal(10,2);not null"` // 申报单价
+	NetWeight     float64   `json:"net_weight" gorm:"type:decimal(10,3);not null"` // 单件净重（公斤）
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FreeShippingRule 表示包邮规则
+//
+// 规则中配置的各项条件需同时满足才可包邮，未配置的条件不做限制。
+// 例如只配置 Threshold 为满额包邮；配置 ShippingZoneIDs 和 Threshold 为指定区域满额包邮；
+// 配置 MinMemberLevel 为会员包邮；配置 PromotionIDs 为参与指定促销活动包邮。
+type FreeShippingRule struct {
+	ID                  uint           `json:"id" gorm:"primaryKey"`
+	Name                string         `json:"name" gorm:"size:100;not null"`
+	Description         string         `json:"description" gorm:"size:255"`
+	ShippingZoneIDs     UintSlice      `json:"shipping_zone_ids" gorm:"type:jsonb"`        // 适用区域，为空表示所有区域
+	ShippingMethodIDs   UintSlice      `json:"shipping_method_ids" gorm:"type:jsonb"`      // 适用配送方式，为空表示所有配送方式
+	Threshold           *float64       `json:"threshold" gorm:"type:decimal(10,2)"`        // 包邮门槛金额，按排除分类后的商品金额计算
+	MinMemberLevel      *int           `json:"min_member_level"`                           // 最低会员等级
+	PromotionIDs        UintSlice      `json:"promotion_ids" gorm:"type:jsonb"`            // 授予包邮的促销活动ID，满足其一即可
+	ExcludedCategoryIDs UintSlice      `json:"excluded_category_ids" gorm:"type:jsonb"`    // 不参与包邮的商品分类ID
+	MaxShippingFee      *float64       `json:"max_shipping_fee" gorm:"type:decimal(10,2)"` // 最多减免的运费，为空表示全额减免
+	Priority            int            `json:"priority" gorm:"default:0"`                  // 优先级，越高越优先
+	IsActive            bool           `json:"is_active" gorm:"default:true"`
+	StartAt             *time.Time     `json:"start_at"` // 生效时间，null表示立即生效
+	EndAt               *time.Time     `json:"end_at"`   // 失效时间，null表示永久有效
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
+}
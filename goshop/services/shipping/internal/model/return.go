@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 退货面单类型
+const (
+	ReturnLabelTypeLabel  = "label"   // 预付费打印面单
+	ReturnLabelTypeQRCode = "qr_code" // 网点扫码寄件
+)
+
+// 退货运单状态
+const (
+	ReturnStatusLabelCreated = "label_created"
+	ReturnStatusInTransit    = "in_transit"
+	ReturnStatusReceived     = "received"
+	ReturnStatusException    = "exception"
+	ReturnStatusCancelled    = "cancelled"
+)
+
+// ReturnShipment 表示售后退货（RMA）的退货运单，与正向配送单分开追踪
+type ReturnShipment struct {
+	ID                  uint           `json:"id" gorm:"primaryKey"`
+	RMANumber           string         `json:"rma_number" gorm:"size:50;uniqueIndex;not null"` // 订单服务生成的售后单号
+	OrderID             uint           `json:"order_id" gorm:"index;not null"`
+	OrderNumber         string         `json:"order_number" gorm:"size:50;not null"`
+	UserID              uint           `json:"user_id" gorm:"index"`
+	ShipmentID          *uint          `json:"shipment_id" gorm:"index"` // 原配送单ID
+	WarehouseID         uint           `json:"warehouse_id" gorm:"index;not null"`
+	ShippingCarrierID   uint           `json:"shipping_carrier_id" gorm:"index;not null"`
+	ShippingCarrierName string         `json:"shipping_carrier_name" gorm:"size:50"`
+	LabelType           string         `json:"label_type" gorm:"size:20;not null"` // label, qr_code
+	TrackingNumber      string         `json:"tracking_number" gorm:"size:100;index"`
+	TrackingURL         *string        `json:"tracking_url" gorm:"size:255"`
+	LabelURL            *string        `json:"label_url" gorm:"size:255"`
+	LabelFormat         string         `json:"label_format" gorm:"size:10"`
+	LabelData           []byte         `json:"-" gorm:"type:bytea"`              // 承运商未托管面单时保存面单文件
+	DropOffCode         *string        `json:"drop_off_code" gorm:"size:50"`     // 网点寄件码，仅 qr_code
+	Status              string         `json:"status" gorm:"size:20;not null"`   // label_created, in_transit, received, exception, cancelled
+	SenderAddress       JSONMap        `json:"sender_address" gorm:"type:jsonb"` // 用户寄件地址
+	ReturnAddress       JSONMap        `json:"return_address" gorm:"type:jsonb"` // 仓库收件地址
+	ExpiresAt           time.Time      `json:"expires_at"`                       // 面单过期时间
+	InboundNotifiedAt   *time.Time     `json:"inbound_notified_at"`              // 已通知仓库退货在途的时间
+	ReceivedAt          *time.Time     `json:"received_at"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ReturnCheckpoint 表示退货运单的物流轨迹节点
+type ReturnCheckpoint struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	ReturnShipmentID uint      `json:"return_shipment_id" gorm:"index;not null"`
+	Status           string    `json:"status" gorm:"size:30;not null"` // in_transit, out_for_delivery, delivered, exception
+	Description      string    `json:"description" gorm:"size:255;not null"`
+	Location         string    `json:"location" gorm:"size:100"`
+	OccurredAt       time.Time `json:"occurred_at" gorm:"index;not null"`
+	CreatedAt        time.Time `json:"created_at"`
+}
@@ -44,17 +44,18 @@ func (a *UintSlice) Scan(value interface{}) error {
 
 // ShippingCarrier 表示物流承运商/快递公司
 type ShippingCarrier struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Name        string         `json:"name" gorm:"size:50;not null"`
-	Code        string         `json:"code" gorm:"size:20;uniqueIndex;not null"`
-	TrackingURL string         `json:"tracking_url" gorm:"size:255"` // 物流追踪URL模板，例如"https://example.com/track/{tracking_number}"
-	Logo        *string        `json:"logo" gorm:"size:255"`
-	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	SortOrder   int            `json:"sort_order" gorm:"default:0"`
-	APICode     *string        `json:"api_code" gorm:"size:50"` // 第三方物流API的代码
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	Name              string         `json:"name" gorm:"size:50;not null"`
+	Code              string         `json:"code" gorm:"size:20;uniqueIndex;not null"`
+	TrackingURL       string         `json:"tracking_url" gorm:"size:255"` // 物流追踪URL模板，例如"https://example.com/track/{tracking_number}"
+	Logo              *string        `json:"logo" gorm:"size:255"`
+	IsActive          bool           `json:"is_active" gorm:"default:true"`
+	SortOrder         int            `json:"sort_order" gorm:"default:0"`
+	APICode           *string        `json:"api_code" gorm:"size:50"`                                   // 第三方物流API的代码
+	VolumetricDivisor float64        `json:"volumetric_divisor" gorm:"type:decimal(10,2);default:6000"` // 体积重系数，体积重(公斤) = 长×宽×高(厘米) / 系数
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // JSONMap 是一个自定义类型，用于存储 JSON 对象
@@ -92,6 +93,7 @@ type ShippingZone struct {
 	Name        string         `json:"name" gorm:"size:50;not null"`
 	Description string         `json:"description" gorm:"size:255"`
 	RegionCodes []string       `json:"region_codes" gorm:"type:text[]"` // 地区代码列表，如省份/城市代码
+	ExtraDays   int            `json:"extra_days" gorm:"default:0"`     // 额外时效天数，如偏远地区需多加的运输天数
 	IsActive    bool           `json:"is_active" gorm:"default:true"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
@@ -118,27 +120,41 @@ type ShippingRate struct {
 	DeletedAt        gorm.DeletedAt            `json:"-" gorm:"index"`
 }
 
+// 配送单状态
+const (
+	ShipmentStatusPending        = "pending"
+	ShipmentStatusShipped        = "shipped"
+	ShipmentStatusOutForDelivery = "out_for_delivery"
+	ShipmentStatusDelivered      = "delivered"
+	ShipmentStatusException      = "exception"
+	ShipmentStatusFailed         = "failed"
+)
+
 // Shipment 表示物流配送信息
 type Shipment struct {
-	ID                  uint           `json:"id" gorm:"primaryKey"`
-	OrderID             uint           `json:"order_id" gorm:"index;not null"`
-	OrderNumber         string         `json:"order_number" gorm:"size:50;not null"`
-	UserID              uint           `json:"user_id" gorm:"index"`
-	ShippingMethodID    uint           `json:"shipping_method_id" gorm:"index"`
-	ShippingMethodName  string         `json:"shipping_method_name" gorm:"size:50"`
-	ShippingCarrierID   *uint          `json:"shipping_carrier_id" gorm:"index"`
-	ShippingCarrierName *string        `json:"shipping_carrier_name" gorm:"size:50"`
-	TrackingNumber      *string        `json:"tracking_number" gorm:"size:100"`
-	TrackingURL         *string        `json:"tracking_url" gorm:"size:255"`
-	ShippedAt           *time.Time     `json:"shipped_at"`
-	DeliveredAt         *time.Time     `json:"delivered_at"`
-	Status              string         `json:"status" gorm:"size:20;default:'pending'"`         // pending, shipped, delivered, failed
-	Address             JSONMap        `json:"address" gorm:"type:jsonb;not null"`              // 配送地址
-	Items               JSONMap        `json:"items" gorm:"type:jsonb;not null"`                // 配送商品信息
-	TrackingInfo        JSONMap        `json:"tracking_info" gorm:"type:jsonb"`                 // 物流追踪信息
-	ShippingFee         float64        `json:"shipping_fee" gorm:"type:decimal(10,2);not null"` // 运费
-	Note                *string        `json:"note" gorm:"size:255"`                            // 配送备注
-	CreatedAt           time.Time      `json:"created_at"`
-	UpdatedAt           time.Time      `json:"updated_at"`
-	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                  uint                `json:"id" gorm:"primaryKey"`
+	OrderID             uint                `json:"order_id" gorm:"index;not null"`
+	OrderNumber         string              `json:"order_number" gorm:"size:50;not null"`
+	UserID              uint                `json:"user_id" gorm:"index"`
+	ShippingMethodID    uint                `json:"shipping_method_id" gorm:"index"`
+	ShippingMethodName  string              `json:"shipping_method_name" gorm:"size:50"`
+	ShippingCarrierID   *uint               `json:"shipping_carrier_id" gorm:"index"`
+	ShippingCarrierName *string             `json:"shipping_carrier_name" gorm:"size:50"`
+	TrackingNumber      *string             `json:"tracking_number" gorm:"size:100"`
+	TrackingURL         *string             `json:"tracking_url" gorm:"size:255"`
+	WarehouseID         *uint               `json:"warehouse_id" gorm:"index"`                                  // 发货仓库ID
+	DestinationCountry  string              `json:"destination_country" gorm:"size:2;not null;default:'CN'"`    // 目的地国家，ISO 3166-1 二位代码
+	CustomsDeclaration  *CustomsDeclaration `json:"customs_declaration,omitempty" gorm:"foreignKey:ShipmentID"` // 报关信息，仅跨境配送
+	ShippedAt           *time.Time          `json:"shipped_at"`
+	DeliveredAt         *time.Time          `json:"delivered_at"`
+	EstimatedDeliveryAt *time.Time          `json:"estimated_delivery_at"`                           // 预计送达时间
+	Status              string              `json:"status" gorm:"size:20;default:'pending'"`         // pending, shipped, out_for_delivery, delivered, exception, failed
+	Address             JSONMap             `json:"address" gorm:"type:jsonb;not null"`              // 配送地址
+	Items               JSONMap             `json:"items" gorm:"type:jsonb;not null"`                // 配送商品信息
+	TrackingInfo        JSONMap             `json:"tracking_info" gorm:"type:jsonb"`                 // 物流追踪信息
+	ShippingFee         float64             `json:"shipping_fee" gorm:"type:decimal(10,2);not null"` // 运费
+	Note                *string             `json:"note" gorm:"size:255"`                            // 配送备注
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt      `json:"-" gorm:"index"`
 }
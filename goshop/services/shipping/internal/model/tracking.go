@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// ShipmentCheckpoint 表示配送单的物流轨迹节点
+type ShipmentCheckpoint struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ShipmentID  uint      `json:"shipment_id" gorm:"index;not null"`
+	Status      string    `json:"status" gorm:"size:30;not null"` // in_transit, out_for_delivery, delivered, exception
+	Description string    `json:"description" gorm:"size:255;not null"`
+	Location    string    `json:"location" gorm:"size:100"`
+	OccurredAt  time.Time `json:"occurred_at" gorm:"index;not null"` // 承运商记录的发生时间
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"gorm.io/gorm"
+)
+
+// CalendarRepository 定义工作日历仓库接口
+type CalendarRepository interface {
+	GetCalendarByID(ctx context.Context, id uint) (*model.BusinessCalendar, error)
+	GetDefaultCalendar(ctx context.Context) (*model.BusinessCalendar, error)
+	GetWarehouseSchedule(ctx context.Context, warehouseID uint) (*model.WarehouseSchedule, error)
+}
+
+// GormCalendarRepository 实现 CalendarRepository 接口的 GORM 仓库
+type GormCalendarRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarRepository 创建工作日历仓库实例
+func NewCalendarRepository(db *gorm.DB) CalendarRepository {
+	return &GormCalendarRepository{
+		db: db,
+	}
+}
+
+// GetCalendarByID 根据 ID 获取工作日历及其节假日
+func (r *GormCalendarRepository) GetCalendarByID(ctx context.Context, id uint) (*model.BusinessCalendar, error) {
+	var calendar model.BusinessCalendar
+	err := r.db.WithContext(ctx).Preload("Holidays").First(&calendar, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+// GetDefaultCalendar 获取默认工作日历
+func (r *GormCalendarRepository) GetDefaultCalendar(ctx context.Context) (*model.BusinessCalendar, error) {
+	var calendar model.BusinessCalendar
+	err := r.db.WithContext(ctx).Preload("Holidays").Where("is_default = ?", true).First(&calendar).Error
+	if err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+// GetWarehouseSchedule 获取仓库的发货安排
+func (r *GormCalendarRepository) GetWarehouseSchedule(ctx context.Context, warehouseID uint) (*model.WarehouseSchedule, error) {
+	var schedule model.WarehouseSchedule
+	err := r.db.WithContext(ctx).Where("warehouse_id = ?", warehouseID).First(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"gorm.io/gorm"
+)
+
+// CustomsRepository 定义报关信息仓库接口
+type CustomsRepository interface {
+	Save(ctx context.Context, declaration *model.CustomsDeclaration) error
+	GetByShipmentID(ctx context.Context, shipmentID uint) (*model.CustomsDeclaration, error)
+}
+
+// GormCustomsRepository 实现 CustomsRepository 接口的 GORM 仓库
+type GormCustomsRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomsRepository 创建报关信息仓库实例
+func NewCustomsRepository(db *gorm.DB) CustomsRepository {
+	return &GormCustomsRepository{
+		db: db,
+	}
+}
+
+// Save 保存报关信息，已有的报关明细会被整体替换
+func (r *GormCustomsRepository) Save(ctx context.Context, declaration *model.CustomsDeclaration) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if declaration.ID != 0 {
+			if err := tx.Where("declaration_id = ?", declaration.ID).Delete(&model.CustomsItem{}).Error; err != nil {
+				return err
+			}
+			for i := range declaration.Items {
+				declaration.Items[i].ID = 0
+			}
+		}
+		return tx.Save(declaration).Error
+	})
+}
+
+// GetByShipmentID 获取配送单的报关信息及明细
+func (r *GormCustomsRepository) GetByShipmentID(ctx context.Context, shipmentID uint) (*model.CustomsDeclaration, error) {
+	var declaration model.CustomsDeclaration
+	err := r.db.WithContext(ctx).Preload("Items").Where("shipment_id = ?", shipmentID).First(&declaration).Error
+	if err != nil {
+		return nil, err
+	}
+	return &declaration, nil
+}
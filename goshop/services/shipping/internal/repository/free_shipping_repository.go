@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"gorm.io/gorm"
+)
+
+// FreeShippingRuleRepository 定义包邮规则仓库接口
+type FreeShippingRuleRepository interface {
+	ListEffective(ctx context.Context, at time.Time) ([]*model.FreeShippingRule, error)
+}
+
+// GormFreeShippingRuleRepository 实现 FreeShippingRuleRepository 接口的 GORM 仓库
+type GormFreeShippingRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewFreeShippingRuleRepository 创建包邮规则仓库实例
+func NewFreeShippingRuleRepository(db *gorm.DB) FreeShippingRuleRepository {
+	return &GormFreeShippingRuleRepository{
+		db: db,
+	}
+}
+
+// ListEffective 获取指定时间生效的包邮规则，按优先级从高到低排序
+func (r *GormFreeShippingRuleRepository) ListEffective(ctx context.Context, at time.Time) ([]*model.FreeShippingRule, error) {
+	var rules []*model.FreeShippingRule
+	err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Where("start_at IS NULL OR start_at <= ?", at).
+		Where("end_at IS NULL OR end_at > ?", at).
+		Order("priority DESC, id ASC").
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"gorm.io/gorm"
+)
+
+// ReturnRepository 定义退货运单仓库接口
+type ReturnRepository interface {
+	Create(ctx context.Context, ret *model.ReturnShipment) error
+	GetByID(ctx context.Context, id uint) (*model.ReturnShipment, error)
+	GetByRMANumber(ctx context.Context, rmaNumber string) (*model.ReturnShipment, error)
+	ListByOrderID(ctx context.Context, orderID uint) ([]*model.ReturnShipment, error)
+	Update(ctx context.Context, ret *model.ReturnShipment) error
+	AddCheckpoint(ctx context.Context, checkpoint *model.ReturnCheckpoint) error
+	ListCheckpoints(ctx context.Context, returnID uint) ([]*model.ReturnCheckpoint, error)
+}
+
+// GormReturnRepository 实现 ReturnRepository 接口的 GORM 仓库
+type GormReturnRepository struct {
+	db *gorm.DB
+}
+
+// NewReturnRepository 创建退货运单仓库实例
+func NewReturnRepository(db *gorm.DB) ReturnRepository {
+	return &GormReturnRepository{
+		db: db,
+	}
+}
+
+// Create 创建退货运单
+func (r *GormReturnRepository) Create(ctx context.Context, ret *model.ReturnShipment) error {
+	return r.db.WithContext(ctx).Create(ret).Error
+}
+
+// GetByID 根据 ID 获取退货运单
+func (r *GormReturnRepository) GetByID(ctx context.Context, id uint) (*model.ReturnShipment, error) {
+	var ret model.ReturnShipment
+	err := r.db.WithContext(ctx).First(&ret, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// GetByRMANumber 根据售后单号获取退货运单
+func (r *GormReturnRepository) GetByRMANumber(ctx context.Context, rmaNumber string) (*model.ReturnShipment, error) {
+	var ret model.ReturnShipment
+	err := r.db.WithContext(ctx).Where("rma_number = ?", rmaNumber).First(&ret).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// ListByOrderID 获取订单的所有退货运单
+func (r *GormReturnRepository) ListByOrderID(ctx context.Context, orderID uint) ([]*model.ReturnShipment, error) {
+	var rets []*model.ReturnShipment
+	err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&rets).Error
+	if err != nil {
+		return nil, err
+	}
+	return rets, nil
+}
+
+// Update 更新退货运单
+func (r *GormReturnRepository) Update(ctx context.Context, ret *model.ReturnShipment) error {
+	return r.db.WithContext(ctx).Save(ret).Error
+}
+
+// AddCheckpoint 添加退货物流轨迹节点
+func (r *GormReturnRepository) AddCheckpoint(ctx context.Context, checkpoint *model.ReturnCheckpoint) error {
+	return r.db.WithContext(ctx).Create(checkpoint).Error
+}
+
+// ListCheckpoints 获取退货运单的物流轨迹，按发生时间倒序
+func (r *GormReturnRepository) ListCheckpoints(ctx context.Context, returnID uint) ([]*model.ReturnCheckpoint, error) {
+	var checkpoints []*model.ReturnCheckpoint
+	err := r.db.WithContext(ctx).
+		Where("return_shipment_id = ?", returnID).
+		Order("occurred_at DESC").
+		Find(&checkpoints).Error
+	if err != nil {
+		return nil, err
+	}
+	return checkpoints, nil
+}
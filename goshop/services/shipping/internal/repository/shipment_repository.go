@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"gorm.io/gorm"
+)
+
+// ShipmentRepository 定义配送单仓库接口
+type ShipmentRepository interface {
+	Create(ctx context.Context, shipment *model.Shipment) error
+	GetByID(ctx context.Context, id uint) (*model.Shipment, error)
+	ListByOrderID(ctx context.Context, orderID uint) ([]*model.Shipment, error)
+	Update(ctx context.Context, shipment *model.Shipment) error
+	AddCheckpoint(ctx context.Context, checkpoint *model.ShipmentCheckpoint) error
+	ListCheckpoints(ctx context.Context, shipmentID uint, limit int) ([]*model.ShipmentCheckpoint, error)
+}
+
+// GormShipmentRepository 实现 ShipmentRepository 接口的 GORM 仓库
+type GormShipmentRepository struct {
+	db *gorm.DB
+}
+
+// NewShipmentRepository 创建配送单仓库实例
+func NewShipmentRepository(db *gorm.DB) ShipmentRepository {
+	return &GormShipmentRepository{
+		db: db,
+	}
+}
+
+// Create 创建配送单
+func (r *GormShipmentRepository) Create(ctx context.Context, shipment *model.Shipment) error {
+	return r.db.WithContext(ctx).Create(shipment).Error
+}
+
+// GetByID 根据 ID 获取配送单
+func (r *GormShipmentRepository) GetByID(ctx context.Context, id uint) (*model.Shipment, error) {
+	var shipment model.Shipment
+	err := r.db.WithContext(ctx).Preload("CustomsDeclaration.Items").First(&shipment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// ListByOrderID 获取订单的所有配送单
+func (r *GormShipmentRepository) ListByOrderID(ctx context.Context, orderID uint) ([]*model.Shipment, error) {
+	var shipments []*model.Shipment
+	err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+// Update 更新配送单
+func (r *GormShipmentRepository) Update(ctx context.Context, shipment *model.Shipment) error {
+	return r.db.WithContext(ctx).Save(shipment).Error
+}
+
+// AddCheckpoint 添加物流轨迹节点
+func (r *GormShipmentRepository) AddCheckpoint(ctx context.Context, checkpoint *model.ShipmentCheckpoint) error {
+	return r.db.WithContext(ctx).Create(checkpoint).Error
+}
+
+// ListCheckpoints 获取配送单的物流轨迹，按发生时间倒序，limit 小于等于 0 时返回全部
+func (r *GormShipmentRepository) ListCheckpoints(ctx context.Context, shipmentID uint, limit int) ([]*model.ShipmentCheckpoint, error) {
+	var checkpoints []*model.ShipmentCheckpoint
+
+	query := r.db.WithContext(ctx).
+		Where("shipment_id = ?", shipmentID).
+		Order("occurred_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&checkpoints).Error; err != nil {
+		return nil, err
+	}
+	return checkpoints, nil
+}
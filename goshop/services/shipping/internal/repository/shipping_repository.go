@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"gorm.io/gorm"
+)
+
+// ShippingRepository 定义物流配置仓库接口
+type ShippingRepository interface {
+	GetMethodByID(ctx context.Context, id uint) (*model.ShippingMethod, error)
+	ListActiveMethods(ctx context.Context) ([]*model.ShippingMethod, error)
+	GetCarrierByID(ctx context.Context, id uint) (*model.ShippingCarrier, error)
+	ListActiveZones(ctx context.Context) ([]*model.ShippingZone, error)
+	ListActiveRates(ctx context.Context, methodID, zoneID uint) ([]*model.ShippingRate, error)
+
+	// 后台管理
+	ListMethods(ctx context.Context) ([]*model.ShippingMethod, error)
+	CreateMethod(ctx context.Context, method *model.ShippingMethod) error
+	UpdateMethod(ctx context.Context, method *model.ShippingMethod) error
+	DeleteMethod(ctx context.Context, id uint) error
+	ListCarriers(ctx context.Context) ([]*model.ShippingCarrier, error)
+	CreateCarrier(ctx context.Context, carrier *model.ShippingCarrier) error
+	UpdateCarrier(ctx context.Context, carrier *model.ShippingCarrier) error
+	DeleteCarrier(ctx context.Context, id uint) error
+	GetZoneByID(ctx context.Context, id uint) (*model.ShippingZone, error)
+	ListZones(ctx context.Context) ([]*model.ShippingZone, error)
+	CreateZone(ctx context.Context, zone *model.ShippingZone) error
+	UpdateZone(ctx context.Context, zone *model.ShippingZone) error
+	DeleteZone(ctx context.Context, id uint) error
+	GetRateByID(ctx context.Context, id uint) (*model.ShippingRate, error)
+	ListRates(ctx context.Context, methodID, zoneID uint) ([]*model.ShippingRate, error)
+	CreateRate(ctx context.Context, rate *model.ShippingRate) error
+	UpdateRate(ctx context.Context, rate *model.ShippingRate) error
+	DeleteRate(ctx context.Context, id uint) error
+}
+
+// GormShippingRepository 实现 ShippingRepository 接口的 GORM 仓库
+type GormShippingRepository struct {
+	db *gorm.DB
+}
+
+// NewShippingRepository 创建物流配置仓库实例
+func NewShippingRepository(db *gorm.DB) ShippingRepository {
+	return &GormShippingRepository{
+		db: db,
+	}
+}
+
+// GetMethodByID 根据 ID 获取配送方式
+func (r *GormShippingRepository) GetMethodByID(ctx context.Context, id uint) (*model.ShippingMethod, error) {
+	var method model.ShippingMethod
+	err := r.db.WithContext(ctx).First(&method, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &method, nil
+}
+
+// ListActiveMethods 获取所有启用的配送方式
+func (r *GormShippingRepository) ListActiveMethods(ctx context.Context) ([]*model.ShippingMethod, error) {
+	var methods []*model.ShippingMethod
+	err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("sort_order ASC, id ASC").
+		Find(&methods).Error
+	if err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+
+// GetCarrierByID 根据 ID 获取物流公司
+func (r *GormShippingRepository) GetCarrierByID(ctx context.Context, id uint) (*model.ShippingCarrier, error) {
+	var carrier model.ShippingCarrier
+	err := r.db.WithContext(ctx).First(&carrier, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &carrier, nil
+}
+
+// ListActiveZones 获取所有启用的运费区域
+func (r *GormShippingRepository) ListActiveZones(ctx context.Context) ([]*model.ShippingZone, error) {
+	var zones []*model.ShippingZone
+	err := r.db.WithContext(ctx).Where("is_active = ?", true).Find(&zones).Error
+	if err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// ListActiveRates 获取指定配送方式和区域下启用的运费规则
+func (r *GormShippingRepository) ListActiveRates(ctx context.Context, methodID, zoneID uint) ([]*model.ShippingRate, error) {
+	var rates []*model.ShippingRate
+	err := r.db.WithContext(ctx).
+		Where("shipping_method_id = ? AND shipping_zone_id = ? AND is_active = ?", methodID, zoneID, true).
+		Order("condition_min ASC").
+		Find(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// ListMethods 获取所有配送方式
+func (r *GormShippingRepository) ListMethods(ctx context.Context) ([]*model.ShippingMethod, error) {
+	var methods []*model.ShippingMethod
+	err := r.db.WithContext(ctx).Order("sort_order ASC, id ASC").Find(&methods).Error
+	if err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+
+// CreateMethod 创建配送方式
+func (r *GormShippingRepository) CreateMethod(ctx context.Context, method *model.ShippingMethod) error {
+	return r.db.WithContext(ctx).Create(method).Error
+}
+
+// UpdateMethod 更新配送方式
+func (r *GormShippingRepository) UpdateMethod(ctx context.Context, method *model.ShippingMethod) error {
+	return r.db.WithContext(ctx).Save(method).Error
+}
+
+// DeleteMethod 删除配送方式及其运费规则
+func (r *GormShippingRepository) DeleteMethod(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("shipping_method_id = ?", id).Delete(&model.ShippingRate{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.ShippingMethod{}, id).Error
+	})
+}
+
+// ListCarriers 获取所有物流公司
+func (r *GormShippingRepository) ListCarriers(ctx context.Context) ([]*model.ShippingCarrier, error) {
+	var carriers []*model.ShippingCarrier
+	err := r.db.WithContext(ctx).Order("sort_order ASC, id ASC").Find(&carriers).Error
+	if err != nil {
+		return nil, err
+	}
+	return carriers, nil
+}
+
+// CreateCarrier 创建物流公司
+func (r *GormShippingRepository) CreateCarrier(ctx context.Context, carrier *model.ShippingCarrier) error {
+	return r.db.WithContext(ctx).Create(carrier).Error
+}
+
+// UpdateCarrier 更新物流公司
+func (r *GormShippingRepository) UpdateCarrier(ctx context.Context, carrier *model.ShippingCarrier) error {
+	return r.db.WithContext(ctx).Save(carrier).Error
+}
+
+// DeleteCarrier 删除物流公司
+func (r *GormShippingRepository) DeleteCarrier(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.ShippingCarrier{}, id).Error
+}
+
+// GetZoneByID 根据 ID 获取运费区域
+func (r *GormShippingRepository) GetZoneByID(ctx context.Context, id uint) (*model.ShippingZone, error) {
+	var zone model.ShippingZone
+	err := r.db.WithContext(ctx).First(&zone, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// ListZones 获取所有运费区域
+func (r *GormShippingRepository) ListZones(ctx context.Context) ([]*model.ShippingZone, error) {
+	var zones []*model.ShippingZone
+	err := r.db.WithContext(ctx).Order("id ASC").Find(&zones).Error
+	if err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// CreateZone 创建运费区域
+func (r *GormShippingRepository) CreateZone(ctx context.Context, zone *model.ShippingZone) error {
+	return r.db.WithContext(ctx).Create(zone).Error
+}
+
+// UpdateZone 更新运费区域
+func (r *GormShippingRepository) UpdateZone(ctx context.Context, zone *model.ShippingZone) error {
+	return r.db.WithContext(ctx).Save(zone).Error
+}
+
+// DeleteZone 删除运费区域及其运费规则
+func (r *GormShippingRepository) DeleteZone(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("shipping_zone_id = ?", id).Delete(&model.ShippingRate{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.ShippingZone{}, id).Error
+	})
+}
+
+// GetRateByID 根据 ID 获取运费规则
+func (r *GormShippingRepository) GetRateByID(ctx context.Context, id uint) (*model.ShippingRate, error) {
+	var rate model.ShippingRate
+	err := r.db.WithContext(ctx).First(&rate, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// ListRates 获取运费规则，methodID 或 zoneID 为 0 时不按该条件过滤
+func (r *GormShippingRepository) ListRates(ctx context.Context, methodID, zoneID uint) ([]*model.ShippingRate, error) {
+	var rates []*model.ShippingRate
+
+	query := r.db.WithContext(ctx)
+	if methodID != 0 {
+		query = query.Where("shipping_method_id = ?", methodID)
+	}
+	if zoneID != 0 {
+		query = query.Where("shipping_zone_id = ?", zoneID)
+	}
+
+	err := query.Order("shipping_method_id ASC, shipping_zone_id ASC, condition_min ASC").Find(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// CreateRate 创建运费规则
+func (r *GormShippingRepository) CreateRate(ctx context.Context, rate *model.ShippingRate) error {
+	return r.db.WithContext(ctx).Create(rate).Error
+}
+
+// UpdateRate 更新运费规则
+func (r *GormShippingRepository) UpdateRate(ctx context.Context, rate *model.ShippingRate) error {
+	return r.db.WithContext(ctx).Save(rate).Error
+}
+
+// DeleteRate 删除运费规则
+func (r *GormShippingRepository) DeleteRate(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.ShippingRate{}, id).Error
+}
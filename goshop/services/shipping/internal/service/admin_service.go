@@ -0,0 +1,482 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"github.com/yourusername/goshop/services/shipping/internal/repository"
+	"gorm.io/gorm"
+)
+
+// MethodRequest 表示创建或更新配送方式的请求
+type MethodRequest struct {
+	Name          string  `json:"name" binding:"required,max=50"`
+	Code          string  `json:"code" binding:"required,max=20"`
+	Description   string  `json:"description" binding:"max=255"`
+	IsActive      *bool   `json:"is_active"`
+	SortOrder     int     `json:"sort_order"`
+	EstimatedDays string  `json:"estimated_days" binding:"max=50"`
+	Icon          *string `json:"icon" binding:"omitempty,max=255"`
+	CarrierIDs    []uint  `json:"carrier_ids"`
+}
+
+// CarrierRequest 表示创建或更新物流公司的请求
+type CarrierRequest struct {
+	Name              string  `json:"name" binding:"required,max=50"`
+	Code              string  `json:"code" binding:"required,max=20"`
+	TrackingURL       string  `json:"tracking_url" binding:"max=255"`
+	Logo              *string `json:"logo" binding:"omitempty,max=255"`
+	IsActive          *bool   `json:"is_active"`
+	SortOrder         int     `json:"sort_order"`
+	APICode           *string `json:"api_code" binding:"omitempty,max=50"`
+	VolumetricDivisor float64 `json:"volumetric_divisor" binding:"min=0"`
+}
+
+// ZoneRequest 表示创建或更新运费区域的请求
+type ZoneRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	RegionCodes []string `json:"region_codes" binding:"required,min=1"`
+	ExtraDays   int      `json:"extra_days" binding:"min=0"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// RateRequest 表示创建或更新运费规则的请求
+type RateRequest struct {
+	ShippingMethodID uint     `json:"shipping_method_id" binding:"required"`
+	ShippingZoneID   uint     `json:"shipping_zone_id" binding:"required"`
+	Name             string   `json:"name" binding:"required,max=50"`
+	ConditionType    string   `json:"condition_type" binding:"required,oneof=weight price quantity"`
+	ConditionMin     float64  `json:"condition_min" binding:"min=0"`
+	ConditionMax     *float64 `json:"condition_max"`
+	BaseRate         float64  `json:"base_rate" binding:"min=0"`
+	AdditionalRate   float64  `json:"additional_rate" binding:"min=0"`
+	AdditionalUnit   float64  `json:"additional_unit" binding:"min=0"`
+	IsFreeThreshold  bool     `json:"is_free_threshold"`
+	FreeThreshold    *float64 `json:"free_threshold"`
+	IsActive         *bool    `json:"is_active"`
+}
+
+// AdminService 负责配送方式、物流公司、运费区域和运费规则的后台管理
+type AdminService struct {
+	repo repository.ShippingRepository
+}
+
+// NewAdminService 创建物流后台管理服务
+func NewAdminService(repo repository.ShippingRepository) *AdminService {
+	return &AdminService{
+		repo: repo,
+	}
+}
+
+// ListMethods 获取所有配送方式
+func (s *AdminService) ListMethods(ctx context.Context) ([]*model.ShippingMethod, error) {
+	methods, err := s.repo.ListMethods(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取配送方式失败", err)
+	}
+	return methods, nil
+}
+
+// GetMethod 获取配送方式
+func (s *AdminService) GetMethod(ctx context.Context, id uint) (*model.ShippingMethod, error) {
+	method, err := s.repo.GetMethodByID(ctx, id)
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("配送方式 %d 不存在", id), "获取配送方式失败")
+	}
+	return method, nil
+}
+
+// CreateMethod 创建配送方式
+func (s *AdminService) CreateMethod(ctx context.Context, req *MethodRequest) (*model.ShippingMethod, error) {
+	method := &model.ShippingMethod{}
+	if err := s.applyMethod(ctx, method, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateMethod(ctx, method); err != nil {
+		return nil, apperrors.NewInternalServerError("创建配送方式失败", err)
+	}
+	return method, nil
+}
+
+// UpdateMethod 更新配送方式
+func (s *AdminService) UpdateMethod(ctx context.Context, id uint, req *MethodRequest) (*model.ShippingMethod, error) {
+	method, err := s.GetMethod(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyMethod(ctx, method, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateMethod(ctx, method); err != nil {
+		return nil, apperrors.NewInternalServerError("更新配送方式失败", err)
+	}
+	return method, nil
+}
+
+// DeleteMethod 删除配送方式，其下的运费规则一并删除
+func (s *AdminService) DeleteMethod(ctx context.Context, id uint) error {
+	if _, err := s.GetMethod(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteMethod(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除配送方式失败", err)
+	}
+	return nil
+}
+
+func (s *AdminService) applyMethod(ctx context.Context, method *model.ShippingMethod, req *MethodRequest) error {
+	methods, err := s.repo.ListMethods(ctx)
+	if err != nil {
+		return apperrors.NewInternalServerError("获取配送方式失败", err)
+	}
+	for _, m := range methods {
+		if m.ID != method.ID && strings.EqualFold(m.Code, req.Code) {
+			return apperrors.NewConflict(fmt.Sprintf("配送方式代码 %s 已存在", req.Code), nil)
+		}
+	}
+	for _, carrierID := range req.CarrierIDs {
+		if _, err := s.repo.GetCarrierByID(ctx, carrierID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NewBadRequest(fmt.Sprintf("物流公司 %d 不存在", carrierID), err)
+			}
+			return apperrors.NewInternalServerError("获取物流公司失败", err)
+		}
+	}
+
+	method.Name = req.Name
+	method.Code = req.Code
+	method.Description = req.Description
+	method.IsActive = boolOrDefault(req.IsActive, true)
+	method.SortOrder = req.SortOrder
+	method.EstimatedDays = req.EstimatedDays
+	method.Icon = req.Icon
+	method.CarrierIDs = req.CarrierIDs
+	return nil
+}
+
+// ListCarriers 获取所有物流公司
+func (s *AdminService) ListCarriers(ctx context.Context) ([]*model.ShippingCarrier, error) {
+	carriers, err := s.repo.ListCarriers(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取物流公司失败", err)
+	}
+	return carriers, nil
+}
+
+// GetCarrier 获取物流公司
+func (s *AdminService) GetCarrier(ctx context.Context, id uint) (*model.ShippingCarrier, error) {
+	carrier, err := s.repo.GetCarrierByID(ctx, id)
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("物流公司 %d 不存在", id), "获取物流公司失败")
+	}
+	return carrier, nil
+}
+
+// CreateCarrier 创建物流公司
+func (s *AdminService) CreateCarrier(ctx context.Context, req *CarrierRequest) (*model.ShippingCarrier, error) {
+	carrier := &model.ShippingCarrier{}
+	if err := s.applyCarrier(ctx, carrier, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateCarrier(ctx, carrier); err != nil {
+		return nil, apperrors.NewInternalServerError("创建物流公司失败", err)
+	}
+	return carrier, nil
+}
+
+// UpdateCarrier 更新物流公司
+func (s *AdminService) UpdateCarrier(ctx context.Context, id uint, req *CarrierRequest) (*model.ShippingCarrier, error) {
+	carrier, err := s.GetCarrier(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyCarrier(ctx, carrier, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateCarrier(ctx, carrier); err != nil {
+		return nil, apperrors.NewInternalServerError("更新物流公司失败", err)
+	}
+	return carrier, nil
+}
+
+// DeleteCarrier 删除物流公司，仍被配送方式关联时不允许删除
+func (s *AdminService) DeleteCarrier(ctx context.Context, id uint) error {
+	if _, err := s.GetCarrier(ctx, id); err != nil {
+		return err
+	}
+
+	methods, err := s.repo.ListMethods(ctx)
+	if err != nil {
+		return apperrors.NewInternalServerError("获取配送方式失败", err)
+	}
+	for _, m := range methods {
+		for _, carrierID := range m.CarrierIDs {
+			if carrierID == id {
+				return apperrors.NewConflict(fmt.Sprintf("物流公司仍被配送方式 %s 使用", m.Name), nil)
+			}
+		}
+	}
+
+	if err := s.repo.DeleteCarrier(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除物流公司失败", err)
+	}
+	return nil
+}
+
+func (s *AdminService) applyCarrier(ctx context.Context, carrier *model.ShippingCarrier, req *CarrierRequest) error {
+	carriers, err := s.repo.ListCarriers(ctx)
+	if err != nil {
+		return apperrors.NewInternalServerError("获取物流公司失败", err)
+	}
+	for _, c := range carriers {
+		if c.ID != carrier.ID && strings.EqualFold(c.Code, req.Code) {
+			return apperrors.NewConflict(fmt.Sprintf("物流公司代码 %s 已存在", req.Code), nil)
+		}
+	}
+
+	carrier.Name = req.Name
+	carrier.Code = req.Code
+	carrier.TrackingURL = req.TrackingURL
+	carrier.Logo = req.Logo
+	carrier.IsActive = boolOrDefault(req.IsActive, true)
+	carrier.SortOrder = req.SortOrder
+	carrier.APICode = req.APICode
+	carrier.VolumetricDivisor = req.VolumetricDivisor
+	if carrier.VolumetricDivisor == 0 {
+		carrier.VolumetricDivisor = defaultVolumetricDivisor
+	}
+	return nil
+}
+
+// ListZones 获取所有运费区域
+func (s *AdminService) ListZones(ctx context.Context) ([]*model.ShippingZone, error) {
+	zones, err := s.repo.ListZones(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取运费区域失败", err)
+	}
+	return zones, nil
+}
+
+// GetZone 获取运费区域
+func (s *AdminService) GetZone(ctx context.Context, id uint) (*model.ShippingZone, error) {
+	zone, err := s.repo.GetZoneByID(ctx, id)
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("运费区域 %d 不存在", id), "获取运费区域失败")
+	}
+	return zone, nil
+}
+
+// CreateZone 创建运费区域
+func (s *AdminService) CreateZone(ctx context.Context, req *ZoneRequest) (*model.ShippingZone, error) {
+	zone := &model.ShippingZone{}
+	if err := s.applyZone(ctx, zone, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateZone(ctx, zone); err != nil {
+		return nil, apperrors.NewInternalServerError("创建运费区域失败", err)
+	}
+	return zone, nil
+}
+
+// UpdateZone 更新运费区域
+func (s *AdminService) UpdateZone(ctx context.Context, id uint, req *ZoneRequest) (*model.ShippingZone, error) {
+	zone, err := s.GetZone(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyZone(ctx, zone, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateZone(ctx, zone); err != nil {
+		return nil, apperrors.NewInternalServerError("更新运费区域失败", err)
+	}
+	return zone, nil
+}
+
+// DeleteZone 删除运费区域，其下的运费规则一并删除
+func (s *AdminService) DeleteZone(ctx context.Context, id uint) error {
+	if _, err := s.GetZone(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteZone(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除运费区域失败", err)
+	}
+	return nil
+}
+
+// applyZone 校验并填充运费区域，同一地区代码不能出现在两个启用的区域中，否则询价匹配结果不确定
+func (s *AdminService) applyZone(ctx context.Context, zone *model.ShippingZone, req *ZoneRequest) error {
+	isActive := boolOrDefault(req.IsActive, true)
+	if isActive {
+		zones, err := s.repo.ListActiveZones(ctx)
+		if err != nil {
+			return apperrors.NewInternalServerError("获取运费区域失败", err)
+		}
+		for _, z := range zones {
+			if z.ID == zone.ID {
+				continue
+			}
+			for _, code := range req.RegionCodes {
+				for _, rc := range z.RegionCodes {
+					if rc == code {
+						return apperrors.NewConflict(fmt.Sprintf("地区代码 %s 已属于运费区域 %s", code, z.Name), nil)
+					}
+				}
+			}
+		}
+	}
+
+	zone.Name = req.Name
+	zone.Description = req.Description
+	zone.RegionCodes = req.RegionCodes
+	zone.ExtraDays = req.ExtraDays
+	zone.IsActive = isActive
+	return nil
+}
+
+// ListRates 获取运费规则，可按配送方式和区域过滤
+func (s *AdminService) ListRates(ctx context.Context, methodID, zoneID uint) ([]*model.ShippingRate, error) {
+	rates, err := s.repo.ListRates(ctx, methodID, zoneID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取运费规则失败", err)
+	}
+	return rates, nil
+}
+
+// GetRate 获取运费规则
+func (s *AdminService) GetRate(ctx context.Context, id uint) (*model.ShippingRate, error) {
+	rate, err := s.repo.GetRateByID(ctx, id)
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("运费规则 %d 不存在", id), "获取运费规则失败")
+	}
+	return rate, nil
+}
+
+// CreateRate 创建运费规则
+func (s *AdminService) CreateRate(ctx context.Context, req *RateRequest) (*model.ShippingRate, error) {
+	rate := &model.ShippingRate{}
+	if err := s.applyRate(ctx, rate, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRate(ctx, rate); err != nil {
+		return nil, apperrors.NewInternalServerError("创建运费规则失败", err)
+	}
+	return rate, nil
+}
+
+// UpdateRate 更新运费规则
+func (s *AdminService) UpdateRate(ctx context.Context, id uint, req *RateRequest) (*model.ShippingRate, error) {
+	rate, err := s.GetRate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRate(ctx, rate, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateRate(ctx, rate); err != nil {
+		return nil, apperrors.NewInternalServerError("更新运费规则失败", err)
+	}
+	return rate, nil
+}
+
+// DeleteRate 删除运费规则
+func (s *AdminService) DeleteRate(ctx context.Context, id uint) error {
+	if _, err := s.GetRate(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteRate(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除运费规则失败", err)
+	}
+	return nil
+}
+
+func (s *AdminService) applyRate(ctx context.Context, rate *model.ShippingRate, req *RateRequest) error {
+	if req.ConditionMax != nil && *req.ConditionMax <= req.ConditionMin {
+		return apperrors.NewBadRequest("condition_max 必须大于 condition_min", nil)
+	}
+	if req.IsFreeThreshold && req.FreeThreshold == nil {
+		return apperrors.NewBadRequest("启用包邮门槛时必须设置 free_threshold", nil)
+	}
+	if _, err := s.GetMethod(ctx, req.ShippingMethodID); err != nil {
+		return err
+	}
+	if _, err := s.GetZone(ctx, req.ShippingZoneID); err != nil {
+		return err
+	}
+
+	candidate := model.ShippingRate{
+		ID:               rate.ID,
+		ShippingMethodID: req.ShippingMethodID,
+		ShippingZoneID:   req.ShippingZoneID,
+		Name:             req.Name,
+		ConditionType:    model.ShippingRateConditionType(req.ConditionType),
+		ConditionMin:     req.ConditionMin,
+		ConditionMax:     req.ConditionMax,
+		BaseRate:         req.BaseRate,
+		AdditionalRate:   req.AdditionalRate,
+		AdditionalUnit:   req.AdditionalUnit,
+		IsFreeThreshold:  req.IsFreeThreshold,
+		FreeThreshold:    req.FreeThreshold,
+		IsActive:         boolOrDefault(req.IsActive, true),
+	}
+	if candidate.AdditionalUnit == 0 {
+		candidate.AdditionalUnit = 1
+	}
+
+	if candidate.IsActive {
+		existing, err := s.repo.ListActiveRates(ctx, req.ShippingMethodID, req.ShippingZoneID)
+		if err != nil {
+			return apperrors.NewInternalServerError("获取运费规则失败", err)
+		}
+		if err := checkRateBrackets(&candidate, existing); err != nil {
+			return err
+		}
+	}
+
+	candidate.CreatedAt = rate.CreatedAt
+	*rate = candidate
+	return nil
+}
+
+// checkRateBrackets 校验同一配送方式和区域下启用的运费规则：计算条件类型必须一致，条件区间 [min, max) 不能重叠
+func checkRateBrackets(rate *model.ShippingRate, existing []*model.ShippingRate) error {
+	for _, other := range existing {
+		if other.ID == rate.ID {
+			continue
+		}
+		if other.ConditionType != rate.ConditionType {
+			return apperrors.NewConflict(fmt.Sprintf("该配送方式和区域已按 %s 计费，不能混用 %s", other.ConditionType, rate.ConditionType), nil)
+		}
+		if bracketsOverlap(rate, other) {
+			return apperrors.NewConflict(fmt.Sprintf("条件区间与运费规则 %s 重叠", other.Name), nil)
+		}
+	}
+	return nil
+}
+
+// bracketsOverlap 判断两个左闭右开区间是否重叠，max 为空表示无上限
+func bracketsOverlap(a, b *model.ShippingRate) bool {
+	aBelowB := a.ConditionMax != nil && *a.ConditionMax <= b.ConditionMin
+	bBelowA := b.ConditionMax != nil && *b.ConditionMax <= a.ConditionMin
+	return !aBelowB && !bBelowA
+}
+
+// notFoundOr 将记录不存在转换为 404，其他错误转换为 500
+func notFoundOr(err error, notFoundMsg, internalMsg string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NewNotFound(notFoundMsg, err)
+	}
+	return apperrors.NewInternalServerError(internalMsg, err)
+}
+
+func boolOrDefault(v *bool, def bool) bool {
+	if v == nil {
+		return def
+	}
+	return *v
+}
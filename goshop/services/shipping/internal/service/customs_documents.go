@@ -0,0 +1,87 @@
+package service
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+)
+
+// customsDocumentData 是报关单据模板的数据
+type customsDocumentData struct {
+	Shipment    *model.Shipment
+	Declaration *model.CustomsDeclaration
+	IssuedAt    time.Time
+}
+
+var customsTemplateFuncs = template.FuncMap{
+	"lineTotal": func(item model.CustomsItem) float64 {
+		return roundAmount(item.UnitValue * float64(item.Quantity))
+	},
+	"lineWeight": func(item model.CustomsItem) float64 {
+		return item.NetWeight * float64(item.Quantity)
+	},
+}
+
+const customsItemsTable = `
+<table border="1" cellspacing="0" cellpadding="4" width="100%">
+  <tr><th>Description</th><th>HS Code</th><th>Origin</th><th>Qty</th><th>Net Weight (kg)</th><th>Value ({{.Declaration.Currency}})</th></tr>
+  {{range .Declaration.Items}}
+  <tr><td>{{.Description}}</td><td>{{.HSCode}}</td><td>{{.OriginCountry}}</td><td>{{.Quantity}}</td><td>{{printf "%.3f" (lineWeight .)}}</td><td>{{printf "%.2f" (lineTotal .)}}</td></tr>
+  {{end}}
+  <tr><th colspan="4">Total</th><th>{{printf "%.3f" .Declaration.TotalNetWeight}}</th><th>{{printf "%.2f" .Declaration.TotalDeclaredValue}}</th></tr>
+</table>`
+
+const postalFormTemplate = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head><body>
+<h2>{{.Title}} - CUSTOMS DECLARATION</h2>
+<p>Designated operator: China Post &nbsp; May be opened officially</p>
+<p>Category: <b>{{.Data.Declaration.ContentsType}}</b></p>
+<p>Sender: {{.Data.Declaration.SenderName}}, {{.Data.Declaration.SenderAddress}}, {{.Data.Declaration.SenderCountry}}</p>
+<p>Addressee: {{.Data.Declaration.ReceiverName}}, {{.Data.Declaration.ReceiverAddress}}, {{.Data.Declaration.ReceiverCountry}}</p>
+{{template "items" .Data}}
+<p>I certify that the particulars given in this customs declaration are correct and that this item does not contain any dangerous article prohibited by legislation or by postal or customs regulations.</p>
+<p>Date: {{.Data.IssuedAt.Format "2006-01-02"}} &nbsp; Sender's signature: ____________</p>
+</body></html>`
+
+const commercialInvoiceTemplate = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Commercial Invoice {{.Declaration.InvoiceNumber}}</title></head><body>
+<h2>COMMERCIAL INVOICE</h2>
+<p>Invoice No.: {{.Declaration.InvoiceNumber}} &nbsp; Date: {{.IssuedAt.Format "2006-01-02"}}</p>
+<p>Order No.: {{.Shipment.OrderNumber}}{{if .Shipment.TrackingNumber}} &nbsp; Tracking No.: {{.Shipment.TrackingNumber}}{{end}}</p>
+<p>Exporter: {{.Declaration.SenderName}}, {{.Declaration.SenderAddress}}, {{.Declaration.SenderCountry}}</p>
+<p>Consignee: {{.Declaration.ReceiverName}}, {{.Declaration.ReceiverAddress}}, {{.Declaration.ReceiverCountry}}{{if .Declaration.ReceiverTaxID}} &nbsp; Tax ID: {{.Declaration.ReceiverTaxID}}{{end}}</p>
+<p>Terms of Delivery (Incoterms 2020): <b>{{.Declaration.Incoterm}}</b> &nbsp; Reason for Export: {{.Declaration.ContentsType}}</p>
+{{template "items" .}}
+<p>I declare that the above information is true and correct to the best of my knowledge.</p>
+<p>Signature: ____________</p>
+</body></html>`
+
+// customsTemplate 渲染单个报关单据
+type customsTemplate func(w io.Writer, data customsDocumentData) error
+
+var customsTemplates = map[CustomsDocumentType]customsTemplate{
+	CustomsDocumentCN22:              postalForm("CN22"),
+	CustomsDocumentCN23:              postalForm("CN23"),
+	CustomsDocumentCommercialInvoice: commercialInvoice(),
+}
+
+func postalForm(title string) customsTemplate {
+	t := template.Must(template.New(title).Funcs(customsTemplateFuncs).Parse(postalFormTemplate))
+	template.Must(t.New("items").Parse(customsItemsTable))
+	return func(w io.Writer, data customsDocumentData) error {
+		return t.Execute(w, struct {
+			Title string
+			Data  customsDocumentData
+		}{title, data})
+	}
+}
+
+func commercialInvoice() customsTemplate {
+	t := template.Must(template.New("invoice").Funcs(customsTemplateFuncs).Parse(commercialInvoiceTemplate))
+	template.Must(t.New("items").Parse(customsItemsTable))
+	return func(w io.Writer, data customsDocumentData) error {
+		return t.Execute(w, data)
+	}
+}
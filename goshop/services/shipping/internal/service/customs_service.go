@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/idgen"
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"github.com/yourusername/goshop/services/shipping/internal/repository"
+	"gorm.io/gorm"
+)
+
+// 发货地所在国家，目的地为其他国家的配送单需要报关
+const domesticCountry = "CN"
+
+// CustomsDocumentType 表示报关单据类型
+type CustomsDocumentType string
+
+const (
+	// CustomsDocumentCN22 CN22 邮政报关单，适用于低价值小包
+	CustomsDocumentCN22 CustomsDocumentType = "cn22"
+	// CustomsDocumentCN23 CN23 邮政报关单
+	CustomsDocumentCN23 CustomsDocumentType = "cn23"
+	// CustomsDocumentCommercialInvoice 商业发票
+	CustomsDocumentCommercialInvoice CustomsDocumentType = "commercial-invoice"
+)
+
+// CN22 仅适用于净重不超过 2 公斤且价值不超过 300 SDR 的邮件
+const cn22MaxWeight = 2.0
+
+// cn22MaxValues 为按近似汇率折算的 300 SDR 上限
+var cn22MaxValues = map[string]float64{
+	"CNY": 2800,
+	"USD": 400,
+	"EUR": 370,
+	"GBP": 320,
+	"JPY": 58000,
+	"HKD": 3100,
+}
+
+var (
+	hsCodePattern  = regexp.MustCompile(`^\d{6,10}$`)
+	countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+var validIncoterms = map[model.Incoterm]bool{
+	model.IncotermDAP: true,
+	model.IncotermDDP: true,
+	model.IncotermEXW: true,
+	model.IncotermFCA: true,
+	model.IncotermCPT: true,
+	model.IncotermCIP: true,
+}
+
+// CustomsItemRequest 表示报关明细项
+type CustomsItemRequest struct {
+	SKUID         *uint   `json:"sku_id"`
+	Description   string  `json:"description" binding:"required,max=255"`
+	HSCode        string  `json:"hs_code" binding:"required"`
+	OriginCountry string  `json:"origin_country" binding:"required,len=2"`
+	Quantity      int     `json:"quantity" binding:"required,min=1"`
+	UnitValue     float64 `json:"unit_value" binding:"required,gt=0"`
+	NetWeight     float64 `json:"net_weight" binding:"required,gt=0"`
+}
+
+// CustomsRequest 表示报关信息请求
+type CustomsRequest struct {
+	Incoterm        model.Incoterm            `json:"incoterm" binding:"required"`
+	ContentsType    model.CustomsContentsType `json:"contents_type"`
+	Currency        string                    `json:"currency" binding:"omitempty,len=3"`
+	SenderName      string                    `json:"sender_name" binding:"required"`
+	SenderAddress   string                    `json:"sender_address" binding:"required"`
+	SenderCountry   string                    `json:"sender_country" binding:"omitempty,len=2"`
+	ReceiverName    string                    `json:"receiver_name" binding:"required"`
+	ReceiverAddress string                    `json:"receiver_address" binding:"required"`
+	ReceiverTaxID   *string                   `json:"receiver_tax_id"`
+	Items           []CustomsItemRequest      `json:"items" binding:"required,min=1,dive"`
+}
+
+// IsInternational 判断目的地国家是否需要报关
+func IsInternational(country string) bool {
+	return country != "" && !strings.EqualFold(country, domesticCountry)
+}
+
+// buildDeclaration 校验报关请求并生成报关信息
+func buildDeclaration(req *CustomsRequest, receiverCountry string) (*model.CustomsDeclaration, error) {
+	if !validIncoterms[req.Incoterm] {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("不支持的贸易术语 %s", req.Incoterm), nil)
+	}
+	if req.Incoterm == model.IncotermDDP && (req.ReceiverTaxID == nil || *req.ReceiverTaxID == "") {
+		// 完税交货由发件人代缴关税，清关需要收件人税号
+		return nil, apperrors.NewBadRequest("DDP 贸易术语需要提供收件人税号", nil)
+	}
+
+	invoiceNumber, err := idgen.NewNumber(idgen.PrefixInvoice)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成发票号失败", err)
+	}
+	declaration := &model.CustomsDeclaration{
+		InvoiceNumber:   invoiceNumber,
+		Incoterm:        req.Incoterm,
+		ContentsType:    req.ContentsType,
+		Currency:        strings.ToUpper(req.Currency),
+		SenderName:      req.SenderName,
+		SenderAddress:   req.SenderAddress,
+		SenderCountry:   strings.ToUpper(req.SenderCountry),
+		ReceiverName:    req.ReceiverName,
+		ReceiverAddress: req.ReceiverAddress,
+		ReceiverCountry: strings.ToUpper(receiverCountry),
+		ReceiverTaxID:   req.ReceiverTaxID,
+	}
+	if declaration.ContentsType == "" {
+		declaration.ContentsType = model.CustomsContentsMerchandise
+	}
+	if declaration.Currency == "" {
+		declaration.Currency = "CNY"
+	}
+	if declaration.SenderCountry == "" {
+		declaration.SenderCountry = domesticCountry
+	}
+	if !countryPattern.MatchString(declaration.ReceiverCountry) {
+		return nil, apperrors.NewBadRequest("无效的目的地国家代码", nil)
+	}
+
+	for i, item := range req.Items {
+		origin := strings.ToUpper(item.OriginCountry)
+		if !hsCodePattern.MatchString(item.HSCode) {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("第 %d 项的海关编码必须为 6-10 位数字", i+1), nil)
+		}
+		if !countryPattern.MatchString(origin) {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("第 %d 项的原产国代码无效", i+1), nil)
+		}
+
+		declaration.Items = append(declaration.Items, model.CustomsItem{
+			SKUID:         item.SKUID,
+			Description:   item.Description,
+			HSCode:        item.HSCode,
+			OriginCountry: origin,
+			Quantity:      item.Quantity,
+			UnitValue:     item.UnitValue,
+			NetWeight:     item.NetWeight,
+		})
+		declaration.TotalDeclaredValue += item.UnitValue * float64(item.Quantity)
+		declaration.TotalNetWeight += item.NetWeight * float64(item.Quantity)
+	}
+	declaration.TotalDeclaredValue = roundAmount(declaration.TotalDeclaredValue)
+
+	return declaration, nil
+}
+
+// PostalFormFor 返回报关信息适用的邮政报关单类型
+func PostalFormFor(declaration *model.CustomsDeclaration) CustomsDocumentType {
+	maxValue, ok := cn22MaxValues[declaration.Currency]
+	if ok && declaration.TotalNetWeight <= cn22MaxWeight && declaration.TotalDeclaredValue <= maxValue {
+		return CustomsDocumentCN22
+	}
+	return CustomsDocumentCN23
+}
+
+// CustomsService 负责跨境配送的报关信息和单据
+type CustomsService struct {
+	customsRepo  repository.CustomsRepository
+	shipmentRepo repository.ShipmentRepository
+}
+
+// NewCustomsService 创建报关服务
+func NewCustomsService(customsRepo repository.CustomsRepository, shipmentRepo repository.ShipmentRepository) *CustomsService {
+	return &CustomsService{
+		customsRepo:  customsRepo,
+		shipmentRepo: shipmentRepo,
+	}
+}
+
+// Declare 创建或更新配送单的报关信息，发货后不可修改
+func (s *CustomsService) Declare(ctx context.Context, shipmentID uint, req *CustomsRequest) (*model.CustomsDeclaration, error) {
+	shipment, err := s.getShipment(ctx, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !IsInternational(shipment.DestinationCountry) {
+		return nil, apperrors.NewBadRequest("境内配送无需报关", nil)
+	}
+	if shipment.Status != model.ShipmentStatusPending {
+		return nil, apperrors.NewConflict("配送单已发货，无法修改报关信息", nil)
+	}
+
+	declaration, err := buildDeclaration(req, shipment.DestinationCountry)
+	if err != nil {
+		return nil, err
+	}
+	declaration.ShipmentID = shipment.ID
+	if existing := shipment.CustomsDeclaration; existing != nil {
+		declaration.ID = existing.ID
+		declaration.InvoiceNumber = existing.InvoiceNumber
+		declaration.CreatedAt = existing.CreatedAt
+	}
+
+	if err := s.customsRepo.Save(ctx, declaration); err != nil {
+		return nil, apperrors.NewInternalServerError("保存报关信息失败", err)
+	}
+	return declaration, nil
+}
+
+// Get 获取配送单的报关信息
+func (s *CustomsService) Get(ctx context.Context, shipmentID uint) (*model.CustomsDeclaration, error) {
+	declaration, err := s.customsRepo.GetByShipmentID(ctx, shipmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("配送单尚未填写报关信息", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取报关信息失败", err)
+	}
+	return declaration, nil
+}
+
+// RenderDocument 生成报关单据的 HTML
+func (s *CustomsService) RenderDocument(ctx context.Context, shipmentID uint, docType CustomsDocumentType) ([]byte, error) {
+	shipment, err := s.getShipment(ctx, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+	declaration := shipment.CustomsDeclaration
+	if declaration == nil {
+		return nil, apperrors.NewNotFound("配送单尚未填写报关信息", nil)
+	}
+
+	render, ok := customsTemplates[docType]
+	if !ok {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("不支持的单据类型 %s", docType), nil)
+	}
+	if docType == CustomsDocumentCN22 && PostalFormFor(declaration) != CustomsDocumentCN22 {
+		return nil, apperrors.NewBadRequest("申报价值或重量超出 CN22 限制，请使用 CN23", nil)
+	}
+
+	var buf bytes.Buffer
+	err = render(&buf, customsDocumentData{
+		Shipment:    shipment,
+		Declaration: declaration,
+		IssuedAt:    time.Now(),
+	})
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成报关单据失败", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *CustomsService) getShipment(ctx context.Context, id uint) (*model.Shipment, error) {
+	shipment, err := s.shipmentRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrShipmentNotFound, fmt.Sprintf("配送单 %d 不存在", id), http.StatusNotFound, err)
+		}
+		return nil, apperrors.NewInternalServerError("获取配送单失败", err)
+	}
+	return shipment, nil
+}
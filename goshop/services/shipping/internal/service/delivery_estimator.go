@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"github.com/yourusername/goshop/services/shipping/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// 配送方式未配置或无法解析 EstimatedDays 时使用的默认运输天数
+	defaultMinTransitDays = 3
+	defaultMaxTransitDays = 7
+
+	// 仓库未配置发货安排时使用的默认截单时间
+	defaultCutoffTime = "16:00"
+
+	dateLayout = "2006-01-02"
+)
+
+var estimatedDaysPattern = regexp.MustCompile(`\d+`)
+
+// DeliveryEstimate 表示预计送达信息
+type DeliveryEstimate struct {
+	ShipDate   time.Time `json:"ship_date"`   // 预计发货日期
+	MinDays    int       `json:"min_days"`    // 最短运输工作日
+	MaxDays    int       `json:"max_days"`    // 最长运输工作日
+	EarliestAt time.Time `json:"earliest_at"` // 最早送达日期
+	LatestAt   time.Time `json:"latest_at"`   // 最晚送达日期，即店铺展示的"预计某日前送达"
+}
+
+// DeliveryEstimator 根据配送方式时效、仓库截单时间、工作日历和目的地区域计算预计送达日期
+type DeliveryEstimator struct {
+	calendarRepo repository.CalendarRepository
+	now          func() time.Time
+}
+
+// NewDeliveryEstimator 创建预计送达日期计算器
+func NewDeliveryEstimator(calendarRepo repository.CalendarRepository) *DeliveryEstimator {
+	return &DeliveryEstimator{
+		calendarRepo: calendarRepo,
+		now:          time.Now,
+	}
+}
+
+// Estimate 以当前时间为起点计算预计送达日期
+func (e *DeliveryEstimator) Estimate(ctx context.Context, method *model.ShippingMethod, zone *model.ShippingZone, warehouseID *uint) (*DeliveryEstimate, error) {
+	return e.EstimateFrom(ctx, e.now(), method, zone, warehouseID)
+}
+
+// EstimateFrom 以指定时间为起点计算预计送达日期
+func (e *DeliveryEstimator) EstimateFrom(ctx context.Context, from time.Time, method *model.ShippingMethod, zone *model.ShippingZone, warehouseID *uint) (*DeliveryEstimate, error) {
+	schedule, calendar, err := e.loadSchedule(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	wc := newWorkCalendar(calendar)
+	local := from.In(wc.loc)
+
+	// 截单时间之后或非工作日下单，顺延到下一个工作日发货
+	shipDay := truncateDay(local)
+	cutoff := parseCutoff(shipDay, schedule.CutoffTime)
+	if !wc.isBusinessDay(shipDay) || !local.Before(cutoff) {
+		shipDay = wc.nextBusinessDay(shipDay)
+	}
+	shipDay = wc.addBusinessDays(shipDay, schedule.HandlingDays)
+
+	minDays, maxDays := parseEstimatedDays(method.EstimatedDays)
+	if zone != nil && zone.ExtraDays > 0 {
+		minDays += zone.ExtraDays
+		maxDays += zone.ExtraDays
+	}
+
+	return &DeliveryEstimate{
+		ShipDate:   shipDay,
+		MinDays:    minDays,
+		MaxDays:    maxDays,
+		EarliestAt: wc.addBusinessDays(shipDay, minDays),
+		LatestAt:   wc.addBusinessDays(shipDay, maxDays),
+	}, nil
+}
+
+// loadSchedule 获取仓库发货安排和对应的工作日历，未配置时回退到默认值
+func (e *DeliveryEstimator) loadSchedule(ctx context.Context, warehouseID *uint) (*model.WarehouseSchedule, *model.BusinessCalendar, error) {
+	schedule := &model.WarehouseSchedule{CutoffTime: defaultCutoffTime}
+	if warehouseID != nil {
+		s, err := e.calendarRepo.GetWarehouseSchedule(ctx, *warehouseID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+		if s != nil {
+			schedule = s
+		}
+	}
+
+	var calendar *model.BusinessCalendar
+	var err error
+	if schedule.CalendarID != 0 {
+		calendar, err = e.calendarRepo.GetCalendarByID(ctx, schedule.CalendarID)
+	} else {
+		calendar, err = e.calendarRepo.GetDefaultCalendar(ctx)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	return schedule, calendar, nil
+}
+
+// parseEstimatedDays 解析配送方式的预计时效，如 "3-5天"、"次日达"、"2天"
+func parseEstimatedDays(s string) (int, int) {
+	if s == "次日达" {
+		return 1, 1
+	}
+	matches := estimatedDaysPattern.FindAllString(s, 2)
+	switch len(matches) {
+	case 1:
+		n, _ := strconv.Atoi(matches[0])
+		return n, n
+	case 2:
+		minDays, _ := strconv.Atoi(matches[0])
+		maxDays, _ := strconv.Atoi(matches[1])
+		if minDays > maxDays {
+			minDays, maxDays = maxDays, minDays
+		}
+		return minDays, maxDays
+	}
+	return defaultMinTransitDays, defaultMaxTransitDays
+}
+
+// parseCutoff 将 15:04 格式的截单时间转换为指定日期的时间点
+func parseCutoff(day time.Time, cutoff string) time.Time {
+	t, err := time.Parse("15:04", cutoff)
+	if err != nil {
+		t, _ = time.Parse("15:04", defaultCutoffTime)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// workCalendar 是工作日历的内存表示
+type workCalendar struct {
+	loc       *time.Location
+	workDays  map[time.Weekday]bool
+	overrides map[string]bool // 日期 -> 是否为工作日，用于节假日和调休补班
+}
+
+func newWorkCalendar(calendar *model.BusinessCalendar) *workCalendar {
+	wc := &workCalendar{
+		loc:       loadLocation("Asia/Shanghai"),
+		workDays:  make(map[time.Weekday]bool),
+		overrides: make(map[string]bool),
+	}
+
+	if calendar == nil || len(calendar.WorkDays) == 0 {
+		for d := time.Monday; d <= time.Friday; d++ {
+			wc.workDays[d] = true
+		}
+	} else {
+		for _, d := range calendar.WorkDays {
+			wc.workDays[time.Weekday(d%7)] = true
+		}
+	}
+
+	if calendar != nil {
+		if calendar.TimeZone != "" {
+			wc.loc = loadLocation(calendar.TimeZone)
+		}
+		for _, h := range calendar.Holidays {
+			wc.overrides[h.Date] = h.IsWorkday
+		}
+	}
+
+	return wc
+}
+
+func loadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		// 运行环境缺少时区数据时，回退到东八区
+		return time.FixedZone("CST", 8*3600)
+	}
+	return loc
+}
+
+func (c *workCalendar) isBusinessDay(t time.Time) bool {
+	if workday, ok := c.overrides[t.Format(dateLayout)]; ok {
+		return workday
+	}
+	return c.workDays[t.Weekday()]
+}
+
+// nextBusinessDay 返回指定日期之后的第一个工作日
+func (c *workCalendar) nextBusinessDay(t time.Time) time.Time {
+	// 最多向后查找一年，防止日历配置错误导致死循环
+	for i := 0; i < 366; i++ {
+		t = t.AddDate(0, 0, 1)
+		if c.isBusinessDay(t) {
+			return t
+		}
+	}
+	return t
+}
+
+// addBusinessDays 在指定日期上增加 n 个工作日
+func (c *workCalendar) addBusinessDays(t time.Time, n int) time.Time {
+	for i := 0; i < n; i++ {
+		t = c.nextBusinessDay(t)
+	}
+	return t
+}
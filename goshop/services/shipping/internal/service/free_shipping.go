@@ -0,0 +1,168 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+)
+
+// FreeShippingDecision 表示包邮判定结果，用于购物车展示"再买¥X即可包邮"等提示
+type FreeShippingDecision struct {
+	Eligible     bool     `json:"eligible"`
+	RuleID       *uint    `json:"rule_id,omitempty"`
+	RuleName     string   `json:"rule_name,omitempty"`
+	Discount     float64  `json:"discount"`                 // 减免的运费
+	AmountToFree *float64 `json:"amount_to_free,omitempty"` // 距离包邮门槛还差的金额
+	Message      string   `json:"message"`
+}
+
+// CouponWaiver 表示营销服务校验包邮券后返回的运费减免指令，由订单服务询价时原样带入
+type CouponWaiver struct {
+	CouponID        uint     `json:"coupon_id"`
+	Code            string   `json:"code"`
+	MethodIDs       []uint   `json:"method_ids,omitempty"`       // 适用的配送方式，为空表示不限
+	ZoneIDs         []uint   `json:"zone_ids,omitempty"`         // 适用的配送区域，为空表示不限
+	MaxFee          *float64 `json:"max_fee,omitempty"`          // 最多减免的运费，为空表示全免
+	ExcludedRegions []string `json:"excluded_regions,omitempty"` // 不参与包邮的省份名称或国家代码
+}
+
+// freeShippingInput 表示包邮规则判定所需的购物车信息
+type freeShippingInput struct {
+	MethodID     uint
+	ZoneID       uint
+	MemberLevel  int
+	PromotionIDs []uint
+	Items        []QuoteItem
+	Fee          float64
+}
+
+// evaluateFreeShipping 按优先级依次判定包邮规则，返回第一个满足的规则；
+// 都不满足时返回差额最小的门槛提示，没有适用规则时返回 nil
+func evaluateFreeShipping(rules []*model.FreeShippingRule, in freeShippingInput) *FreeShippingDecision {
+	if in.Fee <= 0 {
+		return nil
+	}
+
+	var closest *FreeShippingDecision
+	for _, rule := range rules {
+		if !appliesTo(rule, in) {
+			continue
+		}
+		if rule.MinMemberLevel != nil && in.MemberLevel < *rule.MinMemberLevel {
+			continue
+		}
+		if len(rule.PromotionIDs) > 0 && !intersects(rule.PromotionIDs, in.PromotionIDs) {
+			continue
+		}
+
+		if rule.Threshold != nil {
+			subtotal := eligibleSubtotal(in.Items, rule.ExcludedCategoryIDs)
+			if subtotal < *rule.Threshold {
+				gap := roundAmount(*rule.Threshold - subtotal)
+				if closest == nil || gap < *closest.AmountToFree {
+					closest = &FreeShippingDecision{
+						RuleID:       &rule.ID,
+						RuleName:     rule.Name,
+						AmountToFree: &gap,
+						Message:      fmt.Sprintf("再买¥%.2f即可享受包邮", gap),
+					}
+				}
+				continue
+			}
+		}
+
+		discount := in.Fee
+		if rule.MaxShippingFee != nil {
+			discount = math.Min(discount, *rule.MaxShippingFee)
+		}
+		return &FreeShippingDecision{
+			Eligible: true,
+			RuleID:   &rule.ID,
+			RuleName: rule.Name,
+			Discount: roundAmount(discount),
+			Message:  freeShippingMessage(rule, discount, in.Fee),
+		}
+	}
+
+	return closest
+}
+
+// appliesTo 判断规则是否适用于当前配送方式和区域
+func appliesTo(rule *model.FreeShippingRule, in freeShippingInput) bool {
+	if len(rule.ShippingZoneIDs) > 0 && !contains(rule.ShippingZoneIDs, in.ZoneID) {
+		return false
+	}
+	if len(rule.ShippingMethodIDs) > 0 && !contains(rule.ShippingMethodIDs, in.MethodID) {
+		return false
+	}
+	return true
+}
+
+// eligibleSubtotal 计算不属于排除分类的商品金额
+func eligibleSubtotal(items []QuoteItem, excluded model.UintSlice) float64 {
+	var subtotal float64
+	for _, item := range items {
+		if len(excluded) > 0 && intersects(excluded, item.CategoryIDs) {
+			continue
+		}
+		subtotal += item.Price * float64(item.Quantity)
+	}
+	return subtotal
+}
+
+// applyCouponWaiver 计算包邮券对某个配送方式的运费减免，不适用时返回 0
+func applyCouponWaiver(waiver *CouponWaiver, dest Destination, methodID, zoneID uint, fee float64) float64 {
+	if waiver == nil || fee <= 0 {
+		return 0
+	}
+	if len(waiver.MethodIDs) > 0 && !contains(waiver.MethodIDs, methodID) {
+		return 0
+	}
+	if len(waiver.ZoneIDs) > 0 && !contains(waiver.ZoneIDs, zoneID) {
+		return 0
+	}
+	for _, region := range waiver.ExcludedRegions {
+		if strings.EqualFold(region, dest.Country) || region == dest.Province {
+			return 0
+		}
+	}
+	if waiver.MaxFee != nil {
+		return roundAmount(math.Min(fee, *waiver.MaxFee))
+	}
+	return fee
+}
+
+func freeShippingMessage(rule *model.FreeShippingRule, discount, fee float64) string {
+	if discount < fee {
+		return fmt.Sprintf("%s，运费减免¥%.2f", rule.Name, discount)
+	}
+	switch {
+	case rule.Threshold != nil:
+		return fmt.Sprintf("已满¥%.2f，享受包邮", *rule.Threshold)
+	case rule.MinMemberLevel != nil:
+		return "会员专享包邮"
+	case len(rule.PromotionIDs) > 0:
+		return "促销活动包邮"
+	}
+	return rule.Name
+}
+
+func contains(ids model.UintSlice, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func intersects(ids model.UintSlice, others []uint) bool {
+	for _, id := range others {
+		if contains(ids, id) {
+			return true
+		}
+	}
+	return false
+}
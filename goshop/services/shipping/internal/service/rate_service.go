@@ -0,0 +1,532 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/shipping/internal/client"
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"github.com/yourusername/goshop/services/shipping/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// QuoteItem 表示询价的商品项
+type QuoteItem struct {
+	ProductID   uint    `json:"product_id"`
+	SKUID       uint    `json:"sku_id"`
+	Quantity    int     `json:"quantity" binding:"required,min=1"`
+	Price       float64 `json:"price" binding:"min=0"`  // 单价
+	Weight      float64 `json:"weight" binding:"min=0"` // 单件重量（公斤）
+	Length      float64 `json:"length" binding:"min=0"` // 单件长度（厘米）
+	Width       float64 `json:"width" binding:"min=0"`  // 单件宽度（厘米）
+	Height      float64 `json:"height" binding:"min=0"` // 单件高度（厘米）
+	CategoryIDs []uint  `json:"category_ids"`           // 商品所属分类，用于包邮规则的分类排除
+}
+
+// 物流公司未配置体积重系数时使用的默认值
+const defaultVolumetricDivisor = 6000
+
+// ProductCatalog 查询商品目录中的重量和尺寸
+type ProductCatalog interface {
+	GetDimensions(ctx context.Context, productID, skuID uint) (*client.ProductDimensions, error)
+}
+
+// PromotionEvaluator 计算购物车实际享受的促销活动
+type PromotionEvaluator interface {
+	AppliedPromotions(ctx context.Context, userID uint, items []client.CartItem) ([]uint, error)
+}
+
+// Buyer 表示询价的用户，由网关根据已验证的访问令牌设置，匿名询价时为零值
+type Buyer struct {
+	UserID      uint
+	MemberLevel int
+}
+
+// Destination 表示配送目的地
+type Destination struct {
+	Country  string `json:"country" binding:"omitempty,len=2"` // ISO 3166-1 二位代码，为空表示境内
+	Province string `json:"province" binding:"required_without=Country"`
+	City     string `json:"city"`
+	District string `json:"district"`
+}
+
+// QuoteRequest 表示运费询价请求
+type QuoteRequest struct {
+	Destination  Destination   `json:"destination" binding:"required"`
+	WarehouseID  *uint         `json:"warehouse_id"`
+	Items        []QuoteItem   `json:"items" binding:"required,min=1,dive"`
+	CouponWaiver *CouponWaiver `json:"coupon_waiver"` // 包邮券的运费减免指令，来自营销服务的优惠券校验结果
+}
+
+// RateQuote 表示某个配送方式的运费报价
+type RateQuote struct {
+	ShippingMethodID   uint                  `json:"shipping_method_id"`
+	ShippingMethodCode string                `json:"shipping_method_code"`
+	ShippingMethodName string                `json:"shipping_method_name"`
+	ShippingRateID     uint                  `json:"shipping_rate_id"`
+	ShippingZoneID     uint                  `json:"shipping_zone_id"`
+	ChargeableWeight   float64               `json:"chargeable_weight"` // 计费重量，取实际重量与体积重的较大值
+	OriginalFee        float64               `json:"original_fee"`      // 减免前的运费
+	Fee                float64               `json:"fee"`
+	IsFree             bool                  `json:"is_free"`
+	FreeShipping       *FreeShippingDecision `json:"free_shipping,omitempty"`
+	CouponDiscount     float64               `json:"coupon_discount"` // 包邮券减免的运费
+	EstimatedDelivery  *DeliveryEstimate     `json:"estimated_delivery"`
+}
+
+// RateService 负责运费计算
+type RateService struct {
+	repo       repository.ShippingRepository
+	ruleRepo   repository.FreeShippingRuleRepository
+	estimator  *DeliveryEstimator
+	catalog    ProductCatalog
+	promotions PromotionEvaluator
+	log        *logger.Logger
+}
+
+// NewRateService 创建运费计算服务，catalog 为空时只使用请求中的重量和尺寸，
+// promotions 为空时按促销活动包邮的规则不生效
+func NewRateService(
+	repo repository.ShippingRepository,
+	ruleRepo repository.FreeShippingRuleRepository,
+	estimator *DeliveryEstimator,
+	catalog ProductCatalog,
+	promotions PromotionEvaluator,
+	log *logger.Logger,
+) *RateService {
+	return &RateService{
+		repo:       repo,
+		ruleRepo:   ruleRepo,
+		estimator:  estimator,
+		catalog:    catalog,
+		promotions: promotions,
+		log:        log,
+	}
+}
+
+// Quote 计算所有可用配送方式的运费报价和预计送达时间。会员包邮按 buyer 的会员等级判定，
+// 促销包邮按营销服务计算的购物车促销活动判定，都不采用客户端提交的数据
+func (s *RateService) Quote(ctx context.Context, req *QuoteRequest, buyer *Buyer) ([]*RateQuote, error) {
+	zone, err := s.MatchZone(ctx, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := s.repo.ListActiveMethods(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取配送方式失败", err)
+	}
+
+	rules, err := s.ruleRepo.ListEffective(ctx, time.Now())
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取包邮规则失败", err)
+	}
+
+	s.fillDimensions(ctx, req.Items)
+	promotionIDs := s.appliedPromotions(ctx, rules, buyer, req.Items)
+	totals := summarize(req.Items)
+	quotes := make([]*RateQuote, 0, len(methods))
+	for _, method := range methods {
+		rates, err := s.repo.ListActiveRates(ctx, method.ID, zone.ID)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取运费规则失败", err)
+		}
+
+		divisor, err := s.volumetricDivisor(ctx, method)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取物流公司失败", err)
+		}
+		totals.ChargeableWeight = chargeableWeight(totals, divisor)
+
+		rate := matchRate(rates, totals)
+		if rate == nil {
+			continue
+		}
+
+		fee, isFree := calculateFee(rate, totals)
+		originalFee := fee
+		decision := evaluateFreeShipping(rules, freeShippingInput{
+			MethodID:     method.ID,
+			ZoneID:       zone.ID,
+			MemberLevel:  buyer.MemberLevel,
+			PromotionIDs: promotionIDs,
+			Items:        req.Items,
+			Fee:          fee,
+		})
+		if decision != nil && decision.Eligible {
+			fee = roundAmount(fee - decision.Discount)
+			isFree = fee == 0
+		}
+		// 包邮券在包邮规则之后生效，只减免剩余的运费
+		couponDiscount := applyCouponWaiver(req.CouponWaiver, req.Destination, method.ID, zone.ID, fee)
+		if couponDiscount > 0 {
+			fee = roundAmount(fee - couponDiscount)
+			isFree = fee == 0
+		}
+		estimate, err := s.estimator.Estimate(ctx, method, zone, req.WarehouseID)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("计算预计送达时间失败", err)
+		}
+
+		quotes = append(quotes, &RateQuote{
+			ShippingMethodID:   method.ID,
+			ShippingMethodCode: method.Code,
+			ShippingMethodName: method.Name,
+			ShippingRateID:     rate.ID,
+			ShippingZoneID:     zone.ID,
+			ChargeableWeight:   roundAmount(totals.ChargeableWeight),
+			OriginalFee:        originalFee,
+			Fee:                fee,
+			IsFree:             isFree,
+			FreeShipping:       decision,
+			CouponDiscount:     couponDiscount,
+			EstimatedDelivery:  estimate,
+		})
+	}
+
+	if len(quotes) == 0 {
+		return nil, apperrors.New(apperrors.ErrShippingUnavailable, "该地址暂无可用的配送方式", http.StatusUnprocessableEntity, nil)
+	}
+	return quotes, nil
+}
+
+// appliedPromotions 返回购物车享受的促销活动，只在有按促销活动包邮的规则时查询营销服务。
+// 营销服务不可用时按未享受促销处理，促销包邮不生效
+func (s *RateService) appliedPromotions(ctx context.Context, rules []*model.FreeShippingRule, buyer *Buyer, items []QuoteItem) []uint {
+	if s.promotions == nil {
+		return nil
+	}
+	needed := false
+	for _, rule := range rules {
+		if len(rule.PromotionIDs) > 0 {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	cart := make([]client.CartItem, len(items))
+	for i, item := range items {
+		cart[i] = client.CartItem{
+			ProductID:   item.ProductID,
+			SKUID:       item.SKUID,
+			CategoryIDs: item.CategoryIDs,
+			Quantity:    item.Quantity,
+			Price:       item.Price,
+		}
+	}
+	ids, err := s.promotions.AppliedPromotions(ctx, buyer.UserID, cart)
+	if err != nil {
+		s.log.Warn(ctx, "获取购物车促销活动失败", zap.Uint("user_id", buyer.UserID), zap.Error(err))
+		return nil
+	}
+	return ids
+}
+
+// SimulateRequest 表示运费模拟请求，用于后台排查某个假设购物车会命中哪条运费规则，
+// 会员等级和促销活动由后台假设
+type SimulateRequest struct {
+	QuoteRequest
+	ShippingMethodID uint   `json:"shipping_method_id"` // 为空时模拟所有启用的配送方式
+	MemberLevel      int    `json:"member_level"`       // 假设的会员等级
+	PromotionIDs     []uint `json:"promotion_ids"`      // 假设购物车已享受的促销活动
+}
+
+// RateCandidate 表示模拟中被检查的一条运费规则
+type RateCandidate struct {
+	Rate    *model.ShippingRate `json:"rate"`
+	Value   float64             `json:"value"` // 购物车在该规则计算条件下的取值
+	Matched bool                `json:"matched"`
+	Reason  string              `json:"reason"`
+}
+
+// MethodSimulation 表示某个配送方式的模拟结果
+type MethodSimulation struct {
+	ShippingMethodID   uint                  `json:"shipping_method_id"`
+	ShippingMethodCode string                `json:"shipping_method_code"`
+	ShippingMethodName string                `json:"shipping_method_name"`
+	VolumetricDivisor  float64               `json:"volumetric_divisor"`
+	ChargeableWeight   float64               `json:"chargeable_weight"`
+	Candidates         []RateCandidate       `json:"candidates"`
+	MatchedRateID      *uint                 `json:"matched_rate_id"`
+	OriginalFee        float64               `json:"original_fee"`
+	Fee                float64               `json:"fee"`
+	IsFree             bool                  `json:"is_free"`
+	FreeShipping       *FreeShippingDecision `json:"free_shipping,omitempty"`
+}
+
+// RateSimulation 表示运费模拟结果
+type RateSimulation struct {
+	Zone     *model.ShippingZone `json:"zone"`
+	Weight   float64             `json:"weight"` // 实际重量（公斤）
+	Volume   float64             `json:"volume"` // 体积（立方厘米）
+	Subtotal float64             `json:"subtotal"`
+	Quantity int                 `json:"quantity"`
+	Methods  []*MethodSimulation `json:"methods"`
+}
+
+// Simulate 模拟运费计算，列出每条运费规则是否命中及原因
+func (s *RateService) Simulate(ctx context.Context, req *SimulateRequest) (*RateSimulation, error) {
+	zone, err := s.MatchZone(ctx, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []*model.ShippingMethod
+	if req.ShippingMethodID != 0 {
+		method, err := s.repo.GetMethodByID(ctx, req.ShippingMethodID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, apperrors.NewBadRequest("配送方式不存在", err)
+			}
+			return nil, apperrors.NewInternalServerError("获取配送方式失败", err)
+		}
+		methods = append(methods, method)
+	} else {
+		methods, err = s.repo.ListActiveMethods(ctx)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取配送方式失败", err)
+		}
+	}
+
+	rules, err := s.ruleRepo.ListEffective(ctx, time.Now())
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取包邮规则失败", err)
+	}
+
+	s.fillDimensions(ctx, req.Items)
+	totals := summarize(req.Items)
+	sim := &RateSimulation{
+		Zone:     zone,
+		Weight:   roundAmount(totals.Weight),
+		Volume:   roundAmount(totals.Volume),
+		Subtotal: roundAmount(totals.Subtotal),
+		Quantity: totals.Quantity,
+	}
+
+	for _, method := range methods {
+		rates, err := s.repo.ListActiveRates(ctx, method.ID, zone.ID)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取运费规则失败", err)
+		}
+		divisor, err := s.volumetricDivisor(ctx, method)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取物流公司失败", err)
+		}
+		totals.ChargeableWeight = chargeableWeight(totals, divisor)
+
+		ms := &MethodSimulation{
+			ShippingMethodID:   method.ID,
+			ShippingMethodCode: method.Code,
+			ShippingMethodName: method.Name,
+			VolumetricDivisor:  divisor,
+			ChargeableWeight:   roundAmount(totals.ChargeableWeight),
+			Candidates:         make([]RateCandidate, 0, len(rates)),
+		}
+
+		var matched *model.ShippingRate
+		for _, rate := range rates {
+			candidate := explainRate(rate, totals)
+			if candidate.Matched && matched != nil {
+				candidate.Matched = false
+				candidate.Reason = "已命中排序更靠前的规则"
+			}
+			if candidate.Matched {
+				matched = rate
+			}
+			ms.Candidates = append(ms.Candidates, candidate)
+		}
+
+		if matched != nil {
+			fee, isFree := calculateFee(matched, totals)
+			ms.MatchedRateID = &matched.ID
+			ms.OriginalFee = fee
+			ms.FreeShipping = evaluateFreeShipping(rules, freeShippingInput{
+				MethodID:     method.ID,
+				ZoneID:       zone.ID,
+				MemberLevel:  req.MemberLevel,
+				PromotionIDs: req.PromotionIDs,
+				Items:        req.Items,
+				Fee:          fee,
+			})
+			if ms.FreeShipping != nil && ms.FreeShipping.Eligible {
+				fee = roundAmount(fee - ms.FreeShipping.Discount)
+				isFree = fee == 0
+			}
+			ms.Fee = fee
+			ms.IsFree = isFree
+		}
+		sim.Methods = append(sim.Methods, ms)
+	}
+
+	return sim, nil
+}
+
+// explainRate 判断购物车是否落在运费规则的条件区间内，并给出原因
+func explainRate(rate *model.ShippingRate, t cartTotals) RateCandidate {
+	v := conditionValue(rate, t)
+	c := RateCandidate{Rate: rate, Value: roundAmount(v)}
+	switch {
+	case v < rate.ConditionMin:
+		c.Reason = fmt.Sprintf("%s %.2f 小于下限 %.2f", rate.ConditionType, v, rate.ConditionMin)
+	case rate.ConditionMax != nil && v >= *rate.ConditionMax:
+		c.Reason = fmt.Sprintf("%s %.2f 不小于上限 %.2f", rate.ConditionType, v, *rate.ConditionMax)
+	default:
+		c.Matched = true
+		c.Reason = "命中"
+	}
+	return c
+}
+
+// MatchZone 根据目的地匹配运费区域，区、市、省、国家依次从具体到宽泛匹配
+func (s *RateService) MatchZone(ctx context.Context, dest Destination) (*model.ShippingZone, error) {
+	zones, err := s.repo.ListActiveZones(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取运费区域失败", err)
+	}
+
+	for _, code := range []string{dest.District, dest.City, dest.Province, dest.Country} {
+		if code == "" {
+			continue
+		}
+		for _, zone := range zones {
+			for _, rc := range zone.RegionCodes {
+				if rc == code {
+					return zone, nil
+				}
+			}
+		}
+	}
+
+	return nil, apperrors.New(apperrors.ErrShippingUnavailable, "该地址不在配送范围内", http.StatusUnprocessableEntity, nil)
+}
+
+// fillDimensions 对未提供重量或尺寸的商品项，从商品目录中补全
+func (s *RateService) fillDimensions(ctx context.Context, items []QuoteItem) {
+	if s.catalog == nil {
+		return
+	}
+	for i := range items {
+		item := &items[i]
+		if item.ProductID == 0 || (item.Weight > 0 && item.Length > 0 && item.Width > 0 && item.Height > 0) {
+			continue
+		}
+		dims, err := s.catalog.GetDimensions(ctx, item.ProductID, item.SKUID)
+		if err != nil {
+			// 商品服务不可用时按请求中的数据计算，不阻断询价
+			continue
+		}
+		if item.Weight == 0 {
+			item.Weight = dims.Weight
+		}
+		if item.Length == 0 || item.Width == 0 || item.Height == 0 {
+			item.Length, item.Width, item.Height = dims.Length, dims.Width, dims.Height
+		}
+	}
+}
+
+// volumetricDivisor 返回配送方式所关联物流公司的体积重系数
+func (s *RateService) volumetricDivisor(ctx context.Context, method *model.ShippingMethod) (float64, error) {
+	for _, carrierID := range method.CarrierIDs {
+		carrier, err := s.repo.GetCarrierByID(ctx, carrierID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return 0, err
+		}
+		if carrier.IsActive && carrier.VolumetricDivisor > 0 {
+			return carrier.VolumetricDivisor, nil
+		}
+	}
+	return defaultVolumetricDivisor, nil
+}
+
+// cartTotals 汇总询价商品的重量、体积、金额和数量
+type cartTotals struct {
+	Weight           float64 // 实际重量（公斤）
+	Volume           float64 // 体积（立方厘米）
+	ChargeableWeight float64 // 计费重量（公斤）
+	Subtotal         float64
+	Quantity         int
+}
+
+func summarize(items []QuoteItem) cartTotals {
+	var t cartTotals
+	for _, item := range items {
+		qty := float64(item.Quantity)
+		t.Weight += item.Weight * qty
+		t.Volume += item.Length * item.Width * item.Height * qty
+		t.Subtotal += item.Price * qty
+		t.Quantity += item.Quantity
+	}
+	t.ChargeableWeight = t.Weight
+	return t
+}
+
+// chargeableWeight 计算计费重量，体积重大于实际重量时按体积重计费
+func chargeableWeight(t cartTotals, divisor float64) float64 {
+	if divisor <= 0 {
+		return t.Weight
+	}
+	return math.Max(t.Weight, t.Volume/divisor)
+}
+
+// conditionValue 返回运费规则计算条件对应的值
+func conditionValue(rate *model.ShippingRate, t cartTotals) float64 {
+	switch rate.ConditionType {
+	case model.ShippingRateConditionTypeWeight:
+		return t.ChargeableWeight
+	case model.ShippingRateConditionTypePrice:
+		return t.Subtotal
+	case model.ShippingRateConditionTypeQuantity:
+		return float64(t.Quantity)
+	}
+	return 0
+}
+
+// matchRate 找到条件区间 [ConditionMin, ConditionMax) 包含当前值的运费规则
+func matchRate(rates []*model.ShippingRate, t cartTotals) *model.ShippingRate {
+	for _, rate := range rates {
+		v := conditionValue(rate, t)
+		if v < rate.ConditionMin {
+			continue
+		}
+		if rate.ConditionMax != nil && v >= *rate.ConditionMax {
+			continue
+		}
+		return rate
+	}
+	return nil
+}
+
+// calculateFee 按基础运费加续费计算运费，满足包邮门槛时免运费
+func calculateFee(rate *model.ShippingRate, t cartTotals) (float64, bool) {
+	if rate.IsFreeThreshold && rate.FreeThreshold != nil && t.Subtotal >= *rate.FreeThreshold {
+		return 0, true
+	}
+
+	fee := rate.BaseRate
+	v := conditionValue(rate, t)
+	if rate.AdditionalRate > 0 && v > rate.ConditionMin {
+		unit := rate.AdditionalUnit
+		if unit <= 0 {
+			unit = 1
+		}
+		fee += math.Ceil((v-rate.ConditionMin)/unit) * rate.AdditionalRate
+	}
+	return roundAmount(fee), false
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
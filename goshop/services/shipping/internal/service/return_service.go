@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/shipping/internal/carrier"
+	"github.com/yourusername/goshop/services/shipping/internal/event"
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"github.com/yourusername/goshop/services/shipping/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 退货面单有效期，过期后用户需要重新申请
+const returnLabelValidity = 14 * 24 * time.Hour
+
+// CreateReturnRequest 表示生成退货面单的请求，由订单服务的售后流程调用
+type CreateReturnRequest struct {
+	RMANumber         string           `json:"rma_number" binding:"required,max=50"`
+	OrderID           uint             `json:"order_id" binding:"required"`
+	OrderNumber       string           `json:"order_number" binding:"required"`
+	UserID            uint             `json:"user_id"`
+	ShipmentID        *uint            `json:"shipment_id"`
+	WarehouseID       uint             `json:"warehouse_id" binding:"required"`
+	ShippingCarrierID uint             `json:"shipping_carrier_id" binding:"required"`
+	LabelType         string           `json:"label_type" binding:"required,oneof=label qr_code"`
+	SenderAddress     model.JSONMap    `json:"sender_address" binding:"required"`
+	ReturnAddress     model.JSONMap    `json:"return_address" binding:"required"`
+	Parcels           []carrier.Parcel `json:"parcels"`
+}
+
+// ReturnService 负责售后退货面单和退货物流追踪
+type ReturnService struct {
+	returnRepo   repository.ReturnRepository
+	shippingRepo repository.ShippingRepository
+	carriers     *carrier.Registry
+	publisher    event.Publisher
+	log          *logger.Logger
+}
+
+// NewReturnService 创建退货服务
+func NewReturnService(
+	returnRepo repository.ReturnRepository,
+	shippingRepo repository.ShippingRepository,
+	carriers *carrier.Registry,
+	publisher event.Publisher,
+	log *logger.Logger,
+) *ReturnService {
+	return &ReturnService{
+		returnRepo:   returnRepo,
+		shippingRepo: shippingRepo,
+		carriers:     carriers,
+		publisher:    publisher,
+		log:          log,
+	}
+}
+
+// Create 通过承运商生成预付费退货面单或网点寄件二维码，同一售后单重复调用时返回已有的退货运单
+func (s *ReturnService) Create(ctx context.Context, req *CreateReturnRequest) (*model.ReturnShipment, error) {
+	existing, err := s.returnRepo.GetByRMANumber(ctx, req.RMANumber)
+	if err == nil && existing.Status != model.ReturnStatusCancelled && time.Now().Before(existing.ExpiresAt) {
+		return existing, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NewInternalServerError("获取退货运单失败", err)
+	}
+
+	c, err := s.shippingRepo.GetCarrierByID(ctx, req.ShippingCarrierID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewBadRequest("物流公司不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取物流公司失败", err)
+	}
+	if !c.IsActive {
+		return nil, apperrors.NewBadRequest("物流公司已停用", nil)
+	}
+
+	provider, err := s.carriers.ForCarrier(c)
+	if err != nil {
+		if errors.Is(err, carrier.ErrProviderNotFound) {
+			return nil, apperrors.NewBadRequest("该物流公司不支持生成退货面单", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取物流公司适配器失败", err)
+	}
+
+	result, err := provider.CreateShipment(ctx, &carrier.ShipmentRequest{
+		Reference: req.RMANumber,
+		Sender:    carrierAddress(req.SenderAddress, domesticCountry),
+		Recipient: carrierAddress(req.ReturnAddress, domesticCountry),
+		Parcels:   req.Parcels,
+	})
+	if err != nil {
+		return nil, apperrors.New(apperrors.ErrShippingUnavailable, "承运商下单失败", http.StatusBadGateway, err)
+	}
+
+	format := carrier.LabelFormatPDF
+	if req.LabelType == model.ReturnLabelTypeQRCode {
+		format = carrier.LabelFormatQR
+	}
+	label, err := provider.BuyLabel(ctx, result.TrackingNumber, format)
+	if err != nil {
+		// 面单获取失败时取消承运商运单，避免产生无人使用的预付费运单
+		if cancelErr := provider.Cancel(ctx, result.TrackingNumber); cancelErr != nil {
+			s.log.Warn(ctx, "Failed to cancel carrier shipment", zap.String("tracking_number", result.TrackingNumber), zap.Error(cancelErr))
+		}
+		return nil, apperrors.New(apperrors.ErrShippingUnavailable, "获取退货面单失败", http.StatusBadGateway, err)
+	}
+
+	ret := existing
+	if ret == nil {
+		ret = &model.ReturnShipment{}
+	}
+	ret.RMANumber = req.RMANumber
+	ret.OrderID = req.OrderID
+	ret.OrderNumber = req.OrderNumber
+	ret.UserID = req.UserID
+	ret.ShipmentID = req.ShipmentID
+	ret.WarehouseID = req.WarehouseID
+	ret.ShippingCarrierID = c.ID
+	ret.ShippingCarrierName = c.Name
+	ret.LabelType = req.LabelType
+	ret.TrackingNumber = result.TrackingNumber
+	ret.TrackingURL = nil
+	if c.TrackingURL != "" {
+		url := strings.ReplaceAll(c.TrackingURL, "{tracking_number}", result.TrackingNumber)
+		ret.TrackingURL = &url
+	}
+	ret.LabelFormat = string(label.Format)
+	ret.LabelURL = nil
+	ret.LabelData = nil
+	if label.URL != "" {
+		ret.LabelURL = &label.URL
+	} else {
+		ret.LabelData = label.Data
+	}
+	ret.DropOffCode = nil
+	if label.DropOffCode != "" {
+		ret.DropOffCode = &label.DropOffCode
+	}
+	ret.Status = model.ReturnStatusLabelCreated
+	ret.SenderAddress = req.SenderAddress
+	ret.ReturnAddress = req.ReturnAddress
+	ret.ExpiresAt = time.Now().Add(returnLabelValidity)
+	ret.InboundNotifiedAt = nil
+	ret.ReceivedAt = nil
+
+	if ret.ID == 0 {
+		err = s.returnRepo.Create(ctx, ret)
+	} else {
+		err = s.returnRepo.Update(ctx, ret)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("保存退货运单失败", err)
+	}
+
+	s.publish(ctx, event.ReturnLabelCreated, ret, "")
+	return ret, nil
+}
+
+// Get 获取退货运单
+func (s *ReturnService) Get(ctx context.Context, id uint) (*model.ReturnShipment, error) {
+	ret, err := s.returnRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("退货运单 %d 不存在", id), "获取退货运单失败")
+	}
+	return ret, nil
+}
+
+// ListByOrder 获取订单的所有退货运单
+func (s *ReturnService) ListByOrder(ctx context.Context, orderID uint) ([]*model.ReturnShipment, error) {
+	rets, err := s.returnRepo.ListByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取退货运单失败", err)
+	}
+	return rets, nil
+}
+
+// Cancel 取消尚未寄出的退货运单
+func (s *ReturnService) Cancel(ctx context.Context, id uint) (*model.ReturnShipment, error) {
+	ret, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ret.Status != model.ReturnStatusLabelCreated {
+		return nil, apperrors.NewConflict("退货已寄出，无法取消", nil)
+	}
+
+	c, err := s.shippingRepo.GetCarrierByID(ctx, ret.ShippingCarrierID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取物流公司失败", err)
+	}
+	provider, err := s.carriers.ForCarrier(c)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取物流公司适配器失败", err)
+	}
+	if err := provider.Cancel(ctx, ret.TrackingNumber); err != nil {
+		return nil, apperrors.New(apperrors.ErrShippingUnavailable, "承运商取消运单失败", http.StatusBadGateway, err)
+	}
+
+	ret.Status = model.ReturnStatusCancelled
+	if err := s.returnRepo.Update(ctx, ret); err != nil {
+		return nil, apperrors.NewInternalServerError("更新退货运单失败", err)
+	}
+	return ret, nil
+}
+
+// AddCheckpoint 记录退货物流轨迹，首次揽收后通知仓库退货在途，签收后通知仓库已到货
+func (s *ReturnService) AddCheckpoint(ctx context.Context, id uint, req *CheckpointRequest) (*model.ReturnCheckpoint, error) {
+	ret, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ret.Status == model.ReturnStatusCancelled {
+		return nil, apperrors.NewConflict("退货运单已取消", nil)
+	}
+
+	occurredAt := time.Now()
+	if req.OccurredAt != nil {
+		occurredAt = *req.OccurredAt
+	}
+	checkpoint := &model.ReturnCheckpoint{
+		ReturnShipmentID: ret.ID,
+		Status:           req.Status,
+		Description:      req.Description,
+		Location:         req.Location,
+		OccurredAt:       occurredAt,
+	}
+	if err := s.returnRepo.AddCheckpoint(ctx, checkpoint); err != nil {
+		return nil, apperrors.NewInternalServerError("保存物流轨迹失败", err)
+	}
+
+	// 已签收的退货不再回退状态，迟到的轨迹只做记录
+	if ret.Status == model.ReturnStatusReceived {
+		return checkpoint, nil
+	}
+
+	var events []string
+	var reason string
+	switch req.Status {
+	case model.ShipmentStatusDelivered:
+		ret.Status = model.ReturnStatusReceived
+		ret.ReceivedAt = &occurredAt
+		events = append(events, event.ReturnReceived)
+	case model.ShipmentStatusException:
+		ret.Status = model.ReturnStatusException
+		events = append(events, event.ReturnException)
+		reason = req.Description
+	default:
+		ret.Status = model.ReturnStatusInTransit
+	}
+	if ret.InboundNotifiedAt == nil && ret.Status != model.ReturnStatusException {
+		now := time.Now()
+		ret.InboundNotifiedAt = &now
+		events = append([]string{event.ReturnInbound}, events...)
+	}
+
+	if err := s.returnRepo.Update(ctx, ret); err != nil {
+		return nil, apperrors.NewInternalServerError("更新退货运单失败", err)
+	}
+
+	for _, eventType := range events {
+		s.publish(ctx, eventType, ret, reason)
+	}
+	return checkpoint, nil
+}
+
+// ListCheckpoints 获取退货运单的物流轨迹
+func (s *ReturnService) ListCheckpoints(ctx context.Context, id uint) ([]*model.ReturnCheckpoint, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	checkpoints, err := s.returnRepo.ListCheckpoints(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取物流轨迹失败", err)
+	}
+	return checkpoints, nil
+}
+
+// publish 发布退货运单事件，发布失败只记录日志
+func (s *ReturnService) publish(ctx context.Context, eventType string, ret *model.ReturnShipment, reason string) {
+	data := event.ReturnEvent{
+		ReturnID:       ret.ID,
+		RMANumber:      ret.RMANumber,
+		OrderID:        ret.OrderID,
+		OrderNumber:    ret.OrderNumber,
+		UserID:         ret.UserID,
+		WarehouseID:    ret.WarehouseID,
+		Status:         ret.Status,
+		CarrierName:    ret.ShippingCarrierName,
+		TrackingNumber: ret.TrackingNumber,
+		TrackingURL:    ret.TrackingURL,
+		LabelURL:       ret.LabelURL,
+		DropOffCode:    ret.DropOffCode,
+		ExpiresAt:      ret.ExpiresAt,
+		Reason:         reason,
+	}
+
+	checkpoints, err := s.returnRepo.ListCheckpoints(ctx, ret.ID)
+	if err != nil {
+		s.log.Warn(ctx, "Failed to load checkpoints for return event", zap.Uint("return_id", ret.ID), zap.Error(err))
+	}
+	for i, cp := range checkpoints {
+		if i == eventCheckpointLimit {
+			break
+		}
+		data.Checkpoints = append(data.Checkpoints, event.CheckpointSummary{
+			Status:      cp.Status,
+			Description: cp.Description,
+			Location:    cp.Location,
+			OccurredAt:  cp.OccurredAt,
+		})
+	}
+
+	if err := s.publisher.Publish(ctx, eventType, data); err != nil {
+		s.log.Error(ctx, "Failed to publish return event",
+			zap.String("event", eventType),
+			zap.Uint("return_id", ret.ID),
+			zap.Error(err),
+		)
+	}
+}
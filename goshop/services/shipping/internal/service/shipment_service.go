@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/shipping/internal/carrier"
+	"github.com/yourusername/goshop/services/shipping/internal/event"
+	"github.com/yourusername/goshop/services/shipping/internal/model"
+	"github.com/yourusername/goshop/services/shipping/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CreateShipmentRequest 表示创建配送单请求，由订单服务在订单支付后调用
+type CreateShipmentRequest struct {
+	OrderID          uint            `json:"order_id" binding:"required"`
+	OrderNumber      string          `json:"order_number" binding:"required"`
+	UserID           uint            `json:"user_id"`
+	ShippingMethodID uint            `json:"shipping_method_id" binding:"required"`
+	WarehouseID      *uint           `json:"warehouse_id"`
+	Destination      Destination     `json:"destination" binding:"required"`
+	Address          model.JSONMap   `json:"address" binding:"required"`
+	Items            model.JSONMap   `json:"items" binding:"required"`
+	ShippingFee      float64         `json:"shipping_fee" binding:"min=0"`
+	Note             *string         `json:"note"`
+	Customs          *CustomsRequest `json:"customs"` // 跨境配送必填
+}
+
+// ShipRequest 表示配送单发货请求，物流公司已接入适配器时可不填运单号，由承运商下单生成
+type ShipRequest struct {
+	ShippingCarrierID uint   `json:"shipping_carrier_id" binding:"required"`
+	TrackingNumber    string `json:"tracking_number" binding:"max=100"`
+	ServiceCode       string `json:"service_code" binding:"max=50"` // 承运商产品代码
+}
+
+// CheckpointRequest 表示物流轨迹回传请求，来自承运商推送或人工录入
+type CheckpointRequest struct {
+	Status      string     `json:"status" binding:"required,oneof=in_transit out_for_delivery delivered exception"`
+	Description string     `json:"description" binding:"required,max=255"`
+	Location    string     `json:"location" binding:"max=100"`
+	OccurredAt  *time.Time `json:"occurred_at"`
+}
+
+// 事件中携带的物流轨迹节点数量
+const eventCheckpointLimit = 5
+
+// ShipmentService 负责配送单管理
+type ShipmentService struct {
+	shipmentRepo repository.ShipmentRepository
+	rateService  *RateService
+	estimator    *DeliveryEstimator
+	shippingRepo repository.ShippingRepository
+	carriers     *carrier.Registry
+	publisher    event.Publisher
+	log          *logger.Logger
+}
+
+// NewShipmentService 创建配送单服务
+func NewShipmentService(
+	shipmentRepo repository.ShipmentRepository,
+	shippingRepo repository.ShippingRepository,
+	rateService *RateService,
+	estimator *DeliveryEstimator,
+	carriers *carrier.Registry,
+	publisher event.Publisher,
+	log *logger.Logger,
+) *ShipmentService {
+	return &ShipmentService{
+		shipmentRepo: shipmentRepo,
+		shippingRepo: shippingRepo,
+		rateService:  rateService,
+		estimator:    estimator,
+		carriers:     carriers,
+		publisher:    publisher,
+		log:          log,
+	}
+}
+
+// Create 创建配送单并计算预计送达时间，跨境配送单会同时保存报关信息
+func (s *ShipmentService) Create(ctx context.Context, req *CreateShipmentRequest) (*model.Shipment, error) {
+	method, err := s.shippingRepo.GetMethodByID(ctx, req.ShippingMethodID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewBadRequest("配送方式不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取配送方式失败", err)
+	}
+
+	var declaration *model.CustomsDeclaration
+	country := strings.ToUpper(req.Destination.Country)
+	if country == "" {
+		country = domesticCountry
+	}
+	if IsInternational(country) {
+		if req.Customs == nil {
+			return nil, apperrors.NewBadRequest("跨境配送必须填写报关信息", nil)
+		}
+		declaration, err = buildDeclaration(req.Customs, country)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	zone, err := s.rateService.MatchZone(ctx, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate, err := s.estimator.Estimate(ctx, method, zone, req.WarehouseID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("计算预计送达时间失败", err)
+	}
+
+	shipment := &model.Shipment{
+		OrderID:             req.OrderID,
+		OrderNumber:         req.OrderNumber,
+		UserID:              req.UserID,
+		ShippingMethodID:    method.ID,
+		ShippingMethodName:  method.Name,
+		WarehouseID:         req.WarehouseID,
+		DestinationCountry:  country,
+		CustomsDeclaration:  declaration,
+		EstimatedDeliveryAt: &estimate.LatestAt,
+		Status:              model.ShipmentStatusPending,
+		Address:             req.Address,
+		Items:               req.Items,
+		ShippingFee:         req.ShippingFee,
+		Note:                req.Note,
+	}
+
+	if err := s.shipmentRepo.Create(ctx, shipment); err != nil {
+		return nil, apperrors.NewInternalServerError("创建配送单失败", err)
+	}
+
+	s.publish(ctx, event.ShipmentCreated, shipment, "")
+	return shipment, nil
+}
+
+// Ship 登记承运商和运单号，配送单进入已发货状态
+func (s *ShipmentService) Ship(ctx context.Context, id uint, req *ShipRequest) (*model.Shipment, error) {
+	shipment, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if shipment.Status != model.ShipmentStatusPending {
+		return nil, apperrors.NewConflict("配送单已发货", nil)
+	}
+	if shipment.CustomsDeclaration == nil && IsInternational(shipment.DestinationCountry) {
+		return nil, apperrors.NewBadRequest("跨境配送单发货前必须填写报关信息", nil)
+	}
+
+	carrier, err := s.shippingRepo.GetCarrierByID(ctx, req.ShippingCarrierID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewBadRequest("物流公司不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取物流公司失败", err)
+	}
+
+	trackingNumber := req.TrackingNumber
+	if trackingNumber == "" {
+		result, err := s.createCarrierShipment(ctx, carrier, shipment, req.ServiceCode)
+		if err != nil {
+			return nil, err
+		}
+		trackingNumber = result.TrackingNumber
+		if shipment.TrackingInfo == nil {
+			shipment.TrackingInfo = model.JSONMap{}
+		}
+		shipment.TrackingInfo["provider"] = *carrier.APICode
+		shipment.TrackingInfo["provider_shipment_id"] = result.ProviderShipmentID
+	}
+
+	now := time.Now()
+	shipment.ShippingCarrierID = &carrier.ID
+	shipment.ShippingCarrierName = &carrier.Name
+	shipment.TrackingNumber = &trackingNumber
+	if carrier.TrackingURL != "" {
+		url := strings.ReplaceAll(carrier.TrackingURL, "{tracking_number}", trackingNumber)
+		shipment.TrackingURL = &url
+	}
+	shipment.Status = model.ShipmentStatusShipped
+	shipment.ShippedAt = &now
+
+	if err := s.shipmentRepo.Update(ctx, shipment); err != nil {
+		return nil, apperrors.NewInternalServerError("更新配送单失败", err)
+	}
+
+	s.publish(ctx, event.ShipmentShipped, shipment, "")
+	return shipment, nil
+}
+
+// AddCheckpoint 记录物流轨迹，并在派送、签收和异常时更新配送单状态并通知用户
+func (s *ShipmentService) AddCheckpoint(ctx context.Context, id uint, req *CheckpointRequest) (*model.ShipmentCheckpoint, error) {
+	shipment, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if shipment.Status == model.ShipmentStatusPending {
+		return nil, apperrors.NewConflict("配送单尚未发货", nil)
+	}
+
+	occurredAt := time.Now()
+	if req.OccurredAt != nil {
+		occurredAt = *req.OccurredAt
+	}
+	checkpoint := &model.ShipmentCheckpoint{
+		ShipmentID:  shipment.ID,
+		Status:      req.Status,
+		Description: req.Description,
+		Location:    req.Location,
+		OccurredAt:  occurredAt,
+	}
+	if err := s.shipmentRepo.AddCheckpoint(ctx, checkpoint); err != nil {
+		return nil, apperrors.NewInternalServerError("保存物流轨迹失败", err)
+	}
+
+	var eventType string
+	switch req.Status {
+	case model.ShipmentStatusOutForDelivery:
+		eventType = event.ShipmentOutForDelivery
+	case model.ShipmentStatusDelivered:
+		eventType = event.ShipmentDelivered
+		shipment.DeliveredAt = &occurredAt
+	case model.ShipmentStatusException:
+		eventType = event.ShipmentException
+	default:
+		return checkpoint, nil
+	}
+
+	// 已签收的配送单不再回退状态，迟到的轨迹只做记录
+	if shipment.Status == model.ShipmentStatusDelivered {
+		return checkpoint, nil
+	}
+
+	shipment.Status = req.Status
+	if err := s.shipmentRepo.Update(ctx, shipment); err != nil {
+		return nil, apperrors.NewInternalServerError("更新配送单失败", err)
+	}
+
+	s.publish(ctx, eventType, shipment, exceptionReason(req))
+	return checkpoint, nil
+}
+
+// createCarrierShipment 通过承运商适配器下单获取运单号
+func (s *ShipmentService) createCarrierShipment(ctx context.Context, c *model.ShippingCarrier, shipment *model.Shipment, serviceCode string) (*carrier.ShipmentResult, error) {
+	provider, err := s.carriers.ForCarrier(c)
+	if err != nil {
+		if errors.Is(err, carrier.ErrProviderNotFound) {
+			return nil, apperrors.NewBadRequest("该物流公司未接入自动下单，请填写运单号", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取物流公司适配器失败", err)
+	}
+
+	result, err := provider.CreateShipment(ctx, &carrier.ShipmentRequest{
+		Reference:   shipment.OrderNumber,
+		ServiceCode: serviceCode,
+		Recipient:   carrierAddress(shipment.Address, shipment.DestinationCountry),
+	})
+	if err != nil {
+		return nil, apperrors.New(apperrors.ErrShippingUnavailable, "承运商下单失败", http.StatusBadGateway, err)
+	}
+	return result, nil
+}
+
+// carrierAddress 将 JSON 地址转换为承运商地址，地址中未填国家时使用 defaultCountry
+func carrierAddress(addr model.JSONMap, defaultCountry string) carrier.Address {
+	field := func(key string) string {
+		v, _ := addr[key].(string)
+		return v
+	}
+	country := strings.ToUpper(field("country"))
+	if country == "" {
+		country = defaultCountry
+	}
+	return carrier.Address{
+		Name:       field("name"),
+		Phone:      field("phone"),
+		Country:    country,
+		Province:   field("province"),
+		City:       field("city"),
+		District:   field("district"),
+		Street:     field("street"),
+		PostalCode: field("postal_code"),
+	}
+}
+
+// ListCheckpoints 获取配送单的物流轨迹
+func (s *ShipmentService) ListCheckpoints(ctx context.Context, id uint) ([]*model.ShipmentCheckpoint, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	checkpoints, err := s.shipmentRepo.ListCheckpoints(ctx, id, 0)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取物流轨迹失败", err)
+	}
+	return checkpoints, nil
+}
+
+// publish 发布配送单事件，发布失败只记录日志，不影响配送单状态变更
+func (s *ShipmentService) publish(ctx context.Context, eventType string, shipment *model.Shipment, reason string) {
+	data := event.ShipmentEvent{
+		ShipmentID:          shipment.ID,
+		OrderID:             shipment.OrderID,
+		OrderNumber:         shipment.OrderNumber,
+		UserID:              shipment.UserID,
+		Status:              shipment.Status,
+		CarrierName:         shipment.ShippingCarrierName,
+		TrackingNumber:      shipment.TrackingNumber,
+		TrackingURL:         shipment.TrackingURL,
+		EstimatedDeliveryAt: shipment.EstimatedDeliveryAt,
+		Reason:              reason,
+	}
+
+	checkpoints, err := s.shipmentRepo.ListCheckpoints(ctx, shipment.ID, eventCheckpointLimit)
+	if err != nil {
+		s.log.Warn(ctx, "Failed to load checkpoints for shipment event", zap.Uint("shipment_id", shipment.ID), zap.Error(err))
+	}
+	for _, cp := range checkpoints {
+		data.Checkpoints = append(data.Checkpoints, event.CheckpointSummary{
+			Status:      cp.Status,
+			Description: cp.Description,
+			Location:    cp.Location,
+			OccurredAt:  cp.OccurredAt,
+		})
+	}
+
+	if err := s.publisher.Publish(ctx, eventType, data); err != nil {
+		s.log.Error(ctx, "Failed to publish shipment event",
+			zap.String("event", eventType),
+			zap.Uint("shipment_id", shipment.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+func exceptionReason(req *CheckpointRequest) string {
+	if req.Status == model.ShipmentStatusException {
+		return req.Description
+	}
+	return ""
+}
+
+// Get 获取配送单
+func (s *ShipmentService) Get(ctx context.Context, id uint) (*model.Shipment, error) {
+	shipment, err := s.shipmentRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrShipmentNotFound, fmt.Sprintf("配送单 %d 不存在", id), http.StatusNotFound, err)
+		}
+		return nil, apperrors.NewInternalServerError("获取配送单失败", err)
+	}
+	return shipment, nil
+}
+
+// ListByOrder 获取订单的所有配送单
+func (s *ShipmentService) ListByOrder(ctx context.Context, orderID uint) ([]*model.Shipment, error) {
+	shipments, err := s.shipmentRepo.ListByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取配送单失败", err)
+	}
+	return shipments, nil
+}
@@ -0,0 +1,36 @@
+package event
+
+import "time"
+
+// 客服服务发布的事件类型，通知服务据此向客户发送邮件并向运营人员告警
+const (
+	TicketCreated  = "support.ticket_created"
+	TicketReplied  = "support.ticket_replied"
+	TicketResolved = "support.ticket_resolved"
+	SLABreached    = "support.sla_breached"
+)
+
+// TicketEvent 是工单创建、客服回复和工单解决事件的数据。UserID 为 0 时通知发送到 Email，
+// ReplyAddress 是客户回复邮件的地址，邮件主题需要包含工单号
+type TicketEvent struct {
+	TicketID     uint   `json:"ticket_id"`
+	Number       string `json:"number"`
+	UserID       uint   `json:"user_id"`
+	Email        string `json:"email,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Subject      string `json:"subject"`
+	Status       string `json:"status"`
+	Message      string `json:"message,omitempty"` // 客服的回复，只在 support.ticket_replied 中提供
+	ReplyAddress string `json:"reply_address"`
+}
+
+// SLABreachedEvent 是 support.sla_breached 事件的数据
+type SLABreachedEvent struct {
+	TicketID   uint      `json:"ticket_id"`
+	Number     string    `json:"number"`
+	Subject    string    `json:"subject"`
+	Priority   string    `json:"priority"`
+	AssigneeID *uint     `json:"assignee_id,omitempty"`
+	SLA        string    `json:"sla"` // first_response 或 resolution
+	DueAt      time.Time `json:"due_at"`
+}
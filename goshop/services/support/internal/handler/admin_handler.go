@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/support/internal/repository"
+	"github.com/yourusername/goshop/services/support/internal/service"
+)
+
+// AdminHandler 处理客服处理工单和管理快捷回复的 HTTP 请求
+type AdminHandler struct {
+	ticketService      *service.TicketService
+	cannedReplyService *service.CannedReplyService
+}
+
+// NewAdminHandler 创建客服处理器
+func NewAdminHandler(ticketService *service.TicketService, cannedReplyService *service.CannedReplyService) *AdminHandler {
+	return &AdminHandler{
+		ticketService:      ticketService,
+		cannedReplyService: cannedReplyService,
+	}
+}
+
+// RegisterRoutes 注册客服路由
+func (h *AdminHandler) RegisterRoutes(api *gin.RouterGroup) {
+	tickets := api.Group("/support/admin/tickets")
+	{
+		tickets.GET("", h.ListTickets)
+		tickets.GET("/:id", h.GetTicket)
+		tickets.PUT("/:id", h.UpdateTicket)
+		tickets.POST("/:id/messages", h.Reply)
+		tickets.POST("/:id/assign", h.Assign)
+	}
+
+	replies := api.Group("/support/admin/canned-replies")
+	{
+		replies.GET("", h.ListCannedReplies)
+		replies.POST("", h.CreateCannedReply)
+		replies.PUT("/:id", h.UpdateCannedReply)
+		replies.DELETE("/:id", h.DeleteCannedReply)
+	}
+}
+
+// ListTickets 分页获取工单。assignee_id=me 获取分配给自己的工单，unassigned=true 获取未分配的工单，
+// breached=true 获取 SLA 已超时的工单
+func (h *AdminHandler) ListTickets(c *gin.Context) {
+	filter := repository.TicketFilter{
+		Status:     c.Query("status"),
+		Priority:   c.Query("priority"),
+		Category:   c.Query("category"),
+		Unassigned: c.Query("unassigned") == "true",
+		Breached:   c.Query("breached") == "true",
+		Query:      c.Query("q"),
+	}
+	var ok bool
+	if c.Query("assignee_id") == "me" {
+		if filter.AssigneeID, ok = currentUserID(c); !ok {
+			return
+		}
+	} else if filter.AssigneeID, ok = parseIDQuery(c, "assignee_id"); !ok {
+		return
+	}
+	if filter.UserID, ok = parseIDQuery(c, "user_id"); !ok {
+		return
+	}
+	if filter.OrderID, ok = parseIDQuery(c, "order_id"); !ok {
+		return
+	}
+	list, err := h.ticketService.AdminList(c.Request.Context(), filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// GetTicket 获取工单及其全部消息，包括内部备注
+func (h *AdminHandler) GetTicket(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	ticket, err := h.ticketService.AdminGet(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ticket})
+}
+
+// UpdateTicket 修改工单的优先级、分类或状态
+func (h *AdminHandler) UpdateTicket(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.UpdateTicketRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	ticket, err := h.ticketService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ticket})
+}
+
+// Reply 回复工单或添加内部备注
+func (h *AdminHandler) Reply(c *gin.Context) {
+	agentID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	var req service.AgentReplyRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	ticket, err := h.ticketService.AgentReply(c.Request.Context(), agentID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ticket})
+}
+
+// Assign 分配工单
+func (h *AdminHandler) Assign(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.AssignRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	ticket, err := h.ticketService.Assign(c.Request.Context(), id, req.AssigneeID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ticket})
+}
+
+// ListCannedReplies 获取快捷回复，可按 category 过滤
+func (h *AdminHandler) ListCannedReplies(c *gin.Context) {
+	replies, err := h.cannedReplyService.List(c.Request.Context(), c.Query("category"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": replies})
+}
+
+// CreateCannedReply 创建快捷回复
+func (h *AdminHandler) CreateCannedReply(c *gin.Context) {
+	agentID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.CannedReplyRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	reply, err := h.cannedReplyService.Create(c.Request.Context(), agentID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": reply})
+}
+
+// UpdateCannedReply 更新快捷回复
+func (h *AdminHandler) UpdateCannedReply(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.CannedReplyRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	reply, err := h.cannedReplyService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": reply})
+}
+
+// DeleteCannedReply 删除快捷回复
+func (h *AdminHandler) DeleteCannedReply(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.cannedReplyService.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/support/internal/service"
+)
+
+// InboundTokenHeader 是收信回调携带共享密钥的请求头
+const InboundTokenHeader = "X-Inbound-Token"
+
+// InboundHandler 处理邮件服务商转发收到的邮件的回调
+type InboundHandler struct {
+	ticketService *service.TicketService
+	token         string
+}
+
+// NewInboundHandler 创建收信回调处理器，token 为空时不校验请求头，只用于开发环境
+func NewInboundHandler(ticketService *service.TicketService, token string) *InboundHandler {
+	return &InboundHandler{
+		ticketService: ticketService,
+		token:         token,
+	}
+}
+
+// RegisterRoutes 注册收信回调路由
+func (h *InboundHandler) RegisterRoutes(api *gin.RouterGroup) {
+	api.POST("/support/inbound/email", h.ReceiveEmail)
+}
+
+// ReceiveEmail 接收一封邮件，回复已有工单或创建新工单
+func (h *InboundHandler) ReceiveEmail(c *gin.Context) {
+	if h.token != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader(InboundTokenHeader)), []byte(h.token)) != 1 {
+		respondError(c, apperrors.NewUnauthorized("无效的收信密钥", nil))
+		return
+	}
+	var req service.InboundEmail
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	ticket, err := h.ticketService.HandleInboundEmail(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ticket})
+}
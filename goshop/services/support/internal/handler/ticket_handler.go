@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/support/internal/service"
+)
+
+// TicketHandler 处理客户在账户中提交和跟踪工单的 HTTP 请求
+type TicketHandler struct {
+	ticketService *service.TicketService
+}
+
+// NewTicketHandler 创建客户工单处理器
+func NewTicketHandler(ticketService *service.TicketService) *TicketHandler {
+	return &TicketHandler{
+		ticketService: ticketService,
+	}
+}
+
+// RegisterRoutes 注册客户工单路由，客户只能访问自己的工单
+func (h *TicketHandler) RegisterRoutes(api *gin.RouterGroup) {
+	tickets := api.Group("/support/tickets")
+	{
+		tickets.GET("", h.ListTickets)
+		tickets.POST("", h.OpenTicket)
+		tickets.GET("/:id", h.GetTicket)
+		tickets.POST("/:id/messages", h.Reply)
+		tickets.POST("/:id/close", h.CloseTicket)
+	}
+}
+
+// ListTickets 分页获取当前用户的工单，可按 status 过滤
+func (h *TicketHandler) ListTickets(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	list, err := h.ticketService.ListMine(c.Request.Context(), userID, c.Query("status"),
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// OpenTicket 提交工单
+func (h *TicketHandler) OpenTicket(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.OpenTicketRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	ticket, err := h.ticketService.Open(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": ticket})
+}
+
+// GetTicket 获取工单及客服的公开回复
+func (h *TicketHandler) GetTicket(c *gin.Context) {
+	userID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	ticket, err := h.ticketService.GetMine(c.Request.Context(), userID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ticket})
+}
+
+// Reply 回复工单
+func (h *TicketHandler) Reply(c *gin.Context) {
+	userID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	var req service.CustomerReplyRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	ticket, err := h.ticketService.CustomerReply(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ticket})
+}
+
+// CloseTicket 关闭工单
+func (h *TicketHandler) CloseTicket(c *gin.Context) {
+	userID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	ticket, err := h.ticketService.Close(c.Request.Context(), userID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ticket})
+}
+
+// parseOwnedID 解析当前用户 ID 和路径中的 ID
+func parseOwnedID(c *gin.Context) (uint, uint, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	return userID, id, true
+}
@@ -0,0 +1,106 @@
+package model
+
+import "time"
+
+// 工单状态
+const (
+	StatusOpen     = "open"     // 等待客服处理
+	StatusPending  = "pending"  // 等待客户回复，解决时限暂停计时
+	StatusResolved = "resolved" // 已解决，客户回复时重新打开
+	StatusClosed   = "closed"   // 已关闭，客户回复时创建新工单
+)
+
+// 工单优先级，决定 SLA 时限
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// 工单分类
+const (
+	CategoryOrder    = "order"
+	CategoryPayment  = "payment"
+	CategoryShipping = "shipping"
+	CategoryReturn   = "return"
+	CategoryAccount  = "account"
+	CategoryOther    = "other"
+)
+
+// 工单和消息的来源渠道
+const (
+	ChannelWeb   = "web"
+	ChannelEmail = "email"
+)
+
+// 消息作者类型
+const (
+	AuthorCustomer = "customer"
+	AuthorAgent    = "agent"
+)
+
+// SLA 时限的类型
+const (
+	SLAFirstResponse = "first_response"
+	SLAResolution    = "resolution"
+)
+
+// Ticket 表示客户提交的工单。客户通过账户或邮件提交，邮件提交且无法识别用户时 UserID 为 0，
+// 回复按 Email 发送
+type Ticket struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Number      string `json:"number" gorm:"uniqueIndex;size:50;not null"`
+	UserID      uint   `json:"user_id" gorm:"index"`
+	Email       string `json:"email" gorm:"index;size:255"`
+	Name        string `json:"name" gorm:"size:100"`
+	OrderID     *uint  `json:"order_id,omitempty" gorm:"index"`
+	OrderNumber string `json:"order_number,omitempty" gorm:"size:50"`
+	Subject     string `json:"subject" gorm:"size:255;not null"`
+	Category    string `json:"category" gorm:"index;size:20;not null"`
+	Priority    string `json:"priority" gorm:"index;size:20;not null"`
+	Status      string `json:"status" gorm:"index;size:20;not null"`
+	Channel     string `json:"channel" gorm:"size:20;not null"`
+	AssigneeID  *uint  `json:"assignee_id,omitempty" gorm:"index"`
+
+	// SLA 计时。首次响应时限在客服首次公开回复后不再检查；解决时限在等待客户回复期间暂停，
+	// 恢复时顺延暂停的时长。超时后标记一次并发出告警
+	FirstResponseDue      time.Time  `json:"first_response_due" gorm:"not null"`
+	FirstRespondedAt      *time.Time `json:"first_responded_at,omitempty"`
+	FirstResponseBreached bool       `json:"first_response_breached" gorm:"not null;default:false"`
+	ResolutionDue         time.Time  `json:"resolution_due" gorm:"not null"`
+	ResolutionBreached    bool       `json:"resolution_breached" gorm:"not null;default:false"`
+	PausedAt              *time.Time `json:"paused_at,omitempty"`
+	PausedSeconds         int64      `json:"paused_seconds" gorm:"not null;default:0"` // 已结束的暂停累计时长
+	ResolvedAt            *time.Time `json:"resolved_at,omitempty"`
+	ClosedAt              *time.Time `json:"closed_at,omitempty"`
+
+	LastMessageAt time.Time        `json:"last_message_at" gorm:"index;not null"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	Messages      []*TicketMessage `json:"messages,omitempty" gorm:"foreignKey:TicketID"`
+}
+
+// TicketMessage 表示工单中的一条消息，内部备注只有客服可见
+type TicketMessage struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TicketID   uint      `json:"ticket_id" gorm:"index;not null"`
+	AuthorType string    `json:"author_type" gorm:"size:20;not null"`
+	AuthorID   uint      `json:"author_id"` // 邮件客户为 0
+	Body       string    `json:"body" gorm:"type:text;not null"`
+	Internal   bool      `json:"internal" gorm:"not null;default:false"`
+	Channel    string    `json:"channel" gorm:"size:20;not null"`
+	MessageID  *string   `json:"-" gorm:"uniqueIndex;size:255"` // 收到邮件的 Message-ID，重复投递的邮件只保存一次
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CannedReply 表示客服的快捷回复，正文中的 {{name}} 和 {{ticket_number}} 在使用时替换
+type CannedReply struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Title     string    `json:"title" gorm:"size:100;not null"`
+	Category  string    `json:"category" gorm:"index;size:20"` // 为空时适用于所有分类
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
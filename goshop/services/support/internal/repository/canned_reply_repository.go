@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/support/internal/model"
+	"gorm.io/gorm"
+)
+
+// CannedReplyRepository 定义快捷回复仓库接口
+type CannedReplyRepository interface {
+	Create(ctx context.Context, reply *model.CannedReply) error
+	GetByID(ctx context.Context, id uint) (*model.CannedReply, error)
+	Update(ctx context.Context, reply *model.CannedReply) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, category string) ([]*model.CannedReply, error)
+}
+
+// GormCannedReplyRepository 实现 CannedReplyRepository 接口的 GORM 仓库
+type GormCannedReplyRepository struct {
+	db *gorm.DB
+}
+
+// NewCannedReplyRepository 创建快捷回复仓库实例
+func NewCannedReplyRepository(db *gorm.DB) CannedReplyRepository {
+	return &GormCannedReplyRepository{
+		db: db,
+	}
+}
+
+// Create 创建快捷回复
+func (r *GormCannedReplyRepository) Create(ctx context.Context, reply *model.CannedReply) error {
+	return r.db.WithContext(ctx).Create(reply).Error
+}
+
+// GetByID 根据 ID 获取快捷回复
+func (r *GormCannedReplyRepository) GetByID(ctx context.Context, id uint) (*model.CannedReply, error) {
+	var reply model.CannedReply
+	if err := r.db.WithContext(ctx).First(&reply, id).Error; err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// Update 更新快捷回复
+func (r *GormCannedReplyRepository) Update(ctx context.Context, reply *model.CannedReply) error {
+	return r.db.WithContext(ctx).Save(reply).Error
+}
+
+// Delete 删除快捷回复
+func (r *GormCannedReplyRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.CannedReply{}, id).Error
+}
+
+// List 获取快捷回复，category 不为空时只返回该分类和通用的快捷回复
+func (r *GormCannedReplyRepository) List(ctx context.Context, category string) ([]*model.CannedReply, error) {
+	var replies []*model.CannedReply
+	query := r.db.WithContext(ctx)
+	if category != "" {
+		query = query.Where("category = ? OR category = ''", category)
+	}
+	err := query.Order("title ASC").Find(&replies).Error
+	return replies, err
+}
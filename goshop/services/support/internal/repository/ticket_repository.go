@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/support/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TicketFilter 表示查询工单的过滤条件，零值字段不参与过滤
+type TicketFilter struct {
+	UserID     uint
+	OrderID    uint
+	Status     string
+	Priority   string
+	Category   string
+	AssigneeID uint
+	Unassigned bool
+	Breached   bool // 任一 SLA 已超时
+	Query      string
+}
+
+// TicketRepository 定义工单仓库接口
+type TicketRepository interface {
+	Create(ctx context.Context, ticket *model.Ticket) error
+	GetByID(ctx context.Context, id uint, withInternal bool) (*model.Ticket, error)
+	GetByNumber(ctx context.Context, number string) (*model.Ticket, error)
+	Update(ctx context.Context, ticket *model.Ticket) error
+	AddMessage(ctx context.Context, ticket *model.Ticket, message *model.TicketMessage) (bool, error)
+	MessageExists(ctx context.Context, messageID string) (bool, error)
+	List(ctx context.Context, filter TicketFilter, offset, limit int) ([]*model.Ticket, int64, error)
+	ListBreaching(ctx context.Context, now time.Time, limit int) ([]*model.Ticket, error)
+	MarkBreached(ctx context.Context, id uint, sla string) (bool, error)
+}
+
+// GormTicketRepository 实现 TicketRepository 接口的 GORM 仓库
+type GormTicketRepository struct {
+	db *gorm.DB
+}
+
+// NewTicketRepository 创建工单仓库实例
+func NewTicketRepository(db *gorm.DB) TicketRepository {
+	return &GormTicketRepository{
+		db: db,
+	}
+}
+
+// Create 创建工单及其首条消息
+func (r *GormTicketRepository) Create(ctx context.Context, ticket *model.Ticket) error {
+	return r.db.WithContext(ctx).Create(ticket).Error
+}
+
+// GetByID 根据 ID 获取工单及其消息，withInternal 为 false 时不包含内部备注
+func (r *GormTicketRepository) GetByID(ctx context.Context, id uint, withInternal bool) (*model.Ticket, error) {
+	var ticket model.Ticket
+	err := r.db.WithContext(ctx).
+		Preload("Messages", func(db *gorm.DB) *gorm.DB {
+			if !withInternal {
+				db = db.Where("internal = ?", false)
+			}
+			return db.Order("id ASC")
+		}).
+		First(&ticket, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// GetByNumber 根据工单号获取工单，不包含消息
+func (r *GormTicketRepository) GetByNumber(ctx context.Context, number string) (*model.Ticket, error) {
+	var ticket model.Ticket
+	err := r.db.WithContext(ctx).Where("number = ?", number).First(&ticket).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// Update 保存工单，不修改消息
+func (r *GormTicketRepository) Update(ctx context.Context, ticket *model.Ticket) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(ticket).Error
+}
+
+// AddMessage 保存消息和工单的状态变化。带 Message-ID 的邮件已保存过时不做修改并返回 false
+func (r *GormTicketRepository) AddMessage(ctx context.Context, ticket *model.Ticket, message *model.TicketMessage) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		message.TicketID = ticket.ID
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(message)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Omit(clause.Associations).Save(ticket).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// MessageExists 判断邮件是否已保存过
+func (r *GormTicketRepository) MessageExists(ctx context.Context, messageID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.TicketMessage{}).Where("message_id = ?", messageID).Count(&count).Error
+	return count > 0, err
+}
+
+// List 按过滤条件分页获取工单，按最后一条消息的时间倒序
+func (r *GormTicketRepository) List(ctx context.Context, filter TicketFilter, offset, limit int) ([]*model.Ticket, int64, error) {
+	var tickets []*model.Ticket
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Ticket{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.OrderID != 0 {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.AssigneeID != 0 {
+		query = query.Where("assignee_id = ?", filter.AssigneeID)
+	}
+	if filter.Unassigned {
+		query = query.Where("assignee_id IS NULL")
+	}
+	if filter.Breached {
+		query = query.Where("first_response_breached = ? OR resolution_breached = ?", true, true)
+	}
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("number = ? OR subject ILIKE ? OR email ILIKE ?", filter.Query, like, like)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("last_message_at DESC, id DESC").Offset(offset).Limit(limit).Find(&tickets).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return tickets, total, nil
+}
+
+// ListBreaching 获取未解决且首次响应或解决时限已过、尚未标记超时的工单
+func (r *GormTicketRepository) ListBreaching(ctx context.Context, now time.Time, limit int) ([]*model.Ticket, error) {
+	var tickets []*model.Ticket
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", []string{model.StatusOpen, model.StatusPending}).
+		Where(r.db.
+			Where("first_responded_at IS NULL AND first_response_breached = ? AND first_response_due <= ?", false, now).
+			Or("paused_at IS NULL AND resolution_breached = ? AND resolution_due <= ?", false, now)).
+		Order("id ASC").
+		Limit(limit).
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// MarkBreached 标记工单的 SLA 已超时，已被标记时返回 false，避免重复告警
+func (r *GormTicketRepository) MarkBreached(ctx context.Context, id uint, sla string) (bool, error) {
+	column := "resolution_breached"
+	if sla == model.SLAFirstResponse {
+		column = "first_response_breached"
+	}
+	result := r.db.WithContext(ctx).Model(&model.Ticket{}).
+		Where("id = ? AND "+column+" = ?", id, false).
+		Update(column, true)
+	return result.RowsAffected > 0, result.Error
+}
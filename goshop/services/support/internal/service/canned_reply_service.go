@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/support/internal/model"
+	"github.com/yourusername/goshop/services/support/internal/repository"
+	"gorm.io/gorm"
+)
+
+// CannedReplyRequest 表示创建或更新快捷回复的请求
+type CannedReplyRequest struct {
+	Title    string `json:"title" binding:"required,max=100"`
+	Category string `json:"category" binding:"omitempty,oneof=order payment shipping return account other"`
+	Body     string `json:"body" binding:"required,max=10000"`
+}
+
+// CannedReplyService 负责管理客服的快捷回复
+type CannedReplyService struct {
+	cannedRepo repository.CannedReplyRepository
+}
+
+// NewCannedReplyService 创建快捷回复服务
+func NewCannedReplyService(cannedRepo repository.CannedReplyRepository) *CannedReplyService {
+	return &CannedReplyService{
+		cannedRepo: cannedRepo,
+	}
+}
+
+// Create 创建快捷回复
+func (s *CannedReplyService) Create(ctx context.Context, agentID uint, req *CannedReplyRequest) (*model.CannedReply, error) {
+	reply := &model.CannedReply{
+		Title:     req.Title,
+		Category:  req.Category,
+		Body:      req.Body,
+		CreatedBy: agentID,
+	}
+	if err := s.cannedRepo.Create(ctx, reply); err != nil {
+		return nil, apperrors.NewInternalServerError("创建快捷回复失败", err)
+	}
+	return reply, nil
+}
+
+// List 获取快捷回复，category 不为空时只返回该分类和通用的快捷回复
+func (s *CannedReplyService) List(ctx context.Context, category string) ([]*model.CannedReply, error) {
+	replies, err := s.cannedRepo.List(ctx, category)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取快捷回复失败", err)
+	}
+	return replies, nil
+}
+
+// Update 更新快捷回复
+func (s *CannedReplyService) Update(ctx context.Context, id uint, req *CannedReplyRequest) (*model.CannedReply, error) {
+	reply, err := getCannedReply(ctx, s.cannedRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	reply.Title = req.Title
+	reply.Category = req.Category
+	reply.Body = req.Body
+	if err := s.cannedRepo.Update(ctx, reply); err != nil {
+		return nil, apperrors.NewInternalServerError("更新快捷回复失败", err)
+	}
+	return reply, nil
+}
+
+// Delete 删除快捷回复
+func (s *CannedReplyService) Delete(ctx context.Context, id uint) error {
+	if _, err := getCannedReply(ctx, s.cannedRepo, id); err != nil {
+		return err
+	}
+	if err := s.cannedRepo.Delete(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除快捷回复失败", err)
+	}
+	return nil
+}
+
+// getCannedReply 获取快捷回复
+func getCannedReply(ctx context.Context, cannedRepo repository.CannedReplyRepository, id uint) (*model.CannedReply, error) {
+	reply, err := cannedRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("快捷回复 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取快捷回复失败", err)
+	}
+	return reply, nil
+}
+
+// renderCannedReply 替换快捷回复正文中的客户名称和工单号
+func renderCannedReply(reply *model.CannedReply, ticket *model.Ticket) string {
+	return strings.NewReplacer(
+		"{{name}}", ticket.Name,
+		"{{ticket_number}}", ticket.Number,
+	).Replace(reply.Body)
+}
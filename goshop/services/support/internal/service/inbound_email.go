@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/support/internal/event"
+	"github.com/yourusername/goshop/services/support/internal/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ticketNumberPattern 匹配邮件主题中的工单号，通知邮件的主题包含 [工单号]，客户直接回复即可关联工单
+var ticketNumberPattern = regexp.MustCompile(`\[(TKT\d+)\]`)
+
+// InboundEmail 表示邮件服务商转发的一封收到的邮件
+type InboundEmail struct {
+	MessageID string `json:"message_id" binding:"required,max=255"`
+	From      string `json:"from" binding:"required,email,max=255"`
+	FromName  string `json:"from_name" binding:"max=100"`
+	Subject   string `json:"subject" binding:"max=255"`
+	Text      string `json:"text" binding:"required"`
+}
+
+// HandleInboundEmail 处理收到的邮件。主题包含发件人自己的未关闭工单的工单号时作为客户回复，
+// 否则创建新工单。同一封邮件重复投递时返回已处理的结果
+func (s *TicketService) HandleInboundEmail(ctx context.Context, email *InboundEmail) (*model.Ticket, error) {
+	exists, err := s.ticketRepo.MessageExists(ctx, email.MessageID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("查询邮件失败", err)
+	}
+	if exists {
+		return nil, nil
+	}
+
+	from := strings.ToLower(email.From)
+	message := &model.TicketMessage{
+		AuthorType: model.AuthorCustomer,
+		Body:       strings.TrimSpace(email.Text),
+		Channel:    model.ChannelEmail,
+		MessageID:  &email.MessageID,
+	}
+	ticket, err := s.findReplyTicket(ctx, email.Subject, from)
+	if err != nil {
+		return nil, err
+	}
+	if ticket != nil {
+		message.AuthorID = ticket.UserID
+		if err := s.addCustomerMessage(ctx, ticket, message); err != nil {
+			return nil, err
+		}
+		return ticket, nil
+	}
+
+	subject := strings.TrimSpace(email.Subject)
+	if subject == "" {
+		subject = "(无主题)"
+	}
+	ticket, err = s.newTicket(model.ChannelEmail, subject, model.CategoryOther, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	ticket.Email = from
+	ticket.Name = strings.TrimSpace(email.FromName)
+	ticket.Messages = []*model.TicketMessage{message}
+	if err := s.ticketRepo.Create(ctx, ticket); err != nil {
+		return nil, apperrors.NewInternalServerError("创建工单失败", err)
+	}
+	s.log.Info(ctx, "已根据邮件创建工单", zap.String("ticket_number", ticket.Number))
+	s.publish(ctx, event.TicketCreated, ticket, "")
+	return ticket, nil
+}
+
+// findReplyTicket 根据主题中的工单号查找发件人的未关闭工单，找不到时返回 nil
+func (s *TicketService) findReplyTicket(ctx context.Context, subject, from string) (*model.Ticket, error) {
+	match := ticketNumberPattern.FindStringSubmatch(subject)
+	if match == nil {
+		return nil, nil
+	}
+	ticket, err := s.ticketRepo.GetByNumber(ctx, match[1])
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apperrors.NewInternalServerError("获取工单失败", err)
+	}
+	if ticket.Email != from || ticket.Status == model.StatusClosed {
+		return nil, nil
+	}
+	return ticket, nil
+}
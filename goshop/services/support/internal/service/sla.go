@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/support/internal/event"
+	"github.com/yourusername/goshop/services/support/internal/model"
+	"go.uber.org/zap"
+)
+
+// breachBatchSize 是每轮最多检查的超时工单数
+const breachBatchSize = 100
+
+// applySLA 按工单的优先级计算首次响应和解决时限，解决时限顺延已暂停的时长。
+// 新时限尚未到期时清除超时标记
+func (s *TicketService) applySLA(ticket *model.Ticket, now time.Time) {
+	ticket.FirstResponseDue = ticket.CreatedAt.Add(s.cfg.FirstResponse[ticket.Priority])
+	ticket.ResolutionDue = ticket.CreatedAt.Add(s.cfg.Resolution[ticket.Priority]).
+		Add(time.Duration(ticket.PausedSeconds) * time.Second)
+	if ticket.FirstResponseDue.After(now) {
+		ticket.FirstResponseBreached = false
+	}
+	if ticket.ResolutionDue.After(now) {
+		ticket.ResolutionBreached = false
+	}
+}
+
+// setStatus 修改工单状态并维护解决时限的暂停：等待客户回复期间暂停计时，
+// 离开该状态时把暂停的时长顺延到解决时限
+func (s *TicketService) setStatus(ticket *model.Ticket, status string, now time.Time) {
+	if status == ticket.Status {
+		return
+	}
+	if ticket.PausedAt != nil && status != model.StatusPending {
+		paused := now.Sub(*ticket.PausedAt)
+		ticket.PausedSeconds += int64(paused / time.Second)
+		ticket.ResolutionDue = ticket.ResolutionDue.Add(paused)
+		ticket.PausedAt = nil
+	}
+
+	switch status {
+	case model.StatusOpen:
+		ticket.ResolvedAt = nil
+	case model.StatusPending:
+		ticket.PausedAt = &now
+	case model.StatusResolved:
+		ticket.ResolvedAt = &now
+	case model.StatusClosed:
+		ticket.ClosedAt = &now
+		if ticket.ResolvedAt == nil {
+			ticket.ResolvedAt = &now
+		}
+	}
+	ticket.Status = status
+}
+
+// Run 定期检查超过 SLA 时限的工单，每个时限只告警一次，直到 ctx 结束
+func (s *TicketService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.checkSLA(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *TicketService) checkSLA(ctx context.Context, now time.Time) {
+	tickets, err := s.ticketRepo.ListBreaching(ctx, now, breachBatchSize)
+	if err != nil {
+		s.log.Error(ctx, "Failed to list tickets breaching SLA", zap.Error(err))
+		return
+	}
+	for _, ticket := range tickets {
+		if ctx.Err() != nil {
+			return
+		}
+		if ticket.FirstRespondedAt == nil && !ticket.FirstResponseBreached && !ticket.FirstResponseDue.After(now) {
+			s.breach(ctx, ticket, model.SLAFirstResponse, ticket.FirstResponseDue)
+		}
+		if ticket.PausedAt == nil && !ticket.ResolutionBreached && !ticket.ResolutionDue.After(now) {
+			s.breach(ctx, ticket, model.SLAResolution, ticket.ResolutionDue)
+		}
+	}
+}
+
+// breach 标记工单的 SLA 超时并发布告警事件
+func (s *TicketService) breach(ctx context.Context, ticket *model.Ticket, sla string, due time.Time) {
+	marked, err := s.ticketRepo.MarkBreached(ctx, ticket.ID, sla)
+	if err != nil {
+		s.log.Error(ctx, "Failed to mark SLA breach", zap.String("ticket_number", ticket.Number), zap.Error(err))
+		return
+	}
+	if !marked {
+		return
+	}
+	s.log.Warn(ctx, "Ticket breached SLA",
+		zap.String("ticket_number", ticket.Number),
+		zap.String("sla", sla),
+		zap.String("priority", ticket.Priority),
+		zap.Time("due_at", due),
+	)
+	err = s.publisher.Publish(ctx, event.SLABreached, event.EventVersion, &event.SLABreachedEvent{
+		TicketID:   ticket.ID,
+		Number:     ticket.Number,
+		Subject:    ticket.Subject,
+		Priority:   ticket.Priority,
+		AssigneeID: ticket.AssigneeID,
+		SLA:        sla,
+		DueAt:      due,
+	})
+	if err != nil {
+		s.log.Warn(ctx, "Failed to publish SLA breach", zap.String("ticket_number", ticket.Number), zap.Error(err))
+	}
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/idgen"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/support/internal/event"
+	"github.com/yourusername/goshop/services/support/internal/model"
+	"github.com/yourusername/goshop/services/support/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// OpenTicketRequest 表示客户提交工单的请求，email 用于接收回复，未提供时按账户的联系方式通知
+type OpenTicketRequest struct {
+	Subject     string `json:"subject" binding:"required,max=255"`
+	Category    string `json:"category" binding:"required,oneof=order payment shipping return account other"`
+	OrderID     *uint  `json:"order_id"`
+	OrderNumber string `json:"order_number" binding:"max=50"`
+	Body        string `json:"body" binding:"required,max=10000"`
+	Email       string `json:"email" binding:"omitempty,email,max=255"`
+	Name        string `json:"name" binding:"max=100"`
+}
+
+// CustomerReplyRequest 表示客户回复工单的请求
+type CustomerReplyRequest struct {
+	Body string `json:"body" binding:"required,max=10000"`
+}
+
+// AgentReplyRequest 表示客服回复工单的请求。使用快捷回复时 body 可以为空；
+// 公开回复后工单默认进入等待客户回复状态，可以通过 status 指定
+type AgentReplyRequest struct {
+	Body          string `json:"body" binding:"max=10000"`
+	CannedReplyID uint   `json:"canned_reply_id"`
+	Internal      bool   `json:"internal"`
+	Status        string `json:"status" binding:"omitempty,oneof=open pending resolved"`
+}
+
+// UpdateTicketRequest 表示客服修改工单的请求，未提供的字段保持不变
+type UpdateTicketRequest struct {
+	Priority string `json:"priority" binding:"omitempty,oneof=low normal high urgent"`
+	Category string `json:"category" binding:"omitempty,oneof=order payment shipping return account other"`
+	Status   string `json:"status" binding:"omitempty,oneof=open pending resolved closed"`
+}
+
+// AssignRequest 表示分配工单的请求，assignee_id 为 0 时取消分配
+type AssignRequest struct {
+	AssigneeID uint `json:"assignee_id"`
+}
+
+// TicketList 表示分页的工单列表
+type TicketList struct {
+	Items    []*model.Ticket `json:"items"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// SLAConfig 表示各优先级的首次响应和解决时限
+type SLAConfig struct {
+	FirstResponse map[string]time.Duration
+	Resolution    map[string]time.Duration
+	ReplyAddress  string // 客户回复邮件的地址
+}
+
+// TicketService 负责工单的提交、回复、分配和状态流转，并维护每个工单的 SLA 计时。
+// 工单创建、客服回复和解决时发布事件，由通知服务向客户发送邮件
+type TicketService struct {
+	ticketRepo repository.TicketRepository
+	cannedRepo repository.CannedReplyRepository
+	publisher  event.Publisher
+	cfg        SLAConfig
+	log        *logger.Logger
+}
+
+// NewTicketService 创建工单服务
+func NewTicketService(ticketRepo repository.TicketRepository, cannedRepo repository.CannedReplyRepository, publisher event.Publisher, cfg SLAConfig, log *logger.Logger) *TicketService {
+	return &TicketService{
+		ticketRepo: ticketRepo,
+		cannedRepo: cannedRepo,
+		publisher:  publisher,
+		cfg:        cfg,
+		log:        log,
+	}
+}
+
+// Open 以客户身份提交工单
+func (s *TicketService) Open(ctx context.Context, userID uint, req *OpenTicketRequest) (*model.Ticket, error) {
+	ticket, err := s.newTicket(model.ChannelWeb, req.Subject, req.Category, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	ticket.UserID = userID
+	ticket.Email = strings.ToLower(req.Email)
+	ticket.Name = strings.TrimSpace(req.Name)
+	ticket.OrderID = req.OrderID
+	ticket.OrderNumber = strings.TrimSpace(req.OrderNumber)
+	ticket.Messages = []*model.TicketMessage{{
+		AuthorType: model.AuthorCustomer,
+		AuthorID:   userID,
+		Body:       req.Body,
+		Channel:    model.ChannelWeb,
+	}}
+	if err := s.ticketRepo.Create(ctx, ticket); err != nil {
+		return nil, apperrors.NewInternalServerError("提交工单失败", err)
+	}
+	s.publish(ctx, event.TicketCreated, ticket, "")
+	return ticket, nil
+}
+
+// ListMine 分页获取客户自己的工单
+func (s *TicketService) ListMine(ctx context.Context, userID uint, status string, page, pageSize int) (*TicketList, error) {
+	return s.list(ctx, repository.TicketFilter{UserID: userID, Status: status}, page, pageSize)
+}
+
+// GetMine 获取客户自己的工单及其公开消息
+func (s *TicketService) GetMine(ctx context.Context, userID, id uint) (*model.Ticket, error) {
+	ticket, err := s.get(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.UserID != userID {
+		return nil, apperrors.NewNotFound(fmt.Sprintf("工单 %d 不存在", id), nil)
+	}
+	return ticket, nil
+}
+
+// CustomerReply 以客户身份回复工单，已解决的工单重新打开，已关闭的工单需要提交新工单
+func (s *TicketService) CustomerReply(ctx context.Context, userID, id uint, req *CustomerReplyRequest) (*model.Ticket, error) {
+	ticket, err := s.GetMine(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == model.StatusClosed {
+		return nil, apperrors.NewConflict("工单已关闭，请提交新工单", nil)
+	}
+	message := &model.TicketMessage{
+		AuthorType: model.AuthorCustomer,
+		AuthorID:   userID,
+		Body:       req.Body,
+		Channel:    model.ChannelWeb,
+	}
+	if err := s.addCustomerMessage(ctx, ticket, message); err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// Close 以客户身份关闭自己的工单
+func (s *TicketService) Close(ctx context.Context, userID, id uint) (*model.Ticket, error) {
+	ticket, err := s.GetMine(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == model.StatusClosed {
+		return ticket, nil
+	}
+	s.setStatus(ticket, model.StatusClosed, time.Now())
+	if err := s.ticketRepo.Update(ctx, ticket); err != nil {
+		return nil, apperrors.NewInternalServerError("关闭工单失败", err)
+	}
+	return ticket, nil
+}
+
+// AdminList 按条件分页获取工单
+func (s *TicketService) AdminList(ctx context.Context, filter repository.TicketFilter, page, pageSize int) (*TicketList, error) {
+	return s.list(ctx, filter, page, pageSize)
+}
+
+// AdminGet 获取工单及其全部消息，包括内部备注
+func (s *TicketService) AdminGet(ctx context.Context, id uint) (*model.Ticket, error) {
+	return s.get(ctx, id, true)
+}
+
+// AgentReply 以客服身份回复工单或添加内部备注。首次公开回复结束首次响应计时
+func (s *TicketService) AgentReply(ctx context.Context, agentID, id uint, req *AgentReplyRequest) (*model.Ticket, error) {
+	ticket, err := s.get(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == model.StatusClosed {
+		return nil, apperrors.NewConflict("工单已关闭", nil)
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" && req.CannedReplyID != 0 {
+		reply, err := getCannedReply(ctx, s.cannedRepo, req.CannedReplyID)
+		if err != nil {
+			return nil, err
+		}
+		body = renderCannedReply(reply, ticket)
+	}
+	if body == "" {
+		return nil, apperrors.NewBadRequest("回复内容不能为空", nil)
+	}
+
+	now := time.Now()
+	message := &model.TicketMessage{
+		AuthorType: model.AuthorAgent,
+		AuthorID:   agentID,
+		Body:       body,
+		Internal:   req.Internal,
+		Channel:    model.ChannelWeb,
+	}
+	status := ticket.Status
+	if !req.Internal {
+		if ticket.FirstRespondedAt == nil {
+			ticket.FirstRespondedAt = &now
+		}
+		if ticket.AssigneeID == nil {
+			ticket.AssigneeID = &agentID
+		}
+		ticket.LastMessageAt = now
+		status = model.StatusPending
+		if req.Status != "" {
+			status = req.Status
+		}
+	}
+	previous := ticket.Status
+	s.setStatus(ticket, status, now)
+	if _, err := s.ticketRepo.AddMessage(ctx, ticket, message); err != nil {
+		return nil, apperrors.NewInternalServerError("回复工单失败", err)
+	}
+	ticket.Messages = append(ticket.Messages, message)
+
+	if !req.Internal {
+		s.publish(ctx, event.TicketReplied, ticket, body)
+		if ticket.Status == model.StatusResolved && previous != model.StatusResolved {
+			s.publish(ctx, event.TicketResolved, ticket, "")
+		}
+	}
+	return ticket, nil
+}
+
+// Update 修改工单的优先级、分类或状态，修改优先级后按新的优先级重新计算 SLA 时限
+func (s *TicketService) Update(ctx context.Context, id uint, req *UpdateTicketRequest) (*model.Ticket, error) {
+	ticket, err := s.get(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	previous := ticket.Status
+	if req.Priority != "" && req.Priority != ticket.Priority {
+		ticket.Priority = req.Priority
+		s.applySLA(ticket, now)
+	}
+	if req.Category != "" {
+		ticket.Category = req.Category
+	}
+	if req.Status != "" {
+		s.setStatus(ticket, req.Status, now)
+	}
+	if err := s.ticketRepo.Update(ctx, ticket); err != nil {
+		return nil, apperrors.NewInternalServerError("修改工单失败", err)
+	}
+	if ticket.Status == model.StatusResolved && previous != model.StatusResolved {
+		s.publish(ctx, event.TicketResolved, ticket, "")
+	}
+	return ticket, nil
+}
+
+// Assign 将工单分配给客服，assigneeID 为 0 时取消分配
+func (s *TicketService) Assign(ctx context.Context, id, assigneeID uint) (*model.Ticket, error) {
+	ticket, err := s.get(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	ticket.AssigneeID = nil
+	if assigneeID != 0 {
+		ticket.AssigneeID = &assigneeID
+	}
+	if err := s.ticketRepo.Update(ctx, ticket); err != nil {
+		return nil, apperrors.NewInternalServerError("分配工单失败", err)
+	}
+	return ticket, nil
+}
+
+func (s *TicketService) list(ctx context.Context, filter repository.TicketFilter, page, pageSize int) (*TicketList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	items, total, err := s.ticketRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取工单失败", err)
+	}
+	return &TicketList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+func (s *TicketService) get(ctx context.Context, id uint, withInternal bool) (*model.Ticket, error) {
+	ticket, err := s.ticketRepo.GetByID(ctx, id, withInternal)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("工单 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取工单失败", err)
+	}
+	return ticket, nil
+}
+
+// newTicket 创建新工单并按普通优先级设置 SLA 时限
+func (s *TicketService) newTicket(channel, subject, category string, now time.Time) (*model.Ticket, error) {
+	number, err := idgen.NewNumber(idgen.PrefixTicket)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成工单号失败", err)
+	}
+	ticket := &model.Ticket{
+		Number:        number,
+		Subject:       strings.TrimSpace(subject),
+		Category:      category,
+		Priority:      model.PriorityNormal,
+		Status:        model.StatusOpen,
+		Channel:       channel,
+		LastMessageAt: now,
+		CreatedAt:     now,
+	}
+	s.applySLA(ticket, now)
+	return ticket, nil
+}
+
+// addCustomerMessage 保存客户的回复，等待客户回复或已解决的工单重新打开。
+// 邮件重复投递时不做修改
+func (s *TicketService) addCustomerMessage(ctx context.Context, ticket *model.Ticket, message *model.TicketMessage) error {
+	now := time.Now()
+	ticket.LastMessageAt = now
+	s.setStatus(ticket, model.StatusOpen, now)
+	if _, err := s.ticketRepo.AddMessage(ctx, ticket, message); err != nil {
+		return apperrors.NewInternalServerError("回复工单失败", err)
+	}
+	ticket.Messages = append(ticket.Messages, message)
+	return nil
+}
+
+// publish 发布工单事件，事件只用于通知，发布失败只记录日志
+func (s *TicketService) publish(ctx context.Context, eventType string, ticket *model.Ticket, message string) {
+	err := s.publisher.Publish(ctx, eventType, event.EventVersion, &event.TicketEvent{
+		TicketID:     ticket.ID,
+		Number:       ticket.Number,
+		UserID:       ticket.UserID,
+		Email:        ticket.Email,
+		Name:         ticket.Name,
+		Subject:      ticket.Subject,
+		Status:       ticket.Status,
+		Message:      message,
+		ReplyAddress: s.cfg.ReplyAddress,
+	})
+	if err != nil {
+		s.log.Warn(ctx, "发布工单事件失败",
+			zap.String("event_type", eventType),
+			zap.String("ticket_number", ticket.Number),
+			zap.Error(err),
+		)
+	}
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
@@ -6,13 +6,37 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+	"github.com/yourusername/goshop/pkg/cache"
 	"github.com/yourusername/goshop/pkg/config"
+	"github.com/yourusername/goshop/pkg/database"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/graphql"
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/pkg/health"
+	"github.com/yourusername/goshop/pkg/idgen"
 	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/pkg/metrics"
+	"github.com/yourusername/goshop/pkg/rbac"
+	"github.com/yourusername/goshop/pkg/shutdown"
+	"github.com/yourusername/goshop/pkg/storage"
+	"github.com/yourusername/goshop/pkg/tracing"
+	"github.com/yourusername/goshop/pkg/validator"
+	authrpc "github.com/yourusername/goshop/services/auth/rpc"
+	"github.com/yourusername/goshop/services/user/internal/event"
+	"github.com/yourusername/goshop/services/user/internal/graph"
+	"github.com/yourusername/goshop/services/user/internal/handler"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/region"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"github.com/yourusername/goshop/services/user/internal/service"
+	"github.com/yourusername/goshop/services/user/rpc"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -20,15 +44,16 @@ import (
 const serviceName = "user"
 
 func main() {
-	// Load configuration
-	cfg, err := config.Load(serviceName, "")
+	// Load configuration and watch it for changes
+	watcher, err := config.Watch(serviceName, "")
 	if err != nil {
 		fmt.Printf("Failed to load configuration: %v\n", err)
 		os.Exit(1)
 	}
+	cfg := watcher.Current()
 
 	// Initialize logger
-	log, err := logger.New(serviceName, cfg.Service.LogLevel)
+	log, err := logger.New(serviceName, cfg.Service.LogLevel, logger.WithConfig(cfg.Log, cfg.Service.Environment))
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
@@ -42,19 +67,201 @@ func main() {
 		zap.Int("grpc_port", cfg.GRPC.Port),
 	)
 
+	// Apply configuration changes at runtime, SIGHUP forces a reload and periodic
+	// refreshes pick up rotated secrets
+	watcher.OnError(func(err error) {
+		log.Error(ctx, "Failed to reload configuration", zap.Error(err))
+	})
+	watcher.OnChange(func(old, new *config.Config) {
+		if new.Service.LogLevel == old.Service.LogLevel {
+			return
+		}
+		if err := log.SetLevel(new.Service.LogLevel); err != nil {
+			log.Warn(ctx, "Invalid log level", zap.String("level", new.Service.LogLevel), zap.Error(err))
+			return
+		}
+		log.Info(ctx, "Log level changed", zap.String("level", new.Service.LogLevel))
+	})
+	watcher.ReloadOnSignal(syscall.SIGHUP)
+	watcher.RefreshSecrets(ctx, time.Duration(cfg.Secrets.CacheTTL)*time.Second)
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Trace, serviceName, cfg.Service.Environment)
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize tracing", zap.Error(err))
+	}
+
+	// Shutdown hooks are registered as resources are created and run in phases
+	// on SIGINT or SIGTERM
+	lc := shutdown.New(log)
+	lc.Add(shutdown.PhaseFlush, "tracing", 0, shutdownTracing)
+
+	// Initialize database
+	db, err := database.Open(cfg.Database, log, database.WithAutoMigrate(
+		&model.User{},
+		&model.Address{},
+		&model.LoginHistory{},
+		&model.PasswordHistory{},
+		&model.SocialAccount{},
+		&model.UserPreference{},
+		&model.UserTag{},
+		&model.Segment{},
+		&model.UserOrder{},
+		&model.UserOrderRefund{},
+		&events.OutboxMessage{},
+	))
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize database", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseClose, "postgres", 0, shutdown.Close(func() error { return database.Close(db) }))
+
+	// Initialize Redis, used to cache users and to track failed logins
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.RedisAddr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	lc.Add(shutdown.PhaseClose, "redis", 0, shutdown.Close(rdb.Close))
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		log.Fatal(ctx, "Failed to connect to Redis", zap.Error(err))
+	}
+
+	// Lease a worker ID unique across all services for the generated IDs
+	releaseWorkerID, err := idgen.LeaseWorkerID(ctx, rdb, log)
+	if err != nil {
+		log.Fatal(ctx, "Failed to lease worker ID", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseFlush, "worker-id", 0, shutdown.Func(releaseWorkerID))
+
+	// Initialize NATS connection
+	nc, err := nats.Connect(cfg.NATS.URL, nats.Name(serviceName))
+	if err != nil {
+		log.Fatal(ctx, "Failed to connect to NATS", zap.Error(err))
+	}
+	lc.Add(shutdown.PhaseFlush, "nats", 0, shutdown.Close(nc.Drain))
+
+	// User events are recorded in the outbox with the changes they describe
+	// and published to the USERS stream by the relay
+	js, err := nc.JetStream()
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize JetStream", zap.Error(err))
+	}
+	if err := event.EnsureStream(js, time.Duration(cfg.User.EventStreamMaxAge)*time.Hour); err != nil {
+		log.Fatal(ctx, "Failed to create user event stream", zap.Error(err))
+	}
+	if err := events.EnsureDeadLetterStream(js, 30*24*time.Hour); err != nil {
+		log.Fatal(ctx, "Failed to create dead-letter stream", zap.Error(err))
+	}
+	outbox := events.NewOutbox(serviceName)
+	relayCtx, stopRelay := context.WithCancel(ctx)
+	lc.Add(shutdown.PhaseFlush, "outbox-relay", 0, shutdown.Func(stopRelay))
+	go events.NewRelay(db, events.NewPublisher(js, serviceName), events.RelayConfig{}, log).Run(relayCtx)
+
+	// Initialize gRPC clients
+	clients := grpcclient.NewFactory(cfg.GRPC, log)
+	lc.Add(shutdown.PhaseClose, "grpc-clients", 0, shutdown.Func(clients.Close))
+	authConn, err := clients.Conn("auth")
+	if err != nil {
+		log.Fatal(ctx, "Failed to create auth client", zap.Error(err))
+	}
+
+	// Load the region dataset used to validate addresses
+	regions, err := region.Load(cfg.User.RegionsFile)
+	if err != nil {
+		log.Fatal(ctx, "Failed to load region dataset", zap.Error(err))
+	}
+
+	// Initialize object storage for uploaded avatars
+	store, err := storage.New(ctx, cfg.Storage)
+	if err != nil {
+		log.Fatal(ctx, "Failed to initialize object storage", zap.Error(err))
+	}
+
+	// Initialize metrics
+	m := metrics.New(serviceName)
+	if err := m.RegisterDB(db, cfg.Database.DBName); err != nil {
+		log.Warn(ctx, "Failed to register database metrics", zap.Error(err))
+	}
+	if err := m.RegisterRedis(rdb); err != nil {
+		log.Warn(ctx, "Failed to register Redis metrics", zap.Error(err))
+	}
+
+	// Initialize repositories and services, user lookups are cached in Redis
+	userRepo := repository.NewUserRepository(db)
+	addressRepo := repository.NewAddressRepository(db)
+	if cfg.User.CacheTTL > 0 {
+		userCache := repository.NewUserCache(cache.New(rdb, serviceName), time.Duration(cfg.User.CacheTTL)*time.Second, m, log)
+		userRepo = repository.NewCachedUserRepository(userRepo, userCache)
+		addressRepo = repository.NewCachedAddressRepository(addressRepo, userCache)
+	}
+	userService := service.NewUserService(userRepo, outbox, cfg.User.PasswordPolicy)
+	authClient := authrpc.NewClient(authConn)
+	enforcer := rbac.New(authClient, rdb, time.Duration(cfg.Auth.PermissionCacheTTL)*time.Second, log)
+	lockoutService := service.NewLockoutService(rdb, userRepo, authClient, outbox, cfg.User.Lockout, log)
+	loginService := service.NewLoginService(userRepo, authClient, lockoutService, log)
+	passwordService := service.NewPasswordService(userRepo, authClient, lockoutService, cfg.User.PasswordPolicy, log)
+	verificationService := service.NewVerificationService(userRepo, authClient, outbox, cfg.User, log)
+	addressService := service.NewAddressService(addressRepo, regions, cfg.User.MaxAddresses)
+	preferenceService := service.NewPreferenceService(repository.NewPreferenceRepository(db), addressRepo, outbox, cfg.I18n, cfg.Currency, log)
+	oauthService := service.NewOAuthService(userRepo, repository.NewSocialAccountRepository(db), loginService, outbox, cfg.User.OAuth, log)
+	avatarService := service.NewAvatarService(userRepo, store, outbox, cfg.User, log)
+	segmentService := service.NewSegmentService(repository.NewTagRepository(db), repository.NewSegmentRepository(db), userRepo, log)
+
+	// Consume order events, customer spend and recency feed the segments
+	consumer := events.NewConsumer(js, events.ConsumerConfig{
+		Durable:      serviceName,
+		StreamMaxAge: time.Duration(cfg.NATS.StreamMaxAge) * time.Hour,
+	}, log)
+	lc.Add(shutdown.PhaseFlush, "consumer", 0, shutdown.Func(consumer.Close))
+	if err := segmentService.Subscribe(consumer); err != nil {
+		log.Fatal(ctx, "Failed to subscribe to order events", zap.Error(err))
+	}
+
+	// Initialize health checks
+	h := health.New()
+	lc.Add(shutdown.PhaseTraffic, "health", 0, shutdown.Func(h.Shutdown))
+	h.Add("postgres", health.Database(db))
+	h.Add("redis", health.Redis(rdb))
+	h.Add("nats", health.NATS(nc))
+
 	// Initialize HTTP server
 	router := gin.Default()
+	router.Use(tracing.GinMiddleware(), m.GinMiddleware())
+	router.Use(apperrors.Middleware(log, cfg.Service.Environment, apperrors.WithTranslator(validator.ErrorTranslator)))
+	m.Register(router)
+	h.Register(router)
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.HTTP.Port),
 		Handler: router,
 	}
+	lc.Add(shutdown.PhaseServers, "http", 0, shutdown.HTTPServer(httpServer))
 
 	// Register HTTP routes
-	setupHTTPRoutes(router)
+	setupHTTPRoutes(router,
+		handler.NewUserHandler(userService, loginService, verificationService, lockoutService),
+		handler.NewPasswordHandler(passwordService),
+		handler.NewOAuthHandler(oauthService),
+		handler.NewAddressHandler(addressService),
+		handler.NewPreferenceHandler(preferenceService),
+		handler.NewAvatarHandler(avatarService),
+		handler.NewAdminHandler(segmentService, lockoutService),
+		enforcer,
+	)
+
+	// Serve users as entities of the gateway's GraphQL graph
+	schema, err := graph.NewSchema(userService)
+	if err != nil {
+		log.Fatal(ctx, "Failed to build GraphQL schema", zap.Error(err))
+	}
+	router.GET(cfg.GraphQL.Path, graphql.Handler(schema))
+	router.POST(cfg.GraphQL.Path, graphql.Handler(schema))
 
 	// Initialize gRPC server
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(grpcclient.UnaryServerInterceptor(), tracing.UnaryServerInterceptor(), m.UnaryServerInterceptor(), apperrors.UnaryServerInterceptor()))
 	// Register gRPC services
+	h.RegisterGRPC(grpcServer)
+	rpc.RegisterUserServer(grpcServer, handler.NewGRPCHandler(userService, addressService, segmentService))
+	lc.Add(shutdown.PhaseServers, "grpc", 0, shutdown.GRPCServer(grpcServer))
 
 	// Start HTTP server
 	go func() {
@@ -76,71 +283,28 @@ func main() {
 		}
 	}()
 
-	// Wait for interrupt signal to gracefully shutdown the server
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Info(ctx, "Received shutdown signal")
-
-	// Gracefully shutdown servers
-	log.Info(ctx, "Shutting down servers")
-	grpcServer.GracefulStop()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := httpServer.Shutdown(ctx); err != nil {
-		log.Fatal(ctx, "Server forced to shutdown", zap.Error(err))
-	}
-
-	log.Info(ctx, "Server has been shutdown successfully")
+	// Wait for interrupt signal to gracefully shutdown the service, failed hooks
+	// are logged by the coordinator
+	lc.Wait()
 }
 
 // Setup HTTP routes
-func setupHTTPRoutes(router *gin.Engine) {
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "UP",
-		})
-	})
-
+func setupHTTPRoutes(router *gin.Engine, userHandler *handler.UserHandler, passwordHandler *handler.PasswordHandler, oauthHandler *handler.OAuthHandler, addressHandler *handler.AddressHandler, preferenceHandler *handler.PreferenceHandler, avatarHandler *handler.AvatarHandler, adminHandler *handler.AdminHandler, enforcer *rbac.Enforcer) {
 	api := router.Group("/api/v1")
 	{
 		users := api.Group("/users")
+		userHandler.RegisterRoutes(users)
+		passwordHandler.RegisterRoutes(users)
+		oauthHandler.RegisterRoutes(users)
+		addressHandler.RegisterRoutes(users)
+		preferenceHandler.RegisterRoutes(users)
+		avatarHandler.RegisterRoutes(users)
+		adminHandler.RegisterRoutes(api, enforcer.RequirePermission("users.manage"))
 		{
-			users.POST("/register", func(c *gin.Context) {
-				// Not implemented yet
-				c.JSON(http.StatusOK, gin.H{"message": "Not implemented"})
-			})
-
-			users.POST("/login", func(c *gin.Context) {
-				// Not implemented yet
-				c.JSON(http.StatusOK, gin.H{"message": "Not implemented"})
-			})
-
 			users.POST("/reset-password", func(c *gin.Context) {
 				// Not implemented yet
 				c.JSON(http.StatusOK, gin.H{"message": "Not implemented"})
 			})
-
-			users.GET("/me", func(c *gin.Context) {
-				// Not implemented yet
-				c.JSON(http.StatusOK, gin.H{"message": "Not implemented"})
-			})
-
-			users.PUT("/me", func(c *gin.Context) {
-				// Not implemented yet
-				c.JSON(http.StatusOK, gin.H{"message": "Not implemented"})
-			})
-
-			users.GET("/me/addresses", func(c *gin.Context) {
-				// Not implemented yet
-				c.JSON(http.StatusOK, gin.H{"message": "Not implemented"})
-			})
-
-			users.POST("/me/addresses", func(c *gin.Context) {
-				// Not implemented yet
-				c.JSON(http.StatusOK, gin.H{"message": "Not implemented"})
-			})
 		}
 	}
 }
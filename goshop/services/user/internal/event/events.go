@@ -0,0 +1,107 @@
+package event
+
+import (
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/yourusername/goshop/pkg/events"
+)
+
+// 用户服务通过发件箱发布的通知事件类型，通知服务据此向用户发送账号相关的邮件，并按用户偏好发送营销邮件和推送
+const (
+	EmailVerificationRequested = "user.email_verification_requested"
+	PreferencesUpdated         = "user.preferences_updated"
+	AccountLocked              = "user.account_locked"
+)
+
+// 用户领域事件，通过事件总线的发件箱与描述的变更在同一事务中记录。
+// 营销服务据此发放新人礼包，通知服务同步联系方式，分析服务统计用户增长，都不需要调用用户服务
+const (
+	UserRegistered    = "user.registered"
+	UserUpdated       = "user.updated"
+	UserEmailVerified = "user.email_verified"
+	UserDeleted       = "user.deleted"
+)
+
+// DomainEventVersion 是用户服务事件数据的版本，数据不兼容地变更时递增
+const DomainEventVersion = 1
+
+// StreamName 是保存用户服务事件的 JetStream 流
+const StreamName = "USERS"
+
+// EnsureStream 创建或更新保存 user.> 事件的流，订阅者的持久消费者在服务重启期间也不会丢失事件
+func EnsureStream(js nats.JetStreamContext, maxAge time.Duration) error {
+	return events.EnsureStream(js, events.StreamConfig{
+		Name:     StreamName,
+		Subjects: []string{"user.>"},
+		MaxAge:   maxAge,
+	})
+}
+
+// UserRegisteredEvent 是 user.registered 事件的数据，通过社交账号注册时 Provider 为服务商
+type UserRegisteredEvent struct {
+	UserID        uint      `json:"user_id"`
+	Email         string    `json:"email"`
+	Phone         string    `json:"phone,omitempty"`
+	Username      string    `json:"username"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	EmailVerified bool      `json:"email_verified"`
+	Provider      string    `json:"provider,omitempty"`
+	RegisteredAt  time.Time `json:"registered_at"`
+}
+
+// UserUpdatedEvent 是 user.updated 事件的数据，包含更新后的用户资料，Fields 是变更的字段
+type UserUpdatedEvent struct {
+	UserID    uint     `json:"user_id"`
+	Email     string   `json:"email"`
+	Phone     string   `json:"phone,omitempty"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Avatar    string   `json:"avatar,omitempty"`
+	Birthday  string   `json:"birthday,omitempty"` // 2006-01-02
+	Fields    []string `json:"fields"`
+}
+
+// UserEmailVerifiedEvent 是 user.email_verified 事件的数据
+type UserEmailVerifiedEvent struct {
+	UserID     uint      `json:"user_id"`
+	Email      string    `json:"email"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// UserDeletedEvent 是 user.deleted 事件的数据，订阅者应删除或匿名化保存的用户个人信息
+type UserDeletedEvent struct {
+	UserID    uint      `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// EmailVerificationRequestedEvent 是 user.email_verification_requested 事件的数据，
+// VerifyURL 是包含验证令牌的链接，只能使用一次
+type EmailVerificationRequestedEvent struct {
+	UserID    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	VerifyURL string    `json:"verify_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AccountLockedEvent 是 user.account_locked 事件的数据，账号因多次密码错误被暂时锁定，
+// UnlockURL 是包含解锁令牌的链接，只能使用一次
+type AccountLockedEvent struct {
+	UserID      uint      `json:"user_id"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	IP          string    `json:"ip"` // 最后一次密码错误的客户端 IP
+	UnlockURL   string    `json:"unlock_url"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// PreferencesUpdatedEvent 是 user.preferences_updated 事件的数据，包含更新后的全部偏好
+type PreferencesUpdatedEvent struct {
+	UserID            uint   `json:"user_id"`
+	Locale            string `json:"locale"`
+	Currency          string `json:"currency"`
+	MarketingEmails   bool   `json:"marketing_emails"`
+	PushNotifications bool   `json:"push_notifications"`
+}
@@ -0,0 +1,25 @@
+package event
+
+import "time"
+
+// 用户服务订阅的订单事件类型，用于统计用户的消费金额、订单数和最近下单时间
+const (
+	OrderCompleted = "order.completed"
+	OrderRefunded  = "order.refunded"
+)
+
+// OrderEvent 是 order.completed 事件中用户服务使用的数据
+type OrderEvent struct {
+	OrderID    uint      `json:"order_id"`
+	UserID     uint      `json:"user_id"`
+	GrandTotal float64   `json:"grand_total"`
+	PlacedAt   time.Time `json:"placed_at"`
+}
+
+// OrderRefundEvent 是 order.refunded 事件中用户服务使用的数据，部分退款时 RefundAmount 小于 GrandTotal
+type OrderRefundEvent struct {
+	OrderID      uint    `json:"order_id"`
+	UserID       uint    `json:"user_id"`
+	RefundID     string  `json:"refund_id"`
+	RefundAmount float64 `json:"refund_amount"`
+}
@@ -0,0 +1,80 @@
+// Package graph 将用户作为联邦 GraphQL 子图的实体提供给网关，其他子图可以扩展 User 类型
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/yourusername/goshop/pkg/graphql"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/service"
+)
+
+// NewSchema 创建用户子图。联系方式只对用户本人可见，其他用户只能看到公开资料
+func NewSchema(userService *service.UserService) (*graphql.Schema, error) {
+	private := func(name string) graphql.ResolveFunc {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			user := p.Source.(*model.User)
+			if user.ID != currentUserID(p.Context) {
+				return nil, nil
+			}
+			return graphql.DefaultResolver(user, name)
+		}
+	}
+
+	user := &graphql.Object{
+		Name: "User",
+		Key:  "id",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NonNullOf(graphql.ID)},
+			{Name: "username", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "firstName", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "lastName", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "fullName", Type: graphql.NonNullOf(graphql.String)},
+			{Name: "avatar", Type: graphql.String},
+			{Name: "memberLevel", Type: graphql.NonNullOf(graphql.Int)},
+			{Name: "email", Type: graphql.String, Resolve: private("email")},
+			{Name: "phone", Type: graphql.String, Resolve: private("phone")},
+			{Name: "points", Type: graphql.Int, Resolve: private("points")},
+			{Name: "createdAt", Type: graphql.NonNullOf(graphql.String)},
+		},
+		ResolveEntities: func(ctx context.Context, representations []map[string]interface{}) ([]interface{}, error) {
+			users, err := userService.GetUsers(ctx, graphql.RepresentationIDs(representations, "id"))
+			if err != nil {
+				return nil, err
+			}
+			result := make([]interface{}, len(users))
+			for i, user := range users {
+				result[i] = user
+			}
+			return result, nil
+		},
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{
+				Name: "me",
+				Type: user,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := currentUserID(p.Context)
+					if userID == 0 {
+						return nil, nil
+					}
+					return userService.GetUser(p.Context, userID)
+				},
+			},
+		},
+	}
+	return graphql.NewSubgraph(query, user)
+}
+
+// currentUserID 返回网关认证后通过 X-User-ID 转发的用户 ID，未登录时为 0
+func currentUserID(ctx context.Context) uint {
+	id, err := strconv.ParseUint(graphql.Header(ctx).Get("X-User-ID"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/user/internal/service"
+)
+
+// AddressHandler 处理当前用户收货地址的 HTTP 请求
+type AddressHandler struct {
+	addressService *service.AddressService
+}
+
+// NewAddressHandler 创建收货地址处理器
+func NewAddressHandler(addressService *service.AddressService) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+	}
+}
+
+// RegisterRoutes 注册收货地址路由
+func (h *AddressHandler) RegisterRoutes(users *gin.RouterGroup) {
+	addresses := users.Group("/me/addresses")
+	addresses.GET("", h.List)
+	addresses.POST("", h.Create)
+	addresses.PUT("/:id", h.Update)
+	addresses.DELETE("/:id", h.Delete)
+	addresses.PUT("/:id/default", h.SetDefault)
+}
+
+// List 获取当前用户的收货地址
+func (h *AddressHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	addresses, err := h.addressService.List(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": addresses})
+}
+
+// Create 添加收货地址
+func (h *AddressHandler) Create(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.AddressRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	address, err := h.addressService.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": address})
+}
+
+// Update 更新收货地址
+func (h *AddressHandler) Update(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.AddressRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	address, err := h.addressService.Update(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": address})
+}
+
+// Delete 删除收货地址
+func (h *AddressHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.addressService.Delete(c.Request.Context(), userID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SetDefault 将收货地址设为默认地址，其他地址同时取消默认
+func (h *AddressHandler) SetDefault(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	address, err := h.addressService.SetDefault(c.Request.Context(), userID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": address})
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/user/internal/service"
+)
+
+// AdminHandler 处理后台客户标签、客户分群和账号锁定的 HTTP 请求
+type AdminHandler struct {
+	segmentService *service.SegmentService
+	lockoutService *service.LockoutService
+}
+
+// NewAdminHandler 创建后台客户处理器
+func NewAdminHandler(segmentService *service.SegmentService, lockoutService *service.LockoutService) *AdminHandler {
+	return &AdminHandler{
+		segmentService: segmentService,
+		lockoutService: lockoutService,
+	}
+}
+
+// RegisterRoutes 注册后台客户路由，middleware 在所有后台路由之前执行，用于检查权限
+func (h *AdminHandler) RegisterRoutes(api *gin.RouterGroup, middleware ...gin.HandlerFunc) {
+	admin := api.Group("/users/admin", middleware...)
+	{
+		admin.GET("/tags", h.TagSummary)
+		admin.GET("/users/:id/tags", h.ListUserTags)
+		admin.PUT("/users/:id/tags/:tag", h.AddTag)
+		admin.DELETE("/users/:id/tags/:tag", h.RemoveTag)
+		admin.GET("/users/:id/lock", h.LockStatus)
+		admin.DELETE("/users/:id/lock", h.Unlock)
+
+		admin.GET("/segments", h.ListSegments)
+		admin.POST("/segments", h.CreateSegment)
+		admin.GET("/segments/:id", h.GetSegment)
+		admin.PUT("/segments/:id", h.UpdateSegment)
+		admin.DELETE("/segments/:id", h.DeleteSegment)
+		admin.GET("/segments/:id/members", h.ListMembers)
+	}
+}
+
+// TagSummary 获取使用中的标签及各标签的客户数
+func (h *AdminHandler) TagSummary(c *gin.Context) {
+	counts, err := h.segmentService.TagSummary(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": counts})
+}
+
+// ListUserTags 获取客户的标签
+func (h *AdminHandler) ListUserTags(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	tags, err := h.segmentService.ListUserTags(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// AddTag 给客户打标签，如 vip、wholesale、at_risk
+func (h *AdminHandler) AddTag(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	tags, err := h.segmentService.AddTag(c.Request.Context(), userID, c.Param("tag"), adminID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// RemoveTag 删除客户的标签
+func (h *AdminHandler) RemoveTag(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.segmentService.RemoveTag(c.Request.Context(), userID, c.Param("tag")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// LockStatus 获取客户账号的锁定状态和连续登录失败次数
+func (h *AdminHandler) LockStatus(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	status, err := h.lockoutService.Status(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+// Unlock 解除客户账号因多次登录失败的锁定
+func (h *AdminHandler) Unlock(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.lockoutService.AdminUnlock(c.Request.Context(), userID, adminID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListSegments 获取全部客户分群
+func (h *AdminHandler) ListSegments(c *gin.Context) {
+	segments, err := h.segmentService.List(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": segments})
+}
+
+// CreateSegment 创建客户分群
+func (h *AdminHandler) CreateSegment(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.SegmentRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	segment, err := h.segmentService.Create(c.Request.Context(), &req, adminID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": segment})
+}
+
+// GetSegment 获取客户分群及当前符合规则的客户数
+func (h *AdminHandler) GetSegment(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	segment, err := h.segmentService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": segment})
+}
+
+// UpdateSegment 更新客户分群
+func (h *AdminHandler) UpdateSegment(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.SegmentRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	segment, err := h.segmentService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": segment})
+}
+
+// DeleteSegment 删除客户分群
+func (h *AdminHandler) DeleteSegment(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.segmentService.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListMembers 按 ID 顺序分页获取分群的客户，查询参数：after_id、limit
+func (h *AdminHandler) ListMembers(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	users, err := h.segmentService.ListMembers(c.Request.Context(), id, parseUintQuery(c, "after_id"), parseIntQuery(c, "limit", 100))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": users})
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/user/internal/service"
+)
+
+// multipartOverhead 是 multipart 请求中除头像文件外的边界和头部的大小上限
+const multipartOverhead = 64 << 10
+
+// AvatarHandler 处理当前用户头像的 HTTP 请求
+type AvatarHandler struct {
+	avatarService *service.AvatarService
+}
+
+// NewAvatarHandler 创建头像处理器
+func NewAvatarHandler(avatarService *service.AvatarService) *AvatarHandler {
+	return &AvatarHandler{
+		avatarService: avatarService,
+	}
+}
+
+// RegisterRoutes 注册头像路由
+func (h *AvatarHandler) RegisterRoutes(users *gin.RouterGroup) {
+	users.POST("/me/avatar", h.Upload)
+}
+
+// Upload 上传当前用户的头像，文件在 multipart 表单的 avatar 字段中
+func (h *AvatarHandler) Upload(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.avatarService.MaxSize()+multipartOverhead)
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, apperrors.NewBadRequest("头像文件过大", err))
+			return
+		}
+		respondError(c, apperrors.NewBadRequest("缺少头像文件", err))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, apperrors.NewBadRequest("读取头像失败", err))
+		return
+	}
+	defer file.Close()
+
+	user, err := h.avatarService.Upload(c.Request.Context(), userID, file, header.Size)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/service"
+	"github.com/yourusername/goshop/services/user/rpc"
+)
+
+// GRPCHandler 实现用户服务的 gRPC 接口，网关将用户查询接口转码为 gRPC 调用，
+// 订单、支付和营销等服务通过 rpc.Client 获取用户、收货地址和客户分群
+type GRPCHandler struct {
+	userService    *service.UserService
+	addressService *service.AddressService
+	segmentService *service.SegmentService
+}
+
+// NewGRPCHandler 创建 gRPC 处理器
+func NewGRPCHandler(userService *service.UserService, addressService *service.AddressService, segmentService *service.SegmentService) *GRPCHandler {
+	return &GRPCHandler{
+		userService:    userService,
+		addressService: addressService,
+		segmentService: segmentService,
+	}
+}
+
+// GetUser 获取用户信息
+func (h *GRPCHandler) GetUser(ctx context.Context, req *rpc.GetUserRequest) (*model.User, error) {
+	return h.userService.GetUser(ctx, req.ID)
+}
+
+// GetUserByEmail 按邮箱获取用户
+func (h *GRPCHandler) GetUserByEmail(ctx context.Context, req *rpc.GetUserByEmailRequest) (*model.User, error) {
+	return h.userService.GetUserByEmail(ctx, req.Email)
+}
+
+// ValidateUser 检查用户是否存在且处于正常状态，不通过时在结果中返回原因
+func (h *GRPCHandler) ValidateUser(ctx context.Context, req *rpc.ValidateUserRequest) (*rpc.ValidateUserReply, error) {
+	reply := &rpc.ValidateUserReply{ID: req.ID}
+	user, err := h.userService.GetUser(ctx, req.ID)
+	if err != nil {
+		if apperrors.HTTPStatus(err) == http.StatusNotFound {
+			reply.Reason = rpc.InvalidNotFound
+			return reply, nil
+		}
+		return nil, err
+	}
+	reply.Name = user.DisplayName()
+	reply.Status = user.Status
+	reply.Role = user.Role
+	reply.MemberLevel = user.MemberLevel
+	switch {
+	case user.Status != "active":
+		reply.Reason = rpc.InvalidInactive
+	case req.RequireVerifiedEmail && !user.EmailVerified:
+		reply.Reason = rpc.InvalidEmailUnverified
+	default:
+		reply.Valid = true
+	}
+	return reply, nil
+}
+
+// GetAddresses 获取用户的收货地址，默认地址排在最前
+func (h *GRPCHandler) GetAddresses(ctx context.Context, req *rpc.GetAddressesRequest) (*rpc.AddressesReply, error) {
+	if req.UserID == 0 {
+		return nil, apperrors.NewBadRequest("缺少用户", nil)
+	}
+	addresses, err := h.addressService.List(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.AddressesReply{Addresses: addresses}, nil
+}
+
+// GetSegments 获取客户的标签和所属的分群
+func (h *GRPCHandler) GetSegments(ctx context.Context, req *rpc.GetSegmentsRequest) (*rpc.SegmentsReply, error) {
+	if req.UserID == 0 {
+		return nil, apperrors.NewBadRequest("缺少用户", nil)
+	}
+	tags, err := h.segmentService.ListUserTags(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := h.segmentService.UserSegments(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	reply := &rpc.SegmentsReply{UserID: req.UserID, Tags: make([]string, len(tags)), Segments: segments}
+	for i, tag := range tags {
+		reply.Tags[i] = tag.Tag
+	}
+	return reply, nil
+}
+
+// ListSegment 按 ID 顺序分页获取分群的客户
+func (h *GRPCHandler) ListSegment(ctx context.Context, req *rpc.ListSegmentRequest) (*rpc.SegmentMembersReply, error) {
+	users, err := h.segmentService.ListMembers(ctx, req.SegmentID, req.AfterID, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.SegmentMembersReply{Users: users}, nil
+}
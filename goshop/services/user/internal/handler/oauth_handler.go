@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/user/internal/service"
+)
+
+// 保存授权请求 nonce 的 Cookie，只在回调路径上发送
+const (
+	oauthNonceCookie = "oauth_nonce"
+	oauthCookiePath  = "/api/v1/users/oauth"
+)
+
+// OAuthHandler 处理社交账号登录的 HTTP 请求
+type OAuthHandler struct {
+	oauthService *service.OAuthService
+}
+
+// NewOAuthHandler 创建社交账号登录处理器
+func NewOAuthHandler(oauthService *service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+	}
+}
+
+// RegisterRoutes 注册社交账号登录路由
+func (h *OAuthHandler) RegisterRoutes(users *gin.RouterGroup) {
+	users.GET("/oauth/:provider/authorize", h.Authorize)
+	users.GET("/oauth/:provider/callback", h.Callback)
+}
+
+// Authorize 返回服务商的授权地址，客户端跳转到该地址完成授权。
+// 已登录用户发起授权时，授权完成后社交账号关联到该用户
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	userID, _ := strconv.ParseUint(c.GetHeader("X-User-ID"), 10, 64)
+	auth, err := h.oauthService.Authorize(c.Request.Context(), c.Param("provider"), uint(userID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	maxAge := int(time.Until(auth.ExpiresAt).Seconds())
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthNonceCookie, auth.Nonce, maxAge, oauthCookiePath, "", secureRequest(c), true)
+	c.JSON(http.StatusOK, gin.H{"data": auth})
+}
+
+// Callback 处理服务商的授权回调，返回与密码登录相同的访问令牌和刷新令牌
+// 查询参数：code、state，用户拒绝授权时服务商返回 error
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	nonce, _ := c.Cookie(oauthNonceCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthNonceCookie, "", -1, oauthCookiePath, "", secureRequest(c), true)
+	if c.Query("error") != "" {
+		respondError(c, apperrors.NewBadRequest("已取消授权", nil))
+		return
+	}
+	result, err := h.oauthService.Callback(c.Request.Context(), c.Param("provider"), c.Query("code"), c.Query("state"), nonce, &service.LoginClient{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// secureRequest 判断客户端是否通过 HTTPS 访问，网关终止 TLS 时按 X-Forwarded-Proto 判断
+func secureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
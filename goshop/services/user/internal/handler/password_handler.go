@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/user/internal/service"
+)
+
+// PasswordHandler 处理当前用户修改密码的 HTTP 请求
+type PasswordHandler struct {
+	passwordService *service.PasswordService
+}
+
+// NewPasswordHandler 创建密码处理器
+func NewPasswordHandler(passwordService *service.PasswordService) *PasswordHandler {
+	return &PasswordHandler{
+		passwordService: passwordService,
+	}
+}
+
+// RegisterRoutes 注册密码路由
+func (h *PasswordHandler) RegisterRoutes(users *gin.RouterGroup) {
+	users.PUT("/me/password", h.Change)
+}
+
+// Change 修改当前用户的密码，返回为当前客户端签发的新令牌，其他设备需要重新登录
+func (h *PasswordHandler) Change(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.ChangePasswordRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	result, err := h.passwordService.Change(c.Request.Context(), userID, &req, &service.LoginClient{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
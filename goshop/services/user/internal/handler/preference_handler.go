@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/user/internal/service"
+)
+
+// PreferenceHandler 处理当前用户偏好的 HTTP 请求
+type PreferenceHandler struct {
+	preferenceService *service.PreferenceService
+}
+
+// NewPreferenceHandler 创建用户偏好处理器
+func NewPreferenceHandler(preferenceService *service.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{
+		preferenceService: preferenceService,
+	}
+}
+
+// RegisterRoutes 注册用户偏好路由
+func (h *PreferenceHandler) RegisterRoutes(users *gin.RouterGroup) {
+	users.GET("/me/preferences", h.Get)
+	users.PATCH("/me/preferences", h.Update)
+}
+
+// Get 获取当前用户的偏好
+func (h *PreferenceHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	preference, err := h.preferenceService.Get(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": preference})
+}
+
+// Update 更新当前用户的偏好，只修改请求中提供的字段
+func (h *PreferenceHandler) Update(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.UpdatePreferencesRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	preference, err := h.preferenceService.Update(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": preference})
+}
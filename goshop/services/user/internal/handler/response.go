@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+)
+
+// respondError 中止请求，由错误中间件将错误转换为统一的 JSON 响应
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// parseIDParam 解析路径中的 ID 参数
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		respondError(c, apperrors.NewBadRequest("无效的 "+name, err))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseIntQuery 解析整数查询参数，未提供或格式错误时返回默认值
+func parseIntQuery(c *gin.Context, name string, def int) int {
+	v, err := strconv.Atoi(c.Query(name))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// parseUintQuery 解析可选的 ID 查询参数，未提供或格式错误时返回 0
+func parseUintQuery(c *gin.Context, name string) uint {
+	v, err := strconv.ParseUint(c.Query(name), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(v)
+}
+
+// currentUserID 获取当前登录用户 ID，由网关认证后通过 X-User-ID 请求头传递
+func currentUserID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.GetHeader("X-User-ID"), 10, 64)
+	if err != nil || id == 0 {
+		respondError(c, apperrors.NewUnauthorized("未登录", err))
+		return 0, false
+	}
+	return uint(id), true
+}
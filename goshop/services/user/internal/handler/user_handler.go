@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/user/internal/service"
+)
+
+// UserHandler 处理用户相关的 HTTP 请求
+type UserHandler struct {
+	userService         *service.UserService
+	loginService        *service.LoginService
+	verificationService *service.VerificationService
+	lockoutService      *service.LockoutService
+}
+
+// NewUserHandler 创建用户处理器
+func NewUserHandler(userService *service.UserService, loginService *service.LoginService, verificationService *service.VerificationService, lockoutService *service.LockoutService) *UserHandler {
+	return &UserHandler{
+		userService:         userService,
+		loginService:        loginService,
+		verificationService: verificationService,
+		lockoutService:      lockoutService,
+	}
+}
+
+// RegisterRoutes 注册用户路由
+func (h *UserHandler) RegisterRoutes(users *gin.RouterGroup) {
+	users.POST("/register", h.Register)
+	users.POST("/login", h.Login)
+	users.GET("/verify-email", h.VerifyEmail)
+	users.GET("/unlock", h.Unlock)
+	users.POST("/me/verification-email", h.SendVerificationEmail)
+	users.GET("/me", h.GetProfile)
+	users.PUT("/me", h.UpdateProfile)
+	users.DELETE("/me", h.Delete)
+	users.GET("/celebrants", h.ListCelebrants)
+}
+
+// Register 注册用户并发送邮箱验证链接，返回的用户资料不包含密码
+func (h *UserHandler) Register(c *gin.Context) {
+	var req service.RegisterRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	user, err := h.userService.Register(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	h.verificationService.SendAfterRegister(c.Request.Context(), user)
+	c.JSON(http.StatusCreated, gin.H{"data": user})
+}
+
+// Login 使用邮箱或用户名和密码登录，返回访问令牌、刷新令牌及其过期时间
+func (h *UserHandler) Login(c *gin.Context) {
+	var req service.LoginRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	result, err := h.loginService.Login(c.Request.Context(), &req, &service.LoginClient{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// VerifyEmail 使用验证邮件中的链接验证邮箱，查询参数 token 是验证令牌
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	user, err := h.verificationService.VerifyEmail(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// Unlock 使用解锁邮件中的链接解除账号的锁定，查询参数 token 是解锁令牌
+func (h *UserHandler) Unlock(c *gin.Context) {
+	user, err := h.lockoutService.Unlock(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// SendVerificationEmail 重新向当前用户发送邮箱验证链接
+func (h *UserHandler) SendVerificationEmail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.verificationService.SendEmailVerification(c.Request.Context(), userID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// GetProfile 获取当前用户的资料
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	user, err := h.userService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// UpdateProfile 更新当前用户的资料，只修改请求中提供的字段
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.UpdateProfileRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	user, err := h.userService.UpdateProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// Delete 注销当前用户
+func (h *UserHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.userService.Delete(c.Request.Context(), userID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListCelebrants 按生日或注册周年分页获取用户
+// 查询参数：type=birthday|anniversary、month、day、before（2006-01-02，仅 anniversary）、after_id、limit
+func (h *UserHandler) ListCelebrants(c *gin.Context) {
+	month, _ := strconv.Atoi(c.Query("month"))
+	day, _ := strconv.Atoi(c.Query("day"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	q := &service.CelebrantQuery{
+		Type:  c.Query("type"),
+		Month: month,
+		Day:   day,
+		Limit: limit,
+	}
+	if raw := c.Query("after_id"); raw != "" {
+		afterID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			respondError(c, apperrors.NewBadRequest("无效的 after_id", err))
+			return
+		}
+		q.AfterID = uint(afterID)
+	}
+	if raw := c.Query("before"); raw != "" {
+		before, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+		if err != nil {
+			respondError(c, apperrors.NewBadRequest("无效的 before", err))
+			return
+		}
+		q.Before = before
+	}
+
+	users, err := h.userService.ListCelebrants(c.Request.Context(), q)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": users})
+}
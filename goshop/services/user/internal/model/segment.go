@@ -0,0 +1,79 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 常用的客户标签，运营也可以使用其他标签
+const (
+	TagVIP       = "vip"       // 重要客户
+	TagWholesale = "wholesale" // 批发客户
+	TagAtRisk    = "at_risk"   // 有流失风险的客户
+)
+
+// UserTag 表示运营给客户打的标签，标签是小写字母、数字和下划线组成的名称
+type UserTag struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Tag       string    `json:"tag" gorm:"primaryKey;size:30;index"`
+	CreatedBy uint      `json:"created_by"` // 打标签的后台用户
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SegmentRules 是客户分群的规则，设置了的条件必须全部满足。
+// 消费金额为已完成订单的实付金额减去退款金额，按基础货币计
+type SegmentRules struct {
+	Tags              []string `json:"tags,omitempty"`                // 拥有其中任一标签
+	MinMemberLevel    *int     `json:"min_member_level,omitempty"`    // 会员等级不低于
+	MaxMemberLevel    *int     `json:"max_member_level,omitempty"`    // 会员等级不高于
+	MinTotalSpent     *float64 `json:"min_total_spent,omitempty"`     // 累计消费金额不低于
+	MaxTotalSpent     *float64 `json:"max_total_spent,omitempty"`     // 累计消费金额不高于
+	MinOrders         *int     `json:"min_orders,omitempty"`          // 已完成订单数不少于
+	OrderedWithinDays *int     `json:"ordered_within_days,omitempty"` // 最近 N 天内下过单
+	NoOrderForDays    *int     `json:"no_order_for_days,omitempty"`   // 最近 N 天内没有下单，包括从未下单
+}
+
+// Value 实现 driver.Valuer 接口
+func (r SegmentRules) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (r *SegmentRules) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("类型断言为 []byte 失败")
+	}
+	return json.Unmarshal(b, r)
+}
+
+// Segment 表示按规则定义的客户分群，成员在查询时按规则实时计算，营销服务据此定向发放优惠券和开展活动
+type Segment struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	Name        string       `json:"name" gorm:"uniqueIndex;size:100;not null"`
+	Description string       `json:"description" gorm:"size:500"`
+	Rules       SegmentRules `json:"rules" gorm:"type:jsonb;not null"`
+	CreatedBy   uint         `json:"created_by"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// UserOrder 记录用户已完成的订单，由订单事件维护，用于按消费金额和最近下单时间分群
+type UserOrder struct {
+	OrderID   uint      `json:"order_id" gorm:"primaryKey;autoIncrement:false"`
+	UserID    uint      `json:"user_id" gorm:"index;not null"`
+	Total     float64   `json:"total" gorm:"type:decimal(12,2);not null"`
+	Refunded  float64   `json:"refunded" gorm:"type:decimal(12,2);not null;default:0"`
+	PlacedAt  time.Time `json:"placed_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserOrderRefund 记录已计入的退款，重复收到同一退款事件时不会重复扣减消费金额
+type UserOrderRefund struct {
+	RefundID  string    `json:"refund_id" gorm:"primaryKey;size:100"`
+	OrderID   uint      `json:"order_id" gorm:"index;not null"`
+	Amount    float64   `json:"amount" gorm:"type:decimal(12,2);not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
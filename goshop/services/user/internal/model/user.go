@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -25,6 +26,7 @@ type User struct {
 	MemberLevel   int            `json:"member_level" gorm:"default:0"` // 会员等级: 0=普通会员，1，2，3 等为更高等级
 	Points        int            `json:"points" gorm:"default:0"`       // 积分
 	TwoFactorAuth bool           `json:"two_factor_auth" gorm:"default:false"`
+	Birthday      *time.Time     `json:"birthday" gorm:"type:date;default:null"` // 生日，用于生日礼遇
 	Addresses     []Address      `json:"addresses" gorm:"foreignKey:UserID"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
@@ -48,14 +50,56 @@ type Address struct {
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
-// LoginHistory 表示用户的登录历史
+// UserPreference 表示用户的偏好设置，没有保存过的用户使用默认值。
+// 营销邮件需要用户主动订阅，推送通知默认开启
+type UserPreference struct {
+	UserID            uint      `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	Locale            string    `json:"locale" gorm:"size:10"`       // 如 zh-CN、en-US
+	Currency          string    `json:"currency" gorm:"size:3"`      // ISO 4217 货币代码，如 CNY
+	MarketingEmails   bool      `json:"marketing_emails"`            // 是否接收营销邮件
+	PushNotifications bool      `json:"push_notifications"`          // 是否接收推送通知
+	DefaultAddressID  *uint     `json:"default_address_id" gorm:"-"` // 取自地址簿中的默认地址，不单独保存
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// SocialAccount 表示用户关联的社交账号，同一服务商的账号只能关联一个用户
+type SocialAccount struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	Provider  string    `json:"provider" gorm:"uniqueIndex:idx_social_account;size:20;not null"` // 服务商: google, github, wechat
+	Subject   string    `json:"-" gorm:"uniqueIndex:idx_social_account;size:100;not null"`       // 用户在服务商的唯一 ID
+	Email     string    `json:"email" gorm:"size:255"`
+	Name      string    `json:"name" gorm:"size:100"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoginHistory 表示用户的登录历史，包括密码错误等失败的登录
 type LoginHistory struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	UserID    uint      `json:"user_id" gorm:"index"`
 	IP        string    `json:"ip" gorm:"size:50"`
 	UserAgent string    `json:"user_agent" gorm:"size:255"`
 	Location  string    `json:"location" gorm:"size:100"`
-	CreatedAt time.Time `json:"created_at"` // 登录时间
+	Failed    bool      `json:"failed" gorm:"default:false"`
+	Reason    string    `json:"reason" gorm:"size:50"` // 登录失败原因: wrong_password, inactive, locked；lockout 表示这次失败使账号被锁定
+	CreatedAt time.Time `json:"created_at"`            // 登录时间
+}
+
+// PasswordHistory 保存用户以前使用过的密码哈希，修改密码时不能重复使用最近的密码
+type PasswordHistory struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index;not null"`
+	Hash      string    `json:"-" gorm:"size:255;not null"`
+	CreatedAt time.Time `json:"created_at"` // 密码被替换的时间
+}
+
+// DisplayName 返回展示给其他用户和写入访问令牌的名称，没有填写姓名时使用用户名
+func (u *User) DisplayName() string {
+	if name := strings.TrimSpace(u.FirstName + " " + u.LastName); name != "" {
+		return name
+	}
+	return u.Username
 }
 
 // BeforeSave 在保存前处理 User
@@ -0,0 +1,119 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// GitHub 用户接口
+const (
+	githubUserURL   = "https://api.github.com/user"
+	githubEmailsURL = "https://api.github.com/user/emails"
+)
+
+// GitHub 通过 GitHub OAuth App 登录
+type GitHub struct {
+	oauth  *oauth2.Config
+	client *http.Client
+}
+
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// NewGitHub 创建 GitHub 服务商
+func NewGitHub(cfg config.OAuthProviderConfig, client *http.Client) *GitHub {
+	return &GitHub{
+		oauth: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     github.Endpoint,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       []string{"read:user", "user:email"},
+		},
+		client: client,
+	}
+}
+
+// Name 返回服务商名称
+func (g *GitHub) Name() string {
+	return ProviderGitHub
+}
+
+// AuthCodeURL 返回 GitHub 的授权地址
+func (g *GitHub) AuthCodeURL(state string) string {
+	return g.oauth.AuthCodeURL(state)
+}
+
+// Identify 用授权码换取访问令牌并获取用户信息。用户资料中的邮箱可能未公开，
+// 邮箱取自邮箱列表中的主邮箱
+func (g *GitHub) Identify(ctx context.Context, code string) (*Identity, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, g.client)
+	token, err := g.oauth.Exchange(ctx, code)
+	if err != nil {
+		return nil, exchangeError(ProviderGitHub, err)
+	}
+	client := g.oauth.Client(ctx, token)
+
+	var user githubUser
+	if err := g.get(ctx, client, githubUserURL, &user); err != nil {
+		return nil, err
+	}
+	var emails []githubEmail
+	if err := g.get(ctx, client, githubEmailsURL, &emails); err != nil {
+		return nil, err
+	}
+	identity := &Identity{
+		Subject: strconv.FormatInt(user.ID, 10),
+		Name:    user.Name,
+		Avatar:  user.AvatarURL,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+	return identity, nil
+}
+
+// get 请求 GitHub 接口并解析 JSON 响应
+func (g *GitHub) get(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return httpError(ProviderGitHub, resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/yourusername/goshop/pkg/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// googleUserInfoURL 是 Google OpenID Connect 的用户信息接口
+const googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+
+// Google 通过 Google OAuth 2.0 登录
+type Google struct {
+	oauth  *oauth2.Config
+	client *http.Client
+}
+
+type googleUserInfo struct {
+	Sub           string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Picture       string `json:"picture"`
+}
+
+// NewGoogle 创建 Google 服务商
+func NewGoogle(cfg config.OAuthProviderConfig, client *http.Client) *Google {
+	return &Google{
+		oauth: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     google.Endpoint,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       []string{"openid", "email", "profile"},
+		},
+		client: client,
+	}
+}
+
+// Name 返回服务商名称
+func (g *Google) Name() string {
+	return ProviderGoogle
+}
+
+// AuthCodeURL 返回 Google 的授权地址
+func (g *Google) AuthCodeURL(state string) string {
+	return g.oauth.AuthCodeURL(state, oauth2.AccessTypeOnline)
+}
+
+// Identify 用授权码换取访问令牌并获取用户信息
+func (g *Google) Identify(ctx context.Context, code string) (*Identity, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, g.client)
+	token, err := g.oauth.Exchange(ctx, code)
+	if err != nil {
+		return nil, exchangeError(ProviderGoogle, err)
+	}
+	resp, err := g.oauth.Client(ctx, token).Get(googleUserInfoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get google user info: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpError(ProviderGoogle, resp)
+	}
+	var info googleUserInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode google user info: %w", err)
+	}
+	if info.Sub == "" {
+		return nil, errors.New("google user info has no subject")
+	}
+	return &Identity{
+		Subject:       info.Sub,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+		Name:          info.Name,
+		FirstName:     info.GivenName,
+		LastName:      info.FamilyName,
+		Avatar:        info.Picture,
+	}, nil
+}
+
+// exchangeError 转换换取令牌的错误，服务商拒绝授权码时包装 ErrInvalidCode
+func exchangeError(name string, err error) error {
+	var retrieve *oauth2.RetrieveError
+	if errors.As(err, &retrieve) && retrieve.Response != nil &&
+		retrieve.Response.StatusCode >= 400 && retrieve.Response.StatusCode < 500 {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidCode, name, err)
+	}
+	return fmt.Errorf("failed to exchange %s authorization code: %w", name, err)
+}
@@ -0,0 +1,70 @@
+// Package oauth 实现社交账号登录的服务商：Google、GitHub 和微信开放平台的网站应用。
+// 服务商生成授权地址，并用回调中的授权码换取用户在服务商的身份
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+)
+
+// 服务商名称，也是路由中的 :provider
+const (
+	ProviderGoogle = "google"
+	ProviderGitHub = "github"
+	ProviderWeChat = "wechat"
+)
+
+// ErrInvalidCode 表示授权码无效、已使用或已过期，用户需要重新授权
+var ErrInvalidCode = errors.New("invalid authorization code")
+
+// requestTimeout 是请求服务商接口的超时时间
+const requestTimeout = 10 * time.Second
+
+// Identity 是用户在服务商的身份
+type Identity struct {
+	Subject       string // 用户在服务商的唯一 ID，微信为 unionid，没有 unionid 时为 openid
+	Email         string
+	EmailVerified bool // 服务商是否验证过邮箱，只有验证过的邮箱才会用于关联账号
+	Name          string
+	FirstName     string
+	LastName      string
+	Avatar        string
+}
+
+// Provider 定义社交登录服务商接口
+type Provider interface {
+	// Name 返回服务商名称
+	Name() string
+	// AuthCodeURL 返回用户授权的地址，state 在回调时原样返回
+	AuthCodeURL(state string) string
+	// Identify 用授权码换取用户的身份，授权码无效时返回包装了 ErrInvalidCode 的错误
+	Identify(ctx context.Context, code string) (*Identity, error)
+}
+
+// FromConfig 按配置创建已启用的服务商
+func FromConfig(cfg config.OAuthConfig) map[string]Provider {
+	client := &http.Client{Timeout: requestTimeout}
+	providers := make(map[string]Provider)
+	if cfg.Google.Enabled() {
+		providers[ProviderGoogle] = NewGoogle(cfg.Google, client)
+	}
+	if cfg.GitHub.Enabled() {
+		providers[ProviderGitHub] = NewGitHub(cfg.GitHub, client)
+	}
+	if cfg.WeChat.Enabled() {
+		providers[ProviderWeChat] = NewWeChat(cfg.WeChat, client)
+	}
+	return providers
+}
+
+// httpError 根据服务商的 HTTP 响应生成错误
+func httpError(name string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s returned status %d: %s", name, resp.StatusCode, body)
+}
@@ -0,0 +1,142 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/yourusername/goshop/pkg/config"
+)
+
+// 微信开放平台网站应用的接口
+const (
+	wechatAuthorizeURL = "https://open.weixin.qq.com/connect/qrconnect"
+	wechatTokenURL     = "https://api.weixin.qq.com/sns/oauth2/access_token"
+	wechatUserInfoURL  = "https://api.weixin.qq.com/sns/userinfo"
+)
+
+// 授权码无效或已使用时微信返回的错误码
+var wechatInvalidCodes = map[int]bool{
+	40029: true, // invalid code
+	40163: true, // code been used
+}
+
+// WeChat 通过微信开放平台网站应用扫码登录。微信不提供邮箱，
+// 用户以 unionid 标识，同一开放平台下的公众号和小程序中也相同
+type WeChat struct {
+	appID     string
+	appSecret string
+	redirect  string
+	client    *http.Client
+}
+
+type wechatError struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+type wechatToken struct {
+	wechatError
+	AccessToken string `json:"access_token"`
+	OpenID      string `json:"openid"`
+	UnionID     string `json:"unionid"`
+}
+
+type wechatUserInfo struct {
+	wechatError
+	OpenID     string `json:"openid"`
+	UnionID    string `json:"unionid"`
+	Nickname   string `json:"nickname"`
+	HeadImgURL string `json:"headimgurl"`
+}
+
+// NewWeChat 创建微信服务商，ClientID 和 ClientSecret 是网站应用的 AppID 和 AppSecret
+func NewWeChat(cfg config.OAuthProviderConfig, client *http.Client) *WeChat {
+	return &WeChat{
+		appID:     cfg.ClientID,
+		appSecret: cfg.ClientSecret,
+		redirect:  cfg.RedirectURL,
+		client:    client,
+	}
+}
+
+// Name 返回服务商名称
+func (w *WeChat) Name() string {
+	return ProviderWeChat
+}
+
+// AuthCodeURL 返回微信扫码登录的地址
+func (w *WeChat) AuthCodeURL(state string) string {
+	query := url.Values{
+		"appid":         {w.appID},
+		"redirect_uri":  {w.redirect},
+		"response_type": {"code"},
+		"scope":         {"snsapi_login"},
+		"state":         {state},
+	}
+	return wechatAuthorizeURL + "?" + query.Encode() + "#wechat_redirect"
+}
+
+// Identify 用授权码换取访问令牌并获取用户信息
+func (w *WeChat) Identify(ctx context.Context, code string) (*Identity, error) {
+	var token wechatToken
+	err := w.get(ctx, wechatTokenURL, url.Values{
+		"appid":      {w.appID},
+		"secret":     {w.appSecret},
+		"code":       {code},
+		"grant_type": {"authorization_code"},
+	}, &token, &token.wechatError)
+	if err != nil {
+		return nil, err
+	}
+
+	var info wechatUserInfo
+	err = w.get(ctx, wechatUserInfoURL, url.Values{
+		"access_token": {token.AccessToken},
+		"openid":       {token.OpenID},
+	}, &info, &info.wechatError)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Subject: info.UnionID,
+		Name:    info.Nickname,
+		Avatar:  info.HeadImgURL,
+	}
+	if identity.Subject == "" {
+		identity.Subject = token.UnionID
+	}
+	if identity.Subject == "" {
+		identity.Subject = token.OpenID
+	}
+	return identity, nil
+}
+
+// get 请求微信接口。微信接口出错时同样返回 200，错误码在响应体中
+func (w *WeChat) get(ctx context.Context, endpoint string, query url.Values, v interface{}, apiErr *wechatError) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return httpError(ProviderWeChat, resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", endpoint, err)
+	}
+	if apiErr.ErrCode != 0 {
+		if wechatInvalidCodes[apiErr.ErrCode] {
+			return fmt.Errorf("%w: wechat error %d: %s", ErrInvalidCode, apiErr.ErrCode, apiErr.ErrMsg)
+		}
+		return fmt.Errorf("wechat error %d: %s", apiErr.ErrCode, apiErr.ErrMsg)
+	}
+	return nil
+}
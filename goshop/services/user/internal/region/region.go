@@ -0,0 +1,79 @@
+// Package region 校验收货地址的省、市和区县。数据集按省、市、区县三级组织，
+// 默认使用内置的数据集，内置数据集只列出了直辖市的区县，没有列出区县的城市接受任意区县；
+// 部署时可以通过配置提供完整的数据集，格式与内置的 regions.json 相同
+package region
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed regions.json
+var builtin []byte
+
+// Dataset 是省、市和区县的数据集，不可修改，可并发使用
+type Dataset struct {
+	provinces map[string]map[string]map[string]bool
+}
+
+// Default 返回内置的数据集
+func Default() (*Dataset, error) {
+	return Parse(builtin)
+}
+
+// Load 从 path 读取数据集，path 为空时返回内置的数据集
+func Load(path string) (*Dataset, error) {
+	if path == "" {
+		return Default()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read region dataset: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse 解析 JSON 数据集，格式为 {"省": {"市": ["区县", ...]}}
+func Parse(data []byte) (*Dataset, error) {
+	var raw map[string]map[string][]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse region dataset: %w", err)
+	}
+	d := &Dataset{provinces: make(map[string]map[string]map[string]bool, len(raw))}
+	for province, cities := range raw {
+		d.provinces[province] = make(map[string]map[string]bool, len(cities))
+		for city, districts := range cities {
+			set := make(map[string]bool, len(districts))
+			for _, district := range districts {
+				set[district] = true
+			}
+			d.provinces[province][city] = set
+		}
+	}
+	return d, nil
+}
+
+// Validate 检查市属于省、区县属于市，返回的错误信息可以直接展示给用户。
+// 数据集没有列出区县的城市接受任意区县，列出了区县的城市必须填写区县
+func (d *Dataset) Validate(province, city, district string) error {
+	cities, ok := d.provinces[province]
+	if !ok {
+		return fmt.Errorf("未知的省份 %s", province)
+	}
+	districts, ok := cities[city]
+	if !ok {
+		return fmt.Errorf("%s 没有城市 %s", province, city)
+	}
+	if len(districts) == 0 {
+		return nil
+	}
+	if district == "" {
+		return fmt.Errorf("请选择 %s 的区县", city)
+	}
+	if !districts[district] {
+		return fmt.Errorf("%s 没有区县 %s", city, district)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAddressLimit 表示用户的地址数量已达到上限
+var ErrAddressLimit = errors.New("address limit reached")
+
+// AddressRepository 定义收货地址仓库接口。用户最多有一个默认地址，
+// 修改默认地址的操作在事务中同时取消其他地址的默认状态
+type AddressRepository interface {
+	ListByUser(ctx context.Context, userID uint) ([]*model.Address, error)
+	Get(ctx context.Context, userID, id uint) (*model.Address, error)
+	GetDefault(ctx context.Context, userID uint) (*model.Address, error)
+	Create(ctx context.Context, address *model.Address, limit int) error
+	Update(ctx context.Context, address *model.Address) error
+	Delete(ctx context.Context, userID, id uint) error
+	SetDefault(ctx context.Context, userID, id uint) error
+}
+
+// GormAddressRepository 实现 AddressRepository 接口的 GORM 仓库
+type GormAddressRepository struct {
+	db *gorm.DB
+}
+
+// NewAddressRepository 创建收货地址仓库实例
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &GormAddressRepository{
+		db: db,
+	}
+}
+
+// ListByUser 获取用户的收货地址，默认地址排在最前
+func (r *GormAddressRepository) ListByUser(ctx context.Context, userID uint) ([]*model.Address, error) {
+	var addresses []*model.Address
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).
+		Order("is_default DESC, id DESC").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+// Get 获取用户的收货地址，地址不属于该用户时返回 gorm.ErrRecordNotFound
+func (r *GormAddressRepository) Get(ctx context.Context, userID, id uint) (*model.Address, error) {
+	var address model.Address
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&address).Error
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// GetDefault 获取用户的默认地址
+func (r *GormAddressRepository) GetDefault(ctx context.Context, userID uint) (*model.Address, error) {
+	var address model.Address
+	err := r.db.WithContext(ctx).Where("user_id = ? AND is_default", userID).First(&address).Error
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// Create 在事务中创建收货地址，用户已有 limit 个地址时返回 ErrAddressLimit。
+// 用户的第一个地址总是默认地址；锁定用户行，并发创建时不会超过上限
+func (r *GormAddressRepository) Create(ctx context.Context, address *model.Address, limit int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, address.UserID).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&model.Address{}).Where("user_id = ?", address.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(limit) {
+			return ErrAddressLimit
+		}
+		if count == 0 {
+			address.IsDefault = true
+		}
+		if address.IsDefault {
+			if err := clearDefault(tx, address.UserID, 0); err != nil {
+				return err
+			}
+		}
+		return tx.Create(address).Error
+	})
+}
+
+// Update 在事务中更新收货地址，设为默认地址时取消其他地址的默认状态。
+// 默认地址不能通过更新取消默认，只能将其他地址设为默认
+func (r *GormAddressRepository) Update(ctx context.Context, address *model.Address) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefault(tx, address.UserID, address.ID); err != nil {
+				return err
+			}
+		}
+		result := tx.Model(&model.Address{}).
+			Where("id = ? AND user_id = ?", address.ID, address.UserID).
+			Updates(map[string]interface{}{
+				"name":          address.Name,
+				"phone":         address.Phone,
+				"province":      address.Province,
+				"city":          address.City,
+				"district":      address.District,
+				"detailed_info": address.DetailedInfo,
+				"postal_code":   address.PostalCode,
+				"is_default":    gorm.Expr("is_default OR ?", address.IsDefault),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// Delete 在事务中删除收货地址，删除的是默认地址时将最近添加的地址设为默认
+func (r *GormAddressRepository) Delete(ctx context.Context, userID, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var address model.Address
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", id, userID).
+			First(&address).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+		var next model.Address
+		err = tx.Where("user_id = ?", userID).Order("id DESC").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+// SetDefault 在事务中将收货地址设为默认地址，同时取消其他地址的默认状态
+func (r *GormAddressRepository) SetDefault(ctx context.Context, userID, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := clearDefault(tx, userID, id); err != nil {
+			return err
+		}
+		result := tx.Model(&model.Address{}).
+			Where("id = ? AND user_id = ?", id, userID).
+			Update("is_default", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// clearDefault 取消用户除 exceptID 外所有地址的默认状态
+func clearDefault(tx *gorm.DB, userID, exceptID uint) error {
+	return tx.Model(&model.Address{}).
+		Where("user_id = ? AND id <> ? AND is_default", userID, exceptID).
+		Update("is_default", false).Error
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yourusername/goshop/pkg/cache"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/pkg/metrics"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+// UserCache 在 Redis 中缓存按 ID、邮箱和用户名查询的用户。同一用户的缓存都关联到用户的标签，
+// 用户或收货地址变更后一起删除。并发未命中同一个键时只查询一次数据库，Redis 不可用时直接查询数据库
+type UserCache struct {
+	cache   *cache.Cache
+	ttl     time.Duration
+	group   singleflight.Group
+	lookups *prometheus.CounterVec
+	log     *logger.Logger
+}
+
+// NewUserCache 创建用户缓存，缓存的用户在 ttl 后过期
+func NewUserCache(c *cache.Cache, ttl time.Duration, m *metrics.Metrics, log *logger.Logger) *UserCache {
+	return &UserCache{
+		cache:   c,
+		ttl:     ttl,
+		lookups: m.Counter("user_cache_lookups_total", "User lookups by lookup and result: hit, miss or error when Redis is unavailable.", "lookup", "result"),
+		log:     log,
+	}
+}
+
+// get 返回 key 缓存的用户，未命中时通过 load 查询并缓存。用户不存在时不缓存。
+// 缓存中不保存密码哈希（User 的 JSON 不包含密码），是否命中缓存返回的用户 Password 都为空
+func (c *UserCache) get(ctx context.Context, lookup, key string, load func(ctx context.Context) (*model.User, error)) (*model.User, error) {
+	var cached model.User
+	err := c.cache.Get(ctx, key, &cached)
+	if err == nil {
+		c.lookups.WithLabelValues(lookup, "hit").Inc()
+		return &cached, nil
+	}
+	if !errors.Is(err, cache.ErrMiss) {
+		c.lookups.WithLabelValues(lookup, "error").Inc()
+		user, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = ""
+		return user, nil
+	}
+
+	c.lookups.WithLabelValues(lookup, "miss").Inc()
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		user, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = ""
+		if err := c.cache.Set(ctx, key, user, c.ttl, userTag(user.ID)); err != nil {
+			c.log.Warn(ctx, "缓存用户失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+		return user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 共享同一次查询的调用方各自得到一份用户，互不影响
+	user := *v.(*model.User)
+	return &user, nil
+}
+
+// Invalidate 删除用户的全部缓存。失败只记录日志，缓存的用户在过期后更新
+func (c *UserCache) Invalidate(ctx context.Context, ids ...uint) {
+	if len(ids) == 0 {
+		return
+	}
+	tags := make([]string, len(ids))
+	for i, id := range ids {
+		tags[i] = userTag(id)
+	}
+	if err := c.cache.InvalidateTags(ctx, tags...); err != nil {
+		c.log.Warn(ctx, "删除用户缓存失败", zap.Uints("user_ids", ids), zap.Error(err))
+	}
+}
+
+func userTag(id uint) string {
+	return cache.Key("user", strconv.FormatUint(uint64(id), 10))
+}
+
+// CachedUserRepository 是带缓存的用户仓库，GetByID、GetByEmail 和 GetByUsername 先查询缓存，
+// 修改用户的方法在修改后删除用户的缓存，其他方法直接使用被包装的仓库
+type CachedUserRepository struct {
+	UserRepository
+	cache *UserCache
+	// pending 是事务中修改的用户，事务结束后才删除缓存，避免其他请求在提交前重新缓存旧的数据；
+	// 为 nil 表示不在事务中
+	pending *[]uint
+}
+
+// NewCachedUserRepository 创建带缓存的用户仓库
+func NewCachedUserRepository(repo UserRepository, c *UserCache) UserRepository {
+	return &CachedUserRepository{
+		UserRepository: repo,
+		cache:          c,
+	}
+}
+
+// Transaction 在事务中执行 fn，事务中的查询不使用缓存，修改的用户在事务结束后删除缓存
+func (r *CachedUserRepository) Transaction(ctx context.Context, fn func(repo UserRepository, tx *gorm.DB) error) error {
+	var pending []uint
+	err := r.UserRepository.Transaction(ctx, func(repo UserRepository, tx *gorm.DB) error {
+		return fn(&CachedUserRepository{UserRepository: repo, cache: r.cache, pending: &pending}, tx)
+	})
+	r.cache.Invalidate(ctx, pending...)
+	return err
+}
+
+// GetByID 根据 ID 获取用户，包括用户的收货地址
+func (r *CachedUserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
+	if r.pending != nil {
+		return r.UserRepository.GetByID(ctx, id)
+	}
+	return r.cache.get(ctx, "id", cache.Key("id", strconv.FormatUint(uint64(id), 10)), func(ctx context.Context) (*model.User, error) {
+		return r.UserRepository.GetByID(ctx, id)
+	})
+}
+
+// GetByEmail 根据邮箱获取用户
+func (r *CachedUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	if r.pending != nil {
+		return r.UserRepository.GetByEmail(ctx, email)
+	}
+	return r.cache.get(ctx, "email", cache.Key("email", email), func(ctx context.Context) (*model.User, error) {
+		return r.UserRepository.GetByEmail(ctx, email)
+	})
+}
+
+// GetByUsername 根据用户名获取用户
+func (r *CachedUserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	if r.pending != nil {
+		return r.UserRepository.GetByUsername(ctx, username)
+	}
+	return r.cache.get(ctx, "username", cache.Key("username", username), func(ctx context.Context) (*model.User, error) {
+		return r.UserRepository.GetByUsername(ctx, username)
+	})
+}
+
+// Update 更新用户信息
+func (r *CachedUserRepository) Update(ctx context.Context, user *model.User) error {
+	return r.invalidate(ctx, user.ID, r.UserRepository.Update(ctx, user))
+}
+
+// UpdateFields 更新用户的指定字段
+func (r *CachedUserRepository) UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return r.invalidate(ctx, id, r.UserRepository.UpdateFields(ctx, id, fields))
+}
+
+// Delete 删除用户（软删除）
+func (r *CachedUserRepository) Delete(ctx context.Context, id uint) error {
+	return r.invalidate(ctx, id, r.UserRepository.Delete(ctx, id))
+}
+
+// VerifyEmail 验证用户邮箱
+func (r *CachedUserRepository) VerifyEmail(ctx context.Context, id uint) error {
+	return r.invalidate(ctx, id, r.UserRepository.VerifyEmail(ctx, id))
+}
+
+// VerifyPhone 验证用户手机
+func (r *CachedUserRepository) VerifyPhone(ctx context.Context, id uint) error {
+	return r.invalidate(ctx, id, r.UserRepository.VerifyPhone(ctx, id))
+}
+
+// UpdateAvatar 更新用户头像
+func (r *CachedUserRepository) UpdateAvatar(ctx context.Context, id uint, avatar string) error {
+	return r.invalidate(ctx, id, r.UserRepository.UpdateAvatar(ctx, id, avatar))
+}
+
+// UpdateLastLogin 更新最后登录时间
+func (r *CachedUserRepository) UpdateLastLogin(ctx context.Context, id uint) error {
+	return r.invalidate(ctx, id, r.UserRepository.UpdateLastLogin(ctx, id))
+}
+
+// AddPoints 添加积分
+func (r *CachedUserRepository) AddPoints(ctx context.Context, id uint, points int) error {
+	return r.invalidate(ctx, id, r.UserRepository.AddPoints(ctx, id, points))
+}
+
+// UpdateMemberLevel 更新会员等级
+func (r *CachedUserRepository) UpdateMemberLevel(ctx context.Context, id uint, level int) error {
+	return r.invalidate(ctx, id, r.UserRepository.UpdateMemberLevel(ctx, id, level))
+}
+
+// invalidate 在修改用户后删除用户的缓存，事务中推迟到事务结束。修改失败时也删除，
+// 失败的修改可能已经部分生效
+func (r *CachedUserRepository) invalidate(ctx context.Context, id uint, err error) error {
+	if r.pending != nil {
+		*r.pending = append(*r.pending, id)
+		return err
+	}
+	r.cache.Invalidate(ctx, id)
+	return err
+}
+
+// CachedAddressRepository 是在地址变更后删除用户缓存的收货地址仓库，缓存的用户包括收货地址
+type CachedAddressRepository struct {
+	AddressRepository
+	cache *UserCache
+}
+
+// NewCachedAddressRepository 创建在地址变更后删除用户缓存的收货地址仓库
+func NewCachedAddressRepository(repo AddressRepository, c *UserCache) AddressRepository {
+	return &CachedAddressRepository{
+		AddressRepository: repo,
+		cache:             c,
+	}
+}
+
+// Create 创建收货地址
+func (r *CachedAddressRepository) Create(ctx context.Context, address *model.Address, limit int) error {
+	err := r.AddressRepository.Create(ctx, address, limit)
+	r.cache.Invalidate(ctx, address.UserID)
+	return err
+}
+
+// Update 更新收货地址
+func (r *CachedAddressRepository) Update(ctx context.Context, address *model.Address) error {
+	err := r.AddressRepository.Update(ctx, address)
+	r.cache.Invalidate(ctx, address.UserID)
+	return err
+}
+
+// Delete 删除收货地址
+func (r *CachedAddressRepository) Delete(ctx context.Context, userID, id uint) error {
+	err := r.AddressRepository.Delete(ctx, userID, id)
+	r.cache.Invalidate(ctx, userID)
+	return err
+}
+
+// SetDefault 设置默认收货地址
+func (r *CachedAddressRepository) SetDefault(ctx context.Context, userID, id uint) error {
+	err := r.AddressRepository.SetDefault(ctx, userID, id)
+	r.cache.Invalidate(ctx, userID)
+	return err
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PreferenceRepository 定义用户偏好仓库接口
+type PreferenceRepository interface {
+	Transaction(ctx context.Context, fn func(repo PreferenceRepository, tx *gorm.DB) error) error
+	Get(ctx context.Context, userID uint) (*model.UserPreference, error)
+	Save(ctx context.Context, preference *model.UserPreference) error
+}
+
+// GormPreferenceRepository 实现 PreferenceRepository 接口的 GORM 仓库
+type GormPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewPreferenceRepository 创建用户偏好仓库实例
+func NewPreferenceRepository(db *gorm.DB) PreferenceRepository {
+	return &GormPreferenceRepository{
+		db: db,
+	}
+}
+
+// Transaction 在事务中执行 fn，repo 和 tx 使用同一个事务，
+// 用于将偏好的变更与描述变更的事件一起提交
+func (r *GormPreferenceRepository) Transaction(ctx context.Context, fn func(repo PreferenceRepository, tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormPreferenceRepository{db: tx}, tx)
+	})
+}
+
+// Get 获取用户保存的偏好
+func (r *GormPreferenceRepository) Get(ctx context.Context, userID uint) (*model.UserPreference, error) {
+	var preference model.UserPreference
+	err := r.db.WithContext(ctx).First(&preference, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// Save 创建或更新用户的偏好
+func (r *GormPreferenceRepository) Save(ctx context.Context, preference *model.UserPreference) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"locale", "currency", "marketing_emails", "push_notifications", "updated_at"}),
+		}).
+		Create(preference).Error
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SegmentRepository 定义客户分群仓库接口，同时维护分群规则使用的用户订单统计
+type SegmentRepository interface {
+	Create(ctx context.Context, segment *model.Segment) error
+	Get(ctx context.Context, id uint) (*model.Segment, error)
+	GetByName(ctx context.Context, name string) (*model.Segment, error)
+	List(ctx context.Context) ([]*model.Segment, error)
+	Update(ctx context.Context, segment *model.Segment) error
+	Delete(ctx context.Context, id uint) error
+	ListMembers(ctx context.Context, rules *model.SegmentRules, now time.Time, afterID uint, limit int) ([]*model.User, error)
+	CountMembers(ctx context.Context, rules *model.SegmentRules, now time.Time) (int64, error)
+	IsMember(ctx context.Context, rules *model.SegmentRules, now time.Time, userID uint) (bool, error)
+	RecordOrder(ctx context.Context, order *model.UserOrder) error
+	RecordRefund(ctx context.Context, refund *model.UserOrderRefund) error
+}
+
+// GormSegmentRepository 实现 SegmentRepository 接口的 GORM 仓库
+type GormSegmentRepository struct {
+	db *gorm.DB
+}
+
+// NewSegmentRepository 创建客户分群仓库实例
+func NewSegmentRepository(db *gorm.DB) SegmentRepository {
+	return &GormSegmentRepository{
+		db: db,
+	}
+}
+
+// Create 创建客户分群
+func (r *GormSegmentRepository) Create(ctx context.Context, segment *model.Segment) error {
+	return r.db.WithContext(ctx).Create(segment).Error
+}
+
+// Get 根据 ID 获取客户分群
+func (r *GormSegmentRepository) Get(ctx context.Context, id uint) (*model.Segment, error) {
+	var segment model.Segment
+	if err := r.db.WithContext(ctx).First(&segment, id).Error; err != nil {
+		return nil, err
+	}
+	return &segment, nil
+}
+
+// GetByName 根据名称获取客户分群
+func (r *GormSegmentRepository) GetByName(ctx context.Context, name string) (*model.Segment, error) {
+	var segment model.Segment
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&segment).Error; err != nil {
+		return nil, err
+	}
+	return &segment, nil
+}
+
+// List 获取全部客户分群
+func (r *GormSegmentRepository) List(ctx context.Context) ([]*model.Segment, error) {
+	var segments []*model.Segment
+	err := r.db.WithContext(ctx).Order("id").Find(&segments).Error
+	return segments, err
+}
+
+// Update 更新客户分群
+func (r *GormSegmentRepository) Update(ctx context.Context, segment *model.Segment) error {
+	return r.db.WithContext(ctx).Save(segment).Error
+}
+
+// Delete 删除客户分群
+func (r *GormSegmentRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Segment{}, id).Error
+}
+
+// ListMembers 按 ID 顺序分页获取符合规则的活跃用户，afterID 为上一页最后一个用户的 ID
+func (r *GormSegmentRepository) ListMembers(ctx context.Context, rules *model.SegmentRules, now time.Time, afterID uint, limit int) ([]*model.User, error) {
+	var users []*model.User
+	err := r.members(ctx, rules, now).
+		Select("users.*").
+		Where("users.id > ?", afterID).
+		Order("users.id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// CountMembers 统计符合规则的活跃用户数
+func (r *GormSegmentRepository) CountMembers(ctx context.Context, rules *model.SegmentRules, now time.Time) (int64, error) {
+	var count int64
+	err := r.members(ctx, rules, now).Count(&count).Error
+	return count, err
+}
+
+// IsMember 判断用户是否符合规则
+func (r *GormSegmentRepository) IsMember(ctx context.Context, rules *model.SegmentRules, now time.Time, userID uint) (bool, error) {
+	var count int64
+	err := r.members(ctx, rules, now).Where("users.id = ?", userID).Count(&count).Error
+	return count > 0, err
+}
+
+// members 构造查询符合规则的活跃用户的语句，用户的订单统计来自 user_orders
+func (r *GormSegmentRepository) members(ctx context.Context, rules *model.SegmentRules, now time.Time) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	stats := db.Model(&model.UserOrder{}).
+		Select("user_id, COUNT(*) AS orders, SUM(total - refunded) AS spent, MAX(placed_at) AS last_order_at").
+		Group("user_id")
+	q := db.Model(&model.User{}).
+		Joins("LEFT JOIN (?) AS s ON s.user_id = users.id", stats).
+		Where("users.status = ?", "active")
+
+	if len(rules.Tags) > 0 {
+		q = q.Where("EXISTS (SELECT 1 FROM user_tags t WHERE t.user_id = users.id AND t.tag IN ?)", rules.Tags)
+	}
+	if rules.MinMemberLevel != nil {
+		q = q.Where("users.member_level >= ?", *rules.MinMemberLevel)
+	}
+	if rules.MaxMemberLevel != nil {
+		q = q.Where("users.member_level <= ?", *rules.MaxMemberLevel)
+	}
+	if rules.MinTotalSpent != nil {
+		q = q.Where("COALESCE(s.spent, 0) >= ?", *rules.MinTotalSpent)
+	}
+	if rules.MaxTotalSpent != nil {
+		q = q.Where("COALESCE(s.spent, 0) <= ?", *rules.MaxTotalSpent)
+	}
+	if rules.MinOrders != nil {
+		q = q.Where("COALESCE(s.orders, 0) >= ?", *rules.MinOrders)
+	}
+	if rules.OrderedWithinDays != nil {
+		q = q.Where("s.last_order_at >= ?", now.AddDate(0, 0, -*rules.OrderedWithinDays))
+	}
+	if rules.NoOrderForDays != nil {
+		q = q.Where("(s.last_order_at IS NULL OR s.last_order_at < ?)", now.AddDate(0, 0, -*rules.NoOrderForDays))
+	}
+	return q
+}
+
+// RecordOrder 记录用户已完成的订单，重复记录同一订单时不变。
+// 退款事件可能先于订单事件到达，记录订单时计入此前已记录的退款
+func (r *GormSegmentRepository) RecordOrder(ctx context.Context, order *model.UserOrder) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.UserOrderRefund{}).
+			Select("COALESCE(SUM(amount), 0)").
+			Where("order_id = ?", order.OrderID).
+			Scan(&order.Refunded).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(order).Error
+	})
+}
+
+// RecordRefund 在事务中记录退款并累加订单的退款金额，同一退款只计入一次
+func (r *GormSegmentRepository) RecordRefund(ctx context.Context, refund *model.UserOrderRefund) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(refund)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&model.UserOrder{}).
+			Where("order_id = ?", refund.OrderID).
+			Update("refunded", gorm.Expr("refunded + ?", refund.Amount)).Error
+	})
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"gorm.io/gorm"
+)
+
+// SocialAccountRepository 定义社交账号仓库接口
+type SocialAccountRepository interface {
+	Create(ctx context.Context, account *model.SocialAccount) error
+	GetBySubject(ctx context.Context, provider, subject string) (*model.SocialAccount, error)
+}
+
+// GormSocialAccountRepository 实现 SocialAccountRepository 接口的 GORM 仓库
+type GormSocialAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewSocialAccountRepository 创建社交账号仓库实例
+func NewSocialAccountRepository(db *gorm.DB) SocialAccountRepository {
+	return &GormSocialAccountRepository{
+		db: db,
+	}
+}
+
+// Create 关联社交账号
+func (r *GormSocialAccountRepository) Create(ctx context.Context, account *model.SocialAccount) error {
+	return r.db.WithContext(ctx).Create(account).Error
+}
+
+// GetBySubject 根据服务商和用户在服务商的 ID 获取社交账号
+func (r *GormSocialAccountRepository) GetBySubject(ctx context.Context, provider, subject string) (*model.SocialAccount, error) {
+	var account model.SocialAccount
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
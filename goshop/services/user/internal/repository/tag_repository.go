@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagCount 表示标签及拥有该标签的客户数
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Users int64  `json:"users"`
+}
+
+// TagRepository 定义客户标签仓库接口
+type TagRepository interface {
+	Add(ctx context.Context, tag *model.UserTag) error
+	Remove(ctx context.Context, userID uint, tag string) (bool, error)
+	ListByUser(ctx context.Context, userID uint) ([]*model.UserTag, error)
+	Summary(ctx context.Context) ([]*TagCount, error)
+}
+
+// GormTagRepository 实现 TagRepository 接口的 GORM 仓库
+type GormTagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository 创建客户标签仓库实例
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &GormTagRepository{
+		db: db,
+	}
+}
+
+// Add 给客户打标签，客户已有该标签时不变
+func (r *GormTagRepository) Add(ctx context.Context, tag *model.UserTag) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(tag).Error
+}
+
+// Remove 删除客户的标签，返回客户是否有该标签
+func (r *GormTagRepository) Remove(ctx context.Context, userID uint, tag string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND tag = ?", userID, tag).Delete(&model.UserTag{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListByUser 获取客户的标签
+func (r *GormTagRepository) ListByUser(ctx context.Context, userID uint) ([]*model.UserTag, error) {
+	var tags []*model.UserTag
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("tag").Find(&tags).Error
+	return tags, err
+}
+
+// Summary 获取使用中的标签及各标签的客户数
+func (r *GormTagRepository) Summary(ctx context.Context) ([]*TagCount, error) {
+	var counts []*TagCount
+	err := r.db.WithContext(ctx).Model(&model.UserTag{}).
+		Select("tag, COUNT(*) AS users").
+		Group("tag").
+		Order("tag").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -2,27 +2,43 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/yourusername/goshop/services/user/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrDuplicateUser 表示邮箱、用户名或手机号已被其他用户使用，包括已删除的用户
+var ErrDuplicateUser = errors.New("duplicate user")
+
 // UserRepository 定义用户仓库接口
 type UserRepository interface {
+	Transaction(ctx context.Context, fn func(repo UserRepository, tx *gorm.DB) error) error
 	Create(ctx context.Context, user *model.User) error
 	GetByID(ctx context.Context, id uint) (*model.User, error)
+	ListByIDs(ctx context.Context, ids []uint) ([]*model.User, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	GetByPhone(ctx context.Context, phone string) (*model.User, error)
+	GetPasswordHash(ctx context.Context, id uint) (string, error)
 	Update(ctx context.Context, user *model.User) error
+	UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, offset, limit int) ([]*model.User, int64, error)
 	VerifyEmail(ctx context.Context, id uint) error
 	VerifyPhone(ctx context.Context, id uint) error
+	UpdateAvatar(ctx context.Context, id uint, avatar string) error
 	UpdateLastLogin(ctx context.Context, id uint) error
 	AddPoints(ctx context.Context, id uint, points int) error
 	UpdateMemberLevel(ctx context.Context, id uint, level int) error
 	AddLoginHistory(ctx context.Context, history *model.LoginHistory) error
 	GetLoginHistory(ctx context.Context, userID uint, limit int) ([]*model.LoginHistory, error)
+	AddPasswordHistory(ctx context.Context, history *model.PasswordHistory, keep int) error
+	ListPasswordHistory(ctx context.Context, userID uint, limit int) ([]*model.PasswordHistory, error)
+	ListByBirthday(ctx context.Context, month, day int, afterID uint, limit int) ([]*model.User, error)
+	ListBySignupDate(ctx context.Context, month, day int, before time.Time, afterID uint, limit int) ([]*model.User, error)
 }
 
 // GormUserRepository 实现 UserRepository 接口的 GORM 仓库
@@ -37,9 +53,24 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	}
 }
 
-// Create 创建新用户
+// Transaction 在事务中执行 fn，repo 和 tx 使用同一个事务，
+// 用于将用户的变更与描述变更的事件一起提交
+func (r *GormUserRepository) Transaction(ctx context.Context, fn func(repo UserRepository, tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormUserRepository{db: tx}, tx)
+	})
+}
+
+// Create 创建新用户，邮箱、用户名或手机号与已有用户重复时返回 ErrDuplicateUser
 func (r *GormUserRepository) Create(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(user)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDuplicateUser
+	}
+	return nil
 }
 
 // GetByID 根据 ID 获取用户
@@ -52,6 +83,13 @@ func (r *GormUserRepository) GetByID(ctx context.Context, id uint) (*model.User,
 	return &user, nil
 }
 
+// ListByIDs 根据 ID 批量获取用户，不存在的用户不在结果中
+func (r *GormUserRepository) ListByIDs(ctx context.Context, ids []uint) ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
 // GetByEmail 根据邮箱获取用户
 func (r *GormUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
@@ -72,9 +110,35 @@ func (r *GormUserRepository) GetByUsername(ctx context.Context, username string)
 	return &user, nil
 }
 
-// Update 更新用户信息
+// GetByPhone 根据手机号获取用户
+func (r *GormUserRepository) GetByPhone(ctx context.Context, phone string) (*model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).Where("phone = ?", phone).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetPasswordHash 获取用户的密码哈希，通过社交账号注册的用户返回空字符串。
+// 其他查询返回的用户可能来自缓存，不包含密码哈希，校验密码时必须使用该方法
+func (r *GormUserRepository) GetPasswordHash(ctx context.Context, id uint) (string, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).Select("id", "password").First(&user, id).Error
+	if err != nil {
+		return "", err
+	}
+	return user.Password, nil
+}
+
+// Update 更新用户信息，不修改密码：传入的用户可能来自缓存，没有密码哈希，密码通过 UpdateFields 修改
 func (r *GormUserRepository) Update(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return r.db.WithContext(ctx).Omit("password").Save(user).Error
+}
+
+// UpdateFields 更新用户的指定字段
+func (r *GormUserRepository) UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(fields).Error
 }
 
 // Delete 删除用户（软删除）
@@ -110,6 +174,11 @@ func (r *GormUserRepository) VerifyPhone(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("phone_verified", true).Error
 }
 
+// UpdateAvatar 更新用户头像
+func (r *GormUserRepository) UpdateAvatar(ctx context.Context, id uint, avatar string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("avatar", avatar).Error
+}
+
 // UpdateLastLogin 更新最后登录时间
 func (r *GormUserRepository) UpdateLastLogin(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("last_login_at", gorm.Expr("NOW()")).Error
@@ -148,3 +217,52 @@ func (r *GormUserRepository) GetLoginHistory(ctx context.Context, userID uint, l
 
 	return histories, nil
 }
+
+// AddPasswordHistory 添加用户以前的密码，只保留最近的 keep 个
+func (r *GormUserRepository) AddPasswordHistory(ctx context.Context, history *model.PasswordHistory, keep int) error {
+	if err := r.db.WithContext(ctx).Create(history).Error; err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND id NOT IN (?)", history.UserID,
+			r.db.Model(&model.PasswordHistory{}).Select("id").
+				Where("user_id = ?", history.UserID).
+				Order("id DESC").
+				Limit(keep)).
+		Delete(&model.PasswordHistory{}).Error
+}
+
+// ListPasswordHistory 获取用户最近的 limit 个以前的密码，按替换时间倒序
+func (r *GormUserRepository) ListPasswordHistory(ctx context.Context, userID uint, limit int) ([]*model.PasswordHistory, error) {
+	var histories []*model.PasswordHistory
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&histories).Error
+	return histories, err
+}
+
+// ListByBirthday 按 ID 顺序分页获取指定月日过生日的活跃用户，afterID 为上一页最后一个用户的 ID
+func (r *GormUserRepository) ListByBirthday(ctx context.Context, month, day int, afterID uint, limit int) ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND id > ?", "active", afterID).
+		Where("EXTRACT(MONTH FROM birthday) = ? AND EXTRACT(DAY FROM birthday) = ?", month, day).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// ListBySignupDate 按 ID 顺序分页获取在指定月日注册、且注册时间早于 before 的活跃用户
+func (r *GormUserRepository) ListBySignupDate(ctx context.Context, month, day int, before time.Time, afterID uint, limit int) ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND id > ? AND created_at < ?", "active", afterID, before).
+		Where("EXTRACT(MONTH FROM created_at) = ? AND EXTRACT(DAY FROM created_at) = ?", month, day).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
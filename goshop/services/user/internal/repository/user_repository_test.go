@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/goshop/pkg/database"
+	"github.com/yourusername/goshop/pkg/testutil"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"gorm.io/gorm"
+)
+
+func TestUserRepositoryGetByEmail(t *testing.T) {
+	db := testutil.DB(t, database.WithAutoMigrate(&model.User{}, &model.Address{}))
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	id := testutil.User().Set("email", "alice@example.com").Set("role", "staff").Create(t, db)
+	testutil.User().Create(t, db)
+
+	user, err := repo.GetByEmail(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail: %v", err)
+	}
+	if user.ID != id || user.Role != "staff" || user.Status != "active" {
+		t.Errorf("GetByEmail = {ID: %d, Role: %q, Status: %q}, want {ID: %d, Role: \"staff\", Status: \"active\"}", user.ID, user.Role, user.Status, id)
+	}
+
+	if _, err := repo.GetByEmail(ctx, "nobody@example.com"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetByEmail of unknown email: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestUserRepositoryCreateDuplicate(t *testing.T) {
+	db := testutil.DB(t, database.WithAutoMigrate(&model.User{}, &model.Address{}))
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	testutil.User().Set("email", "alice@example.com").Set("username", "alice").Create(t, db)
+
+	if err := repo.Create(ctx, &model.User{Email: "alice@example.com", Username: "alice2", Role: "shopper", Status: "active"}); !errors.Is(err, ErrDuplicateUser) {
+		t.Errorf("Create with taken email: err = %v, want ErrDuplicateUser", err)
+	}
+	if err := repo.Create(ctx, &model.User{Email: "bob@example.com", Username: "bob", Role: "shopper", Status: "active"}); err != nil {
+		t.Errorf("Create: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/region"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"gorm.io/gorm"
+)
+
+// AddressRequest 表示创建或更新收货地址的请求
+type AddressRequest struct {
+	Name         string `json:"name" binding:"required,max=50"`
+	Phone        string `json:"phone" binding:"required,max=20"`
+	Province     string `json:"province" binding:"required,max=50"`
+	City         string `json:"city" binding:"required,max=50"`
+	District     string `json:"district" binding:"max=50"`
+	DetailedInfo string `json:"detailed_info" binding:"required,max=255"`
+	PostalCode   string `json:"postal_code" binding:"max=20"`
+	IsDefault    bool   `json:"is_default"`
+}
+
+// AddressService 管理用户的收货地址。省、市和区县按地区数据集校验，
+// 每个用户的地址数量有上限，第一个地址自动成为默认地址
+type AddressService struct {
+	addressRepo  repository.AddressRepository
+	regions      *region.Dataset
+	maxAddresses int
+}
+
+// NewAddressService 创建收货地址服务，每个用户最多保存 maxAddresses 个地址
+func NewAddressService(addressRepo repository.AddressRepository, regions *region.Dataset, maxAddresses int) *AddressService {
+	return &AddressService{
+		addressRepo:  addressRepo,
+		regions:      regions,
+		maxAddresses: maxAddresses,
+	}
+}
+
+// List 获取用户的收货地址，默认地址排在最前
+func (s *AddressService) List(ctx context.Context, userID uint) ([]*model.Address, error) {
+	addresses, err := s.addressRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取收货地址失败", err)
+	}
+	return addresses, nil
+}
+
+// Create 添加收货地址
+func (s *AddressService) Create(ctx context.Context, userID uint, req *AddressRequest) (*model.Address, error) {
+	address, err := s.build(req)
+	if err != nil {
+		return nil, err
+	}
+	address.UserID = userID
+	if err := s.addressRepo.Create(ctx, address, s.maxAddresses); err != nil {
+		if errors.Is(err, repository.ErrAddressLimit) {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("最多保存 %d 个收货地址", s.maxAddresses), err)
+		}
+		return nil, apperrors.NewInternalServerError("添加收货地址失败", err)
+	}
+	return address, nil
+}
+
+// Update 更新收货地址
+func (s *AddressService) Update(ctx context.Context, userID, id uint, req *AddressRequest) (*model.Address, error) {
+	address, err := s.build(req)
+	if err != nil {
+		return nil, err
+	}
+	address.ID = id
+	address.UserID = userID
+	if err := s.addressRepo.Update(ctx, address); err != nil {
+		return nil, addressError(err, "更新收货地址失败")
+	}
+	return s.get(ctx, userID, id)
+}
+
+// Delete 删除收货地址，删除默认地址时最近添加的地址成为默认地址
+func (s *AddressService) Delete(ctx context.Context, userID, id uint) error {
+	if err := s.addressRepo.Delete(ctx, userID, id); err != nil {
+		return addressError(err, "删除收货地址失败")
+	}
+	return nil
+}
+
+// SetDefault 将收货地址设为默认地址
+func (s *AddressService) SetDefault(ctx context.Context, userID, id uint) (*model.Address, error) {
+	if err := s.addressRepo.SetDefault(ctx, userID, id); err != nil {
+		return nil, addressError(err, "设置默认地址失败")
+	}
+	return s.get(ctx, userID, id)
+}
+
+func (s *AddressService) get(ctx context.Context, userID, id uint) (*model.Address, error) {
+	address, err := s.addressRepo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, addressError(err, "获取收货地址失败")
+	}
+	return address, nil
+}
+
+// build 校验请求中的地区并生成地址
+func (s *AddressService) build(req *AddressRequest) (*model.Address, error) {
+	address := &model.Address{
+		Name:         strings.TrimSpace(req.Name),
+		Phone:        strings.TrimSpace(req.Phone),
+		Province:     strings.TrimSpace(req.Province),
+		City:         strings.TrimSpace(req.City),
+		District:     strings.TrimSpace(req.District),
+		DetailedInfo: strings.TrimSpace(req.DetailedInfo),
+		PostalCode:   strings.TrimSpace(req.PostalCode),
+		IsDefault:    req.IsDefault,
+	}
+	if err := s.regions.Validate(address.Province, address.City, address.District); err != nil {
+		return nil, apperrors.NewBadRequest(err.Error(), nil)
+	}
+	return address, nil
+}
+
+// addressError 转换仓库返回的错误，地址不存在或不属于当前用户时返回 404
+func addressError(err error, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NewNotFound("收货地址不存在", err)
+	}
+	return apperrors.NewInternalServerError(message, err)
+}
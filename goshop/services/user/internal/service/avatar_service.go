@@ -0,0 +1,168 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/pkg/storage"
+	"github.com/yourusername/goshop/services/user/internal/event"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"go.uber.org/zap"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器
+	"gorm.io/gorm"
+)
+
+// maxAvatarPixels 是头像原图的最大像素数，避免解码超大尺寸的图片耗尽内存
+const maxAvatarPixels = 40_000_000
+
+// avatarTypes 是允许上传的头像类型
+var avatarTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// AvatarService 处理用户头像上传。图片居中裁剪为正方形并缩放到统一尺寸后保存到对象存储，
+// 每次上传使用新的对象，保存成功后删除旧头像并发布 user.updated 事件
+type AvatarService struct {
+	userRepo repository.UserRepository
+	store    storage.Store
+	outbox   *events.Outbox
+	maxSize  int64
+	size     int
+	log      *logger.Logger
+}
+
+// NewAvatarService 创建头像服务
+func NewAvatarService(userRepo repository.UserRepository, store storage.Store, outbox *events.Outbox, cfg config.UserConfig, log *logger.Logger) *AvatarService {
+	return &AvatarService{
+		userRepo: userRepo,
+		store:    store,
+		outbox:   outbox,
+		maxSize:  int64(cfg.AvatarMaxSize),
+		size:     cfg.AvatarSize,
+		log:      log,
+	}
+}
+
+// MaxSize 返回头像文件的大小上限
+func (s *AvatarService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload 保存用户上传的头像并返回更新后的用户，支持 JPEG、PNG 和 WebP。
+// 文件类型按内容判断，不信任客户端声明的类型
+func (s *AvatarService) Upload(ctx context.Context, userID uint, file io.Reader, size int64) (*model.User, error) {
+	if size > s.maxSize {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("头像不能超过 %d MB", s.maxSize>>20), nil)
+	}
+	data, err := io.ReadAll(io.LimitReader(file, s.maxSize+1))
+	if err != nil {
+		return nil, apperrors.NewBadRequest("读取头像失败", err)
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("头像不能超过 %d MB", s.maxSize>>20), nil)
+	}
+	if !avatarTypes[http.DetectContentType(data)] {
+		return nil, apperrors.NewBadRequest("头像只支持 JPEG、PNG 和 WebP 格式", nil)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("用户不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+
+	body, contentType, ext, err := s.resize(data)
+	if err != nil {
+		return nil, err
+	}
+	name, err := randomString(12)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("保存头像失败", err)
+	}
+	key := fmt.Sprintf("avatars/%d/%s.%s", userID, name, ext)
+	if err := s.store.Put(ctx, key, bytes.NewReader(body), contentType); err != nil {
+		return nil, apperrors.NewServiceUnavailable("保存头像失败，请稍后再试", err)
+	}
+
+	oldAvatar, avatar := user.Avatar, s.store.URL(key)
+	user.Avatar = &avatar
+	err = s.userRepo.Transaction(ctx, func(repo repository.UserRepository, tx *gorm.DB) error {
+		if err := repo.UpdateAvatar(ctx, userID, avatar); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, tx, event.UserUpdated, event.DomainEventVersion, updatedEvent(user, []string{"avatar"}))
+	})
+	if err != nil {
+		s.deleteObject(ctx, key)
+		return nil, apperrors.NewInternalServerError("更新头像失败", err)
+	}
+
+	// 旧头像可能是社交账号的头像地址，只删除保存在对象存储中的头像
+	if oldAvatar != nil {
+		if oldKey, ok := s.store.Key(*oldAvatar); ok {
+			s.deleteObject(ctx, oldKey)
+		}
+	}
+	return user, nil
+}
+
+// resize 解码图片，居中裁剪为正方形并缩放到头像尺寸。PNG 保持 PNG 以保留透明度，其他格式编码为 JPEG
+func (s *AvatarService) resize(data []byte) ([]byte, string, string, error) {
+	invalid := apperrors.NewBadRequest("无法识别的图片", nil)
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", "", invalid
+	}
+	if cfg.Width*cfg.Height > maxAvatarPixels {
+		return nil, "", "", apperrors.NewBadRequest("图片尺寸过大", nil)
+	}
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", "", invalid
+	}
+
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+	dst := image.NewRGBA(image.Rect(0, 0, s.size, s.size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if format == "png" {
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, "", "", apperrors.NewInternalServerError("处理头像失败", err)
+		}
+		return buf.Bytes(), "image/png", "png", nil
+	}
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, "", "", apperrors.NewInternalServerError("处理头像失败", err)
+	}
+	return buf.Bytes(), "image/jpeg", "jpg", nil
+}
+
+// deleteObject 删除对象存储中的头像，失败只记录日志
+func (s *AvatarService) deleteObject(ctx context.Context, key string) {
+	if err := s.store.Delete(ctx, key); err != nil {
+		s.log.Warn(ctx, "删除头像失败", zap.String("key", key), zap.Error(err))
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	authrpc "github.com/yourusername/goshop/services/auth/rpc"
+	"github.com/yourusername/goshop/services/user/internal/event"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// failureScript 增加失败次数，第一次失败时设置计数的过期时间，窗口结束后计数自动清零
+var failureScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then redis.call('EXPIRE', KEYS[1], ARGV[1]) end
+return count
+`)
+
+// LockStatus 是账号的锁定状态
+type LockStatus struct {
+	UserID      uint       `json:"user_id"`
+	Locked      bool       `json:"locked"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	Failures    int64      `json:"failures"` // 当前窗口内连续失败的次数
+}
+
+// LockoutService 防止暴力破解密码。连续登录失败和修改密码时当前密码错误的次数按账号和客户端 IP 记录在 Redis 中，
+// 账号失败达到上限后暂时锁定并向用户发送解锁邮件，IP 失败达到上限后在窗口结束前不允许登录。
+// 后台可以查看和解除账号的锁定。Redis 不可用时只记录日志，不影响登录
+type LockoutService struct {
+	rdb      *redis.Client
+	userRepo repository.UserRepository
+	auth     *authrpc.Client
+	outbox   *events.Outbox
+	cfg      config.LockoutConfig
+	log      *logger.Logger
+}
+
+// NewLockoutService 创建登录锁定服务
+func NewLockoutService(rdb *redis.Client, userRepo repository.UserRepository, auth *authrpc.Client, outbox *events.Outbox, cfg config.LockoutConfig, log *logger.Logger) *LockoutService {
+	return &LockoutService{
+		rdb:      rdb,
+		userRepo: userRepo,
+		auth:     auth,
+		outbox:   outbox,
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+// CheckIP 在客户端 IP 失败次数达到上限时返回 429
+func (s *LockoutService) CheckIP(ctx context.Context, ip string) error {
+	count, err := s.rdb.Get(ctx, ipFailuresKey(ip)).Int64()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			s.log.Warn(ctx, "获取 IP 登录失败次数失败", zap.String("ip", ip), zap.Error(err))
+		}
+		return nil
+	}
+	if count >= int64(s.cfg.MaxIPFailures) {
+		return apperrors.NewTooManyRequests("登录失败次数过多，请稍后再试", nil)
+	}
+	return nil
+}
+
+// LockedUntil 返回账号锁定的截止时间，账号未锁定时返回零值
+func (s *LockoutService) LockedUntil(ctx context.Context, userID uint) time.Time {
+	ttl, err := s.rdb.PTTL(ctx, lockKey(userID)).Result()
+	if err != nil {
+		s.log.Warn(ctx, "获取账号锁定状态失败", zap.Uint("user_id", userID), zap.Error(err))
+		return time.Time{}
+	}
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// RecordIPFailure 记录来自客户端 IP 的一次登录失败，用于账号不存在时
+func (s *LockoutService) RecordIPFailure(ctx context.Context, ip string) {
+	if _, err := s.incr(ctx, ipFailuresKey(ip)); err != nil {
+		s.log.Warn(ctx, "记录 IP 登录失败失败", zap.String("ip", ip), zap.Error(err))
+	}
+}
+
+// RecordFailure 记录用户的一次密码错误，失败次数达到上限时锁定账号、发送解锁邮件并返回锁定的截止时间，
+// 未锁定时返回零值
+func (s *LockoutService) RecordFailure(ctx context.Context, user *model.User, ip string) time.Time {
+	s.RecordIPFailure(ctx, ip)
+	count, err := s.incr(ctx, failuresKey(user.ID))
+	if err != nil {
+		s.log.Warn(ctx, "记录登录失败失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		return time.Time{}
+	}
+	if count < int64(s.cfg.MaxFailures) {
+		return time.Time{}
+	}
+
+	duration := time.Duration(s.cfg.LockDuration) * time.Minute
+	lockedUntil := time.Now().Add(duration)
+	locked, err := s.rdb.SetNX(ctx, lockKey(user.ID), lockedUntil.Unix(), duration).Result()
+	if err != nil {
+		s.log.Warn(ctx, "锁定账号失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		return time.Time{}
+	}
+	if err := s.rdb.Del(ctx, failuresKey(user.ID)).Err(); err != nil {
+		s.log.Warn(ctx, "清除登录失败次数失败", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	if !locked {
+		// 并发的失败已经锁定了账号，解锁邮件由那次失败发送
+		return s.LockedUntil(ctx, user.ID)
+	}
+	s.log.Warn(ctx, "账号因多次登录失败被锁定", zap.Uint("user_id", user.ID), zap.String("ip", ip), zap.Time("locked_until", lockedUntil))
+	if err := s.sendUnlock(ctx, user, ip, lockedUntil); err != nil {
+		s.log.Warn(ctx, "发送解锁邮件失败", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	return lockedUntil
+}
+
+// Reset 在登录成功后清除账号的失败次数
+func (s *LockoutService) Reset(ctx context.Context, userID uint) {
+	if err := s.rdb.Del(ctx, failuresKey(userID)).Err(); err != nil {
+		s.log.Warn(ctx, "清除登录失败次数失败", zap.Uint("user_id", userID), zap.Error(err))
+	}
+}
+
+// Unlock 使用解锁邮件中的令牌解除账号的锁定，令牌只能使用一次
+func (s *LockoutService) Unlock(ctx context.Context, token string) (*model.User, error) {
+	if token == "" {
+		return nil, apperrors.NewBadRequest("解锁链接无效或已过期", nil)
+	}
+	reply, err := s.auth.ConsumeVerification(ctx, &authrpc.ConsumeVerificationRequest{
+		Token: token,
+		Type:  authrpc.VerificationAccountUnlock,
+	})
+	if err != nil {
+		return nil, authError(err)
+	}
+	user, err := s.getUser(ctx, reply.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.unlock(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	s.log.Info(ctx, "用户通过邮件解锁账号", zap.Uint("user_id", user.ID))
+	return user, nil
+}
+
+// Status 获取账号的锁定状态
+func (s *LockoutService) Status(ctx context.Context, userID uint) (*LockStatus, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	failures, err := s.rdb.Get(ctx, failuresKey(userID)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, apperrors.NewServiceUnavailable("获取账号锁定状态失败", err)
+	}
+	ttl, err := s.rdb.PTTL(ctx, lockKey(userID)).Result()
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable("获取账号锁定状态失败", err)
+	}
+	status := &LockStatus{UserID: userID, Failures: failures}
+	if ttl > 0 {
+		lockedUntil := time.Now().Add(ttl)
+		status.Locked, status.LockedUntil = true, &lockedUntil
+	}
+	return status, nil
+}
+
+// AdminUnlock 由后台解除账号的锁定并清除失败次数
+func (s *LockoutService) AdminUnlock(ctx context.Context, userID, operatorID uint) error {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.unlock(ctx, userID); err != nil {
+		return err
+	}
+	s.log.Info(ctx, "后台解锁账号", zap.Uint("user_id", userID), zap.Uint("operator_id", operatorID))
+	return nil
+}
+
+// sendUnlock 签发解锁令牌并发布账号锁定事件，由通知服务发送解锁邮件。令牌在锁定结束时过期
+func (s *LockoutService) sendUnlock(ctx context.Context, user *model.User, ip string, lockedUntil time.Time) error {
+	reply, err := s.auth.IssueVerification(ctx, &authrpc.IssueVerificationRequest{
+		UserID: user.ID,
+		Type:   authrpc.VerificationAccountUnlock,
+		TTL:    s.cfg.LockDuration * int(time.Minute/time.Second),
+	})
+	if err != nil {
+		return err
+	}
+	link, err := url.Parse(s.cfg.UnlockURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", reply.Token)
+	link.RawQuery = query.Encode()
+
+	return s.userRepo.Transaction(ctx, func(_ repository.UserRepository, tx *gorm.DB) error {
+		return s.outbox.Add(ctx, tx, event.AccountLocked, event.DomainEventVersion, &event.AccountLockedEvent{
+			UserID:      user.ID,
+			Email:       user.Email,
+			Name:        user.DisplayName(),
+			IP:          ip,
+			UnlockURL:   link.String(),
+			LockedUntil: lockedUntil,
+		})
+	})
+}
+
+// unlock 删除账号的锁定和失败次数
+func (s *LockoutService) unlock(ctx context.Context, userID uint) error {
+	if err := s.rdb.Del(ctx, lockKey(userID), failuresKey(userID)).Err(); err != nil {
+		return apperrors.NewServiceUnavailable("解锁账号失败，请稍后再试", err)
+	}
+	return nil
+}
+
+func (s *LockoutService) getUser(ctx context.Context, userID uint) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("用户不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	return user, nil
+}
+
+// incr 增加 key 的失败次数，计数在窗口结束后过期
+func (s *LockoutService) incr(ctx context.Context, key string) (int64, error) {
+	window := s.cfg.Window * int(time.Minute/time.Second)
+	return failureScript.Run(ctx, s.rdb, []string{key}, window).Int64()
+}
+
+func failuresKey(userID uint) string {
+	return fmt.Sprintf("user:login:failures:%d", userID)
+}
+
+func ipFailuresKey(ip string) string {
+	return "user:login:ip_failures:" + ip
+}
+
+func lockKey(userID uint) string {
+	return fmt.Sprintf("user:login:lock:%d", userID)
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	authrpc "github.com/yourusername/goshop/services/auth/rpc"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// 登录失败原因，记录在登录历史中
+const (
+	loginReasonWrongPassword = "wrong_password"
+	loginReasonInactive      = "inactive"
+	loginReasonLocked        = "locked"
+	loginReasonLockout       = "lockout"
+)
+
+// LoginRequest 表示登录请求，login 可以是邮箱或用户名
+type LoginRequest struct {
+	Login    string `json:"login" binding:"required,max=255"`
+	Password string `json:"password" binding:"required,max=72"`
+}
+
+// LoginClient 表示发起登录的客户端
+type LoginClient struct {
+	IP        string
+	UserAgent string
+}
+
+// LoginResult 是登录成功后签发的令牌和用户资料
+type LoginResult struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        int64       `json:"expires_in"` // 访问令牌的有效秒数
+	ExpiresAt        time.Time   `json:"expires_at"`
+	RefreshToken     string      `json:"refresh_token"`
+	RefreshExpiresAt time.Time   `json:"refresh_expires_at"`
+	User             *model.User `json:"user"`
+}
+
+// LoginService 验证用户的密码并通过认证服务签发令牌，登录结果记录在登录历史中
+type LoginService struct {
+	userRepo repository.UserRepository
+	auth     *authrpc.Client
+	lockout  *LockoutService
+	log      *logger.Logger
+}
+
+// NewLoginService 创建登录服务
+func NewLoginService(userRepo repository.UserRepository, auth *authrpc.Client, lockout *LockoutService, log *logger.Logger) *LoginService {
+	return &LoginService{
+		userRepo: userRepo,
+		auth:     auth,
+		lockout:  lockout,
+		log:      log,
+	}
+}
+
+// Login 验证密码并签发访问令牌和刷新令牌。用户不存在和密码错误返回相同的错误，
+// 不向客户端透露账号是否存在；连续多次密码错误的账号被暂时锁定，失败过多的 IP 暂时不能登录
+func (s *LoginService) Login(ctx context.Context, req *LoginRequest, client *LoginClient) (*LoginResult, error) {
+	invalid := apperrors.NewUnauthorized("账号或密码错误", nil)
+	login := strings.TrimSpace(req.Login)
+
+	if err := s.lockout.CheckIP(ctx, client.IP); err != nil {
+		return nil, err
+	}
+
+	var (
+		user *model.User
+		err  error
+	)
+	if strings.Contains(login, "@") {
+		user, err = s.userRepo.GetByEmail(ctx, strings.ToLower(login))
+	} else {
+		user, err = s.userRepo.GetByUsername(ctx, login)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.lockout.RecordIPFailure(ctx, client.IP)
+			return nil, invalid
+		}
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+
+	if lockedUntil := s.lockout.LockedUntil(ctx, user.ID); !lockedUntil.IsZero() {
+		s.record(ctx, user.ID, client, loginReasonLocked)
+		return nil, lockedError(lockedUntil)
+	}
+	hash, err := s.userRepo.GetPasswordHash(ctx, user.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password)) != nil {
+		if lockedUntil := s.lockout.RecordFailure(ctx, user, client.IP); !lockedUntil.IsZero() {
+			s.record(ctx, user.ID, client, loginReasonLockout)
+			return nil, lockedError(lockedUntil)
+		}
+		s.record(ctx, user.ID, client, loginReasonWrongPassword)
+		return nil, invalid
+	}
+	s.lockout.Reset(ctx, user.ID)
+	return s.issue(ctx, user, client)
+}
+
+// issue 为已验证身份的用户签发令牌，密码登录和社交账号登录签发相同的令牌
+func (s *LoginService) issue(ctx context.Context, user *model.User, client *LoginClient) (*LoginResult, error) {
+	if user.Status != "active" {
+		s.record(ctx, user.ID, client, loginReasonInactive)
+		return nil, apperrors.NewForbidden("账号已停用", nil)
+	}
+
+	tokens, err := s.auth.IssueTokens(ctx, &authrpc.IssueTokensRequest{
+		UserID:      user.ID,
+		Name:        user.DisplayName(),
+		Role:        user.Role,
+		MemberLevel: user.MemberLevel,
+		IP:          client.IP,
+		UserAgent:   client.UserAgent,
+	})
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable("暂时无法登录，请稍后再试", err)
+	}
+	s.record(ctx, user.ID, client, "")
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
+		s.log.Warn(ctx, "更新最后登录时间失败", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+
+	return &LoginResult{
+		AccessToken:      tokens.AccessToken,
+		TokenType:        tokens.TokenType,
+		ExpiresIn:        tokens.ExpiresIn,
+		ExpiresAt:        tokens.ExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+		User:             user,
+	}, nil
+}
+
+// record 记录登录历史，reason 为空表示登录成功。记录失败不影响登录
+func (s *LoginService) record(ctx context.Context, userID uint, client *LoginClient, reason string) {
+	history := &model.LoginHistory{
+		UserID:    userID,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Failed:    reason != "",
+		Reason:    reason,
+	}
+	if err := s.userRepo.AddLoginHistory(ctx, history); err != nil {
+		s.log.Warn(ctx, "记录登录历史失败", zap.Uint("user_id", userID), zap.Error(err))
+	}
+}
+
+// lockedError 返回账号被锁定的错误，告知用户解锁的时间和方式
+func lockedError(lockedUntil time.Time) error {
+	minutes := int(time.Until(lockedUntil).Minutes()) + 1
+	return apperrors.NewTooManyRequests(fmt.Sprintf("密码错误次数过多，账号已锁定，请在 %d 分钟后重试或通过邮件中的链接解锁", minutes), nil)
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/user/internal/event"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/oauth"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// oauthState 是授权请求的 state 参数，签名后在回调时验证
+type oauthState struct {
+	Provider  string `json:"p"`
+	UserID    uint   `json:"u,omitempty"` // 已登录用户关联社交账号时为用户 ID
+	Nonce     string `json:"n"`           // 同时保存在发起授权的浏览器的 Cookie 中
+	ExpiresAt int64  `json:"e"`
+}
+
+// Authorization 是发起社交账号授权的结果，客户端跳转到 URL 完成授权
+type Authorization struct {
+	URL       string    `json:"url"`
+	Nonce     string    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// OAuthService 通过 Google、GitHub 和微信登录。首次登录时按服务商验证过的邮箱关联已有用户，
+// 没有对应用户时创建用户；已登录的用户也可以主动关联社交账号。登录成功后签发与密码登录相同的令牌
+type OAuthService struct {
+	userRepo    repository.UserRepository
+	accountRepo repository.SocialAccountRepository
+	login       *LoginService
+	outbox      *events.Outbox
+	providers   map[string]oauth.Provider
+	secret      []byte
+	stateTTL    time.Duration
+	log         *logger.Logger
+	now         func() time.Time
+}
+
+// NewOAuthService 创建社交账号登录服务，只启用 cfg 中配置了的服务商
+func NewOAuthService(userRepo repository.UserRepository, accountRepo repository.SocialAccountRepository, login *LoginService, outbox *events.Outbox, cfg config.OAuthConfig, log *logger.Logger) *OAuthService {
+	return &OAuthService{
+		userRepo:    userRepo,
+		accountRepo: accountRepo,
+		login:       login,
+		outbox:      outbox,
+		providers:   oauth.FromConfig(cfg),
+		secret:      []byte(cfg.StateSecret),
+		stateTTL:    time.Duration(cfg.StateTTL) * time.Second,
+		log:         log,
+		now:         time.Now,
+	}
+}
+
+// Authorize 生成服务商的授权地址。userID 不为 0 时授权完成后将社交账号关联到该用户
+func (s *OAuthService) Authorize(ctx context.Context, provider string, userID uint) (*Authorization, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, apperrors.NewNotFound("不支持的登录方式", nil)
+	}
+	nonce, err := randomString(16)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成授权请求失败", err)
+	}
+	expiresAt := s.now().Add(s.stateTTL)
+	state, err := s.signState(&oauthState{
+		Provider:  provider,
+		UserID:    userID,
+		Nonce:     nonce,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成授权请求失败", err)
+	}
+	return &Authorization{
+		URL:       p.AuthCodeURL(state),
+		Nonce:     nonce,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Callback 处理服务商的授权回调并签发令牌。nonce 是发起授权时保存在浏览器中的值，
+// 用于确认回调来自发起授权的浏览器
+func (s *OAuthService) Callback(ctx context.Context, provider, code, state, nonce string, client *LoginClient) (*LoginResult, error) {
+	expired := apperrors.NewBadRequest("授权已失效，请重新登录", nil)
+	st, err := s.verifyState(state)
+	if err != nil || st.Provider != provider || subtle.ConstantTimeCompare([]byte(st.Nonce), []byte(nonce)) != 1 {
+		return nil, expired
+	}
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, apperrors.NewNotFound("不支持的登录方式", nil)
+	}
+	if code == "" {
+		return nil, expired
+	}
+
+	identity, err := p.Identify(ctx, code)
+	if err != nil {
+		if errors.Is(err, oauth.ErrInvalidCode) {
+			return nil, expired
+		}
+		s.log.Error(ctx, "获取社交账号身份失败", zap.String("provider", provider), zap.Error(err))
+		return nil, apperrors.NewServiceUnavailable("暂时无法登录，请稍后再试", err)
+	}
+
+	user, err := s.resolveUser(ctx, provider, identity, st.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.login.issue(ctx, user, client)
+}
+
+// resolveUser 返回社交账号关联的用户，首次登录时关联或创建用户。
+// 为防止他人预先用同一邮箱注册并设置密码，只有邮箱已验证的用户才会自动关联
+func (s *OAuthService) resolveUser(ctx context.Context, provider string, identity *oauth.Identity, linkUserID uint) (*model.User, error) {
+	account, err := s.accountRepo.GetBySubject(ctx, provider, identity.Subject)
+	switch {
+	case err == nil:
+		if linkUserID != 0 && account.UserID != linkUserID {
+			return nil, apperrors.NewConflict("该社交账号已关联其他用户", nil)
+		}
+		return s.getUser(ctx, account.UserID)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, apperrors.NewInternalServerError("获取社交账号失败", err)
+	}
+
+	email := strings.ToLower(strings.TrimSpace(identity.Email))
+	var user *model.User
+	switch {
+	case linkUserID != 0:
+		if user, err = s.getUser(ctx, linkUserID); err != nil {
+			return nil, err
+		}
+	case email != "" && identity.EmailVerified:
+		user, err = s.userRepo.GetByEmail(ctx, email)
+		switch {
+		case err == nil:
+			if !user.EmailVerified {
+				return nil, apperrors.NewConflict("邮箱已被注册，请使用密码登录后关联该账号", nil)
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if user, err = s.createUser(ctx, provider, email, identity); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, apperrors.NewInternalServerError("获取用户失败", err)
+		}
+	default:
+		// 微信等不提供已验证邮箱的服务商不能创建用户，只能由已登录的用户关联
+		return nil, apperrors.NewBadRequest("该账号没有已验证的邮箱，请先注册后在账户中关联", nil)
+	}
+
+	// 关联失败时已创建的用户保留，邮箱已验证，重新登录时按邮箱关联
+	if err := s.accountRepo.Create(ctx, &model.SocialAccount{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  identity.Subject,
+		Email:    email,
+		Name:     identity.Name,
+	}); err != nil {
+		return nil, apperrors.NewInternalServerError("关联社交账号失败", err)
+	}
+	s.log.Info(ctx, "社交账号已关联", zap.Uint("user_id", user.ID), zap.String("provider", provider))
+	return user, nil
+}
+
+// createUser 为首次登录的社交账号创建用户并发布 user.registered 事件。用户没有密码，不能使用密码登录
+func (s *OAuthService) createUser(ctx context.Context, provider, email string, identity *oauth.Identity) (*model.User, error) {
+	username, err := s.availableUsername(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	firstName, lastName := identity.FirstName, identity.LastName
+	if firstName == "" && lastName == "" {
+		firstName = identity.Name
+	}
+	user := &model.User{
+		Email:         email,
+		Username:      username,
+		FirstName:     truncate(firstName, 50),
+		LastName:      truncate(lastName, 50),
+		Role:          "shopper",
+		Status:        "active",
+		EmailVerified: true,
+	}
+	if identity.Avatar != "" {
+		avatar := truncate(identity.Avatar, 255)
+		user.Avatar = &avatar
+	}
+	err = s.userRepo.Transaction(ctx, func(repo repository.UserRepository, tx *gorm.DB) error {
+		if err := repo.Create(ctx, user); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, tx, event.UserRegistered, event.DomainEventVersion, registeredEvent(user, provider))
+	})
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("创建用户失败", err)
+	}
+	return user, nil
+}
+
+// availableUsername 按邮箱生成未被使用的用户名，被占用时追加随机数字
+func (s *OAuthService) availableUsername(ctx context.Context, email string) (string, error) {
+	base := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return -1
+	}, strings.SplitN(email, "@", 2)[0])
+	base = truncate(base, 40)
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		_, err := s.userRepo.GetByUsername(ctx, candidate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", apperrors.NewInternalServerError("获取用户失败", err)
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+		if err != nil {
+			return "", apperrors.NewInternalServerError("创建用户失败", err)
+		}
+		candidate = fmt.Sprintf("%s%06d", base, n.Int64())
+	}
+	return "", apperrors.NewConflict("无法生成用户名，请稍后再试", nil)
+}
+
+func (s *OAuthService) getUser(ctx context.Context, id uint) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("用户不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	return user, nil
+}
+
+// signState 序列化并签名 state，格式为 base64(JSON).base64(HMAC-SHA256)
+func (s *OAuthService) signState(st *oauthState) (string, error) {
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+// verifyState 验证 state 的签名和有效期
+func (s *OAuthService) verifyState(state string) (*oauthState, error) {
+	encoded, signature, ok := strings.Cut(state, ".")
+	if !ok {
+		return nil, errors.New("malformed state")
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return nil, errors.New("invalid state signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var st oauthState
+	if err := json.Unmarshal(payload, &st); err != nil {
+		return nil, err
+	}
+	if s.now().Unix() >= st.ExpiresAt {
+		return nil, errors.New("state expired")
+	}
+	return &st, nil
+}
+
+func (s *OAuthService) sign(data string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// randomString 生成 n 个随机字节，以 URL 安全的 base64 编码
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/logger"
+	authrpc "github.com/yourusername/goshop/services/auth/rpc"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ChangePasswordRequest 表示修改密码的请求
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,max=72"`
+	NewPassword     string `json:"new_password" binding:"required,max=72"`
+}
+
+// PasswordService 修改用户的密码。新密码必须符合密码策略，且不能与当前密码和最近使用过的密码相同；
+// 修改后作废用户的全部刷新令牌，其他设备需要重新登录，当前客户端获得新的令牌。
+// 当前密码错误与登录失败一起计数，防止通过盗用的令牌暴力破解密码
+type PasswordService struct {
+	userRepo repository.UserRepository
+	auth     *authrpc.Client
+	lockout  *LockoutService
+	policy   config.PasswordPolicyConfig
+	log      *logger.Logger
+}
+
+// NewPasswordService 创建密码服务
+func NewPasswordService(userRepo repository.UserRepository, auth *authrpc.Client, lockout *LockoutService, policy config.PasswordPolicyConfig, log *logger.Logger) *PasswordService {
+	return &PasswordService{
+		userRepo: userRepo,
+		auth:     auth,
+		lockout:  lockout,
+		policy:   policy,
+		log:      log,
+	}
+}
+
+// Change 验证当前密码后修改为新密码，返回为当前客户端签发的新令牌
+func (s *PasswordService) Change(ctx context.Context, userID uint, req *ChangePasswordRequest, client *LoginClient) (*LoginResult, error) {
+	if err := checkPassword(s.policy, req.NewPassword); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("用户不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	if lockedUntil := s.lockout.LockedUntil(ctx, user.ID); !lockedUntil.IsZero() {
+		return nil, lockedError(lockedUntil)
+	}
+	// 缓存的用户不包含密码哈希，从数据库读取
+	if user.Password, err = s.userRepo.GetPasswordHash(ctx, userID); err != nil {
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	// 通过社交账号注册的用户没有密码
+	if user.Password == "" {
+		return nil, apperrors.NewBadRequest("账号未设置密码", nil)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)) != nil {
+		if lockedUntil := s.lockout.RecordFailure(ctx, user, client.IP); !lockedUntil.IsZero() {
+			return nil, lockedError(lockedUntil)
+		}
+		return nil, apperrors.NewBadRequest("当前密码错误", nil)
+	}
+	s.lockout.Reset(ctx, user.ID)
+	if err := s.checkReuse(ctx, user, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("修改密码失败", err)
+	}
+	err = s.userRepo.Transaction(ctx, func(repo repository.UserRepository, tx *gorm.DB) error {
+		if err := repo.UpdateFields(ctx, user.ID, map[string]interface{}{"password": string(hash)}); err != nil {
+			return err
+		}
+		if s.policy.History == 0 {
+			return nil
+		}
+		return repo.AddPasswordHistory(ctx, &model.PasswordHistory{UserID: user.ID, Hash: user.Password}, s.policy.History)
+	})
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("修改密码失败", err)
+	}
+	s.log.Info(ctx, "用户修改了密码", zap.Uint("user_id", user.ID), zap.String("ip", client.IP))
+
+	// 密码已经修改，作废令牌失败时仍然返回成功，由用户在其他设备上退出登录
+	if _, err := s.auth.RevokeTokens(ctx, &authrpc.RevokeTokensRequest{UserID: user.ID}); err != nil {
+		s.log.Error(ctx, "作废刷新令牌失败", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	tokens, err := s.auth.IssueTokens(ctx, &authrpc.IssueTokensRequest{
+		UserID:      user.ID,
+		Name:        user.DisplayName(),
+		Role:        user.Role,
+		MemberLevel: user.MemberLevel,
+		IP:          client.IP,
+		UserAgent:   client.UserAgent,
+	})
+	if err != nil {
+		return nil, apperrors.NewServiceUnavailable("密码已修改，请重新登录", err)
+	}
+	user.Password = string(hash)
+	return &LoginResult{
+		AccessToken:      tokens.AccessToken,
+		TokenType:        tokens.TokenType,
+		ExpiresIn:        tokens.ExpiresIn,
+		ExpiresAt:        tokens.ExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+		User:             user,
+	}, nil
+}
+
+// checkReuse 检查新密码是否与当前密码或最近使用过的密码相同
+func (s *PasswordService) checkReuse(ctx context.Context, user *model.User, password string) error {
+	hashes := []string{user.Password}
+	if s.policy.History > 0 {
+		histories, err := s.userRepo.ListPasswordHistory(ctx, user.ID, s.policy.History)
+		if err != nil {
+			return apperrors.NewInternalServerError("获取密码历史失败", err)
+		}
+		for _, history := range histories {
+			hashes = append(hashes, history.Hash)
+		}
+	}
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			if s.policy.History == 0 {
+				return apperrors.NewBadRequest("新密码不能与当前密码相同", nil)
+			}
+			return apperrors.NewBadRequest(fmt.Sprintf("新密码不能与最近使用过的 %d 个密码相同", s.policy.History+1), nil)
+		}
+	}
+	return nil
+}
+
+// checkPassword 检查密码是否符合密码策略，注册和修改密码时使用
+func checkPassword(policy config.PasswordPolicyConfig, password string) error {
+	// bcrypt 只使用密码的前 72 个字节，更长的密码不能安全地保存
+	if len(password) > 72 {
+		return apperrors.NewBadRequest("密码不能超过 72 个字节", nil)
+	}
+	if len([]rune(password)) < policy.MinLength {
+		return apperrors.NewBadRequest(fmt.Sprintf("密码至少需要 %d 个字符", policy.MinLength), nil)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	var missing []string
+	if policy.RequireUpper && !upper {
+		missing = append(missing, "大写字母")
+	}
+	if policy.RequireLower && !lower {
+		missing = append(missing, "小写字母")
+	}
+	if policy.RequireDigit && !digit {
+		missing = append(missing, "数字")
+	}
+	if policy.RequireSymbol && !symbol {
+		missing = append(missing, "符号")
+	}
+	if len(missing) > 0 {
+		return apperrors.NewBadRequest("密码需要包含"+strings.Join(missing, "、"), nil)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/user/internal/event"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"gorm.io/gorm"
+)
+
+// UpdatePreferencesRequest 表示更新用户偏好的请求，未提供的字段保持不变
+type UpdatePreferencesRequest struct {
+	Locale            *string `json:"locale" binding:"omitempty,max=10"`
+	Currency          *string `json:"currency" binding:"omitempty,len=3"`
+	MarketingEmails   *bool   `json:"marketing_emails"`
+	PushNotifications *bool   `json:"push_notifications"`
+	DefaultAddressID  *uint   `json:"default_address_id" binding:"omitempty,min=1"`
+}
+
+// PreferenceService 管理用户的语言、货币、营销邮件和推送订阅以及默认地址。
+// 偏好变更后发布事件，通知服务据此选择通知的语言并决定是否发送营销邮件和推送
+type PreferenceService struct {
+	preferenceRepo repository.PreferenceRepository
+	addressRepo    repository.AddressRepository
+	outbox         *events.Outbox
+	i18n           config.I18nConfig
+	currency       config.CurrencyConfig
+	log            *logger.Logger
+}
+
+// NewPreferenceService 创建用户偏好服务，语言和货币只能选择 i18n 和 currency 中支持的值
+func NewPreferenceService(preferenceRepo repository.PreferenceRepository, addressRepo repository.AddressRepository, outbox *events.Outbox, i18n config.I18nConfig, currency config.CurrencyConfig, log *logger.Logger) *PreferenceService {
+	return &PreferenceService{
+		preferenceRepo: preferenceRepo,
+		addressRepo:    addressRepo,
+		outbox:         outbox,
+		i18n:           i18n,
+		currency:       currency,
+		log:            log,
+	}
+}
+
+// Get 获取用户的偏好，没有保存过时返回默认值
+func (s *PreferenceService) Get(ctx context.Context, userID uint) (*model.UserPreference, error) {
+	preference, err := s.preferenceRepo.Get(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		preference, err = &model.UserPreference{
+			UserID:            userID,
+			Locale:            s.i18n.DefaultLocale,
+			Currency:          s.currency.Base,
+			PushNotifications: true,
+		}, nil
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取用户偏好失败", err)
+	}
+
+	address, err := s.addressRepo.GetDefault(ctx, userID)
+	switch {
+	case err == nil:
+		preference.DefaultAddressID = &address.ID
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, apperrors.NewInternalServerError("获取默认地址失败", err)
+	}
+	return preference, nil
+}
+
+// Update 更新用户的偏好。默认地址在地址簿中设置，与将地址设为默认地址相同
+func (s *PreferenceService) Update(ctx context.Context, userID uint, req *UpdatePreferencesRequest) (*model.UserPreference, error) {
+	preference, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Locale != nil {
+		if !containsString(s.i18n.Locales, *req.Locale) {
+			return nil, apperrors.NewBadRequest("不支持的语言，可选："+strings.Join(s.i18n.Locales, ", "), nil)
+		}
+		preference.Locale = *req.Locale
+	}
+	if req.Currency != nil {
+		currency := strings.ToUpper(*req.Currency)
+		if !containsString(s.currency.Currencies, currency) {
+			return nil, apperrors.NewBadRequest("不支持的货币，可选："+strings.Join(s.currency.Currencies, ", "), nil)
+		}
+		preference.Currency = currency
+	}
+	if req.MarketingEmails != nil {
+		preference.MarketingEmails = *req.MarketingEmails
+	}
+	if req.PushNotifications != nil {
+		preference.PushNotifications = *req.PushNotifications
+	}
+	if req.DefaultAddressID != nil {
+		if err := s.addressRepo.SetDefault(ctx, userID, *req.DefaultAddressID); err != nil {
+			return nil, addressError(err, "设置默认地址失败")
+		}
+		preference.DefaultAddressID = req.DefaultAddressID
+	}
+
+	err = s.preferenceRepo.Transaction(ctx, func(repo repository.PreferenceRepository, tx *gorm.DB) error {
+		if err := repo.Save(ctx, preference); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, tx, event.PreferencesUpdated, event.DomainEventVersion, &event.PreferencesUpdatedEvent{
+			UserID:            userID,
+			Locale:            preference.Locale,
+			Currency:          preference.Currency,
+			MarketingEmails:   preference.MarketingEmails,
+			PushNotifications: preference.PushNotifications,
+		})
+	})
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("保存用户偏好失败", err)
+	}
+	return preference, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
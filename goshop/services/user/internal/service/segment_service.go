@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/user/internal/event"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// tagPattern 是标签名称的格式
+var tagPattern = regexp.MustCompile(`^[a-z0-9_]{1,30}$`)
+
+// SegmentRequest 表示创建或更新客户分群的请求
+type SegmentRequest struct {
+	Name        string             `json:"name" binding:"required,max=100"`
+	Description string             `json:"description" binding:"max=500"`
+	Rules       model.SegmentRules `json:"rules"`
+}
+
+// SegmentDetail 是客户分群及当前符合规则的客户数
+type SegmentDetail struct {
+	*model.Segment
+	Members int64 `json:"members"`
+}
+
+// SegmentService 管理客户标签和按规则定义的客户分群。分群可以按标签、会员等级、
+// 累计消费金额、订单数和最近下单时间定义，消费统计来自订单服务的已完成和退款事件
+type SegmentService struct {
+	tagRepo     repository.TagRepository
+	segmentRepo repository.SegmentRepository
+	userRepo    repository.UserRepository
+	log         *logger.Logger
+	now         func() time.Time
+}
+
+// NewSegmentService 创建客户分群服务
+func NewSegmentService(tagRepo repository.TagRepository, segmentRepo repository.SegmentRepository, userRepo repository.UserRepository, log *logger.Logger) *SegmentService {
+	return &SegmentService{
+		tagRepo:     tagRepo,
+		segmentRepo: segmentRepo,
+		userRepo:    userRepo,
+		log:         log,
+		now:         time.Now,
+	}
+}
+
+// TagSummary 获取使用中的标签及各标签的客户数
+func (s *SegmentService) TagSummary(ctx context.Context) ([]*repository.TagCount, error) {
+	counts, err := s.tagRepo.Summary(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取标签失败", err)
+	}
+	return counts, nil
+}
+
+// ListUserTags 获取客户的标签
+func (s *SegmentService) ListUserTags(ctx context.Context, userID uint) ([]*model.UserTag, error) {
+	tags, err := s.tagRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取客户标签失败", err)
+	}
+	return tags, nil
+}
+
+// AddTag 给客户打标签并返回客户的全部标签，adminID 是操作的后台用户
+func (s *SegmentService) AddTag(ctx context.Context, userID uint, tag string, adminID uint) ([]*model.UserTag, error) {
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("用户不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	if err := s.tagRepo.Add(ctx, &model.UserTag{UserID: userID, Tag: tag, CreatedBy: adminID}); err != nil {
+		return nil, apperrors.NewInternalServerError("添加客户标签失败", err)
+	}
+	return s.ListUserTags(ctx, userID)
+}
+
+// RemoveTag 删除客户的标签
+func (s *SegmentService) RemoveTag(ctx context.Context, userID uint, tag string) error {
+	removed, err := s.tagRepo.Remove(ctx, userID, strings.ToLower(strings.TrimSpace(tag)))
+	if err != nil {
+		return apperrors.NewInternalServerError("删除客户标签失败", err)
+	}
+	if !removed {
+		return apperrors.NewNotFound("客户没有该标签", nil)
+	}
+	return nil
+}
+
+// List 获取全部客户分群
+func (s *SegmentService) List(ctx context.Context) ([]*model.Segment, error) {
+	segments, err := s.segmentRepo.List(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取客户分群失败", err)
+	}
+	return segments, nil
+}
+
+// Get 获取客户分群及当前符合规则的客户数
+func (s *SegmentService) Get(ctx context.Context, id uint) (*SegmentDetail, error) {
+	segment, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.segmentRepo.CountMembers(ctx, &segment.Rules, s.now())
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("统计分群客户失败", err)
+	}
+	return &SegmentDetail{Segment: segment, Members: members}, nil
+}
+
+// Create 创建客户分群，adminID 是操作的后台用户
+func (s *SegmentService) Create(ctx context.Context, req *SegmentRequest, adminID uint) (*model.Segment, error) {
+	if err := s.validate(ctx, 0, req); err != nil {
+		return nil, err
+	}
+	segment := &model.Segment{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Rules:       req.Rules,
+		CreatedBy:   adminID,
+	}
+	if err := s.segmentRepo.Create(ctx, segment); err != nil {
+		return nil, apperrors.NewInternalServerError("创建客户分群失败", err)
+	}
+	return segment, nil
+}
+
+// Update 更新客户分群的名称、说明和规则
+func (s *SegmentService) Update(ctx context.Context, id uint, req *SegmentRequest) (*model.Segment, error) {
+	segment, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(ctx, id, req); err != nil {
+		return nil, err
+	}
+	segment.Name = strings.TrimSpace(req.Name)
+	segment.Description = req.Description
+	segment.Rules = req.Rules
+	if err := s.segmentRepo.Update(ctx, segment); err != nil {
+		return nil, apperrors.NewInternalServerError("更新客户分群失败", err)
+	}
+	return segment, nil
+}
+
+// Delete 删除客户分群
+func (s *SegmentService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.get(ctx, id); err != nil {
+		return err
+	}
+	if err := s.segmentRepo.Delete(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除客户分群失败", err)
+	}
+	return nil
+}
+
+// ListMembers 按 ID 顺序分页获取分群的客户，afterID 为上一页最后一个客户的 ID
+func (s *SegmentService) ListMembers(ctx context.Context, id, afterID uint, limit int) ([]*model.User, error) {
+	segment, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	users, err := s.segmentRepo.ListMembers(ctx, &segment.Rules, s.now(), afterID, limit)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取分群客户失败", err)
+	}
+	return users, nil
+}
+
+// UserSegments 返回客户所属的分群 ID
+func (s *SegmentService) UserSegments(ctx context.Context, userID uint) ([]uint, error) {
+	segments, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	ids := make([]uint, 0)
+	for _, segment := range segments {
+		ok, err := s.segmentRepo.IsMember(ctx, &segment.Rules, now, userID)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取客户分群失败", err)
+		}
+		if ok {
+			ids = append(ids, segment.ID)
+		}
+	}
+	return ids, nil
+}
+
+// Subscribe 订阅订单完成和退款事件，统计客户的消费金额、订单数和最近下单时间
+func (s *SegmentService) Subscribe(consumer *events.Consumer) error {
+	if err := consumer.Subscribe(event.OrderCompleted, s.handleOrderCompleted); err != nil {
+		return err
+	}
+	return consumer.Subscribe(event.OrderRefunded, s.handleOrderRefunded)
+}
+
+func (s *SegmentService) handleOrderCompleted(ctx context.Context, env *events.Envelope) error {
+	var evt event.OrderEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	return s.HandleOrderCompleted(ctx, &evt)
+}
+
+func (s *SegmentService) handleOrderRefunded(ctx context.Context, env *events.Envelope) error {
+	var evt event.OrderRefundEvent
+	if err := env.Decode(&evt); err != nil {
+		return events.Permanent(err)
+	}
+	return s.HandleOrderRefunded(ctx, &evt)
+}
+
+// HandleOrderCompleted 记录客户已完成的订单
+func (s *SegmentService) HandleOrderCompleted(ctx context.Context, evt *event.OrderEvent) error {
+	if evt.OrderID == 0 || evt.UserID == 0 {
+		return nil
+	}
+	placedAt := evt.PlacedAt
+	if placedAt.IsZero() {
+		placedAt = s.now()
+	}
+	return s.segmentRepo.RecordOrder(ctx, &model.UserOrder{
+		OrderID:  evt.OrderID,
+		UserID:   evt.UserID,
+		Total:    evt.GrandTotal,
+		PlacedAt: placedAt,
+	})
+}
+
+// HandleOrderRefunded 从客户的消费金额中扣除退款
+func (s *SegmentService) HandleOrderRefunded(ctx context.Context, evt *event.OrderRefundEvent) error {
+	if evt.RefundID == "" || evt.RefundAmount <= 0 {
+		s.log.Warn(ctx, "忽略无效的退款事件", zap.Uint("order_id", evt.OrderID), zap.String("refund_id", evt.RefundID))
+		return nil
+	}
+	return s.segmentRepo.RecordRefund(ctx, &model.UserOrderRefund{
+		RefundID: evt.RefundID,
+		OrderID:  evt.OrderID,
+		Amount:   evt.RefundAmount,
+	})
+}
+
+func (s *SegmentService) get(ctx context.Context, id uint) (*model.Segment, error) {
+	segment, err := s.segmentRepo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("客户分群不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取客户分群失败", err)
+	}
+	return segment, nil
+}
+
+// validate 检查分群名称未被其他分群使用，并检查和规范化规则，id 为正在更新的分群，创建时为 0
+func (s *SegmentService) validate(ctx context.Context, id uint, req *SegmentRequest) error {
+	existing, err := s.segmentRepo.GetByName(ctx, strings.TrimSpace(req.Name))
+	switch {
+	case err == nil && existing.ID != id:
+		return apperrors.NewConflict("分群名称已被使用", nil)
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return apperrors.NewInternalServerError("获取客户分群失败", err)
+	}
+
+	rules := &req.Rules
+	seen := make(map[string]bool, len(rules.Tags))
+	tags := make([]string, 0, len(rules.Tags))
+	for _, tag := range rules.Tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	rules.Tags = tags
+
+	switch {
+	case len(rules.Tags) == 0 && rules.MinMemberLevel == nil && rules.MaxMemberLevel == nil &&
+		rules.MinTotalSpent == nil && rules.MaxTotalSpent == nil && rules.MinOrders == nil &&
+		rules.OrderedWithinDays == nil && rules.NoOrderForDays == nil:
+		return apperrors.NewBadRequest("分群至少需要一个条件", nil)
+	case negativeInt(rules.MinMemberLevel) || negativeInt(rules.MaxMemberLevel) || negativeInt(rules.MinOrders) ||
+		negativeFloat(rules.MinTotalSpent) || negativeFloat(rules.MaxTotalSpent):
+		return apperrors.NewBadRequest("分群条件不能为负数", nil)
+	case rules.MinMemberLevel != nil && rules.MaxMemberLevel != nil && *rules.MinMemberLevel > *rules.MaxMemberLevel:
+		return apperrors.NewBadRequest("最低会员等级不能高于最高会员等级", nil)
+	case rules.MinTotalSpent != nil && rules.MaxTotalSpent != nil && *rules.MinTotalSpent > *rules.MaxTotalSpent:
+		return apperrors.NewBadRequest("最低消费金额不能高于最高消费金额", nil)
+	case rules.OrderedWithinDays != nil && *rules.OrderedWithinDays <= 0,
+		rules.NoOrderForDays != nil && *rules.NoOrderForDays <= 0:
+		return apperrors.NewBadRequest("天数必须大于 0", nil)
+	}
+	return nil
+}
+
+// normalizeTag 将标签转换为小写并检查格式
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !tagPattern.MatchString(tag) {
+		return "", apperrors.NewBadRequest("标签只能包含小写字母、数字和下划线，最长 30 个字符", nil)
+	}
+	return tag, nil
+}
+
+func negativeInt(v *int) bool {
+	return v != nil && *v < 0
+}
+
+func negativeFloat(v *float64) bool {
+	return v != nil && *v < 0
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"time"
+
+	"github.com/yourusername/goshop/services/user/internal/event"
+	"github.com/yourusername/goshop/services/user/internal/model"
+)
+
+// registeredEvent 生成 user.registered 事件的数据，provider 为空表示使用邮箱注册
+func registeredEvent(user *model.User, provider string) *event.UserRegisteredEvent {
+	return &event.UserRegisteredEvent{
+		UserID:        user.ID,
+		Email:         user.Email,
+		Phone:         valueOf(user.Phone),
+		Username:      user.Username,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		EmailVerified: user.EmailVerified,
+		Provider:      provider,
+		RegisteredAt:  user.CreatedAt,
+	}
+}
+
+// updatedEvent 生成 user.updated 事件的数据，user 是更新后的用户
+func updatedEvent(user *model.User, fields []string) *event.UserUpdatedEvent {
+	evt := &event.UserUpdatedEvent{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Phone:     valueOf(user.Phone),
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Avatar:    valueOf(user.Avatar),
+		Fields:    fields,
+	}
+	if user.Birthday != nil {
+		evt.Birthday = user.Birthday.Format(time.DateOnly)
+	}
+	return evt
+}
+
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/services/user/internal/event"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// 纪念日类型
+const (
+	CelebrationBirthday    = "birthday"    // 生日
+	CelebrationAnniversary = "anniversary" // 注册周年
+)
+
+// RegisterRequest 表示注册请求
+type RegisterRequest struct {
+	Email     string `json:"email" binding:"required,email,max=255"`
+	Username  string `json:"username" binding:"required,alphanum,min=3,max=50"`
+	Password  string `json:"password" binding:"required,min=8,max=72"`
+	FirstName string `json:"first_name" binding:"max=50"`
+	LastName  string `json:"last_name" binding:"max=50"`
+	Phone     string `json:"phone" binding:"omitempty,phone"`
+}
+
+// UpdateProfileRequest 表示更新用户资料的请求，未提供的字段保持不变。
+// 生日只能设置一次，避免反复修改领取生日礼遇
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name" binding:"omitempty,max=50"`
+	LastName  *string `json:"last_name" binding:"omitempty,max=50"`
+	Phone     *string `json:"phone" binding:"omitempty,phone"`
+	Birthday  *string `json:"birthday"` // 2006-01-02
+}
+
+// CelebrantQuery 表示按纪念日查询用户的条件
+type CelebrantQuery struct {
+	Type    string // birthday 或 anniversary
+	Month   int
+	Day     int
+	Before  time.Time // 注册周年时只返回在此之前注册的用户
+	AfterID uint      // 上一页最后一个用户的 ID
+	Limit   int
+}
+
+// UserService 提供用户相关的业务逻辑，注册、资料变更和注销时通过 outbox 发布用户领域事件
+type UserService struct {
+	userRepo repository.UserRepository
+	outbox   *events.Outbox
+	policy   config.PasswordPolicyConfig
+}
+
+// NewUserService 创建用户服务，注册时密码必须符合 policy
+func NewUserService(userRepo repository.UserRepository, outbox *events.Outbox, policy config.PasswordPolicyConfig) *UserService {
+	return &UserService{
+		userRepo: userRepo,
+		outbox:   outbox,
+		policy:   policy,
+	}
+}
+
+// Register 注册用户，邮箱、用户名和手机号不能与已有用户重复，密码以 bcrypt 哈希保存
+func (s *UserService) Register(ctx context.Context, req *RegisterRequest) (*model.User, error) {
+	if err := checkPassword(s.policy, req.Password); err != nil {
+		return nil, err
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	username := strings.TrimSpace(req.Username)
+
+	if _, err := s.userRepo.GetByEmail(ctx, email); err == nil {
+		return nil, apperrors.NewConflict("邮箱已被注册", nil)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	if _, err := s.userRepo.GetByUsername(ctx, username); err == nil {
+		return nil, apperrors.NewConflict("用户名已被使用", nil)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	var phone *string
+	if req.Phone != "" {
+		if _, err := s.userRepo.GetByPhone(ctx, req.Phone); err == nil {
+			return nil, apperrors.NewConflict("手机号已被注册", nil)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewInternalServerError("获取用户失败", err)
+		}
+		phone = &req.Phone
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("注册失败", err)
+	}
+	user := &model.User{
+		Email:     email,
+		Phone:     phone,
+		Username:  username,
+		Password:  string(hash),
+		FirstName: strings.TrimSpace(req.FirstName),
+		LastName:  strings.TrimSpace(req.LastName),
+		Role:      "shopper",
+		Status:    "active",
+	}
+	// 并发注册时由唯一索引保证不重复，后提交的注册返回冲突
+	err = s.userRepo.Transaction(ctx, func(repo repository.UserRepository, tx *gorm.DB) error {
+		if err := repo.Create(ctx, user); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, tx, event.UserRegistered, event.DomainEventVersion, registeredEvent(user, ""))
+	})
+	if errors.Is(err, repository.ErrDuplicateUser) {
+		return nil, apperrors.NewConflict("邮箱、用户名或手机号已被注册", err)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("注册失败", err)
+	}
+	return user, nil
+}
+
+// GetUser 获取用户信息
+func (s *UserService) GetUser(ctx context.Context, id uint) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("用户不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	return user, nil
+}
+
+// GetUserByEmail 按邮箱获取用户，邮箱不区分大小写
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, apperrors.NewBadRequest("缺少邮箱", nil)
+	}
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound("用户不存在", err)
+		}
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	return user, nil
+}
+
+// UpdateProfile 更新用户资料并发布 user.updated 事件。修改手机号后需要重新验证
+func (s *UserService) UpdateProfile(ctx context.Context, id uint, req *UpdateProfileRequest) (*model.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if req.FirstName != nil {
+		if name := strings.TrimSpace(*req.FirstName); name != user.FirstName {
+			user.FirstName = name
+			fields["first_name"] = name
+		}
+	}
+	if req.LastName != nil {
+		if name := strings.TrimSpace(*req.LastName); name != user.LastName {
+			user.LastName = name
+			fields["last_name"] = name
+		}
+	}
+	if req.Phone != nil && *req.Phone != valueOf(user.Phone) {
+		existing, err := s.userRepo.GetByPhone(ctx, *req.Phone)
+		switch {
+		case err == nil && existing.ID != id:
+			return nil, apperrors.NewConflict("手机号已被注册", nil)
+		case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, apperrors.NewInternalServerError("获取用户失败", err)
+		}
+		phone := *req.Phone
+		user.Phone, user.PhoneVerified = &phone, false
+		fields["phone"] = phone
+		fields["phone_verified"] = false
+	}
+	if req.Birthday != nil {
+		birthday, err := time.Parse(time.DateOnly, *req.Birthday)
+		if err != nil || birthday.After(time.Now()) {
+			return nil, apperrors.NewBadRequest("无效的生日", err)
+		}
+		switch {
+		case user.Birthday == nil:
+			user.Birthday = &birthday
+			fields["birthday"] = birthday
+		case !user.Birthday.Equal(birthday):
+			return nil, apperrors.NewBadRequest("生日设置后不能修改", nil)
+		}
+	}
+	if len(fields) == 0 {
+		return user, nil
+	}
+
+	changed := make([]string, 0, len(fields))
+	for field := range fields {
+		if field != "phone_verified" {
+			changed = append(changed, field)
+		}
+	}
+	sort.Strings(changed)
+	err = s.userRepo.Transaction(ctx, func(repo repository.UserRepository, tx *gorm.DB) error {
+		if err := repo.UpdateFields(ctx, id, fields); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, tx, event.UserUpdated, event.DomainEventVersion, updatedEvent(user, changed))
+	})
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("更新用户资料失败", err)
+	}
+	return user, nil
+}
+
+// Delete 注销用户并发布 user.deleted 事件。用户被软删除，其他服务收到事件后清理保存的个人信息
+func (s *UserService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.GetUser(ctx, id); err != nil {
+		return err
+	}
+	err := s.userRepo.Transaction(ctx, func(repo repository.UserRepository, tx *gorm.DB) error {
+		if err := repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, tx, event.UserDeleted, event.DomainEventVersion, &event.UserDeletedEvent{
+			UserID:    id,
+			DeletedAt: time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return apperrors.NewInternalServerError("注销用户失败", err)
+	}
+	return nil
+}
+
+// GetUsers 批量获取用户，结果与 ids 一一对应，不存在的用户为 nil
+func (s *UserService) GetUsers(ctx context.Context, ids []uint) ([]*model.User, error) {
+	users, err := s.userRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	byID := make(map[uint]*model.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	result := make([]*model.User, len(ids))
+	for i, id := range ids {
+		result[i] = byID[id]
+	}
+	return result, nil
+}
+
+// ListCelebrants 分页获取在指定月日过生日或注册周年的活跃用户，供营销服务发放纪念日奖励
+func (s *UserService) ListCelebrants(ctx context.Context, q *CelebrantQuery) ([]*model.User, error) {
+	if q.Month < 1 || q.Month > 12 || q.Day < 1 || q.Day > 31 {
+		return nil, apperrors.NewBadRequest("无效的日期", nil)
+	}
+	if q.Limit <= 0 || q.Limit > 500 {
+		q.Limit = 100
+	}
+
+	var (
+		users []*model.User
+		err   error
+	)
+	switch q.Type {
+	case CelebrationBirthday:
+		users, err = s.userRepo.ListByBirthday(ctx, q.Month, q.Day, q.AfterID, q.Limit)
+	case CelebrationAnniversary:
+		if q.Before.IsZero() {
+			return nil, apperrors.NewBadRequest("缺少 before 参数", nil)
+		}
+		users, err = s.userRepo.ListBySignupDate(ctx, q.Month, q.Day, q.Before, q.AfterID, q.Limit)
+	default:
+		return nil, apperrors.NewBadRequest("无效的纪念日类型", nil)
+	}
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("查询用户失败", err)
+	}
+	return users, nil
+}
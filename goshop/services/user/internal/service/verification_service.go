@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/yourusername/goshop/pkg/config"
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	authrpc "github.com/yourusername/goshop/services/auth/rpc"
+	"github.com/yourusername/goshop/services/user/internal/event"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"github.com/yourusername/goshop/services/user/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// VerificationService 验证用户的邮箱。验证令牌由认证服务签发和使用，
+// 验证邮件由通知服务根据发布的事件发送，验证成功后通过 outbox 发布 user.email_verified 事件
+type VerificationService struct {
+	userRepo repository.UserRepository
+	auth     *authrpc.Client
+	outbox   *events.Outbox
+	cfg      config.UserConfig
+	log      *logger.Logger
+}
+
+// NewVerificationService 创建邮箱验证服务
+func NewVerificationService(userRepo repository.UserRepository, auth *authrpc.Client, outbox *events.Outbox, cfg config.UserConfig, log *logger.Logger) *VerificationService {
+	return &VerificationService{
+		userRepo: userRepo,
+		auth:     auth,
+		outbox:   outbox,
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+// SendEmailVerification 向用户的邮箱发送验证链接，之前发送的链接随之失效。
+// 邮箱已验证时返回错误，距上次发送未超过重发间隔时返回 429
+func (s *VerificationService) SendEmailVerification(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NewNotFound("用户不存在", err)
+		}
+		return apperrors.NewInternalServerError("获取用户失败", err)
+	}
+	if user.EmailVerified {
+		return apperrors.NewConflict("邮箱已验证", nil)
+	}
+	return s.send(ctx, user)
+}
+
+// SendAfterRegister 向新注册的用户发送验证链接，发送失败只记录日志，用户可以稍后重新发送
+func (s *VerificationService) SendAfterRegister(ctx context.Context, user *model.User) {
+	if err := s.send(ctx, user); err != nil {
+		s.log.Warn(ctx, "发送验证邮件失败", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// VerifyEmail 使用验证链接中的令牌验证邮箱，令牌只能使用一次
+func (s *VerificationService) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	if token == "" {
+		return nil, apperrors.NewBadRequest("验证链接无效或已过期", nil)
+	}
+	reply, err := s.auth.ConsumeVerification(ctx, &authrpc.ConsumeVerificationRequest{
+		Token: token,
+		Type:  authrpc.VerificationEmail,
+	})
+	if err != nil {
+		return nil, authError(err)
+	}
+	var user *model.User
+	err = s.userRepo.Transaction(ctx, func(repo repository.UserRepository, tx *gorm.DB) error {
+		if err := repo.VerifyEmail(ctx, reply.UserID); err != nil {
+			return err
+		}
+		var err error
+		if user, err = repo.GetByID(ctx, reply.UserID); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, tx, event.UserEmailVerified, event.DomainEventVersion, &event.UserEmailVerifiedEvent{
+			UserID:     user.ID,
+			Email:      user.Email,
+			VerifiedAt: time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("验证邮箱失败", err)
+	}
+	return user, nil
+}
+
+// send 签发验证令牌并发布验证邮件事件
+func (s *VerificationService) send(ctx context.Context, user *model.User) error {
+	reply, err := s.auth.IssueVerification(ctx, &authrpc.IssueVerificationRequest{
+		UserID:         user.ID,
+		Type:           authrpc.VerificationEmail,
+		TTL:            s.cfg.EmailVerificationTTL * int(time.Hour/time.Second),
+		ResendInterval: s.cfg.VerificationResendInterval,
+	})
+	if err != nil {
+		return authError(err)
+	}
+
+	link, err := url.Parse(s.cfg.VerifyEmailURL)
+	if err != nil {
+		return apperrors.NewInternalServerError("无效的验证链接地址", err)
+	}
+	query := link.Query()
+	query.Set("token", reply.Token)
+	link.RawQuery = query.Encode()
+
+	err = s.userRepo.Transaction(ctx, func(_ repository.UserRepository, tx *gorm.DB) error {
+		return s.outbox.Add(ctx, tx, event.EmailVerificationRequested, event.DomainEventVersion, &event.EmailVerificationRequestedEvent{
+			UserID:    user.ID,
+			Email:     user.Email,
+			Name:      user.DisplayName(),
+			VerifyURL: link.String(),
+			ExpiresAt: reply.ExpiresAt,
+		})
+	})
+	if err != nil {
+		return apperrors.NewServiceUnavailable("发送验证邮件失败", err)
+	}
+	return nil
+}
+
+// authError 转换认证服务返回的错误：请求错误原样返回，其他错误表示认证服务暂不可用
+func authError(err error) error {
+	if apperrors.HTTPStatus(err) < http.StatusInternalServerError {
+		return err
+	}
+	return apperrors.NewServiceUnavailable("认证服务暂不可用，请稍后再试", err)
+}
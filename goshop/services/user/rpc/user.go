@@ -0,0 +1,256 @@
+// Package rpc defines the gRPC API of the user service, shared by the service
+// and its clients. Messages are plain Go structs encoded with the JSON codec of
+// pkg/grpcclient rather than generated protobuf types.
+package rpc
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/pkg/grpcclient"
+	"github.com/yourusername/goshop/services/user/internal/model"
+	"google.golang.org/grpc"
+)
+
+// Full method names of the user service
+const (
+	ServiceName          = "user.UserService"
+	GetUserMethod        = "/" + ServiceName + "/GetUser"
+	GetUserByEmailMethod = "/" + ServiceName + "/GetUserByEmail"
+	ValidateUserMethod   = "/" + ServiceName + "/ValidateUser"
+	GetAddressesMethod   = "/" + ServiceName + "/GetAddresses"
+	GetSegmentsMethod    = "/" + ServiceName + "/GetSegments"
+	ListSegmentMethod    = "/" + ServiceName + "/ListSegment"
+)
+
+// GetUserRequest requests a user
+type GetUserRequest struct {
+	ID uint `json:"id"`
+}
+
+// SetUserID requests the authenticated user, the gateway transcodes /users/me
+// this way
+func (r *GetUserRequest) SetUserID(id uint) {
+	r.ID = id
+}
+
+// GetUserByEmailRequest requests the user registered with an email address,
+// compared case-insensitively
+type GetUserByEmailRequest struct {
+	Email string `json:"email"`
+}
+
+// ValidateUserRequest asks whether a user may act on the platform, e.g. place
+// an order or pay. RequireVerifiedEmail also rejects users whose email address
+// is not verified yet.
+type ValidateUserRequest struct {
+	ID                   uint `json:"id"`
+	RequireVerifiedEmail bool `json:"require_verified_email"`
+}
+
+// Reasons a user is not valid
+const (
+	InvalidNotFound        = "not_found"
+	InvalidInactive        = "inactive"
+	InvalidEmailUnverified = "email_unverified"
+)
+
+// ValidateUserReply tells whether the user is valid, Reason is set otherwise.
+// A missing user is not an error but an invalid user.
+type ValidateUserReply struct {
+	ID          uint   `json:"id"`
+	Valid       bool   `json:"valid"`
+	Reason      string `json:"reason,omitempty"`
+	Name        string `json:"name,omitempty"` // display name, the name claim of access tokens
+	Status      string `json:"status,omitempty"`
+	Role        string `json:"role,omitempty"`
+	MemberLevel int    `json:"member_level"`
+}
+
+// GetAddressesRequest requests the address book of a user
+type GetAddressesRequest struct {
+	UserID uint `json:"user_id"`
+}
+
+// SetUserID requests the address book of the authenticated user
+func (r *GetAddressesRequest) SetUserID(id uint) {
+	r.UserID = id
+}
+
+// AddressesReply lists the addresses of a user, the default address first
+type AddressesReply struct {
+	Addresses []*model.Address `json:"addresses"`
+}
+
+// Default returns the default address, nil when the user has none
+func (r *AddressesReply) Default() *model.Address {
+	for _, address := range r.Addresses {
+		if address.IsDefault {
+			return address
+		}
+	}
+	return nil
+}
+
+// Find returns the address with id, nil when the user has no such address
+func (r *AddressesReply) Find(id uint) *model.Address {
+	for _, address := range r.Addresses {
+		if address.ID == id {
+			return address
+		}
+	}
+	return nil
+}
+
+// GetSegmentsRequest requests the tags and segments of a user
+type GetSegmentsRequest struct {
+	UserID uint `json:"user_id"`
+}
+
+// SegmentsReply lists the tags of a user and the IDs of the segments whose
+// rules the user currently matches
+type SegmentsReply struct {
+	UserID   uint     `json:"user_id"`
+	Tags     []string `json:"tags"`
+	Segments []uint   `json:"segments"`
+}
+
+// InSegment reports whether the user belongs to the segment
+func (r *SegmentsReply) InSegment(id uint) bool {
+	for _, segment := range r.Segments {
+		if segment == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ListSegmentRequest pages through the members of a segment in ID order,
+// AfterID is the last user of the previous page
+type ListSegmentRequest struct {
+	SegmentID uint `json:"segment_id"`
+	AfterID   uint `json:"after_id"`
+	Limit     int  `json:"limit"` // at most 500, 100 by default
+}
+
+// SegmentMembersReply is a page of segment members, empty after the last page
+type SegmentMembersReply struct {
+	Users []*model.User `json:"users"`
+}
+
+// UserServer is the server API of the user service
+type UserServer interface {
+	GetUser(ctx context.Context, req *GetUserRequest) (*model.User, error)
+	GetUserByEmail(ctx context.Context, req *GetUserByEmailRequest) (*model.User, error)
+	ValidateUser(ctx context.Context, req *ValidateUserRequest) (*ValidateUserReply, error)
+	GetAddresses(ctx context.Context, req *GetAddressesRequest) (*AddressesReply, error)
+	GetSegments(ctx context.Context, req *GetSegmentsRequest) (*SegmentsReply, error)
+	ListSegment(ctx context.Context, req *ListSegmentRequest) (*SegmentMembersReply, error)
+}
+
+// serviceDesc describes the user service to the gRPC server
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*UserServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("GetUser", GetUserMethod, UserServer.GetUser),
+		unary("GetUserByEmail", GetUserByEmailMethod, UserServer.GetUserByEmail),
+		unary("ValidateUser", ValidateUserMethod, UserServer.ValidateUser),
+		unary("GetAddresses", GetAddressesMethod, UserServer.GetAddresses),
+		unary("GetSegments", GetSegmentsMethod, UserServer.GetSegments),
+		unary("ListSegment", ListSegmentMethod, UserServer.ListSegment),
+	},
+}
+
+// RegisterUserServer registers srv with the gRPC server s
+func RegisterUserServer(s grpc.ServiceRegistrar, srv UserServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// unary describes a unary method calling fn on the server, through the
+// interceptors of the server when it has some
+func unary[Req, Reply any](name, fullMethod string, fn func(UserServer, context.Context, *Req) (*Reply, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(Req)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return fn(srv.(UserServer), ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return fn(srv.(UserServer), ctx, req.(*Req))
+			}
+			return interceptor(ctx, in, info, handler)
+		},
+	}
+}
+
+// Client calls the user service
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient creates a client of the user service on conn, usually obtained
+// from grpcclient.Factory.Conn("user")
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// GetUser returns a user
+func (c *Client) GetUser(ctx context.Context, id uint) (*model.User, error) {
+	out := new(model.User)
+	if err := c.conn.Invoke(ctx, GetUserMethod, &GetUserRequest{ID: id}, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetUserByEmail returns the user registered with email
+func (c *Client) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	out := new(model.User)
+	if err := c.conn.Invoke(ctx, GetUserByEmailMethod, &GetUserByEmailRequest{Email: email}, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ValidateUser tells whether a user may act on the platform
+func (c *Client) ValidateUser(ctx context.Context, req *ValidateUserRequest) (*ValidateUserReply, error) {
+	out := new(ValidateUserReply)
+	if err := c.conn.Invoke(ctx, ValidateUserMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAddresses returns the address book of a user
+func (c *Client) GetAddresses(ctx context.Context, userID uint) (*AddressesReply, error) {
+	out := new(AddressesReply)
+	if err := c.conn.Invoke(ctx, GetAddressesMethod, &GetAddressesRequest{UserID: userID}, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetSegments returns the tags and segments of a user, e.g. to check that a
+// user may redeem a coupon targeted at a segment
+func (c *Client) GetSegments(ctx context.Context, userID uint) (*SegmentsReply, error) {
+	out := new(SegmentsReply)
+	if err := c.conn.Invoke(ctx, GetSegmentsMethod, &GetSegmentsRequest{UserID: userID}, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListSegment returns a page of the members of a segment, e.g. to grant them
+// coupons
+func (c *Client) ListSegment(ctx context.Context, req *ListSegmentRequest) (*SegmentMembersReply, error) {
+	out := new(SegmentMembersReply)
+	if err := c.conn.Invoke(ctx, ListSegmentMethod, req, out, grpcclient.JSON()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
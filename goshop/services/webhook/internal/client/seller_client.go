@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// sellerStatusActive 是正常营业的商家状态
+const sellerStatusActive = "active"
+
+// SellerClient 通过 HTTP 调用商家服务
+type SellerClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewSellerClient 创建商家服务客户端
+func NewSellerClient(baseURL string) *SellerClient {
+	return &SellerClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// sellerResponse 对应商家服务 GET /api/v1/sellers/me 的响应
+type sellerResponse struct {
+	Data struct {
+		ID     uint   `json:"id"`
+		Status string `json:"status"`
+	} `json:"data"`
+}
+
+// ActiveSellerID 返回用户入驻的商家 ID，用户未入驻或商家不在营业状态时返回 0
+func (c *SellerClient) ActiveSellerID(ctx context.Context, userID uint) (uint, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/sellers/me", nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-User-ID", strconv.FormatUint(uint64(userID), 10))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("seller service returned status %d", resp.StatusCode)
+	}
+
+	var result sellerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	if result.Data.Status != sellerStatusActive {
+		return 0, nil
+	}
+	return result.Data.ID, nil
+}
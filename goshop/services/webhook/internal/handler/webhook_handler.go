@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/goshop/pkg/validator"
+	"github.com/yourusername/goshop/services/webhook/internal/repository"
+	"github.com/yourusername/goshop/services/webhook/internal/service"
+)
+
+// WebhookHandler 处理 Webhook 端点和投递记录相关的 HTTP 请求
+type WebhookHandler struct {
+	endpointService *service.EndpointService
+	deliveryService *service.DeliveryService
+}
+
+// NewWebhookHandler 创建 Webhook 处理器
+func NewWebhookHandler(endpointService *service.EndpointService, deliveryService *service.DeliveryService) *WebhookHandler {
+	return &WebhookHandler{
+		endpointService: endpointService,
+		deliveryService: deliveryService,
+	}
+}
+
+// RegisterRoutes 注册 Webhook 路由，只有商家和后台人员可以管理端点，商家只能管理自己的端点和投递记录
+func (h *WebhookHandler) RegisterRoutes(api *gin.RouterGroup) {
+	endpoints := api.Group("/webhooks/endpoints", h.requireOwner)
+	{
+		endpoints.GET("", h.ListEndpoints)
+		endpoints.POST("", h.CreateEndpoint)
+		endpoints.GET("/:id", h.GetEndpoint)
+		endpoints.PUT("/:id", h.UpdateEndpoint)
+		endpoints.DELETE("/:id", h.DeleteEndpoint)
+		endpoints.POST("/:id/rotate-secret", h.RotateSecret)
+	}
+
+	deliveries := api.Group("/webhooks/deliveries", h.requireOwner)
+	{
+		deliveries.GET("", h.ListDeliveries)
+		deliveries.GET("/:id", h.GetDelivery)
+		deliveries.POST("/:id/redeliver", h.Redeliver)
+	}
+
+	admin := api.Group("/webhooks/admin/deliveries")
+	{
+		admin.GET("", h.AdminListDeliveries)
+		admin.GET("/:id", h.AdminGetDelivery)
+		admin.POST("/:id/redeliver", h.AdminRedeliver)
+	}
+}
+
+// ListEndpoints 获取当前商家的端点
+func (h *WebhookHandler) ListEndpoints(c *gin.Context) {
+	merchantID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	endpoints, err := h.endpointService.List(c.Request.Context(), merchantID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": endpoints})
+}
+
+// CreateEndpoint 注册端点，响应中包含只返回一次的签名密钥
+func (h *WebhookHandler) CreateEndpoint(c *gin.Context) {
+	merchantID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req service.EndpointRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	endpoint, err := h.endpointService.Create(c.Request.Context(), merchantID, c.GetUint(sellerIDKey), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": endpoint})
+}
+
+// GetEndpoint 获取端点
+func (h *WebhookHandler) GetEndpoint(c *gin.Context) {
+	merchantID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	endpoint, err := h.endpointService.Get(c.Request.Context(), merchantID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": endpoint})
+}
+
+// UpdateEndpoint 更新端点
+func (h *WebhookHandler) UpdateEndpoint(c *gin.Context) {
+	merchantID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	var req service.EndpointRequest
+	if err := validator.BindJSON(c, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+	endpoint, err := h.endpointService.Update(c.Request.Context(), merchantID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": endpoint})
+}
+
+// DeleteEndpoint 删除端点
+func (h *WebhookHandler) DeleteEndpoint(c *gin.Context) {
+	merchantID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	if err := h.endpointService.Delete(c.Request.Context(), merchantID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RotateSecret 轮换端点的签名密钥
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	merchantID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	endpoint, err := h.endpointService.RotateSecret(c.Request.Context(), merchantID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": endpoint})
+}
+
+// ListDeliveries 分页获取当前商家端点的投递记录
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	merchantID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	h.listDeliveries(c, merchantID)
+}
+
+// GetDelivery 获取投递记录及每次尝试的结果
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	merchantID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	h.getDelivery(c, merchantID, id)
+}
+
+// Redeliver 立即重新投递
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	merchantID, id, ok := parseOwnedID(c)
+	if !ok {
+		return
+	}
+	h.redeliver(c, merchantID, id)
+}
+
+// AdminListDeliveries 分页获取所有端点的投递记录，可按 status=dead_letter 查询死信
+func (h *WebhookHandler) AdminListDeliveries(c *gin.Context) {
+	h.listDeliveries(c, 0)
+}
+
+// AdminGetDelivery 获取任意投递记录
+func (h *WebhookHandler) AdminGetDelivery(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	h.getDelivery(c, 0, id)
+}
+
+// AdminRedeliver 重新投递任意投递记录
+func (h *WebhookHandler) AdminRedeliver(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	h.redeliver(c, 0, id)
+}
+
+func (h *WebhookHandler) listDeliveries(c *gin.Context, merchantID uint) {
+	endpointID, ok := parseIDQuery(c, "endpoint_id")
+	if !ok {
+		return
+	}
+	filter := repository.DeliveryFilter{
+		Status:    c.Query("status"),
+		EventType: c.Query("event_type"),
+	}
+	if endpointID != 0 {
+		filter.EndpointIDs = []uint{endpointID}
+	}
+	list, err := h.deliveryService.List(c.Request.Context(), merchantID, filter,
+		parseIntQuery(c, "page", 1), parseIntQuery(c, "page_size", 20))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+func (h *WebhookHandler) getDelivery(c *gin.Context, merchantID, id uint) {
+	delivery, err := h.deliveryService.Get(c.Request.Context(), merchantID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": delivery})
+}
+
+func (h *WebhookHandler) redeliver(c *gin.Context, merchantID, id uint) {
+	delivery, err := h.deliveryService.Redeliver(c.Request.Context(), merchantID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": delivery})
+}
+
+// sellerIDKey 是 requireOwner 保存端点所属商家 ID 的上下文键
+const sellerIDKey = "SellerID"
+
+// requireOwner 只允许商家和后台人员访问，并保存端点所属的商家 ID
+func (h *WebhookHandler) requireOwner(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	sellerID, err := h.endpointService.Owner(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Set(sellerIDKey, sellerID)
+	c.Next()
+}
+
+// parseOwnedID 解析当前商家 ID 和路径中的 ID
+func parseOwnedID(c *gin.Context) (uint, uint, bool) {
+	merchantID, ok := currentUserID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	return merchantID, id, true
+}
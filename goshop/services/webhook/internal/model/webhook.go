@@ -0,0 +1,103 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// 投递状态
+const (
+	StatusPending    = "pending"     // 等待投递或等待重试
+	StatusSucceeded  = "succeeded"   // 端点返回了 2xx
+	StatusDeadLetter = "dead_letter" // 重试次数用尽，只能手动重新投递
+)
+
+// Endpoint 表示商家注册的 Webhook 端点及其订阅的事件类型。商家的端点只接收数据中 seller_id
+// 为该商家的事件，后台人员注册的端点 SellerID 为 0，接收订阅的全部事件
+type Endpoint struct {
+	ID          uint        `json:"id" gorm:"primaryKey"`
+	MerchantID  uint        `json:"merchant_id" gorm:"index;not null"` // 注册端点的用户
+	SellerID    uint        `json:"seller_id" gorm:"index;not null;default:0"`
+	URL         string      `json:"url" gorm:"size:500;not null"`
+	Description string      `json:"description" gorm:"size:255"`
+	Secret      string      `json:"-" gorm:"size:100;not null"` // 签名密钥，只在创建和轮换时返回一次
+	EventTypes  StringSlice `json:"event_types" gorm:"type:jsonb;not null"`
+	Active      bool        `json:"active" gorm:"not null"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// Subscribes 判断端点是否订阅了事件类型。订阅项可以是完整的事件类型、
+// 以 .* 结尾的前缀如 order.*，或表示全部事件的 *
+func (e *Endpoint) Subscribes(eventType string) bool {
+	for _, pattern := range e.EventTypes {
+		switch {
+		case pattern == "*" || pattern == eventType:
+			return true
+		case strings.HasSuffix(pattern, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// Receives 判断端点是否接收事件：端点订阅了事件类型，并且商家的端点只接收自己的事件。
+// sellerID 为事件数据中的 seller_id，没有时为 nil
+func (e *Endpoint) Receives(eventType string, sellerID *uint) bool {
+	if !e.Subscribes(eventType) {
+		return false
+	}
+	return e.SellerID == 0 || (sellerID != nil && *sellerID == e.SellerID)
+}
+
+// Delivery 表示一个事件向一个端点的投递，同一事件对同一端点只投递一次
+type Delivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	EndpointID     uint       `json:"endpoint_id" gorm:"uniqueIndex:idx_delivery_event;not null"`
+	EventID        string     `json:"event_id" gorm:"uniqueIndex:idx_delivery_event;size:100;not null"`
+	EventType      string     `json:"event_type" gorm:"index;size:100;not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"` // 发送的请求体，重新投递时原样发送
+	Status         string     `json:"status" gorm:"index:idx_delivery_due;size:20;not null;default:'pending'"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error" gorm:"size:1000"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_delivery_due;not null"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	AttemptLog []*DeliveryAttempt `json:"attempt_log,omitempty" gorm:"foreignKey:DeliveryID"`
+}
+
+// DeliveryAttempt 记录一次投递尝试的请求结果
+type DeliveryAttempt struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	DeliveryID   uint      `json:"delivery_id" gorm:"index;not null"`
+	Attempt      int       `json:"attempt" gorm:"not null"`
+	StatusCode   int       `json:"status_code"` // 请求未得到响应时为 0
+	ResponseBody string    `json:"response_body" gorm:"size:1000"`
+	Error        string    `json:"error" gorm:"size:1000"`
+	DurationMs   int64     `json:"duration_ms"`
+	Manual       bool      `json:"manual"` // 手动重新投递
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// StringSlice 是一个自定义类型，用于存储字符串数组
+type StringSlice []string
+
+// Value 实现 driver.Valuer 接口
+func (a StringSlice) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (a *StringSlice) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("类型断言为 []byte 失败")
+	}
+	return json.Unmarshal(b, &a)
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/goshop/services/webhook/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeliveryFilter 表示查询投递记录的过滤条件，零值字段不参与过滤
+type DeliveryFilter struct {
+	EndpointIDs []uint
+	Status      string
+	EventType   string
+}
+
+// DeliveryRepository 定义投递记录仓库接口
+type DeliveryRepository interface {
+	Create(ctx context.Context, delivery *model.Delivery) (bool, error)
+	GetByID(ctx context.Context, id uint) (*model.Delivery, error)
+	Update(ctx context.Context, delivery *model.Delivery) error
+	SaveAttempt(ctx context.Context, delivery *model.Delivery, attempt *model.DeliveryAttempt) error
+	List(ctx context.Context, filter DeliveryFilter, offset, limit int) ([]*model.Delivery, int64, error)
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*model.Delivery, error)
+	Claim(ctx context.Context, id uint, now, until time.Time) (bool, error)
+}
+
+// GormDeliveryRepository 实现 DeliveryRepository 接口的 GORM 仓库
+type GormDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewDeliveryRepository 创建投递记录仓库实例
+func NewDeliveryRepository(db *gorm.DB) DeliveryRepository {
+	return &GormDeliveryRepository{
+		db: db,
+	}
+}
+
+// Create 创建投递记录，同一事件对同一端点的投递已存在时不创建并返回 false
+func (r *GormDeliveryRepository) Create(ctx context.Context, delivery *model.Delivery) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetByID 根据 ID 获取投递记录及其每次尝试的结果
+func (r *GormDeliveryRepository) GetByID(ctx context.Context, id uint) (*model.Delivery, error) {
+	var delivery model.Delivery
+	err := r.db.WithContext(ctx).
+		Preload("AttemptLog", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&delivery, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// Update 保存投递状态
+func (r *GormDeliveryRepository) Update(ctx context.Context, delivery *model.Delivery) error {
+	return r.db.WithContext(ctx).Omit("AttemptLog").Save(delivery).Error
+}
+
+// SaveAttempt 在一个事务中保存投递状态和本次尝试的结果
+func (r *GormDeliveryRepository) SaveAttempt(ctx context.Context, delivery *model.Delivery, attempt *model.DeliveryAttempt) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("AttemptLog").Save(delivery).Error; err != nil {
+			return err
+		}
+		return tx.Create(attempt).Error
+	})
+}
+
+// List 按过滤条件分页获取投递记录，按创建时间倒序
+func (r *GormDeliveryRepository) List(ctx context.Context, filter DeliveryFilter, offset, limit int) ([]*model.Delivery, int64, error) {
+	var deliveries []*model.Delivery
+	var total int64
+	query := r.db.WithContext(ctx).Model(&model.Delivery{})
+	if filter.EndpointIDs != nil {
+		query = query.Where("endpoint_id IN ?", filter.EndpointIDs)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// ListDue 获取到期待投递的记录，按到期时间升序
+func (r *GormDeliveryRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*model.Delivery, error) {
+	var deliveries []*model.Delivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", model.StatusPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Claim 将到期的投递顺延到 until，防止投递过程中被再次取出。
+// 投递已被其他进程领取或已不再待投递时返回 false
+func (r *GormDeliveryRepository) Claim(ctx context.Context, id uint, now, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Delivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, model.StatusPending, now).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/goshop/services/webhook/internal/model"
+	"gorm.io/gorm"
+)
+
+// EndpointRepository 定义 Webhook 端点仓库接口
+type EndpointRepository interface {
+	Create(ctx context.Context, endpoint *model.Endpoint) error
+	GetByID(ctx context.Context, id uint) (*model.Endpoint, error)
+	Update(ctx context.Context, endpoint *model.Endpoint) error
+	Delete(ctx context.Context, id uint) error
+	ListByMerchant(ctx context.Context, merchantID uint) ([]*model.Endpoint, error)
+	ListActive(ctx context.Context) ([]*model.Endpoint, error)
+}
+
+// GormEndpointRepository 实现 EndpointRepository 接口的 GORM 仓库
+type GormEndpointRepository struct {
+	db *gorm.DB
+}
+
+// NewEndpointRepository 创建 Webhook 端点仓库实例
+func NewEndpointRepository(db *gorm.DB) EndpointRepository {
+	return &GormEndpointRepository{
+		db: db,
+	}
+}
+
+// Create 创建端点
+func (r *GormEndpointRepository) Create(ctx context.Context, endpoint *model.Endpoint) error {
+	return r.db.WithContext(ctx).Create(endpoint).Error
+}
+
+// GetByID 根据 ID 获取端点
+func (r *GormEndpointRepository) GetByID(ctx context.Context, id uint) (*model.Endpoint, error) {
+	var endpoint model.Endpoint
+	err := r.db.WithContext(ctx).First(&endpoint, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// Update 保存端点
+func (r *GormEndpointRepository) Update(ctx context.Context, endpoint *model.Endpoint) error {
+	return r.db.WithContext(ctx).Save(endpoint).Error
+}
+
+// Delete 删除端点，端点的投递记录保留
+func (r *GormEndpointRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Endpoint{}, id).Error
+}
+
+// ListByMerchant 获取商家的所有端点
+func (r *GormEndpointRepository) ListByMerchant(ctx context.Context, merchantID uint) ([]*model.Endpoint, error) {
+	var endpoints []*model.Endpoint
+	err := r.db.WithContext(ctx).Where("merchant_id = ?", merchantID).Order("id ASC").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// ListActive 获取所有启用的端点
+func (r *GormEndpointRepository) ListActive(ctx context.Context) ([]*model.Endpoint, error) {
+	var endpoints []*model.Endpoint
+	err := r.db.WithContext(ctx).Where("active = ?", true).Find(&endpoints).Error
+	return endpoints, err
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/events"
+	"github.com/yourusername/goshop/pkg/logger"
+	"github.com/yourusername/goshop/services/webhook/internal/model"
+	"github.com/yourusername/goshop/services/webhook/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// sendLease 是领取一条投递后的发送时限，进程在发送中退出时投递会在到期后被重新取出
+	sendLease = 2 * time.Minute
+	// dueBatchSize 是每轮最多取出的到期投递数
+	dueBatchSize = 100
+	// maxErrorLength 是记录的错误和响应体的最大长度
+	maxErrorLength = 1000
+	// maxResponseBytes 是读取的端点响应体的最大字节数
+	maxResponseBytes = 4096
+)
+
+// 投递请求的请求头
+const (
+	HeaderEventID    = "X-Goshop-Event-Id"
+	HeaderEventType  = "X-Goshop-Event-Type"
+	HeaderDeliveryID = "X-Goshop-Delivery-Id"
+	// HeaderSignature 的格式为 t=<Unix 时间戳>,v1=<签名>，签名是以端点密钥对
+	// "<时间戳>.<请求体>" 计算的 HMAC-SHA256 十六进制值，端点应拒绝时间戳过旧的请求以防重放
+	HeaderSignature = "X-Goshop-Signature"
+)
+
+// DeliveryConfig 表示投递的超时和重试设置
+type DeliveryConfig struct {
+	Timeout          time.Duration // 端点响应的超时时间
+	MaxAttempts      int           // 投递次数上限，用尽后转入死信
+	RetryInterval    time.Duration // 首次重试的间隔，之后每次失败翻倍
+	MaxRetryInterval time.Duration // 重试间隔的上限
+}
+
+// DeliveryList 表示分页的投递记录列表
+type DeliveryList struct {
+	Items    []*model.Delivery `json:"items"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// DeliveryService 负责把事件投递到订阅的端点：请求体以端点密钥签名，
+// 失败的投递按指数退避重试，重试次数用尽后转入死信，可以手动重新投递
+type DeliveryService struct {
+	endpointRepo repository.EndpointRepository
+	deliveryRepo repository.DeliveryRepository
+	client       *http.Client
+	cfg          DeliveryConfig
+	log          *logger.Logger
+}
+
+// NewDeliveryService 创建投递服务
+func NewDeliveryService(endpointRepo repository.EndpointRepository, deliveryRepo repository.DeliveryRepository, cfg DeliveryConfig, log *logger.Logger) *DeliveryService {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = time.Minute
+	}
+	if cfg.MaxRetryInterval < cfg.RetryInterval {
+		cfg.MaxRetryInterval = cfg.RetryInterval
+	}
+	return &DeliveryService{
+		endpointRepo: endpointRepo,
+		deliveryRepo: deliveryRepo,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// 重定向视为投递失败，端点应直接返回 2xx
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg: cfg,
+		log: log,
+	}
+}
+
+// Subscribe 订阅可供端点订阅的事件主题
+func (s *DeliveryService) Subscribe(consumer *events.Consumer, subjects []string) error {
+	for _, subject := range subjects {
+		if err := consumer.Subscribe(subject, s.HandleEvent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleEvent 为接收该事件的每个启用的端点生成投递并立即投递，投递失败的由 Run 重试。
+// 同一事件重复处理时不会重复投递
+func (s *DeliveryService) HandleEvent(ctx context.Context, env *events.Envelope) error {
+	endpoints, err := s.endpointRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+	// 商家的端点只接收数据中 seller_id 为该商家的事件
+	var scope struct {
+		SellerID *uint `json:"seller_id"`
+	}
+	_ = env.Decode(&scope)
+
+	var payload []byte
+	for _, endpoint := range endpoints {
+		if !endpoint.Receives(env.Type, scope.SellerID) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(env); err != nil {
+				return err
+			}
+		}
+		d := &model.Delivery{
+			EndpointID:    endpoint.ID,
+			EventID:       env.ID,
+			EventType:     env.Type,
+			Payload:       string(payload),
+			Status:        model.StatusPending,
+			NextAttemptAt: time.Now().Add(sendLease),
+		}
+		// 保存时即领取投递，避免与 Run 同时投递
+		created, err := s.deliveryRepo.Create(ctx, d)
+		if err != nil {
+			return err
+		}
+		if created {
+			s.deliver(ctx, d, endpoint, false)
+		}
+	}
+	return nil
+}
+
+// Run 定期投递到期的投递，直到 ctx 结束
+func (s *DeliveryService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *DeliveryService) tick(ctx context.Context, now time.Time) {
+	due, err := s.deliveryRepo.ListDue(ctx, now, dueBatchSize)
+	if err != nil {
+		s.log.Error(ctx, "Failed to list due deliveries", zap.Error(err))
+		return
+	}
+	for _, d := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		claimed, err := s.deliveryRepo.Claim(ctx, d.ID, now, now.Add(sendLease))
+		if err != nil {
+			s.log.Error(ctx, "Failed to claim delivery", zap.Uint("delivery_id", d.ID), zap.Error(err))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		endpoint, err := s.endpointRepo.GetByID(ctx, d.EndpointID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.log.Error(ctx, "Failed to get endpoint", zap.Uint("endpoint_id", d.EndpointID), zap.Error(err))
+			continue
+		}
+		s.deliver(ctx, d, endpoint, false)
+	}
+}
+
+// List 分页查询投递记录，merchantID 不为 0 时只查询该商家端点的投递
+func (s *DeliveryService) List(ctx context.Context, merchantID uint, filter repository.DeliveryFilter, page, pageSize int) (*DeliveryList, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	if merchantID != 0 {
+		endpoints, err := s.endpointRepo.ListByMerchant(ctx, merchantID)
+		if err != nil {
+			return nil, apperrors.NewInternalServerError("获取端点失败", err)
+		}
+		ids := make([]uint, 0, len(endpoints))
+		for _, endpoint := range endpoints {
+			if len(filter.EndpointIDs) == 0 || containsID(filter.EndpointIDs, endpoint.ID) {
+				ids = append(ids, endpoint.ID)
+			}
+		}
+		filter.EndpointIDs = ids
+	}
+	items, total, err := s.deliveryRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取投递记录失败", err)
+	}
+	return &DeliveryList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Get 获取投递记录及每次尝试的结果，merchantID 不为 0 时投递必须属于该商家的端点
+func (s *DeliveryService) Get(ctx context.Context, merchantID, id uint) (*model.Delivery, error) {
+	d, err := s.deliveryRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("投递记录 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取投递记录失败", err)
+	}
+	if merchantID != 0 {
+		if _, err := getEndpoint(ctx, s.endpointRepo, merchantID, d.EndpointID); err != nil {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("投递记录 %d 不存在", id), err)
+		}
+	}
+	return d, nil
+}
+
+// Redeliver 立即按原请求体重新投递一次，成功后投递标记为成功；失败时已转入死信的投递仍为死信，
+// 等待重试的投递保持原有的重试计划
+func (s *DeliveryService) Redeliver(ctx context.Context, merchantID, id uint) (*model.Delivery, error) {
+	d, err := s.Get(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := getEndpoint(ctx, s.endpointRepo, 0, d.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+	s.deliver(ctx, d, endpoint, true)
+	return s.Get(ctx, merchantID, id)
+}
+
+// deliver 发送一次投递并保存结果。endpoint 为 nil 或已停用时投递直接转入死信
+func (s *DeliveryService) deliver(ctx context.Context, d *model.Delivery, endpoint *model.Endpoint, manual bool) {
+	attempt := &model.DeliveryAttempt{DeliveryID: d.ID, Manual: manual}
+	var err error
+	if endpoint == nil || (!endpoint.Active && !manual) {
+		err = errors.New("端点已删除或已停用")
+	} else {
+		err = s.send(ctx, d, endpoint, attempt)
+	}
+
+	d.Attempts++
+	attempt.Attempt = d.Attempts
+	d.LastStatusCode = attempt.StatusCode
+	now := time.Now()
+	switch {
+	case err == nil:
+		d.Status = model.StatusSucceeded
+		d.LastError = ""
+		d.DeliveredAt = &now
+	case manual:
+		// 手动投递失败不改变投递状态和重试计划
+		d.LastError = truncate(err.Error(), maxErrorLength)
+	case endpoint == nil || !endpoint.Active || d.Attempts >= s.cfg.MaxAttempts:
+		d.Status = model.StatusDeadLetter
+		d.LastError = truncate(err.Error(), maxErrorLength)
+		s.log.Warn(ctx, "Webhook delivery dead-lettered",
+			zap.Uint("delivery_id", d.ID),
+			zap.Uint("endpoint_id", d.EndpointID),
+			zap.String("event_type", d.EventType),
+			zap.Int("attempts", d.Attempts),
+			zap.Error(err),
+		)
+	default:
+		d.LastError = truncate(err.Error(), maxErrorLength)
+		d.NextAttemptAt = now.Add(s.backoff(d.Attempts))
+		s.log.Info(ctx, "Webhook delivery will be retried",
+			zap.Uint("delivery_id", d.ID),
+			zap.Int("attempts", d.Attempts),
+			zap.Time("next_attempt_at", d.NextAttemptAt),
+			zap.Error(err),
+		)
+	}
+	if err != nil {
+		attempt.Error = truncate(err.Error(), maxErrorLength)
+	}
+	if err := s.deliveryRepo.SaveAttempt(ctx, d, attempt); err != nil {
+		s.log.Error(ctx, "Failed to save webhook delivery", zap.Uint("delivery_id", d.ID), zap.Error(err))
+	}
+}
+
+// send 以端点密钥签名并发送请求体，端点返回非 2xx 时返回错误
+func (s *DeliveryService) send(ctx context.Context, d *model.Delivery, endpoint *model.Endpoint, attempt *model.DeliveryAttempt) error {
+	start := time.Now()
+	defer func() { attempt.DurationMs = time.Since(start).Milliseconds() }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader([]byte(d.Payload)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoShop-Webhooks/1.0")
+	req.Header.Set(HeaderEventID, d.EventID)
+	req.Header.Set(HeaderEventType, d.EventType)
+	req.Header.Set(HeaderDeliveryID, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, start, []byte(d.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	attempt.StatusCode = resp.StatusCode
+	attempt.ResponseBody = truncate(string(body), maxErrorLength)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("端点返回 HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff 返回第 attempts 次失败后的重试间隔，每次翻倍，不超过上限
+func (s *DeliveryService) backoff(attempts int) time.Duration {
+	delay := s.cfg.RetryInterval
+	for i := 1; i < attempts && delay < s.cfg.MaxRetryInterval; i++ {
+		delay *= 2
+	}
+	return min(delay, s.cfg.MaxRetryInterval)
+}
+
+// Sign 计算投递请求的签名头，端点以相同方式计算签名并用常量时间比较
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// truncate 截断到 n 个字符
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
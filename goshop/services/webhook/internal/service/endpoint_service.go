@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	apperrors "github.com/yourusername/goshop/pkg/errors"
+	"github.com/yourusername/goshop/pkg/rbac"
+	"github.com/yourusername/goshop/services/webhook/internal/client"
+	"github.com/yourusername/goshop/services/webhook/internal/model"
+	"github.com/yourusername/goshop/services/webhook/internal/repository"
+	"gorm.io/gorm"
+)
+
+// secretPrefix 是签名密钥的前缀，便于商家识别密钥的用途
+const secretPrefix = "whsec_"
+
+// eventTypePattern 匹配订阅项：完整的事件类型、以 .* 结尾的前缀或 *
+var eventTypePattern = regexp.MustCompile(`^(\*|[a-z_]+(\.[a-z_]+)*(\.\*)?)$`)
+
+// EndpointRequest 表示创建或更新端点的请求
+type EndpointRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=500"`
+	Description string   `json:"description" binding:"max=255"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1,max=50,dive,required,max=100"`
+	Active      *bool    `json:"active"` // 创建时默认启用
+}
+
+// EndpointWithSecret 表示创建或轮换密钥后的端点，只有此时返回签名密钥
+type EndpointWithSecret struct {
+	*model.Endpoint
+	Secret string `json:"secret"`
+}
+
+// backOfficeRoles 是可以管理平台端点的角色，平台端点接收订阅的全部事件
+var backOfficeRoles = []string{"admin", "staff"}
+
+// EndpointService 负责商家注册和管理 Webhook 端点
+type EndpointService struct {
+	endpointRepo repository.EndpointRepository
+	enforcer     *rbac.Enforcer
+	sellers      *client.SellerClient
+	allowHTTP    bool
+}
+
+// NewEndpointService 创建端点服务，通过 enforcer 识别后台人员、通过 sellers 识别商家，
+// allowHTTP 为 false 时端点必须使用 https
+func NewEndpointService(endpointRepo repository.EndpointRepository, enforcer *rbac.Enforcer, sellers *client.SellerClient, allowHTTP bool) *EndpointService {
+	return &EndpointService{
+		endpointRepo: endpointRepo,
+		enforcer:     enforcer,
+		sellers:      sellers,
+		allowHTTP:    allowHTTP,
+	}
+}
+
+// Owner 返回用户注册的端点所属的商家 ID。后台人员返回 0，其端点接收订阅的全部事件；
+// 正常营业的商家返回商家 ID；其他用户不能管理端点和投递记录
+func (s *EndpointService) Owner(ctx context.Context, userID uint) (uint, error) {
+	grants, err := s.enforcer.Grants(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for _, role := range backOfficeRoles {
+		if grants.HasRole(role) {
+			return 0, nil
+		}
+	}
+	sellerID, err := s.sellers.ActiveSellerID(ctx, userID)
+	if err != nil {
+		return 0, apperrors.NewServiceUnavailable("暂时无法获取商家信息", err)
+	}
+	if sellerID == 0 {
+		return 0, apperrors.NewForbidden("只有已入驻的商家可以管理 Webhook", nil)
+	}
+	return sellerID, nil
+}
+
+// Create 为商家注册端点并生成签名密钥，sellerID 为 Owner 返回的商家 ID
+func (s *EndpointService) Create(ctx context.Context, merchantID, sellerID uint, req *EndpointRequest) (*EndpointWithSecret, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成签名密钥失败", err)
+	}
+	endpoint := &model.Endpoint{
+		MerchantID:  merchantID,
+		SellerID:    sellerID,
+		URL:         req.URL,
+		Description: req.Description,
+		Secret:      secret,
+		EventTypes:  model.StringSlice(req.EventTypes),
+		Active:      req.Active == nil || *req.Active,
+	}
+	if err := s.endpointRepo.Create(ctx, endpoint); err != nil {
+		return nil, apperrors.NewInternalServerError("创建端点失败", err)
+	}
+	return &EndpointWithSecret{Endpoint: endpoint, Secret: secret}, nil
+}
+
+// List 获取商家的所有端点
+func (s *EndpointService) List(ctx context.Context, merchantID uint) ([]*model.Endpoint, error) {
+	endpoints, err := s.endpointRepo.ListByMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("获取端点失败", err)
+	}
+	return endpoints, nil
+}
+
+// Get 获取商家的端点，merchantID 为 0 时不校验归属
+func (s *EndpointService) Get(ctx context.Context, merchantID, id uint) (*model.Endpoint, error) {
+	return getEndpoint(ctx, s.endpointRepo, merchantID, id)
+}
+
+// Update 更新端点的地址、订阅的事件类型和启用状态
+func (s *EndpointService) Update(ctx context.Context, merchantID, id uint, req *EndpointRequest) (*model.Endpoint, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+	endpoint, err := getEndpoint(ctx, s.endpointRepo, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.URL = req.URL
+	endpoint.Description = req.Description
+	endpoint.EventTypes = model.StringSlice(req.EventTypes)
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+	if err := s.endpointRepo.Update(ctx, endpoint); err != nil {
+		return nil, apperrors.NewInternalServerError("更新端点失败", err)
+	}
+	return endpoint, nil
+}
+
+// Delete 删除端点，尚未完成的投递会在下次尝试时转入死信
+func (s *EndpointService) Delete(ctx context.Context, merchantID, id uint) error {
+	if _, err := getEndpoint(ctx, s.endpointRepo, merchantID, id); err != nil {
+		return err
+	}
+	if err := s.endpointRepo.Delete(ctx, id); err != nil {
+		return apperrors.NewInternalServerError("删除端点失败", err)
+	}
+	return nil
+}
+
+// RotateSecret 为端点生成新的签名密钥，旧密钥立即失效
+func (s *EndpointService) RotateSecret(ctx context.Context, merchantID, id uint) (*EndpointWithSecret, error) {
+	endpoint, err := getEndpoint(ctx, s.endpointRepo, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, apperrors.NewInternalServerError("生成签名密钥失败", err)
+	}
+	endpoint.Secret = secret
+	if err := s.endpointRepo.Update(ctx, endpoint); err != nil {
+		return nil, apperrors.NewInternalServerError("轮换签名密钥失败", err)
+	}
+	return &EndpointWithSecret{Endpoint: endpoint, Secret: secret}, nil
+}
+
+// validate 校验端点地址的协议和订阅项的格式
+func (s *EndpointService) validate(req *EndpointRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || u.Host == "" {
+		return apperrors.NewBadRequest("无效的端点地址", err)
+	}
+	if u.Scheme != "https" && !(s.allowHTTP && u.Scheme == "http") {
+		return apperrors.NewBadRequest("端点地址必须使用 https", nil)
+	}
+	for _, eventType := range req.EventTypes {
+		if !eventTypePattern.MatchString(eventType) {
+			return apperrors.NewBadRequest(fmt.Sprintf("无效的事件类型 %s", eventType), nil)
+		}
+	}
+	return nil
+}
+
+// getEndpoint 获取端点，merchantID 不为 0 时端点必须属于该商家
+func getEndpoint(ctx context.Context, endpointRepo repository.EndpointRepository, merchantID, id uint) (*model.Endpoint, error) {
+	endpoint, err := endpointRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFound(fmt.Sprintf("端点 %d 不存在", id), err)
+		}
+		return nil, apperrors.NewInternalServerError("获取端点失败", err)
+	}
+	if merchantID != 0 && endpoint.MerchantID != merchantID {
+		return nil, apperrors.NewNotFound(fmt.Sprintf("端点 %d 不存在", id), nil)
+	}
+	return endpoint, nil
+}
+
+// newSecret 生成随机的签名密钥
+func newSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}